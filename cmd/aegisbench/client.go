@@ -0,0 +1,78 @@
+// file: cmd/aegisbench/client.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// gatewayClient 是对网关 HTTP API 的最小封装
+type gatewayClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newGatewayClient(baseURL string, timeout time.Duration, maxConns int) *gatewayClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxConns
+	transport.MaxIdleConnsPerHost = maxConns
+	return &gatewayClient{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		http:    &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// login 使用用户名与密码登录，并在之后的请求中携带返回的令牌
+func (c *gatewayClient) login(user, pass string) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.call(http.MethodPost, "/auth/login", map[string]string{"user": user, "pass": pass}, &resp); err != nil {
+		return fmt.Errorf("登录失败: %w", err)
+	}
+	c.token = resp.Token
+	return nil
+}
+
+// call 发送一个 JSON 请求，非 2xx 响应作为错误返回。out 为 nil 时丢弃响应体。
+func (c *gatewayClient) call(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s 返回 %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
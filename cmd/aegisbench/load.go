@@ -0,0 +1,190 @@
+// file: cmd/aegisbench/load.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// opStats 收集某一类请求的延迟样本与错误数
+type opStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int64
+	lastErr   error
+}
+
+func (s *opStats) record(d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.errors++
+		s.lastErr = err
+		return
+	}
+	s.latencies = append(s.latencies, d)
+}
+
+// percentile 返回已排序样本的第 p 百分位 (最近秩法)
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func runLoad(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	gateway := fs.String("gateway", "http://localhost:10224", "网关地址")
+	user := fs.String("user", "", "登录用户名；为空时以匿名身份查询 (写入需要登录)")
+	pass := fs.String("pass", "", "登录密码")
+	bizName := fs.String("biz", "bench", "业务组名称")
+	concurrency := fs.Int("concurrency", 16, "并发 worker 数")
+	duration := fs.Duration("duration", 30*time.Second, "压测时长")
+	warmup := fs.Duration("warmup", 0, "预热时长，期间的请求不计入统计")
+	mutateRatio := fs.Float64("mutate-ratio", 0, "写请求占比 (0-1)")
+	fuzzyRatio := fs.Float64("fuzzy-ratio", 0.2, "查询中使用模糊匹配 (title LIKE) 的比例，其余为 creator 等值查询")
+	pageSize := fs.Int("page-size", 20, "每页记录数")
+	maxPage := fs.Int("max-page", 10, "随机页码的上限")
+	timeout := fs.Duration("timeout", 10*time.Second, "单个请求的超时时间")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "随机数种子")
+	sh := addShapeFlags(fs)
+	_ = fs.Parse(args)
+
+	if *concurrency < 1 {
+		return fmt.Errorf("concurrency 必须 >= 1")
+	}
+	client := newGatewayClient(*gateway, *timeout, *concurrency)
+	if *user != "" {
+		if err := client.login(*user, *pass); err != nil {
+			return err
+		}
+	} else if *mutateRatio > 0 {
+		return fmt.Errorf("写请求需要登录，请指定 -user 与 -pass")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *warmup+*duration)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	stats := map[string]*opStats{"query": {}, "mutate": {}}
+	measureFrom := time.Now().Add(*warmup)
+	var nextID atomic.Int64
+	// 写入的主键从较大的值开始，避免与 generate 生成的记录冲突
+	nextID.Store(1 << 40)
+
+	fmt.Printf("▶️  压测 %s (业务组 %s)：%d 并发，预热 %s，持续 %s，写请求占比 %.0f%%\n",
+		*gateway, *bizName, *concurrency, *warmup, *duration, *mutateRatio*100)
+
+	var wg sync.WaitGroup
+	for w := 0; w < *concurrency; w++ {
+		wg.Add(1)
+		rng := rand.New(rand.NewSource(*seed + int64(w)))
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				op, body, path := "query", buildQuery(rng, *bizName, *sh, *fuzzyRatio, *pageSize, *maxPage), "/data/query"
+				if rng.Float64() < *mutateRatio {
+					op, body, path = "mutate", buildCreate(rng, *bizName, *sh, nextID.Add(1)), "/data/mutate"
+				}
+				started := time.Now()
+				err := client.call(http.MethodPost, path, body, nil)
+				if ctx.Err() != nil {
+					return // 压测结束时被取消的请求不计入统计
+				}
+				if started.After(measureFrom) {
+					stats[op].record(time.Since(started), err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	measured := *duration
+	if elapsed := time.Since(measureFrom); elapsed < measured {
+		measured = elapsed
+	}
+	report(stats, measured)
+	return nil
+}
+
+func buildQuery(rng *rand.Rand, bizName string, sh shape, fuzzyRatio float64, pageSize, maxPage int) map[string]interface{} {
+	filter := map[string]interface{}{"field": "creator", "value": creatorName(rng.Intn(creatorPoolSize))}
+	if rng.Float64() < fuzzyRatio {
+		filter = map[string]interface{}{"field": "title", "value": string(textAlphabet[rng.Intn(len(textAlphabet)-1)]), "fuzzy": true}
+	}
+	return map[string]interface{}{
+		"biz_name": bizName,
+		"query": map[string]interface{}{
+			"table":   sh.table,
+			"filters": []interface{}{filter},
+			"page":    1 + rng.Intn(maxPage),
+			"size":    pageSize,
+		},
+	}
+}
+
+func buildCreate(rng *rand.Rand, bizName string, sh shape, id int64) map[string]interface{} {
+	data := map[string]interface{}{
+		"id":      id,
+		"title":   randomText(rng, 24),
+		"creator": creatorName(rng.Intn(creatorPoolSize)),
+		"year":    1800 + rng.Intn(225),
+	}
+	for _, col := range sh.columns()[4:] {
+		data[col] = randomText(rng, sh.textLength)
+	}
+	return map[string]interface{}{
+		"biz_name":  bizName,
+		"operation": "create",
+		"payload":   map[string]interface{}{"table_name": sh.table, "data": data},
+	}
+}
+
+// report 以表格形式输出每类请求的吞吐量、错误数与延迟分位数
+func report(stats map[string]*opStats, measured time.Duration) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "op\trequests\terrors\treq/s\tp50\tp90\tp95\tp99\tmax\t")
+	for _, op := range []string{"query", "mutate"} {
+		s := stats[op]
+		s.mu.Lock()
+		sorted := append([]time.Duration(nil), s.latencies...)
+		errs, lastErr := s.errors, s.lastErr
+		s.mu.Unlock()
+		if len(sorted) == 0 && errs == 0 {
+			continue
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		total := int64(len(sorted)) + errs
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t\n",
+			op, total, errs, float64(total)/measured.Seconds(),
+			round(percentile(sorted, 50)), round(percentile(sorted, 90)), round(percentile(sorted, 95)),
+			round(percentile(sorted, 99)), round(percentile(sorted, 100)))
+		if lastErr != nil {
+			defer fmt.Printf("⚠️  %s 最近一次错误: %v\n", op, lastErr)
+		}
+	}
+	_ = w.Flush()
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
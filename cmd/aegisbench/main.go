@@ -0,0 +1,54 @@
+// file: cmd/aegisbench/main.go
+// aegisbench 是 ArchiveAegis 的压测工具：生成合成的 SQLite 档案库、在网关中注册业务组，
+// 并以可配置的并发驱动查询/写入负载，输出各类请求的延迟分位数，用于上线前的容量验证。
+//
+// 用法:
+//
+//	aegisbench generate -instance ./instance -biz bench -libs 4 -rows 100000
+//	aegisbench register -gateway http://localhost:10224 -user admin -pass password -biz bench
+//	aegisbench run      -gateway http://localhost:10224 -user admin -pass password -biz bench -concurrency 32 -duration 1m
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `aegisbench - ArchiveAegis 压测工具
+
+子命令:
+  generate  生成合成的 SQLite 档案库文件
+  register  在网关中配置业务组 (可选地创建并启动插件实例)
+  run       对网关施加并发查询/写入负载并报告延迟分位数
+
+使用 "aegisbench <子命令> -h" 查看各子命令的参数。`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = runGenerate(os.Args[2:])
+	case "register":
+		err = runRegister(os.Args[2:])
+	case "run":
+		err = runLoad(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "未知子命令: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
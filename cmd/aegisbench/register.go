@@ -0,0 +1,115 @@
+// file: cmd/aegisbench/register.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func runRegister(args []string) error {
+	fs := flag.NewFlagSet("register", flag.ExitOnError)
+	gateway := fs.String("gateway", "http://localhost:10224", "网关地址")
+	user := fs.String("user", "admin", "管理员用户名")
+	pass := fs.String("pass", "", "管理员密码")
+	bizName := fs.String("biz", "bench", "业务组名称")
+	pluginID := fs.String("plugin-id", "", "非空时为业务组创建并启动该插件的实例 (独立进程模式下插件需已安装)")
+	pluginVersion := fs.String("plugin-version", "1.0.0", "插件版本")
+	pluginMode := fs.String("plugin-mode", "process", "插件实例的运行方式: process 或 embedded (嵌入式无需安装插件)")
+	startTimeout := fs.Duration("start-timeout", 30*time.Second, "等待插件实例进入 RUNNING 状态的最长时间")
+	sh := addShapeFlags(fs)
+	_ = fs.Parse(args)
+
+	client := newGatewayClient(*gateway, 30*time.Second, 4)
+	if err := client.login(*user, *pass); err != nil {
+		return err
+	}
+
+	if *pluginID != "" {
+		if err := startPluginInstance(client, *bizName, *pluginID, *pluginVersion, *pluginMode, *startTimeout); err != nil {
+			return err
+		}
+	}
+
+	base := "/admin/biz-config/" + *bizName
+	if err := client.call(http.MethodPut, base+"/settings", map[string]interface{}{
+		"is_publicly_searchable": true,
+		"default_query_table":    sh.table,
+	}, nil); err != nil {
+		return fmt.Errorf("配置业务组失败: %w", err)
+	}
+	if err := client.call(http.MethodPut, base+"/tables", map[string]interface{}{
+		"searchable_tables": []string{sh.table},
+	}, nil); err != nil {
+		return fmt.Errorf("配置可搜索表失败: %w", err)
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	for _, col := range sh.columns() {
+		dataType := "text"
+		if col == "id" || col == "year" {
+			dataType = "integer"
+		}
+		fields = append(fields, map[string]interface{}{
+			"field_name":    col,
+			"is_searchable": col == "creator" || col == "year" || col == "id",
+			"is_returnable": true,
+			"dataType":      dataType,
+		})
+	}
+	tableBase := base + "/tables/" + sh.table
+	if err := client.call(http.MethodPut, tableBase+"/fields", fields, nil); err != nil {
+		return fmt.Errorf("配置字段失败: %w", err)
+	}
+	if err := client.call(http.MethodPut, tableBase+"/permissions", map[string]interface{}{
+		"allow_create": true,
+		"allow_update": false,
+		"allow_delete": false,
+	}, nil); err != nil {
+		return fmt.Errorf("配置写权限失败: %w", err)
+	}
+
+	fmt.Printf("✅ 业务组 '%s' 已配置，表 '%s' 可搜索并允许写入\n", *bizName, sh.table)
+	return nil
+}
+
+// startPluginInstance 为业务组创建插件实例并等待其运行
+func startPluginInstance(client *gatewayClient, bizName, pluginID, version, mode string, timeout time.Duration) error {
+	var created struct {
+		InstanceID string `json:"instance_id"`
+	}
+	if err := client.call(http.MethodPost, "/admin/plugins/instances", map[string]string{
+		"display_name": "aegisbench " + bizName,
+		"plugin_id":    pluginID,
+		"version":      version,
+		"biz_name":     bizName,
+		"mode":         mode,
+	}, &created); err != nil {
+		return fmt.Errorf("创建插件实例失败: %w", err)
+	}
+	if err := client.call(http.MethodPost, "/admin/plugins/instances/"+created.InstanceID+"/start", nil, nil); err != nil {
+		return fmt.Errorf("启动插件实例失败: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var list struct {
+			Data []struct {
+				InstanceID string `json:"instance_id"`
+				Status     string `json:"status"`
+			} `json:"data"`
+		}
+		if err := client.call(http.MethodGet, "/admin/plugins/instances", nil, &list); err != nil {
+			return err
+		}
+		for _, inst := range list.Data {
+			if inst.InstanceID == created.InstanceID && inst.Status == "RUNNING" {
+				fmt.Printf("✅ 插件实例 %s 已运行\n", created.InstanceID)
+				return nil
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("插件实例 %s 未能在 %s 内进入 RUNNING 状态", created.InstanceID, timeout)
+}
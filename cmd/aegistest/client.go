@@ -0,0 +1,109 @@
+// file: cmd/aegistest/client.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// requestRate 与 requestBurst 低于网关默认的每 IP 限制 (1 req/s，峰值 20)，
+// 场景中所有请求都来自同一地址，超出后不仅会被拒绝，还会被记入违规计数
+const (
+	requestRate  = 0.9
+	requestBurst = 10
+)
+
+// gatewayClient 是对网关 HTTP API 的最小封装，场景需要同时检查状态码与响应体
+type gatewayClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	pacer   *rate.Limiter
+}
+
+func newGatewayClient(port int) *gatewayClient {
+	return &gatewayClient{
+		baseURL: fmt.Sprintf("http://127.0.0.1:%d/api/v1", port),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		pacer:   rate.NewLimiter(requestRate, requestBurst),
+	}
+}
+
+// do 发送一个 JSON 请求并返回状态码；状态码为 2xx 且 out 不为 nil 时解码响应体，
+// 否则返回响应体的前 512 字节，用于在报告中说明失败原因。anonymous 为 true 时不携带令牌。
+func (c *gatewayClient) do(method, path string, body, out interface{}, anonymous bool) (int, string, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return 0, "", err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" && !anonymous {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if err := c.pacer.Wait(context.Background()); err != nil {
+		return 0, "", err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, strings.TrimSpace(string(msg)), nil
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, "", nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, "", fmt.Errorf("%s %s 的响应体无法解析: %w", method, path, err)
+	}
+	return resp.StatusCode, "", nil
+}
+
+// call 以管理员身份发送请求，非 2xx 响应作为错误返回
+func (c *gatewayClient) call(method, path string, body, out interface{}) error {
+	status, msg, err := c.do(method, path, body, out, false)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("%s %s 返回 %d: %s", method, path, status, msg)
+	}
+	return nil
+}
+
+// expect 以管理员身份发送请求并要求返回 want 状态码，否则作为约定违反返回错误
+func (c *gatewayClient) expect(want int, method, path string, body, out interface{}) error {
+	if want < 200 || want > 299 {
+		out = nil
+	}
+	status, msg, err := c.do(method, path, body, out, false)
+	if err != nil {
+		return err
+	}
+	if status != want {
+		return fmt.Errorf("%s %s 期望返回 %d，实际为 %d: %s", method, path, want, status, msg)
+	}
+	return nil
+}
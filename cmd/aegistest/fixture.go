@@ -0,0 +1,249 @@
+// file: cmd/aegistest/fixture.go
+package main
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"archive/zip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// testPluginID 与网关随附的 SQLite 插件一致，夹具仓库只提供场景专用的版本号
+	testPluginID      = "io.archiveaegis.sqlite"
+	testPluginVersion = "0.0.0-aegistest"
+	testTable         = "records"
+	// creatorCount 决定 creator 列的取值个数，第 i 条记录的 creator 为 creator-(i % creatorCount)
+	creatorCount = 5
+)
+
+// fixture 是为一次场景准备的临时网关根目录，布局与发布包一致: bin/、configs/、instance/
+type fixture struct {
+	root       string
+	gatewayBin string
+	port       int
+	logPath    string
+}
+
+// prepareFixture 在临时目录中布置网关根目录、本地插件仓库、网关配置与夹具库
+func prepareFixture(opts options) (*fixture, error) {
+	root, err := os.MkdirTemp("", "aegistest-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	fx := &fixture{root: root, logPath: filepath.Join(root, "gateway.log")}
+
+	// 网关以可执行文件所在目录的上一级作为根目录，因此必须复制而不是链接
+	fx.gatewayBin = filepath.Join(root, "bin", filepath.Base(opts.gatewayBin))
+	if err := copyExecutable(opts.gatewayBin, fx.gatewayBin); err != nil {
+		return fx, fmt.Errorf("复制网关可执行文件失败: %w", err)
+	}
+	if err := writeRepository(root, opts.pluginBin); err != nil {
+		return fx, fmt.Errorf("生成本地插件仓库失败: %w", err)
+	}
+	if fx.port, err = freePort(); err != nil {
+		return fx, fmt.Errorf("分配网关端口失败: %w", err)
+	}
+	if err := writeConfig(root, opts.baseConfig, fx.port); err != nil {
+		return fx, fmt.Errorf("生成网关配置失败: %w", err)
+	}
+	if err := writeSampleDB(filepath.Join(root, "instance", opts.bizName, "aegistest.db"), opts.rows); err != nil {
+		return fx, fmt.Errorf("生成夹具库失败: %w", err)
+	}
+	return fx, nil
+}
+
+func copyExecutable(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeRepository 把插件可执行文件打包为 zip，并生成引用它 (带 sha256 校验和) 的仓库清单 repo/repository.json
+func writeRepository(root, pluginBin string) error {
+	repoDir := filepath.Join(root, "repo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return err
+	}
+	entrypoint := filepath.Base(pluginBin)
+	zipPath := filepath.Join(repoDir, "sqlite_plugin.zip")
+	if err := zipExecutable(pluginBin, entrypoint, zipPath); err != nil {
+		return err
+	}
+	checksum, err := fileSHA256(zipPath)
+	if err != nil {
+		return err
+	}
+
+	repo := domain.Repository{
+		Name:        "aegistest 本地仓库",
+		Owner:       "aegistest",
+		LastUpdated: time.Now().UTC(),
+		Plugins: []domain.PluginManifest{{
+			ID:          testPluginID,
+			Name:        "SQLite 插件 (aegistest)",
+			Description: "端到端测试使用的 SQLite 插件",
+			Author:      "aegistest",
+			Versions: []domain.PluginVersion{{
+				VersionString: testPluginVersion,
+				ReleaseDate:   time.Now().UTC(),
+				Source:        domain.Source{URL: "./repo/sqlite_plugin.zip", Checksum: "sha256:" + checksum},
+				Execution: domain.Execution{
+					Entrypoint: entrypoint,
+					Args:       []string{"-name", "<name>", "-biz", "<biz_name>", "-host", "<host>", "-port", "<port>", "-instance_dir", "<instance_dir>"},
+				},
+			}},
+		}},
+	}
+	data, err := json.MarshalIndent(repo, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(repoDir, "repository.json"), data, 0644)
+}
+
+func zipExecutable(src, name, zipPath string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	header.SetMode(0755)
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// writeConfig 以运维的配置文件为基础，把监听端口、插件仓库与安装目录改写到临时根目录，
+// 其余配置原样保留，使场景验证的是实际要上线的配置
+func writeConfig(root, baseConfig string, port int) error {
+	v := viper.New()
+	if baseConfig != "" {
+		v.SetConfigFile(baseConfig)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("读取基础配置 '%s' 失败: %w", baseConfig, err)
+		}
+	}
+	v.Set("server.port", port)
+	v.Set("plugin_management.install_directory", "./instance/plugins")
+	v.Set("plugin_management.repositories", []map[string]interface{}{
+		{"name": "aegistest", "url": "./repo/repository.json", "enabled": true},
+	})
+	// 固定的端口范围与抓取端口可能被同机的其它网关占用
+	v.Set("plugin_management.ports", map[string]interface{}{"bind_address": "127.0.0.1", "range_start": 0, "range_end": 0})
+	v.Set("observability.metrics_address", "")
+
+	configDir := filepath.Join(root, "configs")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+	return v.WriteConfigAs(filepath.Join(configDir, "config.yaml"))
+}
+
+// writeSampleDB 生成场景查询使用的夹具库: records(id, title, creator)
+func writeSampleDB(path string, rows int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE ` + testTable + ` (id INTEGER PRIMARY KEY, title TEXT NOT NULL, creator TEXT NOT NULL)`); err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO ` + testTable + ` (id, title, creator) VALUES (?, ?, ?)`)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for i := 1; i <= rows; i++ {
+		if _, err := stmt.Exec(i, fmt.Sprintf("record %d", i), creatorName(i)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func creatorName(i int) string {
+	return fmt.Sprintf("creator-%d", i%creatorCount)
+}
+
+// expectedByCreator 返回夹具库中 creator 为 creatorName(i) 的记录数
+func expectedByCreator(rows, i int) int {
+	count := 0
+	for id := 1; id <= rows; id++ {
+		if id%creatorCount == i%creatorCount {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,119 @@
+// file: cmd/aegistest/gateway.go
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// gatewayProcess 是场景启动的网关子进程，输出写入临时目录中的 gateway.log
+type gatewayProcess struct {
+	cmd  *exec.Cmd
+	log  *os.File
+	done chan struct{}
+	err  error
+}
+
+func startGateway(fx *fixture) (*gatewayProcess, error) {
+	logFile, err := os.Create(fx.logPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建网关日志文件失败: %w", err)
+	}
+	cmd := exec.Command(fx.gatewayBin)
+	cmd.Dir = fx.root
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		_ = logFile.Close()
+		return nil, fmt.Errorf("启动网关失败: %w", err)
+	}
+	g := &gatewayProcess{cmd: cmd, log: logFile, done: make(chan struct{})}
+	go func() {
+		g.err = cmd.Wait()
+		_ = logFile.Close()
+		close(g.done)
+	}()
+	return g, nil
+}
+
+// exited 报告网关进程是否已经退出
+func (g *gatewayProcess) exited() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitReady 轮询 /system/status 直到网关开始响应；该接口不经过限流器，轮询不占用场景的请求配额
+func (g *gatewayProcess) waitReady(port int, timeout time.Duration) error {
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/system/status", port)
+	client := &http.Client{Timeout: 2 * time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if g.exited() {
+			return fmt.Errorf("网关在就绪前退出: %v", g.err)
+		}
+		if resp, err := client.Get(url); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("网关未能在 %s 内就绪", timeout)
+}
+
+// stop 发送停机信号并等待网关退出，超时后强制结束。返回网关的退出错误 (正常退出时为 nil)
+func (g *gatewayProcess) stop(timeout time.Duration) error {
+	if g.exited() {
+		return fmt.Errorf("网关已提前退出: %v", g.err)
+	}
+	if err := g.cmd.Process.Signal(os.Interrupt); err != nil {
+		_ = g.cmd.Process.Kill()
+		<-g.done
+		return fmt.Errorf("无法向网关发送停机信号: %w", err)
+	}
+	select {
+	case <-g.done:
+		return g.err
+	case <-time.After(timeout):
+		_ = g.cmd.Process.Kill()
+		<-g.done
+		return fmt.Errorf("网关未能在 %s 内完成优雅停机，已强制结束", timeout)
+	}
+}
+
+// kill 在场景中途失败时结束网关，不关心其退出状态
+func (g *gatewayProcess) kill() {
+	if g.exited() {
+		return
+	}
+	_ = g.cmd.Process.Kill()
+	<-g.done
+}
+
+// tailLog 返回网关日志的最后 n 行，用于在失败时给出上下文
+func tailLog(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
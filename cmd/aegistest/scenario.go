@@ -0,0 +1,357 @@
+// file: cmd/aegistest/scenario.go
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	adminUser = "aegistest"
+	// mutateID 是写入场景新增记录的主键，夹具库的主键从 1 开始连续编号
+	mutateID = 1 << 30
+	// anonymousBurst 是限流场景为匿名访问者配置的峰值，已登录请求使用宽松的限制以免干扰其它场景
+	anonymousBurst = 3
+)
+
+// scenario 保存场景各步骤之间共享的状态
+type scenario struct {
+	opts       options
+	fx         *fixture
+	gateway    *gatewayProcess
+	client     *gatewayClient
+	instanceID string
+	pluginPort int
+}
+
+type step struct {
+	name string
+	run  func(s *scenario) error
+}
+
+var steps = []step{
+	{"setup", (*scenario).setup},
+	{"plugin", (*scenario).installPlugin},
+	{"config", (*scenario).configure},
+	{"query", (*scenario).query},
+	{"mutate", (*scenario).mutate},
+	{"rate_limit", (*scenario).rateLimit},
+	{"shutdown", (*scenario).shutdown},
+}
+
+// run 布置临时根目录、启动网关并依次执行各步骤，遇到第一个失败的步骤即停止
+func run(opts options) error {
+	fx, err := prepareFixture(opts)
+	if fx != nil {
+		defer func() {
+			if opts.keep {
+				fmt.Printf("📁 临时目录已保留: %s\n", fx.root)
+				return
+			}
+			_ = os.RemoveAll(fx.root)
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	gw, err := startGateway(fx)
+	if err != nil {
+		return err
+	}
+	s := &scenario{opts: opts, fx: fx, gateway: gw, client: newGatewayClient(fx.port)}
+	fmt.Printf("▶️  网关已启动 (端口 %d，根目录 %s)\n", fx.port, fx.root)
+
+	for _, st := range steps {
+		started := time.Now()
+		if err := st.run(s); err != nil {
+			gw.kill()
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n--- gateway.log (最后 40 行) ---\n", st.name, err)
+			for _, line := range tailLog(fx.logPath, 40) {
+				fmt.Fprintln(os.Stderr, line)
+			}
+			return fmt.Errorf("场景 '%s' 违反约定", st.name)
+		}
+		fmt.Printf("✅ %-10s %s\n", st.name, time.Since(started).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// setup 等待网关就绪，用安装令牌创建管理员，并确认可以用新账户登录
+func (s *scenario) setup() error {
+	if err := s.gateway.waitReady(s.fx.port, s.opts.startTimeout); err != nil {
+		return err
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := s.client.expect(http.StatusOK, http.MethodGet, "/system/status", nil, &status); err != nil {
+		return err
+	}
+	if status.Status != "needs_setup" {
+		return fmt.Errorf("全新的实例目录应处于 needs_setup 状态，实际为 '%s'", status.Status)
+	}
+
+	var setupToken struct {
+		Token string `json:"token"`
+	}
+	if err := s.client.expect(http.StatusOK, http.MethodGet, "/system/setup", nil, &setupToken); err != nil {
+		return err
+	}
+	pass, err := randomPassword()
+	if err != nil {
+		return err
+	}
+	if err := s.client.expect(http.StatusOK, http.MethodPost, "/system/setup", map[string]string{
+		"token": setupToken.Token, "user": adminUser, "pass": pass,
+	}, nil); err != nil {
+		return err
+	}
+	// 安装完成后不得再次下发安装令牌
+	if err := s.client.expect(http.StatusForbidden, http.MethodGet, "/system/setup", nil, nil); err != nil {
+		return err
+	}
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := s.client.expect(http.StatusOK, http.MethodPost, "/auth/login", map[string]string{"user": adminUser, "pass": pass}, &login); err != nil {
+		return err
+	}
+	if login.Token == "" {
+		return fmt.Errorf("登录成功但未返回令牌")
+	}
+	s.client.token = login.Token
+	return nil
+}
+
+// installPlugin 从本地仓库夹具安装插件，为业务组创建实例并等待其运行
+func (s *scenario) installPlugin() error {
+	var available struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := s.client.call(http.MethodGet, "/admin/plugins/available", nil, &available); err != nil {
+		return err
+	}
+	found := false
+	for _, p := range available.Data {
+		found = found || p.ID == testPluginID
+	}
+	if !found {
+		return fmt.Errorf("插件目录中没有本地仓库夹具提供的插件 '%s'", testPluginID)
+	}
+
+	if err := s.client.call(http.MethodPost, "/admin/plugins/install", map[string]string{
+		"plugin_id": testPluginID, "version": testPluginVersion,
+	}, nil); err != nil {
+		return err
+	}
+	var created struct {
+		InstanceID string `json:"instance_id"`
+	}
+	if err := s.client.expect(http.StatusCreated, http.MethodPost, "/admin/plugins/instances", map[string]string{
+		"display_name": "aegistest " + s.opts.bizName,
+		"plugin_id":    testPluginID,
+		"version":      testPluginVersion,
+		"biz_name":     s.opts.bizName,
+		"mode":         "process",
+	}, &created); err != nil {
+		return err
+	}
+	s.instanceID = created.InstanceID
+	if err := s.client.call(http.MethodPost, "/admin/plugins/instances/"+s.instanceID+"/start", nil, nil); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.opts.startTimeout)
+	for time.Now().Before(deadline) {
+		var list struct {
+			Data []struct {
+				InstanceID string `json:"instance_id"`
+				Status     string `json:"status"`
+				Port       int    `json:"port"`
+			} `json:"data"`
+		}
+		if err := s.client.call(http.MethodGet, "/admin/plugins/instances", nil, &list); err != nil {
+			return err
+		}
+		for _, inst := range list.Data {
+			if inst.InstanceID == s.instanceID && inst.Status == "RUNNING" {
+				s.pluginPort = inst.Port
+				return nil
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("插件实例 %s 未能在 %s 内进入 RUNNING 状态", s.instanceID, s.opts.startTimeout)
+}
+
+// configure 配置业务组的检索设置、字段、写权限与限流。限流必须在第一次检索之前写入，
+// 网关在业务组第一次被访问时才创建它的限流器
+func (s *scenario) configure() error {
+	base := "/admin/biz-config/" + s.opts.bizName
+	if err := s.client.call(http.MethodPut, base+"/settings", map[string]interface{}{
+		"is_publicly_searchable": true,
+		"default_query_table":    testTable,
+	}, nil); err != nil {
+		return err
+	}
+	if err := s.client.call(http.MethodPut, base+"/tables", map[string]interface{}{
+		"searchable_tables": []string{testTable},
+	}, nil); err != nil {
+		return err
+	}
+	fields := []map[string]interface{}{
+		{"field_name": "id", "is_searchable": true, "is_returnable": true, "dataType": "integer"},
+		{"field_name": "title", "is_searchable": true, "is_returnable": true, "dataType": "text"},
+		{"field_name": "creator", "is_searchable": true, "is_returnable": true, "dataType": "text"},
+	}
+	tableBase := base + "/tables/" + testTable
+	if err := s.client.call(http.MethodPut, tableBase+"/fields", fields, nil); err != nil {
+		return err
+	}
+	if err := s.client.call(http.MethodPut, tableBase+"/permissions", map[string]interface{}{
+		"allow_create": true, "allow_update": false, "allow_delete": false,
+	}, nil); err != nil {
+		return err
+	}
+	return s.client.call(http.MethodPut, base+"/rate-limit", map[string]interface{}{
+		"rate_limit_per_second": 100,
+		"burst_size":            100,
+		"anonymous":             map[string]interface{}{"rate_limit_per_second": 0.1, "burst_size": anonymousBurst},
+	}, nil)
+}
+
+// countByCreator 以管理员身份按 creator 检索并返回 total
+func (s *scenario) countByCreator(creator string) (int, error) {
+	var result struct {
+		Data struct {
+			Items []map[string]interface{} `json:"items"`
+			Total float64                  `json:"total"`
+		} `json:"data"`
+	}
+	if err := s.client.expect(http.StatusOK, http.MethodPost, "/data/query", s.queryBody(creator), &result); err != nil {
+		return 0, err
+	}
+	if len(result.Data.Items) > int(result.Data.Total) {
+		return 0, fmt.Errorf("检索返回 %d 条记录，多于 total %v", len(result.Data.Items), result.Data.Total)
+	}
+	for _, item := range result.Data.Items {
+		if item["creator"] != creator {
+			return 0, fmt.Errorf("检索 creator='%s' 返回了不匹配的记录: %v", creator, item)
+		}
+	}
+	return int(result.Data.Total), nil
+}
+
+func (s *scenario) queryBody(creator string) map[string]interface{} {
+	return map[string]interface{}{
+		"biz_name": s.opts.bizName,
+		"query": map[string]interface{}{
+			"table":   testTable,
+			"filters": []interface{}{map[string]interface{}{"field": "creator", "value": creator}},
+			"page":    1,
+			"size":    20,
+		},
+	}
+}
+
+// query 检查经插件返回的记录数与夹具库一致，且不存在的业务组返回 404
+func (s *scenario) query() error {
+	for i := 0; i < 2 && i < s.opts.rows; i++ {
+		creator := creatorName(i + 1)
+		got, err := s.countByCreator(creator)
+		if err != nil {
+			return err
+		}
+		if want := expectedByCreator(s.opts.rows, i+1); got != want {
+			return fmt.Errorf("检索 creator='%s' 返回 total %d，夹具库中为 %d", creator, got, want)
+		}
+	}
+	return s.client.expect(http.StatusNotFound, http.MethodPost, "/data/query", map[string]interface{}{
+		"biz_name": s.opts.bizName + "-missing",
+		"query":    map[string]interface{}{"table": testTable},
+	}, nil)
+}
+
+// mutate 新增一条记录并确认随后的检索可以读到它
+func (s *scenario) mutate() error {
+	creator := "aegistest-mutate"
+	if err := s.client.call(http.MethodPost, "/data/mutate", map[string]interface{}{
+		"biz_name":  s.opts.bizName,
+		"operation": "create",
+		"payload": map[string]interface{}{
+			"table_name": testTable,
+			"data":       map[string]interface{}{"id": mutateID, "title": "written by aegistest", "creator": creator},
+		},
+	}, nil); err != nil {
+		return err
+	}
+	got, err := s.countByCreator(creator)
+	if err != nil {
+		return err
+	}
+	if got != 1 {
+		return fmt.Errorf("写入后检索 creator='%s' 返回 total %d，期望为 1", creator, got)
+	}
+	return nil
+}
+
+// rateLimit 以匿名身份连续检索，前 anonymousBurst 次必须成功，之后必须在几次之内被业务组的匿名限流拒绝
+func (s *scenario) rateLimit() error {
+	body := s.queryBody(creatorName(1))
+	for i := 1; i <= anonymousBurst+3; i++ {
+		status, msg, err := s.client.do(http.MethodPost, "/data/query", body, nil, true)
+		if err != nil {
+			return err
+		}
+		switch {
+		case status == http.StatusTooManyRequests && i > anonymousBurst:
+			return nil
+		case status == http.StatusTooManyRequests:
+			return fmt.Errorf("第 %d 次匿名检索即被限流，配置的峰值为 %d: %s", i, anonymousBurst, msg)
+		case status != http.StatusOK:
+			return fmt.Errorf("第 %d 次匿名检索返回 %d: %s", i, status, msg)
+		}
+	}
+	return fmt.Errorf("连续 %d 次匿名检索均未被限流 (峰值 %d)", anonymousBurst+3, anonymousBurst)
+}
+
+// shutdown 发送停机信号，要求网关在限定时间内以零状态退出，且插件进程随之停止监听
+func (s *scenario) shutdown() error {
+	if err := s.gateway.stop(s.opts.shutdownTimeout); err != nil {
+		return err
+	}
+	if s.pluginPort == 0 {
+		return nil
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(s.pluginPort))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err != nil {
+			return nil
+		}
+		_ = conn.Close()
+		if time.Now().After(deadline) {
+			return fmt.Errorf("网关退出后插件实例仍在 %s 上监听", addr)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成管理员密码失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -3,12 +3,85 @@
 package main
 
 import (
+	"ArchiveAegis/internal/adapter/configrpc"
+	"ArchiveAegis/internal/adapter/datasource/caching"
+	"ArchiveAegis/internal/adapter/datasource/coalescing"
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
 	"ArchiveAegis/internal/aegmiddleware"
 	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/abuse"
+	"ArchiveAegis/internal/service/accesslog"
 	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/analytics"
+	"ArchiveAegis/internal/service/annotations"
+	"ArchiveAegis/internal/service/approvals"
+	"ArchiveAegis/internal/service/bizclone"
+	"ArchiveAegis/internal/service/bizrename"
+	"ArchiveAegis/internal/service/bookmarks"
+	"ArchiveAegis/internal/service/bootreport"
+	"ArchiveAegis/internal/service/capture"
+	"ArchiveAegis/internal/service/catalog"
+	"ArchiveAegis/internal/service/colstats"
+	"ArchiveAegis/internal/service/compaction"
+	"ArchiveAegis/internal/service/compliance"
+	"ArchiveAegis/internal/service/configtemplates"
+	"ArchiveAegis/internal/service/datadict"
+	"ArchiveAegis/internal/service/datasubject"
+	"ArchiveAegis/internal/service/demo"
+	"ArchiveAegis/internal/service/diagnostics"
+	"ArchiveAegis/internal/service/digest"
+	"ArchiveAegis/internal/service/doctor"
+	"ArchiveAegis/internal/service/embed"
+	"ArchiveAegis/internal/service/exports"
+	"ArchiveAegis/internal/service/fieldguard"
+	"ArchiveAegis/internal/service/fixity"
+	"ArchiveAegis/internal/service/fulltext"
+	"ArchiveAegis/internal/service/identpolicy"
+	"ArchiveAegis/internal/service/iiif"
+	"ArchiveAegis/internal/service/impact"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/internal/service/legacymigrate"
+	"ArchiveAegis/internal/service/licensing"
+	"ArchiveAegis/internal/service/links"
+	"ArchiveAegis/internal/service/maintenance"
+	"ArchiveAegis/internal/service/mockdata"
+	"ArchiveAegis/internal/service/oaipmh"
+	"ArchiveAegis/internal/service/ownership"
+	"ArchiveAegis/internal/service/penalties"
+	"ArchiveAegis/internal/service/piiscan"
 	"ArchiveAegis/internal/service/plugin_manager"
+	"ArchiveAegis/internal/service/policy"
+	"ArchiveAegis/internal/service/preferences"
+	"ArchiveAegis/internal/service/provenance"
+	"ArchiveAegis/internal/service/querydict"
+	"ArchiveAegis/internal/service/queryext"
+	"ArchiveAegis/internal/service/querytemplates"
+	"ArchiveAegis/internal/service/readsplitbiz"
+	"ArchiveAegis/internal/service/replicacheck"
+	"ArchiveAegis/internal/service/replication"
+	"ArchiveAegis/internal/service/resultdiff"
+	"ArchiveAegis/internal/service/semantic"
+	"ArchiveAegis/internal/service/sessions"
+	"ArchiveAegis/internal/service/sharding"
+	"ArchiveAegis/internal/service/sharelinks"
+	"ArchiveAegis/internal/service/siem"
+	"ArchiveAegis/internal/service/signing"
+	"ArchiveAegis/internal/service/sitemap"
+	"ArchiveAegis/internal/service/slo"
+	"ArchiveAegis/internal/service/snapshot"
+	"ArchiveAegis/internal/service/standby"
+	"ArchiveAegis/internal/service/storage"
+	"ArchiveAegis/internal/service/suggest"
+	"ArchiveAegis/internal/service/telemetry"
+	"ArchiveAegis/internal/service/typegen"
+	"ArchiveAegis/internal/service/updates"
+	"ArchiveAegis/internal/service/uploads"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"ArchiveAegis/internal/service/vocabulary"
+	"ArchiveAegis/internal/service/warmup"
 	"ArchiveAegis/internal/transport/http/router"
 	"context"
 	"crypto/rand"
@@ -20,19 +93,35 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
+	// 内嵌时区数据库，业务组的时区设置在没有系统时区数据的主机 (如 Windows) 上同样可用
+	_ "time/tzdata"
 
 	"github.com/spf13/viper"
 	_ "modernc.org/sqlite"
 )
 
-const version = "v1.0.0-alpha5"
+// 版本与构建信息，commit 与 buildDate 在构建时注入，例如：
+//
+//	go build -ldflags "-X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/gateway
+var (
+	version   = "v1.0.0-alpha5"
+	commit    = ""
+	buildDate = ""
+)
+
+// sqlitePluginID 是官方 SQLite 插件在仓库中的标识，也用于登记其嵌入式版本
+const sqlitePluginID = "io.archiveaegis.sqlite"
 
 // =============================================================================
 // 配置与应用核心结构体
@@ -41,16 +130,129 @@ const version = "v1.0.0-alpha5"
 type PluginManagementConfig struct {
 	InstallDirectory string                            `mapstructure:"install_directory"`
 	Repositories     []plugin_manager.RepositoryConfig `mapstructure:"repositories"`
+	// ShutdownGracePeriod 是停止插件时等待其优雅退出的时长，超时后依次发送 SIGTERM 与 SIGKILL
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown_grace_period"`
+	// Ports 控制插件实例的监听地址与端口范围
+	Ports plugin_manager.PortPolicy `mapstructure:"ports"`
+	// ConfigService 控制提供给插件进程的只读配置接口
+	ConfigService PluginConfigServiceConfig `mapstructure:"config_service"`
+	// CrashDumps 控制插件实例反复崩溃时诊断包的收集
+	CrashDumps plugin_manager.CrashDumpPolicy `mapstructure:"crash_dumps"`
+}
+
+// PluginConfigServiceConfig 控制提供给插件进程的配置接口 (见 proto/config/v1/config.proto)。
+// 启用时插件通过该接口读取业务组配置，不再直接打开 auth.db
+type PluginConfigServiceConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Address 是接口的监听地址，端口为 0 时由系统分配；接口只以启动时生成的令牌认证，应只监听本机回环地址
+	Address string `mapstructure:"address"`
 }
 
 type ServerConfig struct {
 	Port     int    `mapstructure:"port"`
 	LogLevel string `mapstructure:"log_level"`
+	// LogFormat 是日志输出格式 (json / text)，默认为 json
+	LogFormat string `mapstructure:"log_format"`
+	// LogModules 是各模块 (router / plugin_manager / adapter) 的日志级别，未列出的模块使用 LogLevel
+	LogModules map[string]string `mapstructure:"log_modules"`
+}
+
+// QueryCacheConfig 定义网关侧读穿透查询缓存的配置
+type QueryCacheConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	TTL          time.Duration `mapstructure:"ttl"`
+	MaxEntries   int           `mapstructure:"max_entries"`
+	BypassTables []string      `mapstructure:"bypass_tables"`
+	// ServeStale 中的业务组在插件不可用时以缓存的旧结果回答相同的查询，"*" 表示所有业务组
+	ServeStale []string `mapstructure:"serve_stale"`
+	// StaleTTL 是旧结果可被使用的最长时间，默认为 24 小时
+	StaleTTL time.Duration `mapstructure:"stale_ttl"`
+}
+
+// QueryCoalescingConfig 定义相同并发查询的合并，默认启用
+type QueryCoalescingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// QueryLimitsConfig 定义网关对查询请求规模的限制，对所有插件生效
+type QueryLimitsConfig struct {
+	// MaxInValues 是过滤条件中 "in" 运算符取值列表的最大长度，不大于 0 时取默认值 1000
+	MaxInValues int `mapstructure:"max_in_values"`
+	// MaxResponseBytes 是单次检索结果的默认字节数上限，业务组可单独设置；不大于 0 时取默认值 8 MiB
+	MaxResponseBytes int64 `mapstructure:"max_response_bytes"`
+	// Cost 是检索代价检查的默认策略与扫描行数上限，业务组可单独设置
+	Cost router.QueryCostOptions `mapstructure:"cost"`
+	// AdaptivePaging 控制按客户端的下载速度调低检索结果的字节数上限
+	AdaptivePaging router.AdaptivePagingOptions `mapstructure:"adaptive_paging"`
 }
 
 type Config struct {
-	Server           ServerConfig           `mapstructure:"server"`
-	PluginManagement PluginManagementConfig `mapstructure:"plugin_management"`
+	Server           ServerConfig            `mapstructure:"server"`
+	PluginManagement PluginManagementConfig  `mapstructure:"plugin_management"`
+	OAIPMH           oaipmh.Options          `mapstructure:"oai_pmh"`
+	QueryCache       QueryCacheConfig        `mapstructure:"query_cache"`
+	QueryCoalescing  QueryCoalescingConfig   `mapstructure:"query_coalescing"`
+	Observability    aegobserve.ScrapeConfig `mapstructure:"observability"`
+	UsageAnalytics   analytics.Options       `mapstructure:"usage_analytics"`
+	Suggestions      suggest.Options         `mapstructure:"search_suggestions"`
+	SemanticSearch   semantic.Options        `mapstructure:"semantic_search"`
+	Catalog          catalog.Options         `mapstructure:"catalog"`
+	Preferences      preferences.Options     `mapstructure:"preferences"`
+	Bookmarks        bookmarks.Options       `mapstructure:"bookmarks"`
+	Annotations      annotations.Options     `mapstructure:"annotations"`
+	ShareLinks       sharelinks.Options      `mapstructure:"share_links"`
+	Embed            embed.Options           `mapstructure:"embed"`
+	Sitemap          sitemap.Options         `mapstructure:"sitemap"`
+	RequestSigning   signing.Options         `mapstructure:"request_signing"`
+	Approvals        approvals.Options       `mapstructure:"approvals"`
+	DailyDigest      digest.Options          `mapstructure:"daily_digest"`
+	UpdateCheck      updates.Options         `mapstructure:"update_check"`
+	Telemetry        telemetry.Options       `mapstructure:"telemetry"`
+	Penalties        penalties.Options       `mapstructure:"penalties"`
+	AbuseDetection   abuse.Options           `mapstructure:"abuse_detection"`
+	DataSubjects     datasubject.Options     `mapstructure:"data_subjects"`
+	PIIScan          piiscan.Options         `mapstructure:"pii_scan"`
+	Provenance       provenance.Options      `mapstructure:"provenance"`
+	Fixity           fixity.Options          `mapstructure:"fixity"`
+	RequestCapture   capture.Options         `mapstructure:"request_capture"`
+	FieldGuard       fieldguard.Options      `mapstructure:"response_field_guard"`
+	IdentifierPolicy identpolicy.Options     `mapstructure:"identifier_policy"`
+	QueryExtensions  queryext.Options        `mapstructure:"query_extensions"`
+	MockData         mockdata.Options        `mapstructure:"mock_data"`
+	SLO              slo.Options             `mapstructure:"slo"`
+	Maintenance      maintenance.Options     `mapstructure:"maintenance"`
+	Storage          storage.Options         `mapstructure:"storage"`
+	ColumnStats      colstats.Options        `mapstructure:"column_stats"`
+	QueryLimits      QueryLimitsConfig       `mapstructure:"query_limits"`
+	// Warmup 是插件实例注册后的预热设置
+	Warmup warmup.Options `mapstructure:"warmup"`
+	// Uploads 是分块上传的设置，上传的数据保存在根目录下的 uploads 目录
+	Uploads uploads.Options `mapstructure:"uploads"`
+	// Exports 是记录导出任务的设置，导出文件保存在根目录下的 exports 目录
+	Exports exports.Options `mapstructure:"exports"`
+	// Standby 启用后网关作为备用网关，定期从主网关复制 auth.db 并只读服务，可提升为主网关
+	Standby standby.Options `mapstructure:"standby"`
+	// RecordAccessLog 是敏感业务组记录访问日志的设置
+	RecordAccessLog accesslog.Options `mapstructure:"record_access_log"`
+	// Sessions 是登录会话的设置，启用后可限制每个用户同时登录的会话数并由管理员注销
+	Sessions sessions.Options `mapstructure:"sessions"`
+	// Vocabularies 是受控词表的设置
+	Vocabularies vocabulary.Options `mapstructure:"vocabularies"`
+	// ComplianceExport 是合规快照的设置，配置 dir 时定期写入该目录
+	ComplianceExport compliance.Options `mapstructure:"compliance_export"`
+	// SecurityEvents 是安全事件转发的设置，配置 syslog 或 http 接收端时启用
+	SecurityEvents siem.Options `mapstructure:"security_events"`
+	// AuthorizationHooks 是外部授权策略 (如 OPA) 的设置，配置 url 时启用
+	AuthorizationHooks policy.Options `mapstructure:"authorization_hooks"`
+	// ResultDiff 是结果比对 (/api/v1/data/diff) 的设置
+	ResultDiff resultdiff.Options `mapstructure:"result_diff"`
+
+	// LoadShedding 是数据平面的过载保护，在速率限制之前生效
+	LoadShedding aegmiddleware.LoadShedOptions `mapstructure:"load_shedding"`
+	// Compression 是 API 响应的压缩配置，默认启用
+	Compression router.CompressionOptions `mapstructure:"compression"`
+	// CachePolicy 是各类接口响应的 Cache-Control 取值
+	CachePolicy router.CachePolicyOptions `mapstructure:"cache_policy"`
 }
 
 // application 结构体作为我们应用的核心容器，持有所有依赖。
@@ -59,8 +261,78 @@ type application struct {
 	db                 *sql.DB
 	logger             *slog.Logger
 	pluginManager      *plugin_manager.PluginManager
+	oaiPMHService      *oaipmh.Service
+	iiifService        *iiif.Service
+	replicationService *replication.Service
+	replicaCheck       *replicacheck.Service
+	snapshotService    *snapshot.Service
+	virtualBizService  *virtualbiz.Service
+	shardedBizService  *sharding.Service
+	readSplitService   *readsplitbiz.Service
+	warmup             *warmup.Service
+	uploads            *uploads.Service
+	configTemplates    *configtemplates.Service
+	bizClone           *bizclone.Service
+	bizRename          *bizrename.Service
+	queryDictionaries  *querydict.Service
+	dataDictionary     *datadict.Service
+	vocabularies       *vocabulary.Service
+	recordLinks        *links.Service
+	accessLog          *accesslog.Service
+	compliance         *compliance.Service
+	securityEvents     *siem.Service
+	authorizationHooks *policy.Service
+	queryTemplates     *querytemplates.Service
+	resultDiff         *resultdiff.Service
+	jobService         *jobs.Service
+	fullTextService    *fulltext.Service
+	demoService        *demo.Service
+	diagnosticsService *diagnostics.Service
+	doctorService      *doctor.Service
+	catalogService     *catalog.Service
+	preferences        *preferences.Service
+	bookmarks          *bookmarks.Service
+	annotations        *annotations.Service
+	shareLinks         *sharelinks.Service
+	embeds             *embed.Service
+	usageAnalytics     *analytics.Service
+	searchSuggestions  *suggest.Service
+	semanticSearch     *semantic.Service
+	sitemaps           *sitemap.Service
+	requestSigning     *signing.Service
+	bizOwners          *ownership.Service
+	fieldImpact        *impact.Service
+	typegen            *typegen.Service
+	fieldGuard         *fieldguard.Service
+	identifierPolicy   *identpolicy.Service
+	queryExtensions    *queryext.Service
+	approvals          *approvals.Service
+	digests            *digest.Service
+	updates            *updates.Service
+	captures           *capture.Service
+	licenses           *licensing.Service
+	telemetry          *telemetry.Service
+	penalties          *penalties.Service
+	abuse              *abuse.Service
+	slos               *slo.Service
+	maintenance        *maintenance.Service
+	compaction         *compaction.Service
+	columnStats        *colstats.Service
+	storage            *storage.Service
+	dataSubjects       *datasubject.Service
+	exports            *exports.Service
+	standby            *standby.Service
+	sessions           *sessions.Service
+	boot               *bootreport.Recorder
+	piiScanner         *piiscan.Service
+	provenance         *provenance.Service
+	fixity             *fixity.Service
+	queryCache         *caching.Cache
 	adminConfigService port.QueryAdminConfigService
+	configEndpoint     *configrpc.Endpoint
 	rateLimiter        *aegmiddleware.BusinessRateLimiter
+	loadShedder        *aegmiddleware.LoadShedder
+	scrapeAllowlist    *aegobserve.Allowlist
 	dataSourceRegistry map[string]port.DataSource
 	closableAdapters   *[]io.Closer
 }
@@ -98,10 +370,18 @@ func main() {
 func build() (*application, error) {
 	// --- 命令行标志处理 ---
 	serviceTokenUser := flag.String("gen-service-token", "", "为指定的服务账户用户名生成一个长生命周期的Token并退出")
+	seedDemoBiz := flag.String("seed-demo", "", "生成指定名称的演示业务组（库文件、字段配置与视图）并退出")
+	seedDemoForce := flag.Bool("seed-demo-force", false, "与 -seed-demo 一起使用，覆盖已存在的演示库")
+	migrateLegacyDir := flag.String("migrate-legacy", "", "从旧版 (v0.2.x) 单体部署的 instance 目录迁移业务组（库文件、配置与插件实例），打印核对报告并退出")
+	migrateMode := flag.String("migrate-mode", plugin_manager.InstanceModeEmbedded, "与 -migrate-legacy 一起使用，新建插件实例的运行方式（embedded 或 process）")
+	migrateVersion := flag.String("migrate-version", "", "与 -migrate-legacy 一起使用，process 模式下 SQLite 插件的版本")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "与 -migrate-legacy 一起使用，只列出将要迁移的业务组，不做任何修改")
+	checkOnly := flag.Bool("check", false, "执行启动自检（配置、目录与库的读写权限、端口、插件仓库）并退出")
 	flag.Parse()
 
 	// --- 配置加载 ---
 	log.Printf("ArchiveAegis Universal Kernel %s 正在启动...", version)
+	boot := bootreport.New(version, time.Now())
 	exePath, err := os.Executable()
 	if err != nil {
 		return nil, fmt.Errorf("无法获取可执行文件路径: %w", err)
@@ -109,20 +389,67 @@ func build() (*application, error) {
 	rootDir := filepath.Dir(filepath.Dir(exePath))
 	configFilePath := filepath.Join(rootDir, "configs", "config.yaml")
 	viper.SetConfigFile(configFilePath)
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("读取配置文件 '%s' 失败: %w", configFilePath, err)
+	configDefaults := map[string]interface{}{
+		"observability.allowed_scrapers":           []string{"127.0.0.1", "::1"},
+		"plugin_management.shutdown_grace_period":  plugin_manager.DefaultShutdownGracePeriod,
+		"plugin_management.ports.bind_address":     "127.0.0.1",
+		"plugin_management.config_service.enabled": true,
+		"plugin_management.config_service.address": "127.0.0.1:0",
+		"plugin_management.crash_dumps.enabled":    true,
+		"compression.enabled":                      true,
+		"query_coalescing.enabled":                 true,
+		"warmup.enabled":                           true,
+	}
+	for key, value := range configDefaults {
+		viper.SetDefault(key, value)
 	}
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("解析配置到结构体失败: %w", err)
+	configErr := viper.ReadInConfig()
+	if configErr == nil {
+		configErr = viper.Unmarshal(&config)
+	}
+	var defaultedKeys []string
+	for key := range configDefaults {
+		if !viper.InConfig(key) {
+			defaultedKeys = append(defaultedKeys, key)
+		}
+	}
+	boot.SetConfig(configFilePath, configErr == nil, defaultedKeys)
+	if configErr != nil && !*checkOnly {
+		return nil, fmt.Errorf("加载配置文件 '%s' 失败: %w", configFilePath, configErr)
+	}
+	config.PluginManagement.InstallDirectory = filepath.Join(rootDir, config.PluginManagement.InstallDirectory)
+	for i, repo := range config.PluginManagement.Repositories {
+		if !strings.Contains(repo.URL, "://") {
+			absPath := filepath.Join(rootDir, repo.URL)
+			config.PluginManagement.Repositories[i].URL = "file://" + filepath.ToSlash(absPath)
+		}
+	}
+	instanceDir := filepath.Join(rootDir, "instance")
+	authDbPath := filepath.Join(instanceDir, "auth.db")
+	doctorOptions := doctor.Options{
+		RootDir:      rootDir,
+		ConfigPath:   configFilePath,
+		ConfigErr:    configErr,
+		InstanceDir:  instanceDir,
+		DBPath:       authDbPath,
+		InstallDir:   config.PluginManagement.InstallDirectory,
+		Repositories: config.PluginManagement.Repositories,
+		ListenAddrs: map[string]string{
+			"server":  fmt.Sprintf(":%d", config.Server.Port),
+			"metrics": config.Observability.MetricsAddress,
+		},
+	}
+
+	// 自检模式: 只报告问题，不初始化任何状态
+	if *checkOnly {
+		return nil, runDoctorAndExit(doctorOptions)
 	}
 
 	// --- 数据库和可观测性初始化 ---
-	instanceDir := filepath.Join(rootDir, "instance")
 	if _, err := os.Stat(instanceDir); os.IsNotExist(err) {
 		_ = os.MkdirAll(instanceDir, 0755)
 	}
-	authDbPath := filepath.Join(instanceDir, "auth.db")
 	sysDB, err := initAuthDB(authDbPath)
 	if err != nil {
 		return nil, err
@@ -142,9 +469,13 @@ func build() (*application, error) {
 	if err != nil {
 		return nil, err
 	}
+	boot.SetFeatures(enabledFeatures)
 
 	if enabledFeatures["io.archiveaegis.system.observability"] {
-		aegobserve.InitLogger(config.Server.LogLevel)
+		logSettings := aegobserve.LogSettings{Level: config.Server.LogLevel, Format: config.Server.LogFormat, Modules: config.Server.LogModules}
+		if err := aegobserve.InitLogger(logSettings); err != nil {
+			return nil, fmt.Errorf("日志配置无效: %w", err)
+		}
 	} else {
 		log.Println("ℹ️  高级可观测性功能未启用，使用标准日志。")
 	}
@@ -152,13 +483,6 @@ func build() (*application, error) {
 	slog.Info("ArchiveAegis Universal Kernel starting up", "version", version)
 
 	// --- 服务初始化 ---
-	config.PluginManagement.InstallDirectory = filepath.Join(rootDir, config.PluginManagement.InstallDirectory)
-	for i, repo := range config.PluginManagement.Repositories {
-		if !strings.Contains(repo.URL, "://") {
-			absPath := filepath.Join(rootDir, repo.URL)
-			config.PluginManagement.Repositories[i].URL = "file://" + filepath.ToSlash(absPath)
-		}
-	}
 
 	adminConfigService, err := admin_config.NewAdminConfigServiceImpl(sysDB, 1000, 5*time.Minute)
 	if err != nil {
@@ -171,15 +495,533 @@ func build() (*application, error) {
 	if err != nil {
 		return nil, err
 	}
+	pm.SetShutdownGracePeriod(config.PluginManagement.ShutdownGracePeriod)
+	pm.SetCrashDumpPolicy(config.PluginManagement.CrashDumps)
+	pm.SetConfigVersionSource(adminConfigService.ConfigVersion)
+	warmupService, err := warmup.NewService(adminConfigService, config.Warmup)
+	if err != nil {
+		return nil, fmt.Errorf("预热配置无效: %w", err)
+	}
+	if warmupService.Enabled() {
+		pm.SetRegistrationHook(warmupService.Warm)
+	}
+	var configEndpoint *configrpc.Endpoint
+	if config.PluginManagement.ConfigService.Enabled {
+		if configEndpoint, err = configrpc.Start(config.PluginManagement.ConfigService.Address, adminConfigService); err != nil {
+			return nil, err
+		}
+		pm.SetPluginEnv(configEndpoint.Env())
+	}
+	if err := pm.SetPortPolicy(config.PluginManagement.Ports); err != nil {
+		return nil, fmt.Errorf("插件端口配置无效: %w", err)
+	}
+	// SQLite 插件随网关一起编译，可按实例选择以嵌入式模式运行（无子进程、无 gRPC）
+	err = pm.RegisterEmbeddedPlugin(sqlitePluginID, func(bizName string) (port.DataSource, error) {
+		manager := sqlite.NewManager(adminConfigService)
+		if err := manager.InitForBiz(context.Background(), instanceDir, bizName); err != nil {
+			_ = manager.Close()
+			return nil, err
+		}
+		if err := manager.StartStorage(bizName); err != nil {
+			_ = manager.Close()
+			return nil, err
+		}
+		return manager, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if *migrateLegacyDir != "" {
+		migrateService, err := legacymigrate.NewService(sysDB, adminConfigService, pm, instanceDir)
+		if err != nil {
+			return nil, err
+		}
+		return nil, migrateLegacyAndExit(migrateService, legacymigrate.Options{
+			LegacyDir: *migrateLegacyDir,
+			PluginID:  sqlitePluginID,
+			Mode:      *migrateMode,
+			Version:   *migrateVersion,
+			DryRun:    *migrateDryRun,
+		})
+	}
+
+	// 装饰器按顺序由内向外包装数据源：库文件校验需要看到每一次写操作，放在缓存之内
+	var decorators []plugin_manager.DataSourceDecorator
+	var storageService *storage.Service
+	if config.Storage.Enabled {
+		storageService, err = storage.NewService(instanceDir, config.PluginManagement.InstallDirectory, config.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("空间统计配置无效: %w", err)
+		}
+		decorators = append(decorators, storageService.Wrap)
+		boot.Enable("storage", "min_free_mb", config.Storage.MinFreeMB, "backup_directories", config.Storage.BackupDirectories)
+	}
+	var fixityService *fixity.Service
+	if config.Fixity.Enabled {
+		fixityService, err = fixity.NewService(sysDB, instanceDir, config.Fixity)
+		if err != nil {
+			return nil, fmt.Errorf("库文件校验配置无效: %w", err)
+		}
+		decorators = append(decorators, fixityService.Wrap)
+		boot.Enable("fixity", "interval_minutes", config.Fixity.IntervalMinutes, "webhook", config.Fixity.WebhookURL != "")
+	}
+	// 维护服务统计实际到达数据源的请求，放在缓存之内
+	var maintenanceService *maintenance.Service
+	if config.Maintenance.Enabled {
+		maintenanceService, err = maintenance.NewService(sysDB, dataSourceRegistry, config.Maintenance)
+		if err != nil {
+			return nil, fmt.Errorf("库文件维护配置无效: %w", err)
+		}
+		decorators = append(decorators, maintenanceService.Wrap)
+		boot.Enable("maintenance", "interval_hours", config.Maintenance.IntervalHours, "fragmentation_threshold", config.Maintenance.FragmentationThreshold)
+	}
+
+	// 合并放在缓存之内: 缓存未命中或被绕过的相同查询也只有一个到达插件
+	if config.QueryCoalescing.Enabled {
+		decorators = append(decorators, coalescing.New().Wrap)
+		boot.Enable("query_coalescing")
+	}
+	var queryCache *caching.Cache
+	if config.QueryCache.Enabled {
+		queryCache = caching.New(caching.Options{
+			TTL:          config.QueryCache.TTL,
+			MaxEntries:   config.QueryCache.MaxEntries,
+			BypassTables: config.QueryCache.BypassTables,
+			ServeStale:   config.QueryCache.ServeStale,
+			StaleTTL:     config.QueryCache.StaleTTL,
+		})
+		decorators = append(decorators, queryCache.Wrap)
+		boot.Enable("query_cache", "ttl", config.QueryCache.TTL, "bypass_tables", config.QueryCache.BypassTables, "serve_stale", config.QueryCache.ServeStale)
+	}
+	// 模拟数据在最外层回答查询，不经过缓存与库文件校验
+	if config.MockData.Enabled {
+		mockData, err := mockdata.NewService(config.MockData)
+		if err != nil {
+			return nil, fmt.Errorf("模拟数据配置无效: %w", err)
+		}
+		decorators = append(decorators, mockData.Wrap)
+		boot.Enable("mock_data", "bizs", len(config.MockData.Bizs))
+		boot.Warn("模拟数据模式已启用，检索结果不是真实数据，仅供开发环境使用")
+	}
+	if len(decorators) > 0 {
+		pm.SetDataSourceDecorator(func(bizName string, ds port.DataSource) port.DataSource {
+			for _, decorate := range decorators {
+				ds = decorate(bizName, ds)
+			}
+			return ds
+		})
+	}
+
+	replicationService, err := replication.NewService(sysDB, dataSourceRegistry)
+	if err != nil {
+		return nil, err
+	}
+	replicaCheck, err := replicacheck.NewService(adminConfigService, dataSourceRegistry, replicationService)
+	if err != nil {
+		return nil, err
+	}
+
+	oaiPMHService, err := oaipmh.NewService(sysDB, dataSourceRegistry, config.OAIPMH)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualBizService, err := virtualbiz.NewService(sysDB, pm)
+	if err != nil {
+		return nil, err
+	}
+	if err := virtualBizService.LoadAll(context.Background()); err != nil {
+		return nil, err
+	}
+
+	shardedBizService, err := sharding.NewService(sysDB, pm)
+	if err != nil {
+		return nil, err
+	}
+	if err := shardedBizService.LoadAll(context.Background()); err != nil {
+		return nil, err
+	}
+
+	readSplitService, err := readsplitbiz.NewService(sysDB, pm)
+	if err != nil {
+		return nil, err
+	}
+	if err := readSplitService.LoadAll(context.Background()); err != nil {
+		return nil, err
+	}
+
+	configTemplates, err := configtemplates.NewService(sysDB, adminConfigService, dataSourceRegistry)
+	if err != nil {
+		return nil, err
+	}
+	bizClone, err := bizclone.NewService(adminConfigService, dataSourceRegistry)
+	if err != nil {
+		return nil, err
+	}
+	bizRename, err := bizrename.NewService(sysDB, adminConfigService, dataSourceRegistry, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	queryDictionaries, err := querydict.NewService(sysDB)
+	if err != nil {
+		return nil, err
+	}
+	if err := queryDictionaries.LoadAll(context.Background()); err != nil {
+		return nil, err
+	}
+	dataDictionary, err := datadict.NewService(sysDB, adminConfigService)
+	if err != nil {
+		return nil, err
+	}
+	vocabularies, err := vocabulary.NewService(sysDB, adminConfigService, config.Vocabularies)
+	if err != nil {
+		return nil, err
+	}
+	queryTemplates, err := querytemplates.NewService(sysDB, adminConfigService)
+	if err != nil {
+		return nil, err
+	}
+	resultDiff, err := resultdiff.NewService(dataSourceRegistry, config.ResultDiff)
+	if err != nil {
+		return nil, err
+	}
+	recordLinks, err := links.NewService(sysDB, dataSourceRegistry, adminConfigService)
+	if err != nil {
+		return nil, err
+	}
+	accessLogService, err := accesslog.NewService(sysDB, dataSourceRegistry, adminConfigService, config.RecordAccessLog)
+	if err != nil {
+		return nil, fmt.Errorf("记录访问日志配置无效: %w", err)
+	}
+	complianceService, err := compliance.NewService(sysDB, config.ComplianceExport)
+	if err != nil {
+		return nil, fmt.Errorf("合规导出配置无效: %w", err)
+	}
+	securityEvents, err := siem.NewService(sysDB, config.SecurityEvents)
+	if err != nil {
+		return nil, fmt.Errorf("安全事件转发配置无效: %w", err)
+	}
+	authorizationHooks, err := policy.NewService(config.AuthorizationHooks)
+	if err != nil {
+		return nil, fmt.Errorf("授权钩子配置无效: %w", err)
+	}
+	if config.AuthorizationHooks.URL != "" {
+		boot.Enable("authorization_hooks", "url", config.AuthorizationHooks.URL, "fail_open", config.AuthorizationHooks.FailOpen)
+	}
+
+	jobService, err := jobs.NewService(sysDB)
+	if err != nil {
+		return nil, err
+	}
+	fullTextService, err := fulltext.NewService(adminConfigService, dataSourceRegistry, jobService)
+	if err != nil {
+		return nil, err
+	}
+	compactionService, err := compaction.NewService(dataSourceRegistry, jobService)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotService, err := snapshot.NewService(sysDB, instanceDir, adminConfigService, pm)
+	if err != nil {
+		return nil, err
+	}
+	// 上传目录不能位于 instance 目录下，否则会被当作业务组目录监视
+	uploadService, err := uploads.NewService(sysDB, filepath.Join(rootDir, "uploads"), config.Uploads)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosticsService, err := diagnostics.NewService(sysDB)
+	if err != nil {
+		return nil, err
+	}
+
+	doctorService, err := doctor.NewService(doctorOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogService, err := catalog.NewService(adminConfigService, dataSourceRegistry, config.Catalog)
+	if err != nil {
+		return nil, fmt.Errorf("目录配置无效: %w", err)
+	}
+
+	preferenceService, err := preferences.NewService(sysDB, config.Preferences)
+	if err != nil {
+		return nil, fmt.Errorf("偏好设置配置无效: %w", err)
+	}
+
+	bookmarkService, err := bookmarks.NewService(sysDB, dataSourceRegistry, config.Bookmarks)
+	if err != nil {
+		return nil, fmt.Errorf("收藏配置无效: %w", err)
+	}
+
+	shareLinkService, err := sharelinks.NewService(sysDB, dataSourceRegistry, config.ShareLinks)
+	if err != nil {
+		return nil, fmt.Errorf("分享链接配置无效: %w", err)
+	}
+
+	embedService, err := embed.NewService(sysDB, dataSourceRegistry, config.Embed)
+	if err != nil {
+		return nil, fmt.Errorf("嵌入接口配置无效: %w", err)
+	}
+
+	demoService, err := demo.NewService(adminConfigService, instanceDir)
+	if err != nil {
+		return nil, err
+	}
+	if *seedDemoBiz != "" {
+		return nil, seedDemoAndExit(demoService, *seedDemoBiz, *seedDemoForce)
+	}
+
+	var usageAnalytics *analytics.Service
+	if config.UsageAnalytics.Enabled {
+		usageAnalytics, err = analytics.NewService(sysDB, config.UsageAnalytics)
+		if err != nil {
+			return nil, fmt.Errorf("检索统计配置无效: %w", err)
+		}
+		boot.Enable("usage_analytics", "term_sample_rate", config.UsageAnalytics.TermSampleRate, "store_terms", config.UsageAnalytics.StoreTerms)
+	}
+
+	var searchSuggestions *suggest.Service
+	if config.Suggestions.Enabled {
+		searchSuggestions, err = suggest.NewService(sysDB, adminConfigService, dataSourceRegistry, jobService, config.Suggestions)
+		if err != nil {
+			return nil, fmt.Errorf("拼写建议配置无效: %w", err)
+		}
+		if err := searchSuggestions.LoadAll(context.Background()); err != nil {
+			return nil, err
+		}
+		boot.Enable("search_suggestions", "result_threshold", config.Suggestions.ResultThreshold)
+	}
+
+	var semanticSearch *semantic.Service
+	if config.SemanticSearch.Enabled {
+		semanticSearch, err = semantic.NewService(sysDB, adminConfigService, dataSourceRegistry, jobService, config.SemanticSearch)
+		if err != nil {
+			return nil, fmt.Errorf("语义检索配置无效: %w", err)
+		}
+		boot.Enable("semantic_search", "model", semanticSearch.Model())
+	}
+
+	var annotationService *annotations.Service
+	if config.Annotations.Enabled {
+		annotationService, err = annotations.NewService(sysDB, adminConfigService, dataSourceRegistry, config.Annotations)
+		if err != nil {
+			return nil, fmt.Errorf("记录批注配置无效: %w", err)
+		}
+		boot.Enable("annotations", "auto_approve_admins", config.Annotations.AutoApproveAdmins)
+	}
+
+	var sitemapService *sitemap.Service
+	if config.Sitemap.Enabled {
+		sitemapService, err = sitemap.NewService(adminConfigService, dataSourceRegistry, config.Sitemap)
+		if err != nil {
+			return nil, fmt.Errorf("站点地图配置无效: %w", err)
+		}
+		boot.Enable("sitemap", "base_url", config.Sitemap.BaseURL)
+	}
+
+	var requestSigning *signing.Service
+	if config.RequestSigning.Enabled {
+		requestSigning, err = signing.NewService(sysDB, config.RequestSigning)
+		if err != nil {
+			return nil, fmt.Errorf("请求签名配置无效: %w", err)
+		}
+		boot.Enable("request_signing", "clock_skew_seconds", config.RequestSigning.ClockSkewSeconds)
+	}
+
+	bizOwners, err := ownership.NewService(sysDB)
+	if err != nil {
+		return nil, err
+	}
+	fieldImpact, err := impact.NewService(adminConfigService, shareLinkService, virtualBizService)
+	if err != nil {
+		return nil, err
+	}
+	typegenService, err := typegen.NewService(adminConfigService)
+	if err != nil {
+		return nil, err
+	}
+	columnStatsService, err := colstats.NewService(dataSourceRegistry, config.ColumnStats)
+	if err != nil {
+		return nil, fmt.Errorf("字段统计配置无效: %w", err)
+	}
+	fieldGuard, err := fieldguard.NewService(adminConfigService, config.FieldGuard)
+	if err != nil {
+		return nil, fmt.Errorf("字段白名单配置无效: %w", err)
+	}
+	boot.Enable("response_field_guard", "mode", fieldGuard.Mode())
+	// IIIF 清单与查询接口共用字段白名单与记录访问日志
+	iiifService, err := iiif.NewService(sysDB, dataSourceRegistry, fieldGuard, accessLogService)
+	if err != nil {
+		return nil, err
+	}
+	identifierPolicy, err := identpolicy.NewService(adminConfigService, config.IdentifierPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("标识符策略配置无效: %w", err)
+	}
+	adminConfigService.SetIdentifierChecker(identifierPolicy)
+	boot.Enable("identifier_policy", "mode", identifierPolicy.Mode())
+	queryExtensions, err := queryext.NewService(config.QueryExtensions)
+	if err != nil {
+		return nil, fmt.Errorf("查询扩展选项配置无效: %w", err)
+	}
+	if len(config.QueryExtensions.Allow) > 0 {
+		boot.Enable("query_extensions", "bizs", len(config.QueryExtensions.Allow))
+	}
+
+	var approvalService *approvals.Service
+	if config.Approvals.Enabled {
+		approvalService, err = approvals.NewService(sysDB, config.Approvals)
+		if err != nil {
+			return nil, fmt.Errorf("双人审批配置无效: %w", err)
+		}
+		boot.Enable("approvals", "expire_after_hours", config.Approvals.ExpireAfterHours)
+	}
+
+	var digestService *digest.Service
+	if config.DailyDigest.Enabled {
+		digestService, err = digest.NewService(sysDB, instanceDir, config.DailyDigest)
+		if err != nil {
+			return nil, fmt.Errorf("每日摘要配置无效: %w", err)
+		}
+		slog.Info("每日摘要已启用", "hour_utc", config.DailyDigest.Hour,
+			"webhook", config.DailyDigest.WebhookURL != "", "email", config.DailyDigest.Email.SMTPAddr != "")
+	}
+
+	var dataSubjectService *datasubject.Service
+	if config.DataSubjects.Enabled {
+		dataSubjectService, err = datasubject.NewService(sysDB, adminConfigService, dataSourceRegistry, config.DataSubjects)
+		if err != nil {
+			return nil, fmt.Errorf("数据主体请求配置无效: %w", err)
+		}
+		boot.Enable("data_subjects")
+	}
+
+	var exportService *exports.Service
+	if config.Exports.Enabled {
+		exportService, err = exports.NewService(adminConfigService, dataSourceRegistry, jobService, filepath.Join(rootDir, "exports"), config.Exports)
+		if err != nil {
+			return nil, fmt.Errorf("记录导出配置无效: %w", err)
+		}
+		boot.Enable("exports", "retention_hours", config.Exports.RetentionHours, "max_rows", config.Exports.MaxRows)
+	}
+
+	standbyService, err := standby.NewService(sysDB, instanceDir, config.Standby)
+	if err != nil {
+		return nil, fmt.Errorf("备用模式配置无效: %w", err)
+	}
+	if standbyService.IsStandby() {
+		boot.Enable("standby", "primary_url", config.Standby.PrimaryURL, "interval_seconds", config.Standby.IntervalSeconds)
+		boot.Warn("网关以备用模式运行，只接受读请求")
+	}
+
+	var piiScanService *piiscan.Service
+	if config.PIIScan.Enabled {
+		piiScanService, err = piiscan.NewService(sysDB, adminConfigService, dataSourceRegistry, config.PIIScan)
+		if err != nil {
+			return nil, fmt.Errorf("个人信息扫描配置无效: %w", err)
+		}
+		boot.Enable("pii_scan", "sample_size", config.PIIScan.SampleSize)
+	}
+
+	var provenanceService *provenance.Service
+	if config.Provenance.Enabled {
+		provenanceService, err = provenance.NewService(sysDB, config.Provenance)
+		if err != nil {
+			return nil, fmt.Errorf("来源信息配置无效: %w", err)
+		}
+		boot.Enable("provenance", "issuer", config.Provenance.Issuer)
+	}
+
+	var updateService *updates.Service
+	if config.UpdateCheck.Enabled {
+		updateService, err = updates.NewService(sysDB, pm, version, config.UpdateCheck)
+		if err != nil {
+			return nil, fmt.Errorf("更新检查配置无效: %w", err)
+		}
+		boot.Enable("update_check", "release_feed", config.UpdateCheck.ReleaseFeedURL != "")
+	}
+
+	captureService, err := capture.NewService(config.RequestCapture)
+	if err != nil {
+		return nil, fmt.Errorf("请求采集配置无效: %w", err)
+	}
+
+	// 需要授权的插件在启动前由授权服务离线校验
+	licenseService, err := licensing.NewService(sysDB, pm)
+	if err != nil {
+		return nil, fmt.Errorf("初始化插件授权服务失败: %w", err)
+	}
+	pm.SetLicenseChecker(licenseService.Entitlement)
+
+	telemetryService, err := telemetry.NewService(sysDB, version, config.Telemetry)
+	if err != nil {
+		return nil, fmt.Errorf("使用统计配置无效: %w", err)
+	}
+	if telemetryService.Enabled() {
+		boot.Enable("telemetry", "endpoint", config.Telemetry.Endpoint)
+	}
+
+	penaltyService, err := penalties.NewService(sysDB, config.Penalties)
+	if err != nil {
+		return nil, fmt.Errorf("登录锁定与封禁配置无效: %w", err)
+	}
+	if securityEvents.Enabled() {
+		penaltyService.SetLockHook(securityEvents.PenaltyLocked)
+	}
+
+	var sessionService *sessions.Service
+	if config.Sessions.Enabled {
+		sessionService, err = sessions.NewService(sysDB, config.Sessions)
+		if err != nil {
+			return nil, fmt.Errorf("登录会话配置无效: %w", err)
+		}
+		boot.Enable("sessions", "max_per_user", config.Sessions.MaxPerUser, "on_limit", config.Sessions.OnLimit)
+	}
+
+	var abuseService *abuse.Service
+	if config.AbuseDetection.Enabled {
+		abuseService, err = abuse.NewService(sysDB, penaltyService, config.AbuseDetection)
+		if err != nil {
+			return nil, fmt.Errorf("异常行为检测配置无效: %w", err)
+		}
+		boot.Enable("abuse_detection", "scrape_max_pages", config.AbuseDetection.ScrapeMaxPages, "forbidden_threshold", config.AbuseDetection.ForbiddenThreshold)
+	}
+
+	var sloService *slo.Service
+	if config.SLO.Enabled {
+		sloService, err = slo.NewService(sysDB, config.SLO)
+		if err != nil {
+			return nil, fmt.Errorf("服务等级目标配置无效: %w", err)
+		}
+		boot.Enable("slo", "webhook", config.SLO.WebhookURL != "")
+	}
 
 	rateLimiter := aegmiddleware.NewBusinessRateLimiter(adminConfigService, 10, 30)
+	rateLimiter.SetPenaltyBox(penaltyService)
+	loadShedder, err := aegmiddleware.NewLoadShedder(config.LoadShedding)
+	if err != nil {
+		return nil, fmt.Errorf("过载保护配置无效: %w", err)
+	}
+	if config.LoadShedding.Enabled {
+		boot.Enable("load_shedding", "max_in_flight", config.LoadShedding.MaxInFlight, "max_heap_mb", config.LoadShedding.MaxHeapMB)
+	}
 
 	// --- 按需启用监控 ---
-	if enabledFeatures["io.archiveaegis.system.observability"] {
-		aegobserve.EnablePprof("0.0.0.0:6060")
+	// 抓取端点要求来源在白名单内，并携带 -gen-service-token 生成的服务 Token。
+	// pprof 等诊断接口由管理后台的 /api/v1/admin/debug 提供，不再单独监听。
+	scrapeAllowlist, err := aegobserve.ParseAllowlist(config.Observability.AllowedScrapers)
+	if err != nil {
+		return nil, err
 	}
+	scrapeAuth := service.NewAuthenticator(sysDB).RequireServiceToken
 	aegobserve.Register()
-	slog.Info("监控: metrics 已注册。")
+	aegobserve.Serve("metrics", config.Observability.MetricsAddress, aegobserve.Guard(aegobserve.MetricsMux(), scrapeAllowlist, scrapeAuth))
+	boot.Enable("observability", "metrics_address", config.Observability.MetricsAddress)
 
 	// --- 组装 application 实例 ---
 	app := &application{
@@ -187,8 +1029,78 @@ func build() (*application, error) {
 		db:                 sysDB,
 		logger:             slog.Default(),
 		pluginManager:      pm,
+		oaiPMHService:      oaiPMHService,
+		iiifService:        iiifService,
+		replicationService: replicationService,
+		replicaCheck:       replicaCheck,
+		snapshotService:    snapshotService,
+		virtualBizService:  virtualBizService,
+		shardedBizService:  shardedBizService,
+		readSplitService:   readSplitService,
+		warmup:             warmupService,
+		uploads:            uploadService,
+		configTemplates:    configTemplates,
+		bizClone:           bizClone,
+		bizRename:          bizRename,
+		queryDictionaries:  queryDictionaries,
+		dataDictionary:     dataDictionary,
+		vocabularies:       vocabularies,
+		recordLinks:        recordLinks,
+		accessLog:          accessLogService,
+		compliance:         complianceService,
+		securityEvents:     securityEvents,
+		authorizationHooks: authorizationHooks,
+		queryTemplates:     queryTemplates,
+		resultDiff:         resultDiff,
+		jobService:         jobService,
+		fullTextService:    fullTextService,
+		demoService:        demoService,
+		diagnosticsService: diagnosticsService,
+		doctorService:      doctorService,
+		catalogService:     catalogService,
+		preferences:        preferenceService,
+		bookmarks:          bookmarkService,
+		annotations:        annotationService,
+		shareLinks:         shareLinkService,
+		embeds:             embedService,
+		usageAnalytics:     usageAnalytics,
+		searchSuggestions:  searchSuggestions,
+		semanticSearch:     semanticSearch,
+		sitemaps:           sitemapService,
+		requestSigning:     requestSigning,
+		bizOwners:          bizOwners,
+		fieldImpact:        fieldImpact,
+		typegen:            typegenService,
+		fieldGuard:         fieldGuard,
+		identifierPolicy:   identifierPolicy,
+		queryExtensions:    queryExtensions,
+		approvals:          approvalService,
+		digests:            digestService,
+		updates:            updateService,
+		captures:           captureService,
+		licenses:           licenseService,
+		telemetry:          telemetryService,
+		penalties:          penaltyService,
+		abuse:              abuseService,
+		slos:               sloService,
+		maintenance:        maintenanceService,
+		compaction:         compactionService,
+		columnStats:        columnStatsService,
+		storage:            storageService,
+		dataSubjects:       dataSubjectService,
+		exports:            exportService,
+		standby:            standbyService,
+		sessions:           sessionService,
+		boot:               boot,
+		piiScanner:         piiScanService,
+		provenance:         provenanceService,
+		fixity:             fixityService,
+		queryCache:         queryCache,
 		adminConfigService: adminConfigService,
+		configEndpoint:     configEndpoint,
 		rateLimiter:        rateLimiter,
+		loadShedder:        loadShedder,
+		scrapeAllowlist:    scrapeAllowlist,
 		dataSourceRegistry: dataSourceRegistry,
 		closableAdapters:   &closableAdapters,
 	}
@@ -196,6 +1108,33 @@ func build() (*application, error) {
 	return app, nil
 }
 
+// whenActive 在网关成为主网关后于新的 goroutine 中运行 fn。向外推送或发送的后台任务只应在主网关上运行，
+// 备用网关上运行会与主网关重复推送，且备用网关上的发送队列会被主网关的快照覆盖
+func (app *application) whenActive(ctx context.Context, fn func()) {
+	go func() {
+		select {
+		case <-app.standby.Active():
+			fn()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// finishBootReport 汇总插件实例与已注册的业务组，完成并记录启动报告
+func (app *application) finishBootReport(addr string, pluginFailures map[string]error) {
+	instances, err := app.pluginManager.ListInstances()
+	if err != nil {
+		app.boot.Warn("读取插件实例失败: %v", err)
+	}
+	app.boot.SetPlugins(instances, pluginFailures)
+	configured, err := app.adminConfigService.GetAllConfiguredBizNames(context.Background())
+	if err != nil {
+		app.boot.Warn("读取业务组配置失败: %v", err)
+	}
+	app.boot.SetBizs(app.pluginManager.RegisteredBizNames(), configured)
+	app.boot.Finish(addr)
+}
+
 // run 方法负责启动 HTTP 服务和处理优雅停机。
 func (app *application) run() error {
 	// 启动后台任务
@@ -207,7 +1146,117 @@ func (app *application) run() error {
 			app.pluginManager.RefreshRepositories()
 		}
 	}()
-	app.logger.Info("后台任务: 插件仓库定期刷新已启动。")
+	app.boot.Task("plugin_repository_refresh")
+
+	pluginFailures := app.pluginManager.StartEmbeddedInstances()
+
+	standbyCtx, stopStandby := context.WithCancel(context.Background())
+	defer stopStandby()
+	if app.standby.IsStandby() {
+		go app.standby.Run(standbyCtx)
+		app.boot.Task("standby_replication")
+	}
+
+	replicationCtx, stopReplication := context.WithCancel(context.Background())
+	defer stopReplication()
+	app.whenActive(replicationCtx, func() { app.replicationService.Run(replicationCtx, 30*time.Second) })
+	app.boot.Task("biz_replication")
+
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	defer stopAnalytics()
+	if app.usageAnalytics != nil {
+		go app.usageAnalytics.Run(analyticsCtx, time.Minute)
+		app.boot.Task("usage_analytics_flush")
+	}
+
+	digestCtx, stopDigest := context.WithCancel(context.Background())
+	defer stopDigest()
+	if app.digests != nil {
+		app.whenActive(digestCtx, func() { app.digests.Run(digestCtx, time.Minute) })
+		app.boot.Task("daily_digest")
+	}
+
+	complianceCtx, stopCompliance := context.WithCancel(context.Background())
+	defer stopCompliance()
+	if app.config.ComplianceExport.Dir != "" {
+		app.whenActive(complianceCtx, func() { app.compliance.Run(complianceCtx) })
+		app.boot.Task("compliance_export")
+	}
+
+	securityEventsCtx, stopSecurityEvents := context.WithCancel(context.Background())
+	defer stopSecurityEvents()
+	if app.securityEvents.Enabled() {
+		app.whenActive(securityEventsCtx, func() { app.securityEvents.Run(securityEventsCtx, 10*time.Second) })
+		app.boot.Task("security_events")
+	}
+
+	updateCtx, stopUpdates := context.WithCancel(context.Background())
+	defer stopUpdates()
+	if app.updates != nil {
+		go app.updates.Run(updateCtx)
+		app.boot.Task("update_check")
+	}
+
+	shedCtx, stopShedding := context.WithCancel(context.Background())
+	defer stopShedding()
+	go app.loadShedder.Run(shedCtx, time.Second)
+
+	penaltiesCtx, stopPenalties := context.WithCancel(context.Background())
+	defer stopPenalties()
+	go app.penalties.Run(penaltiesCtx, 30*time.Second)
+
+	accessLogCtx, stopAccessLog := context.WithCancel(context.Background())
+	defer stopAccessLog()
+	app.whenActive(accessLogCtx, func() { app.accessLog.Run(accessLogCtx) })
+	app.boot.Task("record_access_log_purge")
+
+	sessionsCtx, stopSessions := context.WithCancel(context.Background())
+	defer stopSessions()
+	if app.sessions != nil {
+		app.whenActive(sessionsCtx, func() { app.sessions.Run(sessionsCtx) })
+		app.boot.Task("session_sweep")
+	}
+
+	abuseCtx, stopAbuse := context.WithCancel(context.Background())
+	defer stopAbuse()
+	if app.abuse != nil {
+		go app.abuse.Run(abuseCtx, time.Minute)
+	}
+
+	sloCtx, stopSLO := context.WithCancel(context.Background())
+	defer stopSLO()
+	if app.slos != nil {
+		go app.slos.Run(sloCtx)
+	}
+
+	maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+	defer stopMaintenance()
+	if app.maintenance != nil {
+		go app.maintenance.Run(maintenanceCtx)
+	}
+
+	fixityCtx, stopFixity := context.WithCancel(context.Background())
+	defer stopFixity()
+	if app.fixity != nil {
+		go app.fixity.Run(fixityCtx)
+	}
+
+	uploadsCtx, stopUploads := context.WithCancel(context.Background())
+	defer stopUploads()
+	go app.uploads.Run(uploadsCtx)
+
+	exportsCtx, stopExports := context.WithCancel(context.Background())
+	defer stopExports()
+	if app.exports != nil {
+		go app.exports.Run(exportsCtx)
+	}
+
+	telemetryCtx, stopTelemetry := context.WithCancel(context.Background())
+	defer stopTelemetry()
+	if app.telemetry.Enabled() {
+		go app.telemetry.Run(telemetryCtx)
+		app.boot.Task("telemetry")
+	}
 
 	// 准备 Setup Token
 	var setupToken string
@@ -216,6 +1265,7 @@ func (app *application) run() error {
 		setupToken = genToken()
 		setupTokenDeadline = time.Now().Add(30 * time.Minute)
 		app.logger.Warn("系统中无管理员，安装令牌已生成 (30分钟内有效)", "setup_token", setupToken)
+		app.boot.Warn("系统中无管理员，请在 30 分钟内使用日志中的安装令牌完成初始化")
 	}
 
 	// 创建 HTTP 路由器
@@ -224,13 +1274,88 @@ func (app *application) run() error {
 			Registry:           app.dataSourceRegistry,
 			AdminConfigService: app.adminConfigService,
 			PluginManager:      app.pluginManager,
+			OAIPMHService:      app.oaiPMHService,
+			IIIFService:        app.iiifService,
+			ReplicationService: app.replicationService,
+			ReplicaCheck:       app.replicaCheck,
+			SnapshotService:    app.snapshotService,
+			VirtualBizService:  app.virtualBizService,
+			ShardedBizService:  app.shardedBizService,
+			ReadSplitService:   app.readSplitService,
+			Warmup:             app.warmup,
+			Uploads:            app.uploads,
+			ConfigTemplates:    app.configTemplates,
+			BizClone:           app.bizClone,
+			BizRename:          app.bizRename,
+			QueryDictionaries:  app.queryDictionaries,
+			DataDictionary:     app.dataDictionary,
+			Vocabularies:       app.vocabularies,
+			RecordLinks:        app.recordLinks,
+			AccessLog:          app.accessLog,
+			Compliance:         app.compliance,
+			SecurityEvents:     app.securityEvents,
+			AuthorizationHooks: app.authorizationHooks,
+			QueryTemplates:     app.queryTemplates,
+			ResultDiff:         app.resultDiff,
+			FullTextService:    app.fullTextService,
+			JobService:         app.jobService,
+			DemoService:        app.demoService,
+			DiagnosticsService: app.diagnosticsService,
+			DoctorService:      app.doctorService,
+			CatalogService:     app.catalogService,
+			Preferences:        app.preferences,
+			Bookmarks:          app.bookmarks,
+			Annotations:        app.annotations,
+			ShareLinks:         app.shareLinks,
+			Embeds:             app.embeds,
+			UsageAnalytics:     app.usageAnalytics,
+			SearchSuggestions:  app.searchSuggestions,
+			SemanticSearch:     app.semanticSearch,
+			Sitemaps:           app.sitemaps,
+			RequestSigning:     app.requestSigning,
+			BizOwners:          app.bizOwners,
+			FieldImpact:        app.fieldImpact,
+			Typegen:            app.typegen,
+			FieldGuard:         app.fieldGuard,
+			IdentifierPolicy:   app.identifierPolicy,
+			QueryExtensions:    app.queryExtensions,
+			MaxInValues:        app.config.QueryLimits.MaxInValues,
+			MaxResponseBytes:   app.config.QueryLimits.MaxResponseBytes,
+			QueryCost:          app.config.QueryLimits.Cost,
+			AdaptivePaging:     app.config.QueryLimits.AdaptivePaging,
+			Approvals:          app.approvals,
+			Digests:            app.digests,
+			Updates:            app.updates,
+			Captures:           app.captures,
+			Licenses:           app.licenses,
+			Telemetry:          app.telemetry,
+			Penalties:          app.penalties,
+			AbuseDetector:      app.abuse,
+			DataSubjects:       app.dataSubjects,
+			Exports:            app.exports,
+			Standby:            app.standby,
+			Sessions:           app.sessions,
+			BootReport:         app.boot,
+			PIIScanner:         app.piiScanner,
+			Provenance:         app.provenance,
+			Fixity:             app.fixity,
+			SLO:                app.slos,
+			Maintenance:        app.maintenance,
+			Compaction:         app.compaction,
+			ColumnStats:        app.columnStats,
+			Storage:            app.storage,
+			BuildInfo:          buildInfo(),
+			QueryCache:         app.queryCache,
 			RateLimiter:        app.rateLimiter,
+			LoadShedder:        app.loadShedder,
+			Compression:        app.config.Compression,
+			CachePolicy:        app.config.CachePolicy,
+			ScrapeAllowlist:    app.scrapeAllowlist,
 			AuthDB:             app.db,
 			SetupToken:         setupToken,
 			SetupTokenDeadline: setupTokenDeadline,
 		},
 	)
-	app.logger.Info("传输层: HTTP 路由器创建完成。")
 
 	// 创建并启动 HTTP 服务
 	addr := fmt.Sprintf(":%d", app.config.Server.Port)
@@ -250,6 +1375,37 @@ func (app *application) run() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		stopStandby()
+		stopReplication()
+
+		// 先停止接收 HTTP 请求，进行中的请求仍需要插件提供服务
+		err := server.Shutdown(ctx)
+
+		// 后台任务依赖插件提供的数据源，必须在停止插件前结束
+		if errJobs := app.jobService.Shutdown(ctx); errJobs != nil {
+			app.logger.Error("等待后台任务结束超时", "error", errJobs)
+		}
+
+		stopAnalytics()
+		if app.usageAnalytics != nil {
+			if err := app.usageAnalytics.Flush(context.Background()); err != nil {
+				app.logger.Error("写入检索统计时发生错误", "error", err)
+			}
+		}
+		stopSLO()
+		if app.slos != nil {
+			if err := app.slos.Flush(context.Background()); err != nil {
+				app.logger.Error("写入服务等级目标的请求统计时发生错误", "error", err)
+			}
+		}
+
+		app.logger.Info("正在停止所有插件实例...")
+		app.pluginManager.StopAll(plugin_manager.StopReasonGatewayShutdown)
+
+		if app.configEndpoint != nil {
+			app.configEndpoint.Stop()
+		}
+
 		app.logger.Info("正在关闭所有插件适配器...")
 		for _, closer := range *app.closableAdapters {
 			if err := closer.Close(); err != nil {
@@ -257,10 +1413,10 @@ func (app *application) run() error {
 			}
 		}
 
-		shutdownErr <- server.Shutdown(ctx)
+		shutdownErr <- err
 	}()
 
-	app.logger.Info("ArchiveAegis 内核启动成功，开始监听HTTP请求...", "address", addr)
+	app.finishBootReport(addr, pluginFailures)
 	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
@@ -306,29 +1462,110 @@ func generateServiceTokenAndExit(db *sql.DB, username string) error {
 	return nil // 实际上，os.Exit(0)会立刻终止程序
 }
 
-// loadEnabledFeatures 从数据库加载启用的功能列表
-func loadEnabledFeatures(db *sql.DB) (map[string]bool, error) {
-	rows, err := db.Query("SELECT feature_id FROM system_features WHERE enabled = TRUE")
+// seedDemoAndExit 生成演示业务组并打印结果
+func seedDemoAndExit(demoService *demo.Service, bizName string, force bool) error {
+	result, err := demoService.Seed(context.Background(), bizName, force)
 	if err != nil {
-		return nil, fmt.Errorf("查询启用的系统功能列表失败: %w", err)
+		return fmt.Errorf("生成演示业务组 '%s' 失败: %w", bizName, err)
 	}
-	defer rows.Close()
 
-	features := make(map[string]bool)
-	for rows.Next() {
-		var featureID string
-		if err := rows.Scan(&featureID); err != nil {
-			log.Printf("⚠️ 扫描启用的功能ID失败: %v", err)
+	fmt.Printf("\n演示业务组 '%s' 已生成: %s\n", result.BizName, result.DBPath)
+	for table, count := range result.Tables {
+		fmt.Printf("  - %s: %d 条记录\n", table, count)
+	}
+	fmt.Println("请在管理后台为该业务组创建并启动一个 SQLite 插件实例后即可查询。")
+
+	os.Exit(0)
+	return nil
+}
+
+// migrateLegacyAndExit 迁移旧版部署并打印核对报告，存在错误项时以非零状态退出
+func migrateLegacyAndExit(migrateService *legacymigrate.Service, opts legacymigrate.Options) error {
+	report, err := migrateService.Migrate(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("迁移旧版部署失败: %w", err)
+	}
+
+	fmt.Printf("\n旧版部署迁移: %s -> %s\n", report.LegacyDir, report.InstanceDir)
+	if report.InPlace {
+		fmt.Println("旧版目录即网关的 instance 目录，库文件与配置原地沿用。")
+	}
+	fmt.Println("------------------------------------------------------------------")
+	for _, biz := range report.Bizs {
+		if report.DryRun {
+			fmt.Printf("  - %s: %s\n", biz.BizName, strings.Join(biz.Libraries, ", "))
 			continue
 		}
-		features[featureID] = true
+		fmt.Printf("[%-7s] %-20s 实例 %s", strings.ToUpper(biz.Status), biz.BizName, biz.InstanceID)
+		if biz.InstanceCreated {
+			fmt.Print(" (新建)")
+		}
+		fmt.Printf("，%d 个库文件，%d 张表\n", len(biz.Libraries), biz.Tables)
+		for _, table := range slices.Sorted(maps.Keys(biz.ConfigRows)) {
+			fmt.Printf("          已复制配置 %s: %d 行\n", table, biz.ConfigRows[table])
+		}
+		for _, w := range biz.Warnings {
+			fmt.Printf("          警告: %s\n", w)
+		}
+		for _, p := range biz.Problems {
+			fmt.Printf("          错误: %s\n", p)
+		}
+	}
+	fmt.Println("------------------------------------------------------------------")
+	switch {
+	case report.DryRun:
+		fmt.Printf("共 %d 个业务组待迁移，去掉 -migrate-dry-run 后执行迁移。\n", len(report.Bizs))
+	case !report.OK:
+		fmt.Println("迁移存在错误，请根据上述信息修复后重新执行（已迁移的部分不会被覆盖）。")
+		os.Exit(1)
+	default:
+		fmt.Printf("迁移完成，共 %d 个业务组。\n", len(report.Bizs))
 	}
-	return features, rows.Err()
+
+	os.Exit(0)
+	return nil
+}
+
+// runDoctorAndExit 执行启动自检并打印结果，存在失败项时以非零状态退出
+func runDoctorAndExit(opts doctor.Options) error {
+	report := doctor.Run(context.Background(), opts)
+
+	fmt.Printf("\nArchiveAegis %s 启动自检\n", version)
+	fmt.Println("------------------------------------------------------------------")
+	for _, f := range report.Findings {
+		fmt.Printf("[%-4s] %-24s %s\n", strings.ToUpper(f.Status), f.Check, f.Message)
+		if f.Hint != "" {
+			fmt.Printf("       %-24s 建议: %s\n", "", f.Hint)
+		}
+	}
+	fmt.Println("------------------------------------------------------------------")
+	if !report.Healthy {
+		fmt.Println("自检未通过，请根据上述建议修复后重试。")
+		os.Exit(1)
+	}
+	fmt.Println("自检通过。")
+
+	os.Exit(0)
+	return nil
+}
+
+// loadEnabledFeatures 从数据库加载启用的功能列表
+func loadEnabledFeatures(db *sql.DB) (map[string]bool, error) {
+	ids, err := service.EnabledFeatures(db)
+	if err != nil {
+		return nil, err
+	}
+	features := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		features[id] = true
+	}
+	return features, nil
 }
 
 // initAuthDB 封装了认证数据库的初始化逻辑
 func initAuthDB(path string) (*sql.DB, error) {
-	dsn := fmt.Sprintf("file:%s?_busy_timeout=10000&_journal_mode=WAL&_foreign_keys=ON&_synchronous=NORMAL", path)
+	// modernc 驱动只识别 _pragma 参数。外键约束从未启用，嵌入式实例的 version 不在 installed_plugins 中，依赖这一点
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)", path)
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("打开/创建认证数据库 '%s' 失败: %w", path, err)
@@ -348,3 +1585,25 @@ func genToken() string {
 	}
 	return hex.EncodeToString(b)
 }
+
+// buildInfo 汇总版本与构建信息，未通过 -ldflags 注入时使用 Go 工具链记录的 VCS 信息
+func buildInfo() domain.BuildInfo {
+	info := domain.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,248 @@
+// file: cmd/plugins/rest_plugin/main.go
+package main
+
+import (
+	datasourcev1 "ArchiveAegis/gen/go/proto/datasource/v1"
+	"ArchiveAegis/internal/adapter/datasource/rest"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+//go:embed README.md
+var pluginDescription string
+
+const pluginVersion = "1.0.0"
+
+// drainTimeout 是优雅退出时等待进行中请求完成的最长时间，超时后强制关闭 gRPC 服务
+const drainTimeout = 5 * time.Second
+
+// server 结构体实现了 gRPC 生成的 DataSourceServer 接口。语义检索未实现，由生成代码返回 UNIMPLEMENTED；
+// 管理操作只支持查看与清空上游响应缓存 (port.MutateOpUpstreamCache)
+type server struct {
+	datasourcev1.UnimplementedDataSourceServer
+	manager    *rest.Manager
+	pluginName string
+	bizName    string
+	// shutdown 接收网关通过 Shutdown RPC 发来的停止原因，由 main 负责实际的清理与退出
+	shutdown chan string
+}
+
+// GetPluginInfo 方法实现
+func (s *server) GetPluginInfo(ctx context.Context, req *datasourcev1.GetPluginInfoRequest) (*datasourcev1.GetPluginInfoResponse, error) {
+	slog.Info("插件收到 GetPluginInfo 请求")
+	return &datasourcev1.GetPluginInfoResponse{
+		Name:                s.pluginName,
+		Version:             pluginVersion,
+		Type:                "rest_plugin",
+		SupportedBizNames:   []string{s.bizName},
+		DescriptionMarkdown: pluginDescription,
+	}, nil
+}
+
+// Query 将查询翻译为对上游接口的请求，上游不可用且没有可降级的响应时返回 UNAVAILABLE
+func (s *server) Query(ctx context.Context, req *datasourcev1.QueryRequest) (*datasourcev1.QueryResult, error) {
+	queryStruct := req.GetQuery()
+	if queryStruct == nil {
+		return nil, status.Error(codes.InvalidArgument, "查询体 (query) 不能为空")
+	}
+	goReq := port.QueryRequest{
+		BizName: req.BizName,
+		Query:   queryStruct.AsMap(),
+	}
+
+	slog.Info("插件收到 Query 请求", "biz", req.BizName)
+	result, err := s.manager.Query(ctx, goReq)
+	if errors.Is(err, rest.ErrUpstreamUnavailable) {
+		return nil, status.Errorf(codes.Unavailable, "查询数据失败: %v", err)
+	}
+	if err != nil {
+		slog.Error("插件执行 Query 失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "查询数据失败: %v", err)
+	}
+
+	resultData, err := structpb.NewStruct(result.Data)
+	if err != nil {
+		slog.Error("转换查询结果为 structpb.Struct 失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "序列化查询结果失败: %v", err)
+	}
+	return &datasourcev1.QueryResult{
+		Data:   resultData,
+		Source: result.Source,
+	}, nil
+}
+
+// Mutate 总是拒绝，上游接口只读
+func (s *server) Mutate(ctx context.Context, req *datasourcev1.MutateRequest) (*datasourcev1.MutateResult, error) {
+	_, err := s.manager.Mutate(ctx, port.MutateRequest{BizName: req.BizName, Operation: req.Operation})
+	return nil, status.Error(codes.PermissionDenied, err.Error())
+}
+
+// AdminMutate 执行管理操作，只支持查看与清空上游响应缓存，其余操作返回 UNIMPLEMENTED
+func (s *server) AdminMutate(ctx context.Context, req *datasourcev1.MutateRequest) (*datasourcev1.MutateResult, error) {
+	slog.Info("插件收到 AdminMutate 请求", "biz", req.BizName, "operation", req.Operation)
+	result, err := port.AdminMutate(ctx, s.manager, port.MutateRequest{
+		BizName:   req.BizName,
+		Operation: req.Operation,
+		Payload:   req.GetPayload().AsMap(),
+	})
+	if errors.Is(err, port.ErrAdminMutateUnsupported) {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+	if errors.Is(err, port.ErrBizNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "管理操作失败: %v", err)
+	}
+
+	resultData, err := structpb.NewStruct(result.Data)
+	if err != nil {
+		slog.Error("转换管理操作结果为 structpb.Struct 失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "序列化管理操作结果失败: %v", err)
+	}
+	return &datasourcev1.MutateResult{Data: resultData, Source: result.Source}, nil
+}
+
+func (s *server) GetSchema(ctx context.Context, req *datasourcev1.SchemaRequest) (*datasourcev1.SchemaResult, error) {
+	slog.Info("插件收到 GetSchema 请求", "biz", req.BizName)
+	result, err := s.manager.GetSchema(ctx, port.SchemaRequest{BizName: req.BizName, TableName: req.TableName})
+	if err != nil {
+		return nil, err
+	}
+
+	grpcTables := make(map[string]*datasourcev1.TableSchema)
+	for tableName, tableSchema := range result.Tables {
+		var grpcFields []*datasourcev1.FieldDescription
+		for _, field := range tableSchema {
+			grpcFields = append(grpcFields, &datasourcev1.FieldDescription{
+				Name:         field.Name,
+				DataType:     field.DataType,
+				IsSearchable: field.IsSearchable,
+				IsReturnable: field.IsReturnable,
+				IsPrimary:    field.IsPrimary,
+				Description:  field.Description,
+			})
+		}
+		grpcTables[tableName] = &datasourcev1.TableSchema{Fields: grpcFields, RecordKey: result.RecordKeys[tableName]}
+	}
+	return &datasourcev1.SchemaResult{Tables: grpcTables}, nil
+}
+
+func (s *server) HealthCheck(ctx context.Context, req *datasourcev1.HealthCheckRequest) (*datasourcev1.HealthCheckResponse, error) {
+	if err := s.manager.HealthCheck(ctx); err != nil {
+		slog.Warn("插件健康检查失败", "error", err)
+		return &datasourcev1.HealthCheckResponse{Status: datasourcev1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &datasourcev1.HealthCheckResponse{Status: datasourcev1.HealthCheckResponse_SERVING}, nil
+}
+
+// Shutdown 接受网关的关闭请求。响应先于清理返回，清理在 main 中进行，避免请求被自身的优雅退出阻塞。
+func (s *server) Shutdown(ctx context.Context, req *datasourcev1.ShutdownRequest) (*datasourcev1.ShutdownResponse, error) {
+	slog.Info("插件收到 Shutdown 请求", "reason", req.GetReason(), "grace_period_ms", req.GetGracePeriodMs())
+	select {
+	case s.shutdown <- req.GetReason():
+	default:
+		// 已有关闭流程在进行中
+	}
+	return &datasourcev1.ShutdownResponse{Accepted: true}, nil
+}
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})))
+
+	hostFlag := flag.String("host", "", "服务监听地址，为空时监听所有地址")
+	portFlag := flag.Int("port", 50051, "服务监听端口")
+	bizNameFlag := flag.String("biz", "", "此插件管理的业务组名称 (必须)")
+	pluginNameFlag := flag.String("name", "unnamed-rest-plugin", "此插件实例的唯一名称")
+	instanceDir := flag.String("instance_dir", "./instance", "实例目录的路径")
+	flag.Parse()
+
+	if *bizNameFlag == "" {
+		slog.Error("启动失败：必须通过 -biz 参数指定插件管理的业务组名称")
+		os.Exit(1)
+	}
+	slog.Info("🔌 插件启动中...", "name", *pluginNameFlag, "version", pluginVersion, "biz", *bizNameFlag, "host", *hostFlag, "port", *portFlag)
+
+	opts, err := rest.LoadOptions(filepath.Join(*instanceDir, *bizNameFlag))
+	if err != nil {
+		slog.Error("插件读取上游接口配置失败", "biz", *bizNameFlag, "error", err)
+		os.Exit(1)
+	}
+	manager, err := rest.NewManager(*bizNameFlag, opts)
+	if err != nil {
+		slog.Error("插件初始化业务失败", "biz", *bizNameFlag, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("成功加载上游接口配置", "biz", *bizNameFlag, "base_url", opts.BaseURL, "tables", len(opts.Tables),
+		"cache_disabled", opts.Cache.Disabled, "offline_fallback", opts.Cache.OfflineFallback)
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(*hostFlag, strconv.Itoa(*portFlag)))
+	if err != nil {
+		slog.Error("gRPC 服务监听端口失败", "port", *portFlag, "error", err)
+		os.Exit(1)
+	}
+
+	grpcServer := grpc.NewServer()
+	srv := &server{
+		manager:    manager,
+		pluginName: *pluginNameFlag,
+		bizName:    *bizNameFlag,
+		shutdown:   make(chan string, 1),
+	}
+	datasourcev1.RegisterDataSourceServer(grpcServer, srv)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
+	slog.Info("✅ REST插件启动成功，开始提供服务...")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serveErr:
+		slog.Error("gRPC 服务启动失败", "error", err)
+		os.Exit(1)
+	case reason := <-srv.shutdown:
+		slog.Info("网关请求插件退出，开始优雅关闭...", "reason", reason)
+	case sig := <-quit:
+		slog.Info("收到停机信号，开始优雅关闭...", "signal", sig.String())
+	}
+
+	gracefulStop(grpcServer)
+	stats := manager.CacheStats()
+	slog.Info("👋 插件已退出", "cache_entries", stats.Entries, "cache_hits", stats.Hits,
+		"cache_revalidated", stats.Revalidated, "cache_misses", stats.Misses, "cache_stale", stats.Stale)
+}
+
+// gracefulStop 等待进行中的请求完成，超过 drainTimeout 后强制关闭
+func gracefulStop(grpcServer *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		slog.Warn("等待进行中的请求超时，强制关闭 gRPC 服务")
+		grpcServer.Stop()
+	}
+}
@@ -3,22 +3,30 @@ package main
 
 import (
 	datasourcev1 "ArchiveAegis/gen/go/proto/datasource/v1"
+	"ArchiveAegis/internal/adapter/configrpc"
+	"ArchiveAegis/internal/adapter/datasource/grpc_client"
 	"ArchiveAegis/internal/adapter/datasource/sqlite"
 	"ArchiveAegis/internal/core/port"
 	"ArchiveAegis/internal/service/admin_config"
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	_ "modernc.org/sqlite"
@@ -29,12 +37,20 @@ var pluginDescription string
 
 const pluginVersion = "1.0.0"
 
+// configCacheTTL 是业务组配置的本地缓存时间，网关的配置版本变化时缓存会被提前丢弃
+const configCacheTTL = 1 * time.Minute
+
+// drainTimeout 是优雅退出时等待进行中请求完成的最长时间，超时后强制关闭 gRPC 服务
+const drainTimeout = 5 * time.Second
+
 // server 结构体实现了 gRPC 生成的 DataSourceServer 接口
 type server struct {
 	datasourcev1.UnimplementedDataSourceServer
 	manager    port.DataSource
 	pluginName string
 	bizName    string
+	// shutdown 接收网关通过 Shutdown RPC 发来的停止原因，由 main 负责实际的清理与退出
+	shutdown chan string
 }
 
 // GetPluginInfo 方法实现
@@ -61,6 +77,10 @@ func (s *server) Query(ctx context.Context, req *datasourcev1.QueryRequest) (*da
 		BizName: req.BizName,
 		Query:   queryStruct.AsMap(),
 	}
+	// SQLite 插件目前不识别任何扩展选项，转交后由 Manager 忽略
+	if ext := req.GetExtensions(); ext != nil {
+		goReq.Extensions = ext.AsMap()
+	}
 
 	slog.Info("插件收到 Query 请求", "biz", req.BizName)
 	result, err := s.manager.Query(ctx, goReq)
@@ -85,7 +105,18 @@ func (s *server) Query(ctx context.Context, req *datasourcev1.QueryRequest) (*da
 // Mutate 方法现在处理通用的 gRPC 请求
 func (s *server) Mutate(ctx context.Context, req *datasourcev1.MutateRequest) (*datasourcev1.MutateResult, error) {
 	slog.Info("插件收到 Mutate 请求", "biz", req.BizName, "operation", req.Operation)
+	return s.mutate(ctx, req, s.manager.Mutate)
+}
 
+// AdminMutate 执行管理操作 (同步变更、全文索引重建、库文件维护、压缩与整库替换)
+func (s *server) AdminMutate(ctx context.Context, req *datasourcev1.MutateRequest) (*datasourcev1.MutateResult, error) {
+	slog.Info("插件收到 AdminMutate 请求", "biz", req.BizName, "operation", req.Operation)
+	return s.mutate(ctx, req, func(ctx context.Context, goReq port.MutateRequest) (*port.MutateResult, error) {
+		return port.AdminMutate(ctx, s.manager, goReq)
+	})
+}
+
+func (s *server) mutate(ctx context.Context, req *datasourcev1.MutateRequest, call func(context.Context, port.MutateRequest) (*port.MutateResult, error)) (*datasourcev1.MutateResult, error) {
 	// 直接将收到的通用载荷对象传递给核心 port.MutateRequest
 	goReq := port.MutateRequest{
 		BizName:   req.BizName,
@@ -93,7 +124,10 @@ func (s *server) Mutate(ctx context.Context, req *datasourcev1.MutateRequest) (*
 		Payload:   req.GetPayload().AsMap(),
 	}
 
-	goResult, err := s.manager.Mutate(ctx, goReq)
+	goResult, err := call(ctx, goReq)
+	if errors.Is(err, port.ErrAdminMutateUnsupported) {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
 	if err != nil {
 		slog.Error("插件执行 Mutate 失败", "error", err)
 		return nil, status.Errorf(codes.Internal, "写操作失败: %v", err)
@@ -126,15 +160,17 @@ func (s *server) GetSchema(ctx context.Context, req *datasourcev1.SchemaRequest)
 		var grpcFields []*datasourcev1.FieldDescription
 		for _, field := range tableSchema {
 			grpcFields = append(grpcFields, &datasourcev1.FieldDescription{
-				Name:         field.Name,
-				DataType:     field.DataType,
-				IsSearchable: field.IsSearchable,
-				IsReturnable: field.IsReturnable,
-				IsPrimary:    field.IsPrimary,
-				Description:  field.Description,
+				Name:              field.Name,
+				DataType:          field.DataType,
+				IsSearchable:      field.IsSearchable,
+				IsReturnable:      field.IsReturnable,
+				IsPrimary:         field.IsPrimary,
+				Description:       field.Description,
+				PinyinSearchable:  field.PinyinSearchable,
+				FullTextTokenizer: field.FullTextTokenizer,
 			})
 		}
-		grpcTables[tableName] = &datasourcev1.TableSchema{Fields: grpcFields}
+		grpcTables[tableName] = &datasourcev1.TableSchema{Fields: grpcFields, RecordKey: result.RecordKeys[tableName]}
 	}
 
 	return &datasourcev1.SchemaResult{Tables: grpcTables}, nil
@@ -149,9 +185,131 @@ func (s *server) HealthCheck(ctx context.Context, req *datasourcev1.HealthCheckR
 	return &datasourcev1.HealthCheckResponse{Status: datasourcev1.HealthCheckResponse_SERVING}, nil
 }
 
+// Shutdown 接受网关的关闭请求。响应先于清理返回，清理在 main 中进行，避免请求被自身的优雅退出阻塞。
+func (s *server) Shutdown(ctx context.Context, req *datasourcev1.ShutdownRequest) (*datasourcev1.ShutdownResponse, error) {
+	slog.Info("插件收到 Shutdown 请求", "reason", req.GetReason(), "grace_period_ms", req.GetGracePeriodMs())
+	select {
+	case s.shutdown <- req.GetReason():
+	default:
+		// 已有关闭流程在进行中
+	}
+	return &datasourcev1.ShutdownResponse{Accepted: true}, nil
+}
+
+// vectorSearcher 返回数据源的语义检索扩展，数据源未实现时返回 UNIMPLEMENTED 与生成代码的默认行为一致
+func (s *server) vectorSearcher() (port.VectorSearcher, error) {
+	searcher, ok := s.manager.(port.VectorSearcher)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "数据源不支持语义检索")
+	}
+	return searcher, nil
+}
+
+// ScanEmbeddingSources 按游标分页返回待向量化的文本
+func (s *server) ScanEmbeddingSources(ctx context.Context, req *datasourcev1.ScanEmbeddingSourcesRequest) (*datasourcev1.ScanEmbeddingSourcesResponse, error) {
+	searcher, err := s.vectorSearcher()
+	if err != nil {
+		return nil, err
+	}
+	page, err := searcher.ScanEmbeddingSources(ctx, port.EmbeddingSourceRequest{
+		BizName:    req.GetBizName(),
+		TableName:  req.GetTableName(),
+		Fields:     req.GetFields(),
+		Lib:        req.GetLib(),
+		AfterRowID: req.GetAfterRowid(),
+		Limit:      int(req.GetLimit()),
+	})
+	if err != nil {
+		slog.Error("插件读取待向量化文本失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "读取待向量化文本失败: %v", err)
+	}
+	res := &datasourcev1.ScanEmbeddingSourcesResponse{NextLib: page.NextLib, NextRowid: page.NextRowID, Done: page.Done}
+	for _, src := range page.Sources {
+		res.Sources = append(res.Sources, &datasourcev1.EmbeddingSource{Lib: src.Lib, RecordId: src.RecordID, Text: src.Text})
+	}
+	return res, nil
+}
+
+// UpsertEmbeddings 保存网关计算的向量
+func (s *server) UpsertEmbeddings(ctx context.Context, req *datasourcev1.UpsertEmbeddingsRequest) (*datasourcev1.UpsertEmbeddingsResponse, error) {
+	searcher, err := s.vectorSearcher()
+	if err != nil {
+		return nil, err
+	}
+	embeddings := make([]port.Embedding, len(req.GetEmbeddings()))
+	for i, e := range req.GetEmbeddings() {
+		embeddings[i] = port.Embedding{Lib: e.GetLib(), RecordID: e.GetRecordId(), Vector: e.GetVector()}
+	}
+	result, err := searcher.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{
+		BizName:     req.GetBizName(),
+		TableName:   req.GetTableName(),
+		Model:       req.GetModel(),
+		Generation:  req.GetGeneration(),
+		Embeddings:  embeddings,
+		PruneBefore: req.GetPruneBefore(),
+		DropAll:     req.GetDropAll(),
+	})
+	if err != nil {
+		slog.Error("插件写入向量失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "写入向量失败: %v", err)
+	}
+	return &datasourcev1.UpsertEmbeddingsResponse{Upserted: result.Upserted, Deleted: result.Deleted}, nil
+}
+
+// SimilaritySearch 返回与查询向量最相似的记录
+func (s *server) SimilaritySearch(ctx context.Context, req *datasourcev1.SimilaritySearchRequest) (*datasourcev1.SimilaritySearchResponse, error) {
+	searcher, err := s.vectorSearcher()
+	if err != nil {
+		return nil, err
+	}
+	result, err := searcher.SimilaritySearch(ctx, port.SimilaritySearchRequest{
+		BizName:   req.GetBizName(),
+		TableName: req.GetTableName(),
+		Model:     req.GetModel(),
+		Vector:    req.GetVector(),
+		TopK:      int(req.GetTopK()),
+		MinScore:  req.GetMinScore(),
+	})
+	if err != nil {
+		slog.Error("插件执行相似度检索失败", "error", err)
+		return nil, status.Errorf(codes.Internal, "相似度检索失败: %v", err)
+	}
+	res := &datasourcev1.SimilaritySearchResponse{Source: result.Source}
+	for _, m := range result.Matches {
+		res.Matches = append(res.Matches, &datasourcev1.SimilarityMatch{Lib: m.Lib, RecordId: m.RecordID, Score: m.Score})
+	}
+	return res, nil
+}
+
+// configVersionInterceptor 比较请求携带的网关配置版本与上一次看到的版本，不同时说明管理员修改了配置，
+// 先使本进程的配置缓存失效再处理请求。未携带版本的请求 (如旧版网关) 仍依赖缓存过期
+func configVersionInterceptor(configService port.QueryAdminConfigService) grpc.UnaryServerInterceptor {
+	var (
+		mu       sync.Mutex
+		lastSeen string
+	)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(grpc_client.ConfigVersionMetadataKey); len(values) > 0 {
+				mu.Lock()
+				if values[0] != lastSeen {
+					if lastSeen != "" {
+						slog.Info("网关配置版本已变化，丢弃配置缓存", "version", values[0])
+					}
+					configService.InvalidateAllCaches()
+					lastSeen = values[0]
+				}
+				mu.Unlock()
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
 func main() {
 	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})))
 
+	hostFlag := flag.String("host", "", "服务监听地址，为空时监听所有地址")
 	portFlag := flag.Int("port", 50051, "服务监听端口")
 	bizNameFlag := flag.String("biz", "", "此插件管理的业务组名称 (必须)")
 	pluginNameFlag := flag.String("name", "unnamed-sqlite-plugin", "此插件实例的唯一名称")
@@ -162,50 +320,108 @@ func main() {
 		slog.Error("启动失败：必须通过 -biz 参数指定插件管理的业务组名称")
 		os.Exit(1)
 	}
-	slog.Info("🔌 插件启动中...", "name", *pluginNameFlag, "version", pluginVersion, "biz", *bizNameFlag, "port", *portFlag)
+	slog.Info("🔌 插件启动中...", "name", *pluginNameFlag, "version", pluginVersion, "biz", *bizNameFlag, "host", *hostFlag, "port", *portFlag)
 
 	slog.Info("正在初始化依赖...")
-	authDbPath := filepath.Join(*instanceDir, "auth.db")
-	pluginSysDB, err := initAuthDB(authDbPath)
+	adminConfigService, closeConfig, err := openConfigService(*instanceDir)
 	if err != nil {
-		slog.Error("插件无法初始化认证数据库连接", "error", err)
+		slog.Error("插件无法获取业务组配置", "error", err)
 		os.Exit(1)
 	}
-	defer pluginSysDB.Close()
-	slog.Info("成功连接到 auth.db")
-
-	adminConfigService, err := admin_config.NewAdminConfigServiceImpl(pluginSysDB, 100, 1*time.Minute)
-	if err != nil {
-		slog.Error("插件无法创建 AdminConfigService", "error", err)
-		os.Exit(1)
-	}
-	slog.Info("成功创建 AdminConfigService")
+	defer closeConfig()
 
 	sqliteManager := sqlite.NewManager(adminConfigService)
 	if err := sqliteManager.InitForBiz(context.Background(), *instanceDir, *bizNameFlag); err != nil {
 		slog.Error("插件初始化业务失败", "biz", *bizNameFlag, "error", err)
 		os.Exit(1)
 	}
+	if err := sqliteManager.StartStorage(*bizNameFlag); err != nil {
+		slog.Error("插件启动存储同步失败", "biz", *bizNameFlag, "error", err)
+		os.Exit(1)
+	}
 	slog.Info("成功初始化业务数据", "biz", *bizNameFlag)
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *portFlag))
+	lis, err := net.Listen("tcp", net.JoinHostPort(*hostFlag, strconv.Itoa(*portFlag)))
 	if err != nil {
 		slog.Error("gRPC 服务监听端口失败", "port", *portFlag, "error", err)
 		os.Exit(1)
 	}
 
-	grpcServer := grpc.NewServer()
-	datasourcev1.RegisterDataSourceServer(grpcServer, &server{
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(configVersionInterceptor(adminConfigService)))
+	srv := &server{
 		manager:    sqliteManager,
 		pluginName: *pluginNameFlag,
 		bizName:    *bizNameFlag,
-	})
+		shutdown:   make(chan string, 1),
+	}
+	datasourcev1.RegisterDataSourceServer(grpcServer, srv)
 
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
 	slog.Info("✅ SQLite插件启动成功，开始提供服务...")
-	if err := grpcServer.Serve(lis); err != nil {
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serveErr:
 		slog.Error("gRPC 服务启动失败", "error", err)
+		_ = sqliteManager.Close()
 		os.Exit(1)
+	case reason := <-srv.shutdown:
+		slog.Info("网关请求插件退出，开始优雅关闭...", "reason", reason)
+	case sig := <-quit:
+		slog.Info("收到停机信号，开始优雅关闭...", "signal", sig.String())
+	}
+
+	gracefulStop(grpcServer)
+	// 关闭连接时 SQLite 会完成 WAL 检查点，避免留下未合并的 -wal 文件
+	if err := sqliteManager.Close(); err != nil {
+		slog.Error("关闭业务数据库失败", "error", err)
+	}
+	slog.Info("👋 插件已退出")
+}
+
+// gracefulStop 等待进行中的请求完成，超过 drainTimeout 后强制关闭
+func gracefulStop(grpcServer *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		slog.Warn("等待进行中的请求超时，强制关闭 gRPC 服务")
+		grpcServer.Stop()
+	}
+}
+
+// openConfigService 优先通过网关的配置接口读取业务组配置 (见 configrpc)；
+// 由未提供配置接口的网关启动或手动运行时没有相应的环境变量，退回直接读取 auth.db
+func openConfigService(instanceDir string) (port.QueryAdminConfigService, func(), error) {
+	client, err := configrpc.DialFromEnv(os.Getenv, configCacheTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if client != nil {
+		slog.Info("通过网关配置接口读取业务组配置", "address", os.Getenv(configrpc.EnvAddress))
+		return client, func() { _ = client.Close() }, nil
 	}
+
+	pluginSysDB, err := initAuthDB(filepath.Join(instanceDir, "auth.db"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化认证数据库连接失败: %w", err)
+	}
+	adminConfigService, err := admin_config.NewAdminConfigServiceImpl(pluginSysDB, 100, configCacheTTL)
+	if err != nil {
+		_ = pluginSysDB.Close()
+		return nil, nil, err
+	}
+	slog.Info("成功连接到 auth.db")
+	return adminConfigService, func() { _ = pluginSysDB.Close() }, nil
 }
 
 func initAuthDB(path string) (*sql.DB, error) {
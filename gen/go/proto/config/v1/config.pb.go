@@ -0,0 +1,1028 @@
+// file: proto/config/v1/config.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v4.25.3
+// source: config/v1/config.proto
+
+package configv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetBizQueryConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BizName       string                 `protobuf:"bytes,1,opt,name=biz_name,json=bizName,proto3" json:"biz_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBizQueryConfigRequest) Reset() {
+	*x = GetBizQueryConfigRequest{}
+	mi := &file_config_v1_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBizQueryConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBizQueryConfigRequest) ProtoMessage() {}
+
+func (x *GetBizQueryConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBizQueryConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetBizQueryConfigRequest) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetBizQueryConfigRequest) GetBizName() string {
+	if x != nil {
+		return x.BizName
+	}
+	return ""
+}
+
+type GetBizQueryConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        *BizQueryConfig        `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBizQueryConfigResponse) Reset() {
+	*x = GetBizQueryConfigResponse{}
+	mi := &file_config_v1_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBizQueryConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBizQueryConfigResponse) ProtoMessage() {}
+
+func (x *GetBizQueryConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBizQueryConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetBizQueryConfigResponse) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBizQueryConfigResponse) GetConfig() *BizQueryConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type ListBizNamesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBizNamesRequest) Reset() {
+	*x = ListBizNamesRequest{}
+	mi := &file_config_v1_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBizNamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBizNamesRequest) ProtoMessage() {}
+
+func (x *ListBizNamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBizNamesRequest.ProtoReflect.Descriptor instead.
+func (*ListBizNamesRequest) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{2}
+}
+
+type ListBizNamesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BizNames      []string               `protobuf:"bytes,1,rep,name=biz_names,json=bizNames,proto3" json:"biz_names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBizNamesResponse) Reset() {
+	*x = ListBizNamesResponse{}
+	mi := &file_config_v1_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBizNamesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBizNamesResponse) ProtoMessage() {}
+
+func (x *ListBizNamesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBizNamesResponse.ProtoReflect.Descriptor instead.
+func (*ListBizNamesResponse) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBizNamesResponse) GetBizNames() []string {
+	if x != nil {
+		return x.BizNames
+	}
+	return nil
+}
+
+// BizQueryConfig 是业务组的查询配置
+type BizQueryConfig struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	BizName              string                 `protobuf:"bytes,1,opt,name=biz_name,json=bizName,proto3" json:"biz_name,omitempty"`
+	IsPubliclySearchable bool                   `protobuf:"varint,2,opt,name=is_publicly_searchable,json=isPubliclySearchable,proto3" json:"is_publicly_searchable,omitempty"`
+	DefaultQueryTable    string                 `protobuf:"bytes,3,opt,name=default_query_table,json=defaultQueryTable,proto3" json:"default_query_table,omitempty"`
+	ChangeCaptureEnabled bool                   `protobuf:"varint,4,opt,name=change_capture_enabled,json=changeCaptureEnabled,proto3" json:"change_capture_enabled,omitempty"`
+	CitationTemplate     string                 `protobuf:"bytes,5,opt,name=citation_template,json=citationTemplate,proto3" json:"citation_template,omitempty"`
+	Enabled              bool                   `protobuf:"varint,6,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	MaxResponseBytes     int64                  `protobuf:"varint,7,opt,name=max_response_bytes,json=maxResponseBytes,proto3" json:"max_response_bytes,omitempty"`
+	QueryCostPolicy      string                 `protobuf:"bytes,8,opt,name=query_cost_policy,json=queryCostPolicy,proto3" json:"query_cost_policy,omitempty"`
+	MaxScanRows          int64                  `protobuf:"varint,9,opt,name=max_scan_rows,json=maxScanRows,proto3" json:"max_scan_rows,omitempty"`
+	Timezone             string                 `protobuf:"bytes,10,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Locale               string                 `protobuf:"bytes,11,opt,name=locale,proto3" json:"locale,omitempty"`
+	Sensitive            bool                   `protobuf:"varint,12,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
+	// 键为表名
+	Tables        map[string]*TableConfig `protobuf:"bytes,13,rep,name=tables,proto3" json:"tables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BizQueryConfig) Reset() {
+	*x = BizQueryConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BizQueryConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BizQueryConfig) ProtoMessage() {}
+
+func (x *BizQueryConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BizQueryConfig.ProtoReflect.Descriptor instead.
+func (*BizQueryConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BizQueryConfig) GetBizName() string {
+	if x != nil {
+		return x.BizName
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetIsPubliclySearchable() bool {
+	if x != nil {
+		return x.IsPubliclySearchable
+	}
+	return false
+}
+
+func (x *BizQueryConfig) GetDefaultQueryTable() string {
+	if x != nil {
+		return x.DefaultQueryTable
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetChangeCaptureEnabled() bool {
+	if x != nil {
+		return x.ChangeCaptureEnabled
+	}
+	return false
+}
+
+func (x *BizQueryConfig) GetCitationTemplate() string {
+	if x != nil {
+		return x.CitationTemplate
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *BizQueryConfig) GetMaxResponseBytes() int64 {
+	if x != nil {
+		return x.MaxResponseBytes
+	}
+	return 0
+}
+
+func (x *BizQueryConfig) GetQueryCostPolicy() string {
+	if x != nil {
+		return x.QueryCostPolicy
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetMaxScanRows() int64 {
+	if x != nil {
+		return x.MaxScanRows
+	}
+	return 0
+}
+
+func (x *BizQueryConfig) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *BizQueryConfig) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+func (x *BizQueryConfig) GetTables() map[string]*TableConfig {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+// TableConfig 是单个表的查询和写操作配置，未配置的可选项不设置
+type TableConfig struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	TableName    string                 `protobuf:"bytes,1,opt,name=table_name,json=tableName,proto3" json:"table_name,omitempty"`
+	IsSearchable bool                   `protobuf:"varint,2,opt,name=is_searchable,json=isSearchable,proto3" json:"is_searchable,omitempty"`
+	// 键为字段名
+	Fields        map[string]*FieldSetting `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	AllowCreate   bool                     `protobuf:"varint,4,opt,name=allow_create,json=allowCreate,proto3" json:"allow_create,omitempty"`
+	AllowUpdate   bool                     `protobuf:"varint,5,opt,name=allow_update,json=allowUpdate,proto3" json:"allow_update,omitempty"`
+	AllowDelete   bool                     `protobuf:"varint,6,opt,name=allow_delete,json=allowDelete,proto3" json:"allow_delete,omitempty"`
+	Fts           *FTSConfig               `protobuf:"bytes,7,opt,name=fts,proto3" json:"fts,omitempty"`
+	Ranking       *RankingConfig           `protobuf:"bytes,8,opt,name=ranking,proto3" json:"ranking,omitempty"`
+	Hierarchy     *HierarchyConfig         `protobuf:"bytes,9,opt,name=hierarchy,proto3" json:"hierarchy,omitempty"`
+	SqlView       *SQLViewConfig           `protobuf:"bytes,10,opt,name=sql_view,json=sqlView,proto3" json:"sql_view,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TableConfig) Reset() {
+	*x = TableConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableConfig) ProtoMessage() {}
+
+func (x *TableConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableConfig.ProtoReflect.Descriptor instead.
+func (*TableConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TableConfig) GetTableName() string {
+	if x != nil {
+		return x.TableName
+	}
+	return ""
+}
+
+func (x *TableConfig) GetIsSearchable() bool {
+	if x != nil {
+		return x.IsSearchable
+	}
+	return false
+}
+
+func (x *TableConfig) GetFields() map[string]*FieldSetting {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *TableConfig) GetAllowCreate() bool {
+	if x != nil {
+		return x.AllowCreate
+	}
+	return false
+}
+
+func (x *TableConfig) GetAllowUpdate() bool {
+	if x != nil {
+		return x.AllowUpdate
+	}
+	return false
+}
+
+func (x *TableConfig) GetAllowDelete() bool {
+	if x != nil {
+		return x.AllowDelete
+	}
+	return false
+}
+
+func (x *TableConfig) GetFts() *FTSConfig {
+	if x != nil {
+		return x.Fts
+	}
+	return nil
+}
+
+func (x *TableConfig) GetRanking() *RankingConfig {
+	if x != nil {
+		return x.Ranking
+	}
+	return nil
+}
+
+func (x *TableConfig) GetHierarchy() *HierarchyConfig {
+	if x != nil {
+		return x.Hierarchy
+	}
+	return nil
+}
+
+func (x *TableConfig) GetSqlView() *SQLViewConfig {
+	if x != nil {
+		return x.SqlView
+	}
+	return nil
+}
+
+// FieldSetting 是单个字段的查询和返回配置
+type FieldSetting struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	FieldName         string                 `protobuf:"bytes,1,opt,name=field_name,json=fieldName,proto3" json:"field_name,omitempty"`
+	IsSearchable      bool                   `protobuf:"varint,2,opt,name=is_searchable,json=isSearchable,proto3" json:"is_searchable,omitempty"`
+	IsReturnable      bool                   `protobuf:"varint,3,opt,name=is_returnable,json=isReturnable,proto3" json:"is_returnable,omitempty"`
+	DataType          string                 `protobuf:"bytes,4,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`
+	DisplayName       string                 `protobuf:"bytes,5,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Pinyin            bool                   `protobuf:"varint,6,opt,name=pinyin,proto3" json:"pinyin,omitempty"`
+	CaseInsensitive   bool                   `protobuf:"varint,7,opt,name=case_insensitive,json=caseInsensitive,proto3" json:"case_insensitive,omitempty"`
+	AccentInsensitive bool                   `protobuf:"varint,8,opt,name=accent_insensitive,json=accentInsensitive,proto3" json:"accent_insensitive,omitempty"`
+	SearchWeight      float64                `protobuf:"fixed64,9,opt,name=search_weight,json=searchWeight,proto3" json:"search_weight,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FieldSetting) Reset() {
+	*x = FieldSetting{}
+	mi := &file_config_v1_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldSetting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldSetting) ProtoMessage() {}
+
+func (x *FieldSetting) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldSetting.ProtoReflect.Descriptor instead.
+func (*FieldSetting) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FieldSetting) GetFieldName() string {
+	if x != nil {
+		return x.FieldName
+	}
+	return ""
+}
+
+func (x *FieldSetting) GetIsSearchable() bool {
+	if x != nil {
+		return x.IsSearchable
+	}
+	return false
+}
+
+func (x *FieldSetting) GetIsReturnable() bool {
+	if x != nil {
+		return x.IsReturnable
+	}
+	return false
+}
+
+func (x *FieldSetting) GetDataType() string {
+	if x != nil {
+		return x.DataType
+	}
+	return ""
+}
+
+func (x *FieldSetting) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *FieldSetting) GetPinyin() bool {
+	if x != nil {
+		return x.Pinyin
+	}
+	return false
+}
+
+func (x *FieldSetting) GetCaseInsensitive() bool {
+	if x != nil {
+		return x.CaseInsensitive
+	}
+	return false
+}
+
+func (x *FieldSetting) GetAccentInsensitive() bool {
+	if x != nil {
+		return x.AccentInsensitive
+	}
+	return false
+}
+
+func (x *FieldSetting) GetSearchWeight() float64 {
+	if x != nil {
+		return x.SearchWeight
+	}
+	return 0
+}
+
+// FTSConfig 是表的全文检索配置
+type FTSConfig struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Fields           []string               `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	Tokenizer        string                 `protobuf:"bytes,2,opt,name=tokenizer,proto3" json:"tokenizer,omitempty"`
+	RemoveDiacritics bool                   `protobuf:"varint,3,opt,name=remove_diacritics,json=removeDiacritics,proto3" json:"remove_diacritics,omitempty"`
+	Stopwords        []string               `protobuf:"bytes,4,rep,name=stopwords,proto3" json:"stopwords,omitempty"`
+	Version          int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *FTSConfig) Reset() {
+	*x = FTSConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FTSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FTSConfig) ProtoMessage() {}
+
+func (x *FTSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FTSConfig.ProtoReflect.Descriptor instead.
+func (*FTSConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FTSConfig) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *FTSConfig) GetTokenizer() string {
+	if x != nil {
+		return x.Tokenizer
+	}
+	return ""
+}
+
+func (x *FTSConfig) GetRemoveDiacritics() bool {
+	if x != nil {
+		return x.RemoveDiacritics
+	}
+	return false
+}
+
+func (x *FTSConfig) GetStopwords() []string {
+	if x != nil {
+		return x.Stopwords
+	}
+	return nil
+}
+
+func (x *FTSConfig) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// RankingConfig 是表的相关度得分计算方式
+type RankingConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Expression    string                 `protobuf:"bytes,1,opt,name=expression,proto3" json:"expression,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RankingConfig) Reset() {
+	*x = RankingConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RankingConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RankingConfig) ProtoMessage() {}
+
+func (x *RankingConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RankingConfig.ProtoReflect.Descriptor instead.
+func (*RankingConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RankingConfig) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+// HierarchyConfig 是表中记录的层级关系
+type HierarchyConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyField      string                 `protobuf:"bytes,1,opt,name=key_field,json=keyField,proto3" json:"key_field,omitempty"`
+	ParentField   string                 `protobuf:"bytes,2,opt,name=parent_field,json=parentField,proto3" json:"parent_field,omitempty"`
+	SortField     string                 `protobuf:"bytes,3,opt,name=sort_field,json=sortField,proto3" json:"sort_field,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HierarchyConfig) Reset() {
+	*x = HierarchyConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HierarchyConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HierarchyConfig) ProtoMessage() {}
+
+func (x *HierarchyConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HierarchyConfig.ProtoReflect.Descriptor instead.
+func (*HierarchyConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *HierarchyConfig) GetKeyField() string {
+	if x != nil {
+		return x.KeyField
+	}
+	return ""
+}
+
+func (x *HierarchyConfig) GetParentField() string {
+	if x != nil {
+		return x.ParentField
+	}
+	return ""
+}
+
+func (x *HierarchyConfig) GetSortField() string {
+	if x != nil {
+		return x.SortField
+	}
+	return ""
+}
+
+// SQLViewConfig 是管理员登记的 SQL 视图表
+type SQLViewConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sql           string                 `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	Params        []*SQLViewParam        `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SQLViewConfig) Reset() {
+	*x = SQLViewConfig{}
+	mi := &file_config_v1_config_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SQLViewConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQLViewConfig) ProtoMessage() {}
+
+func (x *SQLViewConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQLViewConfig.ProtoReflect.Descriptor instead.
+func (*SQLViewConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SQLViewConfig) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *SQLViewConfig) GetParams() []*SQLViewParam {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *SQLViewConfig) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// SQLViewParam 是 SQL 视图表的一个参数，default_value 未设置表示没有默认值
+type SQLViewParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	DefaultValue  *structpb.Value        `protobuf:"bytes,3,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SQLViewParam) Reset() {
+	*x = SQLViewParam{}
+	mi := &file_config_v1_config_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SQLViewParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SQLViewParam) ProtoMessage() {}
+
+func (x *SQLViewParam) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_config_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SQLViewParam.ProtoReflect.Descriptor instead.
+func (*SQLViewParam) Descriptor() ([]byte, []int) {
+	return file_config_v1_config_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SQLViewParam) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SQLViewParam) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SQLViewParam) GetDefaultValue() *structpb.Value {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return nil
+}
+
+var File_config_v1_config_proto protoreflect.FileDescriptor
+
+const file_config_v1_config_proto_rawDesc = "" +
+	"\n" +
+	"\x16config/v1/config.proto\x12\tconfig.v1\x1a\x1cgoogle/protobuf/struct.proto\"5\n" +
+	"\x18GetBizQueryConfigRequest\x12\x19\n" +
+	"\bbiz_name\x18\x01 \x01(\tR\abizName\"N\n" +
+	"\x19GetBizQueryConfigResponse\x121\n" +
+	"\x06config\x18\x01 \x01(\v2\x19.config.v1.BizQueryConfigR\x06config\"\x15\n" +
+	"\x13ListBizNamesRequest\"3\n" +
+	"\x14ListBizNamesResponse\x12\x1b\n" +
+	"\tbiz_names\x18\x01 \x03(\tR\bbizNames\"\xf0\x04\n" +
+	"\x0eBizQueryConfig\x12\x19\n" +
+	"\bbiz_name\x18\x01 \x01(\tR\abizName\x124\n" +
+	"\x16is_publicly_searchable\x18\x02 \x01(\bR\x14isPubliclySearchable\x12.\n" +
+	"\x13default_query_table\x18\x03 \x01(\tR\x11defaultQueryTable\x124\n" +
+	"\x16change_capture_enabled\x18\x04 \x01(\bR\x14changeCaptureEnabled\x12+\n" +
+	"\x11citation_template\x18\x05 \x01(\tR\x10citationTemplate\x12\x18\n" +
+	"\aenabled\x18\x06 \x01(\bR\aenabled\x12,\n" +
+	"\x12max_response_bytes\x18\a \x01(\x03R\x10maxResponseBytes\x12*\n" +
+	"\x11query_cost_policy\x18\b \x01(\tR\x0fqueryCostPolicy\x12\"\n" +
+	"\rmax_scan_rows\x18\t \x01(\x03R\vmaxScanRows\x12\x1a\n" +
+	"\btimezone\x18\n" +
+	" \x01(\tR\btimezone\x12\x16\n" +
+	"\x06locale\x18\v \x01(\tR\x06locale\x12\x1c\n" +
+	"\tsensitive\x18\f \x01(\bR\tsensitive\x12=\n" +
+	"\x06tables\x18\r \x03(\v2%.config.v1.BizQueryConfig.TablesEntryR\x06tables\x1aQ\n" +
+	"\vTablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.config.v1.TableConfigR\x05value:\x028\x01\"\x95\x04\n" +
+	"\vTableConfig\x12\x1d\n" +
+	"\n" +
+	"table_name\x18\x01 \x01(\tR\ttableName\x12#\n" +
+	"\ris_searchable\x18\x02 \x01(\bR\fisSearchable\x12:\n" +
+	"\x06fields\x18\x03 \x03(\v2\".config.v1.TableConfig.FieldsEntryR\x06fields\x12!\n" +
+	"\fallow_create\x18\x04 \x01(\bR\vallowCreate\x12!\n" +
+	"\fallow_update\x18\x05 \x01(\bR\vallowUpdate\x12!\n" +
+	"\fallow_delete\x18\x06 \x01(\bR\vallowDelete\x12&\n" +
+	"\x03fts\x18\a \x01(\v2\x14.config.v1.FTSConfigR\x03fts\x122\n" +
+	"\aranking\x18\b \x01(\v2\x18.config.v1.RankingConfigR\aranking\x128\n" +
+	"\thierarchy\x18\t \x01(\v2\x1a.config.v1.HierarchyConfigR\thierarchy\x123\n" +
+	"\bsql_view\x18\n" +
+	" \x01(\v2\x18.config.v1.SQLViewConfigR\asqlView\x1aR\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.config.v1.FieldSettingR\x05value:\x028\x01\"\xce\x02\n" +
+	"\fFieldSetting\x12\x1d\n" +
+	"\n" +
+	"field_name\x18\x01 \x01(\tR\tfieldName\x12#\n" +
+	"\ris_searchable\x18\x02 \x01(\bR\fisSearchable\x12#\n" +
+	"\ris_returnable\x18\x03 \x01(\bR\fisReturnable\x12\x1b\n" +
+	"\tdata_type\x18\x04 \x01(\tR\bdataType\x12!\n" +
+	"\fdisplay_name\x18\x05 \x01(\tR\vdisplayName\x12\x16\n" +
+	"\x06pinyin\x18\x06 \x01(\bR\x06pinyin\x12)\n" +
+	"\x10case_insensitive\x18\a \x01(\bR\x0fcaseInsensitive\x12-\n" +
+	"\x12accent_insensitive\x18\b \x01(\bR\x11accentInsensitive\x12#\n" +
+	"\rsearch_weight\x18\t \x01(\x01R\fsearchWeight\"\xa6\x01\n" +
+	"\tFTSConfig\x12\x16\n" +
+	"\x06fields\x18\x01 \x03(\tR\x06fields\x12\x1c\n" +
+	"\ttokenizer\x18\x02 \x01(\tR\ttokenizer\x12+\n" +
+	"\x11remove_diacritics\x18\x03 \x01(\bR\x10removeDiacritics\x12\x1c\n" +
+	"\tstopwords\x18\x04 \x03(\tR\tstopwords\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\"/\n" +
+	"\rRankingConfig\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x01 \x01(\tR\n" +
+	"expression\"p\n" +
+	"\x0fHierarchyConfig\x12\x1b\n" +
+	"\tkey_field\x18\x01 \x01(\tR\bkeyField\x12!\n" +
+	"\fparent_field\x18\x02 \x01(\tR\vparentField\x12\x1d\n" +
+	"\n" +
+	"sort_field\x18\x03 \x01(\tR\tsortField\"t\n" +
+	"\rSQLViewConfig\x12\x10\n" +
+	"\x03sql\x18\x01 \x01(\tR\x03sql\x12/\n" +
+	"\x06params\x18\x02 \x03(\v2\x17.config.v1.SQLViewParamR\x06params\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\"s\n" +
+	"\fSQLViewParam\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12;\n" +
+	"\rdefault_value\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\fdefaultValue2\xc0\x01\n" +
+	"\rConfigService\x12^\n" +
+	"\x11GetBizQueryConfig\x12#.config.v1.GetBizQueryConfigRequest\x1a$.config.v1.GetBizQueryConfigResponse\x12O\n" +
+	"\fListBizNames\x12\x1e.config.v1.ListBizNamesRequest\x1a\x1f.config.v1.ListBizNamesResponseB\x1bZ\x19gen/go/config/v1;configv1b\x06proto3"
+
+var (
+	file_config_v1_config_proto_rawDescOnce sync.Once
+	file_config_v1_config_proto_rawDescData []byte
+)
+
+func file_config_v1_config_proto_rawDescGZIP() []byte {
+	file_config_v1_config_proto_rawDescOnce.Do(func() {
+		file_config_v1_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_config_v1_config_proto_rawDesc), len(file_config_v1_config_proto_rawDesc)))
+	})
+	return file_config_v1_config_proto_rawDescData
+}
+
+var file_config_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_config_v1_config_proto_goTypes = []any{
+	(*GetBizQueryConfigRequest)(nil),  // 0: config.v1.GetBizQueryConfigRequest
+	(*GetBizQueryConfigResponse)(nil), // 1: config.v1.GetBizQueryConfigResponse
+	(*ListBizNamesRequest)(nil),       // 2: config.v1.ListBizNamesRequest
+	(*ListBizNamesResponse)(nil),      // 3: config.v1.ListBizNamesResponse
+	(*BizQueryConfig)(nil),            // 4: config.v1.BizQueryConfig
+	(*TableConfig)(nil),               // 5: config.v1.TableConfig
+	(*FieldSetting)(nil),              // 6: config.v1.FieldSetting
+	(*FTSConfig)(nil),                 // 7: config.v1.FTSConfig
+	(*RankingConfig)(nil),             // 8: config.v1.RankingConfig
+	(*HierarchyConfig)(nil),           // 9: config.v1.HierarchyConfig
+	(*SQLViewConfig)(nil),             // 10: config.v1.SQLViewConfig
+	(*SQLViewParam)(nil),              // 11: config.v1.SQLViewParam
+	nil,                               // 12: config.v1.BizQueryConfig.TablesEntry
+	nil,                               // 13: config.v1.TableConfig.FieldsEntry
+	(*structpb.Value)(nil),            // 14: google.protobuf.Value
+}
+var file_config_v1_config_proto_depIdxs = []int32{
+	4,  // 0: config.v1.GetBizQueryConfigResponse.config:type_name -> config.v1.BizQueryConfig
+	12, // 1: config.v1.BizQueryConfig.tables:type_name -> config.v1.BizQueryConfig.TablesEntry
+	13, // 2: config.v1.TableConfig.fields:type_name -> config.v1.TableConfig.FieldsEntry
+	7,  // 3: config.v1.TableConfig.fts:type_name -> config.v1.FTSConfig
+	8,  // 4: config.v1.TableConfig.ranking:type_name -> config.v1.RankingConfig
+	9,  // 5: config.v1.TableConfig.hierarchy:type_name -> config.v1.HierarchyConfig
+	10, // 6: config.v1.TableConfig.sql_view:type_name -> config.v1.SQLViewConfig
+	11, // 7: config.v1.SQLViewConfig.params:type_name -> config.v1.SQLViewParam
+	14, // 8: config.v1.SQLViewParam.default_value:type_name -> google.protobuf.Value
+	5,  // 9: config.v1.BizQueryConfig.TablesEntry.value:type_name -> config.v1.TableConfig
+	6,  // 10: config.v1.TableConfig.FieldsEntry.value:type_name -> config.v1.FieldSetting
+	0,  // 11: config.v1.ConfigService.GetBizQueryConfig:input_type -> config.v1.GetBizQueryConfigRequest
+	2,  // 12: config.v1.ConfigService.ListBizNames:input_type -> config.v1.ListBizNamesRequest
+	1,  // 13: config.v1.ConfigService.GetBizQueryConfig:output_type -> config.v1.GetBizQueryConfigResponse
+	3,  // 14: config.v1.ConfigService.ListBizNames:output_type -> config.v1.ListBizNamesResponse
+	13, // [13:15] is the sub-list for method output_type
+	11, // [11:13] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_config_v1_config_proto_init() }
+func file_config_v1_config_proto_init() {
+	if File_config_v1_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_config_v1_config_proto_rawDesc), len(file_config_v1_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_config_v1_config_proto_goTypes,
+		DependencyIndexes: file_config_v1_config_proto_depIdxs,
+		MessageInfos:      file_config_v1_config_proto_msgTypes,
+	}.Build()
+	File_config_v1_config_proto = out.File
+	file_config_v1_config_proto_goTypes = nil
+	file_config_v1_config_proto_depIdxs = nil
+}
@@ -90,13 +90,20 @@ type QueryRequest struct {
 	//	  "size": 10
 	//	}
 	//
+	// 网关会先把请求中的嵌套 where 表达式编译为 filters 再下发。filters 元素中网关约定的可选键有
+	// "values" (候选值)、"fts" (全文检索) 与 "negate" (对条件取反)，插件应拒绝无法支持的 negate 条件。
+	//
 	// 示例 (对于一个Elasticsearch插件):
 	//
 	//	{
 	//	  "index": "products",
 	//	  "query": { "match": { "description": "durable laptop" } }
 	//	}
-	Query         *structpb.Struct `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Query *structpb.Struct `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// extensions 是插件专有的查询选项 (如搜索引擎的 query string、MongoDB 的 hint)，
+	// 键与值的含义由插件自行定义。网关只按业务组的白名单放行允许的键，不解释其内容；
+	// 不识别某个键的插件应忽略它。
+	Extensions    *structpb.Struct `protobuf:"bytes,3,opt,name=extensions,proto3" json:"extensions,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -145,6 +152,13 @@ func (x *QueryRequest) GetQuery() *structpb.Struct {
 	return nil
 }
 
+func (x *QueryRequest) GetExtensions() *structpb.Struct {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
 // QueryResult 代表一次查询的结果。
 type QueryResult struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -528,15 +542,17 @@ func (x *SchemaRequest) GetTableName() string {
 }
 
 type FieldDescription struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	DataType      string                 `protobuf:"bytes,2,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`              // 例如: "TEXT", "INTEGER", "TIMESTAMP", "NESTED"
-	IsSearchable  bool                   `protobuf:"varint,3,opt,name=is_searchable,json=isSearchable,proto3" json:"is_searchable,omitempty"` // 该字段是否可以作为查询条件
-	IsReturnable  bool                   `protobuf:"varint,4,opt,name=is_returnable,json=isReturnable,proto3" json:"is_returnable,omitempty"` // 该字段是否可以在结果中返回
-	IsPrimary     bool                   `protobuf:"varint,5,opt,name=is_primary,json=isPrimary,proto3" json:"is_primary,omitempty"`          // 是否是主键或唯一标识符
-	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`                        // 字段的描述信息
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DataType          string                 `protobuf:"bytes,2,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`                              // 例如: "TEXT", "INTEGER", "TIMESTAMP", "NESTED"
+	IsSearchable      bool                   `protobuf:"varint,3,opt,name=is_searchable,json=isSearchable,proto3" json:"is_searchable,omitempty"`                 // 该字段是否可以作为查询条件
+	IsReturnable      bool                   `protobuf:"varint,4,opt,name=is_returnable,json=isReturnable,proto3" json:"is_returnable,omitempty"`                 // 该字段是否可以在结果中返回
+	IsPrimary         bool                   `protobuf:"varint,5,opt,name=is_primary,json=isPrimary,proto3" json:"is_primary,omitempty"`                          // 是否是主键或唯一标识符
+	Description       string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`                                        // 字段的描述信息
+	PinyinSearchable  bool                   `protobuf:"varint,7,opt,name=pinyin_searchable,json=pinyinSearchable,proto3" json:"pinyin_searchable,omitempty"`     // 是否支持按拼音 (全拼或首字母) 检索该字段
+	FullTextTokenizer string                 `protobuf:"bytes,8,opt,name=full_text_tokenizer,json=fullTextTokenizer,proto3" json:"full_text_tokenizer,omitempty"` // 当前生效的全文索引所用分词器，为空表示不支持全文检索
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *FieldDescription) Reset() {
@@ -611,6 +627,20 @@ func (x *FieldDescription) GetDescription() string {
 	return ""
 }
 
+func (x *FieldDescription) GetPinyinSearchable() bool {
+	if x != nil {
+		return x.PinyinSearchable
+	}
+	return false
+}
+
+func (x *FieldDescription) GetFullTextTokenizer() string {
+	if x != nil {
+		return x.FullTextTokenizer
+	}
+	return ""
+}
+
 type SchemaResult struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
 	Tables        map[string]*TableSchema `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -658,6 +688,7 @@ func (x *SchemaResult) GetTables() map[string]*TableSchema {
 type TableSchema struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Fields        []*FieldDescription    `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	RecordKey     []string               `protobuf:"bytes,2,rep,name=record_key,json=recordKey,proto3" json:"record_key,omitempty"` // 记录的有效标识列：显式主键按声明顺序，无主键时为 rowid
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -699,6 +730,13 @@ func (x *TableSchema) GetFields() []*FieldDescription {
 	return nil
 }
 
+func (x *TableSchema) GetRecordKey() []string {
+	if x != nil {
+		return x.RecordKey
+	}
+	return nil
+}
+
 // --- HealthCheck 相关 (保持不变) ---
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -780,14 +818,738 @@ func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
 	return HealthCheckResponse_UNKNOWN
 }
 
+// --- Shutdown 相关 ---
+type ShutdownRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// reason 是网关停止插件的原因，例如 "admin_stop"、"health_check_failed"，供插件记录日志。
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// grace_period_ms 是网关等待插件进程退出的时长（毫秒），插件应在此时间内完成清理。
+	GracePeriodMs int64 `protobuf:"varint,2,opt,name=grace_period_ms,json=gracePeriodMs,proto3" json:"grace_period_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShutdownRequest) Reset() {
+	*x = ShutdownRequest{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShutdownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShutdownRequest) ProtoMessage() {}
+
+func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShutdownRequest.ProtoReflect.Descriptor instead.
+func (*ShutdownRequest) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ShutdownRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ShutdownRequest) GetGracePeriodMs() int64 {
+	if x != nil {
+		return x.GracePeriodMs
+	}
+	return 0
+}
+
+type ShutdownResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// accepted 表示插件已接受关闭请求，将在返回响应后开始清理并退出。
+	Accepted      bool `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShutdownResponse) Reset() {
+	*x = ShutdownResponse{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShutdownResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShutdownResponse) ProtoMessage() {}
+
+func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShutdownResponse.ProtoReflect.Descriptor instead.
+func (*ShutdownResponse) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ShutdownResponse) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+type ScanEmbeddingSourcesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BizName   string                 `protobuf:"bytes,1,opt,name=biz_name,json=bizName,proto3" json:"biz_name,omitempty"`
+	TableName string                 `protobuf:"bytes,2,opt,name=table_name,json=tableName,proto3" json:"table_name,omitempty"`
+	// fields 中各字段的非空值按顺序以换行拼接为一条文本。
+	Fields []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	// lib 与 after_rowid 是上一页返回的游标，首页均为空。
+	Lib           string `protobuf:"bytes,4,opt,name=lib,proto3" json:"lib,omitempty"`
+	AfterRowid    int64  `protobuf:"varint,5,opt,name=after_rowid,json=afterRowid,proto3" json:"after_rowid,omitempty"`
+	Limit         int32  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanEmbeddingSourcesRequest) Reset() {
+	*x = ScanEmbeddingSourcesRequest{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanEmbeddingSourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanEmbeddingSourcesRequest) ProtoMessage() {}
+
+func (x *ScanEmbeddingSourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanEmbeddingSourcesRequest.ProtoReflect.Descriptor instead.
+func (*ScanEmbeddingSourcesRequest) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetBizName() string {
+	if x != nil {
+		return x.BizName
+	}
+	return ""
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetTableName() string {
+	if x != nil {
+		return x.TableName
+	}
+	return ""
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetLib() string {
+	if x != nil {
+		return x.Lib
+	}
+	return ""
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetAfterRowid() int64 {
+	if x != nil {
+		return x.AfterRowid
+	}
+	return 0
+}
+
+func (x *ScanEmbeddingSourcesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type EmbeddingSource struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// lib 是记录所在的库，与 record_id 一起唯一标识一条记录。
+	Lib string `protobuf:"bytes,1,opt,name=lib,proto3" json:"lib,omitempty"`
+	// record_id 与 Query 的 record 模式使用的标识一致 (主键值或 rowid)。
+	RecordId      string `protobuf:"bytes,2,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Text          string `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbeddingSource) Reset() {
+	*x = EmbeddingSource{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbeddingSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbeddingSource) ProtoMessage() {}
+
+func (x *EmbeddingSource) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbeddingSource.ProtoReflect.Descriptor instead.
+func (*EmbeddingSource) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EmbeddingSource) GetLib() string {
+	if x != nil {
+		return x.Lib
+	}
+	return ""
+}
+
+func (x *EmbeddingSource) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *EmbeddingSource) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ScanEmbeddingSourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sources       []*EmbeddingSource     `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	NextLib       string                 `protobuf:"bytes,2,opt,name=next_lib,json=nextLib,proto3" json:"next_lib,omitempty"`
+	NextRowid     int64                  `protobuf:"varint,3,opt,name=next_rowid,json=nextRowid,proto3" json:"next_rowid,omitempty"`
+	Done          bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanEmbeddingSourcesResponse) Reset() {
+	*x = ScanEmbeddingSourcesResponse{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanEmbeddingSourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanEmbeddingSourcesResponse) ProtoMessage() {}
+
+func (x *ScanEmbeddingSourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanEmbeddingSourcesResponse.ProtoReflect.Descriptor instead.
+func (*ScanEmbeddingSourcesResponse) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ScanEmbeddingSourcesResponse) GetSources() []*EmbeddingSource {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ScanEmbeddingSourcesResponse) GetNextLib() string {
+	if x != nil {
+		return x.NextLib
+	}
+	return ""
+}
+
+func (x *ScanEmbeddingSourcesResponse) GetNextRowid() int64 {
+	if x != nil {
+		return x.NextRowid
+	}
+	return 0
+}
+
+func (x *ScanEmbeddingSourcesResponse) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+type Embedding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lib           string                 `protobuf:"bytes,1,opt,name=lib,proto3" json:"lib,omitempty"`
+	RecordId      string                 `protobuf:"bytes,2,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Vector        []float32              `protobuf:"fixed32,3,rep,packed,name=vector,proto3" json:"vector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Embedding) Reset() {
+	*x = Embedding{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Embedding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Embedding) ProtoMessage() {}
+
+func (x *Embedding) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Embedding.ProtoReflect.Descriptor instead.
+func (*Embedding) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Embedding) GetLib() string {
+	if x != nil {
+		return x.Lib
+	}
+	return ""
+}
+
+func (x *Embedding) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Embedding) GetVector() []float32 {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+type UpsertEmbeddingsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BizName   string                 `protobuf:"bytes,1,opt,name=biz_name,json=bizName,proto3" json:"biz_name,omitempty"`
+	TableName string                 `protobuf:"bytes,2,opt,name=table_name,json=tableName,proto3" json:"table_name,omitempty"`
+	// model 标识生成向量的模型，检索时只比较同一模型的向量。
+	Model string `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	// generation 标记本批向量所属的重建轮次。
+	Generation int64        `protobuf:"varint,4,opt,name=generation,proto3" json:"generation,omitempty"`
+	Embeddings []*Embedding `protobuf:"bytes,5,rep,name=embeddings,proto3" json:"embeddings,omitempty"`
+	// prune_before 大于 0 时，删除 generation 小于该值的向量 (重建结束时清理已删除记录的向量)。
+	PruneBefore int64 `protobuf:"varint,6,opt,name=prune_before,json=pruneBefore,proto3" json:"prune_before,omitempty"`
+	// drop_all 为 true 时删除表的全部向量，忽略其它字段。
+	DropAll       bool `protobuf:"varint,7,opt,name=drop_all,json=dropAll,proto3" json:"drop_all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertEmbeddingsRequest) Reset() {
+	*x = UpsertEmbeddingsRequest{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertEmbeddingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertEmbeddingsRequest) ProtoMessage() {}
+
+func (x *UpsertEmbeddingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertEmbeddingsRequest.ProtoReflect.Descriptor instead.
+func (*UpsertEmbeddingsRequest) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpsertEmbeddingsRequest) GetBizName() string {
+	if x != nil {
+		return x.BizName
+	}
+	return ""
+}
+
+func (x *UpsertEmbeddingsRequest) GetTableName() string {
+	if x != nil {
+		return x.TableName
+	}
+	return ""
+}
+
+func (x *UpsertEmbeddingsRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *UpsertEmbeddingsRequest) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *UpsertEmbeddingsRequest) GetEmbeddings() []*Embedding {
+	if x != nil {
+		return x.Embeddings
+	}
+	return nil
+}
+
+func (x *UpsertEmbeddingsRequest) GetPruneBefore() int64 {
+	if x != nil {
+		return x.PruneBefore
+	}
+	return 0
+}
+
+func (x *UpsertEmbeddingsRequest) GetDropAll() bool {
+	if x != nil {
+		return x.DropAll
+	}
+	return false
+}
+
+type UpsertEmbeddingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upserted      int64                  `protobuf:"varint,1,opt,name=upserted,proto3" json:"upserted,omitempty"`
+	Deleted       int64                  `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertEmbeddingsResponse) Reset() {
+	*x = UpsertEmbeddingsResponse{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertEmbeddingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertEmbeddingsResponse) ProtoMessage() {}
+
+func (x *UpsertEmbeddingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertEmbeddingsResponse.ProtoReflect.Descriptor instead.
+func (*UpsertEmbeddingsResponse) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpsertEmbeddingsResponse) GetUpserted() int64 {
+	if x != nil {
+		return x.Upserted
+	}
+	return 0
+}
+
+func (x *UpsertEmbeddingsResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type SimilaritySearchRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BizName   string                 `protobuf:"bytes,1,opt,name=biz_name,json=bizName,proto3" json:"biz_name,omitempty"`
+	TableName string                 `protobuf:"bytes,2,opt,name=table_name,json=tableName,proto3" json:"table_name,omitempty"`
+	Model     string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Vector    []float32              `protobuf:"fixed32,4,rep,packed,name=vector,proto3" json:"vector,omitempty"`
+	TopK      int32                  `protobuf:"varint,5,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	// min_score 是余弦相似度的下限，范围 [-1, 1]。
+	MinScore      float64 `protobuf:"fixed64,6,opt,name=min_score,json=minScore,proto3" json:"min_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilaritySearchRequest) Reset() {
+	*x = SimilaritySearchRequest{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilaritySearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilaritySearchRequest) ProtoMessage() {}
+
+func (x *SimilaritySearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilaritySearchRequest.ProtoReflect.Descriptor instead.
+func (*SimilaritySearchRequest) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SimilaritySearchRequest) GetBizName() string {
+	if x != nil {
+		return x.BizName
+	}
+	return ""
+}
+
+func (x *SimilaritySearchRequest) GetTableName() string {
+	if x != nil {
+		return x.TableName
+	}
+	return ""
+}
+
+func (x *SimilaritySearchRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SimilaritySearchRequest) GetVector() []float32 {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+func (x *SimilaritySearchRequest) GetTopK() int32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+func (x *SimilaritySearchRequest) GetMinScore() float64 {
+	if x != nil {
+		return x.MinScore
+	}
+	return 0
+}
+
+type SimilarityMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lib           string                 `protobuf:"bytes,1,opt,name=lib,proto3" json:"lib,omitempty"`
+	RecordId      string                 `protobuf:"bytes,2,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Score         float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarityMatch) Reset() {
+	*x = SimilarityMatch{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarityMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarityMatch) ProtoMessage() {}
+
+func (x *SimilarityMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarityMatch.ProtoReflect.Descriptor instead.
+func (*SimilarityMatch) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SimilarityMatch) GetLib() string {
+	if x != nil {
+		return x.Lib
+	}
+	return ""
+}
+
+func (x *SimilarityMatch) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *SimilarityMatch) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SimilaritySearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*SimilarityMatch     `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilaritySearchResponse) Reset() {
+	*x = SimilaritySearchResponse{}
+	mi := &file_datasource_v1_datasource_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilaritySearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilaritySearchResponse) ProtoMessage() {}
+
+func (x *SimilaritySearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasource_v1_datasource_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilaritySearchResponse.ProtoReflect.Descriptor instead.
+func (*SimilaritySearchResponse) Descriptor() ([]byte, []int) {
+	return file_datasource_v1_datasource_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SimilaritySearchResponse) GetMatches() []*SimilarityMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *SimilaritySearchResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
 var File_datasource_v1_datasource_proto protoreflect.FileDescriptor
 
 const file_datasource_v1_datasource_proto_rawDesc = "" +
 	"\n" +
-	"\x1edatasource/v1/datasource.proto\x12\rdatasource.v1\x1a\x1cgoogle/protobuf/struct.proto\"X\n" +
+	"\x1edatasource/v1/datasource.proto\x12\rdatasource.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x91\x01\n" +
 	"\fQueryRequest\x12\x19\n" +
 	"\bbiz_name\x18\x01 \x01(\tR\abizName\x12-\n" +
-	"\x05query\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x05query\"R\n" +
+	"\x05query\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x05query\x127\n" +
+	"\n" +
+	"extensions\x18\x03 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"extensions\"R\n" +
 	"\vQueryResult\x12+\n" +
 	"\x04data\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x04data\x12\x16\n" +
 	"\x06source\x18\x02 \x01(\tR\x06source\"{\n" +
@@ -808,7 +1570,7 @@ const file_datasource_v1_datasource_proto_rawDesc = "" +
 	"\rSchemaRequest\x12\x19\n" +
 	"\bbiz_name\x18\x01 \x01(\tR\abizName\x12\x1d\n" +
 	"\n" +
-	"table_name\x18\x02 \x01(\tR\ttableName\"\xce\x01\n" +
+	"table_name\x18\x02 \x01(\tR\ttableName\"\xab\x02\n" +
 	"\x10FieldDescription\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
 	"\tdata_type\x18\x02 \x01(\tR\bdataType\x12#\n" +
@@ -816,28 +1578,96 @@ const file_datasource_v1_datasource_proto_rawDesc = "" +
 	"\ris_returnable\x18\x04 \x01(\bR\fisReturnable\x12\x1d\n" +
 	"\n" +
 	"is_primary\x18\x05 \x01(\bR\tisPrimary\x12 \n" +
-	"\vdescription\x18\x06 \x01(\tR\vdescription\"\xa6\x01\n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12+\n" +
+	"\x11pinyin_searchable\x18\a \x01(\bR\x10pinyinSearchable\x12.\n" +
+	"\x13full_text_tokenizer\x18\b \x01(\tR\x11fullTextTokenizer\"\xa6\x01\n" +
 	"\fSchemaResult\x12?\n" +
 	"\x06tables\x18\x01 \x03(\v2'.datasource.v1.SchemaResult.TablesEntryR\x06tables\x1aU\n" +
 	"\vTablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
-	"\x05value\x18\x02 \x01(\v2\x1a.datasource.v1.TableSchemaR\x05value:\x028\x01\"F\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.datasource.v1.TableSchemaR\x05value:\x028\x01\"e\n" +
 	"\vTableSchema\x127\n" +
-	"\x06fields\x18\x01 \x03(\v2\x1f.datasource.v1.FieldDescriptionR\x06fields\"\x14\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1f.datasource.v1.FieldDescriptionR\x06fields\x12\x1d\n" +
+	"\n" +
+	"record_key\x18\x02 \x03(\tR\trecordKey\"\x14\n" +
 	"\x12HealthCheckRequest\"\x9b\x01\n" +
 	"\x13HealthCheckResponse\x12H\n" +
 	"\x06status\x18\x01 \x01(\x0e20.datasource.v1.HealthCheckResponse.ServingStatusR\x06status\":\n" +
 	"\rServingStatus\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x022\x8d\x03\n" +
+	"\vNOT_SERVING\x10\x02\"Q\n" +
+	"\x0fShutdownRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12&\n" +
+	"\x0fgrace_period_ms\x18\x02 \x01(\x03R\rgracePeriodMs\".\n" +
+	"\x10ShutdownResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\"\xb8\x01\n" +
+	"\x1bScanEmbeddingSourcesRequest\x12\x19\n" +
+	"\bbiz_name\x18\x01 \x01(\tR\abizName\x12\x1d\n" +
+	"\n" +
+	"table_name\x18\x02 \x01(\tR\ttableName\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\x12\x10\n" +
+	"\x03lib\x18\x04 \x01(\tR\x03lib\x12\x1f\n" +
+	"\vafter_rowid\x18\x05 \x01(\x03R\n" +
+	"afterRowid\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"T\n" +
+	"\x0fEmbeddingSource\x12\x10\n" +
+	"\x03lib\x18\x01 \x01(\tR\x03lib\x12\x1b\n" +
+	"\trecord_id\x18\x02 \x01(\tR\brecordId\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"\xa6\x01\n" +
+	"\x1cScanEmbeddingSourcesResponse\x128\n" +
+	"\asources\x18\x01 \x03(\v2\x1e.datasource.v1.EmbeddingSourceR\asources\x12\x19\n" +
+	"\bnext_lib\x18\x02 \x01(\tR\anextLib\x12\x1d\n" +
+	"\n" +
+	"next_rowid\x18\x03 \x01(\x03R\tnextRowid\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\"R\n" +
+	"\tEmbedding\x12\x10\n" +
+	"\x03lib\x18\x01 \x01(\tR\x03lib\x12\x1b\n" +
+	"\trecord_id\x18\x02 \x01(\tR\brecordId\x12\x16\n" +
+	"\x06vector\x18\x03 \x03(\x02R\x06vector\"\x81\x02\n" +
+	"\x17UpsertEmbeddingsRequest\x12\x19\n" +
+	"\bbiz_name\x18\x01 \x01(\tR\abizName\x12\x1d\n" +
+	"\n" +
+	"table_name\x18\x02 \x01(\tR\ttableName\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x04 \x01(\x03R\n" +
+	"generation\x128\n" +
+	"\n" +
+	"embeddings\x18\x05 \x03(\v2\x18.datasource.v1.EmbeddingR\n" +
+	"embeddings\x12!\n" +
+	"\fprune_before\x18\x06 \x01(\x03R\vpruneBefore\x12\x19\n" +
+	"\bdrop_all\x18\a \x01(\bR\adropAll\"P\n" +
+	"\x18UpsertEmbeddingsResponse\x12\x1a\n" +
+	"\bupserted\x18\x01 \x01(\x03R\bupserted\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\x03R\adeleted\"\xb3\x01\n" +
+	"\x17SimilaritySearchRequest\x12\x19\n" +
+	"\bbiz_name\x18\x01 \x01(\tR\abizName\x12\x1d\n" +
+	"\n" +
+	"table_name\x18\x02 \x01(\tR\ttableName\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x16\n" +
+	"\x06vector\x18\x04 \x03(\x02R\x06vector\x12\x13\n" +
+	"\x05top_k\x18\x05 \x01(\x05R\x04topK\x12\x1b\n" +
+	"\tmin_score\x18\x06 \x01(\x01R\bminScore\"V\n" +
+	"\x0fSimilarityMatch\x12\x10\n" +
+	"\x03lib\x18\x01 \x01(\tR\x03lib\x12\x1b\n" +
+	"\trecord_id\x18\x02 \x01(\tR\brecordId\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\"l\n" +
+	"\x18SimilaritySearchResponse\x128\n" +
+	"\amatches\x18\x01 \x03(\v2\x1e.datasource.v1.SimilarityMatchR\amatches\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source2\xdf\x06\n" +
 	"\n" +
 	"DataSource\x12Z\n" +
 	"\rGetPluginInfo\x12#.datasource.v1.GetPluginInfoRequest\x1a$.datasource.v1.GetPluginInfoResponse\x12@\n" +
 	"\x05Query\x12\x1b.datasource.v1.QueryRequest\x1a\x1a.datasource.v1.QueryResult\x12C\n" +
 	"\x06Mutate\x12\x1c.datasource.v1.MutateRequest\x1a\x1b.datasource.v1.MutateResult\x12F\n" +
 	"\tGetSchema\x12\x1c.datasource.v1.SchemaRequest\x1a\x1b.datasource.v1.SchemaResult\x12T\n" +
-	"\vHealthCheck\x12!.datasource.v1.HealthCheckRequest\x1a\".datasource.v1.HealthCheckResponseB#Z!gen/go/datasource/v1;datasourcev1b\x06proto3"
+	"\vHealthCheck\x12!.datasource.v1.HealthCheckRequest\x1a\".datasource.v1.HealthCheckResponse\x12K\n" +
+	"\bShutdown\x12\x1e.datasource.v1.ShutdownRequest\x1a\x1f.datasource.v1.ShutdownResponse\x12o\n" +
+	"\x14ScanEmbeddingSources\x12*.datasource.v1.ScanEmbeddingSourcesRequest\x1a+.datasource.v1.ScanEmbeddingSourcesResponse\x12c\n" +
+	"\x10UpsertEmbeddings\x12&.datasource.v1.UpsertEmbeddingsRequest\x1a'.datasource.v1.UpsertEmbeddingsResponse\x12c\n" +
+	"\x10SimilaritySearch\x12&.datasource.v1.SimilaritySearchRequest\x1a'.datasource.v1.SimilaritySearchResponse\x12H\n" +
+	"\vAdminMutate\x12\x1c.datasource.v1.MutateRequest\x1a\x1b.datasource.v1.MutateResultB#Z!gen/go/datasource/v1;datasourcev1b\x06proto3"
 
 var (
 	file_datasource_v1_datasource_proto_rawDescOnce sync.Once
@@ -852,7 +1682,7 @@ func file_datasource_v1_datasource_proto_rawDescGZIP() []byte {
 }
 
 var file_datasource_v1_datasource_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_datasource_v1_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_datasource_v1_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_datasource_v1_datasource_proto_goTypes = []any{
 	(HealthCheckResponse_ServingStatus)(0), // 0: datasource.v1.HealthCheckResponse.ServingStatus
 	(*QueryRequest)(nil),                   // 1: datasource.v1.QueryRequest
@@ -867,33 +1697,58 @@ var file_datasource_v1_datasource_proto_goTypes = []any{
 	(*TableSchema)(nil),                    // 10: datasource.v1.TableSchema
 	(*HealthCheckRequest)(nil),             // 11: datasource.v1.HealthCheckRequest
 	(*HealthCheckResponse)(nil),            // 12: datasource.v1.HealthCheckResponse
-	nil,                                    // 13: datasource.v1.SchemaResult.TablesEntry
-	(*structpb.Struct)(nil),                // 14: google.protobuf.Struct
+	(*ShutdownRequest)(nil),                // 13: datasource.v1.ShutdownRequest
+	(*ShutdownResponse)(nil),               // 14: datasource.v1.ShutdownResponse
+	(*ScanEmbeddingSourcesRequest)(nil),    // 15: datasource.v1.ScanEmbeddingSourcesRequest
+	(*EmbeddingSource)(nil),                // 16: datasource.v1.EmbeddingSource
+	(*ScanEmbeddingSourcesResponse)(nil),   // 17: datasource.v1.ScanEmbeddingSourcesResponse
+	(*Embedding)(nil),                      // 18: datasource.v1.Embedding
+	(*UpsertEmbeddingsRequest)(nil),        // 19: datasource.v1.UpsertEmbeddingsRequest
+	(*UpsertEmbeddingsResponse)(nil),       // 20: datasource.v1.UpsertEmbeddingsResponse
+	(*SimilaritySearchRequest)(nil),        // 21: datasource.v1.SimilaritySearchRequest
+	(*SimilarityMatch)(nil),                // 22: datasource.v1.SimilarityMatch
+	(*SimilaritySearchResponse)(nil),       // 23: datasource.v1.SimilaritySearchResponse
+	nil,                                    // 24: datasource.v1.SchemaResult.TablesEntry
+	(*structpb.Struct)(nil),                // 25: google.protobuf.Struct
 }
 var file_datasource_v1_datasource_proto_depIdxs = []int32{
-	14, // 0: datasource.v1.QueryRequest.query:type_name -> google.protobuf.Struct
-	14, // 1: datasource.v1.QueryResult.data:type_name -> google.protobuf.Struct
-	14, // 2: datasource.v1.MutateRequest.payload:type_name -> google.protobuf.Struct
-	14, // 3: datasource.v1.MutateResult.data:type_name -> google.protobuf.Struct
-	13, // 4: datasource.v1.SchemaResult.tables:type_name -> datasource.v1.SchemaResult.TablesEntry
-	8,  // 5: datasource.v1.TableSchema.fields:type_name -> datasource.v1.FieldDescription
-	0,  // 6: datasource.v1.HealthCheckResponse.status:type_name -> datasource.v1.HealthCheckResponse.ServingStatus
-	10, // 7: datasource.v1.SchemaResult.TablesEntry.value:type_name -> datasource.v1.TableSchema
-	5,  // 8: datasource.v1.DataSource.GetPluginInfo:input_type -> datasource.v1.GetPluginInfoRequest
-	1,  // 9: datasource.v1.DataSource.Query:input_type -> datasource.v1.QueryRequest
-	3,  // 10: datasource.v1.DataSource.Mutate:input_type -> datasource.v1.MutateRequest
-	7,  // 11: datasource.v1.DataSource.GetSchema:input_type -> datasource.v1.SchemaRequest
-	11, // 12: datasource.v1.DataSource.HealthCheck:input_type -> datasource.v1.HealthCheckRequest
-	6,  // 13: datasource.v1.DataSource.GetPluginInfo:output_type -> datasource.v1.GetPluginInfoResponse
-	2,  // 14: datasource.v1.DataSource.Query:output_type -> datasource.v1.QueryResult
-	4,  // 15: datasource.v1.DataSource.Mutate:output_type -> datasource.v1.MutateResult
-	9,  // 16: datasource.v1.DataSource.GetSchema:output_type -> datasource.v1.SchemaResult
-	12, // 17: datasource.v1.DataSource.HealthCheck:output_type -> datasource.v1.HealthCheckResponse
-	13, // [13:18] is the sub-list for method output_type
-	8,  // [8:13] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	25, // 0: datasource.v1.QueryRequest.query:type_name -> google.protobuf.Struct
+	25, // 1: datasource.v1.QueryRequest.extensions:type_name -> google.protobuf.Struct
+	25, // 2: datasource.v1.QueryResult.data:type_name -> google.protobuf.Struct
+	25, // 3: datasource.v1.MutateRequest.payload:type_name -> google.protobuf.Struct
+	25, // 4: datasource.v1.MutateResult.data:type_name -> google.protobuf.Struct
+	24, // 5: datasource.v1.SchemaResult.tables:type_name -> datasource.v1.SchemaResult.TablesEntry
+	8,  // 6: datasource.v1.TableSchema.fields:type_name -> datasource.v1.FieldDescription
+	0,  // 7: datasource.v1.HealthCheckResponse.status:type_name -> datasource.v1.HealthCheckResponse.ServingStatus
+	16, // 8: datasource.v1.ScanEmbeddingSourcesResponse.sources:type_name -> datasource.v1.EmbeddingSource
+	18, // 9: datasource.v1.UpsertEmbeddingsRequest.embeddings:type_name -> datasource.v1.Embedding
+	22, // 10: datasource.v1.SimilaritySearchResponse.matches:type_name -> datasource.v1.SimilarityMatch
+	10, // 11: datasource.v1.SchemaResult.TablesEntry.value:type_name -> datasource.v1.TableSchema
+	5,  // 12: datasource.v1.DataSource.GetPluginInfo:input_type -> datasource.v1.GetPluginInfoRequest
+	1,  // 13: datasource.v1.DataSource.Query:input_type -> datasource.v1.QueryRequest
+	3,  // 14: datasource.v1.DataSource.Mutate:input_type -> datasource.v1.MutateRequest
+	7,  // 15: datasource.v1.DataSource.GetSchema:input_type -> datasource.v1.SchemaRequest
+	11, // 16: datasource.v1.DataSource.HealthCheck:input_type -> datasource.v1.HealthCheckRequest
+	13, // 17: datasource.v1.DataSource.Shutdown:input_type -> datasource.v1.ShutdownRequest
+	15, // 18: datasource.v1.DataSource.ScanEmbeddingSources:input_type -> datasource.v1.ScanEmbeddingSourcesRequest
+	19, // 19: datasource.v1.DataSource.UpsertEmbeddings:input_type -> datasource.v1.UpsertEmbeddingsRequest
+	21, // 20: datasource.v1.DataSource.SimilaritySearch:input_type -> datasource.v1.SimilaritySearchRequest
+	3,  // 21: datasource.v1.DataSource.AdminMutate:input_type -> datasource.v1.MutateRequest
+	6,  // 22: datasource.v1.DataSource.GetPluginInfo:output_type -> datasource.v1.GetPluginInfoResponse
+	2,  // 23: datasource.v1.DataSource.Query:output_type -> datasource.v1.QueryResult
+	4,  // 24: datasource.v1.DataSource.Mutate:output_type -> datasource.v1.MutateResult
+	9,  // 25: datasource.v1.DataSource.GetSchema:output_type -> datasource.v1.SchemaResult
+	12, // 26: datasource.v1.DataSource.HealthCheck:output_type -> datasource.v1.HealthCheckResponse
+	14, // 27: datasource.v1.DataSource.Shutdown:output_type -> datasource.v1.ShutdownResponse
+	17, // 28: datasource.v1.DataSource.ScanEmbeddingSources:output_type -> datasource.v1.ScanEmbeddingSourcesResponse
+	20, // 29: datasource.v1.DataSource.UpsertEmbeddings:output_type -> datasource.v1.UpsertEmbeddingsResponse
+	23, // 30: datasource.v1.DataSource.SimilaritySearch:output_type -> datasource.v1.SimilaritySearchResponse
+	4,  // 31: datasource.v1.DataSource.AdminMutate:output_type -> datasource.v1.MutateResult
+	22, // [22:32] is the sub-list for method output_type
+	12, // [12:22] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_datasource_v1_datasource_proto_init() }
@@ -907,7 +1762,7 @@ func file_datasource_v1_datasource_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_datasource_v1_datasource_proto_rawDesc), len(file_datasource_v1_datasource_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   13,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
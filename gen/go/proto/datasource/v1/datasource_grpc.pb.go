@@ -21,11 +21,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataSource_GetPluginInfo_FullMethodName = "/datasource.v1.DataSource/GetPluginInfo"
-	DataSource_Query_FullMethodName         = "/datasource.v1.DataSource/Query"
-	DataSource_Mutate_FullMethodName        = "/datasource.v1.DataSource/Mutate"
-	DataSource_GetSchema_FullMethodName     = "/datasource.v1.DataSource/GetSchema"
-	DataSource_HealthCheck_FullMethodName   = "/datasource.v1.DataSource/HealthCheck"
+	DataSource_GetPluginInfo_FullMethodName        = "/datasource.v1.DataSource/GetPluginInfo"
+	DataSource_Query_FullMethodName                = "/datasource.v1.DataSource/Query"
+	DataSource_Mutate_FullMethodName               = "/datasource.v1.DataSource/Mutate"
+	DataSource_GetSchema_FullMethodName            = "/datasource.v1.DataSource/GetSchema"
+	DataSource_HealthCheck_FullMethodName          = "/datasource.v1.DataSource/HealthCheck"
+	DataSource_Shutdown_FullMethodName             = "/datasource.v1.DataSource/Shutdown"
+	DataSource_ScanEmbeddingSources_FullMethodName = "/datasource.v1.DataSource/ScanEmbeddingSources"
+	DataSource_UpsertEmbeddings_FullMethodName     = "/datasource.v1.DataSource/UpsertEmbeddings"
+	DataSource_SimilaritySearch_FullMethodName     = "/datasource.v1.DataSource/SimilaritySearch"
+	DataSource_AdminMutate_FullMethodName          = "/datasource.v1.DataSource/AdminMutate"
 )
 
 // DataSourceClient is the client API for DataSource service.
@@ -46,6 +51,23 @@ type DataSourceClient interface {
 	GetSchema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*SchemaResult, error)
 	// HealthCheck 用于网关对插件进行健康检查，以实现自愈和监控。
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// Shutdown 通知插件优雅退出：停止接收新请求，刷写并关闭数据库连接后自行结束进程。
+	// 网关在宽限期内等待进程退出，超时后依次发送 SIGTERM 与 SIGKILL。
+	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error)
+	// --- 可选扩展: 语义 (向量) 检索 ---
+	// 以下接口由支持向量存储的插件实现；未实现的插件由生成代码返回 UNIMPLEMENTED，
+	// 网关据此判断该业务组不支持语义检索。向量由网关计算，插件只负责提供文本、保存向量与相似度检索。
+	//
+	// ScanEmbeddingSources 按游标分页读取表中待向量化的文本。
+	ScanEmbeddingSources(ctx context.Context, in *ScanEmbeddingSourcesRequest, opts ...grpc.CallOption) (*ScanEmbeddingSourcesResponse, error)
+	// UpsertEmbeddings 写入或删除记录的向量。
+	UpsertEmbeddings(ctx context.Context, in *UpsertEmbeddingsRequest, opts ...grpc.CallOption) (*UpsertEmbeddingsResponse, error)
+	// SimilaritySearch 返回与查询向量最相似的记录。
+	SimilaritySearch(ctx context.Context, in *SimilaritySearchRequest, opts ...grpc.CallOption) (*SimilaritySearchResponse, error)
+	// AdminMutate 执行绕过表级写权限的管理操作 (同步变更、全文索引重建、库文件维护、压缩与整库替换)，
+	// 请求与结果的结构与 Mutate 相同，operation 为管理操作名。网关只在管理接口与后台任务中调用，
+	// 插件的 Mutate 应只接受 create、update 与 delete。未实现的插件由生成代码返回 UNIMPLEMENTED。
+	AdminMutate(ctx context.Context, in *MutateRequest, opts ...grpc.CallOption) (*MutateResult, error)
 }
 
 type dataSourceClient struct {
@@ -106,6 +128,56 @@ func (c *dataSourceClient) HealthCheck(ctx context.Context, in *HealthCheckReque
 	return out, nil
 }
 
+func (c *dataSourceClient) Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShutdownResponse)
+	err := c.cc.Invoke(ctx, DataSource_Shutdown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourceClient) ScanEmbeddingSources(ctx context.Context, in *ScanEmbeddingSourcesRequest, opts ...grpc.CallOption) (*ScanEmbeddingSourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanEmbeddingSourcesResponse)
+	err := c.cc.Invoke(ctx, DataSource_ScanEmbeddingSources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourceClient) UpsertEmbeddings(ctx context.Context, in *UpsertEmbeddingsRequest, opts ...grpc.CallOption) (*UpsertEmbeddingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpsertEmbeddingsResponse)
+	err := c.cc.Invoke(ctx, DataSource_UpsertEmbeddings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourceClient) SimilaritySearch(ctx context.Context, in *SimilaritySearchRequest, opts ...grpc.CallOption) (*SimilaritySearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimilaritySearchResponse)
+	err := c.cc.Invoke(ctx, DataSource_SimilaritySearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSourceClient) AdminMutate(ctx context.Context, in *MutateRequest, opts ...grpc.CallOption) (*MutateResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MutateResult)
+	err := c.cc.Invoke(ctx, DataSource_AdminMutate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataSourceServer is the server API for DataSource service.
 // All implementations must embed UnimplementedDataSourceServer
 // for forward compatibility.
@@ -124,6 +196,23 @@ type DataSourceServer interface {
 	GetSchema(context.Context, *SchemaRequest) (*SchemaResult, error)
 	// HealthCheck 用于网关对插件进行健康检查，以实现自愈和监控。
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// Shutdown 通知插件优雅退出：停止接收新请求，刷写并关闭数据库连接后自行结束进程。
+	// 网关在宽限期内等待进程退出，超时后依次发送 SIGTERM 与 SIGKILL。
+	Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error)
+	// --- 可选扩展: 语义 (向量) 检索 ---
+	// 以下接口由支持向量存储的插件实现；未实现的插件由生成代码返回 UNIMPLEMENTED，
+	// 网关据此判断该业务组不支持语义检索。向量由网关计算，插件只负责提供文本、保存向量与相似度检索。
+	//
+	// ScanEmbeddingSources 按游标分页读取表中待向量化的文本。
+	ScanEmbeddingSources(context.Context, *ScanEmbeddingSourcesRequest) (*ScanEmbeddingSourcesResponse, error)
+	// UpsertEmbeddings 写入或删除记录的向量。
+	UpsertEmbeddings(context.Context, *UpsertEmbeddingsRequest) (*UpsertEmbeddingsResponse, error)
+	// SimilaritySearch 返回与查询向量最相似的记录。
+	SimilaritySearch(context.Context, *SimilaritySearchRequest) (*SimilaritySearchResponse, error)
+	// AdminMutate 执行绕过表级写权限的管理操作 (同步变更、全文索引重建、库文件维护、压缩与整库替换)，
+	// 请求与结果的结构与 Mutate 相同，operation 为管理操作名。网关只在管理接口与后台任务中调用，
+	// 插件的 Mutate 应只接受 create、update 与 delete。未实现的插件由生成代码返回 UNIMPLEMENTED。
+	AdminMutate(context.Context, *MutateRequest) (*MutateResult, error)
 	mustEmbedUnimplementedDataSourceServer()
 }
 
@@ -149,6 +238,21 @@ func (UnimplementedDataSourceServer) GetSchema(context.Context, *SchemaRequest)
 func (UnimplementedDataSourceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedDataSourceServer) Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
+}
+func (UnimplementedDataSourceServer) ScanEmbeddingSources(context.Context, *ScanEmbeddingSourcesRequest) (*ScanEmbeddingSourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanEmbeddingSources not implemented")
+}
+func (UnimplementedDataSourceServer) UpsertEmbeddings(context.Context, *UpsertEmbeddingsRequest) (*UpsertEmbeddingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertEmbeddings not implemented")
+}
+func (UnimplementedDataSourceServer) SimilaritySearch(context.Context, *SimilaritySearchRequest) (*SimilaritySearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimilaritySearch not implemented")
+}
+func (UnimplementedDataSourceServer) AdminMutate(context.Context, *MutateRequest) (*MutateResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminMutate not implemented")
+}
 func (UnimplementedDataSourceServer) mustEmbedUnimplementedDataSourceServer() {}
 func (UnimplementedDataSourceServer) testEmbeddedByValue()                    {}
 
@@ -260,6 +364,96 @@ func _DataSource_HealthCheck_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataSource_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShutdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourceServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSource_Shutdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourceServer).Shutdown(ctx, req.(*ShutdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSource_ScanEmbeddingSources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanEmbeddingSourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourceServer).ScanEmbeddingSources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSource_ScanEmbeddingSources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourceServer).ScanEmbeddingSources(ctx, req.(*ScanEmbeddingSourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSource_UpsertEmbeddings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertEmbeddingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourceServer).UpsertEmbeddings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSource_UpsertEmbeddings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourceServer).UpsertEmbeddings(ctx, req.(*UpsertEmbeddingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSource_SimilaritySearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimilaritySearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourceServer).SimilaritySearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSource_SimilaritySearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourceServer).SimilaritySearch(ctx, req.(*SimilaritySearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSource_AdminMutate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MutateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSourceServer).AdminMutate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSource_AdminMutate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSourceServer).AdminMutate(ctx, req.(*MutateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataSource_ServiceDesc is the grpc.ServiceDesc for DataSource service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -287,6 +481,26 @@ var DataSource_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _DataSource_HealthCheck_Handler,
 		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _DataSource_Shutdown_Handler,
+		},
+		{
+			MethodName: "ScanEmbeddingSources",
+			Handler:    _DataSource_ScanEmbeddingSources_Handler,
+		},
+		{
+			MethodName: "UpsertEmbeddings",
+			Handler:    _DataSource_UpsertEmbeddings_Handler,
+		},
+		{
+			MethodName: "SimilaritySearch",
+			Handler:    _DataSource_SimilaritySearch_Handler,
+		},
+		{
+			MethodName: "AdminMutate",
+			Handler:    _DataSource_AdminMutate_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "datasource/v1/datasource.proto",
@@ -0,0 +1,188 @@
+// Package configrpc file: internal/adapter/configrpc/client.go
+package configrpc
+
+import (
+	configv1 "ArchiveAegis/gen/go/proto/config/v1"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// 编译期断言，插件可以直接把 Client 交给数据源使用
+var _ port.QueryAdminConfigService = (*Client)(nil)
+
+// ErrUnsupported 表示配置接口不提供该操作：插件只能读取业务组的查询配置，其他配置由网关管理
+var ErrUnsupported = errors.New("网关配置接口只提供业务组查询配置的读取")
+
+// Client 通过网关的配置接口读取业务组配置，实现 port.QueryAdminConfigService 中插件需要的读取部分，
+// 并与直接读取系统库时一样在本地缓存。网关修改配置后插件应调用 InvalidateAllCaches (见 grpc_client.ConfigVersionMetadataKey)
+type Client struct {
+	conn   *grpc.ClientConn
+	client configv1.ConfigServiceClient
+	token  string
+	cache  *lru.LRU[string, *domain.BizQueryConfig]
+}
+
+// Dial 连接网关的配置接口，cacheTTL 是本地缓存的过期时间
+func Dial(address, token string, cacheTTL time.Duration) (*Client, error) {
+	if address == "" || token == "" {
+		return nil, fmt.Errorf("配置接口的地址与访问令牌不能为空")
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("无法连接到网关配置接口 at %s: %w", address, err)
+	}
+	return &Client{
+		conn:   conn,
+		client: configv1.NewConfigServiceClient(conn),
+		token:  token,
+		cache:  lru.NewLRU[string, *domain.BizQueryConfig](100, nil, cacheTTL),
+	}, nil
+}
+
+// DialFromEnv 按网关设置的环境变量连接配置接口；未设置时返回 (nil, nil)，插件应退回直接读取系统库
+func DialFromEnv(getenv func(string) string, cacheTTL time.Duration) (*Client, error) {
+	address := getenv(EnvAddress)
+	if address == "" {
+		return nil, nil
+	}
+	return Dial(address, getenv(EnvToken), cacheTTL)
+}
+
+// Close 关闭与网关的连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// withToken 在请求的元数据中附加访问令牌
+func (c *Client) withToken(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+}
+
+// GetBizQueryConfig 返回业务组的查询配置，业务组未配置时返回 (nil, nil)，与直接读取系统库时相同
+func (c *Client) GetBizQueryConfig(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+	if bizName == "" {
+		return nil, fmt.Errorf("业务组名称 (bizName) 不能为空")
+	}
+	if cfg, found := c.cache.Get(bizName); found {
+		return cfg, nil
+	}
+	res, err := c.client.GetBizQueryConfig(c.withToken(ctx), &configv1.GetBizQueryConfigRequest{BizName: bizName})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("从网关读取业务组 '%s' 的配置失败: %w", bizName, err)
+	}
+	if res.GetConfig() == nil {
+		return nil, fmt.Errorf("网关返回的业务组 '%s' 配置为空", bizName)
+	}
+	cfg := bizQueryConfigFromProto(res.GetConfig())
+	c.cache.Add(bizName, cfg)
+	return cfg, nil
+}
+
+// GetAllConfiguredBizNames 返回所有已配置的业务组名
+func (c *Client) GetAllConfiguredBizNames(ctx context.Context) ([]string, error) {
+	res, err := c.client.ListBizNames(c.withToken(ctx), &configv1.ListBizNamesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("从网关读取业务组列表失败: %w", err)
+	}
+	return res.GetBizNames(), nil
+}
+
+// InvalidateCacheForBiz 使指定业务组的本地缓存失效
+func (c *Client) InvalidateCacheForBiz(bizName string) {
+	c.cache.Remove(bizName)
+}
+
+// InvalidateAllCaches 清除所有本地缓存
+func (c *Client) InvalidateAllCaches() {
+	c.cache.Purge()
+	slog.Debug("[ConfigRPC] 已清除本地的业务组配置缓存")
+}
+
+// 以下操作由网关管理，插件不能通过配置接口执行
+
+func (c *Client) UpdateBizOverallSettings(context.Context, string, domain.BizOverallSettings) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateBizSearchableTables(context.Context, string, []string) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableWritePermissions(context.Context, string, string, domain.TableConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableFieldSettings(context.Context, string, string, []domain.FieldSetting) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateFieldSettingsBulk(context.Context, string, map[string][]domain.FieldSetting) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableFTSConfig(context.Context, string, string, *domain.FTSConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableRankingConfig(context.Context, string, string, *domain.RankingConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableHierarchyConfig(context.Context, string, string, *domain.HierarchyConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) UpdateTableSQLViewConfig(context.Context, string, string, *domain.SQLViewConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) GetDefaultViewConfig(context.Context, string, string) (*domain.ViewConfig, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *Client) GetAllViewConfigsForBiz(context.Context, string) (map[string][]*domain.ViewConfig, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *Client) UpdateAllViewsForBiz(context.Context, string, map[string][]*domain.ViewConfig) error {
+	return ErrUnsupported
+}
+
+func (c *Client) GetIPLimitSettings(context.Context) (*domain.IPLimitSetting, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *Client) UpdateIPLimitSettings(context.Context, domain.IPLimitSetting) error {
+	return ErrUnsupported
+}
+
+func (c *Client) GetUserLimitSettings(context.Context, int64) (*domain.UserLimitSetting, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *Client) UpdateUserLimitSettings(context.Context, int64, domain.UserLimitSetting) error {
+	return ErrUnsupported
+}
+
+func (c *Client) GetBizRateLimitSettings(context.Context, string) (*domain.BizRateLimitSetting, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *Client) UpdateBizRateLimitSettings(context.Context, string, domain.BizRateLimitSetting) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,129 @@
+// file: internal/adapter/configrpc/configrpc_test.go
+package configrpc
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubConfigService 只实现配置接口用到的两个读取方法
+type stubConfigService struct {
+	port.QueryAdminConfigService
+	configs map[string]*domain.BizQueryConfig
+	reads   int
+}
+
+func (s *stubConfigService) GetBizQueryConfig(_ context.Context, bizName string) (*domain.BizQueryConfig, error) {
+	s.reads++
+	return s.configs[bizName], nil
+}
+
+func (s *stubConfigService) GetAllConfiguredBizNames(context.Context) ([]string, error) {
+	names := make([]string, 0, len(s.configs))
+	for name := range s.configs {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func TestConfigService_RoundTrip(t *testing.T) {
+	stub := &stubConfigService{configs: map[string]*domain.BizQueryConfig{
+		"archive": {
+			BizName:              "archive",
+			IsPubliclySearchable: true,
+			MaxResponseBytes:     4096,
+			Tables: map[string]*domain.TableConfig{
+				"letters": {
+					TableName:    "letters",
+					IsSearchable: true,
+					AllowUpdate:  true,
+					Fields: map[string]domain.FieldSetting{
+						"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true, DisplayName: "发信人", SearchWeight: 2.5},
+					},
+					FTS:       &domain.FTSConfig{Fields: []string{"sender"}, Tokenizer: "unicode61", Stopwords: []string{"的"}, Version: 3},
+					Ranking:   &domain.RankingConfig{Expression: domain.RankExpressionBM25},
+					Hierarchy: &domain.HierarchyConfig{KeyField: "id", ParentField: "parent_id"},
+				},
+				"by_year": {
+					TableName: "by_year",
+					Fields:    map[string]domain.FieldSetting{},
+					SQLView: &domain.SQLViewConfig{
+						SQL: "SELECT * FROM letters WHERE year = :year",
+						Params: []domain.SQLViewParam{
+							{Name: "year", Type: domain.SQLViewParamInteger, Default: float64(1921)},
+							{Name: "sender", Type: domain.SQLViewParamText},
+						},
+					},
+				},
+			},
+		},
+	}}
+	endpoint, err := Start("127.0.0.1:0", stub)
+	require.NoError(t, err)
+	defer endpoint.Stop()
+
+	env := map[string]string{}
+	for _, kv := range endpoint.Env() {
+		key, value, _ := strings.Cut(kv, "=")
+		env[key] = value
+	}
+	client, err := DialFromEnv(func(key string) string { return env[key] }, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	defer client.Close()
+	ctx := context.Background()
+
+	cfg, err := client.GetBizQueryConfig(ctx, "archive")
+	require.NoError(t, err)
+	assert.Equal(t, stub.configs["archive"], cfg)
+	_, _ = client.GetBizQueryConfig(ctx, "archive")
+	assert.Equal(t, 1, stub.reads, "读取结果在本地缓存")
+	client.InvalidateAllCaches()
+	_, _ = client.GetBizQueryConfig(ctx, "archive")
+	assert.Equal(t, 2, stub.reads)
+
+	missing, err := client.GetBizQueryConfig(ctx, "museum")
+	require.NoError(t, err, "未配置的业务组与直接读取系统库时一样返回 nil")
+	assert.Nil(t, missing)
+
+	names, err := client.GetAllConfiguredBizNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive"}, names)
+
+	assert.ErrorIs(t, client.UpdateTableFieldSettings(ctx, "archive", "letters", nil), ErrUnsupported)
+
+	// 令牌错误的请求被拒绝
+	intruder, err := Dial(endpoint.Address, "wrong", time.Minute)
+	require.NoError(t, err)
+	defer intruder.Close()
+	_, err = intruder.GetBizQueryConfig(ctx, "archive")
+	assert.Equal(t, codes.Unauthenticated, status.Code(unwrapStatus(err)))
+
+	none, err := DialFromEnv(func(string) string { return "" }, time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, none, "未设置环境变量时由调用方退回读取系统库")
+}
+
+// unwrapStatus 取出被包装的 gRPC 状态错误
+func unwrapStatus(err error) error {
+	for err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return err
+		}
+		err = u.Unwrap()
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+// Package configrpc file: internal/adapter/configrpc/convert.go
+package configrpc
+
+import (
+	configv1 "ArchiveAegis/gen/go/proto/config/v1"
+	"ArchiveAegis/internal/core/domain"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// bizQueryConfigToProto 把业务组的查询配置转换为 proto 消息。
+// SQL 视图参数的默认值以 google.protobuf.Value 传输，只能是 JSON 可表示的值
+func bizQueryConfigToProto(cfg *domain.BizQueryConfig) (*configv1.BizQueryConfig, error) {
+	out := &configv1.BizQueryConfig{
+		BizName:              cfg.BizName,
+		IsPubliclySearchable: cfg.IsPubliclySearchable,
+		DefaultQueryTable:    cfg.DefaultQueryTable,
+		ChangeCaptureEnabled: cfg.ChangeCaptureEnabled,
+		CitationTemplate:     cfg.CitationTemplate,
+		Enabled:              cfg.Enabled,
+		MaxResponseBytes:     cfg.MaxResponseBytes,
+		QueryCostPolicy:      cfg.QueryCostPolicy,
+		MaxScanRows:          cfg.MaxScanRows,
+		Timezone:             cfg.Timezone,
+		Locale:               cfg.Locale,
+		Sensitive:            cfg.Sensitive,
+		Tables:               make(map[string]*configv1.TableConfig, len(cfg.Tables)),
+	}
+	for name, table := range cfg.Tables {
+		if table == nil {
+			continue
+		}
+		t, err := tableConfigToProto(table)
+		if err != nil {
+			return nil, fmt.Errorf("表 '%s': %w", name, err)
+		}
+		out.Tables[name] = t
+	}
+	return out, nil
+}
+
+func tableConfigToProto(table *domain.TableConfig) (*configv1.TableConfig, error) {
+	out := &configv1.TableConfig{
+		TableName:    table.TableName,
+		IsSearchable: table.IsSearchable,
+		Fields:       make(map[string]*configv1.FieldSetting, len(table.Fields)),
+		AllowCreate:  table.AllowCreate,
+		AllowUpdate:  table.AllowUpdate,
+		AllowDelete:  table.AllowDelete,
+	}
+	for name, field := range table.Fields {
+		out.Fields[name] = &configv1.FieldSetting{
+			FieldName:         field.FieldName,
+			IsSearchable:      field.IsSearchable,
+			IsReturnable:      field.IsReturnable,
+			DataType:          field.DataType,
+			DisplayName:       field.DisplayName,
+			Pinyin:            field.Pinyin,
+			CaseInsensitive:   field.CaseInsensitive,
+			AccentInsensitive: field.AccentInsensitive,
+			SearchWeight:      field.SearchWeight,
+		}
+	}
+	if fts := table.FTS; fts != nil {
+		out.Fts = &configv1.FTSConfig{
+			Fields:           fts.Fields,
+			Tokenizer:        fts.Tokenizer,
+			RemoveDiacritics: fts.RemoveDiacritics,
+			Stopwords:        fts.Stopwords,
+			Version:          fts.Version,
+		}
+	}
+	if ranking := table.Ranking; ranking != nil {
+		out.Ranking = &configv1.RankingConfig{Expression: ranking.Expression}
+	}
+	if hierarchy := table.Hierarchy; hierarchy != nil {
+		out.Hierarchy = &configv1.HierarchyConfig{
+			KeyField:    hierarchy.KeyField,
+			ParentField: hierarchy.ParentField,
+			SortField:   hierarchy.SortField,
+		}
+	}
+	if view := table.SQLView; view != nil {
+		out.SqlView = &configv1.SQLViewConfig{Sql: view.SQL, Description: view.Description}
+		for _, param := range view.Params {
+			p := &configv1.SQLViewParam{Name: param.Name, Type: param.Type}
+			if param.Default != nil {
+				value, err := structpb.NewValue(param.Default)
+				if err != nil {
+					return nil, fmt.Errorf("SQL 视图参数 '%s' 的默认值无法传输: %w", param.Name, err)
+				}
+				p.DefaultValue = value
+			}
+			out.SqlView.Params = append(out.SqlView.Params, p)
+		}
+	}
+	return out, nil
+}
+
+// bizQueryConfigFromProto 把 proto 消息还原为业务组的查询配置。
+// 空的列表还原为 nil；SQL 视图参数默认值中的数值还原为 float64，与经 JSON 读取时相同
+func bizQueryConfigFromProto(cfg *configv1.BizQueryConfig) *domain.BizQueryConfig {
+	out := &domain.BizQueryConfig{
+		BizName:              cfg.GetBizName(),
+		IsPubliclySearchable: cfg.GetIsPubliclySearchable(),
+		DefaultQueryTable:    cfg.GetDefaultQueryTable(),
+		ChangeCaptureEnabled: cfg.GetChangeCaptureEnabled(),
+		CitationTemplate:     cfg.GetCitationTemplate(),
+		Enabled:              cfg.GetEnabled(),
+		MaxResponseBytes:     cfg.GetMaxResponseBytes(),
+		QueryCostPolicy:      cfg.GetQueryCostPolicy(),
+		MaxScanRows:          cfg.GetMaxScanRows(),
+		Timezone:             cfg.GetTimezone(),
+		Locale:               cfg.GetLocale(),
+		Sensitive:            cfg.GetSensitive(),
+		Tables:               make(map[string]*domain.TableConfig, len(cfg.GetTables())),
+	}
+	for name, table := range cfg.GetTables() {
+		out.Tables[name] = tableConfigFromProto(table)
+	}
+	return out
+}
+
+func tableConfigFromProto(table *configv1.TableConfig) *domain.TableConfig {
+	out := &domain.TableConfig{
+		TableName:    table.GetTableName(),
+		IsSearchable: table.GetIsSearchable(),
+		Fields:       make(map[string]domain.FieldSetting, len(table.GetFields())),
+		AllowCreate:  table.GetAllowCreate(),
+		AllowUpdate:  table.GetAllowUpdate(),
+		AllowDelete:  table.GetAllowDelete(),
+	}
+	for name, field := range table.GetFields() {
+		out.Fields[name] = domain.FieldSetting{
+			FieldName:         field.GetFieldName(),
+			IsSearchable:      field.GetIsSearchable(),
+			IsReturnable:      field.GetIsReturnable(),
+			DataType:          field.GetDataType(),
+			DisplayName:       field.GetDisplayName(),
+			Pinyin:            field.GetPinyin(),
+			CaseInsensitive:   field.GetCaseInsensitive(),
+			AccentInsensitive: field.GetAccentInsensitive(),
+			SearchWeight:      field.GetSearchWeight(),
+		}
+	}
+	if fts := table.GetFts(); fts != nil {
+		out.FTS = &domain.FTSConfig{
+			Fields:           nilIfEmpty(fts.GetFields()),
+			Tokenizer:        fts.GetTokenizer(),
+			RemoveDiacritics: fts.GetRemoveDiacritics(),
+			Stopwords:        nilIfEmpty(fts.GetStopwords()),
+			Version:          fts.GetVersion(),
+		}
+	}
+	if ranking := table.GetRanking(); ranking != nil {
+		out.Ranking = &domain.RankingConfig{Expression: ranking.GetExpression()}
+	}
+	if hierarchy := table.GetHierarchy(); hierarchy != nil {
+		out.Hierarchy = &domain.HierarchyConfig{
+			KeyField:    hierarchy.GetKeyField(),
+			ParentField: hierarchy.GetParentField(),
+			SortField:   hierarchy.GetSortField(),
+		}
+	}
+	if view := table.GetSqlView(); view != nil {
+		out.SQLView = &domain.SQLViewConfig{SQL: view.GetSql(), Description: view.GetDescription()}
+		for _, param := range view.GetParams() {
+			p := domain.SQLViewParam{Name: param.GetName(), Type: param.GetType()}
+			if param.GetDefaultValue() != nil {
+				p.Default = param.GetDefaultValue().AsInterface()
+			}
+			out.SQLView.Params = append(out.SQLView.Params, p)
+		}
+	}
+	return out
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
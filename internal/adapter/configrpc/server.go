@@ -0,0 +1,127 @@
+// Package configrpc file: internal/adapter/configrpc/server.go
+//
+// Package configrpc 实现网关提供给插件进程的只读配置接口 (见 proto/config/v1/config.proto)。
+// 插件通过该接口获取业务组配置，不再直接打开网关的系统库。服务与消息由 proto 生成 (见 gen/go/proto/config/v1)，
+// 与 domain 配置结构之间的转换见 convert.go。
+package configrpc
+
+import (
+	configv1 "ArchiveAegis/gen/go/proto/config/v1"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// 插件进程通过以下环境变量获得配置接口的地址与访问令牌
+const (
+	EnvAddress = "AEGIS_CONFIG_ADDR"
+	EnvToken   = "AEGIS_CONFIG_TOKEN"
+)
+
+// server 以网关的配置服务回答插件的请求，每次调用都需携带启动时生成的令牌
+type server struct {
+	configv1.UnimplementedConfigServiceServer
+	configService port.QueryAdminConfigService
+	token         string
+}
+
+func (s *server) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "缺少配置接口的访问令牌")
+	}
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return status.Error(codes.Unauthenticated, "配置接口的访问令牌无效")
+	}
+	return nil
+}
+
+// GetBizQueryConfig 返回业务组的查询配置，业务组未配置时返回 NOT_FOUND
+func (s *server) GetBizQueryConfig(ctx context.Context, req *configv1.GetBizQueryConfigRequest) (*configv1.GetBizQueryConfigResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	cfg, err := s.configService.GetBizQueryConfig(ctx, req.GetBizName())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取业务组 '%s' 的配置失败: %v", req.GetBizName(), err)
+	}
+	if cfg == nil {
+		return nil, status.Errorf(codes.NotFound, "业务组 '%s' 未配置", req.GetBizName())
+	}
+	out, err := bizQueryConfigToProto(cfg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "序列化业务组 '%s' 的配置失败: %v", req.GetBizName(), err)
+	}
+	return &configv1.GetBizQueryConfigResponse{Config: out}, nil
+}
+
+// ListBizNames 返回所有已配置的业务组名
+func (s *server) ListBizNames(ctx context.Context, _ *configv1.ListBizNamesRequest) (*configv1.ListBizNamesResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	names, err := s.configService.GetAllConfiguredBizNames(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "读取业务组列表失败: %v", err)
+	}
+	return &configv1.ListBizNamesResponse{BizNames: names}, nil
+}
+
+// Endpoint 是网关上运行的配置接口
+type Endpoint struct {
+	// Address 是实际监听的地址，配置的端口为 0 时由系统分配
+	Address string
+	// Token 是本次启动随机生成的访问令牌，只通过环境变量交给网关启动的插件进程
+	Token string
+
+	grpcServer *grpc.Server
+}
+
+// Start 在 address 上启动配置接口。接口不经过 HTTP 层的认证，应只监听本机回环地址
+func Start(address string, configService port.QueryAdminConfigService) (*Endpoint, error) {
+	if configService == nil {
+		return nil, fmt.Errorf("配置接口需要有效的配置服务")
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("生成配置接口的访问令牌失败: %w", err)
+	}
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("配置接口监听 '%s' 失败: %w", address, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	token := hex.EncodeToString(secret)
+	configv1.RegisterConfigServiceServer(grpcServer, &server{configService: configService, token: token})
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			slog.Error("[ConfigRPC] 配置接口停止服务", "error", err)
+		}
+	}()
+	slog.Info("[ConfigRPC] 插件配置接口已启动", "address", lis.Addr().String())
+	return &Endpoint{Address: lis.Addr().String(), Token: token, grpcServer: grpcServer}, nil
+}
+
+// Env 返回插件进程访问配置接口所需的环境变量
+func (e *Endpoint) Env() []string {
+	return []string{EnvAddress + "=" + e.Address, EnvToken + "=" + e.Token}
+}
+
+// Stop 等待进行中的请求完成后停止配置接口
+func (e *Endpoint) Stop() {
+	e.grpcServer.GracefulStop()
+}
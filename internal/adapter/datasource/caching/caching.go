@@ -0,0 +1,381 @@
+// Package caching file: internal/adapter/datasource/caching/caching.go
+package caching
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+)
+
+// Options 定义了读穿透缓存的行为
+type Options struct {
+	// TTL 是缓存条目的存活时间
+	TTL time.Duration
+	// MaxEntries 是缓存条目的上限，超出后按 LRU 淘汰
+	MaxEntries int
+	// BypassTables 中的表永远不走缓存。格式为 "biz.table"，或仅 "table" 表示所有业务组的同名表
+	BypassTables []string
+	// ServeStale 中的业务组在数据源不可用时以此前缓存的结果回答相同的查询，"*" 表示所有业务组。
+	// 旧结果单独保存，不受 TTL 限制，直到 StaleTTL 到期、被 LRU 淘汰或业务组数据发生变更
+	ServeStale []string
+	// StaleTTL 是旧结果可被使用的最长时间，默认为 24 小时
+	StaleTTL time.Duration
+}
+
+// staleEntry 是一次成功查询的结果及其缓存时间
+type staleEntry struct {
+	result   *port.QueryResult
+	cachedAt time.Time
+}
+
+// Cache 是一个跨业务组共享的查询结果缓存，通过 Wrap 为任意 DataSource 加上读穿透缓存。
+// 相同的并发查询只会有一个请求真正到达下游 (防击穿)。
+type Cache struct {
+	store  *lru.LRU[string, *port.QueryResult]
+	group  singleflight.Group
+	bypass map[string]struct{}
+
+	// stale 为 nil 表示没有业务组启用旧结果降级
+	stale     *lru.LRU[string, staleEntry]
+	staleBizs map[string]bool
+	staleAll  bool
+}
+
+// New 创建一个新的查询结果缓存
+func New(opts Options) *Cache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 10000
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 30 * time.Second
+	}
+	bypass := make(map[string]struct{}, len(opts.BypassTables))
+	for _, t := range opts.BypassTables {
+		bypass[t] = struct{}{}
+	}
+	c := &Cache{
+		store:  lru.NewLRU[string, *port.QueryResult](opts.MaxEntries, nil, opts.TTL),
+		bypass: bypass,
+	}
+	if len(opts.ServeStale) > 0 {
+		if opts.StaleTTL <= 0 {
+			opts.StaleTTL = 24 * time.Hour
+		}
+		c.stale = lru.NewLRU[string, staleEntry](opts.MaxEntries, nil, opts.StaleTTL)
+		c.staleBizs = make(map[string]bool, len(opts.ServeStale))
+		for _, biz := range opts.ServeStale {
+			if biz == "*" {
+				c.staleAll = true
+			}
+			c.staleBizs[biz] = true
+		}
+	}
+	return c
+}
+
+// Wrap 返回一个带缓存的 DataSource 装饰器，下游的实现无需任何修改
+func (c *Cache) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	return &Decorator{cache: c, bizName: bizName, inner: inner}
+}
+
+// Purge 清除指定业务组的所有缓存条目；bizName 为空时清空全部缓存。返回被清除的条目数。
+func (c *Cache) Purge(bizName string) int {
+	if c.stale != nil {
+		purgeKeys(c.stale, bizName)
+	}
+	if bizName == "" {
+		n := c.store.Len()
+		c.store.Purge()
+		log.Printf("信息: [QueryCache] 已清空全部查询缓存 (%d 条)。", n)
+		return n
+	}
+	prefix := bizName + "\x00"
+	var n int
+	for _, key := range c.store.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			if c.store.Remove(key) {
+				n++
+			}
+		}
+	}
+	log.Printf("信息: [QueryCache] 已清除业务组 '%s' 的查询缓存 (%d 条)。", bizName, n)
+	return n
+}
+
+// purgeKeys 清除旧结果中属于 bizName 的条目，bizName 为空时全部清除
+func purgeKeys(store *lru.LRU[string, staleEntry], bizName string) {
+	if bizName == "" {
+		store.Purge()
+		return
+	}
+	prefix := bizName + "\x00"
+	for _, key := range store.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			store.Remove(key)
+		}
+	}
+}
+
+// ServesStale 报告业务组是否在数据源不可用时使用旧结果
+func (c *Cache) ServesStale(bizName string) bool {
+	return c.stale != nil && (c.staleAll || c.staleBizs[bizName])
+}
+
+// Stats 按业务组统计缓存条目与旧结果的数量，按业务组名排序；ServeStale 中列出但尚无条目的业务组也会出现
+func (c *Cache) Stats() []domain.QueryCacheBizState {
+	byBiz := make(map[string]*domain.QueryCacheBizState)
+	get := func(bizName string) *domain.QueryCacheBizState {
+		st, ok := byBiz[bizName]
+		if !ok {
+			st = &domain.QueryCacheBizState{BizName: bizName, ServesStale: c.ServesStale(bizName)}
+			byBiz[bizName] = st
+		}
+		return st
+	}
+	for _, key := range c.store.Keys() {
+		if bizName, _, ok := strings.Cut(key, "\x00"); ok {
+			get(bizName).Entries++
+		}
+	}
+	if c.stale != nil {
+		for _, key := range c.stale.Keys() {
+			if bizName, _, ok := strings.Cut(key, "\x00"); ok {
+				get(bizName).StaleEntries++
+			}
+		}
+		for bizName := range c.staleBizs {
+			if bizName != "*" {
+				get(bizName)
+			}
+		}
+	}
+	stats := make([]domain.QueryCacheBizState, 0, len(byBiz))
+	for _, st := range byBiz {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].BizName < stats[j].BizName })
+	return stats
+}
+
+// Stale 返回业务组此前对相同查询缓存的结果，结果带 port.ResultStaleKey 与 port.ResultCachedAtKey；
+// 业务组未启用旧结果降级或没有相应的旧结果时返回 false
+func (c *Cache) Stale(req port.QueryRequest) (*port.QueryResult, bool) {
+	if !c.ServesStale(req.BizName) {
+		return nil, false
+	}
+	key, ok := cacheKey(req)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := c.stale.Get(key)
+	if !ok {
+		return nil, false
+	}
+	// 旧结果在多个请求之间共享，标记加在副本上
+	data := make(map[string]interface{}, len(entry.result.Data)+2)
+	for k, v := range entry.result.Data {
+		data[k] = v
+	}
+	data[port.ResultStaleKey] = true
+	data[port.ResultCachedAtKey] = entry.cachedAt.UTC().Format(time.RFC3339)
+	return &port.QueryResult{Data: data, Source: entry.result.Source}, true
+}
+
+// StaleOnly 返回一个只以旧结果回答查询的 DataSource，用于业务组的插件重启期间暂时从网关注销的情形。
+// 没有相应的旧结果时查询返回 port.ErrBizNotFound，其余操作一律返回 port.ErrBizNotFound
+func (c *Cache) StaleOnly(bizName string) port.DataSource {
+	return &staleOnly{cache: c, bizName: bizName}
+}
+
+// cacheKey 由业务组名、查询与扩展选项生成缓存键。encoding/json 会对 map 的键排序，因此等价的查询得到相同的键
+func cacheKey(req port.QueryRequest) (string, bool) {
+	body, err := json.Marshal(req.Query)
+	if err != nil {
+		return "", false
+	}
+	key := req.BizName + "\x00" + string(body)
+	if len(req.Extensions) > 0 {
+		ext, err := json.Marshal(req.Extensions)
+		if err != nil {
+			return "", false
+		}
+		key += "\x00" + string(ext)
+	}
+	return key, true
+}
+
+func (c *Cache) shouldBypass(bizName string, query map[string]interface{}) bool {
+	// 非默认查询模式 (例如变更日志) 的结果随时间变化，不适合缓存
+	if mode, _ := query[port.QueryModeKey].(string); mode != "" {
+		return true
+	}
+	// explain 结果包含本次执行的耗时，命中缓存时没有意义
+	if explain, _ := query[port.QueryExplainKey].(bool); explain {
+		return true
+	}
+	// 随机抽样每次的结果都应不同
+	if _, sample := query[port.QuerySampleKey]; sample {
+		return true
+	}
+	table, _ := query["table"].(string)
+	if _, ok := c.bypass[table]; ok {
+		return true
+	}
+	_, ok := c.bypass[bizName+"."+table]
+	return ok
+}
+
+// Decorator 为单个业务组的 DataSource 提供读穿透缓存。
+// 缓存命中时返回的结果对象会在多个请求之间共享，调用方不得修改。
+type Decorator struct {
+	cache   *Cache
+	bizName string
+	inner   port.DataSource
+}
+
+var _ port.DataSource = (*Decorator)(nil)
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *Decorator) Unwrap() port.DataSource {
+	return d.inner
+}
+
+func (d *Decorator) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	if d.cache.shouldBypass(req.BizName, req.Query) {
+		aegobserve.QueryCacheRequests.WithLabelValues(d.bizName, "bypass").Inc()
+		return d.inner.Query(ctx, req)
+	}
+
+	key, ok := cacheKey(req)
+	if !ok {
+		return d.inner.Query(ctx, req)
+	}
+
+	if result, ok := d.cache.store.Get(key); ok {
+		aegobserve.QueryCacheRequests.WithLabelValues(d.bizName, "hit").Inc()
+		return result, nil
+	}
+	aegobserve.QueryCacheRequests.WithLabelValues(d.bizName, "miss").Inc()
+
+	v, err, _ := d.cache.group.Do(key, func() (interface{}, error) {
+		// 共享的下游请求不应因首个调用方断开而对其他等待者失败
+		result, err := d.inner.Query(context.WithoutCancel(ctx), req)
+		if err != nil {
+			return nil, err
+		}
+		// 部分库失败的结果只反映一时的故障，不缓存，下一次请求重新查询
+		if _, partial := result.Data[port.ResultWarningsKey]; !partial {
+			d.cache.store.Add(key, result)
+			if d.cache.ServesStale(req.BizName) {
+				d.cache.stale.Add(key, staleEntry{result: result, cachedAt: time.Now()})
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		if stale, ok := d.staleOnFailure(ctx, req, err); ok {
+			return stale, nil
+		}
+		return nil, err
+	}
+	return v.(*port.QueryResult), nil
+}
+
+// staleOnFailure 在查询失败且数据源健康检查也失败时返回旧结果。数据源健康时的失败 (如查询本身无效) 照常返回错误
+func (d *Decorator) staleOnFailure(ctx context.Context, req port.QueryRequest, queryErr error) (*port.QueryResult, bool) {
+	if !d.cache.ServesStale(req.BizName) {
+		return nil, false
+	}
+	stale, ok := d.cache.Stale(req)
+	if !ok {
+		return nil, false
+	}
+	if err := d.inner.HealthCheck(ctx); err == nil {
+		return nil, false
+	}
+	aegobserve.QueryCacheRequests.WithLabelValues(d.bizName, "stale").Inc()
+	slog.Warn("[QueryCache] 数据源不可用，返回缓存中的旧结果", "biz", req.BizName, "cached_at", stale.Data[port.ResultCachedAtKey], "error", queryErr)
+	return stale, true
+}
+
+// Mutate 直接透传，成功后清除该业务组的缓存以避免读到旧数据
+func (d *Decorator) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	result, err := d.inner.Mutate(ctx, req)
+	if err == nil {
+		d.cache.Purge(req.BizName)
+	}
+	return result, err
+}
+
+// AdminMutate 转发管理操作，成功后同样清空业务组的缓存。只读取上游缓存计数的操作不改变数据，不清空
+func (d *Decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	result, err := port.AdminMutate(ctx, d.inner, req)
+	if err == nil && !(req.Operation == port.MutateOpUpstreamCache && req.Payload["step"] == "stats") {
+		d.cache.Purge(req.BizName)
+	}
+	return result, err
+}
+
+func (d *Decorator) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	return d.inner.GetSchema(ctx, req)
+}
+
+func (d *Decorator) HealthCheck(ctx context.Context) error {
+	return d.inner.HealthCheck(ctx)
+}
+
+func (d *Decorator) Type() string {
+	return d.inner.Type()
+}
+
+// staleOnly 是业务组暂时没有可用数据源时的替身，只以旧结果回答查询
+type staleOnly struct {
+	cache   *Cache
+	bizName string
+}
+
+var _ port.DataSource = (*staleOnly)(nil)
+
+func (s *staleOnly) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	if stale, ok := s.cache.Stale(req); ok {
+		aegobserve.QueryCacheRequests.WithLabelValues(s.bizName, "stale").Inc()
+		slog.Warn("[QueryCache] 业务组暂无可用数据源，返回缓存中的旧结果", "biz", req.BizName, "cached_at", stale.Data[port.ResultCachedAtKey])
+		return stale, nil
+	}
+	return nil, s.unavailable()
+}
+
+func (s *staleOnly) Mutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	return nil, s.unavailable()
+}
+
+func (s *staleOnly) AdminMutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	return nil, s.unavailable()
+}
+
+func (s *staleOnly) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return nil, s.unavailable()
+}
+
+func (s *staleOnly) HealthCheck(context.Context) error {
+	return s.unavailable()
+}
+
+func (s *staleOnly) Type() string {
+	return "stale-cache"
+}
+
+func (s *staleOnly) unavailable() error {
+	return fmt.Errorf("%w: 业务组 '%s' 暂无可用的数据源", port.ErrBizNotFound, s.bizName)
+}
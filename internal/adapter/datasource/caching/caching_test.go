@@ -0,0 +1,228 @@
+// file: internal/adapter/datasource/caching/caching_test.go
+package caching
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingDataSource 记录 Query 被真正调用的次数
+type countingDataSource struct {
+	calls atomic.Int32
+	delay time.Duration
+	// partial 为 true 时返回带 port.ResultWarningsKey 的部分成功结果
+	partial bool
+	// queryErr 与 healthErr 不为 nil 时模拟数据源不可用
+	queryErr  error
+	healthErr error
+}
+
+func (d *countingDataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	d.calls.Add(1)
+	time.Sleep(d.delay)
+	if d.queryErr != nil {
+		return nil, d.queryErr
+	}
+	data := map[string]interface{}{"total": 1}
+	if d.partial {
+		data[port.ResultWarningsKey] = []interface{}{map[string]interface{}{"lib": "b", "error": "database is locked"}}
+	}
+	return &port.QueryResult{Data: data, Source: "fake"}, nil
+}
+func (d *countingDataSource) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return &port.MutateResult{}, nil
+}
+func (d *countingDataSource) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	return &port.SchemaResult{}, nil
+}
+func (d *countingDataSource) HealthCheck(ctx context.Context) error { return d.healthErr }
+func (d *countingDataSource) Type() string                          { return "fake" }
+
+func query(table string) port.QueryRequest {
+	return port.QueryRequest{BizName: "biz", Query: map[string]interface{}{"table": table, "page": float64(1)}}
+}
+
+func TestDecorator_CachesAndPurges(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingDataSource{}
+	cache := New(Options{TTL: time.Minute})
+	ds := cache.Wrap("biz", inner)
+
+	for i := 0; i < 3; i++ {
+		_, err := ds.Query(ctx, query("letters"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), inner.calls.Load(), "相同查询应只到达下游一次")
+
+	assert.Equal(t, 1, cache.Purge("biz"))
+	_, _ = ds.Query(ctx, query("letters"))
+	assert.Equal(t, int32(2), inner.calls.Load())
+
+	// 写操作成功后缓存应失效
+	_, err := ds.Mutate(ctx, port.MutateRequest{BizName: "biz"})
+	require.NoError(t, err)
+	_, _ = ds.Query(ctx, query("letters"))
+	assert.Equal(t, int32(3), inner.calls.Load())
+
+	// 扩展选项不同的查询分别缓存
+	hinted := query("letters")
+	hinted.Extensions = map[string]interface{}{"hint": "idx_sender"}
+	_, _ = ds.Query(ctx, hinted)
+	_, _ = ds.Query(ctx, hinted)
+	assert.Equal(t, int32(4), inner.calls.Load())
+}
+
+func TestDecorator_Bypass(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingDataSource{}
+	ds := New(Options{BypassTables: []string{"biz.live"}}).Wrap("biz", inner)
+
+	_, _ = ds.Query(ctx, query("live"))
+	_, _ = ds.Query(ctx, query("live"))
+	assert.Equal(t, int32(2), inner.calls.Load(), "绕过列表中的表不应被缓存")
+
+	changes := port.QueryRequest{BizName: "biz", Query: map[string]interface{}{port.QueryModeKey: port.QueryModeChanges}}
+	_, _ = ds.Query(ctx, changes)
+	_, _ = ds.Query(ctx, changes)
+	assert.Equal(t, int32(4), inner.calls.Load(), "非默认查询模式不应被缓存")
+
+	explain := query("main")
+	explain.Query[port.QueryExplainKey] = true
+	_, _ = ds.Query(ctx, explain)
+	_, _ = ds.Query(ctx, explain)
+	assert.Equal(t, int32(6), inner.calls.Load(), "explain 查询不应被缓存")
+
+	sample := query("main")
+	sample.Query[port.QuerySampleKey] = float64(5)
+	_, _ = ds.Query(ctx, sample)
+	_, _ = ds.Query(ctx, sample)
+	assert.Equal(t, int32(8), inner.calls.Load(), "随机抽样不应被缓存")
+}
+
+func TestDecorator_SkipsPartialResults(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingDataSource{partial: true}
+	ds := New(Options{TTL: time.Minute}).Wrap("biz", inner)
+
+	for i := 0; i < 2; i++ {
+		result, err := ds.Query(ctx, query("letters"))
+		require.NoError(t, err)
+		assert.Contains(t, result.Data, port.ResultWarningsKey)
+	}
+	assert.Equal(t, int32(2), inner.calls.Load(), "部分库失败的结果不应被缓存")
+
+	inner.partial = false
+	_, _ = ds.Query(ctx, query("letters"))
+	_, _ = ds.Query(ctx, query("letters"))
+	assert.Equal(t, int32(3), inner.calls.Load(), "恢复后的完整结果照常缓存")
+}
+
+func TestDecorator_StampedeProtection(t *testing.T) {
+	inner := &countingDataSource{delay: 50 * time.Millisecond}
+	ds := New(Options{}).Wrap("biz", inner)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ds.Query(context.Background(), query("letters"))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), inner.calls.Load(), "并发的相同查询应合并为一次下游请求")
+}
+
+func TestDecorator_ServesStaleWhenUnavailable(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingDataSource{}
+	cache := New(Options{TTL: time.Millisecond, ServeStale: []string{"biz"}})
+	ds := cache.Wrap("biz", inner)
+
+	fresh, err := ds.Query(ctx, query("letters"))
+	require.NoError(t, err)
+	assert.NotContains(t, fresh.Data, port.ResultStaleKey)
+	time.Sleep(5 * time.Millisecond) // 普通缓存已过期
+
+	// 数据源仍健康时的失败 (如查询无效) 照常返回错误
+	inner.queryErr = errors.New("无效请求")
+	_, err = ds.Query(ctx, query("letters"))
+	assert.Error(t, err)
+
+	// 数据源不可用时返回旧结果，原缓存结果不被修改
+	inner.queryErr = errors.New("connection refused")
+	inner.healthErr = errors.New("插件已退出")
+	stale, err := ds.Query(ctx, query("letters"))
+	require.NoError(t, err)
+	assert.Equal(t, true, stale.Data[port.ResultStaleKey])
+	assert.NotEmpty(t, stale.Data[port.ResultCachedAtKey])
+	assert.Equal(t, 1, stale.Data["total"])
+	assert.NotContains(t, fresh.Data, port.ResultStaleKey)
+
+	// 没有执行过的查询没有旧结果
+	_, err = ds.Query(ctx, query("persons"))
+	assert.Error(t, err)
+
+	// 插件注销期间由 StaleOnly 回答
+	only := cache.StaleOnly("biz")
+	result, err := only.Query(ctx, query("letters"))
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Data[port.ResultStaleKey])
+	_, err = only.Query(ctx, query("persons"))
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+
+	// 数据变更后旧结果失效
+	cache.Purge("biz")
+	_, err = ds.Query(ctx, query("letters"))
+	assert.Error(t, err)
+}
+
+func TestDecorator_StaleIsPerBiz(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingDataSource{}
+	cache := New(Options{TTL: time.Millisecond, ServeStale: []string{"other"}})
+	ds := cache.Wrap("biz", inner)
+	assert.False(t, cache.ServesStale("biz"))
+	assert.True(t, cache.ServesStale("other"))
+	assert.False(t, New(Options{}).ServesStale("biz"))
+	assert.True(t, New(Options{ServeStale: []string{"*"}}).ServesStale("biz"))
+
+	_, err := ds.Query(ctx, query("letters"))
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	inner.queryErr = errors.New("connection refused")
+	inner.healthErr = errors.New("插件已退出")
+	_, err = ds.Query(ctx, query("letters"))
+	assert.Error(t, err, "未启用旧结果降级的业务组应返回错误")
+}
+
+func TestCache_Stats(t *testing.T) {
+	ctx := context.Background()
+	cache := New(Options{TTL: time.Minute, ServeStale: []string{"biz", "idle"}})
+	ds := cache.Wrap("biz", &countingDataSource{})
+	_, err := ds.Query(ctx, query("letters"))
+	require.NoError(t, err)
+	_, err = ds.Query(ctx, query("persons"))
+	require.NoError(t, err)
+	_, err = cache.Wrap("other", &countingDataSource{}).Query(ctx, port.QueryRequest{BizName: "other", Query: map[string]interface{}{"table": "t"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.QueryCacheBizState{
+		{BizName: "biz", Entries: 2, StaleEntries: 2, ServesStale: true},
+		{BizName: "idle", ServesStale: true},
+		{BizName: "other", Entries: 1},
+	}, cache.Stats())
+
+	cache.Purge("biz")
+	assert.Equal(t, domain.QueryCacheBizState{BizName: "biz", ServesStale: true}, cache.Stats()[0], "清除后仍列出启用旧结果降级的业务组")
+}
@@ -0,0 +1,88 @@
+// Package coalescing file: internal/adapter/datasource/coalescing/coalescing.go
+package coalescing
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Group 合并相同的并发查询: 同一时刻只有一个请求真正到达下游，其余等待者共享它的结果。
+// 与查询缓存不同，结果不会被保留，请求完成后下一次相同的查询照常到达下游。
+type Group struct {
+	flight singleflight.Group
+}
+
+// New 创建一个跨业务组共享的查询合并器
+func New() *Group {
+	return &Group{}
+}
+
+// Wrap 返回一个合并相同并发查询的 DataSource 装饰器
+func (g *Group) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	return &decorator{DataSource: inner, group: g, bizName: bizName}
+}
+
+// queryKey 由业务组名、规范化后的查询与扩展选项生成合并键。encoding/json 会对 map 的键排序，因此等价的查询得到相同的键
+func queryKey(bizName string, query, extensions map[string]interface{}) (string, bool) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return "", false
+	}
+	if len(extensions) > 0 {
+		ext, err := json.Marshal(extensions)
+		if err != nil {
+			return "", false
+		}
+		body = append(append(body, 0), ext...)
+	}
+	sum := sha256.Sum256(append([]byte(bizName+"\x00"), body...))
+	return hex.EncodeToString(sum[:]), true
+}
+
+type decorator struct {
+	port.DataSource
+	group   *Group
+	bizName string
+}
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *decorator) Unwrap() port.DataSource {
+	return d.DataSource
+}
+
+// AdminMutate 直接转发管理操作
+func (d *decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return port.AdminMutate(ctx, d.DataSource, req)
+}
+
+// Query 合并相同的并发查询。共享的结果对象在多个请求之间共享，调用方不得修改
+func (d *decorator) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	// 随机抽样每次的结果都应不同
+	if _, sample := req.Query[port.QuerySampleKey]; sample {
+		return d.DataSource.Query(ctx, req)
+	}
+	key, ok := queryKey(req.BizName, req.Query, req.Extensions)
+	if !ok {
+		return d.DataSource.Query(ctx, req)
+	}
+
+	leader := false
+	v, err, shared := d.group.flight.Do(key, func() (interface{}, error) {
+		leader = true
+		// 共享的下游请求不应因首个调用方断开而对其他等待者失败
+		return d.DataSource.Query(context.WithoutCancel(ctx), req)
+	})
+	if shared && !leader {
+		aegobserve.CoalescedQueries.WithLabelValues(d.bizName).Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*port.QueryResult), nil
+}
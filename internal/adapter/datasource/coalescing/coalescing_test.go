@@ -0,0 +1,116 @@
+// file: internal/adapter/datasource/coalescing/coalescing_test.go
+package coalescing
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowDataSource 记录 Query 被真正调用的次数，每次调用耗时 delay
+type slowDataSource struct {
+	port.DataSource
+	calls atomic.Int32
+	delay time.Duration
+}
+
+func (d *slowDataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	d.calls.Add(1)
+	time.Sleep(d.delay)
+	return &port.QueryResult{Data: map[string]interface{}{"total": 1}, Source: "fake"}, nil
+}
+
+func queryFor(bizName string, extra map[string]interface{}) port.QueryRequest {
+	q := map[string]interface{}{"table": "letters", "page": float64(1)}
+	for k, v := range extra {
+		q[k] = v
+	}
+	return port.QueryRequest{BizName: bizName, Query: q}
+}
+
+// queryConcurrently 同时发出 n 个相同的查询，并返回结果对象
+func queryConcurrently(t *testing.T, ds port.DataSource, req port.QueryRequest, n int) []*port.QueryResult {
+	t.Helper()
+	results := make([]*port.QueryResult, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := ds.Query(context.Background(), req)
+			assert.NoError(t, err)
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+func TestDecorator_CoalescesConcurrentQueries(t *testing.T) {
+	inner := &slowDataSource{delay: 100 * time.Millisecond}
+	ds := New().Wrap("coalesce", inner)
+	before := testutil.ToFloat64(aegobserve.CoalescedQueries.WithLabelValues("coalesce"))
+
+	results := queryConcurrently(t, ds, queryFor("coalesce", nil), 5)
+	assert.Equal(t, int32(1), inner.calls.Load(), "相同的并发查询应只到达下游一次")
+	for _, r := range results {
+		require.NotNil(t, r)
+		assert.Same(t, results[0], r, "等待者应共享同一个结果")
+	}
+	assert.Equal(t, float64(4), testutil.ToFloat64(aegobserve.CoalescedQueries.WithLabelValues("coalesce"))-before)
+
+	// 结果不被保留，之后的相同查询重新到达下游
+	_, err := ds.Query(context.Background(), queryFor("coalesce", nil))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), inner.calls.Load())
+}
+
+func TestDecorator_DoesNotCoalesceDifferentQueries(t *testing.T) {
+	inner := &slowDataSource{delay: 50 * time.Millisecond}
+	group := New()
+	a, b := group.Wrap("a", inner), group.Wrap("b", inner)
+
+	var wg sync.WaitGroup
+	for _, run := range []func(){
+		func() { _, _ = a.Query(context.Background(), queryFor("a", nil)) },
+		func() { _, _ = b.Query(context.Background(), queryFor("b", nil)) },
+		func() {
+			_, _ = a.Query(context.Background(), queryFor("a", map[string]interface{}{"page": float64(2)}))
+		},
+	} {
+		wg.Add(1)
+		go func(run func()) {
+			defer wg.Done()
+			run()
+		}(run)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), inner.calls.Load(), "不同业务组或不同条件的查询不应合并")
+}
+
+func TestDecorator_SkipsSampleQueries(t *testing.T) {
+	inner := &slowDataSource{delay: 50 * time.Millisecond}
+	ds := New().Wrap("sample", inner)
+
+	queryConcurrently(t, ds, queryFor("sample", map[string]interface{}{port.QuerySampleKey: float64(5)}), 3)
+	assert.Equal(t, int32(3), inner.calls.Load(), "随机抽样每次的结果都应不同，不合并")
+}
+
+func TestQueryKey_IgnoresKeyOrder(t *testing.T) {
+	k1, ok := queryKey("biz", map[string]interface{}{"table": "letters", "page": 1}, nil)
+	require.True(t, ok)
+	k2, _ := queryKey("biz", map[string]interface{}{"page": 1, "table": "letters"}, nil)
+	k3, _ := queryKey("other", map[string]interface{}{"page": 1, "table": "letters"}, nil)
+	assert.Equal(t, k1, k2)
+	assert.NotEqual(t, k1, k3)
+	k4, _ := queryKey("biz", map[string]interface{}{"table": "letters", "page": 1}, map[string]interface{}{"hint": "idx_sender"})
+	assert.NotEqual(t, k1, k4, "扩展选项不同的查询不应合并")
+}
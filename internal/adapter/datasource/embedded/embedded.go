@@ -0,0 +1,110 @@
+// Package embedded file: internal/adapter/datasource/embedded/embedded.go
+package embedded
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// 编译期断言，确保 DataSource 实现了 port.DataSource 接口
+var _ port.DataSource = (*DataSource)(nil)
+
+// DataSource 在网关进程内直接调用插件的数据源实现（不经过 gRPC，也没有子进程）。
+// 请求与结果仍按 gRPC 传输的方式经 structpb 转换一次，
+// 使嵌入式实例与独立进程实例的数据形态保持一致（例如数字统一为 float64）。
+type DataSource struct {
+	inner  port.DataSource
+	closer io.Closer
+}
+
+// New 包装一个进程内的数据源。closer 可为 nil。
+func New(inner port.DataSource, closer io.Closer) *DataSource {
+	return &DataSource{inner: inner, closer: closer}
+}
+
+// Type 返回适配器的类型标识符
+func (d *DataSource) Type() string {
+	return "embedded_plugin"
+}
+
+// Query 按 gRPC 的数据形态转发查询
+func (d *DataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	query, err := wireMap(req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("转换查询请求失败: %w", err)
+	}
+	var extensions map[string]interface{}
+	if len(req.Extensions) > 0 {
+		if extensions, err = wireMap(req.Extensions); err != nil {
+			return nil, fmt.Errorf("转换查询扩展选项失败: %w", err)
+		}
+	}
+	result, err := d.inner.Query(ctx, port.QueryRequest{BizName: req.BizName, Query: query, Extensions: extensions})
+	if err != nil {
+		return nil, err
+	}
+	data, err := wireMap(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化查询结果失败: %w", err)
+	}
+	return &port.QueryResult{Data: data, Source: result.Source}, nil
+}
+
+// Mutate 按 gRPC 的数据形态转发写操作
+func (d *DataSource) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return d.mutate(ctx, req, d.inner.Mutate)
+}
+
+// AdminMutate 与 Mutate 一样转换 payload 与结果后转发管理操作
+func (d *DataSource) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return d.mutate(ctx, req, func(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+		return port.AdminMutate(ctx, d.inner, req)
+	})
+}
+
+func (d *DataSource) mutate(ctx context.Context, req port.MutateRequest, call func(context.Context, port.MutateRequest) (*port.MutateResult, error)) (*port.MutateResult, error) {
+	payload, err := wireMap(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("转换 Mutate payload 失败: %w", err)
+	}
+	result, err := call(ctx, port.MutateRequest{BizName: req.BizName, Operation: req.Operation, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	data, err := wireMap(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化写操作结果失败: %w", err)
+	}
+	return &port.MutateResult{Data: data, Source: result.Source}, nil
+}
+
+// GetSchema 直接转发，结构信息本身不涉及动态类型
+func (d *DataSource) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	return d.inner.GetSchema(ctx, req)
+}
+
+// HealthCheck 直接转发
+func (d *DataSource) HealthCheck(ctx context.Context) error {
+	return d.inner.HealthCheck(ctx)
+}
+
+// Close 释放底层数据源持有的资源
+func (d *DataSource) Close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	return nil
+}
+
+// wireMap 让 map 经过一次 structpb 往返，得到与 gRPC 传输后相同的值类型
+func wireMap(m map[string]interface{}) (map[string]interface{}, error) {
+	s, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil, err
+	}
+	return s.AsMap(), nil
+}
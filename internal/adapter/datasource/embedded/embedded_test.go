@@ -0,0 +1,78 @@
+// file: internal/adapter/datasource/embedded/embedded_test.go
+package embedded
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubDataSource struct {
+	lastQuery  map[string]interface{}
+	lastMutate map[string]interface{}
+	queryErr   error
+	closed     bool
+}
+
+func (s *stubDataSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	s.lastQuery = req.Query
+	if s.queryErr != nil {
+		return nil, s.queryErr
+	}
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": int64(7), "name": "x"}},
+			"total": int64(1),
+		},
+		Source: "stub",
+	}, nil
+}
+
+func (s *stubDataSource) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	s.lastMutate = req.Payload
+	return &port.MutateResult{Data: map[string]interface{}{"rows_affected": 1}, Source: "stub"}, nil
+}
+
+func (s *stubDataSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return &port.SchemaResult{}, nil
+}
+func (s *stubDataSource) HealthCheck(context.Context) error { return nil }
+func (s *stubDataSource) Type() string                      { return "stub" }
+func (s *stubDataSource) Close() error                      { s.closed = true; return nil }
+
+func TestDataSource_WireEquivalence(t *testing.T) {
+	ctx := context.Background()
+	inner := &stubDataSource{}
+	ds := New(inner, inner)
+
+	res, err := ds.Query(ctx, port.QueryRequest{BizName: "b", Query: map[string]interface{}{"table": "t", "page": 2}})
+	require.NoError(t, err)
+	// 请求与结果中的数字与 gRPC 传输后一致，均为 float64
+	assert.Equal(t, float64(2), inner.lastQuery["page"])
+	assert.Equal(t, float64(1), res.Data["total"])
+	item := res.Data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(7), item["id"])
+	assert.Equal(t, "stub", res.Source)
+
+	mres, err := ds.Mutate(ctx, port.MutateRequest{BizName: "b", Operation: "create", Payload: map[string]interface{}{"data": map[string]interface{}{"n": 3}}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), inner.lastMutate["data"].(map[string]interface{})["n"])
+	assert.Equal(t, float64(1), mres.Data["rows_affected"])
+
+	require.NoError(t, ds.Close())
+	assert.True(t, inner.closed)
+}
+
+func TestDataSource_ErrorsPassThrough(t *testing.T) {
+	inner := &stubDataSource{queryErr: port.ErrPermissionDenied}
+	_, err := New(inner, nil).Query(context.Background(), port.QueryRequest{Query: map[string]interface{}{}})
+	assert.True(t, errors.Is(err, port.ErrPermissionDenied))
+
+	// 无法按 gRPC 方式序列化的值应被拒绝，而不是在嵌入式模式下悄悄放行
+	_, err = New(&stubDataSource{}, nil).Query(context.Background(), port.QueryRequest{Query: map[string]interface{}{"bad": struct{}{}}})
+	assert.Error(t, err)
+}
@@ -7,35 +7,83 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-// 编译期断言，确保 ClientAdapter 实现了 port.DataSource 接口
-var _ port.DataSource = (*ClientAdapter)(nil)
+// 编译期断言，确保 ClientAdapter 实现了 port.DataSource 接口、语义检索扩展与管理操作扩展
+var (
+	_ port.DataSource     = (*ClientAdapter)(nil)
+	_ port.VectorSearcher = (*ClientAdapter)(nil)
+	_ port.AdminMutator   = (*ClientAdapter)(nil)
+)
+
+// ConfigVersionMetadataKey 是网关调用插件时携带配置版本的 gRPC 元数据键。
+// 插件各自缓存业务组配置，发现版本与上次不同时应丢弃缓存，使管理员的修改立即生效
+const ConfigVersionMetadataKey = "x-aegis-config-version"
 
 // ClientAdapter 是一个适配器，它实现了port.DataSource接口，
 // 但将其所有调用都转发给一个远程的gRPC插件。
 type ClientAdapter struct {
 	client datasourcev1.DataSourceClient
 	conn   *grpc.ClientConn
+	// configVersion 返回网关当前的配置版本，为 nil 时不携带版本
+	configVersion func() string
+	// errorObserver 在每次调用返回错误 (调用方取消的除外) 时被调用，为 nil 时不通知
+	errorObserver ErrorObserver
 }
 
+// ErrorObserver 接收网关调用插件时的 gRPC 错误，method 为完整的方法名
+type ErrorObserver func(method string, err error)
+
 // New 创建一个新的gRPC客户端适配器实例。
 func New(pluginAddress string) (*ClientAdapter, error) {
+	a := &ClientAdapter{}
 	// 创建一个不安全的gRPC连接（本地开发用），未来可增加TLS
-	conn, err := grpc.NewClient(pluginAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(pluginAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(a.observeErrors),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("无法连接到gRPC插件 at %s: %w", pluginAddress, err)
 	}
 
-	client := datasourcev1.NewDataSourceClient(conn)
-	return &ClientAdapter{
-		client: client,
-		conn:   conn,
-	}, nil
+	a.client = datasourcev1.NewDataSourceClient(conn)
+	a.conn = conn
+	return a, nil
+}
+
+// SetConfigVersionSource 设置配置版本的来源，此后转发给插件的请求都携带当前的配置版本
+func (a *ClientAdapter) SetConfigVersionSource(version func() string) {
+	a.configVersion = version
+}
+
+// SetErrorObserver 设置 gRPC 错误的接收者，应在发起任何调用之前设置
+func (a *ClientAdapter) SetErrorObserver(observer ErrorObserver) {
+	a.errorObserver = observer
+}
+
+// observeErrors 是转发调用的拦截器，把调用错误通知给 errorObserver
+func (a *ClientAdapter) observeErrors(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil && a.errorObserver != nil && status.Code(err) != codes.Canceled {
+		a.errorObserver(method, err)
+	}
+	return err
+}
+
+// withConfigVersion 在请求的元数据中附加当前的配置版本
+func (a *ClientAdapter) withConfigVersion(ctx context.Context) context.Context {
+	if a.configVersion == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, ConfigVersionMetadataKey, a.configVersion())
 }
 
 // GetPluginInfo 方法，用于调用插件的自我介绍接口
@@ -58,9 +106,14 @@ func (a *ClientAdapter) Query(ctx context.Context, req port.QueryRequest) (*port
 		BizName: req.BizName,
 		Query:   queryStruct,
 	}
+	if len(req.Extensions) > 0 {
+		if grpcReq.Extensions, err = structpb.NewStruct(req.Extensions); err != nil {
+			return nil, fmt.Errorf("创建 gRPC extensions struct 失败: %w", err)
+		}
+	}
 
 	// 发起RPC调用
-	grpcRes, err := a.client.Query(ctx, grpcReq)
+	grpcRes, err := a.client.Query(a.withConfigVersion(ctx), grpcReq)
 	if err != nil {
 		return nil, fmt.Errorf("gRPC Query 调用失败: %w", err)
 	}
@@ -78,21 +131,36 @@ func (a *ClientAdapter) Query(ctx context.Context, req port.QueryRequest) (*port
 func (a *ClientAdapter) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
 	slog.Debug("gRPC适配器: 正在将 Mutate 请求转发到插件", "biz", req.BizName, "operation", req.Operation)
 
-	// 将 Go 的 map[string]interface{} 转换为 gRPC 的 Struct
-	payloadStruct, err := structpb.NewStruct(req.Payload)
+	grpcReq, err := mutateRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("转换 Mutate payload 失败: %w", err)
+		return nil, err
 	}
-
-	grpcReq := &datasourcev1.MutateRequest{
-		BizName:   req.BizName,
-		Operation: req.Operation,
-		Payload:   payloadStruct,
+	grpcRes, err := a.client.Mutate(a.withConfigVersion(ctx), grpcReq)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC Mutate 调用失败: %w", err)
 	}
 
-	grpcRes, err := a.client.Mutate(ctx, grpcReq)
+	// 将 gRPC 的 Struct 响应转换为 Go 的 map[string]interface{}
+	return &port.MutateResult{
+		Data:   grpcRes.GetData().AsMap(),
+		Source: grpcRes.GetSource(),
+	}, nil
+}
+
+// AdminMutate 转发管理操作，插件未实现该扩展时返回 port.ErrAdminMutateUnsupported
+func (a *ClientAdapter) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	slog.Debug("gRPC适配器: 正在将 AdminMutate 请求转发到插件", "biz", req.BizName, "operation", req.Operation)
+
+	grpcReq, err := mutateRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("gRPC Mutate 调用失败: %w", err)
+		return nil, err
+	}
+	grpcRes, err := a.client.AdminMutate(a.withConfigVersion(ctx), grpcReq)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, port.ErrAdminMutateUnsupported
+		}
+		return nil, fmt.Errorf("gRPC AdminMutate 调用失败: %w", err)
 	}
 
 	// 将 gRPC 的 Struct 响应转换为 Go 的 map[string]interface{}
@@ -102,6 +170,19 @@ func (a *ClientAdapter) Mutate(ctx context.Context, req port.MutateRequest) (*po
 	}, nil
 }
 
+// mutateRequest 把写操作请求转换为 gRPC 消息，payload 转换为 gRPC 的 Struct
+func mutateRequest(req port.MutateRequest) (*datasourcev1.MutateRequest, error) {
+	payloadStruct, err := structpb.NewStruct(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("转换 Mutate payload 失败: %w", err)
+	}
+	return &datasourcev1.MutateRequest{
+		BizName:   req.BizName,
+		Operation: req.Operation,
+		Payload:   payloadStruct,
+	}, nil
+}
+
 // GetSchema 方法的实现保持不变
 func (a *ClientAdapter) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
 	slog.Debug("gRPC适配器: 正在将 GetSchema 请求转发到插件", "biz", req.BizName)
@@ -111,28 +192,35 @@ func (a *ClientAdapter) GetSchema(ctx context.Context, req port.SchemaRequest) (
 		TableName: req.TableName,
 	}
 
-	grpcRes, err := a.client.GetSchema(ctx, grpcReq)
+	grpcRes, err := a.client.GetSchema(a.withConfigVersion(ctx), grpcReq)
 	if err != nil {
 		return nil, fmt.Errorf("gRPC GetSchema 调用失败: %w", err)
 	}
 
 	goTables := make(map[string][]port.FieldDescription)
+	recordKeys := make(map[string][]string)
 	for tableName, tableSchema := range grpcRes.GetTables() {
+		// 旧版插件不报告记录标识，此时由 SchemaResult.RecordKey 按 IsPrimary 推断
+		if key := tableSchema.GetRecordKey(); len(key) > 0 {
+			recordKeys[tableName] = key
+		}
 		var goFields []port.FieldDescription
 		for _, field := range tableSchema.GetFields() {
 			goFields = append(goFields, port.FieldDescription{
-				Name:         field.GetName(),
-				DataType:     field.GetDataType(),
-				IsSearchable: field.GetIsSearchable(),
-				IsReturnable: field.GetIsReturnable(),
-				IsPrimary:    field.GetIsPrimary(),
-				Description:  field.GetDescription(),
+				Name:              field.GetName(),
+				DataType:          field.GetDataType(),
+				IsSearchable:      field.GetIsSearchable(),
+				IsReturnable:      field.GetIsReturnable(),
+				IsPrimary:         field.GetIsPrimary(),
+				Description:       field.GetDescription(),
+				PinyinSearchable:  field.GetPinyinSearchable(),
+				FullTextTokenizer: field.GetFullTextTokenizer(),
 			})
 		}
 		goTables[tableName] = goFields
 	}
 
-	return &port.SchemaResult{Tables: goTables}, nil
+	return &port.SchemaResult{Tables: goTables, RecordKeys: recordKeys}, nil
 }
 
 // HealthCheck 方法的实现保持不变
@@ -151,6 +239,97 @@ func (a *ClientAdapter) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// Shutdown 请求插件优雅退出。插件接受请求后会自行清理并结束进程，调用方仍需等待进程退出。
+func (a *ClientAdapter) Shutdown(ctx context.Context, reason string, gracePeriod time.Duration) error {
+	slog.Debug("gRPC适配器: 正在向插件发送 Shutdown 请求...", "reason", reason)
+
+	res, err := a.client.Shutdown(ctx, &datasourcev1.ShutdownRequest{
+		Reason:        reason,
+		GracePeriodMs: gracePeriod.Milliseconds(),
+	})
+	if err != nil {
+		return fmt.Errorf("gRPC Shutdown 调用失败: %w", err)
+	}
+	if !res.GetAccepted() {
+		return fmt.Errorf("插件拒绝了关闭请求")
+	}
+	return nil
+}
+
+// ScanEmbeddingSources 转发语义检索扩展的文本读取请求，插件未实现该扩展时返回 port.ErrVectorSearchUnsupported
+func (a *ClientAdapter) ScanEmbeddingSources(ctx context.Context, req port.EmbeddingSourceRequest) (*port.EmbeddingSourcePage, error) {
+	res, err := a.client.ScanEmbeddingSources(a.withConfigVersion(ctx), &datasourcev1.ScanEmbeddingSourcesRequest{
+		BizName:    req.BizName,
+		TableName:  req.TableName,
+		Fields:     req.Fields,
+		Lib:        req.Lib,
+		AfterRowid: req.AfterRowID,
+		Limit:      int32(req.Limit),
+	})
+	if err != nil {
+		return nil, vectorCallError("ScanEmbeddingSources", err)
+	}
+	page := &port.EmbeddingSourcePage{
+		Sources:   make([]port.EmbeddingSource, 0, len(res.GetSources())),
+		NextLib:   res.GetNextLib(),
+		NextRowID: res.GetNextRowid(),
+		Done:      res.GetDone(),
+	}
+	for _, src := range res.GetSources() {
+		page.Sources = append(page.Sources, port.EmbeddingSource{Lib: src.GetLib(), RecordID: src.GetRecordId(), Text: src.GetText()})
+	}
+	return page, nil
+}
+
+// UpsertEmbeddings 转发向量写入请求，插件未实现该扩展时返回 port.ErrVectorSearchUnsupported
+func (a *ClientAdapter) UpsertEmbeddings(ctx context.Context, req port.UpsertEmbeddingsRequest) (*port.UpsertEmbeddingsResult, error) {
+	embeddings := make([]*datasourcev1.Embedding, len(req.Embeddings))
+	for i, e := range req.Embeddings {
+		embeddings[i] = &datasourcev1.Embedding{Lib: e.Lib, RecordId: e.RecordID, Vector: e.Vector}
+	}
+	res, err := a.client.UpsertEmbeddings(a.withConfigVersion(ctx), &datasourcev1.UpsertEmbeddingsRequest{
+		BizName:     req.BizName,
+		TableName:   req.TableName,
+		Model:       req.Model,
+		Generation:  req.Generation,
+		Embeddings:  embeddings,
+		PruneBefore: req.PruneBefore,
+		DropAll:     req.DropAll,
+	})
+	if err != nil {
+		return nil, vectorCallError("UpsertEmbeddings", err)
+	}
+	return &port.UpsertEmbeddingsResult{Upserted: res.GetUpserted(), Deleted: res.GetDeleted()}, nil
+}
+
+// SimilaritySearch 转发相似度检索请求，插件未实现该扩展时返回 port.ErrVectorSearchUnsupported
+func (a *ClientAdapter) SimilaritySearch(ctx context.Context, req port.SimilaritySearchRequest) (*port.SimilaritySearchResult, error) {
+	res, err := a.client.SimilaritySearch(a.withConfigVersion(ctx), &datasourcev1.SimilaritySearchRequest{
+		BizName:   req.BizName,
+		TableName: req.TableName,
+		Model:     req.Model,
+		Vector:    req.Vector,
+		TopK:      int32(req.TopK),
+		MinScore:  req.MinScore,
+	})
+	if err != nil {
+		return nil, vectorCallError("SimilaritySearch", err)
+	}
+	result := &port.SimilaritySearchResult{Matches: make([]port.SimilarityMatch, 0, len(res.GetMatches())), Source: res.GetSource()}
+	for _, m := range res.GetMatches() {
+		result.Matches = append(result.Matches, port.SimilarityMatch{Lib: m.GetLib(), RecordID: m.GetRecordId(), Score: m.GetScore()})
+	}
+	return result, nil
+}
+
+// vectorCallError 把插件返回的 UNIMPLEMENTED 转换为 port.ErrVectorSearchUnsupported
+func vectorCallError(method string, err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return port.ErrVectorSearchUnsupported
+	}
+	return fmt.Errorf("gRPC %s 调用失败: %w", method, err)
+}
+
 // Close 关闭与gRPC插件的连接
 func (a *ClientAdapter) Close() error {
 	if a.conn != nil {
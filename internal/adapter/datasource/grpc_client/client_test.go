@@ -9,8 +9,10 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -28,6 +30,12 @@ type mockDataSourceClient struct {
 	// --- 修正点: 将 SchemaResponse 修改回 SchemaResult ---
 	GetSchemaFunc   func(ctx context.Context, req *datasourcev1.SchemaRequest, opts ...grpc.CallOption) (*datasourcev1.SchemaResult, error)
 	HealthCheckFunc func(ctx context.Context, req *datasourcev1.HealthCheckRequest, opts ...grpc.CallOption) (*datasourcev1.HealthCheckResponse, error)
+	ShutdownFunc    func(ctx context.Context, req *datasourcev1.ShutdownRequest, opts ...grpc.CallOption) (*datasourcev1.ShutdownResponse, error)
+
+	ScanEmbeddingSourcesFunc func(ctx context.Context, req *datasourcev1.ScanEmbeddingSourcesRequest, opts ...grpc.CallOption) (*datasourcev1.ScanEmbeddingSourcesResponse, error)
+	UpsertEmbeddingsFunc     func(ctx context.Context, req *datasourcev1.UpsertEmbeddingsRequest, opts ...grpc.CallOption) (*datasourcev1.UpsertEmbeddingsResponse, error)
+	SimilaritySearchFunc     func(ctx context.Context, req *datasourcev1.SimilaritySearchRequest, opts ...grpc.CallOption) (*datasourcev1.SimilaritySearchResponse, error)
+	AdminMutateFunc          func(ctx context.Context, req *datasourcev1.MutateRequest, opts ...grpc.CallOption) (*datasourcev1.MutateResult, error)
 }
 
 // 以下是 mockDataSourceClient 对接口的实现
@@ -52,6 +60,21 @@ func (m *mockDataSourceClient) GetSchema(ctx context.Context, req *datasourcev1.
 func (m *mockDataSourceClient) HealthCheck(ctx context.Context, req *datasourcev1.HealthCheckRequest, opts ...grpc.CallOption) (*datasourcev1.HealthCheckResponse, error) {
 	return m.HealthCheckFunc(ctx, req, opts...)
 }
+func (m *mockDataSourceClient) Shutdown(ctx context.Context, req *datasourcev1.ShutdownRequest, opts ...grpc.CallOption) (*datasourcev1.ShutdownResponse, error) {
+	return m.ShutdownFunc(ctx, req, opts...)
+}
+func (m *mockDataSourceClient) ScanEmbeddingSources(ctx context.Context, req *datasourcev1.ScanEmbeddingSourcesRequest, opts ...grpc.CallOption) (*datasourcev1.ScanEmbeddingSourcesResponse, error) {
+	return m.ScanEmbeddingSourcesFunc(ctx, req, opts...)
+}
+func (m *mockDataSourceClient) UpsertEmbeddings(ctx context.Context, req *datasourcev1.UpsertEmbeddingsRequest, opts ...grpc.CallOption) (*datasourcev1.UpsertEmbeddingsResponse, error) {
+	return m.UpsertEmbeddingsFunc(ctx, req, opts...)
+}
+func (m *mockDataSourceClient) SimilaritySearch(ctx context.Context, req *datasourcev1.SimilaritySearchRequest, opts ...grpc.CallOption) (*datasourcev1.SimilaritySearchResponse, error) {
+	return m.SimilaritySearchFunc(ctx, req, opts...)
+}
+func (m *mockDataSourceClient) AdminMutate(ctx context.Context, req *datasourcev1.MutateRequest, opts ...grpc.CallOption) (*datasourcev1.MutateResult, error) {
+	return m.AdminMutateFunc(ctx, req, opts...)
+}
 
 // =======================================================================
 // ClientAdapter 所有方法测试（包含异常分支）
@@ -110,6 +133,32 @@ func TestClientAdapter_AllMethods(t *testing.T) {
 		}
 	})
 
+	t.Run("Query_Extensions", func(t *testing.T) {
+		mockClient.QueryFunc = func(ctx context.Context, req *datasourcev1.QueryRequest, opts ...grpc.CallOption) (*datasourcev1.QueryResult, error) {
+			if req.GetExtensions().AsMap()["hint"] != "idx_name" {
+				t.Errorf("Query 请求扩展选项不匹配: got %v", req.GetExtensions())
+			}
+			return &datasourcev1.QueryResult{Data: &structpb.Struct{}}, nil
+		}
+		if _, err := adapter.Query(ctx, port.QueryRequest{
+			BizName:    "user_biz",
+			Query:      map[string]interface{}{"id": 1},
+			Extensions: map[string]interface{}{"hint": "idx_name"},
+		}); err != nil {
+			t.Errorf("Query 测试不应报错: %v", err)
+		}
+
+		mockClient.QueryFunc = func(ctx context.Context, req *datasourcev1.QueryRequest, opts ...grpc.CallOption) (*datasourcev1.QueryResult, error) {
+			if req.Extensions != nil {
+				t.Errorf("没有扩展选项时不应发送 extensions: got %v", req.Extensions)
+			}
+			return &datasourcev1.QueryResult{Data: &structpb.Struct{}}, nil
+		}
+		if _, err := adapter.Query(ctx, port.QueryRequest{BizName: "user_biz", Query: map[string]interface{}{"id": 1}}); err != nil {
+			t.Errorf("Query 测试不应报错: %v", err)
+		}
+	})
+
 	t.Run("Mutate_Success", func(t *testing.T) {
 		mockResponseData := map[string]interface{}{"id": float64(456), "status": "created"}
 		mockResponseStruct, _ := structpb.NewStruct(mockResponseData)
@@ -176,6 +225,27 @@ func TestClientAdapter_AllMethods(t *testing.T) {
 		}
 	})
 
+	t.Run("Shutdown_AcceptedAndRejected", func(t *testing.T) {
+		var got *datasourcev1.ShutdownRequest
+		mockClient.ShutdownFunc = func(ctx context.Context, req *datasourcev1.ShutdownRequest, opts ...grpc.CallOption) (*datasourcev1.ShutdownResponse, error) {
+			got = req
+			return &datasourcev1.ShutdownResponse{Accepted: true}, nil
+		}
+		if err := adapter.Shutdown(ctx, "admin_stop", 2*time.Second); err != nil {
+			t.Errorf("Shutdown 测试失败: %v", err)
+		}
+		if got.GetReason() != "admin_stop" || got.GetGracePeriodMs() != 2000 {
+			t.Errorf("Shutdown 请求参数异常: got %+v", got)
+		}
+
+		mockClient.ShutdownFunc = func(ctx context.Context, req *datasourcev1.ShutdownRequest, opts ...grpc.CallOption) (*datasourcev1.ShutdownResponse, error) {
+			return &datasourcev1.ShutdownResponse{Accepted: false}, nil
+		}
+		if err := adapter.Shutdown(ctx, "admin_stop", time.Second); err == nil {
+			t.Error("插件拒绝关闭请求时应报错")
+		}
+	})
+
 	t.Run("CloseAndType", func(t *testing.T) {
 		if err := adapter.Close(); err != nil {
 			t.Errorf("Close 方法应无异常: %v", err)
@@ -211,3 +281,98 @@ func TestClientAdapter_AllMethods(t *testing.T) {
 		}
 	})
 }
+
+func TestClientAdapter_VectorSearch(t *testing.T) {
+	ctx := context.Background()
+	mockClient := &mockDataSourceClient{}
+	adapter := &ClientAdapter{client: mockClient}
+
+	mockClient.SimilaritySearchFunc = func(ctx context.Context, req *datasourcev1.SimilaritySearchRequest, opts ...grpc.CallOption) (*datasourcev1.SimilaritySearchResponse, error) {
+		if req.GetModel() != "m" || len(req.GetVector()) != 2 || req.GetTopK() != 5 {
+			t.Errorf("SimilaritySearch 请求转换不正确: %v", req)
+		}
+		return &datasourcev1.SimilaritySearchResponse{
+			Matches: []*datasourcev1.SimilarityMatch{{Lib: "main", RecordId: "7", Score: 0.9}},
+			Source:  "sqlite_plugin",
+		}, nil
+	}
+	res, err := adapter.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "b", TableName: "t", Model: "m", Vector: []float32{1, 0}, TopK: 5})
+	if err != nil {
+		t.Fatalf("SimilaritySearch 不应报错: %v", err)
+	}
+	if want := []port.SimilarityMatch{{Lib: "main", RecordID: "7", Score: 0.9}}; !reflect.DeepEqual(res.Matches, want) {
+		t.Errorf("SimilaritySearch 结果转换不正确: got %v", res.Matches)
+	}
+
+	// 未实现扩展的插件由生成代码返回 UNIMPLEMENTED
+	mockClient.UpsertEmbeddingsFunc = func(ctx context.Context, req *datasourcev1.UpsertEmbeddingsRequest, opts ...grpc.CallOption) (*datasourcev1.UpsertEmbeddingsResponse, error) {
+		return datasourcev1.UnimplementedDataSourceServer{}.UpsertEmbeddings(ctx, req)
+	}
+	if _, err := adapter.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{}); !errors.Is(err, port.ErrVectorSearchUnsupported) {
+		t.Errorf("UNIMPLEMENTED 应转换为 ErrVectorSearchUnsupported，实际为 %v", err)
+	}
+}
+
+func TestClientAdapter_AdminMutate(t *testing.T) {
+	ctx := context.Background()
+	mockClient := &mockDataSourceClient{}
+	adapter := &ClientAdapter{client: mockClient}
+
+	mockClient.AdminMutateFunc = func(ctx context.Context, req *datasourcev1.MutateRequest, opts ...grpc.CallOption) (*datasourcev1.MutateResult, error) {
+		if req.GetOperation() != port.MutateOpMaintenance || req.GetPayload().AsMap()["step"] != "inspect" {
+			t.Errorf("AdminMutate 请求转换不正确: %v", req)
+		}
+		data, _ := structpb.NewStruct(map[string]interface{}{"libs": []interface{}{}})
+		return &datasourcev1.MutateResult{Data: data, Source: "sqlite_plugin"}, nil
+	}
+	mockClient.MutateFunc = func(ctx context.Context, req *datasourcev1.MutateRequest, opts ...grpc.CallOption) (*datasourcev1.MutateResult, error) {
+		t.Error("管理操作不应经由 Mutate 转发")
+		return nil, nil
+	}
+	res, err := port.AdminMutate(ctx, adapter, port.MutateRequest{BizName: "b", Operation: port.MutateOpMaintenance, Payload: map[string]interface{}{"step": "inspect"}})
+	if err != nil {
+		t.Fatalf("AdminMutate 不应报错: %v", err)
+	}
+	if res.Source != "sqlite_plugin" {
+		t.Errorf("AdminMutate 结果转换不正确: %v", res)
+	}
+
+	// 旧版插件未实现该扩展，由生成代码返回 UNIMPLEMENTED
+	mockClient.AdminMutateFunc = func(ctx context.Context, req *datasourcev1.MutateRequest, opts ...grpc.CallOption) (*datasourcev1.MutateResult, error) {
+		return datasourcev1.UnimplementedDataSourceServer{}.AdminMutate(ctx, req)
+	}
+	if _, err := adapter.AdminMutate(ctx, port.MutateRequest{Operation: port.MutateOpCompact}); !errors.Is(err, port.ErrAdminMutateUnsupported) {
+		t.Errorf("UNIMPLEMENTED 应转换为 ErrAdminMutateUnsupported，实际为 %v", err)
+	}
+}
+
+func TestClientAdapter_ConfigVersion(t *testing.T) {
+	mockClient := &mockDataSourceClient{}
+	adapter := &ClientAdapter{client: mockClient}
+
+	var got []string
+	mockClient.QueryFunc = func(ctx context.Context, req *datasourcev1.QueryRequest, opts ...grpc.CallOption) (*datasourcev1.QueryResult, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get(ConfigVersionMetadataKey)
+		return &datasourcev1.QueryResult{}, nil
+	}
+	req := port.QueryRequest{BizName: "b", Query: map[string]interface{}{"table": "t"}}
+	if _, err := adapter.Query(context.Background(), req); err != nil {
+		t.Fatalf("Query 不应报错: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("未设置版本来源时不应携带配置版本，实际为 %v", got)
+	}
+
+	version := "v1"
+	adapter.SetConfigVersionSource(func() string { return version })
+	_, _ = adapter.Query(context.Background(), req)
+	if !reflect.DeepEqual(got, []string{"v1"}) {
+		t.Errorf("请求应携带当前的配置版本，实际为 %v", got)
+	}
+	version = "v2"
+	_, _ = adapter.Query(context.Background(), req)
+	if !reflect.DeepEqual(got, []string{"v2"}) {
+		t.Errorf("配置版本变化后请求应携带新版本，实际为 %v", got)
+	}
+}
@@ -0,0 +1,198 @@
+// Package readsplit file: internal/adapter/datasource/readsplit/readsplit.go
+package readsplit
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCooldown 是副本被判定为不可用后暂停向其分发查询的时长
+const DefaultCooldown = 30 * time.Second
+
+// LookupFunc 按业务组名解析写入实例或副本的数据源。每次请求时调用，因此插件重启后无需重新注册读写分离业务组。
+type LookupFunc func(bizName string) (port.DataSource, bool)
+
+// DataSource 把写操作发往写入实例，把查询在健康的副本之间轮询分担
+type DataSource struct {
+	def      domain.ReadSplitBiz
+	lookup   LookupFunc
+	cooldown time.Duration
+	now      func() time.Time
+
+	next atomic.Uint64
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+}
+
+// New 根据读写分离业务组定义创建一个数据源
+func New(def domain.ReadSplitBiz, lookup LookupFunc) *DataSource {
+	return &DataSource{
+		def:       def,
+		lookup:    lookup,
+		cooldown:  DefaultCooldown,
+		now:       time.Now,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+// Type 返回适配器的类型标识符
+func (d *DataSource) Type() string {
+	return "read_split"
+}
+
+// writer 返回写入实例的数据源
+func (d *DataSource) writer() (port.DataSource, error) {
+	ds, loaded := d.lookup(d.def.Writer)
+	if !loaded {
+		return nil, fmt.Errorf("写入实例业务组 '%s' 当前未注册", d.def.Writer)
+	}
+	return ds, nil
+}
+
+// candidates 从轮询位置开始返回当前未被暂停的副本，每次调用轮询位置前进一位
+func (d *DataSource) candidates() []string {
+	n := len(d.def.Readers)
+	if n == 0 {
+		return nil
+	}
+	start := int(d.next.Add(1)-1) % n
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		reader := d.def.Readers[(start+i)%n]
+		if until, down := d.downUntil[reader]; down && now.Before(until) {
+			continue
+		}
+		out = append(out, reader)
+	}
+	return out
+}
+
+// markDown 在冷却时间内不再向该副本分发查询
+func (d *DataSource) markDown(reader string, cause error) {
+	d.mu.Lock()
+	d.downUntil[reader] = d.now().Add(d.cooldown)
+	d.mu.Unlock()
+	slog.Warn("[ReadSplit] 副本不可用，暂停分发查询", "biz", d.def.BizName, "reader", reader, "cooldown", d.cooldown, "error", cause)
+}
+
+// markUp 恢复向该副本分发查询
+func (d *DataSource) markUp(reader string) {
+	d.mu.Lock()
+	delete(d.downUntil, reader)
+	d.mu.Unlock()
+}
+
+// Query 把查询发往下一个健康的副本。副本查询失败时先对其做健康检查：副本健康说明是查询本身的错误，
+// 直接返回；否则暂停该副本并切换到下一个，全部副本不可用时由写入实例处理。
+// 变更日志只在写入实例上维护，changes 模式的查询总是发往写入实例
+func (d *DataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	if mode, _ := req.Query[port.QueryModeKey].(string); mode != port.QueryModeChanges {
+		for _, reader := range d.candidates() {
+			ds, loaded := d.lookup(reader)
+			if !loaded {
+				continue
+			}
+			result, err := ds.Query(ctx, port.QueryRequest{BizName: reader, Query: req.Query, Extensions: req.Extensions})
+			if err == nil {
+				return result, nil
+			}
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			healthErr := ds.HealthCheck(ctx)
+			if healthErr == nil {
+				return nil, err
+			}
+			d.markDown(reader, healthErr)
+		}
+	}
+
+	ds, err := d.writer()
+	if err != nil {
+		return nil, err
+	}
+	return ds.Query(ctx, port.QueryRequest{BizName: d.def.Writer, Query: req.Query, Extensions: req.Extensions})
+}
+
+// Mutate 把写操作 (包括管理操作) 发往写入实例
+func (d *DataSource) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	ds, err := d.writer()
+	if err != nil {
+		return nil, err
+	}
+	return ds.Mutate(ctx, port.MutateRequest{BizName: d.def.Writer, Operation: req.Operation, Payload: req.Payload})
+}
+
+// AdminMutate 把管理操作转发到写入实例
+func (d *DataSource) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	ds, err := d.writer()
+	if err != nil {
+		return nil, err
+	}
+	return port.AdminMutate(ctx, ds, port.MutateRequest{BizName: d.def.Writer, Operation: req.Operation, Payload: req.Payload})
+}
+
+// GetSchema 返回写入实例的结构信息
+func (d *DataSource) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	ds, err := d.writer()
+	if err != nil {
+		return nil, err
+	}
+	return ds.GetSchema(ctx, port.SchemaRequest{BizName: d.def.Writer, TableName: req.TableName})
+}
+
+// HealthCheck 只要写入实例健康即视为健康；同时检查各副本，恢复健康的副本立即重新参与分担查询
+func (d *DataSource) HealthCheck(ctx context.Context) error {
+	for _, reader := range d.def.Readers {
+		ds, loaded := d.lookup(reader)
+		if !loaded {
+			continue
+		}
+		if err := ds.HealthCheck(ctx); err != nil {
+			d.markDown(reader, err)
+		} else {
+			d.markUp(reader)
+		}
+	}
+	ds, err := d.writer()
+	if err != nil {
+		return err
+	}
+	return ds.HealthCheck(ctx)
+}
+
+// ReaderStatus 是副本当前的分发状态
+type ReaderStatus struct {
+	BizName    string     `json:"biz_name"`
+	Registered bool       `json:"registered"`
+	DownUntil  *time.Time `json:"down_until,omitempty"`
+}
+
+// Readers 返回各副本当前是否已注册以及是否处于暂停分发状态
+func (d *DataSource) Readers() []ReaderStatus {
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]ReaderStatus, 0, len(d.def.Readers))
+	for _, reader := range d.def.Readers {
+		_, loaded := d.lookup(reader)
+		status := ReaderStatus{BizName: reader, Registered: loaded}
+		if until, down := d.downUntil[reader]; down && now.Before(until) {
+			u := until
+			status.DownUntil = &u
+		}
+		out = append(out, status)
+	}
+	return out
+}
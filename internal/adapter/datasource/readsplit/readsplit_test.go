@@ -0,0 +1,112 @@
+// file: internal/adapter/datasource/readsplit/readsplit_test.go
+package readsplit
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memberStub 记录收到的请求数，queryErr 与 healthErr 模拟查询错误与实例故障
+type memberStub struct {
+	queries   int
+	mutates   int
+	queryErr  error
+	healthErr error
+}
+
+func (m *memberStub) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	m.queries++
+	if m.queryErr != nil {
+		return nil, m.queryErr
+	}
+	return &port.QueryResult{Source: req.BizName}, nil
+}
+func (m *memberStub) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	m.mutates++
+	return &port.MutateResult{Source: req.BizName}, nil
+}
+func (m *memberStub) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return &port.SchemaResult{}, nil
+}
+func (m *memberStub) HealthCheck(context.Context) error { return m.healthErr }
+func (m *memberStub) Type() string                      { return "stub" }
+
+func newTestReadSplit() (*DataSource, map[string]*memberStub, *time.Time) {
+	members := map[string]*memberStub{"primary": {}, "replica_a": {}, "replica_b": {}}
+	ds := New(domain.ReadSplitBiz{BizName: "archive", Writer: "primary", Readers: []string{"replica_a", "replica_b"}},
+		func(biz string) (port.DataSource, bool) {
+			m, ok := members[biz]
+			return m, ok
+		})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ds.now = func() time.Time { return now }
+	return ds, members, &now
+}
+
+func TestQuery_BalancesAcrossReaders(t *testing.T) {
+	ctx := context.Background()
+	ds, members, _ := newTestReadSplit()
+
+	var sources []string
+	for i := 0; i < 4; i++ {
+		res, err := ds.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{}})
+		require.NoError(t, err)
+		sources = append(sources, res.Source)
+	}
+	assert.Equal(t, []string{"replica_a", "replica_b", "replica_a", "replica_b"}, sources)
+	assert.Zero(t, members["primary"].queries)
+
+	res, err := ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{port.QueryModeKey: port.QueryModeChanges}})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res.Source, "变更日志只在写入实例上维护")
+
+	_, err = ds.Mutate(ctx, port.MutateRequest{Operation: "create"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, members["primary"].mutates)
+	assert.Zero(t, members["replica_a"].mutates)
+}
+
+func TestQuery_FailsOverUnhealthyReaders(t *testing.T) {
+	ctx := context.Background()
+	ds, members, now := newTestReadSplit()
+	members["replica_a"].queryErr = errors.New("连接被拒绝")
+	members["replica_a"].healthErr = errors.New("连接被拒绝")
+
+	res, err := ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "replica_b", res.Source)
+	res, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "replica_b", res.Source)
+	assert.Equal(t, 1, members["replica_a"].queries, "暂停期间不再向故障副本分发查询")
+	require.NotNil(t, ds.Readers()[0].DownUntil)
+
+	members["replica_b"].queryErr = errors.New("连接被拒绝")
+	members["replica_b"].healthErr = errors.New("连接被拒绝")
+	res, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res.Source, "全部副本不可用时由写入实例处理")
+
+	members["replica_a"].queryErr, members["replica_a"].healthErr = nil, nil
+	*now = now.Add(DefaultCooldown)
+	res, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "replica_a", res.Source, "冷却结束后副本重新参与分担")
+}
+
+func TestQuery_ReturnsQueryErrorsFromHealthyReader(t *testing.T) {
+	ds, members, _ := newTestReadSplit()
+	members["replica_a"].queryErr = errors.New("未知字段")
+
+	_, err := ds.Query(context.Background(), port.QueryRequest{Query: map[string]interface{}{}})
+	assert.EqualError(t, err, "未知字段", "副本健康时查询错误直接返回，不切换实例")
+	assert.Zero(t, members["replica_b"].queries)
+	assert.Nil(t, ds.Readers()[0].DownUntil)
+}
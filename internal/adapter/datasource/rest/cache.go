@@ -0,0 +1,124 @@
+// Package rest file: internal/adapter/datasource/rest/cache.go
+package rest
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// cachedResponse 是一次成功的上游响应及其校验信息
+type cachedResponse struct {
+	body         []byte
+	etag         string
+	lastModified string
+	// lifetime 是条目在无需向上游确认的情况下直接使用的时间，由上游的 Cache-Control 与配置的 TTL 决定
+	lifetime time.Duration
+	// validatedAt 是最近一次从上游取得或经 304 确认该响应的时间
+	validatedAt time.Time
+}
+
+// responseCache 按完整的上游 URL 保存响应。条目在有效期 (上游 max-age 与 TTL 中较小者) 内直接使用，
+// 到期后带 ETag 或 Last-Modified 向上游确认，
+// 确认前上游不可用时在 MaxStale 内作为离线降级的结果。条目在最后一次确认后 TTL + MaxStale 被淘汰
+type responseCache struct {
+	opts    CacheOptions
+	entries *lru.LRU[string, cachedResponse]
+	now     func() time.Time
+
+	hits, revalidated, misses, stale atomic.Int64
+}
+
+func newResponseCache(opts CacheOptions) *responseCache {
+	return &responseCache{
+		opts:    opts,
+		entries: lru.NewLRU[string, cachedResponse](opts.MaxEntries, nil, time.Duration(opts.TTL)+time.Duration(opts.MaxStale)),
+		now:     time.Now,
+	}
+}
+
+// get 返回 URL 的缓存条目，fresh 表示条目仍在有效期内、无需向上游确认
+func (c *responseCache) get(url string) (entry cachedResponse, fresh bool, ok bool) {
+	entry, ok = c.entries.Get(url)
+	if !ok {
+		return entry, false, false
+	}
+	return entry, c.now().Sub(entry.validatedAt) < entry.lifetime, true
+}
+
+// usableOffline 报告条目能否在上游不可用时作为降级结果
+func (c *responseCache) usableOffline(entry cachedResponse) bool {
+	return c.opts.OfflineFallback && c.now().Sub(entry.validatedAt) < time.Duration(c.opts.TTL)+time.Duration(c.opts.MaxStale)
+}
+
+func (c *responseCache) put(url string, entry cachedResponse) {
+	entry.validatedAt = c.now()
+	c.entries.Add(url, entry)
+}
+
+func (c *responseCache) remove(url string) {
+	c.entries.Remove(url)
+}
+
+// purge 清空缓存的响应，计数保留
+func (c *responseCache) purge() int {
+	n := c.entries.Len()
+	c.entries.Purge()
+	return n
+}
+
+// lifetime 按上游的 Cache-Control 计算响应的有效期。store 为 false 表示上游以 no-store 或 private
+// 禁止共享缓存，响应不保存。max-age (s-maxage 优先) 短于 TTL 时以其为有效期；no-cache 的响应仍被保存，
+// 但有效期为 0，每次使用前都向上游确认，并可用于离线降级
+func (c *responseCache) lifetime(cacheControl string) (lifetime time.Duration, store bool) {
+	lifetime = time.Duration(c.opts.TTL)
+	maxAge, sharedMaxAge := -1, -1
+	for _, directive := range strings.Split(strings.ToLower(cacheControl), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch name {
+		case "no-store", "private":
+			return 0, false
+		case "no-cache":
+			lifetime = 0
+		case "max-age", "s-maxage":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			if name == "max-age" {
+				maxAge = seconds
+			} else {
+				sharedMaxAge = seconds
+			}
+		}
+	}
+	if sharedMaxAge >= 0 {
+		maxAge = sharedMaxAge
+	}
+	if maxAge >= 0 {
+		lifetime = min(lifetime, time.Duration(maxAge)*time.Second)
+	}
+	return lifetime, true
+}
+
+// CacheStats 是上游响应缓存的计数，Entries 为当前保留的响应条数
+type CacheStats struct {
+	Entries     int   `json:"entries"`
+	Hits        int64 `json:"hits"`
+	Revalidated int64 `json:"revalidated"`
+	Misses      int64 `json:"misses"`
+	Stale       int64 `json:"stale"`
+}
+
+func (c *responseCache) stats() CacheStats {
+	return CacheStats{
+		Entries:     c.entries.Len(),
+		Hits:        c.hits.Load(),
+		Revalidated: c.revalidated.Load(),
+		Misses:      c.misses.Load(),
+		Stale:       c.stale.Load(),
+	}
+}
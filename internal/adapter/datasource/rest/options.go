@@ -0,0 +1,155 @@
+// Package rest file: internal/adapter/datasource/rest/options.go
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 业务组的数据由上游的 REST 接口提供时，接口地址、表与上游资源的对应关系以及缓存策略
+// 由业务组目录下的 rest.json 配置:
+//
+//	{"base_url": "https://api.example.org/v1", "timeout": "10s",
+//	 "headers": {"Accept": "application/json"},
+//	 "tables": {"books": {"path": "/books", "items": "data.items", "total": "data.total",
+//	                      "fields": ["id", "title", "author"], "primary_key": "id"}},
+//	 "cache": {"ttl": "1m", "max_stale": "24h", "offline_fallback": true, "max_entries": 1000}}
+const (
+	// OptionsFilename 是业务组目录下的上游接口配置文件
+	OptionsFilename = "rest.json"
+
+	defaultTimeout    = 10 * time.Second
+	defaultCacheTTL   = time.Minute
+	defaultMaxStale   = 24 * time.Hour
+	defaultMaxEntries = 1000
+	defaultPageParam  = "page"
+	defaultSizeParam  = "size"
+)
+
+// Duration 是 rest.json 中以 "30s"、"5m" 形式书写的时长
+type Duration time.Duration
+
+// UnmarshalJSON 解析 time.ParseDuration 接受的字符串
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("时长必须是 \"30s\" 形式的字符串: %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// TableOptions 描述一个表对应的上游资源
+type TableOptions struct {
+	// Path 是资源相对 base_url 的路径，过滤条件与分页以查询参数附加在其后
+	Path string `json:"path"`
+	// Items 是响应 JSON 中记录数组的位置，以 "." 分隔；为空表示响应本身就是数组
+	Items string `json:"items"`
+	// Total 是响应 JSON 中总条数的位置；为空或缺失时以本页的记录数代替
+	Total string `json:"total"`
+	// Fields 是表的字段，均可检索与返回
+	Fields []string `json:"fields"`
+	// PrimaryKey 是记录标识字段，为空时表不能按记录寻址
+	PrimaryKey string `json:"primary_key"`
+	// PageParam 与 SizeParam 是上游分页参数的名称，默认为 page 与 size
+	PageParam string `json:"page_param"`
+	SizeParam string `json:"size_param"`
+}
+
+// CacheOptions 是业务组的上游响应缓存策略
+type CacheOptions struct {
+	// Disabled 为 true 时每次查询都直接请求上游，也不做离线降级
+	Disabled bool `json:"disabled"`
+	// TTL 是响应在无需向上游确认的情况下直接使用的时间，默认为 1 分钟；到期后以 ETag 或 Last-Modified 向上游确认。
+	// 上游 Cache-Control 的 max-age 更短时以其为准，no-cache 的响应每次使用前都向上游确认
+	TTL Duration `json:"ttl"`
+	// MaxStale 是 TTL 到期后响应仍被保留、用于离线降级的最长时间，默认为 24 小时
+	MaxStale Duration `json:"max_stale"`
+	// OfflineFallback 为 true 时，上游不可达或返回 5xx 时以保留的响应回答查询，结果带 port.ResultStaleKey
+	OfflineFallback bool `json:"offline_fallback"`
+	// MaxEntries 是保留的响应条数上限，超出后按 LRU 淘汰，默认为 1000
+	MaxEntries int `json:"max_entries"`
+}
+
+// Options 是业务组目录下 rest.json 的内容
+type Options struct {
+	BaseURL string                   `json:"base_url"`
+	Timeout Duration                 `json:"timeout"`
+	Headers map[string]string        `json:"headers"`
+	Tables  map[string]*TableOptions `json:"tables"`
+	Cache   CacheOptions             `json:"cache"`
+}
+
+// LoadOptions 读取并校验业务组目录下的 rest.json，填充未指定项的默认值
+func LoadOptions(bizDir string) (Options, error) {
+	var opts Options
+	data, err := os.ReadFile(filepath.Join(bizDir, OptionsFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return opts, fmt.Errorf("业务组目录 '%s' 下缺少 %s", bizDir, OptionsFilename)
+	}
+	if err != nil {
+		return opts, err
+	}
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return opts, fmt.Errorf("解析 %s 失败: %w", OptionsFilename, err)
+	}
+	if err := opts.normalize(); err != nil {
+		return opts, fmt.Errorf("%s: %w", OptionsFilename, err)
+	}
+	return opts, nil
+}
+
+func (o *Options) normalize() error {
+	base, err := url.Parse(o.BaseURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("base_url '%s' 必须是 http 或 https 地址", o.BaseURL)
+	}
+	o.BaseURL = strings.TrimRight(o.BaseURL, "/")
+	if o.Timeout <= 0 {
+		o.Timeout = Duration(defaultTimeout)
+	}
+	if len(o.Tables) == 0 {
+		return errors.New("tables 不能为空")
+	}
+	for name, table := range o.Tables {
+		if table == nil || table.Path == "" || !strings.HasPrefix(table.Path, "/") {
+			return fmt.Errorf("表 '%s' 的 path 必须以 '/' 开头", name)
+		}
+		if len(table.Fields) == 0 {
+			return fmt.Errorf("表 '%s' 的 fields 不能为空", name)
+		}
+		if table.PrimaryKey != "" && !slices.Contains(table.Fields, table.PrimaryKey) {
+			return fmt.Errorf("表 '%s' 的 primary_key '%s' 不在 fields 中", name, table.PrimaryKey)
+		}
+		if table.PageParam == "" {
+			table.PageParam = defaultPageParam
+		}
+		if table.SizeParam == "" {
+			table.SizeParam = defaultSizeParam
+		}
+	}
+	if o.Cache.TTL < 0 || o.Cache.MaxStale < 0 || o.Cache.MaxEntries < 0 {
+		return errors.New("cache 的 ttl、max_stale 与 max_entries 不能为负数")
+	}
+	if o.Cache.TTL == 0 {
+		o.Cache.TTL = Duration(defaultCacheTTL)
+	}
+	if o.Cache.MaxStale == 0 {
+		o.Cache.MaxStale = Duration(defaultMaxStale)
+	}
+	if o.Cache.MaxEntries == 0 {
+		o.Cache.MaxEntries = defaultMaxEntries
+	}
+	return nil
+}
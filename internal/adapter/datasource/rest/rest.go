@@ -0,0 +1,430 @@
+// Package rest file: internal/adapter/datasource/rest/rest.go
+package rest
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// maxResponseBytes 是单次上游响应体的上限，超出时查询失败而不是占满插件内存
+	maxResponseBytes = 32 << 20
+
+	defaultPageSize = 50
+)
+
+// ErrUpstreamUnavailable 表示上游接口不可达或返回 5xx，且没有可用于离线降级的响应
+var ErrUpstreamUnavailable = errors.New("上游接口不可用")
+
+// Manager 以上游的 REST 接口作为业务组的只读数据源。
+// 查询被翻译为对上游资源的 GET 请求，响应按 rest.json 的 cache 配置缓存、重新确认并在上游不可用时降级
+type Manager struct {
+	bizName string
+	opts    Options
+	client  *http.Client
+	// cache 为 nil 表示业务组关闭了上游响应缓存
+	cache *responseCache
+	// fetches 合并对同一 URL 的并发请求，缓存到期时只有一个请求向上游确认
+	fetches singleflight.Group
+}
+
+var (
+	_ port.DataSource   = (*Manager)(nil)
+	_ port.AdminMutator = (*Manager)(nil)
+)
+
+// NewManager 按已校验的配置 (见 LoadOptions) 创建业务组的数据源
+func NewManager(bizName string, opts Options) (*Manager, error) {
+	if bizName == "" {
+		return nil, errors.New("rest.Manager 需要业务组名称")
+	}
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		bizName: bizName,
+		opts:    opts,
+		client:  &http.Client{Timeout: time.Duration(opts.Timeout)},
+	}
+	if !opts.Cache.Disabled {
+		m.cache = newResponseCache(opts.Cache)
+	}
+	return m, nil
+}
+
+// Type 返回适配器的类型标识符
+func (m *Manager) Type() string {
+	return "rest"
+}
+
+// CacheStats 返回上游响应缓存的计数，缓存关闭时返回零值
+func (m *Manager) CacheStats() CacheStats {
+	if m.cache == nil {
+		return CacheStats{}
+	}
+	return m.cache.stats()
+}
+
+// PurgeCache 清空缓存的上游响应并返回清除的条数，下次查询重新请求上游。缓存关闭时返回 0
+func (m *Manager) PurgeCache() int {
+	if m.cache == nil {
+		return 0
+	}
+	n := m.cache.purge()
+	slog.Info("[REST] 已清空上游响应缓存", "biz", m.bizName, "entries", n)
+	return n
+}
+
+// Query 将查询翻译为对上游资源的 GET 请求。过滤条件只支持字段等值匹配，以查询参数传给上游；
+// 结果只包含 rest.json 中声明的字段。以离线降级的响应回答时结果带 port.ResultStaleKey 与 port.ResultCachedAtKey
+func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	if req.BizName != m.bizName {
+		return nil, port.ErrBizNotFound
+	}
+	queryMap := req.Query
+	if mode, _ := queryMap[port.QueryModeKey].(string); mode != "" {
+		return nil, fmt.Errorf("无效请求: REST 数据源不支持查询模式 '%s'", mode)
+	}
+	tableName, _ := queryMap["table"].(string)
+	if tableName == "" {
+		return nil, fmt.Errorf("无效请求: query 体必须包含一个有效的 'table' 字符串字段")
+	}
+	table, ok := m.opts.Tables[tableName]
+	if !ok {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	params := url.Values{}
+	page, size := 1, defaultPageSize
+	if pageF, ok := queryMap["page"].(float64); ok && pageF >= 1 {
+		page = int(pageF)
+	}
+	if sizeF, ok := queryMap["size"].(float64); ok && sizeF >= 1 {
+		size = int(sizeF)
+	}
+	params.Set(table.PageParam, strconv.Itoa(page))
+	params.Set(table.SizeParam, strconv.Itoa(size))
+	if filters, ok := queryMap["filters"].([]interface{}); ok {
+		for i, f := range filters {
+			field, value, err := equalityFilter(table, f)
+			if err != nil {
+				return nil, fmt.Errorf("无效请求: filters 数组的第 %d 个元素: %w", i, err)
+			}
+			params.Add(field, value)
+		}
+	}
+	fields := table.Fields
+	if requested, ok := queryMap["fields_to_return"].([]interface{}); ok && len(requested) > 0 {
+		fields = nil
+		for _, field := range requested {
+			if name, ok := field.(string); ok && slices.Contains(table.Fields, name) {
+				fields = append(fields, name)
+			}
+		}
+	}
+
+	// url.Values.Encode 按参数名排序，相同的查询总是得到相同的 URL，即相同的缓存键
+	target := m.opts.BaseURL + table.Path + "?" + params.Encode()
+	res, err := m.fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(res.body, &doc); err != nil {
+		return nil, fmt.Errorf("解析上游响应失败: %w", err)
+	}
+	rawItems, _ := lookup(doc, table.Items)
+	records, ok := rawItems.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("上游响应中 '%s' 处不是记录数组", table.Items)
+	}
+	items := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		obj, _ := record.(map[string]interface{})
+		row := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			row[field] = obj[field]
+		}
+		items = append(items, row)
+	}
+	total := float64(len(items))
+	if table.Total != "" {
+		if t, ok := lookup(doc, table.Total); ok {
+			if n, ok := t.(float64); ok {
+				total = n
+			}
+		}
+	}
+
+	data := map[string]interface{}{
+		"items": items,
+		"total": int64(total),
+	}
+	if res.stale {
+		data[port.ResultStaleKey] = true
+		data[port.ResultCachedAtKey] = res.cachedAt.UTC().Format(time.RFC3339)
+	}
+	return &port.QueryResult{Data: data, Source: m.Type()}, nil
+}
+
+// equalityFilter 校验过滤条件并返回字段名与取值。上游接口的匹配语义未知，只接受不带运算符的等值匹配
+func equalityFilter(table *TableOptions, f interface{}) (string, string, error) {
+	filterMap, ok := f.(map[string]interface{})
+	if !ok {
+		return "", "", errors.New("不是一个有效的JSON对象")
+	}
+	field, _ := filterMap["field"].(string)
+	if !slices.Contains(table.Fields, field) {
+		return "", "", fmt.Errorf("未知的字段 '%s'", field)
+	}
+	if logic, _ := filterMap["logic"].(string); logic != "" && !strings.EqualFold(logic, "and") {
+		return "", "", errors.New("REST 数据源的过滤条件只能以 and 组合")
+	}
+	for _, key := range []string{"op", "fuzzy", "pinyin", port.FilterFullTextKey, port.FilterNegateKey, port.FilterValuesKey} {
+		if v, ok := filterMap[key]; ok && v != false && v != "" {
+			return "", "", fmt.Errorf("REST 数据源不支持过滤选项 '%s'", key)
+		}
+	}
+	value, ok := filterMap["value"]
+	if !ok || value == nil {
+		return "", "", errors.New("缺少 'value'")
+	}
+	return field, fmt.Sprintf("%v", value), nil
+}
+
+// lookup 按 "." 分隔的路径取出 JSON 文档中的值，路径为空时返回文档本身
+func lookup(doc interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return doc, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// fetched 是一次上游请求的结果。stale 为 true 表示上游不可用，body 是 cachedAt 时确认过的旧响应
+type fetched struct {
+	body     []byte
+	stale    bool
+	cachedAt time.Time
+}
+
+func (m *Manager) fetch(ctx context.Context, target string) (fetched, error) {
+	v, err, _ := m.fetches.Do(target, func() (interface{}, error) {
+		// 共享的上游请求不应因首个调用方断开而对其他等待者失败，改以上游超时为限
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Duration(m.opts.Timeout))
+		defer cancel()
+		return m.fetchOnce(fetchCtx, target)
+	})
+	if err != nil {
+		return fetched{}, err
+	}
+	return v.(fetched), nil
+}
+
+// fetchOnce 在缓存的响应仍在有效期内时直接返回，否则带上 ETag 与 Last-Modified 向上游确认：
+// 304 时继续使用缓存的响应，200 时替换之，上游不可达或返回 5xx 时按离线降级策略返回旧响应
+func (m *Manager) fetchOnce(ctx context.Context, target string) (fetched, error) {
+	var entry cachedResponse
+	var cached bool
+	if m.cache != nil {
+		var fresh bool
+		if entry, fresh, cached = m.cache.get(target); fresh {
+			m.cache.hits.Add(1)
+			return fetched{body: entry.body}, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fetched{}, fmt.Errorf("创建上游请求失败: %w", err)
+	}
+	for key, value := range m.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	if cached {
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := m.client.Do(req)
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		err = fmt.Errorf("状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err != nil {
+		if cached && m.cache.usableOffline(entry) {
+			m.cache.stale.Add(1)
+			slog.Warn("[REST] 上游接口不可用，返回缓存中的旧响应", "biz", m.bizName, "url", target, "validated_at", entry.validatedAt, "error", err)
+			return fetched{body: entry.body, stale: true, cachedAt: entry.validatedAt}, nil
+		}
+		return fetched{}, fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		m.cache.revalidated.Add(1)
+		// 304 携带 Cache-Control 时以其更新有效期
+		if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "" {
+			lifetime, store := m.cache.lifetime(cacheControl)
+			if !store {
+				m.cache.remove(target)
+				return fetched{body: entry.body}, nil
+			}
+			entry.lifetime = lifetime
+		}
+		m.cache.put(target, entry)
+		return fetched{body: entry.body}, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+		if err != nil {
+			return fetched{}, fmt.Errorf("%w: 读取响应失败: %v", ErrUpstreamUnavailable, err)
+		}
+		if len(body) > maxResponseBytes {
+			return fetched{}, fmt.Errorf("上游响应超过 %d 字节", maxResponseBytes)
+		}
+		if m.cache != nil {
+			m.cache.misses.Add(1)
+			if lifetime, store := m.cache.lifetime(resp.Header.Get("Cache-Control")); store {
+				m.cache.put(target, cachedResponse{
+					body:         body,
+					etag:         resp.Header.Get("ETag"),
+					lastModified: resp.Header.Get("Last-Modified"),
+					lifetime:     lifetime,
+				})
+			} else {
+				m.cache.remove(target)
+			}
+		}
+		return fetched{body: body}, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fetched{}, fmt.Errorf("上游接口返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+}
+
+// AdminMutate 只支持 port.MutateOpUpstreamCache：按 payload 的 "step" 返回缓存计数或清空缓存
+func (m *Manager) AdminMutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if req.BizName != m.bizName {
+		return nil, port.ErrBizNotFound
+	}
+	if req.Operation != port.MutateOpUpstreamCache {
+		return nil, fmt.Errorf("%w: '%s'", port.ErrAdminMutateUnsupported, req.Operation)
+	}
+	switch step, _ := req.Payload["step"].(string); step {
+	case "stats":
+		stats := m.CacheStats()
+		return &port.MutateResult{Source: m.Type(), Data: map[string]interface{}{
+			"enabled": m.cache != nil,
+			"cache": map[string]interface{}{
+				"entries":     stats.Entries,
+				"hits":        stats.Hits,
+				"revalidated": stats.Revalidated,
+				"misses":      stats.Misses,
+				"stale":       stats.Stale,
+			},
+		}}, nil
+	case "purge":
+		return &port.MutateResult{Source: m.Type(), Data: map[string]interface{}{"purged": m.PurgeCache()}}, nil
+	default:
+		return nil, fmt.Errorf("无效请求: upstream_cache 的 step 必须是 'stats' 或 'purge'，收到 '%s'", step)
+	}
+}
+
+// Mutate 总是返回错误，REST 数据源只读
+func (m *Manager) Mutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	return nil, fmt.Errorf("%w: REST 数据源只读", port.ErrPermissionDenied)
+}
+
+// GetSchema 返回 rest.json 中声明的表与字段。上游不提供字段类型，字段一律为 string
+func (m *Manager) GetSchema(_ context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	if req.BizName != m.bizName {
+		return nil, port.ErrBizNotFound
+	}
+	result := &port.SchemaResult{
+		Tables:     make(map[string][]port.FieldDescription),
+		RecordKeys: make(map[string][]string),
+	}
+	for name, table := range m.opts.Tables {
+		if req.TableName != "" && req.TableName != name {
+			continue
+		}
+		fields := make([]port.FieldDescription, 0, len(table.Fields))
+		for _, field := range table.Fields {
+			fields = append(fields, port.FieldDescription{
+				Name:         field,
+				DataType:     "string",
+				IsSearchable: true,
+				IsReturnable: true,
+				IsPrimary:    field == table.PrimaryKey,
+			})
+		}
+		result.Tables[name] = fields
+		var key []string
+		if table.PrimaryKey != "" {
+			key = []string{table.PrimaryKey}
+		}
+		result.RecordKeys[name] = key
+	}
+	if req.TableName != "" && len(result.Tables) == 0 {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	return result, nil
+}
+
+// HealthCheck 检查上游接口是否可达。开启离线降级时插件本身始终可以回答查询，上游不可达只记录日志而不报告失败，
+// 避免网关因健康检查失败停止插件、丢失缓存的响应
+func (m *Manager) HealthCheck(ctx context.Context) error {
+	err := m.probe(ctx)
+	if err != nil && m.cache != nil && m.opts.Cache.OfflineFallback {
+		slog.Warn("[REST] 上游接口不可达，以缓存的响应提供服务", "biz", m.bizName, "error", err)
+		return nil
+	}
+	return err
+}
+
+func (m *Manager) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, m.opts.BaseURL, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range m.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: 状态码 %d", ErrUpstreamUnavailable, resp.StatusCode)
+	}
+	return nil
+}
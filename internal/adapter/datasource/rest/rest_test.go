@@ -0,0 +1,417 @@
+// file: internal/adapter/datasource/rest/rest_test.go
+package rest
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstream 模拟上游接口：/books 返回带 ETag 的记录，status 不为 0 时所有请求都以该状态码失败
+type upstream struct {
+	mu       sync.Mutex
+	etag     string
+	status   int
+	requests []*http.Request
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests = append(u.requests, r)
+	if u.status != 0 {
+		w.WriteHeader(u.status)
+		return
+	}
+	switch r.URL.Path {
+	case "/v1/books":
+		if r.Header.Get("If-None-Match") == u.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", u.etag)
+		_, _ = w.Write([]byte(`{"data": {"total": 42, "items": [{"id": 1, "title": "围城", "author": "钱钟书", "secret": "x"}]}}`))
+	case "/v1/letters":
+		if r.Header.Get("If-Modified-Since") == "Mon, 02 Jan 2006 15:04:05 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		_, _ = w.Write([]byte(`[{"id": 7, "body": "见字如面"}]`))
+	case "/v1/recent", "/v1/volatile":
+		if r.Header.Get("If-None-Match") == u.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", u.etag)
+		if r.URL.Path == "/v1/recent" {
+			w.Header().Set("Cache-Control", "public, max-age=30")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	case "/v1/live":
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte(`[]`))
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (u *upstream) set(etag string, status int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.etag, u.status = etag, status
+}
+
+func (u *upstream) last() *http.Request {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.requests[len(u.requests)-1]
+}
+
+func (u *upstream) count() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.requests)
+}
+
+func newTestManager(t *testing.T, cache CacheOptions) (*Manager, *upstream, *time.Time) {
+	t.Helper()
+	up := &upstream{etag: `"v1"`}
+	server := httptest.NewServer(up)
+	t.Cleanup(server.Close)
+	manager, err := NewManager("remote", Options{
+		BaseURL: server.URL + "/v1/",
+		Headers: map[string]string{"Accept": "application/json"},
+		Tables: map[string]*TableOptions{
+			"books":    {Path: "/books", Items: "data.items", Total: "data.total", Fields: []string{"id", "title", "author"}, PrimaryKey: "id", SizeParam: "per_page"},
+			"letters":  {Path: "/letters", Fields: []string{"id", "body"}},
+			"live":     {Path: "/live", Fields: []string{"id"}},
+			"recent":   {Path: "/recent", Fields: []string{"id"}},
+			"volatile": {Path: "/volatile", Fields: []string{"id"}},
+		},
+		Cache: cache,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	if manager.cache != nil {
+		manager.cache.now = func() time.Time { return now }
+	}
+	return manager, up, &now
+}
+
+func query(m *Manager, table string, extra map[string]interface{}) (*port.QueryResult, error) {
+	q := map[string]interface{}{"table": table}
+	for k, v := range extra {
+		q[k] = v
+	}
+	return m.Query(context.Background(), port.QueryRequest{BizName: "remote", Query: q})
+}
+
+func TestLoadOptions(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadOptions(dir)
+	assert.Error(t, err, "缺少 rest.json")
+
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, OptionsFilename), []byte(content), 0o644))
+	}
+	write(`{"base_url": "ftp://example.org", "tables": {"books": {"path": "/books", "fields": ["id"]}}}`)
+	_, err = LoadOptions(dir)
+	assert.Error(t, err)
+	write(`{"base_url": "https://example.org", "tables": {"books": {"path": "/books", "fields": ["id"], "primary_key": "isbn"}}}`)
+	_, err = LoadOptions(dir)
+	assert.Error(t, err)
+	write(`{"base_url": "https://example.org", "cache": {"ttl": "soon"}, "tables": {"books": {"path": "/books", "fields": ["id"]}}}`)
+	_, err = LoadOptions(dir)
+	assert.Error(t, err)
+
+	write(`{"base_url": "https://example.org/api/", "cache": {"ttl": "5m", "offline_fallback": true},
+		"tables": {"books": {"path": "/books", "fields": ["id", "title"]}}}`)
+	opts, err := LoadOptions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/api", opts.BaseURL)
+	assert.Equal(t, Duration(5*time.Minute), opts.Cache.TTL)
+	assert.Equal(t, Duration(defaultMaxStale), opts.Cache.MaxStale)
+	assert.Equal(t, defaultMaxEntries, opts.Cache.MaxEntries)
+	assert.True(t, opts.Cache.OfflineFallback)
+	assert.Equal(t, Duration(defaultTimeout), opts.Timeout)
+	assert.Equal(t, "page", opts.Tables["books"].PageParam)
+}
+
+func TestQuery_TranslatesRequest(t *testing.T) {
+	manager, up, _ := newTestManager(t, CacheOptions{Disabled: true})
+
+	result, err := query(manager, "books", map[string]interface{}{
+		"page":             float64(2),
+		"size":             float64(10),
+		"filters":          []interface{}{map[string]interface{}{"field": "author", "value": "钱钟书"}},
+		"fields_to_return": []interface{}{"title", "secret"},
+	})
+	require.NoError(t, err)
+	req := up.last()
+	assert.Equal(t, "/v1/books", req.URL.Path)
+	assert.Equal(t, "author=%E9%92%B1%E9%92%9F%E4%B9%A6&page=2&per_page=10", req.URL.RawQuery)
+	assert.Equal(t, "application/json", req.Header.Get("Accept"))
+	assert.Equal(t, int64(42), result.Data["total"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"title": "围城"}}, result.Data["items"], "只返回声明的字段")
+	assert.Equal(t, "rest", result.Source)
+
+	// 响应本身是数组且未配置 total 时以本页记录数为总数
+	result, err = query(manager, "letters", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Data["total"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(7), "body": "见字如面"}}, result.Data["items"])
+
+	for name, filter := range map[string]map[string]interface{}{
+		"未知字段": {"field": "secret", "value": "x"},
+		"模糊匹配": {"field": "title", "value": "围", "fuzzy": true},
+		"运算符":  {"field": "title", "op": "is_null"},
+		"或组合":  {"field": "title", "value": "围城", "logic": "or"},
+		"取反":   {"field": "title", "value": "围城", port.FilterNegateKey: true},
+		"缺少取值": {"field": "title"},
+	} {
+		_, err := query(manager, "books", map[string]interface{}{"filters": []interface{}{filter}})
+		assert.Error(t, err, name)
+	}
+	_, err = query(manager, "missing", nil)
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = manager.Query(context.Background(), port.QueryRequest{BizName: "other", Query: map[string]interface{}{"table": "books"}})
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = query(manager, "books", map[string]interface{}{port.QueryModeKey: port.QueryModeChanges})
+	assert.Error(t, err)
+}
+
+func TestQuery_RevalidatesWithETag(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute)})
+
+	_, err := query(manager, "books", nil)
+	require.NoError(t, err)
+	_, err = query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, up.count(), "TTL 内直接使用缓存的响应")
+
+	*now = now.Add(2 * time.Minute)
+	result, err := query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count())
+	assert.Equal(t, `"v1"`, up.last().Header.Get("If-None-Match"))
+	assert.Equal(t, int64(42), result.Data["total"], "304 时使用缓存的响应")
+	assert.Nil(t, result.Data[port.ResultStaleKey])
+
+	// 确认后重新计算 TTL
+	_, err = query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count())
+
+	// 上游内容变化后返回新的响应与 ETag
+	up.set(`"v2"`, 0)
+	*now = now.Add(2 * time.Minute)
+	_, err = query(manager, "books", nil)
+	require.NoError(t, err)
+	*now = now.Add(2 * time.Minute)
+	_, err = query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"v2"`, up.last().Header.Get("If-None-Match"))
+
+	assert.Equal(t, CacheStats{Entries: 1, Hits: 2, Revalidated: 2, Misses: 2}, manager.CacheStats())
+}
+
+func TestQuery_RevalidatesWithLastModified(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute)})
+
+	_, err := query(manager, "letters", nil)
+	require.NoError(t, err)
+	*now = now.Add(2 * time.Minute)
+	result, err := query(manager, "letters", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", up.last().Header.Get("If-Modified-Since"))
+	assert.Equal(t, int64(1), result.Data["total"])
+	assert.EqualValues(t, 1, manager.CacheStats().Revalidated)
+}
+
+func TestQuery_NoStoreIsNotCached(t *testing.T) {
+	manager, up, _ := newTestManager(t, CacheOptions{})
+	for i := 0; i < 2; i++ {
+		_, err := query(manager, "live", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, up.count())
+	assert.Zero(t, manager.CacheStats().Entries)
+}
+
+func TestQuery_MaxAgeShortensTTL(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute)})
+
+	_, err := query(manager, "recent", nil)
+	require.NoError(t, err)
+	*now = now.Add(20 * time.Second)
+	_, err = query(manager, "recent", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, up.count(), "max-age 内直接使用缓存的响应")
+
+	*now = now.Add(20 * time.Second)
+	_, err = query(manager, "recent", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count(), "超过上游 max-age 后即使仍在 TTL 内也向上游确认")
+	assert.Equal(t, `"v1"`, up.last().Header.Get("If-None-Match"))
+
+	// max-age 长于 TTL 时以 TTL 为准
+	manager, up, now = newTestManager(t, CacheOptions{TTL: Duration(10 * time.Second)})
+	_, err = query(manager, "recent", nil)
+	require.NoError(t, err)
+	*now = now.Add(20 * time.Second)
+	_, err = query(manager, "recent", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count())
+}
+
+func TestQuery_NoCacheRevalidatesBeforeServing(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute), MaxStale: Duration(time.Hour), OfflineFallback: true})
+
+	_, err := query(manager, "volatile", nil)
+	require.NoError(t, err)
+	result, err := query(manager, "volatile", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count(), "no-cache 的响应每次使用前都向上游确认")
+	assert.Equal(t, `"v1"`, up.last().Header.Get("If-None-Match"))
+	assert.Equal(t, int64(1), result.Data["total"])
+	assert.Equal(t, CacheStats{Entries: 1, Revalidated: 1, Misses: 1}, manager.CacheStats())
+
+	// 保存的响应仍可用于离线降级
+	up.set(`"v1"`, http.StatusServiceUnavailable)
+	*now = now.Add(10 * time.Minute)
+	result, err = query(manager, "volatile", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Data[port.ResultStaleKey])
+}
+
+func TestAdminMutate_UpstreamCache(t *testing.T) {
+	manager, up, _ := newTestManager(t, CacheOptions{TTL: Duration(time.Minute)})
+	_, err := query(manager, "books", nil)
+	require.NoError(t, err)
+	adminMutate := func(step string) (*port.MutateResult, error) {
+		return port.AdminMutate(context.Background(), manager, port.MutateRequest{
+			BizName: "remote", Operation: port.MutateOpUpstreamCache, Payload: map[string]interface{}{"step": step},
+		})
+	}
+
+	result, err := adminMutate("stats")
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Data["enabled"])
+	assert.Equal(t, 1, result.Data["cache"].(map[string]interface{})["entries"])
+
+	result, err = adminMutate("purge")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Data["purged"])
+	assert.Zero(t, manager.CacheStats().Entries)
+	_, err = query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, up.count(), "清空后重新请求上游")
+	assert.Empty(t, up.last().Header.Get("If-None-Match"))
+
+	_, err = adminMutate("rebuild")
+	assert.Error(t, err)
+	_, err = port.AdminMutate(context.Background(), manager, port.MutateRequest{BizName: "remote", Operation: port.MutateOpCompact})
+	assert.ErrorIs(t, err, port.ErrAdminMutateUnsupported)
+}
+
+func TestQuery_CallerCancelDoesNotFailSharedFetch(t *testing.T) {
+	manager, _, _ := newTestManager(t, CacheOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := manager.Query(ctx, port.QueryRequest{BizName: "remote", Query: map[string]interface{}{"table": "books"}})
+	require.NoError(t, err, "上游请求不随调用方的 ctx 取消")
+	assert.Equal(t, int64(42), result.Data["total"])
+}
+
+func TestQuery_OfflineFallback(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute), MaxStale: Duration(time.Hour), OfflineFallback: true})
+	validatedAt := *now
+
+	_, err := query(manager, "books", nil)
+	require.NoError(t, err)
+
+	up.set(`"v1"`, http.StatusServiceUnavailable)
+	*now = now.Add(30 * time.Minute)
+	result, err := query(manager, "books", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Data[port.ResultStaleKey])
+	assert.Equal(t, validatedAt.UTC().Format(time.RFC3339), result.Data[port.ResultCachedAtKey])
+	assert.Equal(t, int64(42), result.Data["total"])
+	assert.EqualValues(t, 1, manager.CacheStats().Stale)
+
+	// 没有缓存过的查询无法降级
+	_, err = query(manager, "letters", nil)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+	// 超过 TTL + MaxStale 的响应不再用于降级
+	*now = now.Add(time.Hour)
+	_, err = query(manager, "books", nil)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+	// 上游的 4xx 是明确的回答，不触发降级
+	up.set(`"v1"`, http.StatusNotFound)
+	*now = validatedAt.Add(30 * time.Minute)
+	_, err = query(manager, "books", nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestQuery_NoFallbackWhenDisabled(t *testing.T) {
+	manager, up, now := newTestManager(t, CacheOptions{TTL: Duration(time.Minute)})
+	_, err := query(manager, "books", nil)
+	require.NoError(t, err)
+
+	up.set(`"v1"`, http.StatusBadGateway)
+	*now = now.Add(2 * time.Minute)
+	_, err = query(manager, "books", nil)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	manager, up, _ := newTestManager(t, CacheOptions{})
+	require.NoError(t, manager.HealthCheck(ctx))
+	up.set(`"v1"`, http.StatusServiceUnavailable)
+	assert.ErrorIs(t, manager.HealthCheck(ctx), ErrUpstreamUnavailable)
+
+	// 开启离线降级时插件仍可回答查询，健康检查不报告上游的故障
+	fallback, up, _ := newTestManager(t, CacheOptions{OfflineFallback: true})
+	up.set(`"v1"`, http.StatusServiceUnavailable)
+	assert.NoError(t, fallback.HealthCheck(ctx))
+}
+
+func TestSchemaAndMutate(t *testing.T) {
+	ctx := context.Background()
+	manager, _, _ := newTestManager(t, CacheOptions{})
+
+	schema, err := manager.GetSchema(ctx, port.SchemaRequest{BizName: "remote", TableName: "books"})
+	require.NoError(t, err)
+	require.Len(t, schema.Tables, 1)
+	require.Len(t, schema.Tables["books"], 3)
+	assert.True(t, schema.Tables["books"][0].IsPrimary)
+	assert.Equal(t, []string{"id"}, schema.RecordKey("books"))
+
+	schema, err = manager.GetSchema(ctx, port.SchemaRequest{BizName: "remote"})
+	require.NoError(t, err)
+	assert.Len(t, schema.Tables, 5)
+	assert.Empty(t, schema.RecordKey("letters"))
+	_, err = manager.GetSchema(ctx, port.SchemaRequest{BizName: "remote", TableName: "missing"})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+
+	_, err = manager.Mutate(ctx, port.MutateRequest{BizName: "remote", Operation: "create"})
+	assert.ErrorIs(t, err, port.ErrPermissionDenied)
+	_, err = port.AdminMutate(ctx, manager, port.MutateRequest{BizName: "remote", Operation: port.MutateOpReplicate})
+	assert.ErrorIs(t, err, port.ErrAdminMutateUnsupported)
+}
@@ -0,0 +1,94 @@
+// Package sharded file: internal/adapter/datasource/sharded/ranges.go
+package sharded
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyRanges 是按范围下界排序的分片范围，numeric 为 true 时按数值比较分片键
+type keyRanges struct {
+	shards  []domain.Shard
+	numeric bool
+}
+
+// parseRanges 校验分片范围：边界须全部为数字或全部为文本，每个范围非空且互不重叠
+func parseRanges(shards []domain.Shard) (*keyRanges, error) {
+	var numbers, texts int
+	for _, s := range shards {
+		for _, bound := range []string{s.From, s.To} {
+			if bound == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(bound, 64); err == nil {
+				numbers++
+			} else {
+				texts++
+			}
+		}
+	}
+	if numbers > 0 && texts > 0 {
+		return nil, fmt.Errorf("分片范围的边界必须全部为数字或全部为文本")
+	}
+	r := &keyRanges{shards: append([]domain.Shard(nil), shards...), numeric: numbers > 0}
+
+	for _, s := range r.shards {
+		if s.From != "" && s.To != "" && r.compare(s.From, s.To) >= 0 {
+			return nil, fmt.Errorf("分片 '%s' 的范围 [%s, %s) 为空", s.SourceBiz, s.From, s.To)
+		}
+	}
+	sort.SliceStable(r.shards, func(i, j int) bool {
+		a, b := r.shards[i].From, r.shards[j].From
+		if a == "" || b == "" {
+			return a == "" && b != ""
+		}
+		return r.compare(a, b) < 0
+	})
+	for i := 1; i < len(r.shards); i++ {
+		prev, cur := r.shards[i-1], r.shards[i]
+		if prev.To == "" || cur.From == "" || r.compare(prev.To, cur.From) > 0 {
+			return nil, fmt.Errorf("分片 '%s' 与 '%s' 的范围重叠", prev.SourceBiz, cur.SourceBiz)
+		}
+	}
+	return r, nil
+}
+
+// compare 按分片键的比较方式比较两个值；数值模式下无法解析为数字的值排在所有数字之后
+func (r *keyRanges) compare(a, b string) int {
+	if r.numeric {
+		af, errA := strconv.ParseFloat(a, 64)
+		bf, errB := strconv.ParseFloat(b, 64)
+		switch {
+		case errA != nil && errB != nil:
+			return strings.Compare(a, b)
+		case errA != nil:
+			return 1
+		case errB != nil:
+			return -1
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// shardFor 返回分片键取值所在的分片，不落在任何范围内时返回 false
+func (r *keyRanges) shardFor(value string) (domain.Shard, bool) {
+	if r.numeric {
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return domain.Shard{}, false
+		}
+	}
+	for _, s := range r.shards {
+		if (s.From == "" || r.compare(value, s.From) >= 0) && (s.To == "" || r.compare(value, s.To) < 0) {
+			return s, true
+		}
+	}
+	return domain.Shard{}, false
+}
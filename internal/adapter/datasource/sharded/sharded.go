@@ -0,0 +1,414 @@
+// Package sharded file: internal/adapter/datasource/sharded/sharded.go
+package sharded
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LookupFunc 按业务组名解析分片的数据源。每次请求时调用，因此分片的插件重启后无需重新注册分片业务组。
+type LookupFunc func(bizName string) (port.DataSource, bool)
+
+// DataSource 把分片业务组的请求路由到分片键所在的分片，分片键未被约束时扇出到全部分片并合并结果
+type DataSource struct {
+	def    domain.ShardedBiz
+	ranges *keyRanges
+	lookup LookupFunc
+}
+
+// New 根据分片业务组定义创建一个数据源，分片范围无效时返回错误
+func New(def domain.ShardedBiz, lookup LookupFunc) (*DataSource, error) {
+	ranges, err := parseRanges(def.Shards)
+	if err != nil {
+		return nil, err
+	}
+	return &DataSource{def: def, ranges: ranges, lookup: lookup}, nil
+}
+
+// Type 返回适配器的类型标识符
+func (d *DataSource) Type() string {
+	return "sharded"
+}
+
+// allShards 返回按范围排序的全部分片
+func (d *DataSource) allShards() []domain.Shard {
+	return d.ranges.shards
+}
+
+// route 按过滤条件中对分片键的精确约束选出可能包含匹配记录的分片。
+// 过滤条件按从左到右的顺序拼接，只要出现 OR 逻辑，分片键的约束就可能被绕过，此时扇出到全部分片
+func (d *DataSource) route(rawFilters interface{}) []domain.Shard {
+	filters, _ := rawFilters.([]interface{})
+	for _, f := range filters {
+		fm, ok := f.(map[string]interface{})
+		if !ok {
+			// 格式错误由分片的数据源报告
+			return d.allShards()
+		}
+		if logic, _ := fm["logic"].(string); strings.EqualFold(logic, "OR") {
+			return d.allShards()
+		}
+	}
+
+	var selected map[string]bool // 为 nil 表示分片键未被约束
+	for _, f := range filters {
+		values, ok := exactValues(f.(map[string]interface{}), d.def.ShardKey)
+		if !ok {
+			continue
+		}
+		hit := make(map[string]bool)
+		for _, v := range values {
+			if s, found := d.ranges.shardFor(v); found {
+				hit[s.SourceBiz] = true
+			}
+		}
+		if selected == nil {
+			selected = hit
+			continue
+		}
+		for biz := range selected {
+			if !hit[biz] {
+				delete(selected, biz)
+			}
+		}
+	}
+	if selected == nil {
+		return d.allShards()
+	}
+	shards := make([]domain.Shard, 0, len(selected))
+	for _, s := range d.allShards() {
+		if selected[s.SourceBiz] {
+			shards = append(shards, s)
+		}
+	}
+	return shards
+}
+
+// exactValues 返回过滤条件对 field 的精确取值约束：等值条件的 value 与候选值，或 in 条件的候选值。
+// 模糊、拼音、全文、取反、忽略大小写或变音符号以及空值判断的条件不能用于选择分片
+func exactValues(fm map[string]interface{}, field string) ([]string, bool) {
+	if name, _ := fm["field"].(string); name != field {
+		return nil, false
+	}
+	for _, key := range []string{"fuzzy", "pinyin", port.FilterFullTextKey, port.FilterNegateKey, port.FilterCaseInsensitiveKey, port.FilterAccentInsensitiveKey} {
+		if on, _ := fm[key].(bool); on {
+			return nil, false
+		}
+	}
+	var values []string
+	op, _ := fm["op"].(string)
+	switch op {
+	case "":
+		values = append(values, fmt.Sprintf("%v", fm["value"]))
+	case "in":
+	default:
+		return nil, false
+	}
+	if candidates, ok := fm[port.FilterValuesKey].([]interface{}); ok {
+		for _, v := range candidates {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+	return values, len(values) > 0
+}
+
+// shardResult 是一个分片的查询结果
+type shardResult struct {
+	shard  domain.Shard
+	result *port.QueryResult
+	err    error
+}
+
+// queryShards 并发地向各分片发送同一查询
+func (d *DataSource) queryShards(ctx context.Context, shards []domain.Shard, req port.QueryRequest) []shardResult {
+	results := make([]shardResult, len(shards))
+	var wg sync.WaitGroup
+	for i, s := range shards {
+		results[i].shard = s
+		ds, loaded := d.lookup(s.SourceBiz)
+		if !loaded {
+			results[i].err = fmt.Errorf("分片业务组 '%s' 当前未注册", s.SourceBiz)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, ds port.DataSource) {
+			defer wg.Done()
+			results[i].result, results[i].err = ds.Query(ctx, port.QueryRequest{BizName: shards[i].SourceBiz, Query: req.Query, Extensions: req.Extensions})
+		}(i, ds)
+	}
+	wg.Wait()
+	return results
+}
+
+// Query 把查询路由到相关分片。只涉及一个分片时原样返回该分片的结果；涉及多个分片时合并结果，
+// 合并方式与多库业务组合并各库结果相同：分页按分片分别进行，total 为各分片之和
+func (d *DataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	mode, _ := req.Query[port.QueryModeKey].(string)
+	switch mode {
+	case port.QueryModeChanges, port.QueryModeCompleteness, port.QueryModeVocabulary, port.QueryModeStats:
+		return nil, fmt.Errorf("分片业务组 '%s' 不提供 '%s' 模式，请直接读取各分片业务组", d.def.BizName, mode)
+	}
+
+	shards := d.route(req.Query["filters"])
+	if mode == port.QueryModeRecord {
+		// 按主键读取时没有分片键的约束，只能逐个分片查找
+		shards = d.allShards()
+	}
+	if len(shards) == 0 {
+		if mode == port.QueryModeRecord {
+			return &port.QueryResult{Data: map[string]interface{}{"record": nil, "matches": 0}, Source: d.Type()}, nil
+		}
+		return &port.QueryResult{Data: map[string]interface{}{"items": []interface{}{}, "total": 0}, Source: d.Type()}, nil
+	}
+	if len(shards) == 1 {
+		ds, loaded := d.lookup(shards[0].SourceBiz)
+		if !loaded {
+			return nil, fmt.Errorf("分片业务组 '%s' 当前未注册", shards[0].SourceBiz)
+		}
+		return ds.Query(ctx, port.QueryRequest{BizName: shards[0].SourceBiz, Query: req.Query, Extensions: req.Extensions})
+	}
+
+	results := d.queryShards(ctx, shards, req)
+	if mode == port.QueryModeRecord {
+		return d.mergeRecord(results)
+	}
+	return d.mergeItems(req.Query, results)
+}
+
+// mergeRecord 返回第一个找到的记录，matches 为各分片之和
+func (d *DataSource) mergeRecord(results []shardResult) (*port.QueryResult, error) {
+	var record interface{}
+	var matches float64
+	for _, r := range results {
+		if r.err != nil {
+			return nil, fmt.Errorf("在分片 '%s' 中查找记录失败: %w", r.shard.SourceBiz, r.err)
+		}
+		matches += number(r.result.Data["matches"])
+		if rec := r.result.Data["record"]; record == nil && rec != nil {
+			record = rec
+		}
+	}
+	return &port.QueryResult{Data: map[string]interface{}{"record": record, "matches": matches}, Source: d.Type()}, nil
+}
+
+// mergeItems 合并各分片的分页结果。非严格模式下跳过失败的分片并在 warnings 中列出，
+// 每项的 "lib" 为分片业务组名，分片自身报告的失败库以 "分片/库" 的形式列出
+func (d *DataSource) mergeItems(query map[string]interface{}, results []shardResult) (*port.QueryResult, error) {
+	strict, _ := query[port.QueryStrictKey].(bool)
+	items := make([]interface{}, 0)
+	var total float64
+	var truncated bool
+	var warnings, explains []interface{}
+	var firstErr error
+	succeeded := 0
+	for _, r := range results {
+		if r.err != nil {
+			if strict {
+				return nil, fmt.Errorf("分片 '%s' 查询失败: %w", r.shard.SourceBiz, r.err)
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("分片 '%s' 查询失败: %w", r.shard.SourceBiz, r.err)
+			}
+			warnings = append(warnings, map[string]interface{}{"lib": r.shard.SourceBiz, "error": r.err.Error()})
+			continue
+		}
+		succeeded++
+		data := r.result.Data
+		if rows, ok := data["items"].([]interface{}); ok {
+			items = append(items, rows...)
+		}
+		total += number(data["total"])
+		if t, _ := data[port.ResultTruncatedKey].(bool); t {
+			truncated = true
+		}
+		if failed, ok := data[port.ResultWarningsKey].([]interface{}); ok {
+			for _, w := range failed {
+				if wm, ok := w.(map[string]interface{}); ok {
+					lib, _ := wm["lib"].(string)
+					warnings = append(warnings, map[string]interface{}{"lib": r.shard.SourceBiz + "/" + lib, "error": wm["error"]})
+				}
+			}
+		}
+		if explain, ok := data["explain"]; ok {
+			explains = append(explains, map[string]interface{}{"shard": r.shard.SourceBiz, "explain": explain})
+		}
+	}
+	if succeeded == 0 {
+		return nil, firstErr
+	}
+
+	if collapseBy, _ := query[port.QueryCollapseKey].(string); collapseBy != "" {
+		items = mergeGroups(items, collapseBy)
+	} else if distinct, _ := query[port.QueryDistinctKey].(bool); distinct {
+		items = dedupe(items)
+	}
+	if sortKey, _ := query[port.QuerySortKey].(string); sortKey == port.ResultScoreField {
+		sort.SliceStable(items, func(i, j int) bool { return score(items[i]) > score(items[j]) })
+	}
+	if sample, ok := query[port.QuerySampleKey].(float64); ok && len(items) > int(sample) {
+		rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+		items = items[:int(sample)]
+	}
+
+	data := map[string]interface{}{"items": items, "total": total}
+	if truncated {
+		data[port.ResultTruncatedKey] = true
+	}
+	if len(warnings) > 0 {
+		data[port.ResultWarningsKey] = warnings
+	}
+	if len(explains) > 0 {
+		data["explain"] = map[string]interface{}{"shards": explains}
+	}
+	return &port.QueryResult{Data: data, Source: d.Type()}, nil
+}
+
+// mergeGroups 归并各分片中折叠字段取值相同的分组：保留得分较高 (同分时先出现) 的代表记录，累加组内记录数
+func mergeGroups(items []interface{}, collapseBy string) []interface{} {
+	index := make(map[string]int, len(items))
+	merged := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			merged = append(merged, item)
+			continue
+		}
+		key := fmt.Sprintf("%v", row[collapseBy])
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(merged)
+			merged = append(merged, row)
+			continue
+		}
+		kept := merged[i].(map[string]interface{})
+		count := number(kept[port.ResultGroupCountField]) + number(row[port.ResultGroupCountField])
+		if score(row) > score(kept) {
+			kept = row
+		}
+		copied := make(map[string]interface{}, len(kept))
+		for k, v := range kept {
+			copied[k] = v
+		}
+		copied[port.ResultGroupCountField] = count
+		merged[i] = copied
+	}
+	return merged
+}
+
+// dedupe 去除各分片之间重复的行
+func dedupe(items []interface{}) []interface{} {
+	seen := make(map[string]bool, len(items))
+	out := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		key, err := json.Marshal(item)
+		if err == nil && seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		out = append(out, item)
+	}
+	return out
+}
+
+func score(item interface{}) float64 {
+	row, _ := item.(map[string]interface{})
+	return number(row[port.ResultScoreField])
+}
+
+// number 读取结果中的数值，经 gRPC 传输后为 float64，嵌入式数据源返回整数
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// Mutate 把写操作路由到分片：create 写入 data 中分片键所在的分片，update 与 delete 按过滤条件选择分片，
+// 分片键未被约束时依次作用于全部分片 (各分片分别提交，不保证跨分片的原子性)。
+// 分片键不能通过 update 修改，管理操作需直接对各分片业务组执行
+func (d *DataSource) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	var shards []domain.Shard
+	data, _ := req.Payload["data"].(map[string]interface{})
+	switch req.Operation {
+	case "create":
+		value, exists := data[d.def.ShardKey]
+		if !exists || value == nil {
+			return nil, fmt.Errorf("写入分片业务组 '%s' 的记录必须包含分片键 '%s'", d.def.BizName, d.def.ShardKey)
+		}
+		s, found := d.ranges.shardFor(fmt.Sprintf("%v", value))
+		if !found {
+			return nil, fmt.Errorf("分片键 '%s' 的取值 '%v' 不在任何分片的范围内", d.def.ShardKey, value)
+		}
+		shards = []domain.Shard{s}
+	case "update", "delete":
+		if _, changesKey := data[d.def.ShardKey]; changesKey {
+			return nil, fmt.Errorf("分片键 '%s' 不能通过 update 修改，请删除后重新创建记录", d.def.ShardKey)
+		}
+		shards = d.route(req.Payload["filters"])
+	default:
+		return nil, fmt.Errorf("分片业务组 '%s' 不支持 '%s' 操作，请直接对各分片业务组执行", d.def.BizName, req.Operation)
+	}
+
+	var affected float64
+	source := d.Type()
+	for _, s := range shards {
+		ds, loaded := d.lookup(s.SourceBiz)
+		if !loaded {
+			return nil, fmt.Errorf("分片业务组 '%s' 当前未注册", s.SourceBiz)
+		}
+		result, err := ds.Mutate(ctx, port.MutateRequest{BizName: s.SourceBiz, Operation: req.Operation, Payload: req.Payload})
+		if err != nil {
+			if len(shards) == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("在分片 '%s' 上执行写操作失败 (此前的分片已提交): %w", s.SourceBiz, err)
+		}
+		if len(shards) == 1 {
+			return result, nil
+		}
+		affected += number(result.Data["rows_affected"])
+	}
+	return &port.MutateResult{Data: map[string]interface{}{"rows_affected": affected}, Source: source}, nil
+}
+
+// AdminMutate 分片业务组没有自己的库文件，管理操作需对各分片业务组执行
+func (d *DataSource) AdminMutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return nil, fmt.Errorf("%w: 分片业务组 '%s' 不支持 '%s'，请直接对各分片业务组执行", port.ErrAdminMutateUnsupported, d.def.BizName, req.Operation)
+}
+
+// GetSchema 返回第一个已注册分片的结构信息，各分片的表结构应相同
+func (d *DataSource) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	for _, s := range d.allShards() {
+		if ds, loaded := d.lookup(s.SourceBiz); loaded {
+			return ds.GetSchema(ctx, port.SchemaRequest{BizName: s.SourceBiz, TableName: req.TableName})
+		}
+	}
+	return nil, errors.New("分片业务组的所有分片当前均未注册")
+}
+
+// HealthCheck 只有全部分片均已注册且健康时才视为健康
+func (d *DataSource) HealthCheck(ctx context.Context) error {
+	for _, s := range d.allShards() {
+		ds, loaded := d.lookup(s.SourceBiz)
+		if !loaded {
+			return fmt.Errorf("分片业务组 '%s' 当前未注册", s.SourceBiz)
+		}
+		if err := ds.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("分片业务组 '%s' 不健康: %w", s.SourceBiz, err)
+		}
+	}
+	return nil
+}
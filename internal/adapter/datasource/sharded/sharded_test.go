@@ -0,0 +1,178 @@
+// file: internal/adapter/datasource/sharded/sharded_test.go
+package sharded
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shardStub 记录收到的请求，并返回预设的结果
+type shardStub struct {
+	mu       sync.Mutex
+	queries  int
+	mutates  []port.MutateRequest
+	items    []interface{}
+	queryErr error
+}
+
+func (s *shardStub) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries++
+	if s.queryErr != nil {
+		return nil, s.queryErr
+	}
+	return &port.QueryResult{Data: map[string]interface{}{"items": s.items, "total": float64(len(s.items))}, Source: req.BizName}, nil
+}
+func (s *shardStub) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mutates = append(s.mutates, req)
+	return &port.MutateResult{Data: map[string]interface{}{"rows_affected": float64(1)}}, nil
+}
+func (s *shardStub) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return &port.SchemaResult{}, nil
+}
+func (s *shardStub) HealthCheck(context.Context) error { return nil }
+func (s *shardStub) Type() string                      { return "stub" }
+
+func newTestSharded(t *testing.T) (*DataSource, map[string]*shardStub) {
+	stubs := map[string]*shardStub{
+		"letters_old": {items: []interface{}{map[string]interface{}{"year": 1890, "_score": 1.0}}},
+		"letters_mid": {items: []interface{}{map[string]interface{}{"year": 1930, "_score": 3.0}}},
+		"letters_new": {items: []interface{}{map[string]interface{}{"year": 1990, "_score": 2.0}}},
+	}
+	def := domain.ShardedBiz{
+		BizName:  "letters",
+		ShardKey: "year",
+		Shards: []domain.Shard{
+			{SourceBiz: "letters_new", From: "1950"},
+			{SourceBiz: "letters_old", To: "1900"},
+			{SourceBiz: "letters_mid", From: "1900", To: "1950"},
+		},
+	}
+	ds, err := New(def, func(biz string) (port.DataSource, bool) {
+		s, ok := stubs[biz]
+		return s, ok
+	})
+	require.NoError(t, err)
+	return ds, stubs
+}
+
+func filter(field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"field": field, "value": value}
+}
+
+func TestParseRanges(t *testing.T) {
+	_, err := parseRanges([]domain.Shard{{SourceBiz: "a", To: "10"}, {SourceBiz: "b", From: "5"}})
+	assert.Error(t, err, "范围重叠")
+	_, err = parseRanges([]domain.Shard{{SourceBiz: "a", To: "10"}, {SourceBiz: "b", From: "m"}})
+	assert.Error(t, err, "数字与文本边界混用")
+	_, err = parseRanges([]domain.Shard{{SourceBiz: "a", From: "10", To: "10"}})
+	assert.Error(t, err, "空范围")
+
+	r, err := parseRanges([]domain.Shard{{SourceBiz: "b", From: "9"}, {SourceBiz: "a", To: "9"}})
+	require.NoError(t, err)
+	s, ok := r.shardFor("10")
+	assert.True(t, ok)
+	assert.Equal(t, "b", s.SourceBiz, "数值边界按数值而非字符串比较")
+	s, _ = r.shardFor("8.5")
+	assert.Equal(t, "a", s.SourceBiz)
+	_, ok = r.shardFor("unknown")
+	assert.False(t, ok)
+
+	gap, err := parseRanges([]domain.Shard{{SourceBiz: "a", From: "a", To: "h"}, {SourceBiz: "b", From: "m"}})
+	require.NoError(t, err)
+	_, ok = gap.shardFor("k")
+	assert.False(t, ok, "落在范围间隙中的取值不属于任何分片")
+}
+
+func TestRoute(t *testing.T) {
+	ds, _ := newTestSharded(t)
+	names := func(shards []domain.Shard) []string {
+		out := make([]string, 0, len(shards))
+		for _, s := range shards {
+			out = append(out, s.SourceBiz)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"letters_old", "letters_mid", "letters_new"}, names(ds.route(nil)))
+	assert.Equal(t, []string{"letters_mid"}, names(ds.route([]interface{}{filter("year", float64(1930))})))
+	assert.Equal(t, []string{"letters_old", "letters_new"}, names(ds.route([]interface{}{
+		map[string]interface{}{"field": "year", "op": "in", "values": []interface{}{"1850", "1999"}},
+	})))
+	assert.Empty(t, ds.route([]interface{}{filter("year", "1930"), filter("year", "1990")}), "互斥的约束不匹配任何分片")
+
+	negated := filter("year", "1930")
+	negated["negate"] = true
+	assert.Len(t, ds.route([]interface{}{negated}), 3, "取反的条件不能用于选择分片")
+	or := filter("sender", "鲁迅")
+	or["logic"] = "OR"
+	assert.Len(t, ds.route([]interface{}{filter("year", "1930"), or}), 3, "出现 OR 时扇出到全部分片")
+}
+
+func TestQuery_RoutesAndMerges(t *testing.T) {
+	ctx := context.Background()
+	ds, stubs := newTestSharded(t)
+
+	res, err := ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"filters": []interface{}{filter("year", "1930")}}})
+	require.NoError(t, err)
+	assert.Equal(t, "letters_mid", res.Source, "单个分片时原样返回分片的结果")
+	assert.Equal(t, 0, stubs["letters_old"].queries)
+
+	res, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"sort": "_score"}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), res.Data["total"])
+	items := res.Data["items"].([]interface{})
+	require.Len(t, items, 3)
+	assert.Equal(t, 1930, items[0].(map[string]interface{})["year"], "按相关度排序时合并后重新排序")
+
+	stubs["letters_new"].queryErr = errors.New("库文件损坏")
+	res, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), res.Data["total"])
+	assert.Len(t, res.Data[port.ResultWarningsKey], 1)
+
+	_, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{port.QueryStrictKey: true}})
+	assert.Error(t, err, "严格模式下任一分片失败即整体失败")
+
+	_, err = ds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{port.QueryModeKey: port.QueryModeStats}})
+	assert.Error(t, err)
+}
+
+func TestMutate_RoutesByShardKey(t *testing.T) {
+	ctx := context.Background()
+	ds, stubs := newTestSharded(t)
+
+	_, err := ds.Mutate(ctx, port.MutateRequest{Operation: "create", Payload: map[string]interface{}{
+		"table_name": "letters", "data": map[string]interface{}{"year": float64(1999)},
+	}})
+	require.NoError(t, err)
+	require.Len(t, stubs["letters_new"].mutates, 1)
+	assert.Equal(t, "letters_new", stubs["letters_new"].mutates[0].BizName)
+
+	_, err = ds.Mutate(ctx, port.MutateRequest{Operation: "create", Payload: map[string]interface{}{"data": map[string]interface{}{"sender": "鲁迅"}}})
+	assert.Error(t, err, "缺少分片键的记录无法路由")
+
+	_, err = ds.Mutate(ctx, port.MutateRequest{Operation: "update", Payload: map[string]interface{}{
+		"data": map[string]interface{}{"year": float64(1800)}, "filters": []interface{}{filter("year", "1930")},
+	}})
+	assert.Error(t, err, "不能通过 update 修改分片键")
+
+	res, err := ds.Mutate(ctx, port.MutateRequest{Operation: "delete", Payload: map[string]interface{}{"filters": []interface{}{filter("sender", "鲁迅")}}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), res.Data["rows_affected"], "未约束分片键时作用于全部分片")
+
+	_, err = ds.Mutate(ctx, port.MutateRequest{Operation: "compact"})
+	assert.Error(t, err)
+	_, err = port.AdminMutate(ctx, ds, port.MutateRequest{Operation: port.MutateOpCompact})
+	assert.ErrorIs(t, err, port.ErrAdminMutateUnsupported)
+}
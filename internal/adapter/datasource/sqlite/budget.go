@@ -0,0 +1,62 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/budget.go
+package sqlite
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// rowBudget 是一次查询在各库之间共享的结果字节数预算 (见 port.QueryMaxBytesKey)，可被多个 goroutine 并发使用。
+// 为 nil 时所有方法均为空操作，未设置上限的查询无需额外判断。
+type rowBudget struct {
+	limit     int64
+	used      atomic.Int64
+	truncated atomic.Bool
+}
+
+func newRowBudget(limit int64) *rowBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &rowBudget{limit: limit}
+}
+
+// take 为一行扣除预算，超过上限时返回 false 并标记结果被截断，调用方应停止读取。
+// 各库并发扣除，截断后保留下来的是先扫描到的行，不保证与未截断时的顺序前缀一致
+func (b *rowBudget) take(row map[string]any) bool {
+	if b == nil {
+		return true
+	}
+	if b.truncated.Load() || b.used.Add(estimateRowBytes(row)) > b.limit {
+		b.truncated.Store(true)
+		return false
+	}
+	return true
+}
+
+func (b *rowBudget) exhausted() bool {
+	return b != nil && b.truncated.Load()
+}
+
+// estimateRowBytes 估算一行编码为 JSON 后的大小，只求量级接近，不做转义计算
+func estimateRowBytes(row map[string]any) int64 {
+	size := int64(2) // {}
+	for k, v := range row {
+		size += int64(len(k)) + 4 // "k":,
+		switch val := v.(type) {
+		case nil:
+			size += 4
+		case string:
+			size += int64(len(val)) + 2
+		case []byte:
+			size += int64(len(val)) + 2
+		case bool:
+			size += 5
+		case int64, float64:
+			size += 20
+		default:
+			size += int64(len(fmt.Sprint(val)))
+		}
+	}
+	return size
+}
@@ -0,0 +1,491 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/changes.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	changeLogTable = innerPrefix + "changes"
+
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// changeRecord 是变更日志中的一条记录，按库内 seq 严格递增
+type changeRecord struct {
+	Lib            string         `json:"lib"`
+	Seq            int64          `json:"seq"`
+	Table          string         `json:"table"`
+	Op             string         `json:"op"`
+	PK             map[string]any `json:"pk"`
+	ChangedColumns []string       `json:"changed_columns"`
+	Data           map[string]any `json:"data"`
+	Actor          string         `json:"actor"`
+	ChangedAt      int64          `json:"changed_at"`
+}
+
+func (r changeRecord) toMap() map[string]interface{} {
+	var changedColumns []interface{}
+	for _, col := range r.ChangedColumns {
+		changedColumns = append(changedColumns, col)
+	}
+	var data interface{}
+	if r.Data != nil {
+		data = r.Data
+	}
+	return map[string]interface{}{
+		"lib":             r.Lib,
+		"seq":             r.Seq,
+		"table":           r.Table,
+		"op":              r.Op,
+		"pk":              r.PK,
+		"changed_columns": changedColumns,
+		"data":            data,
+		"actor":           r.Actor,
+		"changed_at":      r.ChangedAt,
+	}
+}
+
+// ensureChangeLogTable 在库中创建变更日志表 (若不存在)。
+// 表名带有内部前缀，因此不会出现在 schema 发现结果中。
+func ensureChangeLogTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %q (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			table_name TEXT NOT NULL,
+			op TEXT NOT NULL,
+			pk TEXT NOT NULL,
+			changed_columns TEXT,
+			data TEXT,
+			actor TEXT,
+			changed_at INTEGER NOT NULL
+		)`, changeLogTable))
+	return err
+}
+
+// execWithCapture 在单个事务内执行写操作，并将受影响的行写入变更日志。
+// 对于 update/delete，先通过 rowid 锁定受影响的行；对于 create，使用 LastInsertId。
+// WITHOUT ROWID 表无法定位行，写操作被拒绝 (port.ErrChangeCaptureUnsupported)，
+// 以免变更未进入日志、副本悄无声息地与源库不一致。
+func execWithCapture(ctx context.Context, db *sql.DB, op, tableName, sqlStmt string, args []interface{}, data map[string]interface{}, filters []queryParam, actor string) (rowsAffected int64, err error) {
+	if err = ensureChangeLogTable(ctx, db); err != nil {
+		return 0, fmt.Errorf("创建变更日志表失败: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	withoutRowid, err := isWithoutRowid(ctx, tx, tableName)
+	if err != nil {
+		return 0, err
+	}
+	if withoutRowid {
+		return 0, fmt.Errorf("%w: 表 '%s' 为 WITHOUT ROWID 表，无法定位受影响的行", port.ErrChangeCaptureUnsupported, tableName)
+	}
+	pkCols, err := primaryKeyColumns(ctx, tx, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowIDs []int64
+	// delete 之后行已不存在，主键必须在执行前读取
+	deletedPKs := make(map[int64]map[string]any)
+	if op == "update" || op == "delete" {
+		rowIDs, deletedPKs, err = selectAffectedRows(ctx, tx, tableName, filters, pkCols, op == "delete")
+		if err != nil {
+			return 0, fmt.Errorf("定位受影响的行失败: %w", err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, sqlStmt, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, _ = res.RowsAffected()
+	if op == "create" {
+		id, idErr := res.LastInsertId()
+		if idErr != nil {
+			return 0, fmt.Errorf("%w: 无法获取表 '%s' 新行的 rowid: %v", port.ErrChangeCaptureUnsupported, tableName, idErr)
+		}
+		rowIDs = []int64{id}
+	}
+
+	var changedColumns []string
+	for col := range data {
+		changedColumns = append(changedColumns, col)
+	}
+	sort.Strings(changedColumns)
+	changedAt := time.Now().UnixMilli()
+
+	for _, rowID := range rowIDs {
+		var image map[string]any
+		pk := deletedPKs[rowID]
+		if op != "delete" {
+			image, err = selectRowImage(ctx, tx, tableName, rowID)
+			if err != nil {
+				return 0, err
+			}
+			if image == nil {
+				continue
+			}
+			pk = extractPK(image, pkCols, rowID)
+		}
+		if err = insertChangeRecord(ctx, tx, tableName, op, pk, changedColumns, image, actor, changedAt); err != nil {
+			return 0, err
+		}
+	}
+	return rowsAffected, nil
+}
+
+// rowQuerier 抽象了 *sql.DB 与 *sql.Tx 共有的查询方法
+type rowQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// isWithoutRowid 报告表是否为 WITHOUT ROWID 表
+func isWithoutRowid(ctx context.Context, q rowQuerier, tableName string) (bool, error) {
+	rows, err := q.QueryContext(ctx, `SELECT wr FROM pragma_table_list(?)`, tableName)
+	if err != nil {
+		return false, fmt.Errorf("读取表 '%s' 的结构失败: %w", tableName, err)
+	}
+	defer rows.Close()
+	withoutRowid := false
+	for rows.Next() {
+		var wr int
+		if err := rows.Scan(&wr); err != nil {
+			return false, err
+		}
+		withoutRowid = withoutRowid || wr == 1
+	}
+	return withoutRowid, rows.Err()
+}
+
+// primaryKeyColumns 按声明顺序返回表的主键列；无显式主键时返回空切片，调用方以 rowid 代替
+func primaryKeyColumns(ctx context.Context, q rowQuerier, tableName string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%q)`, tableName))
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 结构失败: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	type pkCol struct {
+		name  string
+		order int
+	}
+	var cols []pkCol
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		if pk > 0 {
+			cols = append(cols, pkCol{name: name, order: pk})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].order < cols[j].order })
+	names := make([]string, 0, len(cols))
+	for _, c := range cols {
+		names = append(names, c.name)
+	}
+	return names, nil
+}
+
+// selectAffectedRows 根据与写操作相同的过滤条件找出将被影响的行
+func selectAffectedRows(ctx context.Context, tx *sql.Tx, tableName string, filters []queryParam, pkCols []string, withPK bool) ([]int64, map[int64]map[string]any, error) {
+	whereClause, whereArgs, err := buildWhereClause(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	selectCols := `rowid`
+	if withPK {
+		for _, col := range pkCols {
+			selectCols += fmt.Sprintf(`, %q`, col)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %q %s`, selectCols, tableName, whereClause), whereArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	pks := make(map[int64]map[string]any)
+	for rows.Next() {
+		var rowID int64
+		dest := []any{&rowID}
+		pkVals := make([]any, 0, len(pkCols))
+		if withPK {
+			pkVals = make([]any, len(pkCols))
+			for i := range pkVals {
+				dest = append(dest, &pkVals[i])
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, rowID)
+		if withPK {
+			image := make(map[string]any, len(pkCols))
+			for i, col := range pkCols {
+				image[col] = normalizeValue(pkVals[i])
+			}
+			pks[rowID] = extractPK(image, pkCols, rowID)
+		}
+	}
+	return ids, pks, rows.Err()
+}
+
+// selectRowImage 读取指定 rowid 的完整行 (after-image)
+func selectRowImage(ctx context.Context, tx *sql.Tx, tableName string, rowID int64) (map[string]any, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %q WHERE rowid = ?`, tableName), rowID)
+	if err != nil {
+		return nil, fmt.Errorf("读取变更后的行数据失败: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	image := make(map[string]any, len(cols))
+	for i, col := range cols {
+		// 拼音影子列等内部生成列不属于业务数据，不写入变更日志
+		if strings.HasPrefix(col, innerPrefix) {
+			continue
+		}
+		image[col] = normalizeValue(vals[i])
+	}
+	return image, nil
+}
+
+// extractPK 从行数据中提取主键；无显式主键时使用 rowid
+func extractPK(image map[string]any, pkCols []string, rowID int64) map[string]any {
+	if len(pkCols) == 0 {
+		return map[string]any{"rowid": rowID}
+	}
+	pk := make(map[string]any, len(pkCols))
+	for _, col := range pkCols {
+		pk[col] = image[col]
+	}
+	return pk
+}
+
+func normalizeValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func insertChangeRecord(ctx context.Context, tx *sql.Tx, tableName, op string, pk map[string]any, changedColumns []string, image map[string]any, actor string, changedAt int64) error {
+	pkJSON, err := json.Marshal(pk)
+	if err != nil {
+		return fmt.Errorf("序列化主键失败: %w", err)
+	}
+	var colsJSON, dataJSON sql.NullString
+	if len(changedColumns) > 0 {
+		b, _ := json.Marshal(changedColumns)
+		colsJSON = sql.NullString{String: string(b), Valid: true}
+	}
+	if image != nil {
+		b, err := json.Marshal(image)
+		if err != nil {
+			return fmt.Errorf("序列化行数据失败: %w", err)
+		}
+		dataJSON = sql.NullString{String: string(b), Valid: true}
+	}
+	_, err = tx.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %q (table_name, op, pk, changed_columns, data, actor, changed_at) VALUES (?, ?, ?, ?, ?, ?, ?)`, changeLogTable),
+		tableName, op, string(pkJSON), colsJSON, dataJSON, actor, changedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("写入变更日志失败: %w", err)
+	}
+	return nil
+}
+
+// encodeChangesCursor / decodeChangesCursor 将各库已读取到的 seq 编码为不透明游标
+func encodeChangesCursor(pos map[string]int64) string {
+	b, _ := json.Marshal(pos)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeChangesCursor(cursor string) (map[string]int64, error) {
+	pos := make(map[string]int64)
+	if cursor == "" {
+		return pos, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("无效请求: 变更游标格式错误")
+	}
+	if err := json.Unmarshal(b, &pos); err != nil {
+		return nil, fmt.Errorf("无效请求: 变更游标格式错误")
+	}
+	return pos, nil
+}
+
+// queryChanges 读取业务组下所有库的变更日志。
+// 各库的日志按 changed_at 归并，并保证每个库内按 seq 顺序消费，游标因此不会跳过任何记录。
+func (m *Manager) queryChanges(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if !bizAdminConfig.ChangeCaptureEnabled {
+		return nil, fmt.Errorf("业务 '%s' 未启用变更捕获", bizName)
+	}
+
+	since, _ := queryMap["since"].(string)
+	positions, err := decodeChangesCursor(since)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultChangesLimit
+	if limitF, ok := queryMap["limit"].(float64); ok && limitF > 0 {
+		limit = int(limitF)
+	}
+	if limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	m.mu.RUnlock()
+
+	perLib := make(map[string][]changeRecord, len(dbInstances))
+	for libName, db := range dbInstances {
+		records, err := readChangeLog(ctx, db, libName, positions[libName], limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("读取库 '%s/%s' 的变更日志失败: %w", bizName, libName, err)
+		}
+		if len(records) > 0 {
+			perLib[libName] = records
+		}
+	}
+
+	// 结果需要经 structpb 传输，因此只能使用 map / []interface{} 等通用类型
+	items := make([]interface{}, 0, limit)
+	for len(items) < limit {
+		var pick string
+		for libName, records := range perLib {
+			if len(records) == 0 {
+				continue
+			}
+			if pick == "" {
+				pick = libName
+				continue
+			}
+			head, best := records[0], perLib[pick][0]
+			if head.ChangedAt < best.ChangedAt || (head.ChangedAt == best.ChangedAt && libName < pick) {
+				pick = libName
+			}
+		}
+		if pick == "" {
+			break
+		}
+		rec := perLib[pick][0]
+		perLib[pick] = perLib[pick][1:]
+		positions[pick] = rec.Seq
+		items = append(items, rec.toMap())
+	}
+
+	hasMore := false
+	for _, records := range perLib {
+		if len(records) > 0 {
+			hasMore = true
+			break
+		}
+	}
+
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"items":       items,
+			"next_cursor": encodeChangesCursor(positions),
+			"has_more":    hasMore,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// readChangeLog 读取单个库中 seq 大于 afterSeq 的变更记录
+func readChangeLog(ctx context.Context, db *sql.DB, libName string, afterSeq int64, limit int) ([]changeRecord, error) {
+	var exists int
+	err := db.QueryRowContext(ctx, `SELECT 1 FROM sqlite_master WHERE type='table' AND name = ?`, changeLogTable).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT seq, table_name, op, pk, changed_columns, data, actor, changed_at FROM %q WHERE seq > ? ORDER BY seq ASC LIMIT ?`, changeLogTable),
+		afterSeq, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []changeRecord
+	for rows.Next() {
+		rec := changeRecord{Lib: libName}
+		var pkJSON string
+		var colsJSON, dataJSON, actor sql.NullString
+		if err := rows.Scan(&rec.Seq, &rec.Table, &rec.Op, &pkJSON, &colsJSON, &dataJSON, &actor, &rec.ChangedAt); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(pkJSON), &rec.PK)
+		if colsJSON.Valid {
+			_ = json.Unmarshal([]byte(colsJSON.String), &rec.ChangedColumns)
+		}
+		if dataJSON.Valid {
+			_ = json.Unmarshal([]byte(dataJSON.String), &rec.Data)
+		}
+		rec.Actor = actor.String
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,163 @@
+// file: internal/adapter/datasource/sqlite/changes_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newChangeCaptureManager(t *testing.T, enabled bool) (*Manager, *sql.DB) {
+	t.Helper()
+	db := createTestDB(t, t.TempDir(), "cdc.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, author TEXT);`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "library",
+		ChangeCaptureEnabled: enabled,
+		Tables: map[string]*domain.TableConfig{
+			"books": {TableName: "books", AllowCreate: true, AllowUpdate: true, AllowDelete: true},
+		},
+	}
+	mockCfgSvc := &mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	}
+	manager := NewManager(mockCfgSvc)
+	manager.group = map[string]map[string]*sql.DB{"library": {"cdc.db": db}}
+	return manager, db
+}
+
+func TestMutate_ChangeCapture(t *testing.T) {
+	ctx := context.Background()
+	manager, _ := newChangeCaptureManager(t, true)
+
+	mutate := func(op string, payload map[string]interface{}) {
+		payload["table_name"] = "books"
+		payload[port.MutateActorKey] = "7"
+		_, err := manager.Mutate(ctx, port.MutateRequest{BizName: "library", Operation: op, Payload: payload})
+		require.NoError(t, err)
+	}
+	mutate("create", map[string]interface{}{"data": map[string]interface{}{"title": "三体", "author": "刘慈欣"}})
+	mutate("update", map[string]interface{}{
+		"data":    map[string]interface{}{"title": "三体 I"},
+		"filters": []interface{}{map[string]interface{}{"field": "id", "value": 1}},
+	})
+	mutate("delete", map[string]interface{}{
+		"filters": []interface{}{map[string]interface{}{"field": "id", "value": 1}},
+	})
+
+	result, err := manager.Query(ctx, port.QueryRequest{
+		BizName: "library",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeChanges, "limit": float64(2)},
+	})
+	require.NoError(t, err)
+	// 结果必须可以被 structpb 序列化，才能经 gRPC 插件返回
+	_, err = structpb.NewStruct(result.Data)
+	require.NoError(t, err)
+
+	items := result.Data["items"].([]interface{})
+	require.Len(t, items, 2)
+	first, second := items[0].(map[string]interface{}), items[1].(map[string]interface{})
+	assert.Equal(t, "create", first["op"])
+	assert.Equal(t, []interface{}{"author", "title"}, first["changed_columns"])
+	assert.Equal(t, "7", first["actor"])
+	assert.Equal(t, "update", second["op"])
+	assert.Equal(t, "三体 I", second["data"].(map[string]interface{})["title"])
+	assert.Equal(t, float64(1), second["pk"].(map[string]interface{})["id"])
+	assert.True(t, result.Data["has_more"].(bool))
+
+	// 使用游标继续读取，应只剩下 delete 记录
+	result, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "library",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeChanges, "since": result.Data["next_cursor"]},
+	})
+	require.NoError(t, err)
+	items = result.Data["items"].([]interface{})
+	require.Len(t, items, 1)
+	deleted := items[0].(map[string]interface{})
+	assert.Equal(t, "delete", deleted["op"])
+	assert.Equal(t, float64(1), deleted["pk"].(map[string]interface{})["id"])
+	assert.Nil(t, deleted["data"])
+	assert.False(t, result.Data["has_more"].(bool))
+}
+
+func TestMutate_ChangeCaptureDisabled(t *testing.T) {
+	ctx := context.Background()
+	manager, db := newChangeCaptureManager(t, false)
+
+	_, err := manager.Mutate(ctx, port.MutateRequest{
+		BizName:   "library",
+		Operation: "create",
+		Payload: map[string]interface{}{
+			"table_name": "books",
+			"data":       map[string]interface{}{"title": "活着"},
+		},
+	})
+	require.NoError(t, err)
+
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, changeLogTable).Scan(&count)
+	require.NoError(t, err)
+	assert.Zero(t, count, "未启用变更捕获时不应创建日志表")
+
+	_, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "library",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeChanges},
+	})
+	assert.Error(t, err)
+}
+
+func TestChangesCursorRoundTrip(t *testing.T) {
+	pos := map[string]int64{"a.db": 3, "b.db": 10}
+	decoded, err := decodeChangesCursor(encodeChangesCursor(pos))
+	require.NoError(t, err)
+	assert.Equal(t, pos, decoded)
+
+	_, err = decodeChangesCursor("!!not-base64")
+	assert.Error(t, err)
+}
+
+func TestMutate_ChangeCaptureRejectsWithoutRowid(t *testing.T) {
+	ctx := context.Background()
+	db := createTestDB(t, t.TempDir(), "cdc.db",
+		`CREATE TABLE tags (code TEXT PRIMARY KEY, label TEXT) WITHOUT ROWID;`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "library",
+		ChangeCaptureEnabled: true,
+		Tables: map[string]*domain.TableConfig{
+			"tags": {TableName: "tags", AllowCreate: true, AllowUpdate: true, AllowDelete: true},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"library": {"cdc.db": db}}
+
+	_, err := manager.Mutate(ctx, port.MutateRequest{BizName: "library", Operation: "create", Payload: map[string]interface{}{
+		"table_name": "tags", "data": map[string]interface{}{"code": "a", "label": "甲"},
+	}})
+	assert.ErrorIs(t, err, port.ErrChangeCaptureUnsupported)
+
+	// 写操作被整体拒绝，库中没有未记录到日志的变更
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM tags`).Scan(&count))
+	assert.Zero(t, count)
+
+	// 关闭变更捕获后可以正常写入
+	cfg.ChangeCaptureEnabled = false
+	_, err = manager.Mutate(ctx, port.MutateRequest{BizName: "library", Operation: "create", Payload: map[string]interface{}{
+		"table_name": "tags", "data": map[string]interface{}{"code": "a", "label": "甲"},
+	}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,133 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/collapse.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// collapseKeyColumn 是折叠查询 (port.QueryCollapseKey) 附加的分组取值列，跨库归并后移除
+	collapseKeyColumn = innerPrefix + "group_key"
+	// collapseCountColumn 是组内记录数，跨库归并后改名为 port.ResultGroupCountField
+	collapseCountColumn = innerPrefix + "group_count"
+	// collapseRowidColumn 用于在组内选出最早写入的记录，并使分页顺序稳定
+	collapseRowidColumn = innerPrefix + "rowid"
+	// collapseRankColumn 是记录在组内的序号，序号为 1 的记录作为代表
+	collapseRankColumn = innerPrefix + "group_rank"
+)
+
+// buildCollapsedQuerySQL 构建按 collapseField 折叠的分页查询：匹配记录按字段取值分组 (NULL 为一组)，
+// 每组返回一条代表记录及组内记录数。代表记录在参与计分时为得分最高的记录，其次为 rowid 最小的记录；
+// WITHOUT ROWID 表在得分相同时任取一条。分组以窗口函数计算，需要 SQLite 3.25 及以上
+func buildCollapsedQuerySQL(
+	tableName string,
+	selectDBFields []string,
+	queryParams []queryParam,
+	collapseField string,
+	scorer *rankScorer,
+	orderByScore bool,
+	hasRowid bool,
+	page int,
+	size int,
+) (string, []any, error) {
+	if tableName == "" || len(selectDBFields) == 0 || collapseField == "" {
+		return "", nil, errors.New("表名、查询字段与折叠字段不能为空 (buildCollapsedQuerySQL)")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 2000 {
+		size = 50
+	}
+	whereClause, whereArgs, err := buildWhereClause(queryParams)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fields := `"` + strings.Join(selectDBFields, `", "`) + `"`
+	carried := []string{fields, fmt.Sprintf("%q", collapseKeyColumn)}
+	var partitionOrder []string
+	var args []any
+
+	var inner strings.Builder
+	inner.WriteString(fmt.Sprintf("SELECT %s, %q AS %q", fields, collapseField, collapseKeyColumn))
+	if scorer != nil {
+		inner.WriteString(fmt.Sprintf(", %s AS %q", scorer.expr, rankScoreColumn))
+		args = append(args, scorer.args...)
+		carried = append(carried, fmt.Sprintf("%q", rankScoreColumn))
+		partitionOrder = append(partitionOrder, fmt.Sprintf("%q DESC", rankScoreColumn))
+	}
+	if hasRowid {
+		inner.WriteString(fmt.Sprintf(", rowid AS %q", collapseRowidColumn))
+		partitionOrder = append(partitionOrder, fmt.Sprintf("%q", collapseRowidColumn))
+	}
+	inner.WriteString(fmt.Sprintf(" FROM %q", tableName))
+	if whereClause != "" {
+		inner.WriteString(" ")
+		inner.WriteString(whereClause)
+	}
+	args = append(args, whereArgs...)
+
+	window := fmt.Sprintf("PARTITION BY %q", collapseKeyColumn)
+	rowNumberWindow := window
+	if len(partitionOrder) > 0 {
+		rowNumberWindow += " ORDER BY " + strings.Join(partitionOrder, ", ")
+	}
+	carriedList := strings.Join(carried, ", ")
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("SELECT %s, %q FROM (", carriedList, collapseCountColumn))
+	sb.WriteString(fmt.Sprintf("SELECT %s", carriedList))
+	if hasRowid {
+		sb.WriteString(fmt.Sprintf(", %q", collapseRowidColumn))
+	}
+	sb.WriteString(fmt.Sprintf(", COUNT(*) OVER (%s) AS %q, ROW_NUMBER() OVER (%s) AS %q FROM (%s)", window, collapseCountColumn, rowNumberWindow, collapseRankColumn, inner.String()))
+	sb.WriteString(fmt.Sprintf(") WHERE %q = 1", collapseRankColumn))
+	switch {
+	case scorer != nil && orderByScore:
+		sb.WriteString(fmt.Sprintf(" ORDER BY %q DESC", rankScoreColumn))
+	case hasRowid:
+		sb.WriteString(fmt.Sprintf(" ORDER BY %q", collapseRowidColumn))
+	}
+	sb.WriteString(" LIMIT ? OFFSET ?")
+
+	args = append(args, size, (page-1)*size)
+	return sb.String(), args, nil
+}
+
+// mergeCollapsedRows 归并各库的折叠结果：取值相同的组只保留第一次出现的代表记录，组内记录数相加；
+// 随后移除分组取值列，并把组内记录数改名为 port.ResultGroupCountField。
+// 按得分排序时应先排序再归并，使保留的代表记录为得分最高的一条
+func mergeCollapsedRows(rows []map[string]any) []map[string]any {
+	index := make(map[string]int, len(rows))
+	out := rows[:0]
+	for _, row := range rows {
+		// 带上类型，避免整数 1 与字符串 "1" 被视为同一组
+		key := fmt.Sprintf("%T:%v", row[collapseKeyColumn], row[collapseKeyColumn])
+		count := groupCount(row[collapseCountColumn])
+		delete(row, collapseKeyColumn)
+		delete(row, collapseCountColumn)
+		if i, seen := index[key]; seen {
+			out[i][port.ResultGroupCountField] = out[i][port.ResultGroupCountField].(int64) + count
+			continue
+		}
+		row[port.ResultGroupCountField] = count
+		index[key] = len(out)
+		out = append(out, row)
+	}
+	return out
+}
+
+func groupCount(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
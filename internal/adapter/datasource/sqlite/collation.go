@@ -0,0 +1,127 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/collation.go
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"strings"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// unaccentFunc 是注册到 SQLite 的确定性函数，去掉文本中拉丁字母的变音符号，用于不区分重音的比较
+const unaccentFunc = "archiveaegis_unaccent"
+
+// accentFolds 列出带变音符号的字母及其基本字母，每组为 "基本字母: 变体..."。
+// 覆盖 Latin-1 补充、拉丁扩展 A 中的常用字母与带声调的汉语拼音韵母
+var accentFolds = []string{
+	"a:àáâãäåāăąǎ", "A:ÀÁÂÃÄÅĀĂĄǍ",
+	"c:çćĉċč", "C:ÇĆĈĊČ",
+	"d:ďđ", "D:ĎĐ",
+	"e:èéêëēĕėęě", "E:ÈÉÊËĒĔĖĘĚ",
+	"g:ĝğġģ", "G:ĜĞĠĢ",
+	"h:ĥħ", "H:ĤĦ",
+	"i:ìíîïĩīĭįıǐ", "I:ÌÍÎÏĨĪĬĮİǏ",
+	"j:ĵ", "J:Ĵ",
+	"k:ķ", "K:Ķ",
+	"l:ĺļľŀł", "L:ĹĻĽĿŁ",
+	"n:ñńņňŉ", "N:ÑŃŅŇ",
+	"o:òóôõöøōŏőǒ", "O:ÒÓÔÕÖØŌŎŐǑ",
+	"r:ŕŗř", "R:ŔŖŘ",
+	"s:śŝşš", "S:ŚŜŞŠ",
+	"t:ţťŧ", "T:ŢŤŦ",
+	"u:ùúûüũūŭůűųǔǖǘǚǜ", "U:ÙÚÛÜŨŪŬŮŰŲǓǕǗǙǛ",
+	"w:ŵ", "W:Ŵ",
+	"y:ýÿŷ", "Y:ÝŸŶ",
+	"z:źżž", "Z:ŹŻŽ",
+}
+
+var accentTable = make(map[rune]rune)
+
+func init() {
+	for _, group := range accentFolds {
+		base, variants, _ := strings.Cut(group, ":")
+		for _, r := range variants {
+			accentTable[r] = rune(base[0])
+		}
+	}
+
+	// 与拼音函数一样只对本进程打开的连接可用，但它只出现在查询条件中，不影响外部工具读写数据库
+	sqlitedriver.MustRegisterDeterministicScalarFunction(unaccentFunc, 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch s := args[0].(type) {
+		case string:
+			return unaccent(s), nil
+		case []byte:
+			return unaccent(string(s)), nil
+		default:
+			// 数值与 NULL 不含变音符号，原样返回以保留 NULL 语义
+			return s, nil
+		}
+	})
+}
+
+// unaccent 去掉文本中拉丁字母的变音符号 ("Lǔ Xùn" -> "Lu Xun")，其它字符保持不变
+func unaccent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if base, ok := accentTable[r]; ok {
+			return base
+		}
+		return r
+	}, s)
+}
+
+// textCondition 生成单个文本值的匹配条件及其参数。
+// 大小写：CaseInsensitive 为 nil 时保持默认行为 (精确匹配区分大小写，模糊匹配不区分)；
+// 显式指定时，精确匹配用 COLLATE NOCASE，区分大小写的模糊匹配用 instr 代替 LIKE。
+// SQLite 的 NOCASE 与 LIKE 只折叠 ASCII 字母的大小写。
+// AccentInsensitive 为 true 时字段与值都先去掉变音符号再比较，此时无法使用字段上的索引
+func textCondition(p queryParam, value string) (string, interface{}) {
+	column := fmt.Sprintf("%q", p.Field)
+	if p.AccentInsensitive != nil && *p.AccentInsensitive {
+		column = fmt.Sprintf("%s(%s)", unaccentFunc, column)
+		value = unaccent(value)
+	}
+	caseSensitive := p.CaseInsensitive != nil && !*p.CaseInsensitive
+	caseInsensitive := p.CaseInsensitive != nil && *p.CaseInsensitive
+	switch {
+	case p.Fuzzy && caseSensitive:
+		return fmt.Sprintf("instr(%s, ?) > 0", column), value
+	case p.Fuzzy:
+		return fmt.Sprintf("%s LIKE ?", column), "%" + escapeLike(value) + "%"
+	case caseInsensitive:
+		return fmt.Sprintf("%s = ? COLLATE NOCASE", column), value
+	default:
+		return fmt.Sprintf("%s = ?", column), value
+	}
+}
+
+// ensureNoCaseIndex 为默认不区分大小写的字段建立 NOCASE 索引，使 "= ? COLLATE NOCASE" 能够走索引。
+// 索引只是优化，创建失败 (例如库文件只读) 时记录警告并继续查询
+func (m *Manager) ensureNoCaseIndex(ctx context.Context, db *sql.DB, tableName, field string) {
+	key := tableName + "\x00" + field
+	m.mu.RLock()
+	info := m.dbSchemaCache[db]
+	ready := info != nil && info.noCaseIndexes[key]
+	m.mu.RUnlock()
+	if ready {
+		return
+	}
+
+	index := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %q ON %q (%q COLLATE NOCASE)`, innerPrefix+"idx_"+tableName+"_nocase_"+field, tableName, field)
+	if _, err := db.ExecContext(ctx, index); err != nil {
+		log.Printf("警告: [DBManager] 为表 '%s' 字段 '%s' 创建 NOCASE 索引失败: %v", tableName, field, err)
+		return
+	}
+
+	m.mu.Lock()
+	if info := m.dbSchemaCache[db]; info != nil {
+		if info.noCaseIndexes == nil {
+			info.noCaseIndexes = make(map[string]bool)
+		}
+		info.noCaseIndexes[key] = true
+	}
+	m.mu.Unlock()
+}
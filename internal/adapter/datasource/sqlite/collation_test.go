@@ -0,0 +1,91 @@
+// file: internal/adapter/datasource/sqlite/collation_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnaccent(t *testing.T) {
+	assert.Equal(t, "Lu Xun", unaccent("Lǔ Xùn"))
+	assert.Equal(t, "Ecole Francaise", unaccent("École Française"))
+	assert.Equal(t, "鲁迅 Dvorak", unaccent("鲁迅 Dvořák"), "非拉丁字符保持不变")
+}
+
+func TestQuery_CaseAndAccent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT, place TEXT);`,
+		`INSERT INTO people (id, name, place) VALUES (1, 'Lǔ Xùn', 'Shaoxing'), (2, 'lu xun', 'SHAOXING'), (3, 'Hu Shi', NULL);`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"people": {
+				TableName:    "people",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":    {FieldName: "id", IsReturnable: true},
+					"name":  {FieldName: "name", IsSearchable: true, IsReturnable: true},
+					"place": {FieldName: "place", IsSearchable: true, IsReturnable: true, CaseInsensitive: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"people": {"id", "name", "place"}}}
+
+	ids := func(filter map[string]interface{}) []int64 {
+		t.Helper()
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+			"table":   "people",
+			"filters": []interface{}{filter},
+		}})
+		require.NoError(t, err)
+		var out []int64
+		for _, item := range result.Data["items"].([]interface{}) {
+			out = append(out, item.(map[string]any)["id"].(int64))
+		}
+		return out
+	}
+
+	// 默认行为不变：精确匹配区分大小写，模糊匹配不区分
+	assert.Empty(t, ids(map[string]interface{}{"field": "name", "value": "LU XUN"}))
+	assert.Equal(t, []int64{2}, ids(map[string]interface{}{"field": "name", "value": "XUN", "fuzzy": true}))
+
+	assert.Equal(t, []int64{2}, ids(map[string]interface{}{"field": "name", "value": "LU XUN", "case_insensitive": true}))
+	assert.Empty(t, ids(map[string]interface{}{"field": "name", "value": "XUN", "fuzzy": true, "case_insensitive": false}))
+	assert.ElementsMatch(t, []int64{1, 2}, ids(map[string]interface{}{"field": "name", "value": "lu xun", "case_insensitive": true, "accent_insensitive": true}))
+	assert.Equal(t, []int64{1}, ids(map[string]interface{}{"field": "name", "value": "Xun", "fuzzy": true, "case_insensitive": false, "accent_insensitive": true}))
+
+	// 字段默认不区分大小写，过滤条件可覆盖；默认设置会为字段建立 NOCASE 索引
+	assert.ElementsMatch(t, []int64{1, 2}, ids(map[string]interface{}{"field": "place", "value": "shaoxing"}))
+	assert.Equal(t, []int64{2}, ids(map[string]interface{}{"field": "place", "value": "SHAOXING", "case_insensitive": false}))
+	var index string
+	require.NoError(t, dbA.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'index' AND name LIKE '%nocase%'`).Scan(&index))
+	assert.Equal(t, innerPrefix+"idx_people_nocase_place", index)
+
+	_, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table":   "people",
+		"filters": []interface{}{map[string]interface{}{"field": "place", "op": "is_null", "case_insensitive": true}},
+	}})
+	assert.ErrorContains(t, err, "只适用于普通文本匹配")
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table":   "people",
+		"filters": []interface{}{map[string]interface{}{"field": "place", "value": "x", "accent_insensitive": "yes"}},
+	}})
+	assert.ErrorContains(t, err, "必须是布尔值")
+}
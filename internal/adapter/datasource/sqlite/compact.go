@@ -0,0 +1,462 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/compact.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 库文件压缩把业务组的多个库合并为一个：先在暂存文件中按表结构的并集合并各库的数据，并为每行记录来源库，
+// 核对行数后，再把源库移入备份目录、以暂存文件替换目标库。合并期间源库仍可读写，
+// 替换前会再次核对行数，期间发生过写入时拒绝替换。
+const (
+	compactStagingSuffix  = ".db.compacting"
+	compactBackupPrefix   = ".compacted-"
+	defaultProvenanceName = "_source_lib"
+)
+
+// 库文件压缩的步骤，见 port.MutateOpCompact
+const (
+	compactStepMerge = "merge"
+	compactStepSwap  = "swap"
+	compactStepAbort = "abort"
+)
+
+// compactPlan 是一次压缩的参数
+type compactPlan struct {
+	libs       []string
+	target     string
+	provenance string
+}
+
+// compact 处理 port.MutateOpCompact。payload 中 "step" 指定步骤：
+//   - merge: 把 "libs" 列出的库 (至少两个) 合并到暂存文件，返回各表的行数
+//   - swap: 核对源库的行数未变后，把源库移入备份目录并以暂存文件替换目标库，随后重新加载
+//   - abort: 删除暂存文件
+//
+// "target" 是合并后的库名，缺省为 libs 中的第一个；它必须是 libs 之一或尚不存在的库。
+// "provenance_column" 是记录来源库的列名，缺省为 "_source_lib"；源表已有该列时保留原值。
+func (m *Manager) compact(ctx context.Context, bizName string, payload map[string]interface{}) (*port.MutateResult, error) {
+	plan, err := parseCompactPlan(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	dbInstances, bizExists := m.group[bizName]
+	sources := make(map[string]*sql.DB, len(plan.libs))
+	for _, lib := range plan.libs {
+		if db, ok := dbInstances[lib]; ok {
+			sources[lib] = db
+		}
+	}
+	_, targetExists := dbInstances[plan.target]
+	m.mu.RUnlock()
+	if !bizExists {
+		return nil, port.ErrBizNotFound
+	}
+	for _, lib := range plan.libs {
+		if sources[lib] == nil {
+			return nil, fmt.Errorf("业务组 '%s' 中不存在库 '%s'", bizName, lib)
+		}
+	}
+	if _, isSource := sources[plan.target]; targetExists && !isSource {
+		return nil, fmt.Errorf("无效请求: 目标库 '%s' 已存在且不在待合并的库中", plan.target)
+	}
+
+	bizDir := filepath.Join(m.root, bizName)
+	staging := filepath.Join(bizDir, plan.target+compactStagingSuffix)
+	data := map[string]interface{}{"step": payload["step"], "target": plan.target}
+	switch payload["step"] {
+	case compactStepMerge:
+		counts, err := mergeLibs(ctx, staging, plan, sources)
+		if err != nil {
+			_ = os.Remove(staging)
+			return nil, fmt.Errorf("合并业务组 '%s' 的库失败: %w", bizName, err)
+		}
+		data["tables"] = tableCountsList(counts)
+
+	case compactStepSwap:
+		if _, err := os.Stat(staging); err != nil {
+			return nil, fmt.Errorf("无效请求: 目标库 '%s' 没有已合并的暂存文件，请先执行 merge", plan.target)
+		}
+		if err := verifyStaging(ctx, staging, plan, sources); err != nil {
+			return nil, err
+		}
+		backupDir, err := m.swapCompacted(ctx, bizName, staging, plan)
+		if err != nil {
+			return nil, err
+		}
+		data["backup_dir"] = backupDir
+
+	case compactStepAbort:
+		if err := os.Remove(staging); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("删除暂存文件失败: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("无效请求: 不支持的库文件压缩步骤 '%v'", payload["step"])
+	}
+	return &port.MutateResult{Data: data, Source: m.Type()}, nil
+}
+
+func parseCompactPlan(payload map[string]interface{}) (*compactPlan, error) {
+	plan := &compactPlan{provenance: defaultProvenanceName}
+	raw, _ := payload["libs"].([]interface{})
+	seen := make(map[string]bool, len(raw))
+	for _, item := range raw {
+		lib, _ := item.(string)
+		if lib == "" || seen[lib] {
+			return nil, fmt.Errorf("无效请求: libs 中的库名 '%v' 为空或重复", item)
+		}
+		seen[lib] = true
+		plan.libs = append(plan.libs, lib)
+	}
+	if len(plan.libs) < 2 {
+		return nil, errors.New("无效请求: compact 操作的 payload 中 'libs' 至少需要列出两个库")
+	}
+	plan.target, _ = payload["target"].(string)
+	if plan.target == "" {
+		plan.target = plan.libs[0]
+	}
+	if strings.ContainsAny(plan.target, `/\`) || strings.HasPrefix(plan.target, ".") {
+		return nil, fmt.Errorf("无效请求: 目标库名 '%s' 不合法", plan.target)
+	}
+	if name, _ := payload["provenance_column"].(string); name != "" {
+		plan.provenance = name
+	}
+	return plan, nil
+}
+
+// libPath 返回连接所对应的库文件路径
+func libPath(ctx context.Context, db *sql.DB) (string, error) {
+	var seq int
+	var name, path string
+	if err := db.QueryRowContext(ctx, `PRAGMA database_list`).Scan(&seq, &name, &path); err != nil {
+		return "", fmt.Errorf("读取库文件路径失败: %w", err)
+	}
+	if path == "" {
+		return "", errors.New("内存库不支持压缩")
+	}
+	return path, nil
+}
+
+// tableColumn 是表中的一列及其声明类型
+type tableColumn struct {
+	name, declType string
+}
+
+// attachedColumns 读取附加库 schema 中表的列
+func attachedColumns(ctx context.Context, conn *sql.Conn, schema, table string) ([]tableColumn, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`PRAGMA %s.table_info(%s)`, schema, quoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []tableColumn
+	for rows.Next() {
+		var (
+			cid     int
+			col     tableColumn
+			notnull int
+			dflt    sql.NullString
+			pk      int
+		)
+		if err := rows.Scan(&cid, &col.name, &col.declType, &notnull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
+
+// userTables 读取附加库 schema 中的用户表及其建表语句，不含 SQLite 与网关的内部表
+func userTables(ctx context.Context, conn *sql.Conn, schema string) (map[string]string, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT name, sql FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%' AND name NOT LIKE ?`, schema),
+		innerPrefix+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := make(map[string]string)
+	for rows.Next() {
+		var name, stmt string
+		if err := rows.Scan(&name, &stmt); err != nil {
+			return nil, err
+		}
+		tables[name] = stmt
+	}
+	return tables, rows.Err()
+}
+
+// tableIndexes 读取附加库 schema 中表上显式创建的索引
+func tableIndexes(ctx context.Context, conn *sql.Conn, schema, table string) (map[string]string, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT name, sql FROM %s.sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL`, schema), table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexes := make(map[string]string)
+	for rows.Next() {
+		var name, stmt string
+		if err := rows.Scan(&name, &stmt); err != nil {
+			return nil, err
+		}
+		indexes[name] = stmt
+	}
+	return indexes, rows.Err()
+}
+
+// mergeLibs 在暂存文件中合并各源库，返回各表合并后的行数。
+// 表第一次出现时沿用其建表语句 (保留主键与约束)，之后出现的列以 ALTER TABLE 追加；索引在数据写入后建立
+func mergeLibs(ctx context.Context, staging string, plan *compactPlan, sources map[string]*sql.DB) (map[string]int64, error) {
+	if err := os.Remove(staging); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("清理旧的暂存文件失败: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+staging)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	// ATTACH 只对单个连接有效
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	columns := make(map[string]map[string]bool)
+	expected := make(map[string]int64)
+	indexes := make(map[string]string)
+	var indexNames []string
+	for _, lib := range plan.libs {
+		path, err := libPath(ctx, sources[lib])
+		if err != nil {
+			return nil, fmt.Errorf("库 '%s': %w", lib, err)
+		}
+		if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS src`, path); err != nil {
+			return nil, fmt.Errorf("附加库 '%s' 失败: %w", lib, err)
+		}
+		if err := mergeLib(ctx, conn, lib, plan.provenance, columns, expected); err != nil {
+			_, _ = conn.ExecContext(context.Background(), `DETACH DATABASE src`)
+			return nil, fmt.Errorf("库 '%s': %w", lib, err)
+		}
+		for table := range columns {
+			found, err := tableIndexes(ctx, conn, "src", table)
+			if err != nil {
+				return nil, err
+			}
+			for name, stmt := range found {
+				if _, dup := indexes[name]; !dup {
+					indexes[name] = stmt
+					indexNames = append(indexNames, name)
+				}
+			}
+		}
+		if _, err := conn.ExecContext(ctx, `DETACH DATABASE src`); err != nil {
+			return nil, fmt.Errorf("分离库 '%s' 失败: %w", lib, err)
+		}
+	}
+	for _, name := range indexNames {
+		if _, err := conn.ExecContext(ctx, indexes[name]); err != nil {
+			return nil, fmt.Errorf("建立索引 '%s' 失败: %w", name, err)
+		}
+	}
+
+	actual, err := countTables(ctx, conn, "main", expected)
+	if err != nil {
+		return nil, err
+	}
+	for table, want := range expected {
+		if actual[table] != want {
+			return nil, fmt.Errorf("表 '%s' 合并后有 %d 行，与源库合计的 %d 行不一致", table, actual[table], want)
+		}
+	}
+	return actual, nil
+}
+
+// mergeLib 把附加为 src 的库中的每张用户表追加到暂存库，expected 累计各表应有的行数
+func mergeLib(ctx context.Context, conn *sql.Conn, lib, provenance string, columns map[string]map[string]bool, expected map[string]int64) error {
+	tables, err := userTables(ctx, conn, "src")
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, table := range names {
+		srcCols, err := attachedColumns(ctx, conn, "src", table)
+		if err != nil {
+			return err
+		}
+		existing, known := columns[table]
+		if !known {
+			if _, err := conn.ExecContext(ctx, tables[table]); err != nil {
+				return fmt.Errorf("建立表 '%s' 失败: %w", table, err)
+			}
+			existing = make(map[string]bool)
+			for _, col := range srcCols {
+				existing[col.name] = true
+			}
+			columns[table] = existing
+		}
+		for _, col := range srcCols {
+			if existing[col.name] {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE main.%s ADD COLUMN %s %s`, quoteIdent(table), quoteIdent(col.name), col.declType)); err != nil {
+				return fmt.Errorf("为表 '%s' 追加列 '%s' 失败: %w", table, col.name, err)
+			}
+			existing[col.name] = true
+		}
+		if !existing[provenance] {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE main.%s ADD COLUMN %s TEXT`, quoteIdent(table), quoteIdent(provenance))); err != nil {
+				return fmt.Errorf("为表 '%s' 追加来源列失败: %w", table, err)
+			}
+			existing[provenance] = true
+		}
+
+		// 源表已有来源列 (如再次压缩已合并的库) 时保留原值
+		quoted := make([]string, 0, len(srcCols)+1)
+		hasProvenance := false
+		for _, col := range srcCols {
+			quoted = append(quoted, quoteIdent(col.name))
+			hasProvenance = hasProvenance || col.name == provenance
+		}
+		insertCols, selectCols := strings.Join(quoted, ", "), strings.Join(quoted, ", ")
+		args := []interface{}{}
+		if !hasProvenance {
+			insertCols += ", " + quoteIdent(provenance)
+			selectCols += ", ?"
+			args = append(args, lib)
+		}
+		res, err := conn.ExecContext(ctx, fmt.Sprintf(`INSERT INTO main.%s (%s) SELECT %s FROM src.%s`,
+			quoteIdent(table), insertCols, selectCols, quoteIdent(table)), args...)
+		if err != nil {
+			return fmt.Errorf("合并表 '%s' 失败 (可能与已合并的数据主键冲突): %w", table, err)
+		}
+		n, _ := res.RowsAffected()
+		expected[table] += n
+	}
+	return nil
+}
+
+// countTables 统计 schema 中各表的行数
+func countTables(ctx context.Context, conn *sql.Conn, schema string, tables map[string]int64) (map[string]int64, error) {
+	counts := make(map[string]int64, len(tables))
+	for table := range tables {
+		var n int64
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s.%s`, schema, quoteIdent(table))).Scan(&n); err != nil {
+			return nil, fmt.Errorf("统计表 '%s' 的行数失败: %w", table, err)
+		}
+		counts[table] = n
+	}
+	return counts, nil
+}
+
+// verifyStaging 核对暂存文件中各表的行数等于源库当前的行数之和，合并后源库发生过写入时返回错误
+func verifyStaging(ctx context.Context, staging string, plan *compactPlan, sources map[string]*sql.DB) error {
+	db, err := sql.Open("sqlite", "file:"+staging)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	expected := make(map[string]int64)
+	for _, lib := range plan.libs {
+		tables, err := getTablesSet(sources[lib])
+		if err != nil {
+			return fmt.Errorf("库 '%s': %w", lib, err)
+		}
+		for table := range tables {
+			var n int64
+			if err := sources[lib].QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, quoteIdent(table))).Scan(&n); err != nil {
+				return fmt.Errorf("统计库 '%s' 表 '%s' 的行数失败: %w", lib, table, err)
+			}
+			expected[table] += n
+		}
+	}
+	actual, err := countTables(ctx, conn, "main", expected)
+	if err != nil {
+		return err
+	}
+	for table, want := range expected {
+		if actual[table] != want {
+			return fmt.Errorf("源库表 '%s' 现有 %d 行，暂存文件中有 %d 行；合并后源库发生过写入，请重新压缩", table, want, actual[table])
+		}
+	}
+	return nil
+}
+
+// swapCompacted 关闭源库并移入备份目录，把暂存文件改名为目标库后重新加载业务组。返回备份目录
+func (m *Manager) swapCompacted(ctx context.Context, bizName, staging string, plan *compactPlan) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bizDir := filepath.Join(m.root, bizName)
+	backupDir := filepath.Join(bizDir, compactBackupPrefix+time.Now().UTC().Format("20060102T150405"))
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		return "", fmt.Errorf("建立备份目录失败: %w", err)
+	}
+	for _, lib := range plan.libs {
+		db := m.group[bizName][lib]
+		if db == nil {
+			continue
+		}
+		path, err := libPath(ctx, db)
+		if err != nil {
+			return "", fmt.Errorf("库 '%s': %w", lib, err)
+		}
+		delete(m.dbSchemaCache, db)
+		delete(m.group[bizName], lib)
+		if err := db.Close(); err != nil {
+			return "", fmt.Errorf("关闭库 '%s' 失败: %w", lib, err)
+		}
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if err := os.Rename(path+suffix, filepath.Join(backupDir, filepath.Base(path)+suffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("移动库 '%s' 到备份目录失败: %w", lib, err)
+			}
+		}
+	}
+	target := filepath.Join(bizDir, plan.target+".db")
+	if err := os.Rename(staging, target); err != nil {
+		return "", fmt.Errorf("替换目标库失败: %w", err)
+	}
+	if err := m.openDBInternal(ctx, target); err != nil {
+		return "", err
+	}
+	// 库的组成已变化，丢弃 schema 缓存文件后重新扫描
+	_ = os.Remove(filepath.Join(bizDir, schemaCacheFilename))
+	m.loadOrRefreshSchemaInternal()
+	return backupDir, nil
+}
+
+func tableCountsList(counts map[string]int64) []interface{} {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		list = append(list, map[string]interface{}{"table": name, "rows": counts[name]})
+	}
+	return list
+}
@@ -0,0 +1,138 @@
+// file: internal/adapter/datasource/sqlite/compact_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompactManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	root := t.TempDir()
+	bizDir := filepath.Join(root, "library")
+	require.NoError(t, os.MkdirAll(bizDir, 0o755))
+
+	a := createTestDB(t, bizDir, "a.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT)`,
+		`CREATE INDEX idx_books_title ON books (title)`,
+		`INSERT INTO books (id, title) VALUES (1, '三体'), (2, '球状闪电')`,
+	)
+	b := createTestDB(t, bizDir, "b.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, year INTEGER)`,
+		`CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO books (id, title, year) VALUES (3, '流浪地球', 2000)`,
+		`INSERT INTO authors (id, name) VALUES (1, '刘慈欣')`,
+	)
+	require.NoError(t, a.Close())
+	require.NoError(t, b.Close())
+
+	cfg := &domain.BizQueryConfig{BizName: "library", Tables: map[string]*domain.TableConfig{}}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	require.NoError(t, manager.InitForBiz(context.Background(), root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager, bizDir
+}
+
+func TestMutate_Compact(t *testing.T) {
+	ctx := context.Background()
+	manager, bizDir := newCompactManager(t)
+
+	compact := func(step string) (map[string]interface{}, error) {
+		res, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact, Payload: map[string]interface{}{
+			"step": step, "libs": []interface{}{"a", "b"}, "target": "merged",
+		}})
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	}
+
+	merged, err := compact("merge")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"table": "authors", "rows": int64(1)},
+		map[string]interface{}{"table": "books", "rows": int64(3)},
+	}, merged["tables"])
+	assert.FileExists(t, filepath.Join(bizDir, "merged"+compactStagingSuffix))
+
+	swapped, err := compact("swap")
+	require.NoError(t, err)
+	backupDir := swapped["backup_dir"].(string)
+	assert.FileExists(t, filepath.Join(backupDir, "a.db"))
+	assert.FileExists(t, filepath.Join(backupDir, "b.db"))
+	assert.NoFileExists(t, filepath.Join(bizDir, "a.db"))
+
+	manager.mu.RLock()
+	libs := manager.group["library"]
+	assert.Len(t, libs, 1)
+	db := libs["merged"]
+	manager.mu.RUnlock()
+	require.NotNil(t, db)
+
+	rows, err := db.Query(`SELECT id, title, year, _source_lib FROM books ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var (
+			id     int
+			title  string
+			year   sql.NullInt64
+			source string
+		)
+		require.NoError(t, rows.Scan(&id, &title, &year, &source))
+		got = append(got, title+"@"+source)
+		if id == 3 {
+			assert.Equal(t, int64(2000), year.Int64)
+		} else {
+			assert.False(t, year.Valid)
+		}
+	}
+	assert.Equal(t, []string{"三体@a", "球状闪电@a", "流浪地球@b"}, got)
+
+	var indexes int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_books_title'`).Scan(&indexes))
+	assert.Equal(t, 1, indexes)
+}
+
+func TestMutate_CompactRejectsChangedSources(t *testing.T) {
+	ctx := context.Background()
+	manager, bizDir := newCompactManager(t)
+	payload := func(step string) map[string]interface{} {
+		return map[string]interface{}{"step": step, "libs": []interface{}{"a", "b"}}
+	}
+
+	_, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact, Payload: payload("merge")})
+	require.NoError(t, err)
+
+	// 合并后源库发生写入，替换应被拒绝且源库保持不变
+	manager.mu.RLock()
+	db := manager.group["library"]["b"]
+	manager.mu.RUnlock()
+	_, err = db.Exec(`INSERT INTO authors (id, name) VALUES (2, '王晋康')`)
+	require.NoError(t, err)
+
+	_, err = manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact, Payload: payload("swap")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authors")
+	assert.FileExists(t, filepath.Join(bizDir, "b.db"))
+
+	_, err = manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact, Payload: payload("abort")})
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(bizDir, "a"+compactStagingSuffix))
+
+	_, err = manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact, Payload: map[string]interface{}{"step": "merge", "libs": []interface{}{"a"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/completeness.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fieldCompleteness 累计单个字段在所有库中的缺失情况
+type fieldCompleteness struct {
+	null  int64
+	empty int64
+}
+
+// queryCompleteness 统计表中每个已配置字段的 NULL 与空字符串数量，用于数据质量检查。
+// 统计覆盖全部已配置字段（不论是否可返回），因此只应通过管理接口调用。
+// 某个库的表中缺少该列时，该库的全部记录均计为 NULL。
+func (m *Manager) queryCompleteness(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	tableName, _ := queryMap["table"].(string)
+	if tableName == "" {
+		return nil, fmt.Errorf("无效请求: completeness 模式必须包含 'table' 字符串字段")
+	}
+
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableAdminConfig, exists := bizAdminConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	fields := make([]string, 0, len(tableAdminConfig.Fields))
+	for fieldName := range tableAdminConfig.Fields {
+		fields = append(fields, fieldName)
+	}
+	sort.Strings(fields)
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libColumns := make(map[string][]string, len(dbInstances))
+	for libName, db := range dbInstances {
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			if columns, tableExists := schema.allTablesAndColumns[tableName]; tableExists {
+				libColumns[libName] = columns
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	var total int64
+	stats := make(map[string]*fieldCompleteness, len(fields))
+	for _, f := range fields {
+		stats[f] = &fieldCompleteness{}
+	}
+	for libName, columns := range libColumns {
+		present := make(map[string]bool, len(columns))
+		for _, col := range columns {
+			present[col] = true
+		}
+		libTotal, err := countMissingValues(ctx, dbInstances[libName], tableName, fields, present, stats)
+		if err != nil {
+			return nil, fmt.Errorf("统计库 '%s/%s' 表 '%s' 的缺失值失败: %w", bizName, libName, tableName, err)
+		}
+		total += libTotal
+	}
+
+	// 结果需要经 structpb 传输，因此只能使用 map / []interface{} 等通用类型
+	items := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		s := stats[f]
+		missing := s.null + s.empty
+		var ratio float64
+		if total > 0 {
+			ratio = float64(total-missing) / float64(total)
+		}
+		items = append(items, map[string]interface{}{
+			"field":        f,
+			"null":         s.null,
+			"empty":        s.empty,
+			"missing":      missing,
+			"completeness": ratio,
+		})
+	}
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"table":  tableName,
+			"total":  total,
+			"fields": items,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// countMissingValues 在单个库中以一条聚合查询统计各字段的缺失值，并累加到 stats 中，返回该库的记录总数
+func countMissingValues(ctx context.Context, db *sql.DB, tableName string, fields []string, present map[string]bool, stats map[string]*fieldCompleteness) (int64, error) {
+	var existing []string
+	selects := []string{"COUNT(*)"}
+	for _, f := range fields {
+		if !present[f] {
+			continue
+		}
+		existing = append(existing, f)
+		selects = append(selects,
+			fmt.Sprintf("SUM(CASE WHEN %q IS NULL THEN 1 ELSE 0 END)", f),
+			fmt.Sprintf("SUM(CASE WHEN %q = '' THEN 1 ELSE 0 END)", f),
+		)
+	}
+
+	// 空表上 SUM 返回 NULL
+	dest := make([]sql.NullInt64, len(selects))
+	destPtrs := make([]any, len(selects))
+	for i := range dest {
+		destPtrs[i] = &dest[i]
+	}
+	query := fmt.Sprintf("SELECT %s FROM %q", strings.Join(selects, ", "), tableName)
+	if err := db.QueryRowContext(ctx, query).Scan(destPtrs...); err != nil {
+		return 0, err
+	}
+
+	total := dest[0].Int64
+	for i, f := range existing {
+		stats[f].null += dest[1+2*i].Int64
+		stats[f].empty += dest[2+2*i].Int64
+	}
+	for _, f := range fields {
+		if !present[f] {
+			stats[f].null += total
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,77 @@
+// file: internal/adapter/datasource/sqlite/completeness_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_CompletenessMode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, place TEXT);`,
+		`INSERT INTO letters (id, sender, place) VALUES (1, '鲁迅', ''), (2, NULL, '北京'), (3, '胡适', NULL);`,
+	)
+	// b 库的表缺少 place 列，其记录应全部计为 NULL
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT);`,
+		`INSERT INTO letters (id, sender) VALUES (1, '');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName: "archive",
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName: "letters",
+				Fields: map[string]domain.FieldSetting{
+					"sender": {FieldName: "sender", IsReturnable: true},
+					"place":  {FieldName: "place", IsReturnable: false},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "place", "sender"}}}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "sender"}}}
+
+	result, err := manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeCompleteness, "table": "letters"},
+	})
+	require.NoError(t, err)
+	_, err = structpb.NewStruct(result.Data)
+	require.NoError(t, err, "结果必须可以被 structpb 序列化")
+
+	assert.Equal(t, int64(4), result.Data["total"])
+	fields := result.Data["fields"].([]interface{})
+	require.Len(t, fields, 2)
+
+	place := fields[0].(map[string]interface{})
+	assert.Equal(t, "place", place["field"])
+	assert.Equal(t, int64(2), place["null"])
+	assert.Equal(t, int64(1), place["empty"])
+	assert.Equal(t, 0.25, place["completeness"])
+
+	sender := fields[1].(map[string]interface{})
+	assert.Equal(t, int64(1), sender["null"])
+	assert.Equal(t, int64(1), sender["empty"])
+	assert.Equal(t, int64(2), sender["missing"])
+
+	_, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeCompleteness, "table": "nope"},
+	})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+}
@@ -0,0 +1,79 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/explain.go
+package sqlite
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// explainStep 记录在单个库上执行的一条语句
+type explainStep struct {
+	lib      string
+	kind     string // "count" 或 "select"
+	sql      string
+	args     []any
+	duration time.Duration
+	rows     int64
+	err      error
+}
+
+// explainLog 收集一次查询在各库上执行的语句，可被多个 goroutine 并发写入。
+// 为 nil 时所有方法均为空操作，未开启 explain 的查询无需额外判断。
+type explainLog struct {
+	mu    sync.Mutex
+	start time.Time
+	steps []explainStep
+}
+
+func newExplainLog() *explainLog {
+	return &explainLog{start: time.Now()}
+}
+
+func (e *explainLog) add(step explainStep) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.steps = append(e.steps, step)
+	e.mu.Unlock()
+}
+
+// toMap 将收集结果转换为可经 structpb 传输的通用结构，步骤按库名与类型排序
+func (e *explainLog) toMap() map[string]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sort.Slice(e.steps, func(i, j int) bool {
+		if e.steps[i].lib != e.steps[j].lib {
+			return e.steps[i].lib < e.steps[j].lib
+		}
+		return e.steps[i].kind < e.steps[j].kind
+	})
+	steps := make([]interface{}, 0, len(e.steps))
+	for _, s := range e.steps {
+		args := make([]interface{}, len(s.args))
+		for i, a := range s.args {
+			if n, ok := a.(int); ok {
+				a = int64(n)
+			}
+			args[i] = a
+		}
+		step := map[string]interface{}{
+			"lib":         s.lib,
+			"kind":        s.kind,
+			"sql":         s.sql,
+			"args":        args,
+			"duration_ms": float64(s.duration.Microseconds()) / 1000,
+			"rows":        s.rows,
+		}
+		if s.err != nil {
+			step["error"] = s.err.Error()
+		}
+		steps = append(steps, step)
+	}
+	return map[string]interface{}{
+		"total_ms": float64(time.Since(e.start).Microseconds()) / 1000,
+		"steps":    steps,
+	}
+}
@@ -0,0 +1,75 @@
+// file: internal/adapter/datasource/sqlite/explain_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_Explain(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT);`,
+		`INSERT INTO letters (id, sender) VALUES (1, '鲁迅'), (2, '胡适');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "sender"}}}
+
+	query := func(explain bool) map[string]interface{} {
+		q := map[string]interface{}{
+			"table":   "letters",
+			"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+		}
+		if explain {
+			q[port.QueryExplainKey] = true
+		}
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: q})
+		require.NoError(t, err)
+		_, err = structpb.NewStruct(result.Data)
+		require.NoError(t, err, "结果必须可以被 structpb 序列化")
+		return result.Data
+	}
+
+	assert.NotContains(t, query(false), "explain", "未开启时不应附带 explain")
+
+	explain := query(true)["explain"].(map[string]interface{})
+	steps := explain["steps"].([]interface{})
+	require.Len(t, steps, 2)
+
+	count := steps[0].(map[string]interface{})
+	assert.Equal(t, "count", count["kind"])
+	assert.Equal(t, int64(1), count["rows"])
+
+	selectStep := steps[1].(map[string]interface{})
+	assert.Equal(t, "a", selectStep["lib"])
+	assert.Equal(t, "select", selectStep["kind"])
+	assert.Contains(t, selectStep["sql"], `WHERE "sender" = ?`)
+	assert.Equal(t, []interface{}{"鲁迅", int64(50), int64(0)}, selectStep["args"])
+	assert.Equal(t, int64(1), selectStep["rows"])
+}
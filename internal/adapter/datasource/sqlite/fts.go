@@ -0,0 +1,513 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/fts.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 全文索引是与业务表同库的 FTS5 表，以业务表的 rowid 关联，由业务表上的触发器保持同步。
+// 重建时先写入暂存索引 (同样挂有触发器，重建期间的写入不会丢失)，分批复制完成后在事务内替换正式索引，
+// 因此重建期间旧索引始终可用。
+const (
+	ftsMetaTable     = innerPrefix + "fts_meta"
+	ftsStagingSuffix = "_build"
+
+	defaultFTSCopyLimit = 2000
+	maxFTSCopyLimit     = 20000
+)
+
+// 全文索引重建的步骤，见 port.MutateOpFTSRebuild
+const (
+	ftsStepPrepare = "prepare"
+	ftsStepCopy    = "copy"
+	ftsStepFinish  = "finish"
+	ftsStepAbort   = "abort"
+	ftsStepDrop    = "drop"
+)
+
+// ftsIndexTable 返回表的正式全文索引名
+func ftsIndexTable(tableName string) string {
+	return innerPrefix + "fts_" + tableName
+}
+
+// ftsMeta 是库中当前生效的全文索引的描述
+type ftsMeta struct {
+	Version   int64
+	Tokenizer string
+	Fields    []string
+	BuiltAt   int64
+}
+
+func (f *ftsMeta) hasField(field string) bool {
+	for _, name := range f.Fields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ftsTokenizeClause 把分词器配置转换为 FTS5 的 tokenize 选项
+func ftsTokenizeClause(cfg *domain.FTSConfig) (string, error) {
+	diacritics := "remove_diacritics 0"
+	if cfg.RemoveDiacritics {
+		diacritics = "remove_diacritics 2"
+	}
+	switch cfg.Tokenizer {
+	case "", domain.FTSTokenizerUnicode61:
+		return "unicode61 " + diacritics, nil
+	case domain.FTSTokenizerPorter:
+		return "porter unicode61 " + diacritics, nil
+	case domain.FTSTokenizerTrigram:
+		return "trigram", nil
+	case domain.FTSTokenizerICU:
+		return "icu", nil
+	default:
+		return "", fmt.Errorf("不支持的全文检索分词器 '%s'", cfg.Tokenizer)
+	}
+}
+
+// rebuildFullText 处理 port.MutateOpFTSRebuild。payload 中 "step" 指定步骤：
+//   - prepare: 在各库中新建空的暂存索引，返回各库的待复制行数
+//   - copy: 把某个库中 rowid 大于 after_rowid 的一批行复制到暂存索引
+//   - finish: 在各库中以暂存索引替换正式索引，并记录索引元数据
+//   - abort: 删除暂存索引
+//   - drop: 删除正式索引与暂存索引
+//
+// prepare、copy 与 finish 使用 payload 中 "config" 给出的配置，而不是读取本地缓存的配置，
+// 以保证整个重建过程使用同一版本的配置。
+func (m *Manager) rebuildFullText(ctx context.Context, bizName string, bizConfig *domain.BizQueryConfig, payload map[string]interface{}) (*port.MutateResult, error) {
+	tableName, ok := payload["table_name"].(string)
+	if !ok || tableName == "" {
+		return nil, errors.New("fts_rebuild 操作的 payload 中必须包含一个有效的 'table_name' 字符串字段")
+	}
+	if _, exists := bizConfig.Tables[tableName]; !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	m.mu.RLock()
+	dbInstances, bizExists := m.group[bizName]
+	m.mu.RUnlock()
+	if !bizExists {
+		return nil, port.ErrBizNotFound
+	}
+	// 只处理物理上存在该表的库，按库名排序以保证各步骤的处理顺序一致
+	libs := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		m.mu.RLock()
+		info := m.dbSchemaCache[db]
+		m.mu.RUnlock()
+		if info == nil {
+			continue
+		}
+		if _, exists := info.allTablesAndColumns[tableName]; exists {
+			libs = append(libs, libName)
+		}
+	}
+	sort.Strings(libs)
+
+	step, _ := payload["step"].(string)
+	data := map[string]interface{}{"step": step}
+	switch step {
+	case ftsStepPrepare:
+		cfg, err := parseFTSPayloadConfig(payload)
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		libTotals := make([]interface{}, 0, len(libs))
+		for _, libName := range libs {
+			rows, err := prepareFTSStaging(ctx, dbInstances[libName], tableName, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			total += rows
+			libTotals = append(libTotals, map[string]interface{}{"lib": libName, "total": rows})
+		}
+		data["libs"] = libTotals
+		data["total"] = total
+
+	case ftsStepCopy:
+		cfg, err := parseFTSPayloadConfig(payload)
+		if err != nil {
+			return nil, err
+		}
+		libName, _ := payload["lib"].(string)
+		db, exists := dbInstances[libName]
+		if !exists {
+			return nil, fmt.Errorf("业务组 '%s' 中不存在库 '%s'", bizName, libName)
+		}
+		afterRowID, _ := payload["after_rowid"].(float64)
+		limit := defaultFTSCopyLimit
+		if l, ok := payload["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+		}
+		if limit > maxFTSCopyLimit {
+			limit = maxFTSCopyLimit
+		}
+		copied, next, err := copyFTSBatch(ctx, db, tableName, cfg.Fields, int64(afterRowID), limit)
+		if err != nil {
+			return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+		}
+		data["lib"] = libName
+		data["copied"] = copied
+		data["next_rowid"] = next
+		data["done"] = copied < int64(limit)
+
+	case ftsStepFinish:
+		cfg, err := parseFTSPayloadConfig(payload)
+		if err != nil {
+			return nil, err
+		}
+		for _, libName := range libs {
+			if err := swapFTSStaging(ctx, dbInstances[libName], tableName, cfg); err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+		}
+
+	case ftsStepAbort, ftsStepDrop:
+		for _, libName := range libs {
+			if err := dropFTSIndex(ctx, dbInstances[libName], tableName, step == ftsStepDrop); err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("无效请求: 不支持的全文索引重建步骤 '%s'", step)
+	}
+	return &port.MutateResult{Data: data, Source: m.Type()}, nil
+}
+
+// parseFTSPayloadConfig 从 payload 的 "config" 对象中解析全文检索配置
+func parseFTSPayloadConfig(payload map[string]interface{}) (*domain.FTSConfig, error) {
+	raw, ok := payload["config"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fts_rebuild 操作的 payload 中必须包含一个 'config' 对象")
+	}
+	cfg := &domain.FTSConfig{}
+	cfg.Tokenizer, _ = raw["tokenizer"].(string)
+	cfg.RemoveDiacritics, _ = raw["remove_diacritics"].(bool)
+	if v, ok := raw["version"].(float64); ok {
+		cfg.Version = int64(v)
+	}
+	fields, _ := raw["fields"].([]interface{})
+	for _, f := range fields {
+		name, ok := f.(string)
+		if !ok || name == "" {
+			return nil, errors.New("全文检索配置的 'fields' 只能包含非空字符串")
+		}
+		cfg.Fields = append(cfg.Fields, name)
+	}
+	if len(cfg.Fields) == 0 {
+		return nil, errors.New("全文检索配置至少需要一个字段")
+	}
+	return cfg, nil
+}
+
+// prepareFTSStaging 新建空的暂存索引及其同步触发器，返回表的行数
+func prepareFTSStaging(ctx context.Context, db *sql.DB, tableName string, cfg *domain.FTSConfig) (int64, error) {
+	tokenize, err := ftsTokenizeClause(cfg)
+	if err != nil {
+		return 0, err
+	}
+	physical, err := listColumns(db, tableName)
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool, len(physical))
+	for _, col := range physical {
+		existing[col] = true
+	}
+	for _, field := range cfg.Fields {
+		if !existing[field] {
+			return 0, fmt.Errorf("表 '%s' 中不存在字段 '%s'", tableName, field)
+		}
+	}
+
+	staging := ftsIndexTable(tableName) + ftsStagingSuffix
+	if err := dropFTSTable(ctx, db, staging); err != nil {
+		return 0, err
+	}
+	stmt := fmt.Sprintf(`CREATE VIRTUAL TABLE %q USING fts5(%s, tokenize=%s)`, staging, quoteIdents(cfg.Fields), quoteLiteral(tokenize))
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		if cfg.Tokenizer == domain.FTSTokenizerICU && strings.Contains(err.Error(), "no such tokenizer") {
+			return 0, errors.New("当前 SQLite 构建不支持 icu 分词器")
+		}
+		return 0, fmt.Errorf("创建全文索引失败: %w", err)
+	}
+	if err := createFTSTriggers(ctx, db, tableName, staging, cfg.Fields); err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %q`, tableName)).Scan(&rows); err != nil {
+		return 0, fmt.Errorf("统计表 '%s' 的行数失败: %w", tableName, err)
+	}
+	return rows, nil
+}
+
+// copyFTSBatch 把 rowid 大于 afterRowID 的至多 limit 行复制到暂存索引，返回复制的行数与本批最大的 rowid。
+// 触发器可能已把重建期间新写入的行加入暂存索引，因此先删除本批范围内的索引行再插入。
+func copyFTSBatch(ctx context.Context, db *sql.DB, tableName string, fields []string, afterRowID int64, limit int) (copied, next int64, err error) {
+	staging := ftsIndexTable(tableName) + ftsStagingSuffix
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, afterRowID, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var maxRowID sql.NullInt64
+	row := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT MAX(rowid), COUNT(*) FROM (SELECT rowid FROM %q WHERE rowid > ? ORDER BY rowid LIMIT ?)`, tableName), afterRowID, limit)
+	if err = row.Scan(&maxRowID, &copied); err != nil {
+		return 0, afterRowID, fmt.Errorf("读取表 '%s' 的 rowid 失败 (WITHOUT ROWID 表不支持全文索引): %w", tableName, err)
+	}
+	if copied == 0 {
+		return 0, afterRowID, nil
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE rowid > ? AND rowid <= ?`, staging), afterRowID, maxRowID.Int64); err != nil {
+		return 0, afterRowID, fmt.Errorf("清理暂存索引失败: %w", err)
+	}
+	cols := quoteIdents(fields)
+	stmt := fmt.Sprintf(`INSERT INTO %q (rowid, %s) SELECT rowid, %s FROM %q WHERE rowid > ? AND rowid <= ?`, staging, cols, cols, tableName)
+	if _, err = tx.ExecContext(ctx, stmt, afterRowID, maxRowID.Int64); err != nil {
+		return 0, afterRowID, fmt.Errorf("写入暂存索引失败: %w", err)
+	}
+	return copied, maxRowID.Int64, nil
+}
+
+// swapFTSStaging 在单个事务内以暂存索引替换正式索引，并写入索引元数据
+func swapFTSStaging(ctx context.Context, db *sql.DB, tableName string, cfg *domain.FTSConfig) (err error) {
+	index := ftsIndexTable(tableName)
+	staging := index + ftsStagingSuffix
+	var exists int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, staging).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errors.New("暂存索引不存在，请重新发起重建")
+	}
+	fieldsJSON, err := json.Marshal(cfg.Fields)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	stmts := append(dropFTSTriggerStmts(staging), dropFTSTriggerStmts(index)...)
+	stmts = append(stmts,
+		fmt.Sprintf(`DROP TABLE IF EXISTS %q`, index),
+		fmt.Sprintf(`ALTER TABLE %q RENAME TO %q`, staging, index),
+	)
+	stmts = append(stmts, createFTSTriggerStmts(tableName, index, cfg.Fields)...)
+	stmts = append(stmts, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %q (
+			table_name TEXT PRIMARY KEY,
+			version INTEGER NOT NULL,
+			tokenizer TEXT NOT NULL,
+			fields TEXT NOT NULL,
+			built_at INTEGER NOT NULL
+		)`, ftsMetaTable))
+	for _, stmt := range stmts {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("替换全文索引失败: %w", err)
+		}
+	}
+	tokenizer := cfg.Tokenizer
+	if tokenizer == "" {
+		tokenizer = domain.FTSTokenizerUnicode61
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %q (table_name, version, tokenizer, fields, built_at) VALUES (?, ?, ?, ?, ?)`, ftsMetaTable),
+		tableName, cfg.Version, tokenizer, string(fieldsJSON), time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("写入全文索引元数据失败: %w", err)
+	}
+	return nil
+}
+
+// dropFTSIndex 删除暂存索引；includeLive 为 true 时同时删除正式索引及其元数据
+func dropFTSIndex(ctx context.Context, db *sql.DB, tableName string, includeLive bool) error {
+	index := ftsIndexTable(tableName)
+	if err := dropFTSTable(ctx, db, index+ftsStagingSuffix); err != nil {
+		return err
+	}
+	if !includeLive {
+		return nil
+	}
+	if err := dropFTSTable(ctx, db, index); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE table_name = ?`, ftsMetaTable), tableName); err != nil && !strings.Contains(err.Error(), "no such table") {
+		return fmt.Errorf("删除全文索引元数据失败: %w", err)
+	}
+	return nil
+}
+
+// dropFTSTable 删除一个全文索引及其同步触发器
+func dropFTSTable(ctx context.Context, db *sql.DB, index string) error {
+	for _, stmt := range append(dropFTSTriggerStmts(index), fmt.Sprintf(`DROP TABLE IF EXISTS %q`, index)) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("删除全文索引 '%s' 失败: %w", index, err)
+		}
+	}
+	return nil
+}
+
+func createFTSTriggers(ctx context.Context, db *sql.DB, tableName, index string, fields []string) error {
+	for _, stmt := range createFTSTriggerStmts(tableName, index, fields) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("创建全文索引触发器失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// createFTSTriggerStmts 返回使索引跟随业务表写入的触发器。
+// 插入前先按 rowid 删除，使 INSERT OR REPLACE 等复用 rowid 的写入也不会产生重复的索引行。
+func createFTSTriggerStmts(tableName, index string, fields []string) []string {
+	cols := quoteIdents(fields)
+	newValues := make([]string, len(fields))
+	for i, f := range fields {
+		newValues[i] = "new." + quoteIdent(f)
+	}
+	insert := fmt.Sprintf(`DELETE FROM %q WHERE rowid = new.rowid; INSERT INTO %q (rowid, %s) VALUES (new.rowid, %s);`, index, index, cols, strings.Join(newValues, ", "))
+	return []string{
+		fmt.Sprintf(`CREATE TRIGGER %q AFTER INSERT ON %q BEGIN %s END`, index+"_ai", tableName, insert),
+		fmt.Sprintf(`CREATE TRIGGER %q AFTER DELETE ON %q BEGIN DELETE FROM %q WHERE rowid = old.rowid; END`, index+"_ad", tableName, index),
+		fmt.Sprintf(`CREATE TRIGGER %q AFTER UPDATE ON %q BEGIN DELETE FROM %q WHERE rowid = old.rowid; %s END`, index+"_au", tableName, index, insert),
+	}
+}
+
+func dropFTSTriggerStmts(index string) []string {
+	return []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %q`, index+"_ai"),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %q`, index+"_ad"),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %q`, index+"_au"),
+	}
+}
+
+// readFTSMeta 读取表当前生效的全文索引元数据，索引不存在时返回 nil
+func readFTSMeta(ctx context.Context, db *sql.DB, tableName string) (*ftsMeta, error) {
+	meta := &ftsMeta{}
+	var fieldsJSON string
+	err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT version, tokenizer, fields, built_at FROM %q WHERE table_name = ?`, ftsMetaTable), tableName).
+		Scan(&meta.Version, &meta.Tokenizer, &fieldsJSON, &meta.BuiltAt)
+	if errors.Is(err, sql.ErrNoRows) || (err != nil && strings.Contains(err.Error(), "no such table")) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 的全文索引元数据失败: %w", tableName, err)
+	}
+	if err := json.Unmarshal([]byte(fieldsJSON), &meta.Fields); err != nil {
+		return nil, fmt.Errorf("解析表 '%s' 的全文索引元数据失败: %w", tableName, err)
+	}
+	return meta, nil
+}
+
+// activeFTSMeta 返回业务组中第一个含有该表的库 (按库名排序) 上生效的全文索引元数据。
+// 各库的索引由同一次重建生成，读取一个库即可；读取失败时视为没有索引。
+func (m *Manager) activeFTSMeta(ctx context.Context, bizName, tableName string) *ftsMeta {
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libs := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if info := m.dbSchemaCache[db]; info != nil {
+			if _, exists := info.allTablesAndColumns[tableName]; exists {
+				libs = append(libs, libName)
+			}
+		}
+	}
+	m.mu.RUnlock()
+	if len(libs) == 0 {
+		return nil
+	}
+	sort.Strings(libs)
+	meta, err := readFTSMeta(ctx, dbInstances[libs[0]], tableName)
+	if err != nil {
+		slog.Warn("[DBManager FTS] 读取全文索引元数据失败", "biz", bizName, "table", tableName, "error", err)
+		return nil
+	}
+	return meta
+}
+
+// buildFTSMatch 把检索值与候选值组装为限定在单个字段上的 FTS5 MATCH 表达式。
+// 每个候选值按空白切分为词并去掉停用词，词之间为 AND，候选值之间为 OR；
+// 每个词都作为带引号的字符串传入，用户输入中的 FTS5 运算符不会生效。
+// prefix 为 true 时每个词按前缀匹配。
+func buildFTSMatch(field, value string, values []string, prefix bool, stopwords []string) (string, error) {
+	stop := make(map[string]bool, len(stopwords))
+	for _, w := range stopwords {
+		stop[strings.ToLower(w)] = true
+	}
+	seen := make(map[string]bool)
+	var alternatives []string
+	for _, candidate := range append([]string{value}, values...) {
+		var terms []string
+		for _, term := range strings.Fields(candidate) {
+			if stop[strings.ToLower(term)] {
+				continue
+			}
+			quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+			if prefix {
+				quoted += "*"
+			}
+			terms = append(terms, quoted)
+		}
+		if len(terms) == 0 {
+			continue
+		}
+		alt := "(" + strings.Join(terms, " ") + ")"
+		if !seen[alt] {
+			seen[alt] = true
+			alternatives = append(alternatives, alt)
+		}
+	}
+	if len(alternatives) == 0 {
+		return "", fmt.Errorf("字段 '%s' 的全文检索词为空或只包含停用词", field)
+	}
+	return fmt.Sprintf("{%s} : (%s)", quoteIdent(field), strings.Join(alternatives, " OR ")), nil
+}
+
+// quoteIdent 以 SQL 标识符的形式为名称加双引号
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteLiteral 以 SQL 字符串字面量的形式为值加单引号
+func quoteLiteral(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
@@ -0,0 +1,177 @@
+// file: internal/adapter/datasource/sqlite/fts_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFTSMatch(t *testing.T) {
+	match, err := buildFTSMatch("title", `the "old" man`, []string{"老人"}, false, []string{"THE"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"title"} : (("""old""" "man") OR ("老人"))`, match)
+
+	match, err = buildFTSMatch("title", "sea", nil, true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"title"} : (("sea"*))`, match)
+
+	_, err = buildFTSMatch("title", "the a", nil, false, []string{"the", "a"})
+	assert.ErrorContains(t, err, "停用词")
+}
+
+func TestFullTextRebuildAndQuery(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (id, title, note) VALUES (1, 'The Old Man and the Sea', 'novella'), (2, 'Café Society', 'essays'), (3, 'Sea of Tranquility', 'novel');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (id, title, note) VALUES (1, '鲁迅全集第一卷', 'collected'), (2, 'Seashore Life', 'field guide');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "library",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"books": {
+				TableName:    "books",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"title": {FieldName: "title", IsSearchable: true, IsReturnable: true},
+					"note":  {FieldName: "note", IsSearchable: true, IsReturnable: true},
+				},
+				FTS: &domain.FTSConfig{Fields: []string{"title"}, Tokenizer: domain.FTSTokenizerUnicode61, RemoveDiacritics: true, Stopwords: []string{"the"}, Version: 1},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"library": {"a": dbA, "b": dbB}}
+	for _, db := range []*sql.DB{dbA, dbB} {
+		manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"books": {"id", "note", "title"}}}
+	}
+
+	step := func(name string, extra map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		payload := map[string]interface{}{
+			"table_name": "books",
+			"step":       name,
+			"config": map[string]interface{}{
+				"fields":            []interface{}{"title"},
+				"tokenizer":         cfg.Tables["books"].FTS.Tokenizer,
+				"remove_diacritics": true,
+				"version":           float64(cfg.Tables["books"].FTS.Version),
+			},
+		}
+		for k, v := range extra {
+			payload[k] = v
+		}
+		res, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpFTSRebuild, Payload: payload})
+		require.NoError(t, err)
+		return res.Data
+	}
+	rebuild := func() {
+		t.Helper()
+		prepared := step(ftsStepPrepare, nil)
+		assert.EqualValues(t, 5, prepared["total"])
+		for _, lib := range []string{"a", "b"} {
+			after := float64(0)
+			for {
+				copied := step(ftsStepCopy, map[string]interface{}{"lib": lib, "after_rowid": after, "limit": float64(2)})
+				after = float64(copied["next_rowid"].(int64))
+				if copied["done"].(bool) {
+					break
+				}
+			}
+		}
+		step(ftsStepFinish, nil)
+	}
+	titles := func(filter map[string]interface{}) []string {
+		t.Helper()
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{
+			"table":   "books",
+			"filters": []interface{}{filter},
+		}})
+		require.NoError(t, err)
+		var out []string
+		for _, item := range result.Data["items"].([]interface{}) {
+			out = append(out, item.(map[string]any)["title"].(string))
+		}
+		return out
+	}
+
+	_, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{
+		"table":   "books",
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": "sea", "fts": true}},
+	}})
+	assert.ErrorContains(t, err, "全文索引尚未建立")
+
+	rebuild()
+	assert.ElementsMatch(t, []string{"The Old Man and the Sea", "Sea of Tranquility"}, titles(map[string]interface{}{"field": "title", "value": "sea", "fts": true}))
+	assert.ElementsMatch(t, []string{"The Old Man and the Sea", "Sea of Tranquility", "Seashore Life"}, titles(map[string]interface{}{"field": "title", "value": "sea", "fts": true, "fuzzy": true}))
+	assert.Equal(t, []string{"Café Society"}, titles(map[string]interface{}{"field": "title", "value": "cafe", "fts": true}), "应忽略附加符号")
+	assert.Equal(t, []string{"The Old Man and the Sea"}, titles(map[string]interface{}{"field": "title", "value": "the old man", "fts": true}), "停用词应被剔除")
+
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{
+		"table":   "books",
+		"filters": []interface{}{map[string]interface{}{"field": "note", "value": "novel", "fts": true}},
+	}})
+	assert.ErrorContains(t, err, "未启用全文检索")
+
+	// 索引随业务表写入同步
+	_, err = dbA.Exec(`INSERT INTO books (id, title, note) VALUES (4, 'Sea Change', 'novel')`)
+	require.NoError(t, err)
+	_, err = dbA.Exec(`DELETE FROM books WHERE id = 3`)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"The Old Man and the Sea", "Sea Change"}, titles(map[string]interface{}{"field": "title", "value": "sea", "fts": true}))
+
+	// 切换为 trigram 后重建，中文可按子串检索，Schema 报告新的分词器
+	cfg.Tables["books"].FTS = &domain.FTSConfig{Fields: []string{"title"}, Tokenizer: domain.FTSTokenizerTrigram, Version: 2}
+	schema, err := manager.GetSchema(ctx, port.SchemaRequest{BizName: "library", TableName: "books"})
+	require.NoError(t, err)
+	for _, field := range schema.Tables["books"] {
+		if field.Name == "title" {
+			assert.Equal(t, domain.FTSTokenizerUnicode61, field.FullTextTokenizer, "重建完成前仍报告旧索引的分词器")
+		}
+	}
+	rebuild()
+	assert.Equal(t, []string{"鲁迅全集第一卷"}, titles(map[string]interface{}{"field": "title", "value": "迅全集", "fts": true}))
+	schema, err = manager.GetSchema(ctx, port.SchemaRequest{BizName: "library", TableName: "books"})
+	require.NoError(t, err)
+	for _, field := range schema.Tables["books"] {
+		expected := ""
+		if field.Name == "title" {
+			expected = domain.FTSTokenizerTrigram
+		}
+		assert.Equal(t, expected, field.FullTextTokenizer, "字段 %s", field.Name)
+	}
+
+	// 索引表与元数据表不出现在物理 Schema 中
+	tables, err := getTablesSet(dbA)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"books": {}}, tables)
+
+	step(ftsStepDrop, nil)
+	meta, err := readFTSMeta(ctx, dbA, "books")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	_, err = dbA.Exec(`INSERT INTO books (id, title, note) VALUES (5, 'After Drop', 'x')`)
+	assert.NoError(t, err, "删除索引后触发器也应被删除")
+}
+
+func TestFullTextRebuild_ICUUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	db := createTestDB(t, dir, "a.db", `CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT);`)
+	_, err := prepareFTSStaging(context.Background(), db, "books", &domain.FTSConfig{Fields: []string{"title"}, Tokenizer: domain.FTSTokenizerICU})
+	assert.EqualError(t, err, "当前 SQLite 构建不支持 icu 分词器")
+}
@@ -3,6 +3,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -10,10 +11,11 @@ import (
 	"strings"
 )
 
-// buildQuerySQL 根据管理员配置动态构建数据查询的 SQL 语句
+// buildQuerySQL 根据管理员配置动态构建数据查询的 SQL 语句；distinct 为 true 时按所选列去重
 func buildQuerySQL(
 	tableName string,
 	selectDBFields []string,
+	distinct bool,
 	queryParams []queryParam,
 	page int,
 	size int,
@@ -36,6 +38,9 @@ func buildQuerySQL(
 
 	var sb strings.Builder
 	sb.WriteString("SELECT ")
+	if distinct {
+		sb.WriteString("DISTINCT ")
+	}
 	sb.WriteString(selectClause)
 	sb.WriteString(fmt.Sprintf(" FROM %q", tableName))
 	if whereClause != "" {
@@ -48,8 +53,8 @@ func buildQuerySQL(
 	return sb.String(), args, nil
 }
 
-// buildCountSQL 用于构建计算总数的SQL查询
-func buildCountSQL(tableName string, queryParams []queryParam) (string, []any, error) {
+// buildCountSQL 用于构建计算总数的SQL查询；distinctFields 非空时统计这些列去重后的组合数
+func buildCountSQL(tableName string, distinctFields []string, queryParams []queryParam) (string, []any, error) {
 	if tableName == "" {
 		return "", nil, errors.New("表名不能为空 (buildCountSQL)")
 	}
@@ -58,11 +63,18 @@ func buildCountSQL(tableName string, queryParams []queryParam) (string, []any, e
 		return "", nil, err
 	}
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("SELECT COUNT(*) FROM %q", tableName))
+	if len(distinctFields) > 0 {
+		sb.WriteString(fmt.Sprintf(`SELECT COUNT(*) FROM (SELECT DISTINCT "%s" FROM %q`, strings.Join(distinctFields, `", "`), tableName))
+	} else {
+		sb.WriteString(fmt.Sprintf("SELECT COUNT(*) FROM %q", tableName))
+	}
 	if whereClause != "" {
 		sb.WriteString(" ")
 		sb.WriteString(whereClause)
 	}
+	if len(distinctFields) > 0 {
+		sb.WriteString(")")
+	}
 	return sb.String(), whereArgs, nil
 }
 
@@ -135,19 +147,61 @@ func buildWhereClause(filters []queryParam) (string, []interface{}, error) {
 	args := make([]interface{}, 0, len(filters))
 
 	for i, p := range filters {
-		var operator, value string
-		if p.Fuzzy {
-			operator = "LIKE"
-			likeValue := strings.ReplaceAll(p.Value, `\`, `\\`)
-			likeValue = strings.ReplaceAll(likeValue, `%`, `\%`)
-			likeValue = strings.ReplaceAll(likeValue, `_`, `\_`)
-			value = "%" + likeValue + "%"
-		} else {
-			operator = "="
-			value = p.Value
+		switch {
+		case p.Op == inOperator:
+			cond, arg, err := inCondition(p)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, cond)
+			args = append(args, arg...)
+		case p.Op != "":
+			tmpl, known := nullOperators[p.Op]
+			if !known {
+				return "", nil, fmt.Errorf("无效的过滤运算符: %s", p.Op)
+			}
+			conditions = append(conditions, fmt.Sprintf(tmpl, p.Field))
+		case p.FullText:
+			// Value 已是完整的 MATCH 表达式，候选值也已并入其中
+			conditions = append(conditions, fmt.Sprintf("rowid IN (SELECT rowid FROM %q WHERE %q MATCH ?)", p.FTSIndex, p.FTSIndex))
+			args = append(args, p.Value)
+		case p.Pinyin:
+			// 输入同时与全拼和首字母比较，"luxun" 与 "lx" 都能命中 "鲁迅"
+			fullCol, initialsCol := pinyinColumnPrefix+p.Field, pinyinInitialsColumnPrefix+p.Field
+			if p.Fuzzy {
+				conditions = append(conditions, fmt.Sprintf("(%q LIKE ? OR %q LIKE ?)", fullCol, initialsCol))
+				like := "%" + escapeLike(p.Value) + "%"
+				args = append(args, like, like)
+			} else {
+				conditions = append(conditions, fmt.Sprintf("(%q = ? OR %q = ?)", fullCol, initialsCol))
+				args = append(args, p.Value, p.Value)
+			}
+		case len(p.Values) > 0:
+			// 候选值之间以 OR 连接并加括号，避免与前后条件的 AND/OR 混淆
+			candidates := append([]string{p.Value}, p.Values...)
+			seen := make(map[string]bool, len(candidates))
+			parts := make([]string, 0, len(candidates))
+			for _, v := range candidates {
+				if seen[v] {
+					continue
+				}
+				seen[v] = true
+				cond, arg := textCondition(p, v)
+				parts = append(parts, cond)
+				args = append(args, arg)
+			}
+			conditions = append(conditions, "("+strings.Join(parts, " OR ")+")")
+		default:
+			cond, arg := textCondition(p, p.Value)
+			conditions = append(conditions, cond)
+			args = append(args, arg)
+		}
+		if p.Negate {
+			// 条件整体加括号后取反，避免 NOT 只作用于其中一部分；
+			// 字段为 NULL 时条件结果为 NULL，以 COALESCE 视为不满足原条件，使 "!=" 能命中空值记录
+			last := len(conditions) - 1
+			conditions[last] = "NOT COALESCE((" + conditions[last] + "), 0)"
 		}
-		conditions = append(conditions, fmt.Sprintf("%q %s ?", p.Field, operator))
-		args = append(args, value)
 		if i < len(filters)-1 {
 			logic := strings.ToUpper(p.Logic)
 			if logic == "AND" || logic == "OR" {
@@ -160,6 +214,34 @@ func buildWhereClause(filters []queryParam) (string, []interface{}, error) {
 	return "WHERE " + strings.Join(conditions, " "), args, nil
 }
 
+// inCondition 生成 "in" 运算符的条件。列表不超过 inlineInValues 时逐个绑定参数，
+// 否则整个列表作为一个 JSON 数组参数，经 json_each 展开为子查询
+func inCondition(p queryParam) (string, []interface{}, error) {
+	if len(p.Values) == 0 {
+		return "", nil, fmt.Errorf("运算符 'in' 的取值列表为空: %s", p.Field)
+	}
+	if len(p.Values) <= inlineInValues {
+		args := make([]interface{}, len(p.Values))
+		for i, v := range p.Values {
+			args[i] = v
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(p.Values)), ", ")
+		return fmt.Sprintf("%q IN (%s)", p.Field, placeholders), args, nil
+	}
+	list, err := json.Marshal(p.Values)
+	if err != nil {
+		return "", nil, fmt.Errorf("编码 'in' 取值列表失败: %w", err)
+	}
+	return fmt.Sprintf("%q IN (SELECT value FROM json_each(?))", p.Field), []interface{}{string(list)}, nil
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `%`, `\%`)
+	return strings.ReplaceAll(v, `_`, `\_`)
+}
+
 // getTablesSet 返回数据库中所有用户表的集合
 func getTablesSet(db *sql.DB) (map[string]struct{}, error) {
 	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE ?`, innerPrefix+"%")
@@ -4,6 +4,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -19,7 +20,7 @@ func TestBuildQuerySQL(t *testing.T) {
 	filters := []queryParam{
 		{Field: "name", Value: "John", Fuzzy: false},
 	}
-	sqlStr, args, err := buildQuerySQL("users", []string{"id", "name"}, filters, 2, 10)
+	sqlStr, args, err := buildQuerySQL("users", []string{"id", "name"}, false, filters, 2, 10)
 	if err != nil {
 		t.Fatalf("buildQuerySQL 返回错误: %v", err)
 	}
@@ -37,7 +38,7 @@ func TestBuildQuerySQL(t *testing.T) {
 
 func TestBuildQuerySQL_Defaults(t *testing.T) {
 	// page<1 与 size<1 应触发默认值 page=1,size=50
-	sqlStr, args, err := buildQuerySQL("tbl", []string{"x"}, nil, 0, 0)
+	sqlStr, args, err := buildQuerySQL("tbl", []string{"x"}, false, nil, 0, 0)
 	if err != nil {
 		t.Fatalf("buildQuerySQL 返回错误: %v", err)
 	}
@@ -51,7 +52,7 @@ func TestBuildQuerySQL_Defaults(t *testing.T) {
 }
 
 func TestBuildCountSQL(t *testing.T) {
-	sqlStr, args, err := buildCountSQL("orders", []queryParam{
+	sqlStr, args, err := buildCountSQL("orders", nil, []queryParam{
 		{Field: "status", Value: "PAID"},
 	})
 	if err != nil {
@@ -144,6 +145,135 @@ func TestBuildWhereClause_FuzzyAndLogic(t *testing.T) {
 	}
 }
 
+func TestBuildWhereClause_NullOperators(t *testing.T) {
+	clause, args, err := buildWhereClause([]queryParam{
+		{Field: "author", Op: "is_null", Logic: "OR"},
+		{Field: "author", Op: "is_empty", Logic: "AND"},
+		{Field: "title", Value: "论语"},
+	})
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	wantClause := `WHERE "author" IS NULL OR "author" = '' AND "title" = ?`
+	if clause != wantClause {
+		t.Errorf("WHERE 子句不匹配: %s", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"论语"}) {
+		t.Errorf("空值运算符不应产生参数: %#v", args)
+	}
+
+	if _, _, err := buildWhereClause([]queryParam{{Field: "author", Op: "is_blank"}}); err == nil {
+		t.Error("未知运算符应返回错误")
+	}
+}
+
+func TestBuildWhereClause_Values(t *testing.T) {
+	clause, args, err := buildWhereClause([]queryParam{
+		{Field: "sender", Value: "鲁迅", Values: []string{"魯迅", "鲁迅", "Lu Xun"}, Logic: "AND"},
+		{Field: "place", Value: "北_京", Values: []string{"北平"}, Fuzzy: true},
+	})
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	wantClause := `WHERE ("sender" = ? OR "sender" = ? OR "sender" = ?) AND ("place" LIKE ? OR "place" LIKE ?)`
+	if clause != wantClause {
+		t.Errorf("WHERE 子句不匹配: %s", clause)
+	}
+	wantArgs := []interface{}{"鲁迅", "魯迅", "Lu Xun", `%北\_京%`, "%北平%"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("参数不匹配: %#v", args)
+	}
+}
+
+func TestBuildWhereClause_Negate(t *testing.T) {
+	filters := []queryParam{
+		{Field: "author", Value: "鲁迅", Negate: true, Logic: "OR"},
+		{Field: "title", Value: "呐", Fuzzy: true, Negate: true, Logic: "AND"},
+		{Field: "year", Value: "1921"},
+	}
+	clause, args, err := buildWhereClause(filters)
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	wantClause := `WHERE NOT COALESCE(("author" = ?), 0) OR NOT COALESCE(("title" LIKE ?), 0) AND "year" = ?`
+	if clause != wantClause {
+		t.Errorf("WHERE 子句不匹配: %s", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"鲁迅", "%呐%", "1921"}) {
+		t.Errorf("参数不匹配: %#v", args)
+	}
+
+	// 取反条件应命中字段为 NULL 的记录
+	db, err := sql.Open("sqlite", "file:memnegate?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("打开内存数据库失败: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE books (title TEXT, author TEXT); INSERT INTO books VALUES ('呐喊', '鲁迅'), ('女神', '郭沫若'), ('佚名集', NULL)`); err != nil {
+		t.Fatalf("初始化表失败: %v", err)
+	}
+	clause, args, err = buildWhereClause([]queryParam{{Field: "author", Value: "鲁迅", Negate: true}})
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM books `+clause, args...).Scan(&count); err != nil {
+		t.Fatalf("执行查询失败: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("author != '鲁迅' 应命中 2 条记录 (含 NULL)，实际 %d", count)
+	}
+}
+
+func TestBuildWhereClause_In(t *testing.T) {
+	clause, args, err := buildWhereClause([]queryParam{
+		{Field: "id", Op: inOperator, Values: []string{"1", "3"}, Logic: "AND"},
+		{Field: "status", Value: "active"},
+	})
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	wantClause := `WHERE "id" IN (?, ?) AND "status" = ?`
+	if clause != wantClause {
+		t.Errorf("WHERE 子句不匹配: %s", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"1", "3", "active"}) {
+		t.Errorf("参数不匹配: %#v", args)
+	}
+
+	// 超过 inlineInValues 的列表经 json_each 展开，只绑定一个参数，且仍按列的类型亲和性比较
+	db, err := sql.Open("sqlite", "file:memin?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("打开内存数据库失败: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE items (id INTEGER PRIMARY KEY); WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 500) INSERT INTO items SELECT i FROM n`); err != nil {
+		t.Fatalf("初始化表失败: %v", err)
+	}
+	values := make([]string, 0, 300)
+	for i := 1; i <= 600; i += 2 {
+		values = append(values, fmt.Sprintf("%d", i))
+	}
+	clause, args, err = buildWhereClause([]queryParam{{Field: "id", Op: inOperator, Values: values}})
+	if err != nil {
+		t.Fatalf("buildWhereClause 错误: %v", err)
+	}
+	if clause != `WHERE "id" IN (SELECT value FROM json_each(?))` || len(args) != 1 {
+		t.Fatalf("长列表应以单个 JSON 参数绑定: %s %d", clause, len(args))
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM items `+clause, args...).Scan(&count); err != nil {
+		t.Fatalf("执行查询失败: %v", err)
+	}
+	if count != 250 {
+		t.Errorf("应命中 250 条记录，实际 %d", count)
+	}
+
+	if _, _, err := buildWhereClause([]queryParam{{Field: "id", Op: inOperator}}); err == nil {
+		t.Error("空的 in 列表应返回错误")
+	}
+}
+
 // -----------------------------------------------------------------------------
 // getTablesSet / detectTable / listColumns
 // -----------------------------------------------------------------------------
@@ -0,0 +1,313 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/hierarchy.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxHierarchyDepth 是递归查询展开的最大层数，防止父子关系成环时无限递归
+const maxHierarchyDepth = 64
+
+// hierarchyScope 是一次层级浏览请求解析出的目标表、层级配置、可返回字段及包含该表的库 (按库名排序)
+type hierarchyScope struct {
+	tableName   string
+	hierarchy   *domain.HierarchyConfig
+	returnable  []string
+	libNames    []string
+	dbInstances map[string]*sql.DB
+}
+
+// resolveHierarchy 校验层级浏览请求并解析其作用范围。
+// 与 record 模式一样只对公开且可搜索的表开放，层级关系的键字段与父字段必须可返回
+func (m *Manager) resolveHierarchy(ctx context.Context, bizName string, queryMap map[string]interface{}) (*hierarchyScope, error) {
+	mode, _ := queryMap[port.QueryModeKey].(string)
+	tableName, _ := queryMap["table"].(string)
+	if tableName == "" {
+		return nil, fmt.Errorf("无效请求: %s 模式必须包含 'table' 字符串字段", mode)
+	}
+
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if !bizAdminConfig.IsPubliclySearchable {
+		return nil, port.ErrPermissionDenied
+	}
+	tableAdminConfig, exists := bizAdminConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	if !tableAdminConfig.IsSearchable {
+		return nil, port.ErrPermissionDenied
+	}
+	hierarchy := tableAdminConfig.Hierarchy
+	if hierarchy == nil || hierarchy.KeyField == "" || hierarchy.ParentField == "" {
+		return nil, fmt.Errorf("无效请求: 表 '%s' 未配置层级关系", tableName)
+	}
+	for _, f := range []string{hierarchy.KeyField, hierarchy.ParentField} {
+		if setting, ok := tableAdminConfig.Fields[f]; !ok || !setting.IsReturnable {
+			return nil, fmt.Errorf("表 '%s' 层级关系的字段 '%s' 不可返回", tableName, f)
+		}
+	}
+
+	var returnable []string
+	for fieldName, fieldSetting := range tableAdminConfig.Fields {
+		if fieldSetting.IsReturnable {
+			returnable = append(returnable, fieldName)
+		}
+	}
+	sort.Strings(returnable)
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libNames := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			if _, tableExists := schema.allTablesAndColumns[tableName]; tableExists {
+				libNames = append(libNames, libName)
+			}
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(libNames)
+
+	return &hierarchyScope{
+		tableName:   tableName,
+		hierarchy:   hierarchy,
+		returnable:  returnable,
+		libNames:    libNames,
+		dbInstances: dbInstances,
+	}, nil
+}
+
+// parentCondition 返回匹配 node 直接子节点的条件 (alias 为表别名)，node 为空时匹配根节点
+func (s *hierarchyScope) parentCondition(alias, node string) (string, []interface{}) {
+	if node == "" {
+		return fmt.Sprintf(`(%s.%q IS NULL OR %s.%q = '')`, alias, s.hierarchy.ParentField, alias, s.hierarchy.ParentField), nil
+	}
+	return fmt.Sprintf(`%s.%q = ?`, alias, s.hierarchy.ParentField), []interface{}{node}
+}
+
+// selectColumns 返回带表别名的可返回字段列表
+func (s *hierarchyScope) selectColumns(alias string) string {
+	quoted := make([]string, len(s.returnable))
+	for i, f := range s.returnable {
+		quoted[i] = fmt.Sprintf("%s.%q", alias, f)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// queryChildren 分页读取节点的直接子节点。多库业务组中各库的子节点按库名顺序拼接后分页，
+// 每个子节点附带其在同一库中的直接子节点数 "_child_count"，客户端据此决定是否可以继续展开
+func (m *Manager) queryChildren(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	scope, err := m.resolveHierarchy(ctx, bizName, queryMap)
+	if err != nil {
+		return nil, err
+	}
+	node, _ := queryMap[port.QueryNodeKey].(string)
+	page, size := 1, 50
+	if pageF, ok := queryMap["page"].(float64); ok && pageF >= 1 {
+		page = int(pageF)
+	}
+	if sizeF, ok := queryMap["size"].(float64); ok && sizeF >= 1 {
+		size = int(sizeF)
+	}
+
+	where, args := scope.parentCondition("n", node)
+	sortField := scope.hierarchy.SortField
+	if sortField == "" {
+		sortField = scope.hierarchy.KeyField
+	}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %q AS n WHERE %s`, scope.tableName, where)
+	selectQuery := fmt.Sprintf(
+		`SELECT %s, (SELECT COUNT(*) FROM %q AS c WHERE c.%q = n.%q) AS _child_count FROM %q AS n WHERE %s ORDER BY n.%q, n.%q LIMIT ? OFFSET ?`,
+		scope.selectColumns("n"), scope.tableName, scope.hierarchy.ParentField, scope.hierarchy.KeyField,
+		scope.tableName, where, sortField, scope.hierarchy.KeyField)
+
+	var total int64
+	items := make([]interface{}, 0, size)
+	offset := int64((page - 1) * size)
+	for _, libName := range scope.libNames {
+		db := scope.dbInstances[libName]
+		var n int64
+		if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&n); err != nil {
+			return nil, fmt.Errorf("统计库 '%s/%s' 表 '%s' 的子节点失败: %w", bizName, libName, scope.tableName, err)
+		}
+		total += n
+		if len(items) >= size {
+			continue
+		}
+		if offset >= n {
+			offset -= n
+			continue
+		}
+		fields := append(append([]string{}, scope.returnable...), "_child_count")
+		rows, err := scanHierarchyRows(ctx, db, selectQuery, fields, append(args, size-len(items), offset)...)
+		if err != nil {
+			return nil, fmt.Errorf("读取库 '%s/%s' 表 '%s' 的子节点失败: %w", bizName, libName, scope.tableName, err)
+		}
+		offset = 0
+		for _, row := range rows {
+			row["__lib"] = libName
+			items = append(items, row)
+		}
+	}
+
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"items": items,
+			"total": total,
+			"node":  node,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// queryAncestors 以递归 CTE 沿父字段向上查找，返回从根节点到 node 的路径 (含 node 自身)。
+// 多库业务组中使用库名排序最靠前的包含该节点的库，祖先不会跨库查找
+func (m *Manager) queryAncestors(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	scope, err := m.resolveHierarchy(ctx, bizName, queryMap)
+	if err != nil {
+		return nil, err
+	}
+	node, _ := queryMap[port.QueryNodeKey].(string)
+	if node == "" {
+		return nil, fmt.Errorf("无效请求: ancestors 模式必须包含 '%s' 字符串字段", port.QueryNodeKey)
+	}
+
+	key, parent := scope.hierarchy.KeyField, scope.hierarchy.ParentField
+	query := fmt.Sprintf(
+		`WITH RECURSIVE path AS (
+			SELECT %[1]s, 0 AS _depth FROM %[2]q AS t WHERE t.%[3]q = ?
+			UNION ALL
+			SELECT %[1]s, path._depth + 1 FROM %[2]q AS t JOIN path ON t.%[3]q = path.%[4]q WHERE path._depth < %[5]d
+		) SELECT %[6]s FROM path ORDER BY _depth DESC`,
+		scope.selectColumns("t"), scope.tableName, key, parent, maxHierarchyDepth, scope.selectColumns("path"))
+
+	items := make([]interface{}, 0)
+	for _, libName := range scope.libNames {
+		rows, err := scanHierarchyRows(ctx, scope.dbInstances[libName], query, scope.returnable, node)
+		if err != nil {
+			return nil, fmt.Errorf("读取库 '%s/%s' 表 '%s' 的祖先节点失败: %w", bizName, libName, scope.tableName, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		for _, row := range rows {
+			row["__lib"] = libName
+			items = append(items, row)
+		}
+		break
+	}
+
+	return &port.QueryResult{
+		Data:   map[string]interface{}{"items": items, "node": node},
+		Source: m.Type(),
+	}, nil
+}
+
+// querySubtree 以递归 CTE 统计 node 子树中各层的后代数量，node 为空时从根节点 (第 1 层) 开始统计整个森林。
+// 每个后代按其最浅的层计数一次，父子关系成环时递归在最大深度处停止；多库业务组的结果为各库之和
+func (m *Manager) querySubtree(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	scope, err := m.resolveHierarchy(ctx, bizName, queryMap)
+	if err != nil {
+		return nil, err
+	}
+	node, _ := queryMap[port.QueryNodeKey].(string)
+
+	key, parent := scope.hierarchy.KeyField, scope.hierarchy.ParentField
+	base, args := "SELECT ?, 0", []interface{}{node}
+	if node == "" {
+		where, _ := scope.parentCondition("r", "")
+		base = fmt.Sprintf(`SELECT r.%q, 1 FROM %q AS r WHERE %s`, key, scope.tableName, where)
+		args = nil
+	}
+	query := fmt.Sprintf(
+		`WITH RECURSIVE sub(k, depth) AS (
+			%[1]s
+			UNION
+			SELECT c.%[2]q, sub.depth + 1 FROM %[3]q AS c JOIN sub ON c.%[4]q = sub.k WHERE sub.depth < %[5]d
+		) SELECT depth, COUNT(*) FROM (SELECT k, MIN(depth) AS depth FROM sub GROUP BY k)
+		WHERE depth > 0 GROUP BY depth ORDER BY depth`,
+		base, key, scope.tableName, parent, maxHierarchyDepth)
+
+	counts := make(map[int64]int64)
+	for _, libName := range scope.libNames {
+		if err := func() error {
+			rows, err := scope.dbInstances[libName].QueryContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var depth, n int64
+				if err := rows.Scan(&depth, &n); err != nil {
+					return err
+				}
+				counts[depth] += n
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, fmt.Errorf("统计库 '%s/%s' 表 '%s' 的子树失败: %w", bizName, libName, scope.tableName, err)
+		}
+	}
+
+	depths := make([]int64, 0, len(counts))
+	for d := range counts {
+		depths = append(depths, d)
+	}
+	sort.Slice(depths, func(i, j int) bool { return depths[i] < depths[j] })
+	var descendants, maxDepth int64
+	levels := make([]interface{}, len(depths))
+	for i, d := range depths {
+		levels[i] = map[string]interface{}{"depth": d, "count": counts[d]}
+		descendants += counts[d]
+		maxDepth = d
+	}
+
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"node":        node,
+			"descendants": descendants,
+			"depth":       maxDepth,
+			"levels":      levels,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// scanHierarchyRows 执行查询并按 fields 的顺序把各行读取为字段到值的映射
+func scanHierarchyRows(ctx context.Context, db *sql.DB, query string, fields []string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		vals := make([]any, len(fields))
+		ptrs := make([]any, len(fields))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(fields)+1)
+		for i, f := range fields {
+			row[f] = normalizeValue(vals[i])
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,126 @@
+// file: internal/adapter/datasource/sqlite/hierarchy_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_HierarchyModes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// 全宗 F1 → 系列 S1, S2 → 案卷 A1, A2 (属于 S1)；F2 为另一个全宗，X1/X2 互为父节点构成环
+	db := createTestDB(t, dir, "a.db",
+		`CREATE TABLE units (code TEXT PRIMARY KEY, parent TEXT, title TEXT, seq INTEGER, secret TEXT);`,
+		`INSERT INTO units (code, parent, title, seq, secret) VALUES
+			('F1', NULL, '全宗一', 1, 'x'), ('F2', '', '全宗二', 2, 'x'),
+			('S1', 'F1', '系列一', 2, 'x'), ('S2', 'F1', '系列二', 1, 'x'),
+			('A1', 'S1', '案卷一', 1, 'x'), ('A2', 'S1', '案卷二', 2, 'x'),
+			('X1', 'X2', '环一', 1, 'x'), ('X2', 'X1', '环二', 1, 'x');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"units": {
+				TableName:    "units",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"code":   {FieldName: "code", IsReturnable: true},
+					"parent": {FieldName: "parent", IsReturnable: true},
+					"title":  {FieldName: "title", IsReturnable: true},
+					"seq":    {FieldName: "seq", IsReturnable: true},
+					"secret": {FieldName: "secret", IsReturnable: false},
+				},
+				Hierarchy: &domain.HierarchyConfig{KeyField: "code", ParentField: "parent", SortField: "seq"},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": db}}
+	manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{
+		allTablesAndColumns: map[string][]string{"units": {"code", "parent", "secret", "seq", "title"}},
+	}
+
+	query := func(q map[string]interface{}) map[string]interface{} {
+		q["table"] = "units"
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: q})
+		require.NoError(t, err)
+		_, err = structpb.NewStruct(result.Data)
+		require.NoError(t, err, "结果必须可以转换为 protobuf Struct")
+		return result.Data
+	}
+	codes := func(data map[string]interface{}) []string {
+		var out []string
+		for _, item := range data["items"].([]interface{}) {
+			row := item.(map[string]interface{})
+			assert.NotContains(t, row, "secret", "不可返回的字段不应出现在结果中")
+			out = append(out, row["code"].(string))
+		}
+		return out
+	}
+
+	t.Run("children", func(t *testing.T) {
+		roots := query(map[string]interface{}{port.QueryModeKey: port.QueryModeChildren})
+		assert.Equal(t, []string{"F1", "F2"}, codes(roots), "NULL 与空字符串都视为根节点")
+		first := roots["items"].([]interface{})[0].(map[string]interface{})
+		assert.EqualValues(t, 2, first["_child_count"])
+
+		series := query(map[string]interface{}{port.QueryModeKey: port.QueryModeChildren, port.QueryNodeKey: "F1"})
+		assert.Equal(t, []string{"S2", "S1"}, codes(series), "按 SortField 排序")
+		assert.EqualValues(t, 2, series["total"])
+
+		paged := query(map[string]interface{}{port.QueryModeKey: port.QueryModeChildren, port.QueryNodeKey: "F1", "page": float64(2), "size": float64(1)})
+		assert.Equal(t, []string{"S1"}, codes(paged))
+		assert.EqualValues(t, 2, paged["total"])
+	})
+
+	t.Run("ancestors", func(t *testing.T) {
+		path := query(map[string]interface{}{port.QueryModeKey: port.QueryModeAncestors, port.QueryNodeKey: "A2"})
+		assert.Equal(t, []string{"F1", "S1", "A2"}, codes(path))
+
+		missing := query(map[string]interface{}{port.QueryModeKey: port.QueryModeAncestors, port.QueryNodeKey: "nope"})
+		assert.Empty(t, missing["items"])
+
+		cycle := query(map[string]interface{}{port.QueryModeKey: port.QueryModeAncestors, port.QueryNodeKey: "X1"})
+		assert.Len(t, cycle["items"], maxHierarchyDepth+1, "成环时在最大深度处停止")
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		sub := query(map[string]interface{}{port.QueryModeKey: port.QueryModeSubtree, port.QueryNodeKey: "F1"})
+		assert.EqualValues(t, 4, sub["descendants"])
+		assert.EqualValues(t, 2, sub["depth"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"depth": int64(1), "count": int64(2)},
+			map[string]interface{}{"depth": int64(2), "count": int64(2)},
+		}, sub["levels"])
+
+		forest := query(map[string]interface{}{port.QueryModeKey: port.QueryModeSubtree})
+		assert.EqualValues(t, 6, forest["descendants"], "环中的节点没有根，不计入森林")
+
+		cycle := query(map[string]interface{}{port.QueryModeKey: port.QueryModeSubtree, port.QueryNodeKey: "X1"})
+		assert.EqualValues(t, 1, cycle["descendants"], "成环时每个节点只计数一次，节点自身不计入")
+	})
+
+	t.Run("未配置层级关系", func(t *testing.T) {
+		hierarchy := cfg.Tables["units"].Hierarchy
+		cfg.Tables["units"].Hierarchy = nil
+		defer func() { cfg.Tables["units"].Hierarchy = hierarchy }()
+		_, err := manager.Query(ctx, port.QueryRequest{
+			BizName: "archive",
+			Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeChildren, "table": "units"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,192 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/maintenance.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+// 库文件维护的步骤，见 port.MutateOpMaintenance
+const (
+	maintenanceStepInspect = "inspect"
+	maintenanceStepRun     = "run"
+)
+
+// maintenanceTaskOrder 是维护任务的执行顺序：先更新统计信息，再重建库文件，
+// 最后以检查点把 VACUUM 写入 WAL 的内容写回库文件并截断 WAL
+var maintenanceTaskOrder = []string{domain.MaintenanceTaskAnalyze, domain.MaintenanceTaskVacuum, domain.MaintenanceTaskCheckpoint}
+
+// maintain 处理 port.MutateOpMaintenance。payload 中 "step" 指定步骤：
+//   - inspect: 返回各库的存储状况
+//   - run: 按 "tasks" 逐库执行维护任务，"lib" 不为空时只处理该库，返回各库执行前后的状况
+func (m *Manager) maintain(ctx context.Context, bizName string, payload map[string]interface{}) (*port.MutateResult, error) {
+	m.mu.RLock()
+	dbInstances, bizExists := m.group[bizName]
+	libs := make([]string, 0, len(dbInstances))
+	for libName := range dbInstances {
+		libs = append(libs, libName)
+	}
+	m.mu.RUnlock()
+	if !bizExists {
+		return nil, port.ErrBizNotFound
+	}
+	sort.Strings(libs)
+
+	step, _ := payload["step"].(string)
+	data := map[string]interface{}{"step": step}
+	switch step {
+	case maintenanceStepInspect:
+		stats := make([]interface{}, 0, len(libs))
+		for _, libName := range libs {
+			s, err := inspectLib(ctx, dbInstances[libName], libName)
+			if err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			stats = append(stats, libStatsMap(s))
+		}
+		data["libs"] = stats
+
+	case maintenanceStepRun:
+		tasks, err := parseMaintenanceTasks(payload["tasks"])
+		if err != nil {
+			return nil, err
+		}
+		if lib, _ := payload["lib"].(string); lib != "" {
+			if _, exists := dbInstances[lib]; !exists {
+				return nil, fmt.Errorf("业务组 '%s' 中不存在库 '%s'", bizName, lib)
+			}
+			libs = []string{lib}
+		}
+		results := make([]interface{}, 0, len(libs))
+		for _, libName := range libs {
+			db := dbInstances[libName]
+			before, err := inspectLib(ctx, db, libName)
+			if err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			started := time.Now()
+			if err := runMaintenanceTasks(ctx, db, tasks); err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			elapsed := time.Since(started)
+			after, err := inspectLib(ctx, db, libName)
+			if err != nil {
+				return nil, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			results = append(results, map[string]interface{}{
+				"lib":         libName,
+				"before":      libStatsMap(before),
+				"after":       libStatsMap(after),
+				"duration_ms": elapsed.Milliseconds(),
+			})
+		}
+		taskList := make([]interface{}, len(tasks))
+		for i, t := range tasks {
+			taskList[i] = t
+		}
+		data["tasks"] = taskList
+		data["libs"] = results
+
+	default:
+		return nil, fmt.Errorf("无效请求: 不支持的库文件维护步骤 '%s'", step)
+	}
+	return &port.MutateResult{Data: data, Source: m.Type()}, nil
+}
+
+// parseMaintenanceTasks 校验任务列表并按 maintenanceTaskOrder 排序，列表为空时执行全部任务
+func parseMaintenanceTasks(raw interface{}) ([]string, error) {
+	list, _ := raw.([]interface{})
+	if len(list) == 0 {
+		return maintenanceTaskOrder, nil
+	}
+	requested := make(map[string]bool, len(list))
+	for _, item := range list {
+		task, _ := item.(string)
+		if !slices.Contains(maintenanceTaskOrder, task) {
+			return nil, fmt.Errorf("无效请求: 不支持的维护任务 '%v'", item)
+		}
+		requested[task] = true
+	}
+	tasks := make([]string, 0, len(requested))
+	for _, task := range maintenanceTaskOrder {
+		if requested[task] {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// runMaintenanceTasks 在一个库上依次执行维护任务。VACUUM 需要独占库文件，
+// 期间的其他读写按 busy_timeout 等待
+func runMaintenanceTasks(ctx context.Context, db *sql.DB, tasks []string) error {
+	for _, task := range tasks {
+		var stmt string
+		switch task {
+		case domain.MaintenanceTaskAnalyze:
+			stmt = `ANALYZE`
+		case domain.MaintenanceTaskVacuum:
+			stmt = `VACUUM`
+		case domain.MaintenanceTaskCheckpoint:
+			stmt = `PRAGMA wal_checkpoint(TRUNCATE)`
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("执行 %s 失败: %w", task, err)
+		}
+	}
+	return nil
+}
+
+// inspectLib 读取库文件的页数、空闲页数以及库文件与 WAL 文件的大小
+func inspectLib(ctx context.Context, db *sql.DB, libName string) (domain.MaintenanceLibStats, error) {
+	stats := domain.MaintenanceLibStats{Lib: libName}
+	for _, p := range []struct {
+		pragma string
+		dest   *int64
+	}{
+		{"page_size", &stats.PageSize},
+		{"page_count", &stats.PageCount},
+		{"freelist_count", &stats.FreelistCount},
+	} {
+		if err := db.QueryRowContext(ctx, "PRAGMA "+p.pragma).Scan(p.dest); err != nil {
+			return stats, fmt.Errorf("读取 %s 失败: %w", p.pragma, err)
+		}
+	}
+	if stats.PageCount > 0 {
+		stats.Fragmentation = float64(stats.FreelistCount) / float64(stats.PageCount)
+	}
+
+	// 库文件的路径取自连接本身，内存库的路径为空
+	var seq int
+	var name, path string
+	if err := db.QueryRowContext(ctx, `PRAGMA database_list`).Scan(&seq, &name, &path); err != nil {
+		return stats, fmt.Errorf("读取库文件路径失败: %w", err)
+	}
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			stats.FileBytes = info.Size()
+		}
+		if info, err := os.Stat(path + "-wal"); err == nil {
+			stats.WALBytes = info.Size()
+		}
+	}
+	return stats, nil
+}
+
+func libStatsMap(s domain.MaintenanceLibStats) map[string]interface{} {
+	return map[string]interface{}{
+		"lib":            s.Lib,
+		"page_size":      s.PageSize,
+		"page_count":     s.PageCount,
+		"freelist_count": s.FreelistCount,
+		"fragmentation":  s.Fragmentation,
+		"file_bytes":     s.FileBytes,
+		"wal_bytes":      s.WALBytes,
+	}
+}
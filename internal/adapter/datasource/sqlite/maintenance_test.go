@@ -0,0 +1,53 @@
+// file: internal/adapter/datasource/sqlite/maintenance_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutate_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	manager, db := newChangeCaptureManager(t, false)
+
+	// 写入后删除大量记录，留下空闲页
+	_, err := db.Exec(`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 2000)
+		INSERT INTO books (id, title, author) SELECT i, printf('%0500d', i), 'x' FROM n`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM books WHERE id > 10`)
+	require.NoError(t, err)
+
+	maintain := func(payload map[string]interface{}) map[string]interface{} {
+		res, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpMaintenance, Payload: payload})
+		require.NoError(t, err)
+		return res.Data
+	}
+
+	inspected := maintain(map[string]interface{}{"step": "inspect"})
+	libs := inspected["libs"].([]interface{})
+	require.Len(t, libs, 1)
+	before := libs[0].(map[string]interface{})
+	assert.Equal(t, "cdc.db", before["lib"])
+	assert.Greater(t, before["freelist_count"].(int64), int64(0))
+	assert.Greater(t, before["fragmentation"].(float64), 0.5)
+
+	ran := maintain(map[string]interface{}{"step": "run", "tasks": []interface{}{domain.MaintenanceTaskVacuum, domain.MaintenanceTaskAnalyze}})
+	assert.Equal(t, []interface{}{domain.MaintenanceTaskAnalyze, domain.MaintenanceTaskVacuum}, ran["tasks"])
+	result := ran["libs"].([]interface{})[0].(map[string]interface{})
+	after := result["after"].(map[string]interface{})
+	assert.Equal(t, int64(0), after["freelist_count"])
+	assert.Less(t, after["page_count"].(int64), before["page_count"].(int64))
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM books`).Scan(&count))
+	assert.Equal(t, 10, count)
+
+	_, err = manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpMaintenance, Payload: map[string]interface{}{"step": "run", "tasks": []interface{}{"reindex"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reindex")
+}
@@ -4,6 +4,7 @@ package sqlite
 
 import (
 	"ArchiveAegis/internal/core/port"
+	"context"
 	"database/sql"
 	"log"
 	"sort"
@@ -40,9 +41,18 @@ type Manager struct {
 	// eventTimers 用于文件系统事件的防抖处理
 	eventTimers   map[string]*time.Timer
 	eventTimersMu sync.Mutex
+	// ignoredEvents 记录由管理器自身替换的库文件，在截止时间前忽略其文件事件，见 ignoreFsEvents
+	ignoredEvents map[string]time.Time
+
+	// pinyinMu 串行化拼音影子列的创建，避免并发查询重复 ALTER TABLE
+	pinyinMu sync.Mutex
 
 	// configService 用于在查询和写入时获取权限配置
 	configService port.QueryAdminConfigService
+
+	// storageStops 停止 StartStorage 启动的变更检测与对象存储同步
+	storageStops []context.CancelFunc
+	storageMu    sync.Mutex
 }
 
 // NewManager 创建一个新的 Manager 实例。
@@ -55,6 +65,7 @@ func NewManager(cfgService port.QueryAdminConfigService) *Manager {
 		dbSchemaCache: make(map[*sql.DB]*dbPhysicalSchemaInfo),
 		schema:        make(map[string]map[string][]string),
 		eventTimers:   make(map[string]*time.Timer),
+		ignoredEvents: make(map[string]time.Time),
 		configService: cfgService,
 	}
 }
@@ -62,6 +73,7 @@ func NewManager(cfgService port.QueryAdminConfigService) *Manager {
 // Close 安全地关闭由 Manager 管理的所有数据库连接。
 // 这是为了确保在程序退出或测试清理时，文件句柄能被正确释放。
 func (m *Manager) Close() error {
+	m.stopStorage()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
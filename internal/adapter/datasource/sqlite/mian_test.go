@@ -53,6 +53,21 @@ func (m *mockAdminConfigService) UpdateBizSearchableTables(ctx context.Context,
 func (m *mockAdminConfigService) UpdateTableFieldSettings(ctx context.Context, bizName, tableName string, fields []domain.FieldSetting) error {
 	return nil
 }
+func (m *mockAdminConfigService) UpdateFieldSettingsBulk(ctx context.Context, bizName string, tables map[string][]domain.FieldSetting) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableFTSConfig(ctx context.Context, bizName, tableName string, cfg *domain.FTSConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableRankingConfig(ctx context.Context, bizName, tableName string, cfg *domain.RankingConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableHierarchyConfig(ctx context.Context, bizName, tableName string, cfg *domain.HierarchyConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableSQLViewConfig(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) error {
+	return nil
+}
 func (m *mockAdminConfigService) UpdateTableWritePermissions(ctx context.Context, bizName, tableName string, perms domain.TableConfig) error {
 	return nil
 }
@@ -4,13 +4,47 @@ package sqlite
 import (
 	"ArchiveAegis/internal/core/port"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 )
 
+var _ port.AdminMutator = (*Manager)(nil)
+
+// AdminMutate 实现 port.AdminMutator 接口，处理绕过表级写权限的管理操作
+func (m *Manager) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, req.BizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", req.BizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+
+	switch req.Operation {
+	case port.MutateOpReplicate:
+		return m.applyReplicatedChanges(ctx, req.BizName, req.Payload)
+	case port.MutateOpFTSRebuild:
+		return m.rebuildFullText(ctx, req.BizName, bizAdminConfig, req.Payload)
+	case port.MutateOpMaintenance:
+		return m.maintain(ctx, req.BizName, req.Payload)
+	case port.MutateOpCompact:
+		return m.compact(ctx, req.BizName, req.Payload)
+	case port.MutateOpReplace:
+		return m.replace(ctx, req.BizName, req.Payload)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", port.ErrAdminMutateUnsupported, req.Operation)
+	}
+}
+
 // Mutate 实现 port.DataSource 接口，处理通用的 CUD (Create, Update, Delete) 操作。
+// 管理操作不在此处理，见 AdminMutate
 func (m *Manager) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if !port.IsPublicMutateOp(req.Operation) {
+		return nil, fmt.Errorf("不支持的写操作类型: '%s'", req.Operation)
+	}
+
 	// --- 获取业务和权限配置 ---
 	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, req.BizName)
 	if err != nil {
@@ -31,17 +65,23 @@ func (m *Manager) Mutate(ctx context.Context, req port.MutateRequest) (*port.Mut
 	if !exists {
 		return nil, port.ErrTableNotFoundInBiz
 	}
+	if tableConfig.SQLView != nil {
+		return nil, fmt.Errorf("%w: SQL 视图表 '%s' 只读", port.ErrPermissionDenied, tableName)
+	}
 
 	var opAllowed bool
 	var sqlStmt string
 	var args []interface{}
+	// data 与 filters 在启用变更捕获时用于定位受影响的行
+	var data map[string]interface{}
+	var filters []queryParam
 
 	// --- 根据 operation 字符串决定执行何种操作 ---
 	switch req.Operation {
 	case "create":
 		opAllowed = tableConfig.AllowCreate
 		if opAllowed {
-			data, ok := payload["data"].(map[string]interface{})
+			data, ok = payload["data"].(map[string]interface{})
 			if !ok {
 				return nil, errors.New("create 操作的 payload 中必须包含一个有效的 'data' 对象")
 			}
@@ -51,11 +91,12 @@ func (m *Manager) Mutate(ctx context.Context, req port.MutateRequest) (*port.Mut
 	case "update":
 		opAllowed = tableConfig.AllowUpdate
 		if opAllowed {
-			data, ok := payload["data"].(map[string]interface{})
+			data, ok = payload["data"].(map[string]interface{})
 			if !ok {
 				return nil, errors.New("update 操作的 payload 中必须包含一个有效的 'data' 对象")
 			}
-			filters, parseErr := parseFiltersFromPayload(payload)
+			var parseErr error
+			filters, parseErr = parseFiltersFromPayload(payload)
 			if parseErr != nil {
 				return nil, parseErr
 			}
@@ -65,7 +106,8 @@ func (m *Manager) Mutate(ctx context.Context, req port.MutateRequest) (*port.Mut
 	case "delete":
 		opAllowed = tableConfig.AllowDelete
 		if opAllowed {
-			filters, parseErr := parseFiltersFromPayload(payload)
+			var parseErr error
+			filters, parseErr = parseFiltersFromPayload(payload)
 			if parseErr != nil {
 				return nil, parseErr
 			}
@@ -91,15 +133,25 @@ func (m *Manager) Mutate(ctx context.Context, req port.MutateRequest) (*port.Mut
 		return nil, port.ErrBizNotFound
 	}
 
+	actor, _ := payload[port.MutateActorKey].(string)
+
 	var totalRowsAffected int64
 	for libName, db := range dbInstances {
-		res, execErr := db.ExecContext(ctx, sqlStmt, args...)
+		var rowsAffected int64
+		var execErr error
+		if bizAdminConfig.ChangeCaptureEnabled {
+			rowsAffected, execErr = execWithCapture(ctx, db, req.Operation, tableName, sqlStmt, args, data, filters, actor)
+		} else {
+			var res sql.Result
+			if res, execErr = db.ExecContext(ctx, sqlStmt, args...); execErr == nil {
+				rowsAffected, _ = res.RowsAffected()
+			}
+		}
 		if execErr != nil {
 			errMsg := fmt.Errorf("操作在库 '%s' 上失败并已中止。此前的写操作可能已成功，导致业务组数据不一致。错误: %w", libName, execErr)
 			slog.Error("[DBManager Mutate]", "error", errMsg)
 			return nil, errMsg
 		}
-		rowsAffected, _ := res.RowsAffected()
 		totalRowsAffected += rowsAffected
 	}
 
@@ -141,6 +193,16 @@ func parseFiltersFromPayload(payload map[string]interface{}) ([]queryParam, erro
 
 		param.Logic, _ = filterMap["logic"].(string)
 		param.Fuzzy, _ = filterMap["fuzzy"].(bool)
+		if param.Op, _ = filterMap["op"].(string); param.Op != "" {
+			if _, known := nullOperators[param.Op]; !known {
+				return nil, fmt.Errorf("无效请求: 不支持的过滤运算符 '%s'", param.Op)
+			}
+		}
+		if negate, ok := filterMap[port.FilterNegateKey]; ok {
+			if param.Negate, ok = negate.(bool); !ok {
+				return nil, fmt.Errorf("无效请求: filter 对象的 '%s' 必须是布尔值", port.FilterNegateKey)
+			}
+		}
 		filters = append(filters, param)
 	}
 
@@ -0,0 +1,52 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/partial.go
+package sqlite
+
+import (
+	"sort"
+	"sync"
+)
+
+// libFailures 收集一次多库查询中失败的库 (见 port.ResultWarningsKey)，可被多个 goroutine 并发写入。
+// 为 nil 表示严格模式 (port.QueryStrictKey)：任一库失败即整个查询失败，调用方应直接返回错误
+type libFailures struct {
+	mu     sync.Mutex
+	failed map[string]string
+}
+
+func newLibFailures() *libFailures {
+	return &libFailures{failed: make(map[string]string)}
+}
+
+// add 记录库的失败原因，同一个库只保留首个错误 (COUNT 与 SELECT 可能先后失败)
+func (f *libFailures) add(lib string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.failed[lib]; !exists {
+		f.failed[lib] = err.Error()
+	}
+}
+
+func (f *libFailures) len() int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.failed)
+}
+
+// toList 将失败的库转换为可经 structpb 传输的通用结构，按库名排序
+func (f *libFailures) toList() []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	libs := make([]string, 0, len(f.failed))
+	for lib := range f.failed {
+		libs = append(libs, lib)
+	}
+	sort.Strings(libs)
+	warnings := make([]interface{}, 0, len(libs))
+	for _, lib := range libs {
+		warnings = append(warnings, map[string]interface{}{"lib": lib, "error": f.failed[lib]})
+	}
+	return warnings
+}
@@ -0,0 +1,168 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/pinyin.go
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// pinyinTableData 是内置的汉字拼音表，每行为 "音节 汉字..."，覆盖 CJK 基本区。
+// 每个汉字只取一个最常用读音，多音字按其它读音检索时无法命中。
+//
+//go:embed pinyin_table.txt
+var pinyinTableData string
+
+const (
+	// pinyinFunc 与 pinyinInitialsFunc 是注册到 SQLite 的确定性函数，供拼音影子列的生成表达式使用
+	pinyinFunc         = "archiveaegis_pinyin"
+	pinyinInitialsFunc = "archiveaegis_pinyin_initials"
+
+	// 拼音影子列的列名前缀，列名为前缀加原字段名
+	pinyinColumnPrefix         = innerPrefix + "py_"
+	pinyinInitialsColumnPrefix = innerPrefix + "pyi_"
+)
+
+var pinyinTable = make(map[rune]string)
+
+func init() {
+	for _, line := range strings.Split(pinyinTableData, "\n") {
+		syllable, chars, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		for _, r := range chars {
+			pinyinTable[r] = syllable
+		}
+	}
+
+	// 生成列在每次读写时都会调用这两个函数。它们只对本进程打开的连接可用：
+	// 外部工具写入带拼音影子列的表会因找不到函数而失败，需要先在管理后台关闭该字段的拼音检索
+	sqlitedriver.MustRegisterDeterministicScalarFunction(pinyinFunc, 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return pinyinOf(args[0], false), nil
+	})
+	sqlitedriver.MustRegisterDeterministicScalarFunction(pinyinInitialsFunc, 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return pinyinOf(args[0], true), nil
+	})
+}
+
+func pinyinOf(v driver.Value, initials bool) driver.Value {
+	switch s := v.(type) {
+	case string:
+		return toPinyin(s, initials)
+	case []byte:
+		return toPinyin(string(s), initials)
+	case nil:
+		return nil
+	default:
+		return toPinyin(fmt.Sprintf("%v", s), initials)
+	}
+}
+
+// toPinyin 把文本转换为不带声调与分隔符的小写拼音 ("鲁迅" -> "luxun")；
+// initials 为 true 时每个汉字只取声母首字母 ("鲁迅" -> "lx")。
+// 文本中的字母与数字按小写保留，其它字符被丢弃。
+func toPinyin(s string, initials bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		if syllable, ok := pinyinTable[r]; ok {
+			if initials {
+				b.WriteByte(syllable[0])
+			} else {
+				b.WriteString(syllable)
+			}
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// pinyinToneFolder 去除拼音输入中的声调符号，并把 ü 与常用的替代写法 v 统一为 u (与拼音表一致)
+var pinyinToneFolder = strings.NewReplacer(
+	"ā", "a", "á", "a", "ǎ", "a", "à", "a",
+	"ē", "e", "é", "e", "ě", "e", "è", "e",
+	"ī", "i", "í", "i", "ǐ", "i", "ì", "i",
+	"ō", "o", "ó", "o", "ǒ", "o", "ò", "o",
+	"ū", "u", "ú", "u", "ǔ", "u", "ù", "u",
+	"ǖ", "u", "ǘ", "u", "ǚ", "u", "ǜ", "u", "ü", "u", "v", "u",
+)
+
+// normalizePinyinInput 把用户输入的拼音 ("Lǔ Xùn"、"lu'xun"、"LX") 整理为影子列中的形式。
+// 输入中的汉字同样转换为拼音，因此也可以用同音字检索。
+func normalizePinyinInput(s string) string {
+	return toPinyin(pinyinToneFolder.Replace(strings.ToLower(s)), false)
+}
+
+// ensurePinyinColumns 确保表中存在字段的拼音影子列及其索引。
+// 影子列为 VIRTUAL 生成列，不占用表空间；PRAGMA table_info 不列出生成列，因此不会出现在 Schema 探测结果中。
+// 首次为大表建立索引可能耗时较长，之后的查询只检查内存中的标记。
+func (m *Manager) ensurePinyinColumns(ctx context.Context, db *sql.DB, tableName, field string) error {
+	key := tableName + "\x00" + field
+	m.mu.RLock()
+	info := m.dbSchemaCache[db]
+	ready := info != nil && info.pinyinColumns[key]
+	m.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	m.pinyinMu.Lock()
+	defer m.pinyinMu.Unlock()
+	existing, err := listAllColumns(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
+	columns := []struct{ name, fn string }{
+		{pinyinColumnPrefix + field, pinyinFunc},
+		{pinyinInitialsColumnPrefix + field, pinyinInitialsFunc},
+	}
+	for _, col := range columns {
+		if !existing[col.name] {
+			stmt := fmt.Sprintf(`ALTER TABLE %q ADD COLUMN %q TEXT GENERATED ALWAYS AS (%s(%q)) VIRTUAL`, tableName, col.name, col.fn, field)
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("为表 '%s' 字段 '%s' 创建拼音影子列失败: %w", tableName, field, err)
+			}
+		}
+		index := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %q ON %q (%q)`, innerPrefix+"idx_"+tableName+"_"+strings.TrimPrefix(col.name, innerPrefix), tableName, col.name)
+		if _, err := db.ExecContext(ctx, index); err != nil {
+			return fmt.Errorf("为表 '%s' 字段 '%s' 创建拼音索引失败: %w", tableName, field, err)
+		}
+	}
+
+	m.mu.Lock()
+	if info := m.dbSchemaCache[db]; info != nil {
+		if info.pinyinColumns == nil {
+			info.pinyinColumns = make(map[string]bool)
+		}
+		info.pinyinColumns[key] = true
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// listAllColumns 返回表的全部列名，包括 PRAGMA table_info 不列出的生成列
+func listAllColumns(ctx context.Context, db *sql.DB, tableName string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_xinfo(?)`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 的列信息失败: %w", tableName, err)
+	}
+	defer rows.Close()
+	cols := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols[name] = true
+	}
+	return cols, rows.Err()
+}
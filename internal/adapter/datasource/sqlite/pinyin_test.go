@@ -0,0 +1,95 @@
+// file: internal/adapter/datasource/sqlite/pinyin_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToPinyin(t *testing.T) {
+	assert.Equal(t, "luxun", toPinyin("鲁迅", false))
+	assert.Equal(t, "lx", toPinyin("鲁迅", true))
+	assert.Equal(t, "zhangsan2hao", toPinyin("张三 2 号", false))
+	assert.Equal(t, "a1zs", toPinyin("A1·张三", true), "字母与数字应原样保留")
+
+	assert.Equal(t, "luxun", normalizePinyinInput("Lǔ Xùn"))
+	assert.Equal(t, "luxun", normalizePinyinInput("lu'xun"))
+	assert.Equal(t, "lu", normalizePinyinInput("lv"), "v 是 ü 的常用替代写法")
+	assert.Equal(t, "luxun", normalizePinyinInput("鲁迅"), "汉字输入按读音匹配")
+}
+
+func TestQuery_Pinyin(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, title TEXT);`,
+		`INSERT INTO letters (id, sender, title) VALUES (1, '鲁迅', '呐喊'), (2, '胡适', '尝试集'), (3, '鲁彦', '柚子');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true, Pinyin: true},
+					"title":  {FieldName: "title", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "sender", "title"}}}
+
+	senders := func(filter map[string]interface{}) []string {
+		t.Helper()
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+			"table":   "letters",
+			"filters": []interface{}{filter},
+		}})
+		require.NoError(t, err)
+		var names []string
+		for _, item := range result.Data["items"].([]interface{}) {
+			row := item.(map[string]any)
+			assert.Len(t, row, 3, "结果中不应出现拼音影子列: %v", row)
+			names = append(names, row["sender"].(string))
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"鲁迅"}, senders(map[string]interface{}{"field": "sender", "value": "Lu Xun", "pinyin": true}))
+	assert.Equal(t, []string{"鲁迅"}, senders(map[string]interface{}{"field": "sender", "value": "LX", "pinyin": true}))
+	assert.ElementsMatch(t, []string{"鲁迅", "鲁彦"}, senders(map[string]interface{}{"field": "sender", "value": "lu", "pinyin": true, "fuzzy": true}))
+
+	_, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table":   "letters",
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": "nh", "pinyin": true}},
+	}})
+	assert.ErrorContains(t, err, "未启用拼音检索")
+
+	// 影子列随源字段自动更新，且不出现在物理 Schema 中
+	_, err = dbA.Exec(`INSERT INTO letters (id, sender, title) VALUES (4, '胡风', '论现实主义的路')`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"胡风"}, senders(map[string]interface{}{"field": "sender", "value": "hf", "pinyin": true}))
+	cols, err := listColumns(dbA, "letters")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "sender", "title"}, cols)
+
+	schema, err := manager.GetSchema(ctx, port.SchemaRequest{BizName: "archive", TableName: "letters"})
+	require.NoError(t, err)
+	for _, field := range schema.Tables["letters"] {
+		assert.Equal(t, field.Name == "sender", field.PinyinSearchable, "字段 %s", field.Name)
+	}
+}
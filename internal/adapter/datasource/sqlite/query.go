@@ -2,13 +2,19 @@
 package sqlite
 
 import (
+	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog" // 使用 slog
 	"runtime"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -18,12 +24,76 @@ type queryParam struct {
 	Value string
 	Logic string
 	Fuzzy bool
+	// Op 为空值判断运算符 (见 nullOperators) 或 inOperator；非空时忽略 Value 与 Fuzzy
+	Op string
+	// Values 是网关展开的候选值 (见 port.FilterValuesKey)，与其中任一值匹配即命中
+	Values []string
+	// Pinyin 为 true 时 Value 是拼音输入，匹配字段的全拼或首字母影子列 (见 pinyin.go)；
+	// Fuzzy 仍控制是完整匹配还是包含匹配，Values 被忽略
+	Pinyin bool
+	// FullText 为 true 时 Value 是全文检索词，校验通过后被替换为 FTS5 MATCH 表达式 (见 fts.go)，
+	// FTSIndex 为该表的全文索引名
+	FullText bool
+	FTSIndex string
+	// Negate 为 true 时对整个条件取反 (见 port.FilterNegateKey)
+	Negate bool
+	// CaseInsensitive 与 AccentInsensitive 控制文本匹配的大小写与重音敏感性 (见 textCondition)。
+	// 过滤条件未指定时取字段的默认设置；为 nil 表示沿用默认行为
+	CaseInsensitive   *bool
+	AccentInsensitive *bool
+}
+
+// inOperator 表示字段取值属于 values 列表中的任一值，列表大小由网关按配置限制
+const inOperator = "in"
+
+// inlineInValues 是 IN 列表以逐个参数绑定的最大长度，更长的列表以单个 JSON 数组参数经 json_each 展开，
+// 避免超出 SQLite 的参数数量上限
+const inlineInValues = 100
+
+// nullOperators 是过滤条件中 "op" 可取的空值判断运算符及其 SQL 片段 (%q 为字段名)
+var nullOperators = map[string]string{
+	"is_null":      "%q IS NULL",
+	"is_not_null":  "%q IS NOT NULL",
+	"is_empty":     "%q = ''",
+	"is_not_empty": "%q <> ''",
+}
+
+// optionalFilterBool 读取过滤条件中可选的布尔键，未指定时返回 nil
+func optionalFilterBool(filterMap map[string]interface{}, key string) (*bool, error) {
+	raw, ok := filterMap[key]
+	if !ok {
+		return nil, nil
+	}
+	b, ok := raw.(bool)
+	if !ok {
+		return nil, fmt.Errorf("无效请求: filter 对象的 '%s' 必须是布尔值", key)
+	}
+	return &b, nil
 }
 
 // Query 是适配新协议的公开方法。
 // 它的职责是：解析和校验通用的查询请求，然后调用内部核心逻辑，最后将结果包装成通用格式返回。
 func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
 	queryMap := req.Query
+	switch mode, _ := queryMap[port.QueryModeKey].(string); mode {
+	case port.QueryModeChanges:
+		return m.queryChanges(ctx, req.BizName, queryMap)
+	case port.QueryModeRecord:
+		return m.queryRecord(ctx, req.BizName, queryMap)
+	case port.QueryModeCompleteness:
+		return m.queryCompleteness(ctx, req.BizName, queryMap)
+	case port.QueryModeVocabulary:
+		return m.queryVocabulary(ctx, req.BizName, queryMap)
+	case port.QueryModeStats:
+		return m.queryStats(ctx, req.BizName, queryMap)
+	case port.QueryModeChildren:
+		return m.queryChildren(ctx, req.BizName, queryMap)
+	case port.QueryModeAncestors:
+		return m.queryAncestors(ctx, req.BizName, queryMap)
+	case port.QueryModeSubtree:
+		return m.querySubtree(ctx, req.BizName, queryMap)
+	}
+
 	tableName, ok := queryMap["table"].(string)
 	if !ok || tableName == "" {
 		return nil, fmt.Errorf("无效请求: query 体必须包含一个有效的 'table' 字符串字段")
@@ -33,14 +103,33 @@ func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.Query
 		tableName      string
 		queryParams    []queryParam
 		fieldsToReturn []string
+		distinct       bool
+		sample         int
 		page           int
 		size           int
+		explain        *explainLog
+		budget         *rowBudget
+		failures       *libFailures
+		rank           []rankTerm
+		sortByScore    bool
+		collapseBy     string
+		timeline       *timelineSpec
+		sqlParams      map[string]interface{}
 	}
 	args := parsedArgs{
 		tableName: tableName,
 		page:      1,
 		size:      50,
 	}
+	if explain, _ := queryMap[port.QueryExplainKey].(bool); explain {
+		args.explain = newExplainLog()
+	}
+	if maxBytes, ok := queryMap[port.QueryMaxBytesKey].(float64); ok {
+		args.budget = newRowBudget(int64(maxBytes))
+	}
+	if strict, _ := queryMap[port.QueryStrictKey].(bool); !strict {
+		args.failures = newLibFailures()
+	}
 
 	if pageF, ok := queryMap["page"].(float64); ok {
 		args.page = int(pageF)
@@ -48,6 +137,16 @@ func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.Query
 	if sizeF, ok := queryMap["size"].(float64); ok {
 		args.size = int(sizeF)
 	}
+	args.distinct, _ = queryMap[port.QueryDistinctKey].(bool)
+	if sampleF, ok := queryMap[port.QuerySampleKey].(float64); ok {
+		if sampleF < 1 || sampleF > maxSampleSize || sampleF != float64(int(sampleF)) {
+			return nil, fmt.Errorf("无效请求: '%s' 必须是 1 到 %d 之间的整数", port.QuerySampleKey, maxSampleSize)
+		}
+		if args.distinct {
+			return nil, fmt.Errorf("无效请求: '%s' 不能与 '%s' 同时使用", port.QuerySampleKey, port.QueryDistinctKey)
+		}
+		args.sample = int(sampleF)
+	}
 
 	if filters, ok := queryMap["filters"].([]interface{}); ok {
 		for i, f := range filters {
@@ -63,9 +162,88 @@ func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.Query
 			param.Value = fmt.Sprintf("%v", filterMap["value"])
 			param.Logic, _ = filterMap["logic"].(string)
 			param.Fuzzy, _ = filterMap["fuzzy"].(bool)
+			param.Pinyin, _ = filterMap["pinyin"].(bool)
+			param.FullText, _ = filterMap[port.FilterFullTextKey].(bool)
+			if negate, ok := filterMap[port.FilterNegateKey]; ok {
+				if param.Negate, ok = negate.(bool); !ok {
+					return nil, fmt.Errorf("无效请求: filter 对象的 '%s' 必须是布尔值", port.FilterNegateKey)
+				}
+			}
+			var err error
+			if param.CaseInsensitive, err = optionalFilterBool(filterMap, port.FilterCaseInsensitiveKey); err != nil {
+				return nil, err
+			}
+			if param.AccentInsensitive, err = optionalFilterBool(filterMap, port.FilterAccentInsensitiveKey); err != nil {
+				return nil, err
+			}
+			if values, ok := filterMap[port.FilterValuesKey].([]interface{}); ok {
+				for _, v := range values {
+					param.Values = append(param.Values, fmt.Sprintf("%v", v))
+				}
+			}
+			switch param.Op, _ = filterMap["op"].(string); {
+			case param.Op == inOperator:
+				if len(param.Values) == 0 {
+					return nil, fmt.Errorf("无效请求: 运算符 'in' 需要非空的 '%s' 列表", port.FilterValuesKey)
+				}
+			case param.Op != "":
+				if _, known := nullOperators[param.Op]; !known {
+					return nil, fmt.Errorf("无效请求: 不支持的过滤运算符 '%s'", param.Op)
+				}
+			}
 			args.queryParams = append(args.queryParams, param)
 		}
 	}
+	if rank, ok := queryMap[port.QueryRankKey].([]interface{}); ok {
+		for i, r := range rank {
+			rankMap, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("无效请求: '%s' 数组的第 %d 个元素不是一个有效的JSON对象", port.QueryRankKey, i)
+			}
+			term := rankTerm{Match: queryParam{Fuzzy: true}}
+			term.Match.Field, _ = rankMap["field"].(string)
+			term.Match.Value, _ = rankMap["value"].(string)
+			term.Weight, _ = rankMap["weight"].(float64)
+			if term.Match.Field == "" || term.Match.Value == "" {
+				return nil, fmt.Errorf("无效请求: '%s' 的每一项都需要非空的 'field' 与 'value'", port.QueryRankKey)
+			}
+			args.rank = append(args.rank, term)
+		}
+	}
+	if raw, ok := queryMap[port.QuerySortKey]; ok {
+		sortKey, isString := raw.(string)
+		if !isString || (sortKey != "" && sortKey != port.ResultScoreField) {
+			return nil, fmt.Errorf("无效请求: 不支持的排序键 '%v'，目前只支持 '%s'", raw, port.ResultScoreField)
+		}
+		args.sortByScore = sortKey == port.ResultScoreField
+	}
+	if raw, ok := queryMap[port.QueryCollapseKey]; ok {
+		collapseBy, isString := raw.(string)
+		if !isString || collapseBy == "" {
+			return nil, fmt.Errorf("无效请求: '%s' 必须是非空的字段名", port.QueryCollapseKey)
+		}
+		if args.distinct || args.sample > 0 {
+			return nil, fmt.Errorf("无效请求: '%s' 不能与去重或抽样同时使用", port.QueryCollapseKey)
+		}
+		args.collapseBy = collapseBy
+	}
+	if raw, ok := queryMap[port.QueryTimelineKey]; ok {
+		spec, err := parseTimelineSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+		if args.distinct || args.sample > 0 || args.collapseBy != "" {
+			return nil, fmt.Errorf("无效请求: '%s' 不能与去重、抽样或折叠同时使用", port.QueryTimelineKey)
+		}
+		args.timeline = spec
+	}
+	if raw, ok := queryMap[port.QuerySQLParamsKey]; ok {
+		params, isMap := raw.(map[string]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("无效请求: '%s' 必须是以参数名为键的JSON对象", port.QuerySQLParamsKey)
+		}
+		args.sqlParams = params
+	}
 	if fields, ok := queryMap["fields_to_return"].([]interface{}); ok {
 		for _, field := range fields {
 			if fStr, ok := field.(string); ok {
@@ -78,14 +256,37 @@ func (m *Manager) Query(ctx context.Context, req port.QueryRequest) (*port.Query
 	if err != nil {
 		return nil, err
 	}
+	if args.timeline != nil {
+		data := timelineResult(args.timeline, results, total)
+		if args.failures.len() > 0 {
+			data[port.ResultWarningsKey] = args.failures.toList()
+		}
+		if args.explain != nil {
+			data["explain"] = args.explain.toMap()
+		}
+		return &port.QueryResult{Data: data, Source: m.Type()}, nil
+	}
+
+	// 结果需要经 structpb 传输，[]map[string]any 无法被直接编码
+	items := make([]interface{}, len(results))
+	for i, row := range results {
+		items[i] = row
+	}
 
-	return &port.QueryResult{
-		Data: map[string]interface{}{
-			"items": results,
-			"total": total,
-		},
-		Source: m.Type(),
-	}, nil
+	data := map[string]interface{}{
+		"items": items,
+		"total": total,
+	}
+	if args.budget.exhausted() {
+		data[port.ResultTruncatedKey] = true
+	}
+	if args.failures.len() > 0 {
+		data[port.ResultWarningsKey] = args.failures.toList()
+	}
+	if args.explain != nil {
+		data["explain"] = args.explain.toMap()
+	}
+	return &port.QueryResult{Data: data, Source: m.Type()}, nil
 }
 
 // queryInternal 是查询逻辑的内部核心实现。
@@ -93,8 +294,18 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 	tableName      string
 	queryParams    []queryParam
 	fieldsToReturn []string
+	distinct       bool
+	sample         int
 	page           int
 	size           int
+	explain        *explainLog
+	budget         *rowBudget
+	failures       *libFailures
+	rank           []rankTerm
+	sortByScore    bool
+	collapseBy     string
+	timeline       *timelineSpec
+	sqlParams      map[string]interface{}
 }) ([]map[string]any, int64, error) {
 	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
 	if err != nil {
@@ -122,6 +333,24 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 	if !tableAdminConfig.IsSearchable {
 		return nil, 0, port.ErrPermissionDenied
 	}
+	// SQL 视图表以公用表表达式的形式包在每条查询语句之前，只在含有视图引用的全部表的库中查询
+	var view *sqlView
+	var viewArgs []any
+	libTables := []string{targetTableName}
+	if tableAdminConfig.SQLView != nil {
+		if view, err = compileSQLView(targetTableName, tableAdminConfig.SQLView); err != nil {
+			return nil, 0, fmt.Errorf("表 '%s' 的 SQL 视图定义无效: %w", targetTableName, err)
+		}
+		if viewArgs, err = view.bind(args.sqlParams); err != nil {
+			return nil, 0, fmt.Errorf("无效请求: %w", err)
+		}
+		if args.timeline != nil {
+			return nil, 0, fmt.Errorf("SQL 视图表 '%s' 不支持时间轴查询", targetTableName)
+		}
+		libTables = view.tables
+	} else if len(args.sqlParams) > 0 {
+		return nil, 0, fmt.Errorf("无效请求: 表 '%s' 不是 SQL 视图表，不接受 '%s'", targetTableName, port.QuerySQLParamsKey)
+	}
 
 	validatedQueryParams := make([]queryParam, 0, len(args.queryParams))
 	for _, p := range args.queryParams {
@@ -129,9 +358,63 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 		if !fieldExists || !fieldSetting.IsSearchable {
 			return nil, 0, fmt.Errorf("字段 '%s' 无效或不可搜索", p.Field)
 		}
+		if view != nil && (p.Pinyin || p.FullText) {
+			return nil, 0, fmt.Errorf("SQL 视图表 '%s' 不支持拼音检索与全文检索", targetTableName)
+		}
+		if p.Pinyin {
+			if !fieldSetting.Pinyin {
+				return nil, 0, fmt.Errorf("字段 '%s' 未启用拼音检索", p.Field)
+			}
+			if p.Op != "" {
+				return nil, 0, fmt.Errorf("字段 '%s' 的拼音检索不能与运算符 '%s' 同时使用", p.Field, p.Op)
+			}
+			if p.Value = normalizePinyinInput(p.Value); p.Value == "" {
+				return nil, 0, fmt.Errorf("字段 '%s' 的拼音检索值为空", p.Field)
+			}
+		}
+		if p.FullText {
+			fts := tableAdminConfig.FTS
+			if fts == nil || !slices.Contains(fts.Fields, p.Field) {
+				return nil, 0, fmt.Errorf("字段 '%s' 未启用全文检索", p.Field)
+			}
+			if p.Op != "" || p.Pinyin {
+				return nil, 0, fmt.Errorf("字段 '%s' 的全文检索不能与运算符或拼音检索同时使用", p.Field)
+			}
+			p.FTSIndex = ftsIndexTable(targetTableName)
+		}
+		if p.Op != "" || p.Pinyin || p.FullText {
+			if p.CaseInsensitive != nil || p.AccentInsensitive != nil {
+				return nil, 0, fmt.Errorf("字段 '%s' 的大小写与重音选项只适用于普通文本匹配", p.Field)
+			}
+		} else {
+			if p.CaseInsensitive == nil && fieldSetting.CaseInsensitive {
+				p.CaseInsensitive = &fieldSetting.CaseInsensitive
+			}
+			if p.AccentInsensitive == nil && fieldSetting.AccentInsensitive {
+				p.AccentInsensitive = &fieldSetting.AccentInsensitive
+			}
+		}
 		validatedQueryParams = append(validatedQueryParams, p)
 	}
 
+	// 计分只在普通分页查询中进行：抽样结果本身是随机的，去重时得分列会影响 DISTINCT 的判断
+	var rank []rankTerm
+	if args.sample == 0 && !args.distinct {
+		for _, term := range args.rank {
+			fieldSetting, fieldExists := tableAdminConfig.Fields[term.Match.Field]
+			if !fieldExists || !fieldSetting.IsSearchable {
+				return nil, 0, fmt.Errorf("排序字段 '%s' 无效或不可搜索", term.Match.Field)
+			}
+			if fieldSetting.CaseInsensitive {
+				term.Match.CaseInsensitive = &fieldSetting.CaseInsensitive
+			}
+			if fieldSetting.AccentInsensitive {
+				term.Match.AccentInsensitive = &fieldSetting.AccentInsensitive
+			}
+			rank = append(rank, term)
+		}
+	}
+
 	var selectFieldsForSQL []string
 	if len(args.fieldsToReturn) > 0 {
 		for _, fieldName := range args.fieldsToReturn {
@@ -153,6 +436,18 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 		return nil, 0, fmt.Errorf("在表 '%s' 的配置中，没有找到任何可供返回的字段", targetTableName)
 	}
 	sort.Strings(selectFieldsForSQL)
+	// 去重与折叠时 total 分别为不同行数与分组数
+	var distinctFields []string
+	switch {
+	case args.distinct:
+		distinctFields = selectFieldsForSQL
+	case args.collapseBy != "":
+		fieldSetting, fieldExists := tableAdminConfig.Fields[args.collapseBy]
+		if !fieldExists || !fieldSetting.IsReturnable {
+			return nil, 0, fmt.Errorf("折叠字段 '%s' 无效或未被授权返回", args.collapseBy)
+		}
+		distinctFields = []string{args.collapseBy}
+	}
 
 	m.mu.RLock()
 	dbInstancesInBiz, bizGroupExists := m.group[bizName]
@@ -161,26 +456,145 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 		return []map[string]any{}, 0, nil
 	}
 
+	// 拼音影子列在字段首次被按拼音检索时创建，缺少目标表的库会在后续查询中被跳过
+	for _, p := range validatedQueryParams {
+		if !p.Pinyin {
+			continue
+		}
+		for libName, db := range dbInstancesInBiz {
+			m.mu.RLock()
+			info := m.dbSchemaCache[db]
+			m.mu.RUnlock()
+			if info == nil {
+				continue
+			}
+			if _, exists := info.allTablesAndColumns[targetTableName]; !exists {
+				continue
+			}
+			if err := m.ensurePinyinColumns(ctx, db, targetTableName, p.Field); err != nil {
+				return nil, 0, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+		}
+	}
+
+	// 字段默认不区分大小写时，精确匹配按需建立 NOCASE 索引；去重音比较无法使用索引，不必建立
+	for _, p := range validatedQueryParams {
+		if p.Fuzzy || p.CaseInsensitive == nil || !*p.CaseInsensitive || (p.AccentInsensitive != nil && *p.AccentInsensitive) {
+			continue
+		}
+		if setting := tableAdminConfig.Fields[p.Field]; !setting.CaseInsensitive {
+			continue
+		}
+		for _, db := range dbInstancesInBiz {
+			m.mu.RLock()
+			info := m.dbSchemaCache[db]
+			m.mu.RUnlock()
+			if info == nil {
+				continue
+			}
+			if _, exists := info.allTablesAndColumns[targetTableName]; !exists {
+				continue
+			}
+			m.ensureNoCaseIndex(ctx, db, targetTableName, p.Field)
+		}
+	}
+
+	// 全文检索条件的 MATCH 表达式取决于生效索引的分词器，因此在确认各库的索引后再组装
+	for i, p := range validatedQueryParams {
+		if !p.FullText {
+			continue
+		}
+		var active *ftsMeta
+		for libName, db := range dbInstancesInBiz {
+			m.mu.RLock()
+			info := m.dbSchemaCache[db]
+			m.mu.RUnlock()
+			if info == nil {
+				continue
+			}
+			if _, exists := info.allTablesAndColumns[targetTableName]; !exists {
+				continue
+			}
+			meta, err := readFTSMeta(ctx, db, targetTableName)
+			if err != nil {
+				return nil, 0, fmt.Errorf("库 '%s/%s': %w", bizName, libName, err)
+			}
+			if meta == nil || !meta.hasField(p.Field) {
+				return nil, 0, fmt.Errorf("库 '%s/%s' 中表 '%s' 字段 '%s' 的全文索引尚未建立", bizName, libName, targetTableName, p.Field)
+			}
+			active = meta
+		}
+		if active == nil {
+			continue
+		}
+		// trigram 分词本身即为子串匹配，不支持前缀查询
+		prefix := p.Fuzzy && active.Tokenizer != domain.FTSTokenizerTrigram
+		match, err := buildFTSMatch(p.Field, p.Value, p.Values, prefix, tableAdminConfig.FTS.Stopwords)
+		if err != nil {
+			return nil, 0, err
+		}
+		validatedQueryParams[i].Value = match
+	}
+
+	// 时间轴只需要按日期分桶的计数，不读取记录，也不计分
+	if args.timeline != nil {
+		return m.timelineBuckets(ctx, bizName, targetTableName, tableAdminConfig, dbInstancesInBiz, validatedQueryParams, args.timeline, args.explain, args.failures)
+	}
+
+	// 全文检索条件同样参与计分，权重取字段的 SearchWeight；取反的条件不计分
+	var scorer *rankScorer
+	if args.sample == 0 && !args.distinct {
+		for _, p := range validatedQueryParams {
+			if p.FullText && !p.Negate {
+				rank = append(rank, rankTerm{Match: p, Weight: searchWeight(tableAdminConfig.Fields[p.Field])})
+			}
+		}
+		if len(rank) > 0 {
+			scorer = m.newRankScorer(ctx, bizName, targetTableName, tableAdminConfig, rank)
+		}
+	}
+
 	var totalCount int64
+	// queriedLibs 是实际查询的库数 (有目标表的库)；这些库的数据查询全部失败时，非严格模式也返回错误
+	var queriedLibs int
+	var failedLibs atomic.Int64
+	// 抽样模式下记录各库的匹配数，用于合并时按库的大小加权
+	var libCountsMu sync.Mutex
+	libCounts := make(map[string]int64, len(dbInstancesInBiz))
 	resultsChannel := make(chan []map[string]any, len(dbInstancesInBiz))
 	g, queryCtx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
 		countGroup, countCtx := errgroup.WithContext(queryCtx)
-		for _, db := range dbInstancesInBiz {
-			currentDB := db
+		for libName, db := range dbInstancesInBiz {
+			// 与数据查询一致，跳过没有目标表的库，避免其失败被记为部分错误
+			if !m.libHasTables(db, libTables) {
+				continue
+			}
+			currentLibName, currentDB := libName, db
 			countGroup.Go(func() error {
-				countSQL, countArgs, errBuild := buildCountSQL(targetTableName, validatedQueryParams)
+				countSQL, countArgs, errBuild := buildCountSQL(targetTableName, distinctFields, validatedQueryParams)
 				if errBuild != nil {
 					return fmt.Errorf("构建COUNT查询失败: %w", errBuild)
 				}
+				countSQL, countArgs = view.wrap(countSQL, countArgs, viewArgs)
 				var localCount int64
+				started := time.Now()
 				errScan := currentDB.QueryRowContext(countCtx, countSQL, countArgs...).Scan(&localCount)
+				args.explain.add(explainStep{lib: currentLibName, kind: "count", sql: countSQL, args: countArgs, duration: time.Since(started), rows: localCount, err: errScan})
 				if errScan != nil {
-					slog.Warn("[DBManager Query] 计算总数时部分库查询失败 (不影响总结果)", "error", errScan)
+					errScan = fmt.Errorf("计算库 '%s/%s' 表 '%s' 的总数失败: %w", bizName, currentLibName, targetTableName, errScan)
+					if args.failures == nil {
+						return errScan
+					}
+					slog.Warn("[DBManager Query] 计算总数时部分库查询失败，total 不含此库", "error", errScan)
+					args.failures.add(currentLibName, errScan)
 					return nil
 				}
 				atomic.AddInt64(&totalCount, localCount)
+				libCountsMu.Lock()
+				libCounts[currentLibName] = localCount
+				libCountsMu.Unlock()
 				return nil
 			})
 		}
@@ -191,17 +605,22 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 		defer close(resultsChannel)
 		dataGroup, dataCtx := errgroup.WithContext(queryCtx)
 		sem := make(chan struct{}, runtime.NumCPU())
+		// 非严格模式下单个库的失败只记入 failures，不取消其他库的查询
+		libFailed := func(libName string, err error) error {
+			if args.failures == nil {
+				return err
+			}
+			slog.Warn("[DBManager Query] 部分库查询失败，结果中不含此库", "biz", bizName, "lib", libName, "error", err)
+			args.failures.add(libName, err)
+			failedLibs.Add(1)
+			return nil
+		}
 
 		for libName, dbConn := range dbInstancesInBiz {
-			m.mu.RLock()
-			physicalSchemaInfo, hasPhysicalSchema := m.dbSchemaCache[dbConn]
-			m.mu.RUnlock()
-			if !hasPhysicalSchema || physicalSchemaInfo == nil {
-				continue
-			}
-			if _, tablePhysicallyExists := physicalSchemaInfo.allTablesAndColumns[targetTableName]; !tablePhysicallyExists {
+			if !m.libHasTables(dbConn, libTables) {
 				continue
 			}
+			queriedLibs++
 
 			currentLibName, currentDBConn := libName, dbConn
 			dataGroup.Go(func() error {
@@ -212,21 +631,39 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 					return dataCtx.Err()
 				}
 
-				sqlQuery, queryArgs, errBuild := buildQuerySQL(targetTableName, selectFieldsForSQL, validatedQueryParams, args.page, args.size)
+				var (
+					sqlQuery  string
+					queryArgs []any
+					errBuild  error
+				)
+				switch {
+				case args.sample > 0:
+					sqlQuery, queryArgs, errBuild = buildSampleSQL(targetTableName, selectFieldsForSQL, validatedQueryParams, args.sample, view == nil && tableHasRowid(dataCtx, currentDBConn, targetTableName))
+				case args.collapseBy != "":
+					sqlQuery, queryArgs, errBuild = buildCollapsedQuerySQL(targetTableName, selectFieldsForSQL, validatedQueryParams, args.collapseBy, scorer, args.sortByScore, view == nil && tableHasRowid(dataCtx, currentDBConn, targetTableName), args.page, args.size)
+				case scorer != nil:
+					sqlQuery, queryArgs, errBuild = buildRankedQuerySQL(targetTableName, selectFieldsForSQL, validatedQueryParams, scorer, args.sortByScore, args.page, args.size)
+				default:
+					sqlQuery, queryArgs, errBuild = buildQuerySQL(targetTableName, selectFieldsForSQL, args.distinct, validatedQueryParams, args.page, args.size)
+				}
 				if errBuild != nil {
 					slog.Error("[DBManager Query] 构建SQL失败，已跳过此库", "error", errBuild)
 					return nil
 				}
+				sqlQuery, queryArgs = view.wrap(sqlQuery, queryArgs, viewArgs)
 
+				started := time.Now()
 				rows, errExec := currentDBConn.QueryContext(dataCtx, sqlQuery, queryArgs...)
 				if errExec != nil {
-					return fmt.Errorf("查询库 '%s/%s' 表 '%s' 失败: %w", bizName, currentLibName, targetTableName, errExec)
+					args.explain.add(explainStep{lib: currentLibName, kind: "select", sql: sqlQuery, args: queryArgs, duration: time.Since(started), err: errExec})
+					return libFailed(currentLibName, fmt.Errorf("查询库 '%s/%s' 表 '%s' 失败: %w", bizName, currentLibName, targetTableName, errExec))
 				}
 				defer rows.Close()
 
 				actualReturnedColumns, _ := rows.Columns()
 				var libResults []map[string]any
-				for rows.Next() {
+				// 超出字节数预算后立即停止读取，其余库也不再继续扫描，结果在内存中的大小不超过上限
+				for !args.budget.exhausted() && rows.Next() {
 					scanDest := make([]any, len(actualReturnedColumns))
 					scanDestPtrs := make([]any, len(actualReturnedColumns))
 					for i := range scanDest {
@@ -245,10 +682,15 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 							rowData[colName] = scanDest[i]
 						}
 					}
+					if !args.budget.take(rowData) {
+						break
+					}
 					libResults = append(libResults, rowData)
 				}
-				if errRows := rows.Err(); errRows != nil {
-					return fmt.Errorf("迭代库 '%s/%s' 表 '%s' 行数据时发生错误: %w", bizName, currentLibName, targetTableName, errRows)
+				errRows := rows.Err()
+				args.explain.add(explainStep{lib: currentLibName, kind: "select", sql: sqlQuery, args: queryArgs, duration: time.Since(started), rows: int64(len(libResults)), err: errRows})
+				if errRows != nil {
+					return libFailed(currentLibName, fmt.Errorf("迭代库 '%s/%s' 表 '%s' 行数据时发生错误: %w", bizName, currentLibName, targetTableName, errRows))
 				}
 				if len(libResults) > 0 {
 					resultsChannel <- libResults
@@ -263,11 +705,60 @@ func (m *Manager) queryInternal(ctx context.Context, bizName string, args struct
 	for resSlice := range resultsChannel {
 		allAggregatedResults = append(allAggregatedResults, resSlice...)
 	}
+	// 各库分别 SELECT DISTINCT 后，不同库之间仍可能有重复行，合并时再按所选列去重。
+	// total 是各库去重计数之和，库之间有重复时会大于实际值
+	if args.distinct {
+		allAggregatedResults = dedupRows(allAggregatedResults, selectFieldsForSQL)
+	}
 
 	if err := g.Wait(); err != nil {
 		slog.Error("[DBManager Query] 查询中发生错误", "biz", bizName, "table", targetTableName, "error", err)
 		return allAggregatedResults, totalCount, fmt.Errorf("查询业务 '%s' 的表 '%s' 时发生部分错误: %w", bizName, targetTableName, err)
 	}
+	if queriedLibs > 0 && failedLibs.Load() == int64(queriedLibs) {
+		return nil, 0, fmt.Errorf("查询业务 '%s' 的表 '%s' 失败: 全部 %d 个库均查询失败", bizName, targetTableName, queriedLibs)
+	}
+	if args.sample > 0 {
+		allAggregatedResults = mergeSamples(allAggregatedResults, libCounts, args.sample)
+	}
+	if scorer != nil {
+		finishRankScores(allAggregatedResults, args.sortByScore)
+	}
+	if args.collapseBy != "" {
+		allAggregatedResults = mergeCollapsedRows(allAggregatedResults)
+	}
 
 	return allAggregatedResults, totalCount, nil
 }
+
+// libHasTable 判断库的物理结构缓存中是否存在指定的表
+func (m *Manager) libHasTable(db *sql.DB, tableName string) bool {
+	m.mu.RLock()
+	info := m.dbSchemaCache[db]
+	m.mu.RUnlock()
+	if info == nil {
+		return false
+	}
+	_, exists := info.allTablesAndColumns[tableName]
+	return exists
+}
+
+// dedupRows 按 fields 的取值组合去重，保留每个组合首次出现的行 (及其 __lib)
+func dedupRows(rows []map[string]any, fields []string) []map[string]any {
+	seen := make(map[string]bool, len(rows))
+	out := rows[:0]
+	var key strings.Builder
+	for _, row := range rows {
+		key.Reset()
+		for _, f := range fields {
+			// 带上类型，避免整数 1 与字符串 "1" 被视为相同
+			fmt.Fprintf(&key, "%T:%v\x00", row[f], row[f])
+		}
+		if seen[key.String()] {
+			continue
+		}
+		seen[key.String()] = true
+		out = append(out, row)
+	}
+	return out
+}
@@ -0,0 +1,458 @@
+// file: internal/adapter/datasource/sqlite/query_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_Distinct(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, place TEXT);`,
+		`INSERT INTO letters VALUES (1, '鲁迅', '北京'), (2, '鲁迅', '北京'), (3, '胡适', '上海');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, place TEXT);`,
+		`INSERT INTO letters VALUES (1, '鲁迅', '北京'), (2, '周作人', '北京');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true},
+					"place":  {FieldName: "place", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	columns := map[string][]string{"letters": {"id", "sender", "place"}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+
+	query := func(q map[string]interface{}) (map[string]interface{}, []interface{}) {
+		t.Helper()
+		q["table"] = "letters"
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: q})
+		require.NoError(t, err)
+		return result.Data, result.Data["items"].([]interface{})
+	}
+
+	_, items := query(map[string]interface{}{"fields_to_return": []interface{}{"sender", "place"}})
+	assert.Len(t, items, 5, "未指定 distinct 时不去重")
+
+	data, items := query(map[string]interface{}{"fields_to_return": []interface{}{"sender", "place"}, "distinct": true})
+	var pairs []string
+	for _, item := range items {
+		row := item.(map[string]any)
+		pairs = append(pairs, row["sender"].(string)+"@"+row["place"].(string))
+	}
+	assert.ElementsMatch(t, []string{"鲁迅@北京", "胡适@上海", "周作人@北京"}, pairs, "库内与跨库的重复行都应去掉")
+	assert.Equal(t, int64(4), data["total"], "total 为各库去重计数之和")
+
+	_, items = query(map[string]interface{}{"distinct": true})
+	assert.Len(t, items, 4, "库内各行主键不同，但两个库中完全相同的行只保留一行")
+}
+
+func TestQuery_MaxResponseBytes(t *testing.T) {
+	ctx := context.Background()
+	db := createTestDB(t, t.TempDir(), "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, body TEXT);`,
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 20)
+		 INSERT INTO letters SELECT i, printf('%.1000c', 'x') FROM n;`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":   {FieldName: "id", IsReturnable: true},
+					"body": {FieldName: "body", IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": db}}
+	manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "body"}}}
+
+	result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "letters", "size": float64(20), port.QueryMaxBytesKey: float64(5000),
+	}})
+	require.NoError(t, err)
+	items := result.Data["items"].([]interface{})
+	assert.Len(t, items, 4, "每行约 1 KB，5000 字节的上限内只能放下 4 行")
+	assert.Equal(t, true, result.Data[port.ResultTruncatedKey])
+	assert.Equal(t, int64(20), result.Data["total"], "total 仍为满足条件的总行数")
+
+	result, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "letters", "size": float64(20),
+	}})
+	require.NoError(t, err)
+	assert.Len(t, result.Data["items"], 20)
+	assert.NotContains(t, result.Data, port.ResultTruncatedKey, "未设置上限时不截断")
+}
+
+func TestQuery_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT);`,
+		`INSERT INTO letters VALUES (1, '鲁迅'), (2, '胡适');`,
+	)
+	// b 库的结构缓存声称有 letters 表，实际查询会失败，模拟库文件损坏或被替换
+	dbB := createTestDB(t, dir, "b.db", `CREATE TABLE other (id INTEGER PRIMARY KEY);`)
+	// c 库没有目标表，应被跳过而不是记为失败
+	dbC := createTestDB(t, dir, "c.db", `CREATE TABLE other (id INTEGER PRIMARY KEY);`)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB, "c": dbC}}
+	columns := map[string][]string{"letters": {"id", "sender"}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbC] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"other": {"id"}}}
+
+	result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err, "缺省时跳过失败的库")
+	assert.Len(t, result.Data["items"], 2)
+	assert.Equal(t, int64(2), result.Data["total"])
+	warnings := result.Data[port.ResultWarningsKey].([]interface{})
+	require.Len(t, warnings, 1, "同一个库的 COUNT 与 SELECT 失败只记一次")
+	assert.Equal(t, "b", warnings[0].(map[string]interface{})["lib"])
+	assert.Contains(t, warnings[0].(map[string]interface{})["error"], "no such table")
+
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "letters", port.QueryStrictKey: true,
+	}})
+	assert.Error(t, err, "严格模式下任一库失败即整个查询失败")
+
+	manager.group["archive"] = map[string]*sql.DB{"b": dbB, "c": dbC}
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{"table": "letters"}})
+	assert.Error(t, err, "全部库都失败时没有可返回的部分结果")
+
+	manager.group["archive"] = map[string]*sql.DB{"a": dbA, "c": dbC}
+	result, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "letters", port.QueryStrictKey: true,
+	}})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Data, port.ResultWarningsKey)
+	assert.Len(t, result.Data["items"], 2)
+}
+
+func TestQuery_Rank(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO letters VALUES (1, '致友人', '提及鲁迅'), (2, '鲁迅日记', '鲁迅手稿'), (3, '家书', '无关');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO letters VALUES (4, '鲁迅书信', NULL);`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":    {FieldName: "id", IsReturnable: true},
+					"title": {FieldName: "title", IsSearchable: true, IsReturnable: true},
+					"note":  {FieldName: "note", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	columns := map[string][]string{"letters": {"id", "note", "title"}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+
+	// 网关展开后的全字段检索: title 与 note 之间为 OR，title 的权重更高
+	query := map[string]interface{}{
+		"table": "letters",
+		"filters": []interface{}{
+			map[string]interface{}{"field": "title", "value": "鲁迅", "fuzzy": true, "logic": "OR"},
+			map[string]interface{}{"field": "note", "value": "鲁迅", "fuzzy": true},
+		},
+		port.QueryRankKey: []interface{}{
+			map[string]interface{}{"field": "title", "value": "鲁迅", "weight": float64(3)},
+			map[string]interface{}{"field": "note", "value": "鲁迅", "weight": float64(1)},
+		},
+		port.QuerySortKey: port.ResultScoreField,
+	}
+	result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: query})
+	require.NoError(t, err)
+	items := result.Data["items"].([]interface{})
+	require.Len(t, items, 3)
+	ids := make([]int64, 0, len(items))
+	scores := make([]float64, 0, len(items))
+	for _, item := range items {
+		row := item.(map[string]interface{})
+		assert.NotContains(t, row, rankScoreColumn, "内部得分列不应出现在结果中")
+		ids = append(ids, row["id"].(int64))
+		scores = append(scores, row[port.ResultScoreField].(float64))
+	}
+	assert.Equal(t, []int64{2, 4, 1}, ids, "两个字段都命中的记录最靠前，各库结果按得分合并")
+	assert.Equal(t, []float64{4, 3, 1}, scores)
+
+	// 不按得分排序时仍返回得分
+	query[port.QuerySortKey] = ""
+	result, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: query})
+	require.NoError(t, err)
+	for _, item := range result.Data["items"].([]interface{}) {
+		assert.Contains(t, item.(map[string]interface{}), port.ResultScoreField)
+	}
+
+	query[port.QuerySortKey] = "title"
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: query})
+	assert.ErrorContains(t, err, "不支持的排序键")
+	query[port.QuerySortKey] = port.ResultScoreField
+
+	query[port.QueryRankKey] = []interface{}{map[string]interface{}{"field": "id", "value": "1", "weight": float64(1)}}
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: query})
+	assert.Error(t, err, "排序字段必须可检索")
+}
+
+func TestBuildRankedQuerySQL(t *testing.T) {
+	scorer := weightedScorer([]rankTerm{{Match: queryParam{Field: "title", Value: "a_b", Fuzzy: true}, Weight: 2}})
+	sqlStr, args, err := buildRankedQuerySQL("letters", []string{"id", "title"}, []queryParam{{Field: "title", Value: "a_b", Fuzzy: true}}, scorer, true, 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "title", ((CASE WHEN "title" LIKE ? THEN ? ELSE 0 END)) AS "_archiveaegis_internal_score" FROM "letters" WHERE "title" LIKE ? ORDER BY "_archiveaegis_internal_score" DESC LIMIT ? OFFSET ?`, sqlStr)
+	assert.Equal(t, []any{`%a\_b%`, float64(2), `%a\_b%`, 10, 10}, args)
+
+	scorer = bm25Scorer("letters", []float64{3, 1}, `{"title"} : ("sea")`)
+	sqlStr, args, err = buildRankedQuerySQL("letters", []string{"id"}, nil, scorer, false, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id", COALESCE((SELECT -bm25("_archiveaegis_internal_fts_letters", 3, 1) FROM "_archiveaegis_internal_fts_letters" WHERE "_archiveaegis_internal_fts_letters" MATCH ? AND rowid = "letters".rowid), 0) AS "_archiveaegis_internal_score" FROM "letters" LIMIT ? OFFSET ?`, sqlStr)
+	assert.Equal(t, []any{`{"title"} : ("sea")`, 10, 0}, args)
+}
+
+// TestQuery_RankBM25 验证配置为 bm25 的表按全文索引计分，全文检索条件本身也参与计分
+func TestQuery_RankBM25(t *testing.T) {
+	ctx := context.Background()
+	db := createTestDB(t, t.TempDir(), "a.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books VALUES (1, 'Harbor', 'sea sea sea'), (2, 'Sea Stories', 'tales'), (3, 'Forest', 'trees');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "library",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"books": {
+				TableName:    "books",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":    {FieldName: "id", IsReturnable: true},
+					"title": {FieldName: "title", IsSearchable: true, IsReturnable: true, SearchWeight: 10},
+					"note":  {FieldName: "note", IsSearchable: true, IsReturnable: true},
+				},
+				FTS:     &domain.FTSConfig{Fields: []string{"title", "note"}, Tokenizer: domain.FTSTokenizerUnicode61, Version: 1},
+				Ranking: &domain.RankingConfig{Expression: domain.RankExpressionBM25},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"library": {"a": db}}
+	manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"books": {"id", "note", "title"}}}
+	for _, step := range []map[string]interface{}{
+		{"step": ftsStepPrepare},
+		{"step": ftsStepCopy, "lib": "a", "after_rowid": float64(0), "limit": float64(100)},
+		{"step": ftsStepFinish},
+	} {
+		step["table_name"] = "books"
+		step["config"] = map[string]interface{}{"fields": []interface{}{"title", "note"}, "tokenizer": domain.FTSTokenizerUnicode61, "version": float64(1)}
+		_, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpFTSRebuild, Payload: step})
+		require.NoError(t, err)
+	}
+
+	ids := func(query map[string]interface{}) ([]int64, []float64) {
+		t.Helper()
+		query["table"] = "books"
+		query[port.QuerySortKey] = port.ResultScoreField
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: query})
+		require.NoError(t, err)
+		var out []int64
+		var scores []float64
+		for _, item := range result.Data["items"].([]interface{}) {
+			row := item.(map[string]interface{})
+			out = append(out, row["id"].(int64))
+			scores = append(scores, row[port.ResultScoreField].(float64))
+		}
+		return out, scores
+	}
+
+	// 网关展开的全字段检索：title 的权重更高，标题命中的记录排在正文多次命中的记录之前
+	got, scores := ids(map[string]interface{}{
+		"filters": []interface{}{
+			map[string]interface{}{"field": "title", "value": "sea", "fuzzy": true, "logic": "OR"},
+			map[string]interface{}{"field": "note", "value": "sea", "fuzzy": true},
+		},
+		port.QueryRankKey: []interface{}{
+			map[string]interface{}{"field": "title", "value": "sea", "weight": float64(10)},
+			map[string]interface{}{"field": "note", "value": "sea", "weight": float64(1)},
+		},
+	})
+	assert.Equal(t, []int64{2, 1}, got)
+	assert.Greater(t, scores[0], scores[1])
+	assert.Greater(t, scores[1], float64(0))
+
+	// 降低 title 的权重后，正文多次命中的记录更相关
+	cfg.Tables["books"].Fields["title"] = domain.FieldSetting{FieldName: "title", IsSearchable: true, IsReturnable: true, SearchWeight: 0.01}
+	got, _ = ids(map[string]interface{}{
+		"filters": []interface{}{
+			map[string]interface{}{"field": "title", "value": "sea", "fts": true, "logic": "OR"},
+			map[string]interface{}{"field": "note", "value": "sea", "fts": true},
+		},
+	})
+	assert.Equal(t, []int64{1, 2}, got, "全文检索条件无需计分提示也参与计分")
+}
+
+func TestBuildQuerySQL_Distinct(t *testing.T) {
+	sqlStr, _, err := buildQuerySQL("letters", []string{"place", "sender"}, true, nil, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT DISTINCT "place", "sender" FROM "letters" LIMIT ? OFFSET ?`, sqlStr)
+
+	countSQL, args, err := buildCountSQL("letters", []string{"place", "sender"}, []queryParam{{Field: "place", Value: "北京"}})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT DISTINCT "place", "sender" FROM "letters" WHERE "place" = ?)`, countSQL)
+	assert.Equal(t, []any{"北京"}, args)
+}
+
+func TestQuery_Collapse(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE scans (id INTEGER PRIMARY KEY, doc_id TEXT, caption TEXT, secret TEXT);`,
+		`INSERT INTO scans VALUES (1, 'A', '第一页', 'x'), (2, 'A', '鲁迅手稿第二页', 'x'), (3, 'B', '封面', 'x'), (4, NULL, '散页', 'x'), (5, NULL, '散页', 'x');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE scans (id INTEGER PRIMARY KEY, doc_id TEXT, caption TEXT, secret TEXT);`,
+		`INSERT INTO scans VALUES (10, 'A', '第三页', 'x'), (11, 'C', '鲁迅像', 'x');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"scans": {
+				TableName:    "scans",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":      {FieldName: "id", IsReturnable: true},
+					"doc_id":  {FieldName: "doc_id", IsSearchable: true, IsReturnable: true},
+					"caption": {FieldName: "caption", IsSearchable: true, IsReturnable: true},
+					"secret":  {FieldName: "secret", IsSearchable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	columns := map[string][]string{"scans": {"caption", "doc_id", "id", "secret"}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+
+	query := func(q map[string]interface{}) ([]map[string]interface{}, int64, error) {
+		q["table"] = "scans"
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: q})
+		if err != nil {
+			return nil, 0, err
+		}
+		var rows []map[string]interface{}
+		for _, item := range result.Data["items"].([]interface{}) {
+			rows = append(rows, item.(map[string]interface{}))
+		}
+		return rows, result.Data["total"].(int64), nil
+	}
+
+	rows, total, err := query(map[string]interface{}{port.QueryCollapseKey: "doc_id"})
+	require.NoError(t, err)
+	counts := map[string]int64{}
+	for _, row := range rows {
+		assert.NotContains(t, row, collapseKeyColumn)
+		assert.NotContains(t, row, collapseCountColumn)
+		docID, _ := row["doc_id"].(string)
+		counts[docID] = row[port.ResultGroupCountField].(int64)
+		if docID == "B" {
+			assert.Equal(t, int64(3), row["id"])
+		}
+	}
+	assert.Equal(t, map[string]int64{"A": 3, "B": 1, "": 2, "C": 1}, counts, "NULL 为一组，各库取值相同的分组合并计数")
+	assert.Equal(t, int64(5), total, "total 为各库分组数之和")
+
+	// 参与计分时代表记录为组内得分最高的一条
+	rows, _, err = query(map[string]interface{}{
+		port.QueryCollapseKey: "doc_id",
+		"fields_to_return":    []interface{}{"id"},
+		"filters":             []interface{}{map[string]interface{}{"field": "caption", "value": "页", "fuzzy": true}},
+		port.QueryRankKey:     []interface{}{map[string]interface{}{"field": "caption", "value": "鲁迅", "weight": float64(1)}},
+		port.QuerySortKey:     port.ResultScoreField,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	assert.Equal(t, int64(2), rows[0]["id"], "得分最高的代表记录排在最前")
+	assert.Equal(t, float64(1), rows[0][port.ResultScoreField])
+	assert.NotContains(t, rows[0], "doc_id", "折叠字段不必出现在返回字段中")
+
+	_, _, err = query(map[string]interface{}{port.QueryCollapseKey: "secret"})
+	assert.ErrorContains(t, err, "未被授权返回")
+	_, _, err = query(map[string]interface{}{port.QueryCollapseKey: "doc_id", port.QueryDistinctKey: true})
+	assert.ErrorContains(t, err, "不能与去重或抽样同时使用")
+}
@@ -0,0 +1,179 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/rank.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// rankScoreColumn 是参与计分的查询附加的得分列，合并各库结果后改名为 port.ResultScoreField
+const rankScoreColumn = innerPrefix + "score"
+
+// rankTerm 是一项计分条件：Match 为包含匹配或全文检索条件，命中时记录得分加上 Weight
+type rankTerm struct {
+	Match  queryParam
+	Weight float64
+}
+
+// rankScorer 是计算得分列的 SQL 表达式及其参数，由表的相关度配置决定
+type rankScorer struct {
+	expr string
+	args []any
+}
+
+// searchWeight 返回字段参与计分的权重：0 表示默认权重 1，负数 (不参与全字段检索) 视为 0
+func searchWeight(setting domain.FieldSetting) float64 {
+	switch {
+	case setting.SearchWeight == 0:
+		return 1
+	case setting.SearchWeight < 0:
+		return 0
+	default:
+		return setting.SearchWeight
+	}
+}
+
+// weightedScorer 以各计分条件命中时的权重之和作为得分
+func weightedScorer(terms []rankTerm) *rankScorer {
+	parts := make([]string, 0, len(terms))
+	args := make([]any, 0, len(terms)*2)
+	for _, term := range terms {
+		var cond string
+		var arg any
+		if term.Match.FullText {
+			cond, arg = fmt.Sprintf("rowid IN (SELECT rowid FROM %q WHERE %q MATCH ?)", term.Match.FTSIndex, term.Match.FTSIndex), term.Match.Value
+		} else {
+			cond, arg = textCondition(term.Match, term.Match.Value)
+		}
+		parts = append(parts, fmt.Sprintf("(CASE WHEN %s THEN ? ELSE 0 END)", cond))
+		args = append(args, arg, term.Weight)
+	}
+	return &rankScorer{expr: "(" + strings.Join(parts, " + ") + ")", args: args}
+}
+
+// bm25Scorer 以全文索引对 match 的 BM25 相关度作为得分，各列权重按 weights 的顺序 (与索引列一致)。
+// FTS5 的 bm25() 越小越相关，取反后得分越大越相关；未命中索引的记录得分为 0
+func bm25Scorer(tableName string, weights []float64, match string) *rankScorer {
+	index := ftsIndexTable(tableName)
+	weightArgs := make([]string, len(weights))
+	for i, w := range weights {
+		weightArgs[i] = fmt.Sprintf("%g", w)
+	}
+	expr := fmt.Sprintf("COALESCE((SELECT -bm25(%q, %s) FROM %q WHERE %q MATCH ? AND rowid = %q.rowid), 0)",
+		index, strings.Join(weightArgs, ", "), index, index, tableName)
+	return &rankScorer{expr: expr, args: []any{match}}
+}
+
+// newRankScorer 按表的相关度配置生成得分表达式。配置为 bm25 时，全文检索条件与落在索引列上的计分提示
+// 合并为一个 MATCH 表达式；没有生效的索引或没有可用的检索词时退回加权计分
+func (m *Manager) newRankScorer(ctx context.Context, bizName, tableName string, tableConfig *domain.TableConfig, terms []rankTerm) *rankScorer {
+	if tableConfig.Ranking == nil || tableConfig.Ranking.Expression != domain.RankExpressionBM25 || tableConfig.FTS == nil {
+		return weightedScorer(terms)
+	}
+	meta := m.activeFTSMeta(ctx, bizName, tableName)
+	if meta == nil {
+		return weightedScorer(terms)
+	}
+
+	prefix := meta.Tokenizer != domain.FTSTokenizerTrigram
+	var matches []string
+	for _, term := range terms {
+		switch {
+		case term.Match.FullText:
+			matches = append(matches, "("+term.Match.Value+")")
+		case meta.hasField(term.Match.Field):
+			match, err := buildFTSMatch(term.Match.Field, term.Match.Value, nil, prefix, tableConfig.FTS.Stopwords)
+			if err != nil {
+				// 检索词全部是停用词等情况下该项不参与计分
+				continue
+			}
+			matches = append(matches, "("+match+")")
+		}
+	}
+	if len(matches) == 0 {
+		return weightedScorer(terms)
+	}
+
+	weights := make([]float64, len(meta.Fields))
+	for i, field := range meta.Fields {
+		weights[i] = searchWeight(tableConfig.Fields[field])
+	}
+	return bm25Scorer(tableName, weights, strings.Join(matches, " OR "))
+}
+
+// buildRankedQuerySQL 与 buildQuerySQL 相同，但额外选出得分列；orderByScore 为 true 时按得分从高到低分页，
+// 得分相同的记录保持表中的顺序
+func buildRankedQuerySQL(
+	tableName string,
+	selectDBFields []string,
+	queryParams []queryParam,
+	scorer *rankScorer,
+	orderByScore bool,
+	page int,
+	size int,
+) (string, []any, error) {
+	if tableName == "" || len(selectDBFields) == 0 || scorer == nil {
+		return "", nil, errors.New("表名、查询字段与得分表达式不能为空 (buildRankedQuerySQL)")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 2000 {
+		size = 50
+	}
+
+	whereClause, whereArgs, err := buildWhereClause(queryParams)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	sb.WriteString(`"` + strings.Join(selectDBFields, `", "`) + `"`)
+	sb.WriteString(fmt.Sprintf(", %s AS %q", scorer.expr, rankScoreColumn))
+	sb.WriteString(fmt.Sprintf(" FROM %q", tableName))
+	if whereClause != "" {
+		sb.WriteString(" ")
+		sb.WriteString(whereClause)
+	}
+	if orderByScore {
+		sb.WriteString(fmt.Sprintf(" ORDER BY %q DESC", rankScoreColumn))
+	}
+	sb.WriteString(" LIMIT ? OFFSET ?")
+
+	args := make([]any, 0, len(scorer.args)+len(whereArgs)+2)
+	args = append(args, scorer.args...)
+	args = append(args, whereArgs...)
+	args = append(args, size, (page-1)*size)
+	return sb.String(), args, nil
+}
+
+// finishRankScores 把合并后各库结果的得分列改名为 port.ResultScoreField；
+// orderByScore 为 true 时先按得分从高到低稳定排序
+func finishRankScores(rows []map[string]any, orderByScore bool) {
+	if orderByScore {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rankScore(rows[i][rankScoreColumn]) > rankScore(rows[j][rankScoreColumn])
+		})
+	}
+	for _, row := range rows {
+		row[port.ResultScoreField] = rankScore(row[rankScoreColumn])
+		delete(row, rankScoreColumn)
+	}
+}
+
+func rankScore(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	default:
+		return 0
+	}
+}
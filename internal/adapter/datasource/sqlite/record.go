@@ -0,0 +1,159 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/record.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// queryRecord 按主键读取单条记录，与搜索分页无关，供永久链接使用。
+// 主键值以字符串传入，复合主键按声明顺序以 port.RecordIDSeparator 拼接；无显式主键的表使用 rowid。
+// 同一主键可能出现在业务组的多个库中，可通过 'lib' 指定；未指定时返回库名排序最靠前的一条，并在 matches 中给出命中数。
+func (m *Manager) queryRecord(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	tableName, _ := queryMap["table"].(string)
+	recordID, _ := queryMap["id"].(string)
+	if tableName == "" || recordID == "" {
+		return nil, fmt.Errorf("无效请求: record 模式必须包含 'table' 与 'id' 字符串字段")
+	}
+	libFilter, _ := queryMap["lib"].(string)
+
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if !bizAdminConfig.IsPubliclySearchable {
+		return nil, port.ErrPermissionDenied
+	}
+	tableAdminConfig, exists := bizAdminConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	if !tableAdminConfig.IsSearchable {
+		return nil, port.ErrPermissionDenied
+	}
+
+	var returnable []string
+	for fieldName, fieldSetting := range tableAdminConfig.Fields {
+		if fieldSetting.IsReturnable {
+			returnable = append(returnable, fieldName)
+		}
+	}
+	if len(returnable) == 0 {
+		return nil, fmt.Errorf("在表 '%s' 的配置中，没有找到任何可供返回的字段", tableName)
+	}
+	sort.Strings(returnable)
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libNames := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if libFilter != "" && libName != libFilter {
+			continue
+		}
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			if _, tableExists := schema.allTablesAndColumns[tableName]; tableExists {
+				libNames = append(libNames, libName)
+			}
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(libNames)
+
+	var record map[string]interface{}
+	matches := 0
+	for _, libName := range libNames {
+		row, err := selectRecordByID(ctx, dbInstances[libName], tableName, recordID, returnable)
+		if err != nil {
+			return nil, fmt.Errorf("读取库 '%s/%s' 表 '%s' 的记录失败: %w", bizName, libName, tableName, err)
+		}
+		if row == nil {
+			continue
+		}
+		matches++
+		if record == nil {
+			row["__lib"] = libName
+			record = row
+		}
+	}
+
+	// record 为 nil 时表示未找到，由网关决定如何响应
+	var data interface{}
+	if record != nil {
+		data = record
+	}
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"record":  data,
+			"matches": matches,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// selectRecordByID 在单个库中按主键查找记录，仅选出给定的字段。未找到时返回 nil。
+func selectRecordByID(ctx context.Context, db *sql.DB, tableName, recordID string, fields []string) (map[string]interface{}, error) {
+	pkCols, err := primaryKeyColumns(ctx, db, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []interface{}
+	if len(pkCols) == 0 {
+		rowID, err := strconv.ParseInt(recordID, 10, 64)
+		if err != nil {
+			// 无显式主键的表只能以整数 rowid 寻址，非法值视为不存在
+			return nil, nil
+		}
+		where, args = []string{"rowid = ?"}, []interface{}{rowID}
+	} else {
+		parts := []string{recordID}
+		if len(pkCols) > 1 {
+			parts = strings.Split(recordID, port.RecordIDSeparator)
+		}
+		if len(parts) != len(pkCols) {
+			return nil, nil
+		}
+		for i, col := range pkCols {
+			where = append(where, fmt.Sprintf("%q = ?", col))
+			args = append(args, parts[i])
+		}
+	}
+
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = fmt.Sprintf("%q", f)
+	}
+	rows, err := db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM %q WHERE %s LIMIT 1`, strings.Join(quoted, ", "), tableName, strings.Join(where, " AND ")),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	vals := make([]any, len(fields))
+	ptrs := make([]any, len(fields))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	record := make(map[string]interface{}, len(fields)+1)
+	for i, f := range fields {
+		record[f] = normalizeValue(vals[i])
+	}
+	return record, nil
+}
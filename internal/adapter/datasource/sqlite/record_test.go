@@ -0,0 +1,124 @@
+// file: internal/adapter/datasource/sqlite/record_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_RecordMode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, secret TEXT);`,
+		`INSERT INTO letters (id, sender, secret) VALUES (1, '鲁迅', 'x'), (2, '胡适', 'y');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, secret TEXT);`,
+		`INSERT INTO letters (id, sender, secret) VALUES (1, '周作人', 'z');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"sender": {FieldName: "sender", IsReturnable: true},
+					"secret": {FieldName: "secret", IsReturnable: false},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	for _, db := range []*sql.DB{dbA, dbB} {
+		manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{
+			allTablesAndColumns: map[string][]string{"letters": {"id", "secret", "sender"}},
+		}
+	}
+
+	lookup := func(id, lib string) map[string]interface{} {
+		result, err := manager.Query(ctx, port.QueryRequest{
+			BizName: "archive",
+			Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeRecord, "table": "letters", "id": id, "lib": lib},
+		})
+		require.NoError(t, err)
+		_, err = structpb.NewStruct(result.Data)
+		require.NoError(t, err, "结果必须可以被 structpb 序列化")
+		return result.Data
+	}
+
+	data := lookup("1", "")
+	record := data["record"].(map[string]interface{})
+	assert.Equal(t, 2, data["matches"], "主键在两个库中均存在")
+	assert.Equal(t, "a", record["__lib"])
+	assert.Equal(t, "鲁迅", record["sender"])
+	assert.NotContains(t, record, "secret", "不可返回的字段不应出现在记录中")
+
+	data = lookup("1", "b")
+	assert.Equal(t, "周作人", data["record"].(map[string]interface{})["sender"])
+
+	data = lookup("404", "")
+	assert.Nil(t, data["record"])
+	assert.Equal(t, 0, data["matches"])
+
+	schema, err := manager.GetSchema(ctx, port.SchemaRequest{BizName: "archive", TableName: "letters"})
+	require.NoError(t, err)
+	primary := map[string]bool{}
+	for _, f := range schema.Tables["letters"] {
+		primary[f.Name] = f.IsPrimary
+	}
+	assert.Equal(t, map[string]bool{"id": true, "sender": false, "secret": false}, primary, "Schema 标出按主键寻址所用的列")
+}
+
+func TestGetSchema_RecordKeys(t *testing.T) {
+	ctx := context.Background()
+	db := createTestDB(t, t.TempDir(), "a.db",
+		`CREATE TABLE volumes (year INTEGER, seq INTEGER, title TEXT, PRIMARY KEY (year, seq));`,
+		`CREATE TABLE notes (body TEXT);`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName: "archive",
+		Tables: map[string]*domain.TableConfig{
+			"volumes": {TableName: "volumes", Fields: map[string]domain.FieldSetting{
+				"title": {FieldName: "title", IsReturnable: true},
+				"seq":   {FieldName: "seq", IsReturnable: true},
+			}},
+			"notes": {TableName: "notes", Fields: map[string]domain.FieldSetting{"body": {FieldName: "body", IsReturnable: true}}},
+			"recent": {TableName: "recent", SQLView: &domain.SQLViewConfig{SQL: "SELECT title FROM volumes"},
+				Fields: map[string]domain.FieldSetting{"title": {FieldName: "title", IsReturnable: true}}},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": db}}
+	manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{
+		allTablesAndColumns: map[string][]string{"volumes": {"seq", "title", "year"}, "notes": {"body"}},
+	}
+
+	schema, err := manager.GetSchema(ctx, port.SchemaRequest{BizName: "archive"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"year", "seq"}, schema.RecordKey("volumes"), "复合主键按声明顺序，未配置的主键列也包含在内")
+	assert.Equal(t, []string{port.RecordKeyRowid}, schema.RecordKey("notes"), "无显式主键的表以 rowid 标识")
+	assert.Empty(t, schema.RecordKey("recent"), "SQL 视图表没有记录标识")
+	for _, f := range schema.Tables["volumes"] {
+		assert.Equal(t, f.Name == "seq", f.IsPrimary, f.Name)
+	}
+}
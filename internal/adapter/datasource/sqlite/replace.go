@@ -0,0 +1,204 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/replace.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 库文件替换用于整库重新导入：先把新库复制 (VACUUM INTO) 到业务组目录下的暂存文件并做完整性检查，
+// 再在写锁下关闭旧库、把它移入备份目录、以暂存文件替换并重新加载。持有写锁期间新的查询等待，
+// 已在执行的查询由 sql.DB.Close 等待其结束；替换引发的文件事件会被忽略，不再触发防抖重载。
+const (
+	replaceStagingSuffix = ".db.replacing"
+	replaceBackupPrefix  = ".replaced-"
+)
+
+// replace 处理 port.MutateOpReplace。payload 中 "lib" 是被替换的库名 (不存在时新建)，
+// "source_path" 是新库文件的路径。返回新库各表的行数、与旧库相比新增与移除的表以及备份目录
+func (m *Manager) replace(ctx context.Context, bizName string, payload map[string]interface{}) (*port.MutateResult, error) {
+	lib, _ := payload["lib"].(string)
+	if lib == "" || strings.ContainsAny(lib, `/\`) || strings.HasPrefix(lib, ".") {
+		return nil, fmt.Errorf("无效请求: replace 操作的 payload 中 'lib' 库名 '%s' 不合法", lib)
+	}
+	source, _ := payload["source_path"].(string)
+	if source == "" {
+		return nil, errors.New("无效请求: replace 操作的 payload 中必须包含 'source_path'")
+	}
+	if info, err := os.Stat(source); err != nil || info.IsDir() {
+		return nil, fmt.Errorf("无效请求: 新库文件 '%s' 不存在或不是文件", source)
+	}
+
+	m.mu.RLock()
+	_, bizExists := m.group[bizName]
+	m.mu.RUnlock()
+	if !bizExists {
+		return nil, port.ErrBizNotFound
+	}
+
+	started := time.Now()
+	bizDir := filepath.Join(m.root, bizName)
+	staging := filepath.Join(bizDir, lib+replaceStagingSuffix)
+	counts, err := stageReplacement(ctx, source, staging)
+	if err != nil {
+		_ = os.Remove(staging)
+		return nil, err
+	}
+	swap, err := m.swapReplaced(ctx, bizName, lib, staging, true)
+	if err != nil {
+		_ = os.Remove(staging)
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"lib":            lib,
+		"tables":         tableCountsList(counts),
+		"added_tables":   swap.added,
+		"removed_tables": swap.removed,
+		"created":        swap.backupDir == "",
+		"duration_ms":    time.Since(started).Milliseconds(),
+	}
+	if swap.backupDir != "" {
+		data["backup_dir"] = swap.backupDir
+	}
+	return &port.MutateResult{Data: data, Source: m.Type()}, nil
+}
+
+// stageReplacement 把新库复制到暂存文件并做完整性检查，返回各用户表的行数。新库中没有任何用户表时返回错误
+func stageReplacement(ctx context.Context, source, staging string) (map[string]int64, error) {
+	if err := os.Remove(staging); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("清理旧的暂存文件失败: %w", err)
+	}
+	src, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro&_busy_timeout=5000", source))
+	if err != nil {
+		return nil, err
+	}
+	// VACUUM INTO 得到一致的副本，同时包含新库 WAL 中尚未检查点的内容
+	_, err = src.ExecContext(ctx, `VACUUM INTO ?`, staging)
+	_ = src.Close()
+	if err != nil {
+		return nil, fmt.Errorf("无效请求: 无法读取新库文件 '%s' (可能不是 SQLite 数据库): %w", source, err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+staging)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	var check string
+	if err := db.QueryRowContext(ctx, `PRAGMA quick_check`).Scan(&check); err != nil {
+		return nil, fmt.Errorf("检查新库完整性失败: %w", err)
+	}
+	if check != "ok" {
+		return nil, fmt.Errorf("无效请求: 新库未通过完整性检查: %s", check)
+	}
+	tables, err := getTablesSet(db)
+	if err != nil {
+		return nil, fmt.Errorf("读取新库的表失败: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("无效请求: 新库中没有任何用户表")
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	names := make(map[string]int64, len(tables))
+	for table := range tables {
+		names[table] = 0
+	}
+	return countTables(ctx, conn, "main", names)
+}
+
+// replaceSwap 是一次替换的结果
+type replaceSwap struct {
+	backupDir      string
+	added, removed []string
+}
+
+// swapReplaced 在写锁下关闭旧库并移入备份目录 (backup 为 false 时直接删除)，把暂存文件改名为目标库后重新加载业务组
+func (m *Manager) swapReplaced(ctx context.Context, bizName, lib, staging string, backup bool) (*replaceSwap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bizDir := filepath.Join(m.root, bizName)
+	target := filepath.Join(bizDir, lib+".db")
+	swap := &replaceSwap{}
+	ignored := []string{target}
+	previous := map[string]struct{}{}
+	db := m.group[bizName][lib]
+	if db != nil {
+		if info := m.dbSchemaCache[db]; info != nil {
+			for table := range info.allTablesAndColumns {
+				previous[table] = struct{}{}
+			}
+		}
+		if backup {
+			swap.backupDir = filepath.Join(bizDir, replaceBackupPrefix+time.Now().UTC().Format("20060102T150405"))
+			ignored = append(ignored, filepath.Join(swap.backupDir, lib+".db"))
+		}
+	}
+	m.ignoreFsEvents(ignored...)
+	if db != nil {
+		if backup {
+			if err := os.MkdirAll(swap.backupDir, 0o755); err != nil {
+				return nil, fmt.Errorf("建立备份目录失败: %w", err)
+			}
+		}
+		delete(m.dbSchemaCache, db)
+		delete(m.group[bizName], lib)
+		if err := db.Close(); err != nil {
+			return nil, fmt.Errorf("关闭库 '%s' 失败: %w", lib, err)
+		}
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			var err error
+			if backup {
+				err = os.Rename(target+suffix, filepath.Join(swap.backupDir, lib+".db"+suffix))
+			} else {
+				err = os.Remove(target + suffix)
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("移走旧库 '%s' 失败: %w", lib, err)
+			}
+		}
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return nil, fmt.Errorf("替换库 '%s' 失败: %w", lib, err)
+	}
+	if err := m.openDBInternal(ctx, target); err != nil {
+		return nil, err
+	}
+	// 库的表结构可能已变化，丢弃 schema 缓存文件后重新扫描
+	_ = os.Remove(filepath.Join(bizDir, schemaCacheFilename))
+	m.loadOrRefreshSchemaInternal()
+
+	current := map[string]struct{}{}
+	if info := m.dbSchemaCache[m.group[bizName][lib]]; info != nil {
+		for table := range info.allTablesAndColumns {
+			current[table] = struct{}{}
+		}
+	}
+	swap.added, swap.removed = tableDiff(previous, current), tableDiff(current, previous)
+	return swap, nil
+}
+
+// tableDiff 返回在 b 中而不在 a 中的表，按名称排序
+func tableDiff(a, b map[string]struct{}) []string {
+	diff := []string{}
+	for table := range b {
+		if _, ok := a[table]; !ok {
+			diff = append(diff, table)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
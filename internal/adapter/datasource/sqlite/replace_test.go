@@ -0,0 +1,74 @@
+// file: internal/adapter/datasource/sqlite/replace_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutate_Replace(t *testing.T) {
+	ctx := context.Background()
+	manager, bizDir := newCompactManager(t)
+
+	sourceDir := t.TempDir()
+	source := createTestDB(t, sourceDir, "fresh.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT)`,
+		`CREATE TABLE series (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO books (id, title) VALUES (1, '三体'), (2, '黑暗森林'), (3, '死神永生')`,
+		`INSERT INTO series (id, name) VALUES (1, '地球往事')`,
+	)
+	require.NoError(t, source.Close())
+
+	replace := func(payload map[string]interface{}) (map[string]interface{}, error) {
+		res, err := manager.AdminMutate(ctx, port.MutateRequest{BizName: "library", Operation: port.MutateOpReplace, Payload: payload})
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	}
+
+	data, err := replace(map[string]interface{}{"lib": "a", "source_path": filepath.Join(sourceDir, "fresh.db")})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"table": "books", "rows": int64(3)},
+		map[string]interface{}{"table": "series", "rows": int64(1)},
+	}, data["tables"])
+	assert.Equal(t, []string{"series"}, data["added_tables"])
+	assert.Empty(t, data["removed_tables"])
+	assert.Equal(t, false, data["created"])
+	backupDir, _ := data["backup_dir"].(string)
+	assert.FileExists(t, filepath.Join(backupDir, "a.db"), "旧库移入备份目录")
+	assert.NoFileExists(t, filepath.Join(bizDir, "a"+replaceStagingSuffix))
+	assert.FileExists(t, filepath.Join(sourceDir, "fresh.db"), "新库文件保持不变")
+
+	manager.mu.RLock()
+	db := manager.group["library"]["a"]
+	_, hasSeries := manager.schema["library"]["series"]
+	manager.mu.RUnlock()
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM books`).Scan(&n))
+	assert.Equal(t, 3, n, "替换后的连接指向新库")
+	assert.True(t, hasSeries, "替换后重新发现表结构")
+
+	data, err = replace(map[string]interface{}{"lib": "c", "source_path": filepath.Join(sourceDir, "fresh.db")})
+	require.NoError(t, err)
+	assert.Equal(t, true, data["created"], "不存在的库直接新建")
+	assert.NotContains(t, data, "backup_dir")
+
+	notDB := filepath.Join(sourceDir, "notes.db")
+	require.NoError(t, os.WriteFile(notDB, []byte("这不是数据库文件"), 0o644))
+	_, err = replace(map[string]interface{}{"lib": "a", "source_path": notDB})
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(bizDir, "a"+replaceStagingSuffix), "失败时清理暂存文件")
+
+	_, err = replace(map[string]interface{}{"lib": "../a", "source_path": filepath.Join(sourceDir, "fresh.db")})
+	assert.Error(t, err)
+	_, err = replace(map[string]interface{}{"lib": "a", "source_path": filepath.Join(sourceDir, "missing.db")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/replicate.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// replicatedChange 是从源实例变更日志中解析出的一条待应用记录
+type replicatedChange struct {
+	lib   string
+	table string
+	op    string
+	pk    map[string]interface{}
+	data  map[string]interface{}
+}
+
+// applyReplicatedChanges 将源实例推送的变更按库分组，在各库的单个事务中按顺序应用。
+// 冲突策略为“源端优先”：create/update 以 INSERT OR REPLACE 覆盖本地行，delete 按主键删除。
+func (m *Manager) applyReplicatedChanges(ctx context.Context, bizName string, payload map[string]interface{}) (*port.MutateResult, error) {
+	rawChanges, ok := payload["changes"].([]interface{})
+	if !ok {
+		return nil, errors.New("replicate 操作的 payload 中必须包含一个 'changes' 数组")
+	}
+
+	byLib := make(map[string][]replicatedChange)
+	for i, raw := range rawChanges {
+		change, err := parseReplicatedChange(raw)
+		if err != nil {
+			return nil, fmt.Errorf("无效请求: changes 数组的第 %d 个元素无效: %w", i, err)
+		}
+		byLib[change.lib] = append(byLib[change.lib], change)
+	}
+
+	m.mu.RLock()
+	dbInstances, bizExists := m.group[bizName]
+	m.mu.RUnlock()
+	if !bizExists {
+		return nil, port.ErrBizNotFound
+	}
+
+	libNames := make([]string, 0, len(byLib))
+	for libName := range byLib {
+		if _, exists := dbInstances[libName]; !exists {
+			return nil, fmt.Errorf("同步目标业务组 '%s' 中不存在库 '%s'", bizName, libName)
+		}
+		libNames = append(libNames, libName)
+	}
+	sort.Strings(libNames)
+
+	var applied int64
+	for _, libName := range libNames {
+		if err := applyChangesToDB(ctx, dbInstances[libName], byLib[libName]); err != nil {
+			return nil, fmt.Errorf("在库 '%s' 上应用同步变更失败: %w", libName, err)
+		}
+		applied += int64(len(byLib[libName]))
+	}
+
+	return &port.MutateResult{
+		Data: map[string]interface{}{
+			"success": true,
+			"applied": applied,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+func parseReplicatedChange(raw interface{}) (replicatedChange, error) {
+	var c replicatedChange
+	item, ok := raw.(map[string]interface{})
+	if !ok {
+		return c, errors.New("不是一个有效的JSON对象")
+	}
+	c.lib, _ = item["lib"].(string)
+	c.table, _ = item["table"].(string)
+	c.op, _ = item["op"].(string)
+	c.pk, _ = item["pk"].(map[string]interface{})
+	c.data, _ = item["data"].(map[string]interface{})
+	if c.lib == "" || c.table == "" || len(c.pk) == 0 {
+		return c, errors.New("缺少 'lib'、'table' 或 'pk' 字段")
+	}
+	switch c.op {
+	case "create", "update":
+		if len(c.data) == 0 {
+			return c, fmt.Errorf("'%s' 变更缺少 'data'", c.op)
+		}
+	case "delete":
+	default:
+		return c, fmt.Errorf("不支持的变更类型 '%s'", c.op)
+	}
+	return c, nil
+}
+
+func applyChangesToDB(ctx context.Context, db *sql.DB, changes []replicatedChange) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	for _, c := range changes {
+		var stmt string
+		var args []interface{}
+		if c.op == "delete" {
+			stmt, args = buildDeleteByPKSQL(c.table, c.pk)
+		} else {
+			stmt, args, err = buildReplaceSQL(c.table, c.data, c.pk)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err = tx.ExecContext(ctx, stmt, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildReplaceSQL 构建 INSERT OR REPLACE 语句。
+// 无显式主键的表以 rowid 标识，此时需要把 rowid 一并写入以保持两端一致。
+func buildReplaceSQL(tableName string, data, pk map[string]interface{}) (string, []interface{}, error) {
+	row := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		row[k] = v
+	}
+	if rowID, ok := pk["rowid"]; ok && len(pk) == 1 {
+		row["rowid"] = rowID
+	}
+	query, args, err := buildInsertSQL(tableName, row)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.Replace(query, "INSERT INTO", "INSERT OR REPLACE INTO", 1), args, nil
+}
+
+func buildDeleteByPKSQL(tableName string, pk map[string]interface{}) (string, []interface{}) {
+	cols := make([]string, 0, len(pk))
+	for col := range pk {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	conditions := make([]string, 0, len(cols))
+	args := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		conditions = append(conditions, fmt.Sprintf("%q = ?", col))
+		args = append(args, pk[col])
+	}
+	return fmt.Sprintf("DELETE FROM %q WHERE %s", tableName, strings.Join(conditions, " AND ")), args
+}
@@ -0,0 +1,88 @@
+// file: internal/adapter/datasource/sqlite/replicate_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutate_ReplicateChanges(t *testing.T) {
+	ctx := context.Background()
+	source, _ := newChangeCaptureManager(t, true)
+	mirror, mirrorDB := newChangeCaptureManager(t, false)
+
+	// 镜像端已有一条与源端冲突的记录，同步后应被源端覆盖
+	_, err := mirrorDB.Exec(`INSERT INTO books (id, title, author) VALUES (1, '旧标题', '未知')`)
+	require.NoError(t, err)
+
+	for _, req := range []port.MutateRequest{
+		{Operation: "create", Payload: map[string]interface{}{"data": map[string]interface{}{"id": 1, "title": "围城", "author": "钱钟书"}}},
+		{Operation: "create", Payload: map[string]interface{}{"data": map[string]interface{}{"id": 2, "title": "边城", "author": "沈从文"}}},
+		{Operation: "delete", Payload: map[string]interface{}{"filters": []interface{}{map[string]interface{}{"field": "id", "value": 2}}}},
+	} {
+		req.BizName = "library"
+		req.Payload["table_name"] = "books"
+		_, err := source.Mutate(ctx, req)
+		require.NoError(t, err)
+	}
+
+	changes, err := source.Query(ctx, port.QueryRequest{
+		BizName: "library",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeChanges},
+	})
+	require.NoError(t, err)
+
+	// 模拟经过 HTTP 传输后的通用 JSON 结构
+	raw, err := json.Marshal(changes.Data["items"])
+	require.NoError(t, err)
+	var items []interface{}
+	require.NoError(t, json.Unmarshal(raw, &items))
+
+	result, err := mirror.AdminMutate(ctx, port.MutateRequest{
+		BizName:   "library",
+		Operation: port.MutateOpReplicate,
+		Payload:   map[string]interface{}{"changes": items},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Data["applied"])
+
+	var title string
+	require.NoError(t, mirrorDB.QueryRow(`SELECT title FROM books WHERE id = 1`).Scan(&title))
+	assert.Equal(t, "围城", title)
+
+	var count int
+	require.NoError(t, mirrorDB.QueryRow(`SELECT COUNT(*) FROM books`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestMutate_ReplicateRejectsInvalidChange(t *testing.T) {
+	manager, _ := newChangeCaptureManager(t, false)
+	_, err := manager.AdminMutate(context.Background(), port.MutateRequest{
+		BizName:   "library",
+		Operation: port.MutateOpReplicate,
+		Payload: map[string]interface{}{"changes": []interface{}{
+			map[string]interface{}{"lib": "cdc.db", "table": "books", "op": "truncate", "pk": map[string]interface{}{"id": 1}},
+		}},
+	})
+	assert.Error(t, err)
+}
+
+func TestMutate_RejectsAdminOperations(t *testing.T) {
+	manager, _ := newChangeCaptureManager(t, false)
+	for _, op := range []string{port.MutateOpReplicate, port.MutateOpFTSRebuild, port.MutateOpMaintenance, port.MutateOpCompact, port.MutateOpReplace, "truncate"} {
+		_, err := manager.Mutate(context.Background(), port.MutateRequest{
+			BizName:   "library",
+			Operation: op,
+			Payload:   map[string]interface{}{"changes": []interface{}{}},
+		})
+		assert.Error(t, err, op)
+	}
+
+	_, err := manager.AdminMutate(context.Background(), port.MutateRequest{BizName: "library", Operation: "create"})
+	assert.ErrorIs(t, err, port.ErrAdminMutateUnsupported)
+}
@@ -0,0 +1,201 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/s3sync.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/adapter/objectstore"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3SyncInterval = 5 * time.Minute
+	minS3SyncInterval     = 10 * time.Second
+	// s3SyncStateFilename 记录每个库最近一次同步的对象 ETag，避免重启后重复下载
+	s3SyncStateFilename = ".s3sync.json"
+	s3DownloadSuffix    = ".db.download"
+)
+
+// S3SyncOptions 配置从 S3 兼容的对象存储同步库文件。前缀下直接存放的 <库名>.db 对象被下载到业务组目录，
+// ETag 变化时重新下载，经完整性检查后替换本地的库并重新加载；对象被删除时本地的库保留。
+// 凭据从环境变量 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 与 AWS_SESSION_TOKEN 读取，不写入 storage.json
+type S3SyncOptions struct {
+	objectstore.S3Config
+	Prefix string `json:"prefix"`
+	// Interval 是检查对象变化的间隔，默认为 5 分钟
+	Interval Duration `json:"interval"`
+}
+
+func (o *S3SyncOptions) validate() error {
+	if o.Region == "" || o.Bucket == "" {
+		return errors.New("s3 需要 region 与 bucket")
+	}
+	if o.Interval == 0 {
+		o.Interval = Duration(defaultS3SyncInterval)
+	}
+	if time.Duration(o.Interval) < minS3SyncInterval {
+		return fmt.Errorf("s3.interval 不能小于 %s", minS3SyncInterval)
+	}
+	return nil
+}
+
+// s3Syncer 把一个业务组的库文件从对象存储同步到本地目录
+type s3Syncer struct {
+	m       *Manager
+	bizName string
+	dir     string
+	opts    S3SyncOptions
+	client  *objectstore.S3Client
+	// etags 以库名索引本地库对应的对象 ETag
+	etags map[string]string
+}
+
+func newS3Syncer(m *Manager, bizName string, opts S3SyncOptions) (*s3Syncer, error) {
+	cfg := opts.S3Config
+	cfg.CredentialsFromEnv()
+	client, err := objectstore.NewS3Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &s3Syncer{
+		m:       m,
+		bizName: bizName,
+		dir:     filepath.Join(m.root, bizName),
+		opts:    opts,
+		client:  client,
+		etags:   make(map[string]string),
+	}
+	if data, err := os.ReadFile(filepath.Join(s.dir, s3SyncStateFilename)); err == nil {
+		if err := json.Unmarshal(data, &s.etags); err != nil {
+			log.Printf("警告: [DBManager S3] 同步状态文件损坏，将重新下载全部库: %v", err)
+			s.etags = make(map[string]string)
+		}
+	}
+	return s, nil
+}
+
+func (s *s3Syncer) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.opts.Interval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.syncOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("警告: [DBManager S3] 业务组 '%s' 同步失败: %v", s.bizName, err)
+			}
+		}
+	}
+}
+
+// syncOnce 下载 ETag 变化或本地缺失的库。单个库失败不影响其他库，返回遇到的第一个错误
+func (s *s3Syncer) syncOnce(ctx context.Context) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	objects, err := s.client.List(ctx, s.opts.Prefix)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, obj := range objects {
+		lib, ok := s3LibName(s.opts.Prefix, obj.Key)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.dir, lib+".db")); err == nil && s.etags[lib] == obj.ETag {
+			continue
+		}
+		if err := s.syncLib(ctx, lib, obj); err != nil {
+			log.Printf("警告: [DBManager S3] 同步库 '%s/%s' 失败: %v", s.bizName, lib, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		s.etags[lib] = obj.ETag
+		if err := s.saveState(); err != nil {
+			log.Printf("警告: [DBManager S3] 保存同步状态失败: %v", err)
+		}
+		log.Printf("信息: [DBManager S3] 已从 S3 同步库 '%s/%s' (%d 字节)", s.bizName, lib, obj.Size)
+	}
+	return firstErr
+}
+
+// s3LibName 返回前缀下直接存放的 .db 对象对应的库名；子目录中的对象与以 "." 开头的名称被忽略
+func s3LibName(prefix, key string) (string, bool) {
+	name := strings.TrimPrefix(key, prefix)
+	if name == key && prefix != "" {
+		return "", false
+	}
+	if strings.Contains(name, "/") || !strings.HasSuffix(name, ".db") {
+		return "", false
+	}
+	lib := strings.TrimSuffix(name, ".db")
+	if lib == "" || strings.HasPrefix(lib, ".") || strings.ContainsAny(lib, `\`) {
+		return "", false
+	}
+	return lib, true
+}
+
+// syncLib 下载对象后按库文件替换的流程复制、检查并替换本地的库。旧库不备份，需要时可以从对象存储重新下载
+func (s *s3Syncer) syncLib(ctx context.Context, lib string, obj objectstore.Object) error {
+	download := filepath.Join(s.dir, lib+s3DownloadSuffix)
+	defer os.Remove(download)
+	if err := s.download(ctx, obj, download); err != nil {
+		return err
+	}
+	staging := filepath.Join(s.dir, lib+replaceStagingSuffix)
+	if _, err := stageReplacement(ctx, download, staging); err != nil {
+		_ = os.Remove(staging)
+		return err
+	}
+	if _, err := s.m.swapReplaced(ctx, s.bizName, lib, staging, false); err != nil {
+		_ = os.Remove(staging)
+		return err
+	}
+	return nil
+}
+
+func (s *s3Syncer) download(ctx context.Context, obj objectstore.Object, path string) error {
+	body, err := s.client.Get(ctx, obj.Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("下载对象 '%s' 失败: %w", obj.Key, err)
+	}
+	if obj.Size > 0 && n != obj.Size {
+		return fmt.Errorf("下载对象 '%s' 不完整: 收到 %d 字节，应为 %d 字节", obj.Key, n, obj.Size)
+	}
+	return nil
+}
+
+func (s *s3Syncer) saveState() error {
+	data, err := json.Marshal(s.etags)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(s.dir, s3SyncStateFilename+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, s3SyncStateFilename))
+}
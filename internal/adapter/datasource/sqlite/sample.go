@@ -0,0 +1,91 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/sample.go
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// maxSampleSize 是抽样模式 (port.QuerySampleKey) 单次返回的最大行数，与分页大小的上限一致
+const maxSampleSize = 2000
+
+// buildSampleSQL 构建从匹配行中随机抽取 n 行的 SQL。
+// 有 rowid 的表先在子查询中只对 rowid 做 ORDER BY RANDOM()，再按 rowid 取回所需的列，
+// 排序的数据量与行宽无关，大表上明显快于对整行排序；WITHOUT ROWID 表退回对整行排序
+func buildSampleSQL(tableName string, selectDBFields []string, queryParams []queryParam, n int, hasRowid bool) (string, []any, error) {
+	if tableName == "" || len(selectDBFields) == 0 {
+		return "", nil, errors.New("表名和查询字段不能为空 (buildSampleSQL)")
+	}
+	whereClause, whereArgs, err := buildWhereClause(queryParams)
+	if err != nil {
+		return "", nil, err
+	}
+	if whereClause != "" {
+		whereClause = " " + whereClause
+	}
+
+	selectClause := `"` + strings.Join(selectDBFields, `", "`) + `"`
+	var sqlStr string
+	if hasRowid {
+		sqlStr = fmt.Sprintf("SELECT %s FROM %q WHERE rowid IN (SELECT rowid FROM %q%s ORDER BY RANDOM() LIMIT ?)", selectClause, tableName, tableName, whereClause)
+	} else {
+		sqlStr = fmt.Sprintf("SELECT %s FROM %q%s ORDER BY RANDOM() LIMIT ?", selectClause, tableName, whereClause)
+	}
+	return sqlStr, append(whereArgs, n), nil
+}
+
+// tableHasRowid 判断表是否带有 rowid (即不是 WITHOUT ROWID 表)；无法判断时按没有 rowid 处理
+func tableHasRowid(ctx context.Context, db *sql.DB, tableName string) bool {
+	var ddl string
+	err := db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, tableName).Scan(&ddl)
+	if err != nil {
+		return false
+	}
+	return !strings.Contains(strings.ToUpper(ddl), "WITHOUT ROWID")
+}
+
+// mergeSamples 把各库各自抽取的样本合并为 n 行。
+// 每个库最多贡献 n 行，但库的匹配数可能相差很大，直接截取会使小库被过度代表。
+// 这里按加权无放回抽样 (Efraimidis-Spirakis) 选取：来自某库的每一行代表该库 匹配数/样本数 行，
+// 使合并结果近似于从全部匹配行中均匀抽样
+func mergeSamples(rows []map[string]any, libCounts map[string]int64, n int) []map[string]any {
+	if len(rows) <= n && len(libCounts) <= 1 {
+		rand.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		return rows
+	}
+
+	sampled := make(map[string]int, len(libCounts))
+	for _, row := range rows {
+		lib, _ := row["__lib"].(string)
+		sampled[lib]++
+	}
+	type keyed struct {
+		row map[string]any
+		key float64
+	}
+	candidates := make([]keyed, 0, len(rows))
+	for _, row := range rows {
+		lib, _ := row["__lib"].(string)
+		weight := 1.0
+		if count := libCounts[lib]; count > 0 {
+			weight = float64(count) / float64(sampled[lib])
+		}
+		// key = u^(1/w)，取 key 最大的 n 个；以对数形式计算避免下溢
+		candidates = append(candidates, keyed{row: row, key: math.Log(1-rand.Float64()) / weight})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].key > candidates[j].key })
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	out := make([]map[string]any, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.row
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+// file: internal/adapter/datasource/sqlite/sample_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSampleSQL(t *testing.T) {
+	filters := []queryParam{{Field: "place", Value: "北京"}}
+	sqlStr, args, err := buildSampleSQL("letters", []string{"id", "sender"}, filters, 5, true)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id", "sender" FROM "letters" WHERE rowid IN (SELECT rowid FROM "letters" WHERE "place" = ? ORDER BY RANDOM() LIMIT ?)`, sqlStr)
+	assert.Equal(t, []any{"北京", 5}, args)
+
+	sqlStr, args, err = buildSampleSQL("letters", []string{"id"}, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "id" FROM "letters" ORDER BY RANDOM() LIMIT ?`, sqlStr)
+	assert.Equal(t, []any{3}, args)
+}
+
+func TestMergeSamples_WeightsByLibrarySize(t *testing.T) {
+	// a 库匹配 900 行、b 库匹配 100 行，各自抽出 10 行
+	var rows []map[string]any
+	for i := 0; i < 10; i++ {
+		rows = append(rows, map[string]any{"__lib": "a"}, map[string]any{"__lib": "b"})
+	}
+	fromA := 0
+	const rounds = 2000
+	for i := 0; i < rounds; i++ {
+		input := append([]map[string]any(nil), rows...)
+		merged := mergeSamples(input, map[string]int64{"a": 900, "b": 100}, 10)
+		require.Len(t, merged, 10)
+		for _, row := range merged {
+			if row["__lib"] == "a" {
+				fromA++
+			}
+		}
+	}
+	// 每库只抽出 10 行，合并结果无法完全达到 90%，但应明显偏离不加权时的 50%
+	ratio := float64(fromA) / float64(rounds*10)
+	assert.Greater(t, ratio, 0.75, "实际比例 %.3f", ratio)
+}
+
+func TestQuery_Sample(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	insert := `WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 200) INSERT INTO letters SELECT i, 'sender' || i, CASE WHEN i % 2 = 0 THEN '北京' ELSE '上海' END FROM n`
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, place TEXT);`, insert)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, place TEXT) WITHOUT ROWID;`, insert)
+	assert.True(t, tableHasRowid(ctx, dbA, "letters"))
+	assert.False(t, tableHasRowid(ctx, dbB, "letters"))
+
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"sender": {FieldName: "sender", IsReturnable: true},
+					"place":  {FieldName: "place", IsSearchable: true, IsReturnable: true},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	columns := map[string][]string{"letters": {"id", "sender", "place"}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: columns}
+
+	sample := func() (int64, []interface{}) {
+		t.Helper()
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+			"table":   "letters",
+			"sample":  float64(7),
+			"filters": []interface{}{map[string]interface{}{"field": "place", "value": "北京"}},
+		}})
+		require.NoError(t, err)
+		return result.Data["total"].(int64), result.Data["items"].([]interface{})
+	}
+
+	total, items := sample()
+	assert.Equal(t, int64(200), total, "total 为匹配总数而非样本数")
+	require.Len(t, items, 7)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		row := item.(map[string]any)
+		assert.Equal(t, "北京", row["place"])
+		seen[fmt.Sprintf("%v/%v", row["__lib"], row["id"])] = true
+	}
+	assert.Len(t, seen, 7, "样本中不应有重复行")
+
+	// 连续两次抽样得到完全相同结果的概率可以忽略
+	_, again := sample()
+	assert.NotEqual(t, items, again)
+
+	for _, bad := range []interface{}{float64(0), float64(2.5), float64(maxSampleSize + 1)} {
+		_, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{"table": "letters", "sample": bad}})
+		assert.ErrorContains(t, err, "sample", "sample=%v", bad)
+	}
+}
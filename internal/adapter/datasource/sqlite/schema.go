@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -23,6 +24,10 @@ const (
 type dbPhysicalSchemaInfo struct {
 	detectedDefaultTable string
 	allTablesAndColumns  map[string][]string
+	// pinyinColumns 记录已确认存在拼音影子列的 "表\x00字段"，见 ensurePinyinColumns
+	pinyinColumns map[string]bool
+	// noCaseIndexes 记录已确认建立 NOCASE 索引的 "表\x00字段"，见 ensureNoCaseIndex
+	noCaseIndexes map[string]bool
 }
 
 // schemaFile 表示写入磁盘的 schema_cache.json 的整体 JSON 结构
@@ -32,6 +37,48 @@ type schemaFile struct {
 	Libs      map[string]map[string][]string `json:"libs"`   // 每库各表列
 }
 
+var _ port.PhysicalSchemaReader = (*Manager)(nil)
+
+// PhysicalSchema 实现 port.PhysicalSchemaReader，返回业务组各库实际存在的表与列的并集，不含网关内部的表与影子列
+func (m *Manager) PhysicalSchema(ctx context.Context, bizName string) (map[string][]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	libs, ok := m.group[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	union := make(map[string]map[string]bool)
+	for _, db := range libs {
+		info := m.dbSchemaCache[db]
+		if info == nil {
+			continue
+		}
+		for table, columns := range info.allTablesAndColumns {
+			if strings.HasPrefix(table, innerPrefix) {
+				continue
+			}
+			if union[table] == nil {
+				union[table] = make(map[string]bool)
+			}
+			for _, column := range columns {
+				if !strings.HasPrefix(column, innerPrefix) {
+					union[table][column] = true
+				}
+			}
+		}
+	}
+	tables := make(map[string][]string, len(union))
+	for table, columns := range union {
+		names := make([]string, 0, len(columns))
+		for column := range columns {
+			names = append(names, column)
+		}
+		sort.Strings(names)
+		tables[table] = names
+	}
+	return tables, nil
+}
+
 // GetSchema 实现 port.DataSource 接口，返回由管理员配置定义的、可供查询的 Schema。
 func (m *Manager) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
 	bizConfig, err := m.configService.GetBizQueryConfig(ctx, req.BizName)
@@ -43,21 +90,47 @@ func (m *Manager) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.
 	}
 
 	schemaTables := make(map[string][]port.FieldDescription)
+	recordKeys := make(map[string][]string)
 
 	for tableName, tableConfig := range bizConfig.Tables {
 		if req.TableName != "" && req.TableName != tableName {
 			continue
 		}
 
+		var activeFTS *ftsMeta
+		if tableConfig.FTS != nil {
+			activeFTS = m.activeFTSMeta(ctx, req.BizName, tableName)
+		}
+
+		primaryKeys := make(map[string]bool)
+		if tableConfig.SQLView != nil {
+			// SQL 视图表的行由查询临时生成，没有可稳定寻址的标识
+			recordKeys[tableName] = []string{}
+		} else if key, ok := m.recordKey(ctx, req.BizName, tableName); ok {
+			recordKeys[tableName] = key
+			for _, col := range key {
+				if col != port.RecordKeyRowid {
+					primaryKeys[col] = true
+				}
+			}
+		}
 		var fields []port.FieldDescription
 		for _, fieldSetting := range tableConfig.Fields {
+			var tokenizer string
+			if activeFTS != nil && fieldSetting.IsSearchable && activeFTS.hasField(fieldSetting.FieldName) {
+				tokenizer = activeFTS.Tokenizer
+			}
 			fields = append(fields, port.FieldDescription{
 				Name:         fieldSetting.FieldName,
 				DataType:     fieldSetting.DataType,
 				IsSearchable: fieldSetting.IsSearchable,
 				IsReturnable: fieldSetting.IsReturnable,
-				IsPrimary:    false, // 暂未实现
-				Description:  "",    // 暂未实现
+				IsPrimary:    primaryKeys[fieldSetting.FieldName],
+				Description:  "", // 暂未实现
+				// 拼音检索依赖本适配器注册的 SQL 函数，由适配器而非配置服务声明该能力
+				PinyinSearchable: fieldSetting.IsSearchable && fieldSetting.Pinyin,
+				// 报告实际建立的索引所用的分词器，修改配置后在重建完成前仍为旧值
+				FullTextTokenizer: tokenizer,
 			})
 		}
 		sort.Slice(fields, func(i, j int) bool {
@@ -71,10 +144,45 @@ func (m *Manager) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.
 	}
 
 	return &port.SchemaResult{
-		Tables: schemaTables,
+		Tables:     schemaTables,
+		RecordKeys: recordKeys,
 	}, nil
 }
 
+// recordKey 返回表的有效记录标识：显式主键列 (PRAGMA table_info 中的 pk 顺序)，无显式主键时为 rowid。
+// 以库名排序最靠前、包含该表的库为准；表不存在于任何库或读取失败时 ok 为 false
+func (m *Manager) recordKey(ctx context.Context, bizName, tableName string) (key []string, ok bool) {
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libNames := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			if _, exists := schema.allTablesAndColumns[tableName]; exists {
+				libNames = append(libNames, libName)
+			}
+		}
+	}
+	m.mu.RUnlock()
+	if len(libNames) == 0 {
+		return nil, false
+	}
+	sort.Strings(libNames)
+
+	db := dbInstances[libNames[0]]
+	cols, err := primaryKeyColumns(ctx, db, tableName)
+	if err != nil {
+		log.Printf("警告: [DBManager] GetSchema: 读取业务 '%s' 表 '%s' 的主键失败: %v", bizName, tableName, err)
+		return nil, false
+	}
+	if len(cols) > 0 {
+		return cols, true
+	}
+	if tableHasRowid(ctx, db, tableName) {
+		return []string{port.RecordKeyRowid}, true
+	}
+	return []string{}, true
+}
+
 // loadDBPhysicalSchema 从给定的数据库连接中加载其实际的物理表和列信息。
 func loadDBPhysicalSchema(ctx context.Context, db *sql.DB) (*dbPhysicalSchemaInfo, error) {
 	autoDetectedDefaultTable, errDetect := detectTable(db)
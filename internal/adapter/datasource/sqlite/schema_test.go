@@ -196,3 +196,27 @@ func TestComputeSchemaUnionForBiz(t *testing.T) {
 	}
 	assert.Equal(t, expectedPerLib, perLib)
 }
+
+func TestPhysicalSchema(t *testing.T) {
+	manager := NewManager(&mockAdminConfigService{})
+	dbA, dbB := &sql.DB{}, &sql.DB{}
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{
+		"letters":                   {"sender", "id", innerPrefix + "sender_pinyin"},
+		innerPrefix + "letters_fts": {"content"},
+	}}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{
+		"letters": {"id", "place"},
+		"people":  {"name"},
+	}}
+
+	tables, err := manager.PhysicalSchema(context.Background(), "archive")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"letters": {"id", "place", "sender"},
+		"people":  {"name"},
+	}, tables, "返回各库的并集，不含网关内部的表与影子列")
+
+	_, err = manager.PhysicalSchema(context.Background(), "missing")
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
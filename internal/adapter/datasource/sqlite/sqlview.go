@@ -0,0 +1,441 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/sqlview.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxSQLViewLength = 16 << 10
+
+// sqlViewForbiddenKeywords 是视图语句中不允许出现的关键字：写入、修改结构、事务、访问其他库与递归查询
+var sqlViewForbiddenKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "UPSERT": true, "CREATE": true, "DROP": true, "ALTER": true,
+	"ATTACH": true, "DETACH": true, "PRAGMA": true, "VACUUM": true, "REINDEX": true, "ANALYZE": true,
+	"BEGIN": true, "COMMIT": true, "ROLLBACK": true, "SAVEPOINT": true, "RELEASE": true, "TRANSACTION": true,
+	"RECURSIVE": true, "TEMP": true, "TEMPORARY": true,
+}
+
+// sqlViewForbiddenFunctions 是视图语句中不允许调用的函数 (读写文件、加载扩展等)
+var sqlViewForbiddenFunctions = map[string]bool{
+	"load_extension": true, "readfile": true, "writefile": true, "edit": true, "fts3_tokenizer": true,
+	"zipfile": true, "sqlar_compress": true, "sqlar_uncompress": true,
+}
+
+type sqlTokenKind int
+
+const (
+	tokWord sqlTokenKind = iota
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokParam
+	tokPunct
+)
+
+type sqlToken struct {
+	kind       sqlTokenKind
+	text       string // 关键字与标识符为原文，带引号的标识符为去掉引号后的名称，参数为去掉冒号后的名称
+	start, end int
+}
+
+// isIdent 判断 token 是否可能是标识符 (表名、列名或别名)
+func (t sqlToken) isIdent() bool { return t.kind == tokWord || t.kind == tokQuotedIdent }
+
+func (t sqlToken) is(keyword string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, keyword)
+}
+
+// tokenizeSQLView 把视图语句切分为 token。注释、分号与 ? / @ / $ 形式的参数被拒绝
+func tokenizeSQLView(src string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(src)
+	// 以字节偏移记录位置，便于在原文中替换参数
+	offsets := make([]int, len(runes)+1)
+	for i, pos := 0, 0; i < len(runes); i++ {
+		offsets[i] = pos
+		pos += len(string(runes[i]))
+		offsets[i+1] = pos
+	}
+	isWordRune := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-', r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			return nil, fmt.Errorf("%w: 不允许使用注释", port.ErrInvalidSQLView)
+		case r == ';':
+			return nil, fmt.Errorf("%w: 只允许一条语句，不能包含分号", port.ErrInvalidSQLView)
+		case r == '?' || r == '@' || r == '$':
+			return nil, fmt.Errorf("%w: 参数只能以 :name 形式引用", port.ErrInvalidSQLView)
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			var text strings.Builder
+			for {
+				if j >= len(runes) {
+					return nil, fmt.Errorf("%w: 引号未闭合", port.ErrInvalidSQLView)
+				}
+				if runes[j] == closing {
+					// 引号内的同种引号以两个连续引号表示
+					if closing != ']' && j+1 < len(runes) && runes[j+1] == closing {
+						text.WriteRune(closing)
+						j += 2
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[j])
+				j++
+			}
+			kind := tokQuotedIdent
+			if r == '\'' {
+				kind = tokString
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: text.String(), start: offsets[i], end: offsets[j+1]})
+			i = j + 1
+		case r == ':' && i+1 < len(runes) && isWordRune(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokParam, text: string(runes[i+1 : j]), start: offsets[i], end: offsets[j]})
+			i = j
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (isWordRune(runes[j]) || runes[j] == '.' ||
+				((runes[j] == '+' || runes[j] == '-') && (runes[j-1] == 'e' || runes[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokNumber, text: string(runes[i:j]), start: offsets[i], end: offsets[j]})
+			i = j
+		case isWordRune(r):
+			j := i + 1
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokWord, text: string(runes[i:j]), start: offsets[i], end: offsets[j]})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{kind: tokPunct, text: string(r), start: offsets[i], end: offsets[i+1]})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// sqlView 是编译后的 SQL 视图表：参数已替换为 ?，argNames 是各个 ? 对应的参数名，
+// tables 是语句引用的物理表，只有含有全部这些表的库参与查询
+type sqlView struct {
+	name     string
+	body     string
+	argNames []string
+	params   map[string]domain.SQLViewParam
+	tables   []string
+}
+
+// compileSQLView 校验视图语句属于允许的安全子集并编译。name 是视图表的名称
+func compileSQLView(name string, cfg *domain.SQLViewConfig) (*sqlView, error) {
+	text := strings.TrimSpace(cfg.SQL)
+	if text == "" {
+		return nil, fmt.Errorf("%w: 语句不能为空", port.ErrInvalidSQLView)
+	}
+	if len(text) > maxSQLViewLength {
+		return nil, fmt.Errorf("%w: 语句长度超过 %d 字节", port.ErrInvalidSQLView, maxSQLViewLength)
+	}
+	tokens, err := tokenizeSQLView(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 || !(tokens[0].is("SELECT") || tokens[0].is("WITH")) {
+		return nil, fmt.Errorf("%w: 只允许 SELECT 语句 (可以以 WITH 开头)", port.ErrInvalidSQLView)
+	}
+
+	view := &sqlView{name: name, params: make(map[string]domain.SQLViewParam, len(cfg.Params))}
+	for _, p := range cfg.Params {
+		if p.Name == "" || strings.IndexFunc(p.Name, func(r rune) bool { return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0 {
+			return nil, fmt.Errorf("%w: 参数名 '%s' 只能包含字母、数字与下划线", port.ErrInvalidSQLView, p.Name)
+		}
+		if _, dup := view.params[p.Name]; dup {
+			return nil, fmt.Errorf("%w: 参数 '%s' 重复声明", port.ErrInvalidSQLView, p.Name)
+		}
+		switch p.Type {
+		case domain.SQLViewParamText, domain.SQLViewParamInteger, domain.SQLViewParamReal:
+		default:
+			return nil, fmt.Errorf("%w: 参数 '%s' 的类型 '%s' 无效，可选 text、integer 或 real", port.ErrInvalidSQLView, p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if _, err := coerceSQLViewParam(p, p.Default); err != nil {
+				return nil, fmt.Errorf("%w: 参数 '%s' 的默认值: %v", port.ErrInvalidSQLView, p.Name, err)
+			}
+		}
+		view.params[p.Name] = p
+	}
+
+	// 语句自身 WITH 子句定义的名称不是物理表
+	cteNames := make(map[string]bool)
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i].isIdent() && tokens[i+1].is("AS") && tokens[i+2].text == "(" && tokens[i+2].kind == tokPunct {
+			cteNames[strings.ToLower(tokens[i].text)] = true
+		}
+	}
+
+	used := make(map[string]bool)
+	tables := make(map[string]bool)
+	var body strings.Builder
+	last := 0
+	for i, tok := range tokens {
+		switch tok.kind {
+		case tokWord:
+			upper := strings.ToUpper(tok.text)
+			if sqlViewForbiddenKeywords[upper] {
+				return nil, fmt.Errorf("%w: 不允许使用 %s", port.ErrInvalidSQLView, upper)
+			}
+			if i+1 < len(tokens) && tokens[i+1].text == "(" && sqlViewForbiddenFunctions[strings.ToLower(tok.text)] {
+				return nil, fmt.Errorf("%w: 不允许调用函数 %s", port.ErrInvalidSQLView, tok.text)
+			}
+			// REPLACE 只能作为字符串函数使用，不能作为写入语句
+			if upper == "REPLACE" && (i+1 >= len(tokens) || tokens[i+1].text != "(") {
+				return nil, fmt.Errorf("%w: 不允许使用 REPLACE 语句", port.ErrInvalidSQLView)
+			}
+		case tokParam:
+			if _, declared := view.params[tok.text]; !declared {
+				return nil, fmt.Errorf("%w: 参数 ':%s' 未声明", port.ErrInvalidSQLView, tok.text)
+			}
+			used[tok.text] = true
+			body.WriteString(text[last:tok.start])
+			body.WriteString("?")
+			last = tok.end
+			view.argNames = append(view.argNames, tok.text)
+		}
+		if tok.isIdent() {
+			lower := strings.ToLower(tok.text)
+			if strings.HasPrefix(lower, innerPrefix) || strings.HasPrefix(lower, "sqlite_") {
+				return nil, fmt.Errorf("%w: 不允许访问内部的表或列 '%s'", port.ErrInvalidSQLView, tok.text)
+			}
+		}
+		// FROM 与 JOIN 之后 (以及 FROM 列表中逗号之后) 的名称是引用的表；括号开头的是子查询，其中的引用另行识别
+		if !(tok.is("FROM") || tok.is("JOIN")) {
+			continue
+		}
+		for j := i + 1; j < len(tokens) && tokens[j].isIdent(); {
+			ref := tokens[j].text
+			k := j + 1
+			if k+1 < len(tokens) && tokens[k].text == "." && tokens[k].kind == tokPunct {
+				if !strings.EqualFold(ref, "main") {
+					return nil, fmt.Errorf("%w: 不允许访问其他库 '%s'", port.ErrInvalidSQLView, ref)
+				}
+				ref = tokens[k+1].text
+				k += 2
+			}
+			if !cteNames[strings.ToLower(ref)] {
+				tables[ref] = true
+			}
+			if !tok.is("FROM") {
+				break
+			}
+			// 跳过别名，遇到逗号时继续读取下一个表
+			if k < len(tokens) && tokens[k].is("AS") {
+				k++
+			}
+			if k < len(tokens) && tokens[k].isIdent() && !isSQLClauseKeyword(tokens[k]) {
+				k++
+			}
+			if k+1 < len(tokens) && tokens[k].text == "," && tokens[k].kind == tokPunct {
+				j = k + 1
+				continue
+			}
+			break
+		}
+	}
+	body.WriteString(text[last:])
+	for _, p := range cfg.Params {
+		if !used[p.Name] {
+			return nil, fmt.Errorf("%w: 参数 '%s' 未在语句中使用", port.ErrInvalidSQLView, p.Name)
+		}
+	}
+	for table := range tables {
+		if strings.EqualFold(table, name) {
+			return nil, fmt.Errorf("%w: 视图不能引用与自身同名的表 '%s'", port.ErrInvalidSQLView, table)
+		}
+		view.tables = append(view.tables, table)
+	}
+	sort.Strings(view.tables)
+	view.body = body.String()
+	return view, nil
+}
+
+// isSQLClauseKeyword 判断 FROM 列表中表名之后的单词是否是子句关键字 (而不是省略了 AS 的别名)
+func isSQLClauseKeyword(t sqlToken) bool {
+	if t.kind != tokWord {
+		return false
+	}
+	switch strings.ToUpper(t.text) {
+	case "WHERE", "GROUP", "ORDER", "LIMIT", "HAVING", "UNION", "EXCEPT", "INTERSECT", "JOIN", "LEFT", "RIGHT",
+		"FULL", "INNER", "OUTER", "CROSS", "NATURAL", "ON", "USING", "WINDOW", "INDEXED", "NOT":
+		return true
+	}
+	return false
+}
+
+// coerceSQLViewParam 把参数值转换为声明的类型
+func coerceSQLViewParam(p domain.SQLViewParam, value interface{}) (interface{}, error) {
+	switch p.Type {
+	case domain.SQLViewParamInteger:
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("'%v' 不是整数", v)
+			}
+			return int64(v), nil
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' 不是整数", v)
+			}
+			return n, nil
+		}
+	case domain.SQLViewParamReal:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' 不是数字", v)
+			}
+			return f, nil
+		}
+	case domain.SQLViewParamText:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64, int, int64, bool:
+			return fmt.Sprint(v), nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的值 %v", value)
+}
+
+// bind 按参数在语句中出现的顺序解析参数值，未传的参数使用默认值
+func (v *sqlView) bind(values map[string]interface{}) ([]any, error) {
+	resolved := make(map[string]interface{}, len(v.params))
+	for name, raw := range values {
+		p, ok := v.params[name]
+		if !ok {
+			return nil, fmt.Errorf("SQL 视图表 '%s' 没有参数 '%s'", v.name, name)
+		}
+		value, err := coerceSQLViewParam(p, raw)
+		if err != nil {
+			return nil, fmt.Errorf("SQL 视图表 '%s' 的参数 '%s': %w", v.name, name, err)
+		}
+		resolved[name] = value
+	}
+	args := make([]any, len(v.argNames))
+	for i, name := range v.argNames {
+		value, ok := resolved[name]
+		if !ok {
+			p := v.params[name]
+			if p.Default == nil {
+				return nil, fmt.Errorf("SQL 视图表 '%s' 缺少参数 '%s'", v.name, name)
+			}
+			value, _ = coerceSQLViewParam(p, p.Default)
+		}
+		args[i] = value
+	}
+	return args, nil
+}
+
+// wrap 把查询语句包装为以视图为公用表表达式的语句，视图的参数排在语句参数之前。v 为 nil 时原样返回
+func (v *sqlView) wrap(query string, queryArgs []any, viewArgs []any) (string, []any) {
+	if v == nil {
+		return query, queryArgs
+	}
+	combined := make([]any, 0, len(viewArgs)+len(queryArgs))
+	combined = append(combined, viewArgs...)
+	combined = append(combined, queryArgs...)
+	return fmt.Sprintf("WITH %s AS (%s) %s", quoteIdent(v.name), v.body, query), combined
+}
+
+// libHasTables 判断库中是否存在全部指定的表
+func (m *Manager) libHasTables(db *sql.DB, tables []string) bool {
+	for _, table := range tables {
+		if !m.libHasTable(db, table) {
+			return false
+		}
+	}
+	return true
+}
+
+var _ port.SQLViewValidator = (*Manager)(nil)
+
+// ValidateSQLView 实现 port.SQLViewValidator：校验语句属于安全子集，并在含有视图引用的全部表的每个库中
+// 试执行 (不读取行)，返回按库名排序最靠前的库中视图的列名。未传的参数以默认值或 NULL 代入
+func (m *Manager) ValidateSQLView(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) ([]string, error) {
+	view, err := compileSQLView(tableName, cfg)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, len(view.argNames))
+	for i, name := range view.argNames {
+		if p := view.params[name]; p.Default != nil {
+			args[i], _ = coerceSQLViewParam(p, p.Default)
+		}
+	}
+
+	m.mu.RLock()
+	libs, ok := m.group[bizName]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	libNames := make([]string, 0, len(libs))
+	for libName, db := range libs {
+		if m.libHasTables(db, view.tables) {
+			libNames = append(libNames, libName)
+		}
+	}
+	if len(libNames) == 0 {
+		return nil, fmt.Errorf("%w: 业务组 '%s' 中没有库包含视图引用的全部表 %v", port.ErrInvalidSQLView, bizName, view.tables)
+	}
+	sort.Strings(libNames)
+
+	var columns []string
+	for _, libName := range libNames {
+		query, queryArgs := view.wrap(fmt.Sprintf("SELECT * FROM %s LIMIT 0", quoteIdent(tableName)), nil, args)
+		rows, err := libs[libName].QueryContext(ctx, query, queryArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 在库 '%s' 中执行失败: %v", port.ErrInvalidSQLView, libName, err)
+		}
+		cols, err := rows.Columns()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: 读取库 '%s' 中视图的列失败: %v", port.ErrInvalidSQLView, libName, err)
+		}
+		if columns == nil {
+			columns = cols
+		}
+	}
+	return columns, nil
+}
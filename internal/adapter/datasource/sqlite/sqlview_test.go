@@ -0,0 +1,177 @@
+// file: internal/adapter/datasource/sqlite/sqlview_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSQLView(t *testing.T) {
+	view, err := compileSQLView("letters_by_year", &domain.SQLViewConfig{
+		SQL: `SELECT l.id, l.title, p.name AS sender FROM letters AS l JOIN people p ON p.id = l.sender_id
+			WHERE l.year >= :from AND l.year <= :to AND l.title <> ':not_a_param' AND l.year <> :from`,
+		Params: []domain.SQLViewParam{
+			{Name: "from", Type: domain.SQLViewParamInteger},
+			{Name: "to", Type: domain.SQLViewParamInteger, Default: float64(2100)},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"letters", "people"}, view.tables)
+	assert.Equal(t, []string{"from", "to", "from"}, view.argNames)
+	assert.Contains(t, view.body, "l.year >= ? AND l.year <= ?")
+	assert.Contains(t, view.body, "':not_a_param'", "字符串中的冒号不是参数")
+
+	args, err := view.bind(map[string]interface{}{"from": "1900"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1900), int64(2100), int64(1900)}, args)
+	_, err = view.bind(map[string]interface{}{})
+	assert.ErrorContains(t, err, "缺少参数 'from'")
+	_, err = view.bind(map[string]interface{}{"from": 1.5})
+	assert.Error(t, err)
+	_, err = view.bind(map[string]interface{}{"from": 1, "nope": 1})
+	assert.Error(t, err)
+
+	cte, err := compileSQLView("v", &domain.SQLViewConfig{SQL: `WITH recent AS (SELECT * FROM letters) SELECT * FROM recent, people`})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"letters", "people"}, cte.tables, "公用表表达式的名称不是物理表")
+
+	for _, bad := range []string{
+		"",
+		"DELETE FROM letters",
+		"SELECT * FROM letters; DROP TABLE letters",
+		"SELECT * FROM letters -- 注释",
+		"SELECT * FROM letters /* 注释 */",
+		"SELECT * FROM letters WHERE id = ?",
+		"SELECT * FROM letters WHERE id = @id",
+		"WITH RECURSIVE r(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM r) SELECT n FROM r",
+		"SELECT load_extension('x')",
+		"SELECT readfile('/etc/passwd')",
+		"SELECT * FROM sqlite_master",
+		"SELECT * FROM _archiveaegis_internal_fts_letters",
+		"SELECT * FROM other.letters",
+		"SELECT * FROM v",
+		"SELECT * FROM letters WHERE id = :undeclared",
+		"SELECT * FROM letters WHERE title = 'unterminated",
+	} {
+		_, err := compileSQLView("v", &domain.SQLViewConfig{SQL: bad})
+		assert.True(t, errors.Is(err, port.ErrInvalidSQLView), "应拒绝: %q (%v)", bad, err)
+	}
+	_, err = compileSQLView("v", &domain.SQLViewConfig{SQL: "SELECT replace(title, 'a', 'b') FROM letters"})
+	assert.NoError(t, err, "REPLACE 作为字符串函数是允许的")
+	_, err = compileSQLView("v", &domain.SQLViewConfig{
+		SQL:    "SELECT * FROM letters",
+		Params: []domain.SQLViewParam{{Name: "unused", Type: domain.SQLViewParamText}},
+	})
+	assert.True(t, errors.Is(err, port.ErrInvalidSQLView), "声明但未使用的参数应被拒绝")
+	_, err = compileSQLView("v", &domain.SQLViewConfig{
+		SQL:    "SELECT * FROM letters WHERE id = :id",
+		Params: []domain.SQLViewParam{{Name: "id", Type: "blob"}},
+	})
+	assert.True(t, errors.Is(err, port.ErrInvalidSQLView), "未知的参数类型应被拒绝")
+}
+
+func TestQuery_SQLView(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, title TEXT, year INTEGER, sender_id INTEGER);`,
+		`CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT);`,
+		`INSERT INTO people (id, name) VALUES (1, '鲁迅'), (2, '胡适');`,
+		`INSERT INTO letters (id, title, year, sender_id) VALUES (1, '致许广平', 1925, 1), (2, '致钱玄同', 1918, 1), (3, '致陈独秀', 1920, 2);`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT);`,
+		`INSERT INTO people (id, name) VALUES (1, '周作人');`,
+	)
+	viewCfg := &domain.SQLViewConfig{
+		SQL:    "SELECT l.id, l.title, p.name AS sender FROM letters l JOIN people p ON p.id = l.sender_id WHERE l.year >= :from",
+		Params: []domain.SQLViewParam{{Name: "from", Type: domain.SQLViewParamInteger, Default: float64(1900)}},
+	}
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters_with_sender": {
+				TableName:    "letters_with_sender",
+				IsSearchable: true,
+				AllowDelete:  true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"title":  {FieldName: "title", IsReturnable: true, IsSearchable: true},
+					"sender": {FieldName: "sender", IsReturnable: true, IsSearchable: true},
+				},
+				SQLView: viewCfg,
+			},
+			"people": {TableName: "people", IsSearchable: true, Fields: map[string]domain.FieldSetting{
+				"name": {FieldName: "name", IsReturnable: true},
+			}},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{
+		"letters": {"id", "sender_id", "title", "year"}, "people": {"id", "name"},
+	}}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"people": {"id", "name"}}}
+
+	query := func(q map[string]interface{}) (map[string]interface{}, error) {
+		q["table"] = "letters_with_sender"
+		result, err := manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: q})
+		if err != nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+
+	data, err := query(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, data["total"], "缺少 letters 表的库 b 不参与查询")
+	assert.NotContains(t, data, port.ResultWarningsKey)
+
+	data, err = query(map[string]interface{}{
+		port.QuerySQLParamsKey: map[string]interface{}{"from": float64(1920)},
+		"filters":              []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, data["total"])
+	row := data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "致许广平", row["title"])
+	assert.Equal(t, "a", row["__lib"])
+
+	_, err = query(map[string]interface{}{port.QuerySQLParamsKey: map[string]interface{}{"from": "abc"}})
+	assert.Error(t, err)
+	_, err = query(map[string]interface{}{
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": "x", "pinyin": true}},
+	})
+	assert.Error(t, err, "视图表不支持拼音检索")
+
+	_, err = manager.Query(ctx, port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "people", port.QuerySQLParamsKey: map[string]interface{}{"from": 1},
+	}})
+	assert.Error(t, err, "普通表不接受视图参数")
+
+	_, err = manager.Mutate(ctx, port.MutateRequest{BizName: "archive", Operation: "delete", Payload: map[string]interface{}{
+		"table_name": "letters_with_sender",
+		"filters":    []interface{}{map[string]interface{}{"field": "id", "value": 1}},
+	}})
+	assert.True(t, errors.Is(err, port.ErrPermissionDenied), "视图表只读")
+
+	columns, err := manager.ValidateSQLView(ctx, "archive", "letters_with_sender", viewCfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "title", "sender"}, columns)
+	_, err = manager.ValidateSQLView(ctx, "archive", "v", &domain.SQLViewConfig{SQL: "SELECT missing FROM letters"})
+	assert.True(t, errors.Is(err, port.ErrInvalidSQLView))
+	_, err = manager.ValidateSQLView(ctx, "archive", "v", &domain.SQLViewConfig{SQL: "SELECT * FROM nowhere"})
+	assert.True(t, errors.Is(err, port.ErrInvalidSQLView))
+}
@@ -0,0 +1,255 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/stats.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// statsDistinctUnionLimit 是多库业务组中精确合并基数的上限：各库不同取值数之和不超过该值时，
+// 读取各库的全部不同取值求并集得到精确基数；超过时以各库基数之和作为上界，并标记为不精确
+const statsDistinctUnionLimit = 1000
+
+// columnStats 累计单个字段在所有库中的统计
+type columnStats struct {
+	nulls       int64
+	cardinality int64
+	min, max    interface{}
+	libs        []string // 包含该列的库，用于合并不同取值
+}
+
+// queryStats 统计表中每个可返回字段的基数、最小值、最大值与 NULL 数量，供界面选择过滤控件与网关规划查询。
+// 只统计可返回的字段，因此结果可以公开；某个库的表中缺少该列时，该库的全部记录均计为 NULL。
+func (m *Manager) queryStats(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	tableName, _ := queryMap["table"].(string)
+	if tableName == "" {
+		return nil, fmt.Errorf("无效请求: stats 模式必须包含 'table' 字符串字段")
+	}
+
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableAdminConfig, exists := bizAdminConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	fields := make([]string, 0, len(tableAdminConfig.Fields))
+	for fieldName, setting := range tableAdminConfig.Fields {
+		if setting.IsReturnable {
+			fields = append(fields, fieldName)
+		}
+	}
+	sort.Strings(fields)
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libColumns := make(map[string][]string, len(dbInstances))
+	for libName, db := range dbInstances {
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			if columns, tableExists := schema.allTablesAndColumns[tableName]; tableExists {
+				libColumns[libName] = columns
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	libNames := make([]string, 0, len(libColumns))
+	for libName := range libColumns {
+		libNames = append(libNames, libName)
+	}
+	sort.Strings(libNames)
+
+	var total int64
+	stats := make(map[string]*columnStats, len(fields))
+	for _, f := range fields {
+		stats[f] = &columnStats{}
+	}
+	for _, libName := range libNames {
+		present := make(map[string]bool, len(libColumns[libName]))
+		for _, col := range libColumns[libName] {
+			present[col] = true
+		}
+		libTotal, err := collectColumnStats(ctx, dbInstances[libName], libName, tableName, fields, present, stats)
+		if err != nil {
+			return nil, fmt.Errorf("统计库 '%s/%s' 表 '%s' 的字段失败: %w", bizName, libName, tableName, err)
+		}
+		total += libTotal
+	}
+
+	// 结果需要经 structpb 传输，因此只能使用 map / []interface{} 等通用类型
+	items := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		s := stats[f]
+		exact := len(s.libs) <= 1
+		if !exact && s.cardinality <= statsDistinctUnionLimit {
+			n, err := unionDistinctCount(ctx, dbInstances, s.libs, tableName, f)
+			if err != nil {
+				return nil, fmt.Errorf("合并表 '%s' 字段 '%s' 的不同取值失败: %w", tableName, f, err)
+			}
+			s.cardinality, exact = n, true
+		}
+		var nullRatio float64
+		if total > 0 {
+			nullRatio = float64(s.nulls) / float64(total)
+		}
+		items = append(items, map[string]interface{}{
+			"field":             f,
+			"nulls":             s.nulls,
+			"null_ratio":        nullRatio,
+			"cardinality":       s.cardinality,
+			"cardinality_exact": exact,
+			"min":               s.min,
+			"max":               s.max,
+		})
+	}
+	return &port.QueryResult{
+		Data: map[string]interface{}{
+			"table":  tableName,
+			"total":  total,
+			"fields": items,
+		},
+		Source: m.Type(),
+	}, nil
+}
+
+// collectColumnStats 在单个库中以一条聚合查询统计各字段，并合并到 stats 中，返回该库的记录总数
+func collectColumnStats(ctx context.Context, db *sql.DB, libName, tableName string, fields []string, present map[string]bool, stats map[string]*columnStats) (int64, error) {
+	var existing []string
+	selects := []string{"COUNT(*)"}
+	for _, f := range fields {
+		if !present[f] {
+			continue
+		}
+		existing = append(existing, f)
+		col := quoteIdent(f)
+		selects = append(selects,
+			fmt.Sprintf("COUNT(%s)", col),
+			fmt.Sprintf("COUNT(DISTINCT %s)", col),
+			fmt.Sprintf("MIN(%s)", col),
+			fmt.Sprintf("MAX(%s)", col),
+		)
+	}
+
+	values := make([]interface{}, len(selects))
+	ptrs := make([]interface{}, len(selects))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), quoteIdent(tableName))
+	if err := db.QueryRowContext(ctx, query).Scan(ptrs...); err != nil {
+		return 0, err
+	}
+
+	total := toInt64(values[0])
+	for i, f := range existing {
+		s := stats[f]
+		base := 1 + 4*i
+		s.nulls += total - toInt64(values[base])
+		if n := toInt64(values[base+1]); n > 0 {
+			s.cardinality += n
+			s.libs = append(s.libs, libName)
+		}
+		if v := statsValue(values[base+2]); v != nil && (s.min == nil || compareStatsValues(v, s.min) < 0) {
+			s.min = v
+		}
+		if v := statsValue(values[base+3]); v != nil && (s.max == nil || compareStatsValues(v, s.max) > 0) {
+			s.max = v
+		}
+	}
+	for _, f := range fields {
+		if !present[f] {
+			stats[f].nulls += total
+		}
+	}
+	return total, nil
+}
+
+// unionDistinctCount 读取各库中字段的全部不同取值，返回并集的大小
+func unionDistinctCount(ctx context.Context, dbInstances map[string]*sql.DB, libs []string, tableName, field string) (int64, error) {
+	seen := make(map[string]struct{})
+	for _, libName := range libs {
+		rows, err := dbInstances[libName].QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL",
+			quoteIdent(field), quoteIdent(tableName), quoteIdent(field)))
+		if err != nil {
+			return 0, err
+		}
+		for rows.Next() {
+			var v interface{}
+			if err := rows.Scan(&v); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			// 同一取值在不同库中的类型可能不同 (如 1 与 1.0)，按 SQLite 的比较语义以文本形式归并
+			seen[fmt.Sprintf("%v", statsValue(v))] = struct{}{}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(seen)), nil
+}
+
+// statsValue 把扫描得到的值转换为可经 structpb 传输的类型，BLOB 不参与最小值与最大值的统计
+func statsValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return nil
+	case int64, float64, string:
+		return val
+	case nil:
+		return nil
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// compareStatsValues 按 SQLite 的排序规则比较两个值：数值小于文本，数值之间按大小、文本之间按字节比较
+func compareStatsValues(a, b interface{}) int {
+	af, aNum := statsNumber(a)
+	bf, bNum := statsNumber(b)
+	switch {
+	case aNum && bNum:
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+func statsNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
+
+func toInt64(v interface{}) int64 {
+	switch val := v.(type) {
+	case int64:
+		return val
+	case float64:
+		return int64(val)
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+// file: internal/adapter/datasource/sqlite/stats_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_StatsMode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, year INTEGER, note TEXT);`,
+		`INSERT INTO letters (id, sender, year, note) VALUES (1, '鲁迅', 1925, 'x'), (2, NULL, 1930, 'y'), (3, '胡适', NULL, 'z');`,
+	)
+	// b 库的表缺少 year 列，其记录应全部计为 NULL；'鲁迅' 在两个库中都出现，合并后只计一次
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, note TEXT);`,
+		`INSERT INTO letters (id, sender, note) VALUES (1, '鲁迅', 'w'), (2, '周作人', 'v');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName: "archive",
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName: "letters",
+				Fields: map[string]domain.FieldSetting{
+					"sender": {FieldName: "sender", IsReturnable: true},
+					"year":   {FieldName: "year", IsReturnable: true},
+					"note":   {FieldName: "note", IsReturnable: false},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	manager.dbSchemaCache[dbA] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "note", "sender", "year"}}}
+	manager.dbSchemaCache[dbB] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"letters": {"id", "note", "sender"}}}
+
+	result, err := manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeStats, "table": "letters"},
+	})
+	require.NoError(t, err)
+	_, err = structpb.NewStruct(result.Data)
+	require.NoError(t, err, "结果必须可以被 structpb 序列化")
+
+	assert.Equal(t, int64(5), result.Data["total"])
+	fields := result.Data["fields"].([]interface{})
+	require.Len(t, fields, 2, "不可返回的字段不参与统计")
+
+	sender := fields[0].(map[string]interface{})
+	assert.Equal(t, "sender", sender["field"])
+	assert.Equal(t, int64(1), sender["nulls"])
+	assert.InDelta(t, 0.2, sender["null_ratio"], 1e-9)
+	assert.Equal(t, int64(3), sender["cardinality"])
+	assert.Equal(t, true, sender["cardinality_exact"])
+
+	year := fields[1].(map[string]interface{})
+	assert.Equal(t, int64(3), year["nulls"])
+	assert.Equal(t, int64(2), year["cardinality"])
+	assert.Equal(t, int64(1925), year["min"])
+	assert.Equal(t, int64(1930), year["max"])
+
+	_, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeStats, "table": "missing"},
+	})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+}
+
+func TestCompareStatsValues(t *testing.T) {
+	assert.Equal(t, -1, compareStatsValues(int64(2), 10.5))
+	assert.Equal(t, -1, compareStatsValues(int64(99), "a"), "数值排在文本之前")
+	assert.Equal(t, 1, compareStatsValues("b", "a"))
+	assert.Equal(t, 0, compareStatsValues(int64(3), 3.0))
+}
@@ -0,0 +1,247 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/storage.go
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 业务组目录可以放在网络存储 (NFS、SMB 或由 S3 同步的本地目录) 上。这类存储上 fsnotify 往往收不到
+// 其他主机产生的事件，因此变更检测方式按业务组目录选择，由目录下的 storage.json 配置:
+//
+//	{"watch": {"mode": "poll", "interval": "30s"},
+//	 "s3": {"endpoint": "https://minio.example.org", "region": "us-east-1", "bucket": "archives", "prefix": "letters/", "interval": "5m", "path_style": true}}
+//
+// 没有 storage.json 时不检测变更，与此前的行为一致；库文件的替换仍由管理接口完成。
+const (
+	// StorageConfigFilename 是业务组目录下的存储配置文件
+	StorageConfigFilename = "storage.json"
+
+	// WatchModeOff 不检测库文件的变更
+	WatchModeOff = "off"
+	// WatchModeFSNotify 依赖操作系统的文件事件，适合本地磁盘
+	WatchModeFSNotify = "fsnotify"
+	// WatchModePoll 定期比较文件的大小与修改时间，变化时再比较内容的 SHA-256，适合网络存储
+	WatchModePoll = "poll"
+
+	defaultPollInterval = 30 * time.Second
+	minPollInterval     = time.Second
+)
+
+// Duration 是 storage.json 中以 "30s"、"5m" 形式书写的时长
+type Duration time.Duration
+
+// UnmarshalJSON 解析 time.ParseDuration 接受的字符串
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("时长必须是 \"30s\" 形式的字符串: %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// WatchOptions 选择库文件变更的检测方式
+type WatchOptions struct {
+	// Mode 为 off (默认)、fsnotify 或 poll
+	Mode string `json:"mode"`
+	// Interval 是 poll 模式的检测间隔，默认为 30 秒
+	Interval Duration `json:"interval"`
+}
+
+// StorageOptions 是业务组目录下 storage.json 的内容
+type StorageOptions struct {
+	Watch WatchOptions `json:"watch"`
+	// S3 不为空时定期从对象存储同步库文件到业务组目录，见 S3SyncOptions
+	S3 *S3SyncOptions `json:"s3,omitempty"`
+}
+
+// LoadStorageOptions 读取并校验业务组目录下的 storage.json，文件不存在时返回默认配置
+func LoadStorageOptions(bizDir string) (StorageOptions, error) {
+	var opts StorageOptions
+	data, err := os.ReadFile(filepath.Join(bizDir, StorageConfigFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		opts.Watch.Mode = WatchModeOff
+		return opts, nil
+	}
+	if err != nil {
+		return opts, err
+	}
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return opts, fmt.Errorf("解析 %s 失败: %w", StorageConfigFilename, err)
+	}
+	switch opts.Watch.Mode {
+	case "":
+		opts.Watch.Mode = WatchModeOff
+	case WatchModeOff, WatchModeFSNotify:
+	case WatchModePoll:
+		if opts.Watch.Interval == 0 {
+			opts.Watch.Interval = Duration(defaultPollInterval)
+		}
+		if time.Duration(opts.Watch.Interval) < minPollInterval {
+			return opts, fmt.Errorf("%s: watch.interval 不能小于 %s", StorageConfigFilename, minPollInterval)
+		}
+	default:
+		return opts, fmt.Errorf("%s: 未知的 watch.mode '%s'，可选 off、fsnotify 或 poll", StorageConfigFilename, opts.Watch.Mode)
+	}
+	if opts.S3 != nil {
+		if err := opts.S3.validate(); err != nil {
+			return opts, fmt.Errorf("%s: %w", StorageConfigFilename, err)
+		}
+	}
+	return opts, nil
+}
+
+// StartStorage 按业务组目录下的 storage.json 启动库文件的变更检测与对象存储同步，应在 InitForBiz 之后调用。
+// 配置了 S3 时先完成一次同步再返回，后台任务在 Close 时停止
+func (m *Manager) StartStorage(bizName string) error {
+	m.mu.RLock()
+	root := m.root
+	m.mu.RUnlock()
+	if root == "" {
+		return errors.New("StartStorage 必须在 InitForBiz 之后调用")
+	}
+	bizDir := filepath.Join(root, bizName)
+	opts, err := LoadStorageOptions(bizDir)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.storageMu.Lock()
+	m.storageStops = append(m.storageStops, cancel)
+	m.storageMu.Unlock()
+
+	if opts.S3 != nil {
+		syncer, err := newS3Syncer(m, bizName, *opts.S3)
+		if err != nil {
+			return err
+		}
+		if err := syncer.syncOnce(ctx); err != nil {
+			// 对象存储暂时不可用时继续使用本地已有的库文件
+			log.Printf("警告: [DBManager] 业务组 '%s' 首次从 S3 同步失败，将使用本地库文件并稍后重试: %v", bizName, err)
+		}
+		go syncer.run(ctx)
+	}
+
+	switch opts.Watch.Mode {
+	case WatchModeFSNotify:
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("创建 fsnotify watcher 失败: %w", err)
+		}
+		if err := watcher.Add(bizDir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("监视业务目录 '%s' 失败: %w", bizDir, err)
+		}
+		go m.runFsWatcher(ctx, watcher)
+	case WatchModePoll:
+		go m.pollDir(ctx, bizDir, time.Duration(opts.Watch.Interval))
+	}
+	log.Printf("信息: [DBManager] 业务组 '%s' 的变更检测方式: %s，S3 同步: %t", bizName, opts.Watch.Mode, opts.S3 != nil)
+	return nil
+}
+
+// stopStorage 停止 StartStorage 启动的全部后台任务
+func (m *Manager) stopStorage() {
+	m.storageMu.Lock()
+	defer m.storageMu.Unlock()
+	for _, stop := range m.storageStops {
+		stop()
+	}
+	m.storageStops = nil
+}
+
+// fileFingerprint 记录轮询时库文件的状态。hash 只在大小或修改时间变化时重新计算
+type fileFingerprint struct {
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// pollDir 定期扫描 dir 下的 .db 文件。大小或修改时间变化时比较内容的 SHA-256，
+// 只有内容确实变化 (或文件新增、删除) 才重新加载；网络存储上属性缓存引起的时间抖动不会触发重载
+func (m *Manager) pollDir(ctx context.Context, dir string, interval time.Duration) {
+	known := make(map[string]fileFingerprint)
+	m.pollOnce(dir, known, false)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.pollOnce(dir, known, true)
+		}
+	}
+}
+
+// pollOnce 扫描一次 dir 并更新 known。notify 为 false 时只建立基线
+func (m *Manager) pollOnce(dir string, known map[string]fileFingerprint, notify bool) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	if err != nil {
+		log.Printf("警告: [DBManager] 轮询目录 '%s' 失败: %v", dir, err)
+		return
+	}
+	seen := make(map[string]bool, len(files))
+	for _, path := range files {
+		path = filepath.Clean(path)
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		previous, existed := known[path]
+		if existed && previous.size == info.Size() && previous.modTime.Equal(info.ModTime()) {
+			continue
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			log.Printf("警告: [DBManager] 计算库文件 '%s' 的摘要失败: %v", path, err)
+			continue
+		}
+		known[path] = fileFingerprint{size: info.Size(), modTime: info.ModTime(), hash: hash}
+		if notify && (!existed || previous.hash != hash) {
+			log.Printf("信息: [DBManager Poll] 检测到库文件 '%s' 的内容变化", path)
+			m.scheduleReload(path)
+		}
+	}
+	for path := range known {
+		if !seen[path] {
+			delete(known, path)
+			if notify {
+				log.Printf("信息: [DBManager Poll] 检测到库文件 '%s' 已删除", path)
+				m.scheduleReload(path)
+			}
+		}
+	}
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
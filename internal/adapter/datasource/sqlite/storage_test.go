@@ -0,0 +1,220 @@
+// file: internal/adapter/datasource/sqlite/storage_test.go
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStorageOptions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StorageConfigFilename), []byte(content), 0o644))
+	}
+
+	opts, err := LoadStorageOptions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, WatchModeOff, opts.Watch.Mode, "没有 storage.json 时不检测变更")
+	assert.Nil(t, opts.S3)
+
+	write(`{"watch": {"mode": "poll"}}`)
+	opts, err = LoadStorageOptions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Duration(defaultPollInterval), opts.Watch.Interval)
+
+	write(`{"watch": {"mode": "poll", "interval": "10s"}, "s3": {"region": "us-east-1", "bucket": "archives", "prefix": "letters/"}}`)
+	opts, err = LoadStorageOptions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Duration(10*time.Second), opts.Watch.Interval)
+	require.NotNil(t, opts.S3)
+	assert.Equal(t, "archives", opts.S3.Bucket)
+	assert.Equal(t, Duration(defaultS3SyncInterval), opts.S3.Interval)
+
+	for _, bad := range []string{
+		`{"watch": {"mode": "inotify"}}`,
+		`{"watch": {"mode": "poll", "interval": "100ms"}}`,
+		`{"watch": {"mode": "poll", "interval": 30}}`,
+		`{"s3": {"region": "us-east-1"}}`,
+		`{"s3": {"region": "us-east-1", "bucket": "b", "interval": "1s"}}`,
+		`{"watch": `,
+	} {
+		write(bad)
+		_, err := LoadStorageOptions(dir)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestPollOnce_DetectsContentChanges(t *testing.T) {
+	manager, bizDir := newCompactManager(t)
+	known := make(map[string]fileFingerprint)
+	pending := func() bool {
+		manager.eventTimersMu.Lock()
+		defer manager.eventTimersMu.Unlock()
+		n := len(manager.eventTimers)
+		for path, timer := range manager.eventTimers {
+			timer.Stop()
+			delete(manager.eventTimers, path)
+		}
+		return n > 0
+	}
+
+	manager.pollOnce(bizDir, known, false)
+	assert.Len(t, known, 2)
+	assert.False(t, pending(), "建立基线时不触发重载")
+
+	path := filepath.Join(bizDir, "a.db")
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+	manager.pollOnce(bizDir, known, true)
+	assert.False(t, pending(), "只有修改时间变化时不触发重载")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write(make([]byte, 4096))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	manager.pollOnce(bizDir, known, true)
+	assert.True(t, pending(), "内容变化时触发重载")
+
+	require.NoError(t, os.Remove(filepath.Join(bizDir, "b.db")))
+	manager.pollOnce(bizDir, known, true)
+	assert.True(t, pending(), "文件删除时触发重载")
+	assert.Len(t, known, 1)
+}
+
+// fakeS3 是只支持 ListObjectsV2 与 GetObject 的路径风格 S3 服务
+type fakeS3 struct {
+	objects map[string][]byte
+	etags   map[string]string
+	gets    atomic.Int32
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/archives/")
+	if r.URL.Path == "/archives/" && r.URL.Query().Get("list-type") == "2" {
+		prefix := r.URL.Query().Get("prefix")
+		var b strings.Builder
+		b.WriteString(`<ListBucketResult>`)
+		for k, data := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				fmt.Fprintf(&b, `<Contents><Key>%s</Key><ETag>"%s"</ETag><Size>%d</Size></Contents>`, k, f.etags[k], len(data))
+			}
+		}
+		b.WriteString(`<IsTruncated>false</IsTruncated></ListBucketResult>`)
+		_, _ = w.Write([]byte(b.String()))
+		return
+	}
+	data, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`))
+		return
+	}
+	f.gets.Add(1)
+	_, _ = w.Write(data)
+}
+
+func TestS3Syncer_SyncOnce(t *testing.T) {
+	ctx := context.Background()
+	manager, bizDir := newCompactManager(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	sourceDir := t.TempDir()
+	fresh := createTestDB(t, sourceDir, "fresh.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT)`,
+		`INSERT INTO letters (id, sender) VALUES (1, '鲁迅'), (2, '许广平')`,
+	)
+	_, err := fresh.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	require.NoError(t, err)
+	require.NoError(t, fresh.Close())
+	data, err := os.ReadFile(filepath.Join(sourceDir, "fresh.db"))
+	require.NoError(t, err)
+
+	fake := &fakeS3{
+		objects: map[string][]byte{
+			"library/a.db":         data,
+			"library/c.db":         data,
+			"library/old/d.db":     data,
+			"library/notes.txt":    []byte("说明"),
+			"library/.hidden.db":   data,
+			"library/corrupted.db": []byte("这不是数据库文件"),
+		},
+		etags: map[string]string{"library/a.db": "e1", "library/c.db": "e1", "library/corrupted.db": "e1"},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	opts := S3SyncOptions{Prefix: "library/"}
+	opts.Endpoint, opts.Region, opts.Bucket, opts.PathStyle = server.URL, "us-east-1", "archives", true
+	require.NoError(t, opts.validate())
+	syncer, err := newS3Syncer(manager, "library", opts)
+	require.NoError(t, err)
+
+	err = syncer.syncOnce(ctx)
+	assert.Error(t, err, "损坏的库文件同步失败")
+	assert.Equal(t, int32(3), fake.gets.Load(), "只下载前缀下直接存放的 .db 对象")
+	assert.NoFileExists(t, filepath.Join(bizDir, "d.db"))
+	assert.NoFileExists(t, filepath.Join(bizDir, "corrupted.db"))
+	assert.NoFileExists(t, filepath.Join(bizDir, "corrupted"+s3DownloadSuffix), "失败时清理下载的临时文件")
+	assert.FileExists(t, filepath.Join(bizDir, "b.db"), "对象存储中没有的库保留")
+
+	manager.mu.RLock()
+	a, c := manager.group["library"]["a"], manager.group["library"]["c"]
+	_, hasLetters := manager.schema["library"]["letters"]
+	manager.mu.RUnlock()
+	require.NotNil(t, a)
+	require.NotNil(t, c, "对象存储中新增的库被加载")
+	var n int
+	require.NoError(t, a.QueryRow(`SELECT COUNT(*) FROM letters`).Scan(&n))
+	assert.Equal(t, 2, n, "同步后的连接指向新库")
+	assert.True(t, hasLetters)
+	entries, err := filepath.Glob(filepath.Join(bizDir, replaceBackupPrefix+"*"))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "同步替换不保留备份")
+
+	delete(fake.objects, "library/corrupted.db")
+	require.NoError(t, syncer.syncOnce(ctx))
+	assert.Equal(t, int32(3), fake.gets.Load(), "ETag 未变化时不重新下载")
+
+	fake.etags["library/a.db"] = "e2"
+	restarted, err := newS3Syncer(manager, "library", opts)
+	require.NoError(t, err)
+	require.NoError(t, restarted.syncOnce(ctx))
+	assert.Equal(t, int32(4), fake.gets.Load(), "同步状态在重启后保留，只下载变化的对象")
+}
+
+func TestS3LibName(t *testing.T) {
+	cases := map[string]string{
+		"letters/a.db":     "a",
+		"letters/a.db.bak": "",
+		"letters/sub/a.db": "",
+		"letters/.a.db":    "",
+		"letters/.db":      "",
+		"other/a.db":       "",
+	}
+	for key, want := range cases {
+		lib, ok := s3LibName("letters/", key)
+		assert.Equal(t, want != "", ok, key)
+		assert.Equal(t, want, lib, key)
+	}
+	lib, ok := s3LibName("", "a.db")
+	assert.True(t, ok)
+	assert.Equal(t, "a", lib)
+}
@@ -0,0 +1,234 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/timeline.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// maxTimelineBuckets 是时间轴结果的最大桶数，超出时应改用更粗的粒度或增加过滤条件
+const maxTimelineBuckets = 5000
+
+// timelineGranularities 是时间轴各粒度对应的日期前缀格式 (GLOB 模式) 与桶的长度。
+// 日期按文本前缀分桶，"/" 视为 "-"，因此 "1921"、"1921-03-05" 与 "1921-03-05T10:00:00Z" 都能被识别
+var timelineGranularities = map[string]struct {
+	pattern string
+	length  int
+}{
+	"year":  {pattern: "[0-9][0-9][0-9][0-9]*", length: 4},
+	"month": {pattern: "[0-9][0-9][0-9][0-9]-[0-9][0-9]*", length: 7},
+	"day":   {pattern: "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]*", length: 10},
+}
+
+// localTimeFunc 是注册到 SQLite 的确定性函数 archiveaegis_local_time(value, timezone)：
+// 带时区的 RFC 3339 时间换算为该时区不带时区的本地时间 "2006-01-02T15:04:05"，其它取值原样返回
+const localTimeFunc = "archiveaegis_local_time"
+
+// timelineLocations 缓存按名称加载的时区
+var timelineLocations sync.Map
+
+func init() {
+	sqlitedriver.MustRegisterDeterministicScalarFunction(localTimeFunc, 2, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var value string
+		switch v := args[0].(type) {
+		case string:
+			value = v
+		case []byte:
+			value = string(v)
+		default:
+			return v, nil
+		}
+		name, _ := args[1].(string)
+		loc, err := loadTimelineLocation(name)
+		if err != nil {
+			return value, nil
+		}
+		return localTime(value, loc), nil
+	})
+}
+
+func loadTimelineLocation(name string) (*time.Location, error) {
+	if cached, ok := timelineLocations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timelineLocations.Store(name, loc)
+	return loc, nil
+}
+
+// localTime 把带时区的时间文本换算为 loc 中的本地时间，日期与时间之间可以是 "T" 或空格
+func localTime(value string, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339Nano, strings.Replace(value, " ", "T", 1))
+	if err != nil {
+		return value
+	}
+	return t.In(loc).Format("2006-01-02T15:04:05")
+}
+
+// timelineSpec 是解析后的 port.QueryTimelineKey
+type timelineSpec struct {
+	field       string
+	granularity string
+	// timezone 为空时日期按原文分桶
+	timezone string
+}
+
+// parseTimelineSpec 解析时间轴参数，粒度缺省为 year
+func parseTimelineSpec(raw interface{}) (*timelineSpec, error) {
+	specMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("无效请求: '%s' 必须是包含 'field' 与 'granularity' 的对象", port.QueryTimelineKey)
+	}
+	spec := &timelineSpec{granularity: "year"}
+	if spec.field, _ = specMap["field"].(string); spec.field == "" {
+		return nil, fmt.Errorf("无效请求: '%s' 缺少日期字段 'field'", port.QueryTimelineKey)
+	}
+	if g, _ := specMap["granularity"].(string); g != "" {
+		spec.granularity = g
+	}
+	if _, known := timelineGranularities[spec.granularity]; !known {
+		return nil, fmt.Errorf("无效请求: 不支持的时间轴粒度 '%s'，可选 year、month 或 day", spec.granularity)
+	}
+	if spec.timezone, _ = specMap["timezone"].(string); spec.timezone != "" {
+		if _, err := loadTimelineLocation(spec.timezone); err != nil {
+			return nil, fmt.Errorf("无效请求: 无法识别的时区 '%s'", spec.timezone)
+		}
+	}
+	return spec, nil
+}
+
+// buildTimelineSQL 构建按日期字段分桶计数的 GROUP BY 语句，无法识别的日期归入 NULL 桶
+func buildTimelineSQL(tableName string, spec *timelineSpec, queryParams []queryParam) (string, []any, error) {
+	whereClause, whereArgs, err := buildWhereClause(queryParams)
+	if err != nil {
+		return "", nil, err
+	}
+	g := timelineGranularities[spec.granularity]
+	value := fmt.Sprintf(`replace(CAST(%q AS TEXT), '/', '-')`, spec.field)
+	if spec.timezone != "" {
+		// 时区名称已在解析时验证，只含字母、数字与 "/_+-"，单引号转义仅作防御
+		value = fmt.Sprintf(`%s(%s, '%s')`, localTimeFunc, value, strings.ReplaceAll(spec.timezone, "'", "''"))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`SELECT CASE WHEN %s GLOB '%s' THEN substr(%s, 1, %d) END AS bucket, COUNT(*) FROM %q`,
+		value, g.pattern, value, g.length, tableName))
+	if whereClause != "" {
+		sb.WriteString(" ")
+		sb.WriteString(whereClause)
+	}
+	sb.WriteString(" GROUP BY bucket ORDER BY bucket")
+	return sb.String(), whereArgs, nil
+}
+
+// timelineBuckets 在业务组的各库中执行分桶计数并按桶合并。返回的行为 {"bucket", "count"}，
+// 日期无法识别的记录以 bucket 为 nil 的一行给出 (排在最后)，total 为匹配的记录总数
+func (m *Manager) timelineBuckets(ctx context.Context, bizName, tableName string, tableAdminConfig *domain.TableConfig, dbInstances map[string]*sql.DB,
+	queryParams []queryParam, spec *timelineSpec, explain *explainLog, failures *libFailures) ([]map[string]any, int64, error) {
+	if setting, ok := tableAdminConfig.Fields[spec.field]; !ok || !setting.IsReturnable {
+		return nil, 0, fmt.Errorf("时间轴字段 '%s' 无效或未被授权返回", spec.field)
+	}
+	sqlQuery, queryArgs, err := buildTimelineSQL(tableName, spec, queryParams)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	libNames := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if m.libHasTable(db, tableName) {
+			libNames = append(libNames, libName)
+		}
+	}
+	sort.Strings(libNames)
+
+	counts := make(map[string]int64)
+	var total, undated int64
+	for _, libName := range libNames {
+		started := time.Now()
+		var rowCount int64
+		errQuery := func() error {
+			rows, err := dbInstances[libName].QueryContext(ctx, sqlQuery, queryArgs...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var bucket sql.NullString
+				var n int64
+				if err := rows.Scan(&bucket, &n); err != nil {
+					return err
+				}
+				rowCount++
+				total += n
+				if bucket.Valid {
+					counts[bucket.String] += n
+				} else {
+					undated += n
+				}
+			}
+			return rows.Err()
+		}()
+		explain.add(explainStep{lib: libName, kind: "timeline", sql: sqlQuery, args: queryArgs, duration: time.Since(started), rows: rowCount, err: errQuery})
+		if errQuery != nil {
+			errQuery = fmt.Errorf("统计库 '%s/%s' 表 '%s' 的时间轴失败: %w", bizName, libName, tableName, errQuery)
+			if failures == nil {
+				return nil, 0, errQuery
+			}
+			slog.Warn("[DBManager Query] 部分库的时间轴统计失败，结果中不含此库", "biz", bizName, "lib", libName, "error", errQuery)
+			failures.add(libName, errQuery)
+		}
+	}
+	if len(libNames) > 0 && failures.len() == len(libNames) {
+		return nil, 0, fmt.Errorf("统计业务 '%s' 表 '%s' 的时间轴失败: 全部 %d 个库均查询失败", bizName, tableName, len(libNames))
+	}
+	if len(counts) > maxTimelineBuckets {
+		return nil, 0, fmt.Errorf("无效请求: 时间轴的桶数 %d 超过上限 %d，请使用更粗的粒度或增加过滤条件", len(counts), maxTimelineBuckets)
+	}
+
+	buckets := make([]string, 0, len(counts))
+	for b := range counts {
+		buckets = append(buckets, b)
+	}
+	sort.Strings(buckets)
+	rows := make([]map[string]any, 0, len(buckets)+1)
+	for _, b := range buckets {
+		rows = append(rows, map[string]any{"bucket": b, "count": counts[b]})
+	}
+	if undated > 0 {
+		rows = append(rows, map[string]any{"bucket": nil, "count": undated})
+	}
+	return rows, total, nil
+}
+
+// timelineResult 把分桶计数的行组装为 port.QueryTimelineKey 约定的结果
+func timelineResult(spec *timelineSpec, rows []map[string]any, total int64) map[string]interface{} {
+	buckets := make([]interface{}, 0, len(rows))
+	var undated int64
+	for _, row := range rows {
+		if row["bucket"] == nil {
+			undated, _ = row["count"].(int64)
+			continue
+		}
+		buckets = append(buckets, row)
+	}
+	return map[string]interface{}{
+		"field":       spec.field,
+		"granularity": spec.granularity,
+		"buckets":     buckets,
+		"total":       total,
+		"undated":     undated,
+	}
+}
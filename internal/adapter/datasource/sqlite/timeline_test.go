@@ -0,0 +1,107 @@
+// file: internal/adapter/datasource/sqlite/timeline_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestQuery_Timeline(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, sent TEXT, secret TEXT);`,
+		`INSERT INTO letters (id, sender, sent) VALUES
+			(1, '鲁迅', '1921-03-05'), (2, '鲁迅', '1921/03/20'), (3, '胡适', '1921-07-01T10:00:00Z'),
+			(4, '鲁迅', '1925'), (5, '鲁迅', NULL), (6, '鲁迅', '民国十年');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT, sent TEXT, secret TEXT);`,
+		`INSERT INTO letters (id, sender, sent) VALUES (1, '鲁迅', '1921-03-09');`,
+	)
+	cfg := &domain.BizQueryConfig{
+		BizName:              "archive",
+		IsPubliclySearchable: true,
+		Tables: map[string]*domain.TableConfig{
+			"letters": {
+				TableName:    "letters",
+				IsSearchable: true,
+				Fields: map[string]domain.FieldSetting{
+					"id":     {FieldName: "id", IsReturnable: true},
+					"sender": {FieldName: "sender", IsSearchable: true, IsReturnable: true},
+					"sent":   {FieldName: "sent", IsReturnable: true},
+					"secret": {FieldName: "secret", IsReturnable: false},
+				},
+			},
+		},
+	}
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return cfg, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"archive": {"a": dbA, "b": dbB}}
+	for _, db := range []*sql.DB{dbA, dbB} {
+		manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{
+			allTablesAndColumns: map[string][]string{"letters": {"id", "secret", "sender", "sent"}},
+		}
+	}
+
+	timeline := func(field, granularity string, filters ...interface{}) (*port.QueryResult, error) {
+		return manager.Query(ctx, port.QueryRequest{
+			BizName: "archive",
+			Query: map[string]interface{}{
+				"table":               "letters",
+				"filters":             filters,
+				port.QueryTimelineKey: map[string]interface{}{"field": field, "granularity": granularity},
+			},
+		})
+	}
+	bucket := func(b string, n int64) interface{} {
+		return map[string]interface{}{"bucket": b, "count": n}
+	}
+
+	result, err := timeline("sent", "month")
+	require.NoError(t, err)
+	_, err = structpb.NewStruct(result.Data)
+	require.NoError(t, err, "结果必须可以转换为 protobuf Struct")
+	assert.Equal(t, []interface{}{bucket("1921-03", 3), bucket("1921-07", 1)}, result.Data["buckets"], "各库同一桶的计数合并")
+	assert.EqualValues(t, 7, result.Data["total"])
+	assert.EqualValues(t, 3, result.Data["undated"], "只有年份、缺失或无法识别的日期不计入月份桶")
+
+	result, err = timeline("sent", "year", map[string]interface{}{"field": "sender", "value": "鲁迅"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{bucket("1921", 3), bucket("1925", 1)}, result.Data["buckets"], "应用过滤条件")
+	assert.EqualValues(t, 2, result.Data["undated"])
+
+	result, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query: map[string]interface{}{
+			"table":               "letters",
+			port.QueryTimelineKey: map[string]interface{}{"field": "sent", "granularity": "month", "timezone": "Etc/GMT+12"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{bucket("1921-03", 3), bucket("1921-06", 1)}, result.Data["buckets"], "带时区的日期换算到指定时区后分桶")
+
+	_, err = manager.Query(ctx, port.QueryRequest{
+		BizName: "archive",
+		Query: map[string]interface{}{
+			"table":               "letters",
+			port.QueryTimelineKey: map[string]interface{}{"field": "sent", "granularity": "month", "timezone": "Mars/Olympus"},
+		},
+	})
+	assert.Error(t, err, "无法识别的时区")
+
+	_, err = timeline("secret", "year")
+	assert.Error(t, err, "不可返回的字段不能用于时间轴")
+	_, err = timeline("sent", "week")
+	assert.Error(t, err)
+}
@@ -0,0 +1,419 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/vector.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// 语义检索的向量保存在与业务表同库的内部表中，每条记录一行，以 QueryModeRecord 使用的记录标识关联。
+// 向量按 sqlite-vec 相同的格式 (float32 小端序 BLOB) 存储；modernc 驱动无法加载原生扩展，
+// 余弦相似度由本进程注册的确定性函数计算，检索时逐行比较，适合十万行量级的表。
+const (
+	vecTablePrefix = innerPrefix + "vec_"
+	// vecCosineFunc 计算两个向量 BLOB 的余弦相似度，维度不一致或含零向量时返回 NULL
+	vecCosineFunc = "archiveaegis_vec_cosine"
+
+	defaultEmbeddingScanLimit = 256
+	maxEmbeddingScanLimit     = 5000
+	defaultSimilarityTopK     = 10
+	maxSimilarityTopK         = 200
+)
+
+// 编译期断言，确保 Manager 实现了语义检索扩展
+var _ port.VectorSearcher = (*Manager)(nil)
+
+func init() {
+	sqlitedriver.MustRegisterDeterministicScalarFunction(vecCosineFunc, 2, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		a, okA := args[0].([]byte)
+		b, okB := args[1].([]byte)
+		if !okA || !okB {
+			return nil, nil
+		}
+		score, ok := cosineSimilarity(a, b)
+		if !ok {
+			return nil, nil
+		}
+		return score, nil
+	})
+}
+
+// vecTable 返回表的向量表名
+func vecTable(tableName string) string {
+	return vecTablePrefix + tableName
+}
+
+// encodeVector 把向量编码为 float32 小端序 BLOB
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// cosineSimilarity 直接在 BLOB 上计算余弦相似度
+func cosineSimilarity(a, b []byte) (float64, bool) {
+	if len(a) != len(b) || len(a) == 0 || len(a)%4 != 0 {
+		return 0, false
+	}
+	var dot, normA, normB float64
+	for i := 0; i < len(a); i += 4 {
+		x := float64(math.Float32frombits(binary.LittleEndian.Uint32(a[i:])))
+		y := float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i:])))
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+	return dot / math.Sqrt(normA*normB), true
+}
+
+// vectorLibs 返回业务组中物理上存在该表的库，按库名排序
+func (m *Manager) vectorLibs(bizName, tableName string) (map[string]*sql.DB, []string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dbInstances, exists := m.group[bizName]
+	if !exists {
+		return nil, nil, port.ErrBizNotFound
+	}
+	libs := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if info := m.dbSchemaCache[db]; info != nil {
+			if _, ok := info.allTablesAndColumns[tableName]; ok {
+				libs = append(libs, libName)
+			}
+		}
+	}
+	sort.Strings(libs)
+	return dbInstances, libs, nil
+}
+
+func (m *Manager) checkVectorTable(ctx context.Context, bizName, tableName string) error {
+	bizConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizConfig == nil {
+		return port.ErrBizNotFound
+	}
+	if _, exists := bizConfig.Tables[tableName]; !exists {
+		return port.ErrTableNotFoundInBiz
+	}
+	return nil
+}
+
+// ScanEmbeddingSources 按库名与 rowid 顺序分页读取待向量化的文本。
+// 一页只读取一个库，读完一个库后游标移到下一个库；拼接后为空的记录被跳过，但仍推进游标。
+func (m *Manager) ScanEmbeddingSources(ctx context.Context, req port.EmbeddingSourceRequest) (*port.EmbeddingSourcePage, error) {
+	if req.TableName == "" || len(req.Fields) == 0 {
+		return nil, errors.New("无效请求: 必须指定表名与至少一个字段")
+	}
+	if err := m.checkVectorTable(ctx, req.BizName, req.TableName); err != nil {
+		return nil, err
+	}
+	dbInstances, libs, err := m.vectorLibs(req.BizName, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultEmbeddingScanLimit
+	}
+	if limit > maxEmbeddingScanLimit {
+		limit = maxEmbeddingScanLimit
+	}
+
+	idx := 0
+	if req.Lib != "" {
+		idx = sort.SearchStrings(libs, req.Lib)
+	}
+	page := &port.EmbeddingSourcePage{Sources: []port.EmbeddingSource{}}
+	if idx >= len(libs) {
+		page.Done = true
+		return page, nil
+	}
+	libName := libs[idx]
+	afterRowID := req.AfterRowID
+	if libName != req.Lib {
+		afterRowID = 0
+	}
+
+	db := dbInstances[libName]
+	m.mu.RLock()
+	columns := m.dbSchemaCache[db].allTablesAndColumns[req.TableName]
+	m.mu.RUnlock()
+	var fields []string
+	for _, f := range req.Fields {
+		for _, col := range columns {
+			if col == f {
+				fields = append(fields, f)
+				break
+			}
+		}
+	}
+
+	sources, next, scanned, err := scanEmbeddingBatch(ctx, db, req.TableName, fields, afterRowID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("读取库 '%s/%s' 表 '%s' 失败: %w", req.BizName, libName, req.TableName, err)
+	}
+	for i := range sources {
+		sources[i].Lib = libName
+	}
+	page.Sources = sources
+	if scanned < limit {
+		// 当前库已读完，游标移到下一个库
+		if idx+1 < len(libs) {
+			page.NextLib = libs[idx+1]
+		} else {
+			page.Done = true
+		}
+		return page, nil
+	}
+	page.NextLib, page.NextRowID = libName, next
+	return page, nil
+}
+
+// scanEmbeddingBatch 读取单个库中 rowid 大于 afterRowID 的一批记录，返回拼接的文本、最后一行的 rowid 与读取的行数
+func scanEmbeddingBatch(ctx context.Context, db *sql.DB, tableName string, fields []string, afterRowID int64, limit int) ([]port.EmbeddingSource, int64, int, error) {
+	pkCols, err := primaryKeyColumns(ctx, db, tableName)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	cols := append([]string{}, pkCols...)
+	cols = append(cols, fields...)
+	selectList := "rowid"
+	if len(cols) > 0 {
+		selectList += ", " + quoteIdents(cols)
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %q WHERE rowid > ? ORDER BY rowid LIMIT ?`, selectList, tableName), afterRowID, limit)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer rows.Close()
+
+	var sources []port.EmbeddingSource
+	next, scanned := afterRowID, 0
+	vals := make([]any, len(cols)+1)
+	ptrs := make([]any, len(vals))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, 0, 0, err
+		}
+		scanned++
+		rowID, _ := vals[0].(int64)
+		next = rowID
+
+		recordID := fmt.Sprint(rowID)
+		if len(pkCols) > 0 {
+			parts := make([]string, len(pkCols))
+			for i := range pkCols {
+				parts[i] = fmt.Sprint(normalizeValue(vals[1+i]))
+			}
+			recordID = strings.Join(parts, port.RecordIDSeparator)
+		}
+		var texts []string
+		for i := range fields {
+			v := normalizeValue(vals[1+len(pkCols)+i])
+			if v == nil {
+				continue
+			}
+			if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+		sources = append(sources, port.EmbeddingSource{RecordID: recordID, Text: strings.Join(texts, "\n")})
+	}
+	return sources, next, scanned, rows.Err()
+}
+
+// UpsertEmbeddings 按库写入向量。同一记录的向量被替换，并记录生成向量的模型与重建轮次。
+func (m *Manager) UpsertEmbeddings(ctx context.Context, req port.UpsertEmbeddingsRequest) (*port.UpsertEmbeddingsResult, error) {
+	if req.TableName == "" {
+		return nil, errors.New("无效请求: 必须指定表名")
+	}
+	if err := m.checkVectorTable(ctx, req.BizName, req.TableName); err != nil {
+		return nil, err
+	}
+	dbInstances, libs, err := m.vectorLibs(req.BizName, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	result := &port.UpsertEmbeddingsResult{}
+	table := vecTable(req.TableName)
+
+	if req.DropAll {
+		for _, libName := range libs {
+			db := dbInstances[libName]
+			exists, err := tableExistsInDB(ctx, db, table)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				continue
+			}
+			var n int64
+			if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %q`, table)).Scan(&n); err != nil {
+				return nil, fmt.Errorf("统计库 '%s/%s' 的向量失败: %w", req.BizName, libName, err)
+			}
+			if _, err := db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %q`, table)); err != nil {
+				return nil, fmt.Errorf("删除库 '%s/%s' 的向量失败: %w", req.BizName, libName, err)
+			}
+			result.Deleted += n
+		}
+		return result, nil
+	}
+
+	byLib := make(map[string][]port.Embedding)
+	for _, e := range req.Embeddings {
+		if _, exists := dbInstances[e.Lib]; !exists {
+			return nil, fmt.Errorf("业务组 '%s' 中不存在库 '%s'", req.BizName, e.Lib)
+		}
+		byLib[e.Lib] = append(byLib[e.Lib], e)
+	}
+	for libName, embeddings := range byLib {
+		n, err := upsertEmbeddingBatch(ctx, dbInstances[libName], table, req.Model, req.Generation, embeddings)
+		if err != nil {
+			return nil, fmt.Errorf("写入库 '%s/%s' 的向量失败: %w", req.BizName, libName, err)
+		}
+		result.Upserted += n
+	}
+
+	if req.PruneBefore > 0 {
+		for _, libName := range libs {
+			db := dbInstances[libName]
+			exists, err := tableExistsInDB(ctx, db, table)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				continue
+			}
+			res, err := db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE generation < ?`, table), req.PruneBefore)
+			if err != nil {
+				return nil, fmt.Errorf("清理库 '%s/%s' 的过期向量失败: %w", req.BizName, libName, err)
+			}
+			n, _ := res.RowsAffected()
+			result.Deleted += n
+		}
+	}
+	return result, nil
+}
+
+func upsertEmbeddingBatch(ctx context.Context, db *sql.DB, table, model string, generation int64, embeddings []port.Embedding) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q (
+		record_id TEXT PRIMARY KEY,
+		model TEXT NOT NULL,
+		dimensions INTEGER NOT NULL,
+		generation INTEGER NOT NULL,
+		embedding BLOB NOT NULL
+	)`, table)); err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %q (record_id, model, dimensions, generation, embedding) VALUES (?, ?, ?, ?, ?)`, table))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, e := range embeddings {
+		if len(e.Vector) == 0 {
+			return 0, fmt.Errorf("记录 '%s' 的向量为空", e.RecordID)
+		}
+		if _, err := stmt.ExecContext(ctx, e.RecordID, model, len(e.Vector), generation, encodeVector(e.Vector)); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(embeddings)), tx.Commit()
+}
+
+// SimilaritySearch 在各库中分别取相似度最高的 TopK 条记录后合并
+func (m *Manager) SimilaritySearch(ctx context.Context, req port.SimilaritySearchRequest) (*port.SimilaritySearchResult, error) {
+	if req.TableName == "" || len(req.Vector) == 0 {
+		return nil, errors.New("无效请求: 必须指定表名与查询向量")
+	}
+	if err := m.checkVectorTable(ctx, req.BizName, req.TableName); err != nil {
+		return nil, err
+	}
+	dbInstances, libs, err := m.vectorLibs(req.BizName, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	topK := req.TopK
+	if topK <= 0 {
+		topK = defaultSimilarityTopK
+	}
+	if topK > maxSimilarityTopK {
+		topK = maxSimilarityTopK
+	}
+
+	table := vecTable(req.TableName)
+	query := fmt.Sprintf(`SELECT record_id, score FROM (
+		SELECT record_id, %s(embedding, ?) AS score FROM %q WHERE model = ? AND dimensions = ?
+	) WHERE score IS NOT NULL AND score >= ? ORDER BY score DESC LIMIT ?`, vecCosineFunc, table)
+	vector := encodeVector(req.Vector)
+	matches := make([]port.SimilarityMatch, 0)
+	for _, libName := range libs {
+		db := dbInstances[libName]
+		exists, err := tableExistsInDB(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		rows, err := db.QueryContext(ctx, query, vector, req.Model, len(req.Vector), req.MinScore, topK)
+		if err != nil {
+			return nil, fmt.Errorf("检索库 '%s/%s' 的向量失败: %w", req.BizName, libName, err)
+		}
+		for rows.Next() {
+			match := port.SimilarityMatch{Lib: libName}
+			if err := rows.Scan(&match.RecordID, &match.Score); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			matches = append(matches, match)
+		}
+		err = rows.Close()
+		if err == nil {
+			err = rows.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return &port.SimilaritySearchResult{Matches: matches, Source: m.Type()}, nil
+}
+
+func tableExistsInDB(ctx context.Context, db *sql.DB, table string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n)
+	return n > 0, err
+}
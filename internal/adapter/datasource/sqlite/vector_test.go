@@ -0,0 +1,116 @@
+// file: internal/adapter/datasource/sqlite/vector_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeVectorCosine(t *testing.T) {
+	score, ok := cosineSimilarity(encodeVector([]float32{1, 0}), encodeVector([]float32{1, 1}))
+	require.True(t, ok)
+	assert.InDelta(t, 0.7071, score, 1e-4)
+	_, ok = cosineSimilarity(encodeVector([]float32{1, 0}), encodeVector([]float32{1, 0, 0}))
+	assert.False(t, ok, "维度不同的向量不可比较")
+	_, ok = cosineSimilarity(encodeVector([]float32{0, 0}), encodeVector([]float32{1, 0}))
+	assert.False(t, ok, "零向量没有方向")
+}
+
+func TestVectorSearch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (title, note) VALUES ('Sea', 'waves'), ('Moon', NULL), (NULL, NULL);`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (title, note) VALUES ('Apple', 'fruit');`,
+	)
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return &domain.BizQueryConfig{BizName: "library", Tables: map[string]*domain.TableConfig{
+				"books": {TableName: "books", IsSearchable: true},
+			}}, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"library": {"a": dbA, "b": dbB}}
+	for _, db := range []*sql.DB{dbA, dbB} {
+		manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"books": {"id", "note", "title"}}}
+	}
+
+	// 分页读取：每页只读一个库，空文本的记录被跳过
+	var sources []port.EmbeddingSource
+	req := port.EmbeddingSourceRequest{BizName: "library", TableName: "books", Fields: []string{"title", "note", "missing"}, Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5)
+		page, err := manager.ScanEmbeddingSources(ctx, req)
+		require.NoError(t, err)
+		sources = append(sources, page.Sources...)
+		if page.Done {
+			break
+		}
+		req.Lib, req.AfterRowID = page.NextLib, page.NextRowID
+	}
+	assert.Equal(t, []port.EmbeddingSource{
+		{Lib: "a", RecordID: "1", Text: "Sea\nwaves"},
+		{Lib: "a", RecordID: "2", Text: "Moon"},
+		{Lib: "b", RecordID: "1", Text: "Apple\nfruit"},
+	}, sources)
+
+	res, err := manager.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{
+		BizName: "library", TableName: "books", Model: "m1", Generation: 1,
+		Embeddings: []port.Embedding{
+			{Lib: "a", RecordID: "1", Vector: []float32{1, 0}},
+			{Lib: "a", RecordID: "2", Vector: []float32{0, 1}},
+			{Lib: "b", RecordID: "1", Vector: []float32{0.9, 0.1}},
+		},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.Upserted)
+
+	found, err := manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "books", Model: "m1", Vector: []float32{1, 0}, TopK: 2})
+	require.NoError(t, err)
+	require.Len(t, found.Matches, 2, "各库的结果合并后取前 TopK 条")
+	assert.Equal(t, "a", found.Matches[0].Lib)
+	assert.Equal(t, "1", found.Matches[0].RecordID)
+	assert.InDelta(t, 1, found.Matches[0].Score, 1e-6)
+	assert.Equal(t, "b", found.Matches[1].Lib)
+
+	found, err = manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "books", Model: "m2", Vector: []float32{1, 0}})
+	require.NoError(t, err)
+	assert.Empty(t, found.Matches, "只比较同一模型的向量")
+	found, err = manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "books", Model: "m1", Vector: []float32{1, 0}, MinScore: 0.5})
+	require.NoError(t, err)
+	assert.Len(t, found.Matches, 2, "低于 MinScore 的记录被过滤")
+
+	// 新一轮只写入一条，结束时清理旧轮次的向量
+	_, err = manager.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{
+		BizName: "library", TableName: "books", Model: "m1", Generation: 2,
+		Embeddings: []port.Embedding{{Lib: "a", RecordID: "2", Vector: []float32{0, 1}}},
+	})
+	require.NoError(t, err)
+	res, err = manager.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{BizName: "library", TableName: "books", PruneBefore: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.Deleted)
+	found, err = manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "books", Model: "m1", Vector: []float32{1, 0}, MinScore: -1})
+	require.NoError(t, err)
+	require.Len(t, found.Matches, 1)
+	assert.Equal(t, "2", found.Matches[0].RecordID)
+
+	res, err = manager.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{BizName: "library", TableName: "books", DropAll: true})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Deleted)
+	found, err = manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "books", Model: "m1", Vector: []float32{1, 0}, MinScore: -1})
+	require.NoError(t, err)
+	assert.Empty(t, found.Matches)
+
+	_, err = manager.SimilaritySearch(ctx, port.SimilaritySearchRequest{BizName: "library", TableName: "missing", Model: "m1", Vector: []float32{1, 0}})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+}
@@ -0,0 +1,114 @@
+// Package sqlite file: internal/adapter/datasource/sqlite/vocabulary.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultVocabularyLimit = 1000
+	maxVocabularyLimit     = 10000
+)
+
+// queryVocabulary 按值升序分页返回字段在所有库中的不同取值及出现次数 (空值与 NULL 除外)。
+// 请求中 "after" 为上一页返回的 next_after，首页省略。各库分别取大于游标的前 limit 个值后合并，
+// 合并结果的前 limit 个值即为全局的下一页。
+// 词表包含不可返回字段的取值，因此只应通过管理接口调用。
+func (m *Manager) queryVocabulary(ctx context.Context, bizName string, queryMap map[string]interface{}) (*port.QueryResult, error) {
+	tableName, _ := queryMap["table"].(string)
+	field, _ := queryMap["field"].(string)
+	if tableName == "" || field == "" {
+		return nil, fmt.Errorf("无效请求: vocabulary 模式必须包含 'table' 与 'field' 字符串字段")
+	}
+	after, _ := queryMap["after"].(string)
+	limit := defaultVocabularyLimit
+	if l, ok := queryMap["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > maxVocabularyLimit {
+		limit = maxVocabularyLimit
+	}
+
+	bizAdminConfig, err := m.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("业务 '%s' 查询配置不可用: %w", bizName, err)
+	}
+	if bizAdminConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableAdminConfig, exists := bizAdminConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	if setting, ok := tableAdminConfig.Fields[field]; !ok || !setting.IsSearchable {
+		return nil, fmt.Errorf("字段 '%s' 无效或不可搜索", field)
+	}
+
+	m.mu.RLock()
+	dbInstances := m.group[bizName]
+	libs := make([]string, 0, len(dbInstances))
+	for libName, db := range dbInstances {
+		if schema, ok := m.dbSchemaCache[db]; ok && schema != nil {
+			for _, col := range schema.allTablesAndColumns[tableName] {
+				if col == field {
+					libs = append(libs, libName)
+					break
+				}
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	query := fmt.Sprintf(`SELECT CAST(%q AS TEXT) AS v, COUNT(*) FROM %q WHERE %q IS NOT NULL AND CAST(%q AS TEXT) > ? GROUP BY v ORDER BY v LIMIT ?`, field, tableName, field, field)
+	for _, libName := range libs {
+		rows, err := dbInstances[libName].QueryContext(ctx, query, after, limit)
+		if err != nil {
+			return nil, fmt.Errorf("读取库 '%s/%s' 表 '%s' 字段 '%s' 的取值失败: %w", bizName, libName, tableName, field, err)
+		}
+		for rows.Next() {
+			var value string
+			var n int64
+			if err := rows.Scan(&value, &n); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			counts[value] += n
+		}
+		err = rows.Close()
+		if err == nil {
+			err = rows.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	done := len(values) <= limit
+	if !done {
+		values = values[:limit]
+	}
+
+	items := make([]interface{}, len(values))
+	for i, v := range values {
+		items[i] = map[string]interface{}{"value": v, "count": counts[v]}
+	}
+	data := map[string]interface{}{
+		"table":  tableName,
+		"field":  field,
+		"values": items,
+		"done":   done,
+	}
+	if len(values) > 0 {
+		data["next_after"] = values[len(values)-1]
+	}
+	return &port.QueryResult{Data: data, Source: m.Type()}, nil
+}
@@ -0,0 +1,66 @@
+// file: internal/adapter/datasource/sqlite/vocabulary_test.go
+package sqlite
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryVocabulary(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbA := createTestDB(t, dir, "a.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (title, note) VALUES ('Sea', 'x'), ('Moon', 'y'), (NULL, 'z');`,
+	)
+	dbB := createTestDB(t, dir, "b.db",
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);`,
+		`INSERT INTO books (title, note) VALUES ('Sea', 'x'), ('Apple', 'y');`,
+	)
+	manager := NewManager(&mockAdminConfigService{
+		GetBizQueryConfigFunc: func(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
+			return &domain.BizQueryConfig{BizName: "library", Tables: map[string]*domain.TableConfig{
+				"books": {TableName: "books", IsSearchable: true, Fields: map[string]domain.FieldSetting{
+					"title": {FieldName: "title", IsSearchable: true},
+					"note":  {FieldName: "note", IsReturnable: true},
+				}},
+			}}, nil
+		},
+	})
+	manager.group = map[string]map[string]*sql.DB{"library": {"a": dbA, "b": dbB}}
+	for _, db := range []*sql.DB{dbA, dbB} {
+		manager.dbSchemaCache[db] = &dbPhysicalSchemaInfo{allTablesAndColumns: map[string][]string{"books": {"id", "note", "title"}}}
+	}
+	page := func(after string) map[string]interface{} {
+		t.Helper()
+		query := map[string]interface{}{port.QueryModeKey: port.QueryModeVocabulary, "table": "books", "field": "title", "limit": float64(2)}
+		if after != "" {
+			query["after"] = after
+		}
+		res, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: query})
+		require.NoError(t, err)
+		return res.Data
+	}
+
+	first := page("")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"value": "Apple", "count": int64(1)},
+		map[string]interface{}{"value": "Moon", "count": int64(1)},
+	}, first["values"])
+	assert.Equal(t, false, first["done"])
+
+	second := page(first["next_after"].(string))
+	assert.Equal(t, []interface{}{map[string]interface{}{"value": "Sea", "count": int64(2)}}, second["values"], "各库的计数应合并")
+	assert.Equal(t, true, second["done"])
+
+	_, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{
+		port.QueryModeKey: port.QueryModeVocabulary, "table": "books", "field": "note",
+	}})
+	assert.ErrorContains(t, err, "不可搜索")
+}
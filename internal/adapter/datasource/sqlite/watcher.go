@@ -24,27 +24,7 @@ func (m *Manager) StartWatcher(rootDir string) error {
 		return fmt.Errorf("创建 fsnotify watcher 失败: %w", err)
 	}
 
-	// Goroutine to handle events
-	go func() {
-		defer watcher.Close()
-		log.Printf("信息: [DBManager] 文件监视 goroutine 已启动。")
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					log.Printf("警告: [DBManager] 文件监视器事件通道已关闭。")
-					return
-				}
-				m.handleFsEvent(event, watcher)
-			case errWatch, ok := <-watcher.Errors:
-				if !ok {
-					log.Printf("警告: [DBManager] 文件监视器错误通道已关闭。")
-					return
-				}
-				log.Printf("错误: [DBManager] 文件监视器报告错误: %v", errWatch)
-			}
-		}
-	}()
+	go m.runFsWatcher(context.Background(), watcher)
 
 	// Add root directory to watch for new biz folders
 	if err := watcher.Add(m.root); err != nil {
@@ -66,6 +46,30 @@ func (m *Manager) StartWatcher(rootDir string) error {
 	return nil
 }
 
+// runFsWatcher 处理 fsnotify 的事件，直到 ctx 取消或监视器关闭
+func (m *Manager) runFsWatcher(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	log.Printf("信息: [DBManager] 文件监视 goroutine 已启动。")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				log.Printf("警告: [DBManager] 文件监视器事件通道已关闭。")
+				return
+			}
+			m.handleFsEvent(event, watcher)
+		case errWatch, ok := <-watcher.Errors:
+			if !ok {
+				log.Printf("警告: [DBManager] 文件监视器错误通道已关闭。")
+				return
+			}
+			log.Printf("错误: [DBManager] 文件监视器报告错误: %v", errWatch)
+		}
+	}
+}
+
 // handleFsEvent 处理单个文件系统事件。
 func (m *Manager) handleFsEvent(event fsnotify.Event, watcher *fsnotify.Watcher) {
 	cleanPath := filepath.Clean(event.Name)
@@ -83,9 +87,19 @@ func (m *Manager) handleFsEvent(event fsnotify.Event, watcher *fsnotify.Watcher)
 	if !strings.HasSuffix(strings.ToLower(cleanPath), ".db") {
 		return
 	}
+	m.scheduleReload(cleanPath)
+}
 
+// scheduleReload 在防抖周期后重新加载或卸载库文件 path。文件监视与轮询检测到的变更都经由这里处理
+func (m *Manager) scheduleReload(cleanPath string) {
 	m.eventTimersMu.Lock()
 	defer m.eventTimersMu.Unlock()
+	if until, ignored := m.ignoredEvents[cleanPath]; ignored {
+		if time.Now().Before(until) {
+			return
+		}
+		delete(m.ignoredEvents, cleanPath)
+	}
 	if timer, exists := m.eventTimers[cleanPath]; exists {
 		timer.Stop()
 	}
@@ -97,6 +111,22 @@ func (m *Manager) handleFsEvent(event fsnotify.Event, watcher *fsnotify.Watcher)
 	})
 }
 
+// ignoreFsEvents 在两个防抖周期内忽略 paths 的文件事件，并取消已排队的处理。
+// 管理器自身替换库文件后已重新加载，不需要再由文件事件触发重载
+func (m *Manager) ignoreFsEvents(paths ...string) {
+	m.eventTimersMu.Lock()
+	defer m.eventTimersMu.Unlock()
+	until := time.Now().Add(2 * debounceDuration)
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		m.ignoredEvents[path] = until
+		if timer, exists := m.eventTimers[path]; exists {
+			timer.Stop()
+			delete(m.eventTimers, path)
+		}
+	}
+}
+
 // processDebouncedEvent 在防抖后实际处理 .db 文件的变更。
 func (m *Manager) processDebouncedEvent(path string) {
 	log.Printf("信息: [DBManager Debounced Event] 开始处理文件: '%s'", path)
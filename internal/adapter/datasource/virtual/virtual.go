@@ -0,0 +1,213 @@
+// Package virtual file: internal/adapter/datasource/virtual/virtual.go
+package virtual
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LookupFunc 按业务组名解析底层数据源。每次请求时调用，因此底层插件重启后无需重新注册虚拟业务组。
+type LookupFunc func(bizName string) (port.DataSource, bool)
+
+// DataSource 将虚拟业务组的查询路由到各表对应的底层数据源，并合并它们的结构信息
+type DataSource struct {
+	def    domain.VirtualBiz
+	tables map[string]domain.VirtualTable
+	lookup LookupFunc
+}
+
+// New 根据虚拟业务组定义创建一个数据源
+func New(def domain.VirtualBiz, lookup LookupFunc) *DataSource {
+	tables := make(map[string]domain.VirtualTable, len(def.Tables))
+	for _, t := range def.Tables {
+		tables[t.Name] = t
+	}
+	return &DataSource{def: def, tables: tables, lookup: lookup}
+}
+
+// Type 返回适配器的类型标识符
+func (d *DataSource) Type() string {
+	return "virtual"
+}
+
+// resolve 找到虚拟表对应的底层表与数据源
+func (d *DataSource) resolve(tableName string) (domain.VirtualTable, port.DataSource, error) {
+	vt, exists := d.tables[tableName]
+	if !exists {
+		return domain.VirtualTable{}, nil, port.ErrTableNotFoundInBiz
+	}
+	ds, loaded := d.lookup(vt.SourceBiz)
+	if !loaded {
+		return domain.VirtualTable{}, nil, fmt.Errorf("虚拟表 '%s' 的底层业务组 '%s' 当前未注册", tableName, vt.SourceBiz)
+	}
+	return vt, ds, nil
+}
+
+// Query 将查询改写为底层表上的查询：替换表名，并在用户过滤条件之前插入固定过滤条件
+func (d *DataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	mode, _ := req.Query[port.QueryModeKey].(string)
+	switch mode {
+	case port.QueryModeChanges:
+		return nil, fmt.Errorf("虚拟业务组 '%s' 不提供变更日志，请直接读取底层业务组", d.def.BizName)
+	case port.QueryModeCompleteness:
+		// 统计无法附加固定过滤条件，结果会覆盖整张底层表
+		return nil, fmt.Errorf("虚拟业务组 '%s' 不提供完整性报告，请直接统计底层业务组", d.def.BizName)
+	case port.QueryModeVocabulary:
+		// 同理，词表会包含固定过滤条件之外的取值
+		return nil, fmt.Errorf("虚拟业务组 '%s' 不提供字段词表，请直接读取底层业务组", d.def.BizName)
+	case port.QueryModeStats:
+		return nil, fmt.Errorf("虚拟业务组 '%s' 不提供字段统计，请直接统计底层业务组", d.def.BizName)
+	}
+
+	tableName, _ := req.Query["table"].(string)
+	if tableName == "" {
+		return nil, fmt.Errorf("无效请求: 虚拟业务组的查询必须指定 'table'")
+	}
+	vt, ds, err := d.resolve(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 复制一份查询，避免修改调用方的请求
+	query := make(map[string]interface{}, len(req.Query))
+	for k, v := range req.Query {
+		query[k] = v
+	}
+	query["table"] = vt.SourceTable
+
+	if mode != port.QueryModeRecord && len(vt.Filters) > 0 {
+		filters, err := mergeFilters(vt.Filters, query["filters"])
+		if err != nil {
+			return nil, err
+		}
+		query["filters"] = filters
+	}
+
+	result, err := ds.Query(ctx, port.QueryRequest{BizName: vt.SourceBiz, Query: query, Extensions: req.Extensions})
+	if err != nil {
+		return nil, err
+	}
+
+	// 按主键读取时无法附加过滤条件，改为校验返回的记录是否落在虚拟表的范围内
+	if mode == port.QueryModeRecord && len(vt.Filters) > 0 {
+		if record, _ := result.Data["record"].(map[string]interface{}); record != nil && !matchesFilters(record, vt.Filters) {
+			return &port.QueryResult{
+				Data:   map[string]interface{}{"record": nil, "matches": 0},
+				Source: d.Type(),
+			}, nil
+		}
+	}
+	return result, nil
+}
+
+// mergeFilters 把固定过滤条件以 AND 连接在用户过滤条件之前。
+// 过滤语言按从左到右的顺序拼接且不支持括号，用户条件中若含 OR，
+// 将因运算符优先级绕过固定条件，因此这种组合会被拒绝。
+func mergeFilters(fixed []domain.VirtualTableFilter, userFilters interface{}) ([]interface{}, error) {
+	var user []interface{}
+	if userFilters != nil {
+		var ok bool
+		if user, ok = userFilters.([]interface{}); !ok {
+			return nil, errors.New("无效请求: 'filters' 必须是一个数组")
+		}
+	}
+	for _, f := range user {
+		if fm, ok := f.(map[string]interface{}); ok {
+			if logic, _ := fm["logic"].(string); strings.EqualFold(logic, "OR") {
+				return nil, errors.New("无效请求: 该虚拟表带有固定过滤条件，不支持 OR 逻辑")
+			}
+		}
+	}
+
+	merged := make([]interface{}, 0, len(fixed)+len(user))
+	for i, f := range fixed {
+		filter := map[string]interface{}{"field": f.Field, "value": f.Value}
+		if i < len(fixed)-1 || len(user) > 0 {
+			filter["logic"] = "AND"
+		}
+		merged = append(merged, filter)
+	}
+	merged = append(merged, user...)
+	return merged, nil
+}
+
+// matchesFilters 判断记录是否满足全部固定过滤条件；记录中缺少的字段视为不满足
+func matchesFilters(record map[string]interface{}, filters []domain.VirtualTableFilter) bool {
+	for _, f := range filters {
+		v, exists := record[f.Field]
+		if !exists || v == nil || fmt.Sprintf("%v", v) != f.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Mutate 虚拟业务组是只读的
+func (d *DataSource) Mutate(_ context.Context, _ port.MutateRequest) (*port.MutateResult, error) {
+	return nil, port.ErrPermissionDenied
+}
+
+// AdminMutate 虚拟业务组没有自己的库文件，管理操作需对各底层业务组执行
+func (d *DataSource) AdminMutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return nil, fmt.Errorf("%w: 虚拟业务组不支持 '%s'，请直接对各底层业务组执行", port.ErrAdminMutateUnsupported, req.Operation)
+}
+
+// GetSchema 以虚拟表名合并各底层表的结构信息
+func (d *DataSource) GetSchema(ctx context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	names := make([]string, 0, len(d.tables))
+	if req.TableName != "" {
+		if _, exists := d.tables[req.TableName]; !exists {
+			return nil, port.ErrTableNotFoundInBiz
+		}
+		names = append(names, req.TableName)
+	} else {
+		for name := range d.tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	merged := &port.SchemaResult{
+		Tables:     make(map[string][]port.FieldDescription, len(names)),
+		RecordKeys: make(map[string][]string, len(names)),
+	}
+	for _, name := range names {
+		vt, ds, err := d.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := ds.GetSchema(ctx, port.SchemaRequest{BizName: vt.SourceBiz, TableName: vt.SourceTable})
+		if err != nil {
+			return nil, fmt.Errorf("读取虚拟表 '%s' 的底层结构失败: %w", name, err)
+		}
+		if fields, ok := schema.Tables[vt.SourceTable]; ok {
+			merged.Tables[name] = fields
+			merged.RecordKeys[name] = schema.RecordKey(vt.SourceTable)
+		}
+	}
+	return merged, nil
+}
+
+// HealthCheck 只有全部底层业务组均已注册且健康时才视为健康
+func (d *DataSource) HealthCheck(ctx context.Context) error {
+	checked := make(map[string]bool)
+	for _, vt := range d.def.Tables {
+		if checked[vt.SourceBiz] {
+			continue
+		}
+		checked[vt.SourceBiz] = true
+		ds, loaded := d.lookup(vt.SourceBiz)
+		if !loaded {
+			return fmt.Errorf("底层业务组 '%s' 当前未注册", vt.SourceBiz)
+		}
+		if err := ds.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("底层业务组 '%s' 不健康: %w", vt.SourceBiz, err)
+		}
+	}
+	return nil
+}
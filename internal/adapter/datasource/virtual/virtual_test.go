@@ -0,0 +1,111 @@
+// file: internal/adapter/datasource/virtual/virtual_test.go
+package virtual
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDataSource 记录收到的最后一次查询，并返回预设的结果
+type recordingDataSource struct {
+	lastQuery port.QueryRequest
+	result    *port.QueryResult
+	schema    *port.SchemaResult
+}
+
+func (r *recordingDataSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	r.lastQuery = req
+	return r.result, nil
+}
+func (r *recordingDataSource) Mutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	return nil, nil
+}
+func (r *recordingDataSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return r.schema, nil
+}
+func (r *recordingDataSource) HealthCheck(context.Context) error { return nil }
+func (r *recordingDataSource) Type() string                      { return "recording" }
+
+func newTestVirtual() (*DataSource, *recordingDataSource) {
+	letters := &recordingDataSource{
+		result: &port.QueryResult{Data: map[string]interface{}{"items": []interface{}{}, "total": float64(0)}},
+		schema: &port.SchemaResult{Tables: map[string][]port.FieldDescription{
+			"letters": {{Name: "sender"}, {Name: "era"}},
+		}},
+	}
+	def := domain.VirtualBiz{
+		BizName: "republic_collection",
+		Tables: []domain.VirtualTable{
+			{Name: "republic_letters", SourceBiz: "letters", SourceTable: "letters",
+				Filters: []domain.VirtualTableFilter{{Field: "era", Value: "民国"}}},
+			{Name: "missing", SourceBiz: "ghost", SourceTable: "t"},
+		},
+	}
+	lookup := func(biz string) (port.DataSource, bool) {
+		if biz == "letters" {
+			return letters, true
+		}
+		return nil, false
+	}
+	return New(def, lookup), letters
+}
+
+func TestQuery_RoutesAndInjectsFilters(t *testing.T) {
+	ctx := context.Background()
+	vds, letters := newTestVirtual()
+
+	userQuery := map[string]interface{}{
+		"table":   "republic_letters",
+		"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+	}
+	_, err := vds.Query(ctx, port.QueryRequest{BizName: "republic_collection", Query: userQuery})
+	require.NoError(t, err)
+
+	assert.Equal(t, "letters", letters.lastQuery.BizName)
+	assert.Equal(t, "letters", letters.lastQuery.Query["table"])
+	filters := letters.lastQuery.Query["filters"].([]interface{})
+	require.Len(t, filters, 2)
+	assert.Equal(t, map[string]interface{}{"field": "era", "value": "民国", "logic": "AND"}, filters[0])
+	assert.Equal(t, "republic_letters", userQuery["table"], "调用方的查询不应被修改")
+
+	_, err = vds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{
+		"table":   "republic_letters",
+		"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "a", "logic": "OR"}, map[string]interface{}{"field": "sender", "value": "b"}},
+	}})
+	assert.Error(t, err, "带固定过滤条件的虚拟表应拒绝 OR 逻辑")
+
+	_, err = vds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"table": "nope"}})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = vds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"table": "missing"}})
+	assert.Error(t, err, "底层业务组未注册时应报错")
+
+	_, err = vds.Mutate(ctx, port.MutateRequest{})
+	assert.ErrorIs(t, err, port.ErrPermissionDenied)
+}
+
+func TestQuery_RecordModeChecksFilters(t *testing.T) {
+	ctx := context.Background()
+	vds, letters := newTestVirtual()
+
+	letters.result = &port.QueryResult{Data: map[string]interface{}{
+		"record": map[string]interface{}{"sender": "鲁迅", "era": "晚清"}, "matches": float64(1),
+	}}
+	result, err := vds.Query(ctx, port.QueryRequest{Query: map[string]interface{}{
+		port.QueryModeKey: port.QueryModeRecord, "table": "republic_letters", "id": "1",
+	}})
+	require.NoError(t, err)
+	assert.Nil(t, result.Data["record"], "不满足固定过滤条件的记录不应可见")
+}
+
+func TestGetSchema_RenamesTables(t *testing.T) {
+	vds, _ := newTestVirtual()
+	schema, err := vds.GetSchema(context.Background(), port.SchemaRequest{TableName: "republic_letters"})
+	require.NoError(t, err)
+	assert.Len(t, schema.Tables["republic_letters"], 2)
+	assert.NotContains(t, schema.Tables, "letters")
+}
@@ -0,0 +1,280 @@
+// Package objectstore file: internal/adapter/objectstore/s3.go
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// emptyPayloadHash 是空请求体的 SHA-256，只读请求都没有请求体
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	defaultTimeout   = 30 * time.Second
+)
+
+// S3Config 描述一个 S3 兼容的对象存储 (AWS S3、MinIO、Ceph RGW 等)
+type S3Config struct {
+	// Endpoint 为空时使用 AWS 的区域端点 https://s3.<region>.amazonaws.com
+	Endpoint string `json:"endpoint,omitempty"`
+	Region   string `json:"region"`
+	Bucket   string `json:"bucket"`
+	// PathStyle 为 true 时以 <endpoint>/<bucket>/<key> 访问，MinIO 等自建服务通常需要；否则使用虚拟主机风格
+	PathStyle bool `json:"path_style,omitempty"`
+
+	// 凭据不应写入配置文件，见 CredentialsFromEnv
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	SessionToken    string `json:"-"`
+}
+
+// CredentialsFromEnv 从标准的 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 与 AWS_SESSION_TOKEN 环境变量读取凭据
+func (c *S3Config) CredentialsFromEnv() {
+	c.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	c.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	c.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+}
+
+// Object 是列表中的一个对象
+type Object struct {
+	Key          string
+	ETag         string
+	Size         int64
+	LastModified time.Time
+}
+
+// S3Client 是只读的 S3 客户端，只实现同步库文件所需的列表与下载，以 Signature V4 签名
+type S3Client struct {
+	cfg      S3Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+// NewS3Client 校验配置并创建客户端
+func NewS3Client(cfg S3Config) (*S3Client, error) {
+	if cfg.Region == "" || cfg.Bucket == "" {
+		return nil, errors.New("S3 配置需要 region 与 bucket")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("S3 凭据缺失: 请设置 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY")
+	}
+	raw := cfg.Endpoint
+	if raw == "" {
+		raw = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("无效的 S3 端点 '%s'", raw)
+	}
+	return &S3Client{cfg: cfg, endpoint: endpoint, http: &http.Client{Timeout: defaultTimeout}, now: time.Now}, nil
+}
+
+// objectURL 返回对象 (key 为空时为桶) 的地址
+func (c *S3Client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+	if c.cfg.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.cfg.Bucket + "/" + key
+	} else {
+		u.Host = c.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// 以签名时相同的规则编码路径，服务端看到的路径与签名一致
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// escapePath 按 S3 的规则逐段编码对象键，保留 "/"
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode 按 Signature V4 的规则编码: 只保留非保留字符 A-Z a-z 0-9 - _ . ~
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('A' <= ch && ch <= 'Z') || ('a' <= ch && ch <= 'z') || ('0' <= ch && ch <= '9') || ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// List 列出键以 prefix 开头的全部对象，自动翻页
+func (c *S3Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		u := c.objectURL("")
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		u.RawQuery = q.Encode()
+		resp, err := c.do(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				ETag         string    `xml:"ETag"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析 S3 对象列表失败: %w", err)
+		}
+		for _, o := range page.Contents {
+			objects = append(objects, Object{Key: o.Key, ETag: strings.Trim(o.ETag, `"`), Size: o.Size, LastModified: o.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Get 下载对象，调用方负责关闭返回的 Body
+func (c *S3Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, c.objectURL(key))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do 发出签名的 GET 请求，非 2xx 响应转换为错误
+func (c *S3Client) do(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, c.now().UTC())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 S3 失败: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+		var apiErr struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+			return nil, fmt.Errorf("S3 返回 %d %s: %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return nil, fmt.Errorf("S3 返回 %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// sign 以 AWS Signature Version 4 为请求签名。签名覆盖 host、range 与全部 x-amz-* 头
+func (c *S3Client) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if c.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	scope := day + "/" + c.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretAccessKey), day)
+	for _, part := range []string{c.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, c.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return escapePath(u.Path)
+}
+
+// canonicalQuery 按参数名排序并以 Signature V4 的规则编码查询参数
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
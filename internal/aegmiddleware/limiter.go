@@ -2,6 +2,7 @@
 package aegmiddleware
 
 import (
+	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"ArchiveAegis/internal/service"
 	"bufio"
@@ -12,6 +13,8 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -48,6 +51,62 @@ type BusinessRateLimiter struct {
 
 	bizLimiters map[string]*limiterEntry
 	bizMu       sync.Mutex
+
+	// anonLimiters 是各业务组的匿名访问层，与 bizLimiters 分开计数
+	anonLimiters map[string]*anonymousEntry
+	anonMu       sync.Mutex
+
+	// penalties 记录被限流拒绝的次数并判断临时封禁，为 nil 时不封禁
+	penalties PenaltyBox
+}
+
+// PenaltyBox 保存需要跨重启保留的限流状态：违规计数与临时封禁。令牌桶本身只在内存中
+type PenaltyBox interface {
+	// Banned 判断对象当前是否处于封禁中，并返回截止时间
+	Banned(kind, subject string) (until time.Time, banned bool)
+	// Strike 记录一次被限流拒绝
+	Strike(kind, subject string)
+}
+
+// SetPenaltyBox 设置违规计数与临时封禁的存储，需在处理请求之前调用
+func (brl *BusinessRateLimiter) SetPenaltyBox(p PenaltyBox) {
+	brl.penalties = p
+}
+
+// rejectIfBanned 在对象处于临时封禁中时以 429 与 Retry-After 拒绝请求
+func (brl *BusinessRateLimiter) rejectIfBanned(w http.ResponseWriter, kind, subject string) bool {
+	if brl.penalties == nil {
+		return false
+	}
+	until, banned := brl.penalties.Banned(kind, subject)
+	if !banned {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(until).Seconds())+1))
+	errResp(w, http.StatusTooManyRequests, "请求过于频繁，已被临时封禁，请稍后再试")
+	return true
+}
+
+func (brl *BusinessRateLimiter) strike(kind, subject string) {
+	if brl.penalties != nil {
+		brl.penalties.Strike(kind, subject)
+	}
+}
+
+// anonymousEntry 是一个业务组匿名访问层的限制器及其附加限制
+type anonymousEntry struct {
+	limiterEntry
+	maxPageSize int
+	delay       time.Duration
+}
+
+// anonymousPageCapKey 是匿名访问层每页记录数上限在请求上下文中的键
+type anonymousPageCapKey struct{}
+
+// AnonymousPageSizeCap 返回匿名访问层为本次请求设置的每页记录数上限，0 表示不限制
+func AnonymousPageSizeCap(r *http.Request) int {
+	limit, _ := r.Context().Value(anonymousPageCapKey{}).(int)
+	return limit
 }
 
 // NewBusinessRateLimiter 创建一个新的、功能完备的业务速率限制器。
@@ -65,7 +124,8 @@ func NewBusinessRateLimiter(cs port.QueryAdminConfigService, globalRate float64,
 		userDefaultRate:  5.0,
 		userDefaultBurst: 15,
 
-		bizLimiters: make(map[string]*limiterEntry),
+		bizLimiters:  make(map[string]*limiterEntry),
+		anonLimiters: make(map[string]*anonymousEntry),
 	}
 
 	if cs != nil {
@@ -137,9 +197,73 @@ func (brl *BusinessRateLimiter) cleanupBizs() {
 			}
 		}
 		brl.bizMu.Unlock()
+
+		brl.anonMu.Lock()
+		for name, entry := range brl.anonLimiters {
+			if time.Since(entry.lastSeen) > 15*time.Minute {
+				delete(brl.anonLimiters, name)
+			}
+		}
+		brl.anonMu.Unlock()
 	}
 }
 
+// Snapshot 返回活跃的令牌桶及其剩余令牌数，dimension 为空时返回所有维度，key 非空时只返回该对象的令牌桶。
+// 结果按剩余令牌数从少到多排列，最接近被拒绝的对象排在最前
+func (brl *BusinessRateLimiter) Snapshot(dimension, key string) []domain.RateLimiterBucket {
+	buckets := make([]domain.RateLimiterBucket, 0)
+	add := func(dim, k string, limiter *rate.Limiter, lastSeen *time.Time) {
+		if (dimension != "" && dimension != dim) || (key != "" && key != k) {
+			return
+		}
+		buckets = append(buckets, domain.RateLimiterBucket{
+			Dimension:     dim,
+			Key:           k,
+			RatePerSecond: float64(limiter.Limit()),
+			Burst:         limiter.Burst(),
+			Tokens:        limiter.Tokens(),
+			LastSeen:      lastSeen,
+		})
+	}
+
+	add(domain.LimiterDimensionGlobal, "*", brl.globalLimiter, nil)
+	brl.ipMu.Lock()
+	for ip, entry := range brl.ipLimiters {
+		lastSeen := entry.lastSeen
+		add(domain.LimiterDimensionIP, ip, entry.limiter, &lastSeen)
+	}
+	brl.ipMu.Unlock()
+	brl.userMu.Lock()
+	for id, entry := range brl.userLimiters {
+		lastSeen := entry.lastSeen
+		add(domain.LimiterDimensionUser, strconv.FormatInt(id, 10), entry.limiter, &lastSeen)
+	}
+	brl.userMu.Unlock()
+	brl.bizMu.Lock()
+	for name, entry := range brl.bizLimiters {
+		lastSeen := entry.lastSeen
+		add(domain.LimiterDimensionBiz, name, entry.limiter, &lastSeen)
+	}
+	brl.bizMu.Unlock()
+	brl.anonMu.Lock()
+	for name, entry := range brl.anonLimiters {
+		lastSeen := entry.lastSeen
+		add(domain.LimiterDimensionAnonymous, name, entry.limiter, &lastSeen)
+	}
+	brl.anonMu.Unlock()
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Tokens != buckets[j].Tokens {
+			return buckets[i].Tokens < buckets[j].Tokens
+		}
+		if buckets[i].Dimension != buckets[j].Dimension {
+			return buckets[i].Dimension < buckets[j].Dimension
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
 // ==================================================================
 //  模块化的中间件方法
 // ==================================================================
@@ -159,6 +283,9 @@ func (brl *BusinessRateLimiter) Global(next http.Handler) http.Handler {
 func (brl *BusinessRateLimiter) PerIP(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ip := getClientIP(r)
+		if brl.rejectIfBanned(w, domain.PenaltyKindIP, ip) {
+			return
+		}
 		brl.ipMu.Lock()
 		entry, exists := brl.ipLimiters[ip]
 		if !exists {
@@ -170,6 +297,7 @@ func (brl *BusinessRateLimiter) PerIP(next http.Handler) http.Handler {
 		brl.ipMu.Unlock()
 
 		if !entry.limiter.Allow() {
+			brl.strike(domain.PenaltyKindIP, ip)
 			errResp(w, http.StatusTooManyRequests, "您的请求过于频繁，请稍后再试 (per-ip limit)")
 			return
 		}
@@ -187,6 +315,9 @@ func (brl *BusinessRateLimiter) PerUser(next http.Handler) http.Handler {
 		}
 
 		userID := claims.ID
+		if brl.rejectIfBanned(w, domain.PenaltyKindUser, strconv.FormatInt(userID, 10)) {
+			return
+		}
 		brl.userMu.Lock()
 		entry, exists := brl.userLimiters[userID]
 
@@ -211,6 +342,7 @@ func (brl *BusinessRateLimiter) PerUser(next http.Handler) http.Handler {
 		brl.userMu.Unlock()
 
 		if !entry.limiter.Allow() {
+			brl.strike(domain.PenaltyKindUser, strconv.FormatInt(userID, 10))
 			errResp(w, http.StatusTooManyRequests, "您的账户请求过于频繁，请稍后再试 (per-user limit)")
 			return
 		}
@@ -255,7 +387,12 @@ func (brl *BusinessRateLimiter) PerBiz(next http.Handler) http.Handler {
 			return
 		}
 
-		// 后续的速率限制逻辑完全不变
+		// 未认证的访问者走独立的匿名访问层，不占用已登录用户的业务组配额
+		if service.ClaimFrom(r) == nil {
+			brl.serveAnonymous(w, r, bizName, next)
+			return
+		}
+
 		brl.bizMu.Lock()
 		entry, exists := brl.bizLimiters[bizName]
 		if !exists {
@@ -281,6 +418,48 @@ func (brl *BusinessRateLimiter) PerBiz(next http.Handler) http.Handler {
 	})
 }
 
+// serveAnonymous 按业务组的匿名访问层限制未认证请求：独立的速率与峰值、可选的固定延迟，
+// 以及通过请求上下文传递给检索处理器的每页记录数上限
+func (brl *BusinessRateLimiter) serveAnonymous(w http.ResponseWriter, r *http.Request, bizName string, next http.Handler) {
+	brl.anonMu.Lock()
+	entry, exists := brl.anonLimiters[bizName]
+	if !exists {
+		rateLimit, burstSize := brl.userDefaultRate, brl.userDefaultBurst
+		entry = &anonymousEntry{}
+		if bizSettings, err := brl.configService.GetBizRateLimitSettings(r.Context(), bizName); err == nil && bizSettings != nil {
+			rateLimit, burstSize = rate.Limit(bizSettings.RateLimitPerSecond), bizSettings.BurstSize
+			if anon := bizSettings.Anonymous; anon != nil {
+				rateLimit, burstSize = rate.Limit(anon.RateLimitPerSecond), anon.BurstSize
+				entry.maxPageSize = anon.MaxPageSize
+				entry.delay = time.Duration(anon.ResponseDelayMs) * time.Millisecond
+				log.Printf("调试: [Business Limiter] 为业务组 %s 加载了匿名访问层限制: %.2f req/s, burst %d", bizName, rateLimit, burstSize)
+			}
+		}
+		entry.limiter = rate.NewLimiter(rateLimit, burstSize)
+		brl.anonLimiters[bizName] = entry
+	}
+	entry.lastSeen = time.Now()
+	brl.anonMu.Unlock()
+
+	if !entry.limiter.Allow() {
+		errResp(w, http.StatusTooManyRequests, "匿名访问过于频繁，登录后可获得更高的配额 (anonymous limit)")
+		return
+	}
+	if entry.delay > 0 {
+		timer := time.NewTimer(entry.delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+	if entry.maxPageSize > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), anonymousPageCapKey{}, entry.maxPageSize))
+	}
+	next.ServeHTTP(w, r)
+}
+
 // FullBusinessChain 组合了所有四个限制层，用于核心业务API。
 func (brl *BusinessRateLimiter) FullBusinessChain(next http.Handler) http.Handler {
 	// 顺序: Global -> IP -> User -> Biz -> Handler
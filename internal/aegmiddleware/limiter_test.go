@@ -62,6 +62,21 @@ func (m *mockAdminConfigService) UpdateTableWritePermissions(ctx context.Context
 func (m *mockAdminConfigService) UpdateTableFieldSettings(ctx context.Context, bizName, tableName string, fields []domain.FieldSetting) error {
 	return nil
 }
+func (m *mockAdminConfigService) UpdateFieldSettingsBulk(ctx context.Context, bizName string, tables map[string][]domain.FieldSetting) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableFTSConfig(ctx context.Context, bizName, tableName string, cfg *domain.FTSConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableRankingConfig(ctx context.Context, bizName, tableName string, cfg *domain.RankingConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableHierarchyConfig(ctx context.Context, bizName, tableName string, cfg *domain.HierarchyConfig) error {
+	return nil
+}
+func (m *mockAdminConfigService) UpdateTableSQLViewConfig(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) error {
+	return nil
+}
 func (m *mockAdminConfigService) GetDefaultViewConfig(ctx context.Context, bizName, tableName string) (*domain.ViewConfig, error) {
 	return nil, nil
 }
@@ -301,3 +316,141 @@ func TestBusinessRateLimiter_PerBiz(t *testing.T) {
 		}
 	})
 }
+
+func TestBusinessRateLimiter_AnonymousTier(t *testing.T) {
+	mockService := &mockAdminConfigService{}
+	mockService.GetBizRateLimitSettingsFunc = func(ctx context.Context, bizName string) (*domain.BizRateLimitSetting, error) {
+		return &domain.BizRateLimitSetting{
+			RateLimitPerSecond: 1.0,
+			BurstSize:          1,
+			Anonymous:          &domain.AnonymousAccessSetting{RateLimitPerSecond: 1.0, BurstSize: 1, MaxPageSize: 20, ResponseDelayMs: 30},
+		}, nil
+	}
+	limiter := aegmiddleware.NewBusinessRateLimiter(mockService, 100, 100)
+
+	var pageCap int
+	middleware := limiter.PerBiz(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageCap = aegmiddleware.AnonymousPageSizeCap(r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(claims *service.Claim) (int, time.Duration) {
+		req := httptest.NewRequest("GET", "/data/query?biz=archive", nil)
+		if claims != nil {
+			req = addClaimToContext(req, claims)
+		}
+		rr := httptest.NewRecorder()
+		start := time.Now()
+		middleware.ServeHTTP(rr, req)
+		return rr.Code, time.Since(start)
+	}
+
+	code, elapsed := serve(nil)
+	if code != http.StatusOK {
+		t.Fatalf("First anonymous request should be allowed, got %d", code)
+	}
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("Anonymous request should be delayed by 30ms, took %v", elapsed)
+	}
+	if pageCap != 20 {
+		t.Errorf("Anonymous request should carry page size cap 20, got %d", pageCap)
+	}
+	if code, _ := serve(nil); code != http.StatusTooManyRequests {
+		t.Errorf("Second anonymous request should be blocked, got %d", code)
+	}
+
+	// 匿名访问层耗尽后，已登录用户仍使用自己的业务组配额，且不受延迟与页大小上限影响
+	pageCap = -1
+	code, elapsed = serve(&service.Claim{ID: 1, Role: "user"})
+	if code != http.StatusOK {
+		t.Fatalf("Authenticated request should not be affected by anonymous tier, got %d", code)
+	}
+	if pageCap != 0 || elapsed >= 30*time.Millisecond {
+		t.Errorf("Authenticated request should not be capped or delayed, cap=%d elapsed=%v", pageCap, elapsed)
+	}
+}
+
+// fakePenaltyBox 在违规次数达到 banAfter 时封禁对象
+type fakePenaltyBox struct {
+	strikes  map[string]int
+	banAfter int
+}
+
+func (f *fakePenaltyBox) Banned(kind, subject string) (time.Time, bool) {
+	if f.strikes[kind+":"+subject] >= f.banAfter {
+		return time.Now().Add(time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+func (f *fakePenaltyBox) Strike(kind, subject string) {
+	f.strikes[kind+":"+subject]++
+}
+
+func TestBusinessRateLimiter_PenaltyBox(t *testing.T) {
+	limiter := aegmiddleware.NewBusinessRateLimiter(nil, 100, 100)
+	limiter.SetIPDefaultRateForTest(1e-6, 1)
+	box := &fakePenaltyBox{strikes: make(map[string]int), banAfter: 2}
+	limiter.SetPenaltyBox(box)
+	middleware := limiter.PerIP(testHandler)
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.0.2.9:12345"
+		rr := httptest.NewRecorder()
+		middleware.ServeHTTP(rr, req)
+		return rr
+	}
+	if rr := serve(); rr.Code != http.StatusOK {
+		t.Fatalf("第一个请求应放行，实际为 %d", rr.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if rr := serve(); rr.Code != http.StatusTooManyRequests {
+			t.Fatalf("超出速率的请求应被拒绝，实际为 %d", rr.Code)
+		}
+	}
+	if got := box.strikes[domain.PenaltyKindIP+":192.0.2.9"]; got != 2 {
+		t.Fatalf("每次被限流拒绝应记一次违规，实际为 %d", got)
+	}
+	rr := serve()
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("封禁期间应以 429 与 Retry-After 拒绝，实际为 %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if got := box.strikes[domain.PenaltyKindIP+":192.0.2.9"]; got != 2 {
+		t.Errorf("封禁期间的请求不再计数，实际为 %d", got)
+	}
+}
+
+func TestBusinessRateLimiter_Snapshot(t *testing.T) {
+	limiter := aegmiddleware.NewBusinessRateLimiter(nil, 100, 100)
+	limiter.SetIPDefaultRateForTest(1, 5)
+	middleware := limiter.PerIP(testHandler)
+	serve := func(addr string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = addr
+		middleware.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 5; i++ {
+		serve("192.0.2.1:12345")
+	}
+	serve("192.0.2.2:12345")
+
+	buckets := limiter.Snapshot(domain.LimiterDimensionIP, "")
+	if len(buckets) != 2 {
+		t.Fatalf("期望 2 个 IP 令牌桶，实际为 %+v", buckets)
+	}
+	if buckets[0].Key != "192.0.2.1" || buckets[0].Tokens >= 1 {
+		t.Errorf("令牌耗尽的 IP 应排在最前，实际为 %+v", buckets[0])
+	}
+	if buckets[0].Burst != 5 || buckets[0].RatePerSecond != 1 || buckets[0].LastSeen == nil {
+		t.Errorf("令牌桶的设置错误: %+v", buckets[0])
+	}
+
+	one := limiter.Snapshot("", "192.0.2.2")
+	if len(one) != 1 || one[0].Tokens < 3 {
+		t.Errorf("按对象过滤时只返回该对象的令牌桶: %+v", one)
+	}
+	all := limiter.Snapshot("", "")
+	if len(all) != 3 {
+		t.Errorf("不过滤时应包含全局令牌桶，实际为 %+v", all)
+	}
+}
@@ -0,0 +1,143 @@
+// Package aegmiddleware internal/aegmiddleware/loadshed.go
+package aegmiddleware
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"errors"
+	"net/http"
+	"runtime/metrics"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultRetryAfterSeconds = 5
+	heapMetric               = "/memory/classes/heap/objects:bytes"
+
+	// 拒绝原因，对应 archiveaegis_load_shed_rejections_total 的 reason 标签
+	shedReasonInFlight = "in_flight"
+	shedReasonMemory   = "memory"
+)
+
+// LoadShedOptions 定义过载保护的阈值，各阈值为 0 表示不检查该项
+type LoadShedOptions struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxInFlight 是同时处理的数据平面请求数上限
+	MaxInFlight int `mapstructure:"max_in_flight"`
+	// MaxHeapMB 是堆上存活对象的大小上限 (MiB)，按采样间隔检查
+	MaxHeapMB int `mapstructure:"max_heap_mb"`
+	// RetryAfterSeconds 是拒绝时 Retry-After 响应头的取值，默认为 5 秒
+	RetryAfterSeconds int `mapstructure:"retry_after_seconds"`
+}
+
+// LoadShedder 在正在处理的请求数或内存占用超过阈值时，直接以 503 与 Retry-After 拒绝新的数据平面请求，
+// 避免请求排队使所有请求的延迟一起失控。它在速率限制之前生效，不区分用户与业务组
+type LoadShedder struct {
+	enabled     bool
+	maxInFlight int64
+	maxHeap     uint64
+	retryAfter  string
+
+	inFlight atomic.Int64
+	heap     atomic.Uint64 // 最近一次采样的堆大小
+	readHeap func() uint64
+}
+
+// NewLoadShedder 创建过载保护中间件，未启用时中间件直接放行
+func NewLoadShedder(opts LoadShedOptions) (*LoadShedder, error) {
+	if opts.MaxInFlight < 0 || opts.MaxHeapMB < 0 || opts.RetryAfterSeconds < 0 {
+		return nil, errors.New("max_in_flight、max_heap_mb 与 retry_after_seconds 不能为负数")
+	}
+	if opts.RetryAfterSeconds == 0 {
+		opts.RetryAfterSeconds = defaultRetryAfterSeconds
+	}
+	return &LoadShedder{
+		enabled:     opts.Enabled,
+		maxInFlight: int64(opts.MaxInFlight),
+		maxHeap:     uint64(opts.MaxHeapMB) << 20,
+		retryAfter:  strconv.Itoa(opts.RetryAfterSeconds),
+		readHeap:    readHeapBytes,
+	}, nil
+}
+
+// Run 按间隔采样堆大小，未启用或未设置内存阈值时立即返回，ctx 结束时返回。
+// 采样与请求处理分离，使中间件本身只做原子读取
+func (ls *LoadShedder) Run(ctx context.Context, interval time.Duration) {
+	if !ls.enabled || ls.maxHeap == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ls.heap.Store(ls.readHeap())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Middleware 按阈值拒绝或放行请求，并维护正在处理的请求数
+func (ls *LoadShedder) Middleware(next http.Handler) http.Handler {
+	if !ls.enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ls.maxHeap > 0 && ls.heap.Load() > ls.maxHeap {
+			ls.reject(w, shedReasonMemory)
+			return
+		}
+		current := ls.inFlight.Add(1)
+		defer func() {
+			ls.inFlight.Add(-1)
+			aegobserve.InFlightRequests.Dec()
+		}()
+		aegobserve.InFlightRequests.Inc()
+		if ls.maxInFlight > 0 && current > ls.maxInFlight {
+			ls.reject(w, shedReasonInFlight)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// State 返回过载保护的当前状态：正在处理的请求数、最近一次采样的堆大小及各自的阈值
+func (ls *LoadShedder) State() domain.LoadShedState {
+	state := domain.LoadShedState{
+		Enabled:      ls.enabled,
+		InFlight:     ls.inFlight.Load(),
+		MaxInFlight:  ls.maxInFlight,
+		HeapBytes:    ls.heap.Load(),
+		MaxHeapBytes: ls.maxHeap,
+	}
+	if !state.Enabled {
+		return state
+	}
+	switch {
+	case state.MaxHeapBytes > 0 && state.HeapBytes > state.MaxHeapBytes:
+		state.Shedding, state.Reason = true, shedReasonMemory
+	case state.MaxInFlight > 0 && state.InFlight >= state.MaxInFlight:
+		state.Shedding, state.Reason = true, shedReasonInFlight
+	}
+	return state
+}
+
+func (ls *LoadShedder) reject(w http.ResponseWriter, reason string) {
+	aegobserve.LoadShedRejections.WithLabelValues(reason).Inc()
+	w.Header().Set("Retry-After", ls.retryAfter)
+	errResp(w, http.StatusServiceUnavailable, "网关当前负载过高，请稍后重试 (load shedding)")
+}
+
+// readHeapBytes 读取堆上存活对象的大小，不触发 stop-the-world
+func readHeapBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
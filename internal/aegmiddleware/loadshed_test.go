@@ -0,0 +1,117 @@
+// file: internal/aegmiddleware/loadshed_test.go
+
+package aegmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadShedder_InFlight(t *testing.T) {
+	ls, err := NewLoadShedder(LoadShedOptions{Enabled: true, MaxInFlight: 1, RetryAfterSeconds: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var once sync.Once
+	handler := ls.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+	}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/data/query", nil))
+	}()
+	<-started
+	if state := ls.State(); !state.Shedding || state.Reason != shedReasonInFlight || state.InFlight != 1 {
+		t.Errorf("达到并发上限时状态应为正在拒绝，实际为 %+v", state)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/data/query", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("超过并发上限时期望 503，实际为 %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("Retry-After 期望为 7，实际为 %q", got)
+	}
+
+	close(release)
+	wg.Wait()
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/data/query", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("请求完成后期望恢复放行，实际为 %d", rr.Code)
+	}
+	if n := ls.inFlight.Load(); n != 0 {
+		t.Errorf("正在处理的请求数期望归零，实际为 %d", n)
+	}
+}
+
+func TestLoadShedder_Memory(t *testing.T) {
+	ls, err := NewLoadShedder(LoadShedOptions{Enabled: true, MaxHeapMB: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	heap := uint64(50 << 20)
+	var mu sync.Mutex
+	ls.readHeap = func() uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return heap
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ls.Run(ctx, time.Millisecond)
+
+	handler := ls.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/data/query", nil))
+		return rr
+	}
+	waitFor := func(code int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for serve().Code != code {
+			if time.Now().After(deadline) {
+				t.Fatalf("等待状态码 %d 超时", code)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor(http.StatusOK)
+	mu.Lock()
+	heap = 200 << 20
+	mu.Unlock()
+	waitFor(http.StatusServiceUnavailable)
+	if got := serve().Header().Get("Retry-After"); got != "5" {
+		t.Errorf("默认 Retry-After 期望为 5，实际为 %q", got)
+	}
+	if state := ls.State(); !state.Shedding || state.Reason != shedReasonMemory || state.MaxHeapBytes != 100<<20 {
+		t.Errorf("超过内存阈值时状态应为正在拒绝，实际为 %+v", state)
+	}
+}
+
+func TestLoadShedder_Disabled(t *testing.T) {
+	ls, err := NewLoadShedder(LoadShedOptions{MaxInFlight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	if h := ls.Middleware(next); h == nil {
+		t.Fatal("未启用时应直接返回下一个处理器")
+	}
+	if _, err := NewLoadShedder(LoadShedOptions{MaxInFlight: -1}); err == nil {
+		t.Error("负数阈值应返回错误")
+	}
+}
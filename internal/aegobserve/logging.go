@@ -2,37 +2,274 @@
 package aegobserve
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// InitLogger 初始化全局的结构化日志记录器。
-// 它应该在 main 函数的早期被调用。
-func InitLogger(levelStr string) {
-	var level slog.Level
+// 日志输出格式
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+var (
+	// ErrInvalidLogSettings 表示日志级别、输出格式或模块名不合法
+	ErrInvalidLogSettings = errors.New("无效的日志设置")
+	// ErrLoggerNotInitialized 表示未启用高级可观测性，InitLogger 没有被调用
+	ErrLoggerNotInitialized = errors.New("结构化日志未启用")
+)
+
+// logModules 把可单独设置级别的模块映射到其源码目录，日志按调用位置所在的文件归属模块。
+// 通过标准库 log 输出的日志同样按调用位置归属
+var logModules = map[string]string{
+	"router":         "/internal/transport/",
+	"plugin_manager": "/internal/service/plugin_manager/",
+	"adapter":        "/internal/adapter/",
+}
+
+// LogModules 返回可单独设置日志级别的模块名
+func LogModules() []string {
+	names := make([]string, 0, len(logModules))
+	for name := range logModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	// 根据配置字符串设置日志级别
-	switch strings.ToUpper(levelStr) {
+// LogSettings 描述全局日志级别、输出格式与各模块的级别
+type LogSettings struct {
+	Level  string `json:"level" mapstructure:"log_level"`
+	Format string `json:"format" mapstructure:"log_format"`
+	// Modules 是各模块的日志级别，未列出的模块使用全局级别
+	Modules map[string]string `json:"modules,omitempty" mapstructure:"log_modules"`
+}
+
+// ParseLogLevel 解析 debug / info / warn / error (不区分大小写)
+func ParseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
 	case "DEBUG":
-		level = slog.LevelDebug
+		return slog.LevelDebug, nil
 	case "INFO":
-		level = slog.LevelInfo
-	case "WARN":
-		level = slog.LevelWarn
+		return slog.LevelInfo, nil
+	case "WARN", "WARNING":
+		return slog.LevelWarn, nil
 	case "ERROR":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo // 默认为 INFO 级别
+		return slog.LevelError, nil
 	}
+	return 0, fmt.Errorf("%w: 未知的日志级别 '%s'，可选 debug / info / warn / error", ErrInvalidLogSettings, s)
+}
 
-	// 创建一个 JSON 格式的处理器，输出到标准输出
-	// JSON 格式是生产环境的最佳实践
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     level,
-		AddSource: true, // 添加代码源位置（文件:行号），方便调试
-	})
+func levelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// logState 是运行期可修改的日志配置，由所有派生的处理器共享
+type logState struct {
+	mu      sync.RWMutex
+	out     io.Writer
+	format  string
+	level   slog.Level
+	modules map[string]slog.Level
+	base    slog.Handler // 不做级别过滤的底层处理器，级别由 runtimeHandler 判断
+	minimum slog.Level   // 全局与各模块级别中最低的一个
+}
+
+// state 在 InitLogger 之前为 nil
+var (
+	stateMu sync.RWMutex
+	state   *logState
+)
+
+// pcModules 缓存调用位置到模块名的映射
+var pcModules sync.Map
+
+// InitLogger 初始化全局的结构化日志记录器，之后可通过 UpdateLogSettings 在运行期调整。
+// 它应该在 main 函数的早期被调用。级别为空时使用 info，格式为空时使用 json
+func InitLogger(settings LogSettings) error {
+	return initLogger(os.Stdout, settings)
+}
+
+func initLogger(out io.Writer, settings LogSettings) error {
+	st := &logState{out: out, format: LogFormatJSON, level: slog.LevelInfo, modules: make(map[string]slog.Level)}
+	if err := st.apply(settings); err != nil {
+		return err
+	}
+	stateMu.Lock()
+	state = st
+	stateMu.Unlock()
 
 	// 将我们创建的 logger 设置为全局默认 logger
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(&runtimeHandler{state: st}))
+	return nil
+}
+
+// CurrentLogSettings 返回当前的日志设置
+func CurrentLogSettings() (LogSettings, error) {
+	stateMu.RLock()
+	st := state
+	stateMu.RUnlock()
+	if st == nil {
+		return LogSettings{}, ErrLoggerNotInitialized
+	}
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	settings := LogSettings{Level: levelName(st.level), Format: st.format, Modules: make(map[string]string, len(st.modules))}
+	for name, level := range st.modules {
+		settings.Modules[name] = levelName(level)
+	}
+	return settings, nil
+}
+
+// UpdateLogSettings 在运行期修改日志设置并立即生效。Level 与 Format 为空时保持不变；
+// Modules 中列出的模块被设为对应级别，级别为空字符串或 "inherit" 时恢复使用全局级别
+func UpdateLogSettings(settings LogSettings) (LogSettings, error) {
+	stateMu.RLock()
+	st := state
+	stateMu.RUnlock()
+	if st == nil {
+		return LogSettings{}, ErrLoggerNotInitialized
+	}
+	if err := st.apply(settings); err != nil {
+		return LogSettings{}, err
+	}
+	return CurrentLogSettings()
+}
+
+// apply 校验并合并设置，任一项不合法时不修改任何设置
+func (st *logState) apply(settings LogSettings) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	level, format := st.level, st.format
+	modules := make(map[string]slog.Level, len(st.modules))
+	for name, l := range st.modules {
+		modules[name] = l
+	}
+
+	if settings.Level != "" {
+		l, err := ParseLogLevel(settings.Level)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+	if settings.Format != "" {
+		format = strings.ToLower(settings.Format)
+		if format != LogFormatJSON && format != LogFormatText {
+			return fmt.Errorf("%w: 未知的输出格式 '%s'，可选 json / text", ErrInvalidLogSettings, settings.Format)
+		}
+	}
+	for name, s := range settings.Modules {
+		if _, ok := logModules[name]; !ok {
+			return fmt.Errorf("%w: 未知的模块 '%s'，可选 %s", ErrInvalidLogSettings, name, strings.Join(LogModules(), " / "))
+		}
+		if s == "" || strings.EqualFold(s, "inherit") {
+			delete(modules, name)
+			continue
+		}
+		l, err := ParseLogLevel(s)
+		if err != nil {
+			return err
+		}
+		modules[name] = l
+	}
+
+	minimum := level
+	for _, l := range modules {
+		if l < minimum {
+			minimum = l
+		}
+	}
+	opts := &slog.HandlerOptions{
+		Level:     slog.LevelDebug,
+		AddSource: true, // 添加代码源位置（文件:行号），方便调试
+	}
+
+	if st.base == nil || format != st.format {
+		if format == LogFormatText {
+			st.base = slog.NewTextHandler(st.out, opts)
+		} else {
+			// JSON 格式是生产环境的最佳实践
+			st.base = slog.NewJSONHandler(st.out, opts)
+		}
+	}
+	st.level, st.format, st.modules, st.minimum = level, format, modules, minimum
+	return nil
+}
+
+// threshold 返回调用位置所属模块的日志级别
+func (st *logState) threshold(pc uintptr) slog.Level {
+	if len(st.modules) > 0 && pc != 0 {
+		if level, ok := st.modules[moduleOf(pc)]; ok {
+			return level
+		}
+	}
+	return st.level
+}
+
+// moduleOf 根据调用位置所在的源码文件判断模块，不属于任何模块时返回空字符串
+func moduleOf(pc uintptr) string {
+	if name, ok := pcModules.Load(pc); ok {
+		return name.(string)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	file := strings.ReplaceAll(frame.File, "\\", "/")
+	module := ""
+	for name, dir := range logModules {
+		if strings.Contains(file, dir) {
+			module = name
+			break
+		}
+	}
+	pcModules.Store(pc, module)
+	return module
+}
+
+// runtimeHandler 按共享的 logState 过滤并输出日志。WithAttrs / WithGroup 被记录下来，
+// 每次输出时作用在当前的底层处理器上，使切换输出格式后派生的 logger 仍然有效
+type runtimeHandler struct {
+	state *logState
+	ops   []func(slog.Handler) slog.Handler
+}
+
+func (h *runtimeHandler) Enabled(_ context.Context, level slog.Level) bool {
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	return level >= h.state.minimum
+}
+
+func (h *runtimeHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.state.mu.RLock()
+	threshold := h.state.threshold(r.PC)
+	handler := h.state.base
+	h.state.mu.RUnlock()
+	if r.Level < threshold {
+		return nil
+	}
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *runtimeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *runtimeHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *runtimeHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &runtimeHandler{state: h.state, ops: append(ops, op)}
 }
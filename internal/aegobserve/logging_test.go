@@ -0,0 +1,68 @@
+// file: internal/aegobserve/logging_test.go
+
+package aegobserve
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateLogSettings(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	// 测试代码位于 aegobserve 目录，临时把它登记为 router 模块
+	logModules["router"] = "/internal/aegobserve/"
+	pcModules = sync.Map{}
+	defer func() {
+		logModules["router"] = "/internal/transport/"
+		pcModules = sync.Map{}
+	}()
+
+	var out bytes.Buffer
+	if err := initLogger(&out, LogSettings{Level: "warn"}); err != nil {
+		t.Fatalf("初始化日志失败: %v", err)
+	}
+
+	slog.Info("info-hidden")
+	slog.Warn("warn-shown")
+	if strings.Contains(out.String(), "info-hidden") || !strings.Contains(out.String(), `"msg":"warn-shown"`) {
+		t.Fatalf("全局级别为 warn 时的输出不正确: %s", out.String())
+	}
+
+	settings, err := UpdateLogSettings(LogSettings{Format: "text", Modules: map[string]string{"router": "debug"}})
+	if err != nil {
+		t.Fatalf("修改日志设置失败: %v", err)
+	}
+	if settings.Level != "warn" || settings.Format != LogFormatText || settings.Modules["router"] != "debug" {
+		t.Fatalf("返回的日志设置不正确: %+v", settings)
+	}
+	out.Reset()
+	logger := slog.With("component", "test")
+	logger.Debug("debug-shown")
+	if !strings.Contains(out.String(), "msg=debug-shown") || !strings.Contains(out.String(), "component=test") {
+		t.Fatalf("模块级别为 debug、格式为 text 时的输出不正确: %s", out.String())
+	}
+
+	if _, err := UpdateLogSettings(LogSettings{Modules: map[string]string{"router": "inherit"}}); err != nil {
+		t.Fatalf("恢复模块级别失败: %v", err)
+	}
+	out.Reset()
+	logger.Info("info-hidden-again")
+	if out.Len() != 0 {
+		t.Fatalf("模块恢复使用全局级别后不应输出 info: %s", out.String())
+	}
+
+	for _, invalid := range []LogSettings{{Level: "verbose"}, {Format: "xml"}, {Modules: map[string]string{"unknown": "debug"}}} {
+		if _, err := UpdateLogSettings(invalid); !errors.Is(err, ErrInvalidLogSettings) {
+			t.Fatalf("非法设置 %+v 应返回 ErrInvalidLogSettings，实际: %v", invalid, err)
+		}
+	}
+	if current, _ := CurrentLogSettings(); current.Level != "warn" || current.Format != LogFormatText {
+		t.Fatalf("非法设置不应修改当前设置: %+v", current)
+	}
+}
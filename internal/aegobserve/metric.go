@@ -4,11 +4,11 @@ package aegobserve
 import (
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors" // 1. 导入新的包
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -19,12 +19,64 @@ var (
 		Help:    "HTTP请求的延迟（秒）",
 		Buckets: prometheus.DefBuckets, // 使用默认的延迟分桶
 	}, []string{"path", "method", "code"})
+
+	// QueryCacheRequests 统计查询缓存的命中情况，result 取值为 hit / miss / bypass / stale (数据源不可用时返回旧结果)
+	QueryCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "archiveaegis_query_cache_requests_total",
+		Help: "查询缓存的请求数（按命中结果分类）",
+	}, []string{"biz", "result"})
+
+	// CoalescedQueries 统计与同时进行的相同查询合并、未单独到达插件的查询数
+	CoalescedQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "archiveaegis_coalesced_queries_total",
+		Help: "与进行中的相同查询合并、共享其结果的查询数（按业务组分类）",
+	}, []string{"biz"})
+
+	// PluginEvents 统计插件生命周期事件，event 取值见 plugin_manager 中的 Event* 常量
+	PluginEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "archiveaegis_plugin_events_total",
+		Help: "插件生命周期事件数（按插件与事件类型分类）",
+	}, []string{"plugin", "event"})
+
+	// LoadShedRejections 统计过载保护拒绝的请求数，reason 取值为 in_flight / memory
+	LoadShedRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "archiveaegis_load_shed_rejections_total",
+		Help: "过载保护以 503 拒绝的数据平面请求数（按原因分类）",
+	}, []string{"reason"})
+
+	// InFlightRequests 是正在处理的数据平面请求数
+	InFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "archiveaegis_in_flight_requests",
+		Help: "正在处理的数据平面请求数",
+	})
+
+	// StandbyReplicationLag 是备用网关最近一次应用的 auth.db 快照距今的秒数，只在备用网关上更新
+	StandbyReplicationLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "archiveaegis_standby_replication_lag_seconds",
+		Help: "备用网关最近一次应用的 auth.db 快照距今的秒数",
+	})
 )
 
+// 进程内的请求累计数，供每日摘要计算区间内的请求数与错误数，不依赖 Prometheus 注册表
+var (
+	requestsTotal atomic.Int64
+	errorsTotal   atomic.Int64
+)
+
+// RequestTotals 返回本进程启动以来处理的 HTTP 请求数与 5xx 响应数
+func RequestTotals() (requests, errors int64) {
+	return requestsTotal.Load(), errorsTotal.Load()
+}
+
+// Register 注册网关的指标。默认注册表已包含 Go 运行时与进程指标，重复注册会导致启动时 panic
 func Register() {
 	prometheus.MustRegister(httpRequestDuration)
-	prometheus.MustRegister(collectors.NewGoCollector())
-	prometheus.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	prometheus.MustRegister(QueryCacheRequests)
+	prometheus.MustRegister(CoalescedQueries)
+	prometheus.MustRegister(PluginEvents)
+	prometheus.MustRegister(LoadShedRejections)
+	prometheus.MustRegister(InFlightRequests)
+	prometheus.MustRegister(StandbyReplicationLag)
 }
 
 // Handler 返回 HTTP 处理器
@@ -50,5 +102,9 @@ func PrometheusMiddleware() gin.HandlerFunc {
 
 		// 记录到 Histogram
 		httpRequestDuration.WithLabelValues(path, c.Request.Method, statusCode).Observe(duration)
+		requestsTotal.Add(1)
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			errorsTotal.Add(1)
+		}
 	}
 }
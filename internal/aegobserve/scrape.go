@@ -0,0 +1,110 @@
+// Package aegobserve file: internal/aegobserve/scrape.go
+package aegobserve
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ScrapeConfig 描述监控端点的暴露方式
+type ScrapeConfig struct {
+	// MetricsAddress 是独立的 Prometheus 抓取监听地址，为空时不单独监听
+	MetricsAddress string `mapstructure:"metrics_address"`
+	// AllowedScrapers 是允许访问监控端点的来源 IP 或 CIDR，为空时不限制来源
+	AllowedScrapers []string `mapstructure:"allowed_scrapers"`
+}
+
+// Allowlist 是来源地址白名单。nil 或空白名单放行所有来源。
+type Allowlist struct {
+	nets []*net.IPNet
+}
+
+// ParseAllowlist 解析 IP 与 CIDR 列表，单个 IP 视为 /32 或 /128
+func ParseAllowlist(entries []string) (*Allowlist, error) {
+	a := &Allowlist{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的抓取来源地址 '%s'", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			a.nets = append(a.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("无效的抓取来源网段 '%s': %w", entry, err)
+		}
+		a.nets = append(a.nets, ipNet)
+	}
+	return a, nil
+}
+
+// Allows 判断请求的来源是否在白名单内。
+// 只使用连接的对端地址，不信任 X-Forwarded-For 等可伪造的请求头。
+func (a *Allowlist) Allows(r *http.Request) bool {
+	if a == nil || len(a.nets) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range a.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Guard 在 next 之前依次校验来源白名单与认证。
+// authenticate 负责校验凭证，校验失败时应自行写出响应且不调用 next。
+func Guard(next http.Handler, allow *Allowlist, authenticate func(http.Handler) http.Handler) http.Handler {
+	if authenticate != nil {
+		next = authenticate(next)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allow.Allows(r) {
+			http.Error(w, "来源地址不在抓取白名单内", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MetricsMux 返回只包含 /metrics 的处理器，用于独立的抓取监听
+func MetricsMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return mux
+}
+
+// Serve 在后台启动一个监听，addr 为空时不做任何事
+func Serve(name, addr string, handler http.Handler) {
+	if addr == "" {
+		slog.Info("监控端点未配置监听地址，已禁用", "endpoint", name)
+		return
+	}
+	go func() {
+		slog.Info("监控端点已启动", "endpoint", name, "address", addr)
+		if err := http.ListenAndServe(addr, handler); err != nil {
+			slog.Error("监控端点启动失败", "endpoint", name, "address", addr, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,89 @@
+// file: internal/aegobserve/scrape_test.go
+
+package aegobserve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowlist(t *testing.T) {
+	allow, err := ParseAllowlist([]string{"127.0.0.1", "10.0.0.0/8", "::1", " "})
+	if err != nil {
+		t.Fatalf("ParseAllowlist 失败: %v", err)
+	}
+
+	cases := map[string]bool{
+		"127.0.0.1:5000": true,
+		"10.1.2.3:80":    true,
+		"[::1]:9090":     true,
+		"192.168.1.1:80": false,
+		"127.0.0.2:5000": false,
+		"garbage":        false,
+	}
+	for remote, want := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		r.RemoteAddr = remote
+		if got := allow.Allows(r); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", remote, got, want)
+		}
+	}
+
+	// 伪造的转发头不影响判断
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.RemoteAddr = "192.168.1.1:80"
+	r.Header.Set("X-Forwarded-For", "127.0.0.1")
+	if allow.Allows(r) {
+		t.Errorf("X-Forwarded-For 不应被信任")
+	}
+
+	var empty *Allowlist
+	if !empty.Allows(r) {
+		t.Errorf("空白名单应放行所有来源")
+	}
+
+	if _, err := ParseAllowlist([]string{"not-an-ip"}); err == nil {
+		t.Errorf("无效地址应返回错误")
+	}
+	if _, err := ParseAllowlist([]string{"10.0.0.0/99"}); err == nil {
+		t.Errorf("无效网段应返回错误")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	allow, _ := ParseAllowlist([]string{"127.0.0.1"})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	requireHeader := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	h := Guard(ok, allow, requireHeader)
+
+	cases := []struct {
+		remote string
+		auth   string
+		want   int
+	}{
+		{"192.168.1.1:80", "Bearer x", http.StatusForbidden},
+		{"127.0.0.1:80", "", http.StatusUnauthorized},
+		{"127.0.0.1:80", "Bearer x", http.StatusOK},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		r.RemoteAddr = c.remote
+		if c.auth != "" {
+			r.Header.Set("Authorization", c.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != c.want {
+			t.Errorf("remote=%s auth=%q: got %d, want %d", c.remote, c.auth, w.Code, c.want)
+		}
+	}
+}
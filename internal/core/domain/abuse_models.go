@@ -0,0 +1,34 @@
+// Package domain file: internal/core/domain/abuse_models.go
+package domain
+
+import "time"
+
+// 异常行为的类型
+const (
+	// AbuseReasonScraping 是在时间窗口内以最大分页逐页抓取整张表
+	AbuseReasonScraping = "scraping"
+	// AbuseReasonForbidden 是在时间窗口内反复触发 403
+	AbuseReasonForbidden = "forbidden"
+)
+
+// AbuseFlag 是一次异常行为判定及其导致的临时封禁。Offense 是该对象近期的第几次判定，封禁时长随之递增
+type AbuseFlag struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject"`
+	Reason      string    `json:"reason"`
+	Detail      string    `json:"detail"`
+	Offense     int       `json:"offense"`
+	BanMinutes  int       `json:"ban_minutes"`
+	BannedUntil time.Time `json:"banned_until"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AbuseAllowEntry 是管理员添加的异常检测豁免对象，Kind 为 "ip" 时 Subject 可以是 CIDR
+type AbuseAllowEntry struct {
+	Kind      string    `json:"kind"`
+	Subject   string    `json:"subject"`
+	Note      string    `json:"note"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
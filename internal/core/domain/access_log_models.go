@@ -0,0 +1,29 @@
+// Package domain file: internal/core/domain/access_log_models.go
+package domain
+
+import "time"
+
+// 记录访问的来源
+const (
+	// RecordAccessQuery 表示记录出现在检索结果中
+	RecordAccessQuery = "query"
+	// RecordAccessPermalink 表示通过永久链接按主键读取记录
+	RecordAccessPermalink = "permalink"
+	// RecordAccessIIIF 表示为记录生成 IIIF 清单，清单中包含记录的题名、元数据与图像地址
+	RecordAccessIIIF = "iiif"
+)
+
+// RecordAccess 是敏感业务组的一条记录访问日志：谁在何时、从哪个 IP、以何种方式查看了哪条记录。
+// RecordID 为空表示表没有显式主键或检索结果未返回主键列，此时只能记录该用户检索了这张表。
+// Username 在访问时写入，账户删除后日志仍然可读
+type RecordAccess struct {
+	ID         int64     `json:"id"`
+	BizName    string    `json:"biz_name"`
+	TableName  string    `json:"table_name"`
+	RecordID   string    `json:"record_id"`
+	UserID     *int64    `json:"user_id,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	IP         string    `json:"ip"`
+	Source     string    `json:"source"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
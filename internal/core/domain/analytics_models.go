@@ -0,0 +1,66 @@
+// Package domain file: internal/core/domain/analytics_models.go
+package domain
+
+import "time"
+
+// BizUsageReport 汇总一个业务组在一段时间内的检索情况，供档案管理者改进元数据。
+// 检索词默认只以加盐哈希保存，Term 仅在开启 store_terms 时返回。
+type BizUsageReport struct {
+	BizName           string       `json:"biz_name"`
+	Since             string       `json:"since"` // 统计起始日期 (YYYY-MM-DD，UTC)
+	Queries           int64        `json:"queries"`
+	ZeroResultQueries int64        `json:"zero_result_queries"`
+	Daily             []DailyUsage `json:"daily"`
+	TopFields         []FieldUsage `json:"top_fields"`
+	TopTerms          []TermUsage  `json:"top_terms"`
+	ZeroResultTerms   []TermUsage  `json:"zero_result_terms"`
+	// TermSampleRate 是检索词的抽样比例，检索词次数需除以该比例才是估算的实际次数
+	TermSampleRate float64 `json:"term_sample_rate"`
+}
+
+// DailyUsage 是业务组某一天的检索次数
+type DailyUsage struct {
+	Day         string `json:"day"`
+	Queries     int64  `json:"queries"`
+	ZeroResults int64  `json:"zero_results"`
+}
+
+// FieldUsage 是某个字段被用作检索条件的次数
+type FieldUsage struct {
+	Field string `json:"field"`
+	Hits  int64  `json:"hits"`
+}
+
+// TermUsage 是某个检索词 (抽样后) 的出现次数及其中无结果的次数
+type TermUsage struct {
+	TermHash    string `json:"term_hash"`
+	Term        string `json:"term,omitempty"`
+	Hits        int64  `json:"hits"`
+	ZeroResults int64  `json:"zero_results"`
+}
+
+// ZeroResultSearch 是一条被记录下来的无结果检索，相同业务组、表与检索条件的检索合并为一条。
+// 管理员复核后可打标签并标记为已解决 (例如补充同义词或调整字段配置) 或忽略。
+type ZeroResultSearch struct {
+	ID          int64          `json:"id"`
+	BizName     string         `json:"biz_name"`
+	TableName   string         `json:"table_name"`
+	Filters     []SearchFilter `json:"filters"`
+	Occurrences int64          `json:"occurrences"`
+	FirstSeenAt time.Time      `json:"first_seen_at"`
+	LastSeenAt  time.Time      `json:"last_seen_at"`
+	Status      string         `json:"status"` // open, resolved, ignored
+	Tags        []string       `json:"tags"`
+	Resolution  string         `json:"resolution"`
+	ResolvedBy  *int64         `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// SearchFilter 是检索请求中的一个过滤条件
+type SearchFilter struct {
+	Field string `json:"field"`
+	Op    string `json:"op,omitempty"`
+	Value string `json:"value,omitempty"`
+	Logic string `json:"logic,omitempty"`
+	Fuzzy bool   `json:"fuzzy,omitempty"`
+}
@@ -0,0 +1,74 @@
+// Package domain file: internal/core/domain/annotation_models.go
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 批注类型
+const (
+	// AnnotationKindComment 是针对整条记录的文字批注
+	AnnotationKindComment = "comment"
+	// AnnotationKindCorrection 是针对某个字段的结构化勘误，给出建议的值
+	AnnotationKindCorrection = "correction"
+)
+
+// 批注审核状态
+const (
+	AnnotationStatusPending  = "pending"
+	AnnotationStatusApproved = "approved"
+	AnnotationStatusRejected = "rejected"
+)
+
+// Annotation 是用户附加在记录上的批注，不修改数据源中的原始数据。
+// 记录以与永久链接相同的 (业务组, 表, 主键, 库) 定位
+type Annotation struct {
+	ID        int64  `json:"id"`
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	RecordID  string `json:"record_id"`
+	Lib       string `json:"lib,omitempty"`
+	Kind      string `json:"kind"`
+	Body      string `json:"body"`
+	// Field 与 SuggestedValue 仅用于勘误
+	Field          string          `json:"field,omitempty"`
+	SuggestedValue json.RawMessage `json:"suggested_value,omitempty"`
+	// OriginalValue 是提交勘误时该字段的值，写回前据此判断记录是否已被他人修改
+	OriginalValue  json.RawMessage `json:"original_value,omitempty"`
+	Status         string          `json:"status"`
+	AuthorID       int64           `json:"author_id"`
+	AuthorName     string          `json:"author_name,omitempty"`
+	ModeratorID    *int64          `json:"moderator_id,omitempty"`
+	ModerationNote string          `json:"moderation_note,omitempty"`
+	ModeratedAt    *time.Time      `json:"moderated_at,omitempty"`
+	// AppliedAt 非空表示勘误已写回数据源
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	AppliedBy *int64     `json:"applied_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CorrectionDiff 是勘误写回前供审核者比对的差异
+type CorrectionDiff struct {
+	AnnotationID int64  `json:"annotation_id"`
+	Field        string `json:"field"`
+	// Original 是提交时的值，Current 是记录当前的值，两者不同表示勘误已过时
+	Original  json.RawMessage `json:"original"`
+	Current   json.RawMessage `json:"current"`
+	Suggested json.RawMessage `json:"suggested"`
+	Stale     bool            `json:"stale"`
+	// Applicable 为 false 时 Reason 说明无法写回的原因
+	Applicable bool   `json:"applicable"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// AnnotationFilter 是检索批注的条件，零值表示不限
+type AnnotationFilter struct {
+	BizName   string
+	TableName string
+	RecordID  string
+	Status    string
+	AuthorID  int64
+	Limit     int
+	Offset    int
+}
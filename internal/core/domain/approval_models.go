@@ -0,0 +1,33 @@
+// Package domain file: internal/core/domain/approval_models.go
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 变更申请的状态
+const (
+	ChangeStatusPending  = "pending"
+	ChangeStatusApplied  = "applied"
+	ChangeStatusRejected = "rejected"
+	ChangeStatusFailed   = "failed"
+	ChangeStatusExpired  = "expired"
+)
+
+// ChangeRequest 是一项等待第二位管理员审批的高风险管理变更
+type ChangeRequest struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Target      string          `json:"target"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Status      string          `json:"status"`
+	RequestedBy *int64          `json:"requested_by,omitempty"`
+	ReviewedBy  *int64          `json:"reviewed_by,omitempty"`
+	ReviewNote  string          `json:"review_note,omitempty"`
+	// Error 记录审批通过后执行变更失败的原因
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
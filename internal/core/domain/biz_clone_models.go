@@ -0,0 +1,17 @@
+// Package domain file: internal/core/domain/biz_clone_models.go
+package domain
+
+// BizCloneReport 是把一个业务组的配置复制到另一个业务组的结果 (DryRun 为 true 时仅为预览，未做任何修改)
+type BizCloneReport struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	DryRun bool   `json:"dry_run"`
+	// Tables 是复制到目标业务组的表，按名称排序
+	Tables []string `json:"tables"`
+	// SkippedFields 是源配置中存在、但目标业务组的表中没有对应列的字段，按表名列出，这些字段设置未被复制
+	SkippedFields map[string][]string `json:"skipped_fields"`
+	// Views 是复制的视图数
+	Views int `json:"views"`
+	// RateLimitCopied 表示源业务组有个性化的速率限制，并已复制
+	RateLimitCopied bool `json:"rate_limit_copied"`
+}
@@ -0,0 +1,30 @@
+// Package domain file: internal/core/domain/biz_rename_models.go
+package domain
+
+import "time"
+
+// BizRenameReport 是一次业务组重命名的结果
+type BizRenameReport struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+	// Tables 是各系统表中被改写的行数，只列出有改动的表
+	Tables map[string]int64 `json:"tables"`
+	// InstanceID 是绑定到该业务组的插件实例，没有时为空
+	InstanceID string `json:"instance_id,omitempty"`
+	// DataMoved 表示插件的业务组数据目录已随之重命名
+	DataMoved bool `json:"data_moved"`
+	// Restarted 表示重命名前正在运行的实例已以新名称重新启动
+	Restarted bool      `json:"restarted"`
+	Warnings  []string  `json:"warnings"`
+	RenamedAt time.Time `json:"renamed_at"`
+}
+
+// BizRename 是一条业务组重命名记录
+type BizRename struct {
+	ID          int64     `json:"id"`
+	OldName     string    `json:"old_name"`
+	NewName     string    `json:"new_name"`
+	RenamedBy   *int64    `json:"renamed_by,omitempty"`
+	RowsUpdated int64     `json:"rows_updated"`
+	RenamedAt   time.Time `json:"renamed_at"`
+}
@@ -0,0 +1,35 @@
+// Package domain file: internal/core/domain/bookmark_models.go
+package domain
+
+import "time"
+
+// Bookmark 是用户收藏的一条记录，以业务组、表与主键 (及可选的库名) 定位，与永久链接一致
+type Bookmark struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	BizName   string    `json:"biz_name"`
+	TableName string    `json:"table_name"`
+	RecordID  string    `json:"record_id"`
+	Lib       string    `json:"lib,omitempty"`
+	Note      string    `json:"note"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookmarkFilter 是检索收藏的条件，零值表示不限
+type BookmarkFilter struct {
+	BizName   string
+	TableName string
+	Tag       string
+	// Query 在备注与主键中做不区分大小写的子串匹配
+	Query string
+	Page  int
+	Size  int
+}
+
+// BookmarkTag 是用户使用过的一个标签及其收藏数
+type BookmarkTag struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
@@ -0,0 +1,35 @@
+// Package domain file: internal/core/domain/capture_models.go
+package domain
+
+import "time"
+
+// CaptureSession 是针对单个业务组的请求/响应采集，在 ExpiresAt 之后或被管理员停止后不再采集，
+// 已采集的记录保留到下一次开始采集
+type CaptureSession struct {
+	BizName    string    `json:"biz_name"`
+	StartedBy  int64     `json:"started_by"`
+	StartedAt  time.Time `json:"started_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	MaxEntries int       `json:"max_entries"`
+	Active     bool      `json:"active"`
+	Captured   int       `json:"captured"` // 当前缓冲区中的记录数
+	Dropped    int       `json:"dropped"`  // 缓冲区满后被挤掉的最早记录数
+}
+
+// CapturedExchange 是一次被采集的请求与响应。敏感的请求头与 JSON 字段已被替换为 [REDACTED]，
+// 超过大小上限的请求体与响应体会被截断
+type CapturedExchange struct {
+	ID                int64             `json:"id"`
+	Time              time.Time         `json:"time"`
+	Method            string            `json:"method"`
+	Path              string            `json:"path"`
+	Query             string            `json:"query,omitempty"`
+	Status            int               `json:"status"`
+	DurationMs        int64             `json:"duration_ms"`
+	UserID            int64             `json:"user_id,omitempty"`
+	RequestHeaders    map[string]string `json:"request_headers"`
+	RequestBody       string            `json:"request_body,omitempty"`
+	RequestTruncated  bool              `json:"request_truncated,omitempty"`
+	ResponseBody      string            `json:"response_body,omitempty"`
+	ResponseTruncated bool              `json:"response_truncated,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Package domain file: internal/core/domain/column_stats_models.go
+package domain
+
+import "time"
+
+// 字段统计给出的过滤控件建议
+const (
+	FilterHintDropdown = "dropdown" // 不同取值较少，适合下拉选择
+	FilterHintRange    = "range"    // 数值字段，适合范围过滤
+	FilterHintText     = "text"     // 自由文本输入
+)
+
+// ColumnStats 是表中一个可返回字段的统计。多库业务组无法精确合并基数时 Cardinality 为上界，CardinalityExact 为 false
+type ColumnStats struct {
+	Field            string      `json:"field"`
+	Nulls            int64       `json:"nulls"`
+	NullRatio        float64     `json:"null_ratio"`
+	Cardinality      int64       `json:"cardinality"`
+	CardinalityExact bool        `json:"cardinality_exact"`
+	Min              interface{} `json:"min"`
+	Max              interface{} `json:"max"`
+	FilterHint       string      `json:"filter_hint"`
+}
+
+// TableStats 是一张表的字段统计，在 ComputedAt 时计算并由网关缓存
+type TableStats struct {
+	BizName    string        `json:"biz_name"`
+	Table      string        `json:"table"`
+	Total      int64         `json:"total"`
+	Columns    []ColumnStats `json:"columns"`
+	ComputedAt time.Time     `json:"computed_at"`
+}
@@ -0,0 +1,60 @@
+// Package domain file: internal/core/domain/compliance_models.go
+package domain
+
+import "time"
+
+// 合规审计事件的来源
+const (
+	AuditSourceWrites         = "operation_log"
+	AuditSourceChangeRequests = "admin_change_requests"
+	AuditSourceDataSubjects   = "data_subject_requests"
+)
+
+// ComplianceReport 是安全相关配置 (谁能写什么、哪些业务组公开、速率限制) 与近期审计记录的机器可读快照，
+// 供合规检查与 SIEM 采集使用。报告不含密码摘要、记录内容等敏感数据
+type ComplianceReport struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	Users       []ComplianceUser      `json:"users"`
+	IPRateLimit IPLimitSetting        `json:"ip_rate_limit"`
+	Bizs        []ComplianceBiz       `json:"bizs"`
+	Audit       []ComplianceAuditItem `json:"audit"`
+}
+
+// ComplianceUser 是一个账号及其角色，RateLimit 为 nil 表示使用默认的用户速率
+type ComplianceUser struct {
+	ID        int64             `json:"id"`
+	Username  string            `json:"username"`
+	Role      string            `json:"role"`
+	RateLimit *UserLimitSetting `json:"rate_limit,omitempty"`
+}
+
+// ComplianceBiz 是一个业务组的访问控制概况。Owners 是委派管理员的用户名，
+// WritableTables 只列出至少开放了一种写操作的表；RateLimit 为 nil 表示使用默认的业务组速率
+type ComplianceBiz struct {
+	BizName        string                 `json:"biz_name"`
+	Enabled        bool                   `json:"enabled"`
+	Public         bool                   `json:"public"`
+	Owners         []string               `json:"owners"`
+	WritableTables []ComplianceTableWrite `json:"writable_tables"`
+	RateLimit      *BizRateLimitSetting   `json:"rate_limit,omitempty"`
+}
+
+// ComplianceTableWrite 是表开放的写操作
+type ComplianceTableWrite struct {
+	Table       string `json:"table"`
+	AllowCreate bool   `json:"allow_create"`
+	AllowUpdate bool   `json:"allow_update"`
+	AllowDelete bool   `json:"allow_delete"`
+}
+
+// ComplianceAuditItem 是统一格式的审计事件。Source 为 AuditSource* 之一，ID 是事件在来源表中的编号；
+// Time 保留来源表中的原始时间文本，Target 为 业务组/表/主键 或变更申请的目标
+type ComplianceAuditItem struct {
+	Source string `json:"source"`
+	ID     string `json:"id"`
+	Time   string `json:"time"`
+	UserID *int64 `json:"user_id,omitempty"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Status string `json:"status,omitempty"`
+}
@@ -6,13 +6,46 @@ package domain
 type BizOverallSettings struct {
 	IsPubliclySearchable *bool   `json:"is_publicly_searchable"`
 	DefaultQueryTable    *string `json:"default_query_table"`
+	ChangeCaptureEnabled *bool   `json:"change_capture_enabled"`
+	CitationTemplate     *string `json:"citation_template"`
+	// Enabled 为 false 时业务组暂停服务：数据与元数据接口不再响应，但保留全部配置、视图与实例
+	Enabled *bool `json:"enabled"`
+	// MaxResponseBytes 是单次检索结果的字节数上限，0 表示使用网关的默认上限
+	MaxResponseBytes *int64 `json:"max_response_bytes"`
+	// QueryCostPolicy 决定网关如何处理估算扫描行数超过上限的检索 (见 QueryCostPolicyWarn 等)，空字符串表示使用网关的默认策略
+	QueryCostPolicy *string `json:"query_cost_policy"`
+	// MaxScanRows 是单次检索允许扫描的估算行数上限，0 表示使用网关的默认上限
+	MaxScanRows *int64 `json:"max_scan_rows"`
+	// Timezone 是业务组日期的时区 (IANA 名称，如 "Asia/Shanghai")，用于换算带时区的日期过滤值、
+	// 展开相对日期范围、时间轴分桶与导出时间的格式化；空字符串表示使用服务器时区
+	Timezone *string `json:"timezone"`
+	// Locale 是业务组内容的语言区域 (BCP 47 标签，如 "zh-CN")，在元数据中报告，供客户端格式化日期与数字
+	Locale *string `json:"locale"`
+	// Sensitive 为 true 时网关把用户查看过的记录 (检索结果、永久链接与 IIIF 清单) 写入记录访问日志，供受限档案的合规审查
+	Sensitive *bool `json:"sensitive"`
 }
 
+// 检索代价超过上限时的处理策略
+const (
+	QueryCostPolicyOff    = "off"    // 不估算检索代价
+	QueryCostPolicyWarn   = "warn"   // 照常执行，并在结果中附带 cost_warning
+	QueryCostPolicyReject = "reject" // 拒绝执行并说明原因，管理员可通过请求头强制执行
+)
+
 // BizQueryConfig 定义了单个业务组的完整查询配置
 type BizQueryConfig struct {
 	BizName              string                  `json:"biz_name"`
 	IsPubliclySearchable bool                    `json:"is_publicly_searchable"`
 	DefaultQueryTable    string                  `json:"default_query_table"`
+	ChangeCaptureEnabled bool                    `json:"change_capture_enabled"`
+	CitationTemplate     string                  `json:"citation_template"`
+	Enabled              bool                    `json:"enabled"`
+	MaxResponseBytes     int64                   `json:"max_response_bytes"`
+	QueryCostPolicy      string                  `json:"query_cost_policy"`
+	MaxScanRows          int64                   `json:"max_scan_rows"`
+	Timezone             string                  `json:"timezone"`
+	Locale               string                  `json:"locale"`
+	Sensitive            bool                    `json:"sensitive"`
 	Tables               map[string]*TableConfig `json:"tables"`
 }
 
@@ -24,6 +57,79 @@ type TableConfig struct {
 	AllowCreate  bool                    `json:"allow_create"`
 	AllowUpdate  bool                    `json:"allow_update"`
 	AllowDelete  bool                    `json:"allow_delete"`
+	// FTS 为 nil 表示该表未配置全文检索
+	FTS *FTSConfig `json:"fts,omitempty"`
+	// Ranking 为 nil 时按 RankExpressionWeighted 计算相关度得分
+	Ranking *RankingConfig `json:"ranking,omitempty"`
+	// Hierarchy 为 nil 表示表中的记录没有层级关系
+	Hierarchy *HierarchyConfig `json:"hierarchy,omitempty"`
+	// SQLView 不为 nil 时该表是由管理员定义的 SQL 视图表，不对应库中的物理表，只能查询
+	SQLView *SQLViewConfig `json:"sql_view,omitempty"`
+}
+
+// HierarchyConfig 声明表中记录的层级关系 (如 全宗 → 系列 → 案卷)，用于树形浏览。
+// ParentField 保存父节点 KeyField 的值，为 NULL 或空字符串的记录是根节点。
+// 两个字段都必须可返回；数据量较大时应为 ParentField 建立索引
+type HierarchyConfig struct {
+	KeyField    string `json:"key_field"`
+	ParentField string `json:"parent_field"`
+	// SortField 是同一父节点下子节点的排序字段，为空时按 KeyField 排序
+	SortField string `json:"sort_field,omitempty"`
+}
+
+// SQL 视图表参数的类型
+const (
+	SQLViewParamText    = "text"
+	SQLViewParamInteger = "integer"
+	SQLViewParamReal    = "real"
+)
+
+// SQLViewConfig 是管理员登记的 SQL 视图表：一条只读的 SELECT 语句 (只允许安全子集，不能写入、修改结构或访问其他库)，
+// 以 :name 形式引用参数。视图表在查询时作为公用表表达式展开，可以像普通表一样过滤、排序与分页，
+// 字段设置同样需要在字段配置中声明。视图只在含有其引用的全部表的库中查询
+type SQLViewConfig struct {
+	SQL         string         `json:"sql"`
+	Params      []SQLViewParam `json:"params,omitempty"`
+	Description string         `json:"description,omitempty"`
+}
+
+// SQLViewParam 声明 SQL 视图表的一个参数，查询时通过 "sql_params" 传值。
+// 未传值时使用 Default，没有默认值的参数必须传值
+type SQLViewParam struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Default interface{} `json:"default,omitempty"`
+}
+
+// 相关度得分的计算方式
+const (
+	RankExpressionWeighted = "weighted" // 默认，各字段命中时的权重 (FieldSetting.SearchWeight) 之和
+	RankExpressionBM25     = "bm25"     // 由全文索引按 BM25 计算，各列的权重同样取自 SearchWeight，需要表已配置全文检索
+)
+
+// RankingConfig 定义表在全字段检索与全文检索中的相关度得分计算方式。
+// 得分以 _score 字段随结果返回，并可作为排序键
+type RankingConfig struct {
+	Expression string `json:"expression"`
+}
+
+// FTS 分词器
+const (
+	FTSTokenizerUnicode61 = "unicode61" // 默认，按 Unicode 字符类别切分，适合以空格分词的语言
+	FTSTokenizerPorter    = "porter"    // 在 unicode61 基础上做英文词干还原
+	FTSTokenizerTrigram   = "trigram"   // 按三字切分，支持中日韩文本的子串检索
+	FTSTokenizerICU       = "icu"       // 依赖 SQLite 编译时启用 ICU，不可用时重建会失败
+)
+
+// FTSConfig 定义表的全文检索配置。修改后需要重建索引才会生效，Version 在每次修改时递增，
+// 用于区分当前生效的索引是否已按最新配置建立。
+type FTSConfig struct {
+	Fields           []string `json:"fields"`
+	Tokenizer        string   `json:"tokenizer"`
+	RemoveDiacritics bool     `json:"remove_diacritics"` // 仅对 unicode61 与 porter 有效
+	// Stopwords 在检索时从检索词中剔除，不影响索引内容
+	Stopwords []string `json:"stopwords,omitempty"`
+	Version   int64    `json:"version"`
 }
 
 // FieldSetting 定义了单个字段的查询和返回配置
@@ -32,6 +138,14 @@ type FieldSetting struct {
 	IsSearchable bool   `json:"is_searchable"`
 	IsReturnable bool   `json:"is_returnable"`
 	DataType     string `json:"dataType"`
+	DisplayName  string `json:"display_name"`
+	// Pinyin 为 true 时允许按拼音全拼或首字母检索该字段，仅对支持该能力的数据源生效
+	Pinyin bool `json:"pinyin"`
+	// CaseInsensitive 与 AccentInsensitive 是该字段文本匹配的默认设置，过滤条件可逐条覆盖
+	CaseInsensitive   bool `json:"case_insensitive"`
+	AccentInsensitive bool `json:"accent_insensitive"`
+	// SearchWeight 是全字段检索中该字段命中时的得分，0 表示默认权重 1，负数表示不参与全字段检索
+	SearchWeight float64 `json:"search_weight,omitempty"`
 }
 
 // ViewConfig 是一个完整的视图配置对象，代表一种展示方案
@@ -41,6 +155,78 @@ type ViewConfig struct {
 	DisplayName string      `json:"display_name"`
 	IsDefault   bool        `json:"is_default"`
 	Binding     ViewBinding `json:"binding"`
+	// Projection 将扁平行重组为嵌套文档，键为以点分隔的目标路径，值为源字段名
+	Projection map[string]string `json:"projection,omitempty"`
+	// Distinct 为 true 时，按该视图查询默认对结果去重 (查询中显式指定 distinct 时以查询为准)
+	Distinct bool `json:"distinct,omitempty"`
+	// DefaultFilters 在按该视图查询时总是附加，与查询自身的条件为 AND；条件之间按 logic 连接，与查询的 filters 相同
+	DefaultFilters []ViewFilter `json:"default_filters,omitempty"`
+	// DefaultSort 是按该视图查询时的排序键 (见 port.QuerySortKey)，查询中显式指定 sort 时以查询为准
+	DefaultSort string `json:"default_sort,omitempty"`
+	// ConditionalFormats 是按字段取值突出显示单元格或整行的规则，由客户端按顺序求值，
+	// 同一单元格或行命中多条规则时后面的规则覆盖前面的样式
+	ConditionalFormats []ConditionalFormat `json:"conditional_formats,omitempty"`
+}
+
+// 条件格式的运算符，见 ConditionalFormat.Op
+const (
+	ConditionOpEq        = "eq"
+	ConditionOpNe        = "ne"
+	ConditionOpGt        = "gt"
+	ConditionOpGte       = "gte"
+	ConditionOpLt        = "lt"
+	ConditionOpLte       = "lte"
+	ConditionOpContains  = "contains"
+	ConditionOpIn        = "in"
+	ConditionOpIsNull    = "is_null"
+	ConditionOpIsNotNull = "is_not_null"
+	ConditionOpIsEmpty   = "is_empty"
+)
+
+// 条件格式的作用范围
+const (
+	ConditionScopeCell = "cell"
+	ConditionScopeRow  = "row"
+)
+
+// 条件格式的语义色调，由各客户端映射为自己的配色
+const (
+	ConditionToneDanger  = "danger"
+	ConditionToneWarning = "warning"
+	ConditionToneSuccess = "success"
+	ConditionToneInfo    = "info"
+	ConditionToneMuted   = "muted"
+)
+
+// ConditionalFormat 是一条条件格式规则：字段 Field 的取值满足 Op 与 Value (或 Values) 时，
+// 对该字段的单元格 (Scope 为 cell，缺省) 或整行 (row) 应用样式。比较运算符对数值按大小、对其余值按文本比较
+type ConditionalFormat struct {
+	Field  string           `json:"field"`
+	Op     string           `json:"op"`
+	Value  string           `json:"value,omitempty"`
+	Values []string         `json:"values,omitempty"`
+	Scope  string           `json:"scope,omitempty"`
+	Style  ConditionalStyle `json:"style"`
+}
+
+// ConditionalStyle 是条件格式命中时应用的样式，至少需要设置一项
+type ConditionalStyle struct {
+	Tone   string `json:"tone,omitempty"`
+	Bold   bool   `json:"bold,omitempty"`
+	Italic bool   `json:"italic,omitempty"`
+	// Label 是附加在单元格或行上的简短提示，如 "破损"
+	Label string `json:"label,omitempty"`
+}
+
+// ViewFilter 是视图的一个默认过滤条件，各字段与查询 filters 数组元素中的同名键含义相同
+type ViewFilter struct {
+	Field  string   `json:"field"`
+	Value  string   `json:"value,omitempty"`
+	Values []string `json:"values,omitempty"`
+	Op     string   `json:"op,omitempty"`
+	Fuzzy  bool     `json:"fuzzy,omitempty"`
+	Negate bool     `json:"negate,omitempty"`
+	Logic  string   `json:"logic,omitempty"`
 }
 
 // ViewBinding 包含了所有可能的视图类型的绑定配置
@@ -67,7 +253,34 @@ type TableBinding struct {
 type TableColumnBinding struct {
 	Field       string `json:"field"`
 	DisplayName string `json:"displayName"`
-	Format      string `json:"format,omitempty"`
+	// Format 是列值的显示格式，保存时按格式词汇校验，如 "date:yyyy-MM-dd"、"number:2"、"percent:1"、
+	// "currency:CNY:2"、"boolean:是/否"，可附加 "@zh-CN" 指定区域设置
+	Format string `json:"format,omitempty"`
+	// FormatSpec 是网关解析 Format 得到的结构化格式，随视图返回，保存时忽略
+	FormatSpec *ColumnFormat `json:"format_spec,omitempty"`
+}
+
+// 列格式的类型，见 TableColumnBinding.Format
+const (
+	ColumnFormatDate     = "date"
+	ColumnFormatNumber   = "number"
+	ColumnFormatPercent  = "percent"
+	ColumnFormatCurrency = "currency"
+	ColumnFormatBoolean  = "boolean"
+)
+
+// ColumnFormat 是结构化的列格式，客户端据此统一渲染列值。Precision 为 nil 时由客户端决定小数位数
+type ColumnFormat struct {
+	Type string `json:"type"`
+	// Pattern 是日期格式，由 yyyy、yy、MM、M、dd、d、HH、H、mm、ss 与分隔符组成
+	Pattern   string `json:"pattern,omitempty"`
+	Precision *int   `json:"precision,omitempty"`
+	// Currency 是 ISO 4217 货币代码
+	Currency   string `json:"currency,omitempty"`
+	TrueLabel  string `json:"true_label,omitempty"`
+	FalseLabel string `json:"false_label,omitempty"`
+	// Locale 是 BCP 47 区域设置，为空时使用客户端的区域设置
+	Locale string `json:"locale,omitempty"`
 }
 
 // IPLimitSetting 定义了全局IP速率限制的配置
@@ -86,4 +299,17 @@ type UserLimitSetting struct {
 type BizRateLimitSetting struct {
 	RateLimitPerSecond float64 `json:"rate_limit_per_second"`
 	BurstSize          int     `json:"burst_size"`
+	// Anonymous 是未认证访问者的独立限制，为 nil 时匿名访问沿用上面的速率，但仍与已登录用户分开计数
+	Anonymous *AnonymousAccessSetting `json:"anonymous,omitempty"`
+}
+
+// AnonymousAccessSetting 定义了业务组对未认证访问者 (含搜索引擎爬虫) 的访问层限制，
+// 使公开访问的流量不会挤占已登录用户的配额
+type AnonymousAccessSetting struct {
+	RateLimitPerSecond float64 `json:"rate_limit_per_second"`
+	BurstSize          int     `json:"burst_size"`
+	// MaxPageSize 是匿名检索每页返回的记录数上限，0 表示不额外限制
+	MaxPageSize int `json:"max_page_size"`
+	// ResponseDelayMs 是处理匿名请求前的固定延迟，用于减缓批量抓取，0 表示不延迟
+	ResponseDelayMs int `json:"response_delay_ms"`
 }
@@ -0,0 +1,94 @@
+// Package domain file: internal/core/domain/config_template_models.go
+package domain
+
+import "time"
+
+// ConfigTemplate 是可复用的业务组配置模板，包含字段类型约定、默认视图与速率限制。
+// 应用到业务组时按表名与列名匹配各条规则，为已配置的表填充字段与视图设置
+type ConfigTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Fields 按顺序匹配，每个列采用第一条匹配的规则
+	Fields []TemplateFieldRule `json:"fields"`
+	Views  []TemplateViewRule  `json:"views,omitempty"`
+	// RateLimit 为 nil 时模板不涉及速率限制
+	RateLimit *BizRateLimitSetting `json:"rate_limit,omitempty"`
+	UpdatedBy int64                `json:"updated_by,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// TemplateFieldRule 是模板中的一条字段规则。Table 与 Column 是 path.Match 风格的通配模式，
+// 为空时匹配任意名称；匹配的列按规则中的其余字段生成字段配置
+type TemplateFieldRule struct {
+	Table             string `json:"table,omitempty"`
+	Column            string `json:"column"`
+	IsSearchable      bool   `json:"is_searchable"`
+	IsReturnable      bool   `json:"is_returnable"`
+	DataType          string `json:"dataType"`
+	DisplayName       string `json:"display_name"`
+	Pinyin            bool   `json:"pinyin"`
+	CaseInsensitive   bool   `json:"case_insensitive"`
+	AccentInsensitive bool   `json:"accent_insensitive"`
+	// SearchWeight 与 FieldSetting.SearchWeight 相同
+	SearchWeight float64 `json:"search_weight,omitempty"`
+}
+
+// TemplateViewRule 为名称匹配 Table 模式的每张表创建视图 View
+type TemplateViewRule struct {
+	Table string     `json:"table,omitempty"`
+	View  ViewConfig `json:"view"`
+}
+
+// TemplateApplyReport 是应用配置模板的结果 (DryRun 为 true 时仅为预览，未做任何修改)
+type TemplateApplyReport struct {
+	Template string `json:"template"`
+	BizName  string `json:"biz_name"`
+	DryRun   bool   `json:"dry_run"`
+	// Overwrite 为 false 时只补充缺失的字段与视图，已有的设置保持不变
+	Overwrite bool                `json:"overwrite"`
+	Tables    []TemplateTableDiff `json:"tables"`
+	// UnconfiguredTables 是数据源中存在、但尚未加入业务组配置的表，需先通过 /tables 接口加入后才能应用
+	UnconfiguredTables []string               `json:"unconfigured_tables"`
+	Views              []TemplateViewDiff     `json:"views"`
+	RateLimit          *TemplateRateLimitDiff `json:"rate_limit,omitempty"`
+}
+
+// TemplateTableDiff 是一张表的字段配置差异
+type TemplateTableDiff struct {
+	TableName string                `json:"table_name"`
+	Added     []FieldSetting        `json:"added"`
+	Changed   []TemplateFieldChange `json:"changed"`
+	// Kept 是已有配置且未被修改的字段 (与模板一致，或未指定覆盖)
+	Kept []string `json:"kept"`
+	// Unmatched 是没有任何规则匹配的列
+	Unmatched []string `json:"unmatched"`
+}
+
+// TemplateFieldChange 是一个已有字段配置被模板覆盖前后的值
+type TemplateFieldChange struct {
+	Before FieldSetting `json:"before"`
+	After  FieldSetting `json:"after"`
+}
+
+// 视图差异的处理结果
+const (
+	TemplateViewAdded    = "added"
+	TemplateViewReplaced = "replaced"
+	TemplateViewSkipped  = "skipped"
+)
+
+// TemplateViewDiff 是模板中一个视图在一张表上的处理结果，Action 为 added、replaced 或 skipped
+type TemplateViewDiff struct {
+	TableName string `json:"table_name"`
+	ViewName  string `json:"view_name"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// TemplateRateLimitDiff 是速率限制的差异，Before 为 nil 表示业务组此前没有个性化限制
+type TemplateRateLimitDiff struct {
+	Before  *BizRateLimitSetting `json:"before"`
+	After   *BizRateLimitSetting `json:"after"`
+	Applied bool                 `json:"applied"`
+}
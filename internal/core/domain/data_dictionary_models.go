@@ -0,0 +1,23 @@
+// Package domain file: internal/core/domain/data_dictionary_models.go
+package domain
+
+import "time"
+
+// FieldDictionaryEntry 是数据字典中一个字段的说明：字段的含义、数据的来源、整理时所做的转换，
+// 以及取值所依据的受控词表。由业务组管理员维护，随表结构一起公开给检索者
+type FieldDictionaryEntry struct {
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	FieldName string `json:"field_name"`
+	// Description 是字段的含义
+	Description string `json:"description"`
+	// Source 是数据的来源，如原始档案的著录项或导入的外部数据集
+	Source string `json:"source,omitempty"`
+	// Transformation 是从来源整理为当前取值时所做的转换，如日期的规范化
+	Transformation string `json:"transformation,omitempty"`
+	// VocabularyLink 是取值所依据的受控词表的链接或名称
+	VocabularyLink string `json:"vocabulary_link,omitempty"`
+	// UpdatedBy 是最后修改的管理员，公开给检索者时省略
+	UpdatedBy int64     `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
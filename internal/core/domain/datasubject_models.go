@@ -0,0 +1,83 @@
+// Package domain file: internal/core/domain/datasubject_models.go
+package domain
+
+import "time"
+
+// 数据主体请求的操作类型
+const (
+	DataSubjectActionSearch = "search"
+	DataSubjectActionExport = "export"
+	DataSubjectActionErase  = "erase"
+)
+
+// 数据主体删除请求的处理方式
+const (
+	// DataSubjectModeDelete 删除匹配的记录，要求表开放删除权限
+	DataSubjectModeDelete = "delete"
+	// DataSubjectModeAnonymize 把匹配字段中的标识替换为占位值，保留记录本身，要求表开放更新权限
+	DataSubjectModeAnonymize = "anonymize"
+)
+
+// 单张表的删除结果
+const (
+	DataSubjectStatusDone    = "done"
+	DataSubjectStatusSkipped = "skipped"
+	DataSubjectStatusFailed  = "failed"
+	DataSubjectStatusPlanned = "planned" // dry_run 时的预期结果
+)
+
+// DataSubjectMatch 是一张表中与个人标识匹配的一条记录。RecordID 为主键值 (复合主键以逗号连接)，
+// 主键不可返回时为空；同一业务组有多个库时 Lib 为记录所在的库
+type DataSubjectMatch struct {
+	BizName       string                 `json:"biz_name"`
+	TableName     string                 `json:"table_name"`
+	RecordID      string                 `json:"record_id,omitempty"`
+	Lib           string                 `json:"lib,omitempty"`
+	MatchedFields []string               `json:"matched_fields"`
+	Record        map[string]interface{} `json:"record"`
+}
+
+// DataSubjectTable 汇总一张表的检索结果。Total 是匹配的记录总数，超过单表上限时 Truncated 为 true
+type DataSubjectTable struct {
+	BizName   string   `json:"biz_name"`
+	TableName string   `json:"table_name"`
+	Fields    []string `json:"fields"`
+	Total     int64    `json:"total"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// DataSubjectSearchResult 是一次跨业务组检索的结果
+type DataSubjectSearchResult struct {
+	IdentifierHash string             `json:"identifier_hash"`
+	Tables         []DataSubjectTable `json:"tables"`
+	Matches        []DataSubjectMatch `json:"matches"`
+}
+
+// DataSubjectErasure 是一张表的删除或匿名化结果
+type DataSubjectErasure struct {
+	BizName      string   `json:"biz_name"`
+	TableName    string   `json:"table_name"`
+	Fields       []string `json:"fields"`
+	Matched      int64    `json:"matched"`
+	RowsAffected int64    `json:"rows_affected"`
+	Status       string   `json:"status"`
+	Reason       string   `json:"reason,omitempty"`
+}
+
+// DataSubjectRequest 是一条数据主体请求的审计记录。个人标识本身不落盘，只保存其 SHA-256 摘要，
+// 用于日后核对某个标识的请求是否已经处理
+type DataSubjectRequest struct {
+	ID             int64                `json:"id"`
+	Action         string               `json:"action"`
+	Mode           string               `json:"mode,omitempty"`
+	IdentifierHash string               `json:"identifier_hash"`
+	Fields         []string             `json:"fields,omitempty"`
+	Bizs           []string             `json:"bizs,omitempty"`
+	Matched        int64                `json:"matched"`
+	RowsAffected   int64                `json:"rows_affected"`
+	DryRun         bool                 `json:"dry_run,omitempty"`
+	Erasures       []DataSubjectErasure `json:"erasures,omitempty"`
+	RequestedBy    int64                `json:"requested_by"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+// Package domain file: internal/core/domain/dictionary_models.go
+package domain
+
+import "time"
+
+// QueryDictionary 是一个业务组的检索词典。网关在把查询分发给数据源之前，
+// 按词典把过滤值展开为同义词与归一化后的各种写法，以提高对历史人名、地名异写的召回。
+type QueryDictionary struct {
+	BizName       string             `json:"biz_name"`
+	Synonyms      []SynonymGroup     `json:"synonyms"`
+	Normalization NormalizationRules `json:"normalization"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// SynonymGroup 是一组可以互相替换的写法，命中其中任意一个时会同时检索整组。
+// Fields 为空表示适用于所有字段。
+type SynonymGroup struct {
+	Fields []string `json:"fields,omitempty"`
+	Terms  []string `json:"terms"`
+}
+
+// NormalizationRules 控制对过滤值额外生成哪些写法。Fields 为空表示适用于所有字段。
+type NormalizationRules struct {
+	CaseFold        bool     `json:"case_fold"`        // 小写、大写与首字母大写
+	ChineseVariants bool     `json:"chinese_variants"` // 繁体与简体
+	Transliteration bool     `json:"transliteration"`  // 去除罗马化拼写中的声调与附加符号
+	Fields          []string `json:"fields,omitempty"`
+}
@@ -0,0 +1,38 @@
+// Package domain file: internal/core/domain/digest_models.go
+package domain
+
+import "time"
+
+// DailyDigest 是发给管理员的每日运行摘要，覆盖 Day 当天 (UTC) 的请求、错误、检索与插件情况
+type DailyDigest struct {
+	Day string `json:"day"` // YYYY-MM-DD，UTC
+	// Requests 与 Errors 是网关处理的 HTTP 请求数与 5xx 响应数，统计区间为上一份摘要生成以来；
+	// 网关在区间内重启过时只包含重启后的请求
+	Requests         int64       `json:"requests"`
+	Errors           int64       `json:"errors"`
+	TopBizs          []BizUsage  `json:"top_bizs"`
+	PluginRestarts   int64       `json:"plugin_restarts"`
+	PluginCrashes    int64       `json:"plugin_crashes"`
+	RestartedPlugins []PluginRun `json:"restarted_plugins"`
+	StorageBytes     int64       `json:"storage_bytes"`
+	// StorageGrowth 是与上一份摘要相比的存储增量 (字节)，可能为负；没有上一份摘要时为 0
+	StorageGrowth int64 `json:"storage_growth"`
+	// Delivery 记录各投递渠道的结果，键为渠道名 (webhook / email)，值为 "ok" 或失败原因
+	Delivery  map[string]string `json:"delivery,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// BizUsage 是某个业务组一天内的检索次数
+type BizUsage struct {
+	BizName     string `json:"biz_name"`
+	Queries     int64  `json:"queries"`
+	ZeroResults int64  `json:"zero_results"`
+}
+
+// PluginRun 是某个插件实例一天内的重启与崩溃次数
+type PluginRun struct {
+	InstanceID string `json:"instance_id"`
+	PluginID   string `json:"plugin_id"`
+	Restarts   int64  `json:"restarts"`
+	Crashes    int64  `json:"crashes"`
+}
@@ -0,0 +1,17 @@
+// Package domain file: internal/core/domain/embed_models.go
+package domain
+
+import "time"
+
+// EmbedKey 是管理员为外部网站签发的嵌入密钥。KeyID 随嵌入地址公开，
+// 对应的签名密钥只在创建时返回一次，由外部网站的后端用于签名查询参数
+type EmbedKey struct {
+	ID      int64  `json:"id"`
+	KeyID   string `json:"key_id"`
+	Label   string `json:"label"`
+	BizName string `json:"biz_name"`
+	// AllowedOrigins 是允许嵌入的来源 (scheme://host[:port])，"*" 表示不限
+	AllowedOrigins []string   `json:"allowed_origins"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
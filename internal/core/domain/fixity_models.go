@@ -0,0 +1,45 @@
+// Package domain file: internal/core/domain/fixity_models.go
+package domain
+
+import "time"
+
+// 库文件校验事件的类型
+const (
+	// FixityEventBaseline 表示首次登记库文件的校验值
+	FixityEventBaseline = "baseline"
+	// FixityEventUpdated 表示库文件在网关执行写操作后发生了变化，属于预期内的变更
+	FixityEventUpdated = "updated"
+	// FixityEventMismatch 表示库文件在没有经由网关写入的情况下发生了变化
+	FixityEventMismatch = "mismatch"
+	// FixityEventMissing 表示已登记的库文件不存在了
+	FixityEventMissing = "missing"
+)
+
+// FixityChecksum 是一个库文件当前登记的校验值。ChangedAt 是校验值最近一次变化的时间
+type FixityChecksum struct {
+	BizName   string    `json:"biz_name"`
+	FileName  string    `json:"file_name"`
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	ChangedAt time.Time `json:"changed_at"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// FixityEvent 是一次库文件校验事件，mismatch 与 missing 会触发告警
+type FixityEvent struct {
+	ID             int64     `json:"id"`
+	BizName        string    `json:"biz_name"`
+	FileName       string    `json:"file_name"`
+	Event          string    `json:"event"`
+	ExpectedSHA256 string    `json:"expected_sha256,omitempty"`
+	ActualSHA256   string    `json:"actual_sha256,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// FixityRun 汇总一次校验：检查的文件数与产生的事件
+type FixityRun struct {
+	Files     int           `json:"files"`
+	Events    []FixityEvent `json:"events"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  string        `json:"duration"`
+}
@@ -0,0 +1,29 @@
+// Package domain file: internal/core/domain/identifier_models.go
+package domain
+
+// 标识符在配置中的用途，用于标识符策略的日志与兼容性报告
+const (
+	IdentifierKindTable = "table"
+	IdentifierKindField = "field"
+)
+
+// IdentifierViolation 是现有配置中一处不符合标识符策略的表名或字段名
+type IdentifierViolation struct {
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	// Setting 说明该标识符出现的位置，如 searchable_table、field、fts_field、hierarchy_key_field
+	Setting    string `json:"setting"`
+	Kind       string `json:"kind"`
+	Identifier string `json:"identifier"`
+	Reason     string `json:"reason"`
+}
+
+// IdentifierPolicyReport 是按当前标识符策略检查所有业务组配置的兼容性报告，
+// 供管理员在切换到 strict 模式前修正不合规的配置
+type IdentifierPolicyReport struct {
+	Mode       string                `json:"mode"`
+	Pattern    string                `json:"pattern"`
+	MaxLength  int                   `json:"max_length"`
+	BizCount   int                   `json:"biz_count"`
+	Violations []IdentifierViolation `json:"violations"`
+}
@@ -0,0 +1,21 @@
+// Package domain file: internal/core/domain/iiif_models.go
+package domain
+
+import "time"
+
+// IIIFMapping 定义了如何将业务表中的一条记录表示为 IIIF Presentation 3.0 清单 (Manifest)。
+// ImageFields 中的字段值为图像地址，一个字段可以包含多个以换行或分号分隔的地址，每个地址对应一个画布 (Canvas)。
+type IIIFMapping struct {
+	BizName        string   `json:"biz_name"`
+	TableName      string   `json:"table_name"`
+	LabelField     string   `json:"label_field"`
+	SummaryField   string   `json:"summary_field,omitempty"`
+	RightsField    string   `json:"rights_field,omitempty"`
+	ImageFields    []string `json:"image_fields"`
+	MetadataFields []string `json:"metadata_fields,omitempty"`
+	// ImageService 为 true 时，图像字段的值被视为 IIIF Image API 服务的根地址，而非图像文件本身
+	ImageService bool      `json:"image_service"`
+	WidthField   string    `json:"width_field,omitempty"`
+	HeightField  string    `json:"height_field,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,49 @@
+// Package domain file: internal/core/domain/impact_models.go
+package domain
+
+// FieldImpactReport 是修改一张表的字段配置前的影响分析结果
+type FieldImpactReport struct {
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	// RemovedReturnable 是将不再可返回的字段 (包括从配置中删除的字段)
+	RemovedReturnable []string `json:"removed_returnable"`
+	// RemovedSearchable 是将不再可检索的字段
+	RemovedSearchable []string             `json:"removed_searchable"`
+	Views             []ImpactedView       `json:"views"`
+	ShareLinks        []ImpactedShareLink  `json:"share_links"`
+	SavedQueries      []ImpactedSavedQuery `json:"saved_queries"`
+	Warnings          []string             `json:"warnings"`
+	// Breaking 为 true 表示至少有一个视图、分享链接或保存的查询会失效，应用前需要 force=true
+	Breaking bool `json:"breaking"`
+}
+
+// ImpactedView 是引用了将失去可返回性的字段的视图
+type ImpactedView struct {
+	ViewName string   `json:"view_name"`
+	Fields   []string `json:"fields"`
+}
+
+// ImpactedShareLink 是冻结查询将无法再执行的有效分享链接
+type ImpactedShareLink struct {
+	ID      int64    `json:"id"`
+	OwnerID int64    `json:"owner_id"`
+	Label   string   `json:"label,omitempty"`
+	Fields  []string `json:"fields"`
+}
+
+// ImpactedSavedQuery 是以固定过滤条件保存在虚拟业务组中、将无法再执行的查询
+type ImpactedSavedQuery struct {
+	VirtualBiz   string   `json:"virtual_biz"`
+	VirtualTable string   `json:"virtual_table"`
+	Fields       []string `json:"fields"`
+}
+
+// FieldSettingsBulkResult 是批量修改字段配置时单张表的校验结果
+type FieldSettingsBulkResult struct {
+	TableName string `json:"table_name"`
+	Fields    int    `json:"fields"`
+	// Errors 是字段配置本身的问题 (如缺少字段名、字段重复)，非空时整批修改都不会应用
+	Errors []string `json:"errors"`
+	// Impact 是该表的影响分析结果，未启用影响分析或配置本身有误时为空
+	Impact *FieldImpactReport `json:"impact,omitempty"`
+}
@@ -0,0 +1,58 @@
+// Package domain file: internal/core/domain/inspect_models.go
+package domain
+
+import "time"
+
+// ConfigCacheEntry 是配置缓存中的一个业务组配置，供管理员判断某个业务组读到的配置是否过时
+type ConfigCacheEntry struct {
+	BizName          string    `json:"biz_name"`
+	CachedAt         time.Time `json:"cached_at"`
+	AgeSeconds       float64   `json:"age_seconds"`
+	ExpiresInSeconds float64   `json:"expires_in_seconds"`
+}
+
+// ConfigCacheState 是配置缓存的当前状态
+type ConfigCacheState struct {
+	ConfigVersion string             `json:"config_version"`
+	TTLSeconds    float64            `json:"ttl_seconds"`
+	MaxEntries    int                `json:"max_entries"`
+	Entries       []ConfigCacheEntry `json:"entries"`
+}
+
+// 速率限制的维度，对应 BusinessRateLimiter 的各限制层
+const (
+	LimiterDimensionGlobal    = "global"
+	LimiterDimensionIP        = "ip"
+	LimiterDimensionUser      = "user"
+	LimiterDimensionBiz       = "biz"
+	LimiterDimensionAnonymous = "anonymous"
+)
+
+// RateLimiterBucket 是一个活跃的令牌桶。Tokens 小于 1 时该对象的下一个请求会被拒绝
+type RateLimiterBucket struct {
+	Dimension     string     `json:"dimension"`
+	Key           string     `json:"key"`
+	RatePerSecond float64    `json:"rate_per_second"`
+	Burst         int        `json:"burst"`
+	Tokens        float64    `json:"tokens"`
+	LastSeen      *time.Time `json:"last_seen,omitempty"`
+}
+
+// LoadShedState 是过载保护的当前状态，Shedding 为 true 时新的数据平面请求会被以 503 拒绝
+type LoadShedState struct {
+	Enabled      bool   `json:"enabled"`
+	InFlight     int64  `json:"in_flight"`
+	MaxInFlight  int64  `json:"max_in_flight"`
+	HeapBytes    uint64 `json:"heap_bytes"`
+	MaxHeapBytes uint64 `json:"max_heap_bytes"`
+	Shedding     bool   `json:"shedding"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// QueryCacheBizState 是查询缓存中一个业务组的条目数与旧结果降级设置
+type QueryCacheBizState struct {
+	BizName      string `json:"biz_name"`
+	Entries      int    `json:"entries"`
+	StaleEntries int    `json:"stale_entries"`
+	ServesStale  bool   `json:"serves_stale"`
+}
@@ -0,0 +1,36 @@
+// Package domain file: internal/core/domain/job_models.go
+package domain
+
+import "time"
+
+// 后台任务的状态
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// BackgroundJob 是一个在网关进程内异步执行的长时间任务 (如重建全文索引)。
+// 同一类型、业务组与目标上同时只会有一个未结束的任务。
+type BackgroundJob struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	BizName       string     `json:"biz_name"`
+	Target        string     `json:"target"`
+	Status        string     `json:"status"`
+	ProgressDone  int64      `json:"progress_done"`
+	ProgressTotal int64      `json:"progress_total"` // 0 表示总量未知
+	Message       string     `json:"message"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     int64      `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished 表示任务是否已结束 (成功、失败或被取消)
+func (j *BackgroundJob) Finished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
@@ -0,0 +1,44 @@
+// Package domain file: internal/core/domain/legacy_migration_models.go
+package domain
+
+import "time"
+
+// 旧版迁移中单个业务组的结论
+const (
+	LegacyMigrationOK      = "ok"
+	LegacyMigrationWarning = "warning"
+	LegacyMigrationError   = "error"
+)
+
+// LegacyBizMigration 是旧版单体部署中一个业务组的迁移结果与核对结论
+type LegacyBizMigration struct {
+	BizName string `json:"biz_name"`
+	// Libraries 是业务组目录下的库文件名
+	Libraries []string `json:"libraries"`
+	// DataCopied 表示库文件已从旧版目录复制到网关的 instance 目录；就地迁移时总为 false
+	DataCopied bool `json:"data_copied"`
+	// ConfigRows 是从旧版 auth.db 复制到各配置表的行数，只列出有复制的表
+	ConfigRows map[string]int64 `json:"config_rows"`
+	// InstanceID 是为业务组创建或已存在的插件实例
+	InstanceID      string `json:"instance_id,omitempty"`
+	InstanceCreated bool   `json:"instance_created"`
+	// Tables 是核对时在库文件中找到的表数
+	Tables int `json:"tables"`
+	// Problems 是核对发现的、会使业务组无法正常查询的问题；Warnings 是不影响查询的提示
+	Problems []string `json:"problems"`
+	Warnings []string `json:"warnings"`
+	Status   string   `json:"status"`
+}
+
+// LegacyMigrationReport 是从旧版 (v0.2.x) 单体部署迁移到插件架构的结果与核对报告
+type LegacyMigrationReport struct {
+	LegacyDir   string `json:"legacy_dir"`
+	InstanceDir string `json:"instance_dir"`
+	// InPlace 表示旧版目录就是网关的 instance 目录：库文件与 auth.db 原地沿用，只需创建插件实例
+	InPlace bool `json:"in_place"`
+	// DryRun 为 true 时只列出将要迁移的业务组，不做任何修改，也不做核对
+	DryRun     bool                 `json:"dry_run"`
+	Bizs       []LegacyBizMigration `json:"bizs"`
+	OK         bool                 `json:"ok"`
+	MigratedAt time.Time            `json:"migrated_at"`
+}
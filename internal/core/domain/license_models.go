@@ -0,0 +1,37 @@
+// Package domain file: internal/core/domain/license_models.go
+package domain
+
+import "time"
+
+// 授权的校验状态
+const (
+	LicenseStatusValid   = "valid"
+	LicenseStatusExpired = "expired"
+	LicenseStatusInvalid = "invalid" // 签名不匹配、插件不再需要授权或不在插件目录中
+)
+
+// LicenseClaims 是授权中由发行方签名的内容
+type LicenseClaims struct {
+	PluginID string    `json:"plugin_id"`
+	Licensee string    `json:"licensee"`
+	Features []string  `json:"features,omitempty"`
+	IssuedAt time.Time `json:"issued_at"`
+	// ExpiresAt 为空表示永久授权
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// License 是管理员录入的一份插件授权。授权码本身不对外返回，只给出指纹
+type License struct {
+	LicenseClaims
+	Fingerprint string    `json:"fingerprint"` // 授权码 SHA-256 的前 16 位十六进制
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	CreatedBy   int64     `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Entitlement 是插件启动时从网关获得的授权信息，Key 是原始授权码，插件可自行再次校验
+type Entitlement struct {
+	LicenseClaims
+	Key string `json:"key"`
+}
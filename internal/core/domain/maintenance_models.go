@@ -0,0 +1,76 @@
+// Package domain file: internal/core/domain/maintenance_models.go
+package domain
+
+import "time"
+
+// 库文件维护任务，见 port.MutateOpMaintenance
+const (
+	// MaintenanceTaskAnalyze 更新查询规划器使用的统计信息
+	MaintenanceTaskAnalyze = "analyze"
+	// MaintenanceTaskVacuum 重建库文件，回收空闲页
+	MaintenanceTaskVacuum = "vacuum"
+	// MaintenanceTaskCheckpoint 把 WAL 中的内容写回库文件并截断 WAL
+	MaintenanceTaskCheckpoint = "checkpoint"
+)
+
+// 触发维护的原因
+const (
+	// MaintenanceTriggerSchedule 表示距上次成功维护已超过配置的间隔
+	MaintenanceTriggerSchedule = "schedule"
+	// MaintenanceTriggerFragmentation 表示库文件的空闲页比例达到了阈值
+	MaintenanceTriggerFragmentation = "fragmentation"
+	// MaintenanceTriggerWAL 表示 WAL 文件的大小达到了阈值，只执行检查点
+	MaintenanceTriggerWAL = "wal"
+	// MaintenanceTriggerManual 表示由管理员手动发起
+	MaintenanceTriggerManual = "manual"
+)
+
+// 维护记录的状态
+const (
+	MaintenanceStatusSuccess = "success"
+	MaintenanceStatusFailed  = "failed"
+)
+
+// MaintenanceLibStats 是一个库文件的存储状况。Fragmentation 是空闲页占总页数的比例
+type MaintenanceLibStats struct {
+	Lib           string  `json:"lib"`
+	PageSize      int64   `json:"page_size"`
+	PageCount     int64   `json:"page_count"`
+	FreelistCount int64   `json:"freelist_count"`
+	Fragmentation float64 `json:"fragmentation"`
+	FileBytes     int64   `json:"file_bytes"`
+	WALBytes      int64   `json:"wal_bytes"`
+}
+
+// MaintenanceLibResult 是一个库文件在一次维护中的执行结果
+type MaintenanceLibResult struct {
+	Lib        string              `json:"lib"`
+	Before     MaintenanceLibStats `json:"before"`
+	After      MaintenanceLibStats `json:"after"`
+	DurationMs int64               `json:"duration_ms"`
+}
+
+// MaintenanceRun 是一次维护的记录。ReclaimedBytes 是各库文件与 WAL 合计减少的字节数
+type MaintenanceRun struct {
+	ID             int64                  `json:"id"`
+	BizName        string                 `json:"biz_name"`
+	Trigger        string                 `json:"trigger"`
+	Tasks          []string               `json:"tasks"`
+	Status         string                 `json:"status"`
+	Libs           []MaintenanceLibResult `json:"libs"`
+	ReclaimedBytes int64                  `json:"reclaimed_bytes"`
+	Error          string                 `json:"error,omitempty"`
+	StartedAt      time.Time              `json:"started_at"`
+	FinishedAt     time.Time              `json:"finished_at"`
+}
+
+// MaintenanceStatus 是一个业务组当前的存储状况、流量与最近一次维护
+type MaintenanceStatus struct {
+	BizName           string                `json:"biz_name"`
+	Libs              []MaintenanceLibStats `json:"libs"`
+	RequestsPerMinute int                   `json:"requests_per_minute"`
+	InFlight          int                   `json:"in_flight"`
+	Busy              bool                  `json:"busy"`
+	Running           bool                  `json:"running"`
+	LastRun           *MaintenanceRun       `json:"last_run,omitempty"`
+}
@@ -0,0 +1,16 @@
+// Package domain file: internal/core/domain/oaipmh_models.go
+package domain
+
+import "time"
+
+// OAIMapping 定义了一个业务表如何以 OAI-PMH 集合 (set) 的形式对外提供元数据。
+// Elements 的键为 Dublin Core 元素名 (如 title、creator)，值为业务表中的字段名。
+type OAIMapping struct {
+	BizName        string            `json:"biz_name"`
+	TableName      string            `json:"table_name"`
+	SetName        string            `json:"set_name"`
+	IDField        string            `json:"id_field"`
+	DatestampField string            `json:"datestamp_field,omitempty"`
+	Elements       map[string]string `json:"elements"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
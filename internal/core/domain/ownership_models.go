@@ -0,0 +1,14 @@
+// Package domain file: internal/core/domain/ownership_models.go
+package domain
+
+import "time"
+
+// BizOwner 表示一个被委派管理某业务组的用户 (业务组管理员)。
+// 业务组管理员只能管理自己业务组的字段设置、视图与速率限制
+type BizOwner struct {
+	BizName   string    `json:"biz_name"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	GrantedBy *int64    `json:"granted_by,omitempty"`
+	GrantedAt time.Time `json:"granted_at"`
+}
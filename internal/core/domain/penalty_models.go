@@ -0,0 +1,24 @@
+// Package domain file: internal/core/domain/penalty_models.go
+package domain
+
+import "time"
+
+// 惩罚记录的类型
+const (
+	// PenaltyKindLogin 是登录失败计数与锁定，对象为 "IP|用户名"
+	PenaltyKindLogin = "login"
+	// PenaltyKindIP 是按 IP 累计的限流违规与临时封禁
+	PenaltyKindIP = "ip"
+	// PenaltyKindUser 是按用户 ID 累计的限流违规与临时封禁
+	PenaltyKindUser = "user"
+)
+
+// Penalty 是一条需要跨重启保留的限流状态：时间窗口内的违规次数，以及可能的锁定或封禁截止时间
+type Penalty struct {
+	Kind        string     `json:"kind"`
+	Subject     string     `json:"subject"`
+	Strikes     int        `json:"strikes"`
+	WindowStart time.Time  `json:"window_start"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
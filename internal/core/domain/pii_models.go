@@ -0,0 +1,53 @@
+// Package domain file: internal/core/domain/pii_models.go
+package domain
+
+import "time"
+
+// 个人信息 (PII) 的类型
+const (
+	PIIKindEmail      = "email"
+	PIIKindPhone      = "phone"
+	PIIKindNationalID = "national_id" // 居民身份证号 (18 位，校验码有效)
+	PIIKindCreditCard = "credit_card" // 通过 Luhn 校验的 13-19 位卡号
+	PIIKindIPAddress  = "ip_address"
+)
+
+// PIIScanReport 是对一个业务组的抽样扫描结果。Public 表示业务组当前对匿名访问者开放检索
+type PIIScanReport struct {
+	BizName    string         `json:"biz_name"`
+	Public     bool           `json:"public"`
+	SampleSize int            `json:"sample_size"`
+	Threshold  float64        `json:"threshold"`
+	Tables     []PIITableScan `json:"tables"`
+	// Flagged 是被判定为可能包含个人信息的字段数
+	Flagged   int       `json:"flagged"`
+	ScannedBy int64     `json:"scanned_by"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// PIITableScan 是一张表的扫描结果，Sampled 为实际抽取的行数
+type PIITableScan struct {
+	TableName string         `json:"table_name"`
+	Sampled   int            `json:"sampled"`
+	Fields    []PIIFieldScan `json:"fields"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// PIIFieldScan 是一个可返回字段的扫描结果。NonEmpty 是样本中非空值的个数，
+// Detections 按命中比例从高到低排列；NameHint 是字段名暗示的类型 (如 "email"、"phone")
+type PIIFieldScan struct {
+	Field      string         `json:"field"`
+	Searchable bool           `json:"searchable"`
+	NonEmpty   int            `json:"non_empty"`
+	Detections []PIIDetection `json:"detections"`
+	NameHint   string         `json:"name_hint,omitempty"`
+	// Flagged 为 true 表示某类个人信息的命中比例达到阈值，或字段名暗示个人信息且样本中有非空值
+	Flagged bool `json:"flagged"`
+}
+
+// PIIDetection 是一类个人信息在字段样本中的命中情况，Ratio 为命中数占非空值的比例
+type PIIDetection struct {
+	Kind    string  `json:"kind"`
+	Matches int     `json:"matches"`
+	Ratio   float64 `json:"ratio"`
+}
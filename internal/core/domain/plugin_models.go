@@ -23,6 +23,14 @@ type PluginManifest struct {
 	Tags              []string        `json:"tags"`
 	SupportedBizNames []string        `json:"supported_biz_names"`
 	Versions          []PluginVersion `json:"versions"`
+	// License 不为空表示插件需要管理员录入有效的授权后才能启动
+	License *LicenseRequirement `json:"license,omitempty"`
+}
+
+// LicenseRequirement 声明插件的授权要求
+type LicenseRequirement struct {
+	// PublicKey 是插件发行方用于签发授权的 Ed25519 公钥 (标准 base64 编码)，网关据此离线校验授权
+	PublicKey string `json:"public_key"`
 }
 
 // PluginVersion 代表插件的一个特定版本
@@ -50,14 +58,80 @@ type Execution struct {
 // PluginInstance 代表一个已配置的、可运行的插件实例。
 // 将一个“已安装插件”转化为一个具体“服务”的配置实体。
 type PluginInstance struct {
-	InstanceID    string       `json:"instance_id"`
-	DisplayName   string       `json:"display_name"`
-	PluginID      string       `json:"plugin_id"`
-	Version       string       `json:"version"`
-	BizName       string       `json:"biz_name"`
-	Port          int          `json:"port"`
+	InstanceID  string `json:"instance_id"`
+	DisplayName string `json:"display_name"`
+	PluginID    string `json:"plugin_id"`
+	Version     string `json:"version"`
+	BizName     string `json:"biz_name"`
+	Port        int    `json:"port"`
+	Mode        string `json:"mode"` // process 或 embedded
+	// ProcessOptions 仅对独立进程模式生效，修改后在下次启动时生效
+	ProcessOptions PluginProcessOptions `json:"process_options"`
+	// DependsOn 是启动前必须运行且健康的其它实例ID，网关停机时依赖方先于被依赖方停止
+	DependsOn     []string     `json:"depends_on"`
 	Status        string       `json:"status"`
 	Enabled       bool         `json:"enabled"`
 	CreatedAt     time.Time    `json:"created_at"`
 	LastStartedAt sql.NullTime `json:"last_started_at"`
+	// LastStopReason 是最近一次停止的原因，例如 admin_stop、health_check_failed、process_exited
+	LastStopReason string       `json:"last_stop_reason"`
+	LastStoppedAt  sql.NullTime `json:"last_stopped_at"`
+}
+
+// PluginEvent 是插件或插件实例的一次状态变化记录。
+// 安装与升级属于插件本身，InstanceID 为空。
+type PluginEvent struct {
+	ID         int64     `json:"id"`
+	InstanceID string    `json:"instance_id,omitempty"`
+	PluginID   string    `json:"plugin_id"`
+	Version    string    `json:"version"`
+	Event      string    `json:"event"` // installed, upgraded, started, restarted, stopped, crashed
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PluginProcessOptions 控制独立进程模式下插件进程的运行环境，用于避免网关的敏感环境变量泄漏到插件中
+type PluginProcessOptions struct {
+	// EnvAllowlist 非空时插件只继承列出的网关环境变量（支持 "LC_*" 形式的前缀匹配）；为空时继承全部
+	EnvAllowlist []string `json:"env_allowlist,omitempty"`
+	// Env 为插件额外注入的环境变量，优先于继承的同名变量
+	Env map[string]string `json:"env,omitempty"`
+	// WorkDir 是插件进程的工作目录，相对路径相对于 instance 目录；为空时沿用网关的工作目录
+	WorkDir string `json:"work_dir,omitempty"`
+	// Umask 是插件进程的文件创建掩码（八进制，如 "0077"），仅类 Unix 系统支持；为空时沿用网关的设置
+	Umask string `json:"umask,omitempty"`
+}
+
+// PluginExit 是插件实例进程的一次退出
+type PluginExit struct {
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"` // 停止原因，与 PluginInstance.LastStopReason 相同
+	// ExitCode 是进程的退出码，被信号结束或无法获取时为 -1
+	ExitCode int `json:"exit_code"`
+	// Status 是操作系统报告的退出状态，如 "exit status 2"、"signal: killed"
+	Status string `json:"status"`
+}
+
+// PluginRPCError 是网关调用插件时收到的一次 gRPC 错误
+type PluginRPCError struct {
+	At      time.Time `json:"at"`
+	Method  string    `json:"method"`
+	Code    string    `json:"code"`
+	Message string    `json:"message"`
+}
+
+// 诊断包的收集原因
+const (
+	CrashDumpTriggerCrashLoop = "crash_loop" // 实例在短时间内反复崩溃，由网关自动收集
+	CrashDumpTriggerManual    = "manual"     // 管理员通过 API 收集
+)
+
+// PluginCrashDump 是一个已收集的插件实例诊断包 (zip)，包含最近的日志、退出记录、实例配置、
+// 网关侧的 gRPC 错误与运行环境摘要，供附在提交给插件作者的问题报告中
+type PluginCrashDump struct {
+	Name       string    `json:"name"`
+	InstanceID string    `json:"instance_id"`
+	Trigger    string    `json:"trigger"`
+	CreatedAt  time.Time `json:"created_at"`
+	SizeBytes  int64     `json:"size_bytes"`
 }
@@ -0,0 +1,14 @@
+// Package domain file: internal/core/domain/provenance_models.go
+package domain
+
+import "time"
+
+// Provenance 是附加在导出文件与大批量检索结果中的来源信息，用于追溯被二次传播的档案数据。
+// RequestedBy 是请求用户编号的带密钥摘要，匿名访问时为 "anonymous"；管理员可据此反查用户
+type Provenance struct {
+	Issuer      string    `json:"issuer,omitempty"`
+	Sources     []string  `json:"sources"`
+	ExportedAt  time.Time `json:"exported_at"`
+	RequestedBy string    `json:"requested_by"`
+	License     string    `json:"license,omitempty"`
+}
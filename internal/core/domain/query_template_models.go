@@ -0,0 +1,51 @@
+// Package domain file: internal/core/domain/query_template_models.go
+package domain
+
+import "time"
+
+// 查询模板参数的类型
+const (
+	QueryTemplateParamText    = "text"
+	QueryTemplateParamInteger = "integer"
+	QueryTemplateParamNumber  = "number"
+)
+
+// QueryTemplate 是管理员为业务组预先定义的查询：过滤条件的结构固定，部分取值由调用方以命名参数传入。
+// 客户端通过 meta 接口列出模板，按名称执行，参数在服务端校验后展开为普通的查询
+type QueryTemplate struct {
+	BizName     string `json:"biz_name"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	TableName   string `json:"table_name"`
+	// Filters 与查询请求的 filters 格式相同，但取值可以引用参数
+	Filters []QueryTemplateFilter `json:"filters"`
+	Params  []QueryTemplateParam  `json:"params,omitempty"`
+	// FieldsToReturn 为空时返回表中全部可返回的字段
+	FieldsToReturn []string  `json:"fields_to_return,omitempty"`
+	UpdatedBy      int64     `json:"updated_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// QueryTemplateFilter 是模板中的一个过滤条件。Param 不为空时取值来自同名参数，否则使用固定的 Value (op 为 in 时为数组)。
+// 引用的参数未传入且没有默认值时 (只有非必填参数会出现这种情况)，该条件被整体省略
+type QueryTemplateFilter struct {
+	Field  string      `json:"field"`
+	Op     string      `json:"op,omitempty"`
+	Logic  string      `json:"logic,omitempty"`
+	Fuzzy  bool        `json:"fuzzy,omitempty"`
+	Negate bool        `json:"negate,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+	Param  string      `json:"param,omitempty"`
+}
+
+// QueryTemplateParam 是模板的一个命名参数。Multiple 为 true 时参数值是数组，只能用于 op 为 in 的条件；
+// Enum 不为空时参数值必须是其中之一
+type QueryTemplateParam struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Multiple    bool        `json:"multiple,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
@@ -0,0 +1,15 @@
+// Package domain file: internal/core/domain/read_split_biz_models.go
+package domain
+
+import "time"
+
+// ReadSplitBiz 定义了一个读写分离的业务组：它本身不持有数据，写操作发往写入实例所在的业务组，
+// 查询在各只读副本业务组之间轮询分担，副本不可用时依次切换到其他副本，全部不可用时由写入实例处理。
+// 写入实例与副本各自是独立注册的业务组 (通常由同步功能保持数据一致)，访问控制由读写分离业务组自身的配置决定。
+type ReadSplitBiz struct {
+	BizName     string    `json:"biz_name"`
+	Description string    `json:"description,omitempty"`
+	Writer      string    `json:"writer"`
+	Readers     []string  `json:"readers"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,40 @@
+// Package domain file: internal/core/domain/record_link_models.go
+package domain
+
+import "time"
+
+// 关联相对于某条记录的方向
+const (
+	// LinkDirectionOutgoing 表示记录是关联的源端，如文书引用人名规范档
+	LinkDirectionOutgoing = "outgoing"
+	// LinkDirectionIncoming 表示记录是关联的目标端，如人名规范档被文书引用
+	LinkDirectionIncoming = "incoming"
+)
+
+// RecordLink 是两个业务组记录之间的一条关联 (规范档交叉引用)。
+// 记录以 业务组/表/主键 定位，主键的格式与 /data/record 永久链接中的相同
+type RecordLink struct {
+	ID          int64  `json:"id"`
+	SourceBiz   string `json:"source_biz"`
+	SourceTable string `json:"source_table"`
+	SourceID    string `json:"source_id"`
+	TargetBiz   string `json:"target_biz"`
+	TargetTable string `json:"target_table"`
+	TargetID    string `json:"target_id"`
+	// Relation 是关联的类型，如 "author"、"mentions"，默认为 "related"
+	Relation  string    `json:"relation"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LinkedRecord 是展开到某条记录上的关联，只给出另一端记录的位置，不含其内容；
+// 另一端的字段仍需经其所属业务组的权限检查后读取
+type LinkedRecord struct {
+	LinkID    int64  `json:"link_id"`
+	Direction string `json:"direction"`
+	Relation  string `json:"relation"`
+	Biz       string `json:"biz"`
+	Table     string `json:"table"`
+	ID        string `json:"id"`
+}
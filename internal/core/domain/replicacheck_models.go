@@ -0,0 +1,31 @@
+// Package domain file: internal/core/domain/replicacheck_models.go
+package domain
+
+import "time"
+
+// ReplicaQueryCheck 是一条抽样查询在两侧的比对结果。
+// 两侧都返回了完整结果集时才逐行比对 (ItemsCompared)，否则只比对总数；
+// Missing 与 Extra 是仅出现在本地或仅出现在对端的行 (规范化 JSON)，各至多保留 5 条
+type ReplicaQueryCheck struct {
+	Table         string                 `json:"table"`
+	Query         map[string]interface{} `json:"query"`
+	LocalTotal    int64                  `json:"local_total"`
+	RemoteTotal   int64                  `json:"remote_total"`
+	ItemsCompared bool                   `json:"items_compared"`
+	Missing       []string               `json:"missing,omitempty"`
+	Extra         []string               `json:"extra,omitempty"`
+	Diverged      bool                   `json:"diverged"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// ReplicaVerification 汇总一次副本比对。Against 描述对端，如 "biz:archive_restored" 或远端地址与业务组
+type ReplicaVerification struct {
+	BizName   string              `json:"biz_name"`
+	Against   string              `json:"against"`
+	Queries   int                 `json:"queries"`
+	Diverged  int                 `json:"diverged"`
+	Errors    int                 `json:"errors"`
+	Checks    []ReplicaQueryCheck `json:"checks"`
+	StartedAt time.Time           `json:"started_at"`
+	Duration  string              `json:"duration"`
+}
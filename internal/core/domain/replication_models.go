@@ -0,0 +1,18 @@
+// Package domain file: internal/core/domain/replication_models.go
+package domain
+
+import "time"
+
+// ReplicationTarget 定义了一个业务组向远端 ArchiveAegis 实例单向同步的目标及其运行状态。
+// 冲突策略固定为“源端优先”：远端的同名记录会被源端的变更直接覆盖。
+type ReplicationTarget struct {
+	BizName      string     `json:"biz_name"`
+	TargetURL    string     `json:"target_url"`
+	TargetBiz    string     `json:"target_biz"`
+	AuthToken    string     `json:"auth_token,omitempty"`
+	Enabled      bool       `json:"enabled"`
+	Checkpoint   string     `json:"checkpoint"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	PushedTotal  int64      `json:"pushed_total"`
+}
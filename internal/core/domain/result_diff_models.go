@@ -0,0 +1,42 @@
+// Package domain file: internal/core/domain/result_diff_models.go
+package domain
+
+// 结果比对中一行的差异类型
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// ResultDiff 是同一查询在两个业务组 (如当前数据与从快照恢复的副本) 上结果的差异，行按键字段对应。
+// Entries 是当前页的差异行，按键排序；Summary 统计全部结果
+type ResultDiff struct {
+	Before  string      `json:"before"`
+	After   string      `json:"after"`
+	Table   string      `json:"table"`
+	Key     []string    `json:"key"`
+	Summary DiffSummary `json:"summary"`
+	Entries []DiffEntry `json:"entries"`
+	Page    int         `json:"page"`
+	Size    int         `json:"size"`
+}
+
+// DiffSummary 统计两侧结果的差异。Total 是差异行数 (Added + Removed + Changed)
+type DiffSummary struct {
+	BeforeRows int `json:"before_rows"`
+	AfterRows  int `json:"after_rows"`
+	Added      int `json:"added"`
+	Removed    int `json:"removed"`
+	Changed    int `json:"changed"`
+	Unchanged  int `json:"unchanged"`
+	Total      int `json:"total"`
+}
+
+// DiffEntry 是一行的差异。added 只有 After，removed 只有 Before；changed 两者都有，Fields 列出取值不同的字段
+type DiffEntry struct {
+	Status string                 `json:"status"`
+	Key    map[string]interface{} `json:"key"`
+	Before map[string]interface{} `json:"before,omitempty"`
+	After  map[string]interface{} `json:"after,omitempty"`
+	Fields []string               `json:"fields,omitempty"`
+}
@@ -0,0 +1,44 @@
+// Package domain file: internal/core/domain/security_event_models.go
+package domain
+
+import "time"
+
+// 转发到 SIEM 的安全事件类型
+const (
+	SecurityEventLoginSuccess = "login_success"
+	SecurityEventLoginFailure = "login_failure"
+	// SecurityEventLoginLockout 表示同一 IP 对同一账户连续登录失败达到阈值，已被锁定
+	SecurityEventLoginLockout = "login_lockout"
+	// SecurityEventBan 表示 IP 或用户因限流违规或异常行为被临时封禁
+	SecurityEventBan = "ban"
+	// SecurityEventAdminChange 表示一次管理接口上的写操作 (非 GET 请求)
+	SecurityEventAdminChange = "admin_change"
+)
+
+// SecurityEvent 是一条结构化的安全事件。Subject 是事件针对的对象 (被封禁的 IP、管理接口路径等)，
+// Detail 保存事件类型特有的字段
+type SecurityEvent struct {
+	Type     string                 `json:"type"`
+	Time     time.Time              `json:"time"`
+	UserID   *int64                 `json:"user_id,omitempty"`
+	Username string                 `json:"username,omitempty"`
+	IP       string                 `json:"ip,omitempty"`
+	Subject  string                 `json:"subject,omitempty"`
+	Detail   map[string]interface{} `json:"detail,omitempty"`
+}
+
+// SecurityForwarderStatus 是安全事件转发的队列状态。Dropped 是自网关启动以来因队列已满而丢弃的最旧事件数
+type SecurityForwarderStatus struct {
+	Sinks   []SecuritySinkStatus `json:"sinks"`
+	Dropped int64                `json:"dropped"`
+}
+
+// SecuritySinkStatus 是一个 SIEM 接收端的待发送事件数与最近一次投递的结果
+type SecuritySinkStatus struct {
+	Sink          string     `json:"sink"`
+	Pending       int64      `json:"pending"`
+	OldestPending *time.Time `json:"oldest_pending,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastDelivered *time.Time `json:"last_delivered,omitempty"`
+	NextRetry     *time.Time `json:"next_retry,omitempty"`
+}
@@ -0,0 +1,16 @@
+// Package domain file: internal/core/domain/semantic_models.go
+package domain
+
+import "time"
+
+// SemanticSearchConfig 是一张表的语义检索配置
+type SemanticSearchConfig struct {
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	// Fields 是参与向量化的描述字段，各字段的值按顺序以换行拼接为一条文本
+	Fields []string `json:"fields"`
+	// Model 是最近一次建立索引时使用的嵌入模型，尚未建立索引时为空
+	Model     string     `json:"model"`
+	IndexedAt *time.Time `json:"indexed_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
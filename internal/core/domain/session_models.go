@@ -0,0 +1,26 @@
+// Package domain file: internal/core/domain/session_models.go
+package domain
+
+import "time"
+
+// 登录会话被注销的原因
+const (
+	// SessionRevokedEvicted 表示会话数达到上限后，最早的会话被新的登录挤下线
+	SessionRevokedEvicted = "evicted"
+	// SessionRevokedAdmin 表示会话被管理员注销
+	SessionRevokedAdmin = "admin"
+)
+
+// UserSession 是一次登录签发的 Token 对应的会话，ID 即 Token 的 jti。
+// IP 与 UserAgent 取自登录请求，LastSeenAt 是最近一次使用该 Token 的时间 (按分钟更新)
+type UserSession struct {
+	ID           string     `json:"id"`
+	UserID       int64      `json:"user_id"`
+	IP           string     `json:"ip"`
+	UserAgent    string     `json:"user_agent"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+}
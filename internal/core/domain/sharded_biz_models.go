@@ -0,0 +1,25 @@
+// Package domain file: internal/core/domain/sharded_biz_models.go
+package domain
+
+import "time"
+
+// ShardedBiz 定义了一个水平分片的业务组：它本身不持有数据，记录按分片键的取值范围分布在多个分片中，
+// 每个分片是一个独立注册的业务组 (各自的库文件与插件实例)，表结构相同。
+// 查询约束了分片键时只路由到相关分片，否则扇出到全部分片并合并结果；访问控制仍由各分片业务组的配置决定。
+type ShardedBiz struct {
+	BizName     string `json:"biz_name"`
+	Description string `json:"description,omitempty"`
+	// ShardKey 是决定记录所在分片的字段，所有分片中的所有表都应包含该字段，且该字段应按原值精确匹配
+	// (未开启忽略大小写或变音符号)，否则按分片键路由的查询可能遗漏其他分片中的记录
+	ShardKey  string    `json:"shard_key"`
+	Shards    []Shard   `json:"shards"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Shard 是分片键取值落在 [From, To) 范围内的记录所在的业务组，From 或 To 为空表示该侧无界。
+// 同一分片定义中的范围边界须全部为数字 (按数值比较) 或全部为文本 (按字符串比较)，各分片的范围不能重叠
+type Shard struct {
+	SourceBiz string `json:"source_biz"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+}
@@ -0,0 +1,22 @@
+// Package domain file: internal/core/domain/share_models.go
+package domain
+
+import "time"
+
+// ShareLink 是一条冻结查询的公开分享链接。令牌只在创建时返回一次，库中只保存其哈希
+type ShareLink struct {
+	ID      int64  `json:"id"`
+	OwnerID int64  `json:"owner_id"`
+	Label   string `json:"label,omitempty"`
+	BizName string `json:"biz_name"`
+	// Query 是冻结的查询体 (table、filters、fields_to_return、size)，访问者只能翻页
+	Query map[string]interface{} `json:"query"`
+	// TokenPrefix 是令牌的前几位，便于用户在列表中辨认
+	TokenPrefix   string     `json:"token_prefix"`
+	RatePerMinute int        `json:"rate_per_minute"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	UseCount      int64      `json:"use_count"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
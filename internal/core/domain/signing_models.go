@@ -0,0 +1,17 @@
+// Package domain file: internal/core/domain/signing_models.go
+package domain
+
+import "time"
+
+// SigningKey 是供服务器间集成使用的请求签名密钥。请求以密钥所属用户的身份与角色执行，
+// KeyID 随请求公开，对应的签名密钥只在创建时返回一次
+type SigningKey struct {
+	ID         int64      `json:"id"`
+	KeyID      string     `json:"key_id"`
+	Label      string     `json:"label"`
+	UserID     int64      `json:"user_id"`
+	Username   string     `json:"username"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
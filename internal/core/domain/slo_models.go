@@ -0,0 +1,60 @@
+// Package domain file: internal/core/domain/slo_models.go
+package domain
+
+import "time"
+
+// 服务等级目标的种类
+const (
+	// SLOObjectiveAvailability 统计非 5xx 响应所占的比例
+	SLOObjectiveAvailability = "availability"
+	// SLOObjectiveLatency 统计耗时不超过阈值的请求所占的比例
+	SLOObjectiveLatency = "latency"
+)
+
+// 错误预算告警的种类
+const (
+	// SLOAlertFastBurn 表示最近 1 小时的消耗速度足以在约两天内耗尽整个窗口的错误预算
+	SLOAlertFastBurn = "fast_burn"
+	// SLOAlertSlowBurn 表示最近 6 小时的消耗速度足以在约五天内耗尽整个窗口的错误预算
+	SLOAlertSlowBurn = "slow_burn"
+	// SLOAlertExhausted 表示窗口内的错误预算已经耗尽
+	SLOAlertExhausted = "exhausted"
+)
+
+// BizSLO 是业务组的服务等级目标。目标值为 0 表示不设该项目标；WindowDays 是统计窗口的天数
+type BizSLO struct {
+	BizName            string    `json:"biz_name"`
+	AvailabilityTarget float64   `json:"availability_target"`
+	LatencyThresholdMs int       `json:"latency_threshold_ms"`
+	LatencyTarget      float64   `json:"latency_target"`
+	WindowDays         int       `json:"window_days"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// SLOObjectiveStatus 是一项目标在统计窗口内的达成情况。ErrorBudgetRemaining 是剩余错误预算的比例，
+// 耗尽后为负数；BurnRates 是各时间段内错误预算的消耗速度，1 表示恰好在窗口结束时耗尽
+type SLOObjectiveStatus struct {
+	Objective            string             `json:"objective"`
+	Target               float64            `json:"target"`
+	Actual               float64            `json:"actual"`
+	Requests             int64              `json:"requests"`
+	BadRequests          int64              `json:"bad_requests"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"`
+	BurnRates            map[string]float64 `json:"burn_rates"`
+	Alert                string             `json:"alert,omitempty"`
+}
+
+// SLOStatus 是一个业务组的服务等级目标及其达成情况
+type SLOStatus struct {
+	SLO         BizSLO               `json:"slo"`
+	Objectives  []SLOObjectiveStatus `json:"objectives"`
+	GeneratedAt time.Time            `json:"generated_at"`
+}
+
+// SLOAlert 是发送到 webhook 的错误预算告警
+type SLOAlert struct {
+	BizName string             `json:"biz_name"`
+	Alert   string             `json:"alert"`
+	Status  SLOObjectiveStatus `json:"status"`
+	FiredAt time.Time          `json:"fired_at"`
+}
@@ -0,0 +1,46 @@
+// Package domain file: internal/core/domain/storage_models.go
+package domain
+
+import "time"
+
+// StorageFile 是一个库文件及其 WAL 与共享内存文件占用的空间
+type StorageFile struct {
+	FileName string `json:"file_name"`
+	Bytes    int64  `json:"bytes"`
+	WALBytes int64  `json:"wal_bytes"`
+	SHMBytes int64  `json:"shm_bytes"`
+}
+
+// StorageBiz 是一个业务组的库文件所占用的空间
+type StorageBiz struct {
+	BizName string        `json:"biz_name"`
+	Bytes   int64         `json:"bytes"`
+	Files   []StorageFile `json:"files"`
+}
+
+// StorageDirectory 是一个目录 (含子目录) 占用的空间，目录不存在时 Exists 为 false
+type StorageDirectory struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	Files  int    `json:"files"`
+	Exists bool   `json:"exists"`
+}
+
+// DiskSpace 是 instance 目录所在卷的空间。Low 表示可用空间低于配置的下限，此时写操作与插件安装被拒绝
+type DiskSpace struct {
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	MinFree    uint64 `json:"min_free_bytes"`
+	Low        bool   `json:"low"`
+}
+
+// StorageReport 汇总业务组库文件、instance 目录、插件安装目录与备份目录的空间占用
+type StorageReport struct {
+	Disk        DiskSpace          `json:"disk"`
+	Bizs        []StorageBiz       `json:"bizs"`
+	Instance    StorageDirectory   `json:"instance"`
+	Plugins     StorageDirectory   `json:"plugins"`
+	Backups     []StorageDirectory `json:"backups"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
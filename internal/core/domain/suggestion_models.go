@@ -0,0 +1,21 @@
+// Package domain file: internal/core/domain/suggestion_models.go
+package domain
+
+import "time"
+
+// SearchSuggestion 是对一个过滤值的拼写纠正建议 ("您是不是要找")
+type SearchSuggestion struct {
+	Field      string `json:"field"`
+	Value      string `json:"value"`      // 用户输入的原值
+	Suggestion string `json:"suggestion"` // 替换了疑似拼错的词之后的值
+	Distance   int    `json:"distance"`   // 各词编辑距离之和
+}
+
+// SuggestionVocabulary 描述一个字段已建立的拼写建议词表
+type SuggestionVocabulary struct {
+	BizName   string    `json:"biz_name"`
+	TableName string    `json:"table_name"`
+	FieldName string    `json:"field_name"`
+	TermCount int64     `json:"term_count"`
+	BuiltAt   time.Time `json:"built_at"`
+}
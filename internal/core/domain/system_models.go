@@ -0,0 +1,75 @@
+// Package domain file: internal/core/domain/system_models.go
+package domain
+
+import "time"
+
+// BuildInfo 描述网关的版本与构建信息。Commit 与 BuildDate 在构建时通过 -ldflags 注入，
+// 未注入时取 Go 工具链记录的 VCS 信息，仍然没有时为空
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"` // GOOS/GOARCH
+}
+
+// SystemInfo 是 /api/v1/system/info 的响应，供技术支持排查与批量审计部署版本
+type SystemInfo struct {
+	BuildInfo
+	EnabledFeatures []string               `json:"enabled_features"`
+	Plugins         []RunningPluginSummary `json:"plugins"`
+}
+
+// RunningPluginSummary 是一个正在运行的插件实例及其版本
+type RunningPluginSummary struct {
+	InstanceID string `json:"instance_id"`
+	PluginID   string `json:"plugin_id"`
+	Version    string `json:"version"`
+	BizName    string `json:"biz_name"`
+	Mode       string `json:"mode"`
+}
+
+// BootReport 汇总网关的一次启动：配置来源、启用的功能与组件、插件实例、已注册的业务组以及启动中遇到的问题。
+// 启动完成时记录一次日志，并由 /api/v1/system/boot-report 返回，配置错误在启动后即可发现
+type BootReport struct {
+	Version   string     `json:"version"`
+	StartedAt time.Time  `json:"started_at"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	StartupMS int64      `json:"startup_ms,omitempty"`
+	Listen    string     `json:"listen,omitempty"`
+	Config    BootConfig `json:"config"`
+	// EnabledFeatures 是 auth.db 中启用的系统功能
+	EnabledFeatures []string `json:"enabled_features"`
+	// Components 是 config.yaml 中启用的可选组件及其主要设置
+	Components      []BootComponent `json:"components"`
+	BackgroundTasks []string        `json:"background_tasks"`
+	Plugins         []BootPlugin    `json:"plugins"`
+	// Bizs 是启动完成时已注册数据源的业务组
+	Bizs     []string `json:"bizs"`
+	Warnings []string `json:"warnings"`
+}
+
+// BootConfig 描述配置的来源
+type BootConfig struct {
+	File   string `json:"file"`
+	Loaded bool   `json:"loaded"`
+	// Defaults 是配置文件中没有、使用内置默认值的配置项
+	Defaults []string `json:"defaults"`
+}
+
+// BootComponent 是一个启用的可选组件
+type BootComponent struct {
+	Name     string                 `json:"name"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// BootPlugin 是启动完成时一个插件实例的状态，Error 是启动失败的原因
+type BootPlugin struct {
+	InstanceID string `json:"instance_id"`
+	PluginID   string `json:"plugin_id"`
+	BizName    string `json:"biz_name"`
+	Mode       string `json:"mode"`
+	Enabled    bool   `json:"enabled"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
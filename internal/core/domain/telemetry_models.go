@@ -0,0 +1,29 @@
+// Package domain file: internal/core/domain/telemetry_models.go
+package domain
+
+import "time"
+
+// TelemetryReport 是匿名使用统计发送的全部内容。只包含聚合数量与区间，不包含业务组名、插件名、用户或请求内容
+type TelemetryReport struct {
+	SchemaVersion int `json:"schema_version"`
+	// InstallationID 是首次生成时随机产生的标识，只用于去重，与部署的主机或组织无关
+	InstallationID   string `json:"installation_id"`
+	Version          string `json:"version"`
+	Platform         string `json:"platform"` // GOOS/GOARCH
+	InstalledPlugins int    `json:"installed_plugins"`
+	RunningInstances int    `json:"running_instances"`
+	BizCount         int    `json:"biz_count"`
+	// RequestVolume 是自上次发送 (或网关启动) 以来请求数所在的区间，例如 "1000-9999"
+	RequestVolume string `json:"request_volume"`
+	Day           string `json:"day"` // 生成日期 (UTC，YYYY-MM-DD)，不精确到时刻
+}
+
+// TelemetryStatus 描述使用统计的开关、发送状态，以及下一次将要发送的内容
+type TelemetryStatus struct {
+	Enabled       bool            `json:"enabled"`
+	Endpoint      string          `json:"endpoint,omitempty"`
+	IntervalHours int             `json:"interval_hours"`
+	LastSentAt    *time.Time      `json:"last_sent_at,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	Preview       TelemetryReport `json:"preview"`
+}
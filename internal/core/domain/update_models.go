@@ -0,0 +1,48 @@
+// Package domain file: internal/core/domain/update_models.go
+package domain
+
+import "time"
+
+// UpdateReport 是最近一次更新检查的结果
+type UpdateReport struct {
+	CheckedAt time.Time      `json:"checked_at"`
+	Gateway   GatewayUpdate  `json:"gateway"`
+	Plugins   []PluginUpdate `json:"plugins"`
+	// Errors 是检查过程中的非致命错误，例如发布源不可达
+	Errors []string `json:"errors,omitempty"`
+}
+
+// GatewayUpdate 描述网关自身的版本与发布源中的最新版本。网关不会自动替换自身，只提示管理员
+type GatewayUpdate struct {
+	CurrentVersion  string    `json:"current_version"`
+	LatestVersion   string    `json:"latest_version,omitempty"`
+	UpdateAvailable bool      `json:"update_available"`
+	ReleaseDate     time.Time `json:"release_date,omitempty"`
+	URL             string    `json:"url,omitempty"`
+	Changelog       string    `json:"changelog,omitempty"`
+}
+
+// PluginUpdate 描述一个已安装插件在仓库中的可用新版本
+type PluginUpdate struct {
+	PluginID         string    `json:"plugin_id"`
+	InstalledVersion string    `json:"installed_version"`
+	LatestVersion    string    `json:"latest_version"`
+	ReleaseDate      time.Time `json:"release_date"`
+	Changelog        string    `json:"changelog,omitempty"`
+	// Compatible 为 false 表示新版本要求的网关版本 (MinGatewayVersion) 高于当前网关
+	Compatible bool `json:"compatible"`
+	// OutdatedInstances 是仍在使用旧版本的独立进程实例，应用更新时会被切换到新版本
+	OutdatedInstances []string `json:"outdated_instances"`
+	// StagedVersion 不为空表示该版本已下载安装，等待应用
+	StagedVersion string     `json:"staged_version,omitempty"`
+	StagedAt      *time.Time `json:"staged_at,omitempty"`
+}
+
+// PluginUpdateResult 是应用插件更新时单个实例的切换结果
+type PluginUpdateResult struct {
+	InstanceID  string `json:"instance_id"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	Restarted   bool   `json:"restarted"`
+	Error       string `json:"error,omitempty"`
+}
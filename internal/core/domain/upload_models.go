@@ -0,0 +1,27 @@
+// Package domain file: internal/core/domain/upload_models.go
+package domain
+
+import "time"
+
+// 分块上传会话的状态
+const (
+	// UploadStatusUploading 表示会话仍在接收分块
+	UploadStatusUploading = "uploading"
+	// UploadStatusComplete 表示全部分块已接收且校验通过，可以交给导入、替换等接口使用
+	UploadStatusComplete = "complete"
+)
+
+// UploadSession 是一次分块上传。Received 是已确认写入的字节数，断点续传时从该偏移继续上传
+type UploadSession struct {
+	ID        string `json:"id"`
+	UserID    int64  `json:"user_id"`
+	FileName  string `json:"file_name"`
+	TotalSize int64  `json:"total_size"`
+	// SHA256 是客户端声明的整个文件的校验值，为空时完成上传时不校验
+	SHA256    string    `json:"sha256,omitempty"`
+	Received  int64     `json:"received"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,27 @@
+// Package domain file: internal/core/domain/virtual_biz_models.go
+package domain
+
+import "time"
+
+// VirtualBiz 定义了一个虚拟业务组：它本身不持有数据，其每张表都映射自其它已注册业务组的表。
+// 查询被路由到底层数据源，访问控制仍由底层业务组的配置决定；虚拟业务组始终只读。
+type VirtualBiz struct {
+	BizName     string         `json:"biz_name"`
+	Description string         `json:"description,omitempty"`
+	Tables      []VirtualTable `json:"tables"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// VirtualTable 将底层业务组的一张表以 Name 的名义暴露，并可附加固定的等值过滤条件
+type VirtualTable struct {
+	Name        string               `json:"name"`
+	SourceBiz   string               `json:"source_biz"`
+	SourceTable string               `json:"source_table"`
+	Filters     []VirtualTableFilter `json:"filters,omitempty"`
+}
+
+// VirtualTableFilter 是附加到虚拟表所有查询上的固定过滤条件
+type VirtualTableFilter struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,36 @@
+// Package domain file: internal/core/domain/vocabulary_models.go
+package domain
+
+import "time"
+
+// 受控词表的来源
+const (
+	// VocabularyKindList 表示由管理员上传的词表，词条保存在网关中
+	VocabularyKindList = "list"
+	// VocabularyKindEndpoint 表示由外部权威列表接口提供的词表，网关定期拉取并缓存
+	VocabularyKindEndpoint = "endpoint"
+)
+
+// FieldVocabulary 把一个字段绑定到受控词表 (权威列表)。词表用于界面的下拉选项，
+// Strict 为 true 时，通过 Mutate 写入该字段的值必须是词表中的某个词条
+type FieldVocabulary struct {
+	BizName   string `json:"biz_name"`
+	TableName string `json:"table_name"`
+	FieldName string `json:"field_name"`
+	// Kind 为 list 或 endpoint
+	Kind string `json:"kind"`
+	// Terms 是上传的词条，Kind 为 endpoint 时为空
+	Terms []VocabularyTerm `json:"terms,omitempty"`
+	// EndpointURL 是外部权威列表的地址，GET 请求应返回词条数组 (字符串或 {"value","label"} 对象)，
+	// 或形如 {"data": [...]} 的对象
+	EndpointURL string    `json:"endpoint_url,omitempty"`
+	Strict      bool      `json:"strict"`
+	UpdatedBy   int64     `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VocabularyTerm 是受控词表中的一个词条。Value 是写入字段的规范值，Label 是界面上显示的名称
+type VocabularyTerm struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+}
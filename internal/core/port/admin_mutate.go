@@ -0,0 +1,50 @@
+// Package port file: internal/core/port/admin_mutate.go
+package port
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrAdminMutateUnsupported 表示数据源未实现管理操作扩展 (AdminMutator) 或不支持请求的管理操作
+var ErrAdminMutateUnsupported = errors.New("数据源不支持该管理操作")
+
+// IsPublicMutateOp 报告操作能否经由公开的 Mutate 发起。公开写接口只接受 create、update 与 delete，
+// 这三种操作都受表级写权限约束；其余操作一律拒绝，新增的操作不会因遗漏而绕过权限检查
+func IsPublicMutateOp(op string) bool {
+	switch op {
+	case "create", "update", "delete":
+		return true
+	}
+	return false
+}
+
+// IsAdminMutateOp 报告操作是否为管理操作 (见 MutateOpReplicate 等)
+func IsAdminMutateOp(op string) bool {
+	switch op {
+	case MutateOpReplicate, MutateOpFTSRebuild, MutateOpMaintenance, MutateOpCompact, MutateOpReplace, MutateOpUpstreamCache:
+		return true
+	}
+	return false
+}
+
+// AdminMutator 是数据源可选实现的管理操作扩展：同步变更、全文索引重建、库文件维护、压缩、整库替换与上游缓存管理。
+// 这些操作绕过表级写权限，与公开的 Mutate 分开，只由管理接口与后台服务经 AdminMutate 发起。
+// 装饰器应转发该扩展；gRPC 插件未实现时，客户端适配器返回 ErrAdminMutateUnsupported
+type AdminMutator interface {
+	// AdminMutate 执行一次管理操作，req.Operation 为 MutateOpReplicate 等常量之一
+	AdminMutate(ctx context.Context, req MutateRequest) (*MutateResult, error)
+}
+
+// AdminMutate 对数据源执行一次管理操作。操作不是管理操作或数据源未实现 AdminMutator 时返回 ErrAdminMutateUnsupported
+func AdminMutate(ctx context.Context, dataSource DataSource, req MutateRequest) (*MutateResult, error) {
+	if !IsAdminMutateOp(req.Operation) {
+		return nil, fmt.Errorf("%w: '%s' 不是管理操作", ErrAdminMutateUnsupported, req.Operation)
+	}
+	admin, ok := dataSource.(AdminMutator)
+	if !ok {
+		return nil, ErrAdminMutateUnsupported
+	}
+	return admin.AdminMutate(ctx, req)
+}
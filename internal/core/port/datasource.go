@@ -11,11 +11,139 @@ var (
 	ErrPermissionDenied   = errors.New("权限不足，操作被拒绝")
 	ErrBizNotFound        = errors.New("指定的业务组未找到")
 	ErrTableNotFoundInBiz = errors.New("在当前业务组的配置中未找到指定的表")
+	// ErrInsufficientStorage 表示磁盘可用空间低于配置的下限，写操作被拒绝
+	ErrInsufficientStorage = errors.New("磁盘可用空间不足，已拒绝写入")
+	// ErrInvalidIdentifier 表示表名或字段名不符合网关的标识符策略
+	ErrInvalidIdentifier = errors.New("表名或字段名不符合标识符策略")
+	// ErrChangeCaptureUnsupported 表示业务组启用了变更捕获，但写操作涉及的表无法记录变更 (如 WITHOUT ROWID 表)。
+	// 这样的写操作被拒绝，以免变更未进入日志导致同步副本与源库不一致
+	ErrChangeCaptureUnsupported = errors.New("该表不支持变更捕获")
+)
+
+// 通用 Query/Mutate 协议中由网关保留的键
+const (
+	// QueryModeKey 指定查询模式，缺省为普通的分页查询
+	QueryModeKey = "mode"
+	// QueryModeChanges 表示读取变更捕获 (CDC) 日志，而非业务表数据
+	QueryModeChanges = "changes"
+	// QueryModeRecord 表示按主键读取单条记录，不受分页影响，用于永久链接
+	QueryModeRecord = "record"
+	// QueryModeCompleteness 表示统计表中各已配置字段的缺失值情况，仅供管理接口使用
+	QueryModeCompleteness = "completeness"
+	// QueryModeVocabulary 表示按值分页读取字段的全部不同取值及出现次数，用于建立拼写建议词表，仅供管理接口使用
+	QueryModeVocabulary = "vocabulary"
+	// QueryModeStats 表示统计表中各可返回字段的基数、最小值、最大值与 NULL 数量。
+	// 结果的 "fields" 中每项为 {"field", "nulls", "null_ratio", "cardinality", "cardinality_exact", "min", "max"}，
+	// 多库业务组无法精确合并基数时 cardinality 为各库基数之和且 cardinality_exact 为 false。由网关缓存后经 meta 接口公开
+	QueryModeStats = "stats"
+	// QueryModeChildren 表示在声明了层级关系 (domain.HierarchyConfig) 的表中读取 "node" 的直接子节点，
+	// "node" 缺省时读取根节点；支持 page 与 size 分页，每个子节点附带其直接子节点数 "_child_count"
+	QueryModeChildren = "children"
+	// QueryModeAncestors 表示读取从根节点到 "node" 的路径，结果的 "items" 按从根到该节点的顺序排列，
+	// 节点不存在时 "items" 为空
+	QueryModeAncestors = "ancestors"
+	// QueryModeSubtree 表示统计 "node" 子树中各层的后代数量 (不含节点自身)，"node" 缺省时统计整个森林。
+	// 结果为 {"descendants", "depth", "levels"}，levels 中每项为 {"depth", "count"}，根节点的子节点为第 1 层
+	QueryModeSubtree = "subtree"
+	// QueryNodeKey 是层级浏览中节点的键值，对应层级关系配置中 KeyField 的值
+	QueryNodeKey = "node"
+	// QueryDistinctKey 为 true 时按返回的列对结果去重 (含跨库合并时的去重)。
+	// 多库业务组的 total 为各库去重计数之和，库之间有重复行时会大于实际值
+	QueryDistinctKey = "distinct"
+	// QuerySampleKey 为正整数 N 时，返回匹配记录中随机抽取的至多 N 行而不是分页结果，忽略 page 与 size；
+	// total 仍为匹配的总数。每次请求的结果不同，因此不会被网关缓存
+	QuerySampleKey = "sample"
+	// QueryExplainKey 为 true 时，数据源在结果的 "explain" 中附带实际执行的查询语句、参数与耗时。
+	// 该键只能由网关在确认管理员身份后注入
+	QueryExplainKey = "_explain"
+	// QueryMaxBytesKey 是网关注入的结果字节数上限 (按 JSON 大小估算)。数据源应在逐行组装结果时累计大小，
+	// 超过上限后停止读取并在结果中设置 ResultTruncatedKey；网关对不识别该键的数据源再做一次截断
+	QueryMaxBytesKey = "_max_response_bytes"
+	// ResultTruncatedKey 为 true 表示结果因字节数上限被截断，items 少于请求的页大小
+	ResultTruncatedKey = "truncated"
+	// ResultRecommendedPageSizeKey 由网关在结果被截断时附带，是按本次记录的平均大小估算、在字节数上限内能完整返回的页大小
+	ResultRecommendedPageSizeKey = "recommended_page_size"
+	// QueryStrictKey 为 true 时要求多库查询全有或全无：任一库失败即整个查询失败。
+	// 缺省时跳过失败的库，返回其余库的结果，并在 ResultWarningsKey 中列出失败的库
+	QueryStrictKey = "strict"
+	// ResultWarningsKey 是部分成功的结果中失败的库列表，每项为 {"lib": 库名, "error": 原因}；
+	// 存在该键时 items 与 total 不含这些库的数据
+	ResultWarningsKey = "warnings"
+	// ResultStaleKey 为 true 表示数据源暂时不可用，结果是网关缓存中此前对同一查询的结果；
+	// ResultCachedAtKey 是该结果被缓存的时间 (RFC 3339)
+	ResultStaleKey    = "stale"
+	ResultCachedAtKey = "cached_at"
+	// FilterValuesKey 是过滤条件中可选的候选值列表，由网关按业务组的同义词与归一化规则展开。
+	// 数据源应把与 value 或 values 中任一值的匹配视为命中；不识别该键的数据源只按 value 匹配
+	FilterValuesKey = "values"
+	// QueryRankKey 是网关由全字段检索 ("search") 生成的计分提示，值为 [{"field", "value", "weight"}] 列表：
+	// 记录的得分为其包含 value 的字段的 weight 之和 (或由表的相关度配置另行计算)，全文检索条件同样计入得分。
+	// 匹配条件本身已展开为 filters；不识别该键、或在抽样与去重模式下无法计分的数据源可忽略它
+	QueryRankKey = "_rank"
+	// ResultScoreField 是参与计分的查询中每条记录的相关度得分字段
+	ResultScoreField = "_score"
+	// QuerySortKey 指定结果的排序键，目前只支持 ResultScoreField (按得分从高到低)；
+	// 全字段检索未指定排序时网关默认按得分排序，值为空字符串时保持数据源的自然顺序
+	QuerySortKey = "sort"
+	// QueryCollapseKey 指定折叠字段：匹配记录按该字段的取值分组，每组只返回一条代表记录 (参与计分时为得分最高的一条)，
+	// 组内记录数在 ResultGroupCountField 中给出，total 为分组数。不能与去重或抽样同时使用；
+	// 多库业务组中各库取值相同的分组在合并时归并，total 为各库分组数之和，库之间有相同取值时会大于实际值
+	QueryCollapseKey = "collapse_by"
+	// ResultGroupCountField 是折叠查询中每条代表记录所在分组的记录数
+	ResultGroupCountField = "_group_count"
+	// QueryTimelineKey 为 {"field", "granularity"} 时，不返回记录，而是把匹配的记录按日期字段分桶计数，
+	// granularity 为 year、month 或 day，字段值应为 ISO 8601 格式 (如 "1921"、"1921-03-05")。
+	// 结果为 {"buckets", "total", "undated"}，buckets 中每项为 {"bucket", "count"}，按桶升序排列；
+	// 日期缺失或无法识别的记录计入 undated。忽略 page 与 size，不能与去重、抽样或折叠同时使用。
+	// 可选的 "timezone" (IANA 名称) 由网关按业务组的设置填写：带时区的日期 (如 "1921-03-05T20:00:00Z") 先换算到该时区再分桶
+	QueryTimelineKey = "timeline"
+	// QuerySQLParamsKey 是查询 SQL 视图表 (domain.SQLViewConfig) 时的参数值，为 {"参数名": 值}；
+	// 未传的参数使用视图定义中的默认值
+	QuerySQLParamsKey = "sql_params"
+	// RecordIDSeparator 用于拼接复合主键的各列值，顺序与主键声明顺序一致
+	RecordIDSeparator = ","
+	// RecordKeyRowid 是没有显式主键的表的记录标识，对应 SQLite 的隐式 rowid 列
+	RecordKeyRowid = "rowid"
+	// MutateActorKey 由网关在写操作 payload 中注入，标识发起变更的用户
+	MutateActorKey = "_actor"
+	// MutateOpReplicate 表示应用来自源实例的同步变更，绕过表级写权限，只能经由 AdminMutate 执行
+	MutateOpReplicate = "replicate"
+	// MutateOpFTSRebuild 表示分步重建表的全文索引 (payload 的 "step" 指定步骤)，只能经由 AdminMutate 执行
+	MutateOpFTSRebuild = "fts_rebuild"
+	// MutateOpMaintenance 表示对业务组的库文件执行维护，只能经由 AdminMutate 执行。payload 的 "step" 为 "inspect" 时
+	// 返回各库的存储状况 ("libs")；为 "run" 时按 "tasks" 列出的任务 (见 domain.MaintenanceTask*) 逐库执行，
+	// 返回各库执行前后的状况。不支持该操作的数据源返回错误
+	MutateOpMaintenance = "maintenance"
+	// MutateOpCompact 表示把业务组的多个库文件合并为一个，只能经由 AdminMutate 执行。payload 的 "step" 依次为
+	// "merge" (合并到暂存文件并核对行数)、"swap" (以暂存文件替换源库) 或 "abort" (丢弃暂存文件)，
+	// "libs" 列出待合并的库，"target" 为合并后的库名。不支持该操作的数据源返回错误
+	MutateOpCompact = "compact"
+	// MutateOpReplace 表示以新的库文件整体替换业务组中的一个库，只能经由 AdminMutate 执行。payload 的 "lib" 为库名，
+	// "source_path" 为新库文件的路径；数据源在替换期间暂停该业务组的查询，替换后重新发现表结构。
+	// 不支持该操作的数据源返回错误
+	MutateOpReplace = "replace"
+	// MutateOpUpstreamCache 表示查看或清空数据源对上游接口响应的缓存 (如 REST 数据源)，只能经由 AdminMutate 执行。
+	// payload 的 "step" 为 "stats" 时返回缓存计数 ("cache")；为 "purge" 时清空缓存并返回清除的条数 ("purged")。
+	// 不缓存上游响应的数据源返回错误
+	MutateOpUpstreamCache = "upstream_cache"
+	// FilterFullTextKey 为 true 时，过滤条件的 value 按全文检索语义匹配字段 (需表已配置并建立全文索引)
+	FilterFullTextKey = "fts"
+	// FilterNegateKey 为 true 时对过滤条件取反 (如 "!=" 与 "NOT LIKE")，字段值为空 (NULL) 的记录视为满足取反条件。
+	// 取反只作用于该条件本身，不改变它与前后条件之间 logic 的连接方式
+	FilterNegateKey = "negate"
+	// FilterCaseInsensitiveKey 与 FilterAccentInsensitiveKey 是过滤条件中可选的布尔值，
+	// 分别指定文本匹配是否忽略大小写、是否忽略变音符号 ("é" 与 "e")；未指定时取字段的默认设置
+	FilterCaseInsensitiveKey   = "case_insensitive"
+	FilterAccentInsensitiveKey = "accent_insensitive"
 )
 
 type QueryRequest struct {
 	BizName string
 	Query   map[string]interface{}
+	// Extensions 是插件专有的查询选项 (如搜索引擎的 query string、MongoDB 的 hint)，由插件自行解释，
+	// 不识别的键应忽略。客户端传入的键只有在业务组的白名单中才会放行 (见 queryext.Service)；
+	// 转发查询的装饰器应原样传递
+	Extensions map[string]interface{}
 }
 
 type QueryResult struct {
@@ -48,11 +176,40 @@ type FieldDescription struct {
 	IsReturnable bool   `json:"is_returnable"`
 	IsPrimary    bool   `json:"is_primary"`
 	Description  string `json:"description"`
+	// Source、Transformation 与 VocabularyLink 由网关按业务组的数据字典填写，见 domain.FieldDictionaryEntry
+	Source         string `json:"source,omitempty"`
+	Transformation string `json:"transformation,omitempty"`
+	VocabularyLink string `json:"vocabulary_link,omitempty"`
+	// PinyinSearchable 表示该字段可在过滤条件中设置 "pinyin": true，按拼音全拼或首字母检索
+	PinyinSearchable bool `json:"pinyin_searchable"`
+	// FullTextTokenizer 是该字段当前生效的全文索引所用的分词器，为空表示字段不支持全文检索
+	FullTextTokenizer string `json:"full_text_tokenizer,omitempty"`
 }
 
 // SchemaResult 定义了数据源结构信息的返回
 type SchemaResult struct {
 	Tables map[string][]FieldDescription `json:"tables"`
+	// RecordKeys 是每个表的有效记录标识：显式主键列按声明顺序，无主键时为 [RecordKeyRowid]，
+	// 不能稳定寻址单条记录的表 (如 SQL 视图表) 为空。记录详情、固定链接与折叠查询以它定位记录。
+	// 数据源未报告时按字段的 IsPrimary 推断，见 RecordKey
+	RecordKeys map[string][]string `json:"record_keys,omitempty"`
+}
+
+// RecordKey 返回表的有效记录标识。数据源未在 RecordKeys 中报告该表时，以 IsPrimary 字段按列出顺序代替
+func (r *SchemaResult) RecordKey(tableName string) []string {
+	if r == nil {
+		return nil
+	}
+	if key, ok := r.RecordKeys[tableName]; ok {
+		return key
+	}
+	var key []string
+	for _, f := range r.Tables[tableName] {
+		if f.IsPrimary {
+			key = append(key, f.Name)
+		}
+	}
+	return key
 }
 
 // DataSource 接口定义
@@ -60,7 +217,7 @@ type DataSource interface {
 	// Query 执行一次数据查询 (Read)
 	Query(ctx context.Context, req QueryRequest) (*QueryResult, error)
 
-	// Mutate 执行一次数据变更 (Create, Update, Delete)
+	// Mutate 执行一次数据变更 (Create, Update, Delete)，其他操作返回错误；管理操作见 AdminMutator
 	Mutate(ctx context.Context, req MutateRequest) (*MutateResult, error)
 
 	// GetSchema 获取数据源的结构信息
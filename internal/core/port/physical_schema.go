@@ -0,0 +1,48 @@
+// Package port file: internal/core/port/physical_schema.go
+package port
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"errors"
+)
+
+// ErrPhysicalSchemaUnsupported 表示数据源无法报告业务组的物理表结构
+var ErrPhysicalSchemaUnsupported = errors.New("数据源不支持读取物理表结构")
+
+// ErrInvalidSQLView 表示 SQL 视图表的定义不在允许的安全子集内，或无法在业务组的库中执行
+var ErrInvalidSQLView = errors.New("SQL 视图定义无效")
+
+// PhysicalSchemaReader 是数据源可选实现的扩展，报告业务组中实际存在的表及其列 (不受网关字段配置的限制)，
+// 供配置模板等管理功能按表名与列名匹配。多库业务组返回各库的并集，列名按字母排序
+type PhysicalSchemaReader interface {
+	PhysicalSchema(ctx context.Context, bizName string) (map[string][]string, error)
+}
+
+// SQLViewValidator 是数据源可选实现的扩展，在保存 SQL 视图表的定义之前校验语句是否属于允许的安全子集、
+// 能否在业务组的库中执行，并返回视图的列名，供管理员据此配置字段。定义无效时返回包装了 ErrInvalidSQLView 的错误
+type SQLViewValidator interface {
+	ValidateSQLView(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) ([]string, error)
+}
+
+// DataSourceUnwrapper 由 DataSource 装饰器实现，返回被装饰的 DataSource
+type DataSourceUnwrapper interface {
+	Unwrap() DataSource
+}
+
+// FindCapability 沿装饰器链查找实现了可选扩展 T 的 DataSource，
+// 使装饰器 (如查询缓存) 不会遮蔽底层数据源的扩展能力
+func FindCapability[T any](ds DataSource) (T, bool) {
+	for ds != nil {
+		if capability, ok := ds.(T); ok {
+			return capability, true
+		}
+		unwrapper, ok := ds.(DataSourceUnwrapper)
+		if !ok {
+			break
+		}
+		ds = unwrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,52 @@
+// Package port file: internal/core/port/policy.go
+package port
+
+import "context"
+
+// 授权钩子评估的请求平面
+const (
+	PolicyPlaneData  = "data"
+	PolicyPlaneAdmin = "admin"
+)
+
+// PolicyUser 是发起请求的用户，匿名访问者的 ID 为 0、Role 为空
+type PolicyUser struct {
+	ID   int64  `json:"id"`
+	Role string `json:"role"`
+}
+
+// PolicyInput 是交给授权钩子的请求上下文。Operation 在数据平面是接口名 (query、timeline、record 等)，
+// mutate 请求为其 operation (create/update/delete 等)，在管理平面是 HTTP 方法。
+// Filters 是请求中的过滤条件：查询请求体中的 query、mutate 的 payload.filters，或 GET 请求的 URL 参数，没有时为 nil
+type PolicyInput struct {
+	Plane     string      `json:"plane"`
+	User      PolicyUser  `json:"user"`
+	IP        string      `json:"ip"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Route     string      `json:"route"`
+	BizName   string      `json:"biz_name,omitempty"`
+	Table     string      `json:"table,omitempty"`
+	Operation string      `json:"operation"`
+	Filters   interface{} `json:"filters,omitempty"`
+}
+
+// PolicyDecision 是授权钩子的结论，Reason 会返回给被拒绝的调用方
+type PolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AuthorizationHook 是外部策略引擎的扩展点。机构可以在进程内实现该接口并注册到授权钩子服务，
+// 也可以通过配置的 HTTP 回调 (如 OPA) 评估。返回错误表示无法得出结论，按配置放行或拒绝
+type AuthorizationHook interface {
+	Authorize(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+}
+
+// AuthorizationHookFunc 把函数适配为 AuthorizationHook
+type AuthorizationHookFunc func(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+
+// Authorize 调用函数本身
+func (f AuthorizationHookFunc) Authorize(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	return f(ctx, input)
+}
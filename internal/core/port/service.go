@@ -14,6 +14,11 @@ type QueryAdminConfigService interface {
 	UpdateBizSearchableTables(ctx context.Context, bizName string, tableNames []string) error
 	UpdateTableWritePermissions(ctx context.Context, bizName, tableName string, perms domain.TableConfig) error
 	UpdateTableFieldSettings(ctx context.Context, bizName, tableName string, fields []domain.FieldSetting) error
+	UpdateFieldSettingsBulk(ctx context.Context, bizName string, tables map[string][]domain.FieldSetting) error
+	UpdateTableFTSConfig(ctx context.Context, bizName, tableName string, cfg *domain.FTSConfig) error
+	UpdateTableRankingConfig(ctx context.Context, bizName, tableName string, cfg *domain.RankingConfig) error
+	UpdateTableHierarchyConfig(ctx context.Context, bizName, tableName string, cfg *domain.HierarchyConfig) error
+	UpdateTableSQLViewConfig(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) error
 	GetDefaultViewConfig(ctx context.Context, bizName, tableName string) (*domain.ViewConfig, error)
 	GetAllViewConfigsForBiz(ctx context.Context, bizName string) (map[string][]*domain.ViewConfig, error)
 	UpdateAllViewsForBiz(ctx context.Context, bizName string, viewsData map[string][]*domain.ViewConfig) error
@@ -26,3 +31,14 @@ type QueryAdminConfigService interface {
 	InvalidateCacheForBiz(bizName string)
 	InvalidateAllCaches()
 }
+
+// ConfigCacheInspector 是配置服务可选实现的缓存查看能力，管理端通过类型断言判断是否支持
+type ConfigCacheInspector interface {
+	CacheState() domain.ConfigCacheState
+}
+
+// IdentifierChecker 在保存配置前检查表名或字段名，返回包装了 ErrInvalidIdentifier 的错误时拒绝保存；
+// kind 取值见 domain.IdentifierKindTable 等
+type IdentifierChecker interface {
+	Check(kind, name string) error
+}
@@ -0,0 +1,104 @@
+// Package port file: internal/core/port/vector.go
+package port
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrVectorSearchUnsupported 表示数据源未实现语义检索扩展 (VectorSearcher)
+var ErrVectorSearchUnsupported = errors.New("数据源不支持语义检索")
+
+// EmbeddingSourceRequest 按游标分页读取表中待向量化的文本
+type EmbeddingSourceRequest struct {
+	BizName   string
+	TableName string
+	// Fields 中各字段的非空值按顺序以换行拼接为一条文本
+	Fields []string
+	// Lib 与 AfterRowID 是上一页返回的游标，首页均为零值
+	Lib        string
+	AfterRowID int64
+	Limit      int
+}
+
+// EmbeddingSource 是一条记录待向量化的文本。Lib 与 RecordID 一起唯一标识一条记录，
+// RecordID 与 QueryModeRecord 使用的标识一致
+type EmbeddingSource struct {
+	Lib      string
+	RecordID string
+	Text     string
+}
+
+// EmbeddingSourcePage 是一页待向量化的文本及下一页的游标
+type EmbeddingSourcePage struct {
+	Sources   []EmbeddingSource
+	NextLib   string
+	NextRowID int64
+	Done      bool
+}
+
+// Embedding 是一条记录的向量
+type Embedding struct {
+	Lib      string
+	RecordID string
+	Vector   []float32
+}
+
+// UpsertEmbeddingsRequest 写入或删除表的向量
+type UpsertEmbeddingsRequest struct {
+	BizName   string
+	TableName string
+	// Model 标识生成向量的模型，检索时只比较同一模型的向量
+	Model string
+	// Generation 标记本批向量所属的重建轮次
+	Generation int64
+	Embeddings []Embedding
+	// PruneBefore 大于 0 时删除 Generation 小于该值的向量，用于重建结束时清理已删除记录的向量
+	PruneBefore int64
+	// DropAll 为 true 时删除表的全部向量，忽略其它字段
+	DropAll bool
+}
+
+// UpsertEmbeddingsResult 是写入向量的结果
+type UpsertEmbeddingsResult struct {
+	Upserted int64
+	Deleted  int64
+}
+
+// SimilaritySearchRequest 按余弦相似度检索与 Vector 最接近的记录
+type SimilaritySearchRequest struct {
+	BizName   string
+	TableName string
+	Model     string
+	Vector    []float32
+	TopK      int
+	// MinScore 是余弦相似度的下限，范围 [-1, 1]
+	MinScore float64
+}
+
+// SimilarityMatch 是一条命中的记录及其相似度
+type SimilarityMatch struct {
+	Lib      string  `json:"lib"`
+	RecordID string  `json:"record_id"`
+	Score    float64 `json:"score"`
+}
+
+// SimilaritySearchResult 按相似度降序返回命中的记录
+type SimilaritySearchResult struct {
+	Matches []SimilarityMatch
+	Source  string
+}
+
+// VectorSearcher 是数据源可选实现的语义检索扩展。向量由网关按业务组的配置计算，
+// 数据源只负责提供待向量化的文本、保存向量与相似度检索。
+// 网关通过类型断言判断数据源是否支持；gRPC 插件未实现时，客户端适配器返回 ErrVectorSearchUnsupported
+type VectorSearcher interface {
+	// ScanEmbeddingSources 按游标分页读取表中待向量化的文本
+	ScanEmbeddingSources(ctx context.Context, req EmbeddingSourceRequest) (*EmbeddingSourcePage, error)
+
+	// UpsertEmbeddings 写入或删除记录的向量
+	UpsertEmbeddings(ctx context.Context, req UpsertEmbeddingsRequest) (*UpsertEmbeddingsResult, error)
+
+	// SimilaritySearch 返回与查询向量最相似的记录
+	SimilaritySearch(ctx context.Context, req SimilaritySearchRequest) (*SimilaritySearchResult, error)
+}
@@ -0,0 +1,496 @@
+// Package abuse file: internal/service/abuse/abuse_service.go
+package abuse
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWindowMinutes      = 10
+	defaultScrapePageSize     = 100
+	defaultScrapeMaxPages     = 50
+	defaultForbiddenThreshold = 20
+	defaultBaseBanMinutes     = 30
+	defaultMaxBanMinutes      = 24 * 60
+	defaultOffenseMemoryDays  = 7
+	defaultFlagListLimit      = 100
+	maxFlagListLimit          = 1000
+)
+
+var (
+	// ErrInvalidEntry 表示豁免对象的类型或取值无效
+	ErrInvalidEntry = errors.New("无效的豁免对象")
+	// ErrNotFound 表示豁免对象不存在
+	ErrNotFound = errors.New("豁免对象不存在")
+)
+
+// Options 定义异常行为检测的阈值与通知方式
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowMinutes 是各项计数的时间窗口 (分钟)，默认为 10
+	WindowMinutes int `mapstructure:"window_minutes"`
+	// ScrapePageSize 是视为"最大分页"的每页条数，size 不小于该值的成功查询计入逐页抓取，默认为 100
+	ScrapePageSize int `mapstructure:"scrape_page_size"`
+	// ScrapeMaxPages 是时间窗口内以最大分页读取多少个不同页码后判定为抓取，默认为 50
+	ScrapeMaxPages int `mapstructure:"scrape_max_pages"`
+	// ForbiddenThreshold 是时间窗口内触发多少次 403 后判定为异常，默认为 20
+	ForbiddenThreshold int `mapstructure:"forbidden_threshold"`
+	// BaseBanMinutes 是首次判定的封禁时长 (分钟)，默认为 30；再犯时逐次翻倍
+	BaseBanMinutes int `mapstructure:"base_ban_minutes"`
+	// MaxBanMinutes 是封禁时长的上限 (分钟)，默认为 1440
+	MaxBanMinutes int `mapstructure:"max_ban_minutes"`
+	// OffenseMemoryDays 是计算再犯次数时回看的天数，默认为 7
+	OffenseMemoryDays int `mapstructure:"offense_memory_days"`
+	// AllowIPs 是配置文件中的豁免 IP 或 CIDR，与管理员在后台添加的豁免对象一起生效
+	AllowIPs []string `mapstructure:"allow_ips"`
+	// WebhookURL 不为空时把每次判定以 JSON POST 到该地址
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// Banner 执行临时封禁，由 penalties.Service 实现
+type Banner interface {
+	Ban(ctx context.Context, kind, subject string, duration time.Duration) (time.Time, error)
+}
+
+// Observation 是一次已完成请求中与异常检测有关的信息。UserID 为 0 表示匿名访问者；
+// Page 与 Size 仅对数据查询有意义
+type Observation struct {
+	IP     string
+	UserID int64
+	Status int
+	Page   int
+	Size   int
+}
+
+type subjectKey struct {
+	kind    string
+	subject string
+}
+
+// tracker 是一个对象在当前时间窗口内的计数
+type tracker struct {
+	windowStart time.Time
+	forbidden   int
+	pages       map[int]struct{}
+}
+
+// Service 根据请求结果识别异常行为：以最大分页逐页抓取整张表、反复触发 403。
+// 判定后通过 Banner 临时封禁该对象 (已登录用户按用户、匿名访问者按 IP)，再犯时封禁时长翻倍，
+// 判定记录写入数据库并通过 webhook 通知管理员。豁免名单中的 IP 与用户不参与检测
+type Service struct {
+	db     *sql.DB
+	banner Banner
+	opts   Options
+	now    func() time.Time
+	client *http.Client
+
+	mu       sync.Mutex
+	trackers map[subjectKey]*tracker
+
+	allowMu    sync.RWMutex
+	staticNets []*net.IPNet
+	allowExact map[subjectKey]bool
+	allowNets  []*net.IPNet
+}
+
+// NewService 创建一个新的异常行为检测服务实例，并从数据库加载豁免名单
+func NewService(db *sql.DB, banner Banner, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("abuse.Service 需要一个有效的数据库连接")
+	}
+	if banner == nil {
+		return nil, errors.New("abuse.Service 需要一个封禁执行者")
+	}
+	if opts.WindowMinutes < 0 || opts.ScrapePageSize < 0 || opts.ScrapeMaxPages < 0 || opts.ForbiddenThreshold < 0 ||
+		opts.BaseBanMinutes < 0 || opts.MaxBanMinutes < 0 || opts.OffenseMemoryDays < 0 {
+		return nil, errors.New("异常检测的阈值不能为负数")
+	}
+	if opts.WindowMinutes == 0 {
+		opts.WindowMinutes = defaultWindowMinutes
+	}
+	if opts.ScrapePageSize == 0 {
+		opts.ScrapePageSize = defaultScrapePageSize
+	}
+	if opts.ScrapeMaxPages == 0 {
+		opts.ScrapeMaxPages = defaultScrapeMaxPages
+	}
+	if opts.ForbiddenThreshold == 0 {
+		opts.ForbiddenThreshold = defaultForbiddenThreshold
+	}
+	if opts.BaseBanMinutes == 0 {
+		opts.BaseBanMinutes = defaultBaseBanMinutes
+	}
+	if opts.MaxBanMinutes == 0 {
+		opts.MaxBanMinutes = defaultMaxBanMinutes
+	}
+	if opts.MaxBanMinutes < opts.BaseBanMinutes {
+		return nil, fmt.Errorf("max_ban_minutes (%d) 不能小于 base_ban_minutes (%d)", opts.MaxBanMinutes, opts.BaseBanMinutes)
+	}
+	if opts.OffenseMemoryDays == 0 {
+		opts.OffenseMemoryDays = defaultOffenseMemoryDays
+	}
+	s := &Service{
+		db:         db,
+		banner:     banner,
+		opts:       opts,
+		now:        time.Now,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		trackers:   make(map[subjectKey]*tracker),
+		allowExact: make(map[subjectKey]bool),
+	}
+	for _, raw := range opts.AllowIPs {
+		ipNet, err := parseIPOrCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("allow_ips 中的 '%s' 无效: %w", raw, err)
+		}
+		s.staticNets = append(s.staticNets, ipNet)
+	}
+	if err := s.reloadAllowlist(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Observe 记录一次已完成的请求，达到阈值时封禁对应对象并返回判定记录，否则返回 nil
+func (s *Service) Observe(ctx context.Context, o Observation) (*domain.AbuseFlag, error) {
+	forbidden := o.Status == http.StatusForbidden
+	paging := o.Status >= 200 && o.Status < 300 && o.Size >= s.opts.ScrapePageSize
+	if !forbidden && !paging {
+		return nil, nil
+	}
+	if s.allowed(o.IP, o.UserID) {
+		return nil, nil
+	}
+	key := subjectKey{domain.PenaltyKindIP, o.IP}
+	if o.UserID > 0 {
+		key = subjectKey{domain.PenaltyKindUser, strconv.FormatInt(o.UserID, 10)}
+	}
+	if key.subject == "" {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	t := s.trackers[key]
+	if t == nil || now.Sub(t.windowStart) >= s.window() {
+		t = &tracker{windowStart: now, pages: make(map[int]struct{})}
+		s.trackers[key] = t
+	}
+	var reason, detail string
+	if forbidden {
+		t.forbidden++
+		if t.forbidden >= s.opts.ForbiddenThreshold {
+			reason = domain.AbuseReasonForbidden
+			detail = fmt.Sprintf("%d 分钟内 %d 次 403", s.opts.WindowMinutes, t.forbidden)
+		}
+	} else {
+		t.pages[o.Page] = struct{}{}
+		if len(t.pages) >= s.opts.ScrapeMaxPages {
+			reason = domain.AbuseReasonScraping
+			detail = fmt.Sprintf("%d 分钟内以每页 %d 条读取了 %d 个不同页码", s.opts.WindowMinutes, o.Size, len(t.pages))
+		}
+	}
+	if reason != "" {
+		delete(s.trackers, key)
+	}
+	s.mu.Unlock()
+
+	if reason == "" {
+		return nil, nil
+	}
+	return s.flag(ctx, key, reason, detail)
+}
+
+// flag 封禁对象并写入判定记录，封禁时长按近期的判定次数翻倍
+func (s *Service) flag(ctx context.Context, key subjectKey, reason, detail string) (*domain.AbuseFlag, error) {
+	now := s.now().UTC()
+	since := now.AddDate(0, 0, -s.opts.OffenseMemoryDays)
+	var previous int
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM abuse_flags WHERE kind = ? AND subject = ? AND created_at >= ?`,
+		key.kind, key.subject, since).Scan(&previous); err != nil {
+		return nil, fmt.Errorf("读取异常判定记录失败: %w", err)
+	}
+	offense := previous + 1
+	minutes := s.banMinutes(offense)
+	until, err := s.banner.Ban(ctx, key.kind, key.subject, time.Duration(minutes)*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("封禁 %s '%s' 失败: %w", key.kind, key.subject, err)
+	}
+
+	flag := &domain.AbuseFlag{
+		Kind:        key.kind,
+		Subject:     key.subject,
+		Reason:      reason,
+		Detail:      detail,
+		Offense:     offense,
+		BanMinutes:  minutes,
+		BannedUntil: until.UTC(),
+		CreatedAt:   now,
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO abuse_flags (kind, subject, reason, detail, offense, ban_minutes, banned_until, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		flag.Kind, flag.Subject, flag.Reason, flag.Detail, flag.Offense, flag.BanMinutes, flag.BannedUntil, flag.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("保存异常判定记录失败: %w", err)
+	}
+	flag.ID, _ = res.LastInsertId()
+	slog.Warn("[Abuse] 检测到异常行为，已临时封禁",
+		"kind", flag.Kind, "subject", flag.Subject, "reason", flag.Reason, "offense", flag.Offense, "ban_minutes", flag.BanMinutes)
+
+	if s.opts.WebhookURL != "" {
+		notified := *flag
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+			defer cancel()
+			if err := s.postWebhook(ctx, &notified); err != nil {
+				slog.Warn("[Abuse] 发送异常判定通知失败", "error", err)
+			}
+		}()
+	}
+	return flag, nil
+}
+
+// banMinutes 返回第 offense 次判定的封禁时长：BaseBanMinutes 逐次翻倍，不超过 MaxBanMinutes
+func (s *Service) banMinutes(offense int) int {
+	minutes := s.opts.BaseBanMinutes
+	for i := 1; i < offense && minutes < s.opts.MaxBanMinutes; i++ {
+		minutes *= 2
+	}
+	return min(minutes, s.opts.MaxBanMinutes)
+}
+
+func (s *Service) window() time.Duration {
+	return time.Duration(s.opts.WindowMinutes) * time.Minute
+}
+
+func (s *Service) postWebhook(ctx context.Context, flag *domain.AbuseFlag) error {
+	body, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Run 定期清理已过期的计数窗口，ctx 结束时返回
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune()
+		}
+	}
+}
+
+func (s *Service) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, t := range s.trackers {
+		if now.Sub(t.windowStart) >= s.window() {
+			delete(s.trackers, key)
+		}
+	}
+}
+
+// ListFlags 按时间倒序列出最近的异常判定记录，limit 为 0 时返回 100 条
+func (s *Service) ListFlags(ctx context.Context, limit int) ([]domain.AbuseFlag, error) {
+	if limit <= 0 {
+		limit = defaultFlagListLimit
+	}
+	limit = min(limit, maxFlagListLimit)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, kind, subject, reason, detail, offense, ban_minutes, banned_until, created_at
+		 FROM abuse_flags ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("读取异常判定记录失败: %w", err)
+	}
+	defer rows.Close()
+	flags := make([]domain.AbuseFlag, 0)
+	for rows.Next() {
+		var f domain.AbuseFlag
+		if err := rows.Scan(&f.ID, &f.Kind, &f.Subject, &f.Reason, &f.Detail, &f.Offense, &f.BanMinutes, &f.BannedUntil, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取异常判定记录失败: %w", err)
+		}
+		flags = append(flags, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取异常判定记录失败: %w", err)
+	}
+	return flags, nil
+}
+
+// ListAllowlist 列出管理员添加的豁免对象，配置文件中的 allow_ips 不在其中
+func (s *Service) ListAllowlist(ctx context.Context) ([]domain.AbuseAllowEntry, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT kind, subject, note, COALESCE(created_by, 0), created_at FROM abuse_allowlist ORDER BY kind, subject`)
+	if err != nil {
+		return nil, fmt.Errorf("读取豁免名单失败: %w", err)
+	}
+	defer rows.Close()
+	entries := make([]domain.AbuseAllowEntry, 0)
+	for rows.Next() {
+		var e domain.AbuseAllowEntry
+		if err := rows.Scan(&e.Kind, &e.Subject, &e.Note, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取豁免名单失败: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取豁免名单失败: %w", err)
+	}
+	return entries, nil
+}
+
+// Allow 添加或更新一个豁免对象。kind 为 "ip" 时 subject 为 IP 或 CIDR，为 "user" 时为用户 ID。
+// 豁免只影响之后的检测，已生效的封禁需要另外解除
+func (s *Service) Allow(ctx context.Context, kind, subject, note string, createdBy int64) (*domain.AbuseAllowEntry, error) {
+	subject = strings.TrimSpace(subject)
+	switch kind {
+	case domain.PenaltyKindIP:
+		ipNet, err := parseIPOrCIDR(subject)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEntry, err)
+		}
+		if strings.Contains(subject, "/") {
+			subject = ipNet.String()
+		} else {
+			subject = ipNet.IP.String()
+		}
+	case domain.PenaltyKindUser:
+		if id, err := strconv.ParseInt(subject, 10, 64); err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: 用户 ID 必须是正整数: '%s'", ErrInvalidEntry, subject)
+		}
+	default:
+		return nil, fmt.Errorf("%w: 类型必须是 'ip' 或 'user': '%s'", ErrInvalidEntry, kind)
+	}
+
+	entry := &domain.AbuseAllowEntry{Kind: kind, Subject: subject, Note: note, CreatedBy: createdBy, CreatedAt: s.now().UTC()}
+	var creator any
+	if createdBy > 0 {
+		creator = createdBy
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO abuse_allowlist (kind, subject, note, created_by, created_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(kind, subject) DO UPDATE SET note = excluded.note, created_by = excluded.created_by, created_at = excluded.created_at`,
+		entry.Kind, entry.Subject, entry.Note, creator, entry.CreatedAt); err != nil {
+		return nil, fmt.Errorf("保存豁免对象失败: %w", err)
+	}
+	if err := s.reloadAllowlist(ctx); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Disallow 移除一个豁免对象
+func (s *Service) Disallow(ctx context.Context, kind, subject string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM abuse_allowlist WHERE kind = ? AND subject = ?`, kind, subject)
+	if err != nil {
+		return fmt.Errorf("删除豁免对象失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s '%s'", ErrNotFound, kind, subject)
+	}
+	return s.reloadAllowlist(ctx)
+}
+
+// reloadAllowlist 从数据库重新加载豁免名单到内存
+func (s *Service) reloadAllowlist(ctx context.Context) error {
+	entries, err := s.ListAllowlist(ctx)
+	if err != nil {
+		return err
+	}
+	exact := make(map[subjectKey]bool, len(entries))
+	var nets []*net.IPNet
+	for _, e := range entries {
+		if e.Kind == domain.PenaltyKindIP && strings.Contains(e.Subject, "/") {
+			if _, ipNet, err := net.ParseCIDR(e.Subject); err == nil {
+				nets = append(nets, ipNet)
+			}
+			continue
+		}
+		exact[subjectKey{e.Kind, e.Subject}] = true
+	}
+	s.allowMu.Lock()
+	s.allowExact = exact
+	s.allowNets = nets
+	s.allowMu.Unlock()
+	return nil
+}
+
+// allowed 判断请求方是否在豁免名单中，IP 与用户任一命中即豁免
+func (s *Service) allowed(ip string, userID int64) bool {
+	s.allowMu.RLock()
+	defer s.allowMu.RUnlock()
+	if userID > 0 && s.allowExact[subjectKey{domain.PenaltyKindUser, strconv.FormatInt(userID, 10)}] {
+		return true
+	}
+	if s.allowExact[subjectKey{domain.PenaltyKindIP, ip}] {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, nets := range [][]*net.IPNet{s.staticNets, s.allowNets} {
+		for _, ipNet := range nets {
+			if ipNet.Contains(parsed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseIPOrCIDR 解析单个 IP 或 CIDR，单个 IP 视为只含自身的网段
+func parseIPOrCIDR(raw string) (*net.IPNet, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.Contains(raw, "/") {
+		_, ipNet, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 CIDR: '%s'", raw)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return nil, fmt.Errorf("无效的 IP: '%s'", raw)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,189 @@
+// file: internal/service/abuse/abuse_service_test.go
+package abuse
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/penalties"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, db *sql.DB, opts Options) (*Service, *penalties.Service) {
+	t.Helper()
+	box, err := penalties.NewService(db, penalties.Options{})
+	require.NoError(t, err)
+	svc, err := NewService(db, box, opts)
+	require.NoError(t, err)
+	return svc, box
+}
+
+func TestNewService_Validation(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+	box, err := penalties.NewService(db, penalties.Options{})
+	require.NoError(t, err)
+	_, err = NewService(nil, box, Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, nil, Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, box, Options{ForbiddenThreshold: -1})
+	assert.Error(t, err)
+	_, err = NewService(db, box, Options{BaseBanMinutes: 60, MaxBanMinutes: 30})
+	assert.Error(t, err)
+	_, err = NewService(db, box, Options{AllowIPs: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
+
+func TestObserve_RepeatedForbiddenEscalates(t *testing.T) {
+	ctx := context.Background()
+	svc, box := newTestService(t, fixture.NewSystemDB(t), Options{ForbiddenThreshold: 3, BaseBanMinutes: 10, MaxBanMinutes: 25})
+
+	observe := func() *domain.AbuseFlag {
+		var flag *domain.AbuseFlag
+		for i := 0; i < 3; i++ {
+			f, err := svc.Observe(ctx, Observation{IP: "10.0.0.1", Status: http.StatusForbidden})
+			require.NoError(t, err)
+			if i < 2 {
+				require.Nil(t, f, "未达到阈值前不判定")
+			}
+			flag = f
+		}
+		return flag
+	}
+
+	flag := observe()
+	require.NotNil(t, flag)
+	assert.Equal(t, domain.PenaltyKindIP, flag.Kind)
+	assert.Equal(t, domain.AbuseReasonForbidden, flag.Reason)
+	assert.Equal(t, 1, flag.Offense)
+	assert.Equal(t, 10, flag.BanMinutes)
+	_, banned := box.Banned(domain.PenaltyKindIP, "10.0.0.1")
+	assert.True(t, banned)
+
+	assert.Equal(t, 20, observe().BanMinutes, "再犯时封禁时长翻倍")
+	third := observe()
+	assert.Equal(t, 3, third.Offense)
+	assert.Equal(t, 25, third.BanMinutes, "不超过上限")
+
+	flags, err := svc.ListFlags(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 3)
+	assert.Equal(t, third.ID, flags[0].ID)
+}
+
+func TestObserve_ScrapingByUser(t *testing.T) {
+	ctx := context.Background()
+	svc, box := newTestService(t, fixture.NewSystemDB(t), Options{ScrapePageSize: 100, ScrapeMaxPages: 3})
+
+	for _, o := range []Observation{
+		{IP: "10.0.0.2", UserID: 7, Status: http.StatusOK, Page: 1, Size: 20}, // 小分页不计入
+		{IP: "10.0.0.2", UserID: 7, Status: http.StatusOK, Page: 1, Size: 100},
+		{IP: "10.0.0.2", UserID: 7, Status: http.StatusOK, Page: 1, Size: 100}, // 重复页码不计入
+		{IP: "10.0.0.2", UserID: 7, Status: http.StatusOK, Page: 2, Size: 100},
+	} {
+		f, err := svc.Observe(ctx, o)
+		require.NoError(t, err)
+		require.Nil(t, f)
+	}
+	flag, err := svc.Observe(ctx, Observation{IP: "10.0.0.2", UserID: 7, Status: http.StatusOK, Page: 3, Size: 100})
+	require.NoError(t, err)
+	require.NotNil(t, flag)
+	assert.Equal(t, domain.PenaltyKindUser, flag.Kind, "已登录用户按用户封禁")
+	assert.Equal(t, "7", flag.Subject)
+	assert.Equal(t, domain.AbuseReasonScraping, flag.Reason)
+	_, banned := box.Banned(domain.PenaltyKindUser, "7")
+	assert.True(t, banned)
+}
+
+func TestObserve_WindowExpires(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, fixture.NewSystemDB(t), Options{ForbiddenThreshold: 2, WindowMinutes: 5})
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	f, err := svc.Observe(ctx, Observation{IP: "10.0.0.3", Status: http.StatusForbidden})
+	require.NoError(t, err)
+	require.Nil(t, f)
+	now = now.Add(6 * time.Minute)
+	f, err = svc.Observe(ctx, Observation{IP: "10.0.0.3", Status: http.StatusForbidden})
+	require.NoError(t, err)
+	assert.Nil(t, f, "窗口过期后重新计数")
+
+	svc.prune()
+	now = now.Add(6 * time.Minute)
+	svc.prune()
+	assert.Empty(t, svc.trackers)
+}
+
+func TestAllowlist(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'root', 'x', 'admin')`)
+	require.NoError(t, err)
+	svc, _ := newTestService(t, db, Options{ForbiddenThreshold: 1, AllowIPs: []string{"192.168.0.0/16"}})
+
+	f, err := svc.Observe(ctx, Observation{IP: "192.168.1.5", Status: http.StatusForbidden})
+	require.NoError(t, err)
+	assert.Nil(t, f, "配置文件中的网段豁免")
+
+	_, err = svc.Allow(ctx, "ip", "bogus", "", 1)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+	_, err = svc.Allow(ctx, "user", "0", "", 1)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+	_, err = svc.Allow(ctx, "host", "x", "", 1)
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+
+	_, err = svc.Allow(ctx, "ip", "10.1.0.0/24", "办公网", 1)
+	require.NoError(t, err)
+	_, err = svc.Allow(ctx, "user", "9", "数据合作方", 1)
+	require.NoError(t, err)
+	entries, err := svc.ListAllowlist(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	f, err = svc.Observe(ctx, Observation{IP: "10.1.0.7", Status: http.StatusForbidden})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	f, err = svc.Observe(ctx, Observation{IP: "10.9.9.9", UserID: 9, Status: http.StatusForbidden})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	// 豁免名单在重启后仍然有效
+	restarted, _ := newTestService(t, db, Options{ForbiddenThreshold: 1})
+	assert.True(t, restarted.allowed("10.1.0.9", 0))
+
+	require.NoError(t, svc.Disallow(ctx, "user", "9"))
+	assert.ErrorIs(t, svc.Disallow(ctx, "user", "9"), ErrNotFound)
+	f, err = svc.Observe(ctx, Observation{IP: "10.9.9.9", UserID: 9, Status: http.StatusForbidden})
+	require.NoError(t, err)
+	assert.NotNil(t, f)
+}
+
+func TestObserve_Webhook(t *testing.T) {
+	received := make(chan domain.AbuseFlag, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var flag domain.AbuseFlag
+		_ = json.NewDecoder(r.Body).Decode(&flag)
+		received <- flag
+	}))
+	defer srv.Close()
+
+	svc, _ := newTestService(t, fixture.NewSystemDB(t), Options{ForbiddenThreshold: 1, WebhookURL: srv.URL})
+	_, err := svc.Observe(context.Background(), Observation{IP: "10.0.0.4", Status: http.StatusForbidden})
+	require.NoError(t, err)
+	select {
+	case flag := <-received:
+		assert.Equal(t, "10.0.0.4", flag.Subject)
+		assert.Equal(t, domain.AbuseReasonForbidden, flag.Reason)
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到 webhook 通知")
+	}
+}
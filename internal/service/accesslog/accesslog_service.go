@@ -0,0 +1,289 @@
+// Package accesslog file: internal/service/accesslog/accesslog_service.go
+package accesslog
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetentionDays = 365
+	defaultListLimit     = 50
+	purgeInterval        = 24 * time.Hour
+)
+
+// Options 定义记录访问日志的配置
+type Options struct {
+	// RetentionDays 是访问日志的保留天数，超过的日志每天清理一次，默认为 365
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
+// Viewer 是查看记录的访问者，匿名访问者的 UserID 为 nil
+type Viewer struct {
+	UserID *int64
+	IP     string
+}
+
+// Filter 是查询访问日志的条件，空值表示不限制
+type Filter struct {
+	BizName   string
+	TableName string
+	RecordID  string
+	UserID    int64
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+	Offset    int
+}
+
+// Service 为标记为敏感的业务组记录谁查看了哪些记录。检索结果中的每条记录与每次永久链接读取各写入一行，
+// 写入失败时调用方应拒绝返回数据：受限档案的法律要求是"未留痕则不可见"
+type Service struct {
+	db            *sql.DB
+	registry      map[string]port.DataSource
+	configService port.QueryAdminConfigService
+	opts          Options
+	now           func() time.Time
+}
+
+// NewService 创建一个新的记录访问日志服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, configService port.QueryAdminConfigService, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("accesslog.Service 需要一个有效的数据库连接")
+	}
+	if registry == nil {
+		return nil, errors.New("accesslog.Service 需要数据源注册表")
+	}
+	if configService == nil {
+		return nil, errors.New("accesslog.Service 需要有效的配置服务")
+	}
+	if opts.RetentionDays < 0 {
+		return nil, errors.New("retention_days 不能为负数")
+	}
+	if opts.RetentionDays == 0 {
+		opts.RetentionDays = defaultRetentionDays
+	}
+	return &Service{db: db, registry: registry, configService: configService, opts: opts, now: time.Now}, nil
+}
+
+// sensitive 判断业务组是否标记为敏感。读取配置失败时返回错误，由调用方拒绝请求
+func (s *Service) sensitive(ctx context.Context, bizName string) (bool, error) {
+	cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return false, fmt.Errorf("读取业务组 '%s' 的敏感标记失败: %w", bizName, err)
+	}
+	return cfg != nil && cfg.Sensitive, nil
+}
+
+// RecordResult 在业务组标记为敏感时，记录检索结果中 items 的每条记录，或按主键读取时的 record
+func (s *Service) RecordResult(ctx context.Context, bizName, tableName string, data map[string]interface{}, viewer Viewer) error {
+	var rows []map[string]interface{}
+	if items, ok := data["items"].([]interface{}); ok {
+		for _, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, row)
+			}
+		}
+	} else if record, ok := data["record"].(map[string]interface{}); ok {
+		rows = append(rows, record)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if on, err := s.sensitive(ctx, bizName); err != nil || !on {
+		return err
+	}
+	primaryKey, err := s.primaryKey(ctx, bizName, tableName)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		id := recordID(row, primaryKey)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return s.write(ctx, bizName, tableName, ids, viewer, domain.RecordAccessQuery)
+}
+
+// RecordPermalink 在业务组标记为敏感时，记录一次按永久链接读取的记录
+func (s *Service) RecordPermalink(ctx context.Context, bizName, tableName, recordID string, viewer Viewer) error {
+	if on, err := s.sensitive(ctx, bizName); err != nil || !on {
+		return err
+	}
+	return s.write(ctx, bizName, tableName, []string{recordID}, viewer, domain.RecordAccessPermalink)
+}
+
+// RecordManifest 在业务组标记为敏感时，记录一次为记录生成的 IIIF 清单
+func (s *Service) RecordManifest(ctx context.Context, bizName, tableName, recordID string, viewer Viewer) error {
+	if on, err := s.sensitive(ctx, bizName); err != nil || !on {
+		return err
+	}
+	return s.write(ctx, bizName, tableName, []string{recordID}, viewer, domain.RecordAccessIIIF)
+}
+
+func (s *Service) write(ctx context.Context, bizName, tableName string, ids []string, viewer Viewer, source string) error {
+	username := ""
+	if viewer.UserID != nil {
+		username, _, _ = service.GetUserById(s.db, *viewer.UserID)
+	}
+	now := s.now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("写入记录访问日志失败: %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO record_access_log (biz_name, table_name, record_id, user_id, username, ip, source, accessed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("写入记录访问日志失败: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, bizName, tableName, id, viewer.UserID, username, viewer.IP, source, now); err != nil {
+			return fmt.Errorf("写入记录访问日志失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("写入记录访问日志失败: %w", err)
+	}
+	return nil
+}
+
+// primaryKey 返回表的主键列；表没有显式主键时返回 nil，结果中的记录无法逐条标识
+func (s *Service) primaryKey(ctx context.Context, bizName, tableName string) ([]string, error) {
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	schema, err := dataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName, TableName: tableName})
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 的主键失败: %w", tableName, err)
+	}
+	primaryKey := schema.RecordKey(tableName)
+	if len(primaryKey) == 1 && primaryKey[0] == port.RecordKeyRowid {
+		return nil, nil
+	}
+	return primaryKey, nil
+}
+
+// List 按访问时间从新到旧返回符合条件的访问日志及总数
+func (s *Service) List(ctx context.Context, filter Filter) ([]domain.RecordAccess, int64, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	where := make([]string, 0, 6)
+	args := make([]interface{}, 0, 8)
+	for column, value := range map[string]string{"biz_name": filter.BizName, "table_name": filter.TableName, "record_id": filter.RecordID} {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if filter.UserID > 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Since != nil {
+		where = append(where, "accessed_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		where = append(where, "accessed_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	clause := ""
+	if len(where) > 0 {
+		clause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_access_log`+clause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计记录访问日志失败: %w", err)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, biz_name, table_name, record_id, user_id, username, ip, source, accessed_at
+		FROM record_access_log`+clause+` ORDER BY accessed_at DESC, id DESC LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询记录访问日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.RecordAccess, 0)
+	for rows.Next() {
+		var item domain.RecordAccess
+		var userID sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.BizName, &item.TableName, &item.RecordID, &userID, &item.Username,
+			&item.IP, &item.Source, &item.AccessedAt); err != nil {
+			return nil, 0, fmt.Errorf("读取记录访问日志失败: %w", err)
+		}
+		if userID.Valid {
+			item.UserID = &userID.Int64
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
+
+// Run 每天清理超过保留期限的访问日志，直到 ctx 被取消
+func (s *Service) Run(ctx context.Context) {
+	s.purge(ctx)
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purge(ctx)
+		}
+	}
+}
+
+func (s *Service) purge(ctx context.Context) {
+	cutoff := s.now().UTC().AddDate(0, 0, -s.opts.RetentionDays)
+	res, err := s.db.ExecContext(ctx, `DELETE FROM record_access_log WHERE accessed_at < ?`, cutoff)
+	if err != nil {
+		slog.Warn("[AccessLog] 清理过期的记录访问日志失败", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		slog.Info("[AccessLog] 已清理过期的记录访问日志", "count", n, "retention_days", s.opts.RetentionDays)
+	}
+}
+
+// recordID 以主键列的值拼接记录标识，主键未知或结果中缺少主键列时返回空字符串
+func recordID(row map[string]interface{}, primaryKey []string) string {
+	if len(primaryKey) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(primaryKey))
+	for _, k := range primaryKey {
+		v, ok := row[k]
+		if !ok || v == nil {
+			return ""
+		}
+		switch x := v.(type) {
+		case string:
+			parts = append(parts, x)
+		case float64:
+			parts = append(parts, strconv.FormatFloat(x, 'f', -1, 64))
+		default:
+			parts = append(parts, fmt.Sprintf("%v", x))
+		}
+	}
+	return strings.Join(parts, port.RecordIDSeparator)
+}
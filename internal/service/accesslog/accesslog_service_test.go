@@ -0,0 +1,52 @@
+// file: internal/service/accesslog/accesslog_service_test.go
+package accesslog
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordID(t *testing.T) {
+	row := map[string]interface{}{"vol": "b", "no": float64(3), "title": nil}
+	assert.Equal(t, "3,b", recordID(row, []string{"no", "vol"}), "按主键声明顺序拼接")
+	assert.Equal(t, "", recordID(row, []string{"id"}), "结果中缺少主键列")
+	assert.Equal(t, "", recordID(row, []string{"title"}))
+	assert.Equal(t, "", recordID(row, nil), "没有显式主键的表")
+}
+
+func TestPurgeAndList(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	configService, err := admin_config.NewAdminConfigServiceImpl(db, 10, time.Minute)
+	require.NoError(t, err)
+	s, err := NewService(db, map[string]port.DataSource{}, configService, Options{RetentionDays: 30})
+	require.NoError(t, err)
+
+	clock := time.Now()
+	s.now = func() time.Time { return clock.AddDate(0, 0, -40) }
+	userID := int64(7)
+	require.NoError(t, s.write(ctx, "archive", "letters", []string{"1", "2"}, Viewer{UserID: &userID, IP: "10.0.0.1"}, "query"))
+	s.now = func() time.Time { return clock }
+	require.NoError(t, s.write(ctx, "archive", "letters", []string{"2"}, Viewer{IP: "10.0.0.2"}, "permalink"))
+
+	items, total, err := s.List(ctx, Filter{RecordID: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "10.0.0.2", items[0].IP, "按访问时间从新到旧")
+	_, total, err = s.List(ctx, Filter{UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	s.purge(ctx)
+	items, total, err = s.List(ctx, Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "超过保留期限的日志被清理")
+	assert.Equal(t, "permalink", items[0].Source)
+}
@@ -4,9 +4,14 @@ package admin_config
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"ArchiveAegis/internal/core/domain"
@@ -20,10 +25,24 @@ import (
 type AdminConfigServiceImpl struct {
 	db    *sql.DB
 	cache *lru.LRU[string, *domain.BizQueryConfig]
+
+	// cachedAt 记录每个缓存条目写入的时间，供 CacheState 展示条目的年龄；条目被淘汰或过期时由回调删除
+	cachedAtMu sync.Mutex
+	cachedAt   map[string]time.Time
+	cacheTTL   time.Duration
+	cacheSize  int
+
+	// instance 与 generation 组成配置版本 (见 ConfigVersion)：instance 区分进程，generation 在每次缓存失效时递增
+	instance   string
+	generation atomic.Uint64
+
+	// identifiers 在保存可查询表与字段配置前检查标识符，为 nil 时不检查
+	identifiers port.IdentifierChecker
 }
 
 // 静态断言，确保 AdminConfigServiceImpl 实现了 port.QueryAdminConfigService 接口。
 var _ port.QueryAdminConfigService = (*AdminConfigServiceImpl)(nil)
+var _ port.ConfigCacheInspector = (*AdminConfigServiceImpl)(nil)
 
 // NewAdminConfigServiceImpl 创建一个新的 AdminConfigServiceImpl 实例。
 // authDB: 认证数据库连接实例。
@@ -40,13 +59,96 @@ func NewAdminConfigServiceImpl(authDB *sql.DB, maxCacheEntries int, defaultCache
 		defaultCacheTTL = 5 * time.Minute // 默认值
 	}
 
+	s := &AdminConfigServiceImpl{
+		db:        authDB,
+		cachedAt:  make(map[string]time.Time),
+		cacheTTL:  defaultCacheTTL,
+		cacheSize: maxCacheEntries,
+		instance:  strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
 	// 初始化一个带有过期时间的 LRU 缓存
-	lruCacheInstance := lru.NewLRU[string, *domain.BizQueryConfig](maxCacheEntries, nil, defaultCacheTTL)
+	s.cache = lru.NewLRU[string, *domain.BizQueryConfig](maxCacheEntries, s.onCacheEvict, defaultCacheTTL)
+	return s, nil
+}
+
+// SetIdentifierChecker 设置保存配置前使用的标识符检查，应在开始处理请求前调用
+func (s *AdminConfigServiceImpl) SetIdentifierChecker(checker port.IdentifierChecker) {
+	s.identifiers = checker
+}
+
+// checkIdentifiers 按标识符策略检查一组将要保存的表名或字段名
+func (s *AdminConfigServiceImpl) checkIdentifiers(kind string, names ...string) error {
+	if s.identifiers == nil {
+		return nil
+	}
+	for _, name := range names {
+		if err := s.identifiers.Check(kind, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return &AdminConfigServiceImpl{
-		db:    authDB,
-		cache: lruCacheInstance,
-	}, nil
+// checkFieldIdentifiers 检查一张表的表名与其字段配置中的字段名
+func (s *AdminConfigServiceImpl) checkFieldIdentifiers(tableName string, fields []domain.FieldSetting) error {
+	if err := s.checkIdentifiers(domain.IdentifierKindTable, tableName); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := s.checkIdentifiers(domain.IdentifierKindField, field.FieldName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cachePut 写入缓存并记录写入时间
+func (s *AdminConfigServiceImpl) cachePut(bizName string, cfg *domain.BizQueryConfig) {
+	s.cache.Add(bizName, cfg)
+	s.cachedAtMu.Lock()
+	s.cachedAt[bizName] = time.Now()
+	s.cachedAtMu.Unlock()
+}
+
+func (s *AdminConfigServiceImpl) onCacheEvict(bizName string, _ *domain.BizQueryConfig) {
+	s.cachedAtMu.Lock()
+	delete(s.cachedAt, bizName)
+	s.cachedAtMu.Unlock()
+}
+
+// CacheState 返回配置缓存中各业务组条目的写入时间与剩余有效期，按业务组名排序
+func (s *AdminConfigServiceImpl) CacheState() domain.ConfigCacheState {
+	keys := s.cache.Keys()
+	now := time.Now()
+	entries := make([]domain.ConfigCacheEntry, 0, len(keys))
+	s.cachedAtMu.Lock()
+	for _, key := range keys {
+		cachedAt, ok := s.cachedAt[key]
+		if !ok {
+			continue
+		}
+		entries = append(entries, domain.ConfigCacheEntry{
+			BizName:          key,
+			CachedAt:         cachedAt,
+			AgeSeconds:       now.Sub(cachedAt).Seconds(),
+			ExpiresInSeconds: max(cachedAt.Add(s.cacheTTL).Sub(now).Seconds(), 0),
+		})
+	}
+	s.cachedAtMu.Unlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].BizName < entries[j].BizName })
+	return domain.ConfigCacheState{
+		ConfigVersion: s.ConfigVersion(),
+		TTLSeconds:    s.cacheTTL.Seconds(),
+		MaxEntries:    s.cacheSize,
+		Entries:       entries,
+	}
+}
+
+// ConfigVersion 返回当前的配置版本，配置被修改 (缓存失效) 后版本随之变化。
+// 运行在独立进程中的插件各自缓存配置，网关在每次调用插件时附带该版本，插件发现版本变化时丢弃自己的缓存。
+// 版本只用于判断是否相同，不比较大小；网关重启后版本必然不同
+func (s *AdminConfigServiceImpl) ConfigVersion() string {
+	return s.instance + "-" + strconv.FormatUint(s.generation.Load(), 10)
 }
 
 // InvalidateCacheForBiz 手动使指定业务组的缓存失效。
@@ -55,12 +157,14 @@ func (s *AdminConfigServiceImpl) InvalidateCacheForBiz(bizName string) {
 		return
 	}
 	s.cache.Remove(bizName)
+	s.generation.Add(1)
 	log.Printf("信息: [AdminConfigService] 业务 '%s' 的查询配置LRU缓存已失效。", bizName)
 }
 
 // InvalidateAllCaches 清除所有缓存。
 func (s *AdminConfigServiceImpl) InvalidateAllCaches() {
 	s.cache.Purge()
+	s.generation.Add(1)
 	log.Printf("信息: [AdminConfigService] 所有查询配置LRU缓存已清除。")
 }
 
@@ -86,20 +190,22 @@ func (s *AdminConfigServiceImpl) loadBizQueryConfigFromDB(ctx context.Context, b
 	bizConfig.Tables = tables
 
 	// 更新缓存（如果有必要）
-	s.cache.Add(bizName, bizConfig)
+	s.cachePut(bizName, bizConfig)
 
 	return bizConfig, nil
 }
 
 // queryBizOverallConfig 查询业务组整体配置。
 func (s *AdminConfigServiceImpl) queryBizOverallConfig(ctx context.Context, bizName string) (*domain.BizQueryConfig, error) {
-	var isPubliclySearchable bool
-	var defaultQueryTableNullable sql.NullString
+	var isPubliclySearchable, changeCaptureEnabled, enabled, sensitive bool
+	var defaultQueryTableNullable, citationTemplateNullable sql.NullString
+	var maxResponseBytes, maxScanRows int64
+	var queryCostPolicy, timezone, locale string
 
 	err := s.db.QueryRowContext(ctx,
-		`SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings WHERE biz_name = ?`,
+		`SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings WHERE biz_name = ?`,
 		bizName,
-	).Scan(&isPubliclySearchable, &defaultQueryTableNullable)
+	).Scan(&isPubliclySearchable, &defaultQueryTableNullable, &changeCaptureEnabled, &citationTemplateNullable, &enabled, &maxResponseBytes, &queryCostPolicy, &maxScanRows, &timezone, &locale, &sensitive)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil // 业务未配置，不是错误
@@ -112,6 +218,15 @@ func (s *AdminConfigServiceImpl) queryBizOverallConfig(ctx context.Context, bizN
 		BizName:              bizName,
 		IsPubliclySearchable: isPubliclySearchable,
 		DefaultQueryTable:    "",
+		ChangeCaptureEnabled: changeCaptureEnabled,
+		CitationTemplate:     citationTemplateNullable.String,
+		Enabled:              enabled,
+		MaxResponseBytes:     maxResponseBytes,
+		QueryCostPolicy:      queryCostPolicy,
+		MaxScanRows:          maxScanRows,
+		Timezone:             timezone,
+		Locale:               locale,
+		Sensitive:            sensitive,
 		Tables:               make(map[string]*domain.TableConfig),
 	}
 	if defaultQueryTableNullable.Valid {
@@ -125,7 +240,7 @@ func (s *AdminConfigServiceImpl) queryBizTables(ctx context.Context, bizName str
 	tables := make(map[string]*domain.TableConfig)
 
 	queryTables := `
-		SELECT table_name, is_searchable, allow_create, allow_update, allow_delete
+		SELECT table_name, is_searchable, allow_create, allow_update, allow_delete, fts_config, ranking_config, hierarchy_config, sql_view_config
 		FROM biz_searchable_tables WHERE biz_name = ?
 	`
 	rows, err := s.db.QueryContext(ctx, queryTables, bizName)
@@ -138,10 +253,39 @@ func (s *AdminConfigServiceImpl) queryBizTables(ctx context.Context, bizName str
 		tc := &domain.TableConfig{
 			Fields: make(map[string]domain.FieldSetting),
 		}
-		if err := rows.Scan(&tc.TableName, &tc.IsSearchable, &tc.AllowCreate, &tc.AllowUpdate, &tc.AllowDelete); err != nil {
+		var ftsConfig, rankingConfig, hierarchyConfig, sqlViewConfig string
+		if err := rows.Scan(&tc.TableName, &tc.IsSearchable, &tc.AllowCreate, &tc.AllowUpdate, &tc.AllowDelete, &ftsConfig, &rankingConfig, &hierarchyConfig, &sqlViewConfig); err != nil {
 			log.Printf("警告: [AdminConfigService] 扫描业务 '%s' 的表配置失败: %v，已跳过该表", bizName, err)
 			continue
 		}
+		if ftsConfig != "" {
+			tc.FTS = &domain.FTSConfig{}
+			if err := json.Unmarshal([]byte(ftsConfig), tc.FTS); err != nil {
+				log.Printf("警告: [AdminConfigService] 解析表 '%s/%s' 的全文检索配置失败: %v，已忽略", bizName, tc.TableName, err)
+				tc.FTS = nil
+			}
+		}
+		if rankingConfig != "" {
+			tc.Ranking = &domain.RankingConfig{}
+			if err := json.Unmarshal([]byte(rankingConfig), tc.Ranking); err != nil {
+				log.Printf("警告: [AdminConfigService] 解析表 '%s/%s' 的相关度得分配置失败: %v，已忽略", bizName, tc.TableName, err)
+				tc.Ranking = nil
+			}
+		}
+		if hierarchyConfig != "" {
+			tc.Hierarchy = &domain.HierarchyConfig{}
+			if err := json.Unmarshal([]byte(hierarchyConfig), tc.Hierarchy); err != nil {
+				log.Printf("警告: [AdminConfigService] 解析表 '%s/%s' 的层级关系配置失败: %v，已忽略", bizName, tc.TableName, err)
+				tc.Hierarchy = nil
+			}
+		}
+		if sqlViewConfig != "" {
+			tc.SQLView = &domain.SQLViewConfig{}
+			if err := json.Unmarshal([]byte(sqlViewConfig), tc.SQLView); err != nil {
+				log.Printf("警告: [AdminConfigService] 解析表 '%s/%s' 的 SQL 视图定义失败: %v，已忽略", bizName, tc.TableName, err)
+				tc.SQLView = nil
+			}
+		}
 
 		fields, err := s.queryTableFields(ctx, bizName, tc.TableName)
 		if err != nil {
@@ -166,7 +310,7 @@ func (s *AdminConfigServiceImpl) queryTableFields(ctx context.Context, bizName,
 	fields := make(map[string]domain.FieldSetting)
 
 	rows, err := s.db.QueryContext(ctx,
-		`SELECT field_name, is_searchable, is_returnable, data_type
+		`SELECT field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight
 		 FROM biz_table_field_settings
 		 WHERE biz_name = ? AND table_name = ?`,
 		bizName, tableName)
@@ -177,7 +321,7 @@ func (s *AdminConfigServiceImpl) queryTableFields(ctx context.Context, bizName,
 
 	for rows.Next() {
 		var fs domain.FieldSetting
-		if err := rows.Scan(&fs.FieldName, &fs.IsSearchable, &fs.IsReturnable, &fs.DataType); err != nil {
+		if err := rows.Scan(&fs.FieldName, &fs.IsSearchable, &fs.IsReturnable, &fs.DataType, &fs.DisplayName, &fs.Pinyin, &fs.CaseInsensitive, &fs.AccentInsensitive, &fs.SearchWeight); err != nil {
 			log.Printf("警告: [AdminConfigService] 扫描字段失败(业务 '%s', 表 '%s'): %v，已跳过", bizName, tableName, err)
 			continue
 		}
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"ArchiveAegis/internal/core/domain"
+
 	"github.com/DATA-DOG/go-sqlmock"
 )
 
@@ -34,31 +36,31 @@ func TestLoadBizQueryConfigFromDB_Normal(t *testing.T) {
 	ctx := context.Background()
 
 	// 1. Mock 总体配置
-	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table"}).
-		AddRow(true, "main")
-	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings").
+	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table", "change_capture_enabled", "citation_template", "enabled", "max_response_bytes", "query_cost_policy", "max_scan_rows", "timezone", "locale", "sensitive"}).
+		AddRow(true, "main", false, nil, true, 4096, "reject", 5000, "Asia/Shanghai", "zh-CN", true)
+	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings").
 		WithArgs("biz1").
 		WillReturnRows(rowsSetting)
 
 	// 2. Mock 表配置（两张表）
-	rowsTables := sqlmock.NewRows([]string{"table_name", "is_searchable", "allow_create", "allow_update", "allow_delete"}).
-		AddRow("main", true, true, true, true).
-		AddRow("sub", false, false, false, false)
-	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete FROM biz_searchable_tables").
+	rowsTables := sqlmock.NewRows([]string{"table_name", "is_searchable", "allow_create", "allow_update", "allow_delete", "fts_config", "ranking_config", "hierarchy_config", "sql_view_config"}).
+		AddRow("main", true, true, true, true, `{"fields":["name"],"tokenizer":"trigram","version":2}`, `{"expression":"bm25"}`, `{"key_field":"id","parent_field":"parent_id"}`, "").
+		AddRow("sub", false, false, false, false, "", "", "", `{"sql":"SELECT id FROM main"}`)
+	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete, fts_config, ranking_config, hierarchy_config, sql_view_config FROM biz_searchable_tables").
 		WithArgs("biz1").
 		WillReturnRows(rowsTables)
 
 	// 3. Mock 字段(main表有两个字段)
-	rowsFieldsMain := sqlmock.NewRows([]string{"field_name", "is_searchable", "is_returnable", "data_type"}).
-		AddRow("id", true, true, "int").
-		AddRow("name", false, true, "string")
-	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type FROM biz_table_field_settings").
+	rowsFieldsMain := sqlmock.NewRows([]string{"field_name", "is_searchable", "is_returnable", "data_type", "display_name", "pinyin_enabled", "case_insensitive", "accent_insensitive", "search_weight"}).
+		AddRow("id", true, true, "int", "编号", false, false, false, 0.0).
+		AddRow("name", false, true, "string", "", true, true, true, 2.0)
+	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight FROM biz_table_field_settings").
 		WithArgs("biz1", "main").
 		WillReturnRows(rowsFieldsMain)
 
 	// 4. Mock 字段(sub表无字段)
-	rowsFieldsSub := sqlmock.NewRows([]string{"field_name", "is_searchable", "is_returnable", "data_type"})
-	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type FROM biz_table_field_settings").
+	rowsFieldsSub := sqlmock.NewRows([]string{"field_name", "is_searchable", "is_returnable", "data_type", "display_name", "pinyin_enabled", "case_insensitive", "accent_insensitive", "search_weight"})
+	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight FROM biz_table_field_settings").
 		WithArgs("biz1", "sub").
 		WillReturnRows(rowsFieldsSub)
 
@@ -75,6 +77,45 @@ func TestLoadBizQueryConfigFromDB_Normal(t *testing.T) {
 	if len(cfg.Tables["main"].Fields) != 2 || cfg.Tables["sub"].Fields == nil {
 		t.Fatalf("字段数量或字段为空: %+v", cfg.Tables)
 	}
+	if name := cfg.Tables["main"].Fields["name"]; !name.Pinyin || !name.CaseInsensitive || !name.AccentInsensitive || name.SearchWeight != 2 {
+		t.Fatalf("字段匹配选项解析不正确: %+v", name)
+	}
+	if fts := cfg.Tables["main"].FTS; fts == nil || fts.Tokenizer != "trigram" || fts.Version != 2 {
+		t.Fatalf("全文检索配置解析不正确: %+v", fts)
+	}
+	if cfg.Tables["sub"].FTS != nil {
+		t.Fatalf("未配置全文检索的表 FTS 应为 nil: %+v", cfg.Tables["sub"].FTS)
+	}
+	if r := cfg.Tables["main"].Ranking; r == nil || r.Expression != "bm25" {
+		t.Fatalf("相关度得分配置解析错误: %+v", r)
+	}
+	if cfg.Tables["sub"].Ranking != nil {
+		t.Fatalf("未配置相关度得分的表 Ranking 应为 nil: %+v", cfg.Tables["sub"].Ranking)
+	}
+	if h := cfg.Tables["main"].Hierarchy; h == nil || h.KeyField != "id" || h.ParentField != "parent_id" {
+		t.Fatalf("层级关系配置解析错误: %+v", h)
+	}
+	if cfg.Tables["sub"].Hierarchy != nil {
+		t.Fatalf("未配置层级关系的表 Hierarchy 应为 nil: %+v", cfg.Tables["sub"].Hierarchy)
+	}
+	if cfg.Tables["main"].SQLView != nil {
+		t.Fatalf("物理表的 SQLView 应为 nil: %+v", cfg.Tables["main"].SQLView)
+	}
+	if v := cfg.Tables["sub"].SQLView; v == nil || v.SQL != "SELECT id FROM main" {
+		t.Fatalf("SQL 视图定义解析错误: %+v", v)
+	}
+	if cfg.MaxResponseBytes != 4096 {
+		t.Fatalf("结果字节数上限解析不正确: %d", cfg.MaxResponseBytes)
+	}
+	if cfg.QueryCostPolicy != "reject" || cfg.MaxScanRows != 5000 {
+		t.Fatalf("检索代价设置解析不正确: %s %d", cfg.QueryCostPolicy, cfg.MaxScanRows)
+	}
+	if cfg.Timezone != "Asia/Shanghai" || cfg.Locale != "zh-CN" {
+		t.Fatalf("时区与语言区域解析不正确: %s %s", cfg.Timezone, cfg.Locale)
+	}
+	if !cfg.Sensitive {
+		t.Fatalf("敏感标记解析不正确")
+	}
 }
 
 // ===============================
@@ -85,9 +126,9 @@ func TestLoadBizQueryConfigFromDB_NoRows(t *testing.T) {
 	defer teardown()
 	ctx := context.Background()
 
-	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings").
+	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings").
 		WithArgs("unknown").
-		WillReturnRows(sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table"}))
+		WillReturnRows(sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table", "change_capture_enabled", "citation_template", "enabled", "max_response_bytes", "query_cost_policy", "max_scan_rows", "timezone", "locale", "sensitive"}))
 
 	cfg, err := svc.loadBizQueryConfigFromDB(ctx, "unknown")
 	if err != nil {
@@ -106,7 +147,7 @@ func TestLoadBizQueryConfigFromDB_OverallError(t *testing.T) {
 	defer teardown()
 	ctx := context.Background()
 
-	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings").
+	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings").
 		WithArgs("errcase").
 		WillReturnError(errors.New("fail"))
 	cfg, err := svc.loadBizQueryConfigFromDB(ctx, "errcase")
@@ -123,13 +164,13 @@ func TestLoadBizQueryConfigFromDB_TableError(t *testing.T) {
 	defer teardown()
 	ctx := context.Background()
 
-	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table"}).
-		AddRow(false, nil)
-	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings").
+	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table", "change_capture_enabled", "citation_template", "enabled", "max_response_bytes", "query_cost_policy", "max_scan_rows", "timezone", "locale", "sensitive"}).
+		AddRow(false, nil, false, nil, true, 0, "", 0, "", "", false)
+	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings").
 		WithArgs("tableerr").
 		WillReturnRows(rowsSetting)
 
-	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete FROM biz_searchable_tables").
+	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete, fts_config, ranking_config, hierarchy_config, sql_view_config FROM biz_searchable_tables").
 		WithArgs("tableerr").
 		WillReturnError(errors.New("tablefail"))
 
@@ -147,19 +188,19 @@ func TestLoadBizQueryConfigFromDB_FieldError(t *testing.T) {
 	defer teardown()
 	ctx := context.Background()
 
-	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table"}).
-		AddRow(false, nil)
-	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table FROM biz_overall_settings").
+	rowsSetting := sqlmock.NewRows([]string{"is_publicly_searchable", "default_query_table", "change_capture_enabled", "citation_template", "enabled", "max_response_bytes", "query_cost_policy", "max_scan_rows", "timezone", "locale", "sensitive"}).
+		AddRow(false, nil, false, nil, true, 0, "", 0, "", "", false)
+	mock.ExpectQuery("SELECT is_publicly_searchable, default_query_table, change_capture_enabled, citation_template, enabled, max_response_bytes, query_cost_policy, max_scan_rows, timezone, locale, sensitive FROM biz_overall_settings").
 		WithArgs("fielderr").
 		WillReturnRows(rowsSetting)
 
-	rowsTables := sqlmock.NewRows([]string{"table_name", "is_searchable", "allow_create", "allow_update", "allow_delete"}).
-		AddRow("main", false, false, false, false)
-	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete FROM biz_searchable_tables").
+	rowsTables := sqlmock.NewRows([]string{"table_name", "is_searchable", "allow_create", "allow_update", "allow_delete", "fts_config", "ranking_config", "hierarchy_config", "sql_view_config"}).
+		AddRow("main", false, false, false, false, "", "", "", "")
+	mock.ExpectQuery("SELECT table_name, is_searchable, allow_create, allow_update, allow_delete, fts_config, ranking_config, hierarchy_config, sql_view_config FROM biz_searchable_tables").
 		WithArgs("fielderr").
 		WillReturnRows(rowsTables)
 
-	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type FROM biz_table_field_settings").
+	mock.ExpectQuery("SELECT field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight FROM biz_table_field_settings").
 		WithArgs("fielderr", "main").
 		WillReturnError(errors.New("fieldfail"))
 
@@ -191,3 +232,61 @@ func TestLoadBizQueryConfigFromDB_EmptyBizName(t *testing.T) {
 		t.Fatalf("bizName为空应cfg为nil, 实际: cfg=%+v", cfg)
 	}
 }
+
+func TestConfigVersion_ChangesOnInvalidation(t *testing.T) {
+	svc, _, teardown := newTestService(t)
+	defer teardown()
+
+	v0 := svc.ConfigVersion()
+	if v0 != svc.ConfigVersion() {
+		t.Fatalf("未修改配置时版本不应变化")
+	}
+	svc.InvalidateCacheForBiz("")
+	if svc.ConfigVersion() != v0 {
+		t.Fatalf("空业务组名不会使缓存失效，版本不应变化")
+	}
+	svc.InvalidateCacheForBiz("biz1")
+	v1 := svc.ConfigVersion()
+	if v1 == v0 {
+		t.Fatalf("缓存失效后版本应变化")
+	}
+	svc.InvalidateAllCaches()
+	if svc.ConfigVersion() == v1 {
+		t.Fatalf("清除所有缓存后版本应变化")
+	}
+}
+
+// ===============================
+// 缓存状态
+// ===============================
+func TestCacheState(t *testing.T) {
+	svc, _, teardown := newTestService(t)
+	defer teardown()
+
+	svc.cachePut("biz_b", &domain.BizQueryConfig{BizName: "biz_b"})
+	svc.cachePut("biz_a", &domain.BizQueryConfig{BizName: "biz_a"})
+	state := svc.CacheState()
+	if state.MaxEntries != 10 || state.TTLSeconds != 60 {
+		t.Errorf("缓存设置错误: %+v", state)
+	}
+	if len(state.Entries) != 2 || state.Entries[0].BizName != "biz_a" || state.Entries[1].BizName != "biz_b" {
+		t.Fatalf("缓存条目应按业务组名排序: %+v", state.Entries)
+	}
+	if e := state.Entries[0]; e.ExpiresInSeconds <= 0 || e.ExpiresInSeconds > 60 || e.AgeSeconds < 0 {
+		t.Errorf("缓存条目的年龄或剩余有效期错误: %+v", e)
+	}
+
+	version := state.ConfigVersion
+	svc.InvalidateCacheForBiz("biz_a")
+	state = svc.CacheState()
+	if len(state.Entries) != 1 || state.Entries[0].BizName != "biz_b" {
+		t.Errorf("失效的条目不应再出现: %+v", state.Entries)
+	}
+	if state.ConfigVersion == version {
+		t.Error("缓存失效后配置版本应变化")
+	}
+	svc.InvalidateAllCaches()
+	if state = svc.CacheState(); len(state.Entries) != 0 || len(svc.cachedAt) != 0 {
+		t.Errorf("清空缓存后不应有条目: %+v", state.Entries)
+	}
+}
@@ -29,7 +29,7 @@ func (s *AdminConfigServiceImpl) GetBizQueryConfig(ctx context.Context, bizName
 
 	// 加载成功则加入缓存
 	if dbConfig != nil {
-		s.cache.Add(bizName, dbConfig)
+		s.cachePut(bizName, dbConfig)
 	}
 	return dbConfig, nil
 }
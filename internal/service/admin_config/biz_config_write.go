@@ -7,8 +7,13 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"regexp"
+	"time"
 )
 
+// localePattern 是 BCP 47 语言标签的宽松形式：语言子标签后跟若干以 "-" 分隔的子标签 (如 zh-Hans-CN)
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
 // UpdateBizOverallSettings 更新业务组的总体设置。
 // settings 中的 nil 字段表示不更新该设置。
 // 此函数现在执行 UPSERT (INSERT INTO ... ON CONFLICT DO UPDATE) 操作，
@@ -84,6 +89,91 @@ func (s *AdminConfigServiceImpl) UpdateBizOverallSettings(ctx context.Context, b
 	// 对于 UPSERT 操作，不需要检查 RowsAffected，因为其值可能为 0 (如果数据未更改) 或 1 (插入或更新)。
 	// 之前的 "业务组 '%s' 未找到或数据未变更" 错误将不再发生。
 
+	// 可选开关仅在客户端显式传递时才更新，避免覆盖已有值
+	if settings.ChangeCaptureEnabled != nil {
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET change_capture_enabled = ? WHERE biz_name = ?`,
+			*settings.ChangeCaptureEnabled, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的变更捕获开关失败: %w", bizName, err)
+		}
+	}
+	if settings.CitationTemplate != nil {
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET citation_template = ? WHERE biz_name = ?`,
+			*settings.CitationTemplate, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的引用模板失败: %w", bizName, err)
+		}
+	}
+	if settings.Enabled != nil {
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET enabled = ? WHERE biz_name = ?`,
+			*settings.Enabled, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的启用状态失败: %w", bizName, err)
+		}
+	}
+	if settings.MaxResponseBytes != nil {
+		if *settings.MaxResponseBytes < 0 {
+			return fmt.Errorf("业务 '%s' 的 max_response_bytes 不能为负数", bizName)
+		}
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET max_response_bytes = ? WHERE biz_name = ?`,
+			*settings.MaxResponseBytes, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的结果字节数上限失败: %w", bizName, err)
+		}
+	}
+	if settings.QueryCostPolicy != nil {
+		switch *settings.QueryCostPolicy {
+		case "", domain.QueryCostPolicyOff, domain.QueryCostPolicyWarn, domain.QueryCostPolicyReject:
+		default:
+			return fmt.Errorf("业务 '%s' 的 query_cost_policy 无效: '%s'", bizName, *settings.QueryCostPolicy)
+		}
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET query_cost_policy = ? WHERE biz_name = ?`,
+			*settings.QueryCostPolicy, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的检索代价策略失败: %w", bizName, err)
+		}
+	}
+	if settings.MaxScanRows != nil {
+		if *settings.MaxScanRows < 0 {
+			return fmt.Errorf("业务 '%s' 的 max_scan_rows 不能为负数", bizName)
+		}
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET max_scan_rows = ? WHERE biz_name = ?`,
+			*settings.MaxScanRows, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的扫描行数上限失败: %w", bizName, err)
+		}
+	}
+	if settings.Timezone != nil {
+		if *settings.Timezone != "" {
+			if _, errTZ := time.LoadLocation(*settings.Timezone); errTZ != nil || *settings.Timezone == "Local" {
+				return fmt.Errorf("业务 '%s' 的 timezone 无效: '%s'，应为 IANA 时区名称 (如 Asia/Shanghai)", bizName, *settings.Timezone)
+			}
+		}
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET timezone = ? WHERE biz_name = ?`,
+			*settings.Timezone, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的时区失败: %w", bizName, err)
+		}
+	}
+	if settings.Locale != nil {
+		if *settings.Locale != "" && !localePattern.MatchString(*settings.Locale) {
+			return fmt.Errorf("业务 '%s' 的 locale 无效: '%s'，应为 BCP 47 语言标签 (如 zh-CN)", bizName, *settings.Locale)
+		}
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET locale = ? WHERE biz_name = ?`,
+			*settings.Locale, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的语言区域失败: %w", bizName, err)
+		}
+	}
+
+	if settings.Sensitive != nil {
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE biz_overall_settings SET sensitive = ? WHERE biz_name = ?`,
+			*settings.Sensitive, bizName); err != nil {
+			return fmt.Errorf("更新业务 '%s' 的敏感标记失败: %w", bizName, err)
+		}
+	}
+
 	// 清除缓存
 	s.InvalidateCacheForBiz(bizName)
 	log.Printf("信息: 业务组 '%s' 的总体配置已更新/插入，相关缓存已失效。", bizName)
@@ -97,6 +187,9 @@ func (s *AdminConfigServiceImpl) UpdateBizSearchableTables(ctx context.Context,
 	if bizName == "" {
 		return fmt.Errorf("业务组名称不能为空")
 	}
+	if err = s.checkIdentifiers(domain.IdentifierKindTable, tableNames...); err != nil {
+		return err
+	}
 
 	// 开启事务
 	tx, err := s.db.BeginTx(ctx, nil)
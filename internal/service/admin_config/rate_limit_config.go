@@ -164,31 +164,51 @@ func (s *AdminConfigServiceImpl) UpdateUserLimitSettings(ctx context.Context, us
 
 // GetBizRateLimitSettings 获取特定业务组的速率限制配置。
 func (s *AdminConfigServiceImpl) GetBizRateLimitSettings(ctx context.Context, bizName string) (*domain.BizRateLimitSetting, error) {
-	query := "SELECT rate_limit_per_second, burst_size FROM biz_ratelimit_settings WHERE biz_name = ?"
+	query := `SELECT rate_limit_per_second, burst_size, anon_rate_limit_per_second, anon_burst_size, anon_max_page_size, anon_response_delay_ms
+		FROM biz_ratelimit_settings WHERE biz_name = ?`
 	setting := &domain.BizRateLimitSetting{}
-	err := s.db.QueryRowContext(ctx, query, bizName).Scan(&setting.RateLimitPerSecond, &setting.BurstSize)
+	var anonRate sql.NullFloat64
+	anonymous := domain.AnonymousAccessSetting{}
+	err := s.db.QueryRowContext(ctx, query, bizName).Scan(&setting.RateLimitPerSecond, &setting.BurstSize,
+		&anonRate, &anonymous.BurstSize, &anonymous.MaxPageSize, &anonymous.ResponseDelayMs)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // 业务组未设置个性化限制
 		}
 		return nil, fmt.Errorf("数据库查询业务组 '%s' 速率限制失败: %w", bizName, err)
 	}
+	if anonRate.Valid {
+		anonymous.RateLimitPerSecond = anonRate.Float64
+		setting.Anonymous = &anonymous
+	}
 	return setting, nil
 }
 
 // UpdateBizRateLimitSettings 更新特定业务组的速率限制配置。
-// 使用 UPSERT 确保配置的存在性或更新。
+// 使用 UPSERT 确保配置的存在性或更新；settings.Anonymous 为 nil 时清除匿名访问层的设置。
 func (s *AdminConfigServiceImpl) UpdateBizRateLimitSettings(ctx context.Context, bizName string, settings domain.BizRateLimitSetting) error {
+	var anonRate sql.NullFloat64
+	anonymous := domain.AnonymousAccessSetting{}
+	if settings.Anonymous != nil {
+		anonymous = *settings.Anonymous
+		anonRate = sql.NullFloat64{Float64: anonymous.RateLimitPerSecond, Valid: true}
+	}
 	query := `
-        INSERT INTO biz_ratelimit_settings (biz_name, rate_limit_per_second, burst_size) 
-        VALUES (?, ?, ?) 
+        INSERT INTO biz_ratelimit_settings (biz_name, rate_limit_per_second, burst_size,
+            anon_rate_limit_per_second, anon_burst_size, anon_max_page_size, anon_response_delay_ms) 
+        VALUES (?, ?, ?, ?, ?, ?, ?) 
         ON CONFLICT(biz_name) DO UPDATE SET 
             rate_limit_per_second = excluded.rate_limit_per_second, 
-            burst_size = excluded.burst_size`
-	_, err := s.db.ExecContext(ctx, query, bizName, settings.RateLimitPerSecond, settings.BurstSize)
+            burst_size = excluded.burst_size,
+            anon_rate_limit_per_second = excluded.anon_rate_limit_per_second,
+            anon_burst_size = excluded.anon_burst_size,
+            anon_max_page_size = excluded.anon_max_page_size,
+            anon_response_delay_ms = excluded.anon_response_delay_ms`
+	_, err := s.db.ExecContext(ctx, query, bizName, settings.RateLimitPerSecond, settings.BurstSize,
+		anonRate, anonymous.BurstSize, anonymous.MaxPageSize, anonymous.ResponseDelayMs)
 	if err != nil {
 		return fmt.Errorf("数据库更新业务组 '%s' 速率限制失败: %w", bizName, err)
 	}
-	log.Printf("信息: 业务组 '%s' 的速率限制已更新 (Rate: %.2f, Burst: %d)", bizName, settings.RateLimitPerSecond, settings.BurstSize)
+	log.Printf("信息: 业务组 '%s' 的速率限制已更新 (Rate: %.2f, Burst: %d, 匿名访问层: %v)", bizName, settings.RateLimitPerSecond, settings.BurstSize, settings.Anonymous != nil)
 	return nil
 }
@@ -4,11 +4,14 @@ package admin_config
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 
 	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
 )
 
 // UpdateTableWritePermissions 更新指定表的写权限设置。
@@ -89,6 +92,9 @@ func (s *AdminConfigServiceImpl) UpdateTableFieldSettings(ctx context.Context, b
 	if bizName == "" || tableName == "" {
 		return fmt.Errorf("业务名或表名不能为空")
 	}
+	if err = s.checkFieldIdentifiers(tableName, fields); err != nil {
+		return err
+	}
 
 	// 开启事务
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -112,23 +118,80 @@ func (s *AdminConfigServiceImpl) UpdateTableFieldSettings(ctx context.Context, b
 		}
 	}()
 
+	if err = replaceFieldSettingsTx(ctx, tx, bizName, tableName, fields); err != nil {
+		return err
+	}
+
+	s.InvalidateCacheForBiz(bizName)
+	return nil // 事务提交已在 defer 中处理
+}
+
+// UpdateFieldSettingsBulk 在同一个事务中全量更新多张表的字段配置，键为表名。
+// 任一张表写入失败时整体回滚，不会留下只更新了部分表的配置。
+func (s *AdminConfigServiceImpl) UpdateFieldSettingsBulk(ctx context.Context, bizName string, tables map[string][]domain.FieldSetting) (err error) {
+	if bizName == "" {
+		return fmt.Errorf("业务名不能为空")
+	}
+	tableNames := make([]string, 0, len(tables))
+	for tableName := range tables {
+		if tableName == "" {
+			return fmt.Errorf("表名不能为空")
+		}
+		if err = s.checkFieldIdentifiers(tableName, tables[tableName]); err != nil {
+			return err
+		}
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败 (业务 '%s'): %w", bizName, err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			log.Printf("严重错误: UpdateFieldSettingsBulk 触发 panic，事务已回滚 (业务 '%s'): %v", bizName, p)
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback()
+			log.Printf("警告: UpdateFieldSettingsBulk 执行失败，事务已回滚 (业务 '%s'): %v", bizName, err)
+		} else {
+			if commitErr := tx.Commit(); commitErr != nil {
+				err = fmt.Errorf("提交事务失败 (业务 '%s'): %w", bizName, commitErr)
+			}
+		}
+	}()
+
+	for _, tableName := range tableNames {
+		if err = replaceFieldSettingsTx(ctx, tx, bizName, tableName, tables[tableName]); err != nil {
+			return err
+		}
+	}
+
+	s.InvalidateCacheForBiz(bizName)
+	log.Printf("信息: [AdminConfigService] 业务组 '%s' 的 %d 张表的字段配置已批量更新，相关缓存已失效。", bizName, len(tableNames))
+	return nil // 事务提交由 defer 执行
+}
+
+// replaceFieldSettingsTx 在事务中删除表的现有字段配置并插入新的配置
+func replaceFieldSettingsTx(ctx context.Context, tx *sql.Tx, bizName, tableName string, fields []domain.FieldSetting) error {
 	// 删除旧字段配置
-	if _, err = tx.ExecContext(ctx,
+	if _, err := tx.ExecContext(ctx,
 		"DELETE FROM biz_table_field_settings WHERE biz_name = ? AND table_name = ?", bizName, tableName); err != nil {
 		return fmt.Errorf("清除旧字段配置失败 (业务 '%s', 表 '%s'): %w", bizName, tableName, err)
 	}
 
 	if len(fields) == 0 {
 		// 如果没有字段配置，删除完即可，无需插入
-		s.InvalidateCacheForBiz(bizName)
 		return nil
 	}
 
 	// 准备批量插入字段配置的语句
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO biz_table_field_settings 
-		(biz_name, table_name, field_name, is_searchable, is_returnable, data_type) 
-		VALUES (?, ?, ?, ?, ?, ?)`)
+		(biz_name, table_name, field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("准备插入字段配置失败 (业务 '%s', 表 '%s'): %w", bizName, tableName, err)
 	}
@@ -140,12 +203,143 @@ func (s *AdminConfigServiceImpl) UpdateTableFieldSettings(ctx context.Context, b
 
 	// 插入新字段配置
 	for _, field := range fields {
-		if _, err = stmt.ExecContext(ctx, bizName, tableName, field.FieldName,
-			field.IsSearchable, field.IsReturnable, field.DataType); err != nil {
+		if _, err := stmt.ExecContext(ctx, bizName, tableName, field.FieldName,
+			field.IsSearchable, field.IsReturnable, field.DataType, field.DisplayName, field.Pinyin, field.CaseInsensitive, field.AccentInsensitive, field.SearchWeight); err != nil {
 			return fmt.Errorf("插入字段配置失败 (业务 '%s', 表 '%s', 字段 '%s'): %w", bizName, tableName, field.FieldName, err)
 		}
 	}
 
+	return nil
+}
+
+// UpdateTableFTSConfig 保存指定表的全文检索配置，cfg 为 nil 时清除配置。
+// 表必须已被配置为业务组的可查询表；本方法只保存配置，索引由数据源另行重建。
+func (s *AdminConfigServiceImpl) UpdateTableFTSConfig(ctx context.Context, bizName, tableName string, cfg *domain.FTSConfig) error {
+	if bizName == "" || tableName == "" {
+		return fmt.Errorf("业务名和表名不能为空")
+	}
+
+	if cfg != nil {
+		if err := s.checkIdentifiers(domain.IdentifierKindField, cfg.Fields...); err != nil {
+			return err
+		}
+	}
+
+	value := ""
+	if cfg != nil {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("序列化表 '%s/%s' 的全文检索配置失败: %w", bizName, tableName, err)
+		}
+		value = string(data)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE biz_searchable_tables SET fts_config = ? WHERE biz_name = ? AND table_name = ?", value, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("更新表 '%s/%s' 的全文检索配置失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return port.ErrTableNotFoundInBiz
+	}
+
 	s.InvalidateCacheForBiz(bizName)
-	return nil // 事务提交已在 defer 中处理
+	return nil
+}
+
+// UpdateTableRankingConfig 保存指定表的相关度得分配置，cfg 为 nil 时恢复默认的加权计分。
+// 表必须已被配置为业务组的可查询表。
+func (s *AdminConfigServiceImpl) UpdateTableRankingConfig(ctx context.Context, bizName, tableName string, cfg *domain.RankingConfig) error {
+	if bizName == "" || tableName == "" {
+		return fmt.Errorf("业务名和表名不能为空")
+	}
+
+	value := ""
+	if cfg != nil {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("序列化表 '%s/%s' 的相关度得分配置失败: %w", bizName, tableName, err)
+		}
+		value = string(data)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE biz_searchable_tables SET ranking_config = ? WHERE biz_name = ? AND table_name = ?", value, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("更新表 '%s/%s' 的相关度得分配置失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return port.ErrTableNotFoundInBiz
+	}
+
+	s.InvalidateCacheForBiz(bizName)
+	return nil
+}
+
+// UpdateTableHierarchyConfig 保存指定表的层级关系配置，cfg 为 nil 时清除。
+// 表必须已被配置为业务组的可查询表。
+func (s *AdminConfigServiceImpl) UpdateTableHierarchyConfig(ctx context.Context, bizName, tableName string, cfg *domain.HierarchyConfig) error {
+	if bizName == "" || tableName == "" {
+		return fmt.Errorf("业务名和表名不能为空")
+	}
+
+	if cfg != nil {
+		fields := []string{cfg.KeyField, cfg.ParentField}
+		if cfg.SortField != "" {
+			fields = append(fields, cfg.SortField)
+		}
+		if err := s.checkIdentifiers(domain.IdentifierKindField, fields...); err != nil {
+			return err
+		}
+	}
+
+	value := ""
+	if cfg != nil {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("序列化表 '%s/%s' 的层级关系配置失败: %w", bizName, tableName, err)
+		}
+		value = string(data)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE biz_searchable_tables SET hierarchy_config = ? WHERE biz_name = ? AND table_name = ?", value, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("更新表 '%s/%s' 的层级关系配置失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return port.ErrTableNotFoundInBiz
+	}
+
+	s.InvalidateCacheForBiz(bizName)
+	return nil
+}
+
+// UpdateTableSQLViewConfig 保存指定表的 SQL 视图定义，cfg 为 nil 时清除 (表恢复为普通的物理表)。
+// 表必须已被配置为业务组的可查询表；定义的合法性由调用方在保存前校验
+func (s *AdminConfigServiceImpl) UpdateTableSQLViewConfig(ctx context.Context, bizName, tableName string, cfg *domain.SQLViewConfig) error {
+	if bizName == "" || tableName == "" {
+		return fmt.Errorf("业务名和表名不能为空")
+	}
+
+	value := ""
+	if cfg != nil {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("序列化表 '%s/%s' 的 SQL 视图定义失败: %w", bizName, tableName, err)
+		}
+		value = string(data)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE biz_searchable_tables SET sql_view_config = ? WHERE biz_name = ? AND table_name = ?", value, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("更新表 '%s/%s' 的 SQL 视图定义失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return port.ErrTableNotFoundInBiz
+	}
+
+	s.InvalidateCacheForBiz(bizName)
+	return nil
 }
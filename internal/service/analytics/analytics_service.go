@@ -0,0 +1,453 @@
+// Package analytics file: internal/service/analytics/analytics_service.go
+package analytics
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	mrand "math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// saltSettingKey 是检索词哈希所用盐值在 global_settings 中的键，首次启动时随机生成
+	saltSettingKey = "usage_analytics_term_salt"
+	// maxTermLength 是参与统计的检索词的最大长度 (字符)，更长的值多半是粘贴的整段文本
+	maxTermLength = 100
+	dayLayout     = "2006-01-02"
+)
+
+// Options 定义检索统计的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TermSampleRate 是记录检索词的抽样比例 (0, 1]，为 0 时使用 1。检索次数与字段统计不抽样
+	TermSampleRate float64 `mapstructure:"term_sample_rate"`
+	// StoreTerms 为 true 时同时保存检索词原文，否则只保存加盐哈希
+	StoreTerms bool `mapstructure:"store_terms"`
+	// RetentionDays 是统计数据的保留天数，0 表示永久保留
+	RetentionDays int `mapstructure:"retention_days"`
+	// CaptureZeroResults 为 true 时登记带过滤条件的无结果检索 (含检索词原文) 以供管理员复核
+	CaptureZeroResults bool `mapstructure:"capture_zero_results"`
+}
+
+type dayKey struct{ biz, day string }
+
+type fieldKey struct{ biz, day, field string }
+
+type termKey struct{ biz, day, hash string }
+
+type dailyCounts struct{ queries, zeroResults int64 }
+
+type termCounts struct {
+	term              string
+	hits, zeroResults int64
+}
+
+// usageBuffer 是尚未写入数据库的统计
+type usageBuffer struct {
+	daily       map[dayKey]*dailyCounts
+	fields      map[fieldKey]int64
+	terms       map[termKey]*termCounts
+	zeroResults map[string]*zeroResultCapture // 键为检索条件的哈希
+}
+
+func newUsageBuffer() *usageBuffer {
+	return &usageBuffer{
+		daily:       make(map[dayKey]*dailyCounts),
+		fields:      make(map[fieldKey]int64),
+		terms:       make(map[termKey]*termCounts),
+		zeroResults: make(map[string]*zeroResultCapture),
+	}
+}
+
+// Service 统计每个业务组的检索次数、常用检索字段、常见检索词与无结果检索。
+// 统计先在内存中累加，由 Run 定期写入数据库，避免每次检索都写库。
+type Service struct {
+	db   *sql.DB
+	opts Options
+	salt []byte
+	now  func() time.Time
+	rand func() float64
+
+	mu      sync.Mutex
+	pending *usageBuffer
+}
+
+// NewService 创建一个新的检索统计服务实例，并加载或生成检索词哈希的盐值
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("analytics.Service 需要一个有效的数据库连接")
+	}
+	if opts.TermSampleRate < 0 || opts.TermSampleRate > 1 {
+		return nil, fmt.Errorf("term_sample_rate 必须在 0 到 1 之间，当前为 %v", opts.TermSampleRate)
+	}
+	if opts.TermSampleRate == 0 {
+		opts.TermSampleRate = 1
+	}
+	salt, err := loadSalt(db)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		db:      db,
+		opts:    opts,
+		salt:    salt,
+		now:     time.Now,
+		rand:    mrand.Float64,
+		pending: newUsageBuffer(),
+	}, nil
+}
+
+// loadSalt 读取盐值，不存在时生成并保存。盐值保证哈希无法通过常见词表反查
+func loadSalt(db *sql.DB) ([]byte, error) {
+	var encoded string
+	err := db.QueryRow(`SELECT value FROM global_settings WHERE key = ?`, saltSettingKey).Scan(&encoded)
+	if err == nil {
+		return hex.DecodeString(encoded)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("读取检索统计盐值失败: %w", err)
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成检索统计盐值失败: %w", err)
+	}
+	_, err = db.Exec(`INSERT OR IGNORE INTO global_settings (key, value, description) VALUES (?, ?, ?)`,
+		saltSettingKey, hex.EncodeToString(salt), "检索词哈希的盐值，修改后历史检索词统计将无法与新数据合并")
+	if err != nil {
+		return nil, fmt.Errorf("保存检索统计盐值失败: %w", err)
+	}
+	// 并发启动时以先写入者为准
+	if err := db.QueryRow(`SELECT value FROM global_settings WHERE key = ?`, saltSettingKey).Scan(&encoded); err != nil {
+		return nil, fmt.Errorf("读取检索统计盐值失败: %w", err)
+	}
+	return hex.DecodeString(encoded)
+}
+
+// Record 记录一次普通检索。query 为请求中的查询对象，data 为数据源返回的结果，
+// 结果中的 total (或 items 的条数) 为 0 时计为无结果检索；带过滤条件的无结果检索会被登记以供复核。
+func (s *Service) Record(bizName string, query, data map[string]interface{}) {
+	zero := isZeroResult(data)
+	fields, terms := extractFilters(query)
+	sampled := len(terms) > 0 && s.rand() < s.opts.TermSampleRate
+	now := s.now()
+	day := now.UTC().Format(dayLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := s.pending
+
+	dk := dayKey{bizName, day}
+	counts := buf.daily[dk]
+	if counts == nil {
+		counts = &dailyCounts{}
+		buf.daily[dk] = counts
+	}
+	counts.queries++
+	if zero {
+		counts.zeroResults++
+		if s.opts.CaptureZeroResults {
+			s.captureZeroResult(bizName, query, now)
+		}
+	}
+	for _, f := range fields {
+		buf.fields[fieldKey{bizName, day, f}]++
+	}
+	if !sampled {
+		return
+	}
+	for _, term := range terms {
+		tk := termKey{bizName, day, s.hashTerm(term)}
+		tc := buf.terms[tk]
+		if tc == nil {
+			tc = &termCounts{}
+			if s.opts.StoreTerms {
+				tc.term = term
+			}
+			buf.terms[tk] = tc
+		}
+		tc.hits++
+		if zero {
+			tc.zeroResults++
+		}
+	}
+}
+
+// hashTerm 返回检索词的加盐哈希 (HMAC-SHA256 的前 16 个十六进制字符)
+func (s *Service) hashTerm(term string) string {
+	mac := hmac.New(sha256.New, s.salt)
+	mac.Write([]byte(term))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// extractFilters 从查询对象中取出检索字段与检索词。
+// 只有不带运算符的条件 (精确或模糊匹配) 的值被视为检索词，IS NULL 等运算符条件只计字段。
+func extractFilters(query map[string]interface{}) (fields, terms []string) {
+	filters, _ := query["filters"].([]interface{})
+	seenField := make(map[string]bool)
+	seenTerm := make(map[string]bool)
+	for _, f := range filters {
+		filterMap, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field, _ := filterMap["field"].(string)
+		if field == "" {
+			continue
+		}
+		if !seenField[field] {
+			seenField[field] = true
+			fields = append(fields, field)
+		}
+		if op, _ := filterMap["op"].(string); op != "" {
+			continue
+		}
+		value, ok := filterMap["value"].(string)
+		if !ok {
+			continue
+		}
+		term := normalizeTerm(value)
+		if term == "" || utf8.RuneCountInString(term) > maxTermLength || seenTerm[term] {
+			continue
+		}
+		seenTerm[term] = true
+		terms = append(terms, term)
+	}
+	return fields, terms
+}
+
+// normalizeTerm 统一大小写并合并空白，使同一检索词的不同写法计为一项
+func normalizeTerm(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+// isZeroResult 判断检索是否没有结果，无法判断时视为有结果
+func isZeroResult(data map[string]interface{}) bool {
+	switch total := data["total"].(type) {
+	case int:
+		return total == 0
+	case int64:
+		return total == 0
+	case float64: // 经 gRPC (structpb) 传输的数字
+		return total == 0
+	}
+	if items, ok := data["items"].([]interface{}); ok {
+		return len(items) == 0
+	}
+	return false
+}
+
+// Run 定期把内存中的统计写入数据库，ctx 结束时返回。停机前应再调用一次 Flush
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				slog.Error("[Analytics] 写入检索统计失败", "error", err)
+			}
+		}
+	}
+}
+
+// Flush 把内存中累加的统计写入数据库并清理过期数据。写入失败时统计会放回内存，下次重试
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	buf := s.pending
+	s.pending = newUsageBuffer()
+	s.mu.Unlock()
+
+	if err := s.write(ctx, buf); err != nil {
+		s.restore(buf)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) write(ctx context.Context, buf *usageBuffer) error {
+	if len(buf.daily) == 0 && s.opts.RetentionDays <= 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启检索统计事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	for k, c := range buf.daily {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO biz_usage_daily (biz_name, day, queries, zero_results) VALUES (?, ?, ?, ?)
+			ON CONFLICT(biz_name, day) DO UPDATE SET queries = queries + excluded.queries, zero_results = zero_results + excluded.zero_results`,
+			k.biz, k.day, c.queries, c.zeroResults); err != nil {
+			return fmt.Errorf("写入每日检索次数失败: %w", err)
+		}
+	}
+	for k, hits := range buf.fields {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO biz_usage_fields (biz_name, day, field, hits) VALUES (?, ?, ?, ?)
+			ON CONFLICT(biz_name, day, field) DO UPDATE SET hits = hits + excluded.hits`,
+			k.biz, k.day, k.field, hits); err != nil {
+			return fmt.Errorf("写入检索字段统计失败: %w", err)
+		}
+	}
+	for k, c := range buf.terms {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO biz_usage_terms (biz_name, day, term_hash, term, hits, zero_results) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(biz_name, day, term_hash) DO UPDATE SET
+				hits = hits + excluded.hits,
+				zero_results = zero_results + excluded.zero_results,
+				term = CASE WHEN excluded.term != '' THEN excluded.term ELSE term END`,
+			k.biz, k.day, k.hash, c.term, c.hits, c.zeroResults); err != nil {
+			return fmt.Errorf("写入检索词统计失败: %w", err)
+		}
+	}
+	if err := writeZeroResults(ctx, tx, buf.zeroResults); err != nil {
+		return err
+	}
+
+	// 无结果检索是待复核的工作项，不随统计一起过期
+	if s.opts.RetentionDays > 0 {
+		cutoff := s.now().UTC().AddDate(0, 0, -s.opts.RetentionDays).Format(dayLayout)
+		for _, table := range []string{"biz_usage_daily", "biz_usage_fields", "biz_usage_terms"} {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE day < ?`, cutoff); err != nil {
+				return fmt.Errorf("清理过期检索统计失败: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// restore 把写入失败的统计合并回内存
+func (s *Service) restore(buf *usageBuffer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.pending
+	for k, c := range buf.daily {
+		if existing := cur.daily[k]; existing != nil {
+			existing.queries += c.queries
+			existing.zeroResults += c.zeroResults
+		} else {
+			cur.daily[k] = c
+		}
+	}
+	for k, hits := range buf.fields {
+		cur.fields[k] += hits
+	}
+	for k, c := range buf.terms {
+		if existing := cur.terms[k]; existing != nil {
+			existing.hits += c.hits
+			existing.zeroResults += c.zeroResults
+		} else {
+			cur.terms[k] = c
+		}
+	}
+	for k, c := range buf.zeroResults {
+		if existing := cur.zeroResults[k]; existing != nil {
+			existing.occurrences += c.occurrences
+			existing.firstSeen = c.firstSeen
+		} else {
+			cur.zeroResults[k] = c
+		}
+	}
+}
+
+// Report 汇总业务组最近 days 天 (含今天) 的检索统计，各排行最多返回 limit 项。
+// 汇总前会先写入内存中尚未落库的统计。
+func (s *Service) Report(ctx context.Context, bizName string, days, limit int) (*domain.BizUsageReport, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	since := s.now().UTC().AddDate(0, 0, 1-days).Format(dayLayout)
+	report := &domain.BizUsageReport{
+		BizName:         bizName,
+		Since:           since,
+		Daily:           make([]domain.DailyUsage, 0),
+		TopFields:       make([]domain.FieldUsage, 0),
+		TopTerms:        make([]domain.TermUsage, 0),
+		ZeroResultTerms: make([]domain.TermUsage, 0),
+		TermSampleRate:  s.opts.TermSampleRate,
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT day, queries, zero_results FROM biz_usage_daily
+		WHERE biz_name = ? AND day >= ? ORDER BY day`, bizName, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询每日检索次数失败: %w", err)
+	}
+	for rows.Next() {
+		var d domain.DailyUsage
+		if err := rows.Scan(&d.Day, &d.Queries, &d.ZeroResults); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("扫描每日检索次数失败: %w", err)
+		}
+		report.Queries += d.Queries
+		report.ZeroResultQueries += d.ZeroResults
+		report.Daily = append(report.Daily, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT field, SUM(hits) AS total FROM biz_usage_fields
+		WHERE biz_name = ? AND day >= ? GROUP BY field ORDER BY total DESC, field LIMIT ?`, bizName, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询检索字段统计失败: %w", err)
+	}
+	for rows.Next() {
+		var f domain.FieldUsage
+		if err := rows.Scan(&f.Field, &f.Hits); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("扫描检索字段统计失败: %w", err)
+		}
+		report.TopFields = append(report.TopFields, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if report.TopTerms, err = s.topTerms(ctx, bizName, since, "hits", limit); err != nil {
+		return nil, err
+	}
+	if report.ZeroResultTerms, err = s.topTerms(ctx, bizName, since, "zero_results", limit); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// topTerms 按 orderBy 列 (hits 或 zero_results) 返回排名靠前的检索词，只返回该列大于 0 的项
+func (s *Service) topTerms(ctx context.Context, bizName, since, orderBy string, limit int) ([]domain.TermUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT term_hash, MAX(term), SUM(hits), SUM(zero_results) FROM biz_usage_terms
+		WHERE biz_name = ? AND day >= ? GROUP BY term_hash HAVING SUM(`+orderBy+`) > 0
+		ORDER BY SUM(`+orderBy+`) DESC, term_hash LIMIT ?`, bizName, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询检索词统计失败: %w", err)
+	}
+	defer rows.Close()
+
+	terms := make([]domain.TermUsage, 0)
+	for rows.Next() {
+		var t domain.TermUsage
+		if err := rows.Scan(&t.TermHash, &t.Term, &t.Hits, &t.ZeroResults); err != nil {
+			return nil, fmt.Errorf("扫描检索词统计失败: %w", err)
+		}
+		terms = append(terms, t)
+	}
+	return terms, rows.Err()
+}
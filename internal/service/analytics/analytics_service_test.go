@@ -0,0 +1,112 @@
+// file: internal/service/analytics/analytics_service_test.go
+package analytics
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) *Service {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	s, err := NewService(db, opts)
+	require.NoError(t, err)
+	return s
+}
+
+func filterQuery(filters ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(filters))
+	for i, f := range filters {
+		list[i] = f
+	}
+	return map[string]interface{}{"table": "letters", "filters": list}
+}
+
+func TestUsageReport(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, Options{Enabled: true})
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	found := map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1}}, "total": float64(1)}
+	empty := map[string]interface{}{"items": []interface{}{}, "total": int64(0)}
+
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "鲁迅"}), found)
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "  鲁迅 "}), found)
+	s.Record("letters", filterQuery(
+		map[string]interface{}{"field": "sender", "value": "Lu  Xun"},
+		map[string]interface{}{"field": "date", "op": "is_null"},
+	), empty)
+	s.Record("other", filterQuery(map[string]interface{}{"field": "title", "value": "x"}), found)
+	require.NoError(t, s.Flush(ctx))
+
+	// 第二次写入与已落库的数据合并
+	now = now.AddDate(0, 0, 1)
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "lu xun"}), empty)
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "LU XUN"}), found)
+
+	report, err := s.Report(ctx, "letters", 30, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, report.Queries)
+	assert.EqualValues(t, 2, report.ZeroResultQueries)
+	require.Len(t, report.Daily, 2)
+	assert.Equal(t, "2024-05-02", report.Daily[0].Day)
+
+	require.Len(t, report.TopFields, 2)
+	assert.Equal(t, "sender", report.TopFields[0].Field)
+	assert.EqualValues(t, 5, report.TopFields[0].Hits)
+	assert.Equal(t, "date", report.TopFields[1].Field)
+
+	// 检索词只以哈希保存，大小写与空白不同的写法合并为一项
+	require.Len(t, report.TopTerms, 2)
+	assert.Equal(t, s.hashTerm("lu xun"), report.TopTerms[0].TermHash)
+	assert.EqualValues(t, 3, report.TopTerms[0].Hits)
+	assert.Empty(t, report.TopTerms[0].Term)
+	require.Len(t, report.ZeroResultTerms, 1)
+	assert.EqualValues(t, 2, report.ZeroResultTerms[0].ZeroResults)
+
+	// 统计窗口之外的数据不计入
+	report, err = s.Report(ctx, "letters", 1, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, report.Queries)
+}
+
+func TestUsageSamplingAndRetention(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, Options{Enabled: true, TermSampleRate: 0.5, StoreTerms: true, RetentionDays: 7})
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	draws := []float64{0.2, 0.9}
+	s.rand = func() float64 {
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}
+	q := filterQuery(map[string]interface{}{"field": "sender", "value": "鲁迅"})
+	s.Record("letters", q, map[string]interface{}{"total": 1})
+	s.Record("letters", q, map[string]interface{}{"total": 1})
+
+	report, err := s.Report(ctx, "letters", 30, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, report.Queries, "检索次数不抽样")
+	require.Len(t, report.TopTerms, 1)
+	assert.EqualValues(t, 1, report.TopTerms[0].Hits, "只有抽中的检索词被记录")
+	assert.Equal(t, "鲁迅", report.TopTerms[0].Term)
+	assert.Equal(t, 0.5, report.TermSampleRate)
+
+	now = now.AddDate(0, 0, 10)
+	require.NoError(t, s.Flush(ctx))
+	report, err = s.Report(ctx, "letters", 366, 10)
+	require.NoError(t, err)
+	assert.Zero(t, report.Queries, "超过保留天数的统计被清理")
+	assert.Empty(t, report.TopTerms)
+
+	_, err = NewService(s.db, Options{TermSampleRate: 2})
+	assert.Error(t, err)
+}
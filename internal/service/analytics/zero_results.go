@@ -0,0 +1,289 @@
+// Package analytics file: internal/service/analytics/zero_results.go
+package analytics
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 无结果检索的复核状态
+const (
+	ZeroResultOpen     = "open"     // 待复核
+	ZeroResultResolved = "resolved" // 已处理，再次出现时会重新打开
+	ZeroResultIgnored  = "ignored"  // 无需处理，再次出现时保持忽略
+)
+
+const (
+	maxZeroResultTags     = 20
+	defaultZeroResultPage = 50
+)
+
+var (
+	// ErrZeroResultNotFound 表示指定的无结果检索记录不存在
+	ErrZeroResultNotFound = errors.New("无结果检索记录不存在")
+	// ErrInvalidReview 表示复核内容不合法
+	ErrInvalidReview = errors.New("无效的复核内容")
+)
+
+// ZeroResultFilter 是列出无结果检索时的筛选条件，空字段表示不限制
+type ZeroResultFilter struct {
+	BizName string
+	Status  string
+	Tag     string
+	Limit   int
+	Offset  int
+}
+
+// ZeroResultReview 描述对一条无结果检索的复核，nil 字段保持不变
+type ZeroResultReview struct {
+	Status     *string   `json:"status"`
+	Tags       *[]string `json:"tags"`
+	Resolution *string   `json:"resolution"`
+}
+
+type zeroResultCapture struct {
+	biz, table, filtersJSON string
+	occurrences             int64
+	firstSeen, lastSeen     time.Time
+}
+
+// captureZeroResult 在内存中登记一次带过滤条件的无结果检索。调用前必须持有 s.mu
+func (s *Service) captureZeroResult(bizName string, query map[string]interface{}, now time.Time) {
+	filters := searchFilters(query)
+	if len(filters) == 0 {
+		return
+	}
+	table, _ := query["table"].(string)
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(bizName + "\x00" + table + "\x00" + string(filtersJSON)))
+	key := hex.EncodeToString(sum[:])
+
+	capture := s.pending.zeroResults[key]
+	if capture == nil {
+		capture = &zeroResultCapture{biz: bizName, table: table, filtersJSON: string(filtersJSON), firstSeen: now}
+		s.pending.zeroResults[key] = capture
+	}
+	capture.occurrences++
+	capture.lastSeen = now
+}
+
+// searchFilters 把查询对象中的过滤条件整理为统一格式，值去除首尾空白，使同一检索能被合并
+func searchFilters(query map[string]interface{}) []domain.SearchFilter {
+	raw, _ := query["filters"].([]interface{})
+	filters := make([]domain.SearchFilter, 0, len(raw))
+	for _, f := range raw {
+		filterMap, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		filter := domain.SearchFilter{}
+		if filter.Field, _ = filterMap["field"].(string); filter.Field == "" {
+			continue
+		}
+		filter.Op, _ = filterMap["op"].(string)
+		filter.Logic, _ = filterMap["logic"].(string)
+		filter.Fuzzy, _ = filterMap["fuzzy"].(bool)
+		if v, exists := filterMap["value"]; exists && v != nil {
+			filter.Value = strings.TrimSpace(fmt.Sprintf("%v", v))
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+// writeZeroResults 把内存中登记的无结果检索合并到数据库。已解决的检索再次出现说明问题仍在，重新打开
+func writeZeroResults(ctx context.Context, tx *sql.Tx, captures map[string]*zeroResultCapture) error {
+	for key, c := range captures {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO zero_result_searches (biz_name, table_name, filters_hash, filters_json, occurrences, first_seen_at, last_seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(filters_hash) DO UPDATE SET
+				occurrences = occurrences + excluded.occurrences,
+				last_seen_at = excluded.last_seen_at,
+				status = CASE WHEN status = 'resolved' THEN 'open' ELSE status END,
+				resolved_by = CASE WHEN status = 'resolved' THEN NULL ELSE resolved_by END,
+				resolved_at = CASE WHEN status = 'resolved' THEN NULL ELSE resolved_at END`,
+			c.biz, c.table, key, c.filtersJSON, c.occurrences, c.firstSeen, c.lastSeen); err != nil {
+			return fmt.Errorf("写入无结果检索失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListZeroResults 按出现次数倒序列出无结果检索，同时返回符合条件的总数。
+// 列出前会先写入内存中尚未落库的记录。
+func (s *Service) ListZeroResults(ctx context.Context, filter ZeroResultFilter) ([]domain.ZeroResultSearch, int64, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, 0, err
+	}
+	if filter.Status != "" && !validZeroResultStatus(filter.Status) {
+		return nil, 0, fmt.Errorf("%w: 未知的状态 '%s'", ErrInvalidReview, filter.Status)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultZeroResultPage
+	}
+
+	where := make([]string, 0, 3)
+	args := make([]interface{}, 0, 5)
+	if filter.BizName != "" {
+		where = append(where, "biz_name = ?")
+		args = append(args, filter.BizName)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(zero_result_searches.tags) WHERE json_each.value = ?)")
+		args = append(args, filter.Tag)
+	}
+	clause := ""
+	if len(where) > 0 {
+		clause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM zero_result_searches`+clause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计无结果检索失败: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, biz_name, table_name, filters_json, occurrences, first_seen_at, last_seen_at, status, tags, resolution, resolved_by, resolved_at
+		FROM zero_result_searches`+clause+` ORDER BY occurrences DESC, id DESC LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询无结果检索失败: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.ZeroResultSearch, 0)
+	for rows.Next() {
+		item, err := scanZeroResult(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, *item)
+	}
+	return items, total, rows.Err()
+}
+
+// GetZeroResult 返回一条无结果检索记录
+func (s *Service) GetZeroResult(ctx context.Context, id int64) (*domain.ZeroResultSearch, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, biz_name, table_name, filters_json, occurrences, first_seen_at, last_seen_at, status, tags, resolution, resolved_by, resolved_at
+		FROM zero_result_searches WHERE id = ?`, id)
+	item, err := scanZeroResult(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrZeroResultNotFound, id)
+	}
+	return item, err
+}
+
+func scanZeroResult(row interface{ Scan(...interface{}) error }) (*domain.ZeroResultSearch, error) {
+	var item domain.ZeroResultSearch
+	var filtersJSON, tagsJSON string
+	var resolvedBy sql.NullInt64
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&item.ID, &item.BizName, &item.TableName, &filtersJSON, &item.Occurrences, &item.FirstSeenAt, &item.LastSeenAt,
+		&item.Status, &tagsJSON, &item.Resolution, &resolvedBy, &resolvedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("扫描无结果检索失败: %w", err)
+	}
+	if err := json.Unmarshal([]byte(filtersJSON), &item.Filters); err != nil {
+		return nil, fmt.Errorf("解析无结果检索 %d 的过滤条件失败: %w", item.ID, err)
+	}
+	if err := json.Unmarshal([]byte(tagsJSON), &item.Tags); err != nil {
+		return nil, fmt.Errorf("解析无结果检索 %d 的标签失败: %w", item.ID, err)
+	}
+	if resolvedBy.Valid {
+		item.ResolvedBy = &resolvedBy.Int64
+	}
+	if resolvedAt.Valid {
+		item.ResolvedAt = &resolvedAt.Time
+	}
+	return &item, nil
+}
+
+// ReviewZeroResult 修改一条无结果检索的标签、状态与处理说明。
+// 标记为已解决时记录复核人与时间，重新打开时清除这两项。
+func (s *Service) ReviewZeroResult(ctx context.Context, id int64, review ZeroResultReview, reviewerID int64) (*domain.ZeroResultSearch, error) {
+	sets := make([]string, 0, 5)
+	args := make([]interface{}, 0, 6)
+
+	if review.Tags != nil {
+		tags, err := normalizeTags(*review.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return nil, fmt.Errorf("序列化标签失败: %w", err)
+		}
+		sets = append(sets, "tags = ?")
+		args = append(args, string(tagsJSON))
+	}
+	if review.Resolution != nil {
+		sets = append(sets, "resolution = ?")
+		args = append(args, strings.TrimSpace(*review.Resolution))
+	}
+	if review.Status != nil {
+		status := *review.Status
+		if !validZeroResultStatus(status) {
+			return nil, fmt.Errorf("%w: 未知的状态 '%s'，可选值为 %s、%s 或 %s", ErrInvalidReview, status, ZeroResultOpen, ZeroResultResolved, ZeroResultIgnored)
+		}
+		sets = append(sets, "status = ?")
+		args = append(args, status)
+		if status == ZeroResultOpen {
+			sets = append(sets, "resolved_by = NULL", "resolved_at = NULL")
+		} else {
+			sets = append(sets, "resolved_by = ?", "resolved_at = ?")
+			args = append(args, reviewerID, s.now())
+		}
+	}
+
+	if len(sets) > 0 {
+		res, err := s.db.ExecContext(ctx, `UPDATE zero_result_searches SET `+strings.Join(sets, ", ")+` WHERE id = ?`, append(args, id)...)
+		if err != nil {
+			return nil, fmt.Errorf("更新无结果检索 %d 失败: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil, fmt.Errorf("%w: %d", ErrZeroResultNotFound, id)
+		}
+	}
+	return s.GetZeroResult(ctx, id)
+}
+
+func validZeroResultStatus(status string) bool {
+	return status == ZeroResultOpen || status == ZeroResultResolved || status == ZeroResultIgnored
+}
+
+// normalizeTags 去除空白与重复的标签，保持原有顺序
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	if len(cleaned) > maxZeroResultTags {
+		return nil, fmt.Errorf("%w: 标签最多 %d 个", ErrInvalidReview, maxZeroResultTags)
+	}
+	return cleaned, nil
+}
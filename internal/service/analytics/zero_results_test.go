@@ -0,0 +1,82 @@
+// file: internal/service/analytics/zero_results_test.go
+package analytics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroResultReviewWorkflow(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, Options{Enabled: true, CaptureZeroResults: true})
+	empty := map[string]interface{}{"items": []interface{}{}, "total": float64(0)}
+
+	q := filterQuery(map[string]interface{}{"field": "sender", "value": "周树人 "})
+	s.Record("letters", q, empty)
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "周树人"}), empty)
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "place", "value": "绍兴"}), empty)
+	// 有结果的检索与不带过滤条件的检索不登记
+	s.Record("letters", q, map[string]interface{}{"total": float64(3)})
+	s.Record("letters", map[string]interface{}{"table": "letters"}, empty)
+
+	items, total, err := s.ListZeroResults(ctx, ZeroResultFilter{BizName: "letters"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, items, 2)
+	top := items[0]
+	assert.EqualValues(t, 2, top.Occurrences, "首尾空白不同的同一检索合并为一条")
+	assert.Equal(t, "letters", top.TableName)
+	require.Len(t, top.Filters, 1)
+	assert.Equal(t, "周树人", top.Filters[0].Value)
+	assert.Equal(t, ZeroResultOpen, top.Status)
+
+	// 打标签并标记为已解决
+	tags := []string{"synonym", " synonym", ""}
+	status := ZeroResultResolved
+	resolution := "已将“周树人”添加为“鲁迅”的同义词"
+	reviewed, err := s.ReviewZeroResult(ctx, top.ID, ZeroResultReview{Tags: &tags, Status: &status, Resolution: &resolution}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"synonym"}, reviewed.Tags)
+	assert.Equal(t, ZeroResultResolved, reviewed.Status)
+	require.NotNil(t, reviewed.ResolvedBy)
+	assert.EqualValues(t, 7, *reviewed.ResolvedBy)
+
+	items, _, err = s.ListZeroResults(ctx, ZeroResultFilter{Tag: "synonym"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	items, _, err = s.ListZeroResults(ctx, ZeroResultFilter{Status: ZeroResultOpen})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "place", items[0].Filters[0].Field)
+
+	// 已解决的检索再次无结果时重新打开，标签与说明保留
+	s.Record("letters", q, empty)
+	reopened, err := s.GetZeroResult(ctx, top.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ZeroResultResolved, reopened.Status, "尚未写入数据库")
+	require.NoError(t, s.Flush(ctx))
+	reopened, err = s.GetZeroResult(ctx, top.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ZeroResultOpen, reopened.Status)
+	assert.EqualValues(t, 3, reopened.Occurrences)
+	assert.Nil(t, reopened.ResolvedBy)
+	assert.Equal(t, resolution, reopened.Resolution)
+
+	bad := "done"
+	_, err = s.ReviewZeroResult(ctx, top.ID, ZeroResultReview{Status: &bad}, 7)
+	assert.ErrorIs(t, err, ErrInvalidReview)
+	_, err = s.ReviewZeroResult(ctx, 999, ZeroResultReview{Status: &status}, 7)
+	assert.ErrorIs(t, err, ErrZeroResultNotFound)
+}
+
+func TestZeroResultCaptureDisabled(t *testing.T) {
+	s := newTestService(t, Options{Enabled: true})
+	s.Record("letters", filterQuery(map[string]interface{}{"field": "sender", "value": "x"}), map[string]interface{}{"total": 0})
+	items, total, err := s.ListZeroResults(context.Background(), ZeroResultFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,427 @@
+// Package annotations file: internal/service/annotations/annotations_service.go
+package annotations
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxBodyRunes      = 5000
+	defaultMaxPendingPerUser = 200
+	maxSuggestedValueBytes   = 16 * 1024
+	defaultPageSize          = 50
+	maxPageSize              = 500
+)
+
+var (
+	// ErrInvalidAnnotation 表示批注的参数不合法
+	ErrInvalidAnnotation = errors.New("无效的批注")
+	// ErrNotFound 表示批注或被批注的记录不存在
+	ErrNotFound = errors.New("批注不存在")
+	// ErrAlreadyModerated 表示批注已被审核，不能再撤回
+	ErrAlreadyModerated = errors.New("批注已被审核")
+	// ErrQuotaExceeded 表示用户待审核的批注数已达上限
+	ErrQuotaExceeded = errors.New("待审核的批注数已达上限")
+	// ErrNotApplicable 表示勘误无法写回数据源，如批注不是勘误、表不允许更新或记录无法唯一定位
+	ErrNotApplicable = errors.New("勘误无法写回")
+	// ErrStale 表示提交勘误后记录已被修改，需重新核对
+	ErrStale = errors.New("勘误已过时")
+)
+
+// Options 定义批注的配置
+type Options struct {
+	// Enabled 为 false 时不提供批注功能
+	Enabled bool `mapstructure:"enabled"`
+	// AutoApproveAdmins 为 true 时管理员提交的批注直接通过审核
+	AutoApproveAdmins bool `mapstructure:"auto_approve_admins"`
+	// MaxBodyRunes 是批注正文的字符数上限，默认为 5000
+	MaxBodyRunes int `mapstructure:"max_body_runes"`
+	// MaxPendingPerUser 是每个用户同时待审核的批注数上限，默认为 200
+	MaxPendingPerUser int `mapstructure:"max_pending_per_user"`
+}
+
+// Service 管理用户附加在记录上的批注 (文字批注与针对字段的勘误)。
+// 批注保存在平台库中，不修改数据源的原始数据；新批注处于待审核状态，
+// 经管理员通过后才会随记录一并返回。勘误还可由管理员比对差异后写回数据源，
+// 写回经由数据源的 Mutate 执行，并在 operation_log 中留下写入前后的值。
+type Service struct {
+	db       *sql.DB
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+	opts     Options
+}
+
+// NewService 创建一个新的批注服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("annotations.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("annotations.Service 需要有效的配置服务")
+	}
+	if opts.MaxBodyRunes < 0 || opts.MaxPendingPerUser < 0 {
+		return nil, errors.New("批注的配置项不能为负数")
+	}
+	if opts.MaxBodyRunes == 0 {
+		opts.MaxBodyRunes = defaultMaxBodyRunes
+	}
+	if opts.MaxPendingPerUser == 0 {
+		opts.MaxPendingPerUser = defaultMaxPendingPerUser
+	}
+	return &Service{db: db, config: config, registry: registry, opts: opts}, nil
+}
+
+// Create 为一条记录提交批注。isAdmin 为 true 且开启了 AutoApproveAdmins 时批注直接通过审核
+func (s *Service) Create(ctx context.Context, authorID int64, isAdmin bool, a domain.Annotation) (*domain.Annotation, error) {
+	a.BizName, a.TableName, a.RecordID, a.Lib = strings.TrimSpace(a.BizName), strings.TrimSpace(a.TableName), strings.TrimSpace(a.RecordID), strings.TrimSpace(a.Lib)
+	a.Body, a.Field = strings.TrimSpace(a.Body), strings.TrimSpace(a.Field)
+	if a.BizName == "" || a.TableName == "" || a.RecordID == "" {
+		return nil, fmt.Errorf("%w: 必须提供 biz_name、table_name 与 record_id", ErrInvalidAnnotation)
+	}
+	if n := utf8.RuneCountInString(a.Body); n > s.opts.MaxBodyRunes {
+		return nil, fmt.Errorf("%w: 正文为 %d 个字符，上限为 %d 个字符", ErrInvalidAnnotation, n, s.opts.MaxBodyRunes)
+	}
+
+	var suggested sql.NullString
+	switch a.Kind {
+	case domain.AnnotationKindComment:
+		if a.Body == "" {
+			return nil, fmt.Errorf("%w: 批注正文不能为空", ErrInvalidAnnotation)
+		}
+		if a.Field != "" || len(a.SuggestedValue) > 0 {
+			return nil, fmt.Errorf("%w: 只有勘误可以指定 field 与 suggested_value", ErrInvalidAnnotation)
+		}
+	case domain.AnnotationKindCorrection:
+		if a.Field == "" || len(a.SuggestedValue) == 0 {
+			return nil, fmt.Errorf("%w: 勘误必须提供 field 与 suggested_value", ErrInvalidAnnotation)
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, a.SuggestedValue); err != nil {
+			return nil, fmt.Errorf("%w: suggested_value 不是合法的 JSON", ErrInvalidAnnotation)
+		}
+		if buf.Len() > maxSuggestedValueBytes {
+			return nil, fmt.Errorf("%w: suggested_value 为 %d 字节，上限为 %d 字节", ErrInvalidAnnotation, buf.Len(), maxSuggestedValueBytes)
+		}
+		if err := s.checkField(ctx, a.BizName, a.TableName, a.Field); err != nil {
+			return nil, err
+		}
+		suggested = sql.NullString{String: buf.String(), Valid: true}
+	default:
+		return nil, fmt.Errorf("%w: kind 只能为 '%s' 或 '%s'", ErrInvalidAnnotation, domain.AnnotationKindComment, domain.AnnotationKindCorrection)
+	}
+
+	record, err := s.lookupRecord(ctx, a.BizName, a.TableName, a.RecordID, a.Lib)
+	if err != nil {
+		return nil, err
+	}
+	lib := a.Lib
+	if lib == "" {
+		lib, _ = record.row["__lib"].(string)
+	}
+	var original sql.NullString
+	if a.Kind == domain.AnnotationKindCorrection {
+		value, err := json.Marshal(record.row[a.Field])
+		if err != nil {
+			return nil, fmt.Errorf("序列化字段 '%s' 的原值失败: %w", a.Field, err)
+		}
+		original = sql.NullString{String: string(value), Valid: true}
+	}
+
+	now := time.Now().UTC()
+	status := domain.AnnotationStatusPending
+	var moderatorID sql.NullInt64
+	var moderatedAt sql.NullTime
+	if isAdmin && s.opts.AutoApproveAdmins {
+		status = domain.AnnotationStatusApproved
+		moderatorID = sql.NullInt64{Int64: authorID, Valid: true}
+		moderatedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启批注事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	if status == domain.AnnotationStatusPending {
+		var pending int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_annotations WHERE author_id = ? AND status = ?`,
+			authorID, domain.AnnotationStatusPending).Scan(&pending); err != nil {
+			return nil, fmt.Errorf("统计批注失败: %w", err)
+		}
+		if pending >= s.opts.MaxPendingPerUser {
+			return nil, fmt.Errorf("%w: 最多同时有 %d 条待审核的批注", ErrQuotaExceeded, s.opts.MaxPendingPerUser)
+		}
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO record_annotations
+		(biz_name, table_name, record_id, lib, kind, body, field_name, suggested_value, original_value, status, author_id, moderator_id, moderated_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.BizName, a.TableName, a.RecordID, lib, a.Kind, a.Body, a.Field, suggested, original, status, authorID, moderatorID, moderatedAt, now)
+	if err != nil {
+		return nil, fmt.Errorf("写入批注失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交批注失败: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+// checkField 确认勘误的字段在表中配置为可返回，避免借勘误探测隐藏字段
+func (s *Service) checkField(ctx context.Context, bizName, tableName, field string) error {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return err
+	}
+	if bizConfig == nil {
+		return port.ErrBizNotFound
+	}
+	tableConfig, ok := bizConfig.Tables[tableName]
+	if !ok {
+		return port.ErrTableNotFoundInBiz
+	}
+	if setting, ok := tableConfig.Fields[field]; !ok || !setting.IsReturnable {
+		return fmt.Errorf("%w: 字段 '%s' 不存在或不可返回", ErrInvalidAnnotation, field)
+	}
+	return nil
+}
+
+// recordLookup 是按主键读取到的记录，matches 是主键在业务组各库中的命中数
+type recordLookup struct {
+	row     map[string]interface{}
+	matches int
+}
+
+// lookupRecord 按主键读取记录，确认记录存在且当前可见
+func (s *Service) lookupRecord(ctx context.Context, bizName, tableName, recordID, lib string) (*recordLookup, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	result, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+			"lib":             lib,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	row, _ := result.Data["record"].(map[string]interface{})
+	if row == nil {
+		return nil, fmt.Errorf("%w: 记录 '%s' 在表 '%s' 中不存在", ErrNotFound, recordID, tableName)
+	}
+	lookup := &recordLookup{row: row, matches: 1}
+	// 经 gRPC 传输后数字均为 float64
+	switch n := result.Data["matches"].(type) {
+	case int:
+		lookup.matches = n
+	case float64:
+		lookup.matches = int(n)
+	}
+	return lookup, nil
+}
+
+const selectColumns = `SELECT a.id, a.biz_name, a.table_name, a.record_id, a.lib, a.kind, a.body, a.field_name, a.suggested_value,
+	a.original_value, a.status, a.author_id, COALESCE(u.username, ''), a.moderator_id, a.moderation_note, a.moderated_at,
+	a.applied_at, a.applied_by, a.created_at
+	FROM record_annotations a LEFT JOIN _user u ON u.id = a.author_id`
+
+func scanAnnotation(scanner interface{ Scan(...any) error }) (*domain.Annotation, error) {
+	var a domain.Annotation
+	var suggested, original sql.NullString
+	var moderatorID, appliedBy sql.NullInt64
+	var moderatedAt, appliedAt sql.NullTime
+	if err := scanner.Scan(&a.ID, &a.BizName, &a.TableName, &a.RecordID, &a.Lib, &a.Kind, &a.Body, &a.Field, &suggested,
+		&original, &a.Status, &a.AuthorID, &a.AuthorName, &moderatorID, &a.ModerationNote, &moderatedAt,
+		&appliedAt, &appliedBy, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	if suggested.Valid {
+		a.SuggestedValue = json.RawMessage(suggested.String)
+	}
+	if original.Valid {
+		a.OriginalValue = json.RawMessage(original.String)
+	}
+	if appliedBy.Valid {
+		a.AppliedBy = &appliedBy.Int64
+	}
+	if appliedAt.Valid {
+		a.AppliedAt = &appliedAt.Time
+	}
+	if moderatorID.Valid {
+		a.ModeratorID = &moderatorID.Int64
+	}
+	if moderatedAt.Valid {
+		a.ModeratedAt = &moderatedAt.Time
+	}
+	return &a, nil
+}
+
+// Get 返回一条批注，不存在时返回 ErrNotFound
+func (s *Service) Get(ctx context.Context, id int64) (*domain.Annotation, error) {
+	a, err := scanAnnotation(s.db.QueryRowContext(ctx, selectColumns+` WHERE a.id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: ID %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询批注失败: %w", err)
+	}
+	return a, nil
+}
+
+// List 按条件分页返回批注与符合条件的总数，按提交时间倒序
+func (s *Service) List(ctx context.Context, filter domain.AnnotationFilter) ([]domain.Annotation, int, error) {
+	if filter.Status != "" && !validStatus(filter.Status) {
+		return nil, 0, fmt.Errorf("%w: 未知的审核状态 '%s'", ErrInvalidAnnotation, filter.Status)
+	}
+	var conds []string
+	var args []any
+	for _, c := range []struct {
+		column, value string
+	}{
+		{"a.biz_name", filter.BizName},
+		{"a.table_name", filter.TableName},
+		{"a.record_id", filter.RecordID},
+		{"a.status", filter.Status},
+	} {
+		if c.value != "" {
+			conds = append(conds, c.column+" = ?")
+			args = append(args, c.value)
+		}
+	}
+	if filter.AuthorID > 0 {
+		conds = append(conds, "a.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_annotations a`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计批注失败: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := s.db.QueryContext(ctx, selectColumns+where+` ORDER BY a.created_at DESC, a.id DESC LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询批注失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.Annotation{}
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("扫描批注失败: %w", err)
+		}
+		out = append(out, *a)
+	}
+	return out, total, rows.Err()
+}
+
+// Approved 返回一条记录上已通过审核的批注，按提交时间正序。
+// 未指定库的批注适用于该主键在任意库中的记录
+func (s *Service) Approved(ctx context.Context, bizName, tableName, recordID, lib string) ([]domain.Annotation, error) {
+	rows, err := s.db.QueryContext(ctx, selectColumns+`
+		WHERE a.biz_name = ? AND a.table_name = ? AND a.record_id = ? AND a.status = ? AND (a.lib = '' OR a.lib = ?)
+		ORDER BY a.created_at, a.id`, bizName, tableName, recordID, domain.AnnotationStatusApproved, lib)
+	if err != nil {
+		return nil, fmt.Errorf("查询批注失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.Annotation{}
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描批注失败: %w", err)
+		}
+		out = append(out, *a)
+	}
+	return out, rows.Err()
+}
+
+// Withdraw 撤回用户自己提交且尚未审核的批注
+func (s *Service) Withdraw(ctx context.Context, authorID, id int64) error {
+	var status string
+	err := s.db.QueryRowContext(ctx, `SELECT status FROM record_annotations WHERE id = ? AND author_id = ?`, id, authorID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: ID %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("查询批注失败: %w", err)
+	}
+	if status != domain.AnnotationStatusPending {
+		return fmt.Errorf("%w: 当前状态为 '%s'", ErrAlreadyModerated, status)
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM record_annotations WHERE id = ? AND author_id = ? AND status = ?`,
+		id, authorID, domain.AnnotationStatusPending)
+	if err != nil {
+		return fmt.Errorf("删除批注失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// 查询与删除之间被审核
+		return ErrAlreadyModerated
+	}
+	return nil
+}
+
+// Review 审核一条批注，status 为 approved 或 rejected。已审核的批注可以重新审核，
+// 例如撤下此前通过的勘误；已写回数据源的勘误除外
+func (s *Service) Review(ctx context.Context, moderatorID, id int64, status, note string) (*domain.Annotation, error) {
+	if status != domain.AnnotationStatusApproved && status != domain.AnnotationStatusRejected {
+		return nil, fmt.Errorf("%w: 审核结果只能为 '%s' 或 '%s'", ErrInvalidAnnotation, domain.AnnotationStatusApproved, domain.AnnotationStatusRejected)
+	}
+	note = strings.TrimSpace(note)
+	if n := utf8.RuneCountInString(note); n > s.opts.MaxBodyRunes {
+		return nil, fmt.Errorf("%w: 审核意见为 %d 个字符，上限为 %d 个字符", ErrInvalidAnnotation, n, s.opts.MaxBodyRunes)
+	}
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.AppliedAt != nil {
+		// 已写回的勘误改变了源数据，不能再改为驳回或待审核
+		return nil, fmt.Errorf("%w: 勘误已写回数据源", ErrAlreadyModerated)
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE record_annotations SET status = ?, moderator_id = ?, moderation_note = ?, moderated_at = ? WHERE id = ?`,
+		status, moderatorID, note, time.Now().UTC(), id); err != nil {
+		return nil, fmt.Errorf("更新批注失败: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+func validStatus(status string) bool {
+	switch status {
+	case domain.AnnotationStatusPending, domain.AnnotationStatusApproved, domain.AnnotationStatusRejected:
+		return true
+	}
+	return false
+}
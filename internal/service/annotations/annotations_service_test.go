@@ -0,0 +1,257 @@
+// file: internal/service/annotations/annotations_service_test.go
+package annotations
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordSource 只支持按主键读取与更新 books 表中的记录，记录都位于 main 库
+type recordSource struct {
+	titles  map[string]string
+	matches int
+	updates []port.MutateRequest
+}
+
+func (d *recordSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	if table, _ := req.Query["table"].(string); table != "books" {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	id, _ := req.Query["id"].(string)
+	var record interface{}
+	if title, ok := d.titles[id]; ok {
+		record = map[string]interface{}{"id": id, "title": title, "__lib": "main"}
+	}
+	matches := d.matches
+	if matches == 0 {
+		matches = 1
+	}
+	return &port.QueryResult{Data: map[string]interface{}{"record": record, "matches": matches}}, nil
+}
+
+func (d *recordSource) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if req.Operation != "update" {
+		return nil, errors.New("not implemented")
+	}
+	d.updates = append(d.updates, req)
+	filter := req.Payload["filters"].([]interface{})[0].(map[string]interface{})
+	id := filter["value"].(string)
+	d.titles[id] = req.Payload["data"].(map[string]interface{})["title"].(string)
+	return &port.MutateResult{Data: map[string]interface{}{"rows_affected": int64(1)}}, nil
+}
+
+func (d *recordSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	return &port.SchemaResult{Tables: map[string][]port.FieldDescription{
+		"books": {{Name: "id", IsPrimary: true}, {Name: "title"}},
+	}}, nil
+}
+
+func (d *recordSource) HealthCheck(context.Context) error { return nil }
+func (d *recordSource) Type() string                      { return "stub" }
+
+func newTestService(t *testing.T, opts Options) *Service {
+	t.Helper()
+	svc, _ := newTestEnv(t, opts)
+	return svc
+}
+
+func newTestEnv(t *testing.T, opts Options) (*Service, *recordSource) {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'user'), (2, 'root', 'x', 'admin')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('library', 'books')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "secret", IsSearchable: false, IsReturnable: false},
+	}))
+	source := &recordSource{titles: map[string]string{"1": "老人与海", "2": "战地钟声"}}
+	svc, err := NewService(db, config, map[string]port.DataSource{"library": source}, opts)
+	require.NoError(t, err)
+	return svc, source
+}
+
+func TestAnnotationModeration(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{})
+
+	comment, err := svc.Create(ctx, 1, false, domain.Annotation{BizName: "library", TableName: "books", RecordID: "1", Kind: domain.AnnotationKindComment, Body: " 此版本缺第三章 "})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnnotationStatusPending, comment.Status)
+	assert.Equal(t, "此版本缺第三章", comment.Body)
+	assert.Equal(t, "main", comment.Lib, "未指定库时记录所在的库")
+	assert.Equal(t, "alice", comment.AuthorName)
+
+	correction, err := svc.Create(ctx, 1, false, domain.Annotation{BizName: "library", TableName: "books", RecordID: "1", Kind: domain.AnnotationKindCorrection,
+		Field: "title", SuggestedValue: json.RawMessage(` "老人与海（修订版）" `), Body: "书名有误"})
+	require.NoError(t, err)
+	assert.Equal(t, `"老人与海（修订版）"`, string(correction.SuggestedValue))
+	assert.Equal(t, `"老人与海"`, string(correction.OriginalValue), "提交时记录字段的原值")
+
+	approved, err := svc.Approved(ctx, "library", "books", "1", "main")
+	require.NoError(t, err)
+	assert.Empty(t, approved, "待审核的批注不随记录返回")
+
+	reviewed, err := svc.Review(ctx, 2, correction.ID, domain.AnnotationStatusApproved, "已核对原书")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnnotationStatusApproved, reviewed.Status)
+	require.NotNil(t, reviewed.ModeratorID)
+	assert.EqualValues(t, 2, *reviewed.ModeratorID)
+	assert.NotNil(t, reviewed.ModeratedAt)
+
+	approved, err = svc.Approved(ctx, "library", "books", "1", "main")
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	assert.Equal(t, correction.ID, approved[0].ID)
+	approved, err = svc.Approved(ctx, "library", "books", "1", "other")
+	require.NoError(t, err)
+	assert.Empty(t, approved, "其他库中的同名主键不显示该批注")
+
+	items, total, err := svc.List(ctx, domain.AnnotationFilter{Status: domain.AnnotationStatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, comment.ID, items[0].ID)
+	_, _, err = svc.List(ctx, domain.AnnotationFilter{Status: "deleted"})
+	assert.ErrorIs(t, err, ErrInvalidAnnotation)
+
+	assert.ErrorIs(t, svc.Withdraw(ctx, 1, correction.ID), ErrAlreadyModerated, "已审核的批注不能撤回")
+	assert.ErrorIs(t, svc.Withdraw(ctx, 2, comment.ID), ErrNotFound, "不能撤回他人的批注")
+	require.NoError(t, svc.Withdraw(ctx, 1, comment.ID))
+	_, err = svc.Get(ctx, comment.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.Review(ctx, 2, correction.ID, domain.AnnotationStatusPending, "")
+	assert.ErrorIs(t, err, ErrInvalidAnnotation)
+	_, err = svc.Review(ctx, 2, 999, domain.AnnotationStatusRejected, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAnnotationValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{MaxBodyRunes: 10, MaxPendingPerUser: 1})
+	base := domain.Annotation{BizName: "library", TableName: "books", RecordID: "1"}
+
+	for name, tc := range map[string]struct {
+		mutate func(a *domain.Annotation)
+		want   error
+	}{
+		"未知类型": {func(a *domain.Annotation) { a.Kind = "vote" }, ErrInvalidAnnotation},
+		"空批注":  {func(a *domain.Annotation) { a.Kind = domain.AnnotationKindComment }, ErrInvalidAnnotation},
+		"正文过长": {func(a *domain.Annotation) {
+			a.Kind, a.Body = domain.AnnotationKindComment, "一二三四五六七八九十十一"
+		}, ErrInvalidAnnotation},
+		"批注带字段": {func(a *domain.Annotation) { a.Kind, a.Body, a.Field = domain.AnnotationKindComment, "x", "title" }, ErrInvalidAnnotation},
+		"勘误缺值":  {func(a *domain.Annotation) { a.Kind, a.Field = domain.AnnotationKindCorrection, "title" }, ErrInvalidAnnotation},
+		"勘误非法JSON": {func(a *domain.Annotation) {
+			a.Kind, a.Field, a.SuggestedValue = domain.AnnotationKindCorrection, "title", json.RawMessage(`{`)
+		}, ErrInvalidAnnotation},
+		"勘误隐藏字段": {func(a *domain.Annotation) {
+			a.Kind, a.Field, a.SuggestedValue = domain.AnnotationKindCorrection, "secret", json.RawMessage(`1`)
+		}, ErrInvalidAnnotation},
+		"记录不存在":  {func(a *domain.Annotation) { a.Kind, a.Body, a.RecordID = domain.AnnotationKindComment, "x", "404" }, ErrNotFound},
+		"业务组不存在": {func(a *domain.Annotation) { a.Kind, a.Body, a.BizName = domain.AnnotationKindComment, "x", "archive" }, port.ErrBizNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := base
+			tc.mutate(&a)
+			_, err := svc.Create(ctx, 1, false, a)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+
+	a := base
+	a.Kind, a.Body = domain.AnnotationKindComment, "第一条"
+	_, err := svc.Create(ctx, 1, false, a)
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, 1, false, a)
+	assert.ErrorIs(t, err, ErrQuotaExceeded, "待审核的批注数已达上限")
+}
+
+func TestAnnotationAutoApproveAdmins(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{AutoApproveAdmins: true})
+	a := domain.Annotation{BizName: "library", TableName: "books", RecordID: "2", Lib: "main", Kind: domain.AnnotationKindComment, Body: "馆藏说明"}
+
+	byAdmin, err := svc.Create(ctx, 2, true, a)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnnotationStatusApproved, byAdmin.Status)
+	byUser, err := svc.Create(ctx, 1, false, a)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnnotationStatusPending, byUser.Status)
+}
+
+func TestApplyCorrection(t *testing.T) {
+	ctx := context.Background()
+	svc, source := newTestEnv(t, Options{})
+	propose := func(recordID, value string) *domain.Annotation {
+		a, err := svc.Create(ctx, 1, false, domain.Annotation{BizName: "library", TableName: "books", RecordID: recordID,
+			Kind: domain.AnnotationKindCorrection, Field: "title", SuggestedValue: json.RawMessage(value)})
+		require.NoError(t, err)
+		return a
+	}
+
+	correction := propose("1", `"老人与海（修订版）"`)
+	diff, err := svc.Diff(ctx, correction.ID)
+	require.NoError(t, err)
+	assert.False(t, diff.Applicable, "表未开放更新时不能写回")
+	_, err = svc.Apply(ctx, 2, correction.ID, "")
+	assert.ErrorIs(t, err, ErrNotApplicable)
+	require.NoError(t, svc.config.UpdateTableWritePermissions(ctx, "library", "books", domain.TableConfig{AllowUpdate: true}))
+
+	competing := propose("1", `"老人与海 (初版)"`)
+	diff, err = svc.Diff(ctx, correction.ID)
+	require.NoError(t, err)
+	assert.True(t, diff.Applicable, diff.Reason)
+	assert.False(t, diff.Stale)
+	assert.Equal(t, `"老人与海"`, string(diff.Current))
+
+	applied, err := svc.Apply(ctx, 2, correction.ID, "已核对原书")
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnnotationStatusApproved, applied.Status)
+	require.NotNil(t, applied.AppliedAt)
+	assert.Equal(t, "老人与海（修订版）", source.titles["1"])
+	require.Len(t, source.updates, 1)
+	assert.Equal(t, "2", source.updates[0].Payload[port.MutateActorKey], "以审核者身份执行写操作")
+
+	var before, after string
+	require.NoError(t, svc.db.QueryRow(`SELECT data_before, data_after FROM operation_log WHERE biz_name = 'library' AND target_pk = '1'`).Scan(&before, &after))
+	assert.JSONEq(t, `{"title":"老人与海"}`, before)
+	assert.JSONEq(t, `{"title":"老人与海（修订版）"}`, after)
+
+	_, err = svc.Apply(ctx, 2, correction.ID, "")
+	assert.ErrorIs(t, err, ErrAlreadyModerated, "同一勘误不能重复写回")
+	_, err = svc.Review(ctx, 2, correction.ID, domain.AnnotationStatusRejected, "")
+	assert.ErrorIs(t, err, ErrAlreadyModerated, "已写回的勘误不能再驳回")
+
+	diff, err = svc.Diff(ctx, competing.ID)
+	require.NoError(t, err)
+	assert.True(t, diff.Stale, "记录已被修改")
+	_, err = svc.Apply(ctx, 2, competing.ID, "")
+	assert.ErrorIs(t, err, ErrStale)
+
+	object := propose("2", `{"zh": "战地钟声"}`)
+	_, err = svc.Apply(ctx, 2, object.ID, "")
+	assert.ErrorIs(t, err, ErrNotApplicable, "对象不能写入单个字段")
+
+	source.matches = 2
+	ambiguous := propose("2", `"丧钟为谁而鸣"`)
+	_, err = svc.Apply(ctx, 2, ambiguous.ID, "")
+	assert.ErrorIs(t, err, ErrNotApplicable, "主键存在于多个库时无法只修改一条")
+	assert.Len(t, source.updates, 1)
+}
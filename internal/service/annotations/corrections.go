@@ -0,0 +1,200 @@
+// Package annotations file: internal/service/annotations/corrections.go
+package annotations
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// correctionTarget 是勘误写回时定位记录所需的信息
+type correctionTarget struct {
+	annotation *domain.Annotation
+	current    json.RawMessage
+	// primaryKey 为空表示无法写回，原因见 reason
+	primaryKey string
+	reason     string
+}
+
+// Diff 返回勘误的原值、当前值与建议值，供审核者在写回前比对
+func (s *Service) Diff(ctx context.Context, id int64) (*domain.CorrectionDiff, error) {
+	target, err := s.prepareCorrection(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a := target.annotation
+	return &domain.CorrectionDiff{
+		AnnotationID: a.ID,
+		Field:        a.Field,
+		Original:     a.OriginalValue,
+		Current:      target.current,
+		Suggested:    a.SuggestedValue,
+		Stale:        !bytes.Equal(a.OriginalValue, target.current),
+		Applicable:   target.primaryKey != "",
+		Reason:       target.reason,
+	}, nil
+}
+
+// Apply 采纳一条勘误：以 editorID 的身份通过数据源的 update 操作写回建议值，
+// 将批注标记为已通过并写入 operation_log。提交勘误后字段已被修改时返回 ErrStale。
+func (s *Service) Apply(ctx context.Context, editorID, id int64, note string) (*domain.Annotation, error) {
+	target, err := s.prepareCorrection(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a := target.annotation
+	if target.primaryKey == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotApplicable, target.reason)
+	}
+	if !bytes.Equal(a.OriginalValue, target.current) {
+		return nil, fmt.Errorf("%w: 字段 '%s' 的值已由 %s 变为 %s", ErrStale, a.Field, a.OriginalValue, target.current)
+	}
+	value, err := decodeScalar(a.SuggestedValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.registry[a.BizName].Mutate(ctx, port.MutateRequest{
+		BizName:   a.BizName,
+		Operation: "update",
+		Payload: map[string]interface{}{
+			"table_name": a.TableName,
+			"data":       map[string]interface{}{a.Field: value},
+			"filters": []interface{}{
+				map[string]interface{}{"field": target.primaryKey, "value": a.RecordID},
+			},
+			port.MutateActorKey: strconv.FormatInt(editorID, 10),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	slog.Info("审计日志: 勘误已写回数据源", "annotation_id", a.ID, "editor_id", editorID, "biz", a.BizName,
+		"table", a.TableName, "record_id", a.RecordID, "field", a.Field)
+
+	// 数据源已写入，此后的失败只影响批注状态与操作日志，需要人工核对
+	now := time.Now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启批注事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE record_annotations
+		SET status = ?, moderator_id = ?, moderation_note = ?, moderated_at = ?, applied_at = ?, applied_by = ?
+		WHERE id = ?`, domain.AnnotationStatusApproved, editorID, strings.TrimSpace(note), now, now, editorID, a.ID); err != nil {
+		return nil, fmt.Errorf("更新批注失败: %w", err)
+	}
+	before, _ := json.Marshal(map[string]json.RawMessage{a.Field: a.OriginalValue})
+	after, _ := json.Marshal(map[string]json.RawMessage{a.Field: a.SuggestedValue})
+	if _, err := tx.ExecContext(ctx, `INSERT INTO operation_log
+		(operation_id, timestamp, user_id, biz_name, table_name, operation_type, target_pk, data_before, data_after, status)
+		VALUES (?, ?, ?, ?, ?, 'UPDATE', ?, ?, ?, 'COMPLETED')`,
+		uuid.NewString(), now, editorID, a.BizName, a.TableName, a.RecordID, string(before), string(after)); err != nil {
+		return nil, fmt.Errorf("写入操作日志失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交批注失败: %w", err)
+	}
+	return s.Get(ctx, a.ID)
+}
+
+// prepareCorrection 读取勘误与记录的当前值，并判断能否写回。
+// 写回只支持单列显式主键、且只存在于一个库中的记录：Mutate 会在业务组的全部库上执行，
+// 按主键过滤才能保证只修改这一条记录。
+func (s *Service) prepareCorrection(ctx context.Context, id int64) (*correctionTarget, error) {
+	a, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Kind != domain.AnnotationKindCorrection {
+		return nil, fmt.Errorf("%w: 批注 %d 不是勘误", ErrNotApplicable, id)
+	}
+	if a.AppliedAt != nil {
+		return nil, fmt.Errorf("%w: 勘误已于 %s 写回", ErrAlreadyModerated, a.AppliedAt.Format(time.RFC3339))
+	}
+	if a.Status == domain.AnnotationStatusRejected {
+		return nil, fmt.Errorf("%w: 勘误已被驳回", ErrAlreadyModerated)
+	}
+
+	record, err := s.lookupRecord(ctx, a.BizName, a.TableName, a.RecordID, a.Lib)
+	if err != nil {
+		return nil, err
+	}
+	current, err := json.Marshal(record.row[a.Field])
+	if err != nil {
+		return nil, fmt.Errorf("序列化字段 '%s' 的当前值失败: %w", a.Field, err)
+	}
+	target := &correctionTarget{annotation: a, current: current}
+
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, a.BizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if tableConfig, ok := bizConfig.Tables[a.TableName]; !ok || !tableConfig.AllowUpdate {
+		target.reason = fmt.Sprintf("表 '%s' 不允许更新", a.TableName)
+		return target, nil
+	}
+	if _, err := decodeScalar(a.SuggestedValue); err != nil {
+		target.reason = err.Error()
+		return target, nil
+	}
+	if record.matches > 1 {
+		target.reason = fmt.Sprintf("主键 '%s' 存在于 %d 个库中，无法只修改其中一条", a.RecordID, record.matches)
+		return target, nil
+	}
+
+	schema, err := s.registry[a.BizName].GetSchema(ctx, port.SchemaRequest{BizName: a.BizName, TableName: a.TableName})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, field := range schema.Tables[a.TableName] {
+		if field.IsPrimary {
+			keys = append(keys, field.Name)
+		}
+	}
+	switch {
+	case len(keys) == 1 && keys[0] == a.Field:
+		target.reason = "不能修改主键字段"
+	case len(keys) == 1:
+		target.primaryKey = keys[0]
+	case len(keys) == 0:
+		target.reason = fmt.Sprintf("表 '%s' 没有已配置的显式主键字段", a.TableName)
+	default:
+		target.reason = fmt.Sprintf("表 '%s' 使用复合主键", a.TableName)
+	}
+	return target, nil
+}
+
+// decodeScalar 把建议值解码为可写入单个字段的标量，整数保持为 int64 以免损失精度
+func decodeScalar(raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: suggested_value 不是合法的 JSON", ErrInvalidAnnotation)
+	}
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	default:
+		return nil, errors.New("建议值是对象或数组，不能直接写入单个字段")
+	}
+}
@@ -0,0 +1,266 @@
+// Package approvals file: internal/service/approvals/approvals_service.go
+package approvals
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 需要双人审批的变更类型
+const (
+	// KindDisablePublicSearch 关闭业务组的公开检索，目标为业务组名，载荷为 domain.BizOverallSettings
+	KindDisablePublicSearch = "biz.disable_public_search"
+	// KindDeleteInstance 删除插件实例，目标为实例 ID
+	KindDeleteInstance = "plugin.delete_instance"
+	// KindUpdateIPLimit 修改全局 IP 限流设置，载荷为 domain.IPLimitSetting
+	KindUpdateIPLimit = "security.ip_limit"
+)
+
+const defaultExpireAfter = 72 * time.Hour
+
+var (
+	// ErrInvalidChange 表示变更申请的类型或载荷不合法
+	ErrInvalidChange = errors.New("无效的变更申请")
+	// ErrNotFound 表示变更申请不存在
+	ErrNotFound = errors.New("变更申请不存在")
+	// ErrNotPending 表示变更申请已被处理或已过期
+	ErrNotPending = errors.New("变更申请不处于待审批状态")
+	// ErrSelfApproval 表示申请人试图审批自己的变更
+	ErrSelfApproval = errors.New("不能审批自己提交的变更")
+)
+
+// Applier 在审批通过后执行变更
+type Applier func(ctx context.Context, target string, payload json.RawMessage) error
+
+// Options 定义双人审批的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ExpireAfterHours 是变更申请的有效期，超时未审批的申请自动作废，默认为 72 小时
+	ExpireAfterHours int `mapstructure:"expire_after_hours"`
+}
+
+// Service 为高风险的管理操作实现双人规则：操作先记录为待审批的变更申请，
+// 由另一位管理员审批通过后才真正执行
+type Service struct {
+	db          *sql.DB
+	expireAfter time.Duration
+	now         func() time.Time
+
+	mu       sync.RWMutex
+	appliers map[string]Applier
+}
+
+// NewService 创建一个新的审批服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("approvals.Service 需要一个有效的数据库连接")
+	}
+	if opts.ExpireAfterHours < 0 {
+		return nil, fmt.Errorf("expire_after_hours 不能为负数: %d", opts.ExpireAfterHours)
+	}
+	expireAfter := defaultExpireAfter
+	if opts.ExpireAfterHours > 0 {
+		expireAfter = time.Duration(opts.ExpireAfterHours) * time.Hour
+	}
+	return &Service{
+		db:          db,
+		expireAfter: expireAfter,
+		now:         time.Now,
+		appliers:    make(map[string]Applier),
+	}, nil
+}
+
+// Register 注册一种变更的执行函数，只有注册过的类型才能提交申请
+func (s *Service) Register(kind string, apply Applier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appliers[kind] = apply
+}
+
+// Kinds 返回已注册的变更类型
+func (s *Service) Kinds() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	kinds := make([]string, 0, len(s.appliers))
+	for kind := range s.appliers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (s *Service) applier(kind string) (Applier, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	apply, ok := s.appliers[kind]
+	return apply, ok
+}
+
+// Submit 记录一项待审批的变更
+func (s *Service) Submit(ctx context.Context, kind, target string, payload any, requestedBy int64) (*domain.ChangeRequest, error) {
+	if _, ok := s.applier(kind); !ok {
+		return nil, fmt.Errorf("%w: 未知的变更类型 '%s'", ErrInvalidChange, kind)
+	}
+	var raw []byte
+	if payload != nil {
+		var err error
+		if raw, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("%w: 载荷无法序列化: %v", ErrInvalidChange, err)
+		}
+	}
+	now := s.now().UTC()
+	res, err := s.db.ExecContext(ctx, `INSERT INTO admin_change_requests (kind, target, payload, status, requested_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		kind, strings.TrimSpace(target), nullString(raw), domain.ChangeStatusPending, nullID(requestedBy), now, now.Add(s.expireAfter))
+	if err != nil {
+		return nil, fmt.Errorf("保存变更申请失败: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	slog.Info("审计日志: 提交待审批的变更", "change_id", id, "kind", kind, "target", target, "requested_by", requestedBy)
+	return s.Get(ctx, id)
+}
+
+// List 返回指定状态的变更申请 (status 为空时返回全部)，最新的在前
+func (s *Service) List(ctx context.Context, status string) ([]domain.ChangeRequest, error) {
+	if err := s.expire(ctx); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		return s.query(ctx, ` ORDER BY id DESC`)
+	}
+	return s.query(ctx, ` WHERE status = ? ORDER BY id DESC`, status)
+}
+
+// Get 返回单个变更申请
+func (s *Service) Get(ctx context.Context, id int64) (*domain.ChangeRequest, error) {
+	if err := s.expire(ctx); err != nil {
+		return nil, err
+	}
+	list, err := s.query(ctx, ` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return &list[0], nil
+}
+
+// Approve 由另一位管理员批准并执行变更。执行失败时申请标记为 failed 并记录原因，
+// 返回的申请反映最终状态
+func (s *Service) Approve(ctx context.Context, id, reviewer int64, note string) (*domain.ChangeRequest, error) {
+	cr, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cr.Status != domain.ChangeStatusPending {
+		return nil, fmt.Errorf("%w: 当前状态为 %s", ErrNotPending, cr.Status)
+	}
+	if cr.RequestedBy != nil && *cr.RequestedBy == reviewer {
+		return nil, ErrSelfApproval
+	}
+	apply, ok := s.applier(cr.Kind)
+	if !ok {
+		return nil, fmt.Errorf("%w: 未知的变更类型 '%s'", ErrInvalidChange, cr.Kind)
+	}
+	// 先以条件更新占住申请，避免两位管理员同时审批导致重复执行
+	if err := s.transition(ctx, id, domain.ChangeStatusApplied, reviewer, note); err != nil {
+		return nil, err
+	}
+	if applyErr := apply(ctx, cr.Target, cr.Payload); applyErr != nil {
+		slog.Error("审计日志: 已批准的变更执行失败", "change_id", id, "kind", cr.Kind, "target", cr.Target, "error", applyErr)
+		if _, err := s.db.ExecContext(ctx, `UPDATE admin_change_requests SET status = ?, error = ? WHERE id = ?`,
+			domain.ChangeStatusFailed, applyErr.Error(), id); err != nil {
+			return nil, fmt.Errorf("记录变更执行失败时出错: %w", err)
+		}
+		return s.Get(ctx, id)
+	}
+	slog.Info("审计日志: 变更已批准并执行", "change_id", id, "kind", cr.Kind, "target", cr.Target, "reviewed_by", reviewer)
+	return s.Get(ctx, id)
+}
+
+// Reject 驳回变更申请。申请人也可以用它撤回自己的申请
+func (s *Service) Reject(ctx context.Context, id, reviewer int64, note string) (*domain.ChangeRequest, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.transition(ctx, id, domain.ChangeStatusRejected, reviewer, note); err != nil {
+		return nil, err
+	}
+	slog.Info("审计日志: 变更已驳回", "change_id", id, "reviewed_by", reviewer)
+	return s.Get(ctx, id)
+}
+
+// transition 把待审批的申请转为终态，申请已被处理时返回 ErrNotPending
+func (s *Service) transition(ctx context.Context, id int64, status string, reviewer int64, note string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE admin_change_requests SET status = ?, reviewed_by = ?, review_note = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?`,
+		status, nullID(reviewer), strings.TrimSpace(note), s.now().UTC(), id, domain.ChangeStatusPending)
+	if err != nil {
+		return fmt.Errorf("更新变更申请失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %d", ErrNotPending, id)
+	}
+	return nil
+}
+
+// expire 把超过有效期的待审批申请标记为 expired
+func (s *Service) expire(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE admin_change_requests SET status = ? WHERE status = ? AND expires_at <= ?`,
+		domain.ChangeStatusExpired, domain.ChangeStatusPending, s.now().UTC()); err != nil {
+		return fmt.Errorf("清理过期的变更申请失败: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) query(ctx context.Context, where string, args ...any) ([]domain.ChangeRequest, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, kind, target, payload, status, requested_by, reviewed_by, review_note, error,
+		created_at, expires_at, reviewed_at FROM admin_change_requests`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询变更申请失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.ChangeRequest{}
+	for rows.Next() {
+		var cr domain.ChangeRequest
+		var payload sql.NullString
+		var requestedBy, reviewedBy sql.NullInt64
+		var reviewedAt sql.NullTime
+		if err := rows.Scan(&cr.ID, &cr.Kind, &cr.Target, &payload, &cr.Status, &requestedBy, &reviewedBy, &cr.ReviewNote, &cr.Error,
+			&cr.CreatedAt, &cr.ExpiresAt, &reviewedAt); err != nil {
+			return nil, fmt.Errorf("扫描变更申请失败: %w", err)
+		}
+		if payload.Valid {
+			cr.Payload = json.RawMessage(payload.String)
+		}
+		if requestedBy.Valid {
+			cr.RequestedBy = &requestedBy.Int64
+		}
+		if reviewedBy.Valid {
+			cr.ReviewedBy = &reviewedBy.Int64
+		}
+		if reviewedAt.Valid {
+			cr.ReviewedAt = &reviewedAt.Time
+		}
+		out = append(out, cr)
+	}
+	return out, rows.Err()
+}
+
+func nullString(raw []byte) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: raw != nil}
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id > 0}
+}
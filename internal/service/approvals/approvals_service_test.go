@@ -0,0 +1,103 @@
+// file: internal/service/approvals/approvals_service_test.go
+package approvals
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'admin'), (2, 'bob', 'x', 'admin')`)
+	require.NoError(t, err)
+	svc, err := NewService(db, Options{Enabled: true, ExpireAfterHours: 1})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestApproveChange(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	var applied []string
+	svc.Register(KindUpdateIPLimit, func(_ context.Context, target string, payload json.RawMessage) error {
+		var setting domain.IPLimitSetting
+		if err := json.Unmarshal(payload, &setting); err != nil {
+			return err
+		}
+		applied = append(applied, target)
+		assert.Equal(t, 3, setting.BurstSize)
+		return nil
+	})
+
+	_, err := svc.Submit(ctx, "unknown.kind", "", nil, 1)
+	assert.ErrorIs(t, err, ErrInvalidChange)
+
+	cr, err := svc.Submit(ctx, KindUpdateIPLimit, "global", domain.IPLimitSetting{RateLimitPerMinute: 60, BurstSize: 3}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeStatusPending, cr.Status)
+	assert.Empty(t, applied, "提交时不执行变更")
+
+	_, err = svc.Approve(ctx, cr.ID, 1, "")
+	assert.ErrorIs(t, err, ErrSelfApproval)
+
+	cr, err = svc.Approve(ctx, cr.ID, 2, "确认")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeStatusApplied, cr.Status)
+	require.NotNil(t, cr.ReviewedBy)
+	assert.EqualValues(t, 2, *cr.ReviewedBy)
+	assert.Equal(t, []string{"global"}, applied)
+
+	_, err = svc.Approve(ctx, cr.ID, 2, "")
+	assert.ErrorIs(t, err, ErrNotPending, "不能重复执行")
+	_, err = svc.Approve(ctx, 999, 2, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRejectFailAndExpire(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.Register(KindDeleteInstance, func(_ context.Context, target string, _ json.RawMessage) error {
+		return errors.New("实例 " + target + " 不存在")
+	})
+
+	rejected, err := svc.Submit(ctx, KindDeleteInstance, "inst-a", nil, 1)
+	require.NoError(t, err)
+	rejected, err = svc.Reject(ctx, rejected.ID, 1, "撤回")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeStatusRejected, rejected.Status)
+
+	failed, err := svc.Submit(ctx, KindDeleteInstance, "inst-b", nil, 1)
+	require.NoError(t, err)
+	failed, err = svc.Approve(ctx, failed.ID, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeStatusFailed, failed.Status)
+	assert.Contains(t, failed.Error, "inst-b")
+
+	stale, err := svc.Submit(ctx, KindDeleteInstance, "inst-c", nil, 1)
+	require.NoError(t, err)
+	pending, err := svc.List(ctx, domain.ChangeStatusPending)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	now = now.Add(2 * time.Hour)
+	_, err = svc.Approve(ctx, stale.ID, 2, "")
+	assert.ErrorIs(t, err, ErrNotPending, "过期的申请不能再审批")
+	pending, err = svc.List(ctx, domain.ChangeStatusPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	all, err := svc.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, domain.ChangeStatusExpired, all[0].Status)
+}
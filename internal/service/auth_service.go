@@ -139,13 +139,23 @@ func GetUserByUsername(db *sql.DB, username string) (id int64, role string, ok b
 	return id, role, true
 }
 
+// TokenLifetime 是普通登录 Token 的有效期
+const TokenLifetime = 24 * time.Hour
+
 // GenToken 为普通用户生成一个新的、有生命周期限制的 JWT
 func GenToken(uid int64, role string) (string, error) {
+	return GenSessionToken(uid, role, "")
+}
+
+// GenSessionToken 与 GenToken 相同，但把登录会话 ID 写入 Token 的 jti，
+// 认证中间件据此拒绝已被挤下线或注销的会话。sessionID 为空时不写入
+func GenSessionToken(uid int64, role, sessionID string) (string, error) {
 	claims := Claim{
 		ID:   uid,
 		Role: role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenLifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "ArchiveAegis",
@@ -155,6 +165,14 @@ func GenToken(uid int64, role string) (string, error) {
 	return token.SignedString(hmacKey)
 }
 
+// ServiceTokenIssuer 是服务 Token 的发行方，用于将其与普通登录 Token 区分
+const ServiceTokenIssuer = "ArchiveAegis-Service"
+
+// IsServiceToken 判断该 Claim 是否来自 -gen-service-token 生成的服务 Token
+func (c *Claim) IsServiceToken() bool {
+	return c != nil && c.Issuer == ServiceTokenIssuer
+}
+
 // GenServiceToken 为服务账户生成一个长生命周期的服务 Token
 func GenServiceToken(uid int64, role string) (string, error) {
 	claims := Claim{
@@ -165,7 +183,7 @@ func GenServiceToken(uid int64, role string) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * 365 * 24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "ArchiveAegis-Service", // 使用不同的发行方以作区分
+			Issuer:    ServiceTokenIssuer, // 使用不同的发行方以作区分
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -222,9 +240,24 @@ func ClaimFrom(r *http.Request) *Claim {
    HTTP 中间件
 ============================================================================= */
 
+// SessionChecker 判断登录会话是否仍然有效
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID string, userID int64) bool
+}
+
+// SessionID 返回 Token 对应的登录会话 ID，未开启会话管理时签发的 Token 没有会话 ID
+func (c *Claim) SessionID() string {
+	if c == nil {
+		return ""
+	}
+	return c.RegisteredClaims.ID
+}
+
 // Authenticator 是一个持有数据库连接的结构体，用于实现认证中间件
 type Authenticator struct {
 	DB *sql.DB
+	// Sessions 校验 Token 携带的登录会话，为 nil 时不校验
+	Sessions SessionChecker
 }
 
 // NewAuthenticator 创建一个新的 Authenticator 实例
@@ -247,7 +280,7 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 				if err == nil && claims != nil {
 					// 令牌有效，再确认一下用户是否仍然存在于数据库中
 					_, _, userExists := GetUserById(a.DB, claims.ID)
-					if userExists {
+					if userExists && a.sessionActive(r.Context(), claims) {
 						// 用户存在，将 claim 注入 context
 						ctx := context.WithValue(r.Context(), ClaimKey, claims)
 						r = r.WithContext(ctx)
@@ -258,3 +291,22 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// sessionActive 校验 Token 携带的登录会话，没有会话 ID 的 Token (服务 Token 等) 不受影响
+func (a *Authenticator) sessionActive(ctx context.Context, claims *Claim) bool {
+	if a.Sessions == nil || claims.SessionID() == "" {
+		return true
+	}
+	return a.Sessions.SessionActive(ctx, claims.SessionID(), claims.ID)
+}
+
+// RequireServiceToken 只放行携带有效服务 Token 的请求，用于 /metrics 等面向机器的端点
+func (a *Authenticator) RequireServiceToken(next http.Handler) http.Handler {
+	return a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ClaimFrom(r).IsServiceToken() {
+			http.Error(w, "需要有效的服务 Token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
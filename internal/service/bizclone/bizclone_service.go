@@ -0,0 +1,223 @@
+// Package bizclone file: internal/service/bizclone/bizclone_service.go
+package bizclone
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidRequest 表示复制请求的参数不合法
+	ErrInvalidRequest = errors.New("无效的配置复制请求")
+	// ErrTargetConfigured 表示目标业务组已有配置，复制只用于新的业务组
+	ErrTargetConfigured = errors.New("目标业务组已有配置")
+	// ErrMissingTables 表示源配置中的表在目标业务组的数据源中不存在
+	ErrMissingTables = errors.New("目标业务组缺少源配置中的表")
+)
+
+// Service 把一个业务组的配置 (总体设置、可查询表及其写权限与检索设置、字段设置、视图与速率限制)
+// 复制到另一个尚未配置的业务组，用于快速配置结构相似的馆藏。复制前按目标业务组的物理表结构校验表是否存在，
+// 目标表中没有的列不复制其字段设置
+type Service struct {
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+}
+
+// NewService 创建一个新的配置复制服务实例
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource) (*Service, error) {
+	if config == nil {
+		return nil, errors.New("bizclone.Service 需要有效的配置服务")
+	}
+	if registry == nil {
+		return nil, errors.New("bizclone.Service 需要有效的数据源注册表")
+	}
+	return &Service{config: config, registry: registry}, nil
+}
+
+// Clone 把 source 的配置复制到 target 并返回复制报告；dryRun 为 true 时只做校验并生成报告。
+// target 必须已注册数据源、尚未配置，且其数据源支持 port.PhysicalSchemaReader
+func (s *Service) Clone(ctx context.Context, source, target string, dryRun bool) (*domain.BizCloneReport, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("%w: 必须指定目标业务组 target", ErrInvalidRequest)
+	}
+	if target == source {
+		return nil, fmt.Errorf("%w: 目标业务组不能与源业务组相同", ErrInvalidRequest)
+	}
+	cfg, err := s.config.GetBizQueryConfig(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("%w: 业务组 '%s'", port.ErrBizNotFound, source)
+	}
+	dataSource, ok := s.registry[target]
+	if !ok {
+		return nil, fmt.Errorf("%w: 业务组 '%s'", port.ErrBizNotFound, target)
+	}
+	existing, err := s.config.GetBizQueryConfig(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: '%s'，请先删除其配置或改用配置模板", ErrTargetConfigured, target)
+	}
+	reader, ok := port.FindCapability[port.PhysicalSchemaReader](dataSource)
+	if !ok {
+		return nil, port.ErrPhysicalSchemaUnsupported
+	}
+	schema, err := reader.PhysicalSchema(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.BizCloneReport{
+		Source:        source,
+		Target:        target,
+		DryRun:        dryRun,
+		Tables:        []string{},
+		SkippedFields: map[string][]string{},
+	}
+	var missing []string
+	fields := make(map[string][]domain.FieldSetting, len(cfg.Tables))
+	for name, table := range cfg.Tables {
+		report.Tables = append(report.Tables, name)
+		// SQL 视图表不对应物理表，其列由视图语句决定，字段设置原样复制
+		if table.SQLView != nil {
+			fields[name] = sortedFields(table.Fields, nil)
+			continue
+		}
+		columns, ok := schema[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		present := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			present[column] = true
+		}
+		fields[name] = sortedFields(table.Fields, present)
+		for field := range table.Fields {
+			if !present[field] {
+				report.SkippedFields[name] = append(report.SkippedFields[name], field)
+			}
+		}
+		sort.Strings(report.SkippedFields[name])
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s", ErrMissingTables, strings.Join(missing, ", "))
+	}
+	sort.Strings(report.Tables)
+
+	views, err := s.config.GetAllViewConfigsForBiz(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range views {
+		report.Views += len(list)
+	}
+	rateLimit, err := s.config.GetBizRateLimitSettings(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	report.RateLimitCopied = rateLimit != nil
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.copyConfig(ctx, cfg, target, fields); err != nil {
+		return nil, err
+	}
+	if len(views) > 0 {
+		if err := s.config.UpdateAllViewsForBiz(ctx, target, views); err != nil {
+			return nil, fmt.Errorf("复制视图失败: %w", err)
+		}
+	}
+	if rateLimit != nil {
+		if err := s.config.UpdateBizRateLimitSettings(ctx, target, *rateLimit); err != nil {
+			return nil, fmt.Errorf("复制速率限制失败: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// copyConfig 依次写入总体设置、可查询表、各表的写权限与检索设置以及字段设置
+func (s *Service) copyConfig(ctx context.Context, cfg *domain.BizQueryConfig, target string, fields map[string][]domain.FieldSetting) error {
+	settings := domain.BizOverallSettings{
+		IsPubliclySearchable: &cfg.IsPubliclySearchable,
+		DefaultQueryTable:    &cfg.DefaultQueryTable,
+		ChangeCaptureEnabled: &cfg.ChangeCaptureEnabled,
+		CitationTemplate:     &cfg.CitationTemplate,
+		Enabled:              &cfg.Enabled,
+		MaxResponseBytes:     &cfg.MaxResponseBytes,
+		QueryCostPolicy:      &cfg.QueryCostPolicy,
+		MaxScanRows:          &cfg.MaxScanRows,
+		Timezone:             &cfg.Timezone,
+		Locale:               &cfg.Locale,
+		Sensitive:            &cfg.Sensitive,
+	}
+	if err := s.config.UpdateBizOverallSettings(ctx, target, settings); err != nil {
+		return fmt.Errorf("复制总体设置失败: %w", err)
+	}
+
+	tableNames := make([]string, 0, len(cfg.Tables))
+	for name := range cfg.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+	if err := s.config.UpdateBizSearchableTables(ctx, target, tableNames); err != nil {
+		return fmt.Errorf("复制可查询表失败: %w", err)
+	}
+	for _, name := range tableNames {
+		table := cfg.Tables[name]
+		if table.AllowCreate || table.AllowUpdate || table.AllowDelete {
+			if err := s.config.UpdateTableWritePermissions(ctx, target, name, *table); err != nil {
+				return fmt.Errorf("复制表 '%s' 的写权限失败: %w", name, err)
+			}
+		}
+		if table.FTS != nil {
+			if err := s.config.UpdateTableFTSConfig(ctx, target, name, table.FTS); err != nil {
+				return fmt.Errorf("复制表 '%s' 的全文检索配置失败: %w", name, err)
+			}
+		}
+		if table.Ranking != nil {
+			if err := s.config.UpdateTableRankingConfig(ctx, target, name, table.Ranking); err != nil {
+				return fmt.Errorf("复制表 '%s' 的相关度得分配置失败: %w", name, err)
+			}
+		}
+		if table.Hierarchy != nil {
+			if err := s.config.UpdateTableHierarchyConfig(ctx, target, name, table.Hierarchy); err != nil {
+				return fmt.Errorf("复制表 '%s' 的层级关系配置失败: %w", name, err)
+			}
+		}
+		if table.SQLView != nil {
+			if err := s.config.UpdateTableSQLViewConfig(ctx, target, name, table.SQLView); err != nil {
+				return fmt.Errorf("复制表 '%s' 的 SQL 视图定义失败: %w", name, err)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		if err := s.config.UpdateFieldSettingsBulk(ctx, target, fields); err != nil {
+			return fmt.Errorf("复制字段设置失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// sortedFields 返回按字段名排序的字段设置；present 不为 nil 时只保留其中存在的列
+func sortedFields(settings map[string]domain.FieldSetting, present map[string]bool) []domain.FieldSetting {
+	out := make([]domain.FieldSetting, 0, len(settings))
+	for name, setting := range settings {
+		if present == nil || present[name] {
+			out = append(out, setting)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FieldName < out[j].FieldName })
+	return out
+}
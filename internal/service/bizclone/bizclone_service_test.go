@@ -0,0 +1,95 @@
+// file: internal/service/bizclone/bizclone_service_test.go
+package bizclone
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+
+	public, table, tz := false, "books", "Asia/Shanghai"
+	require.NoError(t, config.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{
+		IsPubliclySearchable: &public, DefaultQueryTable: &table, Timezone: &tz,
+	}))
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "library", []string{"books", "recent"}))
+	require.NoError(t, config.UpdateTableWritePermissions(ctx, "library", "books", domain.TableConfig{AllowUpdate: true}))
+	require.NoError(t, config.UpdateTableRankingConfig(ctx, "library", "books", &domain.RankingConfig{Expression: domain.RankExpressionBM25}))
+	require.NoError(t, config.UpdateTableSQLViewConfig(ctx, "library", "recent", &domain.SQLViewConfig{SQL: "SELECT id, title FROM books"}))
+	require.NoError(t, config.UpdateFieldSettingsBulk(ctx, "library", map[string][]domain.FieldSetting{
+		"books": {
+			{FieldName: "title", IsSearchable: true, IsReturnable: true, DataType: "string", DisplayName: "书名"},
+			{FieldName: "shelf", IsReturnable: true, DataType: "string"},
+		},
+		"recent": {{FieldName: "title", IsReturnable: true, DataType: "string"}},
+	}))
+	require.NoError(t, config.UpdateAllViewsForBiz(ctx, "library", map[string][]*domain.ViewConfig{
+		"books": {{ViewName: "default", ViewType: "table", IsDefault: true}},
+	}))
+	require.NoError(t, config.UpdateBizRateLimitSettings(ctx, "library", domain.BizRateLimitSetting{RateLimitPerSecond: 5, BurstSize: 10}))
+
+	registry := map[string]port.DataSource{
+		"library":  fixture.NewFakeDataSource(),
+		"branch":   fixture.NewFakeDataSource().WithTable("books", []port.FieldDescription{{Name: "id"}, {Name: "title"}}),
+		"annex":    fixture.NewFakeDataSource().WithTable("loans", []port.FieldDescription{{Name: "id"}}),
+		"archived": fixture.NewFakeDataSource().WithTable("books", []port.FieldDescription{{Name: "id"}, {Name: "title"}}),
+	}
+	svc, err := NewService(config, registry)
+	require.NoError(t, err)
+
+	report, err := svc.Clone(ctx, "library", "branch", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"books", "recent"}, report.Tables)
+	assert.Equal(t, map[string][]string{"books": {"shelf"}}, report.SkippedFields, "目标表中没有的列不复制，SQL 视图表不校验")
+	assert.Equal(t, 1, report.Views)
+	assert.True(t, report.RateLimitCopied)
+	cfg, err := config.GetBizQueryConfig(ctx, "branch")
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "预览不做修改")
+
+	_, err = svc.Clone(ctx, "library", "branch", false)
+	require.NoError(t, err)
+	cfg, err = config.GetBizQueryConfig(ctx, "branch")
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.False(t, cfg.IsPubliclySearchable)
+	assert.Equal(t, "books", cfg.DefaultQueryTable)
+	assert.Equal(t, "Asia/Shanghai", cfg.Timezone)
+	require.Contains(t, cfg.Tables, "books")
+	assert.True(t, cfg.Tables["books"].AllowUpdate)
+	assert.Equal(t, domain.RankExpressionBM25, cfg.Tables["books"].Ranking.Expression)
+	assert.Equal(t, "书名", cfg.Tables["books"].Fields["title"].DisplayName)
+	assert.NotContains(t, cfg.Tables["books"].Fields, "shelf")
+	require.NotNil(t, cfg.Tables["recent"].SQLView)
+	assert.Contains(t, cfg.Tables["recent"].Fields, "title")
+	views, err := config.GetAllViewConfigsForBiz(ctx, "branch")
+	require.NoError(t, err)
+	assert.Len(t, views["books"], 1)
+	limit, err := config.GetBizRateLimitSettings(ctx, "branch")
+	require.NoError(t, err)
+	require.NotNil(t, limit)
+	assert.Equal(t, 10, limit.BurstSize)
+
+	_, err = svc.Clone(ctx, "library", "branch", false)
+	assert.ErrorIs(t, err, ErrTargetConfigured, "只能复制到尚未配置的业务组")
+	_, err = svc.Clone(ctx, "library", "annex", false)
+	assert.ErrorIs(t, err, ErrMissingTables)
+	_, err = svc.Clone(ctx, "library", "library", false)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = svc.Clone(ctx, "missing", "archived", false)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = svc.Clone(ctx, "library", "unregistered", false)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
@@ -0,0 +1,313 @@
+// Package bizrename file: internal/service/bizrename/bizrename_service.go
+package bizrename
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidName 表示新的业务组名不合法
+	ErrInvalidName = errors.New("无效的业务组名")
+	// ErrNameTaken 表示新的业务组名已被使用，或系统表中仍有该名称的数据
+	ErrNameTaken = errors.New("业务组名已被使用")
+	// ErrReferenced 表示业务组是某个组合业务组 (虚拟、分片或读写分离) 的成员，需先修改其定义
+	ErrReferenced = errors.New("业务组被组合业务组引用")
+	// ErrUnsupported 表示业务组的数据源不是由插件实例提供的，无法在运行时重新注册
+	ErrUnsupported = errors.New("该业务组不支持重命名")
+)
+
+// Instances 是重命名需要的插件实例操作，由 plugin_manager.PluginManager 实现
+type Instances interface {
+	// InstanceForBiz 返回绑定到业务组的插件实例及其是否在运行，没有时返回空字符串
+	InstanceForBiz(bizName string) (string, bool, error)
+	Start(instanceID string) error
+	Stop(instanceID string) error
+	// MoveBizData 重命名插件的业务组数据目录，目录不存在时返回 false
+	MoveBizData(oldName, newName string) (bool, error)
+}
+
+// compositeTables 是组合业务组的定义表，其中的 JSON 列以名称引用成员业务组
+var compositeTables = []struct{ table, column string }{
+	{"virtual_biz_definitions", "tables_json"},
+	{"sharded_biz_definitions", "shards_json"},
+	{"read_split_biz_definitions", "readers_json"},
+	{"read_split_biz_definitions", "writer_biz"},
+}
+
+// Service 在一个事务中把业务组名改写到所有带 biz_name 列的系统表 (配置、可查询表、字段设置、视图、速率限制、
+// 插件实例绑定以及日志与统计) 和记录关联的两端，并以新名称重新注册插件实例，使配置与历史随业务组一起迁移
+type Service struct {
+	db        *sql.DB
+	config    port.QueryAdminConfigService
+	registry  map[string]port.DataSource
+	instances Instances
+	now       func() time.Time
+
+	// mu 串行化重命名，避免两次重命名交错地停止与启动同一个实例
+	mu sync.Mutex
+}
+
+// NewService 创建一个新的业务组重命名服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, instances Instances) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("bizrename.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("bizrename.Service 需要有效的配置服务")
+	}
+	if registry == nil {
+		return nil, errors.New("bizrename.Service 需要有效的数据源注册表")
+	}
+	if instances == nil {
+		return nil, errors.New("bizrename.Service 需要插件管理器")
+	}
+	return &Service{db: db, config: config, registry: registry, instances: instances, now: time.Now}, nil
+}
+
+// Rename 把业务组 oldName 重命名为 newName。绑定的插件实例在运行时先停止，数据目录随之改名，
+// 系统表在一个事务中改写，最后以新名称重新启动实例。事务提交后实例未能启动时重命名仍然生效，原因记入 Warnings
+func (s *Service) Rename(ctx context.Context, oldName, newName string, userID *int64) (*domain.BizRenameReport, error) {
+	newName = strings.TrimSpace(newName)
+	if err := validateName(newName); err != nil {
+		return nil, err
+	}
+	if newName == oldName {
+		return nil, fmt.Errorf("%w: 新名称与原名称相同", ErrInvalidName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instanceID, running, err := s.instances.InstanceForBiz(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSource(ctx, oldName, instanceID); err != nil {
+		return nil, err
+	}
+	tables, err := bizTables(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, newName, tables); err != nil {
+		return nil, err
+	}
+
+	report := &domain.BizRenameReport{OldName: oldName, NewName: newName, InstanceID: instanceID, Warnings: []string{}}
+	if running {
+		if err := s.instances.Stop(instanceID); err != nil {
+			return nil, fmt.Errorf("停止插件实例 '%s' 失败: %w", instanceID, err)
+		}
+	}
+	// 出错时以原名称恢复实例
+	restore := func() {
+		if report.DataMoved {
+			if _, err := s.instances.MoveBizData(newName, oldName); err != nil {
+				slog.Error("[BizRename] 恢复业务组数据目录失败", "biz", oldName, "error", err)
+			}
+		}
+		if running {
+			if err := s.instances.Start(instanceID); err != nil {
+				slog.Error("[BizRename] 重新启动插件实例失败", "instance", instanceID, "error", err)
+			}
+		}
+	}
+	if instanceID != "" {
+		if report.DataMoved, err = s.instances.MoveBizData(oldName, newName); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	report.RenamedAt = s.now().UTC()
+	if report.Tables, err = s.rewrite(ctx, oldName, newName, tables, userID, report.RenamedAt); err != nil {
+		restore()
+		return nil, err
+	}
+	s.config.InvalidateAllCaches()
+
+	if running {
+		if err := s.instances.Start(instanceID); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("插件实例 '%s' 以新名称启动失败: %v", instanceID, err))
+		} else {
+			report.Restarted = true
+		}
+	}
+	return report, nil
+}
+
+// checkSource 确认原业务组存在，且其数据源可以随重命名重新注册
+func (s *Service) checkSource(ctx context.Context, oldName, instanceID string) error {
+	cfg, err := s.config.GetBizQueryConfig(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	if cfg == nil && instanceID == "" {
+		return fmt.Errorf("%w: 业务组 '%s'", port.ErrBizNotFound, oldName)
+	}
+	if _, registered := s.registry[oldName]; registered && instanceID == "" {
+		return fmt.Errorf("%w: 业务组 '%s' 的数据源不是由插件实例提供的，组合业务组请以新名称重新定义", ErrUnsupported, oldName)
+	}
+	quoted, _ := json.Marshal(oldName)
+	for _, c := range compositeTables {
+		var owner string
+		err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT biz_name FROM %s WHERE biz_name = ? OR %s = ? OR instr(%s, ?) > 0 LIMIT 1`, c.table, c.column, c.column),
+			oldName, oldName, string(quoted)).Scan(&owner)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("检查组合业务组的引用失败: %w", err)
+		}
+		if owner == oldName {
+			return fmt.Errorf("%w: '%s' 是组合业务组，请以新名称重新定义", ErrUnsupported, oldName)
+		}
+		return fmt.Errorf("%w: '%s' 是组合业务组 '%s' 的成员，请先修改其定义", ErrReferenced, oldName, owner)
+	}
+	return nil
+}
+
+// checkTarget 确认新名称未被数据源占用，且系统表中没有该名称的残留数据 (否则改写后会与之混在一起)
+func (s *Service) checkTarget(ctx context.Context, newName string, tables []string) error {
+	if _, registered := s.registry[newName]; registered {
+		return fmt.Errorf("%w: 业务组 '%s' 已注册数据源", ErrNameTaken, newName)
+	}
+	used := make([]string, 0)
+	for _, table := range tables {
+		var n int
+		if err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %q WHERE biz_name = ?`, table), newName).Scan(&n); err != nil {
+			return fmt.Errorf("检查表 '%s' 失败: %w", table, err)
+		}
+		if n > 0 {
+			used = append(used, table)
+		}
+	}
+	var links int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_links WHERE source_biz = ? OR target_biz = ?`, newName, newName).Scan(&links); err != nil {
+		return fmt.Errorf("检查记录关联失败: %w", err)
+	}
+	if links > 0 {
+		used = append(used, "record_links")
+	}
+	if len(used) > 0 {
+		return fmt.Errorf("%w: 以下表中已有业务组 '%s' 的数据: %s", ErrNameTaken, newName, strings.Join(used, ", "))
+	}
+	return nil
+}
+
+// rewrite 在一个事务中改写所有表中的业务组名并写入重命名记录，返回各表改写的行数。
+// 可查询表与字段设置以外键引用总体配置，外键检查推迟到提交时进行
+func (s *Service) rewrite(ctx context.Context, oldName, newName string, tables []string, userID *int64, at time.Time) (map[string]int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始重命名事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return nil, fmt.Errorf("推迟外键检查失败: %w", err)
+	}
+
+	updated := make(map[string]int64)
+	var total int64
+	exec := func(table, query string, args ...interface{}) error {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("改写表 '%s' 失败: %w", table, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			updated[table] += n
+			total += n
+		}
+		return nil
+	}
+	for _, table := range tables {
+		if err := exec(table, fmt.Sprintf(`UPDATE %q SET biz_name = ? WHERE biz_name = ?`, table), newName, oldName); err != nil {
+			return nil, err
+		}
+	}
+	for _, column := range []string{"source_biz", "target_biz"} {
+		if err := exec("record_links", fmt.Sprintf(`UPDATE record_links SET %s = ? WHERE %s = ?`, column, column), newName, oldName); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO biz_renames (old_name, new_name, renamed_by, rows_updated, renamed_at) VALUES (?, ?, ?, ?, ?)`,
+		oldName, newName, userID, total, at); err != nil {
+		return nil, fmt.Errorf("写入重命名记录失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交重命名事务失败: %w", err)
+	}
+	return updated, nil
+}
+
+// List 按时间从新到旧返回重命名记录，bizName 非空时只返回新旧名称之一为该名称的记录
+func (s *Service) List(ctx context.Context, bizName string) ([]domain.BizRename, error) {
+	query := `SELECT id, old_name, new_name, renamed_by, rows_updated, renamed_at FROM biz_renames`
+	args := []interface{}{}
+	if bizName != "" {
+		query += ` WHERE old_name = ? OR new_name = ?`
+		args = append(args, bizName, bizName)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY renamed_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询重命名记录失败: %w", err)
+	}
+	defer rows.Close()
+	items := []domain.BizRename{}
+	for rows.Next() {
+		var item domain.BizRename
+		var renamedBy sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.OldName, &item.NewName, &renamedBy, &item.RowsUpdated, &item.RenamedAt); err != nil {
+			return nil, fmt.Errorf("读取重命名记录失败: %w", err)
+		}
+		if renamedBy.Valid {
+			item.RenamedBy = &renamedBy.Int64
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// bizTables 返回 auth.db 中所有带 biz_name 列的表。按列名而不是固定清单查找，新增的系统表无需修改这里
+func bizTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT m.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND p.name = 'biz_name' ORDER BY m.name`)
+	if err != nil {
+		return nil, fmt.Errorf("查找带业务组名的表失败: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("查找带业务组名的表失败: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// validateName 拒绝空名称与可能被解释为路径的名称：插件按业务组名在实例目录下保存数据
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: 必须指定新的业务组名 new_name", ErrInvalidName)
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: '%s' 不能包含路径分隔符", ErrInvalidName, name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("%w: '%s' 不能包含控制字符", ErrInvalidName, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// file: internal/service/bizrename/bizrename_service_test.go
+package bizrename
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInstances 记录重命名对插件实例的操作
+type fakeInstances struct {
+	db       *sql.DB
+	running  bool
+	calls    []string
+	moveErr  error
+	startErr error
+}
+
+func (f *fakeInstances) InstanceForBiz(bizName string) (string, bool, error) {
+	var id string
+	err := f.db.QueryRow(`SELECT instance_id FROM plugin_instances WHERE biz_name = ?`, bizName).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	return id, f.running, err
+}
+func (f *fakeInstances) Start(instanceID string) error {
+	f.calls = append(f.calls, "start")
+	return f.startErr
+}
+func (f *fakeInstances) Stop(instanceID string) error {
+	f.calls = append(f.calls, "stop")
+	return nil
+}
+func (f *fakeInstances) MoveBizData(oldName, newName string) (bool, error) {
+	f.calls = append(f.calls, "move "+oldName+"->"+newName)
+	return f.moveErr == nil, f.moveErr
+}
+
+func newTestService(t *testing.T) (*Service, *fakeInstances, map[string]port.DataSource) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable) VALUES ('letters', TRUE)`,
+		`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('letters', 'main')`,
+		`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_returnable) VALUES ('letters', 'main', 'sender', TRUE)`,
+		`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES ('sqlite', '1.0.0', '/tmp')`,
+		`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('inst-1', 'Letters', 'sqlite', '1.0.0', 'letters', 50100)`,
+		`INSERT INTO record_links (source_biz, source_table, source_id, target_biz, target_table, target_id, relation, created_at)
+			VALUES ('letters', 'main', '1', 'persons', 'main', '7', 'mentions', CURRENT_TIMESTAMP)`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 10, time.Minute)
+	require.NoError(t, err)
+	instances := &fakeInstances{db: db}
+	registry := map[string]port.DataSource{}
+	s, err := NewService(db, cfg, registry, instances)
+	require.NoError(t, err)
+	return s, instances, registry
+}
+
+func TestRename(t *testing.T) {
+	ctx := context.Background()
+	s, instances, _ := newTestService(t)
+	instances.running = true
+	userID := int64(1)
+
+	report, err := s.Rename(ctx, "letters", " correspondence ", &userID)
+	require.NoError(t, err)
+	assert.Equal(t, "correspondence", report.NewName)
+	assert.Equal(t, "inst-1", report.InstanceID)
+	assert.True(t, report.DataMoved)
+	assert.True(t, report.Restarted)
+	assert.Equal(t, []string{"stop", "move letters->correspondence", "start"}, instances.calls)
+	assert.Equal(t, int64(1), report.Tables["biz_table_field_settings"])
+	assert.Equal(t, int64(1), report.Tables["plugin_instances"])
+	assert.Equal(t, int64(1), report.Tables["record_links"])
+
+	cfg, err := s.config.GetBizQueryConfig(ctx, "correspondence")
+	require.NoError(t, err)
+	require.NotNil(t, cfg, "配置随业务组迁移")
+	assert.True(t, cfg.Tables["main"].Fields["sender"].IsReturnable)
+	old, err := s.config.GetBizQueryConfig(ctx, "letters")
+	require.NoError(t, err)
+	assert.Nil(t, old)
+
+	renames, err := s.List(ctx, "letters")
+	require.NoError(t, err)
+	require.Len(t, renames, 1)
+	assert.Equal(t, "correspondence", renames[0].NewName)
+	assert.Equal(t, &userID, renames[0].RenamedBy)
+	assert.Positive(t, renames[0].RowsUpdated)
+}
+
+func TestRename_Rejected(t *testing.T) {
+	ctx := context.Background()
+	s, instances, registry := newTestService(t)
+
+	_, err := s.Rename(ctx, "letters", "../etc", nil)
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = s.Rename(ctx, "letters", "letters", nil)
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = s.Rename(ctx, "missing", "other", nil)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = s.Rename(ctx, "letters", "persons", nil)
+	assert.ErrorIs(t, err, ErrNameTaken, "记录关联中已有该名称")
+
+	_, err = s.db.Exec(`INSERT INTO read_split_biz_definitions (biz_name, writer_biz, readers_json) VALUES ('split', 'primary', '["letters"]')`)
+	require.NoError(t, err)
+	_, err = s.Rename(ctx, "letters", "correspondence", nil)
+	assert.ErrorIs(t, err, ErrReferenced)
+	_, err = s.db.Exec(`DELETE FROM read_split_biz_definitions`)
+	require.NoError(t, err)
+
+	registry["static"] = nil
+	_, err = s.db.Exec(`INSERT INTO biz_overall_settings (biz_name) VALUES ('static')`)
+	require.NoError(t, err)
+	_, err = s.Rename(ctx, "static", "other", nil)
+	assert.ErrorIs(t, err, ErrUnsupported, "不是由插件实例提供的数据源")
+	assert.Empty(t, instances.calls)
+
+	// 数据目录改名失败时不改写任何表，正在运行的实例以原名称恢复
+	instances.running = true
+	instances.moveErr = errors.New("磁盘只读")
+	_, err = s.Rename(ctx, "letters", "correspondence", nil)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"stop", "move letters->correspondence", "start"}, instances.calls)
+	var n int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM biz_overall_settings WHERE biz_name = 'letters'`).Scan(&n))
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,308 @@
+// Package bookmarks file: internal/service/bookmarks/bookmarks_service.go
+package bookmarks
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxPerUser = 5000
+	maxNoteRunes      = 2000
+	maxTags           = 20
+	maxTagRunes       = 32
+	defaultPageSize   = 50
+	maxPageSize       = 500
+)
+
+var (
+	// ErrInvalidBookmark 表示收藏的参数不合法
+	ErrInvalidBookmark = errors.New("无效的收藏")
+	// ErrNotFound 表示收藏或被收藏的记录不存在
+	ErrNotFound = errors.New("收藏不存在")
+	// ErrDuplicate 表示该记录已被收藏
+	ErrDuplicate = errors.New("该记录已在收藏中")
+	// ErrQuotaExceeded 表示收藏数已达上限
+	ErrQuotaExceeded = errors.New("收藏数已达上限")
+)
+
+// Options 定义收藏的配置
+type Options struct {
+	// MaxPerUser 是每个用户的收藏数上限，默认为 5000
+	MaxPerUser int `mapstructure:"max_per_user"`
+}
+
+// Service 管理用户对记录的收藏。收藏以与永久链接相同的 (业务组, 表, 主键, 库) 定位记录，
+// 创建时按主键读取一次记录，确认记录存在且对公开检索可见；之后记录被删除时收藏仍保留。
+type Service struct {
+	db         *sql.DB
+	registry   map[string]port.DataSource
+	maxPerUser int
+}
+
+// NewService 创建一个新的收藏服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("bookmarks.Service 需要一个有效的数据库连接")
+	}
+	if opts.MaxPerUser < 0 {
+		return nil, errors.New("收藏数上限不能为负数")
+	}
+	maxPerUser := opts.MaxPerUser
+	if maxPerUser == 0 {
+		maxPerUser = defaultMaxPerUser
+	}
+	return &Service{db: db, registry: registry, maxPerUser: maxPerUser}, nil
+}
+
+// Create 收藏一条记录
+func (s *Service) Create(ctx context.Context, userID int64, b domain.Bookmark) (*domain.Bookmark, error) {
+	b.BizName, b.TableName, b.RecordID, b.Lib = strings.TrimSpace(b.BizName), strings.TrimSpace(b.TableName), strings.TrimSpace(b.RecordID), strings.TrimSpace(b.Lib)
+	if b.BizName == "" || b.TableName == "" || b.RecordID == "" {
+		return nil, fmt.Errorf("%w: 必须提供 biz_name、table_name 与 record_id", ErrInvalidBookmark)
+	}
+	tags, err := normalizeAnnotations(b.Note, b.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRecord(ctx, b.BizName, b.TableName, b.RecordID, b.Lib); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启收藏事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_bookmarks WHERE user_id = ?`, userID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("统计收藏失败: %w", err)
+	}
+	if count >= s.maxPerUser {
+		return nil, fmt.Errorf("%w: 最多收藏 %d 条记录", ErrQuotaExceeded, s.maxPerUser)
+	}
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM user_bookmarks WHERE user_id = ? AND biz_name = ? AND table_name = ? AND record_id = ? AND lib = ?`,
+		userID, b.BizName, b.TableName, b.RecordID, b.Lib).Scan(&exists)
+	if err == nil {
+		return nil, ErrDuplicate
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("查询收藏失败: %w", err)
+	}
+
+	tagsJSON, _ := json.Marshal(tags)
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `INSERT INTO user_bookmarks (user_id, biz_name, table_name, record_id, lib, note, tags_json, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, userID, b.BizName, b.TableName, b.RecordID, b.Lib, b.Note, string(tagsJSON), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("写入收藏失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交收藏失败: %w", err)
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// checkRecord 按主键读取记录，确认记录存在且当前可见
+func (s *Service) checkRecord(ctx context.Context, bizName, tableName, recordID, lib string) error {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return port.ErrBizNotFound
+	}
+	result, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+			"lib":             lib,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if record, _ := result.Data["record"].(map[string]interface{}); record == nil {
+		return fmt.Errorf("%w: 记录 '%s' 在表 '%s' 中不存在", ErrNotFound, recordID, tableName)
+	}
+	return nil
+}
+
+// Update 修改收藏的备注与标签
+func (s *Service) Update(ctx context.Context, userID, id int64, note string, tags []string) (*domain.Bookmark, error) {
+	tags, err := normalizeAnnotations(note, tags)
+	if err != nil {
+		return nil, err
+	}
+	tagsJSON, _ := json.Marshal(tags)
+	res, err := s.db.ExecContext(ctx, `UPDATE user_bookmarks SET note = ?, tags_json = ?, updated_at = ? WHERE id = ? AND user_id = ?`,
+		note, string(tagsJSON), time.Now().UTC(), id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("更新收藏失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// Delete 删除一条收藏
+func (s *Service) Delete(ctx context.Context, userID, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_bookmarks WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("删除收藏失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get 返回用户的一条收藏
+func (s *Service) Get(ctx context.Context, userID, id int64) (*domain.Bookmark, error) {
+	items, _, err := s.query(ctx, userID, `id = ?`, []interface{}{id}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// List 按条件分页检索用户的收藏，按创建时间倒序排列，返回当前页与总数
+func (s *Service) List(ctx context.Context, userID int64, filter domain.BookmarkFilter) ([]domain.Bookmark, int64, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Size <= 0 {
+		filter.Size = defaultPageSize
+	}
+	if filter.Size > maxPageSize {
+		filter.Size = maxPageSize
+	}
+	where, args := filterClause(filter)
+	return s.query(ctx, userID, where, args, filter.Size, (filter.Page-1)*filter.Size)
+}
+
+// Export 返回符合条件的全部收藏，用于导出
+func (s *Service) Export(ctx context.Context, userID int64, filter domain.BookmarkFilter) ([]domain.Bookmark, error) {
+	where, args := filterClause(filter)
+	items, _, err := s.query(ctx, userID, where, args, -1, 0)
+	return items, err
+}
+
+// Tags 返回用户使用过的标签及各自的收藏数，按收藏数降序排列
+func (s *Service) Tags(ctx context.Context, userID int64) ([]domain.BookmarkTag, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.value, COUNT(*) FROM user_bookmarks b, json_each(b.tags_json) t
+		WHERE b.user_id = ? GROUP BY t.value ORDER BY COUNT(*) DESC, t.value`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("统计收藏标签失败: %w", err)
+	}
+	defer rows.Close()
+	out := make([]domain.BookmarkTag, 0)
+	for rows.Next() {
+		var tag domain.BookmarkTag
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, tag)
+	}
+	return out, rows.Err()
+}
+
+func filterClause(filter domain.BookmarkFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.BizName != "" {
+		conds = append(conds, `biz_name = ?`)
+		args = append(args, filter.BizName)
+	}
+	if filter.TableName != "" {
+		conds = append(conds, `table_name = ?`)
+		args = append(args, filter.TableName)
+	}
+	if filter.Tag != "" {
+		conds = append(conds, `EXISTS (SELECT 1 FROM json_each(tags_json) WHERE value = ?)`)
+		args = append(args, strings.ToLower(strings.TrimSpace(filter.Tag)))
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(strings.ToLower(q)) + "%"
+		conds = append(conds, `(LOWER(note) LIKE ? ESCAPE '\' OR LOWER(record_id) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// query 读取用户的收藏，limit 为负数时不分页
+func (s *Service) query(ctx context.Context, userID int64, where string, args []interface{}, limit, offset int) ([]domain.Bookmark, int64, error) {
+	clause := `user_id = ?`
+	if where != "" {
+		clause += ` AND ` + where
+	}
+	allArgs := append([]interface{}{userID}, args...)
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_bookmarks WHERE `+clause, allArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计收藏失败: %w", err)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, biz_name, table_name, record_id, lib, note, tags_json, created_at, updated_at
+		FROM user_bookmarks WHERE `+clause+` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, append(allArgs, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询收藏失败: %w", err)
+	}
+	defer rows.Close()
+	out := make([]domain.Bookmark, 0)
+	for rows.Next() {
+		var b domain.Bookmark
+		var tagsJSON string
+		if err := rows.Scan(&b.ID, &b.UserID, &b.BizName, &b.TableName, &b.RecordID, &b.Lib, &b.Note, &tagsJSON, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, 0, fmt.Errorf("扫描收藏失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tagsJSON), &b.Tags); err != nil || b.Tags == nil {
+			b.Tags = []string{}
+		}
+		out = append(out, b)
+	}
+	return out, total, rows.Err()
+}
+
+// normalizeAnnotations 校验备注长度，并把标签去除首尾空白、转为小写、去重
+func normalizeAnnotations(note string, tags []string) ([]string, error) {
+	if utf8.RuneCountInString(note) > maxNoteRunes {
+		return nil, fmt.Errorf("%w: 备注不能超过 %d 个字符", ErrInvalidBookmark, maxNoteRunes)
+	}
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(out, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagRunes {
+			return nil, fmt.Errorf("%w: 标签 '%s' 超过 %d 个字符", ErrInvalidBookmark, tag, maxTagRunes)
+		}
+		out = append(out, tag)
+	}
+	if len(out) > maxTags {
+		return nil, fmt.Errorf("%w: 每条收藏最多 %d 个标签", ErrInvalidBookmark, maxTags)
+	}
+	return out, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
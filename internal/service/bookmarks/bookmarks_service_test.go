@@ -0,0 +1,106 @@
+// file: internal/service/bookmarks/bookmarks_service_test.go
+package bookmarks
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) *Service {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'user'), (2, 'bob', 'x', 'user')`)
+	require.NoError(t, err)
+	books := fixture.NewFakeDataSource().WithTable("books", []port.FieldDescription{{Name: "id", IsPrimary: true}},
+		map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}, map[string]interface{}{"id": "3"})
+	registry := map[string]port.DataSource{"library": books}
+	svc, err := NewService(db, registry, opts)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestBookmarkLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{})
+
+	first, err := svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "1", Note: "Hemingway 初版", Tags: []string{" Novel ", "novel", "sea"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"novel", "sea"}, first.Tags, "标签被规范化并去重")
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "2", Note: "50%_off", Tags: []string{"novel"}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, 2, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "3"})
+	require.NoError(t, err)
+
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "1"})
+	assert.ErrorIs(t, err, ErrDuplicate)
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "404"})
+	assert.ErrorIs(t, err, ErrNotFound, "不存在的记录不能收藏")
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "archive", TableName: "books", RecordID: "1"})
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books"})
+	assert.ErrorIs(t, err, ErrInvalidBookmark)
+
+	items, total, err := svc.List(ctx, 1, domain.BookmarkFilter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total, "只列出自己的收藏")
+	assert.Equal(t, "2", items[0].RecordID, "按创建时间倒序")
+
+	items, _, err = svc.List(ctx, 1, domain.BookmarkFilter{Tag: "SEA"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "1", items[0].RecordID)
+	items, _, err = svc.List(ctx, 1, domain.BookmarkFilter{Query: "hemingway"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	items, _, err = svc.List(ctx, 1, domain.BookmarkFilter{Query: "%_"})
+	require.NoError(t, err)
+	require.Len(t, items, 1, "LIKE 通配符按字面匹配")
+	assert.Equal(t, "2", items[0].RecordID)
+	items, total, err = svc.List(ctx, 1, domain.BookmarkFilter{Page: 2, Size: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, items, 1)
+	assert.Equal(t, "1", items[0].RecordID)
+
+	tags, err := svc.Tags(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.BookmarkTag{{Tag: "novel", Count: 2}, {Tag: "sea", Count: 1}}, tags)
+
+	updated, err := svc.Update(ctx, 1, first.ID, "reread", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "reread", updated.Note)
+	assert.Empty(t, updated.Tags)
+	_, err = svc.Update(ctx, 2, first.ID, "hijack", nil)
+	assert.ErrorIs(t, err, ErrNotFound, "不能修改他人的收藏")
+	assert.ErrorIs(t, svc.Delete(ctx, 2, first.ID), ErrNotFound)
+
+	exported, err := svc.Export(ctx, 1, domain.BookmarkFilter{BizName: "library"})
+	require.NoError(t, err)
+	assert.Len(t, exported, 2)
+
+	require.NoError(t, svc.Delete(ctx, 1, first.ID))
+	_, err = svc.Get(ctx, 1, first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBookmarkLimits(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{MaxPerUser: 1})
+	_, err := svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "1"})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, 1, domain.Bookmark{BizName: "library", TableName: "books", RecordID: "2"})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	tags := make([]string, maxTags+1)
+	for i := range tags {
+		tags[i] = string(rune('a' + i))
+	}
+	_, err = svc.Update(ctx, 1, 1, "", tags)
+	assert.ErrorIs(t, err, ErrInvalidBookmark)
+}
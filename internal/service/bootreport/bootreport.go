@@ -0,0 +1,192 @@
+// Package bootreport file: internal/service/bootreport/bootreport.go
+package bootreport
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder 在启动过程中收集启动报告。各初始化步骤向其登记启用的组件、后台任务与警告，
+// 启动完成时由 Finish 汇总插件实例与业务组并记录一次日志，取代分散的启动日志
+type Recorder struct {
+	mu     sync.RWMutex
+	report domain.BootReport
+}
+
+// New 创建一个启动报告，startedAt 是进程开始初始化的时间
+func New(version string, startedAt time.Time) *Recorder {
+	return &Recorder{report: domain.BootReport{
+		Version:         version,
+		StartedAt:       startedAt.UTC(),
+		Config:          domain.BootConfig{Defaults: []string{}},
+		EnabledFeatures: []string{},
+		Components:      []domain.BootComponent{},
+		BackgroundTasks: []string{},
+		Plugins:         []domain.BootPlugin{},
+		Bizs:            []string{},
+		Warnings:        []string{},
+	}}
+}
+
+// SetConfig 记录配置文件及使用内置默认值的配置项
+func (r *Recorder) SetConfig(file string, loaded bool, defaults []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Config = domain.BootConfig{File: file, Loaded: loaded, Defaults: sortedCopy(defaults)}
+}
+
+// SetFeatures 记录 auth.db 中启用的系统功能
+func (r *Recorder) SetFeatures(features map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enabled := make([]string, 0, len(features))
+	for id, on := range features {
+		if on {
+			enabled = append(enabled, id)
+		}
+	}
+	sort.Strings(enabled)
+	r.report.EnabledFeatures = enabled
+}
+
+// Enable 登记一个启用的组件。name 使用 config.yaml 中的配置键，settings 与 slog 相同，按键值对交替给出
+func (r *Recorder) Enable(name string, settings ...interface{}) {
+	component := domain.BootComponent{Name: name}
+	if len(settings) > 0 {
+		component.Settings = make(map[string]interface{}, len(settings)/2)
+		for i := 0; i+1 < len(settings); i += 2 {
+			component.Settings[fmt.Sprint(settings[i])] = settings[i+1]
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Components = append(r.report.Components, component)
+}
+
+// Task 登记一个已启动的后台任务
+func (r *Recorder) Task(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.BackgroundTasks = append(r.report.BackgroundTasks, name)
+}
+
+// Warn 登记一个启动中遇到的问题。问题不会阻止启动，但通常意味着配置需要调整
+func (r *Recorder) Warn(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Warnings = append(r.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// SetPlugins 记录插件实例的状态，failures 以实例 ID 索引启动失败的原因。
+// 启动失败的实例同时登记为警告
+func (r *Recorder) SetPlugins(instances []domain.PluginInstance, failures map[string]error) {
+	plugins := make([]domain.BootPlugin, 0, len(instances))
+	var warnings []string
+	for _, inst := range instances {
+		p := domain.BootPlugin{
+			InstanceID: inst.InstanceID,
+			PluginID:   inst.PluginID,
+			BizName:    inst.BizName,
+			Mode:       inst.Mode,
+			Enabled:    inst.Enabled,
+			Status:     inst.Status,
+		}
+		if err := failures[inst.InstanceID]; err != nil {
+			p.Error = err.Error()
+			warnings = append(warnings, fmt.Sprintf("插件实例 '%s' (业务组 '%s') 启动失败: %v", inst.InstanceID, inst.BizName, err))
+		}
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].InstanceID < plugins[j].InstanceID })
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Plugins = plugins
+	r.report.Warnings = append(r.report.Warnings, warnings...)
+}
+
+// SetBizs 记录已注册数据源的业务组。configured 是管理配置中存在的业务组，其中没有数据源的登记为警告
+func (r *Recorder) SetBizs(registered, configured []string) {
+	bizs := sortedCopy(registered)
+	known := make(map[string]bool, len(bizs))
+	for _, biz := range bizs {
+		known[biz] = true
+	}
+	var missing []string
+	for _, biz := range sortedCopy(configured) {
+		if !known[biz] {
+			missing = append(missing, biz)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Bizs = bizs
+	if len(missing) > 0 {
+		r.report.Warnings = append(r.report.Warnings, fmt.Sprintf("以下业务组已有配置但没有运行中的数据源，检索将返回 404: %s", strings.Join(missing, ", ")))
+	}
+}
+
+// Finish 标记启动完成并记录一次启动日志，返回最终的报告
+func (r *Recorder) Finish(listen string) domain.BootReport {
+	r.mu.Lock()
+	now := time.Now().UTC()
+	r.report.ReadyAt = &now
+	r.report.StartupMS = now.Sub(r.report.StartedAt).Milliseconds()
+	r.report.Listen = listen
+	r.mu.Unlock()
+
+	report := r.Report()
+	components := make([]string, 0, len(report.Components))
+	for _, c := range report.Components {
+		components = append(components, c.Name)
+	}
+	running := 0
+	for _, p := range report.Plugins {
+		if p.Status == "RUNNING" {
+			running++
+		}
+	}
+	level := slog.LevelInfo
+	if len(report.Warnings) > 0 {
+		level = slog.LevelWarn
+	}
+	slog.Log(context.Background(), level, "启动报告",
+		"version", report.Version,
+		"startup_ms", report.StartupMS,
+		"listen", report.Listen,
+		slog.Group("config", "file", report.Config.File, "loaded", report.Config.Loaded, "defaults", report.Config.Defaults),
+		"features", report.EnabledFeatures,
+		"components", components,
+		"background_tasks", report.BackgroundTasks,
+		slog.Group("plugins", "total", len(report.Plugins), "running", running),
+		"bizs", report.Bizs,
+		"warnings", report.Warnings,
+	)
+	return report
+}
+
+// Report 返回当前报告的副本。启动完成前 ReadyAt 为空
+func (r *Recorder) Report() domain.BootReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	report := r.report
+	report.Config.Defaults = append([]string{}, r.report.Config.Defaults...)
+	report.EnabledFeatures = append([]string{}, r.report.EnabledFeatures...)
+	report.Components = append([]domain.BootComponent{}, r.report.Components...)
+	report.BackgroundTasks = append([]string{}, r.report.BackgroundTasks...)
+	report.Plugins = append([]domain.BootPlugin{}, r.report.Plugins...)
+	report.Bizs = append([]string{}, r.report.Bizs...)
+	report.Warnings = append([]string{}, r.report.Warnings...)
+	return report
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,53 @@
+// file: internal/service/bootreport/bootreport_test.go
+package bootreport
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	started := time.Now().Add(-1500 * time.Millisecond)
+	r := New("v1.2.3", started)
+	assert.Nil(t, r.Report().ReadyAt, "启动完成前没有 ready_at")
+
+	r.SetConfig("/opt/aegis/configs/config.yaml", true, []string{"warmup.enabled", "compression.enabled"})
+	r.SetFeatures(map[string]bool{"io.archiveaegis.system.observability": true, "io.archiveaegis.system.disabled": false})
+	r.Enable("query_cache", "ttl", time.Minute, "serve_stale", []string{"letters"})
+	r.Enable("query_coalescing")
+	r.Task("biz_replication")
+	r.Warn("模拟数据模式已启用")
+	r.SetPlugins([]domain.PluginInstance{
+		{InstanceID: "sqlite-b", PluginID: "sqlite", BizName: "photos", Mode: "embedded", Enabled: true, Status: "STOPPED"},
+		{InstanceID: "sqlite-a", PluginID: "sqlite", BizName: "letters", Mode: "embedded", Enabled: true, Status: "RUNNING"},
+	}, map[string]error{"sqlite-b": errors.New("库目录不存在")})
+	r.SetBizs([]string{"letters"}, []string{"photos", "letters", "maps"})
+
+	report := r.Finish(":10224")
+	require.NotNil(t, report.ReadyAt)
+	assert.GreaterOrEqual(t, report.StartupMS, int64(1500))
+	assert.Equal(t, ":10224", report.Listen)
+	assert.Equal(t, []string{"compression.enabled", "warmup.enabled"}, report.Config.Defaults)
+	assert.Equal(t, []string{"io.archiveaegis.system.observability"}, report.EnabledFeatures)
+	require.Len(t, report.Components, 2)
+	assert.Equal(t, "query_cache", report.Components[0].Name)
+	assert.Equal(t, time.Minute, report.Components[0].Settings["ttl"])
+	assert.Nil(t, report.Components[1].Settings)
+	assert.Equal(t, []string{"biz_replication"}, report.BackgroundTasks)
+
+	require.Len(t, report.Plugins, 2)
+	assert.Equal(t, "sqlite-a", report.Plugins[0].InstanceID, "插件实例按 ID 排序")
+	assert.Equal(t, "库目录不存在", report.Plugins[1].Error)
+	assert.Equal(t, []string{"letters"}, report.Bizs)
+	require.Len(t, report.Warnings, 3)
+	assert.Contains(t, report.Warnings[1], "sqlite-b")
+	assert.Contains(t, report.Warnings[2], "maps, photos", "已配置但没有数据源的业务组")
+
+	report.Warnings[0] = "被调用方修改"
+	assert.Equal(t, "模拟数据模式已启用", r.Report().Warnings[0], "Report 返回副本")
+}
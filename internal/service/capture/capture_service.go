@@ -0,0 +1,317 @@
+// Package capture file: internal/service/capture/capture_service.go
+package capture
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxEntries  = 200
+	defaultMaxBody     = 64 << 10
+	defaultMaxDuration = time.Hour
+	defaultDuration    = 10 * time.Minute
+
+	redacted = "[REDACTED]"
+)
+
+var (
+	// ErrInvalidCapture 表示采集时长或条数不合法
+	ErrInvalidCapture = errors.New("无效的采集设置")
+	// ErrNotFound 表示该业务组没有采集记录
+	ErrNotFound = errors.New("该业务组没有采集会话")
+)
+
+// sensitiveHeaders 中的请求头只记录是否存在，不记录取值
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+	"X-Setup-Token":       true,
+}
+
+// sensitiveKeys 是 JSON 字段名与查询参数名中表示凭据的片段 (不区分大小写)
+var sensitiveKeys = []string{"password", "passwd", "passphrase", "secret", "token", "api_key", "apikey", "credential", "signature"}
+
+// Options 定义请求/响应采集的配置
+type Options struct {
+	// MaxEntries 是单个业务组缓冲区可保存的记录数上限，也是开始采集时可指定的最大值，默认为 200
+	MaxEntries int `mapstructure:"max_entries"`
+	// MaxBodyBytes 是每条记录中请求体与响应体各自保存的最大字节数，默认为 64 KiB
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// MaxDurationMinutes 是单次采集的最长时长，默认为 60 分钟
+	MaxDurationMinutes int `mapstructure:"max_duration_minutes"`
+}
+
+type session struct {
+	info    domain.CaptureSession
+	entries []domain.CapturedExchange // 按采集顺序排列，满时挤掉最早的记录
+	nextID  int64
+}
+
+// Service 在管理员开启后的有限时间内，把发往指定业务组的请求与响应 (脱敏后) 保存在内存中的定长缓冲区，
+// 用于排查前后端不一致的问题。采集记录不落盘，网关重启后丢失
+type Service struct {
+	maxEntries  int
+	maxBody     int
+	maxDuration time.Duration
+	now         func() time.Time
+
+	mu       sync.RWMutex
+	sessions map[string]*session
+}
+
+// NewService 创建一个新的采集服务实例
+func NewService(opts Options) (*Service, error) {
+	if opts.MaxEntries < 0 || opts.MaxBodyBytes < 0 || opts.MaxDurationMinutes < 0 {
+		return nil, errors.New("max_entries、max_body_bytes 与 max_duration_minutes 不能为负数")
+	}
+	s := &Service{
+		maxEntries:  defaultMaxEntries,
+		maxBody:     defaultMaxBody,
+		maxDuration: defaultMaxDuration,
+		now:         time.Now,
+		sessions:    make(map[string]*session),
+	}
+	if opts.MaxEntries > 0 {
+		s.maxEntries = opts.MaxEntries
+	}
+	if opts.MaxBodyBytes > 0 {
+		s.maxBody = opts.MaxBodyBytes
+	}
+	if opts.MaxDurationMinutes > 0 {
+		s.maxDuration = time.Duration(opts.MaxDurationMinutes) * time.Minute
+	}
+	return s, nil
+}
+
+// ReadLimit 返回中间件读取请求体与响应体的字节数上限。读取量大于保存的上限，
+// 使略超上限的 JSON 仍能完整解析并脱敏后再截断
+func (s *Service) ReadLimit() int {
+	return s.maxBody * 4
+}
+
+// Start 开始采集业务组的请求，duration 为 0 时使用 10 分钟 (不超过上限)，maxEntries 为 0 时使用上限。
+// 已有的采集会被替换，之前的记录随之清空
+func (s *Service) Start(bizName string, duration time.Duration, maxEntries int, startedBy int64) (*domain.CaptureSession, error) {
+	if strings.TrimSpace(bizName) == "" {
+		return nil, fmt.Errorf("%w: 业务组名不能为空", ErrInvalidCapture)
+	}
+	if duration < 0 || duration > s.maxDuration {
+		return nil, fmt.Errorf("%w: 采集时长必须在 0 到 %s 之间", ErrInvalidCapture, s.maxDuration)
+	}
+	if maxEntries < 0 || maxEntries > s.maxEntries {
+		return nil, fmt.Errorf("%w: 采集条数必须在 0 到 %d 之间", ErrInvalidCapture, s.maxEntries)
+	}
+	if duration == 0 {
+		duration = min(defaultDuration, s.maxDuration)
+	}
+	if maxEntries == 0 {
+		maxEntries = s.maxEntries
+	}
+	now := s.now().UTC()
+	sess := &session{info: domain.CaptureSession{
+		BizName:    bizName,
+		StartedBy:  startedBy,
+		StartedAt:  now,
+		ExpiresAt:  now.Add(duration),
+		MaxEntries: maxEntries,
+	}}
+	s.mu.Lock()
+	s.sessions[bizName] = sess
+	info := s.snapshot(sess)
+	s.mu.Unlock()
+	return &info, nil
+}
+
+// Stop 提前结束业务组的采集，已采集的记录保留
+func (s *Service) Stop(bizName string) (*domain.CaptureSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[bizName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, bizName)
+	}
+	if now := s.now().UTC(); sess.info.ExpiresAt.After(now) {
+		sess.info.ExpiresAt = now
+	}
+	info := s.snapshot(sess)
+	return &info, nil
+}
+
+// Sessions 返回所有业务组的采集会话，按业务组名排序
+func (s *Service) Sessions() []domain.CaptureSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]domain.CaptureSession, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		list = append(list, s.snapshot(sess))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].BizName < list[j].BizName })
+	return list
+}
+
+// Entries 返回业务组已采集的记录，按时间先后排列
+func (s *Service) Entries(bizName string) (*domain.CaptureSession, []domain.CapturedExchange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[bizName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, bizName)
+	}
+	info := s.snapshot(sess)
+	entries := make([]domain.CapturedExchange, len(sess.entries))
+	copy(entries, sess.entries)
+	return &info, entries, nil
+}
+
+// AnyActive 判断是否有正在进行的采集，供中间件在没有采集时跳过解析业务组名
+func (s *Service) AnyActive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	for _, sess := range s.sessions {
+		if now.Before(sess.info.ExpiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Active 判断业务组当前是否在采集
+func (s *Service) Active(bizName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[bizName]
+	return ok && s.now().Before(sess.info.ExpiresAt)
+}
+
+// Record 脱敏后保存一次请求与响应。采集已结束时忽略；缓冲区满时挤掉最早的记录
+func (s *Service) Record(bizName string, req *http.Request, reqBody []byte, status int, respBody []byte, duration time.Duration, userID int64) {
+	entry := domain.CapturedExchange{
+		Time:           s.now().UTC(),
+		Method:         req.Method,
+		Path:           req.URL.Path,
+		Query:          sanitizeQuery(req.URL.Query()),
+		Status:         status,
+		DurationMs:     duration.Milliseconds(),
+		UserID:         userID,
+		RequestHeaders: sanitizeHeaders(req.Header),
+	}
+	entry.RequestBody, entry.RequestTruncated = s.sanitizeBody(reqBody)
+	entry.ResponseBody, entry.ResponseTruncated = s.sanitizeBody(respBody)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[bizName]
+	if !ok || !entry.Time.Before(sess.info.ExpiresAt) {
+		return
+	}
+	sess.nextID++
+	entry.ID = sess.nextID
+	if len(sess.entries) >= sess.info.MaxEntries {
+		sess.entries = append(sess.entries[:0], sess.entries[1:]...)
+		sess.info.Dropped++
+	}
+	sess.entries = append(sess.entries, entry)
+}
+
+// snapshot 返回会话信息的副本，调用方需持有锁
+func (s *Service) snapshot(sess *session) domain.CaptureSession {
+	info := sess.info
+	info.Active = s.now().Before(info.ExpiresAt)
+	info.Captured = len(sess.entries)
+	return info
+}
+
+func sensitiveKey(name string) bool {
+	lower := strings.ToLower(name)
+	for _, key := range sensitiveKeys {
+		if strings.Contains(lower, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeHeaders 合并同名请求头，敏感请求头的取值替换为 [REDACTED]
+func sanitizeHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || sensitiveKey(name) {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func sanitizeQuery(q url.Values) string {
+	for name := range q {
+		if sensitiveKey(name) {
+			q[name] = []string{redacted}
+		}
+	}
+	return q.Encode()
+}
+
+// sanitizeBody 把 JSON 中的敏感字段替换为 [REDACTED] 并按大小上限截断。非 UTF-8 内容只记录长度
+func (s *Service) sanitizeBody(body []byte) (string, bool) {
+	if len(body) == 0 {
+		return "", false
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err == nil {
+		if redactJSON(doc) {
+			if b, err := json.Marshal(doc); err == nil {
+				body = b
+			}
+		}
+	} else if !utf8.Valid(body) {
+		return fmt.Sprintf("[二进制内容 %d 字节]", len(body)), false
+	}
+	if len(body) <= s.maxBody {
+		return string(body), false
+	}
+	cut := s.maxBody
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]), true
+}
+
+// redactJSON 递归替换敏感字段的取值，返回是否有字段被替换
+func redactJSON(v any) bool {
+	changed := false
+	switch node := v.(type) {
+	case map[string]any:
+		for key, child := range node {
+			if sensitiveKey(key) {
+				node[key] = redacted
+				changed = true
+				continue
+			}
+			if redactJSON(child) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, child := range node {
+			if redactJSON(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,77 @@
+// file: internal/service/capture/capture_service_test.go
+package capture
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_Sanitizes(t *testing.T) {
+	svc, err := NewService(Options{MaxBodyBytes: 64})
+	require.NoError(t, err)
+	_, err = svc.Start("archive", time.Minute, 0, 1)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/data/query?biz=archive&access_token=abc", nil)
+	req.Header.Set("Authorization", "Bearer secret-jwt")
+	req.Header.Set("X-Aegis-Signature", "deadbeef")
+	req.Header.Set("Content-Type", "application/json")
+	body := []byte(`{"biz_name":"archive","auth":{"password":"hunter2"},"items":[{"api_key":"k"}]}`)
+	svc.Record("archive", req, body, http.StatusOK, []byte(strings.Repeat("文", 30)), 5*time.Millisecond, 7)
+	svc.Record("other", req, body, http.StatusOK, nil, 0, 0)
+
+	_, entries, err := svc.Entries("archive")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, "[REDACTED]", e.RequestHeaders["Authorization"])
+	assert.Equal(t, "[REDACTED]", e.RequestHeaders["X-Aegis-Signature"])
+	assert.Equal(t, "application/json", e.RequestHeaders["Content-Type"])
+	assert.Contains(t, e.Query, "access_token=%5BREDACTED%5D")
+	assert.NotContains(t, e.RequestBody, "hunter2")
+	assert.NotContains(t, e.RequestBody, `"k"`)
+	assert.Contains(t, e.RequestBody, `"biz_name":"archive"`)
+	assert.True(t, e.ResponseTruncated)
+	assert.LessOrEqual(t, len(e.ResponseBody), 64)
+	assert.True(t, strings.HasSuffix(e.ResponseBody, "文"), "截断不应切开多字节字符")
+	assert.Equal(t, int64(7), e.UserID)
+
+	_, _, err = svc.Entries("other")
+	assert.ErrorIs(t, err, ErrNotFound, "未开启采集的业务组不记录")
+}
+
+func TestSession_ExpiresAndLimits(t *testing.T) {
+	svc, err := NewService(Options{MaxEntries: 10, MaxDurationMinutes: 5})
+	require.NoError(t, err)
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	_, err = svc.Start("archive", time.Hour, 0, 1)
+	assert.ErrorIs(t, err, ErrInvalidCapture, "超过最长时长")
+	_, err = svc.Start("archive", 0, 11, 1)
+	assert.ErrorIs(t, err, ErrInvalidCapture, "超过条数上限")
+
+	session, err := svc.Start("archive", 0, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, session.ExpiresAt.Sub(session.StartedAt), "默认时长不超过上限")
+	assert.Equal(t, 10, session.MaxEntries)
+	assert.True(t, svc.AnyActive())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/meta/schema/archive", nil)
+	svc.Record("archive", req, nil, http.StatusOK, nil, 0, 0)
+	now = now.Add(6 * time.Minute)
+	assert.False(t, svc.Active("archive"))
+	assert.False(t, svc.AnyActive())
+	svc.Record("archive", req, nil, http.StatusOK, nil, 0, 0)
+
+	info, entries, err := svc.Entries("archive")
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+	assert.Len(t, entries, 1, "过期后不再采集，已有记录保留")
+}
@@ -0,0 +1,250 @@
+// Package catalog file: internal/service/catalog/catalog_service.go
+package catalog
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL    = time.Minute
+	defaultConcurrency = 8
+	defaultBizTimeout  = 10 * time.Second
+)
+
+// Options 定义目录汇总的配置
+type Options struct {
+	// CacheTTLSeconds 是目录的缓存时间，默认为 60 秒
+	CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+	// Concurrency 是同时汇总的业务组数，默认为 8
+	Concurrency int `mapstructure:"concurrency"`
+	// BizTimeoutSeconds 是汇总单个业务组的超时，超时的业务组在目录中带有 error，默认为 10 秒
+	BizTimeoutSeconds int `mapstructure:"biz_timeout_seconds"`
+}
+
+// Catalog 是全部已注册业务组的目录
+type Catalog struct {
+	Bizs        []BizEntry `json:"bizs"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// BizEntry 描述一个业务组。不公开检索的业务组只列出名称
+type BizEntry struct {
+	BizName              string       `json:"biz_name"`
+	Source               string       `json:"source"`
+	IsPubliclySearchable bool         `json:"is_publicly_searchable"`
+	DefaultTable         string       `json:"default_table,omitempty"`
+	Tables               []TableEntry `json:"tables"`
+	// Error 表示汇总该业务组时出错，其余字段可能不完整
+	Error string `json:"error,omitempty"`
+}
+
+// TableEntry 描述一张可检索的表
+type TableEntry struct {
+	Name   string                  `json:"name"`
+	Fields []port.FieldDescription `json:"fields"`
+	// Rows 是表的记录数，数据源无法统计时为 nil
+	Rows  *int64      `json:"rows,omitempty"`
+	Views []ViewEntry `json:"views"`
+}
+
+// ViewEntry 是表上可用的一个视图
+type ViewEntry struct {
+	ViewName    string `json:"view_name"`
+	ViewType    string `json:"view_type"`
+	DisplayName string `json:"display_name"`
+	IsDefault   bool   `json:"is_default"`
+}
+
+// Service 并发汇总所有已注册业务组的 Schema、记录数与视图，供门户首页一次性列出全部馆藏。
+// 结果按 CacheTTLSeconds 缓存，缓存过期后的首个请求负责重建，其余并发请求等待同一次重建。
+type Service struct {
+	config      port.QueryAdminConfigService
+	registry    map[string]port.DataSource
+	ttl         time.Duration
+	concurrency int
+	bizTimeout  time.Duration
+
+	mu      sync.Mutex
+	cached  *Catalog
+	expires time.Time
+}
+
+// NewService 创建一个新的目录服务实例
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if config == nil {
+		return nil, errors.New("catalog.Service 需要有效的配置服务")
+	}
+	if opts.CacheTTLSeconds < 0 || opts.Concurrency < 0 || opts.BizTimeoutSeconds < 0 {
+		return nil, errors.New("目录的配置项不能为负数")
+	}
+	s := &Service{
+		config:      config,
+		registry:    registry,
+		ttl:         defaultCacheTTL,
+		concurrency: defaultConcurrency,
+		bizTimeout:  defaultBizTimeout,
+	}
+	if opts.CacheTTLSeconds > 0 {
+		s.ttl = time.Duration(opts.CacheTTLSeconds) * time.Second
+	}
+	if opts.Concurrency > 0 {
+		s.concurrency = opts.Concurrency
+	}
+	if opts.BizTimeoutSeconds > 0 {
+		s.bizTimeout = time.Duration(opts.BizTimeoutSeconds) * time.Second
+	}
+	return s, nil
+}
+
+// Get 返回目录，缓存有效时直接返回缓存
+func (s *Service) Get(ctx context.Context) (*Catalog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Now().Before(s.expires) {
+		return s.cached, nil
+	}
+	catalog, err := s.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.cached, s.expires = catalog, time.Now().Add(s.ttl)
+	return catalog, nil
+}
+
+// Invalidate 丢弃缓存，下次请求时重建目录
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+func (s *Service) build(ctx context.Context) (*Catalog, error) {
+	bizNames := make([]string, 0, len(s.registry))
+	for name := range s.registry {
+		// 暂停服务的业务组不出现在目录中
+		if cfg, err := s.config.GetBizQueryConfig(ctx, name); err == nil && cfg != nil && !cfg.Enabled {
+			continue
+		}
+		bizNames = append(bizNames, name)
+	}
+	sort.Strings(bizNames)
+
+	entries := make([]BizEntry, len(bizNames))
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for i, name := range bizNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				entries[i] = BizEntry{BizName: name, Tables: []TableEntry{}, Error: ctx.Err().Error()}
+				return
+			}
+			bizCtx, cancel := context.WithTimeout(ctx, s.bizTimeout)
+			defer cancel()
+			entries[i] = s.describeBiz(bizCtx, name, s.registry[name])
+		}(i, name)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Catalog{Bizs: entries, GeneratedAt: time.Now().UTC()}, nil
+}
+
+// describeBiz 汇总单个业务组。检索权限以数据源的判断为准：记录数查询被拒绝的表不列出，
+// 全部表都被拒绝时视为不公开检索。
+func (s *Service) describeBiz(ctx context.Context, bizName string, dataSource port.DataSource) BizEntry {
+	entry := BizEntry{BizName: bizName, Source: dataSource.Type(), Tables: []TableEntry{}}
+	fail := func(err error) BizEntry {
+		slog.Warn("[Catalog] 汇总业务组失败", "biz", bizName, "error", err)
+		entry.Error = err.Error()
+		return entry
+	}
+
+	// 虚拟业务组没有自己的查询配置，此时只依赖数据源返回的信息
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return fail(err)
+	}
+	if bizConfig != nil {
+		if !bizConfig.IsPubliclySearchable {
+			return entry
+		}
+		entry.DefaultTable = bizConfig.DefaultQueryTable
+	}
+
+	schema, err := dataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName})
+	if err != nil {
+		return fail(err)
+	}
+	views, err := s.config.GetAllViewConfigsForBiz(ctx, bizName)
+	if err != nil {
+		return fail(err)
+	}
+
+	tableNames := make([]string, 0, len(schema.Tables))
+	for name := range schema.Tables {
+		if bizConfig != nil {
+			if tableConfig, ok := bizConfig.Tables[name]; !ok || !tableConfig.IsSearchable {
+				continue
+			}
+		}
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	for _, name := range tableNames {
+		rows, err := countRows(ctx, dataSource, bizName, name)
+		if errors.Is(err, port.ErrPermissionDenied) {
+			continue
+		}
+		if err != nil {
+			return fail(fmt.Errorf("统计表 '%s' 的记录数失败: %w", name, err))
+		}
+		table := TableEntry{Name: name, Fields: schema.Tables[name], Rows: rows, Views: []ViewEntry{}}
+		if table.Fields == nil {
+			table.Fields = []port.FieldDescription{}
+		}
+		for _, v := range views[name] {
+			table.Views = append(table.Views, ViewEntry{ViewName: v.ViewName, ViewType: v.ViewType, DisplayName: v.DisplayName, IsDefault: v.IsDefault})
+		}
+		entry.Tables = append(entry.Tables, table)
+	}
+	entry.IsPubliclySearchable = len(entry.Tables) > 0
+	return entry
+}
+
+// countRows 以不带过滤条件、只取一条的普通查询读取表的记录总数，
+// 这样无需扩展数据源协议，且与公开检索遵循相同的权限与虚拟表过滤条件
+func countRows(ctx context.Context, dataSource port.DataSource, bizName, tableName string) (*int64, error) {
+	res, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query:   map[string]interface{}{"table": tableName, "page": float64(1), "size": float64(1)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	switch n := res.Data["total"].(type) {
+	case int:
+		total = int64(n)
+	case int64:
+		total = n
+	case float64: // 经 gRPC (structpb) 传输的数字
+		total = int64(n)
+	default:
+		return nil, nil
+	}
+	return &total, nil
+}
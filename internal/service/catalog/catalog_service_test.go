@@ -0,0 +1,99 @@
+// file: internal/service/catalog/catalog_service_test.go
+package catalog
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSource 返回一个内存数据源，每张表只有 title 字段，记录数由 counts 指定；restricted 表拒绝访问
+func newSource(counts map[string]int) *fixture.FakeDataSource {
+	source := fixture.NewFakeDataSource().FailQueryOn("restricted", port.ErrPermissionDenied)
+	fields := []port.FieldDescription{{Name: "title", DataType: "TEXT", IsSearchable: true, IsReturnable: true}}
+	for table, count := range counts {
+		rows := make([]map[string]interface{}, count)
+		for i := range rows {
+			rows[i] = map[string]interface{}{"title": fmt.Sprintf("%s-%d", table, i)}
+		}
+		source.WithTable(table, fields, rows...)
+	}
+	return source
+}
+
+func newConfig(t *testing.T) port.QueryAdminConfigService {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES
+		('library', TRUE, 'books'), ('private', FALSE, 'docs'), ('broken', TRUE, 'items')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES
+		('library', 'books'), ('library', 'authors'), ('library', 'restricted'), ('private', 'docs'), ('broken', 'items')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateAllViewsForBiz(context.Background(), "library", map[string][]*domain.ViewConfig{
+		"books": {{ViewName: "cards", ViewType: "cards", DisplayName: "卡片", IsDefault: true}},
+	}))
+	return config
+}
+
+func TestCatalog(t *testing.T) {
+	ctx := context.Background()
+	library := newSource(map[string]int{"books": 42, "authors": 7, "restricted": 1, "staging": 3})
+	registry := map[string]port.DataSource{
+		"library": library,
+		"private": newSource(map[string]int{"docs": 1}),
+		"broken":  fixture.NewFakeDataSource().FailSchema(errors.New("plugin unavailable")),
+	}
+	svc, err := NewService(newConfig(t), registry, Options{})
+	require.NoError(t, err)
+
+	catalog, err := svc.Get(ctx)
+	require.NoError(t, err)
+	require.Len(t, catalog.Bizs, 3)
+	assert.Equal(t, []string{"broken", "library", "private"}, []string{catalog.Bizs[0].BizName, catalog.Bizs[1].BizName, catalog.Bizs[2].BizName})
+
+	assert.Contains(t, catalog.Bizs[0].Error, "plugin unavailable", "单个业务组出错不影响其它业务组")
+
+	lib := catalog.Bizs[1]
+	assert.True(t, lib.IsPubliclySearchable)
+	assert.Equal(t, "books", lib.DefaultTable)
+	require.Len(t, lib.Tables, 2, "未设为可检索与数据源拒绝访问的表不列出")
+	assert.Equal(t, "authors", lib.Tables[0].Name)
+	assert.EqualValues(t, 7, *lib.Tables[0].Rows)
+	assert.Empty(t, lib.Tables[0].Views)
+	books := lib.Tables[1]
+	assert.EqualValues(t, 42, *books.Rows)
+	assert.Equal(t, []ViewEntry{{ViewName: "cards", ViewType: "cards", DisplayName: "卡片", IsDefault: true}}, books.Views)
+	require.Len(t, books.Fields, 1)
+
+	private := catalog.Bizs[2]
+	assert.False(t, private.IsPubliclySearchable)
+	assert.Empty(t, private.Tables, "不公开检索的业务组只列出名称")
+
+	// 缓存期内不再访问数据源，失效后重建
+	queried := len(library.Queries())
+	require.Positive(t, queried)
+	_, err = svc.Get(ctx)
+	require.NoError(t, err)
+	assert.Len(t, library.Queries(), queried)
+	svc.Invalidate()
+	_, err = svc.Get(ctx)
+	require.NoError(t, err)
+	assert.Len(t, library.Queries(), 2*queried)
+}
+
+func TestNewServiceRejectsNegativeOptions(t *testing.T) {
+	_, err := NewService(newConfig(t), nil, Options{CacheTTLSeconds: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+// Package colstats file: internal/service/colstats/colstats_service.go
+package colstats
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTTLMinutes             = 10
+	defaultDropdownMaxCardinality = 50
+)
+
+// Options 定义字段统计的缓存与过滤控件建议
+type Options struct {
+	// TTLMinutes 是统计结果的缓存时间，默认为 10 分钟
+	TTLMinutes int `mapstructure:"ttl_minutes"`
+	// DropdownMaxCardinality 是建议使用下拉选择的最大不同取值数，默认为 50
+	DropdownMaxCardinality int64 `mapstructure:"dropdown_max_cardinality"`
+}
+
+// Service 按需计算表中可返回字段的基数、最小值、最大值与 NULL 比例 (见 port.QueryModeStats) 并缓存，
+// 同时为每个字段给出过滤控件的建议。统计需要扫描全表，同一张表的并发请求只计算一次
+type Service struct {
+	registry map[string]port.DataSource
+	opts     Options
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// entry 是一张表的缓存项，mu 保证同一张表同时只有一次计算
+type entry struct {
+	mu    sync.Mutex
+	stats *domain.TableStats
+}
+
+// NewService 创建一个新的字段统计服务实例
+func NewService(registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if registry == nil {
+		return nil, errors.New("colstats.Service 需要有效的数据源注册表")
+	}
+	if opts.TTLMinutes < 0 || opts.DropdownMaxCardinality < 0 {
+		return nil, fmt.Errorf("ttl_minutes 与 dropdown_max_cardinality 不能为负数")
+	}
+	if opts.TTLMinutes == 0 {
+		opts.TTLMinutes = defaultTTLMinutes
+	}
+	if opts.DropdownMaxCardinality == 0 {
+		opts.DropdownMaxCardinality = defaultDropdownMaxCardinality
+	}
+	return &Service{registry: registry, opts: opts, now: time.Now, entries: make(map[string]*entry)}, nil
+}
+
+// Get 返回表的字段统计，缓存过期或不存在时重新计算
+func (s *Service) Get(ctx context.Context, bizName, tableName string) (*domain.TableStats, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+
+	key := bizName + "\x00" + tableName
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	s.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stats != nil && s.now().Sub(e.stats.ComputedAt) < time.Duration(s.opts.TTLMinutes)*time.Minute {
+		return e.stats, nil
+	}
+	stats, err := s.compute(ctx, dataSource, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	e.stats = stats
+	return stats, nil
+}
+
+// Cached 返回表最近一次计算的字段统计 (可能已过期)，从未计算过或正在计算时返回 nil，不会触发或等待计算
+func (s *Service) Cached(bizName, tableName string) *domain.TableStats {
+	s.mu.Lock()
+	e, ok := s.entries[bizName+"\x00"+tableName]
+	s.mu.Unlock()
+	if !ok || !e.mu.TryLock() {
+		return nil
+	}
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+func (s *Service) compute(ctx context.Context, dataSource port.DataSource, bizName, tableName string) (*domain.TableStats, error) {
+	res, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query:   map[string]interface{}{port.QueryModeKey: port.QueryModeStats, "table": tableName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 经 gRPC 传输后数值均为 float64，借助 JSON 转换为结构体
+	var decoded struct {
+		Total  int64                `json:"total"`
+		Fields []domain.ColumnStats `json:"fields"`
+	}
+	raw, err := json.Marshal(res.Data)
+	if err != nil {
+		return nil, fmt.Errorf("解析字段统计失败: %w", err)
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("解析字段统计失败: %w", err)
+	}
+
+	stats := &domain.TableStats{
+		BizName:    bizName,
+		Table:      tableName,
+		Total:      decoded.Total,
+		Columns:    decoded.Fields,
+		ComputedAt: s.now().UTC(),
+	}
+	if stats.Columns == nil {
+		stats.Columns = make([]domain.ColumnStats, 0)
+	}
+	for i := range stats.Columns {
+		stats.Columns[i].FilterHint = s.filterHint(&stats.Columns[i])
+	}
+	return stats, nil
+}
+
+// filterHint 建议字段的过滤控件：不同取值不多于 DropdownMaxCardinality 时使用下拉选择，数值字段使用范围过滤，其余为自由文本
+func (s *Service) filterHint(col *domain.ColumnStats) string {
+	if col.CardinalityExact && col.Cardinality > 0 && col.Cardinality <= s.opts.DropdownMaxCardinality {
+		return domain.FilterHintDropdown
+	}
+	_, minNumeric := col.Min.(float64)
+	_, maxNumeric := col.Max.(float64)
+	if minNumeric && maxNumeric {
+		return domain.FilterHintRange
+	}
+	return domain.FilterHintText
+}
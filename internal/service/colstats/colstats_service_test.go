@@ -0,0 +1,85 @@
+// file: internal/service/colstats/colstats_service_test.go
+package colstats
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statsSource 以 gRPC 插件的形式 (数值为 float64) 返回固定的字段统计，并记录被调用的次数
+type statsSource struct {
+	port.DataSource
+	calls atomic.Int32
+}
+
+func (s *statsSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	s.calls.Add(1)
+	if req.Query[port.QueryModeKey] != port.QueryModeStats {
+		return nil, port.ErrPermissionDenied
+	}
+	if req.Query["table"] != "letters" {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	return &port.QueryResult{Data: map[string]interface{}{
+		"table": "letters",
+		"total": float64(200),
+		"fields": []interface{}{
+			map[string]interface{}{"field": "place", "nulls": float64(20), "null_ratio": 0.1, "cardinality": float64(12), "cardinality_exact": true, "min": "上海", "max": "北京"},
+			map[string]interface{}{"field": "sender", "nulls": float64(0), "null_ratio": 0.0, "cardinality": float64(180), "cardinality_exact": true, "min": "a", "max": "z"},
+			map[string]interface{}{"field": "year", "nulls": float64(0), "null_ratio": 0.0, "cardinality": float64(90), "cardinality_exact": false, "min": float64(1900), "max": float64(1949)},
+		},
+	}}, nil
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	source := &statsSource{}
+	svc, err := NewService(map[string]port.DataSource{"archive": source}, Options{})
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	stats, err := svc.Get(ctx, "archive", "letters")
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, stats.Total)
+	require.Len(t, stats.Columns, 3)
+	assert.Equal(t, domain.FilterHintDropdown, stats.Columns[0].FilterHint)
+	assert.EqualValues(t, 20, stats.Columns[0].Nulls)
+	assert.Equal(t, domain.FilterHintText, stats.Columns[1].FilterHint)
+	assert.Equal(t, domain.FilterHintRange, stats.Columns[2].FilterHint)
+	assert.Equal(t, now, stats.ComputedAt)
+
+	// 并发请求与缓存有效期内的请求不再重新计算
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Get(ctx, "archive", "letters")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, source.calls.Load())
+
+	now = now.Add(defaultTTLMinutes * time.Minute)
+	_, err = svc.Get(ctx, "archive", "letters")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, source.calls.Load(), "缓存过期后重新计算")
+
+	_, err = svc.Get(ctx, "archive", "missing")
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = svc.Get(ctx, "nope", "letters")
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+
+	_, err = NewService(map[string]port.DataSource{}, Options{TTLMinutes: -1})
+	assert.Error(t, err)
+}
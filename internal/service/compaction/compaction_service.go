@@ -0,0 +1,133 @@
+// Package compaction file: internal/service/compaction/compaction_service.go
+package compaction
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// JobKindCompact 是库文件压缩任务的类型。任务目标为空，同一业务组同时只运行一个压缩任务
+const JobKindCompact = "compaction"
+
+// ErrInvalidRequest 表示压缩请求的参数不合法
+var ErrInvalidRequest = errors.New("无效的库文件压缩请求")
+
+// Request 描述一次库文件压缩
+type Request struct {
+	// Libs 是待合并的库名，至少两个
+	Libs []string `json:"libs"`
+	// Target 是合并后的库名，缺省为 Libs 中的第一个
+	Target string `json:"target"`
+	// ProvenanceColumn 是记录每行来源库的列名，缺省由数据源决定 ("_source_lib")
+	ProvenanceColumn string `json:"provenance_column"`
+}
+
+// Service 以后台任务的形式驱动数据源把业务组的多个库文件合并为一个 (见 port.MutateOpCompact)：
+// 先合并到暂存文件并核对行数，再以暂存文件替换源库；任一步骤失败时丢弃暂存文件，源库保持不变。
+type Service struct {
+	registry map[string]port.DataSource
+	jobs     *jobs.Service
+}
+
+// NewService 创建一个新的库文件压缩服务实例
+func NewService(registry map[string]port.DataSource, jobService *jobs.Service) (*Service, error) {
+	if registry == nil || jobService == nil {
+		return nil, errors.New("compaction.Service 需要有效的数据源注册表与后台任务服务")
+	}
+	return &Service{registry: registry, jobs: jobService}, nil
+}
+
+// Compact 校验请求并启动压缩任务。同一业务组已有压缩任务在运行时返回 jobs.ErrJobConflict
+func (s *Service) Compact(ctx context.Context, bizName string, req Request, userID int64) (*domain.BackgroundJob, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	seen := make(map[string]bool, len(req.Libs))
+	libs := make([]interface{}, 0, len(req.Libs))
+	for _, lib := range req.Libs {
+		lib = strings.TrimSpace(lib)
+		if lib == "" || seen[lib] {
+			return nil, fmt.Errorf("%w: 库名 '%s' 为空或重复", ErrInvalidRequest, lib)
+		}
+		seen[lib] = true
+		libs = append(libs, lib)
+	}
+	if len(libs) < 2 {
+		return nil, fmt.Errorf("%w: 至少需要两个库", ErrInvalidRequest)
+	}
+	target := strings.TrimSpace(req.Target)
+	if target == "" {
+		target = libs[0].(string)
+	}
+	if strings.ContainsAny(target, `/\`) || strings.HasPrefix(target, ".") {
+		return nil, fmt.Errorf("%w: 目标库名 '%s' 不合法", ErrInvalidRequest, target)
+	}
+
+	base := map[string]interface{}{"libs": libs, "target": target}
+	if req.ProvenanceColumn != "" {
+		base["provenance_column"] = strings.TrimSpace(req.ProvenanceColumn)
+	}
+	return s.jobs.Start(ctx, JobKindCompact, bizName, "", userID, func(ctx context.Context, progress *jobs.Progress) error {
+		return run(ctx, dataSource, bizName, base, progress)
+	})
+}
+
+// run 是压缩任务的执行体
+func run(ctx context.Context, dataSource port.DataSource, bizName string, base map[string]interface{}, progress *jobs.Progress) error {
+	step := func(ctx context.Context, name string) (map[string]interface{}, error) {
+		payload := map[string]interface{}{"step": name}
+		for k, v := range base {
+			payload[k] = v
+		}
+		res, err := port.AdminMutate(ctx, dataSource, port.MutateRequest{BizName: bizName, Operation: port.MutateOpCompact, Payload: payload})
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	}
+	// abort 在任务失败或被取消后清理暂存文件，此时任务的 ctx 可能已被取消
+	abort := func() {
+		if _, err := step(context.Background(), "abort"); err != nil {
+			slog.Warn("[Compaction] 清理暂存文件失败", "biz", bizName, "error", err)
+		}
+	}
+
+	progress.SetTotal(2)
+	progress.SetMessage(fmt.Sprintf("正在把 %d 个库合并到 '%s'", len(base["libs"].([]interface{})), base["target"]))
+	merged, err := step(ctx, "merge")
+	if err != nil {
+		abort()
+		return err
+	}
+	progress.Add(1)
+	if err := ctx.Err(); err != nil {
+		abort()
+		return err
+	}
+
+	progress.SetMessage("合并完成，正在核对行数并替换库文件")
+	swapped, err := step(ctx, "swap")
+	if err != nil {
+		abort()
+		return err
+	}
+	progress.Add(1)
+
+	tables, _ := merged["tables"].([]interface{})
+	summary := make([]string, 0, len(tables))
+	for _, raw := range tables {
+		entry, _ := raw.(map[string]interface{})
+		summary = append(summary, fmt.Sprintf("%v: %v 行", entry["table"], entry["rows"]))
+	}
+	progress.SetMessage(fmt.Sprintf("已合并为 '%s' (%s)，源库已移至 %v；全文索引需重新建立",
+		base["target"], strings.Join(summary, ", "), swapped["backup_dir"]))
+	slog.Info("[Compaction] 库文件压缩完成", "biz", bizName, "target", base["target"], "backup_dir", swapped["backup_dir"])
+	return nil
+}
@@ -0,0 +1,98 @@
+// file: internal/service/compaction/compaction_service_test.go
+package compaction
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('library', 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable) VALUES
+		('library', 'books', 'title', TRUE, TRUE)`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	bizDir := filepath.Join(root, "library")
+	require.NoError(t, os.Mkdir(bizDir, 0o755))
+	for i, name := range []string{"part1", "part2", "part3"} {
+		libDB, err := sql.Open("sqlite", filepath.Join(bizDir, name+".db"))
+		require.NoError(t, err)
+		_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT)`)
+		require.NoError(t, err)
+		_, err = libDB.Exec(`INSERT INTO books (id, title) VALUES (?, ?)`, i+1, name)
+		require.NoError(t, err)
+		require.NoError(t, libDB.Close())
+	}
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+	jobService, err := jobs.NewService(sysDB)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	svc, err := NewService(map[string]port.DataSource{"library": manager}, jobService)
+	require.NoError(t, err)
+
+	_, err = svc.Compact(ctx, "library", Request{Libs: []string{"part1"}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = svc.Compact(ctx, "library", Request{Libs: []string{"part1", "part2"}, Target: "../x"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = svc.Compact(ctx, "missing", Request{Libs: []string{"part1", "part2"}}, 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+
+	// 不存在的库使任务失败，源库保持不变
+	job, err := svc.Compact(ctx, "library", Request{Libs: []string{"part1", "nope"}}, 1)
+	require.NoError(t, err)
+	failed := waitJob(t, jobService, job)
+	assert.Equal(t, domain.JobStatusFailed, failed.Status)
+	assert.FileExists(t, filepath.Join(bizDir, "part1.db"))
+
+	job, err = svc.Compact(ctx, "library", Request{Libs: []string{"part1", "part2", "part3"}, Target: "all"}, 1)
+	require.NoError(t, err)
+	finished := waitJob(t, jobService, job)
+	require.Equal(t, domain.JobStatusSucceeded, finished.Status, finished.Error)
+	assert.EqualValues(t, 2, finished.ProgressDone)
+	assert.Contains(t, finished.Message, "books: 3 行")
+
+	matches, err := filepath.Glob(filepath.Join(bizDir, "*.db"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(bizDir, "all.db")}, matches)
+
+	res, err := manager.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{"table": "books"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.Data["total"])
+}
+
+func waitJob(t *testing.T, jobService *jobs.Service, job *domain.BackgroundJob) *domain.BackgroundJob {
+	t.Helper()
+	require.NotNil(t, job)
+	var current *domain.BackgroundJob
+	require.Eventually(t, func() bool {
+		var err error
+		current, err = jobService.Get(context.Background(), job.ID)
+		require.NoError(t, err)
+		return current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return current
+}
@@ -0,0 +1,424 @@
+// Package compliance file: internal/service/compliance/compliance_service.go
+package compliance
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultIntervalMinutes = 60
+	defaultAuditLimit      = 500
+	maxAuditLimit          = 10000
+
+	// 写入导出目录的文件名
+	JSONFileName       = "archiveaegis_compliance.json"
+	TextfileFileName   = "archiveaegis_compliance.prom"
+	metricPrefix       = "archiveaegis_compliance_"
+	defaultIPPerMinute = 60
+	defaultIPBurst     = 20
+)
+
+// ErrNoExportDir 表示未配置导出目录，无法写入快照文件
+var ErrNoExportDir = errors.New("未配置合规快照的导出目录")
+
+// Options 定义合规导出的配置
+type Options struct {
+	// Dir 不为空时定期把快照写入该目录 (JSON 与 Prometheus textfile 各一份)，供 SIEM 或 node_exporter 采集
+	Dir string `mapstructure:"dir"`
+	// IntervalMinutes 是写入导出目录的间隔，为 0 时使用 60
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// AuditLimit 是快照中每个审计来源保留的最近事件数，为 0 时使用 500
+	AuditLimit int `mapstructure:"audit_limit"`
+}
+
+// Service 生成安全相关配置与近期审计记录的快照，可经管理接口下载，也可定期写入导出目录
+type Service struct {
+	db   *sql.DB
+	opts Options
+	now  func() time.Time
+}
+
+// NewService 创建一个新的合规导出服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("compliance.Service 需要一个有效的数据库连接")
+	}
+	if opts.IntervalMinutes < 0 {
+		return nil, fmt.Errorf("interval_minutes 不能为负数: %d", opts.IntervalMinutes)
+	}
+	if opts.AuditLimit < 0 || opts.AuditLimit > maxAuditLimit {
+		return nil, fmt.Errorf("audit_limit 必须在 0 到 %d 之间: %d", maxAuditLimit, opts.AuditLimit)
+	}
+	if opts.IntervalMinutes == 0 {
+		opts.IntervalMinutes = defaultIntervalMinutes
+	}
+	if opts.AuditLimit == 0 {
+		opts.AuditLimit = defaultAuditLimit
+	}
+	return &Service{db: db, opts: opts, now: time.Now}, nil
+}
+
+// Run 在配置了导出目录时立即写入一次快照，之后按配置的间隔重复写入，ctx 结束时返回
+func (s *Service) Run(ctx context.Context) {
+	if s.opts.Dir == "" {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.opts.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := s.WriteFiles(ctx); err != nil {
+			slog.Error("[Compliance] 写入合规快照失败", "dir", s.opts.Dir, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WriteFiles 生成快照并写入导出目录。文件先写入临时文件再重命名，采集方不会读到写了一半的文件
+func (s *Service) WriteFiles(ctx context.Context) error {
+	if s.opts.Dir == "" {
+		return ErrNoExportDir
+	}
+	report, err := s.Generate(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.opts.Dir, 0o750); err != nil {
+		return fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(s.opts.Dir, JSONFileName), data); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.opts.Dir, TextfileFileName), Textfile(report))
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("写入 '%s' 失败: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("替换 '%s' 失败: %w", path, err)
+	}
+	return nil
+}
+
+// Generate 从系统库读取当前的访问控制配置与各审计来源最近的事件，生成快照
+func (s *Service) Generate(ctx context.Context) (*domain.ComplianceReport, error) {
+	report := &domain.ComplianceReport{GeneratedAt: s.now().UTC()}
+	var err error
+	if report.Users, err = s.users(ctx); err != nil {
+		return nil, err
+	}
+	if report.IPRateLimit, err = s.ipRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	if report.Bizs, err = s.bizs(ctx); err != nil {
+		return nil, err
+	}
+	if report.Audit, err = s.audit(ctx); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) users(ctx context.Context) ([]domain.ComplianceUser, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, username, role, rate_limit_per_second, burst_size FROM _user ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("读取用户失败: %w", err)
+	}
+	defer rows.Close()
+	users := make([]domain.ComplianceUser, 0)
+	for rows.Next() {
+		var u domain.ComplianceUser
+		var rate sql.NullFloat64
+		var burst sql.NullInt64
+		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &rate, &burst); err != nil {
+			return nil, err
+		}
+		if rate.Valid {
+			u.RateLimit = &domain.UserLimitSetting{RateLimitPerSecond: rate.Float64, BurstSize: int(burst.Int64)}
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (s *Service) ipRateLimit(ctx context.Context) (domain.IPLimitSetting, error) {
+	setting := domain.IPLimitSetting{RateLimitPerMinute: defaultIPPerMinute, BurstSize: defaultIPBurst}
+	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM global_settings WHERE key IN ('ip_rate_limit_per_minute', 'ip_burst_size')`)
+	if err != nil {
+		return setting, fmt.Errorf("读取 IP 速率限制失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return setting, err
+		}
+		switch key {
+		case "ip_rate_limit_per_minute":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				setting.RateLimitPerMinute = v
+			}
+		case "ip_burst_size":
+			if v, err := strconv.Atoi(value); err == nil {
+				setting.BurstSize = v
+			}
+		}
+	}
+	return setting, rows.Err()
+}
+
+func (s *Service) bizs(ctx context.Context) ([]domain.ComplianceBiz, error) {
+	byName := make(map[string]*domain.ComplianceBiz)
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, enabled, is_publicly_searchable FROM biz_overall_settings ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("读取业务组设置失败: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		b := &domain.ComplianceBiz{Owners: []string{}, WritableTables: []domain.ComplianceTableWrite{}}
+		if err := rows.Scan(&b.BizName, &b.Enabled, &b.Public); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byName[b.BizName] = b
+		names = append(names, b.BizName)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT biz_name, table_name, allow_create, allow_update, allow_delete FROM biz_searchable_tables
+		WHERE allow_create OR allow_update OR allow_delete ORDER BY biz_name, table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("读取表写权限失败: %w", err)
+	}
+	for rows.Next() {
+		var bizName string
+		var w domain.ComplianceTableWrite
+		if err := rows.Scan(&bizName, &w.Table, &w.AllowCreate, &w.AllowUpdate, &w.AllowDelete); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if b := byName[bizName]; b != nil {
+			b.WritableTables = append(b.WritableTables, w)
+		}
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT o.biz_name, u.username FROM biz_owners o JOIN _user u ON u.id = o.user_id ORDER BY o.biz_name, u.username`)
+	if err != nil {
+		return nil, fmt.Errorf("读取业务组委派管理员失败: %w", err)
+	}
+	for rows.Next() {
+		var bizName, username string
+		if err := rows.Scan(&bizName, &username); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if b := byName[bizName]; b != nil {
+			b.Owners = append(b.Owners, username)
+		}
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT biz_name, rate_limit_per_second, burst_size, anon_rate_limit_per_second, anon_burst_size, anon_max_page_size, anon_response_delay_ms
+		FROM biz_ratelimit_settings`)
+	if err != nil {
+		return nil, fmt.Errorf("读取业务组速率限制失败: %w", err)
+	}
+	for rows.Next() {
+		var bizName string
+		var limit domain.BizRateLimitSetting
+		var anonRate sql.NullFloat64
+		var anon domain.AnonymousAccessSetting
+		if err := rows.Scan(&bizName, &limit.RateLimitPerSecond, &limit.BurstSize, &anonRate, &anon.BurstSize, &anon.MaxPageSize, &anon.ResponseDelayMs); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if anonRate.Valid {
+			anon.RateLimitPerSecond = anonRate.Float64
+			limit.Anonymous = &anon
+		}
+		if b := byName[bizName]; b != nil {
+			b.RateLimit = &limit
+		}
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+
+	bizs := make([]domain.ComplianceBiz, 0, len(names))
+	for _, name := range names {
+		bizs = append(bizs, *byName[name])
+	}
+	return bizs, nil
+}
+
+// audit 读取各审计来源最近的事件，每个来源按编号倒序保留至多 AuditLimit 条。
+// 写操作只记录位置与类型，不含写入前后的数据
+func (s *Service) audit(ctx context.Context) ([]domain.ComplianceAuditItem, error) {
+	sources := []struct {
+		source string
+		query  string
+	}{
+		{domain.AuditSourceWrites, `SELECT id, CAST(timestamp AS TEXT), user_id, operation_type, biz_name || '/' || table_name || '/' || target_pk, status
+			FROM operation_log ORDER BY id DESC LIMIT ?`},
+		{domain.AuditSourceChangeRequests, `SELECT id, CAST(created_at AS TEXT), requested_by, kind, target, status
+			FROM admin_change_requests ORDER BY id DESC LIMIT ?`},
+		{domain.AuditSourceDataSubjects, `SELECT id, CAST(created_at AS TEXT), requested_by, action, bizs, CASE WHEN dry_run THEN 'dry_run' ELSE '' END
+			FROM data_subject_requests ORDER BY id DESC LIMIT ?`},
+	}
+	items := make([]domain.ComplianceAuditItem, 0)
+	for _, src := range sources {
+		rows, err := s.db.QueryContext(ctx, src.query, s.opts.AuditLimit)
+		if err != nil {
+			return nil, fmt.Errorf("读取审计来源 '%s' 失败: %w", src.source, err)
+		}
+		for rows.Next() {
+			item := domain.ComplianceAuditItem{Source: src.source}
+			var id int64
+			var eventTime, target, status sql.NullString
+			var userID sql.NullInt64
+			if err := rows.Scan(&id, &eventTime, &userID, &item.Action, &target, &status); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			item.ID = strconv.FormatInt(id, 10)
+			item.Time, item.Target, item.Status = eventTime.String, target.String, status.String
+			if userID.Valid {
+				uid := userID.Int64
+				item.UserID = &uid
+			}
+			items = append(items, item)
+		}
+		if err := closeRows(rows); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func closeRows(rows *sql.Rows) error {
+	err := rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
+	return err
+}
+
+// Textfile 把快照渲染为 Prometheus textfile 格式的指标，供 node_exporter 的 textfile collector 采集。
+// 审计事件只以各来源的条数给出
+func Textfile(report *domain.ComplianceReport) []byte {
+	var buf bytes.Buffer
+	gauge := func(name, help string) {
+		fmt.Fprintf(&buf, "# HELP %s%s %s\n# TYPE %s%s gauge\n", metricPrefix, name, help, metricPrefix, name)
+	}
+	sample := func(name string, value float64, labels ...string) {
+		buf.WriteString(metricPrefix + name)
+		if len(labels) > 0 {
+			pairs := make([]string, 0, len(labels)/2)
+			for i := 0; i+1 < len(labels); i += 2 {
+				pairs = append(pairs, labels[i]+`="`+labelEscaper.Replace(labels[i+1])+`"`)
+			}
+			buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+		buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+	}
+	boolValue := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	gauge("generated_timestamp_seconds", "合规快照的生成时间")
+	sample("generated_timestamp_seconds", float64(report.GeneratedAt.Unix()))
+
+	roles := make(map[string]int)
+	for _, u := range report.Users {
+		roles[u.Role]++
+	}
+	roleNames := make([]string, 0, len(roles))
+	for r := range roles {
+		roleNames = append(roleNames, r)
+	}
+	sort.Strings(roleNames)
+	gauge("users", "各角色的账号数")
+	for _, r := range roleNames {
+		sample("users", float64(roles[r]), "role", r)
+	}
+
+	gauge("ip_rate_limit_per_minute", "未认证 IP 的每分钟请求数上限")
+	sample("ip_rate_limit_per_minute", report.IPRateLimit.RateLimitPerMinute)
+
+	gauge("biz_enabled", "业务组是否启用")
+	for _, b := range report.Bizs {
+		sample("biz_enabled", boolValue(b.Enabled), "biz", b.BizName)
+	}
+	gauge("biz_public", "业务组是否允许公开检索")
+	for _, b := range report.Bizs {
+		sample("biz_public", boolValue(b.Public), "biz", b.BizName)
+	}
+	gauge("biz_owners", "业务组的委派管理员数")
+	for _, b := range report.Bizs {
+		sample("biz_owners", float64(len(b.Owners)), "biz", b.BizName)
+	}
+	gauge("biz_rate_limit_per_second", "业务组的每秒请求数上限，未单独配置的业务组不输出")
+	for _, b := range report.Bizs {
+		if b.RateLimit != nil {
+			sample("biz_rate_limit_per_second", b.RateLimit.RateLimitPerSecond, "biz", b.BizName)
+		}
+	}
+	gauge("table_write_allowed", "表是否开放某种写操作，只输出至少开放一种写操作的表")
+	for _, b := range report.Bizs {
+		for _, w := range b.WritableTables {
+			sample("table_write_allowed", boolValue(w.AllowCreate), "biz", b.BizName, "table", w.Table, "operation", "create")
+			sample("table_write_allowed", boolValue(w.AllowUpdate), "biz", b.BizName, "table", w.Table, "operation", "update")
+			sample("table_write_allowed", boolValue(w.AllowDelete), "biz", b.BizName, "table", w.Table, "operation", "delete")
+		}
+	}
+
+	counts := map[string]int{domain.AuditSourceWrites: 0, domain.AuditSourceChangeRequests: 0, domain.AuditSourceDataSubjects: 0}
+	for _, item := range report.Audit {
+		counts[item.Source]++
+	}
+	gauge("audit_events", "快照中各审计来源的最近事件数")
+	for _, src := range []string{domain.AuditSourceWrites, domain.AuditSourceChangeRequests, domain.AuditSourceDataSubjects} {
+		sample("audit_events", float64(counts[src]), "source", src)
+	}
+	return buf.Bytes()
+}
+
+// labelEscaper 按 Prometheus 文本格式转义标签值
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
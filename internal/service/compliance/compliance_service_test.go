@@ -0,0 +1,84 @@
+// file: internal/service/compliance/compliance_service_test.go
+package compliance
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAndWriteFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+
+	for _, stmt := range []string{
+		`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'root', 'x', 'admin'), (2, 'editor', 'x', 'user')`,
+		`UPDATE _user SET rate_limit_per_second = 2, burst_size = 4 WHERE id = 2`,
+		`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, enabled) VALUES ('archive', 1, 1), ('private', 0, 1)`,
+		`INSERT INTO biz_searchable_tables (biz_name, table_name, allow_create, allow_update) VALUES ('archive', 'letters', 1, 1), ('archive', 'persons', 0, 0)`,
+		`INSERT INTO biz_owners (biz_name, user_id, granted_at) VALUES ('archive', 2, '2026-01-01 00:00:00')`,
+		`INSERT INTO biz_ratelimit_settings (biz_name, rate_limit_per_second, burst_size) VALUES ('archive', 8, 16)`,
+		`INSERT INTO operation_log (operation_id, user_id, biz_name, table_name, operation_type, target_pk, data_before, data_after, status)
+			VALUES ('op1', 2, 'archive', 'letters', 'UPDATE', '7', '{"secret":"old"}', '{"secret":"new"}', 'COMPLETED')`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	out := filepath.Join(dir, "export")
+	svc, err := NewService(db, Options{Dir: out})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC) }
+
+	report, err := svc.Generate(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Users, 2)
+	assert.Nil(t, report.Users[0].RateLimit)
+	assert.Equal(t, &domain.UserLimitSetting{RateLimitPerSecond: 2, BurstSize: 4}, report.Users[1].RateLimit)
+	assert.Equal(t, domain.IPLimitSetting{RateLimitPerMinute: 60, BurstSize: 20}, report.IPRateLimit)
+
+	require.Len(t, report.Bizs, 2)
+	archive := report.Bizs[0]
+	assert.True(t, archive.Public)
+	assert.Equal(t, []string{"editor"}, archive.Owners)
+	assert.Equal(t, []domain.ComplianceTableWrite{{Table: "letters", AllowCreate: true, AllowUpdate: true}}, archive.WritableTables,
+		"只列出开放了写操作的表")
+	assert.Equal(t, 8.0, archive.RateLimit.RateLimitPerSecond)
+	assert.False(t, report.Bizs[1].Public)
+	assert.Nil(t, report.Bizs[1].RateLimit)
+
+	require.Len(t, report.Audit, 1)
+	write := report.Audit[0]
+	assert.Equal(t, domain.AuditSourceWrites, write.Source)
+	assert.Equal(t, "archive/letters/7", write.Target)
+	assert.Equal(t, int64(2), *write.UserID)
+
+	require.NoError(t, svc.WriteFiles(ctx))
+	data, err := os.ReadFile(filepath.Join(out, JSONFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "快照不含写入前后的数据")
+	var decoded domain.ComplianceReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded.Bizs, 2)
+
+	prom, err := os.ReadFile(filepath.Join(out, TextfileFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(prom), `archiveaegis_compliance_biz_public{biz="private"} 0`)
+	assert.Contains(t, string(prom), `archiveaegis_compliance_table_write_allowed{biz="archive",table="letters",operation="delete"} 0`)
+	assert.Contains(t, string(prom), `archiveaegis_compliance_users{role="admin"} 1`)
+	assert.Contains(t, string(prom), `archiveaegis_compliance_audit_events{source="operation_log"} 1`)
+}
+
+func TestTextfileEscapesLabels(t *testing.T) {
+	report := &domain.ComplianceReport{Bizs: []domain.ComplianceBiz{{BizName: "a\"b\\c\nd"}}}
+	assert.Contains(t, string(Textfile(report)), `archiveaegis_compliance_biz_enabled{biz="a\"b\\c\nd"} 0`)
+}
@@ -0,0 +1,505 @@
+// Package configtemplates file: internal/service/configtemplates/configtemplates_service.go
+package configtemplates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxNameLength 是模板名称的最大长度
+const maxNameLength = 64
+
+var (
+	// ErrTemplateNotFound 表示指定的配置模板不存在
+	ErrTemplateNotFound = errors.New("配置模板不存在")
+	// ErrInvalidTemplate 表示配置模板的内容不合法
+	ErrInvalidTemplate = errors.New("无效的配置模板")
+)
+
+// templateBody 是保存在 template_json 列中的模板内容
+type templateBody struct {
+	Fields    []domain.TemplateFieldRule  `json:"fields"`
+	Views     []domain.TemplateViewRule   `json:"views,omitempty"`
+	RateLimit *domain.BizRateLimitSetting `json:"rate_limit,omitempty"`
+}
+
+// Service 管理保存在 auth.db 中的配置模板，并将模板应用到业务组：
+// 按数据源报告的物理表结构逐列匹配模板规则，生成字段配置、默认视图与速率限制，并报告与现有配置的差异。
+// 模板只为已加入业务组配置的表填充设置，不改变哪些表可被检索
+type Service struct {
+	db       *sql.DB
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+	now      func() time.Time
+}
+
+// NewService 创建一个新的配置模板服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("configtemplates.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("configtemplates.Service 需要有效的配置服务")
+	}
+	return &Service{db: db, config: config, registry: registry, now: time.Now}, nil
+}
+
+// List 返回所有配置模板，按名称排序
+func (s *Service) List(ctx context.Context) ([]domain.ConfigTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, description, template_json, COALESCE(updated_by, 0), created_at, updated_at
+		FROM biz_config_templates ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询配置模板失败: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]domain.ConfigTemplate, 0)
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tmpl)
+	}
+	return templates, rows.Err()
+}
+
+// Get 返回指定名称的配置模板
+func (s *Service) Get(ctx context.Context, name string) (*domain.ConfigTemplate, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT name, description, template_json, COALESCE(updated_by, 0), created_at, updated_at
+		FROM biz_config_templates WHERE name = ?`, name)
+	tmpl, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplate(row rowScanner) (*domain.ConfigTemplate, error) {
+	var tmpl domain.ConfigTemplate
+	var bodyJSON string
+	if err := row.Scan(&tmpl.Name, &tmpl.Description, &bodyJSON, &tmpl.UpdatedBy, &tmpl.CreatedAt, &tmpl.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("扫描配置模板失败: %w", err)
+	}
+	var body templateBody
+	if err := json.Unmarshal([]byte(bodyJSON), &body); err != nil {
+		return nil, fmt.Errorf("解析配置模板 '%s' 失败: %w", tmpl.Name, err)
+	}
+	tmpl.Fields, tmpl.Views, tmpl.RateLimit = body.Fields, body.Views, body.RateLimit
+	if tmpl.Fields == nil {
+		tmpl.Fields = []domain.TemplateFieldRule{}
+	}
+	return &tmpl, nil
+}
+
+// Save 创建或更新一个配置模板，返回保存后的模板
+func (s *Service) Save(ctx context.Context, tmpl domain.ConfigTemplate, actor int64) (*domain.ConfigTemplate, error) {
+	if err := normalize(&tmpl); err != nil {
+		return nil, err
+	}
+	bodyJSON, err := json.Marshal(templateBody{Fields: tmpl.Fields, Views: tmpl.Views, RateLimit: tmpl.RateLimit})
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置模板失败: %w", err)
+	}
+	now := s.now().UTC()
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO biz_config_templates (name, description, template_json, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, NULLIF(?, 0), ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			description = excluded.description,
+			template_json = excluded.template_json,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at`,
+		tmpl.Name, tmpl.Description, string(bodyJSON), actor, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("保存配置模板 '%s' 失败: %w", tmpl.Name, err)
+	}
+	return s.Get(ctx, tmpl.Name)
+}
+
+// Delete 删除一个配置模板，已应用过该模板的业务组配置不受影响
+func (s *Service) Delete(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_config_templates WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("删除配置模板 '%s' 失败: %w", name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// normalize 校验模板并补全默认值：数据类型默认为 string，通配模式必须合法，视图必须有名称
+func normalize(tmpl *domain.ConfigTemplate) error {
+	tmpl.Name = strings.TrimSpace(tmpl.Name)
+	if tmpl.Name == "" {
+		return fmt.Errorf("%w: name 不能为空", ErrInvalidTemplate)
+	}
+	if len(tmpl.Name) > maxNameLength {
+		return fmt.Errorf("%w: name 不能超过 %d 个字符", ErrInvalidTemplate, maxNameLength)
+	}
+	if len(tmpl.Fields) == 0 && len(tmpl.Views) == 0 && tmpl.RateLimit == nil {
+		return fmt.Errorf("%w: 模板至少需要包含字段规则、视图或速率限制之一", ErrInvalidTemplate)
+	}
+	for i := range tmpl.Fields {
+		rule := &tmpl.Fields[i]
+		if err := checkPattern(rule.Table); err != nil {
+			return fmt.Errorf("%w: 第 %d 条字段规则的 table: %v", ErrInvalidTemplate, i+1, err)
+		}
+		if err := checkPattern(rule.Column); err != nil {
+			return fmt.Errorf("%w: 第 %d 条字段规则的 column: %v", ErrInvalidTemplate, i+1, err)
+		}
+		if rule.DataType == "" {
+			rule.DataType = "string"
+		}
+	}
+	for i, rule := range tmpl.Views {
+		if err := checkPattern(rule.Table); err != nil {
+			return fmt.Errorf("%w: 第 %d 个视图的 table: %v", ErrInvalidTemplate, i+1, err)
+		}
+		if strings.TrimSpace(rule.View.ViewName) == "" {
+			return fmt.Errorf("%w: 第 %d 个视图缺少 view_name", ErrInvalidTemplate, i+1)
+		}
+	}
+	if limit := tmpl.RateLimit; limit != nil && (limit.RateLimitPerSecond <= 0 || limit.BurstSize <= 0) {
+		return fmt.Errorf("%w: rate_limit 的速率与突发容量必须为正数", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+func checkPattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// matches 判断名称是否匹配通配模式，空模式匹配任意名称
+func matches(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// Apply 将模板应用到业务组并返回差异报告；dryRun 为 true 时只生成报告。
+// overwrite 为 false 时已有的字段配置、同名视图与速率限制保持不变，只补充缺失的部分。
+// 业务组需已创建配置，且其数据源支持 port.PhysicalSchemaReader
+func (s *Service) Apply(ctx context.Context, name, bizName string, dryRun, overwrite bool) (*domain.TemplateApplyReport, error) {
+	tmpl, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	reader, ok := port.FindCapability[port.PhysicalSchemaReader](dataSource)
+	if !ok {
+		return nil, port.ErrPhysicalSchemaUnsupported
+	}
+	schema, err := reader.PhysicalSchema(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := s.configuredTables(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.fieldSettings(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.TemplateApplyReport{
+		Template:           tmpl.Name,
+		BizName:            bizName,
+		DryRun:             dryRun,
+		Overwrite:          overwrite,
+		Tables:             []domain.TemplateTableDiff{},
+		UnconfiguredTables: []string{},
+		Views:              []domain.TemplateViewDiff{},
+	}
+
+	tableNames := make([]string, 0, len(schema))
+	for table := range schema {
+		tableNames = append(tableNames, table)
+	}
+	sort.Strings(tableNames)
+
+	// final 是应用后各表的字段配置，用于校验视图绑定的字段；changedFields 是需要写回的表
+	final := make(map[string]map[string]domain.FieldSetting)
+	changedFields := make(map[string][]domain.FieldSetting)
+	applicable := make([]string, 0, len(tableNames))
+	for _, table := range tableNames {
+		if !configured[table] {
+			report.UnconfiguredTables = append(report.UnconfiguredTables, table)
+			continue
+		}
+		applicable = append(applicable, table)
+		diff, settings := diffTable(tmpl.Fields, table, schema[table], existing[table], overwrite)
+		final[table] = settings
+		if len(diff.Added) > 0 || len(diff.Changed) > 0 {
+			changedFields[table] = sortedSettings(settings)
+		}
+		report.Tables = append(report.Tables, diff)
+	}
+
+	views, viewsChanged, err := s.diffViews(ctx, tmpl.Views, bizName, applicable, final, overwrite, report)
+	if err != nil {
+		return nil, err
+	}
+
+	var rateLimit *domain.BizRateLimitSetting
+	if tmpl.RateLimit != nil {
+		current, err := s.config.GetBizRateLimitSettings(ctx, bizName)
+		if err != nil {
+			return nil, err
+		}
+		report.RateLimit = &domain.TemplateRateLimitDiff{Before: current, After: current}
+		if current == nil || overwrite {
+			rateLimit = tmpl.RateLimit
+			report.RateLimit.After = tmpl.RateLimit
+			report.RateLimit.Applied = true
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if len(changedFields) > 0 {
+		if err := s.config.UpdateFieldSettingsBulk(ctx, bizName, changedFields); err != nil {
+			return nil, err
+		}
+	}
+	if viewsChanged {
+		if err := s.config.UpdateAllViewsForBiz(ctx, bizName, views); err != nil {
+			return nil, err
+		}
+	}
+	if rateLimit != nil {
+		if err := s.config.UpdateBizRateLimitSettings(ctx, bizName, *rateLimit); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// diffTable 为一张表的每一列匹配模板规则，返回差异与应用后的字段配置 (含表中已不存在的列的原有配置)
+func diffTable(rules []domain.TemplateFieldRule, table string, columns []string, existing map[string]domain.FieldSetting, overwrite bool) (domain.TemplateTableDiff, map[string]domain.FieldSetting) {
+	diff := domain.TemplateTableDiff{
+		TableName: table,
+		Added:     []domain.FieldSetting{},
+		Changed:   []domain.TemplateFieldChange{},
+		Kept:      []string{},
+		Unmatched: []string{},
+	}
+	settings := make(map[string]domain.FieldSetting, len(existing)+len(columns))
+	for field, setting := range existing {
+		settings[field] = setting
+	}
+	for _, column := range columns {
+		rule, ok := matchField(rules, table, column)
+		if !ok {
+			diff.Unmatched = append(diff.Unmatched, column)
+			continue
+		}
+		proposed := domain.FieldSetting{
+			FieldName:         column,
+			IsSearchable:      rule.IsSearchable,
+			IsReturnable:      rule.IsReturnable,
+			DataType:          rule.DataType,
+			DisplayName:       rule.DisplayName,
+			Pinyin:            rule.Pinyin,
+			CaseInsensitive:   rule.CaseInsensitive,
+			AccentInsensitive: rule.AccentInsensitive,
+			SearchWeight:      rule.SearchWeight,
+		}
+		current, exists := existing[column]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, proposed)
+			settings[column] = proposed
+		case overwrite && current != proposed:
+			diff.Changed = append(diff.Changed, domain.TemplateFieldChange{Before: current, After: proposed})
+			settings[column] = proposed
+		default:
+			diff.Kept = append(diff.Kept, column)
+		}
+	}
+	return diff, settings
+}
+
+func matchField(rules []domain.TemplateFieldRule, table, column string) (domain.TemplateFieldRule, bool) {
+	for _, rule := range rules {
+		if matches(rule.Table, table) && matches(rule.Column, column) {
+			return rule, true
+		}
+	}
+	return domain.TemplateFieldRule{}, false
+}
+
+func sortedSettings(settings map[string]domain.FieldSetting) []domain.FieldSetting {
+	fields := make([]domain.FieldSetting, 0, len(settings))
+	for _, setting := range settings {
+		fields = append(fields, setting)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].FieldName < fields[j].FieldName })
+	return fields
+}
+
+// diffViews 将模板视图加入匹配的表，返回应用后业务组的全部视图及是否有变化。
+// 绑定了不可返回字段的视图被跳过；表已有默认视图时，新加入的视图不再作为默认视图
+func (s *Service) diffViews(ctx context.Context, rules []domain.TemplateViewRule, bizName string, tables []string, final map[string]map[string]domain.FieldSetting, overwrite bool, report *domain.TemplateApplyReport) (map[string][]*domain.ViewConfig, bool, error) {
+	if len(rules) == 0 {
+		return nil, false, nil
+	}
+	views, err := s.config.GetAllViewConfigsForBiz(ctx, bizName)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := false
+	for _, table := range tables {
+		for _, rule := range rules {
+			if !matches(rule.Table, table) {
+				continue
+			}
+			view := rule.View
+			entry := domain.TemplateViewDiff{TableName: table, ViewName: view.ViewName}
+			if missing := unreturnableFields(view, final[table]); len(missing) > 0 {
+				entry.Action = domain.TemplateViewSkipped
+				entry.Reason = fmt.Sprintf("绑定的字段不可返回: %s", strings.Join(missing, ", "))
+				report.Views = append(report.Views, entry)
+				continue
+			}
+			index := -1
+			hasOtherDefault := false
+			for i, existing := range views[table] {
+				if existing == nil {
+					continue
+				}
+				if existing.ViewName == view.ViewName {
+					index = i
+				} else if existing.IsDefault {
+					hasOtherDefault = true
+				}
+			}
+			if index >= 0 && !overwrite {
+				entry.Action = domain.TemplateViewSkipped
+				entry.Reason = "已存在同名视图"
+				report.Views = append(report.Views, entry)
+				continue
+			}
+			if hasOtherDefault {
+				view.IsDefault = false
+			}
+			if index >= 0 {
+				views[table][index] = &view
+				entry.Action = domain.TemplateViewReplaced
+			} else {
+				views[table] = append(views[table], &view)
+				entry.Action = domain.TemplateViewAdded
+			}
+			changed = true
+			report.Views = append(report.Views, entry)
+		}
+	}
+	return views, changed, nil
+}
+
+// unreturnableFields 返回视图绑定的字段中在 settings 里不可返回的字段，按名称排序
+func unreturnableFields(view domain.ViewConfig, settings map[string]domain.FieldSetting) []string {
+	bound := make(map[string]bool)
+	if card := view.Binding.Card; card != nil {
+		for _, field := range []string{card.Title, card.Subtitle, card.Description, card.ImageUrl, card.Tag} {
+			if field != "" {
+				bound[field] = true
+			}
+		}
+	}
+	if table := view.Binding.Table; table != nil {
+		for _, column := range table.Columns {
+			if column.Field != "" {
+				bound[column.Field] = true
+			}
+		}
+	}
+	for _, field := range view.Projection {
+		bound[field] = true
+	}
+	missing := make([]string, 0)
+	for field := range bound {
+		if !settings[field].IsReturnable {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// configuredTables 返回已加入业务组配置的表 (含暂不开放检索的表)，字段配置只能写入这些表
+func (s *Service) configuredTables(ctx context.Context, bizName string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT table_name FROM biz_searchable_tables WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("查询业务组 '%s' 的表配置失败: %w", bizName, err)
+	}
+	defer rows.Close()
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("扫描表配置失败: %w", err)
+		}
+		tables[table] = true
+	}
+	return tables, rows.Err()
+}
+
+// fieldSettings 直接读取业务组所有已配置表的字段配置。配置服务的缓存只包含开放检索的表，这里需要全部
+func (s *Service) fieldSettings(ctx context.Context, bizName string) (map[string]map[string]domain.FieldSetting, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT table_name, field_name, is_searchable, is_returnable, data_type, display_name, pinyin_enabled, case_insensitive, accent_insensitive, search_weight
+		FROM biz_table_field_settings WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("查询业务组 '%s' 的字段配置失败: %w", bizName, err)
+	}
+	defer rows.Close()
+	settings := make(map[string]map[string]domain.FieldSetting)
+	for rows.Next() {
+		var table string
+		var f domain.FieldSetting
+		if err := rows.Scan(&table, &f.FieldName, &f.IsSearchable, &f.IsReturnable, &f.DataType, &f.DisplayName, &f.Pinyin, &f.CaseInsensitive, &f.AccentInsensitive, &f.SearchWeight); err != nil {
+			return nil, fmt.Errorf("扫描字段配置失败: %w", err)
+		}
+		if settings[table] == nil {
+			settings[table] = make(map[string]domain.FieldSetting)
+		}
+		settings[table][f.FieldName] = f
+	}
+	return settings, rows.Err()
+}
@@ -0,0 +1,184 @@
+// file: internal/service/configtemplates/configtemplates_service_test.go
+package configtemplates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wrapper 模拟不实现 PhysicalSchemaReader 的装饰器，验证能力沿 Unwrap 链查找
+type wrapper struct{ port.DataSource }
+
+func (w wrapper) Unwrap() port.DataSource { return w.DataSource }
+
+// plainDataSource 隐藏了底层数据源的扩展能力
+type plainDataSource struct{ port.DataSource }
+
+func newTestService(t *testing.T) (*Service, *admin_config.AdminConfigServiceImpl) {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('archive', TRUE, 'books'), ('plain', FALSE, '')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "archive", []string{"books"}))
+	require.NoError(t, config.UpdateTableWritePermissions(ctx, "archive", "authors", domain.TableConfig{}))
+	require.NoError(t, config.UpdateTableFieldSettings(ctx, "archive", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true, DataType: "string", DisplayName: "书名"},
+	}))
+	require.NoError(t, config.UpdateAllViewsForBiz(ctx, "archive", map[string][]*domain.ViewConfig{
+		"books": {{ViewName: "existing", ViewType: "table", IsDefault: true}},
+	}))
+
+	ds := fixture.NewFakeDataSource().
+		WithTable("books", []port.FieldDescription{{Name: "id"}, {Name: "internal_note"}, {Name: "title"}, {Name: "year"}}).
+		WithTable("authors", []port.FieldDescription{{Name: "id"}, {Name: "name"}}).
+		WithTable("loans", []port.FieldDescription{{Name: "id"}, {Name: "reader"}})
+	svc, err := NewService(db, config, map[string]port.DataSource{"archive": wrapper{ds}, "plain": plainDataSource{ds}})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC) }
+	return svc, config
+}
+
+func testTemplate() domain.ConfigTemplate {
+	return domain.ConfigTemplate{
+		Name:        "library",
+		Description: "图书馆常用约定",
+		Fields: []domain.TemplateFieldRule{
+			{Column: "internal_*"},
+			{Column: "id", IsReturnable: true, DataType: "number"},
+			{Column: "title", IsSearchable: true, IsReturnable: true, DisplayName: "题名", CaseInsensitive: true},
+			{Table: "authors", Column: "name", IsSearchable: true, IsReturnable: true, Pinyin: true},
+		},
+		Views: []domain.TemplateViewRule{
+			{View: domain.ViewConfig{ViewName: "list", ViewType: "table", IsDefault: true, Binding: domain.ViewBinding{
+				Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{{Field: "id"}}},
+			}}},
+			{Table: "books", View: domain.ViewConfig{ViewName: "notes", ViewType: "card", Binding: domain.ViewBinding{
+				Card: &domain.CardBinding{Title: "title", Description: "internal_note"},
+			}}},
+		},
+		RateLimit: &domain.BizRateLimitSetting{RateLimitPerSecond: 5, BurstSize: 10},
+	}
+}
+
+func TestSave_Validation(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	saved, err := svc.Save(ctx, testTemplate(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "string", saved.Fields[0].DataType, "未指定数据类型时默认为 string")
+	assert.Equal(t, time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), saved.CreatedAt.UTC())
+
+	bad := testTemplate()
+	bad.Fields[0].Column = "[abc"
+	_, err = svc.Save(ctx, bad, 0)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = svc.Save(ctx, domain.ConfigTemplate{Name: "empty"}, 0)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	list, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, svc.Delete(ctx, "library"))
+	assert.ErrorIs(t, svc.Delete(ctx, "library"), ErrTemplateNotFound)
+	_, err = svc.Get(ctx, "library")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestApply(t *testing.T) {
+	svc, config := newTestService(t)
+	ctx := context.Background()
+	_, err := svc.Save(ctx, testTemplate(), 0)
+	require.NoError(t, err)
+
+	preview, err := svc.Apply(ctx, "library", "archive", true, false)
+	require.NoError(t, err)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, []string{"loans"}, preview.UnconfiguredTables, "未加入业务组配置的表只在报告中列出")
+	limit, err := config.GetBizRateLimitSettings(ctx, "archive")
+	require.NoError(t, err)
+	assert.Nil(t, limit, "预览不做任何修改")
+
+	report, err := svc.Apply(ctx, "library", "archive", false, false)
+	require.NoError(t, err)
+	assert.Equal(t, preview.Tables, report.Tables)
+	require.Len(t, report.Tables, 2)
+
+	authors := report.Tables[0]
+	assert.Equal(t, "authors", authors.TableName)
+	assert.Len(t, authors.Added, 2)
+	assert.Empty(t, authors.Unmatched)
+
+	books := report.Tables[1]
+	assert.Equal(t, "books", books.TableName)
+	assert.Equal(t, []string{"title"}, books.Kept, "未指定覆盖时保留已有的字段配置")
+	assert.Equal(t, []string{"year"}, books.Unmatched)
+	require.Len(t, books.Added, 2)
+	assert.Equal(t, domain.FieldSetting{FieldName: "id", IsReturnable: true, DataType: "number"}, books.Added[0])
+	assert.Equal(t, "internal_note", books.Added[1].FieldName)
+	assert.False(t, books.Added[1].IsReturnable)
+
+	assert.Equal(t, []domain.TemplateViewDiff{
+		{TableName: "authors", ViewName: "list", Action: domain.TemplateViewAdded},
+		{TableName: "books", ViewName: "list", Action: domain.TemplateViewAdded},
+		{TableName: "books", ViewName: "notes", Action: domain.TemplateViewSkipped, Reason: "绑定的字段不可返回: internal_note"},
+	}, report.Views)
+	require.NotNil(t, report.RateLimit)
+	assert.True(t, report.RateLimit.Applied)
+
+	fields, err := svc.fieldSettings(ctx, "archive")
+	require.NoError(t, err)
+	assert.True(t, fields["authors"]["name"].Pinyin)
+	assert.Equal(t, "书名", fields["books"]["title"].DisplayName)
+	views, err := config.GetAllViewConfigsForBiz(ctx, "archive")
+	require.NoError(t, err)
+	defaults := map[string]bool{}
+	for _, view := range views["books"] {
+		defaults[view.ViewName] = view.IsDefault
+	}
+	assert.Equal(t, map[string]bool{"existing": true, "list": false}, defaults, "已有默认视图时新视图不作为默认视图")
+	limit, err = config.GetBizRateLimitSettings(ctx, "archive")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, limit.RateLimitPerSecond)
+
+	again, err := svc.Apply(ctx, "library", "archive", false, false)
+	require.NoError(t, err)
+	assert.Empty(t, again.Tables[1].Added)
+	assert.Equal(t, domain.TemplateViewSkipped, again.Views[0].Action)
+	assert.False(t, again.RateLimit.Applied)
+
+	overwritten, err := svc.Apply(ctx, "library", "archive", false, true)
+	require.NoError(t, err)
+	require.Len(t, overwritten.Tables[1].Changed, 1)
+	assert.Equal(t, "书名", overwritten.Tables[1].Changed[0].Before.DisplayName)
+	assert.Equal(t, "题名", overwritten.Tables[1].Changed[0].After.DisplayName)
+	assert.Equal(t, domain.TemplateViewReplaced, overwritten.Views[0].Action)
+}
+
+func TestApply_Errors(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Apply(ctx, "missing", "archive", true, false)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	_, err = svc.Save(ctx, testTemplate(), 0)
+	require.NoError(t, err)
+	_, err = svc.Apply(ctx, "library", "unknown", true, false)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = svc.Apply(ctx, "library", "plain", true, false)
+	assert.ErrorIs(t, err, port.ErrPhysicalSchemaUnsupported)
+}
@@ -0,0 +1,194 @@
+// Package datadict file: internal/service/datadict/datadict_service.go
+package datadict
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTextRunes = 4000
+	maxLinkRunes = 1000
+)
+
+var (
+	// ErrEntryNotFound 表示字段尚未填写数据字典
+	ErrEntryNotFound = errors.New("字段的数据字典不存在")
+	// ErrInvalidEntry 表示数据字典的内容不合法，或字段未在业务组中配置
+	ErrInvalidEntry = errors.New("无效的数据字典")
+)
+
+// Service 维护字段级的数据字典：每个已配置字段的含义、来源、转换说明与受控词表，
+// 并把它们合并到表结构中，让检索者了解每一列实际表示什么。数据字典直接读写数据库，
+// 快照恢复等批量修改配置表的操作之后无需刷新缓存
+type Service struct {
+	db            *sql.DB
+	configService port.QueryAdminConfigService
+}
+
+// NewService 创建一个新的数据字典服务实例
+func NewService(db *sql.DB, configService port.QueryAdminConfigService) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("datadict.Service 需要一个有效的数据库连接")
+	}
+	if configService == nil {
+		return nil, errors.New("datadict.Service 需要有效的配置服务")
+	}
+	return &Service{db: db, configService: configService}, nil
+}
+
+// List 返回业务组的数据字典，tableName 不为空时只返回该表的字段，按表名与字段名排序
+func (s *Service) List(ctx context.Context, bizName, tableName string) ([]domain.FieldDictionaryEntry, error) {
+	query := `SELECT biz_name, table_name, field_name, description, source, transformation, vocabulary_link, updated_by, updated_at
+		FROM biz_field_dictionary WHERE biz_name = ?`
+	args := []interface{}{bizName}
+	if tableName != "" {
+		query += ` AND table_name = ?`
+		args = append(args, tableName)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY table_name, field_name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询数据字典失败: %w", err)
+	}
+	defer rows.Close()
+	entries := []domain.FieldDictionaryEntry{}
+	for rows.Next() {
+		var e domain.FieldDictionaryEntry
+		if err := rows.Scan(&e.BizName, &e.TableName, &e.FieldName, &e.Description, &e.Source, &e.Transformation,
+			&e.VocabularyLink, &e.UpdatedBy, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描数据字典失败: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListPublic 返回检索者可见的数据字典：只包含仍在业务组配置中、且可检索或可返回的字段，不含修改者
+func (s *Service) ListPublic(ctx context.Context, bizName string) ([]domain.FieldDictionaryEntry, error) {
+	cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	entries, err := s.List(ctx, bizName, "")
+	if err != nil {
+		return nil, err
+	}
+	visible := entries[:0]
+	for _, e := range entries {
+		if field, ok := configuredField(cfg, e.TableName, e.FieldName); ok && (field.IsSearchable || field.IsReturnable) {
+			e.UpdatedBy = 0
+			visible = append(visible, e)
+		}
+	}
+	return visible, nil
+}
+
+// Save 创建或替换字段的数据字典。字段必须已在业务组的字段设置中配置
+func (s *Service) Save(ctx context.Context, entry domain.FieldDictionaryEntry, userID int64) (*domain.FieldDictionaryEntry, error) {
+	entry.Description = strings.TrimSpace(entry.Description)
+	entry.Source = strings.TrimSpace(entry.Source)
+	entry.Transformation = strings.TrimSpace(entry.Transformation)
+	entry.VocabularyLink = strings.TrimSpace(entry.VocabularyLink)
+	if entry.Description == "" && entry.Source == "" && entry.Transformation == "" && entry.VocabularyLink == "" {
+		return nil, fmt.Errorf("%w: 至少需要填写一项说明，如需清除请删除该字段的数据字典", ErrInvalidEntry)
+	}
+	for name, value := range map[string]string{"description": entry.Description, "source": entry.Source, "transformation": entry.Transformation} {
+		if utf8.RuneCountInString(value) > maxTextRunes {
+			return nil, fmt.Errorf("%w: %s 不能超过 %d 个字符", ErrInvalidEntry, name, maxTextRunes)
+		}
+	}
+	if utf8.RuneCountInString(entry.VocabularyLink) > maxLinkRunes {
+		return nil, fmt.Errorf("%w: vocabulary_link 不能超过 %d 个字符", ErrInvalidEntry, maxLinkRunes)
+	}
+
+	cfg, err := s.configService.GetBizQueryConfig(ctx, entry.BizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if _, ok := configuredField(cfg, entry.TableName, entry.FieldName); !ok {
+		return nil, fmt.Errorf("%w: 业务组 '%s' 的表 '%s' 中未配置字段 '%s'", ErrInvalidEntry, entry.BizName, entry.TableName, entry.FieldName)
+	}
+
+	entry.UpdatedBy, entry.UpdatedAt = userID, time.Now().UTC()
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO biz_field_dictionary
+		(biz_name, table_name, field_name, description, source, transformation, vocabulary_link, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(biz_name, table_name, field_name) DO UPDATE SET
+			description = excluded.description, source = excluded.source, transformation = excluded.transformation,
+			vocabulary_link = excluded.vocabulary_link, updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		entry.BizName, entry.TableName, entry.FieldName, entry.Description, entry.Source, entry.Transformation,
+		entry.VocabularyLink, entry.UpdatedBy, entry.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("保存数据字典失败: %w", err)
+	}
+	return &entry, nil
+}
+
+// Delete 删除字段的数据字典
+func (s *Service) Delete(ctx context.Context, bizName, tableName, fieldName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_field_dictionary WHERE biz_name = ? AND table_name = ? AND field_name = ?`,
+		bizName, tableName, fieldName)
+	if err != nil {
+		return fmt.Errorf("删除数据字典失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// Annotate 返回合并了业务组数据字典的表结构，不修改传入的 schema (它可能是数据源缓存的结果)。
+// 数据源自带的字段描述只在数据字典未填写含义时保留
+func (s *Service) Annotate(ctx context.Context, bizName string, schema *port.SchemaResult) (*port.SchemaResult, error) {
+	if schema == nil || len(schema.Tables) == 0 {
+		return schema, nil
+	}
+	entries, err := s.List(ctx, bizName, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return schema, nil
+	}
+	byField := make(map[string]domain.FieldDictionaryEntry, len(entries))
+	for _, e := range entries {
+		byField[e.TableName+"\x00"+e.FieldName] = e
+	}
+	annotated := &port.SchemaResult{Tables: make(map[string][]port.FieldDescription, len(schema.Tables)), RecordKeys: schema.RecordKeys}
+	for table, fields := range schema.Tables {
+		copied := append([]port.FieldDescription(nil), fields...)
+		for i := range copied {
+			e, ok := byField[table+"\x00"+copied[i].Name]
+			if !ok {
+				continue
+			}
+			if e.Description != "" {
+				copied[i].Description = e.Description
+			}
+			copied[i].Source, copied[i].Transformation, copied[i].VocabularyLink = e.Source, e.Transformation, e.VocabularyLink
+		}
+		annotated.Tables[table] = copied
+	}
+	return annotated, nil
+}
+
+func configuredField(cfg *domain.BizQueryConfig, tableName, fieldName string) (domain.FieldSetting, bool) {
+	table, ok := cfg.Tables[tableName]
+	if !ok || table == nil {
+		return domain.FieldSetting{}, false
+	}
+	field, ok := table.Fields[fieldName]
+	return field, ok
+}
@@ -0,0 +1,181 @@
+// file: internal/service/datadict/datadict_service_test.go
+package datadict_test
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/datadict"
+	"ArchiveAegis/pkg/testsupport"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService 配置 library 业务组的 books 表：title 可检索可返回，price 仅可检索，isbn 两者皆否
+func newTestService(t *testing.T) *datadict.Service {
+	t.Helper()
+	ctx := context.Background()
+	db := testsupport.NewSystemDB(t)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	table := "books"
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{DefaultQueryTable: &table}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "price", IsSearchable: true},
+		{FieldName: "isbn"},
+	}))
+	svc, err := datadict.NewService(db, cfg)
+	require.NoError(t, err)
+	return svc
+}
+
+func entry(field, description string) domain.FieldDictionaryEntry {
+	return domain.FieldDictionaryEntry{BizName: "library", TableName: "books", FieldName: field, Description: description}
+}
+
+func TestNewServiceRequiresDependencies(t *testing.T) {
+	_, err := datadict.NewService(nil, nil)
+	assert.Error(t, err)
+	_, err = datadict.NewService(testsupport.NewSystemDB(t), nil)
+	assert.Error(t, err)
+}
+
+func TestSaveAndList(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	saved, err := svc.Save(ctx, domain.FieldDictionaryEntry{
+		BizName: "library", TableName: "books", FieldName: "title",
+		Description: "  题名  ", Source: "MARC 245$a", VocabularyLink: "https://example.org/vocab",
+	}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "题名", saved.Description, "首尾空白被去除")
+	assert.Equal(t, int64(7), saved.UpdatedBy)
+
+	// 再次保存替换原有内容
+	_, err = svc.Save(ctx, domain.FieldDictionaryEntry{BizName: "library", TableName: "books", FieldName: "title", Transformation: "繁体转简体"}, 8)
+	require.NoError(t, err)
+	_, err = svc.Save(ctx, entry("price", "定价，单位为元"), 7)
+	require.NoError(t, err)
+
+	entries, err := svc.List(ctx, "library", "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "price", entries[0].FieldName, "按字段名排序")
+	assert.Equal(t, "title", entries[1].FieldName)
+	assert.Empty(t, entries[1].Description, "再次保存替换全部说明")
+	assert.Empty(t, entries[1].Source)
+	assert.Equal(t, "繁体转简体", entries[1].Transformation)
+	assert.Equal(t, int64(8), entries[1].UpdatedBy)
+
+	entries, err = svc.List(ctx, "library", "authors")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSaveRejectsInvalidEntries(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	cases := map[string]domain.FieldDictionaryEntry{
+		"全部为空":   entry("title", "   "),
+		"说明过长":   entry("title", strings.Repeat("长", 4001)),
+		"词表链接过长": {BizName: "library", TableName: "books", FieldName: "title", VocabularyLink: strings.Repeat("a", 1001)},
+		"字段未配置":  entry("author", "作者"),
+		"表未配置":   {BizName: "library", TableName: "authors", FieldName: "title", Description: "题名"},
+	}
+	for name, e := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Save(ctx, e, 1)
+			assert.ErrorIs(t, err, datadict.ErrInvalidEntry)
+		})
+	}
+
+	_, err := svc.Save(ctx, domain.FieldDictionaryEntry{BizName: "missing", TableName: "books", FieldName: "title", Description: "题名"}, 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+
+	entries, err := svc.List(ctx, "library", "")
+	require.NoError(t, err)
+	assert.Empty(t, entries, "被拒绝的数据字典不应被保存")
+}
+
+func TestListPublicHidesUnexposedFields(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	for _, e := range []domain.FieldDictionaryEntry{entry("title", "题名"), entry("price", "定价"), entry("isbn", "国际标准书号")} {
+		_, err := svc.Save(ctx, e, 7)
+		require.NoError(t, err)
+	}
+
+	entries, err := svc.ListPublic(ctx, "library")
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "既不可检索也不可返回的字段不公开")
+	assert.Equal(t, "price", entries[0].FieldName, "仅可检索的字段仍然公开")
+	assert.Equal(t, "title", entries[1].FieldName)
+	for _, e := range entries {
+		assert.Zero(t, e.UpdatedBy, "不公开修改者")
+	}
+
+	_, err = svc.ListPublic(ctx, "missing")
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, err := svc.Save(ctx, entry("title", "题名"), 7)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Delete(ctx, "library", "books", "title"))
+	entries, err := svc.List(ctx, "library", "")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.ErrorIs(t, svc.Delete(ctx, "library", "books", "title"), datadict.ErrEntryNotFound)
+}
+
+func TestAnnotateMergesDictionaryWithoutMutatingSchema(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	_, err := svc.Save(ctx, domain.FieldDictionaryEntry{
+		BizName: "library", TableName: "books", FieldName: "title",
+		Description: "题名", Source: "MARC 245$a", Transformation: "繁体转简体", VocabularyLink: "LCSH",
+	}, 7)
+	require.NoError(t, err)
+	_, err = svc.Save(ctx, domain.FieldDictionaryEntry{BizName: "library", TableName: "books", FieldName: "price", Source: "采购记录"}, 7)
+	require.NoError(t, err)
+
+	schema := &port.SchemaResult{
+		Tables: map[string][]port.FieldDescription{"books": {
+			{Name: "title", Description: "插件自带的描述"},
+			{Name: "price", Description: "插件自带的描述"},
+			{Name: "isbn"},
+		}},
+		RecordKeys: map[string][]string{"books": {"isbn"}},
+	}
+	annotated, err := svc.Annotate(ctx, "library", schema)
+	require.NoError(t, err)
+
+	fields := annotated.Tables["books"]
+	assert.Equal(t, "题名", fields[0].Description, "数据字典的含义优先")
+	assert.Equal(t, "MARC 245$a", fields[0].Source)
+	assert.Equal(t, "繁体转简体", fields[0].Transformation)
+	assert.Equal(t, "LCSH", fields[0].VocabularyLink)
+	assert.Equal(t, "插件自带的描述", fields[1].Description, "数据字典未填写含义时保留数据源的描述")
+	assert.Equal(t, "采购记录", fields[1].Source)
+	assert.Equal(t, port.FieldDescription{Name: "isbn"}, fields[2])
+	assert.Equal(t, schema.RecordKeys, annotated.RecordKeys)
+
+	assert.Equal(t, "插件自带的描述", schema.Tables["books"][0].Description, "不修改传入的 schema")
+	assert.Empty(t, schema.Tables["books"][0].Source)
+
+	empty, err := svc.Annotate(ctx, "other", schema)
+	require.NoError(t, err)
+	assert.Same(t, schema, empty, "没有数据字典时原样返回")
+}
@@ -0,0 +1,607 @@
+// Package datasubject file: internal/service/datasubject/datasubject_service.go
+package datasubject
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxMatchesPerTable = 500
+	// maxMatchesPerTable 受数据源单次查询的条数上限约束 (SQLite 数据源为 2000)
+	maxMatchesPerTable     = 2000
+	defaultAnonymizedValue = "[已匿名]"
+	defaultRequestLimit    = 50
+	maxRequestLimit        = 500
+)
+
+var (
+	// ErrInvalidRequest 表示个人标识为空、业务组不存在或处理方式无效
+	ErrInvalidRequest = errors.New("无效的数据主体请求")
+)
+
+// Options 定义数据主体检索、导出与删除的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxMatchesPerTable 是每张表最多返回与导出的匹配记录数，默认为 500，最大为 2000。
+	// 删除与匿名化按条件执行，不受该上限约束
+	MaxMatchesPerTable int `mapstructure:"max_matches_per_table"`
+	// AnonymizedValue 是匿名化时替换个人标识的占位值，默认为 "[已匿名]"
+	AnonymizedValue string `mapstructure:"anonymized_value"`
+}
+
+// Request 是一次数据主体请求的检索条件。Fields 与 Bizs 为空时检索所有业务组中全部可检索的字段
+type Request struct {
+	Identifier string   `json:"identifier"`
+	Fields     []string `json:"fields"`
+	Bizs       []string `json:"bizs"`
+}
+
+// EraseRequest 是删除或匿名化请求，DryRun 为 true 时只返回将要执行的操作
+type EraseRequest struct {
+	Request
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// target 是一张与个人标识匹配的表及其写权限
+type target struct {
+	bizName    string
+	tableName  string
+	fields     []string
+	primaryKey []string
+	total      int64
+	config     *domain.TableConfig
+	dataSource port.DataSource
+	err        error
+}
+
+// Service 在所有已配置的业务组中查找与个人标识 (姓名、邮箱、证件号等) 完全匹配的记录，
+// 以压缩包导出，并在表开放写权限时删除或匿名化，用于响应数据主体的查阅与删除请求。
+// 检索经由数据源的普通查询进行，只覆盖网关可检索的表与字段；每次请求都会写入审计记录
+type Service struct {
+	db              *sql.DB
+	config          port.QueryAdminConfigService
+	registry        map[string]port.DataSource
+	maxMatches      int
+	anonymizedValue string
+	now             func() time.Time
+}
+
+// NewService 创建一个新的数据主体请求服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("datasubject.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("datasubject.Service 需要有效的配置服务")
+	}
+	if opts.MaxMatchesPerTable < 0 || opts.MaxMatchesPerTable > maxMatchesPerTable {
+		return nil, fmt.Errorf("max_matches_per_table 必须在 0 到 %d 之间: %d", maxMatchesPerTable, opts.MaxMatchesPerTable)
+	}
+	if opts.MaxMatchesPerTable == 0 {
+		opts.MaxMatchesPerTable = defaultMaxMatchesPerTable
+	}
+	if opts.AnonymizedValue == "" {
+		opts.AnonymizedValue = defaultAnonymizedValue
+	}
+	return &Service{
+		db:              db,
+		config:          config,
+		registry:        registry,
+		maxMatches:      opts.MaxMatchesPerTable,
+		anonymizedValue: opts.AnonymizedValue,
+		now:             time.Now,
+	}, nil
+}
+
+// Search 检索与个人标识匹配的记录并写入审计记录
+func (s *Service) Search(ctx context.Context, req Request, actor int64) (*domain.DataSubjectSearchResult, error) {
+	result, _, err := s.search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, s.auditOf(domain.DataSubjectActionSearch, req, result, actor)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Export 检索与个人标识匹配的记录并写入审计记录，返回的结果由 WriteBundle 打包
+func (s *Service) Export(ctx context.Context, req Request, actor int64) (*domain.DataSubjectSearchResult, error) {
+	result, _, err := s.search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, s.auditOf(domain.DataSubjectActionExport, req, result, actor)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WriteBundle 把检索结果写为 zip 压缩包：manifest.json 汇总各表的匹配情况，
+// records/<业务组>/<表>.json 是该表的匹配记录
+func WriteBundle(w io.Writer, result *domain.DataSubjectSearchResult, generatedAt time.Time) error {
+	zw := zip.NewWriter(w)
+	manifest := map[string]interface{}{
+		"identifier_hash": result.IdentifierHash,
+		"generated_at":    generatedAt.UTC(),
+		"tables":          result.Tables,
+	}
+	if err := writeJSONEntry(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	grouped := make(map[string][]domain.DataSubjectMatch)
+	var names []string
+	for _, m := range result.Matches {
+		name := path.Join("records", m.BizName, m.TableName+".json")
+		if _, ok := grouped[name]; !ok {
+			names = append(names, name)
+		}
+		grouped[name] = append(grouped[name], m)
+	}
+	for _, name := range names {
+		if err := writeJSONEntry(zw, name, grouped[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Erase 删除或匿名化与个人标识匹配的记录。未开放相应写权限的表被跳过，单张表失败不影响其他表；
+// 匿名化不修改主键字段。结果 (含 dry_run) 写入审计记录并返回
+func (s *Service) Erase(ctx context.Context, req EraseRequest, actor int64) (*domain.DataSubjectRequest, error) {
+	if req.Mode != domain.DataSubjectModeDelete && req.Mode != domain.DataSubjectModeAnonymize {
+		return nil, fmt.Errorf("%w: 处理方式必须是 '%s' 或 '%s'", ErrInvalidRequest, domain.DataSubjectModeDelete, domain.DataSubjectModeAnonymize)
+	}
+	result, targets, err := s.search(ctx, req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	identifier := strings.TrimSpace(req.Identifier)
+	audit := s.auditOf(domain.DataSubjectActionErase, req.Request, result, actor)
+	audit.Mode = req.Mode
+	audit.DryRun = req.DryRun
+	audit.Erasures = make([]domain.DataSubjectErasure, 0, len(targets))
+	for _, t := range targets {
+		if t.err == nil && t.total == 0 {
+			continue
+		}
+		erasure := domain.DataSubjectErasure{BizName: t.bizName, TableName: t.tableName, Fields: t.fields, Matched: t.total}
+		switch {
+		case t.err != nil:
+			erasure.Status, erasure.Reason = domain.DataSubjectStatusFailed, t.err.Error()
+		case req.Mode == domain.DataSubjectModeDelete && !t.config.AllowDelete:
+			erasure.Status, erasure.Reason = domain.DataSubjectStatusSkipped, "表未开放删除权限"
+		case req.Mode == domain.DataSubjectModeAnonymize && !t.config.AllowUpdate:
+			erasure.Status, erasure.Reason = domain.DataSubjectStatusSkipped, "表未开放更新权限"
+		case req.Mode == domain.DataSubjectModeAnonymize && len(withoutPrimaryKey(t.fields, t.primaryKey)) == 0:
+			erasure.Status, erasure.Reason = domain.DataSubjectStatusSkipped, "匹配的字段均为主键，无法匿名化，请改用删除"
+		case req.DryRun:
+			erasure.Status = domain.DataSubjectStatusPlanned
+		default:
+			affected, err := s.apply(ctx, t, req.Mode, identifier, actor)
+			erasure.RowsAffected = affected
+			if err != nil {
+				erasure.Status, erasure.Reason = domain.DataSubjectStatusFailed, err.Error()
+				slog.Error("[DataSubject] 删除或匿名化失败", "biz", t.bizName, "table", t.tableName, "mode", req.Mode, "error", err)
+			} else {
+				erasure.Status = domain.DataSubjectStatusDone
+			}
+		}
+		audit.RowsAffected += erasure.RowsAffected
+		audit.Erasures = append(audit.Erasures, erasure)
+	}
+	if err := s.record(ctx, audit); err != nil {
+		return nil, err
+	}
+	if !req.DryRun {
+		slog.Warn("[DataSubject] 已执行数据主体删除请求",
+			"request_id", audit.ID, "mode", audit.Mode, "rows_affected", audit.RowsAffected, "requested_by", actor)
+	}
+	return audit, nil
+}
+
+// apply 通过数据源的写操作执行删除或匿名化。删除一次性删除任一字段匹配的记录；
+// 匿名化逐个字段把等于标识的值替换为占位值
+func (s *Service) apply(ctx context.Context, t target, mode, identifier string, actor int64) (int64, error) {
+	actorID := strconv.FormatInt(actor, 10)
+	if mode == domain.DataSubjectModeDelete {
+		res, err := t.dataSource.Mutate(ctx, port.MutateRequest{
+			BizName:   t.bizName,
+			Operation: "delete",
+			Payload: map[string]interface{}{
+				"table_name":        t.tableName,
+				"filters":           identifierFilters(t.fields, identifier),
+				port.MutateActorKey: actorID,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		return toInt64(res.Data["rows_affected"]), nil
+	}
+
+	var affected int64
+	for _, field := range withoutPrimaryKey(t.fields, t.primaryKey) {
+		res, err := t.dataSource.Mutate(ctx, port.MutateRequest{
+			BizName:   t.bizName,
+			Operation: "update",
+			Payload: map[string]interface{}{
+				"table_name":        t.tableName,
+				"data":              map[string]interface{}{field: s.anonymizedValue},
+				"filters":           identifierFilters([]string{field}, identifier),
+				port.MutateActorKey: actorID,
+			},
+		})
+		if err != nil {
+			return affected, fmt.Errorf("匿名化字段 '%s' 失败: %w", field, err)
+		}
+		affected += toInt64(res.Data["rows_affected"])
+	}
+	return affected, nil
+}
+
+// search 在选定的业务组中逐表检索，任一可检索字段与标识完全相等即视为匹配。
+// 单张表检索失败时记录在结果中并继续检索其他表
+func (s *Service) search(ctx context.Context, req Request) (*domain.DataSubjectSearchResult, []target, error) {
+	identifier := strings.TrimSpace(req.Identifier)
+	if identifier == "" {
+		return nil, nil, fmt.Errorf("%w: 个人标识不能为空", ErrInvalidRequest)
+	}
+	bizNames, err := s.bizNames(ctx, req.Bizs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &domain.DataSubjectSearchResult{
+		IdentifierHash: HashIdentifier(identifier),
+		Tables:         make([]domain.DataSubjectTable, 0),
+		Matches:        make([]domain.DataSubjectMatch, 0),
+	}
+	var targets []target
+	for _, bizName := range bizNames {
+		bizTargets, err := s.targetsOf(ctx, bizName, req.Fields)
+		if err != nil {
+			result.Tables = append(result.Tables, domain.DataSubjectTable{BizName: bizName, Error: err.Error()})
+			continue
+		}
+		for _, t := range bizTargets {
+			matches, total, truncated, err := s.queryTable(ctx, t, identifier)
+			t.total, t.err = total, err
+			entry := domain.DataSubjectTable{BizName: t.bizName, TableName: t.tableName, Fields: t.fields, Total: total, Truncated: truncated}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			result.Tables = append(result.Tables, entry)
+			result.Matches = append(result.Matches, matches...)
+			targets = append(targets, t)
+		}
+	}
+	return result, targets, nil
+}
+
+// bizNames 返回要检索的业务组：requested 为空时为所有已配置且已注册的业务组
+func (s *Service) bizNames(ctx context.Context, requested []string) ([]string, error) {
+	if len(requested) > 0 {
+		names := make([]string, 0, len(requested))
+		for _, name := range requested {
+			if _, ok := s.registry[name]; !ok {
+				return nil, fmt.Errorf("%w: 业务组 '%s' 不存在", ErrInvalidRequest, name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	configured, err := s.config.GetAllConfiguredBizNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(configured))
+	for _, name := range configured {
+		if _, ok := s.registry[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// targetsOf 返回业务组中需要检索的表：可检索的表，及其中可检索且在 fields 之内 (fields 为空时不限) 的字段
+func (s *Service) targetsOf(ctx context.Context, bizName string, fields []string) ([]target, error) {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, nil
+	}
+	dataSource := s.registry[bizName]
+	schema, err := dataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName})
+	if err != nil {
+		return nil, fmt.Errorf("读取业务组结构失败: %w", err)
+	}
+	wanted := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		wanted[f] = true
+	}
+
+	tableNames := make([]string, 0, len(bizConfig.Tables))
+	for name, tableConfig := range bizConfig.Tables {
+		if tableConfig.IsSearchable {
+			tableNames = append(tableNames, name)
+		}
+	}
+	sort.Strings(tableNames)
+
+	targets := make([]target, 0, len(tableNames))
+	for _, name := range tableNames {
+		tableConfig := bizConfig.Tables[name]
+		var searchable []string
+		for fieldName, setting := range tableConfig.Fields {
+			if setting.IsSearchable && (len(wanted) == 0 || wanted[fieldName]) {
+				searchable = append(searchable, fieldName)
+			}
+		}
+		if len(searchable) == 0 {
+			continue
+		}
+		sort.Strings(searchable)
+		var primaryKey []string
+		for _, f := range schema.Tables[name] {
+			if f.IsPrimary {
+				primaryKey = append(primaryKey, f.Name)
+			}
+		}
+		targets = append(targets, target{
+			bizName:    bizName,
+			tableName:  name,
+			fields:     searchable,
+			primaryKey: primaryKey,
+			config:     tableConfig,
+			dataSource: dataSource,
+		})
+	}
+	return targets, nil
+}
+
+// queryTable 读取一张表中至多 maxMatches 条匹配记录
+func (s *Service) queryTable(ctx context.Context, t target, identifier string) ([]domain.DataSubjectMatch, int64, bool, error) {
+	res, err := t.dataSource.Query(ctx, port.QueryRequest{
+		BizName: t.bizName,
+		Query: map[string]interface{}{
+			"table":   t.tableName,
+			"filters": identifierFilters(t.fields, identifier),
+			"page":    float64(1),
+			"size":    float64(s.maxMatches),
+			// 遗漏失败库中的记录会使导出与删除不完整，因此要求全部库查询成功
+			port.QueryStrictKey: true,
+		},
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	items, _ := res.Data["items"].([]interface{})
+	total := toInt64(res.Data["total"])
+	truncated, _ := res.Data[port.ResultTruncatedKey].(bool)
+
+	matches := make([]domain.DataSubjectMatch, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		record := make(map[string]interface{}, len(row))
+		for k, v := range row {
+			record[k] = v
+		}
+		lib, _ := record["__lib"].(string)
+		delete(record, "__lib")
+		match := domain.DataSubjectMatch{
+			BizName:       t.bizName,
+			TableName:     t.tableName,
+			RecordID:      recordID(record, t.primaryKey),
+			Lib:           lib,
+			MatchedFields: make([]string, 0, 1),
+			Record:        record,
+		}
+		for _, f := range t.fields {
+			if strings.EqualFold(stringify(record[f]), identifier) {
+				match.MatchedFields = append(match.MatchedFields, f)
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches, total, truncated || total > int64(len(matches)), nil
+}
+
+// ListRequests 按时间倒序列出审计记录，identifierHash 不为空时只列出该标识的请求
+func (s *Service) ListRequests(ctx context.Context, identifierHash string, limit int) ([]domain.DataSubjectRequest, error) {
+	if limit <= 0 {
+		limit = defaultRequestLimit
+	}
+	limit = min(limit, maxRequestLimit)
+	query := `SELECT id, action, mode, identifier_hash, fields, bizs, matched, rows_affected, dry_run, erasures,
+		COALESCE(requested_by, 0), created_at FROM data_subject_requests`
+	var args []interface{}
+	if identifierHash != "" {
+		query += ` WHERE identifier_hash = ?`
+		args = append(args, identifierHash)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据主体请求记录失败: %w", err)
+	}
+	defer rows.Close()
+	requests := make([]domain.DataSubjectRequest, 0)
+	for rows.Next() {
+		var r domain.DataSubjectRequest
+		var fields, bizs, erasures string
+		if err := rows.Scan(&r.ID, &r.Action, &r.Mode, &r.IdentifierHash, &fields, &bizs, &r.Matched, &r.RowsAffected,
+			&r.DryRun, &erasures, &r.RequestedBy, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取数据主体请求记录失败: %w", err)
+		}
+		_ = json.Unmarshal([]byte(fields), &r.Fields)
+		_ = json.Unmarshal([]byte(bizs), &r.Bizs)
+		_ = json.Unmarshal([]byte(erasures), &r.Erasures)
+		requests = append(requests, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取数据主体请求记录失败: %w", err)
+	}
+	return requests, nil
+}
+
+// auditOf 由检索结果生成审计记录，Matched 为各表匹配总数之和
+func (s *Service) auditOf(action string, req Request, result *domain.DataSubjectSearchResult, actor int64) *domain.DataSubjectRequest {
+	audit := &domain.DataSubjectRequest{
+		Action:         action,
+		IdentifierHash: result.IdentifierHash,
+		Fields:         req.Fields,
+		Bizs:           req.Bizs,
+		RequestedBy:    actor,
+		CreatedAt:      s.now().UTC(),
+	}
+	for _, t := range result.Tables {
+		audit.Matched += t.Total
+	}
+	return audit
+}
+
+// record 写入一条审计记录并回填 ID
+func (s *Service) record(ctx context.Context, r *domain.DataSubjectRequest) error {
+	fields, _ := json.Marshal(nonNil(r.Fields))
+	bizs, _ := json.Marshal(nonNil(r.Bizs))
+	erasures, _ := json.Marshal(r.Erasures)
+	if r.Erasures == nil {
+		erasures = []byte("[]")
+	}
+	var requestedBy interface{}
+	if r.RequestedBy > 0 {
+		requestedBy = r.RequestedBy
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO data_subject_requests (action, mode, identifier_hash, fields, bizs, matched, rows_affected, dry_run, erasures, requested_by, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Action, r.Mode, r.IdentifierHash, string(fields), string(bizs), r.Matched, r.RowsAffected, r.DryRun, string(erasures), requestedBy, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("保存数据主体请求记录失败: %w", err)
+	}
+	r.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// HashIdentifier 返回个人标识 (去除首尾空白后) 的 SHA-256 摘要，审计记录只保存该摘要
+func HashIdentifier(identifier string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(identifier)))
+	return hex.EncodeToString(sum[:])
+}
+
+// identifierFilters 生成任一字段与标识完全相等的过滤条件
+func identifierFilters(fields []string, identifier string) []interface{} {
+	filters := make([]interface{}, 0, len(fields))
+	for i, f := range fields {
+		filter := map[string]interface{}{"field": f, "value": identifier}
+		if i > 0 {
+			filter["logic"] = "or"
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+func withoutPrimaryKey(fields, primaryKey []string) []string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		isKey := false
+		for _, k := range primaryKey {
+			if f == k {
+				isKey = true
+				break
+			}
+		}
+		if !isKey {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// recordID 以主键值拼接记录标识，任一主键字段不在结果中时返回空
+func recordID(record map[string]interface{}, primaryKey []string) string {
+	if len(primaryKey) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(primaryKey))
+	for _, k := range primaryKey {
+		v, ok := record[k]
+		if !ok {
+			return ""
+		}
+		parts = append(parts, stringify(v))
+	}
+	return strings.Join(parts, port.RecordIDSeparator)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case float64: // 经 gRPC (structpb) 传输的数字
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+func stringify(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
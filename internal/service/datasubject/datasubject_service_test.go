@@ -0,0 +1,218 @@
+// file: internal/service/datasubject/datasubject_service_test.go
+package datasubject
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDataSource 按 "field = value" 的 OR 条件检索与变更内存中的行，主键固定为 id
+type stubDataSource struct {
+	rows map[string][]map[string]interface{}
+}
+
+func matchesFilters(row map[string]interface{}, filters []interface{}) bool {
+	for _, f := range filters {
+		filter := f.(map[string]interface{})
+		if fmt.Sprint(row[filter["field"].(string)]) == filter["value"] {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *stubDataSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	table, _ := req.Query["table"].(string)
+	filters, _ := req.Query["filters"].([]interface{})
+	size := int(req.Query["size"].(float64))
+	items := make([]interface{}, 0)
+	total := 0
+	for _, row := range d.rows[table] {
+		if matchesFilters(row, filters) {
+			total++
+			if len(items) < size {
+				items = append(items, row)
+			}
+		}
+	}
+	return &port.QueryResult{Data: map[string]interface{}{"items": items, "total": float64(total)}}, nil
+}
+
+func (d *stubDataSource) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	table := req.Payload["table_name"].(string)
+	filters, _ := req.Payload["filters"].([]interface{})
+	var affected int64
+	kept := make([]map[string]interface{}, 0)
+	for _, row := range d.rows[table] {
+		if !matchesFilters(row, filters) {
+			kept = append(kept, row)
+			continue
+		}
+		affected++
+		if req.Operation == "update" {
+			for k, v := range req.Payload["data"].(map[string]interface{}) {
+				row[k] = v
+			}
+			kept = append(kept, row)
+		}
+	}
+	d.rows[table] = kept
+	return &port.MutateResult{Data: map[string]interface{}{"rows_affected": affected}}, nil
+}
+
+func (d *stubDataSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	out := &port.SchemaResult{Tables: map[string][]port.FieldDescription{}}
+	for table := range d.rows {
+		out.Tables[table] = []port.FieldDescription{{Name: "id", IsPrimary: true, IsReturnable: true}}
+	}
+	return out, nil
+}
+
+func (d *stubDataSource) HealthCheck(context.Context) error { return nil }
+func (d *stubDataSource) Type() string                      { return "stub" }
+
+func newTestService(t *testing.T) (*Service, *sql.DB, *stubDataSource) {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'root', 'x', 'admin')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES
+		('archive', FALSE, 'letters'), ('museum', FALSE, 'donors')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "archive", []string{"letters", "readers"}))
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "museum", []string{"donors"}))
+	for _, tc := range []struct{ biz, table string }{{"archive", "letters"}, {"archive", "readers"}, {"museum", "donors"}} {
+		require.NoError(t, config.UpdateTableFieldSettings(ctx, tc.biz, tc.table, []domain.FieldSetting{
+			{FieldName: "id", IsSearchable: true, IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "recipient", IsSearchable: true, IsReturnable: true},
+			{FieldName: "note", IsReturnable: true},
+		}))
+	}
+	require.NoError(t, config.UpdateTableWritePermissions(ctx, "archive", "letters", domain.TableConfig{AllowUpdate: true, AllowDelete: true}))
+
+	archive := &stubDataSource{rows: map[string][]map[string]interface{}{
+		"letters": {
+			{"id": "1", "sender": "张三", "recipient": "李四", "note": "a"},
+			{"id": "2", "sender": "王五", "recipient": "张三", "note": "b"},
+			{"id": "3", "sender": "王五", "recipient": "赵六", "note": "c"},
+		},
+		"readers": {{"id": "9", "sender": "张三", "recipient": "", "note": "d"}},
+	}}
+	museum := &stubDataSource{rows: map[string][]map[string]interface{}{
+		"donors": {{"id": "7", "sender": "孙七", "recipient": "", "note": "e"}},
+	}}
+	svc, err := NewService(db, config, map[string]port.DataSource{"archive": archive, "museum": museum}, Options{})
+	require.NoError(t, err)
+	return svc, db, archive
+}
+
+func TestNewService_Validation(t *testing.T) {
+	_, err := NewService(nil, nil, nil, Options{})
+	assert.Error(t, err)
+	svc, db, _ := newTestService(t)
+	_, err = NewService(db, svc.config, nil, Options{MaxMatchesPerTable: maxMatchesPerTable + 1})
+	assert.Error(t, err)
+}
+
+func TestSearchAndExport(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(t)
+
+	_, err := svc.Search(ctx, Request{Identifier: "  "}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = svc.Search(ctx, Request{Identifier: "张三", Bizs: []string{"nope"}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	result, err := svc.Search(ctx, Request{Identifier: " 张三 "}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, HashIdentifier("张三"), result.IdentifierHash)
+	require.Len(t, result.Matches, 3)
+	assert.Equal(t, "1", result.Matches[0].RecordID)
+	assert.Equal(t, []string{"sender"}, result.Matches[0].MatchedFields)
+	assert.Equal(t, []string{"recipient"}, result.Matches[1].MatchedFields)
+	assert.Equal(t, "readers", result.Matches[2].TableName)
+	require.Len(t, result.Tables, 3)
+	assert.Equal(t, []string{"id", "recipient", "sender"}, result.Tables[0].Fields, "note 不可检索")
+
+	result, err = svc.Search(ctx, Request{Identifier: "张三", Fields: []string{"recipient"}, Bizs: []string{"archive"}}, 1)
+	require.NoError(t, err)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "2", result.Matches[0].RecordID)
+
+	exported, err := svc.Export(ctx, Request{Identifier: "张三"}, 1)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, WriteBundle(&buf, exported, time.Now()))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"manifest.json", "records/archive/letters.json", "records/archive/readers.json"}, names)
+	f, err := zr.Open("records/archive/letters.json")
+	require.NoError(t, err)
+	var letters []domain.DataSubjectMatch
+	require.NoError(t, json.NewDecoder(f).Decode(&letters))
+	assert.Len(t, letters, 2)
+
+	requests, err := svc.ListRequests(ctx, HashIdentifier("张三"), 0)
+	require.NoError(t, err)
+	require.Len(t, requests, 3, "检索与导出都写入审计记录")
+	assert.Equal(t, domain.DataSubjectActionExport, requests[0].Action)
+	assert.Equal(t, int64(3), requests[0].Matched)
+	assert.Equal(t, []string{"recipient"}, requests[1].Fields)
+}
+
+func TestErase(t *testing.T) {
+	ctx := context.Background()
+	svc, _, archive := newTestService(t)
+
+	_, err := svc.Erase(ctx, EraseRequest{Request: Request{Identifier: "张三"}, Mode: "shred"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	plan, err := svc.Erase(ctx, EraseRequest{Request: Request{Identifier: "张三"}, Mode: domain.DataSubjectModeAnonymize, DryRun: true}, 1)
+	require.NoError(t, err)
+	require.Len(t, plan.Erasures, 2)
+	assert.Equal(t, domain.DataSubjectStatusPlanned, plan.Erasures[0].Status)
+	assert.Equal(t, domain.DataSubjectStatusSkipped, plan.Erasures[1].Status, "readers 未开放更新权限")
+	assert.Equal(t, "张三", archive.rows["letters"][0]["sender"], "dry_run 不修改数据")
+
+	done, err := svc.Erase(ctx, EraseRequest{Request: Request{Identifier: "张三"}, Mode: domain.DataSubjectModeAnonymize}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataSubjectStatusDone, done.Erasures[0].Status)
+	assert.Equal(t, int64(2), done.RowsAffected)
+	assert.Equal(t, defaultAnonymizedValue, archive.rows["letters"][0]["sender"])
+	assert.Equal(t, defaultAnonymizedValue, archive.rows["letters"][1]["recipient"])
+	assert.Equal(t, "王五", archive.rows["letters"][1]["sender"], "只替换与标识相等的字段")
+
+	deleted, err := svc.Erase(ctx, EraseRequest{Request: Request{Identifier: "王五", Bizs: []string{"archive"}}, Mode: domain.DataSubjectModeDelete}, 1)
+	require.NoError(t, err)
+	require.Len(t, deleted.Erasures, 1)
+	assert.Equal(t, int64(2), deleted.RowsAffected)
+	assert.Len(t, archive.rows["letters"], 1)
+
+	requests, err := svc.ListRequests(ctx, "", 0)
+	require.NoError(t, err)
+	require.Len(t, requests, 3)
+	assert.Equal(t, domain.DataSubjectModeDelete, requests[0].Mode)
+	assert.Equal(t, domain.DataSubjectStatusDone, requests[0].Erasures[0].Status)
+	assert.True(t, requests[2].DryRun)
+}
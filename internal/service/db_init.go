@@ -28,6 +28,57 @@ func InitPlatformTables(db *sql.DB) error {
 	if err := initSystemFeaturesTable(db); err != nil {
 		return fmt.Errorf("初始化系统功能表失败: %w", err)
 	}
+	if err := initReplicationTable(db); err != nil {
+		return fmt.Errorf("初始化同步目标表失败: %w", err)
+	}
+	if err := initOAIPMHTable(db); err != nil {
+		return fmt.Errorf("初始化 OAI-PMH 映射表失败: %w", err)
+	}
+	if err := initIIIFTable(db); err != nil {
+		return fmt.Errorf("初始化 IIIF 映射表失败: %w", err)
+	}
+	if err := initVirtualBizTable(db); err != nil {
+		return fmt.Errorf("初始化虚拟业务组表失败: %w", err)
+	}
+	if err := initShardedBizTable(db); err != nil {
+		return fmt.Errorf("初始化分片业务组表失败: %w", err)
+	}
+	if err := initReadSplitBizTable(db); err != nil {
+		return fmt.Errorf("初始化读写分离业务组表失败: %w", err)
+	}
+	if err := initUsageAnalyticsTables(db); err != nil {
+		return fmt.Errorf("初始化检索统计表失败: %w", err)
+	}
+	if err := initQueryDictionaryTable(db); err != nil {
+		return fmt.Errorf("初始化检索词典表失败: %w", err)
+	}
+	if err := initFieldDictionaryTable(db); err != nil {
+		return fmt.Errorf("初始化数据字典表失败: %w", err)
+	}
+	if err := initUploadSessionsTable(db); err != nil {
+		return fmt.Errorf("初始化分块上传表失败: %w", err)
+	}
+	if err := initFieldVocabularyTable(db); err != nil {
+		return fmt.Errorf("初始化受控词表失败: %w", err)
+	}
+	if err := initRecordLinksTable(db); err != nil {
+		return fmt.Errorf("初始化记录关联表失败: %w", err)
+	}
+	if err := initSecurityEventOutboxTable(db); err != nil {
+		return fmt.Errorf("初始化安全事件发送队列表失败: %w", err)
+	}
+	if err := initQueryTemplatesTable(db); err != nil {
+		return fmt.Errorf("初始化查询模板表失败: %w", err)
+	}
+	if err := initUserSessionsTable(db); err != nil {
+		return fmt.Errorf("初始化登录会话表失败: %w", err)
+	}
+	if err := initRecordAccessLogTable(db); err != nil {
+		return fmt.Errorf("初始化记录访问日志表失败: %w", err)
+	}
+	if err := initBizRenamesTable(db); err != nil {
+		return fmt.Errorf("初始化业务组重命名记录表失败: %w", err)
+	}
 
 	log.Println("✅ 数据库: 所有系统表结构初始化/检查完成。")
 	return nil
@@ -49,12 +100,32 @@ func initSystemFeaturesTable(db *sql.DB) error {
 	// 默认为关闭
 	insertQuery := `
 	INSERT OR IGNORE INTO system_features (feature_id, enabled) VALUES
-		('io.archiveaegis.system.observability', FALSE);
+		('io.archiveaegis.system.observability', FALSE),
+		('io.archiveaegis.system.diagnostics', FALSE);
 	`
 	_, err := db.Exec(insertQuery)
 	return err
 }
 
+// EnabledFeatures 返回已启用的系统功能 ID，按字母排序
+func EnabledFeatures(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT feature_id FROM system_features WHERE enabled = TRUE ORDER BY feature_id")
+	if err != nil {
+		return nil, fmt.Errorf("查询启用的系统功能列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	features := make([]string, 0)
+	for rows.Next() {
+		var featureID string
+		if err := rows.Scan(&featureID); err != nil {
+			return nil, fmt.Errorf("扫描启用的功能ID失败: %w", err)
+		}
+		features = append(features, featureID)
+	}
+	return features, rows.Err()
+}
+
 // initUserTable 创建用户表
 func initUserTable(db *sql.DB) error {
 	query := `
@@ -82,17 +153,49 @@ func initPermissionTables(db *sql.DB) error {
     CREATE TABLE IF NOT EXISTS biz_overall_settings (
         biz_name TEXT PRIMARY KEY,
         is_publicly_searchable BOOLEAN DEFAULT TRUE NOT NULL,
-        default_query_table TEXT
+        default_query_table TEXT,
+        change_capture_enabled BOOLEAN DEFAULT FALSE NOT NULL,
+        citation_template TEXT,
+        enabled BOOLEAN DEFAULT TRUE NOT NULL
     );`
 	if _, err := db.Exec(queryBizOverall); err != nil {
 		return fmt.Errorf("创建 'biz_overall_settings' 表失败: %w", err)
 	}
+	// 旧版本创建的表缺少后续新增的列，这里逐一补齐
+	if err := ensureColumn(db, "biz_overall_settings", "change_capture_enabled", "BOOLEAN DEFAULT FALSE NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "citation_template", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "enabled", "BOOLEAN DEFAULT TRUE NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "max_response_bytes", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "query_cost_policy", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "max_scan_rows", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "timezone", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "locale", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_overall_settings", "sensitive", "BOOLEAN DEFAULT FALSE NOT NULL"); err != nil {
+		return err
+	}
 
 	// 创建表级权限配置表 (包含新的写权限字段)
 	queryTablePerms := `
     CREATE TABLE IF NOT EXISTS biz_searchable_tables (
         biz_name TEXT NOT NULL,
         table_name TEXT NOT NULL,
+        is_searchable BOOLEAN DEFAULT TRUE NOT NULL,
         allow_create BOOLEAN DEFAULT FALSE NOT NULL,
         allow_update BOOLEAN DEFAULT FALSE NOT NULL,
         allow_delete BOOLEAN DEFAULT FALSE NOT NULL,
@@ -102,6 +205,26 @@ func initPermissionTables(db *sql.DB) error {
 	if _, err := db.Exec(queryTablePerms); err != nil {
 		return fmt.Errorf("创建 'biz_searchable_tables' 表失败: %w", err)
 	}
+	// 配置读取依赖 is_searchable 列，早期建表语句遗漏了它
+	if err := ensureColumn(db, "biz_searchable_tables", "is_searchable", "BOOLEAN DEFAULT TRUE NOT NULL"); err != nil {
+		return err
+	}
+	// 全文检索配置以 JSON 保存，空字符串表示未配置
+	if err := ensureColumn(db, "biz_searchable_tables", "fts_config", "TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
+	// 相关度得分配置同样以 JSON 保存，空字符串表示使用默认的加权计分
+	if err := ensureColumn(db, "biz_searchable_tables", "ranking_config", "TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
+	// 层级关系配置同样以 JSON 保存，空字符串表示表中的记录没有层级关系
+	if err := ensureColumn(db, "biz_searchable_tables", "hierarchy_config", "TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
+	// SQL 视图表的定义同样以 JSON 保存，空字符串表示普通的物理表
+	if err := ensureColumn(db, "biz_searchable_tables", "sql_view_config", "TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
 
 	// 创建字段级权限配置表
 	queryFieldPerms := `
@@ -118,6 +241,21 @@ func initPermissionTables(db *sql.DB) error {
 	if _, err := db.Exec(queryFieldPerms); err != nil {
 		return fmt.Errorf("创建 'biz_table_field_settings' 表失败: %w", err)
 	}
+	if err := ensureColumn(db, "biz_table_field_settings", "display_name", "TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_table_field_settings", "pinyin_enabled", "BOOLEAN DEFAULT FALSE NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_table_field_settings", "case_insensitive", "BOOLEAN DEFAULT FALSE NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_table_field_settings", "accent_insensitive", "BOOLEAN DEFAULT FALSE NOT NULL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_table_field_settings", "search_weight", "REAL DEFAULT 0 NOT NULL"); err != nil {
+		return err
+	}
 
 	// 创建视图定义表
 	queryViewDefs := `
@@ -185,11 +323,27 @@ func initGlobalSettingsTable(db *sql.DB) error {
 		biz_name TEXT PRIMARY KEY,
 		rate_limit_per_second REAL NOT NULL DEFAULT 5.0,
 		burst_size INTEGER NOT NULL DEFAULT 10,
+		anon_rate_limit_per_second REAL, -- NULL 表示匿名访问沿用业务组的速率
+		anon_burst_size INTEGER NOT NULL DEFAULT 0,
+		anon_max_page_size INTEGER NOT NULL DEFAULT 0,
+		anon_response_delay_ms INTEGER NOT NULL DEFAULT 0,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
 	if _, err := db.Exec(queryBizRateLimit); err != nil {
 		return fmt.Errorf("创建 'biz_ratelimit_settings' 表失败: %w", err)
 	}
+	if err := ensureColumn(db, "biz_ratelimit_settings", "anon_rate_limit_per_second", "REAL"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_ratelimit_settings", "anon_burst_size", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_ratelimit_settings", "anon_max_page_size", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "biz_ratelimit_settings", "anon_response_delay_ms", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -221,11 +375,915 @@ func initPluginManagementTable(db *sql.DB) error {
 		enabled BOOLEAN NOT NULL DEFAULT TRUE,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		last_started_at DATETIME,
+		mode TEXT NOT NULL DEFAULT 'process', -- 运行方式: process (独立进程 + gRPC), embedded (网关进程内)
+		process_options TEXT NOT NULL DEFAULT '{}', -- 独立进程的环境变量、工作目录与 umask 设置 (JSON)
+		last_stop_reason TEXT NOT NULL DEFAULT '', -- 最近一次停止的原因: admin_stop, health_check_failed, process_exited 等
+		last_stopped_at DATETIME,
+		depends_on TEXT NOT NULL DEFAULT '[]', -- 启动前必须就绪的其它实例ID (JSON 数组)
 		FOREIGN KEY (plugin_id, version) REFERENCES installed_plugins(plugin_id, version)
 	);`
 	if _, err := db.Exec(queryInstances); err != nil {
 		return fmt.Errorf("创建 'plugin_instances' 表失败: %w", err)
 	}
+	if err := ensureColumn(db, "plugin_instances", "mode", "TEXT NOT NULL DEFAULT 'process'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "plugin_instances", "process_options", "TEXT NOT NULL DEFAULT '{}'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "plugin_instances", "last_stop_reason", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "plugin_instances", "last_stopped_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "plugin_instances", "depends_on", "TEXT NOT NULL DEFAULT '[]'"); err != nil {
+		return err
+	}
+
+	queryEvents := `
+	CREATE TABLE IF NOT EXISTS plugin_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id TEXT NOT NULL DEFAULT '', -- 安装、升级等插件级事件为空
+		plugin_id TEXT NOT NULL,
+		version TEXT NOT NULL DEFAULT '',
+		event TEXT NOT NULL, -- installed, upgraded, started, restarted, stopped, crashed
+		reason TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(queryEvents); err != nil {
+		return fmt.Errorf("创建 'plugin_events' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_plugin_events_instance ON plugin_events(instance_id, id);`); err != nil {
+		return fmt.Errorf("创建 'plugin_events' 索引失败: %w", err)
+	}
+
+	return nil
+}
+
+// initReplicationTable 创建业务组单向同步目标表，同时记录同步检查点与状态。
+func initReplicationTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS replication_targets (
+		biz_name TEXT PRIMARY KEY,
+		target_url TEXT NOT NULL,
+		target_biz TEXT NOT NULL,
+		auth_token TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		checkpoint TEXT NOT NULL DEFAULT '', -- 源端变更日志的不透明游标
+		last_synced_at DATETIME,
+		last_error TEXT,
+		pushed_total INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'replication_targets' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initOAIPMHTable 创建 OAI-PMH 元数据映射表，每个业务表对应一个可收割的集合。
+func initOAIPMHTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS oai_dc_mappings (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		set_name TEXT NOT NULL DEFAULT '',
+		id_field TEXT NOT NULL,
+		datestamp_field TEXT NOT NULL DEFAULT '',
+		elements_json TEXT NOT NULL, -- Dublin Core 元素名 -> 字段名
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (biz_name, table_name)
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'oai_dc_mappings' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initIIIFTable 创建 IIIF 清单的字段映射表，映射细节以 JSON 形式保存
+func initIIIFTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS iiif_mappings (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		mapping_json TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (biz_name, table_name)
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'iiif_mappings' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initVirtualBizTable 创建虚拟业务组定义表，表映射以 JSON 形式保存
+func initVirtualBizTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS virtual_biz_definitions (
+		biz_name TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		tables_json TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'virtual_biz_definitions' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initShardedBizTable 创建分片业务组定义表，分片列表以 JSON 形式保存
+func initShardedBizTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS sharded_biz_definitions (
+		biz_name TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		shard_key TEXT NOT NULL,
+		shards_json TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'sharded_biz_definitions' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initReadSplitBizTable 创建读写分离业务组定义表，副本列表以 JSON 形式保存
+func initReadSplitBizTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS read_split_biz_definitions (
+		biz_name TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		writer_biz TEXT NOT NULL,
+		readers_json TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'read_split_biz_definitions' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initFieldDictionaryTable 创建字段级的数据字典表。它不引用可检索表的配置，
+// 重新设置业务组的可检索表或字段时已填写的说明不会随之删除
+func initFieldDictionaryTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS biz_field_dictionary (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		field_name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT '',
+		transformation TEXT NOT NULL DEFAULT '',
+		vocabulary_link TEXT NOT NULL DEFAULT '',
+		updated_by INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, table_name, field_name)
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'biz_field_dictionary' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initFieldVocabularyTable 创建字段与受控词表的绑定表，上传的词条以 JSON 形式保存。
+// 与数据字典一样不引用可检索表的配置，重新设置字段时绑定不会随之删除
+func initFieldVocabularyTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS biz_field_vocabularies (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		field_name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		terms_json TEXT NOT NULL DEFAULT '[]',
+		endpoint_url TEXT NOT NULL DEFAULT '',
+		strict INTEGER NOT NULL DEFAULT 0,
+		updated_by INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, table_name, field_name)
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'biz_field_vocabularies' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initRecordLinksTable 创建业务组记录之间的关联表，两端分别建立索引，便于按任一端展开与检查引用
+func initRecordLinksTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS record_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_biz TEXT NOT NULL,
+		source_table TEXT NOT NULL,
+		source_id TEXT NOT NULL,
+		target_biz TEXT NOT NULL,
+		target_table TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		relation TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		UNIQUE (source_biz, source_table, source_id, target_biz, target_table, target_id, relation)
+	);
+	CREATE INDEX IF NOT EXISTS idx_record_links_target ON record_links (target_biz, target_table, target_id);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'record_links' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initSecurityEventOutboxTable 创建待转发到 SIEM 的安全事件队列，每个接收端各有一份，事件投递成功后删除；
+// 采集端不可用时事件保留在表中等待重试，网关重启也不会丢失
+func initSecurityEventOutboxTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS security_event_outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sink TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_security_event_outbox_sink ON security_event_outbox (sink, id);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'security_event_outbox' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initQueryTemplatesTable 创建业务组的查询模板表，模板的条件与参数以 JSON 形式保存
+func initQueryTemplatesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS biz_query_templates (
+		biz_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		template_json TEXT NOT NULL,
+		updated_by INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, name)
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'biz_query_templates' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initUserSessionsTable 创建登录会话表，每次登录签发的 Token 以其 jti 对应一行。
+// 被挤下线或被管理员注销的会话保留到 Token 过期，此后才被清理
+func initUserSessionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS user_sessions (
+		id TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES _user(id) ON DELETE CASCADE,
+		ip TEXT NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		last_seen_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		revoke_reason TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions (user_id, created_at);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'user_sessions' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initRecordAccessLogTable 创建敏感业务组的记录访问日志表，每条被查看的记录一行。
+// 不引用 _user 表，账户删除后日志仍然保留到超过保留期限
+func initRecordAccessLogTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS record_access_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		record_id TEXT NOT NULL,
+		user_id INTEGER,
+		username TEXT NOT NULL DEFAULT '',
+		ip TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL,
+		accessed_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_record_access_log_record ON record_access_log (biz_name, table_name, record_id);
+	CREATE INDEX IF NOT EXISTS idx_record_access_log_user ON record_access_log (user_id, accessed_at);
+	CREATE INDEX IF NOT EXISTS idx_record_access_log_time ON record_access_log (accessed_at);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'record_access_log' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initUploadSessionsTable 创建分块上传的会话表，上传的数据保存在 uploads 目录下以会话 ID 命名的文件中
+func initUploadSessionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS upload_sessions (
+		id TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		file_name TEXT NOT NULL,
+		total_size INTEGER NOT NULL,
+		sha256 TEXT NOT NULL DEFAULT '',
+		received INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires ON upload_sessions (expires_at);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'upload_sessions' 表失败: %w", err)
+	}
+	return nil
+}
+
+// initQueryDictionaryTable 创建按业务组保存的同义词与归一化规则表，词典以 JSON 形式保存
+func initQueryDictionaryTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS biz_query_dictionaries (
+		biz_name TEXT PRIMARY KEY,
+		dictionary_json TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'biz_query_dictionaries' 表失败: %w", err)
+	}
+	return nil
+}
+
+// ensureColumn 检查指定表是否已存在某列，不存在时通过 ALTER TABLE 追加。
+// 用于在不破坏已有数据的前提下，为旧版本创建的系统表补齐新字段。
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	exists, err := columnExists(db, table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q %s", table, column, definition)); err != nil {
+		return fmt.Errorf("为表 '%s' 追加列 '%s' 失败: %w", table, column, err)
+	}
+	log.Printf("信息: 数据库迁移: 已为表 '%s' 追加列 '%s'。", table, column)
+	return nil
+}
+
+// columnExists 通过 PRAGMA table_info 判断表中是否存在指定列
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%q)", table))
+	if err != nil {
+		return false, fmt.Errorf("读取表 '%s' 结构失败: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, fmt.Errorf("扫描表 '%s' 列信息失败: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// initUsageAnalyticsTables 创建按业务组、按天聚合的检索统计表。
+// 检索词只保存加盐哈希，原文仅在配置允许时保存。
+func initUsageAnalyticsTables(db *sql.DB) error {
+	queryDaily := `
+	CREATE TABLE IF NOT EXISTS biz_usage_daily (
+		biz_name TEXT NOT NULL,
+		day TEXT NOT NULL, -- YYYY-MM-DD (UTC)
+		queries INTEGER NOT NULL DEFAULT 0,
+		zero_results INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (biz_name, day)
+	);`
+	if _, err := db.Exec(queryDaily); err != nil {
+		return fmt.Errorf("创建 'biz_usage_daily' 表失败: %w", err)
+	}
+
+	queryFields := `
+	CREATE TABLE IF NOT EXISTS biz_usage_fields (
+		biz_name TEXT NOT NULL,
+		day TEXT NOT NULL,
+		field TEXT NOT NULL,
+		hits INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (biz_name, day, field)
+	);`
+	if _, err := db.Exec(queryFields); err != nil {
+		return fmt.Errorf("创建 'biz_usage_fields' 表失败: %w", err)
+	}
+
+	queryTerms := `
+	CREATE TABLE IF NOT EXISTS biz_usage_terms (
+		biz_name TEXT NOT NULL,
+		day TEXT NOT NULL,
+		term_hash TEXT NOT NULL,
+		term TEXT NOT NULL DEFAULT '', -- 仅在 store_terms 开启时保存原文
+		hits INTEGER NOT NULL DEFAULT 0,
+		zero_results INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (biz_name, day, term_hash)
+	);`
+	if _, err := db.Exec(queryTerms); err != nil {
+		return fmt.Errorf("创建 'biz_usage_terms' 表失败: %w", err)
+	}
+
+	// 无结果检索按 (业务组, 表, 检索条件) 去重，供管理员逐条复核
+	queryZero := `
+	CREATE TABLE IF NOT EXISTS zero_result_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL DEFAULT '',
+		filters_hash TEXT NOT NULL UNIQUE,
+		filters_json TEXT NOT NULL,
+		occurrences INTEGER NOT NULL DEFAULT 0,
+		first_seen_at DATETIME NOT NULL,
+		last_seen_at DATETIME NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open', -- open, resolved, ignored
+		tags TEXT NOT NULL DEFAULT '[]',
+		resolution TEXT NOT NULL DEFAULT '',
+		resolved_by INTEGER,
+		resolved_at DATETIME
+	);`
+	if _, err := db.Exec(queryZero); err != nil {
+		return fmt.Errorf("创建 'zero_result_searches' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_zero_result_searches_biz ON zero_result_searches(biz_name, status);`); err != nil {
+		return fmt.Errorf("创建 'zero_result_searches' 索引失败: %w", err)
+	}
+
+	// 后台任务只记录状态与进度，任务本身在进程内执行，进程重启后未结束的任务被标记为失败
+	queryJobs := `
+	CREATE TABLE IF NOT EXISTS background_jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		biz_name TEXT NOT NULL DEFAULT '',
+		target TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL, -- queued, running, succeeded, failed, cancelled
+		progress_done INTEGER NOT NULL DEFAULT 0,
+		progress_total INTEGER NOT NULL DEFAULT 0,
+		message TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		started_at DATETIME,
+		finished_at DATETIME
+	);`
+	if _, err := db.Exec(queryJobs); err != nil {
+		return fmt.Errorf("创建 'background_jobs' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_background_jobs_kind ON background_jobs(kind, biz_name, target, status);`); err != nil {
+		return fmt.Errorf("创建 'background_jobs' 索引失败: %w", err)
+	}
+
+	// 拼写建议词表由字段的全部取值切词得到，按字段整体重建
+	querySuggestionVocab := `
+	CREATE TABLE IF NOT EXISTS suggestion_vocabulary (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		field_name TEXT NOT NULL,
+		term TEXT NOT NULL,
+		frequency INTEGER NOT NULL,
+		PRIMARY KEY (biz_name, table_name, field_name, term)
+	);`
+	if _, err := db.Exec(querySuggestionVocab); err != nil {
+		return fmt.Errorf("创建 'suggestion_vocabulary' 表失败: %w", err)
+	}
+	querySuggestionFields := `
+	CREATE TABLE IF NOT EXISTS suggestion_vocabulary_fields (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		field_name TEXT NOT NULL,
+		term_count INTEGER NOT NULL,
+		built_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, table_name, field_name)
+	);`
+	if _, err := db.Exec(querySuggestionFields); err != nil {
+		return fmt.Errorf("创建 'suggestion_vocabulary_fields' 表失败: %w", err)
+	}
+
+	// 语义检索配置，向量本身由数据源保存
+	querySemantic := `
+	CREATE TABLE IF NOT EXISTS biz_semantic_search (
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		fields_json TEXT NOT NULL,
+		model TEXT NOT NULL DEFAULT '',
+		indexed_at DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, table_name)
+	);`
+	if _, err := db.Exec(querySemantic); err != nil {
+		return fmt.Errorf("创建 'biz_semantic_search' 表失败: %w", err)
+	}
+
+	// 用户的界面偏好设置，值为任意 JSON
+	queryPreferences := `
+	CREATE TABLE IF NOT EXISTS user_preferences (
+		user_id INTEGER NOT NULL,
+		pref_key TEXT NOT NULL,
+		value_json TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, pref_key),
+		FOREIGN KEY (user_id) REFERENCES _user(id) ON DELETE CASCADE
+	);`
+	if _, err := db.Exec(queryPreferences); err != nil {
+		return fmt.Errorf("创建 'user_preferences' 表失败: %w", err)
+	}
+
+	// 用户收藏的记录，tags_json 为标签数组
+	queryBookmarks := `
+	CREATE TABLE IF NOT EXISTS user_bookmarks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		record_id TEXT NOT NULL,
+		lib TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		tags_json TEXT NOT NULL DEFAULT '[]',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE (user_id, biz_name, table_name, record_id, lib),
+		FOREIGN KEY (user_id) REFERENCES _user(id) ON DELETE CASCADE
+	);`
+	if _, err := db.Exec(queryBookmarks); err != nil {
+		return fmt.Errorf("创建 'user_bookmarks' 表失败: %w", err)
+	}
+
+	// 记录批注与审核状态，批注不修改数据源中的原始数据
+	queryAnnotations := `
+	CREATE TABLE IF NOT EXISTS record_annotations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		biz_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		record_id TEXT NOT NULL,
+		lib TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,
+		body TEXT NOT NULL DEFAULT '',
+		field_name TEXT NOT NULL DEFAULT '',
+		suggested_value TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		author_id INTEGER NOT NULL,
+		moderator_id INTEGER,
+		moderation_note TEXT NOT NULL DEFAULT '',
+		moderated_at DATETIME,
+		original_value TEXT,
+		applied_at DATETIME,
+		applied_by INTEGER,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (author_id) REFERENCES _user(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_record_annotations_record ON record_annotations (biz_name, table_name, record_id, status);
+	CREATE INDEX IF NOT EXISTS idx_record_annotations_status ON record_annotations (status, created_at);`
+	if _, err := db.Exec(queryAnnotations); err != nil {
+		return fmt.Errorf("创建 'record_annotations' 表失败: %w", err)
+	}
+	// 为旧版本创建的批注表补齐勘误写回所需的列：提交时字段的原值、写回时间与执行人
+	if err := ensureColumn(db, "record_annotations", "original_value", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "record_annotations", "applied_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "record_annotations", "applied_by", "INTEGER"); err != nil {
+		return err
+	}
+
+	// 冻结查询的公开分享链接，只保存令牌的 SHA-256 哈希
+	queryShareLinks := `
+	CREATE TABLE IF NOT EXISTS share_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		token_hash TEXT NOT NULL UNIQUE,
+		token_prefix TEXT NOT NULL,
+		owner_id INTEGER NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		biz_name TEXT NOT NULL,
+		query_json TEXT NOT NULL,
+		rate_per_minute INTEGER NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		use_count INTEGER NOT NULL DEFAULT 0,
+		last_used_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (owner_id) REFERENCES _user(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_share_links_owner ON share_links (owner_id, created_at);`
+	if _, err := db.Exec(queryShareLinks); err != nil {
+		return fmt.Errorf("创建 'share_links' 表失败: %w", err)
+	}
+
+	// 外部网站嵌入检索结果所用的密钥。签名密钥需要用于校验 HMAC，因此以原文保存
+	queryEmbedKeys := `
+	CREATE TABLE IF NOT EXISTS embed_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key_id TEXT NOT NULL UNIQUE,
+		secret TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		biz_name TEXT NOT NULL,
+		allowed_origins_json TEXT NOT NULL DEFAULT '[]',
+		created_at DATETIME NOT NULL,
+		revoked_at DATETIME
+	);`
+	if _, err := db.Exec(queryEmbedKeys); err != nil {
+		return fmt.Errorf("创建 'embed_keys' 表失败: %w", err)
+	}
+
+	// 服务器间请求签名所用的密钥，请求以 user_id 对应用户的身份执行
+	querySigningKeys := `
+	CREATE TABLE IF NOT EXISTS signing_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key_id TEXT NOT NULL UNIQUE,
+		secret TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		user_id INTEGER NOT NULL REFERENCES _user(id) ON DELETE CASCADE,
+		created_at DATETIME NOT NULL,
+		last_used_at DATETIME,
+		revoked_at DATETIME
+	);`
+	if _, err := db.Exec(querySigningKeys); err != nil {
+		return fmt.Errorf("创建 'signing_keys' 表失败: %w", err)
+	}
+
+	// 业务组的委派管理员，授权者被删除时保留授权记录
+	queryBizOwners := `
+	CREATE TABLE IF NOT EXISTS biz_owners (
+		biz_name TEXT NOT NULL,
+		user_id INTEGER NOT NULL REFERENCES _user(id) ON DELETE CASCADE,
+		granted_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		granted_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, user_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_biz_owners_user ON biz_owners(user_id);`
+	if _, err := db.Exec(queryBizOwners); err != nil {
+		return fmt.Errorf("创建 'biz_owners' 表失败: %w", err)
+	}
+
+	// 双人审批的高风险变更申请，申请人或审批人被删除时保留记录
+	queryChangeRequests := `
+	CREATE TABLE IF NOT EXISTS admin_change_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		target TEXT NOT NULL DEFAULT '',
+		payload TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		requested_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		reviewed_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		review_note TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		reviewed_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_admin_change_requests_status ON admin_change_requests(status, created_at);`
+	if _, err := db.Exec(queryChangeRequests); err != nil {
+		return fmt.Errorf("创建 'admin_change_requests' 表失败: %w", err)
+	}
+
+	// admin_daily_digests 保存每日运行摘要。请求计数器与 boot_id 是生成时进程内的累计值，
+	// 用于计算与下一份摘要之间的请求数；boot_id 不同说明网关在两份摘要之间重启过
+	queryDigests := `
+	CREATE TABLE IF NOT EXISTS admin_daily_digests (
+		day TEXT PRIMARY KEY, -- YYYY-MM-DD，UTC
+		report TEXT NOT NULL,
+		boot_id TEXT NOT NULL DEFAULT '',
+		requests_counter INTEGER NOT NULL DEFAULT 0,
+		errors_counter INTEGER NOT NULL DEFAULT 0,
+		storage_bytes INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryDigests); err != nil {
+		return fmt.Errorf("创建 'admin_daily_digests' 表失败: %w", err)
+	}
+
+	// plugin_update_stages 记录已下载安装、等待管理员一键应用的插件新版本，每个插件最多一条
+	queryUpdateStages := `
+	CREATE TABLE IF NOT EXISTS plugin_update_stages (
+		plugin_id TEXT PRIMARY KEY,
+		version TEXT NOT NULL,
+		staged_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		staged_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryUpdateStages); err != nil {
+		return fmt.Errorf("创建 'plugin_update_stages' 表失败: %w", err)
+	}
+
+	// plugin_licenses 保存管理员录入的插件授权码，每个插件一份，校验在读取时离线进行
+	queryLicenses := `
+	CREATE TABLE IF NOT EXISTS plugin_licenses (
+		plugin_id TEXT PRIMARY KEY,
+		license_key TEXT NOT NULL,
+		created_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryLicenses); err != nil {
+		return fmt.Errorf("创建 'plugin_licenses' 表失败: %w", err)
+	}
+
+	// rate_limit_penalties 保存登录锁定、临时封禁与违规计数，网关重启后恢复，短窗口的令牌桶只在内存中
+	queryPenalties := `
+	CREATE TABLE IF NOT EXISTS rate_limit_penalties (
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		strikes INTEGER NOT NULL DEFAULT 0,
+		window_start DATETIME NOT NULL,
+		locked_until DATETIME,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (kind, subject)
+	);`
+	if _, err := db.Exec(queryPenalties); err != nil {
+		return fmt.Errorf("创建 'rate_limit_penalties' 表失败: %w", err)
+	}
+
+	// abuse_flags 记录异常行为判定与随之而来的临时封禁，也用于计算再犯时递增的封禁时长
+	queryAbuseFlags := `
+	CREATE TABLE IF NOT EXISTS abuse_flags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		offense INTEGER NOT NULL,
+		ban_minutes INTEGER NOT NULL,
+		banned_until DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryAbuseFlags); err != nil {
+		return fmt.Errorf("创建 'abuse_flags' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_abuse_flags_subject ON abuse_flags(kind, subject, created_at);`); err != nil {
+		return fmt.Errorf("创建 'abuse_flags' 索引失败: %w", err)
+	}
+
+	// abuse_allowlist 保存不参与异常行为检测的 IP (可为 CIDR) 与用户
+	queryAbuseAllowlist := `
+	CREATE TABLE IF NOT EXISTS abuse_allowlist (
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (kind, subject)
+	);`
+	if _, err := db.Exec(queryAbuseAllowlist); err != nil {
+		return fmt.Errorf("创建 'abuse_allowlist' 表失败: %w", err)
+	}
+
+	// data_subject_requests 是数据主体检索、导出与删除请求的审计记录，个人标识只保存摘要
+	queryDataSubjectRequests := `
+	CREATE TABLE IF NOT EXISTS data_subject_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		mode TEXT NOT NULL DEFAULT '',
+		identifier_hash TEXT NOT NULL,
+		fields TEXT NOT NULL DEFAULT '[]',
+		bizs TEXT NOT NULL DEFAULT '[]',
+		matched INTEGER NOT NULL DEFAULT 0,
+		rows_affected INTEGER NOT NULL DEFAULT 0,
+		dry_run BOOLEAN NOT NULL DEFAULT 0,
+		erasures TEXT NOT NULL DEFAULT '[]',
+		requested_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryDataSubjectRequests); err != nil {
+		return fmt.Errorf("创建 'data_subject_requests' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_data_subject_requests_hash ON data_subject_requests(identifier_hash);`); err != nil {
+		return fmt.Errorf("创建 'data_subject_requests' 索引失败: %w", err)
+	}
+
+	// pii_scan_reports 保存每个业务组最近一次个人信息扫描的结果 (JSON)
+	queryPIIScanReports := `
+	CREATE TABLE IF NOT EXISTS pii_scan_reports (
+		biz_name TEXT PRIMARY KEY,
+		report TEXT NOT NULL,
+		scanned_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		scanned_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryPIIScanReports); err != nil {
+		return fmt.Errorf("创建 'pii_scan_reports' 表失败: %w", err)
+	}
+
+	// fixity_checksums 保存每个业务组库文件最近一次校验的 SHA-256，作为下次校验的基准
+	queryFixityChecksums := `
+	CREATE TABLE IF NOT EXISTS fixity_checksums (
+		biz_name TEXT NOT NULL,
+		file_name TEXT NOT NULL,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		changed_at DATETIME NOT NULL,
+		checked_at DATETIME NOT NULL,
+		PRIMARY KEY (biz_name, file_name)
+	);`
+	if _, err := db.Exec(queryFixityChecksums); err != nil {
+		return fmt.Errorf("创建 'fixity_checksums' 表失败: %w", err)
+	}
+
+	// fixity_events 记录库文件的首次登记、经由网关的变更、无法解释的变更与文件丢失
+	queryFixityEvents := `
+	CREATE TABLE IF NOT EXISTS fixity_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		biz_name TEXT NOT NULL,
+		file_name TEXT NOT NULL,
+		event TEXT NOT NULL,
+		expected_sha256 TEXT NOT NULL DEFAULT '',
+		actual_sha256 TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryFixityEvents); err != nil {
+		return fmt.Errorf("创建 'fixity_events' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_fixity_events_biz ON fixity_events(biz_name, created_at);`); err != nil {
+		return fmt.Errorf("创建 'fixity_events' 索引失败: %w", err)
+	}
 
+	// biz_config_templates 保存可复用的业务组配置模板，template_json 包含字段规则、默认视图与速率限制
+	queryConfigTemplates := `
+	CREATE TABLE IF NOT EXISTS biz_config_templates (
+		name TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		template_json TEXT NOT NULL,
+		updated_by INTEGER REFERENCES _user(id) ON DELETE SET NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryConfigTemplates); err != nil {
+		return fmt.Errorf("创建 'biz_config_templates' 表失败: %w", err)
+	}
+
+	// biz_slos 保存业务组的服务等级目标
+	querySLOs := `
+	CREATE TABLE IF NOT EXISTS biz_slos (
+		biz_name TEXT PRIMARY KEY,
+		availability_target REAL NOT NULL DEFAULT 0,
+		latency_threshold_ms INTEGER NOT NULL DEFAULT 0,
+		latency_target REAL NOT NULL DEFAULT 0,
+		window_days INTEGER NOT NULL DEFAULT 30,
+		updated_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(querySLOs); err != nil {
+		return fmt.Errorf("创建 'biz_slos' 表失败: %w", err)
+	}
+
+	// slo_buckets 按 5 分钟汇总各业务组的请求数、5xx 响应数与超过延迟阈值的请求数，用于计算错误预算
+	querySLOBuckets := `
+	CREATE TABLE IF NOT EXISTS slo_buckets (
+		biz_name TEXT NOT NULL,
+		bucket_start DATETIME NOT NULL,
+		requests INTEGER NOT NULL DEFAULT 0,
+		errors INTEGER NOT NULL DEFAULT 0,
+		slow INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (biz_name, bucket_start)
+	);`
+	if _, err := db.Exec(querySLOBuckets); err != nil {
+		return fmt.Errorf("创建 'slo_buckets' 表失败: %w", err)
+	}
+
+	// maintenance_runs 记录业务组库文件的每次维护：触发原因、执行的任务与各库执行前后的存储状况
+	queryMaintenanceRuns := `
+	CREATE TABLE IF NOT EXISTS maintenance_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		biz_name TEXT NOT NULL,
+		trigger TEXT NOT NULL,
+		tasks TEXT NOT NULL,
+		status TEXT NOT NULL,
+		libs TEXT NOT NULL DEFAULT '[]',
+		reclaimed_bytes INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(queryMaintenanceRuns); err != nil {
+		return fmt.Errorf("创建 'maintenance_runs' 表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_maintenance_runs_biz ON maintenance_runs(biz_name, id);`); err != nil {
+		return fmt.Errorf("创建 'maintenance_runs' 索引失败: %w", err)
+	}
+	return nil
+}
+
+// initBizRenamesTable 创建业务组重命名记录表。重命名会改写所有表中的业务组名，
+// 这张表保留新旧名称的对应关系，用于追溯重命名之前的日志与外部引用。列名不使用 biz_name，重命名时不会被改写
+func initBizRenamesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS biz_renames (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		old_name TEXT NOT NULL,
+		new_name TEXT NOT NULL,
+		renamed_by INTEGER,
+		rows_updated INTEGER NOT NULL DEFAULT 0,
+		renamed_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_biz_renames_old ON biz_renames (old_name);
+	CREATE INDEX IF NOT EXISTS idx_biz_renames_new ON biz_renames (new_name);`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建 'biz_renames' 表失败: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,187 @@
+// Package demo file: internal/service/demo/dataset.go
+package demo
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// demoSeed 固定随机种子，保证每次生成的数据完全一致
+const demoSeed = 20240601
+
+const (
+	demoPersonCount = 200
+	demoLetterCount = 3000
+)
+
+// demoColumn 描述演示表中的一列：建表类型与网关字段配置
+type demoColumn struct {
+	name        string
+	sqlType     string
+	dataType    string
+	displayName string
+	searchable  bool
+}
+
+// demoTable 描述一张演示表，rows 以确定性方式生成全部记录
+type demoTable struct {
+	name    string
+	columns []demoColumn
+	rows    func() [][]interface{}
+}
+
+var (
+	surnames    = []string{"王", "李", "张", "刘", "陈", "杨", "黄", "赵", "周", "吴", "徐", "孙", "朱", "胡", "郭", "何", "林", "罗", "高", "梁"}
+	givenChars  = []string{"文", "德", "之", "元", "仲", "伯", "叔", "子", "安", "清", "明", "远", "思", "敬", "宗", "鸿", "瑞", "廷", "寿", "祺"}
+	places      = []string{"苏州", "杭州", "扬州", "江宁", "松江", "绍兴", "宁波", "徽州", "常州", "湖州", "嘉兴", "武昌", "长沙", "福州", "广州", "京师"}
+	subjects    = []string{"问安", "借书", "论学", "家事", "赈灾", "刻书", "荐举", "婚嫁", "丧葬", "田产", "诗文唱和", "书画鉴藏"}
+	summaryBits = []string{"近况尚安", "所托之事已办妥", "书稿校毕待刻", "盼早日回信", "附寄诗稿数首", "秋收不佳", "族中诸事烦扰", "拟于月内启程", "谢赠书之谊", "请代为转致"}
+)
+
+// demoTables 是演示业务组的全部表：人物表与信札表，信札通过 sender_id 关联人物
+var demoTables = []demoTable{
+	{
+		name: "persons",
+		columns: []demoColumn{
+			{name: "id", sqlType: "INTEGER PRIMARY KEY", dataType: "integer", displayName: "编号", searchable: true},
+			{name: "name", sqlType: "TEXT", dataType: "string", displayName: "姓名", searchable: true},
+			{name: "courtesy_name", sqlType: "TEXT", dataType: "string", displayName: "字", searchable: true},
+			{name: "birth_year", sqlType: "INTEGER", dataType: "integer", displayName: "生年", searchable: true},
+			{name: "death_year", sqlType: "INTEGER", dataType: "integer", displayName: "卒年", searchable: true},
+			{name: "native_place", sqlType: "TEXT", dataType: "string", displayName: "籍贯", searchable: true},
+			{name: "biography", sqlType: "TEXT", dataType: "string", displayName: "小传", searchable: false},
+		},
+		rows: personRows,
+	},
+	{
+		name: "letters",
+		columns: []demoColumn{
+			{name: "id", sqlType: "INTEGER PRIMARY KEY", dataType: "integer", displayName: "编号", searchable: true},
+			{name: "archive_no", sqlType: "TEXT", dataType: "string", displayName: "档号", searchable: true},
+			{name: "sender_id", sqlType: "INTEGER", dataType: "integer", displayName: "寄信人编号", searchable: true},
+			{name: "sender", sqlType: "TEXT", dataType: "string", displayName: "寄信人", searchable: true},
+			{name: "recipient", sqlType: "TEXT", dataType: "string", displayName: "收信人", searchable: true},
+			{name: "sent_year", sqlType: "INTEGER", dataType: "integer", displayName: "年份", searchable: true},
+			{name: "place", sqlType: "TEXT", dataType: "string", displayName: "发信地", searchable: true},
+			{name: "subject", sqlType: "TEXT", dataType: "string", displayName: "主题", searchable: true},
+			{name: "summary", sqlType: "TEXT", dataType: "string", displayName: "摘要", searchable: true},
+			{name: "image_url", sqlType: "TEXT", dataType: "string", displayName: "图像", searchable: false},
+		},
+		rows: letterRows,
+	},
+}
+
+// personName 按编号确定性地生成人物姓名，信札表据此引用寄信人
+func personName(id int) string {
+	return surnames[id%len(surnames)] + givenChars[(id/len(surnames))%len(givenChars)] + givenChars[(id*7+3)%len(givenChars)]
+}
+
+func personRows() [][]interface{} {
+	r := rand.New(rand.NewSource(demoSeed))
+	rows := make([][]interface{}, 0, demoPersonCount)
+	for id := 1; id <= demoPersonCount; id++ {
+		birth := 1750 + r.Intn(120)
+		death := birth + 35 + r.Intn(50)
+		place := places[r.Intn(len(places))]
+		rows = append(rows, []interface{}{
+			id,
+			personName(id),
+			givenChars[r.Intn(len(givenChars))] + givenChars[r.Intn(len(givenChars))],
+			birth,
+			death,
+			place,
+			fmt.Sprintf("%s人，生于%d年，卒于%d年。", place, birth, death),
+		})
+	}
+	return rows
+}
+
+func letterRows() [][]interface{} {
+	r := rand.New(rand.NewSource(demoSeed + 1))
+	rows := make([][]interface{}, 0, demoLetterCount)
+	for id := 1; id <= demoLetterCount; id++ {
+		senderID := 1 + r.Intn(demoPersonCount)
+		recipientID := 1 + r.Intn(demoPersonCount)
+		if recipientID == senderID {
+			recipientID = senderID%demoPersonCount + 1
+		}
+		bits := make([]string, 0, 3)
+		for i := 0; i < 1+r.Intn(3); i++ {
+			bits = append(bits, summaryBits[r.Intn(len(summaryBits))])
+		}
+		// 约一成信札缺少发信地，便于演示完整性报告与空值过滤
+		var place interface{}
+		if r.Intn(10) > 0 {
+			place = places[r.Intn(len(places))]
+		}
+		rows = append(rows, []interface{}{
+			id,
+			fmt.Sprintf("DEMO-%05d", id),
+			senderID,
+			personName(senderID),
+			personName(recipientID),
+			1770 + r.Intn(140),
+			place,
+			subjects[r.Intn(len(subjects))],
+			strings.Join(bits, "，") + "。",
+			fmt.Sprintf("https://example.org/demo/letters/%05d.jpg", id),
+		})
+	}
+	return rows
+}
+
+// demoViews 返回演示业务组的视图：信札的表格视图（默认）与卡片视图，人物的卡片视图
+func demoViews() map[string][]*domain.ViewConfig {
+	return map[string][]*domain.ViewConfig{
+		"letters": {
+			{
+				ViewName:    "letters_table",
+				ViewType:    "table",
+				DisplayName: "信札列表",
+				IsDefault:   true,
+				Binding: domain.ViewBinding{Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{
+					{Field: "archive_no", DisplayName: "档号"},
+					{Field: "sender", DisplayName: "寄信人"},
+					{Field: "recipient", DisplayName: "收信人"},
+					{Field: "sent_year", DisplayName: "年份"},
+					{Field: "subject", DisplayName: "主题"},
+				}}},
+			},
+			{
+				ViewName:    "letters_card",
+				ViewType:    "card",
+				DisplayName: "信札卡片",
+				Binding: domain.ViewBinding{Card: &domain.CardBinding{
+					Title:       "subject",
+					Subtitle:    "sender",
+					Description: "summary",
+					ImageUrl:    "image_url",
+					Tag:         "place",
+				}},
+				Projection: map[string]string{
+					"archive_no":          "archive_no",
+					"correspondents.from": "sender",
+					"correspondents.to":   "recipient",
+					"year":                "sent_year",
+					"summary":             "summary",
+				},
+			},
+		},
+		"persons": {
+			{
+				ViewName:    "persons_card",
+				ViewType:    "card",
+				DisplayName: "人物卡片",
+				IsDefault:   true,
+				Binding: domain.ViewBinding{Card: &domain.CardBinding{
+					Title:       "name",
+					Subtitle:    "courtesy_name",
+					Description: "biography",
+					Tag:         "native_place",
+				}},
+			},
+		},
+	}
+}
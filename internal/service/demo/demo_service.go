@@ -0,0 +1,173 @@
+// Package demo file: internal/service/demo/demo_service.go
+package demo
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// DefaultBizName 是未指定名称时创建的演示业务组
+	DefaultBizName = "demo_archive"
+	demoDBFile     = "demo.db"
+)
+
+var (
+	// ErrDemoExists 表示目标业务组已存在演示库文件，且未要求覆盖
+	ErrDemoExists = errors.New("演示业务组已存在")
+	// ErrInvalidBizName 表示业务组名称不能用作 instance 下的目录名
+	ErrInvalidBizName = errors.New("非法的业务组名称")
+)
+
+// Service 一次性创建一个完整的演示业务组：SQLite 库文件、表与字段配置以及视图，
+// 便于评估者无需准备数据即可体验全部功能。生成的数据是确定性的，相同版本每次生成的内容一致。
+type Service struct {
+	configService port.QueryAdminConfigService
+	instanceDir   string
+}
+
+// Result 描述一次生成的结果
+type Result struct {
+	BizName string         `json:"biz_name"`
+	DBPath  string         `json:"db_path"`
+	Tables  map[string]int `json:"tables"` // 表名 -> 记录数
+}
+
+// NewService 创建一个新的演示数据服务实例
+func NewService(configService port.QueryAdminConfigService, instanceDir string) (*Service, error) {
+	if configService == nil {
+		return nil, errors.New("demo.Service 需要一个有效的配置服务")
+	}
+	if instanceDir == "" {
+		return nil, errors.New("demo.Service 需要有效的 instance 目录")
+	}
+	return &Service{configService: configService, instanceDir: instanceDir}, nil
+}
+
+// Seed 生成演示库文件并写入业务组配置。库文件已存在时需要 force 才会覆盖。
+// 生成的业务组仍需为其创建并启动一个 SQLite 插件实例才能被查询。
+func (s *Service) Seed(ctx context.Context, bizName string, force bool) (*Result, error) {
+	if bizName == "" {
+		bizName = DefaultBizName
+	}
+	if bizName != filepath.Base(bizName) || strings.HasPrefix(bizName, ".") {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidBizName, bizName)
+	}
+	bizDir := filepath.Join(s.instanceDir, bizName)
+	dbPath := filepath.Join(bizDir, demoDBFile)
+	if _, err := os.Stat(dbPath); err == nil {
+		if !force {
+			return nil, ErrDemoExists
+		}
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			_ = os.Remove(dbPath + suffix)
+		}
+	}
+	if err := os.MkdirAll(bizDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建目录 '%s' 失败: %w", bizDir, err)
+	}
+
+	counts, err := writeDemoDB(ctx, dbPath)
+	if err != nil {
+		_ = os.Remove(dbPath)
+		return nil, fmt.Errorf("生成演示库失败: %w", err)
+	}
+	if err := s.configure(ctx, bizName); err != nil {
+		return nil, err
+	}
+	return &Result{BizName: bizName, DBPath: dbPath, Tables: counts}, nil
+}
+
+// configure 写入业务组、表、字段与视图配置。演示业务组公开可查，但不允许写入。
+func (s *Service) configure(ctx context.Context, bizName string) error {
+	public := true
+	defaultTable := "letters"
+	citation := `{{.sender}}致{{.recipient}}（{{.sent_year}}）. {{._biz}} / {{._table}} #{{._id}}. 访问于 {{._accessed}}. {{._url}}`
+	if err := s.configService.UpdateBizOverallSettings(ctx, bizName, domain.BizOverallSettings{
+		IsPubliclySearchable: &public,
+		DefaultQueryTable:    &defaultTable,
+		CitationTemplate:     &citation,
+	}); err != nil {
+		return fmt.Errorf("配置演示业务组失败: %w", err)
+	}
+
+	tableNames := make([]string, 0, len(demoTables))
+	for _, t := range demoTables {
+		tableNames = append(tableNames, t.name)
+	}
+	if err := s.configService.UpdateBizSearchableTables(ctx, bizName, tableNames); err != nil {
+		return fmt.Errorf("配置演示业务组的可搜索表失败: %w", err)
+	}
+	for _, t := range demoTables {
+		fields := make([]domain.FieldSetting, 0, len(t.columns))
+		for _, col := range t.columns {
+			fields = append(fields, domain.FieldSetting{
+				FieldName:    col.name,
+				IsSearchable: col.searchable,
+				IsReturnable: true,
+				DataType:     col.dataType,
+				DisplayName:  col.displayName,
+			})
+		}
+		if err := s.configService.UpdateTableFieldSettings(ctx, bizName, t.name, fields); err != nil {
+			return fmt.Errorf("配置演示表 '%s' 的字段失败: %w", t.name, err)
+		}
+	}
+
+	if err := s.configService.UpdateAllViewsForBiz(ctx, bizName, demoViews()); err != nil {
+		return fmt.Errorf("配置演示业务组的视图失败: %w", err)
+	}
+	return nil
+}
+
+// writeDemoDB 建表并写入全部演示数据，返回各表的记录数
+func writeDemoDB(ctx context.Context, path string) (map[string]int, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	counts := make(map[string]int, len(demoTables))
+	for _, t := range demoTables {
+		defs := make([]string, 0, len(t.columns))
+		cols := make([]string, 0, len(t.columns))
+		for _, col := range t.columns {
+			defs = append(defs, fmt.Sprintf("%q %s", col.name, col.sqlType))
+			cols = append(cols, fmt.Sprintf("%q", col.name))
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %q (%s)", t.name, strings.Join(defs, ", "))); err != nil {
+			return nil, fmt.Errorf("创建表 '%s' 失败: %w", t.name, err)
+		}
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %q (%s) VALUES (%s)",
+			t.name, strings.Join(cols, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")))
+		if err != nil {
+			return nil, err
+		}
+		rows := t.rows()
+		for _, row := range rows {
+			if _, err := stmt.ExecContext(ctx, row...); err != nil {
+				_ = stmt.Close()
+				return nil, fmt.Errorf("写入表 '%s' 失败: %w", t.name, err)
+			}
+		}
+		_ = stmt.Close()
+		counts[t.name] = len(rows)
+	}
+	return counts, tx.Commit()
+}
@@ -0,0 +1,93 @@
+// file: internal/service/demo/demo_service_test.go
+package demo
+
+import (
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	configService, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	s, err := NewService(configService, t.TempDir())
+	require.NoError(t, err)
+	return s
+}
+
+// digest 读取演示库中信札表的一个摘要，用于比较两次生成的结果
+func digest(t *testing.T, path string) []string {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	rows, err := db.Query(`SELECT sender || '|' || recipient || '|' || sent_year || '|' || IFNULL(place, '') FROM letters WHERE id % 500 = 0 ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		require.NoError(t, rows.Scan(&s))
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	result, err := s.Seed(ctx, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBizName, result.BizName)
+	assert.Equal(t, demoPersonCount, result.Tables["persons"])
+	assert.Equal(t, demoLetterCount, result.Tables["letters"])
+
+	cfg, err := s.configService.GetBizQueryConfig(ctx, DefaultBizName)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.True(t, cfg.IsPubliclySearchable)
+	assert.Equal(t, "letters", cfg.DefaultQueryTable)
+	require.Contains(t, cfg.Tables, "letters")
+	assert.Equal(t, "寄信人", cfg.Tables["letters"].Fields["sender"].DisplayName)
+	assert.False(t, cfg.Tables["letters"].AllowCreate)
+
+	views, err := s.configService.GetAllViewConfigsForBiz(ctx, DefaultBizName)
+	require.NoError(t, err)
+	assert.Len(t, views["letters"], 2)
+	assert.Len(t, views["persons"], 1)
+
+	_, err = s.Seed(ctx, "", false)
+	assert.ErrorIs(t, err, ErrDemoExists)
+
+	_, err = s.Seed(ctx, "../escape", false)
+	assert.ErrorIs(t, err, ErrInvalidBizName)
+}
+
+func TestSeed_Deterministic(t *testing.T) {
+	ctx := context.Background()
+	first, err := newTestService(t).Seed(ctx, "demo", false)
+	require.NoError(t, err)
+	second, err := newTestService(t).Seed(ctx, "demo", false)
+	require.NoError(t, err)
+
+	a := digest(t, first.DBPath)
+	require.Len(t, a, demoLetterCount/500)
+	assert.Equal(t, a, digest(t, second.DBPath))
+
+	// force 覆盖后内容保持一致
+	s := newTestService(t)
+	r, err := s.Seed(ctx, "demo", false)
+	require.NoError(t, err)
+	_, err = s.Seed(ctx, "demo", true)
+	require.NoError(t, err)
+	assert.Equal(t, a, digest(t, r.DBPath))
+}
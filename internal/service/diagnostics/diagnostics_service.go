@@ -0,0 +1,127 @@
+// Package diagnostics file: internal/service/diagnostics/diagnostics_service.go
+package diagnostics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// FeatureID 是诊断开关在 system_features 表中的标识
+const FeatureID = "io.archiveaegis.system.diagnostics"
+
+// ErrDiagnosticsDisabled 表示诊断功能当前处于关闭状态
+var ErrDiagnosticsDisabled = errors.New("诊断功能未启用")
+
+// Service 管理运行时诊断功能：开关状态持久化在 system_features 中，
+// 开启后管理员可以读取 pprof、运行时统计以及按需生成的 goroutine / 堆转储。
+type Service struct {
+	db        *sql.DB
+	enabled   atomic.Bool
+	startedAt time.Time
+}
+
+// RuntimeStats 是一次运行时状态快照
+type RuntimeStats struct {
+	GoVersion     string        `json:"go_version"`
+	Uptime        string        `json:"uptime"`
+	NumCPU        int           `json:"num_cpu"`
+	GOMAXPROCS    int           `json:"gomaxprocs"`
+	Goroutines    int           `json:"goroutines"`
+	OpenFDs       int           `json:"open_fds"` // 无法统计的平台上为 -1
+	HeapAlloc     uint64        `json:"heap_alloc_bytes"`
+	HeapInuse     uint64        `json:"heap_inuse_bytes"`
+	HeapObjects   uint64        `json:"heap_objects"`
+	Sys           uint64        `json:"sys_bytes"`
+	NumGC         uint32        `json:"num_gc"`
+	LastGC        *time.Time    `json:"last_gc,omitempty"`
+	PauseTotal    time.Duration `json:"gc_pause_total_ns"`
+	GCCPUFraction float64       `json:"gc_cpu_fraction"`
+}
+
+// NewService 创建一个新的诊断服务实例，并从 system_features 读取开关状态
+func NewService(db *sql.DB) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("diagnostics.Service 需要一个有效的数据库连接")
+	}
+	s := &Service{db: db, startedAt: time.Now()}
+	var enabled bool
+	err := db.QueryRow(`SELECT enabled FROM system_features WHERE feature_id = ?`, FeatureID).Scan(&enabled)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("读取诊断功能状态失败: %w", err)
+	}
+	s.enabled.Store(enabled)
+	return s, nil
+}
+
+// Enabled 返回诊断功能当前是否开启
+func (s *Service) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// SetEnabled 切换诊断功能并持久化，重启后保持
+func (s *Service) SetEnabled(ctx context.Context, enabled bool) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO system_features (feature_id, enabled, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(feature_id) DO UPDATE SET enabled = excluded.enabled, updated_at = CURRENT_TIMESTAMP`,
+		FeatureID, enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("更新诊断功能状态失败: %w", err)
+	}
+	s.enabled.Store(enabled)
+	return nil
+}
+
+// Stats 采集一次运行时统计
+func (s *Service) Stats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	stats := RuntimeStats{
+		GoVersion:     runtime.Version(),
+		Uptime:        time.Since(s.startedAt).Round(time.Second).String(),
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		Goroutines:    runtime.NumGoroutine(),
+		OpenFDs:       countOpenFDs(),
+		HeapAlloc:     m.HeapAlloc,
+		HeapInuse:     m.HeapInuse,
+		HeapObjects:   m.HeapObjects,
+		Sys:           m.Sys,
+		NumGC:         m.NumGC,
+		PauseTotal:    time.Duration(m.PauseTotalNs),
+		GCCPUFraction: m.GCCPUFraction,
+	}
+	if m.LastGC > 0 {
+		last := time.Unix(0, int64(m.LastGC))
+		stats.LastGC = &last
+	}
+	return stats
+}
+
+// WriteGoroutineDump 写出所有 goroutine 的完整调用栈（文本格式）
+func (s *Service) WriteGoroutineDump(w io.Writer) error {
+	return pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// WriteHeapDump 先触发一次 GC，再写出堆分析数据（pprof 格式，可用 go tool pprof 打开）
+func (s *Service) WriteHeapDump(w io.Writer) error {
+	runtime.GC()
+	return pprof.Lookup("heap").WriteTo(w, 0)
+}
+
+// countOpenFDs 统计当前进程打开的文件描述符数量，仅在提供 /proc/self/fd 的平台上可用
+func countOpenFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
@@ -0,0 +1,46 @@
+// file: internal/service/diagnostics/diagnostics_service_test.go
+package diagnostics
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnabled_Persists(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+
+	s, err := NewService(db)
+	require.NoError(t, err)
+	assert.False(t, s.Enabled(), "诊断功能默认关闭")
+
+	require.NoError(t, s.SetEnabled(context.Background(), true))
+	assert.True(t, s.Enabled())
+
+	reloaded, err := NewService(db)
+	require.NoError(t, err)
+	assert.True(t, reloaded.Enabled(), "开关状态应在重启后保留")
+}
+
+func TestStatsAndDumps(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+	s, err := NewService(db)
+	require.NoError(t, err)
+
+	stats := s.Stats()
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAlloc)
+	assert.NotEmpty(t, stats.GoVersion)
+
+	var goroutines bytes.Buffer
+	require.NoError(t, s.WriteGoroutineDump(&goroutines))
+	assert.Contains(t, goroutines.String(), "goroutine")
+
+	var heap bytes.Buffer
+	require.NoError(t, s.WriteHeapDump(&heap))
+	assert.Positive(t, heap.Len())
+}
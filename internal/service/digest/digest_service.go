@@ -0,0 +1,387 @@
+// Package digest file: internal/service/digest/digest_service.go
+package digest
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"path/filepath"
+	"time"
+)
+
+const (
+	dayLayout       = "2006-01-02"
+	eventTimeLayout = "2006-01-02 15:04:05" // plugin_events.created_at 使用 SQLite 的 CURRENT_TIMESTAMP 格式
+
+	defaultTopBizs   = 5
+	defaultListLimit = 30
+	maxListLimit     = 366
+
+	// 投递结果中的渠道名
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	deliveryOK     = "ok"
+)
+
+var (
+	// ErrInvalidDay 表示日期不是 YYYY-MM-DD 格式，或者是尚未结束的日期
+	ErrInvalidDay = errors.New("无效的摘要日期")
+	// ErrNotFound 表示指定日期的摘要不存在
+	ErrNotFound = errors.New("摘要不存在")
+)
+
+// EmailOptions 定义通过 SMTP 投递摘要的配置，SMTPAddr 为空表示不发送邮件
+type EmailOptions struct {
+	SMTPAddr string   `mapstructure:"smtp_addr"` // host:port
+	Username string   `mapstructure:"username"`  // 为空时不进行 SMTP 认证
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// Options 定义每日摘要的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Hour 是每天生成前一天摘要的时刻 (UTC 小时，0-23)
+	Hour int `mapstructure:"hour"`
+	// TopBizs 是摘要中列出的检索最多的业务组数量，为 0 时使用 5
+	TopBizs int `mapstructure:"top_bizs"`
+	// RetentionDays 是摘要的保留天数，0 表示永久保留
+	RetentionDays int `mapstructure:"retention_days"`
+	// WebhookURL 不为空时把摘要以 JSON POST 到该地址
+	WebhookURL string       `mapstructure:"webhook_url"`
+	Email      EmailOptions `mapstructure:"email"`
+}
+
+// Service 每天汇总前一天的请求数、错误数、检索最多的业务组、插件重启与存储增长，
+// 保存到数据库并通过 webhook 与邮件发给管理员
+type Service struct {
+	db         *sql.DB
+	storageDir string
+	opts       Options
+	bootID     string
+	now        func() time.Time
+	counters   func() (requests, errors int64)
+	client     *http.Client
+	sendMail   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewService 创建一个新的每日摘要服务实例，storageDir 是统计存储占用的目录 (通常为 instance 目录)
+func NewService(db *sql.DB, storageDir string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("digest.Service 需要一个有效的数据库连接")
+	}
+	if opts.Hour < 0 || opts.Hour > 23 {
+		return nil, fmt.Errorf("hour 必须在 0 到 23 之间: %d", opts.Hour)
+	}
+	if opts.TopBizs < 0 {
+		return nil, fmt.Errorf("top_bizs 不能为负数: %d", opts.TopBizs)
+	}
+	if opts.RetentionDays < 0 {
+		return nil, fmt.Errorf("retention_days 不能为负数: %d", opts.RetentionDays)
+	}
+	if opts.Email.SMTPAddr != "" && (opts.Email.From == "" || len(opts.Email.To) == 0) {
+		return nil, errors.New("启用邮件投递时必须配置 email.from 与 email.to")
+	}
+	if opts.TopBizs == 0 {
+		opts.TopBizs = defaultTopBizs
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("生成进程标识失败: %w", err)
+	}
+	return &Service{
+		db:         db,
+		storageDir: storageDir,
+		opts:       opts,
+		bootID:     hex.EncodeToString(buf),
+		now:        time.Now,
+		counters:   aegobserve.RequestTotals,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		sendMail:   smtp.SendMail,
+	}, nil
+}
+
+// Run 定期检查前一天的摘要是否已生成，到达配置的时刻后生成并投递，ctx 结束时返回
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.runDue(ctx); err != nil {
+				slog.Error("[Digest] 生成每日摘要失败", "error", err)
+			}
+		}
+	}
+}
+
+// runDue 在到达生成时刻且前一天的摘要尚不存在时生成摘要，并清理过期摘要
+func (s *Service) runDue(ctx context.Context) error {
+	now := s.now().UTC()
+	if now.Hour() < s.opts.Hour {
+		return nil
+	}
+	day := now.AddDate(0, 0, -1).Format(dayLayout)
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM admin_daily_digests WHERE day = ?`, day).Scan(&exists)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("查询每日摘要失败: %w", err)
+	}
+	if _, err := s.Generate(ctx, day); err != nil {
+		return err
+	}
+	if s.opts.RetentionDays > 0 {
+		cutoff := now.AddDate(0, 0, -s.opts.RetentionDays).Format(dayLayout)
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM admin_daily_digests WHERE day < ?`, cutoff); err != nil {
+			return fmt.Errorf("清理过期摘要失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Generate 汇总指定日期 (YYYY-MM-DD，UTC，必须已经结束) 的摘要，投递后保存，已存在的同日摘要会被覆盖。
+// 投递失败不会导致生成失败，结果记录在摘要的 Delivery 中
+func (s *Service) Generate(ctx context.Context, day string) (*domain.DailyDigest, error) {
+	start, err := time.Parse(dayLayout, day)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' 不是 YYYY-MM-DD 格式", ErrInvalidDay, day)
+	}
+	end := start.AddDate(0, 0, 1)
+	now := s.now().UTC()
+	if end.After(now) {
+		return nil, fmt.Errorf("%w: %s 尚未结束", ErrInvalidDay, day)
+	}
+
+	digest := &domain.DailyDigest{
+		Day:              day,
+		TopBizs:          make([]domain.BizUsage, 0),
+		RestartedPlugins: make([]domain.PluginRun, 0),
+		CreatedAt:        now,
+	}
+
+	// 请求数与存储增长都相对于前一份摘要计算
+	var prevBoot string
+	var prevRequests, prevErrors, prevStorage int64
+	err = s.db.QueryRowContext(ctx, `
+		SELECT boot_id, requests_counter, errors_counter, storage_bytes FROM admin_daily_digests
+		WHERE day < ? ORDER BY day DESC LIMIT 1`, day).Scan(&prevBoot, &prevRequests, &prevErrors, &prevStorage)
+	hasPrev := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("查询上一份摘要失败: %w", err)
+	}
+
+	requests, errorsCount := s.counters()
+	digest.Requests, digest.Errors = requests, errorsCount
+	if hasPrev && prevBoot == s.bootID {
+		digest.Requests -= prevRequests
+		digest.Errors -= prevErrors
+	}
+
+	digest.StorageBytes = s.storageBytes()
+	if hasPrev {
+		digest.StorageGrowth = digest.StorageBytes - prevStorage
+	}
+
+	if err := s.collectUsage(ctx, digest); err != nil {
+		return nil, err
+	}
+	if err := s.collectPluginEvents(ctx, digest, start, end); err != nil {
+		return nil, err
+	}
+
+	digest.Delivery = s.deliver(ctx, digest)
+
+	report, err := json.Marshal(digest)
+	if err != nil {
+		return nil, fmt.Errorf("序列化摘要失败: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO admin_daily_digests (day, report, boot_id, requests_counter, errors_counter, storage_bytes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(day) DO UPDATE SET report = excluded.report, boot_id = excluded.boot_id,
+			requests_counter = excluded.requests_counter, errors_counter = excluded.errors_counter,
+			storage_bytes = excluded.storage_bytes, created_at = excluded.created_at`,
+		day, string(report), s.bootID, requests, errorsCount, digest.StorageBytes, now)
+	if err != nil {
+		return nil, fmt.Errorf("保存摘要失败: %w", err)
+	}
+	return digest, nil
+}
+
+// List 按日期倒序返回最近的摘要，limit 不大于 0 时返回 30 份
+func (s *Service) List(ctx context.Context, limit int) ([]domain.DailyDigest, error) {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return s.query(ctx, ` ORDER BY day DESC LIMIT ?`, limit)
+}
+
+// Get 返回指定日期的摘要
+func (s *Service) Get(ctx context.Context, day string) (*domain.DailyDigest, error) {
+	list, err := s.query(ctx, ` WHERE day = ?`, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, day)
+	}
+	return &list[0], nil
+}
+
+func (s *Service) query(ctx context.Context, suffix string, args ...any) ([]domain.DailyDigest, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT report FROM admin_daily_digests`+suffix, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询每日摘要失败: %w", err)
+	}
+	defer rows.Close()
+	list := make([]domain.DailyDigest, 0)
+	for rows.Next() {
+		var report string
+		if err := rows.Scan(&report); err != nil {
+			return nil, fmt.Errorf("扫描每日摘要失败: %w", err)
+		}
+		var d domain.DailyDigest
+		if err := json.Unmarshal([]byte(report), &d); err != nil {
+			return nil, fmt.Errorf("解析每日摘要失败: %w", err)
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// collectUsage 读取当天检索最多的业务组。未启用检索统计时该表为空
+func (s *Service) collectUsage(ctx context.Context, digest *domain.DailyDigest) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT biz_name, queries, zero_results FROM biz_usage_daily
+		WHERE day = ? ORDER BY queries DESC, biz_name LIMIT ?`, digest.Day, s.opts.TopBizs)
+	if err != nil {
+		return fmt.Errorf("查询业务组检索次数失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var u domain.BizUsage
+		if err := rows.Scan(&u.BizName, &u.Queries, &u.ZeroResults); err != nil {
+			return fmt.Errorf("扫描业务组检索次数失败: %w", err)
+		}
+		digest.TopBizs = append(digest.TopBizs, u)
+	}
+	return rows.Err()
+}
+
+// collectPluginEvents 统计当天各插件实例的重启与崩溃次数
+func (s *Service) collectPluginEvents(ctx context.Context, digest *domain.DailyDigest, start, end time.Time) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT instance_id, plugin_id,
+			SUM(CASE WHEN event = 'restarted' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN event = 'crashed' THEN 1 ELSE 0 END)
+		FROM plugin_events
+		WHERE event IN ('restarted', 'crashed') AND created_at >= ? AND created_at < ?
+		GROUP BY instance_id, plugin_id ORDER BY instance_id`,
+		start.Format(eventTimeLayout), end.Format(eventTimeLayout))
+	if err != nil {
+		return fmt.Errorf("查询插件事件失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r domain.PluginRun
+		if err := rows.Scan(&r.InstanceID, &r.PluginID, &r.Restarts, &r.Crashes); err != nil {
+			return fmt.Errorf("扫描插件事件失败: %w", err)
+		}
+		digest.PluginRestarts += r.Restarts
+		digest.PluginCrashes += r.Crashes
+		digest.RestartedPlugins = append(digest.RestartedPlugins, r)
+	}
+	return rows.Err()
+}
+
+// storageBytes 统计存储目录下所有文件的大小，无法读取的文件跳过
+func (s *Service) storageBytes() int64 {
+	if s.storageDir == "" {
+		return 0
+	}
+	var total int64
+	_ = filepath.WalkDir(s.storageDir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// deliver 通过已配置的渠道投递摘要，返回各渠道的结果；没有配置任何渠道时返回 nil
+func (s *Service) deliver(ctx context.Context, digest *domain.DailyDigest) map[string]string {
+	var results map[string]string
+	record := func(channel string, err error) {
+		if results == nil {
+			results = make(map[string]string)
+		}
+		if err != nil {
+			slog.Warn("[Digest] 投递每日摘要失败", "channel", channel, "day", digest.Day, "error", err)
+			results[channel] = err.Error()
+			return
+		}
+		results[channel] = deliveryOK
+	}
+	if s.opts.WebhookURL != "" {
+		record(ChannelWebhook, s.postWebhook(ctx, digest))
+	}
+	if s.opts.Email.SMTPAddr != "" {
+		record(ChannelEmail, s.sendEmail(digest))
+	}
+	return results
+}
+
+func (s *Service) postWebhook(ctx context.Context, digest *domain.DailyDigest) error {
+	body, err := json.Marshal(digest)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Service) sendEmail(digest *domain.DailyDigest) error {
+	var auth smtp.Auth
+	if s.opts.Email.Username != "" {
+		host, _, _ := net.SplitHostPort(s.opts.Email.SMTPAddr)
+		auth = smtp.PlainAuth("", s.opts.Email.Username, s.opts.Email.Password, host)
+	}
+	return s.sendMail(s.opts.Email.SMTPAddr, auth, s.opts.Email.From, s.opts.Email.To, renderEmail(s.opts.Email, digest))
+}
@@ -0,0 +1,146 @@
+// file: internal/service/digest/digest_service_test.go
+package digest
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, *sql.DB, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+	svc, err := NewService(db, dir, opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC) }
+	return svc, db, dir
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	var posted domain.DailyDigest
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+	}))
+	defer webhook.Close()
+
+	svc, db, dir := newTestService(t, Options{Enabled: true, TopBizs: 2, WebhookURL: webhook.URL,
+		Email: EmailOptions{SMTPAddr: "mail.example.org:25", From: "aegis@example.org", To: []string{"admin@example.org"}}})
+	var mail string
+	svc.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		mail = string(msg)
+		return nil
+	}
+
+	_, err := db.Exec(`INSERT INTO biz_usage_daily (biz_name, day, queries, zero_results) VALUES
+		('letters', '2026-03-01', 40, 3), ('maps', '2026-03-01', 90, 0), ('photos', '2026-03-01', 10, 1), ('letters', '2026-02-28', 500, 0)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO plugin_events (instance_id, plugin_id, event, created_at) VALUES
+		('sqlite-1', 'sqlite', 'crashed', '2026-03-01 03:00:00'), ('sqlite-1', 'sqlite', 'restarted', '2026-03-01 03:00:05'),
+		('sqlite-1', 'sqlite', 'restarted', '2026-03-01 09:00:00'), ('sqlite-1', 'sqlite', 'started', '2026-03-01 09:00:00'),
+		('sqlite-1', 'sqlite', 'restarted', '2026-03-02 01:00:00')`)
+	require.NoError(t, err)
+
+	svc.counters = func() (int64, int64) { return 100, 4 }
+	first, err := svc.Generate(ctx, "2026-02-28")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), first.Requests)
+	assert.Zero(t, first.StorageGrowth, "没有上一份摘要时不计算增长")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.db"), make([]byte, 2048), 0o644))
+	svc.counters = func() (int64, int64) { return 250, 5 }
+	digest, err := svc.Generate(ctx, "2026-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), digest.Requests, "请求数相对于上一份摘要计算")
+	assert.Equal(t, int64(1), digest.Errors)
+	assert.Equal(t, []domain.BizUsage{{BizName: "maps", Queries: 90}, {BizName: "letters", Queries: 40, ZeroResults: 3}}, digest.TopBizs)
+	assert.Equal(t, int64(2), digest.PluginRestarts)
+	assert.Equal(t, int64(1), digest.PluginCrashes)
+	assert.Equal(t, []domain.PluginRun{{InstanceID: "sqlite-1", PluginID: "sqlite", Restarts: 2, Crashes: 1}}, digest.RestartedPlugins)
+	assert.GreaterOrEqual(t, digest.StorageGrowth, int64(2048))
+	assert.Equal(t, map[string]string{ChannelWebhook: "ok", ChannelEmail: "ok"}, digest.Delivery)
+
+	assert.Equal(t, "2026-03-01", posted.Day)
+	assert.Contains(t, mail, "Subject: =?utf-8?q?")
+	assert.Contains(t, mail, "maps: 90 次检索")
+
+	stored, err := svc.Get(ctx, "2026-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, digest.Requests, stored.Requests)
+	list, err := svc.List(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "2026-03-01", list[0].Day)
+
+	// 网关重启后计数器从零开始，不能与上一份摘要相减
+	svc.bootID = "restarted"
+	svc.now = func() time.Time { return time.Date(2026, 3, 3, 7, 0, 0, 0, time.UTC) }
+	svc.counters = func() (int64, int64) { return 30, 0 }
+	next, err := svc.Generate(ctx, "2026-03-02")
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), next.Requests)
+
+	_, err = svc.Get(ctx, "2026-01-01")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Generate(ctx, "2026-03-03")
+	assert.ErrorIs(t, err, ErrInvalidDay, "当天尚未结束")
+	_, err = svc.Generate(ctx, "yesterday")
+	assert.ErrorIs(t, err, ErrInvalidDay)
+}
+
+func TestGenerate_DeliveryFailure(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+	svc, _, _ := newTestService(t, Options{Enabled: true, WebhookURL: webhook.URL})
+
+	digest, err := svc.Generate(context.Background(), "2026-03-01")
+	require.NoError(t, err, "投递失败不影响摘要的生成与保存")
+	assert.True(t, strings.Contains(digest.Delivery[ChannelWebhook], "502"))
+	_, err = svc.Get(context.Background(), "2026-03-01")
+	assert.NoError(t, err)
+}
+
+func TestRunDue(t *testing.T) {
+	ctx := context.Background()
+	svc, db, _ := newTestService(t, Options{Enabled: true, Hour: 8, RetentionDays: 30})
+	_, err := db.Exec(`INSERT INTO admin_daily_digests (day, report, created_at) VALUES ('2026-01-01', '{"day":"2026-01-01"}', '2026-01-02')`)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.runDue(ctx))
+	_, err = svc.Get(ctx, "2026-03-01")
+	assert.ErrorIs(t, err, ErrNotFound, "未到生成时刻")
+
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC) }
+	require.NoError(t, svc.runDue(ctx))
+	_, err = svc.Get(ctx, "2026-03-01")
+	assert.NoError(t, err)
+	_, err = svc.Get(ctx, "2026-01-01")
+	assert.ErrorIs(t, err, ErrNotFound, "过期摘要被清理")
+}
+
+func TestNewService_InvalidOptions(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = NewService(db, "", Options{Hour: 24})
+	assert.Error(t, err)
+	_, err = NewService(db, "", Options{Email: EmailOptions{SMTPAddr: "mail.example.org:25"}})
+	assert.Error(t, err)
+}
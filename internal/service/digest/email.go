@@ -0,0 +1,55 @@
+// Package digest file: internal/service/digest/email.go
+package digest
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// renderEmail 生成纯文本的摘要邮件 (含邮件头)
+func renderEmail(opts EmailOptions, digest *domain.DailyDigest) []byte {
+	var body strings.Builder
+	fmt.Fprintf(&body, "ArchiveAegis 每日摘要 (%s，UTC)\r\n\r\n", digest.Day)
+	fmt.Fprintf(&body, "请求数: %d\r\n错误数 (5xx): %d\r\n", digest.Requests, digest.Errors)
+	fmt.Fprintf(&body, "插件重启: %d，崩溃: %d\r\n", digest.PluginRestarts, digest.PluginCrashes)
+	fmt.Fprintf(&body, "存储占用: %s (变化 %+d 字节)\r\n", formatBytes(digest.StorageBytes), digest.StorageGrowth)
+
+	if len(digest.TopBizs) > 0 {
+		body.WriteString("\r\n检索最多的业务组:\r\n")
+		for _, u := range digest.TopBizs {
+			fmt.Fprintf(&body, "  %s: %d 次检索，%d 次无结果\r\n", u.BizName, u.Queries, u.ZeroResults)
+		}
+	}
+	if len(digest.RestartedPlugins) > 0 {
+		body.WriteString("\r\n发生重启或崩溃的插件实例:\r\n")
+		for _, r := range digest.RestartedPlugins {
+			fmt.Fprintf(&body, "  %s (%s): 重启 %d 次，崩溃 %d 次\r\n", r.InstanceID, r.PluginID, r.Restarts, r.Crashes)
+		}
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(opts.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "ArchiveAegis 每日摘要 "+digest.Day))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(body.String())
+	return []byte(msg.String())
+}
+
+// formatBytes 把字节数格式化为便于阅读的单位
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
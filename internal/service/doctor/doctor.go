@@ -0,0 +1,256 @@
+// Package doctor file: internal/service/doctor/doctor.go
+package doctor
+
+import (
+	"ArchiveAegis/internal/service/plugin_manager"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// 检查结果的状态
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Finding 是一项检查的结果。Hint 给出可操作的修复建议。
+type Finding struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Report 是一次完整自检的结果。存在任何 fail 项时 Healthy 为 false。
+type Report struct {
+	Healthy   bool      `json:"healthy"`
+	CheckedAt time.Time `json:"checked_at"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Options 描述需要检查的配置与运行环境
+type Options struct {
+	RootDir      string
+	ConfigPath   string
+	ConfigErr    error // 读取或解析配置时的错误，非空时配置检查直接失败
+	InstanceDir  string
+	DBPath       string
+	InstallDir   string
+	Repositories []plugin_manager.RepositoryConfig
+	// ListenAddrs 是需要确认未被占用的监听地址（名称 -> 地址）。
+	// 网关运行中自检时这些端口由本进程占用，应设置 SkipPorts。
+	ListenAddrs map[string]string
+	SkipPorts   bool
+	// ProbeRepository 读取仓库并返回插件数量，为空时使用 plugin_manager.ProbeRepository
+	ProbeRepository func(rootDir string, repo plugin_manager.RepositoryConfig) (int, error)
+}
+
+// Service 让运行中的网关按需重新执行自检
+type Service struct {
+	opts Options
+}
+
+// NewService 创建一个自检服务实例。网关运行中端口必然被本进程占用，因此总是跳过端口检查。
+func NewService(opts Options) (*Service, error) {
+	if opts.ConfigPath == "" || opts.DBPath == "" {
+		return nil, errors.New("doctor.Service 需要配置文件与系统库路径")
+	}
+	opts.SkipPorts = true
+	return &Service{opts: opts}, nil
+}
+
+// Run 执行一次完整自检
+func (s *Service) Run(ctx context.Context) *Report {
+	return Run(ctx, s.opts)
+}
+
+// Run 依次执行全部检查。单项检查失败不会中断后续检查。
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{CheckedAt: time.Now()}
+	add := func(f Finding) {
+		report.Findings = append(report.Findings, f)
+	}
+
+	add(checkConfig(opts))
+	add(checkWritableDir("instance_dir", opts.InstanceDir, "确认运行网关的用户对该目录有写权限，或以正确的用户身份启动"))
+	add(checkDatabase(ctx, opts.DBPath))
+	add(checkWritableDir("plugin_install_dir", opts.InstallDir, "检查 plugin_management.install_directory 配置，并确认该目录可写"))
+	for _, f := range checkPorts(opts) {
+		add(f)
+	}
+	for _, f := range checkRepositories(opts) {
+		add(f)
+	}
+
+	report.Healthy = true
+	for _, f := range report.Findings {
+		if f.Status == StatusFail {
+			report.Healthy = false
+			break
+		}
+	}
+	return report
+}
+
+func checkConfig(opts Options) Finding {
+	f := Finding{Check: "config"}
+	if _, err := os.Stat(opts.ConfigPath); err != nil {
+		f.Status, f.Message = StatusFail, fmt.Sprintf("找不到配置文件 '%s': %v", opts.ConfigPath, err)
+		f.Hint = "网关从可执行文件上一级目录的 configs/config.yaml 读取配置，请确认目录结构完整"
+		return f
+	}
+	if opts.ConfigErr != nil {
+		f.Status, f.Message = StatusFail, fmt.Sprintf("配置文件 '%s' 无法解析: %v", opts.ConfigPath, opts.ConfigErr)
+		f.Hint = "检查 YAML 缩进与字段类型，可对照仓库中的 configs/config.yaml 示例"
+		return f
+	}
+	f.Status, f.Message = StatusOK, fmt.Sprintf("配置文件 '%s' 已加载", opts.ConfigPath)
+	return f
+}
+
+// checkWritableDir 确认目录存在（或可被创建）且可写。目录不存在时只检查其最近的已存在上级目录，不产生副作用。
+func checkWritableDir(check, dir, hint string) Finding {
+	f := Finding{Check: check, Hint: hint}
+	if dir == "" {
+		f.Status, f.Message = StatusFail, "未配置目录"
+		return f
+	}
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				f.Status, f.Message = StatusFail, fmt.Sprintf("'%s' 不是目录", target)
+				return f
+			}
+			break
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			f.Status, f.Message = StatusFail, fmt.Sprintf("目录 '%s' 及其上级目录均不存在", dir)
+			return f
+		}
+		target = parent
+	}
+
+	probe, err := os.CreateTemp(target, ".aegis-doctor-*")
+	if err != nil {
+		f.Status, f.Message = StatusFail, fmt.Sprintf("目录 '%s' 不可写: %v", target, err)
+		return f
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	f.Hint = ""
+	if target != dir {
+		f.Status, f.Message = StatusOK, fmt.Sprintf("目录 '%s' 尚不存在，将在启动时于 '%s' 下创建", dir, target)
+		return f
+	}
+	f.Status, f.Message = StatusOK, fmt.Sprintf("目录 '%s' 可写", dir)
+	return f
+}
+
+// checkDatabase 在回滚的事务中建一张临时表，以确认系统库可写且未被其它进程长时间锁定
+func checkDatabase(ctx context.Context, path string) Finding {
+	f := Finding{Check: "system_db"}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		f.Status, f.Message = StatusOK, fmt.Sprintf("系统库 '%s' 尚不存在，将在首次启动时创建", path)
+		return f
+	}
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_busy_timeout=3000", path))
+	if err != nil {
+		f.Status, f.Message = StatusFail, fmt.Sprintf("无法打开系统库 '%s': %v", path, err)
+		return f
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	tx, err := db.BeginTx(ctx, nil)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `CREATE TABLE _aegis_doctor_probe (id INTEGER)`)
+		_ = tx.Rollback()
+	}
+	if err != nil {
+		f.Status, f.Message = StatusFail, fmt.Sprintf("系统库 '%s' 不可写: %v", path, err)
+		f.Hint = "确认库文件及其所在目录（WAL 需要创建 -wal/-shm 文件）对运行网关的用户可写，且没有其它进程持有写锁"
+		return f
+	}
+	f.Status, f.Message = StatusOK, fmt.Sprintf("系统库 '%s' 可读写", path)
+	return f
+}
+
+func checkPorts(opts Options) []Finding {
+	if opts.SkipPorts {
+		return []Finding{{Check: "ports", Status: StatusSkip, Message: "网关正在运行，监听端口由本进程占用，跳过检查"}}
+	}
+	names := make([]string, 0, len(opts.ListenAddrs))
+	for name := range opts.ListenAddrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	findings := make([]Finding, 0, len(names))
+	for _, name := range names {
+		addr := opts.ListenAddrs[name]
+		f := Finding{Check: "port:" + name}
+		if addr == "" {
+			f.Status, f.Message = StatusSkip, "未配置监听地址"
+			findings = append(findings, f)
+			continue
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			f.Status, f.Message = StatusFail, fmt.Sprintf("无法监听 '%s': %v", addr, err)
+			f.Hint = "该端口可能已被其它进程（或另一个网关实例）占用，请更换端口或停止占用的进程"
+		} else {
+			_ = ln.Close()
+			f.Status, f.Message = StatusOK, fmt.Sprintf("'%s' 可用", addr)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func checkRepositories(opts Options) []Finding {
+	probe := opts.ProbeRepository
+	if probe == nil {
+		probe = plugin_manager.ProbeRepository
+	}
+	if len(opts.Repositories) == 0 {
+		return []Finding{{Check: "repositories", Status: StatusWarn, Message: "未配置任何插件仓库", Hint: "在 plugin_management.repositories 中至少配置一个仓库，否则无法安装插件"}}
+	}
+	findings := make([]Finding, 0, len(opts.Repositories))
+	for _, repo := range opts.Repositories {
+		f := Finding{Check: "repository:" + repo.Name}
+		if !repo.Enabled {
+			f.Status, f.Message = StatusSkip, "仓库已禁用"
+			findings = append(findings, f)
+			continue
+		}
+		count, err := probe(opts.RootDir, repo)
+		switch {
+		case err != nil:
+			f.Status, f.Message = StatusWarn, fmt.Sprintf("无法读取仓库 '%s': %v", repo.URL, err)
+			f.Hint = "检查仓库 URL、网络连通性或代理设置；仓库不可达不影响已安装插件的运行"
+		case count == 0:
+			f.Status, f.Message = StatusWarn, fmt.Sprintf("仓库 '%s' 可达，但其中没有任何插件", repo.URL)
+		default:
+			f.Status, f.Message = StatusOK, fmt.Sprintf("仓库 '%s' 可达，包含 %d 个插件", repo.URL, count)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
@@ -0,0 +1,112 @@
+// file: internal/service/doctor/doctor_test.go
+package doctor
+
+import (
+	"ArchiveAegis/internal/service/plugin_manager"
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findingsByCheck(r *Report) map[string]Finding {
+	out := make(map[string]Finding, len(r.Findings))
+	for _, f := range r.Findings {
+		out[f.Check] = f
+	}
+	return out
+}
+
+func TestRun_Healthy(t *testing.T) {
+	root := t.TempDir()
+	configPath := filepath.Join(root, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  port: 0\n"), 0644))
+	dbPath := filepath.Join(root, "auth.db")
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	repoPath := filepath.Join(root, "repo.json")
+	require.NoError(t, os.WriteFile(repoPath, []byte(`{"repository_name":"local","plugins":[{"id":"sqlite"}]}`), 0644))
+
+	report := Run(context.Background(), Options{
+		RootDir:     root,
+		ConfigPath:  configPath,
+		InstanceDir: root,
+		DBPath:      dbPath,
+		InstallDir:  filepath.Join(root, "not", "yet", "created"),
+		Repositories: []plugin_manager.RepositoryConfig{
+			{Name: "local", URL: "repo.json", Enabled: true},
+			{Name: "off", URL: "https://invalid.example", Enabled: false},
+		},
+		ListenAddrs: map[string]string{"server": "127.0.0.1:0", "metrics": ""},
+	})
+
+	assert.True(t, report.Healthy, "%+v", report.Findings)
+	checks := findingsByCheck(report)
+	assert.Equal(t, StatusOK, checks["config"].Status)
+	assert.Equal(t, StatusOK, checks["system_db"].Status)
+	assert.Equal(t, StatusOK, checks["plugin_install_dir"].Status)
+	assert.Contains(t, checks["plugin_install_dir"].Message, "尚不存在")
+	assert.NoDirExists(t, filepath.Join(root, "not"), "自检不应创建目录")
+	assert.Equal(t, StatusOK, checks["port:server"].Status)
+	assert.Equal(t, StatusSkip, checks["port:metrics"].Status)
+	assert.Equal(t, StatusOK, checks["repository:local"].Status)
+	assert.Equal(t, StatusSkip, checks["repository:off"].Status)
+
+	// 探测用的临时表不应留在库中
+	db, err = sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = '_aegis_doctor_probe'`).Scan(&n))
+	assert.Zero(t, n)
+}
+
+func TestRun_Failures(t *testing.T) {
+	root := t.TempDir()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	report := Run(context.Background(), Options{
+		RootDir:     root,
+		ConfigPath:  filepath.Join(root, "missing.yaml"),
+		InstanceDir: root,
+		DBPath:      filepath.Join(root, "auth.db"),
+		InstallDir:  root,
+		Repositories: []plugin_manager.RepositoryConfig{
+			{Name: "remote", URL: "https://repo.example/index.json", Enabled: true},
+		},
+		ListenAddrs: map[string]string{"server": ln.Addr().String()},
+		ProbeRepository: func(string, plugin_manager.RepositoryConfig) (int, error) {
+			return 0, errors.New("dial tcp: no such host")
+		},
+	})
+
+	assert.False(t, report.Healthy)
+	checks := findingsByCheck(report)
+	assert.Equal(t, StatusFail, checks["config"].Status)
+	assert.NotEmpty(t, checks["config"].Hint)
+	assert.Equal(t, StatusFail, checks["port:server"].Status)
+	assert.Equal(t, StatusWarn, checks["repository:remote"].Status, "仓库不可达只是警告")
+	assert.Equal(t, StatusOK, checks["system_db"].Status, "系统库不存在时将在启动时创建")
+}
+
+func TestService_SkipsPorts(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewService(Options{ConfigPath: filepath.Join(root, "c.yaml"), DBPath: filepath.Join(root, "auth.db"), ListenAddrs: map[string]string{"server": ":1"}})
+	require.NoError(t, err)
+	checks := findingsByCheck(s.Run(context.Background()))
+	assert.Equal(t, StatusSkip, checks["ports"].Status)
+	_, hasServer := checks["port:server"]
+	assert.False(t, hasServer)
+}
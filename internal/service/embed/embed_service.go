@@ -0,0 +1,408 @@
+// Package embed file: internal/service/embed/embed_service.go
+package embed
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxPageSize = 50
+	defaultPageSize    = 10
+	maxSearchRunes     = 200
+	// FilterParamPrefix 是固定过滤条件的参数前缀，如 filter.author=鲁迅
+	FilterParamPrefix = "filter."
+)
+
+// 嵌入地址中不参与签名的参数：签名本身，以及访问者可以自由改变的检索词、页码与输出格式
+var unsignedParams = map[string]bool{"sig": true, "q": true, "page": true, "format": true}
+
+var (
+	// ErrInvalidEmbed 表示嵌入参数或密钥设置不合法
+	ErrInvalidEmbed = errors.New("无效的嵌入参数")
+	// ErrNotFound 表示嵌入密钥不存在或已撤销
+	ErrNotFound = errors.New("嵌入密钥不存在或已撤销")
+	// ErrForbidden 表示签名无效、已过期或来源不在允许范围内
+	ErrForbidden = errors.New("嵌入请求被拒绝")
+)
+
+// Options 定义嵌入接口的配置
+type Options struct {
+	// MaxPageSize 是嵌入结果每页的记录数上限，默认为 50
+	MaxPageSize int `mapstructure:"max_page_size"`
+}
+
+// Result 是一次嵌入查询的结果
+type Result struct {
+	BizName string `json:"biz_name"`
+	Table   string `json:"table"`
+	// Fields 是结果的列顺序：签名参数指定了 fields 时按其顺序，否则按字段名排序
+	Fields []string                 `json:"fields"`
+	Items  []map[string]interface{} `json:"items"`
+	Total  int64                    `json:"total"`
+	Page   int                      `json:"page"`
+	Size   int                      `json:"size"`
+}
+
+// Service 让外部网站无需凭据即可嵌入实时检索结果。
+// 外部网站的后端用嵌入密钥对查询参数 (业务组、表、固定过滤条件、返回字段、每页条数、过期时间) 做 HMAC-SHA256 签名，
+// 浏览器中的访问者只能改变检索词 q 与页码 page；查询仍经由数据源执行，只能看到公开检索可见的数据。
+type Service struct {
+	db       *sql.DB
+	registry map[string]port.DataSource
+	opts     Options
+	now      func() time.Time
+}
+
+// NewService 创建一个新的嵌入服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("embed.Service 需要一个有效的数据库连接")
+	}
+	if opts.MaxPageSize < 0 {
+		return nil, errors.New("嵌入结果每页的记录数上限不能为负数")
+	}
+	if opts.MaxPageSize == 0 {
+		opts.MaxPageSize = defaultMaxPageSize
+	}
+	return &Service{db: db, registry: registry, opts: opts, now: time.Now}, nil
+}
+
+// CreateKey 为一个业务组签发嵌入密钥，返回的签名密钥只在此时可见
+func (s *Service) CreateKey(ctx context.Context, label, bizName string, origins []string) (*domain.EmbedKey, string, error) {
+	bizName = strings.TrimSpace(bizName)
+	if bizName == "" {
+		return nil, "", fmt.Errorf("%w: 必须提供 biz_name", ErrInvalidEmbed)
+	}
+	if _, exists := s.registry[bizName]; !exists {
+		return nil, "", port.ErrBizNotFound
+	}
+	normalized := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		o, err := normalizeOrigin(origin)
+		if err != nil {
+			return nil, "", err
+		}
+		normalized = append(normalized, o)
+	}
+	if len(normalized) == 0 {
+		return nil, "", fmt.Errorf("%w: 必须提供至少一个 allowed_origins，不限来源时使用 \"*\"", ErrInvalidEmbed)
+	}
+	originsJSON, _ := json.Marshal(normalized)
+
+	keyID, err := randomString(9)
+	if err != nil {
+		return nil, "", err
+	}
+	keyID = "ek_" + keyID
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO embed_keys (key_id, secret, label, biz_name, allowed_origins_json, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		keyID, secret, strings.TrimSpace(label), bizName, string(originsJSON), s.now().UTC())
+	if err != nil {
+		return nil, "", fmt.Errorf("写入嵌入密钥失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+	key, _, err := s.getKey(ctx, `id = ?`, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// ListKeys 返回全部嵌入密钥，不含签名密钥
+func (s *Service) ListKeys(ctx context.Context) ([]domain.EmbedKey, error) {
+	rows, err := s.db.QueryContext(ctx, selectColumns+` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询嵌入密钥失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.EmbedKey{}
+	for rows.Next() {
+		key, _, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描嵌入密钥失败: %w", err)
+		}
+		out = append(out, *key)
+	}
+	return out, rows.Err()
+}
+
+// RevokeKey 撤销嵌入密钥，使用该密钥签名的全部地址立即失效
+func (s *Service) RevokeKey(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE embed_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, s.now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("撤销嵌入密钥失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ID %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+// Sign 用密钥 id 为查询参数签名，返回可直接拼接到嵌入地址的参数，
+// 便于没有后端的网站由管理员预先生成固定的嵌入地址
+func (s *Service) Sign(ctx context.Context, id int64, params url.Values) (url.Values, error) {
+	key, secret, err := s.getKey(ctx, `id = ? AND revoked_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	signed := url.Values{}
+	for name, values := range params {
+		if !unsignedParams[name] {
+			signed[name] = values
+		}
+	}
+	signed.Set("key", key.KeyID)
+	signed.Set("biz", key.BizName)
+	if _, _, err := s.buildQuery(signed, ""); err != nil {
+		return nil, err
+	}
+	signed.Set("sig", Signature(secret, signed))
+	return signed, nil
+}
+
+// Signature 计算查询参数的签名：对除 sig、q、page、format 以外的参数按 url.Values.Encode
+// 的规范形式 (键排序、URL 编码) 做 HMAC-SHA256，结果以十六进制表示
+func Signature(secret string, params url.Values) string {
+	canonical := url.Values{}
+	for name, values := range params {
+		if !unsignedParams[name] {
+			canonical[name] = values
+		}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Query 校验签名、过期时间与来源后执行嵌入查询。origin 为请求的 Origin (或由 Referer 推出的来源)，
+// 为空表示非浏览器发起的请求，此时不做来源限制
+func (s *Service) Query(ctx context.Context, params url.Values, origin string) (*Result, error) {
+	key, secret, err := s.getKey(ctx, `key_id = ? AND revoked_at IS NULL`, params.Get("key"))
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(Signature(secret, params)), []byte(params.Get("sig"))) {
+		return nil, fmt.Errorf("%w: 签名无效", ErrForbidden)
+	}
+	if params.Get("biz") != key.BizName {
+		return nil, fmt.Errorf("%w: 密钥不能用于业务组 '%s'", ErrForbidden, params.Get("biz"))
+	}
+	if raw := params.Get("exp"); raw != "" {
+		exp, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: exp 必须是 Unix 时间戳 (秒)", ErrInvalidEmbed)
+		}
+		if !s.now().Before(time.Unix(exp, 0)) {
+			return nil, fmt.Errorf("%w: 嵌入地址已过期", ErrForbidden)
+		}
+	}
+	if origin != "" && !originAllowed(key.AllowedOrigins, origin) {
+		return nil, fmt.Errorf("%w: 来源 '%s' 不在允许范围内", ErrForbidden, origin)
+	}
+
+	query, fields, err := s.buildQuery(params, params.Get("q"))
+	if err != nil {
+		return nil, err
+	}
+	page := 1
+	if raw := params.Get("page"); raw != "" {
+		if page, err = strconv.Atoi(raw); err != nil || page < 1 {
+			return nil, fmt.Errorf("%w: page 必须是正整数", ErrInvalidEmbed)
+		}
+	}
+	query["page"] = float64(page)
+
+	dataSource, exists := s.registry[key.BizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	queryResult, err := dataSource.Query(ctx, port.QueryRequest{BizName: key.BizName, Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{BizName: key.BizName, Table: query["table"].(string), Page: page, Size: int(query["size"].(float64)), Items: []map[string]interface{}{}}
+	rawItems, _ := queryResult.Data["items"].([]interface{})
+	for _, raw := range rawItems {
+		if item, ok := raw.(map[string]interface{}); ok {
+			delete(item, "__lib")
+			result.Items = append(result.Items, item)
+		}
+	}
+	// 经 gRPC 传输后数字均为 float64
+	switch n := queryResult.Data["total"].(type) {
+	case int:
+		result.Total = int64(n)
+	case int64:
+		result.Total = n
+	case float64:
+		result.Total = int64(n)
+	}
+	result.Fields = fields
+	if len(result.Fields) == 0 {
+		seen := map[string]bool{}
+		for _, item := range result.Items {
+			for name := range item {
+				if !seen[name] {
+					seen[name] = true
+					result.Fields = append(result.Fields, name)
+				}
+			}
+		}
+		sort.Strings(result.Fields)
+	}
+	return result, nil
+}
+
+// buildQuery 把嵌入参数转换为数据源的查询：
+// table 必填；fields 为逗号分隔的返回字段；filter.<字段> 为固定的精确匹配条件；
+// search 指定检索词 q 作用的字段，q 以模糊匹配追加；size 为每页条数。
+func (s *Service) buildQuery(params url.Values, q string) (map[string]interface{}, []string, error) {
+	table := strings.TrimSpace(params.Get("table"))
+	if table == "" {
+		return nil, nil, fmt.Errorf("%w: 必须提供 table", ErrInvalidEmbed)
+	}
+	query := map[string]interface{}{"table": table}
+
+	var fields []string
+	if raw := params.Get("fields"); raw != "" {
+		returned := []interface{}{}
+		for _, f := range strings.Split(raw, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				fields = append(fields, f)
+				returned = append(returned, f)
+			}
+		}
+		query["fields_to_return"] = returned
+	}
+
+	filters := []interface{}{}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if field, ok := strings.CutPrefix(name, FilterParamPrefix); ok {
+			if field == "" {
+				return nil, nil, fmt.Errorf("%w: 过滤参数缺少字段名", ErrInvalidEmbed)
+			}
+			filters = append(filters, map[string]interface{}{"field": field, "value": params.Get(name), "logic": "AND"})
+		}
+	}
+	if q = strings.TrimSpace(q); q != "" {
+		searchField := params.Get("search")
+		if searchField == "" {
+			return nil, nil, fmt.Errorf("%w: 嵌入地址未指定 search 字段，不支持检索词", ErrInvalidEmbed)
+		}
+		if len([]rune(q)) > maxSearchRunes {
+			return nil, nil, fmt.Errorf("%w: 检索词不能超过 %d 个字符", ErrInvalidEmbed, maxSearchRunes)
+		}
+		filters = append(filters, map[string]interface{}{"field": searchField, "value": q, "logic": "AND", "fuzzy": true})
+	}
+	if len(filters) > 0 {
+		query["filters"] = filters
+	}
+
+	size := min(defaultPageSize, s.opts.MaxPageSize)
+	if raw := params.Get("size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > s.opts.MaxPageSize {
+			return nil, nil, fmt.Errorf("%w: size 必须在 1 到 %d 之间", ErrInvalidEmbed, s.opts.MaxPageSize)
+		}
+		size = n
+	}
+	query["size"] = float64(size)
+	return query, fields, nil
+}
+
+const selectColumns = `SELECT id, key_id, secret, label, biz_name, allowed_origins_json, created_at, revoked_at FROM embed_keys`
+
+func (s *Service) getKey(ctx context.Context, where string, arg any) (*domain.EmbedKey, string, error) {
+	key, secret, err := scanKey(s.db.QueryRowContext(ctx, selectColumns+` WHERE `+where, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("查询嵌入密钥失败: %w", err)
+	}
+	return key, secret, nil
+}
+
+func scanKey(scanner interface{ Scan(...any) error }) (*domain.EmbedKey, string, error) {
+	var key domain.EmbedKey
+	var secret, originsJSON string
+	var revokedAt sql.NullTime
+	if err := scanner.Scan(&key.ID, &key.KeyID, &secret, &key.Label, &key.BizName, &originsJSON, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal([]byte(originsJSON), &key.AllowedOrigins); err != nil {
+		return nil, "", fmt.Errorf("解析嵌入密钥 '%s' 的来源失败: %w", key.KeyID, err)
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, secret, nil
+}
+
+// normalizeOrigin 把来源规范为小写的 scheme://host[:port]
+func normalizeOrigin(origin string) (string, error) {
+	origin = strings.TrimSpace(origin)
+	if origin == "*" {
+		return origin, nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return "", fmt.Errorf("%w: 来源 '%s' 必须形如 https://example.org", ErrInvalidEmbed, origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// OriginFromReferer 从 Referer 推出来源，供不携带 Origin 的 iframe 请求使用
+func OriginFromReferer(referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, a := range allowed {
+		if a == "*" || a == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机值失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
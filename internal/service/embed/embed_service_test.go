@@ -0,0 +1,126 @@
+// file: internal/service/embed/embed_service_test.go
+package embed
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *fixture.FakeDataSource) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	// 无论查询条件如何都返回两条固定的结果，断言只关心转发给数据源的请求
+	source := fixture.NewFakeDataSource()
+	source.QueryFunc = func(context.Context, port.QueryRequest) (*port.QueryResult, error) {
+		return &port.QueryResult{Data: map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"title": "呐喊", "year": float64(1923), "__lib": "main"},
+				map[string]interface{}{"title": "彷徨", "year": float64(1926), "__lib": "main"},
+			},
+			"total": float64(2),
+		}}, nil
+	}
+	svc, err := NewService(db, map[string]port.DataSource{"library": source}, Options{MaxPageSize: 20})
+	require.NoError(t, err)
+	return svc, source
+}
+
+func TestEmbedSignedQuery(t *testing.T) {
+	ctx := context.Background()
+	svc, source := newTestService(t)
+
+	key, secret, err := svc.CreateKey(ctx, "馆藏专题页", "library", []string{"https://Example.org/"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.org"}, key.AllowedOrigins)
+	assert.NotEmpty(t, secret)
+
+	// 外部网站的后端按同样的规则签名
+	params := url.Values{
+		"key":           {key.KeyID},
+		"biz":           {"library"},
+		"table":         {"books"},
+		"fields":        {"title,year"},
+		"search":        {"title"},
+		"filter.author": {"鲁迅"},
+		"size":          {"5"},
+	}
+	params.Set("sig", Signature(secret, params))
+	params.Set("q", "呐")
+	params.Set("page", "2")
+
+	result, err := svc.Query(ctx, params, "https://example.org")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "year"}, result.Fields)
+	assert.EqualValues(t, 2, result.Total)
+	assert.NotContains(t, result.Items[0], "__lib")
+	queries := source.Queries()
+	last := queries[len(queries)-1].Query
+	assert.Equal(t, float64(2), last["page"])
+	assert.Equal(t, float64(5), last["size"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "author", "value": "鲁迅", "logic": "AND"},
+		map[string]interface{}{"field": "title", "value": "呐", "logic": "AND", "fuzzy": true},
+	}, last["filters"], "签名的固定条件与访问者的检索词一起生效")
+
+	_, err = svc.Query(ctx, params, "")
+	assert.NoError(t, err, "非浏览器请求不做来源限制")
+	_, err = svc.Query(ctx, params, "https://evil.example")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	tampered := url.Values{}
+	for k, v := range params {
+		tampered[k] = v
+	}
+	tampered.Set("filter.author", "胡适")
+	_, err = svc.Query(ctx, tampered, "https://example.org")
+	assert.ErrorIs(t, err, ErrForbidden, "篡改签名参数后签名失效")
+
+	require.NoError(t, svc.RevokeKey(ctx, key.ID))
+	_, err = svc.Query(ctx, params, "https://example.org")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEmbedSignAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	key, _, err := svc.CreateKey(ctx, "", "library", []string{"*"})
+	require.NoError(t, err)
+	signed, err := svc.Sign(ctx, key.ID, url.Values{"table": {"books"}, "exp": {"1767229200"}, "q": {"忽略"}})
+	require.NoError(t, err)
+	assert.Equal(t, key.KeyID, signed.Get("key"))
+	assert.Equal(t, "library", signed.Get("biz"))
+	assert.Empty(t, signed.Get("q"), "未签名的参数不会写入地址")
+
+	result, err := svc.Query(ctx, signed, "https://anywhere.example")
+	require.NoError(t, err)
+	assert.Equal(t, 10, result.Size)
+	assert.Equal(t, []string{"title", "year"}, result.Fields, "未指定 fields 时按字段名排序")
+
+	signed.Set("q", "呐")
+	_, err = svc.Query(ctx, signed, "")
+	assert.ErrorIs(t, err, ErrInvalidEmbed, "未指定 search 字段时不接受检索词")
+	signed.Del("q")
+
+	now = time.Unix(1767229200, 0)
+	_, err = svc.Query(ctx, signed, "")
+	assert.ErrorIs(t, err, ErrForbidden, "过期后失效")
+
+	_, err = svc.Sign(ctx, key.ID, url.Values{"table": {"books"}, "size": {"21"}})
+	assert.ErrorIs(t, err, ErrInvalidEmbed)
+	_, _, err = svc.CreateKey(ctx, "", "library", []string{"example.org"})
+	assert.ErrorIs(t, err, ErrInvalidEmbed)
+	_, _, err = svc.CreateKey(ctx, "", "library", nil)
+	assert.ErrorIs(t, err, ErrInvalidEmbed)
+	_, _, err = svc.CreateKey(ctx, "", "archive", []string{"*"})
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
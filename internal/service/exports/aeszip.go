@@ -0,0 +1,163 @@
+// Package exports file: internal/service/exports/aeszip.go
+package exports
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// 以下常量来自 WinZip AES 加密规范 (AE-2)，7-Zip、WinZip 与 macOS 的归档工具均可解密
+const (
+	// aesMethod 是 AES 加密条目在 zip 头中声明的压缩方法
+	aesMethod uint16 = 99
+	// aesExtraID 是记录实际压缩方法与密钥长度的扩展字段
+	aesExtraID uint16 = 0x9901
+	// aesStrength256 表示 AES-256，对应 16 字节的盐
+	aesStrength256  = 3
+	aesKeyLength    = 32
+	aesSaltLength   = 16
+	aesVerifierLen  = 2
+	aesAuthCodeLen  = 10
+	aesKDFIteration = 1000
+
+	zipFlagEncrypted = 0x1
+	zipFlagUTF8      = 0x800
+)
+
+// writeAESEntry 把 src 的内容以 Deflate 压缩并用 AES-256 (AE-2) 加密后写为 zip 条目 name。
+// AE-2 不记录明文的 CRC，完整性由条目末尾的 HMAC-SHA1 校验码保证。
+// 加密条目的头部需要事先给出压缩后的大小，因此先把压缩结果写入 tmpDir 下的临时文件
+func writeAESEntry(zw *zip.Writer, name string, src io.Reader, passphrase []byte, modified time.Time, tmpDir string) error {
+	compressed, err := os.CreateTemp(tmpDir, "deflate-*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		_ = compressed.Close()
+		_ = os.Remove(compressed.Name())
+	}()
+	fw, err := flate.NewWriter(compressed, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	plainSize, err := io.Copy(fw, src)
+	if err != nil {
+		return fmt.Errorf("压缩导出数据失败: %w", err)
+	}
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("压缩导出数据失败: %w", err)
+	}
+	compressedSize, err := compressed.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := compressed.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	salt := make([]byte, aesSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("生成加密盐失败: %w", err)
+	}
+	stream, mac, verifier, err := aesEntryCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	fh := &zip.FileHeader{
+		Name:               name,
+		Method:             aesMethod,
+		Flags:              zipFlagEncrypted | zipFlagUTF8,
+		Extra:              aesExtra(zip.Deflate),
+		CompressedSize64:   uint64(aesSaltLength + aesVerifierLen + compressedSize + aesAuthCodeLen),
+		UncompressedSize64: uint64(plainSize),
+		Modified:           modified,
+	}
+	fh.ModifiedDate, fh.ModifiedTime = dosDateTime(modified)
+	w, err := zw.CreateRaw(fh)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return err
+	}
+	if _, err := w.Write(verifier); err != nil {
+		return err
+	}
+	// 校验码按密文计算
+	enc := &cipher.StreamWriter{S: stream, W: io.MultiWriter(w, mac)}
+	if _, err := io.Copy(enc, compressed); err != nil {
+		return fmt.Errorf("加密导出数据失败: %w", err)
+	}
+	_, err = w.Write(mac.Sum(nil)[:aesAuthCodeLen])
+	return err
+}
+
+// aesEntryCipher 由口令与盐派生单个条目的 AES-CTR 密钥流、HMAC 与口令校验值。
+// WinZip 的 CTR 计数器以小端序从 1 开始，与 crypto/cipher 的大端计数器不同，因此单独实现
+func aesEntryCipher(passphrase, salt []byte) (cipher.Stream, hash.Hash, []byte, error) {
+	derived := pbkdf2.Key(passphrase, salt, aesKDFIteration, 2*aesKeyLength+aesVerifierLen, sha1.New)
+	block, err := aes.NewCipher(derived[:aesKeyLength])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	mac := hmac.New(sha1.New, derived[aesKeyLength:2*aesKeyLength])
+	return &winzipCTR{block: block}, mac, derived[2*aesKeyLength:], nil
+}
+
+// aesExtra 返回 AE-2 扩展字段，actualMethod 是加密前使用的压缩方法
+func aesExtra(actualMethod uint16) []byte {
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], aesExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], 2) // AE-2
+	copy(extra[6:], "AE")
+	extra[8] = aesStrength256
+	binary.LittleEndian.PutUint16(extra[9:], actualMethod)
+	return extra
+}
+
+// dosDateTime 把时间转换为 zip 头中的 MS-DOS 日期与时间
+func dosDateTime(t time.Time) (uint16, uint16) {
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	date := uint16((t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day())
+	clock := uint16(t.Hour()<<11 | t.Minute()<<5 | t.Second()/2)
+	return date, clock
+}
+
+// winzipCTR 是 WinZip AES 使用的计数器模式: 16 字节的小端序计数器从 1 开始，每个分组加一
+type winzipCTR struct {
+	block   cipher.Block
+	counter uint64
+	buf     [aes.BlockSize]byte
+	used    int
+}
+
+func (s *winzipCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.counter == 0 || s.used == aes.BlockSize {
+			s.counter++
+			var ctr [aes.BlockSize]byte
+			binary.LittleEndian.PutUint64(ctr[:], s.counter)
+			s.block.Encrypt(s.buf[:], ctr[:])
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.buf[s.used]
+		s.used++
+	}
+}
@@ -0,0 +1,579 @@
+// Package exports file: internal/service/exports/exports_service.go
+package exports
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// JobKindExport 是记录导出任务的类型，任务目标为表名
+	JobKindExport = "export"
+
+	// FormatCSV 与 FormatJSONL 是支持的导出格式
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+
+	// MinPassphraseLength 是导出口令的最短字符数
+	MinPassphraseLength = 12
+	// minRSAKeyBits 是接收方公钥的最短长度
+	minRSAKeyBits = 2048
+	// wrappedPassphraseEntry 是公钥模式下压缩包中以接收方公钥 (RSA-OAEP-SHA256) 加密的随机口令
+	wrappedPassphraseEntry = "passphrase.rsa"
+
+	defaultRetentionHours = 72
+	defaultMaxRows        = 1000000
+	exportPageSize        = 1000
+	sweepInterval         = 10 * time.Minute
+	archiveSuffix         = ".zip"
+	tempSuffix            = ".tmp"
+)
+
+var (
+	// ErrInvalidRequest 表示导出请求的参数不合法
+	ErrInvalidRequest = errors.New("无效的导出请求")
+	// ErrNotFound 表示导出任务不存在，或导出文件已过期被清理
+	ErrNotFound = errors.New("导出文件不存在或已过期")
+	// ErrNotReady 表示导出任务尚未成功完成
+	ErrNotReady = errors.New("导出任务尚未完成")
+)
+
+// Options 定义记录导出的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RetentionHours 是导出文件自生成起的保留时间，过期的文件会被删除，默认为 72 小时
+	RetentionHours int `mapstructure:"retention_hours"`
+	// MaxRows 是单次导出的记录数上限，默认为 1000000
+	MaxRows int64 `mapstructure:"max_rows"`
+}
+
+// Request 描述一次导出。Passphrase 与 RecipientPublicKey 至多设置一个，都为空时压缩包不加密
+type Request struct {
+	BizName   string        `json:"biz_name"`
+	TableName string        `json:"table_name"`
+	Filters   []interface{} `json:"filters,omitempty"`
+	// Fields 是导出的字段及其顺序，为空时导出表中全部可返回的字段
+	Fields []string `json:"fields,omitempty"`
+	// Format 为 csv (默认) 或 jsonl
+	Format string `json:"format,omitempty"`
+	// Passphrase 是压缩包的口令，压缩包中的条目以 AES-256 加密
+	Passphrase string `json:"passphrase,omitempty"`
+	// RecipientPublicKey 是接收方的 PEM 格式 RSA 公钥。设置时以随机口令加密，
+	// 口令以该公钥加密后存入压缩包的 passphrase.rsa，只有持有私钥的接收方能够解开
+	RecipientPublicKey string `json:"recipient_public_key,omitempty"`
+}
+
+// manifest 是压缩包中 manifest.json 的内容
+type manifest struct {
+	BizName     string        `json:"biz_name"`
+	TableName   string        `json:"table_name"`
+	Filters     []interface{} `json:"filters,omitempty"`
+	Fields      []string      `json:"fields"`
+	Format      string        `json:"format"`
+	Rows        int64         `json:"rows"`
+	SHA256      string        `json:"sha256"`
+	Encryption  string        `json:"encryption"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	JobID       int64         `json:"job_id"`
+}
+
+// Service 以后台任务的形式把一张表中符合条件的记录导出为 zip 压缩包，供管理员下载后交给研究者。
+// 含敏感记录的导出可以用口令或接收方公钥加密，落在共享存储上的文件不再是明文。
+// 口令只保存在任务执行期间的内存中，不写入数据库或日志
+type Service struct {
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+	jobs     *jobs.Service
+	dir      string
+	opts     Options
+	now      func() time.Time
+}
+
+// NewService 创建一个新的记录导出服务实例，dir 是保存导出文件的目录。
+// 上次运行中被中断的任务留下的临时文件会被删除
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource, jobService *jobs.Service, dir string, opts Options) (*Service, error) {
+	if config == nil || jobService == nil {
+		return nil, errors.New("exports.Service 需要有效的配置服务与后台任务服务")
+	}
+	if dir == "" {
+		return nil, errors.New("exports.Service 需要有效的导出目录")
+	}
+	if opts.RetentionHours < 0 || opts.MaxRows < 0 {
+		return nil, errors.New("retention_hours 与 max_rows 不能为负数")
+	}
+	if opts.RetentionHours == 0 {
+		opts.RetentionHours = defaultRetentionHours
+	}
+	if opts.MaxRows == 0 {
+		opts.MaxRows = defaultMaxRows
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), tempSuffix) {
+				_ = os.Remove(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	return &Service{config: config, registry: registry, jobs: jobService, dir: dir, opts: opts, now: time.Now}, nil
+}
+
+// Start 校验导出请求并启动后台任务。同一张表上已有导出任务在运行时返回 jobs.ErrJobConflict
+func (s *Service) Start(ctx context.Context, req Request, userID int64) (*domain.BackgroundJob, error) {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, req.BizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableConfig, exists := bizConfig.Tables[req.TableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	dataSource, exists := s.registry[req.BizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	fields, err := exportFields(tableConfig, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	switch req.Format {
+	case "":
+		req.Format = FormatCSV
+	case FormatCSV, FormatJSONL:
+	default:
+		return nil, fmt.Errorf("%w: format 必须为 %s 或 %s", ErrInvalidRequest, FormatCSV, FormatJSONL)
+	}
+	key, err := newArchiveKey(req.Passphrase, req.RecipientPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	req.Fields = fields
+	// 口令已转入 key，请求本身随闭包保留到任务结束，不再携带口令
+	req.Passphrase, req.RecipientPublicKey = "", ""
+
+	return s.jobs.Start(ctx, JobKindExport, req.BizName, req.TableName, userID, func(ctx context.Context, progress *jobs.Progress) error {
+		return s.run(ctx, dataSource, req, key, progress)
+	})
+}
+
+// exportFields 返回导出的字段。未指定时取全部可返回的字段并按名称排序
+func exportFields(tableConfig *domain.TableConfig, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		var fields []string
+		for name, setting := range tableConfig.Fields {
+			if setting.IsReturnable {
+				fields = append(fields, name)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%w: 表 '%s' 没有可返回的字段", ErrInvalidRequest, tableConfig.TableName)
+		}
+		sort.Strings(fields)
+		return fields, nil
+	}
+	seen := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		setting, exists := tableConfig.Fields[name]
+		if !exists || !setting.IsReturnable {
+			return nil, fmt.Errorf("%w: 字段 '%s' 不存在或不可返回", ErrInvalidRequest, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: 字段 '%s' 重复", ErrInvalidRequest, name)
+		}
+		seen[name] = true
+	}
+	return requested, nil
+}
+
+// archiveKey 是压缩包的加密方式。passphrase 为空表示不加密
+type archiveKey struct {
+	passphrase []byte
+	// wrapped 是以接收方公钥加密的 passphrase，仅公钥模式下设置
+	wrapped []byte
+}
+
+func (k *archiveKey) mode() string {
+	switch {
+	case len(k.passphrase) == 0:
+		return "none"
+	case k.wrapped != nil:
+		return "aes-256+rsa-oaep-sha256"
+	default:
+		return "aes-256"
+	}
+}
+
+func newArchiveKey(passphrase, publicKeyPEM string) (*archiveKey, error) {
+	switch {
+	case passphrase != "" && publicKeyPEM != "":
+		return nil, fmt.Errorf("%w: passphrase 与 recipient_public_key 只能设置一个", ErrInvalidRequest)
+	case passphrase != "":
+		if utf8.RuneCountInString(passphrase) < MinPassphraseLength {
+			return nil, fmt.Errorf("%w: 口令至少需要 %d 个字符", ErrInvalidRequest, MinPassphraseLength)
+		}
+		return &archiveKey{passphrase: []byte(passphrase)}, nil
+	case publicKeyPEM != "":
+		publicKey, err := parseRecipientKey(publicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("生成随机口令失败: %w", err)
+		}
+		// 口令以可打印字符给出，便于接收方解密后直接输入解压工具
+		generated := []byte(base64.RawURLEncoding.EncodeToString(raw))
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, generated, nil)
+		if err != nil {
+			return nil, fmt.Errorf("以接收方公钥加密口令失败: %w", err)
+		}
+		return &archiveKey{passphrase: generated, wrapped: wrapped}, nil
+	default:
+		return &archiveKey{}, nil
+	}
+}
+
+// parseRecipientKey 解析 PEM 格式 (PUBLIC KEY 或 RSA PUBLIC KEY) 的 RSA 公钥
+func parseRecipientKey(publicKeyPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("%w: recipient_public_key 不是有效的 PEM", ErrInvalidRequest)
+	}
+	var publicKey *rsa.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 无法解析 recipient_public_key: %v", ErrInvalidRequest, err)
+		}
+		key, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: recipient_public_key 必须是 RSA 公钥", ErrInvalidRequest)
+		}
+		publicKey = key
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 无法解析 recipient_public_key: %v", ErrInvalidRequest, err)
+		}
+		publicKey = key
+	default:
+		return nil, fmt.Errorf("%w: 不支持的 PEM 类型 '%s'", ErrInvalidRequest, block.Type)
+	}
+	if publicKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("%w: RSA 公钥至少需要 %d 位", ErrInvalidRequest, minRSAKeyBits)
+	}
+	return publicKey, nil
+}
+
+func (s *Service) archivePath(jobID int64) string {
+	return filepath.Join(s.dir, strconv.FormatInt(jobID, 10)+archiveSuffix)
+}
+
+// run 是导出任务的执行体: 分页读取记录写入临时文件，再打包为 <任务 ID>.zip
+func (s *Service) run(ctx context.Context, dataSource port.DataSource, req Request, key *archiveKey, progress *jobs.Progress) error {
+	jobID := progress.ID()
+	data, err := os.CreateTemp(s.dir, fmt.Sprintf("%d-data-*%s", jobID, tempSuffix))
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		_ = data.Close()
+		_ = os.Remove(data.Name())
+	}()
+
+	progress.SetMessage("正在读取记录")
+	digest := sha256.New()
+	rows, err := s.writeRecords(ctx, dataSource, req, io.MultiWriter(data, digest), progress)
+	if err != nil {
+		return err
+	}
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	progress.SetMessage("正在打包")
+	m := manifest{
+		BizName:     req.BizName,
+		TableName:   req.TableName,
+		Filters:     req.Filters,
+		Fields:      req.Fields,
+		Format:      req.Format,
+		Rows:        rows,
+		SHA256:      hex.EncodeToString(digest.Sum(nil)),
+		Encryption:  key.mode(),
+		GeneratedAt: s.now().UTC(),
+		JobID:       jobID,
+	}
+	if err := s.writeArchive(data, m, key); err != nil {
+		return err
+	}
+	progress.SetMessage(fmt.Sprintf("已导出 %d 条记录", rows))
+	return nil
+}
+
+// writeRecords 分页读取记录并按格式写入 w，返回写入的记录数
+func (s *Service) writeRecords(ctx context.Context, dataSource port.DataSource, req Request, w io.Writer, progress *jobs.Progress) (int64, error) {
+	buffered := bufio.NewWriter(w)
+	var csvWriter *csv.Writer
+	if req.Format == FormatCSV {
+		csvWriter = csv.NewWriter(buffered)
+		if err := csvWriter.Write(req.Fields); err != nil {
+			return 0, err
+		}
+	}
+	encoder := json.NewEncoder(buffered)
+	fieldsToReturn := make([]interface{}, len(req.Fields))
+	for i, f := range req.Fields {
+		fieldsToReturn[i] = f
+	}
+
+	var written int64
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		query := map[string]interface{}{
+			"table":            req.TableName,
+			"fields_to_return": fieldsToReturn,
+			"page":             float64(page),
+			"size":             float64(exportPageSize),
+			// 遗漏失败库中的记录会使导出不完整，因此要求全部库查询成功
+			port.QueryStrictKey: true,
+		}
+		if len(req.Filters) > 0 {
+			query["filters"] = req.Filters
+		}
+		res, err := dataSource.Query(ctx, port.QueryRequest{BizName: req.BizName, Query: query})
+		if err != nil {
+			return 0, err
+		}
+		if truncated, _ := res.Data[port.ResultTruncatedKey].(bool); truncated {
+			return 0, errors.New("数据源截断了导出的分页结果")
+		}
+		total := toInt64(res.Data["total"])
+		if page == 1 {
+			if total > s.opts.MaxRows {
+				return 0, fmt.Errorf("符合条件的记录有 %d 条，超出单次导出上限 %d 条，请缩小过滤条件", total, s.opts.MaxRows)
+			}
+			progress.SetTotal(total)
+		}
+		items, _ := res.Data["items"].([]interface{})
+		for _, item := range items {
+			record, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if csvWriter != nil {
+				row := make([]string, len(req.Fields))
+				for i, f := range req.Fields {
+					row[i] = stringify(record[f])
+				}
+				err = csvWriter.Write(row)
+			} else {
+				out := make(map[string]interface{}, len(req.Fields))
+				for _, f := range req.Fields {
+					out[f] = record[f]
+				}
+				err = encoder.Encode(out)
+			}
+			if err != nil {
+				return 0, fmt.Errorf("写入导出数据失败: %w", err)
+			}
+		}
+		written += int64(len(items))
+		progress.Add(int64(len(items)))
+		if len(items) < exportPageSize || written >= total {
+			break
+		}
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return 0, fmt.Errorf("写入导出数据失败: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("写入导出数据失败: %w", err)
+	}
+	return written, nil
+}
+
+// writeArchive 把导出数据与 manifest.json 打包。先写入临时文件，完成后再改名，下载方不会读到未写完的压缩包
+func (s *Service) writeArchive(data io.Reader, m manifest, key *archiveKey) (err error) {
+	out, err := os.CreateTemp(s.dir, fmt.Sprintf("%d-archive-*%s", m.JobID, tempSuffix))
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		_ = out.Close()
+		if err != nil {
+			_ = os.Remove(out.Name())
+		}
+	}()
+
+	manifestJSON, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(out)
+	entries := []struct {
+		name string
+		r    io.Reader
+	}{
+		{m.TableName + "." + m.Format, data},
+		// 过滤条件可能包含敏感的检索词，清单与数据一同加密
+		{"manifest.json", strings.NewReader(string(manifestJSON))},
+	}
+	for _, e := range entries {
+		if len(key.passphrase) > 0 {
+			err = writeAESEntry(zw, e.name, e.r, key.passphrase, m.GeneratedAt, s.dir)
+		} else {
+			err = writeZipEntry(zw, e.name, e.r, m.GeneratedAt)
+		}
+		if err != nil {
+			return fmt.Errorf("写入压缩包条目 '%s' 失败: %w", e.name, err)
+		}
+	}
+	if key.wrapped != nil {
+		if err = writeZipEntry(zw, wrappedPassphraseEntry, strings.NewReader(string(key.wrapped)), m.GeneratedAt); err != nil {
+			return fmt.Errorf("写入压缩包条目 '%s' 失败: %w", wrappedPassphraseEntry, err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return fmt.Errorf("写入压缩包失败: %w", err)
+	}
+	if err = out.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), s.archivePath(m.JobID))
+}
+
+func writeZipEntry(zw *zip.Writer, name string, r io.Reader, modified time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// Archive 返回已完成的导出任务的压缩包路径与建议的下载文件名
+func (s *Service) Archive(ctx context.Context, jobID int64) (string, string, error) {
+	job, err := s.jobs.Get(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+	if job.Kind != JobKindExport {
+		return "", "", ErrNotFound
+	}
+	if job.Status != domain.JobStatusSucceeded {
+		return "", "", fmt.Errorf("%w: 任务 #%d 的状态为 %s", ErrNotReady, jobID, job.Status)
+	}
+	path := s.archivePath(jobID)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+	return path, fmt.Sprintf("%s_%s_%d%s", job.BizName, job.Target, jobID, archiveSuffix), nil
+}
+
+// Run 定期删除超过保留时间的导出文件，直到 ctx 取消
+func (s *Service) Run(ctx context.Context) {
+	s.sweep()
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+func (s *Service) sweep() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		slog.Warn("[Exports] 读取导出目录失败", "error", err)
+		return
+	}
+	cutoff := s.now().Add(-time.Duration(s.opts.RetentionHours) * time.Hour)
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), archiveSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, e.Name())); err != nil {
+			slog.Warn("[Exports] 删除过期的导出文件失败", "file", e.Name(), "error", err)
+		}
+	}
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// stringify 把记录的取值转换为 CSV 单元格，null 为空串，数字不使用科学计数法
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case []byte:
+		return string(val)
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
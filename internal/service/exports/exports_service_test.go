@@ -0,0 +1,306 @@
+// file: internal/service/exports/exports_service_test.go
+package exports
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv struct {
+	svc  *Service
+	jobs *jobs.Service
+	dir  string
+}
+
+func newTestEnv(t *testing.T, opts Options) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('clinic', TRUE, 'patients')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('clinic', 'patients')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable, data_type) VALUES
+		('clinic', 'patients', 'id', TRUE, TRUE, 'INTEGER'), ('clinic', 'patients', 'name', TRUE, TRUE, 'TEXT'),
+		('clinic', 'patients', 'ward', TRUE, TRUE, 'TEXT'), ('clinic', 'patients', 'secret', FALSE, FALSE, 'TEXT')`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "clinic"), 0o755))
+	libDB, err := sql.Open("sqlite", filepath.Join(root, "clinic", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE patients (id INTEGER PRIMARY KEY, name TEXT, ward TEXT, secret TEXT)`)
+	require.NoError(t, err)
+	// 超过一页，覆盖分页读取
+	for i := 1; i <= exportPageSize+5; i++ {
+		ward := "east"
+		if i%2 == 0 {
+			ward = "west"
+		}
+		_, err = libDB.Exec(`INSERT INTO patients (id, name, ward, secret) VALUES (?, ?, ?, 'x')`, i, "病人,"+strings.Repeat("甲", i%3), ward)
+		require.NoError(t, err)
+	}
+	require.NoError(t, libDB.Close())
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "clinic"))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	jobService, err := jobs.NewService(sysDB)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	dir := filepath.Join(t.TempDir(), "exports")
+	svc, err := NewService(config, map[string]port.DataSource{"clinic": manager}, jobService, dir, opts)
+	require.NoError(t, err)
+	return &testEnv{svc: svc, jobs: jobService, dir: dir}
+}
+
+// export 启动导出任务并等待其结束，返回任务的最终状态
+func (e *testEnv) export(t *testing.T, req Request) *domain.BackgroundJob {
+	t.Helper()
+	job, err := e.svc.Start(context.Background(), req, 1)
+	require.NoError(t, err)
+	var current *domain.BackgroundJob
+	require.Eventually(t, func() bool {
+		current, err = e.jobs.Get(context.Background(), job.ID)
+		require.NoError(t, err)
+		return current.Finished()
+	}, 10*time.Second, 10*time.Millisecond)
+	return current
+}
+
+func (e *testEnv) openArchive(t *testing.T, jobID int64) *zip.ReadCloser {
+	t.Helper()
+	path, name, err := e.svc.Archive(context.Background(), jobID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(name, "clinic_patients_"))
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = zr.Close() })
+	return zr
+}
+
+func entry(t *testing.T, zr *zip.ReadCloser, name string) *zip.File {
+	t.Helper()
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("压缩包中没有条目 %s", name)
+	return nil
+}
+
+// decryptAESEntry 按 WinZip AE-2 规范独立解密条目，用于验证写出的格式
+func decryptAESEntry(t *testing.T, f *zip.File, passphrase []byte) ([]byte, error) {
+	t.Helper()
+	require.Equal(t, aesMethod, f.Method)
+	require.Len(t, f.Extra, 11)
+	assert.Equal(t, aesExtraID, binary.LittleEndian.Uint16(f.Extra))
+	assert.Equal(t, "AE", string(f.Extra[6:8]))
+	assert.Equal(t, zip.Deflate, binary.LittleEndian.Uint16(f.Extra[9:]))
+
+	r, err := f.OpenRaw()
+	require.NoError(t, err)
+	raw, err := io.ReadAll(r)
+	require.NoError(t, err)
+	salt, verifier := raw[:aesSaltLength], raw[aesSaltLength:aesSaltLength+aesVerifierLen]
+	body, authCode := raw[aesSaltLength+aesVerifierLen:len(raw)-aesAuthCodeLen], raw[len(raw)-aesAuthCodeLen:]
+
+	stream, mac, expected, err := aesEntryCipher(passphrase, salt)
+	require.NoError(t, err)
+	if !bytes.Equal(verifier, expected) {
+		return nil, errors.New("口令错误")
+	}
+	mac.Write(body)
+	if !hmac.Equal(authCode, mac.Sum(nil)[:aesAuthCodeLen]) {
+		return nil, errors.New("校验码不一致")
+	}
+	compressed := make([]byte, len(body))
+	stream.XORKeyStream(compressed, body)
+	return io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+}
+
+func TestWinzipCTR_LittleEndianCounter(t *testing.T) {
+	stream, _, _, err := aesEntryCipher([]byte("passphrase-123"), make([]byte, aesSaltLength))
+	require.NoError(t, err)
+	// 分多次加密与一次加密的密钥流应一致
+	whole := make([]byte, 40)
+	stream.XORKeyStream(whole, make([]byte, 40))
+	again, _, _, _ := aesEntryCipher([]byte("passphrase-123"), make([]byte, aesSaltLength))
+	parts := make([]byte, 40)
+	again.XORKeyStream(parts[:7], make([]byte, 7))
+	again.XORKeyStream(parts[7:], make([]byte, 33))
+	assert.Equal(t, whole, parts)
+
+	block := again.(*winzipCTR).block
+	var ctr, first [16]byte
+	ctr[0] = 1
+	block.Encrypt(first[:], ctr[:])
+	assert.Equal(t, first[:], whole[:16], "首个分组使用小端序计数器 1")
+}
+
+func TestExport_PlainCSV(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true})
+	job := env.export(t, Request{BizName: "clinic", TableName: "patients", Fields: []string{"id", "name"},
+		Filters: []interface{}{map[string]interface{}{"field": "ward", "value": "east"}}})
+	require.Equal(t, domain.JobStatusSucceeded, job.Status, job.Error)
+	assert.EqualValues(t, 503, job.ProgressDone)
+	assert.Equal(t, "已导出 503 条记录", job.Message)
+
+	zr := env.openArchive(t, job.ID)
+	rc, err := entry(t, zr, "patients.csv").Open()
+	require.NoError(t, err)
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 504)
+	assert.Equal(t, "id,name", lines[0])
+	assert.Equal(t, `1,"病人,甲"`, lines[1])
+
+	rc, err = entry(t, zr, "manifest.json").Open()
+	require.NoError(t, err)
+	var m manifest
+	require.NoError(t, json.NewDecoder(rc).Decode(&m))
+	assert.EqualValues(t, 503, m.Rows)
+	assert.Equal(t, "none", m.Encryption)
+	sum := sha256.Sum256(body)
+	assert.Equal(t, m.SHA256, hex.EncodeToString(sum[:]))
+}
+
+func TestExport_PassphraseEncryptedJSONL(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true})
+	passphrase := "correct horse battery"
+	job := env.export(t, Request{BizName: "clinic", TableName: "patients", Format: FormatJSONL, Passphrase: passphrase})
+	require.Equal(t, domain.JobStatusSucceeded, job.Status, job.Error)
+
+	zr := env.openArchive(t, job.ID)
+	data := entry(t, zr, "patients.jsonl")
+	assert.NotZero(t, data.Flags&zipFlagEncrypted)
+	_, err := decryptAESEntry(t, data, []byte("wrong passphrase!"))
+	assert.Error(t, err)
+
+	body, err := decryptAESEntry(t, data, []byte(passphrase))
+	require.NoError(t, err)
+	assert.EqualValues(t, len(body), data.UncompressedSize64)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, exportPageSize+5)
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "病人,甲", "ward": "east"}, first, "不可返回的字段不导出")
+
+	manifestJSON, err := decryptAESEntry(t, entry(t, zr, "manifest.json"), []byte(passphrase))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifestJSON), `"encryption": "aes-256"`)
+	assert.NotContains(t, string(manifestJSON), passphrase)
+}
+
+func TestExport_RecipientPublicKey(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true})
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	job := env.export(t, Request{BizName: "clinic", TableName: "patients", RecipientPublicKey: publicPEM})
+	require.Equal(t, domain.JobStatusSucceeded, job.Status, job.Error)
+
+	zr := env.openArchive(t, job.ID)
+	rc, err := entry(t, zr, wrappedPassphraseEntry).Open()
+	require.NoError(t, err)
+	wrapped, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	passphrase, err := rsa.DecryptOAEP(sha256.New(), nil, privateKey, wrapped, nil)
+	require.NoError(t, err)
+
+	body, err := decryptAESEntry(t, entry(t, zr, "patients.csv"), passphrase)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(body), "id,name,ward\n"))
+}
+
+func TestStart_RejectsInvalidRequests(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true})
+	ctx := context.Background()
+	base := Request{BizName: "clinic", TableName: "patients"}
+
+	_, err := env.svc.Start(ctx, Request{BizName: "missing", TableName: "patients"}, 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	_, err = env.svc.Start(ctx, Request{BizName: "clinic", TableName: "missing"}, 1)
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+
+	for name, mutate := range map[string]func(r *Request){
+		"不可返回的字段": func(r *Request) { r.Fields = []string{"secret"} },
+		"重复的字段":   func(r *Request) { r.Fields = []string{"id", "id"} },
+		"未知格式":    func(r *Request) { r.Format = "xlsx" },
+		"口令过短":    func(r *Request) { r.Passphrase = "short" },
+		"口令与公钥并用": func(r *Request) { r.Passphrase, r.RecipientPublicKey = "long enough passphrase", "x" },
+		"无效公钥":    func(r *Request) { r.RecipientPublicKey = "not a pem" },
+	} {
+		req := base
+		mutate(&req)
+		_, err := env.svc.Start(ctx, req, 1)
+		assert.ErrorIs(t, err, ErrInvalidRequest, name)
+	}
+}
+
+func TestExport_MaxRows(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true, MaxRows: 10})
+	job := env.export(t, Request{BizName: "clinic", TableName: "patients"})
+	assert.Equal(t, domain.JobStatusFailed, job.Status)
+	assert.Contains(t, job.Error, "超出单次导出上限")
+
+	_, _, err := env.svc.Archive(context.Background(), job.ID)
+	assert.ErrorIs(t, err, ErrNotReady)
+	entries, err := os.ReadDir(env.dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "失败的任务不应留下文件")
+}
+
+func TestArchive_ExpiresAfterRetention(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true, RetentionHours: 1})
+	job := env.export(t, Request{BizName: "clinic", TableName: "patients"})
+	require.Equal(t, domain.JobStatusSucceeded, job.Status, job.Error)
+
+	_, _, err := env.svc.Archive(context.Background(), job.ID+100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	env.svc.sweep()
+	_, _, err = env.svc.Archive(context.Background(), job.ID)
+	require.NoError(t, err, "未过期的文件不删除")
+
+	env.svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	env.svc.sweep()
+	_, _, err = env.svc.Archive(context.Background(), job.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,169 @@
+// Package fieldguard file: internal/service/fieldguard/fieldguard_service.go
+package fieldguard
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 网关侧字段白名单的执行模式
+const (
+	// ModeStrict 删除未被授权返回的字段并记录日志 (默认)
+	ModeStrict = "strict"
+	// ModeLenient 只记录日志，结果原样返回，便于在切换到 strict 前排查插件
+	ModeLenient = "lenient"
+	// ModeOff 不做检查
+	ModeOff = "off"
+)
+
+// logInterval 是同一张表重复告警的最小间隔，避免行为异常的插件刷屏
+const logInterval = time.Minute
+
+// Options 定义网关侧字段白名单的配置
+type Options struct {
+	// Mode 为 strict、lenient 或 off，默认为 strict
+	Mode string `mapstructure:"mode"`
+}
+
+// Service 在网关侧按管理配置再次检查数据源返回的字段，作为纵深防御：
+// 即使插件有缺陷或版本过旧、返回了未标记为可返回的字段，这些字段也不会到达客户端。
+// 以 "_" 开头的键 (如 __lib、_score) 是数据源附加的元信息，不受检查；
+// 没有自己的查询配置的业务组 (如虚拟业务组) 由底层业务组负责，不做检查
+type Service struct {
+	config port.QueryAdminConfigService
+	mode   string
+	now    func() time.Time
+
+	mu         sync.Mutex
+	lastLogged map[string]time.Time
+}
+
+// NewService 创建一个新的字段白名单服务实例
+func NewService(config port.QueryAdminConfigService, opts Options) (*Service, error) {
+	if config == nil {
+		return nil, fmt.Errorf("fieldguard.Service 需要一个有效的配置服务")
+	}
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	switch mode {
+	case "":
+		mode = ModeStrict
+	case ModeStrict, ModeLenient, ModeOff:
+	default:
+		return nil, fmt.Errorf("未知的字段白名单模式 '%s'，可选 strict、lenient 或 off", opts.Mode)
+	}
+	return &Service{config: config, mode: mode, now: time.Now, lastLogged: make(map[string]time.Time)}, nil
+}
+
+// Mode 返回当前的执行模式
+func (s *Service) Mode() string {
+	return s.mode
+}
+
+// Filter 检查查询结果中 items 的每一行与按主键读取的 record，返回过滤后的结果副本；
+// 原结果可能被查询缓存共享，不会被修改
+func (s *Service) Filter(ctx context.Context, bizName, tableName string, result *port.QueryResult) (*port.QueryResult, error) {
+	if s.mode == ModeOff || result == nil || result.Data == nil {
+		return result, nil
+	}
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return result, nil
+	}
+	if tableName == "" {
+		tableName = bizConfig.DefaultQueryTable
+	}
+	allowed := make(map[string]bool)
+	if table := bizConfig.Tables[tableName]; table != nil {
+		for name, field := range table.Fields {
+			if field.IsReturnable {
+				allowed[name] = true
+			}
+		}
+	}
+
+	leaked := make(map[string]bool)
+	items, hasItems := result.Data["items"].([]interface{})
+	record, hasRecord := result.Data["record"].(map[string]interface{})
+	for _, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			collectLeaks(row, allowed, leaked)
+		}
+	}
+	if hasRecord {
+		collectLeaks(record, allowed, leaked)
+	}
+	if len(leaked) == 0 {
+		return result, nil
+	}
+	s.report(bizName, tableName, result.Source, leaked)
+	if s.mode == ModeLenient {
+		return result, nil
+	}
+
+	data := make(map[string]interface{}, len(result.Data))
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	if hasItems {
+		filtered := make([]interface{}, len(items))
+		for i, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				filtered[i] = stripFields(row, leaked)
+			} else {
+				filtered[i] = item
+			}
+		}
+		data["items"] = filtered
+	}
+	if hasRecord {
+		data["record"] = stripFields(record, leaked)
+	}
+	return &port.QueryResult{Data: data, Source: result.Source}, nil
+}
+
+func collectLeaks(row map[string]interface{}, allowed, leaked map[string]bool) {
+	for key := range row {
+		if !allowed[key] && !strings.HasPrefix(key, "_") {
+			leaked[key] = true
+		}
+	}
+}
+
+func stripFields(row map[string]interface{}, leaked map[string]bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		if !leaked[k] {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// report 记录越权返回的字段，同一张表每分钟最多告警一次
+func (s *Service) report(bizName, tableName, source string, leaked map[string]bool) {
+	key := bizName + "/" + tableName
+	now := s.now()
+	s.mu.Lock()
+	if last, ok := s.lastLogged[key]; ok && now.Sub(last) < logInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastLogged[key] = now
+	s.mu.Unlock()
+
+	fields := make([]string, 0, len(leaked))
+	for name := range leaked {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	slog.Warn("数据源返回了未授权返回的字段", "biz", bizName, "table", tableName, "source", source, "fields", fields, "mode", s.mode)
+}
@@ -0,0 +1,85 @@
+// file: internal/service/fieldguard/fieldguard_service_test.go
+package fieldguard
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfig(t *testing.T) *admin_config.AdminConfigServiceImpl {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	table := "books"
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{DefaultQueryTable: &table}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "id", IsReturnable: true},
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "price", IsSearchable: true},
+	}))
+	return cfg
+}
+
+func pluginResult() *port.QueryResult {
+	return &port.QueryResult{Source: "plugin", Data: map[string]interface{}{
+		"total": float64(1),
+		"items": []interface{}{
+			map[string]interface{}{"id": float64(1), "title": "呐喊", "price": float64(9), "secret": "x", "__lib": "main", "_score": 0.5},
+		},
+	}}
+}
+
+func TestFilterStrict(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newConfig(t), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ModeStrict, svc.Mode())
+
+	original := pluginResult()
+	filtered, err := svc.Filter(ctx, "library", "", original)
+	require.NoError(t, err)
+	row := filtered.Data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "title": "呐喊", "__lib": "main", "_score": 0.5}, row,
+		"按默认表过滤，保留元信息字段")
+	assert.Equal(t, float64(1), filtered.Data["total"])
+	assert.Contains(t, original.Data["items"].([]interface{})[0], "secret", "不修改原结果")
+
+	record := &port.QueryResult{Data: map[string]interface{}{"record": map[string]interface{}{"id": float64(1), "price": float64(9)}}}
+	filtered, err = svc.Filter(ctx, "library", "books", record)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": float64(1)}, filtered.Data["record"])
+
+	clean := &port.QueryResult{Data: map[string]interface{}{"items": []interface{}{map[string]interface{}{"title": "彷徨"}}}}
+	filtered, err = svc.Filter(ctx, "library", "books", clean)
+	require.NoError(t, err)
+	assert.Same(t, clean, filtered, "没有越权字段时原样返回")
+
+	filtered, err = svc.Filter(ctx, "virtual", "books", pluginResult())
+	require.NoError(t, err)
+	assert.Contains(t, filtered.Data["items"].([]interface{})[0], "secret", "未配置的业务组不做检查")
+}
+
+func TestFilterModes(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig(t)
+	for _, mode := range []string{ModeLenient, ModeOff} {
+		svc, err := NewService(cfg, Options{Mode: mode})
+		require.NoError(t, err)
+		filtered, err := svc.Filter(ctx, "library", "books", pluginResult())
+		require.NoError(t, err)
+		assert.Contains(t, filtered.Data["items"].([]interface{})[0], "secret", mode)
+	}
+	_, err := NewService(cfg, Options{Mode: "paranoid"})
+	assert.Error(t, err)
+}
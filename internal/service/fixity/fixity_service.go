@@ -0,0 +1,354 @@
+// Package fixity file: internal/service/fixity/fixity_service.go
+package fixity
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIntervalMinutes = 60
+	defaultEventListLimit  = 100
+	maxEventListLimit      = 1000
+	webhookTimeout         = 10 * time.Second
+)
+
+// Options 定义库文件校验的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalMinutes 是两次校验的间隔，默认为 60 分钟；网关启动时立即校验一次
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// WebhookURL 不为空时把 mismatch 与 missing 事件以 JSON POST 到该地址
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// Service 定期计算 instance 目录下每个业务组库文件的 SHA-256 并与登记的校验值比对。
+// 网关经由数据源执行的写操作由 Wrap 返回的装饰器计数，校验值变化时若期间有过写操作，
+// 视为预期内的变更 (updated)；否则视为网关之外的修改 (mismatch)，与文件丢失 (missing) 一起记录并告警。
+// 写操作的效果可能稍后才由 SQLite 检查点写回库文件，因此写操作计数保留到观察到变化为止
+type Service struct {
+	db          *sql.DB
+	instanceDir string
+	opts        Options
+	now         func() time.Time
+	client      *http.Client
+
+	// runMu 保证同一时间只有一次校验
+	runMu sync.Mutex
+
+	mu sync.Mutex
+	// writes 是各业务组经由网关的写操作次数，explained 是已用于解释文件变化的次数
+	writes    map[string]uint64
+	explained map[string]uint64
+}
+
+// NewService 创建一个新的库文件校验服务实例
+func NewService(db *sql.DB, instanceDir string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("fixity.Service 需要一个有效的数据库连接")
+	}
+	if instanceDir == "" {
+		return nil, errors.New("fixity.Service 需要有效的 instance 目录")
+	}
+	if opts.IntervalMinutes < 0 {
+		return nil, fmt.Errorf("interval_minutes 不能为负数: %d", opts.IntervalMinutes)
+	}
+	if opts.IntervalMinutes == 0 {
+		opts.IntervalMinutes = defaultIntervalMinutes
+	}
+	return &Service{
+		db:          db,
+		instanceDir: instanceDir,
+		opts:        opts,
+		now:         time.Now,
+		client:      &http.Client{Timeout: webhookTimeout},
+		writes:      make(map[string]uint64),
+		explained:   make(map[string]uint64),
+	}, nil
+}
+
+// Wrap 返回一个记录写操作的 DataSource 装饰器，可作为插件管理器的装饰器使用
+func (s *Service) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	return &decorator{DataSource: inner, fixity: s}
+}
+
+type decorator struct {
+	port.DataSource
+	fixity *Service
+}
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *decorator) Unwrap() port.DataSource {
+	return d.DataSource
+}
+
+// Mutate 透传写操作，成功后记录一次该业务组的写入
+func (d *decorator) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	result, err := d.DataSource.Mutate(ctx, req)
+	if err == nil {
+		d.fixity.NoteWrite(req.BizName)
+	}
+	return result, err
+}
+
+// AdminMutate 转发管理操作，同步、压缩与替换同样会修改库文件
+func (d *decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	result, err := port.AdminMutate(ctx, d.DataSource, req)
+	if err == nil {
+		d.fixity.NoteWrite(req.BizName)
+	}
+	return result, err
+}
+
+// NoteWrite 记录一次由网关发起的库文件修改，如数据源写操作或快照恢复
+func (s *Service) NoteWrite(bizName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes[bizName]++
+}
+
+// Run 在启动时与之后每隔 IntervalMinutes 校验一次，ctx 结束时返回
+func (s *Service) Run(ctx context.Context) {
+	if _, err := s.Verify(ctx); err != nil {
+		slog.Error("[Fixity] 库文件校验失败", "error", err)
+	}
+	ticker := time.NewTicker(time.Duration(s.opts.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Verify(ctx); err != nil {
+				slog.Error("[Fixity] 库文件校验失败", "error", err)
+			}
+		}
+	}
+}
+
+// Verify 校验所有业务组的库文件，返回本次产生的事件。单个文件读取失败时记录日志并跳过，不视为丢失
+func (s *Service) Verify(ctx context.Context) (*domain.FixityRun, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	started := s.now()
+	run := &domain.FixityRun{Events: make([]domain.FixityEvent, 0), StartedAt: started.UTC()}
+
+	// 先记下各业务组的写操作次数，校验期间发生的写操作留给下一次
+	s.mu.Lock()
+	writes := make(map[string]uint64, len(s.writes))
+	for biz, n := range s.writes {
+		writes[biz] = n
+	}
+	s.mu.Unlock()
+
+	stored, err := s.ListChecksums(ctx)
+	if err != nil {
+		return nil, err
+	}
+	baseline := make(map[string]domain.FixityChecksum, len(stored))
+	for _, c := range stored {
+		baseline[c.BizName+"/"+c.FileName] = c
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.instanceDir, "*", "*.db"))
+	if err != nil {
+		return nil, fmt.Errorf("扫描库文件失败: %w", err)
+	}
+	sort.Strings(files)
+	changedBiz := make(map[string]bool)
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bizName, fileName := filepath.Base(filepath.Dir(path)), filepath.Base(path)
+		key := bizName + "/" + fileName
+		previous, known := baseline[key]
+		delete(baseline, key)
+
+		sum, size, err := checksum(path)
+		if err != nil {
+			slog.Warn("[Fixity] 读取库文件失败，已跳过", "file", path, "error", err)
+			continue
+		}
+		run.Files++
+		now := s.now().UTC()
+		switch {
+		case !known:
+			run.Events = append(run.Events, domain.FixityEvent{BizName: bizName, FileName: fileName, Event: domain.FixityEventBaseline, ActualSHA256: sum, CreatedAt: now})
+		case previous.SHA256 != sum:
+			event := domain.FixityEventMismatch
+			if writes[bizName] > s.explainedWrites(bizName) {
+				event = domain.FixityEventUpdated
+				changedBiz[bizName] = true
+			}
+			run.Events = append(run.Events, domain.FixityEvent{BizName: bizName, FileName: fileName, Event: event, ExpectedSHA256: previous.SHA256, ActualSHA256: sum, CreatedAt: now})
+		default:
+			if _, err := s.db.ExecContext(ctx, `UPDATE fixity_checksums SET checked_at = ? WHERE biz_name = ? AND file_name = ?`, now, bizName, fileName); err != nil {
+				return nil, fmt.Errorf("更新校验时间失败: %w", err)
+			}
+			continue
+		}
+		// 变化后的校验值成为新的基准，同一次修改只告警一次
+		if _, err := s.db.ExecContext(ctx,
+			`INSERT INTO fixity_checksums (biz_name, file_name, sha256, size, changed_at, checked_at) VALUES (?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(biz_name, file_name) DO UPDATE SET sha256 = excluded.sha256, size = excluded.size, changed_at = excluded.changed_at, checked_at = excluded.checked_at`,
+			bizName, fileName, sum, size, now, now); err != nil {
+			return nil, fmt.Errorf("保存校验值失败: %w", err)
+		}
+	}
+
+	// 本次未找到的已登记文件视为丢失，并移出基准，避免重复告警
+	for _, missing := range baseline {
+		run.Events = append(run.Events, domain.FixityEvent{BizName: missing.BizName, FileName: missing.FileName, Event: domain.FixityEventMissing, ExpectedSHA256: missing.SHA256, CreatedAt: s.now().UTC()})
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM fixity_checksums WHERE biz_name = ? AND file_name = ?`, missing.BizName, missing.FileName); err != nil {
+			return nil, fmt.Errorf("移除校验值失败: %w", err)
+		}
+	}
+
+	for i := range run.Events {
+		event := &run.Events[i]
+		res, err := s.db.ExecContext(ctx,
+			`INSERT INTO fixity_events (biz_name, file_name, event, expected_sha256, actual_sha256, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			event.BizName, event.FileName, event.Event, event.ExpectedSHA256, event.ActualSHA256, event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("保存校验事件失败: %w", err)
+		}
+		event.ID, _ = res.LastInsertId()
+		if event.Event == domain.FixityEventMismatch || event.Event == domain.FixityEventMissing {
+			s.alert(*event)
+		}
+	}
+
+	s.mu.Lock()
+	for biz := range changedBiz {
+		s.explained[biz] = writes[biz]
+	}
+	s.mu.Unlock()
+
+	run.Duration = s.now().Sub(started).String()
+	return run, nil
+}
+
+func (s *Service) explainedWrites(bizName string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.explained[bizName]
+}
+
+// alert 记录告警日志，并在配置了 webhook 时异步通知
+func (s *Service) alert(event domain.FixityEvent) {
+	slog.Error("[Fixity] 库文件发生了网关之外的变化",
+		"biz", event.BizName, "file", event.FileName, "event", event.Event,
+		"expected_sha256", event.ExpectedSHA256, "actual_sha256", event.ActualSHA256)
+	if s.opts.WebhookURL == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+		defer cancel()
+		if err := s.postWebhook(ctx, event); err != nil {
+			slog.Warn("[Fixity] 发送校验告警失败", "error", err)
+		}
+	}()
+}
+
+func (s *Service) postWebhook(ctx context.Context, event domain.FixityEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListChecksums 返回所有已登记的库文件校验值
+func (s *Service) ListChecksums(ctx context.Context) ([]domain.FixityChecksum, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT biz_name, file_name, sha256, size, changed_at, checked_at FROM fixity_checksums ORDER BY biz_name, file_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询校验值失败: %w", err)
+	}
+	defer rows.Close()
+	list := make([]domain.FixityChecksum, 0)
+	for rows.Next() {
+		var c domain.FixityChecksum
+		if err := rows.Scan(&c.BizName, &c.FileName, &c.SHA256, &c.Size, &c.ChangedAt, &c.CheckedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// ListEvents 按时间倒序列出校验事件，bizName 不为空时只列出该业务组，limit 为 0 时返回 100 条
+func (s *Service) ListEvents(ctx context.Context, bizName string, limit int) ([]domain.FixityEvent, error) {
+	if limit <= 0 {
+		limit = defaultEventListLimit
+	}
+	limit = min(limit, maxEventListLimit)
+	query := `SELECT id, biz_name, file_name, event, expected_sha256, actual_sha256, created_at FROM fixity_events`
+	args := []interface{}{}
+	if bizName != "" {
+		query += ` WHERE biz_name = ?`
+		args = append(args, bizName)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询校验事件失败: %w", err)
+	}
+	defer rows.Close()
+	list := make([]domain.FixityEvent, 0)
+	for rows.Next() {
+		var e domain.FixityEvent
+		if err := rows.Scan(&e.ID, &e.BizName, &e.FileName, &e.Event, &e.ExpectedSHA256, &e.ActualSHA256, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// checksum 计算文件的 SHA-256 与大小
+func checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
@@ -0,0 +1,179 @@
+// file: internal/service/fixity/fixity_service_test.go
+package fixity
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type stubDataSource struct {
+	port.DataSource
+	fail bool
+}
+
+func (d *stubDataSource) Mutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	if d.fail {
+		return nil, port.ErrPermissionDenied
+	}
+	return &port.MutateResult{}, nil
+}
+
+func newTestService(t *testing.T, opts Options) (*Service, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+	instanceDir := filepath.Join(dir, "instance")
+	require.NoError(t, os.MkdirAll(filepath.Join(instanceDir, "archive"), 0o755))
+	svc, err := NewService(db, instanceDir, opts)
+	require.NoError(t, err)
+	return svc, instanceDir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func eventTypes(run *domain.FixityRun) []string {
+	types := make([]string, len(run.Events))
+	for i, e := range run.Events {
+		types[i] = e.Event
+	}
+	return types
+}
+
+func TestNewService_Validation(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewService(nil, "instance", Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, "", Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, "instance", Options{IntervalMinutes: -1})
+	assert.Error(t, err)
+
+	svc, err := NewService(db, "instance", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultIntervalMinutes, svc.opts.IntervalMinutes)
+}
+
+func TestVerify_DetectsChanges(t *testing.T) {
+	svc, instanceDir := newTestService(t, Options{})
+	ctx := context.Background()
+	libA := filepath.Join(instanceDir, "archive", "a.db")
+	libB := filepath.Join(instanceDir, "archive", "b.db")
+	writeFile(t, libA, "original")
+	writeFile(t, libB, "other")
+
+	run, err := svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, run.Files)
+	assert.Equal(t, []string{domain.FixityEventBaseline, domain.FixityEventBaseline}, eventTypes(run))
+
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, run.Events, "未变化的文件不产生事件")
+
+	// 没有经由网关写入却发生变化
+	writeFile(t, libA, "tampered")
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	require.Len(t, run.Events, 1)
+	assert.Equal(t, domain.FixityEventMismatch, run.Events[0].Event)
+	assert.Equal(t, "a.db", run.Events[0].FileName)
+	assert.NotEqual(t, run.Events[0].ExpectedSHA256, run.Events[0].ActualSHA256)
+
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, run.Events, "同一次修改只告警一次")
+
+	// 经由网关的写操作解释了随后的变化，写操作未写回前的校验不消耗计数
+	archive := svc.Wrap("archive", &stubDataSource{})
+	_, err = archive.Mutate(ctx, port.MutateRequest{BizName: "archive", Operation: "update"})
+	require.NoError(t, err)
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, run.Events)
+	writeFile(t, libA, "updated through gateway")
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.FixityEventUpdated}, eventTypes(run))
+
+	writeFile(t, libA, "tampered again")
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.FixityEventMismatch}, eventTypes(run), "写操作计数只解释一次变化")
+
+	// 失败的写操作不计数
+	failing := svc.Wrap("archive", &stubDataSource{fail: true})
+	_, err = failing.Mutate(ctx, port.MutateRequest{BizName: "archive", Operation: "update"})
+	require.Error(t, err)
+	writeFile(t, libA, "tampered a third time")
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.FixityEventMismatch}, eventTypes(run))
+
+	require.NoError(t, os.Remove(libB))
+	run, err = svc.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.Files)
+	assert.Equal(t, []string{domain.FixityEventMissing}, eventTypes(run))
+
+	checksums, err := svc.ListChecksums(ctx)
+	require.NoError(t, err)
+	require.Len(t, checksums, 1)
+	assert.Equal(t, "a.db", checksums[0].FileName)
+	assert.Equal(t, int64(len("tampered a third time")), checksums[0].Size)
+
+	events, err := svc.ListEvents(ctx, "archive", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 7)
+	assert.Equal(t, domain.FixityEventMissing, events[0].Event)
+	events, err = svc.ListEvents(ctx, "museum", 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestVerify_AlertsWebhook(t *testing.T) {
+	received := make(chan domain.FixityEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event domain.FixityEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer server.Close()
+
+	svc, instanceDir := newTestService(t, Options{WebhookURL: server.URL})
+	ctx := context.Background()
+	lib := filepath.Join(instanceDir, "archive", "a.db")
+	writeFile(t, lib, "original")
+	_, err := svc.Verify(ctx)
+	require.NoError(t, err)
+	writeFile(t, lib, "tampered")
+	_, err = svc.Verify(ctx)
+	require.NoError(t, err)
+
+	select {
+	case event := <-received:
+		assert.Equal(t, domain.FixityEventMismatch, event.Event)
+		assert.Equal(t, "archive", event.BizName)
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到校验告警")
+	}
+}
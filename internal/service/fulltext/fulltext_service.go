@@ -0,0 +1,283 @@
+// Package fulltext file: internal/service/fulltext/fulltext_service.go
+package fulltext
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// JobKindRebuild 是全文索引重建任务的类型，任务目标为表名
+	JobKindRebuild = "fts_rebuild"
+
+	defaultBatchSize = 2000
+)
+
+// ErrInvalidConfig 表示全文检索配置不合法
+var ErrInvalidConfig = errors.New("无效的全文检索配置")
+
+// Service 管理各表的全文检索配置，并以后台任务的形式驱动数据源分步重建全文索引。
+type Service struct {
+	config    port.QueryAdminConfigService
+	registry  map[string]port.DataSource
+	jobs      *jobs.Service
+	batchSize int
+}
+
+// NewService 创建一个新的全文检索服务实例
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource, jobService *jobs.Service) (*Service, error) {
+	if config == nil || jobService == nil {
+		return nil, errors.New("fulltext.Service 需要有效的配置服务与后台任务服务")
+	}
+	return &Service{config: config, registry: registry, jobs: jobService, batchSize: defaultBatchSize}, nil
+}
+
+// UpdateConfig 校验并保存表的全文检索配置，并在索引内容受影响时启动重建任务。
+// 只修改停用词时不需要重建，返回的任务为 nil。
+// 该表已有重建任务在运行时不再启动新任务，运行中的任务会在完成前发现配置变更并按新配置重新开始。
+func (s *Service) UpdateConfig(ctx context.Context, bizName, tableName string, cfg domain.FTSConfig, userID int64) (*domain.FTSConfig, *domain.BackgroundJob, error) {
+	tableConfig, err := s.tableConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := normalizeConfig(&cfg, tableConfig); err != nil {
+		return nil, nil, err
+	}
+
+	previous := tableConfig.FTS
+	if previous != nil {
+		cfg.Version = previous.Version
+		if !sameIndexContent(previous, &cfg) {
+			cfg.Version++
+		}
+	} else {
+		cfg.Version = 1
+	}
+	if err := s.config.UpdateTableFTSConfig(ctx, bizName, tableName, &cfg); err != nil {
+		return nil, nil, err
+	}
+	if previous != nil && previous.Version == cfg.Version {
+		return &cfg, nil, nil
+	}
+
+	job, err := s.startOrJoin(ctx, bizName, tableName, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cfg, job, nil
+}
+
+// DeleteConfig 清除表的全文检索配置，并启动删除索引的后台任务
+func (s *Service) DeleteConfig(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	tableConfig, err := s.tableConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if tableConfig.FTS == nil {
+		return nil, fmt.Errorf("%w: 表 '%s' 未配置全文检索", ErrInvalidConfig, tableName)
+	}
+	if err := s.config.UpdateTableFTSConfig(ctx, bizName, tableName, nil); err != nil {
+		return nil, err
+	}
+	return s.startOrJoin(ctx, bizName, tableName, userID)
+}
+
+// Rebuild 按表当前的配置启动重建任务；表未配置全文检索时任务会删除已有的索引。
+// 已有重建任务在运行时返回 jobs.ErrJobConflict。
+func (s *Service) Rebuild(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	if _, err := s.tableConfig(ctx, bizName, tableName); err != nil {
+		return nil, err
+	}
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	return s.jobs.Start(ctx, JobKindRebuild, bizName, tableName, userID, func(ctx context.Context, progress *jobs.Progress) error {
+		return s.run(ctx, dataSource, bizName, tableName, progress)
+	})
+}
+
+func (s *Service) startOrJoin(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	job, err := s.Rebuild(ctx, bizName, tableName, userID)
+	if errors.Is(err, jobs.ErrJobConflict) {
+		return s.jobs.FindActive(ctx, JobKindRebuild, bizName, tableName)
+	}
+	return job, err
+}
+
+func (s *Service) tableConfig(ctx context.Context, bizName, tableName string) (*domain.TableConfig, error) {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableConfig, exists := bizConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	return tableConfig, nil
+}
+
+// run 是重建任务的执行体。每轮先建立暂存索引并分批复制，替换前再次读取配置：
+// 配置在复制期间被修改时按新配置重新开始，被清除时删除索引。
+func (s *Service) run(ctx context.Context, dataSource port.DataSource, bizName, tableName string, progress *jobs.Progress) error {
+	step := func(ctx context.Context, name string, cfg *domain.FTSConfig, extra map[string]interface{}) (map[string]interface{}, error) {
+		payload := map[string]interface{}{"table_name": tableName, "step": name}
+		if cfg != nil {
+			fields := make([]interface{}, len(cfg.Fields))
+			for i, f := range cfg.Fields {
+				fields[i] = f
+			}
+			payload["config"] = map[string]interface{}{
+				"fields":            fields,
+				"tokenizer":         cfg.Tokenizer,
+				"remove_diacritics": cfg.RemoveDiacritics,
+				"version":           float64(cfg.Version),
+			}
+		}
+		for k, v := range extra {
+			payload[k] = v
+		}
+		res, err := port.AdminMutate(ctx, dataSource, port.MutateRequest{BizName: bizName, Operation: port.MutateOpFTSRebuild, Payload: payload})
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	}
+	// abort 在任务失败或被取消后清理暂存索引，此时任务的 ctx 可能已被取消
+	abort := func() {
+		_, _ = step(context.Background(), "abort", nil, nil)
+	}
+
+	for {
+		tableConfig, err := s.tableConfig(ctx, bizName, tableName)
+		if err != nil {
+			return err
+		}
+		cfg := tableConfig.FTS
+		if cfg == nil {
+			progress.SetMessage("正在删除全文索引")
+			if _, err := step(ctx, "drop", nil, nil); err != nil {
+				return err
+			}
+			progress.SetMessage("全文索引已删除")
+			return nil
+		}
+
+		prepared, err := step(ctx, "prepare", cfg, nil)
+		if err != nil {
+			abort()
+			return err
+		}
+		progress.Reset(toInt64(prepared["total"]))
+		progress.SetMessage(fmt.Sprintf("正在按版本 %d 的配置建立全文索引", cfg.Version))
+
+		libs, _ := prepared["libs"].([]interface{})
+		for _, raw := range libs {
+			entry, _ := raw.(map[string]interface{})
+			lib, _ := entry["lib"].(string)
+			var after int64
+			for {
+				if err := ctx.Err(); err != nil {
+					abort()
+					return err
+				}
+				copied, err := step(ctx, "copy", cfg, map[string]interface{}{
+					"lib":         lib,
+					"after_rowid": float64(after),
+					"limit":       float64(s.batchSize),
+				})
+				if err != nil {
+					abort()
+					return err
+				}
+				progress.Add(toInt64(copied["copied"]))
+				after = toInt64(copied["next_rowid"])
+				if done, _ := copied["done"].(bool); done {
+					break
+				}
+			}
+		}
+
+		current, err := s.tableConfig(ctx, bizName, tableName)
+		if err != nil {
+			abort()
+			return err
+		}
+		if current.FTS == nil || current.FTS.Version != cfg.Version {
+			progress.SetMessage("全文检索配置已变更，重新开始")
+			continue
+		}
+		if _, err := step(ctx, "finish", cfg, nil); err != nil {
+			abort()
+			return err
+		}
+		progress.SetMessage(fmt.Sprintf("全文索引已按版本 %d 的配置建立", cfg.Version))
+		return nil
+	}
+}
+
+// normalizeConfig 校验字段与分词器，去除空白与重复的字段和停用词
+func normalizeConfig(cfg *domain.FTSConfig, tableConfig *domain.TableConfig) error {
+	switch cfg.Tokenizer {
+	case "":
+		cfg.Tokenizer = domain.FTSTokenizerUnicode61
+	case domain.FTSTokenizerUnicode61, domain.FTSTokenizerPorter, domain.FTSTokenizerTrigram, domain.FTSTokenizerICU:
+	default:
+		return fmt.Errorf("%w: 不支持的分词器 '%s'", ErrInvalidConfig, cfg.Tokenizer)
+	}
+
+	cfg.Fields = dedupe(cfg.Fields, strings.TrimSpace)
+	if len(cfg.Fields) == 0 {
+		return fmt.Errorf("%w: 至少需要一个字段", ErrInvalidConfig)
+	}
+	for _, field := range cfg.Fields {
+		setting, exists := tableConfig.Fields[field]
+		if !exists || !setting.IsSearchable {
+			return fmt.Errorf("%w: 字段 '%s' 不存在或不可搜索", ErrInvalidConfig, field)
+		}
+	}
+	cfg.Stopwords = dedupe(cfg.Stopwords, func(w string) string { return strings.ToLower(strings.TrimSpace(w)) })
+	return nil
+}
+
+// sameIndexContent 判断两份配置建立的索引是否相同 (停用词只在检索时使用，不影响索引)
+func sameIndexContent(a, b *domain.FTSConfig) bool {
+	return a.Tokenizer == b.Tokenizer && a.RemoveDiacritics == b.RemoveDiacritics && slices.Equal(a.Fields, b.Fields)
+}
+
+func dedupe(values []string, clean func(string) string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = clean(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// toInt64 兼容进程内数据源返回的整数与经 gRPC (structpb) 传输后的 float64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
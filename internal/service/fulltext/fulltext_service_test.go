@@ -0,0 +1,151 @@
+// file: internal/service/fulltext/fulltext_service_test.go
+package fulltext
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv struct {
+	svc     *Service
+	jobs    *jobs.Service
+	config  *admin_config.AdminConfigServiceImpl
+	manager *sqlite.Manager
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('library', 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable) VALUES
+		('library', 'books', 'title', TRUE, TRUE), ('library', 'books', 'note', FALSE, TRUE)`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "library"), 0o755))
+	libDB, err := sql.Open("sqlite", filepath.Join(root, "library", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT);
+		INSERT INTO books (title, note) VALUES ('The Old Man and the Sea', 'a'), ('Sea of Tranquility', 'b'), ('Café Society', 'c');`)
+	require.NoError(t, err)
+	require.NoError(t, libDB.Close())
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	jobService, err := jobs.NewService(sysDB)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	svc, err := NewService(config, map[string]port.DataSource{"library": manager}, jobService)
+	require.NoError(t, err)
+	svc.batchSize = 2
+	return &testEnv{svc: svc, jobs: jobService, config: config, manager: manager}
+}
+
+func (e *testEnv) waitJob(t *testing.T, job *domain.BackgroundJob) *domain.BackgroundJob {
+	t.Helper()
+	require.NotNil(t, job)
+	var current *domain.BackgroundJob
+	require.Eventually(t, func() bool {
+		var err error
+		current, err = e.jobs.Get(context.Background(), job.ID)
+		require.NoError(t, err)
+		return current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return current
+}
+
+func (e *testEnv) search(t *testing.T, value string) (int64, error) {
+	t.Helper()
+	res, err := e.manager.Query(context.Background(), port.QueryRequest{BizName: "library", Query: map[string]interface{}{
+		"table":   "books",
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": value, "fts": true}},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.Data["total"].(int64), nil
+}
+
+func TestUpdateConfigRebuildsIndex(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+
+	_, _, err := env.svc.UpdateConfig(ctx, "library", "books", domain.FTSConfig{Fields: []string{"note"}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig, "不可搜索的字段不能建立全文索引")
+	_, _, err = env.svc.UpdateConfig(ctx, "library", "books", domain.FTSConfig{Fields: []string{"title"}, Tokenizer: "jieba"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	cfg, job, err := env.svc.UpdateConfig(ctx, "library", "books", domain.FTSConfig{Fields: []string{" title ", "title"}, RemoveDiacritics: true}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title"}, cfg.Fields)
+	assert.Equal(t, domain.FTSTokenizerUnicode61, cfg.Tokenizer)
+	assert.EqualValues(t, 1, cfg.Version)
+	finished := env.waitJob(t, job)
+	require.Equal(t, domain.JobStatusSucceeded, finished.Status, finished.Error)
+	assert.EqualValues(t, 3, finished.ProgressTotal)
+	assert.EqualValues(t, 3, finished.ProgressDone)
+
+	total, err := env.search(t, "cafe")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	// 只修改停用词不需要重建
+	cfg, job, err = env.svc.UpdateConfig(ctx, "library", "books", domain.FTSConfig{Fields: []string{"title"}, RemoveDiacritics: true, Stopwords: []string{" The ", "of"}}, 1)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.EqualValues(t, 1, cfg.Version)
+	assert.Equal(t, []string{"the", "of"}, cfg.Stopwords)
+	total, err = env.search(t, "the sea")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	// 修改分词器后版本递增并重建，Schema 报告新的分词器
+	cfg, job, err = env.svc.UpdateConfig(ctx, "library", "books", domain.FTSConfig{Fields: []string{"title"}, Tokenizer: domain.FTSTokenizerTrigram}, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, cfg.Version)
+	require.Equal(t, domain.JobStatusSucceeded, env.waitJob(t, job).Status)
+	schema, err := env.manager.GetSchema(ctx, port.SchemaRequest{BizName: "library", TableName: "books"})
+	require.NoError(t, err)
+	for _, field := range schema.Tables["books"] {
+		if field.Name == "title" {
+			assert.Equal(t, domain.FTSTokenizerTrigram, field.FullTextTokenizer)
+		}
+	}
+
+	job, err = env.svc.DeleteConfig(ctx, "library", "books", 1)
+	require.NoError(t, err)
+	require.Equal(t, domain.JobStatusSucceeded, env.waitJob(t, job).Status)
+	_, err = env.search(t, "sea")
+	assert.ErrorContains(t, err, "未启用全文检索")
+	_, err = env.svc.DeleteConfig(ctx, "library", "books", 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestRebuildUnknownTable(t *testing.T) {
+	env := newTestEnv(t)
+	_, err := env.svc.Rebuild(context.Background(), "library", "missing", 1)
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = env.svc.Rebuild(context.Background(), "archive", "books", 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
@@ -0,0 +1,274 @@
+// Package identpolicy file: internal/service/identpolicy/identpolicy_service.go
+package identpolicy
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 标识符策略的执行模式
+const (
+	// ModeStrict 拒绝保存或查询不合规的表名与字段名
+	ModeStrict = "strict"
+	// ModeAudit 只记录日志，不拒绝 (默认)，便于在切换到 strict 前通过兼容性报告修正现有配置
+	ModeAudit = "audit"
+	// ModeOff 不做检查
+	ModeOff = "off"
+)
+
+const (
+	// DefaultPattern 允许以字母或下划线开头、由字母、数字与下划线组成的标识符，字母包含中文等 Unicode 字母
+	DefaultPattern = `^[\p{L}_][\p{L}\p{N}_]*$`
+	// DefaultMaxLength 是标识符的默认最大字符数
+	DefaultMaxLength = 64
+)
+
+// defaultReservedPrefixes 是默认保留的前缀：sqlite_ 为 SQLite 内部对象，__ 与网关附加的元信息键 (如 __lib) 冲突
+var defaultReservedPrefixes = []string{"sqlite_", "__"}
+
+// logInterval 是同一标识符重复告警的最小间隔；maxLogged 限制记录的告警时间条目数，防止被随机字段名撑大
+const (
+	logInterval = time.Minute
+	maxLogged   = 1024
+)
+
+// Options 定义标识符策略的配置
+type Options struct {
+	// Mode 为 strict、audit 或 off，默认为 audit
+	Mode string `mapstructure:"mode"`
+	// Pattern 是标识符必须完整匹配的正则表达式，默认为 DefaultPattern
+	Pattern string `mapstructure:"pattern"`
+	// MaxLength 是标识符的最大字符数，默认为 DefaultMaxLength
+	MaxLength int `mapstructure:"max_length"`
+	// ReservedPrefixes 是不允许使用的前缀 (不区分大小写)，为空时使用默认值
+	ReservedPrefixes []string `mapstructure:"reserved_prefixes"`
+	// ReservedNames 是不允许使用的完整名称 (不区分大小写)
+	ReservedNames []string `mapstructure:"reserved_names"`
+}
+
+// Service 在保存配置与处理请求时检查表名和字段名。这些标识符最终会以引号包裹的形式拼入数据源的 SQL，
+// 插件本身的转义之外，网关按白名单规则、长度上限与保留名再做一层约束，作为纵深防御
+type Service struct {
+	config    port.QueryAdminConfigService
+	mode      string
+	pattern   *regexp.Regexp
+	maxLength int
+	prefixes  []string
+	reserved  map[string]bool
+	now       func() time.Time
+
+	mu         sync.Mutex
+	lastLogged map[string]time.Time
+}
+
+var _ port.IdentifierChecker = (*Service)(nil)
+
+// NewService 创建一个新的标识符策略服务实例
+func NewService(config port.QueryAdminConfigService, opts Options) (*Service, error) {
+	if config == nil {
+		return nil, fmt.Errorf("identpolicy.Service 需要一个有效的配置服务")
+	}
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	switch mode {
+	case "":
+		mode = ModeAudit
+	case ModeStrict, ModeAudit, ModeOff:
+	default:
+		return nil, fmt.Errorf("未知的标识符策略模式 '%s'，可选 strict、audit 或 off", opts.Mode)
+	}
+	patternText := strings.TrimSpace(opts.Pattern)
+	if patternText == "" {
+		patternText = DefaultPattern
+	}
+	pattern, err := regexp.Compile(patternText)
+	if err != nil {
+		return nil, fmt.Errorf("标识符策略的正则表达式无效: %w", err)
+	}
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	prefixes := opts.ReservedPrefixes
+	if len(prefixes) == 0 {
+		prefixes = defaultReservedPrefixes
+	}
+	s := &Service{
+		config:     config,
+		mode:       mode,
+		pattern:    pattern,
+		maxLength:  maxLength,
+		reserved:   make(map[string]bool, len(opts.ReservedNames)),
+		now:        time.Now,
+		lastLogged: make(map[string]time.Time),
+	}
+	for _, prefix := range prefixes {
+		if prefix = strings.ToLower(strings.TrimSpace(prefix)); prefix != "" {
+			s.prefixes = append(s.prefixes, prefix)
+		}
+	}
+	for _, name := range opts.ReservedNames {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			s.reserved[name] = true
+		}
+	}
+	return s, nil
+}
+
+// Mode 返回当前的执行模式
+func (s *Service) Mode() string {
+	return s.mode
+}
+
+// Validate 按策略检查一个标识符，不受执行模式影响；不合规时返回包装了 port.ErrInvalidIdentifier 的错误
+func (s *Service) Validate(name string) error {
+	if reason := s.violation(name); reason != "" {
+		return fmt.Errorf("%w: '%s' %s", port.ErrInvalidIdentifier, name, reason)
+	}
+	return nil
+}
+
+// violation 返回标识符不合规的原因，合规时返回空字符串
+func (s *Service) violation(name string) string {
+	if name == "" {
+		return "为空"
+	}
+	if n := utf8.RuneCountInString(name); n > s.maxLength {
+		return fmt.Sprintf("长度为 %d，超过上限 %d", n, s.maxLength)
+	}
+	lower := strings.ToLower(name)
+	if s.reserved[lower] {
+		return "是保留名称"
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return fmt.Sprintf("使用了保留前缀 '%s'", prefix)
+		}
+	}
+	if !s.pattern.MatchString(name) {
+		return fmt.Sprintf("不匹配规则 %s", s.pattern.String())
+	}
+	return ""
+}
+
+// Check 按执行模式检查一个标识符：strict 模式返回错误，audit 模式只记录日志，off 模式不检查。
+// 服务为 nil 时视为未启用
+func (s *Service) Check(kind, name string) error {
+	if s == nil || s.mode == ModeOff {
+		return nil
+	}
+	err := s.Validate(name)
+	if err == nil {
+		return nil
+	}
+	s.report(kind, name, err)
+	if s.mode == ModeAudit {
+		return nil
+	}
+	return err
+}
+
+// report 记录不合规的标识符，同一标识符每分钟最多告警一次
+func (s *Service) report(kind, name string, err error) {
+	key := kind + "/" + name
+	now := s.now()
+	s.mu.Lock()
+	if last, ok := s.lastLogged[key]; ok && now.Sub(last) < logInterval {
+		s.mu.Unlock()
+		return
+	}
+	if len(s.lastLogged) >= maxLogged {
+		s.lastLogged = make(map[string]time.Time)
+	}
+	s.lastLogged[key] = now
+	s.mu.Unlock()
+	slog.Warn("表名或字段名不符合标识符策略", "kind", kind, "identifier", name, "error", err, "mode", s.mode)
+}
+
+// Report 按当前策略检查所有业务组的配置，列出不合规的表名与字段名，不受执行模式影响
+func (s *Service) Report(ctx context.Context) (*domain.IdentifierPolicyReport, error) {
+	bizNames, err := s.config.GetAllConfiguredBizNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(bizNames)
+	report := &domain.IdentifierPolicyReport{
+		Mode:       s.mode,
+		Pattern:    s.pattern.String(),
+		MaxLength:  s.maxLength,
+		BizCount:   len(bizNames),
+		Violations: []domain.IdentifierViolation{},
+	}
+	for _, bizName := range bizNames {
+		bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+		if err != nil {
+			return nil, fmt.Errorf("读取业务组 '%s' 的配置失败: %w", bizName, err)
+		}
+		if bizConfig == nil {
+			continue
+		}
+		report.Violations = append(report.Violations, s.checkBiz(bizConfig)...)
+	}
+	return report, nil
+}
+
+// checkBiz 检查一个业务组配置中出现的全部标识符
+func (s *Service) checkBiz(bizConfig *domain.BizQueryConfig) []domain.IdentifierViolation {
+	var out []domain.IdentifierViolation
+	add := func(tableName, setting, kind, name string) {
+		if reason := s.violation(name); reason != "" {
+			out = append(out, domain.IdentifierViolation{
+				BizName: bizConfig.BizName, TableName: tableName, Setting: setting, Kind: kind, Identifier: name, Reason: reason,
+			})
+		}
+	}
+	// 已在可查询表或字段配置中列出的标识符只报告一次
+	if _, ok := bizConfig.Tables[bizConfig.DefaultQueryTable]; bizConfig.DefaultQueryTable != "" && !ok {
+		add(bizConfig.DefaultQueryTable, "default_query_table", domain.IdentifierKindTable, bizConfig.DefaultQueryTable)
+	}
+	tableNames := make([]string, 0, len(bizConfig.Tables))
+	for tableName := range bizConfig.Tables {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	for _, tableName := range tableNames {
+		table := bizConfig.Tables[tableName]
+		add(tableName, "searchable_table", domain.IdentifierKindTable, tableName)
+		if table == nil {
+			continue
+		}
+		fieldNames := make([]string, 0, len(table.Fields))
+		for fieldName := range table.Fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+		for _, fieldName := range fieldNames {
+			add(tableName, "field", domain.IdentifierKindField, fieldName)
+		}
+		if table.FTS != nil {
+			for _, fieldName := range table.FTS.Fields {
+				if _, ok := table.Fields[fieldName]; !ok {
+					add(tableName, "fts_field", domain.IdentifierKindField, fieldName)
+				}
+			}
+		}
+		if h := table.Hierarchy; h != nil {
+			for _, ref := range [][2]string{
+				{"hierarchy_key_field", h.KeyField}, {"hierarchy_parent_field", h.ParentField}, {"hierarchy_sort_field", h.SortField},
+			} {
+				if _, ok := table.Fields[ref[1]]; ref[1] != "" && !ok {
+					add(tableName, ref[0], domain.IdentifierKindField, ref[1])
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+// file: internal/service/identpolicy/identpolicy_service_test.go
+package identpolicy
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfig(t *testing.T) *admin_config.AdminConfigServiceImpl {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestNewServiceOptions(t *testing.T) {
+	cfg := newConfig(t)
+	svc, err := NewService(cfg, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ModeAudit, svc.Mode())
+
+	_, err = NewService(cfg, Options{Mode: "paranoid"})
+	assert.Error(t, err)
+	_, err = NewService(cfg, Options{Pattern: "(["})
+	assert.Error(t, err)
+	_, err = NewService(nil, Options{})
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	svc, err := NewService(newConfig(t), Options{MaxLength: 8, ReservedNames: []string{"Users"}})
+	require.NoError(t, err)
+
+	for _, name := range []string{"letters", "_private", "书信", "title2"} {
+		assert.NoError(t, svc.Validate(name), name)
+	}
+	for _, name := range []string{"", "2letters", `a"b`, "a b", "a;--", "toolongname", "users", "SQLITE_master", "__lib"} {
+		assert.ErrorIs(t, svc.Validate(name), port.ErrInvalidIdentifier, name)
+	}
+}
+
+func TestCheckModes(t *testing.T) {
+	cfg := newConfig(t)
+	strict, err := NewService(cfg, Options{Mode: ModeStrict})
+	require.NoError(t, err)
+	assert.ErrorIs(t, strict.Check(domain.IdentifierKindField, `x" OR 1=1`), port.ErrInvalidIdentifier)
+	assert.NoError(t, strict.Check(domain.IdentifierKindField, "title"))
+
+	for _, mode := range []string{ModeAudit, ModeOff} {
+		svc, err := NewService(cfg, Options{Mode: mode})
+		require.NoError(t, err)
+		assert.NoError(t, svc.Check(domain.IdentifierKindField, `x" OR 1=1`), mode)
+	}
+	var disabled *Service
+	assert.NoError(t, disabled.Check(domain.IdentifierKindTable, "a b"))
+}
+
+func TestReportThrottle(t *testing.T) {
+	svc, err := NewService(newConfig(t), Options{})
+	require.NoError(t, err)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	require.NoError(t, svc.Check(domain.IdentifierKindField, "a b"))
+	require.NoError(t, svc.Check(domain.IdentifierKindField, "a b"))
+	assert.Len(t, svc.lastLogged, 1)
+	for i := 0; i < maxLogged+1; i++ {
+		_ = svc.Check(domain.IdentifierKindField, fmt.Sprintf("bad name %d", i))
+	}
+	assert.LessOrEqual(t, len(svc.lastLogged), maxLogged, "告警记录不应无限增长")
+}
+
+func TestStrictRejectsConfigSave(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig(t)
+	svc, err := NewService(cfg, Options{Mode: ModeStrict})
+	require.NoError(t, err)
+	cfg.SetIdentifierChecker(svc)
+
+	assert.ErrorIs(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books", "sqlite_master"}), port.ErrInvalidIdentifier)
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	assert.ErrorIs(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{{FieldName: "a-b"}}), port.ErrInvalidIdentifier)
+	assert.ErrorIs(t, cfg.UpdateFieldSettingsBulk(ctx, "library", map[string][]domain.FieldSetting{"books": {{FieldName: "a b"}}}), port.ErrInvalidIdentifier)
+	assert.ErrorIs(t, cfg.UpdateTableHierarchyConfig(ctx, "library", "books", &domain.HierarchyConfig{KeyField: "id", ParentField: "p;"}), port.ErrInvalidIdentifier)
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{{FieldName: "title", IsReturnable: true}}))
+}
+
+func TestCompatibilityReport(t *testing.T) {
+	ctx := context.Background()
+	cfg := newConfig(t)
+	// 策略启用前保存的配置不受检查，由兼容性报告列出
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books", "old-table"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "title", IsReturnable: true},
+		{FieldName: "publish year", IsReturnable: true},
+	}))
+	require.NoError(t, cfg.UpdateTableFTSConfig(ctx, "library", "books", &domain.FTSConfig{Fields: []string{"title", "sqlite_body"}}))
+
+	svc, err := NewService(cfg, Options{})
+	require.NoError(t, err)
+	report, err := svc.Report(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ModeAudit, report.Mode)
+	assert.Equal(t, DefaultMaxLength, report.MaxLength)
+	assert.Equal(t, 1, report.BizCount)
+	require.Len(t, report.Violations, 3)
+	assert.Equal(t, "publish year", report.Violations[0].Identifier)
+	assert.Equal(t, "field", report.Violations[0].Setting)
+	assert.Equal(t, "sqlite_body", report.Violations[1].Identifier)
+	assert.Equal(t, "fts_field", report.Violations[1].Setting)
+	assert.Contains(t, report.Violations[1].Reason, "sqlite_")
+	assert.Equal(t, domain.IdentifierViolation{
+		BizName: "library", TableName: "old-table", Setting: "searchable_table", Kind: domain.IdentifierKindTable,
+		Identifier: "old-table", Reason: report.Violations[2].Reason,
+	}, report.Violations[2])
+}
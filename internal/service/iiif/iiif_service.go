@@ -0,0 +1,314 @@
+// Package iiif file: internal/service/iiif/iiif_service.go
+package iiif
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/accesslog"
+	"ArchiveAegis/internal/service/fieldguard"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	presentationContext = "http://iiif.io/api/presentation/3/context.json"
+	// defaultCanvasSize 在映射未提供尺寸字段时使用。启用 ImageService 时查看器会以 info.json 中的真实尺寸为准。
+	defaultCanvasSize = 1000
+)
+
+var (
+	// ErrMappingNotFound 表示指定业务表尚未配置 IIIF 映射
+	ErrMappingNotFound = errors.New("该业务表未配置 IIIF 映射")
+	// ErrInvalidMapping 表示映射配置不合法
+	ErrInvalidMapping = errors.New("无效的 IIIF 映射")
+	// ErrRecordNotFound 表示记录不存在或不可访问
+	ErrRecordNotFound = errors.New("记录不存在")
+	// ErrNoImages 表示记录中没有任何可用的图像地址，无法生成清单
+	ErrNoImages = errors.New("记录中没有可展示的图像")
+)
+
+// Service 负责管理 IIIF 字段映射，并为单条记录生成 IIIF Presentation 3.0 清单。
+// 记录通过数据源的按主键读取模式获取，与查询接口一样经网关侧字段白名单过滤，
+// 敏感业务组的每次生成写入记录访问日志，因此清单不会暴露查询接口不返回的字段。
+type Service struct {
+	db         *sql.DB
+	registry   map[string]port.DataSource
+	fieldGuard *fieldguard.Service
+	accessLog  *accesslog.Service
+}
+
+// NewService 创建一个新的 IIIF 服务实例。fieldGuard 或 accessLog 为 nil 时分别不做字段过滤或访问留痕，与查询接口一致
+func NewService(db *sql.DB, registry map[string]port.DataSource, fieldGuard *fieldguard.Service, accessLog *accesslog.Service) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("iiif.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, registry: registry, fieldGuard: fieldGuard, accessLog: accessLog}, nil
+}
+
+// ListMappings 返回所有已配置的映射，按业务组与表名排序
+func (s *Service) ListMappings(ctx context.Context) ([]domain.IIIFMapping, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT mapping_json, updated_at FROM iiif_mappings ORDER BY biz_name, table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 IIIF 映射失败: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := make([]domain.IIIFMapping, 0)
+	for rows.Next() {
+		var raw string
+		var m domain.IIIFMapping
+		if err := rows.Scan(&raw, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描 IIIF 映射失败: %w", err)
+		}
+		updatedAt := m.UpdatedAt
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			return nil, fmt.Errorf("解析 IIIF 映射失败: %w", err)
+		}
+		m.UpdatedAt = updatedAt
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// GetMapping 读取单个业务表的映射，不存在时返回 ErrMappingNotFound
+func (s *Service) GetMapping(ctx context.Context, bizName, tableName string) (*domain.IIIFMapping, error) {
+	var raw string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT mapping_json FROM iiif_mappings WHERE biz_name = ? AND table_name = ?`, bizName, tableName,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMappingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询业务表 '%s/%s' 的 IIIF 映射失败: %w", bizName, tableName, err)
+	}
+	var m domain.IIIFMapping
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("解析业务表 '%s/%s' 的 IIIF 映射失败: %w", bizName, tableName, err)
+	}
+	return &m, nil
+}
+
+// SaveMapping 创建或更新一个业务表的 IIIF 映射
+func (s *Service) SaveMapping(ctx context.Context, m domain.IIIFMapping) error {
+	if m.BizName == "" || m.TableName == "" {
+		return fmt.Errorf("%w: 必须包含 biz_name 和 table_name", ErrInvalidMapping)
+	}
+	if m.LabelField == "" {
+		return fmt.Errorf("%w: 必须指定 label_field", ErrInvalidMapping)
+	}
+	if len(m.ImageFields) == 0 {
+		return fmt.Errorf("%w: 至少需要指定一个图像字段", ErrInvalidMapping)
+	}
+	if (m.WidthField == "") != (m.HeightField == "") {
+		return fmt.Errorf("%w: width_field 与 height_field 必须同时指定", ErrInvalidMapping)
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("序列化 IIIF 映射失败: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO iiif_mappings (biz_name, table_name, mapping_json, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name, table_name) DO UPDATE SET
+			mapping_json = excluded.mapping_json,
+			updated_at = CURRENT_TIMESTAMP`,
+		m.BizName, m.TableName, string(raw),
+	)
+	if err != nil {
+		return fmt.Errorf("保存业务表 '%s/%s' 的 IIIF 映射失败: %w", m.BizName, m.TableName, err)
+	}
+	return nil
+}
+
+// DeleteMapping 删除一个业务表的 IIIF 映射
+func (s *Service) DeleteMapping(ctx context.Context, bizName, tableName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM iiif_mappings WHERE biz_name = ? AND table_name = ?`, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("删除业务表 '%s/%s' 的 IIIF 映射失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMappingNotFound
+	}
+	return nil
+}
+
+// BuildManifest 为一条记录生成 IIIF Presentation 3.0 清单。
+// manifestURL 为清单自身的访问地址，画布、注释页等资源的 id 均由其派生；homepage 为记录的永久链接。
+// 映射引用的字段未标记为可返回时按缺失处理；敏感业务组写入访问日志失败时不生成清单
+func (s *Service) BuildManifest(ctx context.Context, bizName, tableName, recordID, manifestURL, homepage string, viewer accesslog.Viewer) (*Manifest, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	m, err := s.GetMapping(ctx, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.fieldGuard != nil {
+		if result, err = s.fieldGuard.Filter(ctx, bizName, tableName, result); err != nil {
+			return nil, err
+		}
+	}
+	record, _ := result.Data["record"].(map[string]interface{})
+	if record == nil {
+		return nil, ErrRecordNotFound
+	}
+
+	var imageURLs []string
+	for _, field := range m.ImageFields {
+		imageURLs = append(imageURLs, splitURLs(stringify(record[field]))...)
+	}
+	if len(imageURLs) == 0 {
+		return nil, ErrNoImages
+	}
+	if s.accessLog != nil {
+		if err := s.accessLog.RecordManifest(ctx, bizName, tableName, recordID, viewer); err != nil {
+			return nil, err
+		}
+	}
+
+	label := stringify(record[m.LabelField])
+	if label == "" {
+		label = recordID
+	}
+	manifest := &Manifest{
+		Context:  presentationContext,
+		ID:       manifestURL,
+		Type:     "Manifest",
+		Label:    languageMap(label),
+		Homepage: []resource{{ID: homepage, Type: "Text", Label: languageMap(label), Format: "application/json"}},
+	}
+	if summary := stringify(record[m.SummaryField]); m.SummaryField != "" && summary != "" {
+		manifest.Summary = languageMap(summary)
+	}
+	for _, field := range m.MetadataFields {
+		if value := stringify(record[field]); value != "" {
+			manifest.Metadata = append(manifest.Metadata, labelValue{Label: languageMap(field), Value: languageMap(value)})
+		}
+	}
+	if rights := stringify(record[m.RightsField]); m.RightsField != "" && rights != "" {
+		// 规范要求 rights 为 URI，其它文本作为必需声明展示
+		if strings.HasPrefix(rights, "http://") || strings.HasPrefix(rights, "https://") {
+			manifest.Rights = rights
+		} else {
+			manifest.RequiredStatement = &labelValue{Label: languageMap("Rights"), Value: languageMap(rights)}
+		}
+	}
+
+	width, height := defaultCanvasSize, defaultCanvasSize
+	if m.WidthField != "" {
+		if w, h := toInt(record[m.WidthField]), toInt(record[m.HeightField]); w > 0 && h > 0 {
+			width, height = w, h
+		}
+	}
+	for i, imageURL := range imageURLs {
+		canvasID := fmt.Sprintf("%s/canvas/%d", manifestURL, i+1)
+		body := resource{ID: imageURL, Type: "Image", Format: imageFormat(imageURL), Width: width, Height: height}
+		if m.ImageService {
+			base := strings.TrimSuffix(strings.TrimSuffix(imageURL, "/info.json"), "/")
+			body.ID = base + "/full/max/0/default.jpg"
+			body.Format = "image/jpeg"
+			body.Service = []imageService{{ID: base, Type: "ImageService3", Profile: "level1"}}
+		}
+		manifest.Items = append(manifest.Items, canvas{
+			ID:     canvasID,
+			Type:   "Canvas",
+			Label:  languageMap(fmt.Sprintf("%d", i+1)),
+			Width:  width,
+			Height: height,
+			Items: []annotationPage{{
+				ID:   canvasID + "/page",
+				Type: "AnnotationPage",
+				Items: []annotation{{
+					ID:         canvasID + "/page/painting",
+					Type:       "Annotation",
+					Motivation: "painting",
+					Body:       body,
+					Target:     canvasID,
+				}},
+			}},
+		})
+	}
+	return manifest, nil
+}
+
+// splitURLs 从字段值中提取 http(s) 地址，支持以换行、分号或空白分隔的多个地址
+func splitURLs(value string) []string {
+	var urls []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ';' || r == ' ' || r == '\t'
+	}) {
+		if strings.HasPrefix(part, "http://") || strings.HasPrefix(part, "https://") {
+			urls = append(urls, part)
+		}
+	}
+	return urls
+}
+
+// imageFormat 根据扩展名推断图像的 MIME 类型，无法识别时留空
+func imageFormat(imageURL string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(imageURL, "?", 2)[0]))
+	switch ext {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	case ".tif", ".tiff":
+		return "image/tiff"
+	case ".jp2":
+		return "image/jp2"
+	}
+	return ""
+}
+
+// stringify 将经 gRPC 传输后的字段值转换为文本；整数值的 float64 不带小数部分
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func toInt(v interface{}) int {
+	switch val := v.(type) {
+	case float64:
+		return int(val)
+	case int64:
+		return int(val)
+	case int:
+		return val
+	case string:
+		n, _ := strconv.Atoi(val)
+		return n
+	}
+	return 0
+}
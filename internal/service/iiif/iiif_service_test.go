@@ -0,0 +1,137 @@
+// file: internal/service/iiif/iiif_service_test.go
+package iiif
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/accesslog"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/fieldguard"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	return newServiceWith(t, fixture.NewSystemDB(t), nil, nil)
+}
+
+func newServiceWith(t *testing.T, db *sql.DB, guard *fieldguard.Service, accessLog *accesslog.Service) *Service {
+	t.Helper()
+	ds := fixture.NewFakeDataSource().WithTable("sheets", []port.FieldDescription{{Name: "id", IsPrimary: true}},
+		map[string]interface{}{"id": float64(1), "title": "京师全图", "scans": "https://img.example.org/a.jpg\nhttps://img.example.org/b.png", "w": float64(4000), "h": float64(3000), "license": "https://creativecommons.org/publicdomain/mark/1.0/"},
+		map[string]interface{}{"id": float64(2), "title": "无图记录", "scans": nil},
+	)
+	registry := map[string]port.DataSource{"maps": ds}
+	svc, err := NewService(db, registry, guard, accessLog)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveMapping(context.Background(), domain.IIIFMapping{
+		BizName: "maps", TableName: "sheets", LabelField: "title", RightsField: "license",
+		ImageFields: []string{"scans"}, MetadataFields: []string{"id"}, WidthField: "w", HeightField: "h",
+	}))
+	return svc
+}
+
+func TestBuildManifest(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	manifestURL := "http://gw/api/v1/data/iiif/maps/sheets/1/manifest"
+
+	manifest, err := svc.BuildManifest(ctx, "maps", "sheets", "1", manifestURL, "http://gw/api/v1/data/record/maps/sheets/1", accesslog.Viewer{})
+	require.NoError(t, err)
+	assert.Equal(t, "Manifest", manifest.Type)
+	assert.Equal(t, []string{"京师全图"}, manifest.Label["none"])
+	assert.Equal(t, "https://creativecommons.org/publicdomain/mark/1.0/", manifest.Rights)
+	require.Len(t, manifest.Items, 2, "每个图像地址对应一个画布")
+
+	second := manifest.Items[1]
+	assert.Equal(t, manifestURL+"/canvas/2", second.ID)
+	assert.Equal(t, 4000, second.Width)
+	body := second.Items[0].Items[0].Body
+	assert.Equal(t, "https://img.example.org/b.png", body.ID)
+	assert.Equal(t, "image/png", body.Format)
+	assert.Equal(t, second.ID, second.Items[0].Items[0].Target)
+
+	_, err = svc.BuildManifest(ctx, "maps", "sheets", "2", manifestURL, "", accesslog.Viewer{})
+	assert.ErrorIs(t, err, ErrNoImages)
+	_, err = svc.BuildManifest(ctx, "maps", "sheets", "404", manifestURL, "", accesslog.Viewer{})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	_, err = svc.BuildManifest(ctx, "maps", "unmapped", "1", manifestURL, "", accesslog.Viewer{})
+	assert.ErrorIs(t, err, ErrMappingNotFound)
+}
+
+func TestBuildManifest_ImageService(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.SaveMapping(ctx, domain.IIIFMapping{
+		BizName: "maps", TableName: "sheets", LabelField: "title",
+		ImageFields: []string{"scans"}, ImageService: true,
+	}))
+
+	manifest, err := svc.BuildManifest(ctx, "maps", "sheets", "1", "http://gw/m", "", accesslog.Viewer{})
+	require.NoError(t, err)
+	body := manifest.Items[0].Items[0].Items[0].Body
+	assert.Equal(t, "https://img.example.org/a.jpg/full/max/0/default.jpg", body.ID)
+	require.Len(t, body.Service, 1)
+	assert.Equal(t, "ImageService3", body.Service[0].Type)
+	assert.Equal(t, defaultCanvasSize, manifest.Items[0].Width, "未配置尺寸字段时使用默认画布尺寸")
+
+	err = svc.SaveMapping(ctx, domain.IIIFMapping{BizName: "maps", TableName: "sheets", LabelField: "title"})
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+}
+
+func TestBuildManifest_FieldGuardAndAccessLog(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	table, sensitive := "sheets", true
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "maps", domain.BizOverallSettings{DefaultQueryTable: &table, Sensitive: &sensitive}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "maps", []string{"sheets"}))
+	// license 与尺寸字段未标记为可返回
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "maps", "sheets", []domain.FieldSetting{
+		{FieldName: "id", IsReturnable: true},
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "scans", IsReturnable: true},
+		{FieldName: "license", IsSearchable: true},
+	}))
+	guard, err := fieldguard.NewService(cfg, fieldguard.Options{})
+	require.NoError(t, err)
+	accessLog, err := accesslog.NewService(db, map[string]port.DataSource{}, cfg, accesslog.Options{})
+	require.NoError(t, err)
+	svc := newServiceWith(t, db, guard, accessLog)
+
+	userID := int64(7)
+	manifest, err := svc.BuildManifest(ctx, "maps", "sheets", "1", "http://gw/m", "", accesslog.Viewer{UserID: &userID, IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Rights, "不可返回的字段不出现在清单中")
+	assert.Nil(t, manifest.RequiredStatement)
+	assert.Equal(t, defaultCanvasSize, manifest.Items[0].Width, "不可返回的尺寸字段按缺失处理")
+
+	entries, total, err := accessLog.List(ctx, accesslog.Filter{BizName: "maps"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total, "敏感业务组的清单生成写入访问日志")
+	assert.Equal(t, domain.RecordAccessIIIF, entries[0].Source)
+	assert.Equal(t, "1", entries[0].RecordID)
+	assert.Equal(t, "10.0.0.1", entries[0].IP)
+
+	_, err = svc.BuildManifest(ctx, "maps", "sheets", "2", "http://gw/m", "", accesslog.Viewer{})
+	assert.ErrorIs(t, err, ErrNoImages)
+	_, total, err = accessLog.List(ctx, accesslog.Filter{BizName: "maps"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "未生成的清单不写入访问日志")
+
+	// 图像字段不可返回时，清单不能绕过字段设置暴露图像地址
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "maps", "sheets", []domain.FieldSetting{
+		{FieldName: "id", IsReturnable: true},
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "scans", IsSearchable: true},
+	}))
+	_, err = svc.BuildManifest(ctx, "maps", "sheets", "1", "http://gw/m", "", accesslog.Viewer{})
+	assert.ErrorIs(t, err, ErrNoImages)
+}
@@ -0,0 +1,67 @@
+// Package iiif file: internal/service/iiif/manifest.go
+package iiif
+
+// 以下类型只覆盖 IIIF Presentation 3.0 中本服务实际输出的部分
+
+// Manifest 是 IIIF Presentation 3.0 清单的顶层对象
+type Manifest struct {
+	Context           string              `json:"@context"`
+	ID                string              `json:"id"`
+	Type              string              `json:"type"`
+	Label             map[string][]string `json:"label"`
+	Summary           map[string][]string `json:"summary,omitempty"`
+	Metadata          []labelValue        `json:"metadata,omitempty"`
+	Rights            string              `json:"rights,omitempty"`
+	RequiredStatement *labelValue         `json:"requiredStatement,omitempty"`
+	Homepage          []resource          `json:"homepage,omitempty"`
+	Items             []canvas            `json:"items"`
+}
+
+type labelValue struct {
+	Label map[string][]string `json:"label"`
+	Value map[string][]string `json:"value"`
+}
+
+type resource struct {
+	ID      string              `json:"id"`
+	Type    string              `json:"type"`
+	Label   map[string][]string `json:"label,omitempty"`
+	Format  string              `json:"format,omitempty"`
+	Width   int                 `json:"width,omitempty"`
+	Height  int                 `json:"height,omitempty"`
+	Service []imageService      `json:"service,omitempty"`
+}
+
+type imageService struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Profile string `json:"profile"`
+}
+
+type canvas struct {
+	ID     string              `json:"id"`
+	Type   string              `json:"type"`
+	Label  map[string][]string `json:"label"`
+	Width  int                 `json:"width"`
+	Height int                 `json:"height"`
+	Items  []annotationPage    `json:"items"`
+}
+
+type annotationPage struct {
+	ID    string       `json:"id"`
+	Type  string       `json:"type"`
+	Items []annotation `json:"items"`
+}
+
+type annotation struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Motivation string   `json:"motivation"`
+	Body       resource `json:"body"`
+	Target     string   `json:"target"`
+}
+
+// languageMap 构造语言无关的多语言文本，IIIF 以 "none" 表示未指定语言
+func languageMap(text string) map[string][]string {
+	return map[string][]string{"none": {text}}
+}
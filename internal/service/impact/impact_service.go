@@ -0,0 +1,229 @@
+// Package impact file: internal/service/impact/impact_service.go
+package impact
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/sharelinks"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSettings 表示提交的字段配置本身不合法 (字段名为空或重复)
+var ErrInvalidSettings = errors.New("无效的字段配置")
+
+// Service 在管理员修改字段配置前分析其影响：取消字段的可返回性或可检索性后，
+// 引用这些字段的视图、分享链接的冻结查询以及虚拟业务组中保存的固定过滤条件会失效
+type Service struct {
+	configService port.QueryAdminConfigService
+	shareLinks    *sharelinks.Service
+	virtualBiz    *virtualbiz.Service
+}
+
+// NewService 创建一个新的影响分析服务实例，shareLinks 与 virtualBiz 为 nil 时跳过对应的检查
+func NewService(configService port.QueryAdminConfigService, shareLinks *sharelinks.Service, virtualBiz *virtualbiz.Service) (*Service, error) {
+	if configService == nil {
+		return nil, errors.New("impact.Service 需要一个有效的配置服务")
+	}
+	return &Service{configService: configService, shareLinks: shareLinks, virtualBiz: virtualBiz}, nil
+}
+
+// Lint 检查字段配置本身的问题
+func Lint(fields []domain.FieldSetting) error {
+	seen := make(map[string]bool, len(fields))
+	for i, field := range fields {
+		name := strings.TrimSpace(field.FieldName)
+		if name == "" {
+			return fmt.Errorf("%w: 第 %d 个字段缺少 field_name", ErrInvalidSettings, i+1)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: 字段 '%s' 重复", ErrInvalidSettings, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// AnalyzeFieldSettings 把拟提交的字段配置 (全量替换) 与当前配置比较，列出会失效的视图、分享链接与保存的查询
+func (s *Service) AnalyzeFieldSettings(ctx context.Context, bizName, tableName string, proposed []domain.FieldSetting) (*domain.FieldImpactReport, error) {
+	if err := Lint(proposed); err != nil {
+		return nil, err
+	}
+	cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+
+	report := &domain.FieldImpactReport{
+		BizName:      bizName,
+		TableName:    tableName,
+		Views:        []domain.ImpactedView{},
+		ShareLinks:   []domain.ImpactedShareLink{},
+		SavedQueries: []domain.ImpactedSavedQuery{},
+		Warnings:     []string{},
+	}
+	returnable, searchable := map[string]bool{}, map[string]bool{}
+	for _, field := range proposed {
+		returnable[field.FieldName] = field.IsReturnable
+		searchable[field.FieldName] = field.IsSearchable
+	}
+	removedReturnable, removedSearchable := map[string]bool{}, map[string]bool{}
+	table := cfg.Tables[tableName]
+	if table != nil {
+		for name, field := range table.Fields {
+			if field.IsReturnable && !returnable[name] {
+				removedReturnable[name] = true
+			}
+			if field.IsSearchable && !searchable[name] {
+				removedSearchable[name] = true
+			}
+		}
+	}
+	report.RemovedReturnable = sortedKeys(removedReturnable)
+	report.RemovedSearchable = sortedKeys(removedSearchable)
+	if len(removedReturnable) == 0 && len(removedSearchable) == 0 {
+		return report, nil
+	}
+
+	if table != nil && table.FTS != nil {
+		for _, name := range table.FTS.Fields {
+			if removedSearchable[name] {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("全文检索配置包含字段 '%s'，取消其可检索性后需要调整全文检索配置并重建索引", name))
+			}
+		}
+	}
+
+	views, err := s.configService.GetAllViewConfigsForBiz(ctx, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("读取视图配置失败: %w", err)
+	}
+	for _, view := range views[tableName] {
+		if view == nil {
+			continue
+		}
+		if fields := intersect(viewFields(view), removedReturnable); len(fields) > 0 {
+			report.Views = append(report.Views, domain.ImpactedView{ViewName: view.ViewName, Fields: fields})
+		}
+	}
+
+	if s.shareLinks != nil {
+		links, err := s.shareLinks.ActiveForBiz(ctx, bizName)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if table, _ := link.Query["table"].(string); table != tableName {
+				continue
+			}
+			fields := append(intersect(stringList(link.Query["fields_to_return"]), removedReturnable),
+				intersect(filterFields(link.Query["filters"]), removedSearchable)...)
+			if len(fields) > 0 {
+				report.ShareLinks = append(report.ShareLinks, domain.ImpactedShareLink{
+					ID: link.ID, OwnerID: link.OwnerID, Label: link.Label, Fields: dedupe(fields),
+				})
+			}
+		}
+	}
+
+	if s.virtualBiz != nil {
+		defs, err := s.virtualBiz.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range defs {
+			for _, vt := range def.Tables {
+				if vt.SourceBiz != bizName || vt.SourceTable != tableName {
+					continue
+				}
+				names := make([]string, 0, len(vt.Filters))
+				for _, filter := range vt.Filters {
+					names = append(names, filter.Field)
+				}
+				if fields := intersect(names, removedSearchable); len(fields) > 0 {
+					report.SavedQueries = append(report.SavedQueries, domain.ImpactedSavedQuery{
+						VirtualBiz: def.BizName, VirtualTable: vt.Name, Fields: fields,
+					})
+				}
+			}
+		}
+	}
+
+	report.Breaking = len(report.Views) > 0 || len(report.ShareLinks) > 0 || len(report.SavedQueries) > 0
+	return report, nil
+}
+
+// viewFields 返回视图绑定与投影引用的全部源字段
+func viewFields(view *domain.ViewConfig) []string {
+	var fields []string
+	if card := view.Binding.Card; card != nil {
+		fields = append(fields, card.Title, card.Subtitle, card.Description, card.ImageUrl, card.Tag)
+	}
+	if table := view.Binding.Table; table != nil {
+		for _, column := range table.Columns {
+			fields = append(fields, column.Field)
+		}
+	}
+	for _, source := range view.Projection {
+		fields = append(fields, source)
+	}
+	return fields
+}
+
+func filterFields(raw interface{}) []string {
+	list, _ := raw.([]interface{})
+	fields := make([]string, 0, len(list))
+	for _, item := range list {
+		if filterMap, ok := item.(map[string]interface{}); ok {
+			if field, _ := filterMap["field"].(string); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+func stringList(raw interface{}) []string {
+	list, _ := raw.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// intersect 返回 names 中属于 set 的字段，去重并排序
+func intersect(names []string, set map[string]bool) []string {
+	hit := map[string]bool{}
+	for _, name := range names {
+		if name != "" && set[name] {
+			hit[name] = true
+		}
+	}
+	return sortedKeys(hit)
+}
+
+func dedupe(names []string) []string {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
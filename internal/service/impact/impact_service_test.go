@@ -0,0 +1,91 @@
+// file: internal/service/impact/impact_service_test.go
+package impact
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/sharelinks"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeFieldSettings(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	configService, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+
+	public := true
+	require.NoError(t, configService.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{IsPubliclySearchable: &public}))
+	require.NoError(t, configService.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	current := []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "author", IsSearchable: true, IsReturnable: true},
+		{FieldName: "isbn", IsSearchable: true, IsReturnable: true},
+		{FieldName: "cover", IsReturnable: true},
+	}
+	require.NoError(t, configService.UpdateTableFieldSettings(ctx, "library", "books", current))
+	require.NoError(t, configService.UpdateAllViewsForBiz(ctx, "library", map[string][]*domain.ViewConfig{
+		"books": {
+			{ViewName: "cards", ViewType: "cards", IsDefault: true, Binding: domain.ViewBinding{Card: &domain.CardBinding{Title: "title", ImageUrl: "cover"}}},
+			{ViewName: "grid", ViewType: "table", Binding: domain.ViewBinding{Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{{Field: "title"}, {Field: "author"}}}}},
+		},
+	}))
+
+	_, err = db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'reader', 'x', 'user')`)
+	require.NoError(t, err)
+	future, past := time.Now().Add(time.Hour).UTC(), time.Now().Add(-time.Hour).UTC()
+	_, err = db.Exec(`INSERT INTO share_links (token_hash, token_prefix, owner_id, label, biz_name, query_json, rate_per_minute, expires_at, created_at) VALUES
+		('h1', 'p1', 1, '按 ISBN', 'library', '{"table":"books","filters":[{"field":"isbn","value":"978"}]}', 60, ?, ?),
+		('h2', 'p2', 1, '只看标题', 'library', '{"table":"books","fields_to_return":["title"]}', 60, ?, ?),
+		('h3', 'p3', 1, '已过期', 'library', '{"table":"books","filters":[{"field":"isbn","value":"978"}]}', 60, ?, ?)`,
+		future, past, future, past, past, past)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO virtual_biz_definitions (biz_name, tables_json) VALUES
+		('rare', '[{"name":"books","source_biz":"library","source_table":"books","filters":[{"field":"isbn","value":"x"}]}]')`)
+	require.NoError(t, err)
+
+	shareLinks, err := sharelinks.NewService(db, nil, sharelinks.Options{})
+	require.NoError(t, err)
+	virtualBiz, err := virtualbiz.NewService(db, nil)
+	require.NoError(t, err)
+	svc, err := NewService(configService, shareLinks, virtualBiz)
+	require.NoError(t, err)
+
+	// 取消 cover 的可返回性、isbn 的可检索性
+	report, err := svc.AnalyzeFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "author", IsSearchable: true, IsReturnable: true},
+		{FieldName: "isbn", IsReturnable: true},
+		{FieldName: "cover"},
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Breaking)
+	assert.Equal(t, []string{"cover"}, report.RemovedReturnable)
+	assert.Equal(t, []string{"isbn"}, report.RemovedSearchable)
+	assert.Equal(t, []domain.ImpactedView{{ViewName: "cards", Fields: []string{"cover"}}}, report.Views)
+	require.Len(t, report.ShareLinks, 1, "过期的链接与未引用这些字段的链接不受影响")
+	assert.Equal(t, "按 ISBN", report.ShareLinks[0].Label)
+	assert.Equal(t, []domain.ImpactedSavedQuery{{VirtualBiz: "rare", VirtualTable: "books", Fields: []string{"isbn"}}}, report.SavedQueries)
+
+	// 删除 author 字段会影响表格视图
+	report, err = svc.AnalyzeFieldSettings(ctx, "library", "books", current[:1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"author", "cover", "isbn"}, report.RemovedReturnable)
+	assert.Len(t, report.Views, 2)
+
+	// 只新增可检索性的修改不会破坏任何东西
+	report, err = svc.AnalyzeFieldSettings(ctx, "library", "books", append(current, domain.FieldSetting{FieldName: "year", IsSearchable: true}))
+	require.NoError(t, err)
+	assert.False(t, report.Breaking)
+	assert.Empty(t, report.RemovedReturnable)
+
+	_, err = svc.AnalyzeFieldSettings(ctx, "library", "books", []domain.FieldSetting{{FieldName: "title"}, {FieldName: "title"}})
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+}
@@ -0,0 +1,318 @@
+// Package jobs file: internal/service/jobs/jobs_service.go
+package jobs
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+var (
+	// ErrJobNotFound 表示指定的后台任务不存在
+	ErrJobNotFound = errors.New("后台任务不存在")
+	// ErrJobConflict 表示同一目标上已有未结束的同类任务
+	ErrJobConflict = errors.New("已有未结束的同类任务")
+	// ErrJobNotRunning 表示任务已经结束，无法取消
+	ErrJobNotRunning = errors.New("后台任务已结束")
+)
+
+// Func 是后台任务的执行体。ctx 在任务被取消或网关关闭时取消，执行体应尽快返回 ctx.Err()
+type Func func(ctx context.Context, progress *Progress) error
+
+// Service 在进程内执行长时间任务，并把状态与进度持久化，供管理接口查询。
+// 任务不会在进程重启后恢复：启动时仍处于未结束状态的任务被标记为失败。
+type Service struct {
+	db *sql.DB
+
+	baseCtx context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[int64]context.CancelFunc
+	// active 以 "类型\x00业务组\x00目标" 索引未结束的任务
+	active map[string]int64
+}
+
+// NewService 创建一个新的后台任务服务实例，并把上次运行中被中断的任务标记为失败
+func NewService(db *sql.DB) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("jobs.Service 需要一个有效的数据库连接")
+	}
+	_, err := db.Exec(`UPDATE background_jobs SET status = ?, error = ?, finished_at = ? WHERE status IN (?, ?)`,
+		domain.JobStatusFailed, "网关重启，任务被中断", time.Now().UTC(), domain.JobStatusQueued, domain.JobStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("清理被中断的后台任务失败: %w", err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &Service{
+		db:      db,
+		baseCtx: ctx,
+		stop:    stop,
+		running: make(map[int64]context.CancelFunc),
+		active:  make(map[string]int64),
+	}, nil
+}
+
+func activeKey(kind, bizName, target string) string {
+	return kind + "\x00" + bizName + "\x00" + target
+}
+
+// Start 登记并在后台启动一个任务，立即返回任务记录。
+// 同一类型、业务组与目标上已有未结束的任务时返回 ErrJobConflict。
+func (s *Service) Start(ctx context.Context, kind, bizName, target string, createdBy int64, fn Func) (*domain.BackgroundJob, error) {
+	key := activeKey(kind, bizName, target)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.baseCtx.Err() != nil {
+		return nil, errors.New("后台任务服务已关闭")
+	}
+	if id, exists := s.active[key]; exists {
+		return nil, fmt.Errorf("%w: 任务 #%d", ErrJobConflict, id)
+	}
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO background_jobs (kind, biz_name, target, status, created_by, created_at, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		kind, bizName, target, domain.JobStatusRunning, createdBy, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("登记后台任务失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("登记后台任务失败: %w", err)
+	}
+
+	jobCtx, cancel := context.WithCancel(s.baseCtx)
+	s.running[id] = cancel
+	s.active[key] = id
+	s.wg.Add(1)
+	go s.run(jobCtx, id, key, fn)
+
+	return &domain.BackgroundJob{
+		ID: id, Kind: kind, BizName: bizName, Target: target, Status: domain.JobStatusRunning,
+		CreatedBy: createdBy, CreatedAt: now, StartedAt: &now,
+	}, nil
+}
+
+func (s *Service) run(ctx context.Context, id int64, key string, fn Func) {
+	defer s.wg.Done()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("任务异常终止: %v", p)
+			}
+		}()
+		return fn(ctx, &Progress{s: s, id: id})
+	}()
+
+	status, errText := domain.JobStatusSucceeded, ""
+	switch {
+	case err == nil:
+	case ctx.Err() != nil && errors.Is(err, context.Canceled):
+		status = domain.JobStatusCancelled
+	default:
+		status, errText = domain.JobStatusFailed, err.Error()
+		slog.Warn("[Jobs] 后台任务失败", "id", id, "error", err)
+	}
+
+	// 任务的 ctx 可能已被取消，结束状态必须使用独立的 context 写入
+	_, dbErr := s.db.Exec(`UPDATE background_jobs SET status = ?, error = ?, finished_at = ? WHERE id = ?`,
+		status, errText, time.Now().UTC(), id)
+	if dbErr != nil {
+		slog.Error("[Jobs] 更新后台任务状态失败", "id", id, "error", dbErr)
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+		delete(s.running, id)
+	}
+	delete(s.active, key)
+	s.mu.Unlock()
+}
+
+// FindActive 返回指定类型、业务组与目标上未结束的任务，不存在时返回 ErrJobNotFound
+func (s *Service) FindActive(ctx context.Context, kind, bizName, target string) (*domain.BackgroundJob, error) {
+	s.mu.Lock()
+	id, exists := s.active[activeKey(kind, bizName, target)]
+	s.mu.Unlock()
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+	return s.Get(ctx, id)
+}
+
+// Cancel 请求取消一个运行中的任务。取消是异步的，任务在执行体返回后才变为 cancelled
+func (s *Service) Cancel(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	cancel, ok := s.running[id]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+		return nil
+	}
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: 任务 #%d", ErrJobNotRunning, id)
+}
+
+// Shutdown 取消所有运行中的任务，并等待它们结束或 ctx 到期
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stop()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get 返回一个任务的当前状态
+func (s *Service) Get(ctx context.Context, id int64) (*domain.BackgroundJob, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM background_jobs WHERE id = ?`, id)
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: #%d", ErrJobNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询后台任务 #%d 失败: %w", id, err)
+	}
+	return job, nil
+}
+
+// ListFilter 定义后台任务列表的筛选条件，空值表示不限
+type ListFilter struct {
+	Kind    string
+	BizName string
+	Status  string
+	Limit   int
+}
+
+// List 按创建时间倒序返回任务
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*domain.BackgroundJob, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, filter.Kind)
+	}
+	if filter.BizName != "" {
+		conditions = append(conditions, "biz_name = ?")
+		args = append(args, filter.BizName)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM background_jobs`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询后台任务列表失败: %w", err)
+	}
+	defer rows.Close()
+	jobs := make([]*domain.BackgroundJob, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描后台任务失败: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+const jobColumns = `id, kind, biz_name, target, status, progress_done, progress_total, message, error, created_by, created_at, started_at, finished_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (*domain.BackgroundJob, error) {
+	job := &domain.BackgroundJob{}
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Kind, &job.BizName, &job.Target, &job.Status, &job.ProgressDone, &job.ProgressTotal,
+		&job.Message, &job.Error, &job.CreatedBy, &job.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return job, nil
+}
+
+// Progress 供任务执行体报告进度。进度写入失败只记录日志，不影响任务执行
+type Progress struct {
+	s  *Service
+	id int64
+}
+
+// ID 返回任务的 ID，供执行体以任务 ID 命名产出的文件
+func (p *Progress) ID() int64 {
+	return p.id
+}
+
+// SetTotal 设置任务的总工作量
+func (p *Progress) SetTotal(total int64) {
+	p.exec(`UPDATE background_jobs SET progress_total = ? WHERE id = ?`, total)
+}
+
+// Add 累加已完成的工作量
+func (p *Progress) Add(n int64) {
+	p.exec(`UPDATE background_jobs SET progress_done = progress_done + ? WHERE id = ?`, n)
+}
+
+// SetMessage 更新任务当前阶段的说明
+func (p *Progress) SetMessage(message string) {
+	p.exec(`UPDATE background_jobs SET message = ? WHERE id = ?`, message)
+}
+
+// Reset 把已完成的工作量清零并设置新的总量，用于任务从头重新开始
+func (p *Progress) Reset(total int64) {
+	p.exec(`UPDATE background_jobs SET progress_done = 0, progress_total = ? WHERE id = ?`, total)
+}
+
+func (p *Progress) exec(query string, value interface{}) {
+	if _, err := p.s.db.Exec(query, value, p.id); err != nil {
+		slog.Warn("[Jobs] 更新后台任务进度失败", "id", p.id, "error", err)
+	}
+}
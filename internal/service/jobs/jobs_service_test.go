@@ -0,0 +1,110 @@
+// file: internal/service/jobs/jobs_service_test.go
+package jobs
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *sql.DB) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	s, err := NewService(db)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+	return s, db
+}
+
+// waitFinished 轮询直到任务结束
+func waitFinished(t *testing.T, s *Service, id int64) *domain.BackgroundJob {
+	t.Helper()
+	var job *domain.BackgroundJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = s.Get(context.Background(), id)
+		require.NoError(t, err)
+		return job.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	release := make(chan struct{})
+	job, err := s.Start(ctx, "rebuild", "library", "books", 7, func(ctx context.Context, p *Progress) error {
+		p.Reset(10)
+		p.Add(4)
+		p.SetMessage("复制中")
+		<-release
+		p.Add(6)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusRunning, job.Status)
+
+	_, err = s.Start(ctx, "rebuild", "library", "books", 7, func(ctx context.Context, p *Progress) error { return nil })
+	assert.ErrorIs(t, err, ErrJobConflict, "同一目标上不能同时运行两个同类任务")
+	active, err := s.FindActive(ctx, "rebuild", "library", "books")
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, active.ID)
+
+	close(release)
+	finished := waitFinished(t, s, job.ID)
+	assert.Equal(t, domain.JobStatusSucceeded, finished.Status)
+	assert.EqualValues(t, 10, finished.ProgressDone)
+	assert.EqualValues(t, 10, finished.ProgressTotal)
+	assert.Equal(t, "复制中", finished.Message)
+	assert.EqualValues(t, 7, finished.CreatedBy)
+	assert.NotNil(t, finished.FinishedAt)
+
+	_, err = s.FindActive(ctx, "rebuild", "library", "books")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.ErrorIs(t, s.Cancel(ctx, finished.ID), ErrJobNotRunning)
+	assert.ErrorIs(t, s.Cancel(ctx, 999), ErrJobNotFound)
+
+	failed, err := s.Start(ctx, "rebuild", "library", "books", 7, func(ctx context.Context, p *Progress) error {
+		return errors.New("磁盘已满")
+	})
+	require.NoError(t, err, "前一个任务结束后可以再次启动")
+	finished = waitFinished(t, s, failed.ID)
+	assert.Equal(t, domain.JobStatusFailed, finished.Status)
+	assert.Equal(t, "磁盘已满", finished.Error)
+
+	items, err := s.List(ctx, ListFilter{Kind: "rebuild", Status: domain.JobStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, failed.ID, items[0].ID)
+}
+
+func TestJobCancelAndRestart(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestService(t)
+
+	job, err := s.Start(ctx, "rebuild", "library", "books", 0, func(ctx context.Context, p *Progress) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Cancel(ctx, job.ID))
+	assert.Equal(t, domain.JobStatusCancelled, waitFinished(t, s, job.ID).Status)
+
+	// 模拟进程退出时仍在运行的任务，新实例启动时应将其标记为失败
+	_, err = db.Exec(`INSERT INTO background_jobs (kind, status, created_at) VALUES ('rebuild', 'running', ?)`, time.Now().UTC())
+	require.NoError(t, err)
+	restarted, err := NewService(db)
+	require.NoError(t, err)
+	items, err := restarted.List(ctx, ListFilter{Status: domain.JobStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Contains(t, items[0].Error, "中断")
+}
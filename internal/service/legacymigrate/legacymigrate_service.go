@@ -0,0 +1,508 @@
+// Package legacymigrate file: internal/service/legacymigrate/legacymigrate_service.go
+package legacymigrate
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/plugin_manager"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+var (
+	// ErrInvalidOptions 表示迁移参数不完整或取值无效
+	ErrInvalidOptions = errors.New("迁移参数无效")
+	// ErrLegacyDirNotFound 表示旧版 instance 目录不存在
+	ErrLegacyDirNotFound = errors.New("旧版 instance 目录不存在")
+)
+
+// configTables 是旧版 auth.db 中按业务组保存的配置表，按外键依赖排序。
+// 新版在这些表上追加的列不在旧库中，复制时取两边都有的列，其余列取默认值
+var configTables = []string{
+	"biz_overall_settings",
+	"biz_searchable_tables",
+	"biz_table_field_settings",
+	"biz_view_definitions",
+	"biz_ratelimit_settings",
+}
+
+// Instances 是迁移需要的插件实例操作，由 plugin_manager.PluginManager 实现
+type Instances interface {
+	// InstanceForBiz 返回绑定到业务组的插件实例及其是否在运行，没有时返回空字符串
+	InstanceForBiz(bizName string) (string, bool, error)
+	CreateInstance(displayName, pluginID, version, bizName, mode string, processOptions domain.PluginProcessOptions, dependsOn []string) (string, error)
+}
+
+// Options 是一次迁移的参数
+type Options struct {
+	// LegacyDir 是旧版单体部署的 instance 目录，其下每个含有 .db 文件的子目录是一个业务组，auth.db 保存业务组配置
+	LegacyDir string
+	// PluginID 是为各业务组创建实例所用的插件
+	PluginID string
+	// Mode 是实例的运行方式，默认为嵌入式 (与旧版单体的运行方式一致)；独立进程模式需指定 Version
+	Mode    string
+	Version string
+	// DryRun 为 true 时只列出将要迁移的业务组，不做任何修改
+	DryRun bool
+}
+
+// Service 把旧版 (v0.2.x) 单体部署迁移到插件架构：旧版由内置的数据库管理器直接读取 instance/<业务组>/*.db，
+// 新版改由绑定到业务组的插件实例提供数据。迁移为每个业务组复制库文件与配置、创建插件实例，并逐一核对
+// 配置中的表与字段在库文件中存在，使已有部署升级后无需重新设置
+type Service struct {
+	db          *sql.DB
+	config      port.QueryAdminConfigService
+	instances   Instances
+	instanceDir string
+	now         func() time.Time
+}
+
+// NewService 创建一个新的旧版迁移服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, instances Instances, instanceDir string) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("legacymigrate.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("legacymigrate.Service 需要有效的配置服务")
+	}
+	if instances == nil {
+		return nil, errors.New("legacymigrate.Service 需要插件管理器")
+	}
+	if instanceDir == "" {
+		return nil, errors.New("legacymigrate.Service 需要有效的 instance 目录")
+	}
+	return &Service{db: db, config: config, instances: instances, instanceDir: instanceDir, now: time.Now}, nil
+}
+
+// Migrate 迁移旧版目录中的所有业务组并返回核对报告。单个业务组的失败记入其 Problems，不影响其它业务组；
+// 已有配置或插件实例的业务组不会被覆盖，因此可以重复执行
+func (s *Service) Migrate(ctx context.Context, opts Options) (*domain.LegacyMigrationReport, error) {
+	if opts.LegacyDir == "" || opts.PluginID == "" {
+		return nil, fmt.Errorf("%w: 必须指定旧版目录与插件", ErrInvalidOptions)
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = plugin_manager.InstanceModeEmbedded
+	case plugin_manager.InstanceModeEmbedded:
+	case plugin_manager.InstanceModeProcess:
+		if opts.Version == "" {
+			return nil, fmt.Errorf("%w: 独立进程模式必须指定插件版本", ErrInvalidOptions)
+		}
+	default:
+		return nil, fmt.Errorf("%w: 未知的实例运行方式 '%s'", ErrInvalidOptions, opts.Mode)
+	}
+	legacyDir, err := filepath.Abs(opts.LegacyDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+	if info, err := os.Stat(legacyDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrLegacyDirNotFound, legacyDir)
+	}
+	instanceDir, err := filepath.Abs(s.instanceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	bizNames, err := discoverBizs(legacyDir)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.LegacyMigrationReport{
+		LegacyDir:   legacyDir,
+		InstanceDir: instanceDir,
+		InPlace:     sameDir(legacyDir, instanceDir),
+		DryRun:      opts.DryRun,
+		Bizs:        make([]domain.LegacyBizMigration, 0, len(bizNames)),
+		MigratedAt:  s.now(),
+	}
+	for _, bizName := range bizNames {
+		libs, err := libraries(filepath.Join(legacyDir, bizName))
+		if err != nil {
+			return nil, err
+		}
+		report.Bizs = append(report.Bizs, domain.LegacyBizMigration{
+			BizName:    bizName,
+			Libraries:  libs,
+			ConfigRows: map[string]int64{},
+			Problems:   []string{},
+			Warnings:   []string{},
+		})
+	}
+	if opts.DryRun {
+		report.OK = true
+		return report, nil
+	}
+
+	// 就地迁移时 auth.db 已由平台表初始化原地升级，无需复制配置
+	var legacyDB *sql.DB
+	if !report.InPlace {
+		authPath := filepath.Join(legacyDir, "auth.db")
+		if _, err := os.Stat(authPath); err == nil {
+			legacyDB, err = sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", authPath))
+			if err != nil {
+				return nil, fmt.Errorf("打开旧版 auth.db 失败: %w", err)
+			}
+			defer legacyDB.Close()
+		}
+	}
+
+	for i := range report.Bizs {
+		entry := &report.Bizs[i]
+		if !report.InPlace {
+			s.copyData(legacyDir, instanceDir, entry)
+			if legacyDB != nil {
+				s.copyConfig(ctx, legacyDB, entry)
+			} else {
+				entry.Warnings = append(entry.Warnings, "旧版目录中没有 auth.db，业务组配置需在管理后台重新设置")
+			}
+		}
+		s.ensureInstance(opts, entry)
+	}
+	s.config.InvalidateAllCaches()
+
+	report.OK = true
+	for i := range report.Bizs {
+		entry := &report.Bizs[i]
+		s.verify(ctx, instanceDir, entry)
+		switch {
+		case len(entry.Problems) > 0:
+			entry.Status = domain.LegacyMigrationError
+			report.OK = false
+		case len(entry.Warnings) > 0:
+			entry.Status = domain.LegacyMigrationWarning
+		default:
+			entry.Status = domain.LegacyMigrationOK
+		}
+	}
+	slog.Info("审计日志: 旧版部署迁移完成", "legacy_dir", legacyDir, "in_place", report.InPlace, "bizs", len(report.Bizs), "ok", report.OK)
+	return report, nil
+}
+
+// discoverBizs 返回旧版目录下含有 .db 文件的子目录名，按名称排序
+func discoverBizs(legacyDir string) ([]string, error) {
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取旧版目录 '%s' 失败: %w", legacyDir, err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		libs, err := libraries(filepath.Join(legacyDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(libs) > 0 {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// libraries 返回目录下的库文件名，与 SQLite 插件的扫描规则一致
+func libraries(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	if err != nil {
+		return nil, fmt.Errorf("扫描目录 '%s' 失败: %w", dir, err)
+	}
+	libs := make([]string, 0, len(files))
+	for _, f := range files {
+		libs = append(libs, filepath.Base(f))
+	}
+	sort.Strings(libs)
+	return libs, nil
+}
+
+func sameDir(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// copyData 把业务组目录下的文件复制到网关的 instance 目录。目标目录已有库文件时不覆盖
+func (s *Service) copyData(legacyDir, instanceDir string, entry *domain.LegacyBizMigration) {
+	src := filepath.Join(legacyDir, entry.BizName)
+	dst := filepath.Join(instanceDir, entry.BizName)
+	if existing, _ := libraries(dst); len(existing) > 0 {
+		entry.Warnings = append(entry.Warnings, fmt.Sprintf("目录 '%s' 中已有库文件，未从旧版目录复制", dst))
+		return
+	}
+	if err := copyDir(src, dst); err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("复制库文件失败: %v", err))
+		return
+	}
+	entry.DataCopied = true
+}
+
+// copyDir 复制目录下的普通文件 (库文件及其 -wal、-shm 等)，不递归子目录
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyConfig 在一个事务中把业务组在旧版 auth.db 中的配置复制到各配置表。网关中已有该业务组的配置时不覆盖
+func (s *Service) copyConfig(ctx context.Context, legacyDB *sql.DB, entry *domain.LegacyBizMigration) {
+	var exists int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM biz_overall_settings WHERE biz_name = ?", entry.BizName).Scan(&exists); err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("检查现有配置失败: %v", err))
+		return
+	}
+	if exists > 0 {
+		entry.Warnings = append(entry.Warnings, "网关中已有该业务组的配置，未从旧版 auth.db 复制")
+		return
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("开启事务失败: %v", err))
+		return
+	}
+	rows := make(map[string]int64)
+	for _, table := range configTables {
+		n, err := copyTable(ctx, legacyDB, tx, table, entry.BizName)
+		if err != nil {
+			_ = tx.Rollback()
+			entry.Problems = append(entry.Problems, fmt.Sprintf("复制配置表 '%s' 失败: %v", table, err))
+			return
+		}
+		if n > 0 {
+			rows[table] = n
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("提交配置失败: %v", err))
+		return
+	}
+	entry.ConfigRows = rows
+	if len(rows) == 0 {
+		entry.Warnings = append(entry.Warnings, "旧版 auth.db 中没有该业务组的配置，需在管理后台重新设置")
+	}
+}
+
+// copyTable 复制一张配置表中业务组的行，只复制两边都有的列；旧库中没有该表时返回 0
+func copyTable(ctx context.Context, legacyDB *sql.DB, tx *sql.Tx, table, bizName string) (int64, error) {
+	legacyCols, err := columns(ctx, legacyDB, table)
+	if err != nil || len(legacyCols) == 0 {
+		return 0, err
+	}
+	currentCols, err := columns(ctx, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	inLegacy := make(map[string]bool, len(legacyCols))
+	for _, c := range legacyCols {
+		inLegacy[c] = true
+	}
+	var common []string
+	for _, c := range currentCols {
+		if inLegacy[c] {
+			common = append(common, c)
+		}
+	}
+	if len(common) == 0 {
+		return 0, nil
+	}
+	quoted := make([]string, len(common))
+	for i, c := range common {
+		quoted[i] = fmt.Sprintf("%q", c)
+	}
+	colList := strings.Join(quoted, ", ")
+
+	rows, err := legacyDB.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %q WHERE biz_name = ?`, colList, table), bizName)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	insert := fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s)`, table, colList, strings.TrimSuffix(strings.Repeat("?, ", len(common)), ", "))
+	var n int64
+	for rows.Next() {
+		values := make([]interface{}, len(common))
+		ptrs := make([]interface{}, len(common))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, insert, values...); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// columns 返回表的列名，表不存在时返回空列表
+func columns(ctx context.Context, db queryer, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+	return cols, rows.Err()
+}
+
+// ensureInstance 为业务组创建插件实例，已有实例时沿用
+func (s *Service) ensureInstance(opts Options, entry *domain.LegacyBizMigration) {
+	id, _, err := s.instances.InstanceForBiz(entry.BizName)
+	if err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("查询插件实例失败: %v", err))
+		return
+	}
+	if id != "" {
+		entry.InstanceID = id
+		return
+	}
+	id, err = s.instances.CreateInstance(entry.BizName, opts.PluginID, opts.Version, entry.BizName, opts.Mode, domain.PluginProcessOptions{}, nil)
+	if err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("创建插件实例失败: %v", err))
+		return
+	}
+	entry.InstanceID = id
+	entry.InstanceCreated = true
+}
+
+// verify 核对迁移后的业务组：库文件可以打开，配置中的可查询表与字段在库文件中存在
+func (s *Service) verify(ctx context.Context, instanceDir string, entry *domain.LegacyBizMigration) {
+	bizDir := filepath.Join(instanceDir, entry.BizName)
+	libs, err := libraries(bizDir)
+	if err != nil || len(libs) == 0 {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("目录 '%s' 中没有库文件", bizDir))
+		return
+	}
+	schema := make(map[string]map[string]bool) // 表 -> 列 (各库合并)
+	for _, lib := range libs {
+		if err := readSchema(ctx, filepath.Join(bizDir, lib), schema); err != nil {
+			entry.Problems = append(entry.Problems, fmt.Sprintf("无法读取库文件 '%s': %v", lib, err))
+		}
+	}
+	entry.Tables = len(schema)
+
+	cfg, err := s.config.GetBizQueryConfig(ctx, entry.BizName)
+	if err != nil {
+		entry.Problems = append(entry.Problems, fmt.Sprintf("读取业务组配置失败: %v", err))
+		return
+	}
+	if cfg == nil {
+		entry.Warnings = append(entry.Warnings, "业务组尚无查询配置，需在管理后台设置可查询表与字段后才能检索")
+		return
+	}
+	tableNames := make([]string, 0, len(cfg.Tables))
+	for name := range cfg.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+	for _, name := range tableNames {
+		table := cfg.Tables[name]
+		if table != nil && table.SQLView != nil {
+			continue
+		}
+		cols, ok := schema[name]
+		if !ok {
+			entry.Problems = append(entry.Problems, fmt.Sprintf("配置的表 '%s' 在库文件中不存在", name))
+			continue
+		}
+		if table == nil {
+			continue
+		}
+		fieldNames := make([]string, 0, len(table.Fields))
+		for field := range table.Fields {
+			fieldNames = append(fieldNames, field)
+		}
+		sort.Strings(fieldNames)
+		for _, field := range fieldNames {
+			if !cols[field] {
+				entry.Problems = append(entry.Problems, fmt.Sprintf("配置的字段 '%s.%s' 在库文件中不存在", name, field))
+			}
+		}
+	}
+	if _, ok := cfg.Tables[cfg.DefaultQueryTable]; cfg.DefaultQueryTable != "" && !ok {
+		entry.Warnings = append(entry.Warnings, fmt.Sprintf("默认查询表 '%s' 不是可查询表", cfg.DefaultQueryTable))
+	}
+}
+
+// readSchema 以只读方式打开库文件，把其中的表与列合并到 schema
+func readSchema(ctx context.Context, path string, schema map[string]map[string]bool) error {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(ctx, `SELECT m.name, p.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return err
+		}
+		if schema[table] == nil {
+			schema[table] = make(map[string]bool)
+		}
+		schema[table][column] = true
+	}
+	return rows.Err()
+}
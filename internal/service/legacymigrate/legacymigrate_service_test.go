@@ -0,0 +1,212 @@
+// file: internal/service/legacymigrate/legacymigrate_service_test.go
+package legacymigrate
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type fakeInstances struct {
+	bound   map[string]string
+	created []string
+	fail    bool
+}
+
+func (f *fakeInstances) InstanceForBiz(bizName string) (string, bool, error) {
+	return f.bound[bizName], f.bound[bizName] != "", nil
+}
+
+func (f *fakeInstances) CreateInstance(displayName, pluginID, version, bizName, mode string, _ domain.PluginProcessOptions, _ []string) (string, error) {
+	if f.fail {
+		return "", errors.New("boom")
+	}
+	id := fmt.Sprintf("%s-%s-%s", pluginID, mode, bizName)
+	if f.bound == nil {
+		f.bound = make(map[string]string)
+	}
+	f.bound[bizName] = id
+	f.created = append(f.created, bizName)
+	return id, nil
+}
+
+func execAll(t *testing.T, path string, stmts ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+path)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range stmts {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+}
+
+// newLegacyDir 构造一个旧版部署目录：两个业务组的库文件，以及缺少新版列的 auth.db
+func newLegacyDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "archive"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "letters"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+	execAll(t, filepath.Join(dir, "archive", "main.db"),
+		`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, author TEXT)`,
+		`INSERT INTO books (title, author) VALUES ('史记', '司马迁')`)
+	execAll(t, filepath.Join(dir, "letters", "main.db"),
+		`CREATE TABLE letters (id INTEGER PRIMARY KEY, body TEXT)`)
+	execAll(t, filepath.Join(dir, "auth.db"),
+		`CREATE TABLE biz_overall_settings (biz_name TEXT PRIMARY KEY, is_publicly_searchable BOOLEAN DEFAULT TRUE NOT NULL, default_query_table TEXT)`,
+		`CREATE TABLE biz_searchable_tables (biz_name TEXT NOT NULL, table_name TEXT NOT NULL, is_searchable BOOLEAN DEFAULT TRUE NOT NULL, PRIMARY KEY (biz_name, table_name))`,
+		`CREATE TABLE biz_table_field_settings (biz_name TEXT NOT NULL, table_name TEXT NOT NULL, field_name TEXT NOT NULL, is_searchable BOOLEAN, is_returnable BOOLEAN, data_type TEXT, PRIMARY KEY (biz_name, table_name, field_name))`,
+		`INSERT INTO biz_overall_settings VALUES ('archive', 1, 'books')`,
+		`INSERT INTO biz_searchable_tables VALUES ('archive', 'books', 1)`,
+		`INSERT INTO biz_table_field_settings VALUES ('archive', 'books', 'title', 1, 1, 'string')`,
+		`INSERT INTO biz_table_field_settings VALUES ('archive', 'books', 'author', 1, 1, 'string')`)
+	return dir
+}
+
+func newService(t *testing.T, instances Instances) (*Service, *admin_config.AdminConfigServiceImpl, string) {
+	t.Helper()
+	root := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(root, "auth.db"))
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	instanceDir := filepath.Join(root, "instance")
+	svc, err := NewService(db, cfg, instances, instanceDir)
+	require.NoError(t, err)
+	return svc, cfg, instanceDir
+}
+
+func TestNewServiceRequiresDependencies(t *testing.T) {
+	_, err := NewService(nil, nil, nil, "")
+	assert.Error(t, err)
+}
+
+func TestMigrateOptions(t *testing.T) {
+	svc, _, _ := newService(t, &fakeInstances{})
+	ctx := context.Background()
+
+	_, err := svc.Migrate(ctx, Options{PluginID: "p"})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = svc.Migrate(ctx, Options{LegacyDir: t.TempDir(), PluginID: "p", Mode: "process"})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = svc.Migrate(ctx, Options{LegacyDir: t.TempDir(), PluginID: "p", Mode: "remote"})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = svc.Migrate(ctx, Options{LegacyDir: filepath.Join(t.TempDir(), "missing"), PluginID: "p"})
+	assert.ErrorIs(t, err, ErrLegacyDirNotFound)
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	instances := &fakeInstances{}
+	svc, _, instanceDir := newService(t, instances)
+
+	report, err := svc.Migrate(context.Background(), Options{LegacyDir: newLegacyDir(t), PluginID: "p", DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+	require.Len(t, report.Bizs, 2)
+	assert.Equal(t, "archive", report.Bizs[0].BizName)
+	assert.Equal(t, []string{"main.db"}, report.Bizs[0].Libraries)
+	assert.Empty(t, instances.created)
+	_, err = os.Stat(instanceDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMigrateCopiesDataConfigAndCreatesInstances(t *testing.T) {
+	instances := &fakeInstances{bound: map[string]string{"letters": "existing"}}
+	svc, cfg, instanceDir := newService(t, instances)
+	ctx := context.Background()
+
+	report, err := svc.Migrate(ctx, Options{LegacyDir: newLegacyDir(t), PluginID: "p"})
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+	assert.False(t, report.InPlace)
+	require.Len(t, report.Bizs, 2)
+
+	archive := report.Bizs[0]
+	assert.Equal(t, domain.LegacyMigrationOK, archive.Status, archive.Problems)
+	assert.True(t, archive.DataCopied)
+	assert.True(t, archive.InstanceCreated)
+	assert.Equal(t, "p-embedded-archive", archive.InstanceID)
+	assert.Equal(t, int64(1), archive.ConfigRows["biz_overall_settings"])
+	assert.Equal(t, int64(2), archive.ConfigRows["biz_table_field_settings"])
+	assert.Equal(t, 1, archive.Tables)
+	assert.FileExists(t, filepath.Join(instanceDir, "archive", "main.db"))
+
+	bizConfig, err := cfg.GetBizQueryConfig(ctx, "archive")
+	require.NoError(t, err)
+	require.NotNil(t, bizConfig)
+	assert.Equal(t, "books", bizConfig.DefaultQueryTable)
+	assert.Contains(t, bizConfig.Tables["books"].Fields, "title")
+
+	// letters 已绑定实例且旧库中没有配置
+	letters := report.Bizs[1]
+	assert.Equal(t, domain.LegacyMigrationWarning, letters.Status)
+	assert.False(t, letters.InstanceCreated)
+	assert.Equal(t, "existing", letters.InstanceID)
+	assert.NotEmpty(t, letters.Warnings)
+	assert.Equal(t, []string{"archive"}, instances.created)
+}
+
+func TestMigrateIsRepeatable(t *testing.T) {
+	instances := &fakeInstances{}
+	svc, _, _ := newService(t, instances)
+	ctx := context.Background()
+	legacyDir := newLegacyDir(t)
+
+	_, err := svc.Migrate(ctx, Options{LegacyDir: legacyDir, PluginID: "p"})
+	require.NoError(t, err)
+	report, err := svc.Migrate(ctx, Options{LegacyDir: legacyDir, PluginID: "p"})
+	require.NoError(t, err)
+	assert.True(t, report.OK)
+
+	archive := report.Bizs[0]
+	assert.False(t, archive.DataCopied)
+	assert.False(t, archive.InstanceCreated)
+	assert.Empty(t, archive.ConfigRows)
+	assert.Equal(t, domain.LegacyMigrationWarning, archive.Status)
+	assert.Len(t, instances.created, 2)
+}
+
+func TestMigrateInPlaceReportsMissingFields(t *testing.T) {
+	svc, cfg, instanceDir := newService(t, &fakeInstances{})
+	ctx := context.Background()
+	require.NoError(t, os.MkdirAll(filepath.Join(instanceDir, "archive"), 0755))
+	execAll(t, filepath.Join(instanceDir, "archive", "main.db"), `CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT)`)
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "archive", domain.BizOverallSettings{}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "archive", []string{"books", "missing"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "archive", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true, DataType: "string"},
+		{FieldName: "gone", IsSearchable: true, IsReturnable: true, DataType: "string"},
+	}))
+
+	report, err := svc.Migrate(ctx, Options{LegacyDir: instanceDir, PluginID: "p"})
+	require.NoError(t, err)
+	assert.True(t, report.InPlace)
+	assert.False(t, report.OK)
+	require.Len(t, report.Bizs, 1)
+	archive := report.Bizs[0]
+	assert.Equal(t, domain.LegacyMigrationError, archive.Status)
+	assert.False(t, archive.DataCopied)
+	assert.True(t, archive.InstanceCreated)
+	assert.Len(t, archive.Problems, 2)
+}
+
+func TestMigrateInstanceFailureIsProblem(t *testing.T) {
+	svc, _, _ := newService(t, &fakeInstances{fail: true})
+
+	report, err := svc.Migrate(context.Background(), Options{LegacyDir: newLegacyDir(t), PluginID: "p"})
+	require.NoError(t, err)
+	assert.False(t, report.OK)
+	assert.Equal(t, domain.LegacyMigrationError, report.Bizs[0].Status)
+}
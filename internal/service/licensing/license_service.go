@@ -0,0 +1,217 @@
+// Package licensing file: internal/service/licensing/license_service.go
+package licensing
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidLicense 表示授权码格式错误、签名不匹配、已过期，或插件不需要授权
+	ErrInvalidLicense = errors.New("无效的授权")
+	// ErrNotFound 表示插件没有录入授权
+	ErrNotFound = errors.New("授权不存在")
+)
+
+// Catalog 提供插件清单，由 *plugin_manager.PluginManager 实现
+type Catalog interface {
+	GetAvailablePlugins() []domain.PluginManifest
+}
+
+// Service 保存管理员录入的商业插件授权码，并用插件清单中声明的发行方公钥离线校验。
+// 授权码的格式为 base64url(声明 JSON) + "." + base64url(Ed25519 签名)，签名覆盖声明 JSON 的原始字节
+type Service struct {
+	db      *sql.DB
+	catalog Catalog
+	now     func() time.Time
+}
+
+// NewService 创建一个新的授权服务实例
+func NewService(db *sql.DB, catalog Catalog) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("licensing.Service 需要一个有效的数据库连接")
+	}
+	if catalog == nil {
+		return nil, errors.New("licensing.Service 需要插件目录")
+	}
+	return &Service{db: db, catalog: catalog, now: time.Now}, nil
+}
+
+// Issue 用发行方私钥签发授权码，供插件发行方的签发工具与测试使用
+func Issue(priv ed25519.PrivateKey, claims domain.LicenseClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("序列化授权声明失败: %w", err)
+	}
+	sig := ed25519.Sign(priv, payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Put 校验并保存授权码，同一插件已有的授权会被替换
+func (s *Service) Put(ctx context.Context, key string, userID int64) (*domain.License, error) {
+	key = strings.TrimSpace(key)
+	claims, payload, sig, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(claims, payload, sig); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	createdBy := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO plugin_licenses (plugin_id, license_key, created_by, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO UPDATE SET license_key = excluded.license_key, created_by = excluded.created_by, created_at = excluded.created_at`,
+		claims.PluginID, key, createdBy, now)
+	if err != nil {
+		return nil, fmt.Errorf("保存授权失败: %w", err)
+	}
+	return &domain.License{
+		LicenseClaims: *claims,
+		Fingerprint:   fingerprint(key),
+		Status:        domain.LicenseStatusValid,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}, nil
+}
+
+// List 返回所有授权，并按当前的插件清单与时间重新校验其状态
+func (s *Service) List(ctx context.Context) ([]domain.License, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT plugin_id, license_key, COALESCE(created_by, 0), created_at FROM plugin_licenses`)
+	if err != nil {
+		return nil, fmt.Errorf("查询授权失败: %w", err)
+	}
+	defer rows.Close()
+	list := []domain.License{}
+	for rows.Next() {
+		var pluginID, key string
+		var lic domain.License
+		if err := rows.Scan(&pluginID, &key, &lic.CreatedBy, &lic.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取授权失败: %w", err)
+		}
+		lic.PluginID = pluginID
+		lic.Fingerprint = fingerprint(key)
+		lic.Status = domain.LicenseStatusValid
+		claims, payload, sig, err := parseKey(key)
+		if err == nil {
+			lic.LicenseClaims = *claims
+			err = s.verify(claims, payload, sig)
+		}
+		if err != nil {
+			lic.Status, lic.Error = domain.LicenseStatusInvalid, err.Error()
+			if errors.Is(err, errExpired) {
+				lic.Status = domain.LicenseStatusExpired
+			}
+		}
+		list = append(list, lic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取授权失败: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PluginID < list[j].PluginID })
+	return list, nil
+}
+
+// Delete 删除插件的授权。已在运行的实例不受影响，下次启动时被拒绝
+func (s *Service) Delete(ctx context.Context, pluginID string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM plugin_licenses WHERE plugin_id = ?`, pluginID)
+	if err != nil {
+		return fmt.Errorf("删除授权失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, pluginID)
+	}
+	return nil
+}
+
+// Entitlement 返回插件启动时使用的授权信息，实现 plugin_manager.LicenseChecker
+func (s *Service) Entitlement(pluginID string, req domain.LicenseRequirement) (*domain.Entitlement, error) {
+	var key string
+	err := s.db.QueryRow(`SELECT license_key FROM plugin_licenses WHERE plugin_id = ?`, pluginID).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: 尚未录入插件 '%s' 的授权", ErrNotFound, pluginID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询授权失败: %w", err)
+	}
+	claims, payload, sig, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if claims.PluginID != pluginID {
+		return nil, fmt.Errorf("%w: 授权属于插件 '%s'", ErrInvalidLicense, claims.PluginID)
+	}
+	if err := s.check(claims, payload, sig, req.PublicKey); err != nil {
+		return nil, err
+	}
+	return &domain.Entitlement{LicenseClaims: *claims, Key: key}, nil
+}
+
+// errExpired 用于区分过期与其它校验失败
+var errExpired = fmt.Errorf("%w: 授权已过期", ErrInvalidLicense)
+
+// verify 用插件目录中的公钥校验授权
+func (s *Service) verify(claims *domain.LicenseClaims, payload, sig []byte) error {
+	for _, manifest := range s.catalog.GetAvailablePlugins() {
+		if manifest.ID != claims.PluginID {
+			continue
+		}
+		if manifest.License == nil {
+			return fmt.Errorf("%w: 插件 '%s' 不需要授权", ErrInvalidLicense, claims.PluginID)
+		}
+		return s.check(claims, payload, sig, manifest.License.PublicKey)
+	}
+	return fmt.Errorf("%w: 插件 '%s' 不在可用插件目录中，无法校验授权", ErrInvalidLicense, claims.PluginID)
+}
+
+// check 校验签名与有效期
+func (s *Service) check(claims *domain.LicenseClaims, payload, sig []byte, publicKey string) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: 插件 '%s' 清单中的授权公钥无效", ErrInvalidLicense, claims.PluginID)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), payload, sig) {
+		return fmt.Errorf("%w: 签名不匹配", ErrInvalidLicense)
+	}
+	if claims.ExpiresAt != nil && !s.now().Before(*claims.ExpiresAt) {
+		return errExpired
+	}
+	return nil
+}
+
+// parseKey 拆分授权码并解析声明，不校验签名
+func parseKey(key string) (*domain.LicenseClaims, []byte, []byte, error) {
+	encPayload, encSig, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: 授权码格式错误", ErrInvalidLicense)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: 授权码格式错误", ErrInvalidLicense)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: 授权码格式错误", ErrInvalidLicense)
+	}
+	var claims domain.LicenseClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.PluginID == "" {
+		return nil, nil, nil, fmt.Errorf("%w: 授权声明无法解析或缺少 plugin_id", ErrInvalidLicense)
+	}
+	return &claims, payload, sig, nil
+}
+
+func fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,99 @@
+// file: internal/service/licensing/license_service_test.go
+package licensing
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCatalog []domain.PluginManifest
+
+func (c staticCatalog) GetAvailablePlugins() []domain.PluginManifest { return c }
+
+func newTestService(t *testing.T) (*Service, ed25519.PrivateKey) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	catalog := staticCatalog{
+		{ID: "pro-ocr", License: &domain.LicenseRequirement{PublicKey: base64.StdEncoding.EncodeToString(pub)}},
+		{ID: "sqlite"},
+	}
+	svc, err := NewService(db, catalog)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC) }
+	return svc, priv
+}
+
+func issue(t *testing.T, priv ed25519.PrivateKey, claims domain.LicenseClaims) string {
+	t.Helper()
+	key, err := Issue(priv, claims)
+	require.NoError(t, err)
+	return key
+}
+
+func TestPutAndEntitlement(t *testing.T) {
+	svc, priv := newTestService(t)
+	ctx := context.Background()
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := issue(t, priv, domain.LicenseClaims{PluginID: "pro-ocr", Licensee: "市档案馆", Features: []string{"handwriting"}, ExpiresAt: &expires})
+
+	req := domain.LicenseRequirement{PublicKey: base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))}
+	_, err := svc.Entitlement("pro-ocr", req)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	lic, err := svc.Put(ctx, " "+key+"\n", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "市档案馆", lic.Licensee)
+	assert.Equal(t, domain.LicenseStatusValid, lic.Status)
+	assert.Len(t, lic.Fingerprint, 16)
+
+	ent, err := svc.Entitlement("pro-ocr", req)
+	require.NoError(t, err)
+	assert.Equal(t, key, ent.Key)
+	assert.Equal(t, []string{"handwriting"}, ent.Features)
+
+	// 过期后不再给出授权，列表中标记为 expired
+	svc.now = func() time.Time { return expires }
+	_, err = svc.Entitlement("pro-ocr", req)
+	assert.ErrorIs(t, err, ErrInvalidLicense)
+	list, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, domain.LicenseStatusExpired, list[0].Status)
+
+	require.NoError(t, svc.Delete(ctx, "pro-ocr"))
+	assert.ErrorIs(t, svc.Delete(ctx, "pro-ocr"), ErrNotFound)
+}
+
+func TestPut_Rejects(t *testing.T) {
+	svc, priv := newTestService(t)
+	ctx := context.Background()
+	_, otherPriv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	past := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]string{
+		"格式错误":    "not-a-license",
+		"签名不匹配":   issue(t, otherPriv, domain.LicenseClaims{PluginID: "pro-ocr"}),
+		"已过期":     issue(t, priv, domain.LicenseClaims{PluginID: "pro-ocr", ExpiresAt: &past}),
+		"插件不需要授权": issue(t, priv, domain.LicenseClaims{PluginID: "sqlite"}),
+		"插件不在目录中": issue(t, priv, domain.LicenseClaims{PluginID: "unknown"}),
+	}
+	for name, key := range cases {
+		_, err := svc.Put(ctx, key, 0)
+		assert.ErrorIs(t, err, ErrInvalidLicense, name)
+	}
+	list, err := svc.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
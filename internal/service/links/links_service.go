@@ -0,0 +1,388 @@
+// Package links file: internal/service/links/links_service.go
+package links
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultRelation 是未指定类型时关联的类型
+	defaultRelation  = "related"
+	maxRelationRunes = 100
+	maxNoteRunes     = 1000
+	// maxExpandIDs 是一次展开关联的记录数上限，与单页的最大条数相当
+	maxExpandIDs = 1000
+)
+
+var (
+	// ErrLinkNotFound 表示关联不存在
+	ErrLinkNotFound = errors.New("记录关联不存在")
+	// ErrInvalidLink 表示关联的内容不合法，或某一端的记录不存在
+	ErrInvalidLink = errors.New("无效的记录关联")
+	// ErrDuplicateLink 表示两条记录之间已存在同类型的关联
+	ErrDuplicateLink = errors.New("记录之间已存在同类型的关联")
+	// ErrRecordLinked 表示要删除的记录仍被关联引用
+	ErrRecordLinked = errors.New("记录仍被关联引用，不能删除")
+)
+
+// Service 维护业务组记录之间的关联注册表 (如人名规范档与引用它的文书)：
+// 创建时确认两端的记录存在，删除记录前检查引用完整性，并把关联展开到查询结果中
+type Service struct {
+	db            *sql.DB
+	registry      map[string]port.DataSource
+	configService port.QueryAdminConfigService
+	now           func() time.Time
+}
+
+// NewService 创建一个新的记录关联服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, configService port.QueryAdminConfigService) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("links.Service 需要一个有效的数据库连接")
+	}
+	if registry == nil {
+		return nil, errors.New("links.Service 需要数据源注册表")
+	}
+	if configService == nil {
+		return nil, errors.New("links.Service 需要有效的配置服务")
+	}
+	return &Service{db: db, registry: registry, configService: configService, now: time.Now}, nil
+}
+
+// Create 创建一条关联，两端的记录必须存在
+func (s *Service) Create(ctx context.Context, link domain.RecordLink, userID int64) (*domain.RecordLink, error) {
+	for _, part := range []*string{&link.SourceBiz, &link.SourceTable, &link.SourceID, &link.TargetBiz, &link.TargetTable, &link.TargetID} {
+		*part = strings.TrimSpace(*part)
+		if *part == "" {
+			return nil, fmt.Errorf("%w: 源端与目标端的业务组、表与记录主键均不能为空", ErrInvalidLink)
+		}
+	}
+	if link.SourceBiz == link.TargetBiz && link.SourceTable == link.TargetTable && link.SourceID == link.TargetID {
+		return nil, fmt.Errorf("%w: 记录不能关联自身", ErrInvalidLink)
+	}
+	link.Relation = strings.TrimSpace(link.Relation)
+	if link.Relation == "" {
+		link.Relation = defaultRelation
+	}
+	link.Note = strings.TrimSpace(link.Note)
+	if utf8.RuneCountInString(link.Relation) > maxRelationRunes || utf8.RuneCountInString(link.Note) > maxNoteRunes {
+		return nil, fmt.Errorf("%w: relation 不能超过 %d 个字符，note 不能超过 %d 个字符", ErrInvalidLink, maxRelationRunes, maxNoteRunes)
+	}
+	if err := s.requireRecord(ctx, link.SourceBiz, link.SourceTable, link.SourceID); err != nil {
+		return nil, err
+	}
+	if err := s.requireRecord(ctx, link.TargetBiz, link.TargetTable, link.TargetID); err != nil {
+		return nil, err
+	}
+
+	link.CreatedBy, link.CreatedAt = userID, s.now().UTC()
+	res, err := s.db.ExecContext(ctx, `INSERT INTO record_links
+		(source_biz, source_table, source_id, target_biz, target_table, target_id, relation, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		link.SourceBiz, link.SourceTable, link.SourceID, link.TargetBiz, link.TargetTable, link.TargetID,
+		link.Relation, link.Note, link.CreatedBy, link.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("保存记录关联失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrDuplicateLink
+	}
+	if link.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Get 返回指定的关联
+func (s *Service) Get(ctx context.Context, id int64) (*domain.RecordLink, error) {
+	found, err := s.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ErrLinkNotFound
+	}
+	return &found[0], nil
+}
+
+// List 返回以指定记录为任一端的关联；recordID 为空时返回以该表中任意记录为任一端的关联，
+// tableName 也为空时返回涉及该业务组的全部关联
+func (s *Service) List(ctx context.Context, bizName, tableName, recordID string) ([]domain.RecordLink, error) {
+	source, target := []string{"source_biz = ?"}, []string{"target_biz = ?"}
+	args := []interface{}{bizName}
+	if tableName != "" {
+		source, target = append(source, "source_table = ?"), append(target, "target_table = ?")
+		args = append(args, tableName)
+		if recordID != "" {
+			source, target = append(source, "source_id = ?"), append(target, "target_id = ?")
+			args = append(args, recordID)
+		}
+	}
+	return s.query(ctx, fmt.Sprintf(`WHERE (%s) OR (%s)`, strings.Join(source, " AND "), strings.Join(target, " AND ")),
+		append(args, args...)...)
+}
+
+// Delete 删除指定的关联
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM record_links WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除记录关联失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// Expand 返回各记录的关联，以记录主键为键。另一端所在的业务组已暂停服务时不展开；
+// anonymous 为 true 时也不展开另一端不允许匿名检索的关联，避免泄露非公开业务组中的记录位置
+func (s *Service) Expand(ctx context.Context, bizName, tableName string, recordIDs []string, anonymous bool) (map[string][]domain.LinkedRecord, error) {
+	expanded := make(map[string][]domain.LinkedRecord)
+	if len(recordIDs) == 0 {
+		return expanded, nil
+	}
+	if len(recordIDs) > maxExpandIDs {
+		recordIDs = recordIDs[:maxExpandIDs]
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(recordIDs)), ",")
+	args := []interface{}{bizName, tableName}
+	for _, id := range recordIDs {
+		args = append(args, id)
+	}
+	found, err := s.query(ctx, fmt.Sprintf(`WHERE (source_biz = ? AND source_table = ? AND source_id IN (%[1]s))
+		OR (target_biz = ? AND target_table = ? AND target_id IN (%[1]s))`, placeholders), append(args, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[string]bool)
+	isVisible := func(biz string) (bool, error) {
+		if v, ok := visible[biz]; ok {
+			return v, nil
+		}
+		cfg, err := s.configService.GetBizQueryConfig(ctx, biz)
+		if err != nil {
+			return false, err
+		}
+		v := cfg != nil && cfg.Enabled && (!anonymous || cfg.IsPubliclySearchable)
+		visible[biz] = v
+		return v, nil
+	}
+	for _, l := range found {
+		// 同一张表中的记录互相关联时，两个方向都需要展开
+		if l.SourceBiz == bizName && l.SourceTable == tableName {
+			if ok, err := isVisible(l.TargetBiz); err != nil {
+				return nil, err
+			} else if ok {
+				expanded[l.SourceID] = append(expanded[l.SourceID], domain.LinkedRecord{LinkID: l.ID, Direction: domain.LinkDirectionOutgoing,
+					Relation: l.Relation, Biz: l.TargetBiz, Table: l.TargetTable, ID: l.TargetID})
+			}
+		}
+		if l.TargetBiz == bizName && l.TargetTable == tableName {
+			if ok, err := isVisible(l.SourceBiz); err != nil {
+				return nil, err
+			} else if ok {
+				expanded[l.TargetID] = append(expanded[l.TargetID], domain.LinkedRecord{LinkID: l.ID, Direction: domain.LinkDirectionIncoming,
+					Relation: l.Relation, Biz: l.SourceBiz, Table: l.SourceTable, ID: l.SourceID})
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// ExpandItems 把关联展开到查询结果的各行上，返回值与 items 一一对应。tableName 为空时使用业务组的默认查询表；
+// 表没有显式主键或结果中缺少主键字段的行无法定位，对应项为空
+func (s *Service) ExpandItems(ctx context.Context, bizName, tableName string, items []interface{}, anonymous bool) ([][]domain.LinkedRecord, error) {
+	if tableName == "" {
+		cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+		if err != nil {
+			return nil, err
+		}
+		if cfg != nil {
+			tableName = cfg.DefaultQueryTable
+		}
+	}
+	primaryKey, err := s.primaryKey(ctx, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(items))
+	var known []string
+	for i, item := range items {
+		if row, ok := item.(map[string]interface{}); ok && len(primaryKey) > 0 {
+			ids[i] = recordID(row, primaryKey)
+			if ids[i] != "" {
+				known = append(known, ids[i])
+			}
+		}
+	}
+	expanded, err := s.Expand(ctx, bizName, tableName, known, anonymous)
+	if err != nil {
+		return nil, err
+	}
+	aligned := make([][]domain.LinkedRecord, len(items))
+	for i, id := range ids {
+		if id != "" {
+			aligned[i] = expanded[id]
+		}
+	}
+	return aligned, nil
+}
+
+// CheckDelete 在执行 Mutate 的 delete 之前检查引用完整性：表中没有被关联的记录时直接放行；
+// 否则只允许按主键删除单条记录，且该记录不能仍被关联，需先删除相关的关联
+func (s *Service) CheckDelete(ctx context.Context, bizName, tableName string, filters []interface{}) error {
+	var linked int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_links
+		WHERE (source_biz = ? AND source_table = ?) OR (target_biz = ? AND target_table = ?)`,
+		bizName, tableName, bizName, tableName).Scan(&linked); err != nil {
+		return fmt.Errorf("查询记录关联失败: %w", err)
+	}
+	if linked == 0 {
+		return nil
+	}
+	primaryKey, err := s.primaryKey(ctx, bizName, tableName)
+	if err != nil {
+		return err
+	}
+	recordID, ok := recordIDFromFilters(filters, primaryKey)
+	if !ok {
+		return fmt.Errorf("%w: 表 '%s' 中有被关联的记录，只能按主键逐条删除", ErrRecordLinked, tableName)
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM record_links
+		WHERE (source_biz = ? AND source_table = ? AND source_id = ?) OR (target_biz = ? AND target_table = ? AND target_id = ?)`,
+		bizName, tableName, recordID, bizName, tableName, recordID).Scan(&linked); err != nil {
+		return fmt.Errorf("查询记录关联失败: %w", err)
+	}
+	if linked > 0 {
+		return fmt.Errorf("%w: 记录 '%s' 仍有 %d 条关联，请先删除这些关联", ErrRecordLinked, recordID, linked)
+	}
+	return nil
+}
+
+func (s *Service) query(ctx context.Context, where string, args ...interface{}) ([]domain.RecordLink, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, source_biz, source_table, source_id, target_biz, target_table, target_id,
+		relation, note, created_by, created_at FROM record_links `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询记录关联失败: %w", err)
+	}
+	defer rows.Close()
+	found := []domain.RecordLink{}
+	for rows.Next() {
+		var l domain.RecordLink
+		if err := rows.Scan(&l.ID, &l.SourceBiz, &l.SourceTable, &l.SourceID, &l.TargetBiz, &l.TargetTable, &l.TargetID,
+			&l.Relation, &l.Note, &l.CreatedBy, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("扫描记录关联失败: %w", err)
+		}
+		found = append(found, l)
+	}
+	return found, rows.Err()
+}
+
+// requireRecord 以按主键读取模式确认记录存在
+func (s *Service) requireRecord(ctx context.Context, bizName, tableName, recordID string) error {
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return fmt.Errorf("%w: 业务组 '%s' 不存在", ErrInvalidLink, bizName)
+	}
+	result, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: 读取记录 %s/%s/%s 失败: %v", ErrInvalidLink, bizName, tableName, recordID, err)
+	}
+	if record, _ := result.Data["record"].(map[string]interface{}); record == nil {
+		return fmt.Errorf("%w: 记录 %s/%s/%s 不存在", ErrInvalidLink, bizName, tableName, recordID)
+	}
+	return nil
+}
+
+func (s *Service) primaryKey(ctx context.Context, bizName, tableName string) ([]string, error) {
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	schema, err := dataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName, TableName: tableName})
+	if err != nil {
+		return nil, err
+	}
+	primaryKey := schema.RecordKey(tableName)
+	if len(primaryKey) == 1 && primaryKey[0] == port.RecordKeyRowid {
+		// 查询结果不含 rowid，无法从记录中取得标识
+		return nil, nil
+	}
+	return primaryKey, nil
+}
+
+// recordIDFromFilters 在 filters 恰好是对每个主键字段的一次精确匹配时，返回它们定位的记录主键
+func recordIDFromFilters(filters []interface{}, primaryKey []string) (string, bool) {
+	if len(primaryKey) == 0 || len(filters) != len(primaryKey) {
+		return "", false
+	}
+	values := make(map[string]string, len(filters))
+	for _, f := range filters {
+		filter, ok := f.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		field, _ := filter["field"].(string)
+		value, hasValue := filter["value"]
+		op, _ := filter["op"].(string)
+		fuzzy, _ := filter["fuzzy"].(bool)
+		negate, _ := filter[port.FilterNegateKey].(bool)
+		logic, _ := filter["logic"].(string)
+		if field == "" || !hasValue || value == nil || op != "" || fuzzy || negate || (logic != "" && !strings.EqualFold(logic, "and")) {
+			return "", false
+		}
+		if _, dup := values[field]; dup {
+			return "", false
+		}
+		values[field] = stringify(value)
+	}
+	parts := make([]string, 0, len(primaryKey))
+	for _, k := range primaryKey {
+		v, ok := values[k]
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, v)
+	}
+	return strings.Join(parts, port.RecordIDSeparator), true
+}
+
+// recordID 以主键值拼接记录主键，任一主键字段不在结果中或为 NULL 时返回空
+func recordID(row map[string]interface{}, primaryKey []string) string {
+	parts := make([]string, 0, len(primaryKey))
+	for _, k := range primaryKey {
+		v, ok := row[k]
+		if !ok || v == nil {
+			return ""
+		}
+		parts = append(parts, stringify(v))
+	}
+	return strings.Join(parts, port.RecordIDSeparator)
+}
+
+func stringify(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
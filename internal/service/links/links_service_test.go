@@ -0,0 +1,38 @@
+// file: internal/service/links/links_service_test.go
+package links
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordIDFromFilters(t *testing.T) {
+	filter := func(field string, value interface{}) map[string]interface{} {
+		return map[string]interface{}{"field": field, "value": value}
+	}
+	id, ok := recordIDFromFilters([]interface{}{filter("id", float64(7))}, []string{"id"})
+	assert.True(t, ok)
+	assert.Equal(t, "7", id)
+
+	id, ok = recordIDFromFilters([]interface{}{filter("vol", "b"), filter("no", float64(3))}, []string{"no", "vol"})
+	assert.True(t, ok)
+	assert.Equal(t, "3,b", id, "按主键声明顺序拼接")
+
+	fuzzy := filter("id", "7")
+	fuzzy["fuzzy"] = true
+	or := filter("id", "8")
+	or["logic"] = "OR"
+	for name, filters := range map[string][]interface{}{
+		"非主键字段":  {filter("name", "x")},
+		"模糊匹配":   {fuzzy},
+		"OR 组合":  {filter("id", "7"), or},
+		"缺少主键的列": {filter("no", "3")},
+		"空值":     {filter("id", nil)},
+	} {
+		_, ok := recordIDFromFilters(filters, []string{"id"})
+		assert.False(t, ok, name)
+	}
+	_, ok = recordIDFromFilters([]interface{}{filter("id", "7")}, nil)
+	assert.False(t, ok, "没有显式主键的表无法定位记录")
+}
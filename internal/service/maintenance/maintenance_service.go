@@ -0,0 +1,471 @@
+// Package maintenance file: internal/service/maintenance/maintenance_service.go
+package maintenance
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCheckIntervalMinutes   = 30
+	defaultIntervalHours          = 24 * 7
+	defaultFragmentationThreshold = 0.25
+	defaultWALThresholdMB         = 64
+	defaultBusyRequestsPerMinute  = 60
+	defaultRunListLimit           = 100
+	maxRunListLimit               = 1000
+	// trafficWindow 是判断业务组是否繁忙时统计请求数的时间段
+	trafficWindow = time.Minute
+)
+
+var (
+	// ErrBusy 表示业务组当前流量较大，未强制执行时不开始维护
+	ErrBusy = errors.New("业务组当前流量较大，已推迟维护")
+	// ErrRunning 表示业务组已有维护在执行
+	ErrRunning = errors.New("业务组已有维护在执行")
+	// ErrInvalidTasks 表示维护任务列表无效
+	ErrInvalidTasks = errors.New("无效的维护任务")
+)
+
+// allTasks 是支持的维护任务，也是未指定任务时执行的任务
+var allTasks = []string{domain.MaintenanceTaskAnalyze, domain.MaintenanceTaskVacuum, domain.MaintenanceTaskCheckpoint}
+
+// Options 定义库文件维护的计划与触发条件
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckIntervalMinutes 是检查各业务组是否需要维护的间隔，默认为 30 分钟
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+	// IntervalHours 是两次完整维护的最长间隔，默认为 168 小时 (一周)
+	IntervalHours int `mapstructure:"interval_hours"`
+	// FragmentationThreshold 是触发完整维护的空闲页比例，默认为 0.25
+	FragmentationThreshold float64 `mapstructure:"fragmentation_threshold"`
+	// WALThresholdMB 是只触发检查点的 WAL 文件大小，默认为 64 MB
+	WALThresholdMB int `mapstructure:"wal_threshold_mb"`
+	// BusyRequestsPerMinute 是业务组被视为繁忙的每分钟请求数，繁忙或仍有请求在执行时推迟自动维护，默认为 60
+	BusyRequestsPerMinute int `mapstructure:"busy_requests_per_minute"`
+}
+
+// traffic 是一个业务组经由网关的请求情况
+type traffic struct {
+	inFlight    int
+	windowStart time.Time
+	requests    int
+}
+
+// Service 定期检查各业务组库文件的空闲页比例与 WAL 大小，在超过阈值或距上次维护超过间隔时，
+// 经由数据源执行 ANALYZE、VACUUM 与 WAL 检查点 (见 port.MutateOpMaintenance)，并记录每次维护。
+// 由 Wrap 返回的装饰器统计各业务组的请求，业务组繁忙时推迟自动维护
+type Service struct {
+	db       *sql.DB
+	registry map[string]port.DataSource
+	opts     Options
+	now      func() time.Time
+
+	mu      sync.Mutex
+	traffic map[string]*traffic
+	running map[string]bool
+}
+
+// NewService 创建一个新的库文件维护服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("maintenance.Service 需要一个有效的数据库连接")
+	}
+	if opts.CheckIntervalMinutes < 0 || opts.IntervalHours < 0 || opts.WALThresholdMB < 0 || opts.BusyRequestsPerMinute < 0 {
+		return nil, errors.New("库文件维护的间隔与阈值不能为负数")
+	}
+	if opts.FragmentationThreshold < 0 || opts.FragmentationThreshold >= 1 {
+		return nil, fmt.Errorf("fragmentation_threshold 应在 0 到 1 之间 (不含 1): %v", opts.FragmentationThreshold)
+	}
+	if opts.CheckIntervalMinutes == 0 {
+		opts.CheckIntervalMinutes = defaultCheckIntervalMinutes
+	}
+	if opts.IntervalHours == 0 {
+		opts.IntervalHours = defaultIntervalHours
+	}
+	if opts.FragmentationThreshold == 0 {
+		opts.FragmentationThreshold = defaultFragmentationThreshold
+	}
+	if opts.WALThresholdMB == 0 {
+		opts.WALThresholdMB = defaultWALThresholdMB
+	}
+	if opts.BusyRequestsPerMinute == 0 {
+		opts.BusyRequestsPerMinute = defaultBusyRequestsPerMinute
+	}
+	return &Service{
+		db:       db,
+		registry: registry,
+		opts:     opts,
+		now:      time.Now,
+		traffic:  make(map[string]*traffic),
+		running:  make(map[string]bool),
+	}, nil
+}
+
+// Wrap 返回一个统计请求的 DataSource 装饰器，可作为插件管理器的装饰器使用
+func (s *Service) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	return &decorator{DataSource: inner, maintenance: s}
+}
+
+type decorator struct {
+	port.DataSource
+	maintenance *Service
+}
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *decorator) Unwrap() port.DataSource {
+	return d.DataSource
+}
+
+// Query 透传查询，并计入业务组的请求
+func (d *decorator) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	done := d.maintenance.begin(req.BizName)
+	defer done()
+	return d.DataSource.Query(ctx, req)
+}
+
+// Mutate 透传写操作，并计入业务组的请求；维护本身不计入
+func (d *decorator) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	done := d.maintenance.begin(req.BizName)
+	defer done()
+	return d.DataSource.Mutate(ctx, req)
+}
+
+// AdminMutate 转发管理操作，维护本身不计入业务组的请求
+func (d *decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if req.Operation == port.MutateOpMaintenance {
+		return port.AdminMutate(ctx, d.DataSource, req)
+	}
+	done := d.maintenance.begin(req.BizName)
+	defer done()
+	return port.AdminMutate(ctx, d.DataSource, req)
+}
+
+// begin 记录一次开始执行的请求，返回的函数在请求结束时调用
+func (s *Service) begin(bizName string) func() {
+	s.mu.Lock()
+	t := s.trafficLocked(bizName)
+	t.inFlight++
+	t.requests++
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		t.inFlight--
+		s.mu.Unlock()
+	}
+}
+
+// trafficLocked 返回业务组的请求情况，统计时间段已过去时重新计数。调用前必须持有 s.mu
+func (s *Service) trafficLocked(bizName string) *traffic {
+	now := s.now()
+	t, ok := s.traffic[bizName]
+	if !ok {
+		t = &traffic{windowStart: now}
+		s.traffic[bizName] = t
+	}
+	if now.Sub(t.windowStart) >= trafficWindow {
+		t.windowStart = now
+		t.requests = 0
+	}
+	return t
+}
+
+// load 返回业务组最近一分钟的请求数、正在执行的请求数，以及是否视为繁忙
+func (s *Service) load(bizName string) (requests, inFlight int, busy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.trafficLocked(bizName)
+	return t.requests, t.inFlight, t.inFlight > 0 || t.requests >= s.opts.BusyRequestsPerMinute
+}
+
+// Run 每隔 CheckIntervalMinutes 检查一次各业务组，ctx 结束时返回
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.opts.CheckIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckAll(ctx)
+		}
+	}
+}
+
+// CheckAll 检查所有业务组，对需要维护且不繁忙的业务组执行维护，返回本次执行的维护记录。
+// 数据源不支持维护的业务组被跳过；单个业务组失败时记录日志并继续
+func (s *Service) CheckAll(ctx context.Context) []domain.MaintenanceRun {
+	bizNames := make([]string, 0, len(s.registry))
+	for bizName := range s.registry {
+		bizNames = append(bizNames, bizName)
+	}
+	sort.Strings(bizNames)
+
+	runs := make([]domain.MaintenanceRun, 0)
+	for _, bizName := range bizNames {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, _, busy := s.load(bizName); busy {
+			slog.Debug("[Maintenance] 业务组繁忙，推迟维护", "biz", bizName)
+			continue
+		}
+		trigger, tasks, err := s.due(ctx, bizName)
+		if err != nil {
+			slog.Debug("[Maintenance] 无法检查业务组的库文件，已跳过", "biz", bizName, "error", err)
+			continue
+		}
+		if trigger == "" {
+			continue
+		}
+		run, err := s.execute(ctx, bizName, trigger, tasks)
+		if errors.Is(err, ErrRunning) {
+			continue
+		}
+		if err != nil {
+			slog.Error("[Maintenance] 维护业务组库文件失败", "biz", bizName, "trigger", trigger, "error", err)
+		}
+		if run != nil {
+			runs = append(runs, *run)
+		}
+	}
+	return runs
+}
+
+// due 判断业务组是否需要维护，返回触发原因与要执行的任务，不需要维护时触发原因为空
+func (s *Service) due(ctx context.Context, bizName string) (string, []string, error) {
+	libs, err := s.Inspect(ctx, bizName)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(libs) == 0 {
+		return "", nil, nil
+	}
+	last, err := s.lastSuccess(ctx, bizName)
+	if err != nil {
+		return "", nil, err
+	}
+	if last == nil || s.now().Sub(last.StartedAt) >= time.Duration(s.opts.IntervalHours)*time.Hour {
+		return domain.MaintenanceTriggerSchedule, allTasks, nil
+	}
+	walThreshold := int64(s.opts.WALThresholdMB) << 20
+	var walDue bool
+	for _, lib := range libs {
+		if lib.Fragmentation >= s.opts.FragmentationThreshold {
+			return domain.MaintenanceTriggerFragmentation, allTasks, nil
+		}
+		if lib.WALBytes >= walThreshold {
+			walDue = true
+		}
+	}
+	if walDue {
+		return domain.MaintenanceTriggerWAL, []string{domain.MaintenanceTaskCheckpoint}, nil
+	}
+	return "", nil, nil
+}
+
+// Inspect 经由数据源读取业务组各库文件的存储状况
+func (s *Service) Inspect(ctx context.Context, bizName string) ([]domain.MaintenanceLibStats, error) {
+	data, err := s.mutate(ctx, bizName, map[string]interface{}{"step": "inspect"})
+	if err != nil {
+		return nil, err
+	}
+	libs := make([]domain.MaintenanceLibStats, 0)
+	if err := decode(data["libs"], &libs); err != nil {
+		return nil, fmt.Errorf("解析库文件状况失败: %w", err)
+	}
+	return libs, nil
+}
+
+// Status 返回业务组当前的存储状况、流量与最近一次维护
+func (s *Service) Status(ctx context.Context, bizName string) (*domain.MaintenanceStatus, error) {
+	libs, err := s.Inspect(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.MaintenanceStatus{BizName: bizName, Libs: libs}
+	status.RequestsPerMinute, status.InFlight, status.Busy = s.load(bizName)
+	s.mu.Lock()
+	status.Running = s.running[bizName]
+	s.mu.Unlock()
+	runs, err := s.ListRuns(ctx, bizName, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		status.LastRun = &runs[0]
+	}
+	return status, nil
+}
+
+// RunNow 立即维护业务组，tasks 为空时执行全部任务。业务组繁忙时返回 ErrBusy，除非 force 为 true
+func (s *Service) RunNow(ctx context.Context, bizName string, tasks []string, force bool) (*domain.MaintenanceRun, error) {
+	if _, exists := s.registry[bizName]; !exists {
+		return nil, port.ErrBizNotFound
+	}
+	if len(tasks) == 0 {
+		tasks = allTasks
+	}
+	for _, task := range tasks {
+		if !slices.Contains(allTasks, task) {
+			return nil, fmt.Errorf("%w: 不支持的任务 '%s'", ErrInvalidTasks, task)
+		}
+	}
+	if !force {
+		if _, _, busy := s.load(bizName); busy {
+			return nil, ErrBusy
+		}
+	}
+	return s.execute(ctx, bizName, domain.MaintenanceTriggerManual, tasks)
+}
+
+// execute 执行一次维护并保存记录。维护失败时同样保存记录，并返回记录与错误
+func (s *Service) execute(ctx context.Context, bizName, trigger string, tasks []string) (*domain.MaintenanceRun, error) {
+	s.mu.Lock()
+	if s.running[bizName] {
+		s.mu.Unlock()
+		return nil, ErrRunning
+	}
+	s.running[bizName] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, bizName)
+		s.mu.Unlock()
+	}()
+
+	run := &domain.MaintenanceRun{
+		BizName:   bizName,
+		Trigger:   trigger,
+		Tasks:     tasks,
+		Status:    domain.MaintenanceStatusSuccess,
+		Libs:      make([]domain.MaintenanceLibResult, 0),
+		StartedAt: s.now().UTC(),
+	}
+	taskList := make([]interface{}, len(tasks))
+	for i, t := range tasks {
+		taskList[i] = t
+	}
+	data, runErr := s.mutate(ctx, bizName, map[string]interface{}{"step": "run", "tasks": taskList})
+	if runErr == nil {
+		if err := decode(data["libs"], &run.Libs); err != nil {
+			runErr = fmt.Errorf("解析维护结果失败: %w", err)
+		}
+	}
+	if runErr != nil {
+		run.Status = domain.MaintenanceStatusFailed
+		run.Error = runErr.Error()
+	}
+	for _, lib := range run.Libs {
+		run.ReclaimedBytes += lib.Before.FileBytes + lib.Before.WALBytes - lib.After.FileBytes - lib.After.WALBytes
+	}
+	run.FinishedAt = s.now().UTC()
+
+	if err := s.saveRun(run); err != nil {
+		return nil, err
+	}
+	slog.Info("[Maintenance] 业务组库文件维护完成", "biz", bizName, "trigger", trigger, "status", run.Status, "reclaimed_bytes", run.ReclaimedBytes)
+	return run, runErr
+}
+
+func (s *Service) mutate(ctx context.Context, bizName string, payload map[string]interface{}) (map[string]interface{}, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	res, err := port.AdminMutate(ctx, dataSource, port.MutateRequest{BizName: bizName, Operation: port.MutateOpMaintenance, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// saveRun 保存维护记录。维护的 ctx 可能已被取消，记录仍需写入
+func (s *Service) saveRun(run *domain.MaintenanceRun) error {
+	tasks, err := json.Marshal(run.Tasks)
+	if err != nil {
+		return err
+	}
+	libs, err := json.Marshal(run.Libs)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`
+		INSERT INTO maintenance_runs (biz_name, trigger, tasks, status, libs, reclaimed_bytes, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.BizName, run.Trigger, string(tasks), run.Status, string(libs), run.ReclaimedBytes, run.Error, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("保存维护记录失败: %w", err)
+	}
+	run.ID, _ = res.LastInsertId()
+	return nil
+}
+
+// lastSuccess 返回业务组最近一次成功的完整维护 (含 VACUUM)，从未完整维护时返回 nil
+func (s *Service) lastSuccess(ctx context.Context, bizName string) (*domain.MaintenanceRun, error) {
+	runs, err := s.queryRuns(ctx, ` WHERE biz_name = ? AND status = ? AND tasks LIKE ?`, []interface{}{bizName, domain.MaintenanceStatusSuccess, `%"` + domain.MaintenanceTaskVacuum + `"%`}, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// ListRuns 按时间倒序列出维护记录，bizName 不为空时只列出该业务组，limit 为 0 时返回 100 条
+func (s *Service) ListRuns(ctx context.Context, bizName string, limit int) ([]domain.MaintenanceRun, error) {
+	if limit <= 0 {
+		limit = defaultRunListLimit
+	}
+	limit = min(limit, maxRunListLimit)
+	if bizName != "" {
+		return s.queryRuns(ctx, ` WHERE biz_name = ?`, []interface{}{bizName}, limit)
+	}
+	return s.queryRuns(ctx, ``, nil, limit)
+}
+
+func (s *Service) queryRuns(ctx context.Context, where string, args []interface{}, limit int) ([]domain.MaintenanceRun, error) {
+	query := `SELECT id, biz_name, trigger, tasks, status, libs, reclaimed_bytes, error, started_at, finished_at FROM maintenance_runs` +
+		where + ` ORDER BY id DESC LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("查询维护记录失败: %w", err)
+	}
+	defer rows.Close()
+	list := make([]domain.MaintenanceRun, 0)
+	for rows.Next() {
+		var r domain.MaintenanceRun
+		var tasks, libs string
+		if err := rows.Scan(&r.ID, &r.BizName, &r.Trigger, &tasks, &r.Status, &libs, &r.ReclaimedBytes, &r.Error, &r.StartedAt, &r.FinishedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tasks), &r.Tasks); err != nil {
+			return nil, fmt.Errorf("解析维护记录失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(libs), &r.Libs); err != nil {
+			return nil, fmt.Errorf("解析维护记录失败: %w", err)
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// decode 把数据源返回的通用结构转换为 dest，经由 gRPC 插件时数值均为 float64
+func decode(raw interface{}, dest interface{}) error {
+	if raw == nil {
+		return nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dest)
+}
@@ -0,0 +1,116 @@
+// file: internal/service/maintenance/maintenance_service_test.go
+package maintenance
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService 建立一个只有 library 业务组的实例，其库文件中删除过大量记录
+func newTestService(t *testing.T, opts Options, now *time.Time) (*Service, port.DataSource) {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "library"), 0o755))
+	libDB, err := sql.Open("sqlite", filepath.Join(root, "library", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 2000)
+		INSERT INTO books (id, title) SELECT i, printf('%0500d', i) FROM n;
+		DELETE FROM books WHERE id > 10;`)
+	require.NoError(t, err)
+	require.NoError(t, libDB.Close())
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	registry := map[string]port.DataSource{}
+	svc, err := NewService(sysDB, registry, opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return *now }
+	registry["library"] = svc.Wrap("library", manager)
+	return svc, registry["library"]
+}
+
+func TestCheckAll_FragmentationAndSchedule(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 3, 0, 0, 0, time.UTC)
+	svc, _ := newTestService(t, Options{}, &now)
+
+	status, err := svc.Status(ctx, "library")
+	require.NoError(t, err)
+	require.Len(t, status.Libs, 1)
+	assert.Greater(t, status.Libs[0].Fragmentation, defaultFragmentationThreshold)
+	assert.Nil(t, status.LastRun)
+
+	// 从未维护过的业务组按计划维护，回收空闲页
+	runs := svc.CheckAll(ctx)
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.MaintenanceTriggerSchedule, runs[0].Trigger)
+	assert.Equal(t, domain.MaintenanceStatusSuccess, runs[0].Status)
+	assert.Equal(t, allTasks, runs[0].Tasks)
+	require.Len(t, runs[0].Libs, 1)
+	assert.Equal(t, int64(0), runs[0].Libs[0].After.FreelistCount)
+
+	// 刚维护过且没有碎片时不再维护，超过间隔后再次按计划维护
+	assert.Empty(t, svc.CheckAll(ctx))
+	now = now.Add(time.Duration(defaultIntervalHours) * time.Hour)
+	runs = svc.CheckAll(ctx)
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.MaintenanceTriggerSchedule, runs[0].Trigger)
+
+	history, err := svc.ListRuns(ctx, "library", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, runs[0].ID, history[0].ID)
+	assert.Equal(t, runs[0].Libs, history[0].Libs)
+}
+
+func TestCheckAll_PostponedWhileBusy(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 3, 0, 0, 0, time.UTC)
+	svc, dataSource := newTestService(t, Options{BusyRequestsPerMinute: 3}, &now)
+
+	for i := 0; i < 3; i++ {
+		_, _ = dataSource.Query(ctx, port.QueryRequest{BizName: "library", Query: map[string]interface{}{"table": "books"}})
+	}
+	assert.Empty(t, svc.CheckAll(ctx))
+	_, err := svc.RunNow(ctx, "library", nil, false)
+	assert.ErrorIs(t, err, ErrBusy)
+
+	// 管理员可强制执行
+	run, err := svc.RunNow(ctx, "library", []string{domain.MaintenanceTaskCheckpoint}, true)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceTriggerManual, run.Trigger)
+	assert.Equal(t, []string{domain.MaintenanceTaskCheckpoint}, run.Tasks)
+
+	// 一分钟后流量统计重新开始；此前只执行过检查点，仍按计划执行完整维护
+	now = now.Add(time.Minute)
+	runs := svc.CheckAll(ctx)
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.MaintenanceTriggerSchedule, runs[0].Trigger)
+
+	_, err = svc.RunNow(ctx, "library", []string{"reindex"}, true)
+	assert.ErrorIs(t, err, ErrInvalidTasks)
+	_, err = svc.RunNow(ctx, "missing", nil, true)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
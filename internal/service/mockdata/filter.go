@@ -0,0 +1,116 @@
+// Package mockdata file: internal/service/mockdata/filter.go
+package mockdata
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"fmt"
+	"strings"
+)
+
+// compileFilters 把查询的 filters 编译为记录匹配函数，支持精确与模糊 (fuzzy) 匹配、negate
+// 以及 in、is_null、is_not_null、is_empty、is_not_empty 运算符。
+// 条件间的 logic 与 SQLite 数据源一致：写在前一个条件上，AND 先于 OR 结合
+func compileFilters(raw interface{}) (func(map[string]interface{}) bool, error) {
+	if raw == nil {
+		return func(map[string]interface{}) bool { return true }, nil
+	}
+	filters, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("无效请求: 'filters' 必须是一个数组")
+	}
+
+	type leaf func(map[string]interface{}) bool
+	var clauses [][]leaf
+	var current []leaf
+	for i, rf := range filters {
+		filter, ok := rf.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("无效请求: filters 数组的第 %d 个元素不是一个有效的JSON对象", i)
+		}
+		cond, err := compileLeaf(filter)
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, cond)
+		logic, _ := filter["logic"].(string)
+		if strings.EqualFold(logic, "OR") && i < len(filters)-1 {
+			clauses = append(clauses, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		clauses = append(clauses, current)
+	}
+
+	return func(row map[string]interface{}) bool {
+		if len(clauses) == 0 {
+			return true
+		}
+		for _, clause := range clauses {
+			matched := true
+			for _, cond := range clause {
+				if !cond(row) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func compileLeaf(filter map[string]interface{}) (func(map[string]interface{}) bool, error) {
+	field, _ := filter["field"].(string)
+	if field == "" {
+		return nil, fmt.Errorf("无效请求: 过滤条件缺少 'field'")
+	}
+	op, _ := filter["op"].(string)
+	fuzzy, _ := filter["fuzzy"].(bool)
+	negate, _ := filter[port.FilterNegateKey].(bool)
+	want := fmt.Sprintf("%v", filter["value"])
+
+	var cond func(value interface{}, present bool) bool
+	switch op {
+	case "":
+		cond = func(value interface{}, present bool) bool {
+			if !present || value == nil {
+				return false
+			}
+			got := fmt.Sprintf("%v", value)
+			if fuzzy {
+				return strings.Contains(got, want)
+			}
+			return got == want
+		}
+	case "in":
+		values, _ := filter[port.FilterValuesKey].([]interface{})
+		if len(values) == 0 {
+			return nil, fmt.Errorf("无效请求: 运算符 'in' 需要非空的 '%s' 列表", port.FilterValuesKey)
+		}
+		set := make(map[string]bool, len(values))
+		for _, v := range values {
+			set[fmt.Sprintf("%v", v)] = true
+		}
+		cond = func(value interface{}, present bool) bool {
+			return present && value != nil && set[fmt.Sprintf("%v", value)]
+		}
+	case "is_null":
+		cond = func(value interface{}, present bool) bool { return !present || value == nil }
+	case "is_not_null":
+		cond = func(value interface{}, present bool) bool { return present && value != nil }
+	case "is_empty":
+		cond = func(value interface{}, present bool) bool { return present && value == "" }
+	case "is_not_empty":
+		cond = func(value interface{}, present bool) bool { return present && value != nil && value != "" }
+	default:
+		return nil, fmt.Errorf("无效请求: 不支持的过滤运算符 '%s'", op)
+	}
+
+	return func(row map[string]interface{}) bool {
+		value, present := row[field]
+		return cond(value, present) != negate
+	}, nil
+}
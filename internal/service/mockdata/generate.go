@@ -0,0 +1,123 @@
+// Package mockdata file: internal/service/mockdata/generate.go
+package mockdata
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// mockTable 是一张表生成的全部记录
+type mockTable struct {
+	primaryKey string
+	rows       []map[string]interface{}
+}
+
+var (
+	mockWords  = []string{"档案", "信札", "日记", "账簿", "契约", "家谱", "诗稿", "地图", "照片", "报刊", "公文", "碑拓", "题跋", "书目", "手稿", "图册"}
+	mockPlaces = []string{"苏州", "杭州", "扬州", "江宁", "松江", "绍兴", "宁波", "徽州", "武昌", "长沙", "福州", "广州"}
+	// mockEpoch 是生成日期的起点，生成的日期落在其后约一百年内
+	mockEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// generateTable 按字段列表生成 rowCount 条记录。主键按 1, 2, 3... 递增，其余字段按数据类型与字段名生成取值，
+// 约二十分之一的非主键取值为 NULL，以便前端处理空值。相同的种子与字段列表总是生成相同的记录
+func generateTable(tableName string, fields []port.FieldDescription, rowCount int, seed int64) *mockTable {
+	primaryKey := "id"
+	for _, f := range fields {
+		if f.IsPrimary {
+			primaryKey = f.Name
+			break
+		}
+	}
+	r := rand.New(rand.NewSource(seed))
+	table := &mockTable{primaryKey: primaryKey, rows: make([]map[string]interface{}, rowCount)}
+	for i := range table.rows {
+		row := map[string]interface{}{"__lib": sourceType}
+		for _, f := range fields {
+			if f.Name == primaryKey {
+				row[f.Name] = primaryKeyValue(tableName, f.DataType, i+1)
+				continue
+			}
+			// 每个字段都先取一次随机数，使某个字段取值为 NULL 不影响后续字段的取值
+			value := mockValue(r, tableName, f, i+1)
+			if r.Intn(20) == 0 {
+				value = nil
+			}
+			row[f.Name] = value
+		}
+		if _, ok := row[primaryKey]; !ok {
+			row[primaryKey] = int64(i + 1)
+		}
+		table.rows[i] = row
+	}
+	return table
+}
+
+func primaryKeyValue(tableName, dataType string, n int) interface{} {
+	switch dataKind(dataType) {
+	case kindInteger, kindFloat:
+		return int64(n)
+	default:
+		return fmt.Sprintf("%s-%05d", tableName, n)
+	}
+}
+
+// mockValue 按字段的数据类型生成取值；字段名暗示了取值格式 (年份、日期、链接、邮箱、地点) 时生成相应格式的取值
+func mockValue(r *rand.Rand, tableName string, f port.FieldDescription, n int) interface{} {
+	name := strings.ToLower(f.Name)
+	switch dataKind(f.DataType) {
+	case kindBool:
+		return r.Intn(2) == 0
+	case kindInteger:
+		if strings.Contains(name, "year") {
+			return int64(1800 + r.Intn(220))
+		}
+		return r.Int63n(10000)
+	case kindFloat:
+		return math.Round(r.Float64()*100000) / 100
+	}
+	switch {
+	case strings.Contains(name, "date") || strings.Contains(name, "time") || strings.HasSuffix(name, "_at"):
+		return mockEpoch.AddDate(0, 0, r.Intn(365*100)).Format("2006-01-02")
+	case strings.Contains(name, "year"):
+		return fmt.Sprintf("%d", 1800+r.Intn(220))
+	case strings.Contains(name, "url") || strings.Contains(name, "link") || strings.Contains(name, "image"):
+		return fmt.Sprintf("https://example.org/mock/%s/%d", tableName, r.Intn(100000))
+	case strings.Contains(name, "email") || strings.Contains(name, "mail"):
+		return fmt.Sprintf("user%d@example.org", r.Intn(100000))
+	case strings.Contains(name, "place") || strings.Contains(name, "city") || strings.Contains(name, "location"):
+		return mockPlaces[r.Intn(len(mockPlaces))]
+	}
+	return fmt.Sprintf("%s%s %d", mockWords[r.Intn(len(mockWords))], mockWords[r.Intn(len(mockWords))], n)
+}
+
+// fieldKind 是字段取值的基本类型
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInteger
+	kindFloat
+	kindBool
+)
+
+// dataKind 按 SQLite 的类型亲和规则把数据类型映射为基本类型，无法识别的类型按字符串处理
+func dataKind(dataType string) fieldKind {
+	t := strings.ToUpper(dataType)
+	switch {
+	case strings.Contains(t, "BOOL"):
+		return kindBool
+	case strings.Contains(t, "INT"):
+		return kindInteger
+	}
+	for _, marker := range []string{"REAL", "FLOA", "DOUB", "NUM", "DEC"} {
+		if strings.Contains(t, marker) {
+			return kindFloat
+		}
+	}
+	return kindString
+}
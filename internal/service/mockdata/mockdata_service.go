@@ -0,0 +1,225 @@
+// Package mockdata file: internal/service/mockdata/mockdata_service.go
+package mockdata
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+)
+
+const (
+	// defaultRowCount 是每张表默认生成的记录数
+	defaultRowCount = 100
+	// maxRowCount 是每张表可生成的记录数上限，生成的记录常驻内存
+	maxRowCount = 10000
+	// defaultSeed 是未配置随机种子时使用的种子
+	defaultSeed = 20240601
+)
+
+// ResultMockKey 是模拟数据模式下查询结果中附加的标记，值为 true，提醒客户端数据并非真实数据
+const ResultMockKey = "mock"
+
+// sourceType 是模拟数据结果的 Source
+const sourceType = "mock"
+
+// Options 定义模拟数据模式。仅供前端开发环境使用：启用后，Bizs 中列出的业务组不再查询真实数据，
+// 改为按数据源的表结构返回确定性生成的记录，写操作一律被拒绝。生产环境不应启用
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Bizs 是启用模拟数据的业务组及其生成参数，键为业务组名 (配置文件中的键不区分大小写，按小写匹配)
+	Bizs map[string]BizOptions `mapstructure:"bizs"`
+}
+
+// BizOptions 是单个业务组的模拟数据生成参数
+type BizOptions struct {
+	// Rows 是每张表生成的记录数，默认为 100，上限为 10000
+	Rows int `mapstructure:"rows"`
+	// Seed 是随机种子，相同的种子与表结构总是生成相同的记录；为 0 时使用固定的默认种子
+	Seed int64 `mapstructure:"seed"`
+}
+
+// Service 为启用了模拟数据的业务组包装数据源
+type Service struct {
+	bizs map[string]BizOptions
+}
+
+// NewService 创建一个新的模拟数据服务实例
+func NewService(opts Options) (*Service, error) {
+	bizs := make(map[string]BizOptions, len(opts.Bizs))
+	for bizName, biz := range opts.Bizs {
+		if biz.Rows < 0 || biz.Rows > maxRowCount {
+			return nil, fmt.Errorf("业务组 '%s' 的模拟记录数 %d 无效，应在 0 到 %d 之间", bizName, biz.Rows, maxRowCount)
+		}
+		if biz.Rows == 0 {
+			biz.Rows = defaultRowCount
+		}
+		if biz.Seed == 0 {
+			biz.Seed = defaultSeed
+		}
+		bizs[bizName] = biz
+	}
+	return &Service{bizs: bizs}, nil
+}
+
+// Enabled 报告业务组是否处于模拟数据模式
+func (s *Service) Enabled(bizName string) bool {
+	_, ok := s.bizs[bizName]
+	return ok
+}
+
+// Wrap 返回一个模拟数据装饰器，可作为插件管理器的装饰器使用；未启用模拟数据的业务组原样返回 inner
+func (s *Service) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	biz, ok := s.bizs[bizName]
+	if !ok {
+		return inner
+	}
+	slog.Warn("业务组处于模拟数据模式，查询将返回生成的数据", "biz", bizName, "rows", biz.Rows, "seed", biz.Seed)
+	return &decorator{DataSource: inner, opts: biz, tables: make(map[string]*mockTable)}
+}
+
+// decorator 以生成的记录回答查询。表结构仍由被装饰的数据源提供，但不会读取其中的任何记录
+type decorator struct {
+	port.DataSource
+	opts BizOptions
+
+	mu sync.Mutex
+	// tables 缓存各表生成的记录，表结构变化后需重启网关才会重新生成
+	tables map[string]*mockTable
+}
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *decorator) Unwrap() port.DataSource {
+	return d.DataSource
+}
+
+// Query 在生成的记录上执行查询，支持按主键读取 (record) 模式、filters、fields_to_return 与分页；
+// 排序、检索模式等其他选项被忽略
+func (d *decorator) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	tableName, _ := req.Query["table"].(string)
+	table, err := d.table(ctx, req.BizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode, _ := req.Query[port.QueryModeKey].(string); mode == port.QueryModeRecord {
+		recordID := fmt.Sprintf("%v", req.Query["id"])
+		var record interface{}
+		matches := 0
+		for _, row := range table.rows {
+			if fmt.Sprintf("%v", row[table.primaryKey]) == recordID {
+				if record == nil {
+					record = copyRow(row, nil)
+				}
+				matches++
+			}
+		}
+		return &port.QueryResult{
+			Data:   map[string]interface{}{"record": record, "matches": float64(matches), ResultMockKey: true},
+			Source: sourceType,
+		}, nil
+	}
+
+	match, err := compileFilters(req.Query["filters"])
+	if err != nil {
+		return nil, err
+	}
+	var matched []map[string]interface{}
+	for _, row := range table.rows {
+		if match(row) {
+			matched = append(matched, row)
+		}
+	}
+
+	page, size := 1, 50
+	if p, ok := req.Query["page"].(float64); ok && p >= 1 {
+		page = int(p)
+	}
+	if s, ok := req.Query["size"].(float64); ok && s >= 1 && s <= 2000 {
+		size = int(s)
+	}
+	fields := stringList(req.Query["fields_to_return"])
+	items := make([]interface{}, 0, size)
+	for i := (page - 1) * size; i < len(matched) && len(items) < size; i++ {
+		items = append(items, copyRow(matched[i], fields))
+	}
+	return &port.QueryResult{
+		Data:   map[string]interface{}{"items": items, "total": int64(len(matched)), ResultMockKey: true},
+		Source: sourceType,
+	}, nil
+}
+
+// Mutate 拒绝写操作：模拟数据模式下写入真实数据源会让前端看到的数据与实际数据不一致
+func (d *decorator) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return nil, fmt.Errorf("%w: 业务组 '%s' 处于模拟数据模式，不接受写操作", port.ErrPermissionDenied, req.BizName)
+}
+
+// AdminMutate 直接转发管理操作：维护、同步等作用于真实的库文件，与模拟的查询结果无关
+func (d *decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return port.AdminMutate(ctx, d.DataSource, req)
+}
+
+// table 返回表的模拟记录，首次访问时按数据源的表结构生成
+func (d *decorator) table(ctx context.Context, bizName, tableName string) (*mockTable, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if table, ok := d.tables[tableName]; ok {
+		return table, nil
+	}
+	if tableName == "" {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	schema, err := d.DataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName, TableName: tableName})
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := schema.Tables[tableName]
+	if !ok || len(fields) == 0 {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	table := generateTable(tableName, fields, d.opts.Rows, tableSeed(d.opts.Seed, tableName))
+	d.tables[tableName] = table
+	return table, nil
+}
+
+// tableSeed 由业务组的种子与表名得到表的种子，使同一业务组中不同表的数据互不相同
+func tableSeed(seed int64, tableName string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(tableName))
+	return seed ^ int64(h.Sum64())
+}
+
+// copyRow 复制一条记录；fields 不为空时只保留其中的字段与以 "_" 开头的附加字段
+func copyRow(row map[string]interface{}, fields []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	if len(fields) == 0 {
+		for k, v := range row {
+			out[k] = v
+		}
+		return out
+	}
+	for _, field := range fields {
+		if v, ok := row[field]; ok {
+			out[field] = v
+		}
+	}
+	for k, v := range row {
+		if len(k) > 0 && k[0] == '_' {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func stringList(raw interface{}) []string {
+	list, _ := raw.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+// file: internal/service/mockdata/mockdata_service_test.go
+package mockdata
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaSource 只提供表结构，任何查询与写操作都视为测试失败
+type schemaSource struct {
+	port.DataSource
+	t *testing.T
+}
+
+func (s schemaSource) GetSchema(_ context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	if req.TableName != "letters" {
+		return &port.SchemaResult{Tables: map[string][]port.FieldDescription{}}, nil
+	}
+	return &port.SchemaResult{Tables: map[string][]port.FieldDescription{"letters": {
+		{Name: "id", DataType: "INTEGER", IsPrimary: true},
+		{Name: "sender", DataType: "TEXT"},
+		{Name: "sent_year", DataType: "INTEGER"},
+		{Name: "place", DataType: "TEXT"},
+		{Name: "weight", DataType: "REAL"},
+		{Name: "public", DataType: "BOOLEAN"},
+	}}}, nil
+}
+
+func (s schemaSource) Query(context.Context, port.QueryRequest) (*port.QueryResult, error) {
+	s.t.Fatal("模拟数据模式下不应查询真实数据源")
+	return nil, nil
+}
+
+func newMockSource(t *testing.T, biz BizOptions) port.DataSource {
+	t.Helper()
+	svc, err := NewService(Options{Enabled: true, Bizs: map[string]BizOptions{"archive": biz}})
+	require.NoError(t, err)
+	return svc.Wrap("archive", schemaSource{t: t})
+}
+
+func query(t *testing.T, ds port.DataSource, q map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	result, err := ds.Query(context.Background(), port.QueryRequest{BizName: "archive", Query: q})
+	require.NoError(t, err)
+	assert.Equal(t, sourceType, result.Source)
+	assert.Equal(t, true, result.Data[ResultMockKey])
+	return result.Data
+}
+
+func TestMockData_DeterministicRows(t *testing.T) {
+	first := query(t, newMockSource(t, BizOptions{Rows: 30, Seed: 7}), map[string]interface{}{"table": "letters", "size": float64(100)})
+	second := query(t, newMockSource(t, BizOptions{Rows: 30, Seed: 7}), map[string]interface{}{"table": "letters", "size": float64(100)})
+	other := query(t, newMockSource(t, BizOptions{Rows: 30, Seed: 8}), map[string]interface{}{"table": "letters", "size": float64(100)})
+
+	assert.Equal(t, int64(30), first["total"])
+	require.Len(t, first["items"], 30)
+	assert.Equal(t, first["items"], second["items"], "相同的种子应生成相同的记录")
+	assert.NotEqual(t, first["items"], other["items"])
+
+	row := first["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, int64(1), row["id"])
+	assert.Equal(t, "mock", row["__lib"])
+	for _, item := range first["items"].([]interface{}) {
+		r := item.(map[string]interface{})
+		if v := r["sent_year"]; v != nil {
+			assert.IsType(t, int64(0), v)
+		}
+		if v := r["weight"]; v != nil {
+			assert.IsType(t, float64(0), v)
+		}
+		if v := r["public"]; v != nil {
+			assert.IsType(t, true, v)
+		}
+	}
+}
+
+func TestMockData_QueryOptions(t *testing.T) {
+	ds := newMockSource(t, BizOptions{Rows: 200})
+
+	// 默认记录数与分页
+	data := query(t, ds, map[string]interface{}{"table": "letters", "page": float64(2), "size": float64(10)})
+	assert.Equal(t, int64(200), data["total"])
+	items := data["items"].([]interface{})
+	require.Len(t, items, 10)
+	assert.Equal(t, int64(11), items[0].(map[string]interface{})["id"])
+
+	// 过滤与返回字段
+	data = query(t, ds, map[string]interface{}{
+		"table":            "letters",
+		"filters":          []interface{}{map[string]interface{}{"field": "place", "value": "苏州"}},
+		"fields_to_return": []interface{}{"place"},
+		"size":             float64(2000),
+	})
+	items = data["items"].([]interface{})
+	require.NotEmpty(t, items)
+	assert.Equal(t, int64(len(items)), data["total"])
+	for _, item := range items {
+		row := item.(map[string]interface{})
+		assert.Equal(t, "苏州", row["place"])
+		assert.NotContains(t, row, "sender")
+		assert.Contains(t, row, "__lib")
+	}
+
+	// OR 连接与取反
+	data = query(t, ds, map[string]interface{}{
+		"table": "letters",
+		"filters": []interface{}{
+			map[string]interface{}{"field": "id", "value": "1", "logic": "OR"},
+			map[string]interface{}{"field": "id", "op": "in", "values": []interface{}{"2", "3"}},
+		},
+	})
+	assert.Equal(t, int64(3), data["total"])
+	data = query(t, ds, map[string]interface{}{
+		"table":   "letters",
+		"filters": []interface{}{map[string]interface{}{"field": "id", "value": "1", "negate": true}},
+	})
+	assert.Equal(t, int64(199), data["total"])
+
+	// 按主键读取
+	data = query(t, ds, map[string]interface{}{"table": "letters", "mode": "record", "id": "5"})
+	assert.Equal(t, int64(5), data["record"].(map[string]interface{})["id"])
+	assert.Equal(t, float64(1), data["matches"])
+
+	_, err := ds.Query(context.Background(), port.QueryRequest{BizName: "archive", Query: map[string]interface{}{"table": "missing"}})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = ds.Query(context.Background(), port.QueryRequest{BizName: "archive", Query: map[string]interface{}{
+		"table": "letters", "filters": []interface{}{map[string]interface{}{"field": "id", "op": "gt"}},
+	}})
+	assert.Error(t, err)
+}
+
+func TestMockData_RejectsWritesAndSkipsOtherBiz(t *testing.T) {
+	ds := newMockSource(t, BizOptions{})
+	_, err := ds.Mutate(context.Background(), port.MutateRequest{BizName: "archive", Operation: "create"})
+	assert.ErrorIs(t, err, port.ErrPermissionDenied)
+
+	svc, err := NewService(Options{Enabled: true, Bizs: map[string]BizOptions{"archive": {}}})
+	require.NoError(t, err)
+	inner := schemaSource{t: t}
+	assert.Equal(t, port.DataSource(inner), svc.Wrap("other", inner), "未启用模拟数据的业务组应原样返回")
+	assert.True(t, svc.Enabled("archive"))
+	assert.False(t, svc.Enabled("other"))
+
+	_, err = NewService(Options{Enabled: true, Bizs: map[string]BizOptions{"archive": {Rows: maxRowCount + 1}}})
+	assert.Error(t, err)
+}
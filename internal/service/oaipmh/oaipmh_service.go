@@ -0,0 +1,185 @@
+// Package oaipmh file: internal/service/oaipmh/oaipmh_service.go
+package oaipmh
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultPageSize = 100
+
+var (
+	// ErrMappingNotFound 表示指定业务表尚未配置 OAI-PMH 映射
+	ErrMappingNotFound = errors.New("该业务表未配置 OAI-PMH 映射")
+	// ErrInvalidMapping 表示映射配置不合法
+	ErrInvalidMapping = errors.New("无效的 OAI-PMH 映射")
+)
+
+// dcElements 是 Dublin Core 简单元素集 (oai_dc) 的全部 15 个元素
+var dcElements = map[string]bool{
+	"title": true, "creator": true, "subject": true, "description": true, "publisher": true,
+	"contributor": true, "date": true, "type": true, "format": true, "identifier": true,
+	"source": true, "language": true, "relation": true, "coverage": true, "rights": true,
+}
+
+// dcElementOrder 是输出 oai_dc 记录时的元素顺序，与 Dublin Core 规范的列举顺序一致
+var dcElementOrder = []string{
+	"title", "creator", "subject", "description", "publisher", "contributor", "date",
+	"type", "format", "identifier", "source", "language", "relation", "coverage", "rights",
+}
+
+// Options 定义了 OAI-PMH 仓库对外公布的身份信息
+type Options struct {
+	RepositoryName string `mapstructure:"repository_name"`
+	// RepositoryIdentifier 用作 OAI 标识符的命名空间，即 oai:<identifier>:<biz>/<table>/<id>
+	RepositoryIdentifier string `mapstructure:"repository_identifier"`
+	AdminEmail           string `mapstructure:"admin_email"`
+	PageSize             int    `mapstructure:"page_size"`
+}
+
+// Service 负责管理 Dublin Core 映射，并以 OAI-PMH 2.0 协议对外提供元数据收割。
+// 数据读取全部经由数据源注册表完成，因此业务组的公开搜索与字段返回策略同样适用于收割。
+type Service struct {
+	db       *sql.DB
+	registry map[string]port.DataSource
+	opts     Options
+	now      func() time.Time
+}
+
+// NewService 创建一个新的 OAI-PMH 服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("oaipmh.Service 需要一个有效的数据库连接")
+	}
+	if opts.RepositoryName == "" {
+		opts.RepositoryName = "ArchiveAegis"
+	}
+	if opts.RepositoryIdentifier == "" {
+		opts.RepositoryIdentifier = "archiveaegis"
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	return &Service{db: db, registry: registry, opts: opts, now: time.Now}, nil
+}
+
+// ListMappings 返回所有已配置的映射，按业务组与表名排序
+func (s *Service) ListMappings(ctx context.Context) ([]domain.OAIMapping, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT biz_name, table_name, set_name, id_field, datestamp_field, elements_json, updated_at
+		FROM oai_dc_mappings ORDER BY biz_name, table_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询 OAI-PMH 映射失败: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := make([]domain.OAIMapping, 0)
+	for rows.Next() {
+		var m domain.OAIMapping
+		var elementsJSON string
+		if err := rows.Scan(&m.BizName, &m.TableName, &m.SetName, &m.IDField, &m.DatestampField, &elementsJSON, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描 OAI-PMH 映射失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(elementsJSON), &m.Elements); err != nil {
+			return nil, fmt.Errorf("解析业务表 '%s/%s' 的元素映射失败: %w", m.BizName, m.TableName, err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// getMapping 读取单个业务表的映射，不存在时返回 ErrMappingNotFound
+func (s *Service) getMapping(ctx context.Context, bizName, tableName string) (*domain.OAIMapping, error) {
+	mappings, err := s.ListMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mappings {
+		if mappings[i].BizName == bizName && mappings[i].TableName == tableName {
+			return &mappings[i], nil
+		}
+	}
+	return nil, ErrMappingNotFound
+}
+
+// SaveMapping 创建或更新一个业务表的 Dublin Core 映射
+func (s *Service) SaveMapping(ctx context.Context, m domain.OAIMapping) error {
+	if m.BizName == "" || m.TableName == "" || m.IDField == "" {
+		return fmt.Errorf("%w: 必须包含 biz_name、table_name 和 id_field", ErrInvalidMapping)
+	}
+	if strings.ContainsAny(m.BizName+m.TableName, "/:") {
+		return fmt.Errorf("%w: 业务组名与表名不能包含 '/' 或 ':'", ErrInvalidMapping)
+	}
+	if len(m.Elements) == 0 {
+		return fmt.Errorf("%w: 至少需要映射一个 Dublin Core 元素", ErrInvalidMapping)
+	}
+	for element, field := range m.Elements {
+		if !dcElements[element] {
+			return fmt.Errorf("%w: '%s' 不是 Dublin Core 元素", ErrInvalidMapping, element)
+		}
+		if field == "" {
+			return fmt.Errorf("%w: 元素 '%s' 未指定字段", ErrInvalidMapping, element)
+		}
+	}
+	elementsJSON, err := json.Marshal(m.Elements)
+	if err != nil {
+		return fmt.Errorf("序列化元素映射失败: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO oai_dc_mappings (biz_name, table_name, set_name, id_field, datestamp_field, elements_json, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name, table_name) DO UPDATE SET
+			set_name = excluded.set_name,
+			id_field = excluded.id_field,
+			datestamp_field = excluded.datestamp_field,
+			elements_json = excluded.elements_json,
+			updated_at = CURRENT_TIMESTAMP`,
+		m.BizName, m.TableName, m.SetName, m.IDField, m.DatestampField, string(elementsJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("保存业务表 '%s/%s' 的 OAI-PMH 映射失败: %w", m.BizName, m.TableName, err)
+	}
+	return nil
+}
+
+// DeleteMapping 删除一个业务表的映射，该集合将不再可被收割
+func (s *Service) DeleteMapping(ctx context.Context, bizName, tableName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM oai_dc_mappings WHERE biz_name = ? AND table_name = ?`, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("删除业务表 '%s/%s' 的 OAI-PMH 映射失败: %w", bizName, tableName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMappingNotFound
+	}
+	return nil
+}
+
+// fieldsToReturn 返回收割一条记录所需的全部字段，已去重并排序
+func fieldsToReturn(m domain.OAIMapping) []interface{} {
+	set := map[string]bool{m.IDField: true}
+	if m.DatestampField != "" {
+		set[m.DatestampField] = true
+	}
+	for _, field := range m.Elements {
+		set[field] = true
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]interface{}, len(names))
+	for i, name := range names {
+		fields[i] = name
+	}
+	return fields
+}
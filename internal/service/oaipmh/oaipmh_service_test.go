@@ -0,0 +1,125 @@
+// file: internal/service/oaipmh/oaipmh_service_test.go
+package oaipmh
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"encoding/xml"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+
+	letters := fixture.NewFakeDataSource().WithTable("letters", []port.FieldDescription{{Name: "id", IsPrimary: true}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅", "sent_on": "1925-03-11"},
+		map[string]interface{}{"id": float64(2), "sender": "胡适", "sent_on": "1930-07-02"},
+		map[string]interface{}{"id": float64(3), "sender": "周作人", "sent_on": nil},
+	)
+	registry := map[string]port.DataSource{"letters": letters}
+	svc, err := NewService(db, registry, Options{RepositoryIdentifier: "test", PageSize: 2})
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveMapping(context.Background(), domain.OAIMapping{
+		BizName: "letters", TableName: "letters", IDField: "id", DatestampField: "sent_on",
+		Elements: map[string]string{"creator": "sender", "date": "sent_on"},
+	}))
+	return svc
+}
+
+// parsed 只解析测试关心的部分
+type parsed struct {
+	Errors []struct {
+		Code string `xml:"code,attr"`
+	} `xml:"error"`
+	Identify struct {
+		ProtocolVersion string `xml:"protocolVersion"`
+	} `xml:"Identify"`
+	Records []struct {
+		Identifier string `xml:"header>identifier"`
+		Datestamp  string `xml:"header>datestamp"`
+		Creator    string `xml:"metadata>dc>creator"`
+	} `xml:"ListRecords>record"`
+	Token struct {
+		Cursor int    `xml:"cursor,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"ListRecords>resumptionToken"`
+	GetRecord struct {
+		Identifier string `xml:"header>identifier"`
+	} `xml:"GetRecord>record"`
+}
+
+func call(t *testing.T, svc *Service, query string) parsed {
+	t.Helper()
+	args, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	body, err := svc.Handle(context.Background(), "http://example.org/api/v1/oai", args)
+	require.NoError(t, err)
+	var p parsed
+	require.NoError(t, xml.Unmarshal(body, &p), string(body))
+	return p
+}
+
+func TestHandle_ListRecordsWithResumption(t *testing.T) {
+	svc := newTestService(t)
+
+	first := call(t, svc, "verb=ListRecords&metadataPrefix=oai_dc")
+	require.Empty(t, first.Errors)
+	require.Len(t, first.Records, 2)
+	assert.Equal(t, "oai:test:letters/letters/1", first.Records[0].Identifier)
+	assert.Equal(t, "1925-03-11T00:00:00Z", first.Records[0].Datestamp)
+	assert.Equal(t, "鲁迅", first.Records[0].Creator)
+	require.NotEmpty(t, first.Token.Value, "第一页之后应返回 resumptionToken")
+
+	second := call(t, svc, "verb=ListRecords&resumptionToken="+first.Token.Value)
+	require.Empty(t, second.Errors)
+	require.Len(t, second.Records, 1)
+	assert.Equal(t, "周作人", second.Records[0].Creator)
+	assert.Equal(t, 2, second.Token.Cursor)
+	assert.Empty(t, second.Token.Value, "最后一页的 resumptionToken 应为空")
+
+	filtered := call(t, svc, "verb=ListRecords&metadataPrefix=oai_dc&from=1929-01-01&until=1931-01-01")
+	require.Empty(t, filtered.Errors)
+	require.Len(t, filtered.Records, 1)
+	assert.Equal(t, "胡适", filtered.Records[0].Creator)
+}
+
+func TestHandle_ProtocolErrors(t *testing.T) {
+	svc := newTestService(t)
+
+	assert.Equal(t, "2.0", call(t, svc, "verb=Identify").Identify.ProtocolVersion)
+	assert.Equal(t, "oai:test:letters/letters/2",
+		call(t, svc, "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:test:letters/letters/2").GetRecord.Identifier)
+
+	cases := map[string]string{
+		"verb=Nope":                                      "badVerb",
+		"verb=Identify&foo=bar":                          "badArgument",
+		"verb=ListRecords":                               "badArgument",
+		"verb=ListRecords&metadataPrefix=marc21":         "cannotDisseminateFormat",
+		"verb=ListRecords&resumptionToken=garbage":       "badResumptionToken",
+		"verb=ListRecords&metadataPrefix=oai_dc&set=x:y": "noRecordsMatch",
+		"verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:test:letters/letters/99":       "idDoesNotExist",
+		"verb=ListRecords&metadataPrefix=oai_dc&from=2020-01-01&until=2020-01-01T00:00:00Z": "badArgument",
+	}
+	for query, code := range cases {
+		p := call(t, svc, query)
+		require.Len(t, p.Errors, 1, query)
+		assert.Equal(t, code, p.Errors[0].Code, query)
+	}
+}
+
+func TestSaveMapping_Validation(t *testing.T) {
+	svc := newTestService(t)
+	err := svc.SaveMapping(context.Background(), domain.OAIMapping{
+		BizName: "letters", TableName: "letters", IDField: "id",
+		Elements: map[string]string{"author": "sender"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+	assert.ErrorIs(t, svc.DeleteMapping(context.Background(), "letters", "missing"), ErrMappingNotFound)
+}
@@ -0,0 +1,598 @@
+// Package oaipmh file: internal/service/oaipmh/provider.go
+package oaipmh
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	oaiNamespace   = "http://www.openarchives.org/OAI/2.0/"
+	oaiSchema      = "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	oaiDCNamespace = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	oaiDCSchema    = "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	dcNamespace    = "http://purl.org/dc/elements/1.1/"
+	xsiNamespace   = "http://www.w3.org/2001/XMLSchema-instance"
+
+	metadataPrefixDC = "oai_dc"
+	timeGranularity  = "2006-01-02T15:04:05Z"
+	dateGranularity  = "2006-01-02"
+)
+
+// verbArgs 列出各动词允许的参数，值为 true 表示必填 (resumptionToken 的互斥规则单独处理)
+var verbArgs = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": false},
+	"ListSets":            {"resumptionToken": false},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"ListRecords":         {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+}
+
+// --- 协议响应的 XML 结构 ---
+
+type response struct {
+	XMLName        xml.Name `xml:"OAI-PMH"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsXsi       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate   string   `xml:"responseDate"`
+	Request        request  `xml:"request"`
+
+	Errors              []oaiError           `xml:"error,omitempty"`
+	Identify            *identify            `xml:"Identify,omitempty"`
+	ListMetadataFormats *listMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *listSets            `xml:"ListSets,omitempty"`
+	GetRecord           *getRecord           `xml:"GetRecord,omitempty"`
+	ListIdentifiers     *listIdentifiers     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *listRecords         `xml:"ListRecords,omitempty"`
+}
+
+type request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type identify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+type metadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type listMetadataFormats struct {
+	Formats []metadataFormat `xml:"metadataFormat"`
+}
+
+type set struct {
+	SetSpec string `xml:"setSpec"`
+	SetName string `xml:"setName"`
+}
+
+type listSets struct {
+	Sets []set `xml:"set"`
+}
+
+type header struct {
+	Identifier string `xml:"identifier"`
+	Datestamp  string `xml:"datestamp"`
+	SetSpec    string `xml:"setSpec"`
+}
+
+type dcElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type dcRecord struct {
+	XMLName        xml.Name    `xml:"oai_dc:dc"`
+	XmlnsOAIDC     string      `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string      `xml:"xmlns:dc,attr"`
+	XmlnsXsi       string      `xml:"xmlns:xsi,attr"`
+	SchemaLocation string      `xml:"xsi:schemaLocation,attr"`
+	Elements       []dcElement `xml:",any"`
+}
+
+type metadata struct {
+	DC dcRecord `xml:"oai_dc:dc"`
+}
+
+type record struct {
+	Header   header    `xml:"header"`
+	Metadata *metadata `xml:"metadata,omitempty"`
+}
+
+type getRecord struct {
+	Record record `xml:"record"`
+}
+
+type resumptionToken struct {
+	Cursor int    `xml:"cursor,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type listIdentifiers struct {
+	Headers         []header         `xml:"header"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+type listRecords struct {
+	Records         []record         `xml:"record"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// harvestState 是编码进 resumptionToken 的收割进度，服务端因此无需保存会话
+type harvestState struct {
+	Prefix  string `json:"p"`
+	Set     string `json:"s,omitempty"`
+	From    string `json:"f,omitempty"`
+	Until   string `json:"u,omitempty"`
+	Mapping int    `json:"m"`
+	Page    int    `json:"pg"`
+	Cursor  int    `json:"c"`
+}
+
+func encodeToken(st harvestState) string {
+	b, _ := json.Marshal(st)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeToken(token string) (harvestState, bool) {
+	var st harvestState
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(b, &st) != nil || st.Prefix == "" || st.Page < 1 || st.Mapping < 0 {
+		return harvestState{}, false
+	}
+	return st, true
+}
+
+// Handle 处理一次 OAI-PMH 请求并返回完整的 XML 文档。
+// 协议层面的错误 (badVerb、idDoesNotExist 等) 按规范写入响应体；返回的 error 仅表示内部故障。
+func (s *Service) Handle(ctx context.Context, baseURL string, args url.Values) ([]byte, error) {
+	resp := &response{
+		Xmlns:          oaiNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: oaiSchema,
+		ResponseDate:   s.now().UTC().Format(timeGranularity),
+		Request:        request{URL: baseURL},
+	}
+
+	if err := s.dispatch(ctx, baseURL, args, resp); err != nil {
+		return nil, err
+	}
+
+	body, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化 OAI-PMH 响应失败: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func (s *Service) dispatch(ctx context.Context, baseURL string, args url.Values, resp *response) error {
+	fail := func(code, msg string) error {
+		resp.Errors = append(resp.Errors, oaiError{Code: code, Message: msg})
+		return nil
+	}
+
+	verbs := args["verb"]
+	if len(verbs) != 1 {
+		return fail("badVerb", "必须且只能指定一个 verb 参数")
+	}
+	verb := verbs[0]
+	allowed, ok := verbArgs[verb]
+	if !ok {
+		return fail("badVerb", fmt.Sprintf("不支持的 verb '%s'", verb))
+	}
+
+	// 规范要求出现非法参数时，request 元素只回显 baseURL
+	for key, values := range args {
+		if key == "verb" {
+			continue
+		}
+		if _, known := allowed[key]; !known {
+			return fail("badArgument", fmt.Sprintf("verb '%s' 不接受参数 '%s'", verb, key))
+		}
+		if len(values) != 1 {
+			return fail("badArgument", fmt.Sprintf("参数 '%s' 重复出现", key))
+		}
+	}
+	token := args.Get("resumptionToken")
+	if token != "" && len(args) > 2 {
+		return fail("badArgument", "resumptionToken 必须单独使用")
+	}
+	if token == "" {
+		for key, required := range allowed {
+			if required && args.Get(key) == "" {
+				return fail("badArgument", fmt.Sprintf("缺少必填参数 '%s'", key))
+			}
+		}
+	}
+
+	resp.Request = request{
+		Verb:            verb,
+		Identifier:      args.Get("identifier"),
+		MetadataPrefix:  args.Get("metadataPrefix"),
+		From:            args.Get("from"),
+		Until:           args.Get("until"),
+		Set:             args.Get("set"),
+		ResumptionToken: token,
+		URL:             baseURL,
+	}
+
+	switch verb {
+	case "Identify":
+		resp.Identify = &identify{
+			RepositoryName:    s.opts.RepositoryName,
+			BaseURL:           baseURL,
+			ProtocolVersion:   "2.0",
+			AdminEmail:        s.opts.AdminEmail,
+			EarliestDatestamp: "1970-01-01T00:00:00Z",
+			DeletedRecord:     "no",
+			Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+		}
+		return nil
+
+	case "ListMetadataFormats":
+		if id := args.Get("identifier"); id != "" {
+			if _, found, err := s.lookupRecord(ctx, id); err != nil {
+				return err
+			} else if !found {
+				return fail("idDoesNotExist", fmt.Sprintf("标识符 '%s' 不存在", id))
+			}
+		}
+		resp.ListMetadataFormats = &listMetadataFormats{Formats: []metadataFormat{{
+			MetadataPrefix:    metadataPrefixDC,
+			Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+			MetadataNamespace: oaiDCNamespace,
+		}}}
+		return nil
+
+	case "ListSets":
+		if token != "" {
+			return fail("badResumptionToken", "集合列表不分页，不接受 resumptionToken")
+		}
+		mappings, err := s.harvestableMappings(ctx, "")
+		if err != nil {
+			return err
+		}
+		if len(mappings) == 0 {
+			return fail("noSetHierarchy", "仓库尚未配置任何可收割的集合")
+		}
+		sets := make([]set, 0, len(mappings))
+		for _, m := range mappings {
+			name := m.SetName
+			if name == "" {
+				name = m.BizName + " / " + m.TableName
+			}
+			sets = append(sets, set{SetSpec: setSpec(m), SetName: name})
+		}
+		resp.ListSets = &listSets{Sets: sets}
+		return nil
+
+	case "GetRecord":
+		if args.Get("metadataPrefix") != metadataPrefixDC {
+			return fail("cannotDisseminateFormat", "仅支持 oai_dc 元数据格式")
+		}
+		rec, found, err := s.lookupRecord(ctx, args.Get("identifier"))
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fail("idDoesNotExist", fmt.Sprintf("标识符 '%s' 不存在", args.Get("identifier")))
+		}
+		resp.GetRecord = &getRecord{Record: rec}
+		return nil
+
+	default: // ListIdentifiers / ListRecords
+		var st harvestState
+		if token != "" {
+			var valid bool
+			if st, valid = decodeToken(token); !valid {
+				return fail("badResumptionToken", "resumptionToken 无效或已过期")
+			}
+		} else {
+			st = harvestState{
+				Prefix: args.Get("metadataPrefix"),
+				Set:    args.Get("set"),
+				From:   args.Get("from"),
+				Until:  args.Get("until"),
+				Page:   1,
+			}
+			if st.Prefix != metadataPrefixDC {
+				return fail("cannotDisseminateFormat", "仅支持 oai_dc 元数据格式")
+			}
+		}
+		from, until, ok := parseRange(st.From, st.Until)
+		if !ok {
+			return fail("badArgument", "from/until 格式错误或粒度不一致")
+		}
+
+		records, next, err := s.harvest(ctx, st, from, until)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 && token == "" {
+			return fail("noRecordsMatch", "没有符合条件的记录")
+		}
+
+		var rt *resumptionToken
+		if token != "" || next != nil {
+			// 最后一页需要返回空的 resumptionToken，表示列表已完整
+			rt = &resumptionToken{Cursor: st.Cursor}
+			if next != nil {
+				rt.Value = encodeToken(*next)
+			}
+		}
+		if verb == "ListIdentifiers" {
+			headers := make([]header, len(records))
+			for i, r := range records {
+				headers[i] = r.Header
+			}
+			resp.ListIdentifiers = &listIdentifiers{Headers: headers, ResumptionToken: rt}
+		} else {
+			resp.ListRecords = &listRecords{Records: records, ResumptionToken: rt}
+		}
+		return nil
+	}
+}
+
+// harvest 从 st 指向的位置读取下一批记录。
+// 返回的 next 为 nil 表示所有集合均已读取完毕。
+func (s *Service) harvest(ctx context.Context, st harvestState, from, until time.Time) ([]record, *harvestState, error) {
+	mappings, err := s.harvestableMappings(ctx, st.Set)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []record
+	for len(records) == 0 && st.Mapping < len(mappings) {
+		m := mappings[st.Mapping]
+		result, err := s.registry[m.BizName].Query(ctx, port.QueryRequest{
+			BizName: m.BizName,
+			Query: map[string]interface{}{
+				"table":            m.TableName,
+				"page":             float64(st.Page),
+				"size":             float64(s.opts.PageSize),
+				"fields_to_return": fieldsToReturn(m),
+			},
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("收割业务表 '%s/%s' 失败: %w", m.BizName, m.TableName, err)
+		}
+		items, _ := result.Data["items"].([]interface{})
+		for _, item := range items {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rec := s.buildRecord(m, row)
+			stamp, _ := time.Parse(timeGranularity, rec.Header.Datestamp)
+			if (!from.IsZero() && stamp.Before(from)) || (!until.IsZero() && stamp.After(until)) {
+				continue
+			}
+			records = append(records, rec)
+		}
+
+		if len(items) > 0 && int64(st.Page*s.opts.PageSize) < toInt64(result.Data["total"]) {
+			st.Page++
+		} else {
+			st.Mapping++
+			st.Page = 1
+		}
+	}
+
+	if st.Mapping >= len(mappings) {
+		return records, nil, nil
+	}
+	next := st
+	next.Cursor += len(records)
+	return records, &next, nil
+}
+
+// harvestableMappings 返回可被收割的映射：业务组必须已加载到注册表中；setFilter 非空时只保留对应集合
+func (s *Service) harvestableMappings(ctx context.Context, setFilter string) ([]domain.OAIMapping, error) {
+	all, err := s.ListMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]domain.OAIMapping, 0, len(all))
+	for _, m := range all {
+		if _, loaded := s.registry[m.BizName]; !loaded {
+			continue
+		}
+		if setFilter != "" && setSpec(m) != setFilter && m.BizName != setFilter {
+			continue
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// lookupRecord 根据 OAI 标识符读取单条记录，复用数据源的按主键读取模式
+func (s *Service) lookupRecord(ctx context.Context, identifier string) (record, bool, error) {
+	prefix := "oai:" + s.opts.RepositoryIdentifier + ":"
+	if !strings.HasPrefix(identifier, prefix) {
+		return record{}, false, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(identifier, prefix), "/", 3)
+	if len(parts) != 3 {
+		return record{}, false, nil
+	}
+	bizName, tableName, recordID := parts[0], parts[1], parts[2]
+
+	dataSource, loaded := s.registry[bizName]
+	if !loaded {
+		return record{}, false, nil
+	}
+	m, err := s.getMapping(ctx, bizName, tableName)
+	if err == ErrMappingNotFound {
+		return record{}, false, nil
+	}
+	if err != nil {
+		return record{}, false, err
+	}
+
+	result, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+		},
+	})
+	if err != nil {
+		// 权限不足与记录不存在对收割方而言没有区别，避免泄露业务组的访问策略
+		slog.Warn("[OAI-PMH] 读取记录失败", "identifier", identifier, "error", err)
+		return record{}, false, nil
+	}
+	row, _ := result.Data["record"].(map[string]interface{})
+	if row == nil {
+		return record{}, false, nil
+	}
+	return s.buildRecord(*m, row), true, nil
+}
+
+// buildRecord 将一行业务数据按映射转换为 oai_dc 记录
+func (s *Service) buildRecord(m domain.OAIMapping, row map[string]interface{}) record {
+	id := stringify(row[m.IDField])
+	datestamp := m.UpdatedAt.UTC()
+	if m.DatestampField != "" {
+		if t, ok := parseDatestamp(row[m.DatestampField]); ok {
+			datestamp = t
+		}
+	}
+
+	dc := dcRecord{
+		XmlnsOAIDC:     oaiDCNamespace,
+		XmlnsDC:        dcNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: oaiDCSchema,
+	}
+	for _, element := range dcElementOrder {
+		field, mapped := m.Elements[element]
+		if !mapped {
+			continue
+		}
+		if value := stringify(row[field]); value != "" {
+			dc.Elements = append(dc.Elements, dcElement{XMLName: xml.Name{Local: "dc:" + element}, Value: value})
+		}
+	}
+
+	return record{
+		Header: header{
+			Identifier: fmt.Sprintf("oai:%s:%s/%s/%s", s.opts.RepositoryIdentifier, m.BizName, m.TableName, id),
+			Datestamp:  datestamp.Format(timeGranularity),
+			SetSpec:    setSpec(m),
+		},
+		Metadata: &metadata{DC: dc},
+	}
+}
+
+// setSpec 以 biz:table 的层级形式表示集合，收割方既可以收割单表，也可以按业务组整体收割
+func setSpec(m domain.OAIMapping) string {
+	return m.BizName + ":" + m.TableName
+}
+
+// parseRange 解析 from/until 参数。两者必须使用相同的粒度；仅含日期的 until 包含当天全部时间。
+func parseRange(fromStr, untilStr string) (from, until time.Time, ok bool) {
+	parse := func(v string) (time.Time, bool, bool) {
+		if v == "" {
+			return time.Time{}, false, true
+		}
+		if t, err := time.Parse(timeGranularity, v); err == nil {
+			return t, false, true
+		}
+		if t, err := time.Parse(dateGranularity, v); err == nil {
+			return t, true, true
+		}
+		return time.Time{}, false, false
+	}
+	from, fromIsDate, okFrom := parse(fromStr)
+	until, untilIsDate, okUntil := parse(untilStr)
+	if !okFrom || !okUntil {
+		return time.Time{}, time.Time{}, false
+	}
+	if fromStr != "" && untilStr != "" && fromIsDate != untilIsDate {
+		return time.Time{}, time.Time{}, false
+	}
+	if untilIsDate {
+		until = until.Add(24*time.Hour - time.Second)
+	}
+	if !from.IsZero() && !until.IsZero() && from.After(until) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, until, true
+}
+
+// parseDatestamp 尽量从字段值中解析出时间，支持常见的日期字符串与 Unix 秒
+func parseDatestamp(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", dateGranularity, "2006"} {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t.UTC(), true
+			}
+		}
+	case float64, int64, int:
+		if sec := toInt64(val); sec > 0 {
+			return time.Unix(sec, 0).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// stringify 将经 gRPC 传输后的字段值转换为文本；整数值的 float64 不带小数部分
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func toInt64(v interface{}) int64 {
+	switch val := v.(type) {
+	case float64:
+		return int64(val)
+	case int64:
+		return val
+	case int:
+		return int64(val)
+	}
+	return 0
+}
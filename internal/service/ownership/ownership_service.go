@@ -0,0 +1,134 @@
+// Package ownership file: internal/service/ownership/ownership_service.go
+package ownership
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidOwner 表示委派的业务组或用户不合法
+	ErrInvalidOwner = errors.New("无效的业务组管理员设置")
+	// ErrNotFound 表示该用户不是业务组的管理员
+	ErrNotFound = errors.New("业务组管理员不存在")
+)
+
+// Service 管理业务组的委派管理员。平台管理员可以把某个业务组的日常配置 (字段设置、视图、速率限制)
+// 委派给普通用户，这些用户只能访问自己业务组的配置接口
+type Service struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewService 创建一个新的业务组管理员服务实例
+func NewService(db *sql.DB) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("ownership.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, now: time.Now}, nil
+}
+
+// Grant 把业务组委派给用户，重复委派时保持原有记录不变
+func (s *Service) Grant(ctx context.Context, bizName string, userID, grantedBy int64) (*domain.BizOwner, error) {
+	bizName = strings.TrimSpace(bizName)
+	if bizName == "" || userID <= 0 {
+		return nil, fmt.Errorf("%w: 必须提供业务组与 user_id", ErrInvalidOwner)
+	}
+	var role string
+	err := s.db.QueryRowContext(ctx, `SELECT role FROM _user WHERE id = ?`, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: 用户 %d 不存在", ErrInvalidOwner, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if role == "admin" {
+		return nil, fmt.Errorf("%w: 用户 %d 已是平台管理员，无需委派", ErrInvalidOwner, userID)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO biz_owners (biz_name, user_id, granted_by, granted_at) VALUES (?, ?, ?, ?)`,
+		bizName, userID, grantedBy, s.now().UTC()); err != nil {
+		return nil, fmt.Errorf("写入业务组管理员失败: %w", err)
+	}
+	slog.Info("审计日志: 委派业务组管理员", "biz", bizName, "user_id", userID, "granted_by", grantedBy)
+
+	owners, err := s.query(ctx, ` WHERE o.biz_name = ? AND o.user_id = ?`, bizName, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &owners[0], nil
+}
+
+// Revoke 撤销用户对业务组的管理权限
+func (s *Service) Revoke(ctx context.Context, bizName string, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_owners WHERE biz_name = ? AND user_id = ?`, bizName, userID)
+	if err != nil {
+		return fmt.Errorf("删除业务组管理员失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: 用户 %d 不是业务组 '%s' 的管理员", ErrNotFound, userID, bizName)
+	}
+	slog.Info("审计日志: 撤销业务组管理员", "biz", bizName, "user_id", userID)
+	return nil
+}
+
+// Owners 返回业务组的全部管理员
+func (s *Service) Owners(ctx context.Context, bizName string) ([]domain.BizOwner, error) {
+	return s.query(ctx, ` WHERE o.biz_name = ? ORDER BY o.granted_at, o.user_id`, bizName)
+}
+
+// OwnedBizs 返回用户被委派管理的业务组，按名称排序
+func (s *Service) OwnedBizs(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name FROM biz_owners WHERE user_id = ? ORDER BY biz_name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户管理的业务组失败: %w", err)
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// IsOwner 判断用户是否为业务组的管理员
+func (s *Service) IsOwner(ctx context.Context, bizName string, userID int64) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM biz_owners WHERE biz_name = ? AND user_id = ?)`, bizName, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("查询业务组管理员失败: %w", err)
+	}
+	return exists, nil
+}
+
+func (s *Service) query(ctx context.Context, where string, args ...any) ([]domain.BizOwner, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT o.biz_name, o.user_id, u.username, o.granted_by, o.granted_at
+		FROM biz_owners o JOIN _user u ON u.id = o.user_id`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询业务组管理员失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.BizOwner{}
+	for rows.Next() {
+		var owner domain.BizOwner
+		var grantedBy sql.NullInt64
+		if err := rows.Scan(&owner.BizName, &owner.UserID, &owner.Username, &grantedBy, &owner.GrantedAt); err != nil {
+			return nil, fmt.Errorf("扫描业务组管理员失败: %w", err)
+		}
+		if grantedBy.Valid {
+			owner.GrantedBy = &grantedBy.Int64
+		}
+		out = append(out, owner)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,63 @@
+// file: internal/service/ownership/ownership_service_test.go
+package ownership
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBizOwnership(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'root', 'x', 'admin'), (2, 'curator', 'x', 'user'), (3, 'editor', 'x', 'user')`)
+	require.NoError(t, err)
+
+	svc, err := NewService(db)
+	require.NoError(t, err)
+
+	owner, err := svc.Grant(ctx, "library", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "curator", owner.Username)
+	require.NotNil(t, owner.GrantedBy)
+	assert.EqualValues(t, 1, *owner.GrantedBy)
+	_, err = svc.Grant(ctx, "library", 2, 1)
+	require.NoError(t, err, "重复委派不报错")
+	_, err = svc.Grant(ctx, "archive", 2, 1)
+	require.NoError(t, err)
+	_, err = svc.Grant(ctx, "library", 3, 1)
+	require.NoError(t, err)
+
+	_, err = svc.Grant(ctx, "library", 1, 1)
+	assert.ErrorIs(t, err, ErrInvalidOwner, "平台管理员无需委派")
+	_, err = svc.Grant(ctx, "library", 99, 1)
+	assert.ErrorIs(t, err, ErrInvalidOwner)
+	_, err = svc.Grant(ctx, " ", 2, 1)
+	assert.ErrorIs(t, err, ErrInvalidOwner)
+
+	bizs, err := svc.OwnedBizs(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive", "library"}, bizs)
+	owners, err := svc.Owners(ctx, "library")
+	require.NoError(t, err)
+	assert.Len(t, owners, 2)
+
+	ok, err := svc.IsOwner(ctx, "library", 3)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, svc.Revoke(ctx, "library", 3))
+	ok, err = svc.IsOwner(ctx, "library", 3)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.ErrorIs(t, svc.Revoke(ctx, "library", 3), ErrNotFound)
+
+	// 删除用户时一并删除其委派
+	_, err = db.Exec(`DELETE FROM _user WHERE id = 2`)
+	require.NoError(t, err)
+	bizs, err = svc.OwnedBizs(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, bizs)
+}
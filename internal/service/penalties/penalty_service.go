@@ -0,0 +1,375 @@
+// Package penalties file: internal/service/penalties/penalty_service.go
+package penalties
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLoginMaxFailures    = 5
+	defaultLoginLockoutMinutes = 15
+	defaultStrikeWindowMinutes = 10
+	defaultBanMinutes          = 60
+)
+
+// ErrNotFound 表示没有对应的锁定或封禁记录
+var ErrNotFound = errors.New("惩罚记录不存在")
+
+// Options 定义登录锁定与临时封禁的阈值
+type Options struct {
+	// LoginMaxFailures 是同一 IP 对同一账户连续登录失败多少次后锁定，默认为 5
+	LoginMaxFailures int `mapstructure:"login_max_failures"`
+	// LoginLockoutMinutes 是锁定时长 (分钟)，也是失败计数的时间窗口，默认为 15
+	LoginLockoutMinutes int `mapstructure:"login_lockout_minutes"`
+	// StrikeThreshold 是 IP 或用户在时间窗口内被限流拒绝多少次后临时封禁，0 表示不封禁
+	StrikeThreshold int `mapstructure:"strike_threshold"`
+	// StrikeWindowMinutes 是违规计数的时间窗口 (分钟)，默认为 10
+	StrikeWindowMinutes int `mapstructure:"strike_window_minutes"`
+	// BanMinutes 是临时封禁的时长 (分钟)，默认为 60
+	BanMinutes int `mapstructure:"ban_minutes"`
+}
+
+type penaltyKey struct {
+	kind    string
+	subject string
+}
+
+// Service 维护登录失败计数与锁定、IP 与用户的限流违规计数与临时封禁。
+// 状态保存在内存中供每个请求快速判断，同时写入 auth.db，网关启动时恢复，避免通过重启网关绕过锁定。
+// 锁定与封禁立即写入；违规计数变化频繁，由 Run 定期写入
+type Service struct {
+	db   *sql.DB
+	opts Options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[penaltyKey]*domain.Penalty
+	dirty   map[penaltyKey]bool
+	// onLocked 在记录进入锁定或封禁时被调用，用于转发安全事件
+	onLocked func(domain.Penalty)
+}
+
+// NewService 创建一个新的惩罚记录服务实例，并从数据库恢复仍然有效的记录
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("penalties.Service 需要一个有效的数据库连接")
+	}
+	if opts.LoginMaxFailures < 0 || opts.LoginLockoutMinutes < 0 || opts.StrikeThreshold < 0 ||
+		opts.StrikeWindowMinutes < 0 || opts.BanMinutes < 0 {
+		return nil, errors.New("登录锁定与封禁的阈值不能为负数")
+	}
+	if opts.LoginMaxFailures == 0 {
+		opts.LoginMaxFailures = defaultLoginMaxFailures
+	}
+	if opts.LoginLockoutMinutes == 0 {
+		opts.LoginLockoutMinutes = defaultLoginLockoutMinutes
+	}
+	if opts.StrikeWindowMinutes == 0 {
+		opts.StrikeWindowMinutes = defaultStrikeWindowMinutes
+	}
+	if opts.BanMinutes == 0 {
+		opts.BanMinutes = defaultBanMinutes
+	}
+	s := &Service{
+		db:      db,
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[penaltyKey]*domain.Penalty),
+		dirty:   make(map[penaltyKey]bool),
+	}
+	if err := s.restore(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetLockHook 设置记录进入锁定或封禁 (登录锁定、限流封禁与 Ban) 时的回调，应在开始处理请求前调用
+func (s *Service) SetLockHook(fn func(domain.Penalty)) {
+	s.onLocked = fn
+}
+
+// notifyLocked 调用锁定回调
+func (s *Service) notifyLocked(p domain.Penalty) {
+	if s.onLocked != nil {
+		s.onLocked(p)
+	}
+}
+
+// restore 从数据库加载仍在窗口期或锁定期内的记录
+func (s *Service) restore(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT kind, subject, strikes, window_start, locked_until, updated_at FROM rate_limit_penalties`)
+	if err != nil {
+		return fmt.Errorf("读取限流惩罚记录失败: %w", err)
+	}
+	defer rows.Close()
+	now := s.now()
+	restored := 0
+	for rows.Next() {
+		var p domain.Penalty
+		var lockedUntil sql.NullTime
+		if err := rows.Scan(&p.Kind, &p.Subject, &p.Strikes, &p.WindowStart, &lockedUntil, &p.UpdatedAt); err != nil {
+			return fmt.Errorf("读取限流惩罚记录失败: %w", err)
+		}
+		if lockedUntil.Valid {
+			p.LockedUntil = &lockedUntil.Time
+		}
+		if s.expired(&p, now) {
+			continue
+		}
+		s.entries[penaltyKey{p.Kind, p.Subject}] = &p
+		restored++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("读取限流惩罚记录失败: %w", err)
+	}
+	if restored > 0 {
+		slog.Info("[Penalties] 已恢复登录锁定与封禁记录", "count", restored)
+	}
+	return nil
+}
+
+// LoginLocked 判断该 IP 对该账户的登录是否处于锁定中
+func (s *Service) LoginLocked(ip, username string) (time.Time, bool) {
+	return s.Banned(domain.PenaltyKindLogin, loginSubject(ip, username))
+}
+
+// RecordLoginFailure 记录一次登录失败，达到阈值时锁定并返回锁定截止时间
+func (s *Service) RecordLoginFailure(ctx context.Context, ip, username string) (time.Time, bool, error) {
+	key := penaltyKey{domain.PenaltyKindLogin, loginSubject(ip, username)}
+	lockout := time.Duration(s.opts.LoginLockoutMinutes) * time.Minute
+	p, locked, newly := s.strike(key, s.opts.LoginMaxFailures, lockout)
+	// 登录请求量小，每次失败都立即写入
+	if err := s.persist(ctx, p); err != nil {
+		return time.Time{}, false, err
+	}
+	if newly {
+		s.notifyLocked(p)
+	}
+	if locked {
+		return *p.LockedUntil, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// RecordLoginSuccess 在登录成功后清除该 IP 对该账户的失败计数
+func (s *Service) RecordLoginSuccess(ctx context.Context, ip, username string) error {
+	err := s.Lift(ctx, domain.PenaltyKindLogin, loginSubject(ip, username))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Banned 判断对象当前是否处于锁定或封禁中，并返回截止时间
+func (s *Service) Banned(kind, subject string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.entries[penaltyKey{kind, subject}]
+	if !ok || p.LockedUntil == nil || !s.now().Before(*p.LockedUntil) {
+		return time.Time{}, false
+	}
+	return *p.LockedUntil, true
+}
+
+// Strike 记录一次限流违规 (请求被拒绝)，在时间窗口内达到阈值时临时封禁。未配置阈值时什么也不做
+func (s *Service) Strike(kind, subject string) {
+	if s.opts.StrikeThreshold == 0 {
+		return
+	}
+	p, _, newly := s.strike(penaltyKey{kind, subject}, s.opts.StrikeThreshold, time.Duration(s.opts.BanMinutes)*time.Minute)
+	if !newly {
+		return
+	}
+	// 刚刚进入封禁状态，立即写入，避免在下一次定期写入前重启而丢失
+	slog.Warn("[Penalties] 限流违规次数达到阈值，已临时封禁", "kind", kind, "subject", subject, "until", p.LockedUntil)
+	if err := s.persist(context.Background(), p); err != nil {
+		slog.Error("[Penalties] 保存封禁记录失败", "kind", kind, "subject", subject, "error", err)
+	}
+	s.notifyLocked(p)
+}
+
+// Ban 直接封禁对象 duration，覆盖已有的计数与封禁，并立即写入数据库。用于异常行为检测等外部判定
+func (s *Service) Ban(ctx context.Context, kind, subject string, duration time.Duration) (time.Time, error) {
+	key := penaltyKey{kind, subject}
+	s.mu.Lock()
+	now := s.now()
+	until := now.Add(duration)
+	p := &domain.Penalty{Kind: kind, Subject: subject, WindowStart: now, LockedUntil: &until, UpdatedAt: now}
+	s.entries[key] = p
+	delete(s.dirty, key)
+	snapshot := *p
+	s.mu.Unlock()
+	if err := s.persist(ctx, snapshot); err != nil {
+		return time.Time{}, err
+	}
+	s.notifyLocked(snapshot)
+	return until, nil
+}
+
+// strike 对 key 计一次违规，达到 threshold 时锁定 duration 并清零计数。已处于锁定中时不再计数。
+// 返回记录的副本、是否处于锁定中，以及是否由本次违规刚刚进入锁定
+func (s *Service) strike(key penaltyKey, threshold int, duration time.Duration) (p domain.Penalty, locked, newly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	entry, ok := s.entries[key]
+	if ok && entry.LockedUntil != nil && now.Before(*entry.LockedUntil) {
+		return *entry, true, false
+	}
+	if !ok || s.expired(entry, now) {
+		entry = &domain.Penalty{Kind: key.kind, Subject: key.subject, WindowStart: now}
+		s.entries[key] = entry
+	}
+	entry.Strikes++
+	entry.UpdatedAt = now
+	if entry.Strikes >= threshold {
+		until := now.Add(duration)
+		entry.LockedUntil = &until
+		entry.Strikes = 0
+		newly = true
+	}
+	s.dirty[key] = true
+	return *entry, newly, newly
+}
+
+// List 返回当前有效的全部记录，锁定中的在前
+func (s *Service) List() []domain.Penalty {
+	s.mu.Lock()
+	now := s.now()
+	list := make([]domain.Penalty, 0, len(s.entries))
+	for _, p := range s.entries {
+		if !s.expired(p, now) {
+			list = append(list, *p)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].LockedUntil != nil) != (list[j].LockedUntil != nil) {
+			return list[i].LockedUntil != nil
+		}
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Subject < list[j].Subject
+	})
+	return list
+}
+
+// Lift 删除一条记录，解除锁定或封禁
+func (s *Service) Lift(ctx context.Context, kind, subject string) error {
+	key := penaltyKey{kind, subject}
+	s.mu.Lock()
+	_, ok := s.entries[key]
+	delete(s.entries, key)
+	delete(s.dirty, key)
+	s.mu.Unlock()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_penalties WHERE kind = ? AND subject = ?`, kind, subject)
+	if err != nil {
+		return fmt.Errorf("删除限流惩罚记录失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 && !ok {
+		return fmt.Errorf("%w: %s '%s'", ErrNotFound, kind, subject)
+	}
+	return nil
+}
+
+// Run 按间隔写入变化的违规计数并清理过期记录，ctx 结束时写入最后一次后返回
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background())
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush 写入有变化的记录，并从内存与数据库中删除已过期的记录
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	now := s.now()
+	var changed []domain.Penalty
+	var expired []penaltyKey
+	for key, p := range s.entries {
+		if s.expired(p, now) {
+			expired = append(expired, key)
+			delete(s.entries, key)
+			delete(s.dirty, key)
+			continue
+		}
+		if s.dirty[key] {
+			changed = append(changed, *p)
+			delete(s.dirty, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, p := range changed {
+		if err := s.persist(ctx, p); err != nil {
+			slog.Warn("[Penalties] 保存违规计数失败，稍后重试", "kind", p.Kind, "subject", p.Subject, "error", err)
+			s.mu.Lock()
+			if _, ok := s.entries[penaltyKey{p.Kind, p.Subject}]; ok {
+				s.dirty[penaltyKey{p.Kind, p.Subject}] = true
+			}
+			s.mu.Unlock()
+		}
+	}
+	for _, key := range expired {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_penalties WHERE kind = ? AND subject = ?`, key.kind, key.subject); err != nil {
+			slog.Warn("[Penalties] 删除过期记录失败", "kind", key.kind, "subject", key.subject, "error", err)
+		}
+	}
+}
+
+func (s *Service) persist(ctx context.Context, p domain.Penalty) error {
+	var lockedUntil sql.NullTime
+	if p.LockedUntil != nil {
+		lockedUntil = sql.NullTime{Time: *p.LockedUntil, Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO rate_limit_penalties (kind, subject, strikes, window_start, locked_until, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, subject) DO UPDATE SET
+			strikes = excluded.strikes, window_start = excluded.window_start,
+			locked_until = excluded.locked_until, updated_at = excluded.updated_at`,
+		p.Kind, p.Subject, p.Strikes, p.WindowStart, lockedUntil, p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("保存限流惩罚记录失败: %w", err)
+	}
+	return nil
+}
+
+// expired 判断记录是否已失效：锁定已到期，或未锁定且计数窗口已过
+func (s *Service) expired(p *domain.Penalty, now time.Time) bool {
+	if p.LockedUntil != nil {
+		return !now.Before(*p.LockedUntil)
+	}
+	return now.Sub(p.WindowStart) > s.window(p.Kind)
+}
+
+func (s *Service) window(kind string) time.Duration {
+	if kind == domain.PenaltyKindLogin {
+		return time.Duration(s.opts.LoginLockoutMinutes) * time.Minute
+	}
+	return time.Duration(s.opts.StrikeWindowMinutes) * time.Minute
+}
+
+// loginSubject 按 IP 与账户组合计数，避免攻击者通过不断输错密码锁定他人的账户
+func loginSubject(ip, username string) string {
+	return ip + "|" + strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,161 @@
+// file: internal/service/penalties/penalty_service_test.go
+package penalties
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, db *sql.DB, opts Options, now *time.Time) *Service {
+	t.Helper()
+	svc, err := NewService(db, opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return *now }
+	return svc
+}
+
+func TestNewService_Validation(t *testing.T) {
+	_, err := NewService(nil, Options{})
+	assert.Error(t, err)
+	_, err = NewService(fixture.NewSystemDB(t), Options{StrikeThreshold: -1})
+	assert.Error(t, err)
+}
+
+func TestLoginLockout_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := newTestService(t, db, Options{LoginMaxFailures: 3, LoginLockoutMinutes: 10}, &now)
+
+	for i := 0; i < 2; i++ {
+		_, locked, err := svc.RecordLoginFailure(ctx, "10.0.0.1", "Alice")
+		require.NoError(t, err)
+		assert.False(t, locked)
+	}
+	until, locked, err := svc.RecordLoginFailure(ctx, "10.0.0.1", "alice")
+	require.NoError(t, err)
+	require.True(t, locked, "用户名不区分大小写")
+	assert.Equal(t, now.Add(10*time.Minute), until)
+	_, locked = svc.LoginLocked("10.0.0.2", "alice")
+	assert.False(t, locked, "锁定只针对同一 IP")
+
+	// 重新创建服务 (模拟重启) 后锁定仍然有效，到期后解除
+	restarted := newTestService(t, db, Options{LoginMaxFailures: 3, LoginLockoutMinutes: 10}, &now)
+	_, locked = restarted.LoginLocked("10.0.0.1", "alice")
+	assert.True(t, locked)
+	now = now.Add(11 * time.Minute)
+	_, locked = restarted.LoginLocked("10.0.0.1", "alice")
+	assert.False(t, locked)
+}
+
+func TestLoginSuccess_ClearsFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := newTestService(t, fixture.NewSystemDB(t), Options{LoginMaxFailures: 2}, &now)
+
+	_, _, err := svc.RecordLoginFailure(ctx, "10.0.0.1", "bob")
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordLoginSuccess(ctx, "10.0.0.1", "bob"))
+	_, locked, err := svc.RecordLoginFailure(ctx, "10.0.0.1", "bob")
+	require.NoError(t, err)
+	assert.False(t, locked, "登录成功后失败计数重新开始")
+	require.NoError(t, svc.RecordLoginSuccess(ctx, "10.0.0.9", "nobody"), "没有记录时同样成功")
+}
+
+func TestStrike_BanAndFlush(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	opts := Options{StrikeThreshold: 3, StrikeWindowMinutes: 5, BanMinutes: 30}
+	svc := newTestService(t, db, opts, &now)
+
+	svc.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	svc.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	now = now.Add(6 * time.Minute)
+	svc.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	_, banned := svc.Banned(domain.PenaltyKindIP, "10.0.0.1")
+	assert.False(t, banned, "窗口过期后重新计数")
+
+	// 违规计数由 flush 定期写入，重启后恢复
+	svc.flush(ctx)
+	restarted := newTestService(t, db, opts, &now)
+	restarted.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	restarted.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	until, banned := restarted.Banned(domain.PenaltyKindIP, "10.0.0.1")
+	require.True(t, banned)
+	assert.Equal(t, now.Add(30*time.Minute), until)
+
+	list := restarted.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, "10.0.0.1", list[0].Subject)
+	require.NoError(t, restarted.Lift(ctx, domain.PenaltyKindIP, "10.0.0.1"))
+	_, banned = restarted.Banned(domain.PenaltyKindIP, "10.0.0.1")
+	assert.False(t, banned)
+	assert.ErrorIs(t, restarted.Lift(ctx, domain.PenaltyKindIP, "10.0.0.1"), ErrNotFound)
+
+	// 过期记录在 flush 时从数据库删除
+	svc.Strike(domain.PenaltyKindUser, "7")
+	svc.flush(ctx)
+	now = now.Add(time.Hour)
+	svc.flush(ctx)
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM rate_limit_penalties`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestStrike_DisabledByDefault(t *testing.T) {
+	now := time.Now()
+	svc := newTestService(t, fixture.NewSystemDB(t), Options{}, &now)
+	for i := 0; i < 1000; i++ {
+		svc.Strike(domain.PenaltyKindIP, "10.0.0.1")
+	}
+	_, banned := svc.Banned(domain.PenaltyKindIP, "10.0.0.1")
+	assert.False(t, banned)
+	assert.Empty(t, svc.List())
+}
+
+func TestBan_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	svc := newTestService(t, db, Options{}, &now)
+
+	until, err := svc.Ban(ctx, domain.PenaltyKindUser, "7", 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Hour), until)
+
+	restarted := newTestService(t, db, Options{}, &now)
+	got, banned := restarted.Banned(domain.PenaltyKindUser, "7")
+	require.True(t, banned, "外部判定的封禁立即写入，重启后仍然有效")
+	assert.Equal(t, until, got)
+}
+
+func TestLockHook_CalledOnceWhenLocked(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	svc := newTestService(t, fixture.NewSystemDB(t), Options{LoginMaxFailures: 2, StrikeThreshold: 1}, &now)
+	var locked []domain.Penalty
+	svc.SetLockHook(func(p domain.Penalty) { locked = append(locked, p) })
+
+	for i := 0; i < 3; i++ {
+		_, _, err := svc.RecordLoginFailure(ctx, "10.0.0.1", "alice")
+		require.NoError(t, err)
+	}
+	svc.Strike(domain.PenaltyKindIP, "10.0.0.9")
+	_, err := svc.Ban(ctx, domain.PenaltyKindUser, "7", time.Minute)
+	require.NoError(t, err)
+
+	require.Len(t, locked, 3, "锁定期间的失败不会重复触发回调")
+	assert.Equal(t, domain.PenaltyKindLogin, locked[0].Kind)
+	assert.Equal(t, "10.0.0.1|alice", locked[0].Subject)
+	assert.Equal(t, domain.PenaltyKindIP, locked[1].Kind)
+	assert.Equal(t, "7", locked[2].Subject)
+	assert.NotNil(t, locked[2].LockedUntil)
+}
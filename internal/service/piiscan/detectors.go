@@ -0,0 +1,120 @@
+// Package piiscan file: internal/service/piiscan/detectors.go
+package piiscan
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"net"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	// 中国大陆手机号 (可带 +86 前缀)，以及带国家码的国际号码
+	mobilePattern        = regexp.MustCompile(`(?:^|\D)(?:\+?86[\s\-]?)?1[3-9]\d{9}(?:\D|$)`)
+	internationalPattern = regexp.MustCompile(`(?:^|[^\d+])\+\d{1,3}[\s\-]?\(?\d{1,4}\)?(?:[\s\-]?\d{2,4}){2,4}(?:\D|$)`)
+	nationalIDPattern    = regexp.MustCompile(`(?:^|\D)(\d{17}[\dXx])(?:\D|$)`)
+	cardPattern          = regexp.MustCompile(`(?:^|\D)(\d(?:[\s\-]?\d){12,18})(?:\D|$)`)
+	ipv4Pattern          = regexp.MustCompile(`(?:^|[^\d.])(\d{1,3}(?:\.\d{1,3}){3})(?:[^\d.]|$)`)
+)
+
+// nameHints 是字段名 (小写) 中暗示个人信息类型的片段，按顺序匹配第一个命中的类型
+var nameHints = []struct {
+	kind      string
+	fragments []string
+}{
+	{domain.PIIKindEmail, []string{"email", "e_mail", "mail", "邮箱"}},
+	{domain.PIIKindPhone, []string{"phone", "mobile", "tel", "手机", "电话"}},
+	{domain.PIIKindNationalID, []string{"id_card", "idcard", "id_no", "national_id", "ssn", "passport", "身份证", "证件"}},
+	{domain.PIIKindCreditCard, []string{"card_no", "card_number", "credit_card", "bank_card", "银行卡", "卡号"}},
+	{domain.PIIKindIPAddress, []string{"ip_addr", "ip_address", "client_ip", "remote_ip"}},
+}
+
+// nameHint 返回字段名暗示的个人信息类型，没有时返回空
+func nameHint(field string) string {
+	name := strings.ToLower(field)
+	if name == "ip" || strings.HasSuffix(name, "_ip") {
+		return domain.PIIKindIPAddress
+	}
+	for _, hint := range nameHints {
+		for _, fragment := range hint.fragments {
+			if strings.Contains(name, fragment) {
+				return hint.kind
+			}
+		}
+	}
+	return ""
+}
+
+// detect 返回值中出现的个人信息类型，每种类型至多出现一次。
+// 带国家码的电话号码与合法的身份证号都可能满足卡号的格式，此时只计为电话号码或身份证号
+func detect(value string) []string {
+	var kinds []string
+	if emailPattern.MatchString(value) {
+		kinds = append(kinds, domain.PIIKindEmail)
+	}
+	if mobilePattern.MatchString(value) || internationalPattern.MatchString(value) {
+		kinds = append(kinds, domain.PIIKindPhone)
+		value = internationalPattern.ReplaceAllString(mobilePattern.ReplaceAllString(value, " "), " ")
+	}
+	nationalID := false
+	for _, m := range nationalIDPattern.FindAllStringSubmatch(value, -1) {
+		if validNationalID(m[1]) {
+			nationalID = true
+			break
+		}
+	}
+	if nationalID {
+		kinds = append(kinds, domain.PIIKindNationalID)
+	} else {
+		for _, m := range cardPattern.FindAllStringSubmatch(value, -1) {
+			if validCardNumber(m[1]) {
+				kinds = append(kinds, domain.PIIKindCreditCard)
+				break
+			}
+		}
+	}
+	for _, m := range ipv4Pattern.FindAllStringSubmatch(value, -1) {
+		if ip := net.ParseIP(m[1]); ip != nil && ip.To4() != nil {
+			kinds = append(kinds, domain.PIIKindIPAddress)
+			break
+		}
+	}
+	return kinds
+}
+
+// validNationalID 按 GB 11643 校验 18 位居民身份证号的校验码
+func validNationalID(id string) bool {
+	if len(id) != 18 {
+		return false
+	}
+	weights := [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	const checks = "10X98765432"
+	sum := 0
+	for i := 0; i < 17; i++ {
+		sum += int(id[i]-'0') * weights[i]
+	}
+	return strings.ToUpper(id[17:]) == string(checks[sum%11])
+}
+
+// validCardNumber 去除空格与连字符后按 Luhn 算法校验 13-19 位卡号，全部相同的数字不视为卡号
+func validCardNumber(raw string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(raw)
+	if len(digits) < 13 || len(digits) > 19 || strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
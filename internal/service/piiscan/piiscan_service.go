@@ -0,0 +1,246 @@
+// Package piiscan file: internal/service/piiscan/piiscan_service.go
+package piiscan
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSampleSize = 200
+	// maxSampleSize 与数据源抽样模式的上限一致 (SQLite 数据源为 2000)
+	maxSampleSize    = 2000
+	defaultThreshold = 0.3
+)
+
+// ErrNotFound 表示业务组尚未扫描过
+var ErrNotFound = errors.New("扫描结果不存在")
+
+// Options 定义个人信息扫描的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SampleSize 是每张表随机抽取的行数，默认为 200，最大为 2000
+	SampleSize int `mapstructure:"sample_size"`
+	// Threshold 是判定字段包含个人信息的命中比例 (命中数 / 非空值数)，取值 (0, 1]，默认为 0.3
+	Threshold float64 `mapstructure:"threshold"`
+}
+
+// Service 对业务组各表随机抽样，用正则与校验算法识别可返回字段中的邮箱、电话、身份证号、卡号与 IP 地址，
+// 结合字段名给出可能包含个人信息的字段，供管理员在公开前调整字段的可返回性。
+// 抽样经由数据源的普通查询进行，只能覆盖开放检索的表；每个业务组保存最近一次的扫描结果
+type Service struct {
+	db         *sql.DB
+	config     port.QueryAdminConfigService
+	registry   map[string]port.DataSource
+	sampleSize int
+	threshold  float64
+	now        func() time.Time
+}
+
+// NewService 创建一个新的个人信息扫描服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("piiscan.Service 需要一个有效的数据库连接")
+	}
+	if config == nil {
+		return nil, errors.New("piiscan.Service 需要有效的配置服务")
+	}
+	if opts.SampleSize < 0 || opts.SampleSize > maxSampleSize {
+		return nil, fmt.Errorf("sample_size 必须在 0 到 %d 之间: %d", maxSampleSize, opts.SampleSize)
+	}
+	if opts.Threshold < 0 || opts.Threshold > 1 {
+		return nil, fmt.Errorf("threshold 必须在 0 到 1 之间: %v", opts.Threshold)
+	}
+	if opts.SampleSize == 0 {
+		opts.SampleSize = defaultSampleSize
+	}
+	if opts.Threshold == 0 {
+		opts.Threshold = defaultThreshold
+	}
+	return &Service{
+		db:         db,
+		config:     config,
+		registry:   registry,
+		sampleSize: opts.SampleSize,
+		threshold:  opts.Threshold,
+		now:        time.Now,
+	}, nil
+}
+
+// Scan 扫描业务组中所有表的可返回字段，保存并返回扫描结果。单张表抽样失败时记录在结果中并继续扫描其他表
+func (s *Service) Scan(ctx context.Context, bizName string, actor int64) (*domain.PIIScanReport, error) {
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+
+	report := &domain.PIIScanReport{
+		BizName:    bizName,
+		Public:     bizConfig.IsPubliclySearchable,
+		SampleSize: s.sampleSize,
+		Threshold:  s.threshold,
+		Tables:     make([]domain.PIITableScan, 0, len(bizConfig.Tables)),
+		ScannedBy:  actor,
+		ScannedAt:  s.now().UTC(),
+	}
+	tableNames := make([]string, 0, len(bizConfig.Tables))
+	for name := range bizConfig.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+	for _, name := range tableNames {
+		table, scanned := s.scanTable(ctx, dataSource, bizName, bizConfig.Tables[name])
+		if !scanned {
+			continue
+		}
+		for _, f := range table.Fields {
+			if f.Flagged {
+				report.Flagged++
+			}
+		}
+		report.Tables = append(report.Tables, table)
+	}
+
+	raw, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	var scannedBy interface{}
+	if actor > 0 {
+		scannedBy = actor
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO pii_scan_reports (biz_name, report, scanned_by, scanned_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(biz_name) DO UPDATE SET report = excluded.report, scanned_by = excluded.scanned_by, scanned_at = excluded.scanned_at`,
+		bizName, string(raw), scannedBy, report.ScannedAt); err != nil {
+		return nil, fmt.Errorf("保存扫描结果失败: %w", err)
+	}
+	return report, nil
+}
+
+// scanTable 抽样并评估一张表的可返回字段，没有可返回字段时返回 false
+func (s *Service) scanTable(ctx context.Context, dataSource port.DataSource, bizName string, tableConfig *domain.TableConfig) (domain.PIITableScan, bool) {
+	table := domain.PIITableScan{TableName: tableConfig.TableName, Fields: make([]domain.PIIFieldScan, 0)}
+	var returnable []string
+	for name, setting := range tableConfig.Fields {
+		if setting.IsReturnable {
+			returnable = append(returnable, name)
+		}
+	}
+	if len(returnable) == 0 {
+		return table, false
+	}
+	sort.Strings(returnable)
+	if !tableConfig.IsSearchable {
+		table.Error = "表未开放检索，无法抽样"
+		return table, true
+	}
+
+	fieldsToReturn := make([]interface{}, len(returnable))
+	for i, f := range returnable {
+		fieldsToReturn[i] = f
+	}
+	res, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			"table":             tableConfig.TableName,
+			port.QuerySampleKey: float64(s.sampleSize),
+			"fields_to_return":  fieldsToReturn,
+		},
+	})
+	if err != nil {
+		table.Error = err.Error()
+		return table, true
+	}
+	items, _ := res.Data["items"].([]interface{})
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	table.Sampled = len(rows)
+	for _, field := range returnable {
+		table.Fields = append(table.Fields, s.scanField(field, tableConfig.Fields[field].IsSearchable, rows))
+	}
+	return table, true
+}
+
+// scanField 统计字段样本中各类个人信息的命中比例
+func (s *Service) scanField(field string, searchable bool, rows []map[string]interface{}) domain.PIIFieldScan {
+	scan := domain.PIIFieldScan{Field: field, Searchable: searchable, Detections: make([]domain.PIIDetection, 0), NameHint: nameHint(field)}
+	counts := make(map[string]int)
+	for _, row := range rows {
+		value := strings.TrimSpace(stringify(row[field]))
+		if value == "" {
+			continue
+		}
+		scan.NonEmpty++
+		for _, kind := range detect(value) {
+			counts[kind]++
+		}
+	}
+	for kind, n := range counts {
+		ratio := float64(n) / float64(scan.NonEmpty)
+		scan.Detections = append(scan.Detections, domain.PIIDetection{Kind: kind, Matches: n, Ratio: ratio})
+		if ratio >= s.threshold {
+			scan.Flagged = true
+		}
+	}
+	sort.Slice(scan.Detections, func(i, j int) bool {
+		if scan.Detections[i].Ratio != scan.Detections[j].Ratio {
+			return scan.Detections[i].Ratio > scan.Detections[j].Ratio
+		}
+		return scan.Detections[i].Kind < scan.Detections[j].Kind
+	})
+	if scan.NameHint != "" && scan.NonEmpty > 0 {
+		scan.Flagged = true
+	}
+	return scan
+}
+
+// LastReport 返回业务组最近一次的扫描结果
+func (s *Service) LastReport(ctx context.Context, bizName string) (*domain.PIIScanReport, error) {
+	var raw string
+	err := s.db.QueryRowContext(ctx, `SELECT report FROM pii_scan_reports WHERE biz_name = ?`, bizName).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: 业务组 '%s' 尚未扫描", ErrNotFound, bizName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取扫描结果失败: %w", err)
+	}
+	var report domain.PIIScanReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		return nil, fmt.Errorf("解析扫描结果失败: %w", err)
+	}
+	return &report, nil
+}
+
+func stringify(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
@@ -0,0 +1,167 @@
+// file: internal/service/piiscan/piiscan_service_test.go
+package piiscan
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestService(t *testing.T) (*Service, *sql.DB, *fixture.FakeDataSource) {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (7, 'auditor', 'x', 'admin')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('archive', TRUE, 'readers')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "archive", []string{"readers"}))
+	require.NoError(t, config.UpdateTableFieldSettings(ctx, "archive", "readers", []domain.FieldSetting{
+		{FieldName: "name", IsSearchable: true, IsReturnable: true},
+		{FieldName: "contact", IsReturnable: true},
+		{FieldName: "mobile", IsReturnable: true},
+		{FieldName: "internal_code"},
+	}))
+	require.NoError(t, config.UpdateTableWritePermissions(ctx, "archive", "loans", domain.TableConfig{}))
+	require.NoError(t, config.UpdateTableFieldSettings(ctx, "archive", "loans", []domain.FieldSetting{
+		{FieldName: "card_no", IsReturnable: true},
+	}))
+
+	ds := fixture.NewFakeDataSource().WithTable("readers", nil,
+		map[string]interface{}{"name": "张三", "contact": "zhangsan@example.com", "mobile": nil},
+		map[string]interface{}{"name": "李四", "contact": "电话 13812345678", "mobile": nil},
+		map[string]interface{}{"name": "王五", "contact": "lisi@example.org", "mobile": ""},
+		map[string]interface{}{"name": "赵六", "contact": "", "mobile": nil},
+	)
+	svc, err := NewService(db, config, map[string]port.DataSource{"archive": ds}, Options{SampleSize: 50, Threshold: 0.5})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC) }
+	return svc, db, ds
+}
+
+func TestNewService_Validation(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+
+	_, err = NewService(nil, config, nil, Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, config, nil, Options{SampleSize: maxSampleSize + 1})
+	assert.Error(t, err)
+	_, err = NewService(db, config, nil, Options{Threshold: 1.5})
+	assert.Error(t, err)
+
+	svc, err := NewService(db, config, nil, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultSampleSize, svc.sampleSize)
+	assert.Equal(t, defaultThreshold, svc.threshold)
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  []string
+	}{
+		{"联系 zhangsan@example.com", []string{domain.PIIKindEmail}},
+		{"13812345678", []string{domain.PIIKindPhone}},
+		{"+86 138-1234-5678", []string{domain.PIIKindPhone}},
+		{"+1 415-555-2671", []string{domain.PIIKindPhone}},
+		{"11010519491231002X", []string{domain.PIIKindNationalID}},
+		{"110105194912310021", nil}, // 校验码错误
+		{"4111 1111 1111 1111", []string{domain.PIIKindCreditCard}},
+		{"4111111111111112", nil}, // 不满足 Luhn
+		{"0000000000000000", nil},
+		{"来自 192.168.1.20", []string{domain.PIIKindIPAddress}},
+		{"版本 1.2.3.4.5", nil},
+		{"999.1.1.1", nil},
+		{"1949 年的信件", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.want, detect(tc.value))
+		})
+	}
+}
+
+func TestNameHint(t *testing.T) {
+	assert.Equal(t, domain.PIIKindEmail, nameHint("Email"))
+	assert.Equal(t, domain.PIIKindPhone, nameHint("mobile_number"))
+	assert.Equal(t, domain.PIIKindNationalID, nameHint("身份证号"))
+	assert.Equal(t, domain.PIIKindCreditCard, nameHint("bank_card_no"))
+	assert.Equal(t, domain.PIIKindIPAddress, nameHint("client_ip"))
+	assert.Equal(t, domain.PIIKindIPAddress, nameHint("ip"))
+	assert.Equal(t, "", nameHint("zip"))
+	assert.Equal(t, "", nameHint("title"))
+}
+
+func TestScan_FlagsReturnableFields(t *testing.T) {
+	svc, _, ds := newTestService(t)
+	ctx := context.Background()
+
+	report, err := svc.Scan(ctx, "archive", 7)
+	require.NoError(t, err)
+	assert.True(t, report.Public)
+	assert.Equal(t, 50, report.SampleSize)
+	assert.Equal(t, int64(7), report.ScannedBy)
+	queries := ds.Queries()
+	sample := queries[len(queries)-1].Query
+	assert.Equal(t, float64(50), sample[port.QuerySampleKey])
+	assert.Equal(t, []interface{}{"contact", "mobile", "name"}, sample["fields_to_return"])
+
+	require.Len(t, report.Tables, 2)
+	loans := report.Tables[0]
+	assert.Equal(t, "loans", loans.TableName)
+	assert.NotEmpty(t, loans.Error, "未开放检索的表无法抽样")
+
+	readers := report.Tables[1]
+	assert.Equal(t, "readers", readers.TableName)
+	assert.Equal(t, 4, readers.Sampled)
+	require.Len(t, readers.Fields, 3)
+
+	contact := readers.Fields[0]
+	assert.Equal(t, "contact", contact.Field)
+	assert.Equal(t, 3, contact.NonEmpty)
+	require.Len(t, contact.Detections, 2)
+	assert.Equal(t, domain.PIIKindEmail, contact.Detections[0].Kind)
+	assert.Equal(t, 2, contact.Detections[0].Matches)
+	assert.InDelta(t, 2.0/3, contact.Detections[0].Ratio, 1e-9)
+	assert.True(t, contact.Flagged)
+
+	// 字段名暗示个人信息，但样本全部为空时不标记
+	mobile := readers.Fields[1]
+	assert.Equal(t, domain.PIIKindPhone, mobile.NameHint)
+	assert.False(t, mobile.Flagged)
+
+	name := readers.Fields[2]
+	assert.True(t, name.Searchable)
+	assert.False(t, name.Flagged)
+	assert.Equal(t, 1, report.Flagged)
+
+	last, err := svc.LastReport(ctx, "archive")
+	require.NoError(t, err)
+	assert.Equal(t, report.Flagged, last.Flagged)
+	assert.Equal(t, report.ScannedAt, last.ScannedAt)
+	assert.Len(t, last.Tables, 2)
+}
+
+func TestScan_UnknownBiz(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	_, err := svc.Scan(context.Background(), "missing", 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+
+	_, err = svc.LastReport(context.Background(), "archive")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
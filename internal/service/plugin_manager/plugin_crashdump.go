@@ -0,0 +1,399 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_crashdump.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultCrashLogLines 是为每个实例保留的最近日志行数
+	DefaultCrashLogLines = 500
+	// DefaultCrashThreshold 与 DefaultCrashWindow：实例在窗口内崩溃达到该次数时自动收集诊断包
+	DefaultCrashThreshold = 3
+	DefaultCrashWindow    = 10 * time.Minute
+	// DefaultMaxCrashDumps 是每个实例保留的诊断包数，超出时删除最早的
+	DefaultMaxCrashDumps = 5
+
+	// maxExitRecords 与 maxRPCErrors 是每个实例在内存中保留的退出记录与 gRPC 错误条数
+	maxExitRecords = 20
+	maxRPCErrors   = 50
+	// maxLogLineBytes 是单行日志保留的最大字节数，超长的行被截断
+	maxLogLineBytes = 4096
+	// crashDumpDirName 是诊断包在 instance 目录下的存放目录
+	crashDumpDirName = "crash_dumps"
+)
+
+// ErrCrashDumpNotFound 表示指定的诊断包不存在
+var ErrCrashDumpNotFound = errors.New("诊断包不存在")
+
+// crashDumpNamePattern 限定诊断包的文件名，防止下载接口被用于读取其它文件
+var crashDumpNamePattern = regexp.MustCompile(`^[A-Za-z0-9-]+-\d{8}T\d{9}Z-[a-z_]+\.zip$`)
+
+// CrashDumpPolicy 控制插件实例的诊断信息收集。网关始终为独立进程实例保留最近的日志、退出记录与 gRPC 错误，
+// Enabled 只决定反复崩溃时是否自动生成诊断包，管理员随时可以手动生成
+type CrashDumpPolicy struct {
+	Enabled bool `mapstructure:"enabled"`
+	// LogLines 是每个实例保留的最近日志行数 (标准输出与标准错误合并)
+	LogLines int `mapstructure:"log_lines"`
+	// Threshold 与 Window：实例在 Window 内崩溃 Threshold 次时生成诊断包，同一窗口内只生成一次
+	Threshold int           `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+	// MaxDumps 是每个实例保留的诊断包数
+	MaxDumps int `mapstructure:"max_dumps"`
+}
+
+// normalized 返回补全了默认值的策略
+func (p CrashDumpPolicy) normalized() CrashDumpPolicy {
+	if p.LogLines <= 0 {
+		p.LogLines = DefaultCrashLogLines
+	}
+	if p.Threshold <= 0 {
+		p.Threshold = DefaultCrashThreshold
+	}
+	if p.Window <= 0 {
+		p.Window = DefaultCrashWindow
+	}
+	if p.MaxDumps <= 0 {
+		p.MaxDumps = DefaultMaxCrashDumps
+	}
+	return p
+}
+
+// SetCrashDumpPolicy 设置诊断信息的收集策略，日志行数只影响此后首次启动的实例
+func (pm *PluginManager) SetCrashDumpPolicy(policy CrashDumpPolicy) {
+	pm.diagnosticsMu.Lock()
+	defer pm.diagnosticsMu.Unlock()
+	pm.crashDumpPolicy = policy.normalized()
+}
+
+// lineRing 是一个 io.Writer，保留最近写入的若干行。插件的标准输出与标准错误在不同的 goroutine 中写入
+type lineRing struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int
+	full    bool
+	partial []byte
+}
+
+func newLineRing(capacity int) *lineRing {
+	return &lineRing{lines: make([]string, capacity)}
+}
+
+func (r *lineRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.partial = append(r.partial, data...)
+			if len(r.partial) > maxLogLineBytes {
+				r.partial = r.partial[:maxLogLineBytes]
+			}
+			break
+		}
+		r.partial = append(r.partial, data[:i]...)
+		r.push(string(r.partial))
+		r.partial = r.partial[:0]
+		data = data[i+1:]
+	}
+	return len(p), nil
+}
+
+func (r *lineRing) push(line string) {
+	if len(line) > maxLogLineBytes {
+		line = line[:maxLogLineBytes]
+	}
+	r.lines[r.next] = strings.TrimRight(line, "\r")
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Lines 按写入顺序返回保留的行，包括尚未以换行结束的最后一行
+func (r *lineRing) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	if r.full {
+		out = append(out, r.lines[r.next:]...)
+	}
+	out = append(out, r.lines[:r.next]...)
+	if len(r.partial) > 0 {
+		out = append(out, string(r.partial))
+	}
+	return out
+}
+
+// instanceDiagnostics 是网关为一个实例保留的诊断信息，跨越实例的多次启动
+type instanceDiagnostics struct {
+	mu        sync.Mutex
+	logs      *lineRing
+	exits     []domain.PluginExit
+	rpcErrors []domain.PluginRPCError
+	// lastAutoDump 是最近一次自动生成诊断包的时间
+	lastAutoDump time.Time
+}
+
+// diagnosticsFor 返回实例的诊断信息，不存在时创建
+func (pm *PluginManager) diagnosticsFor(instanceID string) *instanceDiagnostics {
+	pm.diagnosticsMu.Lock()
+	defer pm.diagnosticsMu.Unlock()
+	diag, ok := pm.diagnostics[instanceID]
+	if !ok {
+		diag = &instanceDiagnostics{logs: newLineRing(pm.crashDumpPolicy.LogLines)}
+		pm.diagnostics[instanceID] = diag
+	}
+	return diag
+}
+
+// recordRPCError 记录网关调用实例时的一次 gRPC 错误
+func (pm *PluginManager) recordRPCError(instanceID, method string, err error) {
+	st, _ := status.FromError(err)
+	diag := pm.diagnosticsFor(instanceID)
+	diag.mu.Lock()
+	defer diag.mu.Unlock()
+	diag.rpcErrors = append(diag.rpcErrors, domain.PluginRPCError{
+		At: time.Now().UTC(), Method: method, Code: st.Code().String(), Message: st.Message(),
+	})
+	if len(diag.rpcErrors) > maxRPCErrors {
+		diag.rpcErrors = diag.rpcErrors[len(diag.rpcErrors)-maxRPCErrors:]
+	}
+}
+
+// recordExit 记录已退出的实例进程的退出状态；属于崩溃且在窗口内达到阈值时生成诊断包
+func (pm *PluginManager) recordExit(instanceID string, proc *pluginProcess, reason string) {
+	exit := domain.PluginExit{At: time.Now().UTC(), Reason: reason, ExitCode: -1}
+	if state := proc.cmd.ProcessState; state != nil {
+		exit.ExitCode = state.ExitCode()
+		exit.Status = state.String()
+	} else if proc.waitErr != nil {
+		exit.Status = proc.waitErr.Error()
+	}
+
+	pm.diagnosticsMu.Lock()
+	policy := pm.crashDumpPolicy
+	pm.diagnosticsMu.Unlock()
+	diag := pm.diagnosticsFor(instanceID)
+	diag.mu.Lock()
+	diag.exits = append(diag.exits, exit)
+	if len(diag.exits) > maxExitRecords {
+		diag.exits = diag.exits[len(diag.exits)-maxExitRecords:]
+	}
+	crashes := 0
+	for _, e := range diag.exits {
+		if stopEvent(e.Reason) == EventCrashed && exit.At.Sub(e.At) <= policy.Window {
+			crashes++
+		}
+	}
+	collect := policy.Enabled && stopEvent(reason) == EventCrashed && crashes >= policy.Threshold &&
+		exit.At.Sub(diag.lastAutoDump) > policy.Window
+	if collect {
+		diag.lastAutoDump = exit.At
+	}
+	diag.mu.Unlock()
+
+	if collect {
+		log.Printf("🚨 [PluginManager] 插件实例 '%s' 在 %v 内崩溃 %d 次，正在收集诊断包...", instanceID, policy.Window, crashes)
+		if dump, err := pm.CollectCrashDump(instanceID, domain.CrashDumpTriggerCrashLoop); err != nil {
+			log.Printf("⚠️ [PluginManager] 收集插件实例 '%s' 的诊断包失败: %v", instanceID, err)
+		} else {
+			log.Printf("📦 [PluginManager] 插件实例 '%s' 的诊断包已保存: %s", instanceID, dump.Name)
+		}
+	}
+}
+
+// crashDumpDir 返回诊断包的存放目录
+func (pm *PluginManager) crashDumpDir() string {
+	return filepath.Join(filepath.Dir(pm.installDir), crashDumpDirName)
+}
+
+// crashDumpSummary 是诊断包中 summary.json 的内容
+type crashDumpSummary struct {
+	Trigger     string                  `json:"trigger"`
+	CollectedAt time.Time               `json:"collected_at"`
+	Instance    domain.PluginInstance   `json:"instance"`
+	InstallPath string                  `json:"install_path,omitempty"`
+	Running     bool                    `json:"running"`
+	Exits       []domain.PluginExit     `json:"exits"`
+	Events      []domain.PluginEvent    `json:"events"`
+	RPCErrors   []domain.PluginRPCError `json:"grpc_errors"`
+	Environment map[string]interface{}  `json:"environment"`
+}
+
+// CollectCrashDump 立即为实例生成一个诊断包并返回其信息。诊断包包含 summary.json
+// (实例配置、退出记录、生命周期事件、gRPC 错误与运行环境摘要) 与 plugin.log (最近的日志)。
+// 注入的环境变量只保留名称，值被隐去
+func (pm *PluginManager) CollectCrashDump(instanceID, trigger string) (*domain.PluginCrashDump, error) {
+	instances, err := pm.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	var inst *domain.PluginInstance
+	for i := range instances {
+		if instances[i].InstanceID == instanceID {
+			inst = &instances[i]
+			break
+		}
+	}
+	if inst == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrInstanceNotFound, instanceID)
+	}
+	events, err := pm.ListInstanceEvents(instanceID, DefaultEventLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := *inst
+	envNames := make([]string, 0, len(inst.ProcessOptions.Env))
+	if len(inst.ProcessOptions.Env) > 0 {
+		redacted.ProcessOptions.Env = make(map[string]string, len(inst.ProcessOptions.Env))
+		for name := range inst.ProcessOptions.Env {
+			redacted.ProcessOptions.Env[name] = "***"
+			envNames = append(envNames, name)
+		}
+	}
+	sort.Strings(envNames)
+
+	now := time.Now().UTC()
+	summary := crashDumpSummary{
+		Trigger:     trigger,
+		CollectedAt: now,
+		Instance:    redacted,
+		Running:     inst.Status == "RUNNING",
+		Events:      events,
+		Environment: map[string]interface{}{
+			"gateway_go_version": runtime.Version(),
+			"os":                 runtime.GOOS,
+			"arch":               runtime.GOARCH,
+			"num_cpu":            runtime.NumCPU(),
+			"injected_env_names": envNames,
+		},
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		summary.Environment["hostname"] = hostname
+	}
+	var installPath string
+	if err := pm.db.QueryRow(`SELECT install_path FROM installed_plugins WHERE plugin_id = ? AND version = ?`,
+		inst.PluginID, inst.Version).Scan(&installPath); err == nil {
+		summary.InstallPath = installPath
+	}
+
+	diag := pm.diagnosticsFor(instanceID)
+	diag.mu.Lock()
+	summary.Exits = append([]domain.PluginExit{}, diag.exits...)
+	summary.RPCErrors = append([]domain.PluginRPCError{}, diag.rpcErrors...)
+	diag.mu.Unlock()
+	logLines := diag.logs.Lines()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("summary.json")
+	if err != nil {
+		return nil, fmt.Errorf("写入诊断包失败: %w", err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(summary); err != nil {
+		return nil, fmt.Errorf("写入诊断包失败: %w", err)
+	}
+	if w, err = zw.Create("plugin.log"); err != nil {
+		return nil, fmt.Errorf("写入诊断包失败: %w", err)
+	}
+	for _, line := range logLines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return nil, fmt.Errorf("写入诊断包失败: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("写入诊断包失败: %w", err)
+	}
+
+	dir := pm.crashDumpDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建诊断包目录失败: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s.zip", instanceID, strings.Replace(now.Format("20060102T150405.000Z"), ".", "", 1), trigger)
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0600); err != nil {
+		return nil, fmt.Errorf("保存诊断包失败: %w", err)
+	}
+	pm.pruneCrashDumps(instanceID)
+	return &domain.PluginCrashDump{Name: name, InstanceID: instanceID, Trigger: trigger, CreatedAt: now, SizeBytes: int64(buf.Len())}, nil
+}
+
+// ListCrashDumps 按时间倒序返回实例的诊断包
+func (pm *PluginManager) ListCrashDumps(instanceID string) ([]domain.PluginCrashDump, error) {
+	entries, err := os.ReadDir(pm.crashDumpDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取诊断包目录失败: %w", err)
+	}
+	dumps := make([]domain.PluginCrashDump, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !crashDumpNamePattern.MatchString(name) || !strings.HasPrefix(name, instanceID+"-") {
+			continue
+		}
+		// 文件名为 <实例ID>-<时间>-<原因>.zip，实例ID中也含有 "-"
+		rest := strings.TrimSuffix(strings.TrimPrefix(name, instanceID+"-"), ".zip")
+		stamp, trigger, ok := strings.Cut(rest, "-")
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse("20060102T150405.000Z", stamp[:15]+"."+stamp[15:])
+		if err != nil {
+			continue
+		}
+		dump := domain.PluginCrashDump{Name: name, InstanceID: instanceID, Trigger: trigger, CreatedAt: createdAt}
+		if info, err := entry.Info(); err == nil {
+			dump.SizeBytes = info.Size()
+		}
+		dumps = append(dumps, dump)
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].Name > dumps[j].Name })
+	return dumps, nil
+}
+
+// CrashDumpPath 返回实例的某个诊断包的文件路径
+func (pm *PluginManager) CrashDumpPath(instanceID, name string) (string, error) {
+	if !crashDumpNamePattern.MatchString(name) || !strings.HasPrefix(name, instanceID+"-") {
+		return "", ErrCrashDumpNotFound
+	}
+	path := filepath.Join(pm.crashDumpDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrCrashDumpNotFound
+	}
+	return path, nil
+}
+
+// pruneCrashDumps 删除超出保留数量的最早的诊断包
+func (pm *PluginManager) pruneCrashDumps(instanceID string) {
+	pm.diagnosticsMu.Lock()
+	keep := pm.crashDumpPolicy.MaxDumps
+	pm.diagnosticsMu.Unlock()
+	dumps, err := pm.ListCrashDumps(instanceID)
+	if err != nil || len(dumps) <= keep {
+		return
+	}
+	for _, dump := range dumps[keep:] {
+		if err := os.Remove(filepath.Join(pm.crashDumpDir(), dump.Name)); err != nil {
+			log.Printf("⚠️ [PluginManager] 删除过期的诊断包 '%s' 失败: %v", dump.Name, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// file: internal/service/plugin_manager/plugin_crashdump_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLineRing(t *testing.T) {
+	ring := newLineRing(3)
+	_, _ = ring.Write([]byte("a\nb\r\nc"))
+	assert.Equal(t, []string{"a", "b", "c"}, ring.Lines(), "未以换行结束的行也保留")
+	_, _ = ring.Write([]byte("\nd\ne\n"))
+	assert.Equal(t, []string{"c", "d", "e"}, ring.Lines(), "只保留最近的行")
+}
+
+func TestCrashDump_CollectedOnCrashLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 /bin/sh")
+	}
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+
+	registry := make(map[string]port.DataSource)
+	closers := make([]io.Closer, 0)
+	pm, err := NewPluginManager(db, dir, nil, filepath.Join(dir, "plugins"), registry, &closers)
+	require.NoError(t, err)
+	pm.SetCrashDumpPolicy(CrashDumpPolicy{Enabled: true, Threshold: 2, MaxDumps: 1})
+
+	_, err = db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES ('io.example.crash', '1.0.0', '/opt/crash')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port, status, process_options)
+		VALUES ('i1', 'crash', 'io.example.crash', '1.0.0', 'letters', 50999, 'RUNNING', '{"env":{"API_TOKEN":"secret"}}')`)
+	require.NoError(t, err)
+
+	crash := func() {
+		cmd := exec.Command("/bin/sh", "-c", "echo 正在打开数据库; echo 'panic: boom' >&2; exit 3")
+		logs := pm.diagnosticsFor("i1").logs
+		cmd.Stdout, cmd.Stderr = logs, logs
+		require.NoError(t, cmd.Start())
+		proc := newPluginProcess(cmd)
+		pm.runningPlugins["i1"] = proc
+		<-proc.exited
+		require.NoError(t, pm.StopWithReason("i1", StopReasonProcessExited))
+	}
+	crash()
+	dumps, err := pm.ListCrashDumps("i1")
+	require.NoError(t, err)
+	assert.Empty(t, dumps, "未达到阈值")
+
+	pm.recordRPCError("i1", "/datasource.v1.DataSource/Query", status.Error(codes.Unavailable, "connection refused"))
+	crash()
+	dumps, err = pm.ListCrashDumps("i1")
+	require.NoError(t, err)
+	require.Len(t, dumps, 1)
+	assert.Equal(t, domain.CrashDumpTriggerCrashLoop, dumps[0].Trigger)
+
+	path, err := pm.CrashDumpPath("i1", dumps[0].Name)
+	require.NoError(t, err)
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		raw, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(raw)
+	}
+	assert.Contains(t, files["plugin.log"], "panic: boom")
+	var summary crashDumpSummary
+	require.NoError(t, json.Unmarshal([]byte(files["summary.json"]), &summary))
+	require.Len(t, summary.Exits, 2)
+	assert.Equal(t, 3, summary.Exits[1].ExitCode)
+	assert.Equal(t, "exit status 3", summary.Exits[1].Status)
+	assert.Equal(t, "***", summary.Instance.ProcessOptions.Env["API_TOKEN"], "注入的环境变量值被隐去")
+	assert.Equal(t, "/opt/crash", summary.InstallPath)
+	require.Len(t, summary.RPCErrors, 1)
+	assert.Equal(t, "Unavailable", summary.RPCErrors[0].Code)
+	assert.Equal(t, EventCrashed, summary.Events[0].Event, "包含刚发生的崩溃事件")
+	assert.False(t, strings.Contains(files["summary.json"], "secret"))
+
+	// 同一窗口内不重复自动收集；手动收集不受限制，超出保留数量时删除最早的
+	crash()
+	manual, err := pm.CollectCrashDump("i1", domain.CrashDumpTriggerManual)
+	require.NoError(t, err)
+	dumps, err = pm.ListCrashDumps("i1")
+	require.NoError(t, err)
+	require.Len(t, dumps, 1)
+	assert.Equal(t, manual.Name, dumps[0].Name)
+
+	_, err = pm.CrashDumpPath("i1", "../auth.db")
+	assert.ErrorIs(t, err, ErrCrashDumpNotFound)
+	_, err = pm.CollectCrashDump("missing", domain.CrashDumpTriggerManual)
+	assert.ErrorIs(t, err, ErrInstanceNotFound)
+}
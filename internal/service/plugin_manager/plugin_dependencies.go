@@ -0,0 +1,288 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_dependencies.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidDependencies 表示依赖声明不合法 (依赖不存在、依赖自身或形成循环)
+	ErrInvalidDependencies = errors.New("无效的插件实例依赖")
+	// ErrDependencyNotReady 表示实例依赖的其它实例尚未运行或不健康
+	ErrDependencyNotReady = errors.New("插件实例的依赖尚未就绪")
+)
+
+// dependencyReadyTimeout 是启动依赖后等待其健康的最长时间。
+// 独立进程实例的注册最多重试约 10 秒，因此留出足够余量。
+var dependencyReadyTimeout = 30 * time.Second
+
+// loadDependencyGraph 读取所有实例的依赖声明：实例ID -> 其依赖的实例ID列表
+func (pm *PluginManager) loadDependencyGraph() (map[string][]string, error) {
+	rows, err := pm.db.Query(`SELECT instance_id, depends_on FROM plugin_instances`)
+	if err != nil {
+		return nil, fmt.Errorf("查询插件实例依赖失败: %w", err)
+	}
+	defer rows.Close()
+
+	graph := make(map[string][]string)
+	for rows.Next() {
+		var id, dependsJSON string
+		if err := rows.Scan(&id, &dependsJSON); err != nil {
+			return nil, fmt.Errorf("扫描插件实例依赖失败: %w", err)
+		}
+		var deps []string
+		if err := json.Unmarshal([]byte(dependsJSON), &deps); err != nil {
+			return nil, fmt.Errorf("解析插件实例 '%s' 的依赖失败: %w", id, err)
+		}
+		graph[id] = deps
+	}
+	return graph, rows.Err()
+}
+
+// validateDependencies 校验实例的依赖声明，instanceID 为空表示即将创建的新实例。
+// 返回去重后的依赖列表。
+func (pm *PluginManager) validateDependencies(instanceID string, deps []string) ([]string, error) {
+	graph, err := pm.loadDependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(deps))
+	cleaned := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if dep == instanceID {
+			return nil, fmt.Errorf("%w: 实例不能依赖自身", ErrInvalidDependencies)
+		}
+		if _, exists := graph[dep]; !exists {
+			return nil, fmt.Errorf("%w: 依赖的实例 '%s' 不存在", ErrInvalidDependencies, dep)
+		}
+		if !seen[dep] {
+			seen[dep] = true
+			cleaned = append(cleaned, dep)
+		}
+	}
+
+	// 新实例不可能被其它实例依赖，因此不会形成循环
+	if instanceID == "" {
+		return cleaned, nil
+	}
+	graph[instanceID] = cleaned
+	if cycle := findCycle(graph, instanceID); cycle != nil {
+		return nil, fmt.Errorf("%w: 依赖形成循环 %v", ErrInvalidDependencies, cycle)
+	}
+	return cleaned, nil
+}
+
+// findCycle 从 start 出发做深度优先搜索，返回遇到的第一个循环路径，无循环时返回 nil
+func findCycle(graph map[string][]string, start string) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range graph[id] {
+			switch state[dep] {
+			case visiting:
+				for i, p := range path {
+					if p == dep {
+						return append(append([]string(nil), path[i:]...), dep)
+					}
+				}
+			case 0:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	return visit(start)
+}
+
+// checkDependenciesReady 确认实例的全部依赖都在运行且健康
+func (pm *PluginManager) checkDependenciesReady(instanceID string) error {
+	graph, err := pm.loadDependencyGraph()
+	if err != nil {
+		return err
+	}
+	for _, dep := range graph[instanceID] {
+		if err := pm.instanceHealthy(dep); err != nil {
+			return fmt.Errorf("%w: 依赖的实例 '%s' %v", ErrDependencyNotReady, dep, err)
+		}
+	}
+	return nil
+}
+
+// instanceHealthy 判断实例已注册到网关且健康检查通过
+func (pm *PluginManager) instanceHealthy(instanceID string) error {
+	pm.runningPluginsMu.Lock()
+	running := pm.isRunningLocked(instanceID)
+	pm.runningPluginsMu.Unlock()
+	if !running {
+		return errors.New("未在运行")
+	}
+
+	pm.registryMu.RLock()
+	var ds port.DataSource
+	for biz, id := range pm.bizToInstanceID {
+		if id == instanceID {
+			ds = pm.dataSourceRegistry[biz]
+			break
+		}
+	}
+	pm.registryMu.RUnlock()
+	if ds == nil {
+		return errors.New("尚未注册到网关")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return ds.HealthCheck(ctx)
+}
+
+// waitHealthy 轮询直到实例健康或超时
+func (pm *PluginManager) waitHealthy(instanceID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := pm.instanceHealthy(instanceID)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待实例 '%s' 就绪超时: %w", instanceID, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// StartWithDependencies 按依赖顺序启动实例：先递归启动尚未运行的依赖并等待其健康，再启动实例本身
+func (pm *PluginManager) StartWithDependencies(instanceID string) error {
+	graph, err := pm.loadDependencyGraph()
+	if err != nil {
+		return err
+	}
+	if _, exists := graph[instanceID]; !exists {
+		return fmt.Errorf("%w: '%s'", ErrInstanceNotFound, instanceID)
+	}
+
+	for _, id := range dependencyOrder(graph, instanceID) {
+		pm.runningPluginsMu.Lock()
+		running := pm.isRunningLocked(id)
+		pm.runningPluginsMu.Unlock()
+		if !running {
+			if err := pm.Start(id); err != nil {
+				return fmt.Errorf("启动实例 '%s' 失败: %w", id, err)
+			}
+		}
+		if id == instanceID {
+			break
+		}
+		if err := pm.waitHealthy(id, dependencyReadyTimeout); err != nil {
+			return fmt.Errorf("%w: %v", ErrDependencyNotReady, err)
+		}
+	}
+	return nil
+}
+
+// dependencyOrder 返回启动 target 所需的实例序列 (依赖在前，target 在最后)。依赖图在写入时已保证无环。
+func dependencyOrder(graph map[string][]string, target string) []string {
+	visited := make(map[string]bool)
+	var order []string
+	var visit func(id string)
+	visit = func(id string) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		for _, dep := range graph[id] {
+			visit(dep)
+		}
+		order = append(order, id)
+	}
+	visit(target)
+	return order
+}
+
+// stopLayers 把需要停止的实例分层：每层中的实例不被其它尚未停止的实例依赖，
+// 因此依赖方总是先于被依赖方停止。同层实例按ID排序以保证结果稳定。
+func stopLayers(graph map[string][]string, ids []string) [][]string {
+	remaining := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remaining[id] = true
+	}
+	var layers [][]string
+	for len(remaining) > 0 {
+		needed := make(map[string]bool)
+		for id := range remaining {
+			for _, dep := range graph[id] {
+				needed[dep] = true
+			}
+		}
+		var layer []string
+		for id := range remaining {
+			if !needed[id] {
+				layer = append(layer, id)
+			}
+		}
+		if len(layer) == 0 {
+			// 依赖图在写入时已保证无环，这里只是防御：剩余实例一起停止
+			for id := range remaining {
+				layer = append(layer, id)
+			}
+		}
+		sort.Strings(layer)
+		for _, id := range layer {
+			delete(remaining, id)
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
+
+// StopAll 按依赖的逆序停止所有正在运行的插件实例，用于网关停机。同一层的实例并发停止。
+func (pm *PluginManager) StopAll(reason string) {
+	pm.runningPluginsMu.Lock()
+	ids := make([]string, 0, len(pm.runningPlugins)+len(pm.embeddedInstances))
+	for id := range pm.runningPlugins {
+		ids = append(ids, id)
+	}
+	for id := range pm.embeddedInstances {
+		ids = append(ids, id)
+	}
+	pm.runningPluginsMu.Unlock()
+
+	graph, err := pm.loadDependencyGraph()
+	if err != nil {
+		log.Printf("⚠️ [PluginManager] 读取实例依赖失败，将同时停止所有实例: %v", err)
+		graph = nil
+	}
+
+	for _, layer := range stopLayers(graph, ids) {
+		var wg sync.WaitGroup
+		for _, id := range layer {
+			wg.Add(1)
+			go func(instanceID string) {
+				defer wg.Done()
+				if err := pm.StopWithReason(instanceID, reason); err != nil {
+					log.Printf("⚠️ [PluginManager] 停止插件实例 '%s' 失败: %v", instanceID, err)
+				}
+			}(id)
+		}
+		wg.Wait()
+	}
+}
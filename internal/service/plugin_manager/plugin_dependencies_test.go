@@ -0,0 +1,62 @@
+// file: internal/service/plugin_manager/plugin_dependencies_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopLayers(t *testing.T) {
+	graph := map[string][]string{
+		"cache":  {"db"},
+		"search": {"db"},
+		"api":    {"cache", "search"},
+		"db":     nil,
+	}
+	assert.Equal(t, [][]string{{"api"}, {"cache", "search"}, {"db"}}, stopLayers(graph, []string{"db", "cache", "search", "api"}))
+	// 未运行的依赖方不影响分层
+	assert.Equal(t, [][]string{{"cache"}, {"db"}}, stopLayers(graph, []string{"db", "cache"}))
+	assert.Equal(t, []string{"db", "cache", "search", "api"}, dependencyOrder(graph, "api"))
+}
+
+func TestInstanceDependencies(t *testing.T) {
+	pm := newPortTestManager(t)
+	require.NoError(t, pm.RegisterEmbeddedPlugin("io.example.sqlite", func(bizName string) (port.DataSource, error) {
+		return &closingDataSource{}, nil
+	}))
+
+	db, err := pm.CreateInstance("db", "io.example.sqlite", "", "letters", InstanceModeEmbedded, domain.PluginProcessOptions{}, nil)
+	require.NoError(t, err)
+	cache, err := pm.CreateInstance("cache", "io.example.sqlite", "", "letters_cached", InstanceModeEmbedded, domain.PluginProcessOptions{}, []string{db, db})
+	require.NoError(t, err)
+
+	_, err = pm.CreateInstance("bad", "io.example.sqlite", "", "bad", InstanceModeEmbedded, domain.PluginProcessOptions{}, []string{"missing"})
+	assert.ErrorIs(t, err, ErrInvalidDependencies)
+
+	deps := []string{cache}
+	assert.ErrorIs(t, pm.UpdateInstance(db, InstanceUpdate{DependsOn: &deps}), ErrInvalidDependencies, "循环依赖应被拒绝")
+	self := []string{db}
+	assert.ErrorIs(t, pm.UpdateInstance(db, InstanceUpdate{DependsOn: &self}), ErrInvalidDependencies, "实例不能依赖自身")
+
+	instances, err := pm.ListInstances()
+	require.NoError(t, err)
+	for _, inst := range instances {
+		if inst.InstanceID == cache {
+			assert.Equal(t, []string{db}, inst.DependsOn, "重复的依赖应被去重")
+		}
+	}
+
+	// 依赖未运行时直接启动被拒绝，按依赖顺序启动则会先拉起依赖
+	assert.ErrorIs(t, pm.Start(cache), ErrDependencyNotReady)
+	require.NoError(t, pm.StartWithDependencies(cache))
+	assert.Contains(t, pm.dataSourceRegistry, "letters")
+	assert.Contains(t, pm.dataSourceRegistry, "letters_cached")
+
+	pm.StopAll(StopReasonGatewayShutdown)
+	assert.Empty(t, pm.embeddedInstances)
+	assert.Empty(t, pm.dataSourceRegistry)
+}
@@ -0,0 +1,135 @@
+// Package plugin_manager file: internal/service/plugin_embedded.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/embedded"
+	"ArchiveAegis/internal/core/port"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+const (
+	// InstanceModeProcess 插件以独立进程运行，网关通过 gRPC 访问（默认）
+	InstanceModeProcess = "process"
+	// InstanceModeEmbedded 插件直接运行在网关进程内，适合单节点、单容器部署
+	InstanceModeEmbedded = "embedded"
+
+	// EmbeddedVersion 是嵌入式插件在 installed_plugins 中登记的版本号。
+	// 嵌入式插件随网关一起编译，无需下载安装，登记只为满足实例表的外键约束。
+	EmbeddedVersion = "embedded"
+)
+
+// EmbeddedFactory 为指定业务组在进程内创建一个数据源
+type EmbeddedFactory func(bizName string) (port.DataSource, error)
+
+// RegisterEmbeddedPlugin 声明某个插件可以以嵌入式模式运行，应在创建或启动任何实例之前调用。
+func (pm *PluginManager) RegisterEmbeddedPlugin(pluginID string, factory EmbeddedFactory) error {
+	_, err := pm.db.Exec(
+		`INSERT OR IGNORE INTO installed_plugins (plugin_id, version, install_path) VALUES (?, ?, '')`,
+		pluginID, EmbeddedVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("登记嵌入式插件 '%s' 失败: %w", pluginID, err)
+	}
+	pm.runningPluginsMu.Lock()
+	pm.embeddedFactories[pluginID] = factory
+	pm.runningPluginsMu.Unlock()
+	return nil
+}
+
+// StartEmbeddedInstances 启动所有已启用的嵌入式实例。
+// 嵌入式实例没有需要单独托管的进程，随网关启动即可，单个实例失败不影响其它实例。
+// 实例声明的依赖会先被启动并等待其健康。返回以实例 ID 索引的启动失败原因，供启动报告汇总。
+func (pm *PluginManager) StartEmbeddedInstances() map[string]error {
+	failures := make(map[string]error)
+	rows, err := pm.db.Query(`SELECT instance_id FROM plugin_instances WHERE mode = ? AND enabled = TRUE`, InstanceModeEmbedded)
+	if err != nil {
+		log.Printf("⚠️ [PluginManager] 查询嵌入式实例失败: %v", err)
+		return failures
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	_ = rows.Close()
+
+	for _, id := range ids {
+		pm.runningPluginsMu.Lock()
+		running := pm.isRunningLocked(id)
+		pm.runningPluginsMu.Unlock()
+		if running {
+			// 已作为其它实例的依赖被启动
+			continue
+		}
+		if err := pm.StartWithDependencies(id); err != nil {
+			log.Printf("⚠️ [PluginManager] 启动嵌入式实例 '%s' 失败: %v", id, err)
+			failures[id] = err
+		}
+	}
+	return failures
+}
+
+// startEmbedded 在进程内创建数据源并立即注册到网关
+func (pm *PluginManager) startEmbedded(instanceID, pluginID, bizName string) error {
+	pm.runningPluginsMu.Lock()
+	factory, ok := pm.embeddedFactories[pluginID]
+	pm.runningPluginsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("插件 '%s' 不支持嵌入式运行", pluginID)
+	}
+
+	ds, err := factory(bizName)
+	if err != nil {
+		return fmt.Errorf("创建嵌入式数据源失败: %w", err)
+	}
+	adapter := embedded.New(ds, asCloser(ds))
+
+	pm.runningPluginsMu.Lock()
+	if pm.isRunningLocked(instanceID) {
+		pm.runningPluginsMu.Unlock()
+		_ = adapter.Close()
+		return fmt.Errorf("插件实例 '%s' 已经在运行中", instanceID)
+	}
+	pm.embeddedInstances[instanceID] = adapter
+	pm.runningPluginsMu.Unlock()
+
+	pm.registryMu.Lock()
+	var dataSource port.DataSource = adapter
+	if pm.decorator != nil {
+		dataSource = pm.decorator(bizName, adapter)
+	}
+	pm.dataSourceRegistry[bizName] = dataSource
+	pm.bizToInstanceID[bizName] = instanceID
+	*pm.closableAdapters = append(*pm.closableAdapters, adapter)
+	pm.registryMu.Unlock()
+	pm.notifyRegistered(bizName, dataSource)
+
+	pm.recordStartEvent(instanceID)
+	if _, err := pm.db.Exec("UPDATE plugin_instances SET status = 'RUNNING', last_started_at = ? WHERE instance_id = ?", time.Now(), instanceID); err != nil {
+		log.Printf("⚠️ [PluginManager] 更新插件实例 '%s' 状态到 RUNNING 失败: %v", instanceID, err)
+	}
+	log.Printf("✅ [PluginManager] 嵌入式实例 '%s' 已在网关进程内为业务组 '%s' 提供服务。", instanceID, bizName)
+	return nil
+}
+
+// isRunningLocked 判断实例是否在运行（独立进程或嵌入式）。调用前必须持有 runningPluginsMu。
+func (pm *PluginManager) isRunningLocked(instanceID string) bool {
+	if _, ok := pm.runningPlugins[instanceID]; ok {
+		return true
+	}
+	_, ok := pm.embeddedInstances[instanceID]
+	return ok
+}
+
+// asCloser 在数据源持有需要释放的资源时返回它
+func asCloser(ds port.DataSource) io.Closer {
+	if c, ok := ds.(io.Closer); ok {
+		return c
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// file: internal/service/plugin_manager/plugin_embedded_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closingDataSource struct {
+	port.DataSource
+	closed bool
+}
+
+func (c *closingDataSource) HealthCheck(context.Context) error { return nil }
+func (c *closingDataSource) Close() error                      { c.closed = true; return nil }
+
+func TestEmbeddedInstanceLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+
+	registry := make(map[string]port.DataSource)
+	closers := make([]io.Closer, 0)
+	pm, err := NewPluginManager(db, dir, nil, filepath.Join(dir, "plugins"), registry, &closers)
+	require.NoError(t, err)
+
+	_, err = pm.CreateInstance("x", "io.example.sqlite", "", "letters", InstanceModeEmbedded, domain.PluginProcessOptions{}, nil)
+	assert.Error(t, err, "未声明嵌入式支持的插件不能以嵌入式模式创建")
+
+	created := make([]*closingDataSource, 0)
+	require.NoError(t, pm.RegisterEmbeddedPlugin("io.example.sqlite", func(bizName string) (port.DataSource, error) {
+		ds := &closingDataSource{}
+		created = append(created, ds)
+		return ds, nil
+	}))
+
+	_, err = pm.CreateInstance("letters", "io.example.sqlite", "", "letters", InstanceModeEmbedded, domain.PluginProcessOptions{WorkDir: "x"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidProcessOptions, "嵌入式实例不支持进程选项")
+
+	registered := make(chan string, 1)
+	pm.SetRegistrationHook(func(bizName string, _ port.DataSource) { registered <- bizName })
+
+	id, err := pm.CreateInstance("letters", "io.example.sqlite", "", "letters", InstanceModeEmbedded, domain.PluginProcessOptions{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, pm.Start(id))
+	assert.Contains(t, registry, "letters")
+	select {
+	case biz := <-registered:
+		assert.Equal(t, "letters", biz)
+	case <-time.After(time.Second):
+		t.Fatal("注册钩子未被调用")
+	}
+	assert.Error(t, pm.Start(id), "重复启动应被拒绝")
+
+	instances, err := pm.ListInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, InstanceModeEmbedded, instances[0].Mode)
+	assert.Equal(t, EmbeddedVersion, instances[0].Version)
+	assert.Equal(t, "RUNNING", instances[0].Status)
+	assert.Error(t, pm.DeleteInstance(id), "运行中的实例不能删除")
+
+	require.NoError(t, pm.Stop(id))
+	assert.NotContains(t, registry, "letters")
+	require.Len(t, created, 1)
+	assert.True(t, created[0].closed)
+
+	// 随网关启动时会重新拉起嵌入式实例
+	pm.StartEmbeddedInstances()
+	assert.Contains(t, registry, "letters")
+	require.NoError(t, pm.Stop(id))
+
+	_, err = pm.CreateInstance("y", "io.example.sqlite", "", "other", "bogus", domain.PluginProcessOptions{}, nil)
+	assert.Error(t, err)
+	_, err = pm.CreateInstance("y", "io.example.sqlite", "", "other", InstanceModeProcess, domain.PluginProcessOptions{}, nil)
+	assert.Error(t, err, "独立进程模式必须指定版本")
+}
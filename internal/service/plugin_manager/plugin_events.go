@@ -0,0 +1,108 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_events.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// 插件生命周期事件类型，记录在 plugin_events.event 中
+const (
+	EventInstalled = "installed" // 插件的某个版本首次安装
+	EventUpgraded  = "upgraded"  // 已安装过其它版本的插件安装了新版本
+	EventStarted   = "started"   // 实例启动
+	EventRestarted = "restarted" // 实例在异常停止后被再次启动
+	EventStopped   = "stopped"   // 实例被管理员或网关停机正常停止
+	EventCrashed   = "crashed"   // 实例进程退出、健康检查失败或无法注册
+)
+
+// DefaultEventLimit 是查询实例事件时默认返回的条数
+const DefaultEventLimit = 100
+
+// stopEvent 根据停止原因判断这次停止是正常停止还是异常
+func stopEvent(reason string) string {
+	switch reason {
+	case StopReasonProcessExited, StopReasonHealthCheck, StopReasonRegisterFailed:
+		return EventCrashed
+	default:
+		return EventStopped
+	}
+}
+
+// recordEvent 写入一条生命周期事件并累加对应的 Prometheus 计数。
+// 事件日志只用于排查，写入失败不影响生命周期操作本身。
+func (pm *PluginManager) recordEvent(instanceID, pluginID, version, event, reason string) {
+	aegobserve.PluginEvents.WithLabelValues(pluginID, event).Inc()
+	_, err := pm.db.Exec(`INSERT INTO plugin_events (instance_id, plugin_id, version, event, reason) VALUES (?, ?, ?, ?, ?)`,
+		instanceID, pluginID, version, event, reason)
+	if err != nil {
+		log.Printf("⚠️ [PluginManager] 记录插件事件失败 (实例: '%s', 事件: %s): %v", instanceID, event, err)
+	}
+}
+
+// recordInstanceEvent 为实例写入一条事件，插件ID与版本从实例配置中读取
+func (pm *PluginManager) recordInstanceEvent(instanceID, event, reason string) {
+	var pluginID, version string
+	err := pm.db.QueryRow(`SELECT plugin_id, version FROM plugin_instances WHERE instance_id = ?`, instanceID).Scan(&pluginID, &version)
+	if err != nil {
+		log.Printf("⚠️ [PluginManager] 记录插件事件时读取实例 '%s' 失败: %v", instanceID, err)
+		return
+	}
+	pm.recordEvent(instanceID, pluginID, version, event, reason)
+}
+
+// recordStartEvent 记录实例启动。上一次停止属于异常时记为 restarted，原因为上一次的停止原因。
+// 必须在更新 last_stop_reason 之前调用。
+func (pm *PluginManager) recordStartEvent(instanceID string) {
+	var pluginID, version, lastStopReason string
+	err := pm.db.QueryRow(`SELECT plugin_id, version, last_stop_reason FROM plugin_instances WHERE instance_id = ?`, instanceID).
+		Scan(&pluginID, &version, &lastStopReason)
+	if err != nil {
+		log.Printf("⚠️ [PluginManager] 记录插件事件时读取实例 '%s' 失败: %v", instanceID, err)
+		return
+	}
+	if stopEvent(lastStopReason) == EventCrashed {
+		pm.recordEvent(instanceID, pluginID, version, EventRestarted, lastStopReason)
+		return
+	}
+	pm.recordEvent(instanceID, pluginID, version, EventStarted, "")
+}
+
+// ListInstanceEvents 按时间倒序返回实例的生命周期事件，
+// 其中包含实例所用插件的安装与升级事件。limit 非正时使用 DefaultEventLimit。
+func (pm *PluginManager) ListInstanceEvents(instanceID string, limit int) ([]domain.PluginEvent, error) {
+	var pluginID string
+	err := pm.db.QueryRow(`SELECT plugin_id FROM plugin_instances WHERE instance_id = ?`, instanceID).Scan(&pluginID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: '%s'", ErrInstanceNotFound, instanceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询插件实例 '%s' 失败: %w", instanceID, err)
+	}
+	if limit <= 0 {
+		limit = DefaultEventLimit
+	}
+
+	rows, err := pm.db.Query(`
+		SELECT id, instance_id, plugin_id, version, event, reason, created_at FROM plugin_events
+		WHERE instance_id = ? OR (instance_id = '' AND plugin_id = ?)
+		ORDER BY id DESC LIMIT ?`, instanceID, pluginID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询插件实例 '%s' 的事件失败: %w", instanceID, err)
+	}
+	defer rows.Close()
+
+	events := make([]domain.PluginEvent, 0)
+	for rows.Next() {
+		var e domain.PluginEvent
+		if err := rows.Scan(&e.ID, &e.InstanceID, &e.PluginID, &e.Version, &e.Event, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("扫描插件事件失败: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
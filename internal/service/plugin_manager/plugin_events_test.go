@@ -0,0 +1,50 @@
+// file: internal/service/plugin_manager/plugin_events_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventNames(events []domain.PluginEvent) []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Event
+	}
+	return names
+}
+
+func TestInstanceEvents(t *testing.T) {
+	pm := newPortTestManager(t)
+	require.NoError(t, pm.RegisterEmbeddedPlugin("io.example.sqlite", func(bizName string) (port.DataSource, error) {
+		return &closingDataSource{}, nil
+	}))
+	id, err := pm.CreateInstance("letters", "io.example.sqlite", "", "letters", InstanceModeEmbedded, domain.PluginProcessOptions{}, nil)
+	require.NoError(t, err)
+	pm.recordEvent("", "io.example.sqlite", "1.0.0", EventInstalled, "")
+	pm.recordEvent("", "io.example.other", "1.0.0", EventInstalled, "")
+
+	require.NoError(t, pm.Start(id))
+	require.NoError(t, pm.StopWithReason(id, StopReasonHealthCheck))
+	require.NoError(t, pm.Start(id))
+	require.NoError(t, pm.Stop(id))
+
+	events, err := pm.ListInstanceEvents(id, 0)
+	require.NoError(t, err)
+	// 最新的在前；其它插件的安装事件不出现
+	assert.Equal(t, []string{EventStopped, EventRestarted, EventCrashed, EventStarted, EventInstalled}, eventNames(events))
+	assert.Equal(t, StopReasonHealthCheck, events[1].Reason, "异常后重启的原因是上一次的停止原因")
+	assert.Equal(t, StopReasonAdmin, events[0].Reason)
+	assert.Empty(t, events[4].InstanceID)
+
+	limited, err := pm.ListInstanceEvents(id, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{EventStopped, EventRestarted}, eventNames(limited))
+
+	_, err = pm.ListInstanceEvents("missing", 0)
+	assert.ErrorIs(t, err, ErrInstanceNotFound)
+}
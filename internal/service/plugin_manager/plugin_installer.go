@@ -5,7 +5,9 @@ import (
 	"ArchiveAegis/internal/core/domain"
 	"archive/zip"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -80,6 +82,13 @@ func (pm *PluginManager) Install(pluginID, version string) (err error) {
 		return fmt.Errorf("解压插件失败 (%s): %w", pluginID, err)
 	}
 
+	// 安装前已有其它版本时记为升级，原因记录之前最近安装的版本
+	var previousVersion string
+	row := pm.db.QueryRow(`SELECT version FROM installed_plugins WHERE plugin_id = ? AND version != ? ORDER BY installed_at DESC LIMIT 1`, pluginID, version)
+	if err := row.Scan(&previousVersion); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("查询插件 '%s' 的已安装版本失败: %w", pluginID, err)
+	}
+
 	query := `
         INSERT INTO installed_plugins (plugin_id, version, install_path)
         VALUES (?, ?, ?)
@@ -90,6 +99,11 @@ func (pm *PluginManager) Install(pluginID, version string) (err error) {
 	}
 
 	log.Printf("🎉 [PluginManager] 插件 '%s' v%s 安装成功，路径: %s", pluginID, version, pluginInstallPath)
+	if previousVersion != "" {
+		pm.recordEvent("", pluginID, version, EventUpgraded, "from "+previousVersion)
+	} else {
+		pm.recordEvent("", pluginID, version, EventInstalled, "")
+	}
 	return nil
 }
 
@@ -0,0 +1,201 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_instance_update.go
+package plugin_manager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrInstanceNotFound 表示指定的插件实例不存在
+	ErrInstanceNotFound = errors.New("插件实例不存在")
+	// ErrInstanceRunning 表示该修改需要实例处于停止状态
+	ErrInstanceRunning = errors.New("插件实例正在运行")
+	// ErrInstanceConflict 表示业务组名或端口已被占用
+	ErrInstanceConflict = errors.New("插件实例属性冲突")
+	// ErrInvalidInstanceUpdate 表示修改内容不合法
+	ErrInvalidInstanceUpdate = errors.New("无效的插件实例修改")
+)
+
+// InstanceUpdate 描述对插件实例属性的修改，nil 字段保持不变
+type InstanceUpdate struct {
+	DisplayName *string `json:"display_name"`
+	BizName     *string `json:"biz_name"`
+	Port        *int    `json:"port"`
+	// DependsOn 替换实例依赖的其它实例ID，在下次启动时生效
+	DependsOn *[]string `json:"depends_on"`
+	// Version 切换实例使用的插件版本，该版本必须已安装，且只能在实例停止时修改
+	Version *string `json:"version"`
+}
+
+// UpdateInstance 修改插件实例的展示名、绑定的业务组、端口、依赖与插件版本。
+// 展示名可随时修改，独立进程实例会在下次启动时以新名称传给插件；
+// 业务组、端口与版本在插件启动时确定，因此只能在实例停止时修改，在下次启动时生效。
+func (pm *PluginManager) UpdateInstance(instanceID string, upd InstanceUpdate) error {
+	var currentBiz, pluginID, currentVersion, mode string
+	var currentPort int
+	err := pm.db.QueryRow(`SELECT biz_name, port, plugin_id, version, mode FROM plugin_instances WHERE instance_id = ?`, instanceID).
+		Scan(&currentBiz, &currentPort, &pluginID, &currentVersion, &mode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: '%s'", ErrInstanceNotFound, instanceID)
+	}
+	if err != nil {
+		return fmt.Errorf("查询插件实例 '%s' 失败: %w", instanceID, err)
+	}
+
+	sets := make([]string, 0, 3)
+	args := make([]interface{}, 0, 4)
+
+	if upd.DisplayName != nil {
+		name := strings.TrimSpace(*upd.DisplayName)
+		if name == "" {
+			return fmt.Errorf("%w: display_name 不能为空", ErrInvalidInstanceUpdate)
+		}
+		sets = append(sets, "display_name = ?")
+		args = append(args, name)
+	}
+
+	bizChanged := upd.BizName != nil && *upd.BizName != currentBiz
+	portChanged := upd.Port != nil && *upd.Port != currentPort
+	versionChanged := upd.Version != nil && *upd.Version != currentVersion
+	if bizChanged || portChanged || versionChanged {
+		pm.runningPluginsMu.Lock()
+		running := pm.isRunningLocked(instanceID)
+		pm.runningPluginsMu.Unlock()
+		if running {
+			return fmt.Errorf("%w: 修改业务组、端口或版本前请先停止实例 '%s'", ErrInstanceRunning, instanceID)
+		}
+	}
+
+	if versionChanged {
+		if mode == InstanceModeEmbedded {
+			return fmt.Errorf("%w: 嵌入式实例随网关升级，不能切换版本", ErrInvalidInstanceUpdate)
+		}
+		var installed int
+		if err := pm.db.QueryRow(`SELECT COUNT(*) FROM installed_plugins WHERE plugin_id = ? AND version = ?`, pluginID, *upd.Version).Scan(&installed); err != nil {
+			return fmt.Errorf("查询插件 '%s' 的已安装版本失败: %w", pluginID, err)
+		}
+		if installed == 0 {
+			return fmt.Errorf("%w: 插件 '%s' 的版本 '%s' 尚未安装", ErrInvalidInstanceUpdate, pluginID, *upd.Version)
+		}
+		sets = append(sets, "version = ?")
+		args = append(args, *upd.Version)
+	}
+
+	if bizChanged {
+		biz := strings.TrimSpace(*upd.BizName)
+		if biz == "" {
+			return fmt.Errorf("%w: biz_name 不能为空", ErrInvalidInstanceUpdate)
+		}
+		if err := pm.checkBizAvailable(instanceID, biz); err != nil {
+			return err
+		}
+		sets = append(sets, "biz_name = ?")
+		args = append(args, biz)
+	}
+
+	if portChanged {
+		port := *upd.Port
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("%w: 端口 %d 超出有效范围", ErrInvalidInstanceUpdate, port)
+		}
+		if policy := pm.currentPortPolicy(); !policy.contains(port) {
+			return fmt.Errorf("%w: 端口 %d 不在配置的范围 [%d, %d] 内", ErrInvalidInstanceUpdate, port, policy.RangeStart, policy.RangeEnd)
+		}
+		var count int
+		if err := pm.db.QueryRow(`SELECT COUNT(*) FROM plugin_instances WHERE port = ? AND instance_id != ?`, port, instanceID).Scan(&count); err != nil {
+			return fmt.Errorf("检查端口时数据库出错: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: 端口 %d 已分配给其他插件实例", ErrInstanceConflict, port)
+		}
+		sets = append(sets, "port = ?")
+		args = append(args, port)
+	}
+
+	if upd.DependsOn != nil {
+		deps, err := pm.validateDependencies(instanceID, *upd.DependsOn)
+		if err != nil {
+			return err
+		}
+		dependsJSON, err := json.Marshal(deps)
+		if err != nil {
+			return fmt.Errorf("序列化依赖失败: %w", err)
+		}
+		sets = append(sets, "depends_on = ?")
+		args = append(args, string(dependsJSON))
+	}
+
+	if len(sets) == 0 {
+		return nil
+	}
+	args = append(args, instanceID)
+	if _, err := pm.db.Exec(`UPDATE plugin_instances SET `+strings.Join(sets, ", ")+` WHERE instance_id = ?`, args...); err != nil {
+		return fmt.Errorf("更新插件实例 '%s' 失败: %w", instanceID, err)
+	}
+	log.Printf("✏️ [PluginManager] 插件实例 '%s' 的属性已更新。", instanceID)
+	return nil
+}
+
+// checkBizAvailable 确认业务组名未被其他插件实例或静态数据源 (例如虚拟业务组) 占用
+func (pm *PluginManager) checkBizAvailable(instanceID, bizName string) error {
+	var count int
+	if err := pm.db.QueryRow(`SELECT COUNT(*) FROM plugin_instances WHERE biz_name = ? AND instance_id != ?`, bizName, instanceID).Scan(&count); err != nil {
+		return fmt.Errorf("检查 biz_name 时数据库出错: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: 业务组名称 '%s' 已被其他插件实例占用", ErrInstanceConflict, bizName)
+	}
+
+	pm.registryMu.RLock()
+	_, registered := pm.dataSourceRegistry[bizName]
+	owner, ownedByPlugin := pm.bizToInstanceID[bizName]
+	pm.registryMu.RUnlock()
+	if registered && (!ownedByPlugin || owner != instanceID) {
+		return fmt.Errorf("%w: 业务组名称 '%s' 已被网关中的其它数据源占用", ErrInstanceConflict, bizName)
+	}
+	return nil
+}
+
+// InstanceForBiz 返回绑定到业务组的插件实例及其是否在运行，没有绑定实例时返回空字符串
+func (pm *PluginManager) InstanceForBiz(bizName string) (string, bool, error) {
+	var instanceID string
+	err := pm.db.QueryRow(`SELECT instance_id FROM plugin_instances WHERE biz_name = ?`, bizName).Scan(&instanceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("查询业务组 '%s' 绑定的插件实例失败: %w", bizName, err)
+	}
+	pm.runningPluginsMu.Lock()
+	running := pm.isRunningLocked(instanceID)
+	pm.runningPluginsMu.Unlock()
+	return instanceID, running, nil
+}
+
+// MoveBizData 把插件按 <instance_dir>/<biz_name> 约定保存的业务组数据目录改为新的业务组名，
+// 目录不存在时返回 false；新目录已存在时拒绝覆盖。调用前应停止使用该目录的实例
+func (pm *PluginManager) MoveBizData(oldName, newName string) (bool, error) {
+	root, err := filepath.Abs(filepath.Dir(pm.installDir))
+	if err != nil {
+		return false, fmt.Errorf("无法确定 instance 根目录: %w", err)
+	}
+	oldDir, newDir := filepath.Join(root, oldName), filepath.Join(root, newName)
+	if info, err := os.Stat(oldDir); err != nil || !info.IsDir() {
+		return false, nil
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		return false, fmt.Errorf("%w: 数据目录 '%s' 已存在", ErrInstanceConflict, newDir)
+	}
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return false, fmt.Errorf("重命名业务组数据目录失败: %w", err)
+	}
+	log.Printf("✏️ [PluginManager] 业务组数据目录 '%s' 已重命名为 '%s'。", oldDir, newDir)
+	return true, nil
+}
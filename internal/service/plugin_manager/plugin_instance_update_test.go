@@ -0,0 +1,91 @@
+// file: internal/service/plugin_manager/plugin_instance_update_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateInstance(t *testing.T) {
+	pm := newPortTestManager(t)
+	for _, stmt := range []string{
+		`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('a', 'A', 'io.example.sqlite', '1.0.0', 'letters', 50101)`,
+		`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('b', 'B', 'io.example.sqlite', '1.0.0', 'persons', 50102)`,
+	} {
+		_, err := pm.db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	str := func(s string) *string { return &s }
+	num := func(i int) *int { return &i }
+
+	require.NoError(t, pm.UpdateInstance("a", InstanceUpdate{DisplayName: str("Letters"), BizName: str("letters_v2"), Port: num(50110)}))
+	var name, biz string
+	var p int
+	require.NoError(t, pm.db.QueryRow(`SELECT display_name, biz_name, port FROM plugin_instances WHERE instance_id = 'a'`).Scan(&name, &biz, &p))
+	assert.Equal(t, "Letters", name)
+	assert.Equal(t, "letters_v2", biz)
+	assert.Equal(t, 50110, p)
+
+	assert.ErrorIs(t, pm.UpdateInstance("missing", InstanceUpdate{DisplayName: str("x")}), ErrInstanceNotFound)
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{DisplayName: str(" ")}), ErrInvalidInstanceUpdate)
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{BizName: str("persons")}), ErrInstanceConflict)
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{Port: num(50102)}), ErrInstanceConflict)
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{Port: num(70000)}), ErrInvalidInstanceUpdate)
+
+	// 业务组名被虚拟业务组等静态数据源占用
+	require.NoError(t, pm.RegisterStaticDataSource("virtual_letters", port.DataSource(nil)))
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{BizName: str("virtual_letters")}), ErrInstanceConflict)
+
+	// 端口范围限制
+	require.NoError(t, pm.SetPortPolicy(PortPolicy{RangeStart: 50100, RangeEnd: 50120}))
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{Port: num(50200)}), ErrInvalidInstanceUpdate)
+
+	// 只能切换到已安装的版本
+	_, err := pm.db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES ('io.example.sqlite', '1.1.0', '/plugins/1.1.0')`)
+	require.NoError(t, err)
+	assert.ErrorIs(t, pm.UpdateInstance("b", InstanceUpdate{Version: str("9.9.9")}), ErrInvalidInstanceUpdate)
+	require.NoError(t, pm.UpdateInstance("b", InstanceUpdate{Version: str("1.1.0")}))
+	var version string
+	require.NoError(t, pm.db.QueryRow(`SELECT version FROM plugin_instances WHERE instance_id = 'b'`).Scan(&version))
+	assert.Equal(t, "1.1.0", version)
+
+	// 运行中的实例只能修改展示名
+	pm.runningPlugins["a"] = &pluginProcess{}
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{BizName: str("letters_v3")}), ErrInstanceRunning)
+	assert.ErrorIs(t, pm.UpdateInstance("a", InstanceUpdate{Version: str("1.1.0")}), ErrInstanceRunning)
+	assert.NoError(t, pm.UpdateInstance("a", InstanceUpdate{DisplayName: str("Letters (live)"), BizName: str("letters_v2")}))
+}
+
+func TestInstanceForBizAndMoveBizData(t *testing.T) {
+	pm := newPortTestManager(t)
+	_, err := pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('a', 'A', 'io.example.sqlite', '1.0.0', 'letters', 50101)`)
+	require.NoError(t, err)
+	id, running, err := pm.InstanceForBiz("letters")
+	require.NoError(t, err)
+	assert.Equal(t, "a", id)
+	assert.False(t, running)
+	id, _, err = pm.InstanceForBiz("persons")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+
+	root := filepath.Dir(pm.installDir)
+	moved, err := pm.MoveBizData("letters", "correspondence")
+	require.NoError(t, err)
+	assert.False(t, moved, "没有数据目录时不做任何事")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "letters"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "letters", "main.db"), []byte("x"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "persons"), 0755))
+	_, err = pm.MoveBizData("letters", "persons")
+	assert.ErrorIs(t, err, ErrInstanceConflict, "不覆盖已存在的目录")
+	moved, err = pm.MoveBizData("letters", "correspondence")
+	require.NoError(t, err)
+	assert.True(t, moved)
+	assert.FileExists(t, filepath.Join(root, "correspondence", "main.db"))
+	assert.NoDirExists(t, filepath.Join(root, "letters"))
+}
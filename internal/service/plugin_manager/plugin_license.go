@@ -0,0 +1,59 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_license.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// 插件进程通过以下环境变量获得授权信息
+const (
+	EnvLicenseKey         = "AEGIS_LICENSE_KEY"
+	EnvLicenseEntitlement = "AEGIS_LICENSE_ENTITLEMENT" // domain.Entitlement 的 JSON
+)
+
+// ErrLicenseRequired 表示插件需要授权，但没有录入有效的授权
+var ErrLicenseRequired = errors.New("插件需要有效的授权")
+
+// LicenseChecker 根据插件的授权要求返回其授权信息，没有有效授权时返回错误
+type LicenseChecker func(pluginID string, req domain.LicenseRequirement) (*domain.Entitlement, error)
+
+// SetLicenseChecker 设置启动需要授权的插件时使用的校验器。未设置时这类插件无法启动
+func (pm *PluginManager) SetLicenseChecker(checker LicenseChecker) {
+	pm.catalogMu.Lock()
+	defer pm.catalogMu.Unlock()
+	pm.licenseChecker = checker
+}
+
+// checkLicense 校验插件的授权，不需要授权的插件返回 nil
+func (pm *PluginManager) checkLicense(manifest domain.PluginManifest) (*domain.Entitlement, error) {
+	if manifest.License == nil {
+		return nil, nil
+	}
+	pm.catalogMu.RLock()
+	checker := pm.licenseChecker
+	pm.catalogMu.RUnlock()
+	if checker == nil {
+		return nil, fmt.Errorf("%w: 网关未启用授权校验，无法启动插件 '%s'", ErrLicenseRequired, manifest.ID)
+	}
+	ent, err := checker(manifest.ID, *manifest.License)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 插件 '%s': %v", ErrLicenseRequired, manifest.ID, err)
+	}
+	return ent, nil
+}
+
+// withLicenseEnv 把授权信息追加到进程环境变量中，env 为 nil 时以网关自身的环境为基础
+func withLicenseEnv(env []string, ent *domain.Entitlement) ([]string, error) {
+	payload, err := json.Marshal(ent)
+	if err != nil {
+		return nil, fmt.Errorf("序列化授权信息失败: %w", err)
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	return append(env, EnvLicenseKey+"="+ent.Key, EnvLicenseEntitlement+"="+string(payload)), nil
+}
@@ -0,0 +1,62 @@
+// file: internal/service/plugin_manager/plugin_license_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_RequiresLicense(t *testing.T) {
+	pm := newPortTestManager(t)
+	pm.catalog["io.example.sqlite"] = domain.PluginManifest{
+		ID:       "io.example.sqlite",
+		Versions: []domain.PluginVersion{{VersionString: "1.0.0", Execution: domain.Execution{Entrypoint: "missing-binary"}}},
+		License:  &domain.LicenseRequirement{PublicKey: "key"},
+	}
+	_, err := pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('a', 'A', 'io.example.sqlite', '1.0.0', 'letters', 50101)`)
+	require.NoError(t, err)
+
+	// 未设置校验器时拒绝启动
+	err = pm.Start("a")
+	assert.ErrorIs(t, err, ErrLicenseRequired)
+
+	var gotReq domain.LicenseRequirement
+	pm.SetLicenseChecker(func(pluginID string, req domain.LicenseRequirement) (*domain.Entitlement, error) {
+		gotReq = req
+		return nil, errors.New("尚未录入授权")
+	})
+	err = pm.Start("a")
+	assert.ErrorIs(t, err, ErrLicenseRequired)
+	assert.Contains(t, err.Error(), "尚未录入授权")
+	assert.Equal(t, "key", gotReq.PublicKey)
+
+	// 授权有效后继续启动流程 (入口文件不存在，在启动进程时失败)
+	pm.SetLicenseChecker(func(string, domain.LicenseRequirement) (*domain.Entitlement, error) {
+		return &domain.Entitlement{Key: "k"}, nil
+	})
+	err = pm.Start("a")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrLicenseRequired)
+}
+
+func TestWithLicenseEnv(t *testing.T) {
+	ent := &domain.Entitlement{LicenseClaims: domain.LicenseClaims{PluginID: "p", Licensee: "市档案馆"}, Key: "payload.sig"}
+	env, err := withLicenseEnv([]string{"PATH=/bin"}, ent)
+	require.NoError(t, err)
+	require.Len(t, env, 3)
+	assert.Equal(t, EnvLicenseKey+"=payload.sig", env[1])
+
+	var decoded domain.Entitlement
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(env[2], EnvLicenseEntitlement+"=")), &decoded))
+	assert.Equal(t, *ent, decoded)
+
+	env, err = withLicenseEnv(nil, ent)
+	require.NoError(t, err)
+	assert.Greater(t, len(env), 2, "未限定环境变量时继承网关的环境")
+}
@@ -6,11 +6,12 @@ import (
 	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -20,7 +21,30 @@ import (
 )
 
 // CreateInstance 在数据库中创建插件实例的配置。
-func (pm *PluginManager) CreateInstance(displayName, pluginID, version, bizName string) (string, error) {
+// mode 为空时使用独立进程模式；嵌入式模式要求插件已通过 RegisterEmbeddedPlugin 声明，且忽略 version。
+// processOptions 只适用于独立进程模式。
+func (pm *PluginManager) CreateInstance(displayName, pluginID, version, bizName, mode string, processOptions domain.PluginProcessOptions, dependsOn []string) (string, error) {
+	switch mode {
+	case "", InstanceModeProcess:
+		mode = InstanceModeProcess
+		if version == "" {
+			return "", fmt.Errorf("独立进程模式的插件实例必须指定 version")
+		}
+	case InstanceModeEmbedded:
+		if !isZeroProcessOptions(processOptions) {
+			return "", fmt.Errorf("%w: 嵌入式实例运行在网关进程内，不支持进程选项", ErrInvalidProcessOptions)
+		}
+		pm.runningPluginsMu.Lock()
+		_, supported := pm.embeddedFactories[pluginID]
+		pm.runningPluginsMu.Unlock()
+		if !supported {
+			return "", fmt.Errorf("插件 '%s' 不支持嵌入式运行", pluginID)
+		}
+		version = EmbeddedVersion
+	default:
+		return "", fmt.Errorf("未知的实例运行方式 '%s'，可选值为 %s 或 %s", mode, InstanceModeProcess, InstanceModeEmbedded)
+	}
+
 	var count int
 	if err := pm.db.QueryRow("SELECT COUNT(*) FROM plugin_instances WHERE biz_name = ?", bizName).Scan(&count); err != nil {
 		return "", fmt.Errorf("检查 biz_name 时数据库出错: %w", err)
@@ -29,25 +53,41 @@ func (pm *PluginManager) CreateInstance(displayName, pluginID, version, bizName
 		return "", fmt.Errorf("业务组名称 (biz_name) '%s' 已被其他插件实例占用", bizName)
 	}
 
-	port, err := findFreePort()
+	port, err := pm.allocatePort()
 	if err != nil {
 		return "", fmt.Errorf("寻找可用端口失败: %w", err)
 	}
 
+	if err := validateProcessOptions(processOptions); err != nil {
+		return "", err
+	}
+	optionsJSON, err := json.Marshal(processOptions)
+	if err != nil {
+		return "", fmt.Errorf("序列化进程选项失败: %w", err)
+	}
+	dependsOn, err = pm.validateDependencies("", dependsOn)
+	if err != nil {
+		return "", err
+	}
+	dependsJSON, err := json.Marshal(dependsOn)
+	if err != nil {
+		return "", fmt.Errorf("序列化依赖失败: %w", err)
+	}
+
 	instanceID := uuid.New().String()
-	query := `INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, Port) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err = pm.db.Exec(query, instanceID, displayName, pluginID, version, bizName, port)
+	query := `INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, Port, mode, process_options, depends_on) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = pm.db.Exec(query, instanceID, displayName, pluginID, version, bizName, port, mode, string(optionsJSON), string(dependsJSON))
 	if err != nil {
 		return "", fmt.Errorf("创建插件实例配置失败: %w", err)
 	}
 
-	log.Printf("✅ [PluginManager] 已成功创建插件实例 '%s' (ID: %s)，绑定到业务组 '%s'。", displayName, instanceID, bizName)
+	log.Printf("✅ [PluginManager] 已成功创建插件实例 '%s' (ID: %s, 模式: %s)，绑定到业务组 '%s'。", displayName, instanceID, mode, bizName)
 	return instanceID, nil
 }
 
 // ListInstances 从数据库查询所有已配置的插件实例列表，并校准状态
 func (pm *PluginManager) ListInstances() ([]domain.PluginInstance, error) {
-	rows, err := pm.db.Query(`SELECT instance_id, display_name, plugin_id, version, biz_name, port, status, enabled, created_at, last_started_at FROM plugin_instances`)
+	rows, err := pm.db.Query(`SELECT instance_id, display_name, plugin_id, version, biz_name, port, mode, process_options, depends_on, status, enabled, created_at, last_started_at, last_stop_reason, last_stopped_at FROM plugin_instances`)
 	if err != nil {
 		return nil, fmt.Errorf("查询插件实例列表失败: %w", err)
 	}
@@ -56,13 +96,20 @@ func (pm *PluginManager) ListInstances() ([]domain.PluginInstance, error) {
 	var instances []domain.PluginInstance
 	for rows.Next() {
 		var p domain.PluginInstance
-		if err := rows.Scan(&p.InstanceID, &p.DisplayName, &p.PluginID, &p.Version, &p.BizName, &p.Port, &p.Status, &p.Enabled, &p.CreatedAt, &p.LastStartedAt); err != nil {
+		var optionsJSON, dependsJSON string
+		if err := rows.Scan(&p.InstanceID, &p.DisplayName, &p.PluginID, &p.Version, &p.BizName, &p.Port, &p.Mode, &optionsJSON, &dependsJSON, &p.Status, &p.Enabled, &p.CreatedAt, &p.LastStartedAt, &p.LastStopReason, &p.LastStoppedAt); err != nil {
 			log.Printf("⚠️ [PluginManager] 扫描插件实例行失败，已跳过: %v", err)
 			continue
 		}
+		if err := json.Unmarshal([]byte(optionsJSON), &p.ProcessOptions); err != nil {
+			log.Printf("⚠️ [PluginManager] 解析插件实例 '%s' 的进程选项失败: %v", p.InstanceID, err)
+		}
+		if err := json.Unmarshal([]byte(dependsJSON), &p.DependsOn); err != nil {
+			log.Printf("⚠️ [PluginManager] 解析插件实例 '%s' 的依赖失败: %v", p.InstanceID, err)
+		}
 
 		pm.runningPluginsMu.Lock()
-		if _, isRunning := pm.runningPlugins[p.InstanceID]; isRunning {
+		if pm.isRunningLocked(p.InstanceID) {
 			p.Status = "RUNNING"
 		} else if p.Status == "RUNNING" {
 			p.Status = "STOPPED"
@@ -81,7 +128,7 @@ func (pm *PluginManager) ListInstances() ([]domain.PluginInstance, error) {
 // DeleteInstance 从数据库中删除一个插件实例的配置。
 func (pm *PluginManager) DeleteInstance(instanceID string) error {
 	pm.runningPluginsMu.Lock()
-	_, isRunning := pm.runningPlugins[instanceID]
+	isRunning := pm.isRunningLocked(instanceID)
 	pm.runningPluginsMu.Unlock()
 	if isRunning {
 		return fmt.Errorf("无法删除正在运行的插件实例 '%s'，请先停止它", instanceID)
@@ -104,21 +151,32 @@ func (pm *PluginManager) DeleteInstance(instanceID string) error {
 // Start 启动一个已配置的插件实例。
 func (pm *PluginManager) Start(instanceID string) error {
 	pm.runningPluginsMu.Lock()
-	if _, isRunning := pm.runningPlugins[instanceID]; isRunning {
+	if pm.isRunningLocked(instanceID) {
 		pm.runningPluginsMu.Unlock()
 		return fmt.Errorf("插件实例 '%s' 已经在运行中", instanceID)
 	}
 	pm.runningPluginsMu.Unlock()
 
+	if err := pm.checkDependenciesReady(instanceID); err != nil {
+		return err
+	}
+
 	var inst domain.PluginInstance
 	var installPath string
-	query := `SELECT pi.display_name, pi.plugin_id, pi.version, pi.biz_name, pi.port, ip.install_path 
+	var optionsJSON string
+	query := `SELECT pi.display_name, pi.plugin_id, pi.version, pi.biz_name, pi.port, pi.mode, pi.process_options, ip.install_path 
               FROM plugin_instances pi 
               JOIN installed_plugins ip ON pi.plugin_id = ip.plugin_id AND pi.version = ip.version
               WHERE pi.instance_id = ?`
-	if err := pm.db.QueryRow(query, instanceID).Scan(&inst.DisplayName, &inst.PluginID, &inst.Version, &inst.BizName, &inst.Port, &installPath); err != nil {
+	if err := pm.db.QueryRow(query, instanceID).Scan(&inst.DisplayName, &inst.PluginID, &inst.Version, &inst.BizName, &inst.Port, &inst.Mode, &optionsJSON, &installPath); err != nil {
 		return fmt.Errorf("未找到插件实例 '%s' 或其安装信息: %w", instanceID, err)
 	}
+	if err := json.Unmarshal([]byte(optionsJSON), &inst.ProcessOptions); err != nil {
+		return fmt.Errorf("解析插件实例 '%s' 的进程选项失败: %w", instanceID, err)
+	}
+	if inst.Mode == InstanceModeEmbedded {
+		return pm.startEmbedded(instanceID, inst.PluginID, inst.BizName)
+	}
 
 	pm.catalogMu.RLock()
 	manifest, ok := pm.catalog[inst.PluginID]
@@ -137,15 +195,30 @@ func (pm *PluginManager) Start(instanceID string) error {
 	if targetVersion == nil {
 		return fmt.Errorf("插件 '%s' 的已安装版本 '%s' 的清单信息未找到", inst.PluginID, inst.Version)
 	}
+	entitlement, err := pm.checkLicense(manifest)
+	if err != nil {
+		return err
+	}
 
-	cmdPath := filepath.Join(installPath, targetVersion.Execution.Entrypoint)
+	// 使用绝对路径，设置了工作目录时相对路径会按工作目录解析
+	cmdPath, err := filepath.Abs(filepath.Join(installPath, targetVersion.Execution.Entrypoint))
+	if err != nil {
+		return fmt.Errorf("无法确定插件入口路径: %w", err)
+	}
 	instanceDir, err := filepath.Abs(filepath.Dir(pm.installDir))
 	if err != nil {
 		return fmt.Errorf("无法确定 instance 根目录: %w", err)
 	}
 
+	port, err := pm.ensureInstancePort(instanceID, inst.Port)
+	if err != nil {
+		return err
+	}
+	policy := pm.currentPortPolicy()
+
 	replacer := strings.NewReplacer(
-		"<port>", strconv.Itoa(inst.Port),
+		"<port>", strconv.Itoa(port),
+		"<host>", policy.BindAddress,
 		"<biz_name>", inst.BizName,
 		"<name>", inst.DisplayName,
 		"<instance_dir>", instanceDir,
@@ -155,18 +228,39 @@ func (pm *PluginManager) Start(instanceID string) error {
 		finalArgs[i] = replacer.Replace(arg)
 	}
 
-	cmd := exec.Command(cmdPath, finalArgs...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd, err := buildPluginCommand(cmdPath, finalArgs, inst.ProcessOptions, instanceDir)
+	if err != nil {
+		return err
+	}
+	if entitlement != nil {
+		if cmd.Env, err = withLicenseEnv(cmd.Env, entitlement); err != nil {
+			return err
+		}
+	}
+	pm.runningPluginsMu.Lock()
+	extraEnv := pm.pluginEnv
+	pm.runningPluginsMu.Unlock()
+	if len(extraEnv) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, extraEnv...)
+	}
+	// 插件输出同时保留在内存中，用于生成诊断包
+	logs := pm.diagnosticsFor(instanceID).logs
+	cmd.Stdout = io.MultiWriter(os.Stdout, logs)
+	cmd.Stderr = io.MultiWriter(os.Stderr, logs)
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("启动插件进程失败: %w", err)
 	}
 
+	proc := newPluginProcess(cmd)
 	pm.runningPluginsMu.Lock()
-	pm.runningPlugins[instanceID] = cmd
+	pm.runningPlugins[instanceID] = proc
 	pm.runningPluginsMu.Unlock()
 	log.Printf("🚀 [PluginManager] 插件实例 '%s' (%s) 进程已启动 (PID: %d)", inst.DisplayName, instanceID, cmd.Process.Pid)
+	pm.recordStartEvent(instanceID)
 
 	go func() {
 		if _, err := pm.db.Exec("UPDATE plugin_instances SET status = 'RUNNING', last_started_at = ? WHERE instance_id = ?", time.Now(), instanceID); err != nil {
@@ -174,26 +268,32 @@ func (pm *PluginManager) Start(instanceID string) error {
 		}
 	}()
 
-	go pm.registerAndMonitorPlugin(cmd, instanceID, "localhost:"+strconv.Itoa(inst.Port), inst.BizName)
+	go pm.registerAndMonitorPlugin(proc, instanceID, net.JoinHostPort(policy.dialHost(), strconv.Itoa(port)), inst.BizName)
 	return nil
 }
 
-// Stop 停止一个正在运行的插件实例。
+// Stop 停止一个正在运行的插件实例，停止原因记为管理员操作。
 func (pm *PluginManager) Stop(instanceID string) error {
-	pm.runningPluginsMu.Lock()
-	defer pm.runningPluginsMu.Unlock()
+	return pm.StopWithReason(instanceID, StopReasonAdmin)
+}
 
-	cmd, isRunning := pm.runningPlugins[instanceID]
-	if !isRunning {
+// StopWithReason 停止一个正在运行的插件实例，并将停止原因记录到 plugin_instances 中。
+// 独立进程模式的实例会先从网关注销，再通过 Shutdown RPC 请求其优雅退出，超时后依次发送 SIGTERM 与 SIGKILL。
+func (pm *PluginManager) StopWithReason(instanceID, reason string) error {
+	pm.runningPluginsMu.Lock()
+	proc, isRunning := pm.runningPlugins[instanceID]
+	embeddedAdapter, isEmbedded := pm.embeddedInstances[instanceID]
+	grace := pm.shutdownGracePeriod
+	if !isRunning && !isEmbedded {
+		pm.runningPluginsMu.Unlock()
 		_, _ = pm.db.Exec("UPDATE plugin_instances SET status = 'STOPPED' WHERE instance_id = ?", instanceID)
 		return fmt.Errorf("插件实例 '%s' 并未在运行中", instanceID)
 	}
-
-	if err := cmd.Process.Kill(); err != nil {
-		log.Printf("⚠️ [PluginManager] 停止插件进程 (PID: %d) 失败: %v", cmd.Process.Pid, err)
-	}
 	delete(pm.runningPlugins, instanceID)
+	delete(pm.embeddedInstances, instanceID)
+	pm.runningPluginsMu.Unlock()
 
+	// 先注销业务组，使新请求不再路由到即将退出的插件
 	pm.registryMu.Lock()
 	var bizToUnregister string
 	for biz, iID := range pm.bizToInstanceID {
@@ -209,8 +309,21 @@ func (pm *PluginManager) Stop(instanceID string) error {
 	}
 	pm.registryMu.Unlock()
 
-	log.Printf("👋 [PluginManager] 插件实例 '%s' 已停止。", instanceID)
-	_, err := pm.db.Exec("UPDATE plugin_instances SET status = 'STOPPED' WHERE instance_id = ?", instanceID)
+	if isEmbedded {
+		if err := embeddedAdapter.Close(); err != nil {
+			log.Printf("⚠️ [PluginManager] 关闭嵌入式实例 '%s' 失败: %v", instanceID, err)
+		}
+	} else {
+		pm.terminate(instanceID, proc, reason, grace)
+	}
+
+	log.Printf("👋 [PluginManager] 插件实例 '%s' 已停止 (原因: %s)。", instanceID, reason)
+	pm.recordInstanceEvent(instanceID, stopEvent(reason), reason)
+	_, err := pm.db.Exec("UPDATE plugin_instances SET status = 'STOPPED', last_stop_reason = ?, last_stopped_at = ? WHERE instance_id = ?", reason, time.Now(), instanceID)
+	if !isEmbedded {
+		// 在事件与状态写入之后记录，使自动生成的诊断包包含这次崩溃
+		pm.recordExit(instanceID, proc, reason)
+	}
 	return err
 }
 
@@ -240,6 +353,10 @@ func (pm *PluginManager) performAllHealthChecks() {
 	// 创建一个当前注册表的快照进行检查，避免长时间锁定
 	registrySnapshot := make(map[string]port.DataSource)
 	for bizName, ds := range pm.dataSourceRegistry {
+		// 静态数据源 (如虚拟业务组) 没有对应的插件进程，无需巡检
+		if _, isPlugin := pm.bizToInstanceID[bizName]; !isPlugin {
+			continue
+		}
 		registrySnapshot[bizName] = ds
 	}
 	pm.registryMu.RUnlock()
@@ -277,14 +394,14 @@ func (pm *PluginManager) checkPluginHealth(bizName string, ds port.DataSource) {
 
 		// 采取断然措施：直接停止并清理这个有问题的插件进程
 		log.Printf("- [PluginManager] 正在停止不健康的插件实例 '%s'...", instanceID)
-		if stopErr := pm.Stop(instanceID); stopErr != nil {
+		if stopErr := pm.StopWithReason(instanceID, StopReasonHealthCheck); stopErr != nil {
 			log.Printf("⚠️ [PluginManager] 停止不健康插件 '%s' 时发生错误: %v", instanceID, stopErr)
 		}
 	}
 }
 
 // registerAndMonitorPlugin 连接到新启动的插件，将其注册到网关，并监控其生命周期。
-func (pm *PluginManager) registerAndMonitorPlugin(cmd *exec.Cmd, instanceID, address, bizName string) {
+func (pm *PluginManager) registerAndMonitorPlugin(proc *pluginProcess, instanceID, address, bizName string) {
 	var adapter *grpc_client.ClientAdapter
 	var err error
 	maxRetries := 5
@@ -294,6 +411,7 @@ func (pm *PluginManager) registerAndMonitorPlugin(cmd *exec.Cmd, instanceID, add
 		log.Printf("ℹ️ [PluginManager] 正在尝试连接到实例 '%s' (%s), 第 %d/%d 次...", instanceID, address, i+1, maxRetries)
 		adapter, err = grpc_client.New(address)
 		if err == nil {
+			adapter.SetErrorObserver(func(method string, err error) { pm.recordRPCError(instanceID, method, err) })
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 			_, err = adapter.GetPluginInfo(ctx)
 			cancel()
@@ -308,33 +426,32 @@ func (pm *PluginManager) registerAndMonitorPlugin(cmd *exec.Cmd, instanceID, add
 	}
 	if err != nil {
 		log.Printf("⚠️ [PluginManager] 在 %d 次尝试后，仍无法连接到实例 '%s' 并获取信息: %v", maxRetries, instanceID, err)
-		_ = pm.Stop(instanceID)
+		_ = pm.StopWithReason(instanceID, StopReasonRegisterFailed)
 		return
 	}
 
 	pm.registryMu.Lock()
-	pm.dataSourceRegistry[bizName] = adapter
+	if pm.configVersion != nil {
+		adapter.SetConfigVersionSource(pm.configVersion)
+	}
+	var dataSource port.DataSource = adapter
+	if pm.decorator != nil {
+		dataSource = pm.decorator(bizName, adapter)
+	}
+	pm.dataSourceRegistry[bizName] = dataSource
 	pm.bizToInstanceID[bizName] = instanceID
 	*pm.closableAdapters = append(*pm.closableAdapters, adapter)
 	pm.registryMu.Unlock()
 
-	log.Printf("✅ [PluginManager] 实例 '%s' 现已在地址 '%s' 上运行，并为业务组 '%s' 提供服务。", instanceID, address, bizName)
+	pm.runningPluginsMu.Lock()
+	proc.adapter = adapter
+	pm.runningPluginsMu.Unlock()
+	pm.notifyRegistered(bizName, dataSource)
 
-	err = cmd.Wait()
-	log.Printf("🔌 [PluginManager] 检测到实例 '%s' 进程已退出，错误: %v。", instanceID, err)
-	_ = pm.Stop(instanceID)
-}
+	log.Printf("✅ [PluginManager] 实例 '%s' 现已在地址 '%s' 上运行，并为业务组 '%s' 提供服务。", instanceID, address, bizName)
 
-// findFreePort 查找一个可用的 TCP 端口
-func findFreePort() (int, error) {
-	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
-	if err != nil {
-		return 0, err
-	}
-	l, err := net.ListenTCP("tcp", addr)
-	if err != nil {
-		return 0, err
-	}
-	defer l.Close()
-	return l.Addr().(*net.TCPAddr).Port, nil
+	<-proc.exited
+	// 由网关主动停止的实例已不在运行列表中，此处的 Stop 只会修正状态，不会覆盖停止原因
+	log.Printf("🔌 [PluginManager] 检测到实例 '%s' 进程已退出，错误: %v。", instanceID, proc.waitErr)
+	_ = pm.StopWithReason(instanceID, StopReasonProcessExited)
 }
@@ -2,6 +2,7 @@
 package plugin_manager
 
 import (
+	"ArchiveAegis/internal/adapter/datasource/embedded"
 	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"ArchiveAegis/internal/downloader"
@@ -11,7 +12,7 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
+	"sort"
 	"sync"
 	"time"
 )
@@ -25,17 +26,46 @@ type PluginManager struct {
 	repositories       []RepositoryConfig
 	catalog            map[string]domain.PluginManifest
 	downloaders        []downloader.Downloader
-	runningPlugins     map[string]*exec.Cmd
+	runningPlugins     map[string]*pluginProcess
+	embeddedInstances  map[string]*embedded.DataSource
+	embeddedFactories  map[string]EmbeddedFactory
 	dataSourceRegistry map[string]port.DataSource
 	closableAdapters   *[]io.Closer
 	bizToInstanceID    map[string]string
 
+	// shutdownGracePeriod 是停止插件时等待其优雅退出的时长，由 runningPluginsMu 保护
+	shutdownGracePeriod time.Duration
+	// portPolicy 控制插件实例的监听地址与端口范围，由 runningPluginsMu 保护
+	portPolicy PortPolicy
+
+	// decorator 在插件注册到网关前对其 DataSource 进行包装 (例如加上缓存)，可为 nil
+	decorator DataSourceDecorator
+	// licenseChecker 校验需要授权的插件，由 catalogMu 保护，可为 nil
+	licenseChecker LicenseChecker
+	// pluginEnv 是附加给每个插件进程的环境变量 (例如网关配置接口的地址与令牌)，由 runningPluginsMu 保护
+	pluginEnv []string
+	// configVersion 返回网关当前的配置版本，转发给插件进程的请求携带该版本，由 registryMu 保护，可为 nil
+	configVersion func() string
+	// onRegister 在插件实例的数据源注册到网关后被异步调用 (例如预热)，由 registryMu 保护，可为 nil
+	onRegister RegistrationHook
+
+	// diagnostics 是各实例最近的日志、退出记录与 gRPC 错误，crashDumpPolicy 控制诊断包的收集，均由 diagnosticsMu 保护
+	diagnostics     map[string]*instanceDiagnostics
+	crashDumpPolicy CrashDumpPolicy
+
 	// Mutexes
 	catalogMu        sync.RWMutex
 	runningPluginsMu sync.Mutex
 	registryMu       sync.RWMutex
+	diagnosticsMu    sync.Mutex
 }
 
+// DataSourceDecorator 在插件注册到网关前包装其 DataSource
+type DataSourceDecorator func(bizName string, ds port.DataSource) port.DataSource
+
+// RegistrationHook 在插件实例的数据源注册到网关后被调用，ds 为包装后的数据源
+type RegistrationHook func(bizName string, ds port.DataSource)
+
 // RepositoryConfig 是在网关主配置中定义的仓库信息
 type RepositoryConfig struct {
 	Name    string `mapstructure:"name"`
@@ -55,23 +85,116 @@ func NewPluginManager(db *sql.DB, rootDir string, repos []RepositoryConfig, inst
 		return nil, fmt.Errorf("创建插件安装目录 '%s' 失败: %w", installDir, err)
 	}
 
-	supportedDownloaders := []downloader.Downloader{
+	return &PluginManager{
+		db:                  db,
+		rootDir:             rootDir,
+		installDir:          installDir,
+		repositories:        repos,
+		catalog:             make(map[string]domain.PluginManifest),
+		downloaders:         defaultDownloaders(),
+		runningPlugins:      make(map[string]*pluginProcess),
+		embeddedInstances:   make(map[string]*embedded.DataSource),
+		embeddedFactories:   make(map[string]EmbeddedFactory),
+		dataSourceRegistry:  registry,
+		closableAdapters:    closers,
+		bizToInstanceID:     make(map[string]string),
+		shutdownGracePeriod: DefaultShutdownGracePeriod,
+		diagnostics:         make(map[string]*instanceDiagnostics),
+		crashDumpPolicy:     CrashDumpPolicy{Enabled: true}.normalized(),
+	}, nil
+}
+
+// defaultDownloaders 返回网关支持的全部下载器
+func defaultDownloaders() []downloader.Downloader {
+	return []downloader.Downloader{
 		&downloader.HTTPDownloader{
 			Client: &http.Client{Timeout: 60 * time.Second},
 		},
 		&downloader.FileDownloader{},
 	}
+}
 
-	return &PluginManager{
-		db:                 db,
-		rootDir:            rootDir,
-		installDir:         installDir,
-		repositories:       repos,
-		catalog:            make(map[string]domain.PluginManifest),
-		downloaders:        supportedDownloaders,
-		runningPlugins:     make(map[string]*exec.Cmd),
-		dataSourceRegistry: registry,
-		closableAdapters:   closers,
-		bizToInstanceID:    make(map[string]string),
-	}, nil
+// SetDataSourceDecorator 设置插件注册到网关前使用的 DataSource 包装器。
+// 仅影响此后注册的插件实例，应在启动任何实例之前调用。
+func (pm *PluginManager) SetDataSourceDecorator(decorator DataSourceDecorator) {
+	pm.registryMu.Lock()
+	defer pm.registryMu.Unlock()
+	pm.decorator = decorator
+}
+
+// SetConfigVersionSource 设置网关配置版本的来源。插件进程各自缓存业务组配置，
+// 转发的请求携带该版本后，插件能在管理员修改配置后立即丢弃缓存。仅影响此后注册的插件实例
+func (pm *PluginManager) SetConfigVersionSource(version func() string) {
+	pm.registryMu.Lock()
+	defer pm.registryMu.Unlock()
+	pm.configVersion = version
+}
+
+// SetRegistrationHook 设置插件实例注册到网关后异步调用的钩子，实例每次 (重新) 启动都会调用一次
+func (pm *PluginManager) SetRegistrationHook(hook RegistrationHook) {
+	pm.registryMu.Lock()
+	defer pm.registryMu.Unlock()
+	pm.onRegister = hook
+}
+
+// notifyRegistered 在后台调用注册钩子，不阻塞实例的启动流程
+func (pm *PluginManager) notifyRegistered(bizName string, ds port.DataSource) {
+	pm.registryMu.RLock()
+	hook := pm.onRegister
+	pm.registryMu.RUnlock()
+	if hook != nil {
+		go hook(bizName, ds)
+	}
+}
+
+// SetPluginEnv 设置附加给此后启动的插件进程的环境变量，格式为 KEY=VALUE
+func (pm *PluginManager) SetPluginEnv(env []string) {
+	pm.runningPluginsMu.Lock()
+	defer pm.runningPluginsMu.Unlock()
+	pm.pluginEnv = append([]string(nil), env...)
+}
+
+// ErrBizServedByPlugin 表示该业务组名已由某个插件实例提供服务
+var ErrBizServedByPlugin = errors.New("该业务组名已由插件实例提供服务")
+
+// RegisterStaticDataSource 将一个不由插件进程提供的数据源 (例如虚拟业务组) 注册到网关。
+// 若业务组名已被插件实例占用则返回 ErrBizServedByPlugin；同名的静态数据源会被替换。
+func (pm *PluginManager) RegisterStaticDataSource(bizName string, ds port.DataSource) error {
+	pm.registryMu.Lock()
+	defer pm.registryMu.Unlock()
+	if _, owned := pm.bizToInstanceID[bizName]; owned {
+		return ErrBizServedByPlugin
+	}
+	pm.dataSourceRegistry[bizName] = ds
+	return nil
+}
+
+// UnregisterStaticDataSource 注销一个静态数据源，插件实例提供的业务组不受影响
+func (pm *PluginManager) UnregisterStaticDataSource(bizName string) {
+	pm.registryMu.Lock()
+	defer pm.registryMu.Unlock()
+	if _, owned := pm.bizToInstanceID[bizName]; owned {
+		return
+	}
+	delete(pm.dataSourceRegistry, bizName)
+}
+
+// LookupDataSource 按业务组名查找当前已注册的数据源
+func (pm *PluginManager) LookupDataSource(bizName string) (port.DataSource, bool) {
+	pm.registryMu.RLock()
+	defer pm.registryMu.RUnlock()
+	ds, ok := pm.dataSourceRegistry[bizName]
+	return ds, ok
+}
+
+// RegisteredBizNames 返回当前已注册数据源的业务组名
+func (pm *PluginManager) RegisteredBizNames() []string {
+	pm.registryMu.RLock()
+	defer pm.registryMu.RUnlock()
+	names := make([]string, 0, len(pm.dataSourceRegistry))
+	for bizName := range pm.dataSourceRegistry {
+		names = append(names, bizName)
+	}
+	sort.Strings(names)
+	return names
 }
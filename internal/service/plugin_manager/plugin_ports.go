@@ -0,0 +1,150 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_ports.go
+package plugin_manager
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+)
+
+// ErrPortUnavailable 表示插件实例的端口被其它进程占用，或端口范围已耗尽
+var ErrPortUnavailable = errors.New("插件端口不可用")
+
+// PortPolicy 控制插件实例监听的地址与端口范围，便于在防火墙中只放行固定的端口段
+type PortPolicy struct {
+	// BindAddress 是插件监听的地址，通过清单参数中的 <host> 占位符传给插件；为空时监听所有地址
+	BindAddress string `mapstructure:"bind_address"`
+	// RangeStart 与 RangeEnd 为闭区间；均为 0 时由系统分配随机端口
+	RangeStart int `mapstructure:"range_start"`
+	RangeEnd   int `mapstructure:"range_end"`
+}
+
+// hasRange 判断是否配置了端口范围
+func (p PortPolicy) hasRange() bool {
+	return p.RangeStart != 0 || p.RangeEnd != 0
+}
+
+// validate 校验端口范围与监听地址
+func (p PortPolicy) validate() error {
+	if p.hasRange() {
+		if p.RangeStart < 1 || p.RangeEnd > 65535 || p.RangeStart > p.RangeEnd {
+			return fmt.Errorf("无效的插件端口范围 [%d, %d]", p.RangeStart, p.RangeEnd)
+		}
+	}
+	if p.BindAddress != "" && net.ParseIP(p.BindAddress) == nil && p.BindAddress != "localhost" {
+		return fmt.Errorf("无效的插件监听地址 '%s'，必须是 IP 地址或 localhost", p.BindAddress)
+	}
+	return nil
+}
+
+// contains 判断端口是否落在配置的范围内；未配置范围时任何端口均可接受
+func (p PortPolicy) contains(port int) bool {
+	return !p.hasRange() || (port >= p.RangeStart && port <= p.RangeEnd)
+}
+
+// dialHost 返回网关连接插件时使用的地址。监听所有地址时通过本机回环地址连接。
+func (p PortPolicy) dialHost() string {
+	if ip := net.ParseIP(p.BindAddress); p.BindAddress == "" || (ip != nil && ip.IsUnspecified()) {
+		return "localhost"
+	}
+	return p.BindAddress
+}
+
+// SetPortPolicy 设置插件实例的监听地址与端口范围，应在启动任何实例之前调用
+func (pm *PluginManager) SetPortPolicy(policy PortPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	pm.runningPluginsMu.Lock()
+	defer pm.runningPluginsMu.Unlock()
+	pm.portPolicy = policy
+	return nil
+}
+
+// currentPortPolicy 返回当前的端口策略
+func (pm *PluginManager) currentPortPolicy() PortPolicy {
+	pm.runningPluginsMu.Lock()
+	defer pm.runningPluginsMu.Unlock()
+	return pm.portPolicy
+}
+
+// allocatePort 为实例分配端口：配置了范围时取范围内未被其它实例登记、且当前可绑定的最小端口，
+// 否则由系统分配。并发分配的冲突由 plugin_instances.port 的唯一约束兜底。
+func (pm *PluginManager) allocatePort() (int, error) {
+	policy := pm.currentPortPolicy()
+	if !policy.hasRange() {
+		return findFreePort(policy.BindAddress)
+	}
+
+	rows, err := pm.db.Query(`SELECT port FROM plugin_instances WHERE port BETWEEN ? AND ?`, policy.RangeStart, policy.RangeEnd)
+	if err != nil {
+		return 0, fmt.Errorf("查询已分配的插件端口失败: %w", err)
+	}
+	taken := make(map[int]bool)
+	for rows.Next() {
+		var p int
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("扫描已分配的插件端口失败: %w", err)
+		}
+		taken[p] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for p := policy.RangeStart; p <= policy.RangeEnd; p++ {
+		if !taken[p] && portAvailable(policy.BindAddress, p) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: 端口范围 [%d, %d] 内已没有可用端口", ErrPortUnavailable, policy.RangeStart, policy.RangeEnd)
+}
+
+// ensureInstancePort 在启动前确认实例的端口可用。实例在重启之间沿用同一端口；
+// 只有端口不在当前配置的范围内 (例如范围被修改) 时才重新分配并持久化。
+// 端口被其它进程占用时直接报错，而不是悄悄换一个端口，以免与防火墙规则不一致。
+func (pm *PluginManager) ensureInstancePort(instanceID string, port int) (int, error) {
+	policy := pm.currentPortPolicy()
+	if !policy.contains(port) {
+		newPort, err := pm.allocatePort()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := pm.db.Exec(`UPDATE plugin_instances SET port = ? WHERE instance_id = ?`, newPort, instanceID); err != nil {
+			return 0, fmt.Errorf("更新插件实例 '%s' 的端口失败: %w", instanceID, err)
+		}
+		log.Printf("ℹ️ [PluginManager] 插件实例 '%s' 的端口 %d 不在配置范围内，已重新分配为 %d。", instanceID, port, newPort)
+		return newPort, nil
+	}
+	if !portAvailable(policy.BindAddress, port) {
+		return 0, fmt.Errorf("%w: 端口 %d 已被其它进程占用", ErrPortUnavailable, port)
+	}
+	return port, nil
+}
+
+// portAvailable 通过尝试监听判断端口当前是否可绑定
+func portAvailable(host string, port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
+
+// findFreePort 由系统在指定地址上分配一个可用的 TCP 端口
+func findFreePort(host string) (int, error) {
+	if host == "" {
+		host = "localhost"
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,102 @@
+// file: internal/service/plugin_manager/plugin_ports_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPortTestManager(t *testing.T) *PluginManager {
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+	_, err := db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES ('io.example.sqlite', '1.0.0', '')`)
+	require.NoError(t, err)
+
+	registry := make(map[string]port.DataSource)
+	closers := make([]io.Closer, 0)
+	pm, err := NewPluginManager(db, dir, nil, filepath.Join(dir, "plugins"), registry, &closers)
+	require.NoError(t, err)
+	return pm
+}
+
+// reserveRange 找到一段连续的空闲端口并占用其中第 occupied 个，返回范围起点
+func reserveRange(t *testing.T, size, occupied int) int {
+	for base := 40000; base < 60000; base += size {
+		free := true
+		for p := base; p < base+size; p++ {
+			if !portAvailable("127.0.0.1", p) {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(base+occupied)))
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+		return base
+	}
+	t.Skip("找不到连续的空闲端口")
+	return 0
+}
+
+func TestPortPolicyValidate(t *testing.T) {
+	assert.NoError(t, PortPolicy{}.validate())
+	assert.NoError(t, PortPolicy{BindAddress: "127.0.0.1", RangeStart: 50100, RangeEnd: 50199}.validate())
+	assert.Error(t, PortPolicy{RangeStart: 50199, RangeEnd: 50100}.validate())
+	assert.Error(t, PortPolicy{RangeStart: 0, RangeEnd: 70000}.validate())
+	assert.Error(t, PortPolicy{BindAddress: "example.org"}.validate())
+
+	assert.Equal(t, "localhost", PortPolicy{}.dialHost())
+	assert.Equal(t, "localhost", PortPolicy{BindAddress: "0.0.0.0"}.dialHost())
+	assert.Equal(t, "10.0.0.5", PortPolicy{BindAddress: "10.0.0.5"}.dialHost())
+}
+
+func TestAllocateAndEnsureInstancePort(t *testing.T) {
+	pm := newPortTestManager(t)
+	base := reserveRange(t, 4, 1)
+	require.NoError(t, pm.SetPortPolicy(PortPolicy{BindAddress: "127.0.0.1", RangeStart: base, RangeEnd: base + 3}))
+
+	// 第一个端口被登记给已有实例，第二个被其它进程占用，因此分配到第三个
+	_, err := pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('a', 'a', 'io.example.sqlite', '1.0.0', 'a', ?)`, base)
+	require.NoError(t, err)
+	p, err := pm.allocatePort()
+	require.NoError(t, err)
+	assert.Equal(t, base+2, p)
+
+	// 范围内且可用的端口在重启之间保持不变
+	got, err := pm.ensureInstancePort("a", base)
+	require.NoError(t, err)
+	assert.Equal(t, base, got)
+
+	// 端口被占用时报错而不是悄悄换端口
+	_, err = pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('b', 'b', 'io.example.sqlite', '1.0.0', 'b', ?)`, base+1)
+	require.NoError(t, err)
+	_, err = pm.ensureInstancePort("b", base+1)
+	assert.ErrorIs(t, err, ErrPortUnavailable)
+
+	// 不在范围内的端口会被重新分配并持久化
+	_, err = pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('c', 'c', 'io.example.sqlite', '1.0.0', 'c', 1)`)
+	require.NoError(t, err)
+	got, err = pm.ensureInstancePort("c", 1)
+	require.NoError(t, err)
+	assert.Equal(t, base+2, got)
+	var stored int
+	require.NoError(t, pm.db.QueryRow(`SELECT port FROM plugin_instances WHERE instance_id = 'c'`).Scan(&stored))
+	assert.Equal(t, base+2, stored)
+
+	// 范围耗尽
+	_, err = pm.db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port) VALUES ('d', 'd', 'io.example.sqlite', '1.0.0', 'd', ?)`, base+3)
+	require.NoError(t, err)
+	_, err = pm.allocatePort()
+	assert.ErrorIs(t, err, ErrPortUnavailable)
+}
@@ -0,0 +1,150 @@
+// Package plugin_manager file: internal/service/plugin_process.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidProcessOptions 表示插件进程选项不合法
+var ErrInvalidProcessOptions = errors.New("无效的插件进程选项")
+
+// validateProcessOptions 校验进程选项，在保存时调用，避免到启动时才发现问题
+func validateProcessOptions(opts domain.PluginProcessOptions) error {
+	for _, name := range opts.EnvAllowlist {
+		if name == "" || strings.ContainsAny(name, "=") || (strings.Contains(name, "*") && !strings.HasSuffix(name, "*")) || strings.Count(name, "*") > 1 {
+			return fmt.Errorf("%w: 环境变量白名单项 '%s' 不合法，只支持末尾的 * 通配", ErrInvalidProcessOptions, name)
+		}
+	}
+	for name := range opts.Env {
+		if name == "" || strings.ContainsAny(name, "=*") {
+			return fmt.Errorf("%w: 环境变量名 '%s' 不合法", ErrInvalidProcessOptions, name)
+		}
+	}
+	if opts.Umask != "" {
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("%w: umask 仅在类 Unix 系统上可用", ErrInvalidProcessOptions)
+		}
+		if _, err := parseUmask(opts.Umask); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseUmask 解析八进制的 umask 字符串
+func parseUmask(s string) (uint32, error) {
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > 0777 {
+		return 0, fmt.Errorf("%w: umask '%s' 必须是 0000-0777 之间的八进制数", ErrInvalidProcessOptions, s)
+	}
+	return uint32(v), nil
+}
+
+// buildProcessEnv 根据白名单过滤父进程环境变量，并叠加注入的变量。结果按变量名排序，便于排查。
+func buildProcessEnv(parent []string, opts domain.PluginProcessOptions) []string {
+	env := make(map[string]string, len(parent)+len(opts.Env))
+	for _, kv := range parent {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if len(opts.EnvAllowlist) == 0 || envAllowed(name, opts.EnvAllowlist) {
+			env[name] = value
+		}
+	}
+	for name, value := range opts.Env {
+		env[name] = value
+	}
+
+	out := make([]string, 0, len(env))
+	for name, value := range env {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func envAllowed(name string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPluginCommand 按进程选项构造插件命令。
+// Go 的 exec 无法直接为子进程设置 umask，而修改网关自身的 umask 会影响并发的文件操作，
+// 因此设置 umask 时通过 /bin/sh 先设置掩码再 exec 插件，插件的 PID 与参数保持不变。
+func buildPluginCommand(cmdPath string, args []string, opts domain.PluginProcessOptions, instanceDir string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if opts.Umask != "" {
+		mask, err := parseUmask(opts.Umask)
+		if err != nil {
+			return nil, err
+		}
+		shellArgs := append([]string{"-c", fmt.Sprintf(`umask %04o && exec "$0" "$@"`, mask), cmdPath}, args...)
+		cmd = exec.Command("/bin/sh", shellArgs...)
+	} else {
+		cmd = exec.Command(cmdPath, args...)
+	}
+
+	if opts.WorkDir != "" {
+		dir := opts.WorkDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(instanceDir, dir)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("插件工作目录 '%s' 不存在或不是目录", dir)
+		}
+		cmd.Dir = dir
+	}
+	if len(opts.EnvAllowlist) > 0 || len(opts.Env) > 0 {
+		cmd.Env = buildProcessEnv(os.Environ(), opts)
+	}
+	return cmd, nil
+}
+
+func isZeroProcessOptions(opts domain.PluginProcessOptions) bool {
+	return len(opts.EnvAllowlist) == 0 && len(opts.Env) == 0 && opts.WorkDir == "" && opts.Umask == ""
+}
+
+// UpdateProcessOptions 修改独立进程实例的进程选项，在实例下次启动时生效
+func (pm *PluginManager) UpdateProcessOptions(instanceID string, opts domain.PluginProcessOptions) error {
+	var mode string
+	if err := pm.db.QueryRow(`SELECT mode FROM plugin_instances WHERE instance_id = ?`, instanceID).Scan(&mode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("未找到插件实例 '%s'", instanceID)
+		}
+		return fmt.Errorf("查询插件实例 '%s' 失败: %w", instanceID, err)
+	}
+	if mode == InstanceModeEmbedded && !isZeroProcessOptions(opts) {
+		return fmt.Errorf("%w: 嵌入式实例运行在网关进程内，不支持进程选项", ErrInvalidProcessOptions)
+	}
+	if err := validateProcessOptions(opts); err != nil {
+		return err
+	}
+	optionsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("序列化进程选项失败: %w", err)
+	}
+	if _, err := pm.db.Exec(`UPDATE plugin_instances SET process_options = ? WHERE instance_id = ?`, string(optionsJSON), instanceID); err != nil {
+		return fmt.Errorf("更新插件实例 '%s' 的进程选项失败: %w", instanceID, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// file: internal/service/plugin_manager/plugin_process_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProcessEnv(t *testing.T) {
+	parent := []string{"PATH=/bin", "AEGIS_JWT_KEY=secret", "LC_ALL=C", "LC_CTYPE=UTF-8", "HOME=/root"}
+
+	// 未配置白名单时继承全部，注入的变量覆盖同名变量
+	env := buildProcessEnv(parent, domain.PluginProcessOptions{Env: map[string]string{"HOME": "/srv/plugin"}})
+	assert.Contains(t, env, "AEGIS_JWT_KEY=secret")
+	assert.Contains(t, env, "HOME=/srv/plugin")
+	assert.NotContains(t, env, "HOME=/root")
+
+	env = buildProcessEnv(parent, domain.PluginProcessOptions{
+		EnvAllowlist: []string{"PATH", "LC_*"},
+		Env:          map[string]string{"PLUGIN_MODE": "readonly"},
+	})
+	assert.Equal(t, []string{"LC_ALL=C", "LC_CTYPE=UTF-8", "PATH=/bin", "PLUGIN_MODE=readonly"}, env)
+}
+
+func TestValidateProcessOptions(t *testing.T) {
+	assert.NoError(t, validateProcessOptions(domain.PluginProcessOptions{EnvAllowlist: []string{"PATH", "LC_*"}}))
+	assert.ErrorIs(t, validateProcessOptions(domain.PluginProcessOptions{EnvAllowlist: []string{"*_KEY"}}), ErrInvalidProcessOptions)
+	assert.ErrorIs(t, validateProcessOptions(domain.PluginProcessOptions{Env: map[string]string{"A=B": "x"}}), ErrInvalidProcessOptions)
+	if runtime.GOOS != "windows" {
+		assert.NoError(t, validateProcessOptions(domain.PluginProcessOptions{Umask: "0077"}))
+		assert.ErrorIs(t, validateProcessOptions(domain.PluginProcessOptions{Umask: "0999"}), ErrInvalidProcessOptions)
+		assert.ErrorIs(t, validateProcessOptions(domain.PluginProcessOptions{Umask: "1777"}), ErrInvalidProcessOptions)
+	}
+}
+
+func TestBuildPluginCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 /bin/sh")
+	}
+	instanceDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(instanceDir, "work"), 0755))
+	t.Setenv("AEGIS_TEST_SECRET", "leak")
+	t.Setenv("AEGIS_TEST_KEEP", "keep")
+
+	cmd, err := buildPluginCommand("/bin/sh", []string{"-c", `umask; pwd; echo "[$AEGIS_TEST_SECRET][$AEGIS_TEST_KEEP][$INJECTED]"`}, domain.PluginProcessOptions{
+		EnvAllowlist: []string{"PATH", "AEGIS_TEST_KEEP"},
+		Env:          map[string]string{"INJECTED": "yes"},
+		WorkDir:      "work",
+		Umask:        "027",
+	}, instanceDir)
+	require.NoError(t, err)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "0027", lines[0])
+	resolved, _ := filepath.EvalSymlinks(filepath.Join(instanceDir, "work"))
+	assert.Equal(t, resolved, lines[1])
+	assert.Equal(t, "[][keep][yes]", lines[2])
+
+	_, err = buildPluginCommand("/bin/true", nil, domain.PluginProcessOptions{WorkDir: "missing"}, instanceDir)
+	assert.Error(t, err)
+}
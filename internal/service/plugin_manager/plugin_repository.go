@@ -3,6 +3,7 @@ package plugin_manager
 
 import (
 	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/downloader"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -58,9 +59,27 @@ func (pm *PluginManager) GetAvailablePlugins() []domain.PluginManifest {
 	return catalogSlice
 }
 
+// ProbeRepository 读取并解析一个仓库，返回其中的插件数量。
+// 不依赖 PluginManager 实例，供启动自检在网关完整初始化之前使用；相对路径按 rootDir 解析。
+func ProbeRepository(rootDir string, repoCfg RepositoryConfig) (int, error) {
+	data, err := fetchRepository(rootDir, defaultDownloaders(), repoCfg.URL)
+	if err != nil {
+		return 0, err
+	}
+	var repo domain.Repository
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return 0, fmt.Errorf("解析仓库 JSON 失败: %w", err)
+	}
+	return len(repo.Plugins), nil
+}
+
 // fetchRepository 从远程插件仓库源中读取原始内容
 func (pm *PluginManager) fetchRepository(repoURL string) ([]byte, error) {
-	reader, err := pm.getSourceReader(repoURL)
+	return fetchRepository(pm.rootDir, pm.downloaders, repoURL)
+}
+
+func fetchRepository(rootDir string, downloaders []downloader.Downloader, repoURL string) ([]byte, error) {
+	reader, err := getSourceReader(rootDir, downloaders, repoURL)
 	if err != nil {
 		return nil, fmt.Errorf("获取仓库源失败 (URL: %s): %w", repoURL, err)
 	}
@@ -83,13 +102,17 @@ func (pm *PluginManager) fetchRepository(repoURL string) ([]byte, error) {
 
 // getSourceReader 根据 URL scheme 选择合适的下载器
 func (pm *PluginManager) getSourceReader(rawURL string) (io.ReadCloser, error) {
+	return getSourceReader(pm.rootDir, pm.downloaders, rawURL)
+}
+
+func getSourceReader(rootDir string, downloaders []downloader.Downloader, rawURL string) (io.ReadCloser, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil || u.Scheme == "" {
-		absPath := filepath.Join(pm.rootDir, rawURL)
+		absPath := filepath.Join(rootDir, rawURL)
 		return os.Open(absPath)
 	}
 
-	for _, d := range pm.downloaders {
+	for _, d := range downloaders {
 		if d.SupportsScheme(u.Scheme) {
 			return d.Download(u)
 		}
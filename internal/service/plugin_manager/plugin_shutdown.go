@@ -0,0 +1,109 @@
+// Package plugin_manager file: internal/service/plugin_manager/plugin_shutdown.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/grpc_client"
+	"context"
+	"log"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// 插件实例停止原因，记录在 plugin_instances.last_stop_reason 中
+const (
+	StopReasonAdmin           = "admin_stop"          // 管理员通过 API 停止
+	StopReasonHealthCheck     = "health_check_failed" // 健康检查失败后被网关停止
+	StopReasonRegisterFailed  = "register_failed"     // 启动后无法连接或注册到网关
+	StopReasonProcessExited   = "process_exited"      // 插件进程自行退出
+	StopReasonGatewayShutdown = "gateway_shutdown"    // 网关停机
+	StopReasonUpgrade         = "upgrade"             // 切换到新版本前停止
+)
+
+const (
+	// DefaultShutdownGracePeriod 是请求插件优雅退出后等待其进程结束的默认时长
+	DefaultShutdownGracePeriod = 10 * time.Second
+	// terminateGracePeriod 是发送 SIGTERM 后、发送 SIGKILL 前的等待时长
+	terminateGracePeriod = 3 * time.Second
+)
+
+// pluginProcess 是一个独立进程模式的插件实例
+type pluginProcess struct {
+	cmd *exec.Cmd
+	// exited 在进程退出 (cmd.Wait 返回) 后关闭，waitErr 此时可读
+	exited  chan struct{}
+	waitErr error
+	// adapter 在插件注册到网关后设置，用于发送 Shutdown 请求；注册前为 nil
+	adapter *grpc_client.ClientAdapter
+}
+
+// newPluginProcess 包装一个已启动的进程，并在后台等待其退出。
+// cmd.Wait 只能调用一次，其它地方应通过 exited 判断进程是否已结束。
+func newPluginProcess(cmd *exec.Cmd) *pluginProcess {
+	proc := &pluginProcess{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		proc.waitErr = cmd.Wait()
+		close(proc.exited)
+	}()
+	return proc
+}
+
+// waitExit 在 timeout 内等待进程退出，返回进程是否已退出
+func (p *pluginProcess) waitExit(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.exited:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// SetShutdownGracePeriod 设置停止插件时等待其优雅退出的时长，非正值表示使用默认值
+func (pm *PluginManager) SetShutdownGracePeriod(d time.Duration) {
+	pm.runningPluginsMu.Lock()
+	defer pm.runningPluginsMu.Unlock()
+	if d <= 0 {
+		d = DefaultShutdownGracePeriod
+	}
+	pm.shutdownGracePeriod = d
+}
+
+// terminate 依次尝试 Shutdown RPC、SIGTERM 与 SIGKILL，直到插件进程退出。
+// 前一步在宽限期内未能使进程退出时才进入下一步。
+func (pm *PluginManager) terminate(instanceID string, proc *pluginProcess, reason string, grace time.Duration) {
+	select {
+	case <-proc.exited:
+		return
+	default:
+	}
+	pid := proc.cmd.Process.Pid
+
+	if proc.adapter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		err := proc.adapter.Shutdown(ctx, reason, grace)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ [PluginManager] 请求插件实例 '%s' 优雅退出失败: %v", instanceID, err)
+		} else if proc.waitExit(grace) {
+			return
+		} else {
+			log.Printf("⚠️ [PluginManager] 插件实例 '%s' (PID: %d) 未在 %v 内退出，发送 SIGTERM", instanceID, pid, grace)
+		}
+	}
+
+	if err := proc.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		// Windows 等平台不支持 SIGTERM，直接强制结束
+		log.Printf("⚠️ [PluginManager] 向插件进程 (PID: %d) 发送 SIGTERM 失败: %v", pid, err)
+	} else if proc.waitExit(terminateGracePeriod) {
+		return
+	}
+
+	log.Printf("⚠️ [PluginManager] 插件进程 (PID: %d) 未响应 SIGTERM，强制结束", pid)
+	if err := proc.cmd.Process.Kill(); err != nil {
+		log.Printf("⚠️ [PluginManager] 停止插件进程 (PID: %d) 失败: %v", pid, err)
+		return
+	}
+	<-proc.exited
+}
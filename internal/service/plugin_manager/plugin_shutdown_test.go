@@ -0,0 +1,59 @@
+// file: internal/service/plugin_manager/plugin_shutdown_test.go
+package plugin_manager
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopWithReason_FallsBackToSigterm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 SIGTERM")
+	}
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+
+	registry := make(map[string]port.DataSource)
+	closers := make([]io.Closer, 0)
+	pm, err := NewPluginManager(db, dir, nil, filepath.Join(dir, "plugins"), registry, &closers)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES ('io.example.sleep', '1.0.0', '')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port, status) VALUES ('i1', 'sleep', 'io.example.sleep', '1.0.0', 'letters', 50999, 'RUNNING')`)
+	require.NoError(t, err)
+
+	// 未注册到网关的进程没有 gRPC 连接，停止时应直接进入 SIGTERM 阶段
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	proc := newPluginProcess(cmd)
+	pm.runningPlugins["i1"] = proc
+
+	require.NoError(t, pm.StopWithReason("i1", StopReasonHealthCheck))
+	select {
+	case <-proc.exited:
+	default:
+		t.Fatal("StopWithReason 返回时进程应已退出")
+	}
+
+	instances, err := pm.ListInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "STOPPED", instances[0].Status)
+	assert.Equal(t, StopReasonHealthCheck, instances[0].LastStopReason)
+	assert.True(t, instances[0].LastStoppedAt.Valid)
+
+	// 已停止的实例再次停止时报错，且不覆盖记录的原因
+	assert.Error(t, pm.StopWithReason("i1", StopReasonProcessExited))
+	instances, err = pm.ListInstances()
+	require.NoError(t, err)
+	assert.Equal(t, StopReasonHealthCheck, instances[0].LastStopReason)
+}
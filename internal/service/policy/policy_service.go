@@ -0,0 +1,175 @@
+// Package policy file: internal/service/policy/policy_service.go
+package policy
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeoutMs = 2000
+	maxResponseBytes = 1 << 20
+)
+
+// ErrUnavailable 表示授权钩子无法得出结论 (策略引擎不可达、超时或返回了无法识别的结果)
+var ErrUnavailable = errors.New("授权策略暂时无法评估")
+
+// Options 定义外部授权钩子的配置
+type Options struct {
+	// URL 是 HTTP 策略回调的地址，为空时只使用进程内注册的钩子。请求体为 {"input": PolicyInput}，
+	// 响应兼容 OPA 的 {"result": true} 或 {"result": {"allow": true, "reason": "..."}}
+	URL string `mapstructure:"url"`
+	// Headers 是附加的请求头，通常用于携带策略引擎的认证 Token
+	Headers   map[string]string `mapstructure:"headers"`
+	TimeoutMs int               `mapstructure:"timeout_ms"` // 默认为 2000
+	// FailOpen 为 true 时钩子出错按放行处理，默认拒绝 (返回 503)
+	FailOpen bool `mapstructure:"fail_open"`
+	// Planes 是需要评估的请求平面 (data、admin)，为空表示两者都评估
+	Planes []string `mapstructure:"planes"`
+}
+
+// Service 在数据平面与管理平面的请求到达处理器之前依次调用已注册的授权钩子，
+// 任一钩子拒绝即拒绝请求。未注册任何钩子时不做任何评估
+type Service struct {
+	opts   Options
+	planes map[string]bool
+
+	mu    sync.RWMutex
+	names []string
+	hooks []port.AuthorizationHook
+}
+
+// NewService 创建一个新的授权钩子服务实例，配置了 URL 时注册 HTTP 策略回调
+func NewService(opts Options) (*Service, error) {
+	if opts.TimeoutMs < 0 {
+		return nil, fmt.Errorf("timeout_ms 不能为负数: %d", opts.TimeoutMs)
+	}
+	if opts.TimeoutMs == 0 {
+		opts.TimeoutMs = defaultTimeoutMs
+	}
+	s := &Service{opts: opts, planes: make(map[string]bool)}
+	for _, plane := range opts.Planes {
+		if plane != port.PolicyPlaneData && plane != port.PolicyPlaneAdmin {
+			return nil, fmt.Errorf("未知的请求平面 '%s'，可选 data 或 admin", plane)
+		}
+		s.planes[plane] = true
+	}
+	if len(s.planes) == 0 {
+		s.planes[port.PolicyPlaneData] = true
+		s.planes[port.PolicyPlaneAdmin] = true
+	}
+	if opts.URL != "" {
+		hook, err := NewHTTPHook(opts.URL, opts.Headers, time.Duration(opts.TimeoutMs)*time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		s.Register("http", hook)
+	}
+	return s, nil
+}
+
+// Register 注册一个进程内的授权钩子，钩子按注册顺序评估。name 用于日志
+func (s *Service) Register(name string, hook port.AuthorizationHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.names = append(s.names, name)
+	s.hooks = append(s.hooks, hook)
+}
+
+// Covers 返回是否需要评估该平面的请求
+func (s *Service) Covers(plane string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.hooks) > 0 && s.planes[plane]
+}
+
+// Evaluate 依次调用授权钩子，返回第一个拒绝的结论，全部放行时返回放行。
+// 钩子出错时，FailOpen 为 true 则记录日志并继续评估，否则返回 ErrUnavailable
+func (s *Service) Evaluate(ctx context.Context, input port.PolicyInput) (port.PolicyDecision, error) {
+	s.mu.RLock()
+	names, hooks := s.names, s.hooks
+	s.mu.RUnlock()
+	for i, hook := range hooks {
+		decision, err := hook.Authorize(ctx, input)
+		if err != nil {
+			if s.opts.FailOpen {
+				slog.Warn("[Policy] 授权钩子出错，按配置放行", "hook", names[i], "path", input.Path, "error", err)
+				continue
+			}
+			slog.Error("[Policy] 授权钩子出错，拒绝请求", "hook", names[i], "path", input.Path, "error", err)
+			return port.PolicyDecision{}, fmt.Errorf("%w: %s", ErrUnavailable, names[i])
+		}
+		if !decision.Allow {
+			return decision, nil
+		}
+	}
+	return port.PolicyDecision{Allow: true}, nil
+}
+
+// HTTPHook 把请求上下文 POST 到外部策略引擎 (如 OPA 的 /v1/data/<package>/<rule>)
+type HTTPHook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPHook 创建一个 HTTP 策略回调
+func NewHTTPHook(rawURL string, headers map[string]string, timeout time.Duration) (*HTTPHook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("策略回调地址必须是 http 或 https 地址: '%s'", rawURL)
+	}
+	return &HTTPHook{url: rawURL, headers: headers, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Authorize 实现 port.AuthorizationHook。规则未定义 (响应中没有 result) 时视为拒绝
+func (h *HTTPHook) Authorize(ctx context.Context, input port.PolicyInput) (port.PolicyDecision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return port.PolicyDecision{}, fmt.Errorf("序列化策略输入失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return port.PolicyDecision{}, fmt.Errorf("创建策略请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return port.PolicyDecision{}, fmt.Errorf("请求策略引擎失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return port.PolicyDecision{}, fmt.Errorf("策略引擎返回状态码 %d", resp.StatusCode)
+	}
+	var out struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&out); err != nil {
+		return port.PolicyDecision{}, fmt.Errorf("解析策略引擎响应失败: %w", err)
+	}
+	if len(out.Result) == 0 || string(out.Result) == "null" {
+		return port.PolicyDecision{Allow: false, Reason: "策略未定义"}, nil
+	}
+	var allow bool
+	if err := json.Unmarshal(out.Result, &allow); err == nil {
+		return port.PolicyDecision{Allow: allow}, nil
+	}
+	var decision port.PolicyDecision
+	if err := json.Unmarshal(out.Result, &decision); err != nil {
+		return port.PolicyDecision{}, fmt.Errorf("无法识别的策略结果: %s", out.Result)
+	}
+	return decision, nil
+}
@@ -0,0 +1,101 @@
+// file: internal/service/policy/policy_service_test.go
+package policy
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService_Validation(t *testing.T) {
+	_, err := NewService(Options{Planes: []string{"meta"}})
+	assert.Error(t, err)
+	_, err = NewService(Options{URL: "opa:8181"})
+	assert.Error(t, err)
+
+	svc, err := NewService(Options{Planes: []string{port.PolicyPlaneAdmin}})
+	require.NoError(t, err)
+	assert.False(t, svc.Covers(port.PolicyPlaneAdmin), "未注册钩子时不评估")
+	svc.Register("noop", port.AuthorizationHookFunc(func(context.Context, port.PolicyInput) (port.PolicyDecision, error) {
+		return port.PolicyDecision{Allow: true}, nil
+	}))
+	assert.True(t, svc.Covers(port.PolicyPlaneAdmin))
+	assert.False(t, svc.Covers(port.PolicyPlaneData))
+}
+
+func TestHTTPHook_OPAResults(t *testing.T) {
+	var result string
+	var got map[string]port.PolicyInput
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer opa", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(result))
+	}))
+	defer opa.Close()
+
+	svc, err := NewService(Options{URL: opa.URL, Headers: map[string]string{"Authorization": "Bearer opa"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+	input := port.PolicyInput{Plane: port.PolicyPlaneData, BizName: "archive", Table: "letters", Operation: "query"}
+
+	for _, tc := range []struct {
+		result string
+		allow  bool
+		reason string
+	}{
+		{`{"result": true}`, true, ""},
+		{`{"result": false}`, false, ""},
+		{`{"result": {"allow": false, "reason": "仅限工作时间"}}`, false, "仅限工作时间"},
+		{`{}`, false, "策略未定义"},
+	} {
+		result = tc.result
+		decision, err := svc.Evaluate(ctx, input)
+		require.NoError(t, err, tc.result)
+		assert.Equal(t, tc.allow, decision.Allow, tc.result)
+		if !tc.allow && tc.reason != "" {
+			assert.Equal(t, tc.reason, decision.Reason)
+		}
+	}
+	assert.Equal(t, "letters", got["input"].Table)
+
+	result = `{"result": "yes"}`
+	_, err = svc.Evaluate(ctx, input)
+	assert.True(t, errors.Is(err, ErrUnavailable))
+}
+
+func TestEvaluate_FailOpenAndOrder(t *testing.T) {
+	failing := port.AuthorizationHookFunc(func(context.Context, port.PolicyInput) (port.PolicyDecision, error) {
+		return port.PolicyDecision{}, errors.New("timeout")
+	})
+	calls := 0
+	deny := port.AuthorizationHookFunc(func(context.Context, port.PolicyInput) (port.PolicyDecision, error) {
+		calls++
+		return port.PolicyDecision{Allow: false, Reason: "denied"}, nil
+	})
+
+	closed, err := NewService(Options{})
+	require.NoError(t, err)
+	closed.Register("failing", failing)
+	closed.Register("deny", deny)
+	_, err = closed.Evaluate(context.Background(), port.PolicyInput{})
+	assert.True(t, errors.Is(err, ErrUnavailable))
+	assert.Zero(t, calls, "出错后不再评估后续钩子")
+
+	open, err := NewService(Options{FailOpen: true})
+	require.NoError(t, err)
+	open.Register("failing", failing)
+	decision, err := open.Evaluate(context.Background(), port.PolicyInput{})
+	require.NoError(t, err)
+	assert.True(t, decision.Allow)
+	open.Register("deny", deny)
+	decision, err = open.Evaluate(context.Background(), port.PolicyInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "denied", decision.Reason)
+}
@@ -0,0 +1,181 @@
+// Package preferences file: internal/service/preferences/preferences_service.go
+package preferences
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultMaxKeys       = 64
+	defaultMaxValueBytes = 16 * 1024
+	defaultMaxTotalBytes = 64 * 1024
+)
+
+var (
+	// ErrInvalidPreference 表示偏好设置的键或值不合法
+	ErrInvalidPreference = errors.New("无效的偏好设置")
+	// ErrQuotaExceeded 表示写入后会超出单个用户的偏好设置容量
+	ErrQuotaExceeded = errors.New("偏好设置超出容量限制")
+	// ErrNotFound 表示偏好设置不存在
+	ErrNotFound = errors.New("偏好设置不存在")
+)
+
+// keyPattern 限制键只能由字母、数字与 "._-" 组成，便于前端按 "页面.项目" 约定命名
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Options 定义偏好设置的容量限制
+type Options struct {
+	// MaxKeys 是每个用户的键数上限，默认为 64
+	MaxKeys int `mapstructure:"max_keys"`
+	// MaxValueBytes 是单个值序列化后的字节数上限，默认为 16 KiB
+	MaxValueBytes int `mapstructure:"max_value_bytes"`
+	// MaxTotalBytes 是每个用户全部值的字节数上限，默认为 64 KiB
+	MaxTotalBytes int `mapstructure:"max_total_bytes"`
+}
+
+// Service 以键值形式为每个用户保存界面偏好 (默认分页大小、置顶的业务组、列顺序等)，
+// 值为任意 JSON，由前端自行约定其结构，网关只负责按用户隔离与限制容量。
+type Service struct {
+	db   *sql.DB
+	opts Options
+}
+
+// NewService 创建一个新的偏好设置服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("preferences.Service 需要一个有效的数据库连接")
+	}
+	if opts.MaxKeys < 0 || opts.MaxValueBytes < 0 || opts.MaxTotalBytes < 0 {
+		return nil, errors.New("偏好设置的容量限制不能为负数")
+	}
+	if opts.MaxKeys == 0 {
+		opts.MaxKeys = defaultMaxKeys
+	}
+	if opts.MaxValueBytes == 0 {
+		opts.MaxValueBytes = defaultMaxValueBytes
+	}
+	if opts.MaxTotalBytes == 0 {
+		opts.MaxTotalBytes = defaultMaxTotalBytes
+	}
+	return &Service{db: db, opts: opts}, nil
+}
+
+// List 返回用户的全部偏好设置
+func (s *Service) List(ctx context.Context, userID int64) (map[string]json.RawMessage, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT pref_key, value_json FROM user_preferences WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询偏好设置失败: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("扫描偏好设置失败: %w", err)
+		}
+		out[key] = json.RawMessage(value)
+	}
+	return out, rows.Err()
+}
+
+// Get 返回用户的一项偏好设置，不存在时返回 ErrNotFound
+func (s *Service) Get(ctx context.Context, userID int64, key string) (json.RawMessage, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value_json FROM user_preferences WHERE user_id = ? AND pref_key = ?`, userID, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询偏好设置失败: %w", err)
+	}
+	return json.RawMessage(value), nil
+}
+
+// Set 写入一项偏好设置
+func (s *Service) Set(ctx context.Context, userID int64, key string, value json.RawMessage) error {
+	return s.Merge(ctx, userID, map[string]json.RawMessage{key: value})
+}
+
+// Merge 在一个事务中合并多项偏好设置，值为 JSON null 的键被删除，其余键保持不变。
+// 合并后的键数与总字节数超出限制时整体拒绝。
+func (s *Service) Merge(ctx context.Context, userID int64, values map[string]json.RawMessage) error {
+	compacted := make(map[string]string, len(values))
+	for key, value := range values {
+		if !keyPattern.MatchString(key) {
+			return fmt.Errorf("%w: 键 '%s' 只能包含字母、数字与 ._-，且不超过 64 个字符", ErrInvalidPreference, key)
+		}
+		if len(value) == 0 {
+			return fmt.Errorf("%w: 键 '%s' 缺少值", ErrInvalidPreference, key)
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return fmt.Errorf("%w: 键 '%s' 的值不是合法的 JSON", ErrInvalidPreference, key)
+		}
+		if buf.Len() > s.opts.MaxValueBytes {
+			return fmt.Errorf("%w: 键 '%s' 的值为 %d 字节，上限为 %d 字节", ErrQuotaExceeded, key, buf.Len(), s.opts.MaxValueBytes)
+		}
+		compacted[key] = buf.String()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启偏好设置事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	for key, value := range compacted {
+		if value == "null" {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM user_preferences WHERE user_id = ? AND pref_key = ?`, userID, key); err != nil {
+				return fmt.Errorf("删除偏好设置失败: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_preferences (user_id, pref_key, value_json, updated_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT(user_id, pref_key) DO UPDATE SET value_json = excluded.value_json, updated_at = excluded.updated_at`,
+			userID, key, value, now); err != nil {
+			return fmt.Errorf("写入偏好设置失败: %w", err)
+		}
+	}
+
+	var keys, total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(LENGTH(CAST(value_json AS BLOB))), 0) FROM user_preferences WHERE user_id = ?`,
+		userID).Scan(&keys, &total); err != nil {
+		return fmt.Errorf("统计偏好设置失败: %w", err)
+	}
+	if keys > s.opts.MaxKeys {
+		return fmt.Errorf("%w: 最多保存 %d 项", ErrQuotaExceeded, s.opts.MaxKeys)
+	}
+	if total > s.opts.MaxTotalBytes {
+		return fmt.Errorf("%w: 全部值共 %d 字节，上限为 %d 字节", ErrQuotaExceeded, total, s.opts.MaxTotalBytes)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交偏好设置失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除用户的一项偏好设置，不存在时返回 ErrNotFound
+func (s *Service) Delete(ctx context.Context, userID int64, key string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_preferences WHERE user_id = ? AND pref_key = ?`, userID, key)
+	if err != nil {
+		return fmt.Errorf("删除偏好设置失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, key)
+	}
+	return nil
+}
+
+// MaxRequestBytes 是写入请求体的字节数上限，留出键名与 JSON 结构的余量
+func (s *Service) MaxRequestBytes() int64 {
+	return int64(s.opts.MaxTotalBytes) + int64(s.opts.MaxKeys)*128
+}
@@ -0,0 +1,93 @@
+// file: internal/service/preferences/preferences_service_test.go
+package preferences
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, *sql.DB) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'user'), (2, 'bob', 'x', 'user')`)
+	require.NoError(t, err)
+	svc, err := NewService(db, opts)
+	require.NoError(t, err)
+	return svc, db
+}
+
+func TestPreferencesPerUser(t *testing.T) {
+	ctx := context.Background()
+	svc, db := newTestService(t, Options{})
+
+	require.NoError(t, svc.Set(ctx, 1, "search.page_size", json.RawMessage(`50`)))
+	require.NoError(t, svc.Merge(ctx, 1, map[string]json.RawMessage{
+		"pinned_bizs":         json.RawMessage(`[ "library", "archive" ]`),
+		"columns.books.order": json.RawMessage(`["title","year"]`),
+	}))
+
+	all, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.JSONEq(t, `["library","archive"]`, string(all["pinned_bizs"]))
+	assert.Equal(t, `["library","archive"]`, string(all["pinned_bizs"]), "值以紧凑形式保存")
+
+	other, err := svc.List(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, other, "不同用户的偏好设置相互隔离")
+	_, err = svc.Get(ctx, 2, "search.page_size")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// 值为 null 的键被删除，其余键保持不变
+	require.NoError(t, svc.Merge(ctx, 1, map[string]json.RawMessage{"pinned_bizs": json.RawMessage(`null`)}))
+	value, err := svc.Get(ctx, 1, "search.page_size")
+	require.NoError(t, err)
+	assert.Equal(t, `50`, string(value))
+	_, err = svc.Get(ctx, 1, "pinned_bizs")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, svc.Delete(ctx, 1, "search.page_size"))
+	assert.ErrorIs(t, svc.Delete(ctx, 1, "search.page_size"), ErrNotFound)
+
+	// 删除用户时一并删除其偏好设置
+	_, err = db.Exec(`DELETE FROM _user WHERE id = 1`)
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM user_preferences`).Scan(&n))
+	assert.Zero(t, n)
+}
+
+func TestPreferencesValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, Options{MaxKeys: 2, MaxValueBytes: 16, MaxTotalBytes: 20})
+
+	assert.ErrorIs(t, svc.Set(ctx, 1, "bad key", json.RawMessage(`1`)), ErrInvalidPreference)
+	assert.ErrorIs(t, svc.Set(ctx, 1, ".hidden", json.RawMessage(`1`)), ErrInvalidPreference)
+	assert.ErrorIs(t, svc.Set(ctx, 1, "ok", json.RawMessage(`{broken`)), ErrInvalidPreference)
+	assert.ErrorIs(t, svc.Set(ctx, 1, "ok", nil), ErrInvalidPreference)
+	assert.ErrorIs(t, svc.Set(ctx, 1, "big", json.RawMessage(`"0123456789abcdefg"`)), ErrQuotaExceeded)
+
+	require.NoError(t, svc.Set(ctx, 1, "a", json.RawMessage(`"0123456789"`)))
+	assert.ErrorIs(t, svc.Set(ctx, 1, "b", json.RawMessage(`"0123456789"`)), ErrQuotaExceeded, "总字节数超限")
+	require.NoError(t, svc.Set(ctx, 1, "b", json.RawMessage(`1`)))
+	assert.ErrorIs(t, svc.Set(ctx, 1, "c", json.RawMessage(`1`)), ErrQuotaExceeded, "键数超限")
+
+	// 超限的合并整体回滚
+	assert.ErrorIs(t, svc.Merge(ctx, 1, map[string]json.RawMessage{"b": json.RawMessage(`2`), "c": json.RawMessage(`3`)}), ErrQuotaExceeded)
+	value, err := svc.Get(ctx, 1, "b")
+	require.NoError(t, err)
+	assert.Equal(t, `1`, string(value))
+	// 同时删除一个键时可以写入新键
+	require.NoError(t, svc.Merge(ctx, 1, map[string]json.RawMessage{"b": json.RawMessage(`null`), "c": json.RawMessage(`3`)}))
+
+	_, err = NewService(nil, Options{})
+	assert.Error(t, err)
+	_, err = NewService(svc.db, Options{MaxKeys: -1})
+	assert.Error(t, err)
+}
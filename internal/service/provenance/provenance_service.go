@@ -0,0 +1,178 @@
+// Package provenance file: internal/service/provenance/provenance_service.go
+package provenance
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMinQueryItems = 100
+
+// AnonymousRequester 是匿名访问时 RequestedBy 的取值
+const AnonymousRequester = "anonymous"
+
+// ErrNotFound 表示摘要不对应任何现有用户
+var ErrNotFound = errors.New("未找到对应的用户")
+
+// Options 定义来源信息的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Issuer 是发布方名称，如机构名或网关地址，为空时不输出
+	Issuer string `mapstructure:"issuer"`
+	// License 是默认的授权声明，BizLicenses 可按业务组覆盖
+	License     string            `mapstructure:"license"`
+	BizLicenses map[string]string `mapstructure:"biz_licenses"`
+	// MinQueryItems 是检索结果附带来源信息的最小记录数，默认为 100；导出文件总是附带
+	MinQueryItems int `mapstructure:"min_query_items"`
+	// UserHashSecret 是计算用户摘要的密钥，启用时必填。更换密钥后旧文件中的摘要将无法反查
+	UserHashSecret string `mapstructure:"user_hash_secret"`
+}
+
+// Service 为导出文件与大批量检索结果生成来源信息：来源业务组、导出时间、请求用户的摘要与授权声明。
+// 用户编号以 HMAC-SHA256 摘要的形式出现，外部无法据此得知用户身份，管理员可通过 ResolveUser 反查
+type Service struct {
+	db            *sql.DB
+	issuer        string
+	license       string
+	bizLicenses   map[string]string
+	minQueryItems int
+	secret        []byte
+	now           func() time.Time
+}
+
+// NewService 创建一个新的来源信息服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("provenance.Service 需要一个有效的数据库连接")
+	}
+	if strings.TrimSpace(opts.UserHashSecret) == "" {
+		return nil, errors.New("user_hash_secret 不能为空")
+	}
+	if opts.MinQueryItems < 0 {
+		return nil, fmt.Errorf("min_query_items 不能为负数: %d", opts.MinQueryItems)
+	}
+	if opts.MinQueryItems == 0 {
+		opts.MinQueryItems = defaultMinQueryItems
+	}
+	bizLicenses := make(map[string]string, len(opts.BizLicenses))
+	for biz, license := range opts.BizLicenses {
+		bizLicenses[biz] = license
+	}
+	return &Service{
+		db:            db,
+		issuer:        opts.Issuer,
+		license:       opts.License,
+		bizLicenses:   bizLicenses,
+		minQueryItems: opts.MinQueryItems,
+		secret:        []byte(opts.UserHashSecret),
+		now:           time.Now,
+	}, nil
+}
+
+// For 生成一次导出或检索的来源信息。多个业务组的授权声明不同时以 "; " 连接
+func (s *Service) For(bizNames []string, userID int64) domain.Provenance {
+	sources := make([]string, 0, len(bizNames))
+	seen := make(map[string]bool, len(bizNames))
+	for _, biz := range bizNames {
+		if biz != "" && !seen[biz] {
+			seen[biz] = true
+			sources = append(sources, biz)
+		}
+	}
+	sort.Strings(sources)
+
+	var licenses []string
+	seenLicense := make(map[string]bool)
+	for _, biz := range sources {
+		license, ok := s.bizLicenses[biz]
+		if !ok {
+			license = s.license
+		}
+		if license != "" && !seenLicense[license] {
+			seenLicense[license] = true
+			licenses = append(licenses, license)
+		}
+	}
+	if len(sources) == 0 && s.license != "" {
+		licenses = append(licenses, s.license)
+	}
+
+	return domain.Provenance{
+		Issuer:      s.issuer,
+		Sources:     sources,
+		ExportedAt:  s.now().UTC().Truncate(time.Second),
+		RequestedBy: s.UserHash(userID),
+		License:     strings.Join(licenses, "; "),
+	}
+}
+
+// Large 判断检索结果的记录数是否达到附带来源信息的下限
+func (s *Service) Large(items int) bool {
+	return items >= s.minQueryItems
+}
+
+// UserHash 返回用户编号的带密钥摘要 (十六进制的前 16 位)，匿名用户返回 AnonymousRequester
+func (s *Service) UserHash(userID int64) string {
+	if userID <= 0 {
+		return AnonymousRequester
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strconv.FormatInt(userID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// ResolveUser 在现有用户中查找摘要对应的用户，返回用户编号与用户名
+func (s *Service) ResolveUser(ctx context.Context, hash string) (int64, string, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	rows, err := s.db.QueryContext(ctx, `SELECT id, username FROM _user`)
+	if err != nil {
+		return 0, "", fmt.Errorf("查询用户失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return 0, "", err
+		}
+		if hmac.Equal([]byte(s.UserHash(id)), []byte(hash)) {
+			return id, username, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+	return 0, "", fmt.Errorf("%w: %s", ErrNotFound, hash)
+}
+
+// CommentLines 把来源信息格式化为 CSV 文件开头的注释行 (以 "# " 开头)
+func CommentLines(p domain.Provenance) []string {
+	lines := make([]string, 0, 5)
+	if p.Issuer != "" {
+		lines = append(lines, "# issuer: "+oneLine(p.Issuer))
+	}
+	lines = append(lines,
+		"# sources: "+strings.Join(p.Sources, ","),
+		"# exported_at: "+p.ExportedAt.Format(time.RFC3339),
+		"# requested_by: "+p.RequestedBy,
+	)
+	if p.License != "" {
+		lines = append(lines, "# license: "+oneLine(p.License))
+	}
+	return lines
+}
+
+// oneLine 去掉换行，避免配置中的多行文本破坏注释格式
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
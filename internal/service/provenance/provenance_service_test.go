@@ -0,0 +1,95 @@
+// file: internal/service/provenance/provenance_service_test.go
+package provenance
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, *sql.DB) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'admin', 'x', 'admin'), (42, 'reader', 'x', 'user')`)
+	require.NoError(t, err)
+	svc, err := NewService(db, opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 10, 1, 8, 0, 0, 500, time.UTC) }
+	return svc, db
+}
+
+func TestNewService_Validation(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewService(nil, Options{UserHashSecret: "s"})
+	assert.Error(t, err)
+	_, err = NewService(db, Options{})
+	assert.Error(t, err, "必须提供用户摘要密钥")
+	_, err = NewService(db, Options{UserHashSecret: "s", MinQueryItems: -1})
+	assert.Error(t, err)
+
+	svc, err := NewService(db, Options{UserHashSecret: "s"})
+	require.NoError(t, err)
+	assert.False(t, svc.Large(defaultMinQueryItems-1))
+	assert.True(t, svc.Large(defaultMinQueryItems))
+}
+
+func TestFor_LicensesAndUserHash(t *testing.T) {
+	svc, _ := newTestService(t, Options{
+		Issuer:         "某市档案馆",
+		License:        "CC BY 4.0",
+		BizLicenses:    map[string]string{"museum": "仅限研究使用"},
+		UserHashSecret: "secret",
+	})
+
+	p := svc.For([]string{"museum", "archive", "museum", ""}, 42)
+	assert.Equal(t, "某市档案馆", p.Issuer)
+	assert.Equal(t, []string{"archive", "museum"}, p.Sources)
+	assert.Equal(t, "CC BY 4.0; 仅限研究使用", p.License)
+	assert.Equal(t, time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), p.ExportedAt)
+	assert.Len(t, p.RequestedBy, 16)
+	assert.Equal(t, svc.UserHash(42), p.RequestedBy)
+	assert.NotEqual(t, svc.UserHash(1), p.RequestedBy)
+
+	assert.Equal(t, AnonymousRequester, svc.For([]string{"archive"}, 0).RequestedBy)
+
+	other, _ := newTestService(t, Options{UserHashSecret: "another"})
+	assert.NotEqual(t, svc.UserHash(42), other.UserHash(42), "摘要依赖密钥")
+}
+
+func TestResolveUser(t *testing.T) {
+	svc, _ := newTestService(t, Options{UserHashSecret: "secret"})
+	ctx := context.Background()
+
+	id, username, err := svc.ResolveUser(ctx, svc.UserHash(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.Equal(t, "reader", username)
+
+	_, _, err = svc.ResolveUser(ctx, svc.UserHash(7))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCommentLines(t *testing.T) {
+	p := domain.Provenance{
+		Issuer:      "档案馆\n阅览部",
+		Sources:     []string{"archive", "museum"},
+		ExportedAt:  time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		RequestedBy: "abc",
+	}
+	assert.Equal(t, []string{
+		"# issuer: 档案馆 阅览部",
+		"# sources: archive,museum",
+		"# exported_at: 2026-10-01T08:00:00Z",
+		"# requested_by: abc",
+	}, CommentLines(p))
+}
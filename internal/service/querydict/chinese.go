@@ -0,0 +1,79 @@
+// Package querydict file: internal/service/querydict/chinese.go
+package querydict
+
+import "strings"
+
+// chinesePairs 是内置的繁简字对照 (每项为 "繁简")，覆盖常见姓氏、地名与常用字。
+// 一个简体字对应多个繁体字时，排在前面的作为转换为繁体时的首选；
+// 展开时原值始终保留，因此首选不准确只会少命中，不会漏掉原样匹配的记录。
+const chinesePairs = `
+萬万 與与 專专 業业 東东 絲丝 兩两 嚴严 個个 豐丰 臨临 為为 麗丽 舉举 義义 烏乌 樂乐 喬乔 習习 鄉乡
+書书 買买 亂乱 爭争 於于 虧亏 雲云 亞亚 產产 親亲 億亿 僅仅 從从 倉仓 儀仪 們们 價价 眾众 優优 會会
+偉伟 傳传 傷伤 倫伦 體体 餘余 俠侠 僑侨 係系 債债 傾倾 償偿 儲储 兒儿 黨党 蘭兰 關关 興兴 養养 內内
+岡冈 冊册 寫写 軍军 農农 馮冯 沖冲 決决 況况 凍冻 淨净 涼凉 減减 幾几 鳳凤 憑凭 凱凯 擊击 劃划 劉刘
+則则 剛刚 創创 別别 劍剑 劇剧 勸劝 辦办 務务 動动 勵励 勞劳 勢势 勳勋 匯汇 區区 醫医 華华 協协 單单
+賣卖 盧卢 衛卫 廠厂 廳厅 歷历 曆历 厲厉 壓压 縣县 參参 雙双 發发 髮发 變变 葉叶 號号 後后 嚇吓 呂吕
+嗎吗 聽听 啟启 吳吴 員员 響响 問问 園园 圍围 圖图 國国 團团 場场 壞坏 塊块 堅坚 壇坛 墳坟 墜坠 壘垒
+執执 報报 塵尘 牆墙 壯壮 聲声 殼壳 處处 備备 夠够 頭头 誇夸 奪夺 奮奋 獎奖 婦妇 媽妈 嬌娇 孫孙 學学
+寧宁 寶宝 實实 寵宠 審审 憲宪 宮宫 對对 尋寻 導导 壽寿 將将 爾尔 嘗尝 屆届 屍尸 層层 屬属 歲岁 豈岂
+嶺岭 島岛 崗岗 嶽岳 峽峡 幣币 帥帅 師师 帳帐 帶带 幫帮 庫库 廣广 廟庙 廢废 開开 異异 棄弃 張张 彌弥
+彎弯 彈弹 強强 歸归 當当 錄录 徹彻 徑径 憶忆 懷怀 態态 憐怜 總总 戀恋 惡恶 懸悬 愛爱 慶庆 憂忧 戰战
+戲戏 戶户 撲扑 擴扩 掃扫 揚扬 擾扰 撫抚 搶抢 護护 擔担 擬拟 擁拥 擇择 掛挂 揮挥 損损 撿捡 換换 據据
+擺摆 攜携 搖摇 數数 敵敌 斂敛 齊齐 斬斩 斷断 無无 舊旧 時时 曠旷 晝昼 顯显 晉晋 曬晒 曉晓 暫暂 術术
+機机 殺杀 雜杂 權权 條条 來来 楊杨 極极 構构 槍枪 樞枢 標标 棧栈 欄栏 樹树 樣样 橋桥 檢检 樓楼 歡欢
+歐欧 殘残 氣气 漢汉 湯汤 溝沟 沒没 滬沪 淚泪 潑泼 澤泽 潔洁 灑洒 濁浊 測测 濟济 瀏浏 渾浑 濃浓 濤涛
+漁渔 滲渗 溫温 灣湾 濕湿 滿满 濾滤 濫滥 灘滩 瀾澜 潛潜 災灾 燈灯 靈灵 爐炉 點点 煉炼 爛烂 煙烟 熱热
+燒烧 營营 燦灿 爺爷 牽牵 犧牺 狀状 猶犹 獨独 獄狱 獅狮 獵猎 貓猫 獻献 現现 環环 瑪玛 璽玺 畫画 暢畅
+療疗 瘋疯 盞盏 監监 蓋盖 盜盗 盤盘 睜睁 礦矿 碼码 磚砖 礎础 確确 禮礼 禍祸 禪禅 離离 種种 積积 稱称
+穩稳 窮穷 竊窃 競竞 筆笔 築筑 簡简 節节 範范 類类 糧粮 糾纠 紀纪 約约 紅红 紋纹 納纳 紐纽 純纯 紙纸
+級级 紛纷 細细 終终 組组 經经 結结 給给 絡络 統统 絕绝 網网 綠绿 維维 綿绵 緊紧 線线 練练 緣缘 編编
+緩缓 縮缩 績绩 織织 繞绕 繼继 續续 罰罚 羅罗 罷罢 聖圣 聞闻 聯联 聰聪 肅肃 腸肠 膚肤 腎肾 腫肿 脹胀
+腦脑 臟脏 膽胆 艦舰 艱艰 藝艺 蘆芦 蘇苏 蘋苹 莖茎 薦荐 莊庄 蔣蒋 薩萨 蘿萝 蕭萧 藥药 藍蓝 蟲虫 蝦虾
+螞蚂 蠶蚕 衝冲 補补 裝装 裡里 裏里 製制 復复 複复 見见 規规 覺觉 視视 觀观 計计 訂订 認认 討讨 讓让
+訓训 議议 訊讯 記记 講讲 許许 論论 設设 訪访 證证 評评 識识 診诊 詞词 譯译 試试 詩诗 誠诚 話话 誕诞
+詢询 該该 詳详 語语 誤误 說说 請请 諸诸 讀读 課课 誰谁 調调 談谈 謀谋 謝谢 謠谣 謙谦 謹谨 譜谱 譚谭
+貝贝 負负 財财 貢贡 貧贫 貨货 販贩 貪贪 責责 貴贵 貸贷 費费 貿贸 資资 賊贼 賓宾 賜赐 賞赏 賢贤 賬账
+質质 購购 賽赛 贊赞 贈赠 賀贺 賈贾 賴赖 贛赣 趙赵 趕赶 躍跃 車车 軌轨 軒轩 軟软 轉转 輪轮 較较 載载
+輕轻 輔辅 輩辈 輸输 轄辖 辭辞 邊边 遼辽 達达 遷迁 過过 邁迈 運运 還还 這这 進进 遠远 違违 連连 遲迟
+適适 選选 遺遗 遙遥 鄧邓 鄭郑 鄰邻 郵邮 鄒邹 鄔邬 鄖郧 醜丑 釋释 釣钓 針针 銀银 鐵铁 鋼钢 錢钱 錯错
+鍾钟 鐘钟 鏡镜 錫锡 鎮镇 長长 門门 閃闪 閉闭 閒闲 間间 閱阅 闖闯 閻阎 閔闵 閩闽 闕阙 陽阳 陰阴 陣阵
+際际 陸陆 隊队 階阶 隨随 險险 隱隐 陝陕 難难 雞鸡 雖虽 電电 霧雾 靜静 韓韩 韋韦 頁页 頂顶 項项 順顺
+須须 預预 領领 頻频 題题 額额 顏颜 願愿 顧顾 風风 飛飞 飯饭 飲饮 館馆 饒饶 馬马 馳驰 駐驻 駱骆 驗验
+驚惊 騰腾 驅驱 駕驾 魯鲁 魚鱼 鮮鲜 鳥鸟 鳴鸣 鴻鸿 鶴鹤 鵬鹏 麥麦 黃黄 齒齿 龍龙 龐庞 龔龚 龜龟 陳陈
+聶聂 鄺邝 紹绍 粵粤 瓊琼 廈厦 臺台 颱台 檯台
+`
+
+var (
+	traditionalToSimplified = make(map[rune]rune)
+	simplifiedToTraditional = make(map[rune]rune)
+)
+
+func init() {
+	for _, pair := range strings.Fields(chinesePairs) {
+		runes := []rune(pair)
+		trad, simp := runes[0], runes[1]
+		traditionalToSimplified[trad] = simp
+		if _, exists := simplifiedToTraditional[simp]; !exists {
+			simplifiedToTraditional[simp] = trad
+		}
+	}
+}
+
+// toSimplified 把字符串中的繁体字逐字转换为简体字
+func toSimplified(s string) string {
+	return mapRunes(s, traditionalToSimplified)
+}
+
+// toTraditional 把字符串中的简体字逐字转换为首选的繁体字
+func toTraditional(s string) string {
+	return mapRunes(s, simplifiedToTraditional)
+}
+
+func mapRunes(s string, table map[rune]rune) string {
+	return strings.Map(func(r rune) rune {
+		if mapped, ok := table[r]; ok {
+			return mapped
+		}
+		return r
+	}, s)
+}
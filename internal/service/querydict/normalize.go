@@ -0,0 +1,82 @@
+// Package querydict file: internal/service/querydict/normalize.go
+package querydict
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latinFolding 把带附加符号的拉丁字母 (含汉语拼音声调) 映射为基本字母，
+// 用于匹配 "Lǔ Xùn" 与 "Lu Xun"、"Nguyễn" 与 "Nguyen" 等罗马化拼写
+var latinFolding = map[rune]rune{}
+
+func init() {
+	groups := map[rune]string{
+		'a': "àáâãäåāăąǎǻ", 'A': "ÀÁÂÃÄÅĀĂĄǍǺ",
+		'c': "çćĉċč", 'C': "ÇĆĈĊČ",
+		'd': "ďđ", 'D': "ĎĐ",
+		'e': "èéêëēĕėęěẽ", 'E': "ÈÉÊËĒĔĖĘĚẼ",
+		'g': "ĝğġģ", 'G': "ĜĞĠĢ",
+		'h': "ĥħ", 'H': "ĤĦ",
+		'i': "ìíîïĩīĭįǐı", 'I': "ÌÍÎÏĨĪĬĮǏİ",
+		'j': "ĵ", 'J': "Ĵ",
+		'k': "ķ", 'K': "Ķ",
+		'l': "ĺļľŀł", 'L': "ĹĻĽĿŁ",
+		'n': "ñńņňǹ", 'N': "ÑŃŅŇǸ",
+		'o': "òóôõöøōŏőǒ", 'O': "ÒÓÔÕÖØŌŎŐǑ",
+		'r': "ŕŗř", 'R': "ŔŖŘ",
+		's': "śŝşš", 'S': "ŚŜŞŠ",
+		't': "ţťŧ", 'T': "ŢŤŦ",
+		'u': "ùúûüũūŭůűųǔǖǘǚǜ", 'U': "ÙÚÛÜŨŪŬŮŰŲǓǕǗǙǛ",
+		'w': "ŵ", 'W': "Ŵ",
+		'y': "ýÿŷỳ", 'Y': "ÝŸŶỲ",
+		'z': "źżž", 'Z': "ŹŻŽ",
+	}
+	for base, variants := range groups {
+		for _, r := range variants {
+			latinFolding[r] = base
+		}
+	}
+	// 越南语等使用的叠加声调字母
+	for _, r := range "ạảấầẩẫậắằẳẵặ" {
+		latinFolding[r] = 'a'
+	}
+	for _, r := range "ẹẻếềểễệ" {
+		latinFolding[r] = 'e'
+	}
+	for _, r := range "ọỏốồổỗộớờởỡợơ" {
+		latinFolding[r] = 'o'
+	}
+	for _, r := range "ụủứừửữựư" {
+		latinFolding[r] = 'u'
+	}
+	for _, r := range "ịỉ" {
+		latinFolding[r] = 'i'
+	}
+}
+
+// foldDiacritics 去除拉丁字母上的附加符号
+func foldDiacritics(s string) string {
+	return mapRunes(s, latinFolding)
+}
+
+// titleCase 把每个单词的首字母大写、其余小写
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// collapseSpaces 去除首尾空白并把连续空白合并为一个空格
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// matchKey 是匹配同义词时使用的宽松键：忽略大小写、附加符号与繁简差异
+func matchKey(s string) string {
+	return strings.ToLower(foldDiacritics(toSimplified(collapseSpaces(s))))
+}
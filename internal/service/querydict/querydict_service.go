@@ -0,0 +1,353 @@
+// Package querydict file: internal/service/querydict/querydict_service.go
+package querydict
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxVariants 是单个过滤值最多展开出的写法数 (含原值)，防止大同义词组拖慢查询
+	maxVariants = 32
+	// maxSynonymGroups 是单个业务组词典中同义词组的上限
+	maxSynonymGroups = 5000
+)
+
+var (
+	// ErrDictionaryNotFound 表示指定业务组尚未配置检索词典
+	ErrDictionaryNotFound = errors.New("检索词典不存在")
+	// ErrInvalidDictionary 表示检索词典内容不合法
+	ErrInvalidDictionary = errors.New("无效的检索词典")
+)
+
+// Service 负责持久化各业务组的检索词典，并在查询分发前按词典展开过滤值。
+// 词典在内存中缓存，保存或删除后立即生效。
+type Service struct {
+	db *sql.DB
+
+	mu    sync.RWMutex
+	dicts map[string]*compiledDictionary
+}
+
+// compiledDictionary 是预先建立了匹配索引的词典
+type compiledDictionary struct {
+	def domain.QueryDictionary
+	// groups 以同义词的宽松匹配键 (见 matchKey) 索引所属的同义词组
+	groups          map[string][]int
+	normalizeFields map[string]bool
+}
+
+// NewService 创建一个新的检索词典服务实例
+func NewService(db *sql.DB) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("querydict.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, dicts: make(map[string]*compiledDictionary)}, nil
+}
+
+// LoadAll 在启动时加载所有已保存的词典
+func (s *Service) LoadAll(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, dictionary_json, updated_at FROM biz_query_dictionaries`)
+	if err != nil {
+		return fmt.Errorf("查询检索词典失败: %w", err)
+	}
+	defer rows.Close()
+
+	dicts := make(map[string]*compiledDictionary)
+	for rows.Next() {
+		var def domain.QueryDictionary
+		var dictJSON string
+		if err := rows.Scan(&def.BizName, &dictJSON, &def.UpdatedAt); err != nil {
+			return fmt.Errorf("扫描检索词典失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(dictJSON), &def); err != nil {
+			return fmt.Errorf("解析业务组 '%s' 的检索词典失败: %w", def.BizName, err)
+		}
+		dicts[def.BizName] = compile(def)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.dicts = dicts
+	s.mu.Unlock()
+	return nil
+}
+
+// Get 返回一个业务组的检索词典
+func (s *Service) Get(bizName string) (*domain.QueryDictionary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dict, ok := s.dicts[bizName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDictionaryNotFound, bizName)
+	}
+	def := dict.def
+	return &def, nil
+}
+
+// Save 创建或替换一个业务组的检索词典，并立即生效
+func (s *Service) Save(ctx context.Context, def domain.QueryDictionary) (*domain.QueryDictionary, error) {
+	if def.BizName == "" {
+		return nil, fmt.Errorf("%w: biz_name 不能为空", ErrInvalidDictionary)
+	}
+	if err := normalizeDefinition(&def); err != nil {
+		return nil, err
+	}
+	def.UpdatedAt = time.Now().UTC()
+
+	// biz_name 与 updated_at 已有独立的列，不重复写入 JSON
+	dictJSON, err := json.Marshal(struct {
+		Synonyms      []domain.SynonymGroup     `json:"synonyms"`
+		Normalization domain.NormalizationRules `json:"normalization"`
+	}{def.Synonyms, def.Normalization})
+	if err != nil {
+		return nil, fmt.Errorf("序列化检索词典失败: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO biz_query_dictionaries (biz_name, dictionary_json, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			dictionary_json = excluded.dictionary_json,
+			updated_at = excluded.updated_at`,
+		def.BizName, string(dictJSON), def.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("保存业务组 '%s' 的检索词典失败: %w", def.BizName, err)
+	}
+
+	s.mu.Lock()
+	s.dicts[def.BizName] = compile(def)
+	s.mu.Unlock()
+	return &def, nil
+}
+
+// Delete 删除一个业务组的检索词典，此后该业务组的查询不再展开
+func (s *Service) Delete(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_query_dictionaries WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除业务组 '%s' 的检索词典失败: %w", bizName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrDictionaryNotFound, bizName)
+	}
+	s.mu.Lock()
+	delete(s.dicts, bizName)
+	s.mu.Unlock()
+	return nil
+}
+
+// normalizeDefinition 去除空白与重复的词条并校验词典
+func normalizeDefinition(def *domain.QueryDictionary) error {
+	if len(def.Synonyms) > maxSynonymGroups {
+		return fmt.Errorf("%w: 同义词组最多 %d 个", ErrInvalidDictionary, maxSynonymGroups)
+	}
+	groups := make([]domain.SynonymGroup, 0, len(def.Synonyms))
+	for i, group := range def.Synonyms {
+		terms := dedupe(group.Terms, collapseSpaces)
+		if len(terms) < 2 {
+			return fmt.Errorf("%w: 第 %d 个同义词组至少需要两个不同的词", ErrInvalidDictionary, i+1)
+		}
+		if len(terms) > maxVariants {
+			return fmt.Errorf("%w: 第 %d 个同义词组最多 %d 个词", ErrInvalidDictionary, i+1, maxVariants)
+		}
+		groups = append(groups, domain.SynonymGroup{Fields: dedupe(group.Fields, strings.TrimSpace), Terms: terms})
+	}
+	def.Synonyms = groups
+	def.Normalization.Fields = dedupe(def.Normalization.Fields, strings.TrimSpace)
+	return nil
+}
+
+// dedupe 对每个元素应用 clean 后去除空值与重复值，保持原有顺序
+func dedupe(values []string, clean func(string) string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = clean(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+func compile(def domain.QueryDictionary) *compiledDictionary {
+	dict := &compiledDictionary{def: def, groups: make(map[string][]int)}
+	for i, group := range def.Synonyms {
+		for _, term := range group.Terms {
+			key := matchKey(term)
+			dict.groups[key] = append(dict.groups[key], i)
+		}
+	}
+	if len(def.Normalization.Fields) > 0 {
+		dict.normalizeFields = make(map[string]bool, len(def.Normalization.Fields))
+		for _, f := range def.Normalization.Fields {
+			dict.normalizeFields[f] = true
+		}
+	}
+	return dict
+}
+
+// Expand 按业务组的词典展开查询中的过滤值，返回新的查询对象，不修改传入的查询。
+// 只处理未指定 op 的等值或模糊匹配条件；展开出的写法写入过滤条件的 port.FilterValuesKey。
+// 业务组没有词典或没有任何条件被展开时，原样返回传入的查询。
+func (s *Service) Expand(bizName string, query map[string]interface{}) map[string]interface{} {
+	s.mu.RLock()
+	dict := s.dicts[bizName]
+	s.mu.RUnlock()
+	if dict == nil {
+		return query
+	}
+	filters, ok := query["filters"].([]interface{})
+	if !ok || len(filters) == 0 {
+		return query
+	}
+
+	var expanded []interface{}
+	for i, f := range filters {
+		filterMap, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if op, _ := filterMap["op"].(string); op != "" {
+			continue
+		}
+		// 拼音检索的值是拼音输入而非原文，不适用词典
+		if pinyin, _ := filterMap["pinyin"].(bool); pinyin {
+			continue
+		}
+		field, _ := filterMap["field"].(string)
+		value, _ := filterMap["value"].(string)
+		if field == "" || strings.TrimSpace(value) == "" {
+			continue
+		}
+		variants := dict.variants(field, value)
+		if len(variants) == 0 {
+			continue
+		}
+
+		if expanded == nil {
+			expanded = make([]interface{}, len(filters))
+			copy(expanded, filters)
+		}
+		copied := make(map[string]interface{}, len(filterMap)+1)
+		for k, v := range filterMap {
+			copied[k] = v
+		}
+		values, _ := filterMap[port.FilterValuesKey].([]interface{})
+		values = append(append([]interface{}{}, values...), variants...)
+		copied[port.FilterValuesKey] = values
+		expanded[i] = copied
+	}
+	if expanded == nil {
+		return query
+	}
+
+	result := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		result[k] = v
+	}
+	result["filters"] = expanded
+	return result
+}
+
+// Preview 返回某字段上的一个值会被展开成的全部写法 (含原值)，供管理员核对词典效果
+func (s *Service) Preview(bizName, field, value string) []string {
+	out := []string{value}
+	s.mu.RLock()
+	dict := s.dicts[bizName]
+	s.mu.RUnlock()
+	if dict == nil {
+		return out
+	}
+	for _, v := range dict.variants(field, value) {
+		out = append(out, v.(string))
+	}
+	return out
+}
+
+// variants 计算一个过滤值的其它写法，不含原值，最多 maxVariants-1 个
+func (d *compiledDictionary) variants(field, value string) []interface{} {
+	forms := newOrderedSet(value)
+	for _, idx := range d.groups[matchKey(value)] {
+		group := d.def.Synonyms[idx]
+		if !fieldInScope(group.Fields, field) {
+			continue
+		}
+		for _, term := range group.Terms {
+			forms.add(term)
+		}
+	}
+
+	rules := d.def.Normalization
+	if d.normalizeFields == nil || d.normalizeFields[field] {
+		for _, form := range forms.snapshot() {
+			if rules.Transliteration {
+				forms.add(foldDiacritics(form))
+			}
+			if rules.ChineseVariants {
+				forms.add(toSimplified(form))
+				forms.add(toTraditional(form))
+			}
+		}
+		if rules.CaseFold {
+			for _, form := range forms.snapshot() {
+				forms.add(strings.ToLower(form))
+				forms.add(strings.ToUpper(form))
+				forms.add(titleCase(form))
+			}
+		}
+	}
+
+	items := forms.snapshot()[1:]
+	out := make([]interface{}, len(items))
+	for i, v := range items {
+		out[i] = v
+	}
+	return out
+}
+
+func fieldInScope(fields []string, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedSet 是保持插入顺序、有容量上限的字符串集合
+type orderedSet struct {
+	seen  map[string]bool
+	items []string
+}
+
+func newOrderedSet(first string) *orderedSet {
+	return &orderedSet{seen: map[string]bool{first: true}, items: []string{first}}
+}
+
+func (o *orderedSet) add(v string) {
+	if v == "" || o.seen[v] || len(o.items) >= maxVariants {
+		return
+	}
+	o.seen[v] = true
+	o.items = append(o.items, v)
+}
+
+func (o *orderedSet) snapshot() []string {
+	return append([]string(nil), o.items...)
+}
@@ -0,0 +1,112 @@
+// file: internal/service/querydict/querydict_service_test.go
+package querydict
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *sql.DB) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	s, err := NewService(db)
+	require.NoError(t, err)
+	return s, db
+}
+
+func TestExpandSynonymsAndNormalization(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestService(t)
+
+	_, err := s.Save(ctx, domain.QueryDictionary{
+		BizName: "letters",
+		Synonyms: []domain.SynonymGroup{
+			{Fields: []string{"sender"}, Terms: []string{"鲁迅", " 周树人 ", "Lu Xun", "鲁迅"}},
+		},
+		Normalization: domain.NormalizationRules{ChineseVariants: true, Transliteration: true, Fields: []string{"sender", "place"}},
+	})
+	require.NoError(t, err)
+
+	query := map[string]interface{}{
+		"table": "letters",
+		"filters": []interface{}{
+			map[string]interface{}{"field": "sender", "value": "魯迅", "logic": "AND"},
+			map[string]interface{}{"field": "place", "value": "北京", "fuzzy": true, "logic": "AND"},
+			map[string]interface{}{"field": "title", "value": "吶喊"},
+			map[string]interface{}{"field": "sender", "op": "is_null"},
+		},
+	}
+	expanded := s.Expand("letters", query)
+
+	filters := expanded["filters"].([]interface{})
+	sender := filters[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"鲁迅", "周树人", "Lu Xun", "周樹人"}, sender[port.FilterValuesKey],
+		"繁体写法应命中同义词组，并补充各词的繁简写法")
+	place := filters[1].(map[string]interface{})
+	assert.NotContains(t, place, port.FilterValuesKey, "没有其它写法的值不应展开")
+	assert.NotContains(t, filters[2].(map[string]interface{}), port.FilterValuesKey, "不在归一化范围内的字段不应展开")
+	assert.NotContains(t, filters[3].(map[string]interface{}), port.FilterValuesKey, "空值运算符不应展开")
+
+	original := query["filters"].([]interface{})[0].(map[string]interface{})
+	assert.NotContains(t, original, port.FilterValuesKey, "Expand 不应修改传入的查询")
+
+	// 词典持久化后可被重新加载
+	reloaded, err := NewService(db)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.LoadAll(ctx))
+	assert.Equal(t, sender[port.FilterValuesKey], reloaded.Expand("letters", query)["filters"].([]interface{})[0].(map[string]interface{})[port.FilterValuesKey])
+}
+
+func TestPreviewCaseFoldAndTransliteration(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	_, err := s.Save(ctx, domain.QueryDictionary{
+		BizName:       "letters",
+		Normalization: domain.NormalizationRules{CaseFold: true, Transliteration: true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Lǔ Xùn", "Lu Xun", "lǔ xùn", "LǓ XÙN", "lu xun", "LU XUN"}, s.Preview("letters", "sender", "Lǔ Xùn"))
+	assert.Equal(t, []string{"鲁迅"}, s.Preview("other", "sender", "鲁迅"), "没有词典的业务组只返回原值")
+}
+
+func TestSaveAndDeleteValidation(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	_, err := s.Save(ctx, domain.QueryDictionary{
+		BizName:  "letters",
+		Synonyms: []domain.SynonymGroup{{Terms: []string{"鲁迅", " 鲁迅 "}}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidDictionary, "去重后不足两个词的同义词组应被拒绝")
+
+	_, err = s.Get("letters")
+	assert.ErrorIs(t, err, ErrDictionaryNotFound)
+	assert.ErrorIs(t, s.Delete(ctx, "letters"), ErrDictionaryNotFound)
+
+	_, err = s.Save(ctx, domain.QueryDictionary{BizName: "letters", Normalization: domain.NormalizationRules{CaseFold: true}})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(ctx, "letters"))
+
+	query := map[string]interface{}{"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "Lu Xun"}}}
+	assert.Equal(t, query, s.Expand("letters", query), "删除词典后查询不再展开")
+}
+
+func TestChinesePairsWellFormed(t *testing.T) {
+	for _, pair := range strings.Fields(chinesePairs) {
+		runes := []rune(pair)
+		require.Len(t, runes, 2, "繁简对照 %q 必须恰好包含两个字", pair)
+		assert.NotEqual(t, runes[0], runes[1], "繁简对照 %q 的两个字不应相同", pair)
+	}
+	assert.Equal(t, "东国门书", toSimplified("東國門書"))
+	assert.Equal(t, "臺灣後來", toTraditional("台湾后来"))
+}
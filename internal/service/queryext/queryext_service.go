@@ -0,0 +1,116 @@
+// Package queryext file: internal/service/queryext/queryext_service.go
+package queryext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultMaxBytes 是未配置时扩展选项序列化为 JSON 后的最大字节数
+const defaultMaxBytes = 16 << 10
+
+// AllowAll 出现在业务组的白名单中时，该业务组允许任意扩展选项键
+const AllowAll = "*"
+
+var (
+	// ErrNotAllowed 表示请求中的扩展选项键不在业务组的白名单中
+	ErrNotAllowed = errors.New("扩展选项未被允许")
+	// ErrTooLarge 表示扩展选项超过了大小上限
+	ErrTooLarge = errors.New("扩展选项过大")
+)
+
+// Options 定义插件专有查询扩展选项的白名单
+type Options struct {
+	// Allow 的键为业务组名 (配置文件中的键不区分大小写，按小写匹配)，值为允许客户端传入的扩展选项键，
+	// "*" 表示允许任意键。未列出的业务组不接受任何扩展选项
+	Allow map[string][]string `mapstructure:"allow"`
+	// MaxBytes 是扩展选项序列化为 JSON 后的最大字节数，默认为 16KiB
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+// Service 按业务组的白名单决定客户端传入的扩展选项 (port.QueryRequest.Extensions) 能否下发给插件。
+// 扩展选项的内容由插件解释，网关无法检查，因此默认一律拒绝，只放行管理员为业务组明确允许的键
+type Service struct {
+	allow    map[string]map[string]bool
+	maxBytes int
+}
+
+// NewService 创建一个新的扩展选项白名单服务实例
+func NewService(opts Options) (*Service, error) {
+	allow := make(map[string]map[string]bool, len(opts.Allow))
+	for bizName, keys := range opts.Allow {
+		if strings.TrimSpace(bizName) == "" {
+			return nil, fmt.Errorf("扩展选项白名单中的业务组名不能为空")
+		}
+		set := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				return nil, fmt.Errorf("业务组 '%s' 的扩展选项白名单中有空的键", bizName)
+			}
+			set[key] = true
+		}
+		allow[bizName] = set
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	return &Service{allow: allow, maxBytes: maxBytes}, nil
+}
+
+// lookup 返回业务组的白名单，先按原名查找，再按小写查找
+func (s *Service) lookup(bizName string) map[string]bool {
+	if set, ok := s.allow[bizName]; ok {
+		return set
+	}
+	return s.allow[strings.ToLower(bizName)]
+}
+
+// Allowed 返回业务组允许的扩展选项键，按字母排序；未配置时返回空列表
+func (s *Service) Allowed(bizName string) []string {
+	keys := []string{}
+	if s == nil {
+		return keys
+	}
+	for key := range s.lookup(bizName) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Check 检查请求中的扩展选项：所有键都必须在业务组的白名单中，且总大小不超过上限。
+// 服务为 nil (未配置) 时任何非空的扩展选项都被拒绝
+func (s *Service) Check(bizName string, extensions map[string]interface{}) error {
+	if len(extensions) == 0 {
+		return nil
+	}
+	var set map[string]bool
+	if s != nil {
+		set = s.lookup(bizName)
+	}
+	if !set[AllowAll] {
+		var rejected []string
+		for key := range extensions {
+			if !set[key] {
+				rejected = append(rejected, key)
+			}
+		}
+		if len(rejected) > 0 {
+			sort.Strings(rejected)
+			return fmt.Errorf("%w: 业务组 '%s' 不接受 %s", ErrNotAllowed, bizName, strings.Join(rejected, ", "))
+		}
+	}
+	body, err := json.Marshal(extensions)
+	if err != nil {
+		return fmt.Errorf("%w: 无法序列化: %v", ErrNotAllowed, err)
+	}
+	if len(body) > s.maxBytes {
+		return fmt.Errorf("%w: %d 字节，最多允许 %d 字节", ErrTooLarge, len(body), s.maxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// file: internal/service/queryext/queryext_service_test.go
+package queryext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	svc, err := NewService(Options{Allow: map[string][]string{
+		"archive": {"hint", "query_string"},
+		"search":  {AllowAll},
+	}, MaxBytes: 64})
+	require.NoError(t, err)
+
+	assert.NoError(t, svc.Check("archive", nil))
+	assert.NoError(t, svc.Check("archive", map[string]interface{}{"hint": "idx_sender"}))
+	assert.NoError(t, svc.Check("Archive", map[string]interface{}{"hint": "idx_sender"}), "配置文件中的业务组名按小写匹配")
+	assert.NoError(t, svc.Check("search", map[string]interface{}{"anything": true}))
+
+	err = svc.Check("archive", map[string]interface{}{"hint": "x", "script": "x", "aggs": 1})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.Contains(t, err.Error(), "aggs, script")
+	assert.ErrorIs(t, svc.Check("other", map[string]interface{}{"hint": "x"}), ErrNotAllowed, "未列出的业务组不接受扩展选项")
+	assert.ErrorIs(t, svc.Check("archive", map[string]interface{}{"hint": strings.Repeat("x", 100)}), ErrTooLarge)
+
+	var disabled *Service
+	assert.NoError(t, disabled.Check("archive", nil))
+	assert.ErrorIs(t, disabled.Check("archive", map[string]interface{}{"hint": "x"}), ErrNotAllowed)
+	assert.Equal(t, []string{"hint", "query_string"}, svc.Allowed("archive"))
+	assert.Empty(t, disabled.Allowed("archive"))
+}
+
+func TestNewServiceRejectsEmptyKeys(t *testing.T) {
+	_, err := NewService(Options{Allow: map[string][]string{"archive": {" "}}})
+	assert.Error(t, err)
+	_, err = NewService(Options{Allow: map[string][]string{"": {"hint"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,443 @@
+// Package querytemplates file: internal/service/querytemplates/querytemplates_service.go
+package querytemplates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxTemplateFilters 与 maxTemplateParams 是单个模板的过滤条件数与参数数上限
+	maxTemplateFilters = 50
+	maxTemplateParams  = 20
+	// maxMultipleValues 是数组参数最多包含的取值数
+	maxMultipleValues = 500
+)
+
+// templateNamePattern 限定模板名称，名称会出现在 URL 路径中
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// templateOperators 是模板条件中 op 可取的值，与数据源支持的运算符一致
+var templateOperators = map[string]bool{
+	"in":           true,
+	"is_null":      true,
+	"is_not_null":  true,
+	"is_empty":     true,
+	"is_not_empty": true,
+}
+
+var (
+	// ErrTemplateNotFound 表示查询模板不存在
+	ErrTemplateNotFound = errors.New("查询模板不存在")
+	// ErrInvalidTemplate 表示查询模板的定义不合法
+	ErrInvalidTemplate = errors.New("无效的查询模板")
+	// ErrInvalidParams 表示执行模板时传入的参数不合法
+	ErrInvalidParams = errors.New("无效的模板参数")
+)
+
+// Service 持久化各业务组的查询模板，并把按名称执行的模板与参数展开为普通的查询。
+// 模板直接读写数据库，快照恢复后无需刷新
+type Service struct {
+	db            *sql.DB
+	configService port.QueryAdminConfigService
+	now           func() time.Time
+}
+
+// NewService 创建一个新的查询模板服务实例
+func NewService(db *sql.DB, configService port.QueryAdminConfigService) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("querytemplates.Service 需要一个有效的数据库连接")
+	}
+	if configService == nil {
+		return nil, errors.New("querytemplates.Service 需要有效的配置服务")
+	}
+	return &Service{db: db, configService: configService, now: time.Now}, nil
+}
+
+// List 返回业务组的全部查询模板，按名称排序
+func (s *Service) List(ctx context.Context, bizName string) ([]domain.QueryTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, template_json, updated_by, updated_at
+		FROM biz_query_templates WHERE biz_name = ? ORDER BY name`, bizName)
+	if err != nil {
+		return nil, fmt.Errorf("查询查询模板失败: %w", err)
+	}
+	defer rows.Close()
+	templates := []domain.QueryTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(bizName, rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// ListAvailable 返回业务组中当前可以执行的查询模板：业务组公开可查询，且模板的目标表可查询。
+// 供 meta 接口向客户端公开
+func (s *Service) ListAvailable(ctx context.Context, bizName string) ([]domain.QueryTemplate, error) {
+	cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	available := []domain.QueryTemplate{}
+	if !cfg.IsPubliclySearchable {
+		return available, nil
+	}
+	templates, err := s.List(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if table, ok := cfg.Tables[t.TableName]; ok && table.IsSearchable {
+			available = append(available, t)
+		}
+	}
+	return available, nil
+}
+
+// Get 返回一个查询模板
+func (s *Service) Get(ctx context.Context, bizName, name string) (*domain.QueryTemplate, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT name, template_json, updated_by, updated_at
+		FROM biz_query_templates WHERE biz_name = ? AND name = ?`, bizName, name)
+	t, err := scanTemplate(bizName, row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return t, err
+}
+
+// Save 创建或替换一个查询模板。过滤字段必须是目标表中已配置的可检索字段，返回字段必须可返回；
+// 引用的参数必须已声明，声明的参数也必须被引用
+func (s *Service) Save(ctx context.Context, t domain.QueryTemplate, userID int64) (*domain.QueryTemplate, error) {
+	if !templateNamePattern.MatchString(t.Name) {
+		return nil, fmt.Errorf("%w: 名称只能包含字母、数字、下划线与连字符，长度不超过 64", ErrInvalidTemplate)
+	}
+	cfg, err := s.configService.GetBizQueryConfig(ctx, t.BizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	table, ok := cfg.Tables[t.TableName]
+	if !ok {
+		return nil, fmt.Errorf("%w: 表 '%s' 未在业务组 '%s' 中配置", ErrInvalidTemplate, t.TableName, t.BizName)
+	}
+	if err := validateTemplate(&t, table); err != nil {
+		return nil, err
+	}
+
+	// biz_name、name 与更新信息已有独立的列，不重复写入 JSON
+	templateJSON, err := json.Marshal(struct {
+		Description    string                       `json:"description,omitempty"`
+		TableName      string                       `json:"table_name"`
+		Filters        []domain.QueryTemplateFilter `json:"filters"`
+		Params         []domain.QueryTemplateParam  `json:"params,omitempty"`
+		FieldsToReturn []string                     `json:"fields_to_return,omitempty"`
+	}{t.Description, t.TableName, t.Filters, t.Params, t.FieldsToReturn})
+	if err != nil {
+		return nil, fmt.Errorf("序列化查询模板失败: %w", err)
+	}
+	t.UpdatedBy, t.UpdatedAt = userID, s.now().UTC()
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO biz_query_templates (biz_name, name, template_json, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(biz_name, name) DO UPDATE SET
+			template_json = excluded.template_json, updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		t.BizName, t.Name, string(templateJSON), t.UpdatedBy, t.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("保存查询模板失败: %w", err)
+	}
+	return &t, nil
+}
+
+// Delete 删除一个查询模板
+func (s *Service) Delete(ctx context.Context, bizName, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_query_templates WHERE biz_name = ? AND name = ?`, bizName, name)
+	if err != nil {
+		return fmt.Errorf("删除查询模板失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return nil
+}
+
+// Render 校验参数并把模板展开为查询请求的 query 对象 (table、filters 与 fields_to_return)。
+// 未传入的参数使用默认值；非必填且没有默认值的参数未传入时，引用它的条件被省略
+func (s *Service) Render(ctx context.Context, bizName, name string, values map[string]interface{}) (map[string]interface{}, error) {
+	t, err := s.Get(ctx, bizName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]domain.QueryTemplateParam, len(t.Params))
+	for _, p := range t.Params {
+		params[p.Name] = p
+	}
+	resolved := make(map[string]interface{}, len(t.Params))
+	for key, raw := range values {
+		p, ok := params[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: 模板 '%s' 没有参数 '%s'", ErrInvalidParams, name, key)
+		}
+		if raw == nil {
+			continue
+		}
+		value, err := coerceParam(p, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 参数 '%s' %v", ErrInvalidParams, key, err)
+		}
+		resolved[key] = value
+	}
+	for _, p := range t.Params {
+		if _, ok := resolved[p.Name]; ok {
+			continue
+		}
+		switch {
+		case p.Default != nil:
+			resolved[p.Name], _ = coerceParam(p, p.Default)
+		case p.Required:
+			return nil, fmt.Errorf("%w: 缺少必填参数 '%s'", ErrInvalidParams, p.Name)
+		}
+	}
+
+	filters := make([]interface{}, 0, len(t.Filters))
+	for _, f := range t.Filters {
+		filter := map[string]interface{}{"field": f.Field, "logic": "AND"}
+		if f.Logic != "" {
+			filter["logic"] = strings.ToUpper(f.Logic)
+		}
+		if f.Op != "" {
+			filter["op"] = f.Op
+		}
+		if f.Fuzzy {
+			filter["fuzzy"] = true
+		}
+		if f.Negate {
+			filter[port.FilterNegateKey] = true
+		}
+		value := f.Value
+		if f.Param != "" {
+			var ok bool
+			if value, ok = resolved[f.Param]; !ok {
+				continue
+			}
+		}
+		switch {
+		case f.Op == "in":
+			filter[port.FilterValuesKey] = value
+		case f.Op == "":
+			filter["value"] = value
+		}
+		filters = append(filters, filter)
+	}
+
+	query := map[string]interface{}{"table": t.TableName, "filters": filters}
+	if len(t.FieldsToReturn) > 0 {
+		fields := make([]interface{}, len(t.FieldsToReturn))
+		for i, f := range t.FieldsToReturn {
+			fields[i] = f
+		}
+		query["fields_to_return"] = fields
+	}
+	return query, nil
+}
+
+// validateTemplate 校验模板的条件、参数与返回字段
+func validateTemplate(t *domain.QueryTemplate, table *domain.TableConfig) error {
+	if len(t.Filters) == 0 {
+		return fmt.Errorf("%w: 模板至少需要一个过滤条件", ErrInvalidTemplate)
+	}
+	if len(t.Filters) > maxTemplateFilters || len(t.Params) > maxTemplateParams {
+		return fmt.Errorf("%w: 过滤条件不能超过 %d 个，参数不能超过 %d 个", ErrInvalidTemplate, maxTemplateFilters, maxTemplateParams)
+	}
+
+	params := make(map[string]domain.QueryTemplateParam, len(t.Params))
+	for i, p := range t.Params {
+		if !templateNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("%w: 参数名 '%s' 只能包含字母、数字、下划线与连字符", ErrInvalidTemplate, p.Name)
+		}
+		if _, dup := params[p.Name]; dup {
+			return fmt.Errorf("%w: 参数 '%s' 重复声明", ErrInvalidTemplate, p.Name)
+		}
+		if p.Type == "" {
+			p.Type = domain.QueryTemplateParamText
+			t.Params[i].Type = p.Type
+		}
+		switch p.Type {
+		case domain.QueryTemplateParamText, domain.QueryTemplateParamInteger, domain.QueryTemplateParamNumber:
+		default:
+			return fmt.Errorf("%w: 参数 '%s' 的类型 '%s' 无效，可选 text、integer 或 number", ErrInvalidTemplate, p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if p.Required {
+				return fmt.Errorf("%w: 必填参数 '%s' 不能有默认值", ErrInvalidTemplate, p.Name)
+			}
+			if _, err := coerceParam(p, p.Default); err != nil {
+				return fmt.Errorf("%w: 参数 '%s' 的默认值%v", ErrInvalidTemplate, p.Name, err)
+			}
+		}
+		params[p.Name] = p
+	}
+
+	used := make(map[string]bool, len(params))
+	for _, f := range t.Filters {
+		setting, ok := table.Fields[f.Field]
+		if !ok || !setting.IsSearchable {
+			return fmt.Errorf("%w: 字段 '%s' 无效或不可搜索", ErrInvalidTemplate, f.Field)
+		}
+		if f.Op != "" && !templateOperators[f.Op] {
+			return fmt.Errorf("%w: 不支持的过滤运算符 '%s'", ErrInvalidTemplate, f.Op)
+		}
+		if logic := strings.ToUpper(f.Logic); logic != "" && logic != "AND" && logic != "OR" {
+			return fmt.Errorf("%w: 无效的逻辑操作符 '%s'", ErrInvalidTemplate, f.Logic)
+		}
+		isIn, needsValue := f.Op == "in", f.Op == "" || f.Op == "in"
+		switch {
+		case f.Param != "":
+			p, declared := params[f.Param]
+			if !declared {
+				return fmt.Errorf("%w: 字段 '%s' 引用了未声明的参数 '%s'", ErrInvalidTemplate, f.Field, f.Param)
+			}
+			if !needsValue {
+				return fmt.Errorf("%w: 运算符 '%s' 不需要取值，不能引用参数", ErrInvalidTemplate, f.Op)
+			}
+			if p.Multiple != isIn {
+				return fmt.Errorf("%w: 数组参数只能且必须用于运算符 'in' (参数 '%s')", ErrInvalidTemplate, f.Param)
+			}
+			if f.Value != nil {
+				return fmt.Errorf("%w: 字段 '%s' 的条件不能同时指定 value 与 param", ErrInvalidTemplate, f.Field)
+			}
+			used[f.Param] = true
+		case isIn:
+			values, ok := f.Value.([]interface{})
+			if !ok || len(values) == 0 {
+				return fmt.Errorf("%w: 运算符 'in' 的固定取值必须是非空数组", ErrInvalidTemplate)
+			}
+		case needsValue:
+			switch f.Value.(type) {
+			case string, float64, bool:
+			default:
+				return fmt.Errorf("%w: 字段 '%s' 的条件需要固定的 value 或引用参数", ErrInvalidTemplate, f.Field)
+			}
+		}
+	}
+	for _, p := range t.Params {
+		if !used[p.Name] {
+			return fmt.Errorf("%w: 参数 '%s' 未被任何条件引用", ErrInvalidTemplate, p.Name)
+		}
+	}
+
+	for _, field := range t.FieldsToReturn {
+		if setting, ok := table.Fields[field]; !ok || !setting.IsReturnable {
+			return fmt.Errorf("%w: 字段 '%s' 未配置或不可返回", ErrInvalidTemplate, field)
+		}
+	}
+	return nil
+}
+
+// coerceParam 按参数声明的类型校验并规范化参数值；数组参数返回 []interface{}
+func coerceParam(p domain.QueryTemplateParam, raw interface{}) (interface{}, error) {
+	if p.Multiple {
+		items, ok := raw.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, errors.New("必须是非空数组")
+		}
+		if len(items) > maxMultipleValues {
+			return nil, fmt.Errorf("最多包含 %d 个取值", maxMultipleValues)
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceScalar(p, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+	return coerceScalar(p, raw)
+}
+
+// coerceScalar 校验单个参数值：整数与数字参数也接受数字形式的字符串，文本参数接受数字
+func coerceScalar(p domain.QueryTemplateParam, raw interface{}) (interface{}, error) {
+	var value interface{}
+	switch p.Type {
+	case domain.QueryTemplateParamInteger:
+		switch v := raw.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("'%v' 不是整数", v)
+			}
+			value = int64(v)
+		case string:
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' 不是整数", v)
+			}
+			value = n
+		default:
+			return nil, fmt.Errorf("'%v' 不是整数", raw)
+		}
+	case domain.QueryTemplateParamNumber:
+		switch v := raw.(type) {
+		case float64:
+			value = v
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' 不是数字", v)
+			}
+			value = f
+		default:
+			return nil, fmt.Errorf("'%v' 不是数字", raw)
+		}
+	default:
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("'%v' 不是文本", raw)
+		}
+	}
+	if len(p.Enum) > 0 && !slices.Contains(p.Enum, fmt.Sprint(value)) {
+		return nil, fmt.Errorf("取值 '%v' 不在可选值 %v 中", value, p.Enum)
+	}
+	return value, nil
+}
+
+// rowScanner 是 *sql.Row 与 *sql.Rows 共有的扫描方法
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplate(bizName string, row rowScanner) (*domain.QueryTemplate, error) {
+	t := domain.QueryTemplate{BizName: bizName}
+	var templateJSON string
+	if err := row.Scan(&t.Name, &templateJSON, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("扫描查询模板失败: %w", err)
+	}
+	if err := json.Unmarshal([]byte(templateJSON), &t); err != nil {
+		return nil, fmt.Errorf("解析查询模板 '%s' 失败: %w", t.Name, err)
+	}
+	return &t, nil
+}
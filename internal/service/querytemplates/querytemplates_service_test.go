@@ -0,0 +1,156 @@
+// file: internal/service/querytemplates/querytemplates_service_test.go
+package querytemplates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *admin_config.AdminConfigServiceImpl) {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	public := true
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{IsPubliclySearchable: &public}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "title", IsSearchable: true, IsReturnable: true},
+		{FieldName: "year", IsSearchable: true, IsReturnable: true},
+		{FieldName: "genre", IsSearchable: true, IsReturnable: true},
+		{FieldName: "internal_code"},
+	}))
+	svc, err := NewService(db, cfg)
+	require.NoError(t, err)
+	return svc, cfg
+}
+
+func bookTemplate() domain.QueryTemplate {
+	return domain.QueryTemplate{
+		BizName:     "library",
+		Name:        "by-genre",
+		Description: "按体裁与年份检索",
+		TableName:   "books",
+		Filters: []domain.QueryTemplateFilter{
+			{Field: "genre", Op: "in", Param: "genres"},
+			{Field: "year", Param: "year"},
+			{Field: "title", Op: "is_not_empty"},
+		},
+		Params: []domain.QueryTemplateParam{
+			{Name: "genres", Multiple: true, Required: true, Enum: []string{"诗歌", "小说", "散文"}},
+			{Name: "year", Type: domain.QueryTemplateParamInteger},
+		},
+		FieldsToReturn: []string{"title", "year"},
+	}
+}
+
+func TestQueryTemplates_SaveValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	for name, mutate := range map[string]func(*domain.QueryTemplate){
+		"名称不合法":   func(tpl *domain.QueryTemplate) { tpl.Name = "按体裁" },
+		"不可搜索的字段": func(tpl *domain.QueryTemplate) { tpl.Filters[2].Field = "internal_code" },
+		"未声明的参数":  func(tpl *domain.QueryTemplate) { tpl.Filters[1].Param = "decade" },
+		"未引用的参数": func(tpl *domain.QueryTemplate) {
+			tpl.Params = append(tpl.Params, domain.QueryTemplateParam{Name: "extra"})
+		},
+		"数组参数用于等值": func(tpl *domain.QueryTemplate) { tpl.Filters[0].Op = "" },
+		"未知运算符":    func(tpl *domain.QueryTemplate) { tpl.Filters[2].Op = "like" },
+		"不可返回的字段":  func(tpl *domain.QueryTemplate) { tpl.FieldsToReturn = []string{"internal_code"} },
+		"默认值类型错误":  func(tpl *domain.QueryTemplate) { tpl.Params[1].Default = "abc" },
+		"缺少固定取值": func(tpl *domain.QueryTemplate) {
+			tpl.Filters = append(tpl.Filters, domain.QueryTemplateFilter{Field: "title"})
+		},
+	} {
+		tpl := bookTemplate()
+		mutate(&tpl)
+		_, err := svc.Save(ctx, tpl, 1)
+		assert.ErrorIs(t, err, ErrInvalidTemplate, name)
+	}
+	tpl := bookTemplate()
+	tpl.TableName = "missing"
+	_, err := svc.Save(ctx, tpl, 1)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	saved, err := svc.Save(ctx, bookTemplate(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QueryTemplateParamText, saved.Params[0].Type, "未指定类型的参数按文本处理")
+	got, err := svc.Get(ctx, "library", "by-genre")
+	require.NoError(t, err)
+	assert.Equal(t, "按体裁与年份检索", got.Description)
+	assert.EqualValues(t, 7, got.UpdatedBy)
+	assert.Len(t, got.Filters, 3)
+
+	require.NoError(t, svc.Delete(ctx, "library", "by-genre"))
+	assert.ErrorIs(t, svc.Delete(ctx, "library", "by-genre"), ErrTemplateNotFound)
+	_, err = svc.Get(ctx, "library", "by-genre")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestQueryTemplates_Render(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	_, err := svc.Save(ctx, bookTemplate(), 1)
+	require.NoError(t, err)
+
+	query, err := svc.Render(ctx, "library", "by-genre", map[string]interface{}{
+		"genres": []interface{}{"诗歌", "小说"},
+		"year":   "1925",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "books", query["table"])
+	assert.Equal(t, []interface{}{"title", "year"}, query["fields_to_return"])
+	filters := query["filters"].([]interface{})
+	require.Len(t, filters, 3)
+	assert.Equal(t, []interface{}{"诗歌", "小说"}, filters[0].(map[string]interface{})[port.FilterValuesKey])
+	assert.Equal(t, int64(1925), filters[1].(map[string]interface{})["value"])
+	assert.Equal(t, "is_not_empty", filters[2].(map[string]interface{})["op"])
+
+	query, err = svc.Render(ctx, "library", "by-genre", map[string]interface{}{"genres": []interface{}{"散文"}})
+	require.NoError(t, err)
+	assert.Len(t, query["filters"], 2, "非必填且未传入的参数所在条件被省略")
+
+	for name, values := range map[string]map[string]interface{}{
+		"缺少必填参数":  {"year": 1925.0},
+		"不在可选值中":  {"genres": []interface{}{"戏剧"}},
+		"整数参数非整数": {"genres": []interface{}{"诗歌"}, "year": 1925.5},
+		"未知参数":    {"genres": []interface{}{"诗歌"}, "author": "鲁迅"},
+		"数组参数为空":  {"genres": []interface{}{}},
+	} {
+		_, err := svc.Render(ctx, "library", "by-genre", values)
+		assert.ErrorIs(t, err, ErrInvalidParams, name)
+	}
+	_, err = svc.Render(ctx, "library", "missing", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestQueryTemplates_ListAvailable(t *testing.T) {
+	ctx := context.Background()
+	svc, cfg := newTestService(t)
+	_, err := svc.Save(ctx, bookTemplate(), 1)
+	require.NoError(t, err)
+
+	available, err := svc.ListAvailable(ctx, "library")
+	require.NoError(t, err)
+	require.Len(t, available, 1)
+
+	private := false
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{IsPubliclySearchable: &private}))
+	available, err = svc.ListAvailable(ctx, "library")
+	require.NoError(t, err)
+	assert.Empty(t, available, "业务组不公开时不列出模板")
+
+	all, err := svc.List(ctx, "library")
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
@@ -0,0 +1,172 @@
+// Package readsplitbiz file: internal/service/readsplitbiz/readsplitbiz_service.go
+package readsplitbiz
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/readsplit"
+	"ArchiveAegis/internal/adapter/datasource/sharded"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	// ErrReadSplitBizNotFound 表示指定的读写分离业务组不存在
+	ErrReadSplitBizNotFound = errors.New("读写分离业务组不存在")
+	// ErrInvalidDefinition 表示读写分离业务组定义不合法
+	ErrInvalidDefinition = errors.New("无效的读写分离业务组定义")
+)
+
+// Service 负责持久化读写分离业务组定义，并使注册表中的数据源与定义保持一致
+type Service struct {
+	db        *sql.DB
+	registrar virtualbiz.Registrar
+}
+
+// NewService 创建一个新的读写分离业务组服务实例
+func NewService(db *sql.DB, registrar virtualbiz.Registrar) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("readsplitbiz.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, registrar: registrar}, nil
+}
+
+// LoadAll 在启动时注册所有已保存的读写分离业务组。单个定义注册失败不会影响其它定义。
+func (s *Service) LoadAll(ctx context.Context) error {
+	defs, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if err := s.registrar.RegisterStaticDataSource(def.BizName, readsplit.New(def, s.registrar.LookupDataSource)); err != nil {
+			slog.Warn("[ReadSplit] 注册读写分离业务组失败，已跳过", "biz", def.BizName, "error", err)
+		}
+	}
+	return nil
+}
+
+// List 返回所有读写分离业务组定义
+func (s *Service) List(ctx context.Context) ([]domain.ReadSplitBiz, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, description, writer_biz, readers_json, updated_at FROM read_split_biz_definitions ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询读写分离业务组失败: %w", err)
+	}
+	defer rows.Close()
+
+	defs := make([]domain.ReadSplitBiz, 0)
+	for rows.Next() {
+		var def domain.ReadSplitBiz
+		var readersJSON string
+		if err := rows.Scan(&def.BizName, &def.Description, &def.Writer, &readersJSON, &def.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描读写分离业务组失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(readersJSON), &def.Readers); err != nil {
+			return nil, fmt.Errorf("解析读写分离业务组 '%s' 的副本列表失败: %w", def.BizName, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// Save 创建或更新一个读写分离业务组，并立即在网关中生效
+func (s *Service) Save(ctx context.Context, def domain.ReadSplitBiz) error {
+	if err := s.validate(def); err != nil {
+		return err
+	}
+	readersJSON, err := json.Marshal(def.Readers)
+	if err != nil {
+		return fmt.Errorf("序列化副本列表失败: %w", err)
+	}
+
+	// 先注册再持久化：名称被插件占用时不应留下无法生效的定义
+	if err := s.registrar.RegisterStaticDataSource(def.BizName, readsplit.New(def, s.registrar.LookupDataSource)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO read_split_biz_definitions (biz_name, description, writer_biz, readers_json, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			description = excluded.description,
+			writer_biz = excluded.writer_biz,
+			readers_json = excluded.readers_json,
+			updated_at = CURRENT_TIMESTAMP`,
+		def.BizName, def.Description, def.Writer, string(readersJSON),
+	)
+	if err != nil {
+		s.registrar.UnregisterStaticDataSource(def.BizName)
+		return fmt.Errorf("保存读写分离业务组 '%s' 失败: %w", def.BizName, err)
+	}
+	return nil
+}
+
+// Delete 删除一个读写分离业务组，并将其从网关注销。写入实例与副本业务组不受影响
+func (s *Service) Delete(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM read_split_biz_definitions WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除读写分离业务组 '%s' 失败: %w", bizName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReadSplitBizNotFound
+	}
+	s.registrar.UnregisterStaticDataSource(bizName)
+	return nil
+}
+
+// ReaderStatus 返回读写分离业务组各副本当前的分发状态
+func (s *Service) ReaderStatus(bizName string) ([]readsplit.ReaderStatus, error) {
+	ds, loaded := s.registrar.LookupDataSource(bizName)
+	if !loaded {
+		return nil, ErrReadSplitBizNotFound
+	}
+	rs, ok := ds.(*readsplit.DataSource)
+	if !ok {
+		return nil, ErrReadSplitBizNotFound
+	}
+	return rs.Readers(), nil
+}
+
+// validate 校验定义的完整性。写入实例与副本不能是虚拟、分片或读写分离业务组，以避免循环路由；
+// 名称也不能覆盖已注册的虚拟或分片业务组
+func (s *Service) validate(def domain.ReadSplitBiz) error {
+	if def.BizName == "" {
+		return fmt.Errorf("%w: biz_name 不能为空", ErrInvalidDefinition)
+	}
+	if def.Writer == "" {
+		return fmt.Errorf("%w: writer 不能为空", ErrInvalidDefinition)
+	}
+	if len(def.Readers) == 0 {
+		return fmt.Errorf("%w: 至少需要一个副本", ErrInvalidDefinition)
+	}
+	if ds, loaded := s.registrar.LookupDataSource(def.BizName); loaded {
+		switch ds.(type) {
+		case *virtual.DataSource, *sharded.DataSource:
+			return fmt.Errorf("%w: 名称 '%s' 已被虚拟或分片业务组使用", ErrInvalidDefinition, def.BizName)
+		}
+	}
+
+	seen := make(map[string]bool, len(def.Readers)+1)
+	for _, member := range append([]string{def.Writer}, def.Readers...) {
+		if member == "" {
+			return fmt.Errorf("%w: 副本的业务组名不能为空", ErrInvalidDefinition)
+		}
+		if seen[member] {
+			return fmt.Errorf("%w: 业务组 '%s' 重复出现 (写入实例不能同时作为副本)", ErrInvalidDefinition, member)
+		}
+		seen[member] = true
+		if member == def.BizName {
+			return fmt.Errorf("%w: 写入实例与副本不能是其自身", ErrInvalidDefinition)
+		}
+		if ds, loaded := s.registrar.LookupDataSource(member); loaded {
+			switch ds.(type) {
+			case *virtual.DataSource, *sharded.DataSource, *readsplit.DataSource:
+				return fmt.Errorf("%w: '%s' 不能是虚拟、分片或读写分离业务组", ErrInvalidDefinition, member)
+			}
+		}
+	}
+	return nil
+}
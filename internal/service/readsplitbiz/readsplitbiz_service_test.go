@@ -0,0 +1,203 @@
+// file: internal/service/readsplitbiz/readsplitbiz_service_test.go
+package readsplitbiz_test
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/readsplit"
+	"ArchiveAegis/internal/adapter/datasource/sharded"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/readsplitbiz"
+	"ArchiveAegis/pkg/testsupport"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+	svc      *readsplitbiz.Service
+	registry *testsupport.StaticRegistry
+	writer   *testsupport.FakeDataSource
+	readers  []*testsupport.FakeDataSource
+}
+
+func newLettersSource() *testsupport.FakeDataSource {
+	return testsupport.NewFakeDataSource().WithTable("letters",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅"},
+	)
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		writer:  newLettersSource(),
+		readers: []*testsupport.FakeDataSource{newLettersSource(), newLettersSource()},
+	}
+	f.registry = testsupport.NewStaticRegistry().
+		RegisterPlugin("letters_primary", f.writer).
+		RegisterPlugin("letters_r1", f.readers[0]).
+		RegisterPlugin("letters_r2", f.readers[1])
+	svc, err := readsplitbiz.NewService(testsupport.NewSystemDB(t), f.registry)
+	require.NoError(t, err)
+	f.svc = svc
+	return f
+}
+
+func lettersSplit() domain.ReadSplitBiz {
+	return domain.ReadSplitBiz{BizName: "letters", Writer: "letters_primary", Readers: []string{"letters_r1", "letters_r2"}}
+}
+
+func query(t *testing.T, ds port.DataSource) *port.QueryResult {
+	t.Helper()
+	result, err := ds.Query(context.Background(), port.QueryRequest{BizName: "letters", Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err)
+	return result
+}
+
+func TestNewServiceRequiresDB(t *testing.T) {
+	_, err := readsplitbiz.NewService(nil, testsupport.NewStaticRegistry())
+	assert.Error(t, err)
+}
+
+func TestSaveRegistersReadSplitBiz(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersSplit()))
+
+	ds, loaded := f.registry.LookupDataSource("letters")
+	require.True(t, loaded)
+	require.IsType(t, &readsplit.DataSource{}, ds)
+
+	query(t, ds)
+	query(t, ds)
+	assert.Len(t, f.readers[0].Queries(), 1, "查询在副本之间轮询")
+	assert.Len(t, f.readers[1].Queries(), 1, "查询在副本之间轮询")
+	assert.Empty(t, f.writer.Queries(), "副本健康时写入实例不承担查询")
+
+	_, err := ds.Mutate(ctx, port.MutateRequest{BizName: "letters", Operation: "create",
+		Payload: map[string]interface{}{"table_name": "letters", "data": map[string]interface{}{"sender": "胡适"}}})
+	require.NoError(t, err)
+	require.Len(t, f.writer.Mutations(), 1, "写操作发往写入实例")
+	assert.Equal(t, "letters_primary", f.writer.Mutations()[0].BizName)
+
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "letters_primary", defs[0].Writer)
+	assert.Equal(t, []string{"letters_r1", "letters_r2"}, defs[0].Readers)
+}
+
+func TestReaderStatusReportsUnavailableReader(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersSplit()))
+	down := errors.New("副本不可达")
+	f.readers[0].FailQuery(down).FailHealthCheck(down)
+
+	ds, _ := f.registry.LookupDataSource("letters")
+	query(t, ds)
+	query(t, ds)
+	assert.Len(t, f.readers[1].Queries(), 2, "不可用的副本被暂停后查询由其余副本承担")
+
+	status, err := f.svc.ReaderStatus("letters")
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	assert.Equal(t, "letters_r1", status[0].BizName)
+	assert.True(t, status[0].Registered)
+	assert.NotNil(t, status[0].DownUntil)
+	assert.Nil(t, status[1].DownUntil)
+
+	_, err = f.svc.ReaderStatus("letters_primary")
+	assert.ErrorIs(t, err, readsplitbiz.ErrReadSplitBizNotFound, "普通业务组没有副本状态")
+	_, err = f.svc.ReaderStatus("missing")
+	assert.ErrorIs(t, err, readsplitbiz.ErrReadSplitBizNotFound)
+}
+
+func TestSaveRejectsInvalidDefinitions(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersSplit()))
+	require.NoError(t, f.registry.RegisterStaticDataSource("selected", virtual.New(domain.VirtualBiz{BizName: "selected"}, f.registry.LookupDataSource)))
+	byYear, err := sharded.New(domain.ShardedBiz{BizName: "by_year", ShardKey: "year", Shards: []domain.Shard{{SourceBiz: "letters_r1"}}}, f.registry.LookupDataSource)
+	require.NoError(t, err)
+	require.NoError(t, f.registry.RegisterStaticDataSource("by_year", byYear))
+
+	cases := map[string]domain.ReadSplitBiz{
+		"缺少名称":       {Writer: "letters_primary", Readers: []string{"letters_r1"}},
+		"缺少写入实例":     {BizName: "s", Readers: []string{"letters_r1"}},
+		"没有副本":       {BizName: "s", Writer: "letters_primary"},
+		"副本名为空":      {BizName: "s", Writer: "letters_primary", Readers: []string{""}},
+		"副本重复":       {BizName: "s", Writer: "letters_primary", Readers: []string{"letters_r1", "letters_r1"}},
+		"写入实例兼作副本":   {BizName: "s", Writer: "letters_primary", Readers: []string{"letters_primary"}},
+		"引用自身":       {BizName: "s", Writer: "s", Readers: []string{"letters_r1"}},
+		"写入实例是读写分离":  {BizName: "s", Writer: "letters", Readers: []string{"letters_r1"}},
+		"副本是虚拟业务组":   {BizName: "s", Writer: "letters_primary", Readers: []string{"selected"}},
+		"副本是分片业务组":   {BizName: "s", Writer: "letters_primary", Readers: []string{"by_year"}},
+		"名称被分片业务组占用": {BizName: "by_year", Writer: "letters_primary", Readers: []string{"letters_r1"}},
+	}
+	for name, def := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, f.svc.Save(ctx, def), readsplitbiz.ErrInvalidDefinition)
+		})
+	}
+
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "被拒绝的定义不应被保存")
+	_, loaded := f.registry.LookupDataSource("s")
+	assert.False(t, loaded, "被拒绝的定义不应被注册")
+}
+
+func TestSaveRejectsNameServedByPlugin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	def := domain.ReadSplitBiz{BizName: "letters_r2", Writer: "letters_primary", Readers: []string{"letters_r1"}}
+
+	assert.ErrorIs(t, f.svc.Save(ctx, def), readsplitbiz.ErrInvalidDefinition)
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, defs, "名称被插件占用时不应留下无法生效的定义")
+}
+
+func TestDeleteUnregistersAndKeepsMembers(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersSplit()))
+
+	require.NoError(t, f.svc.Delete(ctx, "letters"))
+	_, loaded := f.registry.LookupDataSource("letters")
+	assert.False(t, loaded)
+	for _, member := range []string{"letters_primary", "letters_r1", "letters_r2"} {
+		_, loaded = f.registry.LookupDataSource(member)
+		assert.True(t, loaded, "业务组 '%s' 不受影响", member)
+	}
+
+	assert.ErrorIs(t, f.svc.Delete(ctx, "letters"), readsplitbiz.ErrReadSplitBizNotFound)
+}
+
+func TestLoadAllRegistersSavedDefinitions(t *testing.T) {
+	ctx := context.Background()
+	db := testsupport.NewSystemDB(t)
+	first, err := readsplitbiz.NewService(db, testsupport.NewStaticRegistry())
+	require.NoError(t, err)
+	require.NoError(t, first.Save(ctx, lettersSplit()))
+	blocked := lettersSplit()
+	blocked.BizName = "reclaimed"
+	require.NoError(t, first.Save(ctx, blocked))
+
+	// 重启后 reclaimed 已由插件提供，跳过它不影响其它定义的注册
+	registry := testsupport.NewStaticRegistry().RegisterPlugin("reclaimed", testsupport.NewFakeDataSource())
+	restarted, err := readsplitbiz.NewService(db, registry)
+	require.NoError(t, err)
+	require.NoError(t, restarted.LoadAll(ctx))
+
+	ds, loaded := registry.LookupDataSource("letters")
+	require.True(t, loaded)
+	assert.IsType(t, &readsplit.DataSource{}, ds)
+	ds, _ = registry.LookupDataSource("reclaimed")
+	assert.IsType(t, &testsupport.FakeDataSource{}, ds)
+}
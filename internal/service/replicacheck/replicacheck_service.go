@@ -0,0 +1,395 @@
+// Package replicacheck file: internal/service/replicacheck/replicacheck_service.go
+package replicacheck
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// QueryPath 是对端网关的数据查询接口路径
+	QueryPath = "/api/v1/data/query"
+
+	defaultSamplesPerTable = 5
+	maxSamplesPerTable     = 50
+	pageSize               = 50
+	maxDiffSamples         = 5
+)
+
+// ErrInvalidRequest 表示比对请求的参数不完整或不合法
+var ErrInvalidRequest = errors.New("无效的副本比对请求")
+
+// TargetLookup 查找业务组配置的同步目标，由 replication.Service 实现
+type TargetLookup interface {
+	Target(ctx context.Context, bizName string) (domain.ReplicationTarget, error)
+}
+
+// Request 指定比对的对端：CompareBiz 为本实例上的另一个业务组 (如从快照恢复的副本)；
+// 否则为 TargetURL 上的远端网关，需同时给出 TargetBiz 与 AuthToken；三者都省略时使用该业务组的同步目标。
+// Tables 为空时比对所有开放检索的表
+type Request struct {
+	CompareBiz      string   `json:"compare_biz"`
+	TargetURL       string   `json:"target_url"`
+	TargetBiz       string   `json:"target_biz"`
+	AuthToken       string   `json:"auth_token"`
+	Tables          []string `json:"tables"`
+	SamplesPerTable int      `json:"samples_per_table"`
+}
+
+// peer 是比对的对端
+type peer interface {
+	query(ctx context.Context, q map[string]interface{}) (items []interface{}, total int64, err error)
+}
+
+// Service 以只读方式对同一业务组的两份数据执行相同的抽样查询并比对结果，用于尽早发现同步或迁移造成的数据损坏。
+// 每张表先比对第一页，再从本地结果中抽取若干行，以其可检索字段的值构造精确查询；
+// 查询只请求可返回字段，两侧结果完整时逐行比对，否则只比对总数
+type Service struct {
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+	targets  TargetLookup
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewService 创建一个新的副本比对服务实例，targets 可为 nil
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource, targets TargetLookup) (*Service, error) {
+	if config == nil {
+		return nil, errors.New("replicacheck.Service 需要有效的配置服务")
+	}
+	return &Service{
+		config:   config,
+		registry: registry,
+		targets:  targets,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Verify 比对业务组与对端的抽样查询结果
+func (s *Service) Verify(ctx context.Context, bizName string, req Request) (*domain.ReplicaVerification, error) {
+	local, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if req.SamplesPerTable < 0 || req.SamplesPerTable > maxSamplesPerTable {
+		return nil, fmt.Errorf("%w: samples_per_table 必须在 0 到 %d 之间", ErrInvalidRequest, maxSamplesPerTable)
+	}
+	if req.SamplesPerTable == 0 {
+		req.SamplesPerTable = defaultSamplesPerTable
+	}
+	other, against, err := s.resolvePeer(ctx, bizName, req)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := selectTables(bizConfig, req.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	started := s.now()
+	report := &domain.ReplicaVerification{
+		BizName:   bizName,
+		Against:   against,
+		Checks:    make([]domain.ReplicaQueryCheck, 0),
+		StartedAt: started.UTC(),
+	}
+	localPeer := &localPeer{bizName: bizName, dataSource: local}
+	for _, table := range tables {
+		var returnable, searchable []string
+		for name, field := range table.Fields {
+			if field.IsReturnable {
+				returnable = append(returnable, name)
+				if field.IsSearchable {
+					searchable = append(searchable, name)
+				}
+			}
+		}
+		if len(returnable) == 0 {
+			continue
+		}
+		sort.Strings(returnable)
+		sort.Strings(searchable)
+
+		first := baseQuery(table.TableName, returnable)
+		check, rows := compare(ctx, localPeer, other, table.TableName, first, returnable)
+		report.Checks = append(report.Checks, check)
+		for _, filter := range sampleFilters(rows, searchable, req.SamplesPerTable) {
+			q := baseQuery(table.TableName, returnable)
+			q["filters"] = []interface{}{filter}
+			check, _ := compare(ctx, localPeer, other, table.TableName, q, returnable)
+			report.Checks = append(report.Checks, check)
+		}
+	}
+	for _, check := range report.Checks {
+		report.Queries++
+		switch {
+		case check.Error != "":
+			report.Errors++
+		case check.Diverged:
+			report.Diverged++
+		}
+	}
+	report.Duration = s.now().Sub(started).String()
+	return report, nil
+}
+
+// resolvePeer 按请求确定对端，返回对端与其描述
+func (s *Service) resolvePeer(ctx context.Context, bizName string, req Request) (peer, string, error) {
+	switch {
+	case req.CompareBiz != "":
+		if req.CompareBiz == bizName {
+			return nil, "", fmt.Errorf("%w: compare_biz 不能与被比对的业务组相同", ErrInvalidRequest)
+		}
+		dataSource, ok := s.registry[req.CompareBiz]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: 对比业务组 '%s' 不存在", ErrInvalidRequest, req.CompareBiz)
+		}
+		return &localPeer{bizName: req.CompareBiz, dataSource: dataSource}, "biz:" + req.CompareBiz, nil
+	case req.TargetURL != "":
+		if req.TargetBiz == "" || req.AuthToken == "" {
+			return nil, "", fmt.Errorf("%w: 指定 target_url 时必须同时给出 target_biz 与 auth_token", ErrInvalidRequest)
+		}
+		if !strings.HasPrefix(req.TargetURL, "http://") && !strings.HasPrefix(req.TargetURL, "https://") {
+			return nil, "", fmt.Errorf("%w: 无效的对端地址 '%s'", ErrInvalidRequest, req.TargetURL)
+		}
+		url := strings.TrimRight(req.TargetURL, "/")
+		return &remotePeer{client: s.client, url: url, bizName: req.TargetBiz, token: req.AuthToken}, url + "#" + req.TargetBiz, nil
+	}
+	if s.targets == nil {
+		return nil, "", fmt.Errorf("%w: 未指定对端", ErrInvalidRequest)
+	}
+	target, err := s.targets.Target(ctx, bizName)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: 未指定对端，且无法使用同步目标: %v", ErrInvalidRequest, err)
+	}
+	return &remotePeer{client: s.client, url: target.TargetURL, bizName: target.TargetBiz, token: target.AuthToken},
+		target.TargetURL + "#" + target.TargetBiz, nil
+}
+
+// selectTables 返回要比对的开放检索的表，按表名排序
+func selectTables(bizConfig *domain.BizQueryConfig, names []string) ([]*domain.TableConfig, error) {
+	var tables []*domain.TableConfig
+	if len(names) == 0 {
+		for _, table := range bizConfig.Tables {
+			if table.IsSearchable {
+				tables = append(tables, table)
+			}
+		}
+	} else {
+		for _, name := range names {
+			table, ok := bizConfig.Tables[name]
+			if !ok || !table.IsSearchable {
+				return nil, fmt.Errorf("%w: 表 '%s' 不存在或未开放检索", ErrInvalidRequest, name)
+			}
+			tables = append(tables, table)
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].TableName < tables[j].TableName })
+	return tables, nil
+}
+
+func baseQuery(table string, fields []string) map[string]interface{} {
+	fieldsToReturn := make([]interface{}, len(fields))
+	for i, f := range fields {
+		fieldsToReturn[i] = f
+	}
+	return map[string]interface{}{
+		"table":            table,
+		"page":             float64(1),
+		"size":             float64(pageSize),
+		"fields_to_return": fieldsToReturn,
+		// 任一侧只返回部分库的结果时比对没有意义，因此要求全部库查询成功
+		port.QueryStrictKey: true,
+	}
+}
+
+// sampleFilters 从本地结果中等距抽取行，轮流以各可检索字段的非空值构造精确过滤条件
+func sampleFilters(rows []map[string]interface{}, searchable []string, n int) []map[string]interface{} {
+	if len(rows) == 0 || len(searchable) == 0 {
+		return nil
+	}
+	step := max(len(rows)/n, 1)
+	var filters []map[string]interface{}
+	for i := 0; i < len(rows) && len(filters) < n; i += step {
+		row := rows[i]
+		for j := range searchable {
+			field := searchable[(len(filters)+j)%len(searchable)]
+			value := row[field]
+			if value == nil || fmt.Sprint(value) == "" {
+				continue
+			}
+			filters = append(filters, map[string]interface{}{"field": field, "value": value})
+			break
+		}
+	}
+	return filters
+}
+
+// compare 在两侧执行同一查询并比对，同时返回本地结果的行供后续抽样
+func compare(ctx context.Context, local, other peer, table string, q map[string]interface{}, fields []string) (domain.ReplicaQueryCheck, []map[string]interface{}) {
+	check := domain.ReplicaQueryCheck{Table: table, Query: q}
+	localItems, localTotal, err := local.query(ctx, cloneQuery(q))
+	if err != nil {
+		check.Error = "本地查询失败: " + err.Error()
+		return check, nil
+	}
+	rows := make([]map[string]interface{}, 0, len(localItems))
+	for _, item := range localItems {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	check.LocalTotal = localTotal
+	remoteItems, remoteTotal, err := other.query(ctx, cloneQuery(q))
+	if err != nil {
+		check.Error = "对端查询失败: " + err.Error()
+		return check, rows
+	}
+	check.RemoteTotal = remoteTotal
+	check.Diverged = localTotal != remoteTotal
+
+	if int64(len(localItems)) != localTotal || int64(len(remoteItems)) != remoteTotal {
+		return check, rows
+	}
+	check.ItemsCompared = true
+	counts := make(map[string]int, len(localItems))
+	for _, item := range localItems {
+		counts[canonical(item, fields)]++
+	}
+	for _, item := range remoteItems {
+		key := canonical(item, fields)
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		if len(check.Extra) < maxDiffSamples {
+			check.Extra = append(check.Extra, key)
+		}
+		check.Diverged = true
+	}
+	var missing []string
+	for key, n := range counts {
+		if n > 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		check.Diverged = true
+		sort.Strings(missing)
+		check.Missing = missing[:min(len(missing), maxDiffSamples)]
+	}
+	return check, rows
+}
+
+// canonical 把行中的指定字段序列化为规范化 JSON，使两侧不同的数值类型 (如 int64 与 float64) 可以比较
+func canonical(item interface{}, fields []string) string {
+	row, _ := item.(map[string]interface{})
+	subset := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		subset[f] = row[f]
+	}
+	raw, err := json.Marshal(subset)
+	if err != nil {
+		return fmt.Sprint(subset)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return string(raw)
+	}
+	raw, _ = json.Marshal(normalized)
+	return string(raw)
+}
+
+func cloneQuery(q map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(q))
+	for k, v := range q {
+		out[k] = v
+	}
+	return out
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return i
+	}
+	return 0
+}
+
+// localPeer 直接查询本实例的数据源
+type localPeer struct {
+	bizName    string
+	dataSource port.DataSource
+}
+
+func (p *localPeer) query(ctx context.Context, q map[string]interface{}) ([]interface{}, int64, error) {
+	result, err := p.dataSource.Query(ctx, port.QueryRequest{BizName: p.bizName, Query: q})
+	if err != nil {
+		return nil, 0, err
+	}
+	items, _ := result.Data["items"].([]interface{})
+	return items, toInt64(result.Data["total"]), nil
+}
+
+// remotePeer 通过对端网关的数据查询接口查询
+type remotePeer struct {
+	client  *http.Client
+	url     string
+	bizName string
+	token   string
+}
+
+func (p *remotePeer) query(ctx context.Context, q map[string]interface{}) ([]interface{}, int64, error) {
+	body, err := json.Marshal(map[string]interface{}{"biz_name": p.bizName, "query": q})
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+QueryPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var result struct {
+		Data map[string]interface{} `json:"Data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("解析对端响应失败: %w", err)
+	}
+	items, _ := result.Data["items"].([]interface{})
+	return items, toInt64(result.Data["total"]), nil
+}
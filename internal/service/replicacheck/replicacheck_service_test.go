@@ -0,0 +1,157 @@
+// file: internal/service/replicacheck/replicacheck_service_test.go
+package replicacheck
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// letterSource 返回只有 letters 表的内存数据源
+func letterSource(rows []map[string]interface{}) *fixture.FakeDataSource {
+	return fixture.NewFakeDataSource().WithTable("letters", []port.FieldDescription{{Name: "id", IsPrimary: true}}, rows...)
+}
+
+type stubTargets struct {
+	target domain.ReplicationTarget
+}
+
+func (s *stubTargets) Target(_ context.Context, bizName string) (domain.ReplicationTarget, error) {
+	if s.target.BizName != bizName {
+		return domain.ReplicationTarget{}, fmt.Errorf("该业务组未配置同步目标")
+	}
+	return s.target, nil
+}
+
+func letters() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"id": int64(1), "sender": "鲁迅", "place": "北京", "secret": "x"},
+		{"id": int64(2), "sender": "胡适", "place": "上海", "secret": "y"},
+		{"id": int64(3), "sender": "鲁迅", "place": "上海", "secret": "z"},
+	}
+}
+
+func newTestService(t *testing.T, registry map[string]port.DataSource, targets TargetLookup) *Service {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('archive', TRUE, 'letters')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, config.UpdateBizSearchableTables(ctx, "archive", []string{"letters"}))
+	require.NoError(t, config.UpdateTableFieldSettings(ctx, "archive", "letters", []domain.FieldSetting{
+		{FieldName: "id", IsReturnable: true},
+		{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+		{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		{FieldName: "secret", IsSearchable: true},
+	}))
+	svc, err := NewService(config, registry, targets)
+	require.NoError(t, err)
+	return svc
+}
+
+func TestVerify_IdenticalCopies(t *testing.T) {
+	copied := letters()
+	// 数值类型不同但值相同，不可返回的字段不同，都不算差异
+	copied[0]["id"] = float64(1)
+	copied[1]["secret"] = "changed"
+	svc := newTestService(t, map[string]port.DataSource{
+		"archive":      letterSource(letters()),
+		"archive_copy": letterSource(copied),
+	}, nil)
+
+	report, err := svc.Verify(context.Background(), "archive", Request{CompareBiz: "archive_copy", SamplesPerTable: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "biz:archive_copy", report.Against)
+	assert.Equal(t, 3, report.Queries, "第一页加两条抽样查询")
+	assert.Zero(t, report.Diverged)
+	assert.Zero(t, report.Errors)
+	assert.True(t, report.Checks[0].ItemsCompared)
+	assert.Equal(t, int64(3), report.Checks[0].LocalTotal)
+	filters := report.Checks[1].Query["filters"].([]interface{})
+	assert.NotContains(t, []string{"id", "secret"}, filters[0].(map[string]interface{})["field"], "只以可返回且可检索的字段构造查询")
+}
+
+func TestVerify_ReportsDivergence(t *testing.T) {
+	damaged := letters()[:2]
+	damaged[1] = map[string]interface{}{"id": int64(2), "sender": "胡适", "place": "南京"}
+	svc := newTestService(t, map[string]port.DataSource{
+		"archive":      letterSource(letters()),
+		"archive_copy": letterSource(damaged),
+	}, nil)
+
+	report, err := svc.Verify(context.Background(), "archive", Request{CompareBiz: "archive_copy"})
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Checks)
+	first := report.Checks[0]
+	assert.True(t, first.Diverged)
+	assert.Equal(t, int64(3), first.LocalTotal)
+	assert.Equal(t, int64(2), first.RemoteTotal)
+	assert.Len(t, first.Missing, 2)
+	assert.Len(t, first.Extra, 1)
+	assert.Contains(t, first.Extra[0], "南京")
+	assert.Positive(t, report.Diverged)
+}
+
+func TestVerify_RemotePeer(t *testing.T) {
+	var authorization string
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		require.Equal(t, QueryPath, r.URL.Path)
+		var body struct {
+			BizName string                 `json:"biz_name"`
+			Query   map[string]interface{} `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "mirror", body.BizName)
+		result, _ := letterSource(letters()).Query(r.Context(), port.QueryRequest{Query: body.Query})
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer remote.Close()
+
+	targets := &stubTargets{target: domain.ReplicationTarget{BizName: "archive", TargetURL: remote.URL, TargetBiz: "mirror", AuthToken: "token"}}
+	svc := newTestService(t, map[string]port.DataSource{"archive": letterSource(letters())}, targets)
+
+	report, err := svc.Verify(context.Background(), "archive", Request{})
+	require.NoError(t, err)
+	assert.Equal(t, remote.URL+"#mirror", report.Against)
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Zero(t, report.Diverged)
+	assert.Zero(t, report.Errors)
+
+	report, err = svc.Verify(context.Background(), "archive", Request{TargetURL: remote.URL + "/", TargetBiz: "mirror", AuthToken: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer other", authorization)
+	assert.Zero(t, report.Diverged)
+}
+
+func TestVerify_InvalidRequests(t *testing.T) {
+	svc := newTestService(t, map[string]port.DataSource{"archive": letterSource(letters())}, &stubTargets{})
+	ctx := context.Background()
+
+	_, err := svc.Verify(ctx, "missing", Request{CompareBiz: "archive"})
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+	for _, req := range []Request{
+		{},
+		{CompareBiz: "archive"},
+		{CompareBiz: "nowhere"},
+		{TargetURL: "https://mirror.example.org"},
+		{TargetURL: "ftp://mirror", TargetBiz: "b", AuthToken: "t"},
+		{CompareBiz: "archive", SamplesPerTable: maxSamplesPerTable + 1},
+	} {
+		_, err := svc.Verify(ctx, "archive", req)
+		assert.ErrorIs(t, err, ErrInvalidRequest, "%+v", req)
+	}
+}
@@ -0,0 +1,310 @@
+// Package replication file: internal/service/replication/replication_service.go
+package replication
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ApplyPath 是远端网关接收同步变更的接口路径
+	ApplyPath = "/api/v1/admin/replication/apply"
+
+	defaultBatchSize = 200
+)
+
+// ErrTargetNotFound 表示指定业务组尚未配置同步目标
+var ErrTargetNotFound = errors.New("该业务组未配置同步目标")
+
+// Service 负责管理同步目标，并在后台将源业务组的变更日志推送到远端实例。
+type Service struct {
+	db        *sql.DB
+	registry  map[string]port.DataSource
+	client    *http.Client
+	batchSize int
+
+	// syncing 防止同一业务组的定时同步与手动同步并发执行，导致检查点回退
+	syncing   map[string]bool
+	syncingMu sync.Mutex
+}
+
+// NewService 创建一个新的同步服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("replication.Service 需要一个有效的数据库连接")
+	}
+	return &Service{
+		db:        db,
+		registry:  registry,
+		client:    &http.Client{Timeout: 60 * time.Second},
+		batchSize: defaultBatchSize,
+		syncing:   make(map[string]bool),
+	}, nil
+}
+
+// ListTargets 返回所有同步目标及其状态，认证令牌不会被返回
+func (s *Service) ListTargets(ctx context.Context) ([]domain.ReplicationTarget, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT biz_name, target_url, target_biz, enabled, checkpoint, last_synced_at, last_error, pushed_total
+		FROM replication_targets ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询同步目标失败: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make([]domain.ReplicationTarget, 0)
+	for rows.Next() {
+		var t domain.ReplicationTarget
+		var lastSynced sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(&t.BizName, &t.TargetURL, &t.TargetBiz, &t.Enabled, &t.Checkpoint, &lastSynced, &lastError, &t.PushedTotal); err != nil {
+			return nil, fmt.Errorf("扫描同步目标失败: %w", err)
+		}
+		if lastSynced.Valid {
+			t.LastSyncedAt = &lastSynced.Time
+		}
+		t.LastError = lastError.String
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// SaveTarget 创建或更新业务组的同步目标。
+// 目标地址或目标业务组发生变化时检查点会被重置，以便从头全量推送。
+func (s *Service) SaveTarget(ctx context.Context, target domain.ReplicationTarget) error {
+	if target.BizName == "" || target.TargetURL == "" || target.TargetBiz == "" || target.AuthToken == "" {
+		return errors.New("同步目标必须包含 biz_name、target_url、target_biz 和 auth_token")
+	}
+	if !strings.HasPrefix(target.TargetURL, "http://") && !strings.HasPrefix(target.TargetURL, "https://") {
+		return fmt.Errorf("无效的同步目标地址 '%s'", target.TargetURL)
+	}
+	target.TargetURL = strings.TrimRight(target.TargetURL, "/")
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO replication_targets (biz_name, target_url, target_biz, auth_token, enabled, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			checkpoint = CASE WHEN target_url = excluded.target_url AND target_biz = excluded.target_biz THEN checkpoint ELSE '' END,
+			pushed_total = CASE WHEN target_url = excluded.target_url AND target_biz = excluded.target_biz THEN pushed_total ELSE 0 END,
+			target_url = excluded.target_url,
+			target_biz = excluded.target_biz,
+			auth_token = excluded.auth_token,
+			enabled = excluded.enabled,
+			updated_at = CURRENT_TIMESTAMP`,
+		target.BizName, target.TargetURL, target.TargetBiz, target.AuthToken, target.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("保存业务 '%s' 的同步目标失败: %w", target.BizName, err)
+	}
+	return nil
+}
+
+// DeleteTarget 删除业务组的同步目标
+func (s *Service) DeleteTarget(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM replication_targets WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除业务 '%s' 的同步目标失败: %w", bizName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+// Run 以固定间隔同步所有已启用的目标，直到 ctx 被取消
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAll(ctx)
+		}
+	}
+}
+
+func (s *Service) syncAll(ctx context.Context) {
+	targets, err := s.loadTargets(ctx, "")
+	if err != nil {
+		slog.Error("[Replication] 加载同步目标失败", "error", err)
+		return
+	}
+	for _, t := range targets {
+		if !t.Enabled {
+			continue
+		}
+		if _, err := s.syncTarget(ctx, t); err != nil {
+			slog.Warn("[Replication] 同步失败", "biz", t.BizName, "target", t.TargetURL, "error", err)
+		}
+	}
+}
+
+// SyncNow 立即同步指定业务组，返回本次推送的变更条数
+func (s *Service) SyncNow(ctx context.Context, bizName string) (int64, error) {
+	targets, err := s.loadTargets(ctx, bizName)
+	if err != nil {
+		return 0, err
+	}
+	if len(targets) == 0 {
+		return 0, ErrTargetNotFound
+	}
+	return s.syncTarget(ctx, targets[0])
+}
+
+// Target 返回业务组的同步目标，包含认证令牌，仅供网关内部使用
+func (s *Service) Target(ctx context.Context, bizName string) (domain.ReplicationTarget, error) {
+	targets, err := s.loadTargets(ctx, bizName)
+	if err != nil {
+		return domain.ReplicationTarget{}, err
+	}
+	if len(targets) == 0 {
+		return domain.ReplicationTarget{}, ErrTargetNotFound
+	}
+	return targets[0], nil
+}
+
+func (s *Service) loadTargets(ctx context.Context, bizName string) ([]domain.ReplicationTarget, error) {
+	query := `SELECT biz_name, target_url, target_biz, auth_token, enabled, checkpoint FROM replication_targets`
+	var args []interface{}
+	if bizName != "" {
+		query += ` WHERE biz_name = ?`
+		args = append(args, bizName)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询同步目标失败: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []domain.ReplicationTarget
+	for rows.Next() {
+		var t domain.ReplicationTarget
+		if err := rows.Scan(&t.BizName, &t.TargetURL, &t.TargetBiz, &t.AuthToken, &t.Enabled, &t.Checkpoint); err != nil {
+			return nil, fmt.Errorf("扫描同步目标失败: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// syncTarget 从检查点开始分批读取变更并推送到远端，每批成功后立即推进检查点。
+func (s *Service) syncTarget(ctx context.Context, t domain.ReplicationTarget) (pushed int64, err error) {
+	s.syncingMu.Lock()
+	if s.syncing[t.BizName] {
+		s.syncingMu.Unlock()
+		return 0, fmt.Errorf("业务 '%s' 正在同步中", t.BizName)
+	}
+	s.syncing[t.BizName] = true
+	s.syncingMu.Unlock()
+	defer func() {
+		s.syncingMu.Lock()
+		delete(s.syncing, t.BizName)
+		s.syncingMu.Unlock()
+		s.recordStatus(t.BizName, err)
+	}()
+
+	dataSource, exists := s.registry[t.BizName]
+	if !exists {
+		return 0, port.ErrBizNotFound
+	}
+
+	checkpoint := t.Checkpoint
+	for {
+		result, err := dataSource.Query(ctx, port.QueryRequest{
+			BizName: t.BizName,
+			Query: map[string]interface{}{
+				port.QueryModeKey: port.QueryModeChanges,
+				"since":           checkpoint,
+				"limit":           float64(s.batchSize),
+			},
+		})
+		if err != nil {
+			return pushed, fmt.Errorf("读取变更日志失败: %w", err)
+		}
+
+		itemsJSON, err := json.Marshal(result.Data["items"])
+		if err != nil {
+			return pushed, fmt.Errorf("序列化变更失败: %w", err)
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(itemsJSON, &items); err != nil {
+			return pushed, fmt.Errorf("解析变更失败: %w", err)
+		}
+		nextCursor, _ := result.Data["next_cursor"].(string)
+		if len(items) == 0 {
+			return pushed, nil
+		}
+
+		if err := s.push(ctx, t, items); err != nil {
+			return pushed, err
+		}
+		pushed += int64(len(items))
+		checkpoint = nextCursor
+
+		if _, err := s.db.ExecContext(ctx,
+			`UPDATE replication_targets SET checkpoint = ?, pushed_total = pushed_total + ? WHERE biz_name = ?`,
+			checkpoint, len(items), t.BizName,
+		); err != nil {
+			return pushed, fmt.Errorf("保存同步检查点失败: %w", err)
+		}
+
+		if hasMore, _ := result.Data["has_more"].(bool); !hasMore {
+			return pushed, nil
+		}
+	}
+}
+
+func (s *Service) push(ctx context.Context, t domain.ReplicationTarget, items []json.RawMessage) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"biz_name": t.TargetBiz,
+		"changes":  items,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化同步请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.TargetURL+ApplyPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建同步请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.AuthToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("推送变更到 '%s' 失败: %w", t.TargetURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("远端 '%s' 拒绝同步请求, 状态码 %d: %s", t.TargetURL, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (s *Service) recordStatus(bizName string, syncErr error) {
+	var lastError sql.NullString
+	if syncErr != nil {
+		lastError = sql.NullString{String: syncErr.Error(), Valid: true}
+	}
+	if _, err := s.db.Exec(
+		`UPDATE replication_targets SET last_synced_at = CURRENT_TIMESTAMP, last_error = ? WHERE biz_name = ?`,
+		lastError, bizName,
+	); err != nil {
+		slog.Error("[Replication] 记录同步状态失败", "biz", bizName, "error", err)
+	}
+}
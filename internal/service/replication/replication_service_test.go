@@ -0,0 +1,243 @@
+// file: internal/service/replication/replication_service_test.go
+package replication
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replicationFixture 包含一个开启变更捕获的源业务组 library、一个镜像业务组 mirror，
+// 以及模拟远端网关同步接口的 HTTP 服务
+type replicationFixture struct {
+	svc      *Service
+	source   *sqlite.Manager
+	mirrorDB *sql.DB
+
+	mu      sync.Mutex
+	batches [][]interface{}
+}
+
+func newReplicationFixture(t *testing.T) *replicationFixture {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table, change_capture_enabled) VALUES ('library', TRUE, 'books', TRUE), ('mirror', TRUE, 'books', FALSE)`,
+		`INSERT INTO biz_searchable_tables (biz_name, table_name, allow_create, allow_update, allow_delete) VALUES ('library', 'books', TRUE, TRUE, TRUE), ('mirror', 'books', FALSE, FALSE, FALSE)`,
+		`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable) VALUES
+			('library', 'books', 'title', TRUE, TRUE), ('library', 'books', 'author', TRUE, TRUE),
+			('mirror', 'books', 'title', TRUE, TRUE), ('mirror', 'books', 'author', TRUE, TRUE)`,
+	} {
+		_, err := sysDB.Exec(stmt)
+		require.NoError(t, err)
+	}
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+
+	newManager := func(bizName string) (*sqlite.Manager, *sql.DB) {
+		root := t.TempDir()
+		bizDir := filepath.Join(root, bizName)
+		require.NoError(t, os.Mkdir(bizDir, 0o755))
+		libDB, err := sql.Open("sqlite", filepath.Join(bizDir, "main.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { libDB.Close() })
+		_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, author TEXT)`)
+		require.NoError(t, err)
+		manager := sqlite.NewManager(config)
+		require.NoError(t, manager.InitForBiz(ctx, root, bizName))
+		t.Cleanup(func() { _ = manager.Close() })
+		return manager, libDB
+	}
+	source, _ := newManager("library")
+	mirror, mirrorDB := newManager("mirror")
+
+	f := &replicationFixture{source: source, mirrorDB: mirrorDB}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ApplyPath || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		var body struct {
+			BizName string        `json:"biz_name"`
+			Changes []interface{} `json:"changes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.BizName != "mirror" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.batches = append(f.batches, body.Changes)
+		f.mu.Unlock()
+		if _, err := port.AdminMutate(r.Context(), mirror, port.MutateRequest{
+			BizName:   body.BizName,
+			Operation: port.MutateOpReplicate,
+			Payload:   map[string]interface{}{"changes": body.Changes},
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	f.svc, err = NewService(sysDB, map[string]port.DataSource{"library": source})
+	require.NoError(t, err)
+	f.svc.batchSize = 2
+	require.NoError(t, f.svc.SaveTarget(ctx, domain.ReplicationTarget{
+		BizName: "library", TargetURL: server.URL, TargetBiz: "mirror", AuthToken: "secret", Enabled: true,
+	}))
+	return f
+}
+
+func (f *replicationFixture) mutate(t *testing.T, op string, payload map[string]interface{}) {
+	t.Helper()
+	payload["table_name"] = "books"
+	_, err := f.source.Mutate(context.Background(), port.MutateRequest{BizName: "library", Operation: op, Payload: payload})
+	require.NoError(t, err)
+}
+
+func (f *replicationFixture) mirrorTitles(t *testing.T) map[int64]string {
+	t.Helper()
+	rows, err := f.mirrorDB.Query(`SELECT id, title FROM books`)
+	require.NoError(t, err)
+	defer rows.Close()
+	titles := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var title string
+		require.NoError(t, rows.Scan(&id, &title))
+		titles[id] = title
+	}
+	require.NoError(t, rows.Err())
+	return titles
+}
+
+func (f *replicationFixture) target(t *testing.T) domain.ReplicationTarget {
+	t.Helper()
+	targets, err := f.svc.ListTargets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	return targets[0]
+}
+
+func byID(id int) []interface{} {
+	return []interface{}{map[string]interface{}{"field": "id", "value": id}}
+}
+
+func TestNewServiceRequiresDB(t *testing.T) {
+	_, err := NewService(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestSyncNowAppliesChanges(t *testing.T) {
+	ctx := context.Background()
+	f := newReplicationFixture(t)
+
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 1, "title": "围城", "author": "钱钟书"}})
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 2, "title": "边城", "author": "沈从文"}})
+	f.mutate(t, "update", map[string]interface{}{"data": map[string]interface{}{"title": "围城 (修订)"}, "filters": byID(1)})
+	f.mutate(t, "delete", map[string]interface{}{"filters": byID(2)})
+
+	pushed, err := f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, pushed)
+	// 批大小为 2，4 条变更分两批推送
+	assert.Len(t, f.batches, 2)
+	assert.Equal(t, map[int64]string{1: "围城 (修订)"}, f.mirrorTitles(t))
+
+	target := f.target(t)
+	assert.NotEmpty(t, target.Checkpoint)
+	assert.EqualValues(t, 4, target.PushedTotal)
+	assert.Empty(t, target.LastError)
+	assert.NotNil(t, target.LastSyncedAt)
+
+	// 没有新变更时不会再推送
+	pushed, err = f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	assert.Zero(t, pushed)
+	assert.Len(t, f.batches, 2)
+}
+
+func TestSyncNowReapplyIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	f := newReplicationFixture(t)
+
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 1, "title": "围城", "author": "钱钟书"}})
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 2, "title": "边城", "author": "沈从文"}})
+	f.mutate(t, "delete", map[string]interface{}{"filters": byID(2)})
+	_, err := f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	require.Equal(t, map[int64]string{1: "围城"}, f.mirrorTitles(t))
+
+	// 远端已应用但检查点未保存时，下次会从旧检查点重放同一批变更，镜像结果必须不变
+	_, err = f.svc.db.ExecContext(ctx, `UPDATE replication_targets SET checkpoint = '' WHERE biz_name = 'library'`)
+	require.NoError(t, err)
+	pushed, err := f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, pushed)
+	assert.Equal(t, map[int64]string{1: "围城"}, f.mirrorTitles(t))
+	assert.EqualValues(t, 6, f.target(t).PushedTotal)
+}
+
+func TestSyncNowResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	f := newReplicationFixture(t)
+
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 1, "title": "围城", "author": "钱钟书"}})
+	_, err := f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	checkpoint := f.target(t).Checkpoint
+	require.NotEmpty(t, checkpoint)
+
+	f.mutate(t, "update", map[string]interface{}{"data": map[string]interface{}{"title": "围城 (修订)"}, "filters": byID(1)})
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 3, "title": "家", "author": "巴金"}})
+	f.batches = nil
+
+	pushed, err := f.svc.SyncNow(ctx, "library")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, pushed)
+	require.Len(t, f.batches, 1)
+	ops := make([]string, 0, 2)
+	for _, item := range f.batches[0] {
+		ops = append(ops, item.(map[string]interface{})["op"].(string))
+	}
+	assert.Equal(t, []string{"update", "create"}, ops, "只应推送检查点之后的变更")
+	assert.Equal(t, map[int64]string{1: "围城 (修订)", 3: "家"}, f.mirrorTitles(t))
+	assert.NotEqual(t, checkpoint, f.target(t).Checkpoint)
+}
+
+func TestSyncNowFailureKeepsCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	f := newReplicationFixture(t)
+
+	f.mutate(t, "create", map[string]interface{}{"data": map[string]interface{}{"id": 1, "title": "围城", "author": "钱钟书"}})
+	require.NoError(t, f.svc.SaveTarget(ctx, domain.ReplicationTarget{
+		BizName: "library", TargetURL: "http://127.0.0.1:1", TargetBiz: "mirror", AuthToken: "secret", Enabled: true,
+	}))
+	_, err := f.svc.SyncNow(ctx, "library")
+	assert.Error(t, err)
+
+	target := f.target(t)
+	assert.Empty(t, target.Checkpoint)
+	assert.Zero(t, target.PushedTotal)
+	assert.NotEmpty(t, target.LastError)
+
+	_, err = f.svc.SyncNow(ctx, "missing")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+}
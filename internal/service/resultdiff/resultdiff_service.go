@@ -0,0 +1,347 @@
+// Package resultdiff file: internal/service/resultdiff/resultdiff_service.go
+package resultdiff
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultMaxRows  = 100000
+	fetchPageSize   = 1000
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+var (
+	// ErrInvalidRequest 表示比对请求的参数不完整或不合法
+	ErrInvalidRequest = errors.New("无效的结果比对请求")
+	// ErrTooManyRows 表示某一侧的结果超过了允许比对的行数
+	ErrTooManyRows = errors.New("结果行数超过比对上限")
+)
+
+// unsupportedQueryKeys 是不能用于比对的查询键：这些模式的结果不是表中的原始记录
+var unsupportedQueryKeys = []string{
+	port.QueryModeKey, port.QuerySampleKey, port.QueryDistinctKey, port.QueryCollapseKey, port.QueryTimelineKey,
+}
+
+// Options 定义结果比对的配置
+type Options struct {
+	// MaxRows 是每一侧结果允许比对的最大行数，默认为 100000
+	MaxRows int `mapstructure:"max_rows"`
+}
+
+// Side 是比对的一侧：业务组与查询 (与 /data/query 的 query 格式相同)
+type Side struct {
+	BizName string                 `json:"biz_name"`
+	Query   map[string]interface{} `json:"query"`
+}
+
+// Request 是一次结果比对。After.Query 省略时与 Before 使用相同的查询；
+// Key 省略时使用 After 一侧数据源报告的主键
+type Request struct {
+	Before Side     `json:"before"`
+	After  Side     `json:"after"`
+	Key    []string `json:"key"`
+	Page   int      `json:"page"`
+	Size   int      `json:"size"`
+}
+
+// Service 在两个业务组上执行查询，读取全部结果后按键字段对应，返回新增、删除与变化的行。
+// 用于核对迁移结果，或与从快照恢复的业务组比较，追踪馆藏的变化
+type Service struct {
+	registry map[string]port.DataSource
+	opts     Options
+}
+
+// NewService 创建一个新的结果比对服务实例
+func NewService(registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if registry == nil {
+		return nil, errors.New("resultdiff.Service 需要有效的数据源注册表")
+	}
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = defaultMaxRows
+	}
+	return &Service{registry: registry, opts: opts}, nil
+}
+
+// Diff 执行比对并返回第 req.Page 页的差异行
+func (s *Service) Diff(ctx context.Context, req Request) (*domain.ResultDiff, error) {
+	if req.Before.BizName == "" || req.After.BizName == "" {
+		return nil, fmt.Errorf("%w: before 与 after 都必须指定 biz_name", ErrInvalidRequest)
+	}
+	if req.Before.Query == nil {
+		return nil, fmt.Errorf("%w: before 必须指定 query", ErrInvalidRequest)
+	}
+	if req.After.Query == nil {
+		req.After.Query = req.Before.Query
+	}
+	table, _ := req.Before.Query["table"].(string)
+	afterTable, _ := req.After.Query["table"].(string)
+	if table == "" || afterTable == "" {
+		return nil, fmt.Errorf("%w: query 必须包含 'table'", ErrInvalidRequest)
+	}
+	for _, q := range []map[string]interface{}{req.Before.Query, req.After.Query} {
+		for _, key := range unsupportedQueryKeys {
+			if _, ok := q[key]; ok {
+				return nil, fmt.Errorf("%w: 比对不支持查询键 '%s'", ErrInvalidRequest, key)
+			}
+		}
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = defaultPageSize
+	}
+	req.Size = min(req.Size, maxPageSize)
+
+	before, ok := s.registry[req.Before.BizName]
+	if !ok {
+		return nil, fmt.Errorf("%w: 业务组 '%s'", port.ErrBizNotFound, req.Before.BizName)
+	}
+	after, ok := s.registry[req.After.BizName]
+	if !ok {
+		return nil, fmt.Errorf("%w: 业务组 '%s'", port.ErrBizNotFound, req.After.BizName)
+	}
+	key := req.Key
+	if len(key) == 0 {
+		schema, err := after.GetSchema(ctx, port.SchemaRequest{BizName: req.After.BizName, TableName: afterTable})
+		if err != nil {
+			return nil, fmt.Errorf("读取表 '%s' 的主键失败: %w", afterTable, err)
+		}
+		for _, f := range schema.Tables[afterTable] {
+			if f.IsPrimary {
+				key = append(key, f.Name)
+			}
+		}
+		if len(key) == 0 {
+			return nil, fmt.Errorf("%w: 表 '%s' 没有主键，请通过 key 指定用于对应行的字段", ErrInvalidRequest, afterTable)
+		}
+	}
+
+	beforeRows, err := s.fetchAll(ctx, before, req.Before.BizName, req.Before.Query, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取 before 一侧的结果失败: %w", err)
+	}
+	afterRows, err := s.fetchAll(ctx, after, req.After.BizName, req.After.Query, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取 after 一侧的结果失败: %w", err)
+	}
+
+	entries, summary, err := diffRows(beforeRows, afterRows, key)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.ResultDiff{
+		Before:  req.Before.BizName,
+		After:   req.After.BizName,
+		Table:   afterTable,
+		Key:     key,
+		Summary: summary,
+		Entries: []domain.DiffEntry{},
+		Page:    req.Page,
+		Size:    req.Size,
+	}
+	if start := (req.Page - 1) * req.Size; start < len(entries) {
+		result.Entries = entries[start:min(start+req.Size, len(entries))]
+	}
+	return result, nil
+}
+
+// fetchAll 逐页读取查询的全部结果。键字段总是被加入返回字段，查询要求全部库成功，
+// 否则缺失的库会被误判为删除
+func (s *Service) fetchAll(ctx context.Context, ds port.DataSource, bizName string, query map[string]interface{}, key []string) ([]map[string]interface{}, error) {
+	q := make(map[string]interface{}, len(query)+3)
+	for k, v := range query {
+		q[k] = v
+	}
+	if fields, ok := q["fields_to_return"].([]interface{}); ok && len(fields) > 0 {
+		merged := append([]interface{}{}, fields...)
+		for _, k := range key {
+			found := false
+			for _, f := range fields {
+				if f == k {
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged = append(merged, k)
+			}
+		}
+		q["fields_to_return"] = merged
+	}
+	q[port.QueryStrictKey] = true
+	q["size"] = float64(fetchPageSize)
+
+	var rows []map[string]interface{}
+	for page := 1; ; page++ {
+		q["page"] = float64(page)
+		result, err := ds.Query(ctx, port.QueryRequest{BizName: bizName, Query: q})
+		if err != nil {
+			return nil, err
+		}
+		if truncated, _ := result.Data[port.ResultTruncatedKey].(bool); truncated {
+			return nil, fmt.Errorf("%w: 业务组 '%s' 的结果因大小限制被截断", ErrTooManyRows, bizName)
+		}
+		items, _ := result.Data["items"].([]interface{})
+		for _, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) > s.opts.MaxRows {
+			return nil, fmt.Errorf("%w: 业务组 '%s' 的结果超过 %d 行，请缩小查询范围", ErrTooManyRows, bizName, s.opts.MaxRows)
+		}
+		if len(items) < fetchPageSize || int64(len(rows)) >= toInt64(result.Data["total"]) {
+			return rows, nil
+		}
+	}
+}
+
+// diffRows 按键字段对应两侧的行，返回按键排序的差异行与统计
+func diffRows(before, after []map[string]interface{}, key []string) ([]domain.DiffEntry, domain.DiffSummary, error) {
+	summary := domain.DiffSummary{BeforeRows: len(before), AfterRows: len(after)}
+	index := func(rows []map[string]interface{}, side string) (map[string]map[string]interface{}, error) {
+		out := make(map[string]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			k, err := rowKey(row, key)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := out[k]; dup {
+				return nil, fmt.Errorf("%w: %s 一侧存在键相同的多行 %s，键字段 %v 不唯一", ErrInvalidRequest, side, k, key)
+			}
+			out[k] = stripInternal(row)
+		}
+		return out, nil
+	}
+	beforeIndex, err := index(before, "before")
+	if err != nil {
+		return nil, summary, err
+	}
+	afterIndex, err := index(after, "after")
+	if err != nil {
+		return nil, summary, err
+	}
+
+	var keys []string
+	for k := range beforeIndex {
+		keys = append(keys, k)
+	}
+	for k := range afterIndex {
+		if _, ok := beforeIndex[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]domain.DiffEntry, 0)
+	for _, k := range keys {
+		b, inBefore := beforeIndex[k]
+		a, inAfter := afterIndex[k]
+		switch {
+		case !inBefore:
+			summary.Added++
+			entries = append(entries, domain.DiffEntry{Status: domain.DiffAdded, Key: keyValues(a, key), After: a})
+		case !inAfter:
+			summary.Removed++
+			entries = append(entries, domain.DiffEntry{Status: domain.DiffRemoved, Key: keyValues(b, key), Before: b})
+		default:
+			if fields := changedFields(b, a); len(fields) > 0 {
+				summary.Changed++
+				entries = append(entries, domain.DiffEntry{Status: domain.DiffChanged, Key: keyValues(a, key), Before: b, After: a, Fields: fields})
+			} else {
+				summary.Unchanged++
+			}
+		}
+	}
+	summary.Total = len(entries)
+	return entries, summary, nil
+}
+
+// rowKey 把行的键字段值序列化为可比较的字符串，数值类型不同 (int64 与 float64) 的同一取值得到相同的键
+func rowKey(row map[string]interface{}, key []string) (string, error) {
+	values := make([]interface{}, len(key))
+	for i, k := range key {
+		v, ok := row[k]
+		if !ok {
+			return "", fmt.Errorf("%w: 结果中没有键字段 '%s' (字段不存在或不可返回)", ErrInvalidRequest, k)
+		}
+		values[i] = v
+	}
+	return canonical(values), nil
+}
+
+func keyValues(row map[string]interface{}, key []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(key))
+	for _, k := range key {
+		out[k] = row[k]
+	}
+	return out
+}
+
+// stripInternal 去掉数据源附加的内部字段 (来源库、得分等)，它们不属于记录本身
+func stripInternal(row map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		if strings.HasPrefix(k, "__") || k == port.ResultScoreField || k == port.ResultGroupCountField {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// changedFields 返回两行中取值不同的字段，只出现在一侧的字段也视为不同
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	for k, v := range before {
+		if w, ok := after[k]; !ok || canonical(v) != canonical(w) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// canonical 把值序列化为规范化 JSON，使两侧不同的数值类型可以比较
+func canonical(v interface{}) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return string(raw)
+	}
+	raw, _ = json.Marshal(normalized)
+	return string(raw)
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+// file: internal/service/resultdiff/resultdiff_service_test.go
+package resultdiff
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLetters 返回一个只有 letters 表、以 id 为主键的内存数据源
+func newLetters(rows ...map[string]interface{}) *fixture.FakeDataSource {
+	fields := []port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}, {Name: "place"}}
+	return fixture.NewFakeDataSource().WithTable("letters", fields, rows...)
+}
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	snapshot := newLetters(
+		map[string]interface{}{"id": int64(1), "sender": "鲁迅", "place": "北京", "__source": "a.db"},
+		map[string]interface{}{"id": int64(2), "sender": "胡适", "place": "上海"},
+		map[string]interface{}{"id": int64(3), "sender": "鲁迅", "place": "上海"},
+	)
+	current := newLetters(
+		map[string]interface{}{"id": 1.0, "sender": "鲁迅", "place": "北京", "__source": "b.db"},
+		map[string]interface{}{"id": 3.0, "sender": "鲁迅", "place": "广州"},
+		map[string]interface{}{"id": 4.0, "sender": "鲁迅", "place": "厦门"},
+	)
+	svc, err := NewService(map[string]port.DataSource{"letters_2024": snapshot, "letters": current}, Options{})
+	require.NoError(t, err)
+
+	req := Request{
+		Before: Side{BizName: "letters_2024", Query: map[string]interface{}{"table": "letters", "page": 3.0}},
+		After:  Side{BizName: "letters"},
+		Size:   2,
+	}
+	diff, err := svc.Diff(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, diff.Key, "未指定 key 时使用主键")
+	assert.Equal(t, domain.DiffSummary{BeforeRows: 3, AfterRows: 3, Added: 1, Removed: 1, Changed: 1, Unchanged: 1, Total: 3}, diff.Summary)
+	require.Len(t, diff.Entries, 2)
+	assert.Equal(t, domain.DiffRemoved, diff.Entries[0].Status)
+	assert.Equal(t, int64(2), diff.Entries[0].Key["id"])
+	assert.Equal(t, domain.DiffChanged, diff.Entries[1].Status)
+	assert.Equal(t, []string{"place"}, diff.Entries[1].Fields, "内部字段不参与比较")
+
+	req.Page = 2
+	diff, err = svc.Diff(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, diff.Entries, 1)
+	assert.Equal(t, domain.DiffAdded, diff.Entries[0].Status)
+	assert.Equal(t, "厦门", diff.Entries[0].After["place"])
+
+	queries := current.Queries()
+	last := queries[len(queries)-1].Query
+	assert.Equal(t, true, last[port.QueryStrictKey], "比对要求全部库成功")
+	assert.Equal(t, 1.0, last["page"], "请求中的 page 不影响读取")
+
+	// 显式指定 key 时不要求主键，键不唯一则拒绝
+	req.Key = []string{"sender"}
+	_, err = svc.Diff(ctx, req)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestDiff_Limits(t *testing.T) {
+	ctx := context.Background()
+	var rows []map[string]interface{}
+	for i := 0; i < 2500; i++ {
+		rows = append(rows, map[string]interface{}{"id": int64(i), "sender": "鲁迅"})
+	}
+	ds := newLetters(rows...)
+	registry := map[string]port.DataSource{"letters": ds}
+
+	svc, err := NewService(registry, Options{})
+	require.NoError(t, err)
+	query := map[string]interface{}{"table": "letters", "fields_to_return": []interface{}{"sender"}}
+	diff, err := svc.Diff(ctx, Request{Before: Side{BizName: "letters", Query: query}, After: Side{BizName: "letters"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2500, diff.Summary.Unchanged, "分页读取全部结果")
+	assert.Empty(t, diff.Entries)
+	assert.Equal(t, []interface{}{"sender", "id"}, ds.Queries()[0].Query["fields_to_return"], "键字段被加入返回字段")
+
+	svc, err = NewService(registry, Options{MaxRows: 2000})
+	require.NoError(t, err)
+	_, err = svc.Diff(ctx, Request{Before: Side{BizName: "letters", Query: query}, After: Side{BizName: "letters"}})
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	for name, req := range map[string]Request{
+		"缺少 table": {Before: Side{BizName: "letters", Query: map[string]interface{}{}}, After: Side{BizName: "letters"}},
+		"不支持的模式":   {Before: Side{BizName: "letters", Query: map[string]interface{}{"table": "letters", port.QueryDistinctKey: true}}, After: Side{BizName: "letters"}},
+		"缺少业务组":    {Before: Side{Query: query}, After: Side{BizName: "letters"}},
+	} {
+		_, err := svc.Diff(ctx, req)
+		assert.ErrorIs(t, err, ErrInvalidRequest, name)
+	}
+	_, err = svc.Diff(ctx, Request{Before: Side{BizName: "missing", Query: query}, After: Side{BizName: "letters"}})
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
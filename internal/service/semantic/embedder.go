@@ -0,0 +1,242 @@
+// Package semantic file: internal/service/semantic/embedder.go
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ProviderHashing 以特征哈希在进程内计算向量，无需外部服务，但只能捕捉字面上的相似
+	ProviderHashing = "hashing"
+	// ProviderHTTP 调用兼容 OpenAI embeddings 接口的外部服务计算向量
+	ProviderHTTP = "http"
+
+	defaultHashingDimensions = 256
+	defaultHTTPTimeout       = 30 * time.Second
+)
+
+// Embedder 把文本转换为向量
+type Embedder interface {
+	// Model 标识向量所属的模型，不同模型的向量不可比较
+	Model() string
+	// Embed 按顺序返回每条文本的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderOptions 定义嵌入模型的配置
+type EmbedderOptions struct {
+	// Provider 为 "hashing" (默认) 或 "http"
+	Provider string `mapstructure:"provider"`
+	// Endpoint 是 http 模式下 embeddings 接口的完整地址
+	Endpoint string `mapstructure:"endpoint"`
+	// Model 是 http 模式下请求的模型名
+	Model string `mapstructure:"model"`
+	// APIKeyEnv 是保存 API Key 的环境变量名，为空时不发送 Authorization 头
+	APIKeyEnv string `mapstructure:"api_key_env"`
+	// Dimensions 是 hashing 模式的向量维度，默认为 256
+	Dimensions int `mapstructure:"dimensions"`
+	// TimeoutSeconds 是 http 模式单次请求的超时，默认为 30 秒
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+}
+
+// NewEmbedder 按配置创建嵌入模型
+func NewEmbedder(opts EmbedderOptions) (Embedder, error) {
+	switch opts.Provider {
+	case "", ProviderHashing:
+		if opts.Dimensions < 0 {
+			return nil, errors.New("语义检索的向量维度不能为负数")
+		}
+		dims := opts.Dimensions
+		if dims == 0 {
+			dims = defaultHashingDimensions
+		}
+		return &hashingEmbedder{dims: dims}, nil
+	case ProviderHTTP:
+		if opts.Endpoint == "" || opts.Model == "" {
+			return nil, errors.New("http 嵌入模型需要配置 endpoint 与 model")
+		}
+		timeout := defaultHTTPTimeout
+		if opts.TimeoutSeconds > 0 {
+			timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+		}
+		apiKey := ""
+		if opts.APIKeyEnv != "" {
+			apiKey = os.Getenv(opts.APIKeyEnv)
+		}
+		return &httpEmbedder{
+			endpoint: opts.Endpoint,
+			model:    opts.Model,
+			apiKey:   apiKey,
+			client:   &http.Client{Timeout: timeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("未知的嵌入模型提供方 '%s'", opts.Provider)
+	}
+}
+
+// hashingEmbedder 把词与字符 n-gram 哈希到固定维度并归一化。
+// 拉丁文本取整词与首尾补位的三元组，汉字取单字与相邻二元组，以容忍拼写差异与中文不分词的情况。
+type hashingEmbedder struct {
+	dims int
+}
+
+func (e *hashingEmbedder) Model() string {
+	return fmt.Sprintf("hashing-%d", e.dims)
+}
+
+func (e *hashingEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = e.embed(text)
+	}
+	return out, nil
+}
+
+func (e *hashingEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dims)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		// 以哈希的最高位决定符号，减小冲突带来的偏差
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vec[sum%uint64(e.dims)] += weight
+	}
+	for _, word := range splitWords(text) {
+		runes := []rune(word)
+		if isHan(runes[0]) {
+			for i, r := range runes {
+				add(string(r), 1)
+				if i > 0 {
+					add(string(runes[i-1:i+1]), 1)
+				}
+			}
+			continue
+		}
+		add("w:"+word, 1)
+		padded := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			add(string(padded[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec
+}
+
+// splitWords 把文本切分为小写的字母数字串与连续的汉字串
+func splitWords(text string) []string {
+	var words []string
+	var cur []rune
+	han := false
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = cur[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isHan(r):
+			if !han {
+				flush()
+			}
+			han = true
+			cur = append(cur, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if han {
+				flush()
+			}
+			han = false
+			cur = append(cur, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// httpEmbedder 调用兼容 OpenAI embeddings 接口的服务
+type httpEmbedder struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+func (e *httpEmbedder) Model() string {
+	return e.model
+}
+
+func (e *httpEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建嵌入请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求嵌入服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("嵌入服务返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var payload struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("解析嵌入服务响应失败: %w", err)
+	}
+	if len(payload.Data) != len(texts) {
+		return nil, fmt.Errorf("嵌入服务返回了 %d 个向量，期望 %d 个", len(payload.Data), len(texts))
+	}
+	out := make([][]float32, len(texts))
+	for _, item := range payload.Data {
+		if item.Index < 0 || item.Index >= len(texts) || out[item.Index] != nil {
+			return nil, fmt.Errorf("嵌入服务返回了无效的序号 %d", item.Index)
+		}
+		out[item.Index] = item.Embedding
+	}
+	return out, nil
+}
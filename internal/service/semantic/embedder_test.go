@@ -0,0 +1,77 @@
+// file: internal/service/semantic/embedder_test.go
+package semantic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	embedder, err := NewEmbedder(EmbedderOptions{Dimensions: 64})
+	require.NoError(t, err)
+	assert.Equal(t, "hashing-64", embedder.Model())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"Old fishermen at sea", "an old fisherman on the sea", "鲁迅全集", "鲁迅文集", ""})
+	require.NoError(t, err)
+	require.Len(t, vectors, 5)
+	assert.Len(t, vectors[0], 64)
+	assert.InDelta(t, 1, cosine(vectors[0], vectors[0]), 1e-5, "向量已归一化")
+	assert.Greater(t, cosine(vectors[0], vectors[1]), cosine(vectors[0], vectors[2]), "字面相近的文本更相似")
+	assert.Greater(t, cosine(vectors[2], vectors[3]), 0.5)
+	assert.Zero(t, cosine(vectors[4], vectors[4]), "空文本为零向量")
+
+	_, err = NewEmbedder(EmbedderOptions{Provider: "unknown"})
+	assert.Error(t, err)
+	_, err = NewEmbedder(EmbedderOptions{Provider: ProviderHTTP})
+	assert.Error(t, err, "http 模式必须配置 endpoint 与 model")
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	t.Setenv("TEST_EMBEDDING_KEY", "secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "text-embed", body.Model)
+		// 故意倒序返回，客户端应按 index 归位
+		data := make([]map[string]interface{}, 0, len(body.Input))
+		for i := len(body.Input) - 1; i >= 0; i-- {
+			data = append(data, map[string]interface{}{"index": i, "embedding": []float32{float32(len(body.Input[i])), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(EmbedderOptions{Provider: ProviderHTTP, Endpoint: server.URL, Model: "text-embed", APIKeyEnv: "TEST_EMBEDDING_KEY"})
+	require.NoError(t, err)
+	assert.Equal(t, "text-embed", embedder.Model())
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 1}, {3, 1}}, vectors)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	embedder, err = NewEmbedder(EmbedderOptions{Provider: ProviderHTTP, Endpoint: failing.URL, Model: "text-embed"})
+	require.NoError(t, err)
+	_, err = embedder.Embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "quota exceeded")
+}
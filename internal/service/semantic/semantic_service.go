@@ -0,0 +1,435 @@
+// Package semantic file: internal/service/semantic/semantic_service.go
+package semantic
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// JobKindIndex 是语义索引重建任务的类型，任务目标为表名
+	JobKindIndex = "semantic_index"
+
+	defaultBatchSize = 64
+	defaultTopK      = 10
+	maxTopK          = 100
+)
+
+var (
+	// ErrInvalidConfig 表示语义检索配置不合法
+	ErrInvalidConfig = errors.New("无效的语义检索配置")
+	// ErrInvalidQuery 表示语义检索请求不合法
+	ErrInvalidQuery = errors.New("无效的语义检索请求")
+	// ErrNotConfigured 表示表未配置语义检索
+	ErrNotConfigured = errors.New("该表未配置语义检索")
+	// ErrIndexOutdated 表示语义索引尚未建立，或由与当前配置不同的模型建立，需要重建
+	ErrIndexOutdated = errors.New("语义索引尚未以当前模型建立，请重建索引")
+)
+
+// Options 定义语义检索的配置
+type Options struct {
+	Enabled  bool            `mapstructure:"enabled"`
+	Embedder EmbedderOptions `mapstructure:"embedder"`
+	// BatchSize 是重建索引时每批读取并向量化的记录数，默认为 64
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// Service 管理各表的语义检索配置，以后台任务的形式为描述字段计算向量并写入数据源，
+// 并把检索文本向量化后交由数据源做相似度检索。向量在网关计算，数据源只需实现 port.VectorSearcher。
+type Service struct {
+	db        *sql.DB
+	config    port.QueryAdminConfigService
+	registry  map[string]port.DataSource
+	jobs      *jobs.Service
+	embedder  Embedder
+	batchSize int
+}
+
+// NewService 创建一个新的语义检索服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, jobService *jobs.Service, opts Options) (*Service, error) {
+	if db == nil || config == nil || jobService == nil {
+		return nil, errors.New("semantic.Service 需要有效的数据库连接、配置服务与后台任务服务")
+	}
+	if opts.BatchSize < 0 {
+		return nil, errors.New("语义检索的 batch_size 不能为负数")
+	}
+	embedder, err := NewEmbedder(opts.Embedder)
+	if err != nil {
+		return nil, err
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Service{
+		db:        db,
+		config:    config,
+		registry:  registry,
+		jobs:      jobService,
+		embedder:  embedder,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Model 返回当前配置的嵌入模型
+func (s *Service) Model() string {
+	return s.embedder.Model()
+}
+
+// SaveConfig 校验并保存表的语义检索配置，随后启动重建任务。
+// 参与向量化的字段必须是可返回字段，以免通过相似度推断出不公开的内容。
+// 该表已有重建任务在运行时不再启动新任务，运行中的任务会在完成前发现配置变更并按新配置重新开始。
+func (s *Service) SaveConfig(ctx context.Context, bizName, tableName string, fields []string, userID int64) (*domain.SemanticSearchConfig, *domain.BackgroundJob, error) {
+	tableConfig, err := s.tableConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var normalized []string
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(normalized, field) {
+			continue
+		}
+		setting, exists := tableConfig.Fields[field]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: 字段 '%s' 不在表 '%s' 的配置中", ErrInvalidConfig, field, tableName)
+		}
+		if !setting.IsReturnable {
+			return nil, nil, fmt.Errorf("%w: 字段 '%s' 不可返回，不能参与语义检索", ErrInvalidConfig, field)
+		}
+		normalized = append(normalized, field)
+	}
+	if len(normalized) == 0 {
+		return nil, nil, fmt.Errorf("%w: 至少需要一个字段", ErrInvalidConfig)
+	}
+
+	fieldsJSON, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO biz_semantic_search (biz_name, table_name, fields_json, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(biz_name, table_name) DO UPDATE SET fields_json = excluded.fields_json, updated_at = excluded.updated_at`,
+		bizName, tableName, string(fieldsJSON), time.Now().UTC()); err != nil {
+		return nil, nil, fmt.Errorf("保存语义检索配置失败: %w", err)
+	}
+	cfg, err := s.GetConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := s.startOrJoin(ctx, bizName, tableName, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, job, nil
+}
+
+// DeleteConfig 删除表的语义检索配置及数据源中已保存的向量
+func (s *Service) DeleteConfig(ctx context.Context, bizName, tableName string) error {
+	cfg, err := s.GetConfig(ctx, bizName, tableName)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return ErrNotConfigured
+	}
+	searcher, err := s.vectorSearcher(bizName)
+	if err != nil {
+		return err
+	}
+	if _, err := searcher.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{BizName: bizName, TableName: tableName, DropAll: true}); err != nil {
+		return fmt.Errorf("删除表 '%s' 的向量失败: %w", tableName, err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM biz_semantic_search WHERE biz_name = ? AND table_name = ?`, bizName, tableName); err != nil {
+		return fmt.Errorf("删除语义检索配置失败: %w", err)
+	}
+	return nil
+}
+
+// Rebuild 按表当前的配置启动重建任务。已有重建任务在运行时返回 jobs.ErrJobConflict。
+func (s *Service) Rebuild(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	if _, err := s.tableConfig(ctx, bizName, tableName); err != nil {
+		return nil, err
+	}
+	cfg, err := s.GetConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, ErrNotConfigured
+	}
+	searcher, err := s.vectorSearcher(bizName)
+	if err != nil {
+		return nil, err
+	}
+	return s.jobs.Start(ctx, JobKindIndex, bizName, tableName, userID, func(ctx context.Context, progress *jobs.Progress) error {
+		return s.run(ctx, searcher, bizName, tableName, progress)
+	})
+}
+
+func (s *Service) startOrJoin(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	job, err := s.Rebuild(ctx, bizName, tableName, userID)
+	if errors.Is(err, jobs.ErrJobConflict) {
+		return s.jobs.FindActive(ctx, JobKindIndex, bizName, tableName)
+	}
+	return job, err
+}
+
+// run 是重建任务的执行体。每轮以新的轮次号分页读取文本、向量化并写入，结束前再次读取配置：
+// 字段在此期间被修改时按新配置重新开始，配置被删除时清除全部向量；否则删除旧轮次遗留的向量。
+func (s *Service) run(ctx context.Context, searcher port.VectorSearcher, bizName, tableName string, progress *jobs.Progress) error {
+	model := s.embedder.Model()
+	for {
+		cfg, err := s.GetConfig(ctx, bizName, tableName)
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			_, err := searcher.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{BizName: bizName, TableName: tableName, DropAll: true})
+			return err
+		}
+
+		generation := time.Now().UnixNano()
+		progress.Reset(0)
+		progress.SetMessage(fmt.Sprintf("正在以模型 '%s' 向量化字段 %s", model, strings.Join(cfg.Fields, ", ")))
+		indexed, err := s.indexAll(ctx, searcher, bizName, tableName, cfg.Fields, model, generation, progress)
+		if err != nil {
+			return err
+		}
+
+		latest, err := s.GetConfig(ctx, bizName, tableName)
+		if err != nil {
+			return err
+		}
+		if latest == nil || !slices.Equal(latest.Fields, cfg.Fields) {
+			slog.Info("[Semantic] 重建期间配置已变更，重新开始", "biz", bizName, "table", tableName)
+			continue
+		}
+
+		res, err := searcher.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{
+			BizName: bizName, TableName: tableName, Model: model, Generation: generation, PruneBefore: generation,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, `UPDATE biz_semantic_search SET model = ?, indexed_at = ? WHERE biz_name = ? AND table_name = ?`,
+			model, time.Now().UTC(), bizName, tableName); err != nil {
+			return fmt.Errorf("登记语义索引失败: %w", err)
+		}
+		progress.SetMessage(fmt.Sprintf("已向量化 %d 条记录，清理 %d 条过期向量", indexed, res.Deleted))
+		return nil
+	}
+}
+
+// indexAll 分页读取表中的文本，逐批向量化后写入数据源，返回写入的记录数
+func (s *Service) indexAll(ctx context.Context, searcher port.VectorSearcher, bizName, tableName string, fields []string, model string, generation int64, progress *jobs.Progress) (int64, error) {
+	req := port.EmbeddingSourceRequest{BizName: bizName, TableName: tableName, Fields: fields, Limit: s.batchSize}
+	var indexed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+		page, err := searcher.ScanEmbeddingSources(ctx, req)
+		if err != nil {
+			return indexed, err
+		}
+		if len(page.Sources) > 0 {
+			texts := make([]string, len(page.Sources))
+			for i, src := range page.Sources {
+				texts[i] = src.Text
+			}
+			vectors, err := s.embedder.Embed(ctx, texts)
+			if err != nil {
+				return indexed, err
+			}
+			embeddings := make([]port.Embedding, len(page.Sources))
+			for i, src := range page.Sources {
+				embeddings[i] = port.Embedding{Lib: src.Lib, RecordID: src.RecordID, Vector: vectors[i]}
+			}
+			if _, err := searcher.UpsertEmbeddings(ctx, port.UpsertEmbeddingsRequest{
+				BizName: bizName, TableName: tableName, Model: model, Generation: generation, Embeddings: embeddings,
+			}); err != nil {
+				return indexed, err
+			}
+			indexed += int64(len(page.Sources))
+			progress.Add(int64(len(page.Sources)))
+		}
+		if page.Done {
+			return indexed, nil
+		}
+		req.Lib, req.AfterRowID = page.NextLib, page.NextRowID
+	}
+}
+
+// Search 把文本向量化后检索最相似的记录，并按主键读取记录内容。
+// 与普通检索一样只对公开可搜索的业务组与表开放，记录只包含可返回字段，附带 "_score" 相似度。
+func (s *Service) Search(ctx context.Context, bizName, tableName, text string, topK int, minScore float64) (map[string]interface{}, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: 检索文本不能为空", ErrInvalidQuery)
+	}
+	if topK < 0 || topK > maxTopK {
+		return nil, fmt.Errorf("%w: top_k 必须在 1 到 %d 之间", ErrInvalidQuery, maxTopK)
+	}
+	if topK == 0 {
+		topK = defaultTopK
+	}
+	if minScore < -1 || minScore > 1 {
+		return nil, fmt.Errorf("%w: min_score 必须在 -1 到 1 之间", ErrInvalidQuery)
+	}
+
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if tableName == "" {
+		tableName = bizConfig.DefaultQueryTable
+	}
+	if !bizConfig.IsPubliclySearchable {
+		return nil, port.ErrPermissionDenied
+	}
+	tableConfig, exists := bizConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	if !tableConfig.IsSearchable {
+		return nil, port.ErrPermissionDenied
+	}
+
+	cfg, err := s.GetConfig(ctx, bizName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, ErrNotConfigured
+	}
+	model := s.embedder.Model()
+	if cfg.Model != model {
+		return nil, ErrIndexOutdated
+	}
+	searcher, err := s.vectorSearcher(bizName)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	res, err := searcher.SimilaritySearch(ctx, port.SimilaritySearchRequest{
+		BizName: bizName, TableName: tableName, Model: model, Vector: vectors[0], TopK: topK, MinScore: minScore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataSource := s.registry[bizName]
+	items := make([]interface{}, 0, len(res.Matches))
+	for _, match := range res.Matches {
+		result, err := dataSource.Query(ctx, port.QueryRequest{
+			BizName: bizName,
+			Query: map[string]interface{}{
+				port.QueryModeKey: port.QueryModeRecord,
+				"table":           tableName,
+				"id":              match.RecordID,
+				"lib":             match.Lib,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, _ := result.Data["record"].(map[string]interface{})
+		if record == nil {
+			// 记录在建立索引后被删除，等待下次重建时清理
+			continue
+		}
+		record["_score"] = match.Score
+		items = append(items, record)
+	}
+	return map[string]interface{}{
+		"items": items,
+		"total": len(items),
+		"model": model,
+	}, nil
+}
+
+// GetConfig 返回表的语义检索配置，未配置时返回 nil
+func (s *Service) GetConfig(ctx context.Context, bizName, tableName string) (*domain.SemanticSearchConfig, error) {
+	configs, err := s.listConfigs(ctx, `WHERE biz_name = ? AND table_name = ?`, bizName, tableName)
+	if err != nil || len(configs) == 0 {
+		return nil, err
+	}
+	return &configs[0], nil
+}
+
+// ListConfigs 返回业务组下所有表的语义检索配置
+func (s *Service) ListConfigs(ctx context.Context, bizName string) ([]domain.SemanticSearchConfig, error) {
+	return s.listConfigs(ctx, `WHERE biz_name = ?`, bizName)
+}
+
+func (s *Service) listConfigs(ctx context.Context, where string, args ...interface{}) ([]domain.SemanticSearchConfig, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, table_name, fields_json, model, indexed_at, updated_at FROM biz_semantic_search `+
+		where+` ORDER BY biz_name, table_name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询语义检索配置失败: %w", err)
+	}
+	defer rows.Close()
+	out := make([]domain.SemanticSearchConfig, 0)
+	for rows.Next() {
+		var cfg domain.SemanticSearchConfig
+		var fieldsJSON string
+		var indexedAt sql.NullTime
+		if err := rows.Scan(&cfg.BizName, &cfg.TableName, &fieldsJSON, &cfg.Model, &indexedAt, &cfg.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描语义检索配置失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(fieldsJSON), &cfg.Fields); err != nil {
+			return nil, fmt.Errorf("解析表 '%s' 的语义检索字段失败: %w", cfg.TableName, err)
+		}
+		if indexedAt.Valid {
+			cfg.IndexedAt = &indexedAt.Time
+		}
+		out = append(out, cfg)
+	}
+	return out, rows.Err()
+}
+
+func (s *Service) tableConfig(ctx context.Context, bizName, tableName string) (*domain.TableConfig, error) {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableConfig, exists := bizConfig.Tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	return tableConfig, nil
+}
+
+// vectorSearcher 返回业务组数据源的语义检索扩展
+func (s *Service) vectorSearcher(bizName string) (port.VectorSearcher, error) {
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	searcher, ok := dataSource.(port.VectorSearcher)
+	if !ok {
+		return nil, port.ErrVectorSearchUnsupported
+	}
+	return searcher, nil
+}
@@ -0,0 +1,178 @@
+// file: internal/service/semantic/semantic_service_test.go
+package semantic
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnv struct {
+	svc   *Service
+	jobs  *jobs.Service
+	sysDB *sql.DB
+	libDB *sql.DB
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('library', 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable, data_type) VALUES
+		('library', 'books', 'title', TRUE, TRUE, 'TEXT'), ('library', 'books', 'description', FALSE, TRUE, 'TEXT'), ('library', 'books', 'secret', FALSE, FALSE, 'TEXT')`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "library"), 0o755))
+	libDB, err := sql.Open("sqlite", "file:"+filepath.Join(root, "library", "main.db")+"?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { libDB.Close() })
+	_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, description TEXT, secret TEXT);
+		INSERT INTO books (title, description, secret) VALUES
+			('The Old Man and the Sea', 'An aging fisherman struggles with a giant marlin far out at sea', 'x'),
+			('Sea of Tranquility', 'Time travel across centuries and a moon colony', 'y'),
+			('Café Society', 'Hollywood glamour and New York nightlife in the thirties', 'z');`)
+	require.NoError(t, err)
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	jobService, err := jobs.NewService(sysDB)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	svc, err := NewService(sysDB, config, map[string]port.DataSource{"library": manager}, jobService, Options{Enabled: true, BatchSize: 2})
+	require.NoError(t, err)
+	return &testEnv{svc: svc, jobs: jobService, sysDB: sysDB, libDB: libDB}
+}
+
+func (e *testEnv) wait(t *testing.T, job *domain.BackgroundJob) *domain.BackgroundJob {
+	t.Helper()
+	require.NotNil(t, job)
+	var current *domain.BackgroundJob
+	var err error
+	require.Eventually(t, func() bool {
+		current, err = e.jobs.Get(context.Background(), job.ID)
+		require.NoError(t, err)
+		return current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, domain.JobStatusSucceeded, current.Status, current.Error)
+	return current
+}
+
+func TestSaveConfigAndSearch(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+
+	_, err := env.svc.Search(ctx, "library", "books", "fisherman", 5, 0)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+
+	_, _, err = env.svc.SaveConfig(ctx, "library", "books", []string{"secret"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig, "不可返回的字段不能参与语义检索")
+	_, _, err = env.svc.SaveConfig(ctx, "library", "books", []string{"missing"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	_, _, err = env.svc.SaveConfig(ctx, "library", "books", nil, 1)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	cfg, job, err := env.svc.SaveConfig(ctx, "library", "books", []string{"title", " description", "title"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "description"}, cfg.Fields)
+	assert.EqualValues(t, 3, env.wait(t, job).ProgressDone)
+
+	configs, err := env.svc.ListConfigs(ctx, "library")
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, env.svc.Model(), configs[0].Model)
+	assert.NotNil(t, configs[0].IndexedAt)
+
+	data, err := env.svc.Search(ctx, "library", "", "old fisherman marlin", 2, 0)
+	require.NoError(t, err)
+	items := data["items"].([]interface{})
+	require.NotEmpty(t, items)
+	first := items[0].(map[string]interface{})
+	assert.Equal(t, "The Old Man and the Sea", first["title"])
+	assert.NotContains(t, first, "secret", "只返回可返回字段")
+	assert.Greater(t, first["_score"].(float64), 0.0)
+
+	// 删除记录后重建，旧向量被清理
+	_, err = env.libDB.Exec(`DELETE FROM books WHERE id = 1`)
+	require.NoError(t, err)
+	job, err = env.svc.Rebuild(ctx, "library", "books", 1)
+	require.NoError(t, err)
+	env.wait(t, job)
+	data, err = env.svc.Search(ctx, "library", "books", "old fisherman marlin", 5, -1)
+	require.NoError(t, err)
+	for _, item := range data["items"].([]interface{}) {
+		assert.NotEqual(t, "The Old Man and the Sea", item.(map[string]interface{})["title"])
+	}
+	assert.Len(t, data["items"], 2)
+
+	_, err = env.svc.Search(ctx, "library", "books", "  ", 5, 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = env.svc.Search(ctx, "library", "books", "sea", 500, 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestSearchRequiresCurrentModel(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	_, job, err := env.svc.SaveConfig(ctx, "library", "books", []string{"description"}, 1)
+	require.NoError(t, err)
+	env.wait(t, job)
+
+	other, err := NewService(env.sysDB, env.svc.config, env.svc.registry, env.jobs, Options{Enabled: true, Embedder: EmbedderOptions{Dimensions: 32}})
+	require.NoError(t, err)
+	_, err = other.Search(ctx, "library", "books", "moon colony", 5, 0)
+	assert.ErrorIs(t, err, ErrIndexOutdated, "更换模型后需要重建索引")
+}
+
+func TestSearchPermissions(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	_, job, err := env.svc.SaveConfig(ctx, "library", "books", []string{"description"}, 1)
+	require.NoError(t, err)
+	env.wait(t, job)
+
+	_, err = env.sysDB.Exec(`UPDATE biz_overall_settings SET is_publicly_searchable = FALSE WHERE biz_name = 'library'`)
+	require.NoError(t, err)
+	env.svc.config.InvalidateCacheForBiz("library")
+	_, err = env.svc.Search(ctx, "library", "books", "moon colony", 5, 0)
+	assert.ErrorIs(t, err, port.ErrPermissionDenied)
+}
+
+func TestDeleteConfig(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	assert.ErrorIs(t, env.svc.DeleteConfig(ctx, "library", "books"), ErrNotConfigured)
+
+	_, job, err := env.svc.SaveConfig(ctx, "library", "books", []string{"description"}, 1)
+	require.NoError(t, err)
+	env.wait(t, job)
+	require.NoError(t, env.svc.DeleteConfig(ctx, "library", "books"))
+
+	configs, err := env.svc.ListConfigs(ctx, "library")
+	require.NoError(t, err)
+	assert.Empty(t, configs)
+	_, err = env.svc.Rebuild(ctx, "library", "books", 1)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	_, err = env.svc.Rebuild(ctx, "archive", "books", 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
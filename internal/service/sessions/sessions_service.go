@@ -0,0 +1,264 @@
+// Package sessions file: internal/service/sessions/sessions_service.go
+package sessions
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PolicyEvictOldest 在会话数达到上限时注销该用户最早的会话，新的登录总能成功
+	PolicyEvictOldest = "evict_oldest"
+	// PolicyReject 在会话数达到上限时拒绝新的登录，直到已有会话过期或被注销
+	PolicyReject = "reject"
+
+	sessionIDRandomLength = 16
+	maxUserAgentLength    = 512
+	// touchInterval 是更新会话最近使用时间的最小间隔，避免每个请求都写入 auth.db
+	touchInterval = time.Minute
+	sweepInterval = time.Hour
+)
+
+var (
+	// ErrLimitReached 表示用户的会话数已达上限且策略为拒绝新的登录
+	ErrLimitReached = errors.New("同时登录的会话数已达上限")
+	// ErrNotFound 表示会话不存在、已过期或已被注销
+	ErrNotFound = errors.New("登录会话不存在或已失效")
+)
+
+// Options 定义登录会话的数量限制
+type Options struct {
+	// Enabled 为 true 时每次登录记录一个会话，Token 携带会话 ID，被注销的会话立即失效
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPerUser 是每个用户同时有效的会话数上限，0 表示不限制
+	MaxPerUser int `mapstructure:"max_per_user"`
+	// OnLimit 是达到上限时的策略：evict_oldest (默认) 或 reject
+	OnLimit string `mapstructure:"on_limit"`
+}
+
+// Service 在 auth.db 的 user_sessions 表中记录每次登录签发的 Token，限制每个用户同时有效的会话数，
+// 并向管理员展示某个账户当前在哪些设备与 IP 上登录
+type Service struct {
+	db   *sql.DB
+	opts Options
+	now  func() time.Time
+
+	// mu 串行化登录时的计数与注销，避免并发登录同时越过上限
+	mu sync.Mutex
+}
+
+// NewService 创建一个新的登录会话服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("sessions.Service 需要一个有效的数据库连接")
+	}
+	if opts.MaxPerUser < 0 {
+		return nil, errors.New("max_per_user 不能为负数")
+	}
+	switch opts.OnLimit {
+	case "":
+		opts.OnLimit = PolicyEvictOldest
+	case PolicyEvictOldest, PolicyReject:
+	default:
+		return nil, fmt.Errorf("未知的会话上限策略 '%s'，应为 %s 或 %s", opts.OnLimit, PolicyEvictOldest, PolicyReject)
+	}
+	return &Service{db: db, opts: opts, now: time.Now}, nil
+}
+
+// Create 为一次成功的登录记录会话，expiresAt 是签发的 Token 的过期时间。
+// 会话数达到上限时按策略注销最早的会话 (返回被注销的会话 ID) 或返回 ErrLimitReached
+func (s *Service) Create(ctx context.Context, userID int64, ip, userAgent string, expiresAt time.Time) (*domain.UserSession, []string, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	now := s.now().UTC()
+	session := &domain.UserSession{
+		ID: id, UserID: userID, IP: ip, UserAgent: userAgent,
+		CreatedAt: now, LastSeenAt: now, ExpiresAt: expiresAt.UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("开始登录会话事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	var evicted []string
+	if s.opts.MaxPerUser > 0 {
+		active, err := activeIDs(ctx, tx, userID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if excess := len(active) - s.opts.MaxPerUser + 1; excess > 0 {
+			if s.opts.OnLimit == PolicyReject {
+				return nil, nil, fmt.Errorf("%w (%d)", ErrLimitReached, s.opts.MaxPerUser)
+			}
+			evicted = active[:excess]
+			for _, old := range evicted {
+				if _, err := tx.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = ?, revoke_reason = ? WHERE id = ?`,
+					now, domain.SessionRevokedEvicted, old); err != nil {
+					return nil, nil, fmt.Errorf("注销最早的登录会话失败: %w", err)
+				}
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO user_sessions (id, user_id, ip, user_agent, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		session.ID, session.UserID, session.IP, session.UserAgent, now, now, session.ExpiresAt); err != nil {
+		return nil, nil, fmt.Errorf("保存登录会话失败: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("提交登录会话失败: %w", err)
+	}
+	return session, evicted, nil
+}
+
+// activeIDs 按登录时间从早到晚返回用户仍然有效的会话 ID
+func activeIDs(ctx context.Context, tx *sql.Tx, userID int64, now time.Time) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY created_at, id`, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("读取登录会话失败: %w", err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("读取登录会话失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SessionActive 判断 Token 携带的会话是否仍然有效，并按分钟更新会话的最近使用时间。
+// 表中没有的会话视为有效：备用网关签发的 Token 会在下一次同步 auth.db 时丢失会话记录，
+// Token 本身已经过签名校验，拒绝它只会让用户莫名其妙地掉线
+func (s *Service) SessionActive(ctx context.Context, sessionID string, userID int64) bool {
+	var owner int64
+	var lastSeen, expiresAt time.Time
+	var revokedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT user_id, last_seen_at, expires_at, revoked_at FROM user_sessions WHERE id = ?`, sessionID).
+		Scan(&owner, &lastSeen, &expiresAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		slog.Warn("[Sessions] 读取登录会话失败", "session", sessionID, "error", err)
+		return false
+	}
+	now := s.now().UTC()
+	if owner != userID || revokedAt.Valid || !expiresAt.After(now) {
+		return false
+	}
+	if now.Sub(lastSeen) >= touchInterval {
+		if _, err := s.db.ExecContext(ctx, `UPDATE user_sessions SET last_seen_at = ? WHERE id = ?`, now, sessionID); err != nil {
+			slog.Warn("[Sessions] 更新登录会话的最近使用时间失败", "session", sessionID, "error", err)
+		}
+	}
+	return true
+}
+
+// List 按登录时间从新到旧返回用户的会话。includeRevoked 为 true 时同时返回已注销但 Token 尚未过期的会话
+func (s *Service) List(ctx context.Context, userID int64, includeRevoked bool) ([]domain.UserSession, error) {
+	query := `SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at, revoked_at, revoke_reason
+		FROM user_sessions WHERE user_id = ? AND expires_at > ?`
+	if !includeRevoked {
+		query += ` AND revoked_at IS NULL`
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY created_at DESC, id`, userID, s.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("读取登录会话失败: %w", err)
+	}
+	defer rows.Close()
+	sessions := []domain.UserSession{}
+	for rows.Next() {
+		var session domain.UserSession
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&session.ID, &session.UserID, &session.IP, &session.UserAgent, &session.CreatedAt,
+			&session.LastSeenAt, &session.ExpiresAt, &revokedAt, &session.RevokeReason); err != nil {
+			return nil, fmt.Errorf("读取登录会话失败: %w", err)
+		}
+		if revokedAt.Valid {
+			session.RevokedAt = &revokedAt.Time
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke 注销用户的一个会话，该会话的 Token 立即失效
+func (s *Service) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = ?, revoke_reason = ?
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?`,
+		s.now().UTC(), domain.SessionRevokedAdmin, strings.TrimSpace(sessionID), userID, s.now().UTC())
+	if err != nil {
+		return fmt.Errorf("注销登录会话失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeAll 注销用户的全部有效会话，返回注销的数量
+func (s *Service) RevokeAll(ctx context.Context, userID int64) (int64, error) {
+	now := s.now().UTC()
+	res, err := s.db.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = ?, revoke_reason = ?
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?`, now, domain.SessionRevokedAdmin, userID, now)
+	if err != nil {
+		return 0, fmt.Errorf("注销登录会话失败: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// Run 定期删除 Token 已过期的会话，直到 ctx 被取消
+func (s *Service) Run(ctx context.Context) {
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE expires_at <= ?`, s.now().UTC())
+	if err != nil {
+		slog.Warn("[Sessions] 清理过期的登录会话失败", "error", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		slog.Info("[Sessions] 已清理过期的登录会话", "count", n)
+	}
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, sessionIDRandomLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成登录会话 ID 失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,102 @@
+// file: internal/service/sessions/sessions_service_test.go
+package sessions
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, int64) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	res, err := db.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('reader', 'hash', 'user')`)
+	require.NoError(t, err)
+	userID, err := res.LastInsertId()
+	require.NoError(t, err)
+	s, err := NewService(db, opts)
+	require.NoError(t, err)
+	return s, userID
+}
+
+func TestCreate_EvictOldest(t *testing.T) {
+	ctx := context.Background()
+	s, userID := newTestService(t, Options{Enabled: true, MaxPerUser: 2})
+	clock := time.Now()
+	s.now = func() time.Time { return clock }
+	expires := clock.Add(time.Hour)
+
+	first, evicted, err := s.Create(ctx, userID, "10.0.0.1", "laptop", expires)
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	clock = clock.Add(time.Second)
+	second, _, err := s.Create(ctx, userID, "10.0.0.2", "phone", expires)
+	require.NoError(t, err)
+	clock = clock.Add(time.Second)
+	third, evicted, err := s.Create(ctx, userID, "10.0.0.3", "tablet", expires)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID}, evicted, "达到上限时挤掉最早的会话")
+
+	assert.False(t, s.SessionActive(ctx, first.ID, userID))
+	assert.True(t, s.SessionActive(ctx, second.ID, userID))
+	assert.True(t, s.SessionActive(ctx, third.ID, userID))
+	assert.False(t, s.SessionActive(ctx, third.ID, userID+1), "会话属于其他用户时无效")
+	assert.True(t, s.SessionActive(ctx, "unknown", userID), "表中没有的会话视为有效")
+
+	active, err := s.List(ctx, userID, false)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, third.ID, active[0].ID, "按登录时间从新到旧")
+	assert.Equal(t, "tablet", active[0].UserAgent)
+	all, err := s.List(ctx, userID, true)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, domain.SessionRevokedEvicted, all[2].RevokeReason)
+	assert.NotNil(t, all[2].RevokedAt)
+
+	require.NoError(t, s.Revoke(ctx, userID, second.ID))
+	assert.False(t, s.SessionActive(ctx, second.ID, userID))
+	assert.ErrorIs(t, s.Revoke(ctx, userID, second.ID), ErrNotFound)
+	n, err := s.RevokeAll(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.False(t, s.SessionActive(ctx, third.ID, userID))
+
+	clock = expires.Add(time.Minute)
+	s.sweep(ctx)
+	all, err = s.List(ctx, userID, true)
+	require.NoError(t, err)
+	assert.Empty(t, all, "Token 过期后会话被清理")
+}
+
+func TestCreate_Reject(t *testing.T) {
+	ctx := context.Background()
+	s, userID := newTestService(t, Options{Enabled: true, MaxPerUser: 1, OnLimit: PolicyReject})
+	expires := time.Now().Add(time.Hour)
+
+	first, _, err := s.Create(ctx, userID, "10.0.0.1", "laptop", expires)
+	require.NoError(t, err)
+	_, _, err = s.Create(ctx, userID, "10.0.0.2", "phone", expires)
+	assert.ErrorIs(t, err, ErrLimitReached)
+	assert.True(t, s.SessionActive(ctx, first.ID, userID), "拒绝新的登录时已有会话不受影响")
+
+	require.NoError(t, s.Revoke(ctx, userID, first.ID))
+	_, _, err = s.Create(ctx, userID, "10.0.0.2", "phone", expires)
+	assert.NoError(t, err, "注销后可以再次登录")
+}
+
+func TestNewServiceValidation(t *testing.T) {
+	_, err := NewService(nil, Options{})
+	assert.Error(t, err)
+	s, _ := newTestService(t, Options{})
+	_, err = NewService(s.db, Options{MaxPerUser: -1})
+	assert.Error(t, err)
+	_, err = NewService(s.db, Options{OnLimit: "drop_newest"})
+	assert.Error(t, err)
+	assert.Equal(t, PolicyEvictOldest, s.opts.OnLimit)
+}
@@ -0,0 +1,167 @@
+// Package sharding file: internal/service/sharding/sharding_service.go
+package sharding
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/readsplit"
+	"ArchiveAegis/internal/adapter/datasource/sharded"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	// ErrShardedBizNotFound 表示指定的分片业务组不存在
+	ErrShardedBizNotFound = errors.New("分片业务组不存在")
+	// ErrInvalidDefinition 表示分片业务组定义不合法
+	ErrInvalidDefinition = errors.New("无效的分片业务组定义")
+)
+
+// Service 负责持久化分片业务组定义，并使注册表中的数据源与定义保持一致
+type Service struct {
+	db        *sql.DB
+	registrar virtualbiz.Registrar
+}
+
+// NewService 创建一个新的分片业务组服务实例
+func NewService(db *sql.DB, registrar virtualbiz.Registrar) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("sharding.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, registrar: registrar}, nil
+}
+
+// LoadAll 在启动时注册所有已保存的分片业务组。单个定义注册失败不会影响其它定义。
+func (s *Service) LoadAll(ctx context.Context) error {
+	defs, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		ds, err := sharded.New(def, s.registrar.LookupDataSource)
+		if err == nil {
+			err = s.registrar.RegisterStaticDataSource(def.BizName, ds)
+		}
+		if err != nil {
+			slog.Warn("[Sharding] 注册分片业务组失败，已跳过", "biz", def.BizName, "error", err)
+		}
+	}
+	return nil
+}
+
+// List 返回所有分片业务组定义
+func (s *Service) List(ctx context.Context) ([]domain.ShardedBiz, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, description, shard_key, shards_json, updated_at FROM sharded_biz_definitions ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询分片业务组失败: %w", err)
+	}
+	defer rows.Close()
+
+	defs := make([]domain.ShardedBiz, 0)
+	for rows.Next() {
+		var def domain.ShardedBiz
+		var shardsJSON string
+		if err := rows.Scan(&def.BizName, &def.Description, &def.ShardKey, &shardsJSON, &def.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描分片业务组失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(shardsJSON), &def.Shards); err != nil {
+			return nil, fmt.Errorf("解析分片业务组 '%s' 的分片列表失败: %w", def.BizName, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// Save 创建或更新一个分片业务组，并立即在网关中生效
+func (s *Service) Save(ctx context.Context, def domain.ShardedBiz) error {
+	if err := s.validate(def); err != nil {
+		return err
+	}
+	ds, err := sharded.New(def, s.registrar.LookupDataSource)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	shardsJSON, err := json.Marshal(def.Shards)
+	if err != nil {
+		return fmt.Errorf("序列化分片列表失败: %w", err)
+	}
+
+	// 先注册再持久化：名称被插件占用时不应留下无法生效的定义
+	if err := s.registrar.RegisterStaticDataSource(def.BizName, ds); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO sharded_biz_definitions (biz_name, description, shard_key, shards_json, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			description = excluded.description,
+			shard_key = excluded.shard_key,
+			shards_json = excluded.shards_json,
+			updated_at = CURRENT_TIMESTAMP`,
+		def.BizName, def.Description, def.ShardKey, string(shardsJSON),
+	)
+	if err != nil {
+		s.registrar.UnregisterStaticDataSource(def.BizName)
+		return fmt.Errorf("保存分片业务组 '%s' 失败: %w", def.BizName, err)
+	}
+	return nil
+}
+
+// Delete 删除一个分片业务组，并将其从网关注销。各分片业务组及其数据不受影响
+func (s *Service) Delete(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sharded_biz_definitions WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除分片业务组 '%s' 失败: %w", bizName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrShardedBizNotFound
+	}
+	s.registrar.UnregisterStaticDataSource(bizName)
+	return nil
+}
+
+// validate 校验定义的完整性。分片不能是虚拟或分片业务组，以避免循环路由；
+// 名称也不能覆盖已注册的虚拟业务组
+func (s *Service) validate(def domain.ShardedBiz) error {
+	if def.BizName == "" {
+		return fmt.Errorf("%w: biz_name 不能为空", ErrInvalidDefinition)
+	}
+	if def.ShardKey == "" {
+		return fmt.Errorf("%w: shard_key 不能为空", ErrInvalidDefinition)
+	}
+	if len(def.Shards) == 0 {
+		return fmt.Errorf("%w: 至少需要一个分片", ErrInvalidDefinition)
+	}
+	if ds, loaded := s.registrar.LookupDataSource(def.BizName); loaded {
+		switch ds.(type) {
+		case *virtual.DataSource, *readsplit.DataSource:
+			return fmt.Errorf("%w: 名称 '%s' 已被虚拟或读写分离业务组使用", ErrInvalidDefinition, def.BizName)
+		}
+	}
+
+	seen := make(map[string]bool, len(def.Shards))
+	for _, shard := range def.Shards {
+		if shard.SourceBiz == "" {
+			return fmt.Errorf("%w: 每个分片必须包含 source_biz", ErrInvalidDefinition)
+		}
+		if seen[shard.SourceBiz] {
+			return fmt.Errorf("%w: 分片业务组 '%s' 重复", ErrInvalidDefinition, shard.SourceBiz)
+		}
+		seen[shard.SourceBiz] = true
+		if shard.SourceBiz == def.BizName {
+			return fmt.Errorf("%w: 分片业务组不能是其自身", ErrInvalidDefinition)
+		}
+		if ds, loaded := s.registrar.LookupDataSource(shard.SourceBiz); loaded {
+			switch ds.(type) {
+			case *virtual.DataSource, *sharded.DataSource:
+				return fmt.Errorf("%w: 分片 '%s' 不能是虚拟或分片业务组", ErrInvalidDefinition, shard.SourceBiz)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// file: internal/service/sharding/sharding_service_test.go
+package sharding_test
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sharded"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/sharding"
+	"ArchiveAegis/pkg/testsupport"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var letterFields = []port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "year"}, {Name: "sender"}}
+
+type fixture struct {
+	svc      *sharding.Service
+	registry *testsupport.StaticRegistry
+	early    *testsupport.FakeDataSource
+	late     *testsupport.FakeDataSource
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		early: testsupport.NewFakeDataSource().WithTable("letters", letterFields,
+			map[string]interface{}{"id": float64(1), "year": float64(1880), "sender": "曾国藩"},
+			map[string]interface{}{"id": float64(2), "year": float64(1895), "sender": "李鸿章"},
+		),
+		late: testsupport.NewFakeDataSource().WithTable("letters", letterFields,
+			map[string]interface{}{"id": float64(3), "year": float64(1920), "sender": "鲁迅"},
+		),
+	}
+	f.registry = testsupport.NewStaticRegistry().
+		RegisterPlugin("letters_early", f.early).
+		RegisterPlugin("letters_late", f.late)
+	svc, err := sharding.NewService(testsupport.NewSystemDB(t), f.registry)
+	require.NoError(t, err)
+	f.svc = svc
+	return f
+}
+
+func lettersByYear() domain.ShardedBiz {
+	return domain.ShardedBiz{
+		BizName:  "letters",
+		ShardKey: "year",
+		Shards: []domain.Shard{
+			{SourceBiz: "letters_early", To: "1900"},
+			{SourceBiz: "letters_late", From: "1900"},
+		},
+	}
+}
+
+func TestNewServiceRequiresDB(t *testing.T) {
+	_, err := sharding.NewService(nil, testsupport.NewStaticRegistry())
+	assert.Error(t, err)
+}
+
+func TestSaveRegistersShardedBizAndRoutesByKey(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersByYear()))
+
+	ds, loaded := f.registry.LookupDataSource("letters")
+	require.True(t, loaded)
+	require.IsType(t, &sharded.DataSource{}, ds)
+
+	result, err := ds.Query(ctx, port.QueryRequest{BizName: "letters", Query: map[string]interface{}{
+		"table":   "letters",
+		"filters": []interface{}{map[string]interface{}{"field": "year", "value": "1920"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), result.Data["total"])
+	assert.Empty(t, f.early.Queries(), "分片键落在后一个分片时不应查询前一个分片")
+	require.Len(t, f.late.Queries(), 1)
+	assert.Equal(t, "letters_late", f.late.Queries()[0].BizName)
+
+	result, err = ds.Query(ctx, port.QueryRequest{BizName: "letters", Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), result.Data["total"], "分片键未被约束时合并全部分片")
+
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "year", defs[0].ShardKey)
+	assert.Equal(t, lettersByYear().Shards, defs[0].Shards)
+}
+
+func TestSaveUpdatesExistingDefinition(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersByYear()))
+
+	def := lettersByYear()
+	def.Shards = def.Shards[:1]
+	def.Shards[0].To = ""
+	require.NoError(t, f.svc.Save(ctx, def))
+
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Len(t, defs[0].Shards, 1)
+
+	ds, _ := f.registry.LookupDataSource("letters")
+	result, err := ds.Query(ctx, port.QueryRequest{BizName: "letters", Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), result.Data["total"], "更新后的定义立即生效")
+	assert.Empty(t, f.late.Queries())
+}
+
+func TestSaveRejectsInvalidDefinitions(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersByYear()))
+	require.NoError(t, f.registry.RegisterStaticDataSource("selected", virtual.New(domain.VirtualBiz{BizName: "selected"}, f.registry.LookupDataSource)))
+
+	shards := func(names ...string) []domain.Shard {
+		out := make([]domain.Shard, 0, len(names))
+		for _, n := range names {
+			out = append(out, domain.Shard{SourceBiz: n})
+		}
+		return out
+	}
+	cases := map[string]domain.ShardedBiz{
+		"缺少名称":    {ShardKey: "year", Shards: shards("letters_early")},
+		"缺少分片键":   {BizName: "s", Shards: shards("letters_early")},
+		"没有分片":    {BizName: "s", ShardKey: "year"},
+		"分片缺少业务组": {BizName: "s", ShardKey: "year", Shards: []domain.Shard{{To: "1900"}}},
+		"分片重复": {BizName: "s", ShardKey: "year", Shards: []domain.Shard{
+			{SourceBiz: "letters_early", To: "1900"}, {SourceBiz: "letters_early", From: "1900"}}},
+		"分片是自身":      {BizName: "s", ShardKey: "year", Shards: shards("s")},
+		"分片是分片业务组":   {BizName: "s", ShardKey: "year", Shards: shards("letters")},
+		"分片是虚拟业务组":   {BizName: "s", ShardKey: "year", Shards: shards("selected")},
+		"名称被虚拟业务组占用": {BizName: "selected", ShardKey: "year", Shards: shards("letters_early")},
+		"范围重叠": {BizName: "s", ShardKey: "year", Shards: []domain.Shard{
+			{SourceBiz: "letters_early", To: "1950"}, {SourceBiz: "letters_late", From: "1900"}}},
+		"边界类型混用": {BizName: "s", ShardKey: "year", Shards: []domain.Shard{
+			{SourceBiz: "letters_early", To: "1900"}, {SourceBiz: "letters_late", From: "m"}}},
+	}
+	for name, def := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, f.svc.Save(ctx, def), sharding.ErrInvalidDefinition)
+		})
+	}
+
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "被拒绝的定义不应被保存")
+	_, loaded := f.registry.LookupDataSource("s")
+	assert.False(t, loaded, "被拒绝的定义不应被注册")
+}
+
+func TestSaveRejectsNameServedByPlugin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	def := lettersByYear()
+	def.BizName = "letters_early"
+	def.Shards = []domain.Shard{{SourceBiz: "letters_late"}}
+
+	assert.ErrorIs(t, f.svc.Save(ctx, def), sharding.ErrInvalidDefinition)
+	defs, err := f.svc.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, defs, "名称被插件占用时不应留下无法生效的定义")
+}
+
+func TestDeleteUnregistersAndKeepsShards(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	require.NoError(t, f.svc.Save(ctx, lettersByYear()))
+
+	require.NoError(t, f.svc.Delete(ctx, "letters"))
+	_, loaded := f.registry.LookupDataSource("letters")
+	assert.False(t, loaded)
+	for _, shard := range []string{"letters_early", "letters_late"} {
+		_, loaded = f.registry.LookupDataSource(shard)
+		assert.True(t, loaded, "分片业务组 '%s' 不受影响", shard)
+	}
+
+	assert.ErrorIs(t, f.svc.Delete(ctx, "letters"), sharding.ErrShardedBizNotFound)
+}
+
+func TestLoadAllRegistersSavedDefinitions(t *testing.T) {
+	ctx := context.Background()
+	db := testsupport.NewSystemDB(t)
+	first, err := sharding.NewService(db, testsupport.NewStaticRegistry())
+	require.NoError(t, err)
+	require.NoError(t, first.Save(ctx, lettersByYear()))
+	blocked := lettersByYear()
+	blocked.BizName = "reclaimed"
+	require.NoError(t, first.Save(ctx, blocked))
+
+	// 重启后 reclaimed 已由插件提供，跳过它不影响其它定义的注册
+	registry := testsupport.NewStaticRegistry().RegisterPlugin("reclaimed", testsupport.NewFakeDataSource())
+	restarted, err := sharding.NewService(db, registry)
+	require.NoError(t, err)
+	require.NoError(t, restarted.LoadAll(ctx))
+
+	ds, loaded := registry.LookupDataSource("letters")
+	require.True(t, loaded)
+	assert.IsType(t, &sharded.DataSource{}, ds)
+	ds, _ = registry.LookupDataSource("reclaimed")
+	assert.IsType(t, &testsupport.FakeDataSource{}, ds)
+}
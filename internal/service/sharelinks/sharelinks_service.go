@@ -0,0 +1,409 @@
+// Package sharelinks file: internal/service/sharelinks/sharelinks_service.go
+package sharelinks
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxPerUser     = 100
+	defaultTTL            = 7 * 24 * time.Hour
+	defaultMaxTTL         = 90 * 24 * time.Hour
+	defaultRatePerMinute  = 60
+	defaultMaxPageSize    = 100
+	maxLabelRunes         = 200
+	tokenPrefixLength     = 8
+	limiterIdleTimeout    = 15 * time.Minute
+	limiterPruneInterval  = time.Minute
+	defaultSharedPageSize = 20
+)
+
+var (
+	// ErrInvalidShareLink 表示分享链接的参数不合法
+	ErrInvalidShareLink = errors.New("无效的分享链接")
+	// ErrNotFound 表示链接不存在、已过期或已撤销
+	ErrNotFound = errors.New("分享链接不存在或已失效")
+	// ErrQuotaExceeded 表示用户的有效分享链接数已达上限
+	ErrQuotaExceeded = errors.New("分享链接数已达上限")
+	// ErrRateLimited 表示链接的访问频率超出其限制
+	ErrRateLimited = errors.New("分享链接访问过于频繁")
+)
+
+// allowedQueryKeys 是冻结查询可以包含的键，排除了 record、changes 等特殊模式与 explain
+var allowedQueryKeys = map[string]bool{"table": true, "filters": true, "fields_to_return": true, "size": true}
+
+// Options 定义分享链接的配置
+type Options struct {
+	// MaxPerUser 是每个用户未过期且未撤销的链接数上限，默认为 100
+	MaxPerUser int `mapstructure:"max_per_user"`
+	// DefaultTTLHours 是未指定有效期时的有效期，默认为 168 小时 (7 天)
+	DefaultTTLHours int `mapstructure:"default_ttl_hours"`
+	// MaxTTLHours 是有效期上限，默认为 2160 小时 (90 天)
+	MaxTTLHours int `mapstructure:"max_ttl_hours"`
+	// DefaultRatePerMinute 是未指定时每条链接每分钟允许的访问次数，默认为 60，
+	// 同时也是创建者可以设置的上限
+	DefaultRatePerMinute int `mapstructure:"default_rate_per_minute"`
+	// MaxPageSize 是冻结查询每页的记录数上限，默认为 100
+	MaxPageSize int `mapstructure:"max_page_size"`
+}
+
+// CreateRequest 是创建分享链接的参数
+type CreateRequest struct {
+	BizName       string                 `json:"biz_name"`
+	Query         map[string]interface{} `json:"query"`
+	Label         string                 `json:"label"`
+	TTLHours      int                    `json:"ttl_hours"`
+	RatePerMinute int                    `json:"rate_per_minute"`
+}
+
+// Created 是新建的链接与其令牌，令牌只在此时返回
+type Created struct {
+	Link  *domain.ShareLink `json:"link"`
+	Token string            `json:"token"`
+}
+
+// Service 让登录用户把一次查询冻结为公开链接：持有令牌的任何人都能以只读方式翻阅结果，
+// 但不能修改查询条件。链接有有效期、可随时撤销，并按链接单独限流。
+// 查询仍经由数据源执行，因此只能看到公开检索可见的数据。
+type Service struct {
+	db       *sql.DB
+	registry map[string]port.DataSource
+	opts     Options
+	now      func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[int64]*limiterEntry
+	lastPrune time.Time
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	perMin   int
+	lastSeen time.Time
+}
+
+// NewService 创建一个新的分享链接服务实例
+func NewService(db *sql.DB, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("sharelinks.Service 需要一个有效的数据库连接")
+	}
+	if opts.MaxPerUser < 0 || opts.DefaultTTLHours < 0 || opts.MaxTTLHours < 0 || opts.DefaultRatePerMinute < 0 || opts.MaxPageSize < 0 {
+		return nil, errors.New("分享链接的配置项不能为负数")
+	}
+	if opts.MaxPerUser == 0 {
+		opts.MaxPerUser = defaultMaxPerUser
+	}
+	if opts.DefaultTTLHours == 0 {
+		opts.DefaultTTLHours = int(defaultTTL / time.Hour)
+	}
+	if opts.MaxTTLHours == 0 {
+		opts.MaxTTLHours = int(defaultMaxTTL / time.Hour)
+	}
+	if opts.DefaultTTLHours > opts.MaxTTLHours {
+		return nil, errors.New("分享链接的默认有效期不能超过有效期上限")
+	}
+	if opts.DefaultRatePerMinute == 0 {
+		opts.DefaultRatePerMinute = defaultRatePerMinute
+	}
+	if opts.MaxPageSize == 0 {
+		opts.MaxPageSize = defaultMaxPageSize
+	}
+	return &Service{
+		db:       db,
+		registry: registry,
+		opts:     opts,
+		now:      time.Now,
+		limiters: make(map[int64]*limiterEntry),
+	}, nil
+}
+
+// Create 校验并试运行查询，成功后生成分享链接
+func (s *Service) Create(ctx context.Context, ownerID int64, req CreateRequest) (*Created, error) {
+	req.BizName, req.Label = strings.TrimSpace(req.BizName), strings.TrimSpace(req.Label)
+	if req.BizName == "" {
+		return nil, fmt.Errorf("%w: 必须提供 biz_name", ErrInvalidShareLink)
+	}
+	if n := utf8.RuneCountInString(req.Label); n > maxLabelRunes {
+		return nil, fmt.Errorf("%w: 标题为 %d 个字符，上限为 %d 个字符", ErrInvalidShareLink, n, maxLabelRunes)
+	}
+	if req.TTLHours < 0 || req.TTLHours > s.opts.MaxTTLHours {
+		return nil, fmt.Errorf("%w: ttl_hours 必须在 1 到 %d 之间", ErrInvalidShareLink, s.opts.MaxTTLHours)
+	}
+	if req.RatePerMinute < 0 || req.RatePerMinute > s.opts.DefaultRatePerMinute {
+		return nil, fmt.Errorf("%w: rate_per_minute 必须在 1 到 %d 之间", ErrInvalidShareLink, s.opts.DefaultRatePerMinute)
+	}
+	query, err := s.freezeQuery(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	dataSource, exists := s.registry[req.BizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	// 试运行一次，让无权限或条件有误的查询在创建时就失败
+	if _, err := dataSource.Query(ctx, port.QueryRequest{BizName: req.BizName, Query: withPage(query, 1)}); err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(s.opts.DefaultTTLHours) * time.Hour
+	if req.TTLHours > 0 {
+		ttl = time.Duration(req.TTLHours) * time.Hour
+	}
+	ratePerMinute := s.opts.DefaultRatePerMinute
+	if req.RatePerMinute > 0 {
+		ratePerMinute = req.RatePerMinute
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("序列化查询失败: %w", err)
+	}
+
+	now := s.now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启分享链接事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	var active int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM share_links WHERE owner_id = ? AND revoked_at IS NULL AND expires_at > ?`,
+		ownerID, now).Scan(&active); err != nil {
+		return nil, fmt.Errorf("统计分享链接失败: %w", err)
+	}
+	if active >= s.opts.MaxPerUser {
+		return nil, fmt.Errorf("%w: 最多同时有 %d 条有效链接", ErrQuotaExceeded, s.opts.MaxPerUser)
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO share_links
+		(token_hash, token_prefix, owner_id, label, biz_name, query_json, rate_per_minute, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hashToken(token), token[:tokenPrefixLength], ownerID, req.Label, req.BizName, string(queryJSON), ratePerMinute, now.Add(ttl), now)
+	if err != nil {
+		return nil, fmt.Errorf("写入分享链接失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交分享链接失败: %w", err)
+	}
+	link, err := s.get(ctx, `id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Created{Link: link, Token: token}, nil
+}
+
+// freezeQuery 只保留允许的键，并限制每页的记录数
+func (s *Service) freezeQuery(query map[string]interface{}) (map[string]interface{}, error) {
+	if len(query) == 0 {
+		return nil, fmt.Errorf("%w: 必须提供 query", ErrInvalidShareLink)
+	}
+	frozen := make(map[string]interface{}, len(query))
+	for key, value := range query {
+		if !allowedQueryKeys[key] {
+			return nil, fmt.Errorf("%w: 分享的查询不支持 '%s'，只能包含 table、filters、fields_to_return 与 size", ErrInvalidShareLink, key)
+		}
+		frozen[key] = value
+	}
+	if table, _ := frozen["table"].(string); strings.TrimSpace(table) == "" {
+		return nil, fmt.Errorf("%w: query 必须包含 table", ErrInvalidShareLink)
+	}
+	size := float64(min(defaultSharedPageSize, s.opts.MaxPageSize))
+	if raw, ok := frozen["size"]; ok {
+		n, ok := raw.(float64)
+		if !ok || n < 1 || n != float64(int(n)) {
+			return nil, fmt.Errorf("%w: size 必须是正整数", ErrInvalidShareLink)
+		}
+		size = n
+	}
+	if size > float64(s.opts.MaxPageSize) {
+		return nil, fmt.Errorf("%w: size 不能超过 %d", ErrInvalidShareLink, s.opts.MaxPageSize)
+	}
+	frozen["size"] = size
+	return frozen, nil
+}
+
+// Open 以令牌读取分享的查询结果的第 page 页
+func (s *Service) Open(ctx context.Context, token string, page int) (*domain.ShareLink, *port.QueryResult, error) {
+	if page < 1 {
+		return nil, nil, fmt.Errorf("%w: page 必须是正整数", ErrInvalidShareLink)
+	}
+	now := s.now().UTC()
+	link, err := s.get(ctx, `token_hash = ?`, hashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if link.RevokedAt != nil || !now.Before(link.ExpiresAt) {
+		return nil, nil, ErrNotFound
+	}
+	if !s.allow(link, now) {
+		return nil, nil, fmt.Errorf("%w: 每分钟最多 %d 次", ErrRateLimited, link.RatePerMinute)
+	}
+	dataSource, exists := s.registry[link.BizName]
+	if !exists {
+		return nil, nil, port.ErrBizNotFound
+	}
+	result, err := dataSource.Query(ctx, port.QueryRequest{BizName: link.BizName, Query: withPage(link.Query, page)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE share_links SET use_count = use_count + 1, last_used_at = ? WHERE id = ?`, now, link.ID); err != nil {
+		return nil, nil, fmt.Errorf("更新分享链接失败: %w", err)
+	}
+	return link, result, nil
+}
+
+// allow 按链接的 rate_per_minute 限流，并顺带清理长时间未访问的限流器
+func (s *Service) allow(link *domain.ShareLink, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPrune) > limiterPruneInterval {
+		for id, entry := range s.limiters {
+			if now.Sub(entry.lastSeen) > limiterIdleTimeout {
+				delete(s.limiters, id)
+			}
+		}
+		s.lastPrune = now
+	}
+	entry, ok := s.limiters[link.ID]
+	if !ok || entry.perMin != link.RatePerMinute {
+		entry = &limiterEntry{
+			limiter: rate.NewLimiter(rate.Limit(float64(link.RatePerMinute)/60), link.RatePerMinute),
+			perMin:  link.RatePerMinute,
+		}
+		s.limiters[link.ID] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter.AllowN(now, 1)
+}
+
+// List 返回用户创建的全部链接，按创建时间倒序
+func (s *Service) List(ctx context.Context, ownerID int64) ([]domain.ShareLink, error) {
+	rows, err := s.db.QueryContext(ctx, selectColumns+` WHERE owner_id = ? ORDER BY created_at DESC, id DESC`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("查询分享链接失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.ShareLink{}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描分享链接失败: %w", err)
+		}
+		out = append(out, *link)
+	}
+	return out, rows.Err()
+}
+
+// ActiveForBiz 返回业务组下所有用户未过期且未撤销的链接，供修改字段配置前做影响分析
+func (s *Service) ActiveForBiz(ctx context.Context, bizName string) ([]domain.ShareLink, error) {
+	rows, err := s.db.QueryContext(ctx, selectColumns+` WHERE biz_name = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY id`,
+		bizName, s.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("查询分享链接失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.ShareLink{}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描分享链接失败: %w", err)
+		}
+		out = append(out, *link)
+	}
+	return out, rows.Err()
+}
+
+// Revoke 撤销用户自己的链接，撤销后立即失效且不可恢复
+func (s *Service) Revoke(ctx context.Context, ownerID, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE share_links SET revoked_at = ? WHERE id = ? AND owner_id = ? AND revoked_at IS NULL`,
+		s.now().UTC(), id, ownerID)
+	if err != nil {
+		return fmt.Errorf("撤销分享链接失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ID %d", ErrNotFound, id)
+	}
+	s.mu.Lock()
+	delete(s.limiters, id)
+	s.mu.Unlock()
+	return nil
+}
+
+const selectColumns = `SELECT id, owner_id, label, biz_name, query_json, token_prefix, rate_per_minute,
+	expires_at, revoked_at, use_count, last_used_at, created_at FROM share_links`
+
+func (s *Service) get(ctx context.Context, where string, arg any) (*domain.ShareLink, error) {
+	link, err := scanLink(s.db.QueryRowContext(ctx, selectColumns+` WHERE `+where, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询分享链接失败: %w", err)
+	}
+	return link, nil
+}
+
+func scanLink(scanner interface{ Scan(...any) error }) (*domain.ShareLink, error) {
+	var link domain.ShareLink
+	var queryJSON string
+	var revokedAt, lastUsedAt sql.NullTime
+	if err := scanner.Scan(&link.ID, &link.OwnerID, &link.Label, &link.BizName, &queryJSON, &link.TokenPrefix, &link.RatePerMinute,
+		&link.ExpiresAt, &revokedAt, &link.UseCount, &lastUsedAt, &link.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(queryJSON), &link.Query); err != nil {
+		return nil, fmt.Errorf("解析分享链接 %d 的查询失败: %w", link.ID, err)
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	if lastUsedAt.Valid {
+		link.LastUsedAt = &lastUsedAt.Time
+	}
+	return &link, nil
+}
+
+// withPage 返回带页码的查询副本，冻结的查询本身不被修改
+func withPage(query map[string]interface{}, page int) map[string]interface{} {
+	out := make(map[string]interface{}, len(query)+1)
+	for k, v := range query {
+		out[k] = v
+	}
+	out["page"] = float64(page)
+	return out
+}
+
+// newToken 生成 32 字节的随机令牌，以 URL 安全的 base64 编码
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成分享令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+// file: internal/service/sharelinks/sharelinks_service_test.go
+package sharelinks
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, *fixture.FakeDataSource) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'user'), (2, 'bob', 'x', 'user')`)
+	require.NoError(t, err)
+	// books 表有 5 条记录，secrets 表无权检索
+	var books []map[string]interface{}
+	for i := 1; i <= 5; i++ {
+		books = append(books, map[string]interface{}{"id": float64(i)})
+	}
+	source := fixture.NewFakeDataSource().WithTable("books", nil, books...).FailQueryOn("secrets", port.ErrPermissionDenied)
+	svc, err := NewService(db, map[string]port.DataSource{"library": source}, opts)
+	require.NoError(t, err)
+	return svc, source
+}
+
+func TestShareLinkLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc, source := newTestService(t, Options{})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	created, err := svc.Create(ctx, 1, CreateRequest{
+		BizName: "library",
+		Label:   "鲁迅书信",
+		Query:   map[string]interface{}{"table": "books", "size": float64(2), "filters": []interface{}{}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, created.Token, 43)
+	assert.Equal(t, created.Token[:8], created.Link.TokenPrefix)
+	assert.Equal(t, now.Add(7*24*time.Hour), created.Link.ExpiresAt.UTC())
+
+	link, result, err := svc.Open(ctx, created.Token, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "鲁迅书信", link.Label)
+	assert.Len(t, result.Data["items"], 1, "第 3 页只剩 1 条")
+	queries := source.Queries()
+	last := queries[len(queries)-1].Query
+	assert.Equal(t, float64(3), last["page"])
+	assert.Equal(t, float64(2), last["size"], "每页记录数被冻结")
+
+	links, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.EqualValues(t, 1, links[0].UseCount)
+	others, err := svc.List(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+
+	_, _, err = svc.Open(ctx, "not-a-token", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, svc.Revoke(ctx, 2, created.Link.ID), ErrNotFound, "不能撤销他人的链接")
+	require.NoError(t, svc.Revoke(ctx, 1, created.Link.ID))
+	_, _, err = svc.Open(ctx, created.Token, 1)
+	assert.ErrorIs(t, err, ErrNotFound, "撤销后立即失效")
+
+	expiring, err := svc.Create(ctx, 1, CreateRequest{BizName: "library", TTLHours: 1, Query: map[string]interface{}{"table": "books"}})
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	_, _, err = svc.Open(ctx, expiring.Token, 1)
+	assert.ErrorIs(t, err, ErrNotFound, "过期后失效")
+}
+
+func TestShareLinkValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, Options{MaxPerUser: 1, MaxPageSize: 10})
+
+	for name, tc := range map[string]struct {
+		req  CreateRequest
+		want error
+	}{
+		"缺少业务组":  {CreateRequest{Query: map[string]interface{}{"table": "books"}}, ErrInvalidShareLink},
+		"缺少表":    {CreateRequest{BizName: "library", Query: map[string]interface{}{"size": float64(1)}}, ErrInvalidShareLink},
+		"特殊模式":   {CreateRequest{BizName: "library", Query: map[string]interface{}{"table": "books", port.QueryModeKey: port.QueryModeRecord}}, ErrInvalidShareLink},
+		"每页过大":   {CreateRequest{BizName: "library", Query: map[string]interface{}{"table": "books", "size": float64(11)}}, ErrInvalidShareLink},
+		"有效期过长":  {CreateRequest{BizName: "library", TTLHours: 10000, Query: map[string]interface{}{"table": "books"}}, ErrInvalidShareLink},
+		"限流超出上限": {CreateRequest{BizName: "library", RatePerMinute: 61, Query: map[string]interface{}{"table": "books"}}, ErrInvalidShareLink},
+		"未知业务组":  {CreateRequest{BizName: "archive", Query: map[string]interface{}{"table": "books"}}, port.ErrBizNotFound},
+		"无权检索":   {CreateRequest{BizName: "library", Query: map[string]interface{}{"table": "secrets"}}, port.ErrPermissionDenied},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Create(ctx, 1, tc.req)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+
+	_, err := svc.Create(ctx, 1, CreateRequest{BizName: "library", Query: map[string]interface{}{"table": "books"}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, 1, CreateRequest{BizName: "library", Query: map[string]interface{}{"table": "books"}})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func TestShareLinkRateLimit(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t, Options{})
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	created, err := svc.Create(ctx, 1, CreateRequest{BizName: "library", RatePerMinute: 2, Query: map[string]interface{}{"table": "books"}})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, _, err = svc.Open(ctx, created.Token, 1)
+		require.NoError(t, err)
+	}
+	_, _, err = svc.Open(ctx, created.Token, 1)
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	now = now.Add(30 * time.Second)
+	_, _, err = svc.Open(ctx, created.Token, 1)
+	assert.NoError(t, err, "按每分钟的速率逐步恢复")
+}
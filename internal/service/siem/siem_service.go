@@ -0,0 +1,359 @@
+// Package siem file: internal/service/siem/siem_service.go
+package siem
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBufferSize      = 10000
+	defaultBatchSize       = 100
+	defaultRetryMaxSeconds = 300
+	retryBase              = 2 * time.Second
+
+	// 接收端名称，同时是发送队列中的 sink 列的值
+	SinkSyslog = "syslog"
+	SinkHTTP   = "http"
+)
+
+// SyslogOptions 定义 syslog 接收端，Address 为空表示不发送到 syslog
+type SyslogOptions struct {
+	// Network 是 udp 或 tcp，默认为 udp。tcp 按 RFC 6587 的八位组计数方式分帧
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"` // host:port
+	// AppName 是 RFC 5424 消息头中的 APP-NAME，默认为 archiveaegis
+	AppName string `mapstructure:"app_name"`
+	// Facility 是 syslog 设施编号 (0-23)，默认为 10 (authpriv)
+	Facility int `mapstructure:"facility"`
+}
+
+// HTTPOptions 定义 HTTP 接收端，URL 为空表示不发送。事件以 JSON 数组 POST 到该地址
+type HTTPOptions struct {
+	URL string `mapstructure:"url"`
+	// Headers 是附加的请求头，通常用于携带采集端的认证 Token
+	Headers        map[string]string `mapstructure:"headers"`
+	TimeoutSeconds int               `mapstructure:"timeout_seconds"` // 默认为 10
+}
+
+// Options 定义安全事件转发的配置，syslog 与 http 都未配置时不记录也不转发事件
+type Options struct {
+	Syslog SyslogOptions `mapstructure:"syslog"`
+	HTTP   HTTPOptions   `mapstructure:"http"`
+	// BufferSize 是每个接收端最多保留的待发送事件数，超出时丢弃最旧的事件，默认为 10000
+	BufferSize int `mapstructure:"buffer_size"`
+	// BatchSize 是每次投递的最多事件数，默认为 100
+	BatchSize int `mapstructure:"batch_size"`
+	// RetryMaxSeconds 是投递失败后重试间隔的上限 (秒)，间隔从 2 秒开始逐次翻倍，默认为 300
+	RetryMaxSeconds int `mapstructure:"retry_max_seconds"`
+}
+
+// sink 把一批事件投递到一个接收端，返回错误时整批事件保留在队列中等待重试
+type sink interface {
+	deliver(ctx context.Context, events []domain.SecurityEvent) error
+}
+
+// sinkState 是一个接收端在内存中的重试状态
+type sinkState struct {
+	failures      int
+	retryAt       time.Time
+	lastError     string
+	lastDelivered *time.Time
+}
+
+// Service 把登录、锁定、封禁、管理变更等安全事件转发到 syslog 或 HTTP SIEM 采集端。
+// 事件先写入 auth.db 中的发送队列 (每个接收端一份)，由 Run 按顺序批量投递，投递成功后删除；
+// 采集端不可用时按指数退避重试，事件在队列中保留，网关重启也不会丢失。投递语义为至少一次
+type Service struct {
+	db    *sql.DB
+	opts  Options
+	now   func() time.Time
+	sinks map[string]sink
+	order []string
+	wake  chan struct{}
+
+	dropped atomic.Int64
+	// flushMu 保证同一时刻只有一次投递，避免定期投递与手动投递重复发送同一批事件
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	states  map[string]*sinkState
+}
+
+// NewService 创建一个新的安全事件转发服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("siem.Service 需要一个有效的数据库连接")
+	}
+	if opts.BufferSize < 0 || opts.BatchSize < 0 || opts.RetryMaxSeconds < 0 || opts.HTTP.TimeoutSeconds < 0 {
+		return nil, errors.New("buffer_size、batch_size、retry_max_seconds 与 timeout_seconds 不能为负数")
+	}
+	if opts.BufferSize == 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.RetryMaxSeconds == 0 {
+		opts.RetryMaxSeconds = defaultRetryMaxSeconds
+	}
+	s := &Service{
+		db:     db,
+		opts:   opts,
+		now:    time.Now,
+		sinks:  make(map[string]sink),
+		wake:   make(chan struct{}, 1),
+		states: make(map[string]*sinkState),
+	}
+	if opts.Syslog.Address != "" {
+		out, err := newSyslogSink(opts.Syslog)
+		if err != nil {
+			return nil, err
+		}
+		s.addSink(SinkSyslog, out)
+	}
+	if opts.HTTP.URL != "" {
+		out, err := newHTTPSink(opts.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		s.addSink(SinkHTTP, out)
+	}
+	return s, nil
+}
+
+func (s *Service) addSink(name string, out sink) {
+	s.sinks[name] = out
+	s.order = append(s.order, name)
+	s.states[name] = &sinkState{}
+}
+
+// Enabled 返回是否配置了至少一个接收端
+func (s *Service) Enabled() bool {
+	return len(s.order) > 0
+}
+
+// Emit 把一个安全事件加入每个接收端的发送队列并唤醒投递，不等待投递完成。
+// 未配置接收端时什么也不做；写入队列失败只记录日志，不影响触发事件的请求
+func (s *Service) Emit(ctx context.Context, event domain.SecurityEvent) {
+	if !s.Enabled() {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = s.now().UTC()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("[SIEM] 序列化安全事件失败", "type", event.Type, "error", err)
+		return
+	}
+	// 请求结束后 ctx 可能已取消，但事件仍然需要入队
+	ctx = context.WithoutCancel(ctx)
+	for _, name := range s.order {
+		if err := s.enqueue(ctx, name, string(payload)); err != nil {
+			slog.Error("[SIEM] 安全事件入队失败", "sink", name, "type", event.Type, "error", err)
+		}
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue 写入一条事件，队列超出 BufferSize 时丢弃最旧的事件
+func (s *Service) enqueue(ctx context.Context, name, payload string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO security_event_outbox (sink, payload, created_at) VALUES (?, ?, ?)`, name, payload, s.now().UTC()); err != nil {
+		return fmt.Errorf("写入安全事件队列失败: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM security_event_outbox WHERE sink = ? AND id <= (
+			SELECT id FROM security_event_outbox WHERE sink = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		name, name, s.opts.BufferSize)
+	if err != nil {
+		return fmt.Errorf("清理安全事件队列失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		s.dropped.Add(n)
+		slog.Warn("[SIEM] 发送队列已满，已丢弃最旧的安全事件", "sink", name, "count", n)
+	}
+	return nil
+}
+
+// PenaltyLocked 把惩罚记录进入锁定或封禁转换为安全事件，供 penalties.Service 的锁定回调使用
+func (s *Service) PenaltyLocked(p domain.Penalty) {
+	event := domain.SecurityEvent{Type: domain.SecurityEventBan, Subject: p.Subject, Detail: map[string]interface{}{"kind": p.Kind}}
+	if p.LockedUntil != nil {
+		event.Detail["until"] = p.LockedUntil.UTC()
+	}
+	if p.Kind == domain.PenaltyKindLogin {
+		// 登录锁定的对象为 "IP|用户名"
+		event.Type = domain.SecurityEventLoginLockout
+		event.IP, event.Username, _ = strings.Cut(p.Subject, "|")
+	} else if p.Kind == domain.PenaltyKindIP {
+		event.IP = p.Subject
+	}
+	s.Emit(context.Background(), event)
+}
+
+// Run 按间隔或在有新事件时投递队列中的事件，ctx 结束时返回。未配置接收端时立即返回
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if !s.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("[SIEM] 投递安全事件失败", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Flush 立即把各接收端队列中的事件按顺序批量投递，处于退避期的接收端被跳过，返回投递成功的事件数。
+// 某个接收端投递失败时记录错误并安排重试，不影响其它接收端；只有读写队列失败才返回错误
+func (s *Service) Flush(ctx context.Context) (int, error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	delivered := 0
+	for _, name := range s.order {
+		for {
+			s.mu.Lock()
+			waiting := s.now().Before(s.states[name].retryAt)
+			s.mu.Unlock()
+			if waiting {
+				break
+			}
+			n, more, err := s.flushBatch(ctx, name)
+			delivered += n
+			if err != nil {
+				return delivered, err
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return delivered, nil
+}
+
+// flushBatch 投递一个接收端最旧的一批事件。more 表示本批已满且投递成功，队列中可能还有事件
+func (s *Service) flushBatch(ctx context.Context, name string) (delivered int, more bool, err error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, payload FROM security_event_outbox WHERE sink = ? ORDER BY id LIMIT ?`, name, s.opts.BatchSize)
+	if err != nil {
+		return 0, false, fmt.Errorf("读取安全事件队列失败: %w", err)
+	}
+	var ids []int64
+	var events []domain.SecurityEvent
+	for rows.Next() {
+		var id int64
+		var payload string
+		if err := rows.Scan(&id, &payload); err != nil {
+			rows.Close()
+			return 0, false, fmt.Errorf("读取安全事件队列失败: %w", err)
+		}
+		var event domain.SecurityEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			// 无法解析的事件永远无法投递，跳过并随本批一起删除
+			slog.Error("[SIEM] 丢弃无法解析的安全事件", "id", id, "error", err)
+		} else {
+			events = append(events, event)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, false, fmt.Errorf("读取安全事件队列失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, false, nil
+	}
+
+	if len(events) > 0 {
+		if derr := s.sinks[name].deliver(ctx, events); derr != nil {
+			s.recordFailure(name, derr)
+			if _, err := s.db.ExecContext(ctx, `UPDATE security_event_outbox SET attempts = attempts + 1 WHERE sink = ? AND id <= ?`,
+				name, ids[len(ids)-1]); err != nil {
+				return 0, false, fmt.Errorf("更新安全事件队列失败: %w", err)
+			}
+			return 0, false, nil
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM security_event_outbox WHERE sink = ? AND id <= ?`, name, ids[len(ids)-1]); err != nil {
+		return 0, false, fmt.Errorf("删除已投递的安全事件失败: %w", err)
+	}
+	s.recordSuccess(name)
+	return len(events), len(ids) == s.opts.BatchSize, nil
+}
+
+// recordFailure 记录投递失败，并按连续失败次数安排下一次重试
+func (s *Service) recordFailure(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.states[name]
+	delay := retryBase << min(state.failures, 16)
+	if limit := time.Duration(s.opts.RetryMaxSeconds) * time.Second; delay > limit {
+		delay = limit
+	}
+	state.failures++
+	state.retryAt = s.now().Add(delay)
+	state.lastError = err.Error()
+	slog.Warn("[SIEM] 投递安全事件失败，稍后重试", "sink", name, "failures", state.failures, "retry_in", delay, "error", err)
+}
+
+func (s *Service) recordSuccess(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.states[name]
+	now := s.now().UTC()
+	state.failures = 0
+	state.retryAt = time.Time{}
+	state.lastError = ""
+	state.lastDelivered = &now
+}
+
+// Status 返回各接收端的队列长度与投递状态
+func (s *Service) Status(ctx context.Context) (*domain.SecurityForwarderStatus, error) {
+	status := &domain.SecurityForwarderStatus{Sinks: make([]domain.SecuritySinkStatus, 0, len(s.order)), Dropped: s.dropped.Load()}
+	for _, name := range s.order {
+		item := domain.SecuritySinkStatus{Sink: name}
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM security_event_outbox WHERE sink = ?`, name).Scan(&item.Pending); err != nil {
+			return nil, fmt.Errorf("统计安全事件队列失败: %w", err)
+		}
+		if item.Pending > 0 {
+			var oldest time.Time
+			if err := s.db.QueryRowContext(ctx, `SELECT created_at FROM security_event_outbox WHERE sink = ? ORDER BY id LIMIT 1`, name).
+				Scan(&oldest); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("统计安全事件队列失败: %w", err)
+			} else if err == nil {
+				item.OldestPending = &oldest
+			}
+		}
+		s.mu.Lock()
+		state := s.states[name]
+		item.LastError = state.lastError
+		item.LastDelivered = state.lastDelivered
+		if !state.retryAt.IsZero() {
+			retryAt := state.retryAt.UTC()
+			item.NextRetry = &retryAt
+		}
+		s.mu.Unlock()
+		status.Sinks = append(status.Sinks, item)
+	}
+	return status, nil
+}
@@ -0,0 +1,185 @@
+// file: internal/service/siem/siem_service_test.go
+package siem
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService_Validation(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+	_, err := NewService(nil, Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, Options{BufferSize: -1})
+	assert.Error(t, err)
+	_, err = NewService(db, Options{Syslog: SyslogOptions{Address: "collector", Network: "udp"}})
+	assert.Error(t, err, "地址缺少端口")
+	_, err = NewService(db, Options{Syslog: SyslogOptions{Address: "127.0.0.1:514", Network: "unix"}})
+	assert.Error(t, err)
+	_, err = NewService(db, Options{HTTP: HTTPOptions{URL: "ftp://collector"}})
+	assert.Error(t, err)
+
+	svc, err := NewService(db, Options{})
+	require.NoError(t, err)
+	assert.False(t, svc.Enabled())
+	svc.Emit(context.Background(), domain.SecurityEvent{Type: domain.SecurityEventLoginSuccess})
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM security_event_outbox`).Scan(&n))
+	assert.Zero(t, n, "未配置接收端时不记录事件")
+}
+
+func TestHTTPSink_RetriesUntilCollectorRecovers(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	down := true
+	var received []domain.SecurityEvent
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "Splunk secret", r.Header.Get("Authorization"))
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var batch []domain.SecurityEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		received = append(received, batch...)
+	}))
+	defer collector.Close()
+
+	db := fixture.NewSystemDB(t)
+	svc, err := NewService(db, Options{BatchSize: 2, HTTP: HTTPOptions{URL: collector.URL, Headers: map[string]string{"Authorization": "Splunk secret"}}})
+	require.NoError(t, err)
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	for _, user := range []string{"a", "b", "c"} {
+		svc.Emit(ctx, domain.SecurityEvent{Type: domain.SecurityEventLoginFailure, Username: user, IP: "10.0.0.1"})
+	}
+	delivered, err := svc.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status.Sinks, 1)
+	assert.Equal(t, int64(3), status.Sinks[0].Pending, "采集端不可用时事件留在队列中")
+	assert.Contains(t, status.Sinks[0].LastError, "503")
+	require.NotNil(t, status.Sinks[0].NextRetry)
+	assert.Equal(t, now.Add(retryBase), *status.Sinks[0].NextRetry)
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	delivered, err = svc.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered, "退避期内不重试")
+
+	now = now.Add(retryBase)
+	delivered, err = svc.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	mu.Lock()
+	require.Len(t, received, 3)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{received[0].Username, received[1].Username, received[2].Username}, "按发生顺序投递")
+	assert.Equal(t, now.Add(-retryBase), received[0].Time)
+	mu.Unlock()
+
+	status, err = svc.Status(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, status.Sinks[0].Pending)
+	assert.Empty(t, status.Sinks[0].LastError)
+	assert.Nil(t, status.Sinks[0].NextRetry)
+}
+
+func TestEmit_DropsOldestWhenBufferFull(t *testing.T) {
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	svc, err := NewService(db, Options{BufferSize: 2, HTTP: HTTPOptions{URL: "http://127.0.0.1:1"}})
+	require.NoError(t, err)
+	for _, user := range []string{"a", "b", "c"} {
+		svc.Emit(ctx, domain.SecurityEvent{Type: domain.SecurityEventLoginSuccess, Username: user})
+	}
+	rows, err := db.Query(`SELECT payload FROM security_event_outbox ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var users []string
+	for rows.Next() {
+		var payload string
+		require.NoError(t, rows.Scan(&payload))
+		var event domain.SecurityEvent
+		require.NoError(t, json.Unmarshal([]byte(payload), &event))
+		users = append(users, event.Username)
+	}
+	assert.Equal(t, []string{"b", "c"}, users)
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.Dropped)
+}
+
+func TestSyslogSink_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	frames := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(prefix))
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			frames <- string(buf)
+		}
+	}()
+
+	svc, err := NewService(fixture.NewSystemDB(t), Options{Syslog: SyslogOptions{Network: "tcp", Address: ln.Addr().String(), AppName: "aegis gw"}})
+	require.NoError(t, err)
+	at := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	until := at.Add(time.Hour)
+	svc.now = func() time.Time { return at }
+	svc.PenaltyLocked(domain.Penalty{Kind: domain.PenaltyKindLogin, Subject: "10.0.0.1|alice", LockedUntil: &until})
+	svc.Emit(context.Background(), domain.SecurityEvent{Type: domain.SecurityEventLoginSuccess, Username: "bob"})
+	delivered, err := svc.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	first := <-frames
+	// authpriv (10) * 8 + warning (4) = 84
+	assert.True(t, strings.HasPrefix(first, "<84>1 2026-05-01T08:00:00Z "), first)
+	assert.Contains(t, first, " aegisgw ", "APP-NAME 中的空白被去除")
+	assert.Contains(t, first, " login_lockout - {")
+	var event domain.SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(first[strings.Index(first, "{"):]), &event))
+	assert.Equal(t, "10.0.0.1", event.IP)
+	assert.Equal(t, "alice", event.Username)
+	assert.Equal(t, domain.PenaltyKindLogin, event.Detail["kind"])
+
+	second := <-frames
+	assert.True(t, strings.HasPrefix(second, "<86>1 "), "登录成功为 info 级别")
+}
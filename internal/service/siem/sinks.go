@@ -0,0 +1,183 @@
+// Package siem file: internal/service/siem/sinks.go
+package siem
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAppName        = "archiveaegis"
+	defaultFacility       = 10 // authpriv
+	defaultHTTPTimeout    = 10 * time.Second
+	syslogDialTimeout     = 10 * time.Second
+	syslogWriteTimeout    = 30 * time.Second
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	syslogSeverityInfo    = 6
+)
+
+// syslogSink 以 RFC 5424 格式发送事件，消息体为事件的 JSON。每批事件使用一个新连接
+type syslogSink struct {
+	network  string
+	address  string
+	appName  string
+	facility int
+	hostname string
+	procID   string
+}
+
+func newSyslogSink(opts SyslogOptions) (*syslogSink, error) {
+	network := opts.Network
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("syslog.network 只能是 udp 或 tcp: '%s'", network)
+	}
+	if _, _, err := net.SplitHostPort(opts.Address); err != nil {
+		return nil, fmt.Errorf("syslog.address 必须是 host:port 格式: %w", err)
+	}
+	facility := opts.Facility
+	if facility == 0 {
+		facility = defaultFacility
+	}
+	if facility < 0 || facility > 23 {
+		return nil, fmt.Errorf("syslog.facility 必须在 0 到 23 之间: %d", facility)
+	}
+	appName := opts.AppName
+	if appName == "" {
+		appName = defaultAppName
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{
+		network:  network,
+		address:  opts.Address,
+		appName:  headerField(appName, 48),
+		facility: facility,
+		hostname: headerField(hostname, 255),
+		procID:   fmt.Sprint(os.Getpid()),
+	}, nil
+}
+
+func (s *syslogSink) deliver(ctx context.Context, events []domain.SecurityEvent) error {
+	dialer := net.Dialer{Timeout: syslogDialTimeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return fmt.Errorf("连接 syslog 服务器失败: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	for _, event := range events {
+		msg, err := s.format(event)
+		if err != nil {
+			return err
+		}
+		if s.network == "tcp" {
+			// RFC 6587 八位组计数分帧，消息体中可以包含换行
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			return fmt.Errorf("发送 syslog 消息失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// format 生成一条 RFC 5424 消息：<PRI>1 时间 主机 应用 进程 MSGID - JSON
+func (s *syslogSink) format(event domain.SecurityEvent) ([]byte, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("序列化安全事件失败: %w", err)
+	}
+	pri := s.facility*8 + severity(event.Type)
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s - ", pri, event.Time.UTC().Format(time.RFC3339Nano),
+		s.hostname, s.appName, s.procID, headerField(event.Type, 32))
+	return append([]byte(header), body...), nil
+}
+
+// severity 按事件类型确定 syslog 严重级别
+func severity(eventType string) int {
+	switch eventType {
+	case domain.SecurityEventLoginFailure, domain.SecurityEventLoginLockout, domain.SecurityEventBan:
+		return syslogSeverityWarning
+	case domain.SecurityEventAdminChange:
+		return syslogSeverityNotice
+	default:
+		return syslogSeverityInfo
+	}
+}
+
+// headerField 把值限制为 RFC 5424 消息头允许的可打印 ASCII 字符与长度，空值用 "-" 表示
+func headerField(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// httpSink 把一批事件以 JSON 数组 POST 到采集端，2xx 视为投递成功
+type httpSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPSink(opts HTTPOptions) (*httpSink, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("http.url 必须是 http 或 https 地址: '%s'", opts.URL)
+	}
+	timeout := defaultHTTPTimeout
+	if opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	return &httpSink{url: opts.URL, headers: opts.Headers, client: &http.Client{Timeout: timeout}}, nil
+}
+
+func (s *httpSink) deliver(ctx context.Context, events []domain.SecurityEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("序列化安全事件失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 SIEM 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送到 SIEM 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("SIEM 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Package signing file: internal/service/signing/signing_service.go
+package signing
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Scheme 是签名请求 Authorization 头的认证方案
+	Scheme = "AEGIS-HMAC-SHA256"
+	// DateHeader 携带请求的签名时间 (RFC 3339)
+	DateHeader = "X-Aegis-Date"
+	// NonceHeader 携带客户端生成的随机值，使同一时刻发出的相同请求也有不同的签名
+	NonceHeader = "X-Aegis-Nonce"
+	// Issuer 是签名请求注入的 Claim 的发行方，用于将其与 JWT 区分
+	Issuer = "ArchiveAegis-HMAC"
+
+	defaultClockSkew    = 5 * time.Minute
+	defaultMaxBodyBytes = 10 << 20
+	maxNonceLength      = 128
+)
+
+var (
+	// ErrInvalidKey 表示创建签名密钥的参数不合法
+	ErrInvalidKey = errors.New("无效的签名密钥参数")
+	// ErrNotFound 表示签名密钥不存在或已撤销
+	ErrNotFound = errors.New("签名密钥不存在或已撤销")
+	// ErrUnauthorized 表示请求的签名无效、过期或被重放
+	ErrUnauthorized = errors.New("请求签名校验失败")
+)
+
+// Options 定义请求签名的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ClockSkewSeconds 是签名时间与服务器时间允许的最大偏差，默认为 300 秒。
+	// 同一签名在该窗口内只能使用一次
+	ClockSkewSeconds int `mapstructure:"clock_skew_seconds"`
+	// MaxBodyBytes 是签名请求体的大小上限，默认为 10 MiB
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+}
+
+// Service 为服务器间的集成提供 HMAC 请求签名认证，作为 JWT 之外的另一种认证方式。
+// 客户端用签名密钥对请求方法、路径、规范化的查询参数、签名时间、随机值与请求体的 SHA-256 做 HMAC-SHA256，
+// 通过后请求以密钥所属用户的身份执行，之后的限流与权限检查与 JWT 认证的请求相同。
+type Service struct {
+	db        *sql.DB
+	clockSkew time.Duration
+	maxBody   int64
+	now       func() time.Time
+
+	mu        sync.Mutex
+	seen      map[string]time.Time // 窗口内已使用的签名 -> 过期时间
+	lastPrune time.Time
+}
+
+// NewService 创建一个新的请求签名服务实例
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("signing.Service 需要一个有效的数据库连接")
+	}
+	if opts.ClockSkewSeconds < 0 || opts.MaxBodyBytes < 0 {
+		return nil, errors.New("请求签名的配置项不能为负数")
+	}
+	s := &Service{
+		db:        db,
+		clockSkew: defaultClockSkew,
+		maxBody:   defaultMaxBodyBytes,
+		now:       time.Now,
+		seen:      make(map[string]time.Time),
+	}
+	if opts.ClockSkewSeconds > 0 {
+		s.clockSkew = time.Duration(opts.ClockSkewSeconds) * time.Second
+	}
+	if opts.MaxBodyBytes > 0 {
+		s.maxBody = opts.MaxBodyBytes
+	}
+	return s, nil
+}
+
+// CreateKey 为一个用户 (通常是服务账户) 签发签名密钥，返回的签名密钥只在此时可见
+func (s *Service) CreateKey(ctx context.Context, userID int64, label string) (*domain.SigningKey, string, error) {
+	if userID <= 0 {
+		return nil, "", fmt.Errorf("%w: 必须提供 user_id", ErrInvalidKey)
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM _user WHERE id = ?)`, userID).Scan(&exists); err != nil {
+		return nil, "", fmt.Errorf("查询用户失败: %w", err)
+	}
+	if !exists {
+		return nil, "", fmt.Errorf("%w: 用户 %d 不存在", ErrInvalidKey, userID)
+	}
+
+	keyID, err := randomString(9)
+	if err != nil {
+		return nil, "", err
+	}
+	keyID = "sk_" + keyID
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO signing_keys (key_id, secret, label, user_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+		keyID, secret, strings.TrimSpace(label), userID, s.now().UTC())
+	if err != nil {
+		return nil, "", fmt.Errorf("写入签名密钥失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+	key, _, _, err := s.getKey(ctx, `k.id = ?`, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// ListKeys 返回全部签名密钥，不含签名密钥本身
+func (s *Service) ListKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	rows, err := s.db.QueryContext(ctx, selectColumns+` ORDER BY k.id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询签名密钥失败: %w", err)
+	}
+	defer rows.Close()
+	out := []domain.SigningKey{}
+	for rows.Next() {
+		key, _, _, err := scanKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描签名密钥失败: %w", err)
+		}
+		out = append(out, *key)
+	}
+	return out, rows.Err()
+}
+
+// RevokeKey 撤销签名密钥，之后用它签名的请求全部被拒绝
+func (s *Service) RevokeKey(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE signing_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, s.now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("撤销签名密钥失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ID %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+// Middleware 校验使用 AEGIS-HMAC-SHA256 方案的请求，通过后将密钥所属用户的 Claim 注入请求上下文。
+// 其它请求原样放行，交由 JWT 中间件处理；签名校验失败的请求直接以 401 拒绝，而不是降级为匿名访问。
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsSigned(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		claims, err := s.Verify(r)
+		if err != nil {
+			slog.Warn("[Signing] 签名请求校验失败", "path", r.URL.Path, "error", err)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), service.ClaimKey, claims)))
+	})
+}
+
+// IsSigned 判断请求是否使用了签名认证方案
+func IsSigned(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Authorization"), Scheme+" ")
+}
+
+// Verify 校验签名请求并返回对应的 Claim。校验需要读取请求体，读取后请求体会被还原，供后续处理器使用
+func (s *Service) Verify(r *http.Request) (*service.Claim, error) {
+	keyID, signature, err := parseAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	date := r.Header.Get(DateHeader)
+	signedAt, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s 必须是 RFC 3339 格式的时间", ErrUnauthorized, DateHeader)
+	}
+	now := s.now()
+	if signedAt.Before(now.Add(-s.clockSkew)) || signedAt.After(now.Add(s.clockSkew)) {
+		return nil, fmt.Errorf("%w: 签名时间超出允许的时钟偏差 (%s)", ErrUnauthorized, s.clockSkew)
+	}
+	nonce := r.Header.Get(NonceHeader)
+	if len(nonce) > maxNonceLength {
+		return nil, fmt.Errorf("%w: %s 过长", ErrUnauthorized, NonceHeader)
+	}
+
+	body, err := s.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	key, secret, role, err := s.getKey(r.Context(), `k.key_id = ? AND k.revoked_at IS NULL`, keyID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: 密钥 '%s' 不存在或已撤销", ErrUnauthorized, keyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	expected := Signature(secret, StringToSign(r, date, nonce, BodyHash(body)))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, fmt.Errorf("%w: 签名不匹配", ErrUnauthorized)
+	}
+	if !s.remember(keyID+":"+signature, signedAt.Add(s.clockSkew)) {
+		return nil, fmt.Errorf("%w: 签名已被使用，疑似重放", ErrUnauthorized)
+	}
+
+	if _, err := s.db.ExecContext(r.Context(), `UPDATE signing_keys SET last_used_at = ? WHERE id = ?`, now.UTC(), key.ID); err != nil {
+		slog.Warn("[Signing] 更新签名密钥的使用时间失败", "key_id", keyID, "error", err)
+	}
+	return &service.Claim{
+		ID:   key.UserID,
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   Issuer,
+			Subject:  keyID,
+			IssuedAt: jwt.NewNumericDate(signedAt),
+		},
+	}, nil
+}
+
+// readBody 读取并还原请求体，超过大小上限时拒绝
+func (s *Service) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.maxBody+1))
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	if int64(len(body)) > s.maxBody {
+		return nil, fmt.Errorf("%w: 请求体超过 %d 字节", ErrUnauthorized, s.maxBody)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// remember 记录窗口内使用过的签名，已存在时返回 false。过期的记录每分钟清理一次
+func (s *Service) remember(signature string, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastPrune) > time.Minute {
+		for sig, exp := range s.seen {
+			if now.After(exp) {
+				delete(s.seen, sig)
+			}
+		}
+		s.lastPrune = now
+	}
+	if exp, ok := s.seen[signature]; ok && !now.After(exp) {
+		return false
+	}
+	s.seen[signature] = expires
+	return true
+}
+
+// parseAuthorization 解析 "AEGIS-HMAC-SHA256 Credential=<key_id>, Signature=<hex>"
+func parseAuthorization(header string) (keyID, signature string, err error) {
+	params, ok := strings.CutPrefix(header, Scheme+" ")
+	if !ok {
+		return "", "", fmt.Errorf("%w: Authorization 头必须以 %s 开头", ErrUnauthorized, Scheme)
+	}
+	for _, part := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "Credential":
+			keyID = value
+		case "Signature":
+			signature = value
+		}
+	}
+	if keyID == "" || signature == "" {
+		return "", "", fmt.Errorf("%w: Authorization 头必须包含 Credential 与 Signature", ErrUnauthorized)
+	}
+	return keyID, signature, nil
+}
+
+// StringToSign 构造待签名的字符串，各部分以换行分隔：
+// 认证方案、请求方法、转义后的路径、规范化的查询参数 (键排序、URL 编码)、签名时间、随机值、请求体的 SHA-256
+func StringToSign(r *http.Request, date, nonce, bodyHash string) string {
+	return strings.Join([]string{
+		Scheme,
+		strings.ToUpper(r.Method),
+		r.URL.EscapedPath(),
+		r.URL.Query().Encode(),
+		date,
+		nonce,
+		bodyHash,
+	}, "\n")
+}
+
+// BodyHash 返回请求体 SHA-256 的十六进制表示，空请求体同样参与计算
+func BodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Signature 计算待签名字符串的 HMAC-SHA256，结果以十六进制表示
+func Signature(secret, stringToSign string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest 为请求设置签名所需的请求头，供 Go 编写的客户端与测试使用。body 必须与请求实际发送的内容一致
+func SignRequest(r *http.Request, keyID, secret string, body []byte, at time.Time) error {
+	nonce, err := randomString(12)
+	if err != nil {
+		return err
+	}
+	date := at.UTC().Format(time.RFC3339)
+	r.Header.Set(DateHeader, date)
+	r.Header.Set(NonceHeader, nonce)
+	sig := Signature(secret, StringToSign(r, date, nonce, BodyHash(body)))
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s, Signature=%s", Scheme, keyID, sig))
+	return nil
+}
+
+const selectColumns = `SELECT k.id, k.key_id, k.secret, k.label, k.user_id, u.username, u.role, k.created_at, k.last_used_at, k.revoked_at
+	FROM signing_keys k JOIN _user u ON u.id = k.user_id`
+
+func (s *Service) getKey(ctx context.Context, where string, arg any) (*domain.SigningKey, string, string, error) {
+	key, secret, role, err := scanKey(s.db.QueryRowContext(ctx, selectColumns+` WHERE `+where, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("查询签名密钥失败: %w", err)
+	}
+	return key, secret, role, nil
+}
+
+func scanKey(scanner interface{ Scan(...any) error }) (*domain.SigningKey, string, string, error) {
+	var key domain.SigningKey
+	var secret, role string
+	var lastUsedAt, revokedAt sql.NullTime
+	if err := scanner.Scan(&key.ID, &key.KeyID, &secret, &key.Label, &key.UserID, &key.Username, &role, &key.CreatedAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, "", "", err
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, secret, role, nil
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机值失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,135 @@
+// file: internal/service/signing/signing_service_test.go
+package signing
+
+import (
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, int64) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	userID, _, err := service.CreateServiceAccount(db, "svc-ingest")
+	require.NoError(t, err)
+	svc, err := NewService(db, Options{ClockSkewSeconds: 60})
+	require.NoError(t, err)
+	return svc, userID
+}
+
+// serve 让请求经过中间件，返回状态码、下游看到的 Claim 与请求体
+func serve(svc *Service, req *http.Request) (int, *service.Claim, string) {
+	var claims *service.Claim
+	var body []byte
+	handler := svc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = service.ClaimFrom(r)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Code, claims, string(body)
+}
+
+func signedRequest(t *testing.T, keyID, secret, body string, at time.Time) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/data/query?view=cards&aliased=true", bytes.NewBufferString(body))
+	require.NoError(t, SignRequest(req, keyID, secret, []byte(body), at))
+	return req
+}
+
+func TestSignedRequest(t *testing.T) {
+	ctx := context.Background()
+	svc, userID := newTestService(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	key, secret, err := svc.CreateKey(ctx, userID, "夜间同步")
+	require.NoError(t, err)
+	assert.Equal(t, "svc-ingest", key.Username)
+
+	body := `{"biz_name":"library","query":{"table":"books"}}`
+	req := signedRequest(t, key.KeyID, secret, body, now.Add(-30*time.Second))
+	code, claims, seenBody := serve(svc, req)
+	require.Equal(t, http.StatusOK, code)
+	require.NotNil(t, claims)
+	assert.Equal(t, userID, claims.ID)
+	assert.Equal(t, "admin", claims.Role, "以密钥所属用户的角色执行")
+	assert.Equal(t, Issuer, claims.Issuer)
+	assert.Equal(t, body, seenBody, "校验后请求体被还原")
+
+	// 同一签名在窗口内只能使用一次
+	replay := signedRequest(t, key.KeyID, secret, body, now)
+	replay.Header = req.Header.Clone()
+	code, _, _ = serve(svc, replay)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	keys, err := svc.ListKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].LastUsedAt)
+
+	// 未使用签名方案的请求原样放行
+	code, claims, _ = serve(svc, httptest.NewRequest(http.MethodGet, "/api/v1/meta/biz", nil))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, claims)
+}
+
+func TestSignedRequestRejected(t *testing.T) {
+	ctx := context.Background()
+	svc, userID := newTestService(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	key, secret, err := svc.CreateKey(ctx, userID, "")
+	require.NoError(t, err)
+
+	cases := map[string]func() *http.Request{
+		"篡改请求体": func() *http.Request {
+			req := signedRequest(t, key.KeyID, secret, `{"a":1}`, now)
+			req.Body = io.NopCloser(bytes.NewBufferString(`{"a":2}`))
+			return req
+		},
+		"篡改查询参数": func() *http.Request {
+			req := signedRequest(t, key.KeyID, secret, "", now)
+			req.URL.RawQuery = "view=table"
+			return req
+		},
+		"超出时钟偏差": func() *http.Request {
+			return signedRequest(t, key.KeyID, secret, "", now.Add(-2*time.Minute))
+		},
+		"错误的密钥": func() *http.Request {
+			return signedRequest(t, key.KeyID, "wrong-secret", "", now)
+		},
+		"未知的密钥": func() *http.Request {
+			return signedRequest(t, "sk_unknown", secret, "", now)
+		},
+		"缺少签名": func() *http.Request {
+			req := signedRequest(t, key.KeyID, secret, "", now)
+			req.Header.Set("Authorization", Scheme+" Credential="+key.KeyID)
+			return req
+		},
+	}
+	for name, build := range cases {
+		code, claims, _ := serve(svc, build())
+		assert.Equal(t, http.StatusUnauthorized, code, name)
+		assert.Nil(t, claims, name)
+	}
+
+	// 撤销后立即失效
+	require.NoError(t, svc.RevokeKey(ctx, key.ID))
+	code, _, _ := serve(svc, signedRequest(t, key.KeyID, secret, "", now))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.ErrorIs(t, svc.RevokeKey(ctx, key.ID), ErrNotFound)
+
+	_, _, err = svc.CreateKey(ctx, 9999, "")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,164 @@
+// Package sitemap file: internal/service/sitemap/render.go
+package sitemap
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	schemaOrgContext = "https://schema.org"
+)
+
+type sitemapIndexXML struct {
+	XMLName  xml.Name        `xml:"sitemapindex"`
+	XMLNS    string          `xml:"xmlns,attr"`
+	Sitemaps []sitemapRefXML `xml:"sitemap"`
+}
+
+type sitemapRefXML struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSetXML struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []urlXML `xml:"url"`
+}
+
+type urlXML struct {
+	Loc string `xml:"loc"`
+}
+
+// SitemapPath 返回某一页站点地图相对站点根的路径
+func SitemapPath(bizName, tableName string, page int) string {
+	return fmt.Sprintf("/sitemaps/%s/%s/%d.xml", url.PathEscape(bizName), url.PathEscape(tableName), page)
+}
+
+// IndexXML 生成站点地图索引 (sitemapindex)
+func (s *Service) IndexXML(ctx context.Context, baseURL string) ([]byte, error) {
+	index, err := s.Index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc := sitemapIndexXML{XMLNS: sitemapNamespace, Sitemaps: make([]sitemapRefXML, 0, len(index))}
+	for _, sm := range index {
+		doc.Sitemaps = append(doc.Sitemaps, sitemapRefXML{
+			Loc:     baseURL + SitemapPath(sm.BizName, sm.TableName, sm.Page),
+			LastMod: sm.LastMod.UTC().Format(time.RFC3339),
+		})
+	}
+	return marshalXML(doc)
+}
+
+// SitemapXML 生成一张表某一页记录的站点地图 (urlset)
+func (s *Service) SitemapXML(ctx context.Context, baseURL, bizName, tableName string, page int) ([]byte, error) {
+	entries, err := s.Page(ctx, bizName, tableName, page)
+	if err != nil {
+		return nil, err
+	}
+	doc := urlSetXML{XMLNS: sitemapNamespace, URLs: make([]urlXML, 0, len(entries))}
+	for _, e := range entries {
+		doc.URLs = append(doc.URLs, urlXML{Loc: Permalink(baseURL, bizName, tableName, e.ID, e.Lib)})
+	}
+	return marshalXML(doc)
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// JSONLD 生成单条记录的 schema.org 结构化元数据。记录以 CreativeWork 描述，
+// 所在的表以 Dataset 描述，其余可返回字段以 PropertyValue 列出。
+// 记录的可见性与记录接口相同，由数据源按公开检索配置判断。
+func (s *Service) JSONLD(ctx context.Context, baseURL, bizName, tableName, recordID, libName string) (map[string]interface{}, error) {
+	dataSource, ok := s.registry[bizName]
+	if !ok {
+		return nil, port.ErrBizNotFound
+	}
+	res, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			port.QueryModeKey: port.QueryModeRecord,
+			"table":           tableName,
+			"id":              recordID,
+			"lib":             libName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	record, _ := res.Data["record"].(map[string]interface{})
+	if record == nil {
+		return nil, fmt.Errorf("%w: 记录 '%s' 在表 '%s' 中不存在", ErrNotFound, recordID, tableName)
+	}
+	if libName == "" && toInt64(res.Data["matches"]) > 1 {
+		libName, _ = record["__lib"].(string)
+	}
+	permalink := Permalink(baseURL, bizName, tableName, recordID, libName)
+
+	name := ""
+	for _, field := range s.nameFields {
+		if name = stringify(record[field]); name != "" {
+			break
+		}
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s / %s #%s", bizName, tableName, recordID)
+	}
+
+	fields := make([]string, 0, len(record))
+	for field, v := range record {
+		if field == "__lib" || v == nil {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	properties := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		properties = append(properties, map[string]interface{}{
+			"@type": "PropertyValue",
+			"name":  field,
+			"value": record[field],
+		})
+	}
+
+	return map[string]interface{}{
+		"@context":   schemaOrgContext,
+		"@type":      "CreativeWork",
+		"@id":        permalink,
+		"url":        permalink,
+		"identifier": recordID,
+		"name":       name,
+		"isPartOf": map[string]interface{}{
+			"@type": "Dataset",
+			"name":  bizName + " / " + tableName,
+			"url":   baseURL + "/api/v1/meta/schema/" + url.PathEscape(bizName),
+		},
+		"additionalProperty": properties,
+	}, nil
+}
+
+// ParseSitemapFile 解析形如 "3.xml" 的站点地图文件名，返回页码
+func ParseSitemapFile(file string) (int, error) {
+	digits, ok := strings.CutSuffix(file, ".xml")
+	page, err := strconv.Atoi(digits)
+	if !ok || err != nil || page < 1 {
+		return 0, fmt.Errorf("%w: 无效的站点地图文件名 '%s'", ErrNotFound, file)
+	}
+	return page, nil
+}
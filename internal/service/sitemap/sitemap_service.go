@@ -0,0 +1,407 @@
+// Package sitemap file: internal/service/sitemap/sitemap_service.go
+package sitemap
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultURLsPerSitemap = 1000
+	// maxURLsPerSitemap 受数据源单次查询的条数上限约束 (SQLite 数据源为 2000)
+	maxURLsPerSitemap    = 2000
+	defaultCheckInterval = 5 * time.Minute
+	defaultCacheTTL      = time.Hour
+)
+
+// defaultNameFields 是生成 JSON-LD 的 name 时依次尝试的字段
+var defaultNameFields = []string{"title", "name"}
+
+// ErrNotFound 表示请求的站点地图页或记录不存在，或所在的表不对搜索引擎公开
+var ErrNotFound = errors.New("站点地图或记录不存在")
+
+// Options 定义站点地图与结构化元数据的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// BaseURL 是对外公开的站点根地址，如 https://archive.example.org。为空时按请求推断，
+	// 部署在反向代理之后时建议显式配置，以免搜索引擎收录到内部地址
+	BaseURL string `mapstructure:"base_url"`
+	// URLsPerSitemap 是单个站点地图包含的记录数，默认为 1000，最大为 2000
+	URLsPerSitemap int `mapstructure:"urls_per_sitemap"`
+	// CheckIntervalSeconds 是重新统计各表记录数的间隔，记录数变化的表会重新生成站点地图，默认为 300 秒
+	CheckIntervalSeconds int `mapstructure:"check_interval_seconds"`
+	// CacheTTLSeconds 是单个站点地图页的缓存时间，默认为 3600 秒
+	CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+	// NameFields 是 JSON-LD 中 name 的候选字段，取第一个非空的字段，默认为 title、name
+	NameFields []string `mapstructure:"name_fields"`
+}
+
+// Sitemap 是站点地图索引中的一项，对应一张表的一页记录
+type Sitemap struct {
+	BizName   string
+	TableName string
+	Page      int
+	// LastMod 是该表最近一次被检测到变化的时间
+	LastMod time.Time
+}
+
+// Entry 是站点地图中的一条记录。只有主键在同一页中出现于多个库时才带有库名
+type Entry struct {
+	ID  string
+	Lib string
+}
+
+type tableKey struct {
+	biz, table string
+}
+
+type pageCache struct {
+	entries []Entry
+	expires time.Time
+}
+
+// tableState 记录一张表上次统计的结果，记录数变化时整体重建
+type tableState struct {
+	primaryKey string
+	total      int64
+	lastMod    time.Time
+	pages      map[int]pageCache
+}
+
+// Service 为公开检索的业务组生成站点地图与单条记录的 JSON-LD 元数据，让搜索引擎能够发现馆藏内容。
+// 只有配置为公开检索、可检索且具有单列主键 (主键字段可返回) 的表会被列入站点地图。
+// 索引每隔 CheckIntervalSeconds 重新统计一次记录数，记录数变化的表丢弃已缓存的页并更新 lastmod。
+type Service struct {
+	config         port.QueryAdminConfigService
+	registry       map[string]port.DataSource
+	baseURL        string
+	urlsPerSitemap int
+	checkInterval  time.Duration
+	cacheTTL       time.Duration
+	nameFields     []string
+	now            func() time.Time
+
+	mu           sync.Mutex
+	index        []Sitemap
+	indexExpires time.Time
+	tables       map[tableKey]*tableState
+}
+
+// NewService 创建一个新的站点地图服务实例
+func NewService(config port.QueryAdminConfigService, registry map[string]port.DataSource, opts Options) (*Service, error) {
+	if config == nil {
+		return nil, errors.New("sitemap.Service 需要有效的配置服务")
+	}
+	if opts.URLsPerSitemap < 0 || opts.CheckIntervalSeconds < 0 || opts.CacheTTLSeconds < 0 {
+		return nil, errors.New("站点地图的配置项不能为负数")
+	}
+	if opts.URLsPerSitemap > maxURLsPerSitemap {
+		return nil, fmt.Errorf("站点地图的 urls_per_sitemap 不能超过 %d", maxURLsPerSitemap)
+	}
+	if opts.BaseURL != "" {
+		u, err := url.Parse(opts.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("站点地图的 base_url '%s' 不是有效的 http(s) 地址", opts.BaseURL)
+		}
+	}
+	s := &Service{
+		config:         config,
+		registry:       registry,
+		baseURL:        strings.TrimRight(opts.BaseURL, "/"),
+		urlsPerSitemap: defaultURLsPerSitemap,
+		checkInterval:  defaultCheckInterval,
+		cacheTTL:       defaultCacheTTL,
+		nameFields:     defaultNameFields,
+		now:            time.Now,
+		tables:         make(map[tableKey]*tableState),
+	}
+	if opts.URLsPerSitemap > 0 {
+		s.urlsPerSitemap = opts.URLsPerSitemap
+	}
+	if opts.CheckIntervalSeconds > 0 {
+		s.checkInterval = time.Duration(opts.CheckIntervalSeconds) * time.Second
+	}
+	if opts.CacheTTLSeconds > 0 {
+		s.cacheTTL = time.Duration(opts.CacheTTLSeconds) * time.Second
+	}
+	if len(opts.NameFields) > 0 {
+		s.nameFields = opts.NameFields
+	}
+	return s, nil
+}
+
+// BaseURL 返回生成链接时使用的根地址，未配置 base_url 时使用由请求推断的 requestBase
+func (s *Service) BaseURL(requestBase string) string {
+	if s.baseURL != "" {
+		return s.baseURL
+	}
+	return requestBase
+}
+
+// Invalidate 丢弃全部缓存，下次请求时重新统计并生成
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.index = nil
+	s.tables = make(map[tableKey]*tableState)
+	s.mu.Unlock()
+}
+
+// Index 返回站点地图索引，按业务组、表名与页码排序
+func (s *Service) Index(ctx context.Context) ([]Sitemap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index != nil && s.now().Before(s.indexExpires) {
+		return s.index, nil
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s.index, nil
+}
+
+// refresh 重新统计全部可收录的表，调用方需持有 s.mu
+func (s *Service) refresh(ctx context.Context) error {
+	bizNames := make([]string, 0, len(s.registry))
+	for name := range s.registry {
+		bizNames = append(bizNames, name)
+	}
+	sort.Strings(bizNames)
+
+	now := s.now()
+	index := make([]Sitemap, 0)
+	seen := make(map[tableKey]bool)
+	for _, bizName := range bizNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tables, err := s.describeBiz(ctx, bizName, s.registry[bizName])
+		if err != nil {
+			// 单个业务组出错时跳过，保留上次的统计结果，避免站点地图整体不可用
+			slog.Warn("[Sitemap] 统计业务组失败，已跳过", "biz", bizName, "error", err)
+			kept := make([]tableKey, 0)
+			for key := range s.tables {
+				if key.biz == bizName {
+					kept = append(kept, key)
+				}
+			}
+			sort.Slice(kept, func(i, j int) bool { return kept[i].table < kept[j].table })
+			for _, key := range kept {
+				seen[key] = true
+				index = append(index, s.pagesOf(key)...)
+			}
+			continue
+		}
+		for _, t := range tables {
+			key := tableKey{biz: bizName, table: t.name}
+			state := s.tables[key]
+			if state == nil || state.total != t.total || state.primaryKey != t.primaryKey {
+				state = &tableState{primaryKey: t.primaryKey, total: t.total, lastMod: now, pages: make(map[int]pageCache)}
+				s.tables[key] = state
+			}
+			seen[key] = true
+			index = append(index, s.pagesOf(key)...)
+		}
+	}
+	for key := range s.tables {
+		if !seen[key] {
+			delete(s.tables, key)
+		}
+	}
+	s.index, s.indexExpires = index, now.Add(s.checkInterval)
+	return nil
+}
+
+func (s *Service) pagesOf(key tableKey) []Sitemap {
+	state := s.tables[key]
+	pages := make([]Sitemap, 0, s.pageCount(state))
+	for p := 1; p <= s.pageCount(state); p++ {
+		pages = append(pages, Sitemap{BizName: key.biz, TableName: key.table, Page: p, LastMod: state.lastMod})
+	}
+	return pages
+}
+
+func (s *Service) pageCount(state *tableState) int {
+	return int((state.total + int64(s.urlsPerSitemap) - 1) / int64(s.urlsPerSitemap))
+}
+
+type tableSummary struct {
+	name       string
+	primaryKey string
+	total      int64
+}
+
+// describeBiz 列出业务组中可收录的表。与目录服务一致，检索权限以数据源的判断为准
+func (s *Service) describeBiz(ctx context.Context, bizName string, dataSource port.DataSource) ([]tableSummary, error) {
+	// 站点地图只收录显式配置为公开检索且未暂停服务的业务组，虚拟业务组没有自己的配置，不予收录
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil || !bizConfig.IsPubliclySearchable || !bizConfig.Enabled {
+		return nil, nil
+	}
+	schema, err := dataSource.GetSchema(ctx, port.SchemaRequest{BizName: bizName})
+	if err != nil {
+		return nil, err
+	}
+
+	tableNames := make([]string, 0, len(bizConfig.Tables))
+	for name, tableConfig := range bizConfig.Tables {
+		if tableConfig.IsSearchable {
+			tableNames = append(tableNames, name)
+		}
+	}
+	sort.Strings(tableNames)
+
+	tables := make([]tableSummary, 0, len(tableNames))
+	for _, name := range tableNames {
+		primaryKey := singlePrimaryKey(schema.Tables[name])
+		if primaryKey == "" {
+			slog.Debug("[Sitemap] 表没有可返回的单列主键，不列入站点地图", "biz", bizName, "table", name)
+			continue
+		}
+		total, err := countRows(ctx, dataSource, bizName, name)
+		if errors.Is(err, port.ErrPermissionDenied) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("统计表 '%s' 的记录数失败: %w", name, err)
+		}
+		tables = append(tables, tableSummary{name: name, primaryKey: primaryKey, total: total})
+	}
+	return tables, nil
+}
+
+// singlePrimaryKey 返回表唯一的主键字段。复合主键或没有主键的表无法生成稳定的永久链接，返回空
+func singlePrimaryKey(fields []port.FieldDescription) string {
+	primaryKey := ""
+	for _, f := range fields {
+		if !f.IsPrimary {
+			continue
+		}
+		if primaryKey != "" || !f.IsReturnable {
+			return ""
+		}
+		primaryKey = f.Name
+	}
+	return primaryKey
+}
+
+// Page 返回一张表某一页的记录
+func (s *Service) Page(ctx context.Context, bizName, tableName string, page int) ([]Entry, error) {
+	if _, err := s.Index(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := tableKey{biz: bizName, table: tableName}
+	state := s.tables[key]
+	if state == nil || page < 1 || page > s.pageCount(state) {
+		return nil, fmt.Errorf("%w: %s/%s 第 %d 页", ErrNotFound, bizName, tableName, page)
+	}
+	if cached, ok := state.pages[page]; ok && s.now().Before(cached.expires) {
+		return cached.entries, nil
+	}
+
+	res, err := s.registry[bizName].Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query: map[string]interface{}{
+			"table":            tableName,
+			"page":             float64(page),
+			"size":             float64(s.urlsPerSitemap),
+			"fields_to_return": []interface{}{state.primaryKey},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, _ := res.Data["items"].([]interface{})
+	entries := toEntries(items, state.primaryKey)
+	state.pages[page] = pageCache{entries: entries, expires: s.now().Add(s.cacheTTL)}
+	return entries, nil
+}
+
+// toEntries 把查询结果转换为站点地图条目。同一主键出现在多个库时，永久链接需要库名才能唯一定位
+func toEntries(items []interface{}, primaryKey string) []Entry {
+	entries := make([]Entry, 0, len(items))
+	occurrences := make(map[string]int, len(items))
+	libs := make([]string, 0, len(items))
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := stringify(row[primaryKey])
+		if id == "" {
+			continue
+		}
+		lib, _ := row["__lib"].(string)
+		entries = append(entries, Entry{ID: id})
+		libs = append(libs, lib)
+		occurrences[id]++
+	}
+	for i := range entries {
+		if occurrences[entries[i].ID] > 1 {
+			entries[i].Lib = libs[i]
+		}
+	}
+	return entries
+}
+
+// Permalink 返回记录的永久链接，与记录接口返回的 citation.permalink 一致
+func Permalink(baseURL, bizName, tableName, recordID, libName string) string {
+	permalink := fmt.Sprintf("%s/api/v1/data/record/%s/%s/%s",
+		baseURL, url.PathEscape(bizName), url.PathEscape(tableName), url.PathEscape(recordID))
+	if libName != "" {
+		permalink += "?lib=" + url.QueryEscape(libName)
+	}
+	return permalink
+}
+
+// countRows 以只取一条的普通查询读取表的记录总数
+func countRows(ctx context.Context, dataSource port.DataSource, bizName, tableName string) (int64, error) {
+	res, err := dataSource.Query(ctx, port.QueryRequest{
+		BizName: bizName,
+		Query:   map[string]interface{}{"table": tableName, "page": float64(1), "size": float64(1)},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(res.Data["total"]), nil
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case float64: // 经 gRPC (structpb) 传输的数字
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+func stringify(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
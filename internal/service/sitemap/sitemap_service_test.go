@@ -0,0 +1,232 @@
+// file: internal/service/sitemap/sitemap_service_test.go
+package sitemap
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDataSource 保存每张表的记录，主键字段由 primaryKeys 指定，多个主键表示复合主键
+type stubDataSource struct {
+	rows        map[string][]map[string]interface{}
+	primaryKeys map[string][]string
+	pageQueries atomic.Int32
+}
+
+func (d *stubDataSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	table, _ := req.Query["table"].(string)
+	rows := d.rows[table]
+	if req.Query[port.QueryModeKey] == port.QueryModeRecord {
+		var found map[string]interface{}
+		matches := 0
+		for _, row := range rows {
+			if fmt.Sprint(row["id"]) == req.Query["id"] {
+				if lib := req.Query["lib"].(string); lib != "" && row["__lib"] != lib {
+					continue
+				}
+				if found == nil {
+					found = row
+				}
+				matches++
+			}
+		}
+		data := map[string]interface{}{"matches": matches}
+		if found != nil {
+			data["record"] = found
+		}
+		return &port.QueryResult{Data: data}, nil
+	}
+
+	page, size := int(req.Query["page"].(float64)), int(req.Query["size"].(float64))
+	if size > 1 {
+		d.pageQueries.Add(1)
+	}
+	start := min((page-1)*size, len(rows))
+	end := min(start+size, len(rows))
+	items := make([]interface{}, 0, end-start)
+	for _, row := range rows[start:end] {
+		items = append(items, row)
+	}
+	return &port.QueryResult{Data: map[string]interface{}{"items": items, "total": float64(len(rows))}}, nil
+}
+
+func (d *stubDataSource) Mutate(context.Context, port.MutateRequest) (*port.MutateResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (d *stubDataSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	out := &port.SchemaResult{Tables: map[string][]port.FieldDescription{}}
+	for table := range d.rows {
+		fields := []port.FieldDescription{{Name: "title", DataType: "TEXT", IsReturnable: true}}
+		for _, pk := range d.primaryKeys[table] {
+			fields = append(fields, port.FieldDescription{Name: pk, DataType: "INTEGER", IsReturnable: true, IsPrimary: true})
+		}
+		out.Tables[table] = fields
+	}
+	return out, nil
+}
+
+func (d *stubDataSource) HealthCheck(context.Context) error { return nil }
+func (d *stubDataSource) Type() string                      { return "stub" }
+
+func newConfig(t *testing.T) port.QueryAdminConfigService {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES
+		('library', TRUE, 'books'), ('private', FALSE, 'docs')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES
+		('library', 'books'), ('library', 'loans'), ('private', 'docs')`)
+	require.NoError(t, err)
+	config, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	return config
+}
+
+func books(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, n)
+	for i := 1; i <= n; i++ {
+		rows = append(rows, map[string]interface{}{"id": float64(i), "title": fmt.Sprintf("书目 %d", i), "__lib": "main"})
+	}
+	return rows
+}
+
+func newTestService(t *testing.T, opts Options) (*Service, *stubDataSource, *time.Time) {
+	t.Helper()
+	library := &stubDataSource{
+		rows: map[string][]map[string]interface{}{
+			"books":   books(5),
+			"loans":   {{"book_id": float64(1), "reader_id": float64(2)}},
+			"staging": books(3),
+		},
+		primaryKeys: map[string][]string{"books": {"id"}, "loans": {"book_id", "reader_id"}, "staging": {"id"}},
+	}
+	registry := map[string]port.DataSource{
+		"library": library,
+		"private": &stubDataSource{rows: map[string][]map[string]interface{}{"docs": books(1)}, primaryKeys: map[string][]string{"docs": {"id"}}},
+	}
+	svc, err := NewService(newConfig(t), registry, opts)
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, library, &now
+}
+
+func TestSitemapIndexAndPages(t *testing.T) {
+	ctx := context.Background()
+	svc, library, now := newTestService(t, Options{URLsPerSitemap: 2})
+
+	index, err := svc.Index(ctx)
+	require.NoError(t, err)
+	require.Len(t, index, 3, "只收录公开业务组中可检索且具有单列主键的表")
+	for i, sm := range index {
+		assert.Equal(t, Sitemap{BizName: "library", TableName: "books", Page: i + 1, LastMod: *now}, sm)
+	}
+
+	entries, err := svc.Page(ctx, "library", "books", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{ID: "5"}}, entries)
+	_, err = svc.Page(ctx, "library", "books", 4)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Page(ctx, "private", "docs", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.Page(ctx, "library", "loans", 1)
+	assert.ErrorIs(t, err, ErrNotFound, "复合主键的表不列入站点地图")
+
+	// 页在缓存期内不再查询数据源
+	_, err = svc.Page(ctx, "library", "books", 3)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, library.pageQueries.Load())
+
+	// 记录数变化在下次统计时被发现：丢弃缓存的页并更新 lastmod
+	library.rows["books"] = append(library.rows["books"], books(6)[5])
+	*now = now.Add(time.Minute)
+	index, err = svc.Index(ctx)
+	require.NoError(t, err)
+	assert.Len(t, index, 3, "统计间隔内沿用上次的索引")
+
+	*now = now.Add(defaultCheckInterval)
+	index, err = svc.Index(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, *now, index[0].LastMod)
+	entries, err = svc.Page(ctx, "library", "books", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{ID: "5"}, {ID: "6"}}, entries)
+	assert.EqualValues(t, 2, library.pageQueries.Load())
+}
+
+func TestSitemapXML(t *testing.T) {
+	ctx := context.Background()
+	svc, library, _ := newTestService(t, Options{BaseURL: "https://archive.example.org/"})
+	library.rows["books"] = append(books(2), map[string]interface{}{"id": float64(2), "title": "副本", "__lib": "mirror"})
+	assert.Equal(t, "https://archive.example.org", svc.BaseURL("http://10.0.0.1:8080"))
+
+	index, err := svc.IndexXML(ctx, svc.BaseURL(""))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	assert.Contains(t, string(index), "<loc>https://archive.example.org/sitemaps/library/books/1.xml</loc>")
+	assert.Contains(t, string(index), "<lastmod>2024-05-01T00:00:00Z</lastmod>")
+
+	page, err := svc.SitemapXML(ctx, svc.BaseURL(""), "library", "books", 1)
+	require.NoError(t, err)
+	body := string(page)
+	assert.Contains(t, body, "<loc>https://archive.example.org/api/v1/data/record/library/books/1</loc>")
+	assert.Contains(t, body, "<loc>https://archive.example.org/api/v1/data/record/library/books/2?lib=main</loc>", "同一主键出现在多个库时带上库名")
+	assert.Contains(t, body, "<loc>https://archive.example.org/api/v1/data/record/library/books/2?lib=mirror</loc>")
+	assert.Equal(t, 3, strings.Count(body, "<url>"))
+
+	page3, err := ParseSitemapFile("3.xml")
+	require.NoError(t, err)
+	assert.Equal(t, 3, page3)
+	for _, bad := range []string{"3", "0.xml", "x.xml", "-1.xml"} {
+		_, err = ParseSitemapFile(bad)
+		assert.ErrorIs(t, err, ErrNotFound, bad)
+	}
+}
+
+func TestJSONLD(t *testing.T) {
+	ctx := context.Background()
+	svc, library, _ := newTestService(t, Options{})
+	library.rows["books"] = append(books(1), map[string]interface{}{"id": float64(1), "title": nil, "__lib": "mirror"})
+
+	doc, err := svc.JSONLD(ctx, "http://localhost", "library", "books", "1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://schema.org", doc["@context"])
+	assert.Equal(t, "CreativeWork", doc["@type"])
+	assert.Equal(t, "http://localhost/api/v1/data/record/library/books/1?lib=main", doc["@id"], "命中多个库时永久链接带上库名")
+	assert.Equal(t, "书目 1", doc["name"])
+	assert.Equal(t, []map[string]interface{}{
+		{"@type": "PropertyValue", "name": "id", "value": float64(1)},
+		{"@type": "PropertyValue", "name": "title", "value": "书目 1"},
+	}, doc["additionalProperty"])
+
+	doc, err = svc.JSONLD(ctx, "http://localhost", "library", "books", "1", "mirror")
+	require.NoError(t, err)
+	assert.Equal(t, "library / books #1", doc["name"], "没有可用的名称字段时以主键命名")
+
+	_, err = svc.JSONLD(ctx, "http://localhost", "library", "books", "99", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.JSONLD(ctx, "http://localhost", "missing", "books", "1", "")
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
+
+func TestNewServiceValidatesOptions(t *testing.T) {
+	config := newConfig(t)
+	_, err := NewService(config, nil, Options{CacheTTLSeconds: -1})
+	assert.Error(t, err)
+	_, err = NewService(config, nil, Options{URLsPerSitemap: maxURLsPerSitemap + 1})
+	assert.Error(t, err)
+	_, err = NewService(config, nil, Options{BaseURL: "archive.example.org"})
+	assert.Error(t, err)
+}
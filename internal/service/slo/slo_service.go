@@ -0,0 +1,497 @@
+// Package slo file: internal/service/slo/slo_service.go
+package slo
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// bucketSize 是请求统计的时间粒度
+	bucketSize = 5 * time.Minute
+	// defaultWindowDays 与 maxWindowDays 是统计窗口的默认天数与上限，统计数据只保留 maxWindowDays 天
+	defaultWindowDays = 30
+	maxWindowDays     = 90
+	// 默认的告警阈值，取自常用的多窗口消耗速度告警：1 小时内消耗 2% 的月度预算、6 小时内消耗 5%
+	defaultFastBurnThreshold = 14.4
+	defaultSlowBurnThreshold = 6
+	defaultCheckMinutes      = 5
+	// alertCooldown 内同一业务组同一目标的同类告警只发送一次
+	alertCooldown = time.Hour
+)
+
+// 消耗速度的统计时间段，作为 SLOObjectiveStatus.BurnRates 的键
+const (
+	BurnWindowFast   = "1h"
+	BurnWindowSlow   = "6h"
+	BurnWindowWindow = "window"
+)
+
+var (
+	// ErrInvalidSLO 表示服务等级目标的取值无效
+	ErrInvalidSLO = errors.New("无效的服务等级目标")
+	// ErrNotFound 表示业务组没有设置服务等级目标
+	ErrNotFound = errors.New("业务组没有设置服务等级目标")
+)
+
+// Options 定义服务等级目标的跟踪与告警方式
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckIntervalMinutes 是写入请求统计并检查错误预算的间隔，默认为 5 分钟
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+	// FastBurnThreshold 是最近 1 小时的消耗速度告警阈值，默认为 14.4
+	FastBurnThreshold float64 `mapstructure:"fast_burn_threshold"`
+	// SlowBurnThreshold 是最近 6 小时的消耗速度告警阈值，默认为 6
+	SlowBurnThreshold float64 `mapstructure:"slow_burn_threshold"`
+	// WebhookURL 不为空时把错误预算告警以 JSON POST 到该地址
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+type bucketKey struct {
+	biz   string
+	start time.Time
+}
+
+// counts 是一段时间内的请求数、5xx 响应数与超过延迟阈值的请求数
+type counts struct {
+	requests, errors, slow int64
+}
+
+func (c *counts) add(o counts) {
+	c.requests += o.requests
+	c.errors += o.errors
+	c.slow += o.slow
+}
+
+type alertKey struct {
+	biz, objective, alert string
+}
+
+// Service 按业务组跟踪服务等级目标：数据与元数据请求的结果由 Observe 按 5 分钟累加，定期写入数据库；
+// 达成情况与错误预算的消耗速度由统计窗口内的数据计算，消耗过快或预算耗尽时记录告警并通知 webhook。
+// 只统计设置了服务等级目标的业务组
+type Service struct {
+	db     *sql.DB
+	opts   Options
+	now    func() time.Time
+	client *http.Client
+
+	mu      sync.Mutex
+	slos    map[string]domain.BizSLO
+	pending map[bucketKey]*counts
+
+	alertMu    sync.Mutex
+	lastAlerts map[alertKey]time.Time
+}
+
+// NewService 创建一个新的服务等级目标服务实例，并从数据库加载已设置的目标
+func NewService(db *sql.DB, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("slo.Service 需要一个有效的数据库连接")
+	}
+	if opts.CheckIntervalMinutes < 0 || opts.FastBurnThreshold < 0 || opts.SlowBurnThreshold < 0 {
+		return nil, errors.New("服务等级目标的检查间隔与告警阈值不能为负数")
+	}
+	if opts.CheckIntervalMinutes == 0 {
+		opts.CheckIntervalMinutes = defaultCheckMinutes
+	}
+	if opts.FastBurnThreshold == 0 {
+		opts.FastBurnThreshold = defaultFastBurnThreshold
+	}
+	if opts.SlowBurnThreshold == 0 {
+		opts.SlowBurnThreshold = defaultSlowBurnThreshold
+	}
+	s := &Service{
+		db:         db,
+		opts:       opts,
+		now:        time.Now,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		slos:       make(map[string]domain.BizSLO),
+		pending:    make(map[bucketKey]*counts),
+		lastAlerts: make(map[alertKey]time.Time),
+	}
+	list, err := s.loadSLOs(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range list {
+		s.slos[o.BizName] = o
+	}
+	return s, nil
+}
+
+func (s *Service) loadSLOs(ctx context.Context) ([]domain.BizSLO, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT biz_name, availability_target, latency_threshold_ms, latency_target, window_days, updated_at
+		FROM biz_slos ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("读取服务等级目标失败: %w", err)
+	}
+	defer rows.Close()
+	var list []domain.BizSLO
+	for rows.Next() {
+		var o domain.BizSLO
+		if err := rows.Scan(&o.BizName, &o.AvailabilityTarget, &o.LatencyThresholdMs, &o.LatencyTarget, &o.WindowDays, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("读取服务等级目标失败: %w", err)
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// SetSLO 设置业务组的服务等级目标，已有的目标被整体替换，已累计的请求统计保留。
+// 目标值应在 (0, 1) 之间或为 0 (不设该项目标)，至少设置一项；设置延迟目标时需要同时设置延迟阈值
+func (s *Service) SetSLO(ctx context.Context, o domain.BizSLO) (*domain.BizSLO, error) {
+	if o.BizName == "" {
+		return nil, fmt.Errorf("%w: 业务组名不能为空", ErrInvalidSLO)
+	}
+	for name, target := range map[string]float64{"availability_target": o.AvailabilityTarget, "latency_target": o.LatencyTarget} {
+		if target < 0 || target >= 1 {
+			return nil, fmt.Errorf("%w: %s 应在 0 到 1 之间 (不含 1)，当前为 %v", ErrInvalidSLO, name, target)
+		}
+	}
+	if o.AvailabilityTarget == 0 && o.LatencyTarget == 0 {
+		return nil, fmt.Errorf("%w: 至少需要设置 availability_target 或 latency_target", ErrInvalidSLO)
+	}
+	if o.LatencyThresholdMs < 0 || (o.LatencyTarget > 0 && o.LatencyThresholdMs == 0) {
+		return nil, fmt.Errorf("%w: 设置 latency_target 时需要正的 latency_threshold_ms", ErrInvalidSLO)
+	}
+	if o.WindowDays == 0 {
+		o.WindowDays = defaultWindowDays
+	}
+	if o.WindowDays < 1 || o.WindowDays > maxWindowDays {
+		return nil, fmt.Errorf("%w: window_days 应在 1 到 %d 之间", ErrInvalidSLO, maxWindowDays)
+	}
+	o.UpdatedAt = s.now().UTC()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO biz_slos (biz_name, availability_target, latency_threshold_ms, latency_target, window_days, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			availability_target = excluded.availability_target,
+			latency_threshold_ms = excluded.latency_threshold_ms,
+			latency_target = excluded.latency_target,
+			window_days = excluded.window_days,
+			updated_at = excluded.updated_at`,
+		o.BizName, o.AvailabilityTarget, o.LatencyThresholdMs, o.LatencyTarget, o.WindowDays, o.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("保存服务等级目标失败: %w", err)
+	}
+	s.mu.Lock()
+	s.slos[o.BizName] = o
+	s.mu.Unlock()
+	return &o, nil
+}
+
+// DeleteSLO 删除业务组的服务等级目标及其请求统计
+func (s *Service) DeleteSLO(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_slos WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除服务等级目标失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, bizName)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM slo_buckets WHERE biz_name = ?`, bizName); err != nil {
+		return fmt.Errorf("删除请求统计失败: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.slos, bizName)
+	for key := range s.pending {
+		if key.biz == bizName {
+			delete(s.pending, key)
+		}
+	}
+	return nil
+}
+
+// Observe 记录一次已完成的请求。未设置服务等级目标的业务组不做统计；
+// 5xx 响应计为可用性目标的错误，耗时超过延迟阈值的请求计为延迟目标的错误
+func (s *Service) Observe(bizName string, status int, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.slos[bizName]
+	if !ok {
+		return
+	}
+	key := bucketKey{biz: bizName, start: s.now().UTC().Truncate(bucketSize)}
+	c := s.pending[key]
+	if c == nil {
+		c = &counts{}
+		s.pending[key] = c
+	}
+	c.requests++
+	if status >= http.StatusInternalServerError {
+		c.errors++
+	}
+	if o.LatencyThresholdMs > 0 && latency > time.Duration(o.LatencyThresholdMs)*time.Millisecond {
+		c.slow++
+	}
+}
+
+// Flush 把内存中累加的请求统计写入数据库并清理超过最长统计窗口的数据。写入失败时统计放回内存，下次重试
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[bucketKey]*counts)
+	s.mu.Unlock()
+
+	if err := s.write(ctx, pending); err != nil {
+		s.mu.Lock()
+		for key, c := range pending {
+			if existing := s.pending[key]; existing != nil {
+				existing.add(*c)
+			} else {
+				s.pending[key] = c
+			}
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Service) write(ctx context.Context, pending map[bucketKey]*counts) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启请求统计事务失败: %w", err)
+	}
+	defer tx.Rollback()
+	for key, c := range pending {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO slo_buckets (biz_name, bucket_start, requests, errors, slow) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(biz_name, bucket_start) DO UPDATE SET
+				requests = requests + excluded.requests,
+				errors = errors + excluded.errors,
+				slow = slow + excluded.slow`,
+			key.biz, key.start, c.requests, c.errors, c.slow); err != nil {
+			return fmt.Errorf("写入请求统计失败: %w", err)
+		}
+	}
+	cutoff := s.now().UTC().AddDate(0, 0, -maxWindowDays)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM slo_buckets WHERE bucket_start < ?`, cutoff); err != nil {
+		return fmt.Errorf("清理过期请求统计失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListStatuses 按业务组名返回所有服务等级目标的达成情况
+func (s *Service) ListStatuses(ctx context.Context) ([]domain.SLOStatus, error) {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.slos))
+	for name := range s.slos {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+
+	statuses := make([]domain.SLOStatus, 0, len(names))
+	for _, name := range names {
+		status, err := s.Status(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue // 期间被删除
+		}
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// Status 返回业务组的服务等级目标在统计窗口内的达成情况，包括尚未写入数据库的统计
+func (s *Service) Status(ctx context.Context, bizName string) (*domain.SLOStatus, error) {
+	now := s.now().UTC()
+	s.mu.Lock()
+	o, ok := s.slos[bizName]
+	pending := make(map[time.Time]counts)
+	for key, c := range s.pending {
+		if key.biz == bizName {
+			pending[key.start] = *c
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotFound, bizName)
+	}
+
+	windowStart := now.AddDate(0, 0, -o.WindowDays)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT bucket_start, requests, errors, slow FROM slo_buckets
+		WHERE biz_name = ? AND bucket_start >= ?`, bizName, windowStart.Truncate(bucketSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取请求统计失败: %w", err)
+	}
+	defer rows.Close()
+	buckets := make(map[time.Time]counts, len(pending))
+	for start, c := range pending {
+		buckets[start] = c
+	}
+	for rows.Next() {
+		var start time.Time
+		var c counts
+		if err := rows.Scan(&start, &c.requests, &c.errors, &c.slow); err != nil {
+			return nil, fmt.Errorf("读取请求统计失败: %w", err)
+		}
+		merged := buckets[start.UTC()]
+		merged.add(c)
+		buckets[start.UTC()] = merged
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取请求统计失败: %w", err)
+	}
+
+	// 各时间段包含起点落在其中的统计桶
+	var window, fast, slow counts
+	for start, c := range buckets {
+		if start.Before(windowStart.Truncate(bucketSize)) {
+			continue
+		}
+		window.add(c)
+		if !start.Before(now.Add(-time.Hour).Truncate(bucketSize)) {
+			fast.add(c)
+		}
+		if !start.Before(now.Add(-6 * time.Hour).Truncate(bucketSize)) {
+			slow.add(c)
+		}
+	}
+
+	status := &domain.SLOStatus{SLO: o, GeneratedAt: now}
+	bad := func(c counts) int64 { return c.errors }
+	if o.AvailabilityTarget > 0 {
+		status.Objectives = append(status.Objectives, s.objectiveStatus(domain.SLOObjectiveAvailability, o.AvailabilityTarget, bad, window, fast, slow))
+	}
+	if o.LatencyTarget > 0 {
+		bad = func(c counts) int64 { return c.slow }
+		status.Objectives = append(status.Objectives, s.objectiveStatus(domain.SLOObjectiveLatency, o.LatencyTarget, bad, window, fast, slow))
+	}
+	return status, nil
+}
+
+// objectiveStatus 计算一项目标的达成情况与各时间段的消耗速度，并判断是否需要告警：
+// 预算耗尽优先，其次为 1 小时的快速消耗、6 小时的持续消耗
+func (s *Service) objectiveStatus(objective string, target float64, bad func(counts) int64, window, fast, slow counts) domain.SLOObjectiveStatus {
+	budget := 1 - target
+	burnRate := func(c counts) float64 {
+		if c.requests == 0 {
+			return 0
+		}
+		return float64(bad(c)) / float64(c.requests) / budget
+	}
+	st := domain.SLOObjectiveStatus{
+		Objective:            objective,
+		Target:               target,
+		Actual:               1,
+		Requests:             window.requests,
+		BadRequests:          bad(window),
+		ErrorBudgetRemaining: 1,
+		BurnRates: map[string]float64{
+			BurnWindowFast:   burnRate(fast),
+			BurnWindowSlow:   burnRate(slow),
+			BurnWindowWindow: burnRate(window),
+		},
+	}
+	if window.requests > 0 {
+		st.Actual = 1 - float64(st.BadRequests)/float64(window.requests)
+		st.ErrorBudgetRemaining = 1 - st.BurnRates[BurnWindowWindow]
+	}
+	switch {
+	case window.requests > 0 && st.ErrorBudgetRemaining <= 0:
+		st.Alert = domain.SLOAlertExhausted
+	case st.BurnRates[BurnWindowFast] >= s.opts.FastBurnThreshold:
+		st.Alert = domain.SLOAlertFastBurn
+	case st.BurnRates[BurnWindowSlow] >= s.opts.SlowBurnThreshold:
+		st.Alert = domain.SLOAlertSlowBurn
+	}
+	return st
+}
+
+// Check 写入请求统计并检查所有业务组的错误预算，返回本次触发的告警。
+// 同一业务组同一目标的同类告警在一小时内只触发一次
+func (s *Service) Check(ctx context.Context) ([]domain.SLOAlert, error) {
+	if err := s.Flush(ctx); err != nil {
+		return nil, err
+	}
+	statuses, err := s.ListStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	var fired []domain.SLOAlert
+	s.alertMu.Lock()
+	for _, status := range statuses {
+		for _, objective := range status.Objectives {
+			if objective.Alert == "" {
+				continue
+			}
+			key := alertKey{biz: status.SLO.BizName, objective: objective.Objective, alert: objective.Alert}
+			if last, ok := s.lastAlerts[key]; ok && now.Sub(last) < alertCooldown {
+				continue
+			}
+			s.lastAlerts[key] = now
+			fired = append(fired, domain.SLOAlert{BizName: status.SLO.BizName, Alert: objective.Alert, Status: objective, FiredAt: now})
+		}
+	}
+	s.alertMu.Unlock()
+
+	for _, alert := range fired {
+		slog.Warn("[SLO] 错误预算告警", "biz", alert.BizName, "objective", alert.Status.Objective, "alert", alert.Alert,
+			"budget_remaining", alert.Status.ErrorBudgetRemaining, "burn_rate_1h", alert.Status.BurnRates[BurnWindowFast])
+		if s.opts.WebhookURL != "" {
+			if err := s.postWebhook(ctx, alert); err != nil {
+				slog.Warn("[SLO] 发送错误预算告警失败", "biz", alert.BizName, "error", err)
+			}
+		}
+	}
+	return fired, nil
+}
+
+func (s *Service) postWebhook(ctx context.Context, alert domain.SLOAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Run 每隔 CheckIntervalMinutes 写入请求统计并检查错误预算，ctx 结束时返回；剩余的统计由调用方在关闭时 Flush
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.opts.CheckIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Check(ctx); err != nil {
+				slog.Error("[SLO] 检查错误预算失败", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// file: internal/service/slo/slo_service_test.go
+package slo
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, db *sql.DB, opts Options, now *time.Time) *Service {
+	t.Helper()
+	svc, err := NewService(db, opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return *now }
+	return svc
+}
+
+func TestSetSLO_Validation(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestService(t, fixture.NewSystemDB(t), Options{}, &now)
+	ctx := context.Background()
+
+	for _, invalid := range []domain.BizSLO{
+		{BizName: "", AvailabilityTarget: 0.99},
+		{BizName: "archive"},
+		{BizName: "archive", AvailabilityTarget: 1},
+		{BizName: "archive", AvailabilityTarget: -0.1},
+		{BizName: "archive", LatencyTarget: 0.95},
+		{BizName: "archive", AvailabilityTarget: 0.99, WindowDays: maxWindowDays + 1},
+	} {
+		_, err := svc.SetSLO(ctx, invalid)
+		assert.ErrorIs(t, err, ErrInvalidSLO, "%+v", invalid)
+	}
+
+	saved, err := svc.SetSLO(ctx, domain.BizSLO{BizName: "archive", AvailabilityTarget: 0.99})
+	require.NoError(t, err)
+	assert.Equal(t, defaultWindowDays, saved.WindowDays)
+	assert.ErrorIs(t, svc.DeleteSLO(ctx, "missing"), ErrNotFound)
+	_, err = svc.Status(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStatus_BudgetAndBurnRates(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestService(t, db, Options{}, &now)
+	ctx := context.Background()
+	_, err := svc.SetSLO(ctx, domain.BizSLO{BizName: "archive", AvailabilityTarget: 0.99, LatencyThresholdMs: 200, LatencyTarget: 0.9})
+	require.NoError(t, err)
+
+	// 两天前: 1000 次请求全部成功
+	now = now.Add(-48 * time.Hour)
+	for i := 0; i < 1000; i++ {
+		svc.Observe("archive", http.StatusOK, 50*time.Millisecond)
+	}
+	require.NoError(t, svc.Flush(ctx))
+	// 最近: 100 次请求，其中 5 次 5xx、20 次超过延迟阈值；4xx 不计为错误
+	now = now.Add(48 * time.Hour)
+	for i := 0; i < 100; i++ {
+		status, latency := http.StatusOK, 50*time.Millisecond
+		switch {
+		case i < 5:
+			status = http.StatusBadGateway
+		case i < 10:
+			status = http.StatusNotFound
+		}
+		if i >= 80 {
+			latency = time.Second
+		}
+		svc.Observe("archive", status, latency)
+	}
+	svc.Observe("unconfigured", http.StatusInternalServerError, 0)
+
+	status, err := svc.Status(ctx, "archive")
+	require.NoError(t, err)
+	require.Len(t, status.Objectives, 2)
+
+	availability := status.Objectives[0]
+	assert.Equal(t, domain.SLOObjectiveAvailability, availability.Objective)
+	assert.Equal(t, int64(1100), availability.Requests)
+	assert.Equal(t, int64(5), availability.BadRequests)
+	assert.InDelta(t, 1-5.0/1100, availability.Actual, 1e-9)
+	assert.InDelta(t, 1-(5.0/1100)/0.01, availability.ErrorBudgetRemaining, 1e-9)
+	assert.InDelta(t, 5.0, availability.BurnRates[BurnWindowFast], 1e-9)
+	assert.InDelta(t, 5.0, availability.BurnRates[BurnWindowSlow], 1e-9)
+	assert.Empty(t, availability.Alert)
+
+	latency := status.Objectives[1]
+	assert.Equal(t, domain.SLOObjectiveLatency, latency.Objective)
+	assert.Equal(t, int64(20), latency.BadRequests)
+	assert.InDelta(t, 2.0, latency.BurnRates[BurnWindowFast], 1e-9)
+
+	// 统计写入数据库后由新的实例读取
+	require.NoError(t, svc.Flush(ctx))
+	reloaded := newTestService(t, db, Options{}, &now)
+	again, err := reloaded.Status(ctx, "archive")
+	require.NoError(t, err)
+	assert.Equal(t, status.Objectives, again.Objectives)
+
+	list, err := reloaded.ListStatuses(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "archive", list[0].SLO.BizName)
+
+	require.NoError(t, reloaded.DeleteSLO(ctx, "archive"))
+	list, err = reloaded.ListStatuses(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCheck_AlertsWithCooldown(t *testing.T) {
+	var mu sync.Mutex
+	var received []domain.SLOAlert
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert domain.SLOAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		mu.Lock()
+		received = append(received, alert)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestService(t, fixture.NewSystemDB(t), Options{WebhookURL: hook.URL}, &now)
+	ctx := context.Background()
+	_, err := svc.SetSLO(ctx, domain.BizSLO{BizName: "archive", AvailabilityTarget: 0.999})
+	require.NoError(t, err)
+
+	// 大量成功请求之后出现一批 5xx：预算未耗尽，但最近 1 小时消耗过快
+	now = now.Add(-10 * 24 * time.Hour)
+	for i := 0; i < 100000; i++ {
+		svc.Observe("archive", http.StatusOK, 0)
+	}
+	now = now.Add(10 * 24 * time.Hour)
+	for i := 0; i < 100; i++ {
+		status := http.StatusOK
+		if i < 2 {
+			status = http.StatusInternalServerError
+		}
+		svc.Observe("archive", status, 0)
+	}
+
+	fired, err := svc.Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	assert.Equal(t, domain.SLOAlertFastBurn, fired[0].Alert)
+	assert.Greater(t, fired[0].Status.ErrorBudgetRemaining, 0.0)
+	mu.Lock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "archive", received[0].BizName)
+	mu.Unlock()
+
+	// 冷却时间内不重复告警
+	fired, err = svc.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, fired)
+
+	// 错误继续增加直到预算耗尽
+	for i := 0; i < 200; i++ {
+		svc.Observe("archive", http.StatusServiceUnavailable, 0)
+	}
+	fired, err = svc.Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	assert.Equal(t, domain.SLOAlertExhausted, fired[0].Alert)
+	assert.Less(t, fired[0].Status.ErrorBudgetRemaining, 0.0)
+}
@@ -0,0 +1,568 @@
+// Package snapshot file: internal/service/snapshot/snapshot_service.go
+package snapshot
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	formatName    = "archiveaegis-biz-snapshot"
+	formatVersion = 1
+
+	manifestEntry = "manifest.json"
+	configEntry   = "config.json"
+	dataDir       = "data/"
+)
+
+const (
+	// configTablePrefix 是业务组配置表的命名前缀。快照带上 auth.db 中所有以它开头且有 biz_name 列的表，
+	// 新增的配置表无需修改这里；usageTablePrefix 开头的用量统计属于实例的运行记录，不随快照迁移
+	configTablePrefix = "biz_"
+	usageTablePrefix  = "biz_usage_"
+
+	// ownersTable 以用户 ID 引用本实例的用户，快照中附带用户名，恢复时按用户名重新对应
+	ownersTable = "biz_owners"
+)
+
+var (
+	// ErrInvalidSnapshot 表示上传的快照包格式不正确或已损坏
+	ErrInvalidSnapshot = errors.New("无效的业务组快照包")
+	// ErrBizDataExists 表示目标实例上已存在该业务组的数据，且未要求覆盖
+	ErrBizDataExists = errors.New("目标业务组已存在数据库文件")
+)
+
+// Manifest 描述快照包的内容，用于恢复时校验
+type Manifest struct {
+	Format        string            `json:"format"`
+	FormatVersion int               `json:"format_version"`
+	BizName       string            `json:"biz_name"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Files         map[string]string `json:"files"` // 库文件名 -> SHA-256
+}
+
+// CacheInvalidator 用于在恢复配置后让配置缓存失效
+type CacheInvalidator interface {
+	InvalidateCacheForBiz(bizName string)
+}
+
+// Instances 是恢复需要的插件实例操作，由 plugin_manager.PluginManager 实现
+type Instances interface {
+	// InstanceForBiz 返回绑定到业务组的插件实例及其是否在运行，没有时返回空字符串
+	InstanceForBiz(bizName string) (string, bool, error)
+	Start(instanceID string) error
+	Stop(instanceID string) error
+}
+
+// RestoreResult 描述一次恢复。业务组绑定的插件实例在运行时，替换库文件期间被停止，之后重新启动
+type RestoreResult struct {
+	Manifest   *Manifest
+	InstanceID string
+	Restarted  bool
+	// Warnings 记录恢复已生效但未能完成的步骤，例如插件实例未能重新启动
+	Warnings []string
+}
+
+// Service 负责把业务组的 SQLite 文件与管理配置打包导出，并在另一实例上恢复。
+type Service struct {
+	db          *sql.DB
+	instanceDir string
+	cache       CacheInvalidator
+	instances   Instances
+
+	// restoreMu 串行化恢复，避免两次恢复交错地停止与启动同一个实例
+	restoreMu sync.Mutex
+}
+
+// NewService 创建一个新的快照服务实例
+func NewService(db *sql.DB, instanceDir string, cache CacheInvalidator, instances Instances) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("snapshot.Service 需要一个有效的数据库连接")
+	}
+	if instanceDir == "" {
+		return nil, errors.New("snapshot.Service 需要有效的 instance 目录")
+	}
+	if instances == nil {
+		return nil, errors.New("snapshot.Service 需要插件管理器")
+	}
+	return &Service{db: db, instanceDir: instanceDir, cache: cache, instances: instances}, nil
+}
+
+// Export 将业务组打包为 zip 写入 w。
+// 每个库通过 VACUUM INTO 生成一致性副本，因此导出期间业务组仍可正常读写。
+func (s *Service) Export(ctx context.Context, bizName string, w io.Writer) error {
+	bizDir, err := s.bizDir(bizName)
+	if err != nil {
+		return err
+	}
+	dbFiles, err := filepath.Glob(filepath.Join(bizDir, "*.db"))
+	if err != nil {
+		return fmt.Errorf("扫描业务组 '%s' 的数据库文件失败: %w", bizName, err)
+	}
+
+	config, err := s.exportConfig(ctx, bizName)
+	if err != nil {
+		return err
+	}
+	if len(dbFiles) == 0 && len(config["biz_overall_settings"]) == 0 {
+		return fmt.Errorf("业务组 '%s' 既没有数据库文件也没有配置，无法创建快照", bizName)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "aegis-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest := Manifest{
+		Format:        formatName,
+		FormatVersion: formatVersion,
+		BizName:       bizName,
+		CreatedAt:     time.Now().UTC(),
+		Files:         make(map[string]string, len(dbFiles)),
+	}
+	copies := make(map[string]string, len(dbFiles))
+	for _, src := range dbFiles {
+		name := filepath.Base(src)
+		dst := filepath.Join(tmpDir, name)
+		if err := vacuumInto(ctx, src, dst); err != nil {
+			return fmt.Errorf("为库 '%s' 生成一致性副本失败: %w", name, err)
+		}
+		sum, err := fileSHA256(dst)
+		if err != nil {
+			return err
+		}
+		manifest.Files[name] = sum
+		copies[name] = dst
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeJSONEntry(zw, manifestEntry, manifest); err != nil {
+		return err
+	}
+	if err := writeJSONEntry(zw, configEntry, config); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(copies))
+	for name := range copies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeFileEntry(zw, dataDir+name, copies[name]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("写入快照包失败: %w", err)
+	}
+	log.Printf("信息: [Snapshot] 业务组 '%s' 快照已导出，包含 %d 个库。", bizName, len(names))
+	return nil
+}
+
+// Restore 从快照包恢复业务组到 bizName (可以与源业务组名称不同)。
+// 已存在数据库文件时，只有 overwrite 为 true 才会覆盖。库文件全部解压并校验后，先停止业务组正在运行的插件实例，
+// 使其关闭对旧库的连接，再替换配置与库文件，最后重新启动实例。实例未能重新启动时恢复仍然生效，原因记入 Warnings
+func (s *Service) Restore(ctx context.Context, bizName string, r io.ReaderAt, size int64, overwrite bool) (*RestoreResult, error) {
+	bizDir, err := s.bizDir(bizName)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	var manifest Manifest
+	if err := readJSONEntry(zr, manifestEntry, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != formatName || manifest.FormatVersion > formatVersion {
+		return nil, fmt.Errorf("%w: 不支持的格式 '%s' (版本 %d)", ErrInvalidSnapshot, manifest.Format, manifest.FormatVersion)
+	}
+	var config map[string][]map[string]interface{}
+	if err := readJSONEntry(zr, configEntry, &config); err != nil {
+		return nil, err
+	}
+
+	existing, _ := filepath.Glob(filepath.Join(bizDir, "*.db"))
+	if len(existing) > 0 && !overwrite {
+		return nil, ErrBizDataExists
+	}
+	if err := os.MkdirAll(bizDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建业务组目录失败: %w", err)
+	}
+
+	// 先把所有库解压到临时文件并校验，全部通过后再替换，避免留下半恢复状态
+	staged := make(map[string]string, len(manifest.Files))
+	defer func() {
+		for _, tmp := range staged {
+			_ = os.Remove(tmp)
+		}
+	}()
+	for name, wantSum := range manifest.Files {
+		if filepath.Base(name) != name || !strings.HasSuffix(name, ".db") {
+			return nil, fmt.Errorf("%w: 非法的库文件名 '%s'", ErrInvalidSnapshot, name)
+		}
+		tmp, err := extractEntry(zr, dataDir+name, bizDir)
+		if err != nil {
+			return nil, err
+		}
+		staged[name] = tmp
+		gotSum, err := fileSHA256(tmp)
+		if err != nil {
+			return nil, err
+		}
+		if gotSum != wantSum {
+			return nil, fmt.Errorf("%w: 库 '%s' 校验和不匹配", ErrInvalidSnapshot, name)
+		}
+	}
+
+	s.restoreMu.Lock()
+	defer s.restoreMu.Unlock()
+	result := &RestoreResult{Manifest: &manifest, Warnings: []string{}}
+	instanceID, running, err := s.instances.InstanceForBiz(bizName)
+	if err != nil {
+		return nil, err
+	}
+	result.InstanceID = instanceID
+	if running {
+		if err := s.instances.Stop(instanceID); err != nil {
+			return nil, fmt.Errorf("停止插件实例 '%s' 失败: %w", instanceID, err)
+		}
+	}
+	// 出错时重新启动实例，让业务组继续以未被替换的库文件提供服务
+	restart := func() {
+		if running {
+			if err := s.instances.Start(instanceID); err != nil {
+				log.Printf("错误: [Snapshot] 恢复失败后重新启动插件实例 '%s' 失败: %v", instanceID, err)
+			}
+		}
+	}
+
+	if err := s.restoreConfig(ctx, bizName, config); err != nil {
+		restart()
+		return nil, err
+	}
+
+	for name, tmp := range staged {
+		target := filepath.Join(bizDir, name)
+		// WAL 辅助文件属于旧库，必须一并移除，否则新库会被错误的日志“回放”
+		_ = os.Remove(target + "-wal")
+		_ = os.Remove(target + "-shm")
+		if err := os.Rename(tmp, target); err != nil {
+			restart()
+			return nil, fmt.Errorf("替换库文件 '%s' 失败: %w", name, err)
+		}
+		delete(staged, name)
+	}
+
+	if s.cache != nil {
+		s.cache.InvalidateCacheForBiz(bizName)
+	}
+	if running {
+		if err := s.instances.Start(instanceID); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("插件实例 '%s' 重新启动失败: %v", instanceID, err))
+		} else {
+			result.Restarted = true
+		}
+	}
+	log.Printf("信息: [Snapshot] 已从 '%s' 的快照恢复业务组 '%s'，包含 %d 个库。", manifest.BizName, bizName, len(manifest.Files))
+	return result, nil
+}
+
+func (s *Service) bizDir(bizName string) (string, error) {
+	if bizName == "" || bizName != filepath.Base(bizName) || strings.HasPrefix(bizName, ".") {
+		return "", fmt.Errorf("非法的业务组名称 '%s'", bizName)
+	}
+	return filepath.Join(s.instanceDir, bizName), nil
+}
+
+// exportConfig 以通用的行结构导出所有配置表中属于该业务组的记录，新增表与新增列都无需修改此处。
+// 业务组负责人的记录附带 username 与 granted_by_username，供另一实例按用户名对应
+func (s *Service) exportConfig(ctx context.Context, bizName string) (map[string][]map[string]interface{}, error) {
+	tables, err := configTables(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string][]map[string]interface{}, len(tables))
+	for _, table := range tables {
+		query := fmt.Sprintf(`SELECT * FROM %q WHERE biz_name = ?`, table)
+		if table == ownersTable {
+			query = `SELECT o.*, u.username, g.username AS granted_by_username FROM biz_owners o
+				JOIN _user u ON u.id = o.user_id LEFT JOIN _user g ON g.id = o.granted_by WHERE o.biz_name = ?`
+		}
+		rows, err := s.db.QueryContext(ctx, query, bizName)
+		if err != nil {
+			return nil, fmt.Errorf("导出配置表 '%s' 失败: %w", table, err)
+		}
+		records, err := scanRows(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置表 '%s' 失败: %w", table, err)
+		}
+		config[table] = records
+	}
+	return config, nil
+}
+
+// restoreConfig 在单个事务中替换业务组的全部配置。
+// 快照中存在而当前版本已不存在的表与列会被忽略，快照中没有的表 (由旧版本导出) 保持不变，以兼容不同版本之间的迁移。
+// 负责人按用户名对应到本实例的用户，本实例没有该用户时跳过该记录
+func (s *Service) restoreConfig(ctx context.Context, bizName string, config map[string][]map[string]interface{}) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败 (业务 '%s'): %w", bizName, err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// 可查询表与字段设置以外键引用总体配置，外键检查推迟到提交时进行，删除与插入的顺序因此无关紧要
+	if _, err = tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return fmt.Errorf("推迟外键检查失败: %w", err)
+	}
+	tables, err := configTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		records, inSnapshot := config[table]
+		if !inSnapshot {
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE biz_name = ?`, table), bizName); err != nil {
+			return fmt.Errorf("清除配置表 '%s' 失败: %w", table, err)
+		}
+		if len(records) == 0 {
+			continue
+		}
+		columns, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			record["biz_name"] = bizName
+			if table == ownersTable {
+				ok, err := mapOwner(ctx, tx, record)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					log.Printf("警告: [Snapshot] 本实例没有用户 '%v'，跳过业务组 '%s' 的该负责人。", record["username"], bizName)
+					continue
+				}
+			}
+			var cols, placeholders []string
+			var args []interface{}
+			for _, col := range columns {
+				if val, ok := record[col]; ok {
+					cols = append(cols, fmt.Sprintf("%q", col))
+					placeholders = append(placeholders, "?")
+					args = append(args, val)
+				}
+			}
+			query := fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s)`, table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("恢复配置表 '%s' 失败: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// queryer 是 *sql.DB 与 *sql.Tx 共有的查询方法
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// configTables 返回 auth.db 中所有带 biz_name 列的业务组配置表，按表名排序
+func configTables(ctx context.Context, q queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT m.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND p.name = 'biz_name' AND substr(m.name, 1, ?) = ? AND substr(m.name, 1, ?) <> ?
+		ORDER BY m.name`, len(configTablePrefix), configTablePrefix, len(usageTablePrefix), usageTablePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("查找业务组配置表失败: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("查找业务组配置表失败: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// mapOwner 把负责人记录中的用户名换成本实例的用户 ID。负责人不存在时返回 false；授权人不存在时记为空
+func mapOwner(ctx context.Context, tx *sql.Tx, record map[string]interface{}) (bool, error) {
+	lookup := func(username interface{}) (interface{}, error) {
+		if username == nil {
+			return nil, nil
+		}
+		var id int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM _user WHERE username = ?`, username).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查找用户 '%v' 失败: %w", username, err)
+		}
+		return id, nil
+	}
+	userID, err := lookup(record["username"])
+	if err != nil || userID == nil {
+		return false, err
+	}
+	grantedBy, err := lookup(record["granted_by_username"])
+	if err != nil {
+		return false, err
+	}
+	record["user_id"], record["granted_by"] = userID, grantedBy
+	return true, nil
+}
+
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %q LIMIT 0`, table))
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 结构失败: %w", table, err)
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
+
+func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				record[col] = string(b)
+			} else {
+				record[col] = vals[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func vacuumInto(ctx context.Context, src, dst string) error {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro&_busy_timeout=5000", src))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.ExecContext(ctx, `VACUUM INTO ?`, dst)
+	return err
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("计算文件 '%s' 校验和失败: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("写入快照条目 '%s' 失败: %w", name, err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeFileEntry(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("写入快照条目 '%s' 失败: %w", name, err)
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func readJSONEntry(zr *zip.Reader, name string, v interface{}) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: 缺少 '%s'", ErrInvalidSnapshot, name)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("%w: 解析 '%s' 失败: %v", ErrInvalidSnapshot, name, err)
+	}
+	return nil
+}
+
+// extractEntry 将 zip 条目解压到 dir 下的临时文件，返回临时文件路径
+func extractEntry(zr *zip.Reader, name, dir string) (string, error) {
+	src, err := zr.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: 缺少 '%s'", ErrInvalidSnapshot, name)
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(dir, ".restore-*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		_ = os.Remove(dst.Name())
+		return "", fmt.Errorf("解压 '%s' 失败: %w", name, err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
@@ -0,0 +1,271 @@
+// file: internal/service/snapshot/snapshot_service_test.go
+package snapshot
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInstances 记录恢复对插件实例的操作，onStop 与 onStart 在对应操作时被调用
+type fakeInstances struct {
+	instanceID string
+	running    bool
+	calls      []string
+	startErr   error
+	onStop     func()
+	onStart    func()
+}
+
+func (f *fakeInstances) InstanceForBiz(bizName string) (string, bool, error) {
+	return f.instanceID, f.running, nil
+}
+func (f *fakeInstances) Start(instanceID string) error {
+	f.calls = append(f.calls, "start "+instanceID)
+	if f.onStart != nil {
+		f.onStart()
+	}
+	return f.startErr
+}
+func (f *fakeInstances) Stop(instanceID string) error {
+	f.calls = append(f.calls, "stop "+instanceID)
+	if f.onStop != nil {
+		f.onStop()
+	}
+	return nil
+}
+
+func TestExportAndRestore(t *testing.T) {
+	ctx := context.Background()
+
+	// --- 源实例: 一个业务组，一个库文件，若干配置 ---
+	srcSys := fixture.NewSystemDB(t)
+	srcInstance := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(srcInstance, "letters"), 0755))
+	libDB, err := sql.Open("sqlite", filepath.Join(srcInstance, "letters", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT); INSERT INTO letters (sender) VALUES ('鲁迅');`)
+	require.NoError(t, err)
+	require.NoError(t, libDB.Close())
+
+	_, err = srcSys.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('letters', TRUE, 'letters')`)
+	require.NoError(t, err)
+	_, err = srcSys.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name, allow_create) VALUES ('letters', 'letters', TRUE)`)
+	require.NoError(t, err)
+	_, err = srcSys.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable) VALUES ('letters', 'letters', 'sender', TRUE, TRUE)`)
+	require.NoError(t, err)
+
+	src, err := NewService(srcSys, srcInstance, nil, &fakeInstances{})
+	require.NoError(t, err)
+	var bundle bytes.Buffer
+	require.NoError(t, src.Export(ctx, "letters", &bundle))
+
+	// --- 目标实例: 以新名称恢复 ---
+	dstSys := fixture.NewSystemDB(t)
+	dstInstance := t.TempDir()
+	dst, err := NewService(dstSys, dstInstance, nil, &fakeInstances{})
+	require.NoError(t, err)
+
+	reader := bytes.NewReader(bundle.Bytes())
+	result, err := dst.Restore(ctx, "letters_mirror", reader, int64(bundle.Len()), false)
+	require.NoError(t, err)
+	assert.Equal(t, "letters", result.Manifest.BizName)
+	assert.Len(t, result.Manifest.Files, 1)
+	assert.False(t, result.Restarted, "业务组没有插件实例")
+
+	restoredDB, err := sql.Open("sqlite", filepath.Join(dstInstance, "letters_mirror", "main.db"))
+	require.NoError(t, err)
+	defer restoredDB.Close()
+	var sender string
+	require.NoError(t, restoredDB.QueryRow(`SELECT sender FROM letters WHERE id = 1`).Scan(&sender))
+	assert.Equal(t, "鲁迅", sender)
+
+	var defaultTable string
+	var allowCreate bool
+	require.NoError(t, dstSys.QueryRow(`SELECT default_query_table FROM biz_overall_settings WHERE biz_name = 'letters_mirror'`).Scan(&defaultTable))
+	require.NoError(t, dstSys.QueryRow(`SELECT allow_create FROM biz_searchable_tables WHERE biz_name = 'letters_mirror' AND table_name = 'letters'`).Scan(&allowCreate))
+	assert.Equal(t, "letters", defaultTable)
+	assert.True(t, allowCreate)
+
+	// 再次恢复到同一业务组时，未指定覆盖应被拒绝
+	_, err = dst.Restore(ctx, "letters_mirror", reader, int64(bundle.Len()), false)
+	assert.ErrorIs(t, err, ErrBizDataExists)
+	_, err = dst.Restore(ctx, "letters_mirror", reader, int64(bundle.Len()), true)
+	assert.NoError(t, err)
+}
+
+// newLettersBundle 导出一个包含 letters 业务组与单个库文件的快照
+func newLettersBundle(t *testing.T) []byte {
+	t.Helper()
+	srcSys := fixture.NewSystemDB(t)
+	srcInstance := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(srcInstance, "letters"), 0755))
+	libDB, err := sql.Open("sqlite", filepath.Join(srcInstance, "letters", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT); INSERT INTO letters (sender) VALUES ('鲁迅');`)
+	require.NoError(t, err)
+	require.NoError(t, libDB.Close())
+	_, err = srcSys.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('letters', TRUE, 'letters')`)
+	require.NoError(t, err)
+
+	src, err := NewService(srcSys, srcInstance, nil, &fakeInstances{})
+	require.NoError(t, err)
+	var bundle bytes.Buffer
+	require.NoError(t, src.Export(context.Background(), "letters", &bundle))
+	return bundle.Bytes()
+}
+
+func TestRestoreStopsRunningInstanceDuringSwap(t *testing.T) {
+	ctx := context.Background()
+	bundle := newLettersBundle(t)
+	dstInstance := t.TempDir()
+	liveFile := filepath.Join(dstInstance, "letters", "main.db")
+	require.NoError(t, os.Mkdir(filepath.Join(dstInstance, "letters"), 0755))
+	liveDB, err := sql.Open("sqlite", liveFile)
+	require.NoError(t, err)
+	_, err = liveDB.Exec(`CREATE TABLE letters (id INTEGER PRIMARY KEY, sender TEXT); INSERT INTO letters (sender) VALUES ('旧数据');`)
+	require.NoError(t, err)
+	require.NoError(t, liveDB.Close())
+
+	sender := func() string {
+		db, err := sql.Open("sqlite", liveFile)
+		require.NoError(t, err)
+		defer db.Close()
+		var s string
+		require.NoError(t, db.QueryRow(`SELECT sender FROM letters WHERE id = 1`).Scan(&s))
+		return s
+	}
+	var atStop, atStart string
+	instances := &fakeInstances{instanceID: "letters-1", running: true}
+	instances.onStop = func() { atStop = sender() }
+	instances.onStart = func() { atStart = sender() }
+
+	dst, err := NewService(fixture.NewSystemDB(t), dstInstance, nil, instances)
+	require.NoError(t, err)
+	result, err := dst.Restore(ctx, "letters", bytes.NewReader(bundle), int64(len(bundle)), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"stop letters-1", "start letters-1"}, instances.calls)
+	assert.Equal(t, "旧数据", atStop, "实例在替换库文件之前停止")
+	assert.Equal(t, "鲁迅", atStart, "实例在替换库文件之后启动")
+	assert.Equal(t, "letters-1", result.InstanceID)
+	assert.True(t, result.Restarted)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestRestoreLeavesStoppedInstanceAlone(t *testing.T) {
+	bundle := newLettersBundle(t)
+	instances := &fakeInstances{instanceID: "letters-1", running: false}
+	dst, err := NewService(fixture.NewSystemDB(t), t.TempDir(), nil, instances)
+	require.NoError(t, err)
+
+	result, err := dst.Restore(context.Background(), "letters", bytes.NewReader(bundle), int64(len(bundle)), false)
+	require.NoError(t, err)
+	assert.Empty(t, instances.calls, "未运行的实例不应被启动")
+	assert.False(t, result.Restarted)
+}
+
+func TestRestoreReportsRestartFailure(t *testing.T) {
+	bundle := newLettersBundle(t)
+	dstInstance := t.TempDir()
+	instances := &fakeInstances{instanceID: "letters-1", running: true, startErr: errors.New("端口被占用")}
+	dst, err := NewService(fixture.NewSystemDB(t), dstInstance, nil, instances)
+	require.NoError(t, err)
+
+	result, err := dst.Restore(context.Background(), "letters", bytes.NewReader(bundle), int64(len(bundle)), false)
+	require.NoError(t, err, "实例未能重新启动时恢复仍然生效")
+	assert.False(t, result.Restarted)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "端口被占用")
+	assert.FileExists(t, filepath.Join(dstInstance, "letters", "main.db"))
+}
+
+func TestRestoreKeepsInstanceRunningOnInvalidBundle(t *testing.T) {
+	instances := &fakeInstances{instanceID: "letters-1", running: true}
+	svc, err := NewService(fixture.NewSystemDB(t), t.TempDir(), nil, instances)
+	require.NoError(t, err)
+
+	garbage := []byte("not a zip")
+	_, err = svc.Restore(context.Background(), "letters", bytes.NewReader(garbage), int64(len(garbage)), false)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	assert.Empty(t, instances.calls, "快照校验失败时不应停止实例")
+}
+
+func TestNewServiceRequiresInstances(t *testing.T) {
+	_, err := NewService(fixture.NewSystemDB(t), t.TempDir(), nil, nil)
+	assert.Error(t, err)
+}
+
+func TestRestoreRejectsInvalidBundle(t *testing.T) {
+	svc, err := NewService(fixture.NewSystemDB(t), t.TempDir(), nil, &fakeInstances{})
+	require.NoError(t, err)
+
+	garbage := []byte("not a zip")
+	_, err = svc.Restore(context.Background(), "biz", bytes.NewReader(garbage), int64(len(garbage)), false)
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	_, err = svc.Restore(context.Background(), "../escape", bytes.NewReader(garbage), int64(len(garbage)), false)
+	assert.Error(t, err)
+}
+
+func TestSnapshotCarriesAllConfigTables(t *testing.T) {
+	ctx := context.Background()
+	srcSys := fixture.NewSystemDB(t)
+	for _, stmt := range []string{
+		`INSERT INTO _user (id, username, password_hash, role) VALUES (1, 'alice', 'x', 'admin'), (2, 'bob', 'x', 'admin')`,
+		`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('letters', TRUE, 'letters')`,
+		`INSERT INTO biz_owners (biz_name, user_id, granted_by, granted_at) VALUES ('letters', 1, 2, CURRENT_TIMESTAMP), ('letters', 2, NULL, CURRENT_TIMESTAMP)`,
+		`INSERT INTO biz_query_dictionaries (biz_name, dictionary_json) VALUES ('letters', '{"鲁迅": ["周树人"]}')`,
+		`INSERT INTO biz_query_templates (biz_name, name, template_json, updated_at) VALUES ('letters', 'by_sender', '{}', CURRENT_TIMESTAMP)`,
+		`INSERT INTO biz_semantic_search (biz_name, table_name, fields_json, updated_at) VALUES ('letters', 'letters', '["body"]', CURRENT_TIMESTAMP)`,
+		`INSERT INTO biz_slos (biz_name, availability_target, updated_at) VALUES ('letters', 0.99, CURRENT_TIMESTAMP)`,
+		`INSERT INTO biz_usage_daily (biz_name, day, queries) VALUES ('letters', '2026-10-01', 12)`,
+	} {
+		_, err := srcSys.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+	src, err := NewService(srcSys, t.TempDir(), nil, &fakeInstances{})
+	require.NoError(t, err)
+	var bundle bytes.Buffer
+	require.NoError(t, src.Export(ctx, "letters", &bundle))
+
+	// 目标实例上 alice 的 ID 不同，bob 不存在
+	dstSys := fixture.NewSystemDB(t)
+	_, err = dstSys.Exec(`INSERT INTO _user (id, username, password_hash, role) VALUES (7, 'carol', 'x', 'user'), (8, 'alice', 'x', 'admin')`)
+	require.NoError(t, err)
+	_, err = dstSys.Exec(`INSERT INTO biz_slos (biz_name, availability_target, updated_at) VALUES ('letters', 0.5, CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	dst, err := NewService(dstSys, t.TempDir(), nil, &fakeInstances{})
+	require.NoError(t, err)
+	_, err = dst.Restore(ctx, "letters", bytes.NewReader(bundle.Bytes()), int64(bundle.Len()), false)
+	require.NoError(t, err)
+
+	count := func(table string) int {
+		var n int
+		require.NoError(t, dstSys.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE biz_name = 'letters'`).Scan(&n))
+		return n
+	}
+	for _, table := range []string{"biz_query_dictionaries", "biz_query_templates", "biz_semantic_search", "biz_slos"} {
+		assert.Equal(t, 1, count(table), table)
+	}
+	assert.Zero(t, count("biz_usage_daily"), "用量统计不随快照迁移")
+
+	var target float64
+	require.NoError(t, dstSys.QueryRow(`SELECT availability_target FROM biz_slos WHERE biz_name = 'letters'`).Scan(&target))
+	assert.Equal(t, 0.99, target, "恢复替换已有的配置")
+
+	var userID int64
+	var grantedBy sql.NullInt64
+	require.NoError(t, dstSys.QueryRow(`SELECT user_id, granted_by FROM biz_owners WHERE biz_name = 'letters'`).Scan(&userID, &grantedBy))
+	assert.Equal(t, int64(8), userID, "负责人按用户名对应")
+	assert.False(t, grantedBy.Valid, "授权人在本实例不存在")
+	assert.Equal(t, 1, count("biz_owners"), "本实例没有的负责人被跳过")
+}
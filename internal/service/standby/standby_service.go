@@ -0,0 +1,421 @@
+// Package standby file: internal/service/standby/standby_service.go
+package standby
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SnapshotPath 是主网关提供 auth.db 快照的接口路径
+	SnapshotPath = "/api/v1/admin/standby/snapshot"
+	// SnapshotTimeHeader 携带主网关生成快照的时间 (RFC 3339)，备用网关据此计算复制延迟
+	SnapshotTimeHeader = "X-Aegis-Snapshot-Time"
+
+	// RoleActive 与 RoleStandby 是网关的角色
+	RoleActive  = "active"
+	RoleStandby = "standby"
+
+	defaultIntervalSeconds = 30
+	// promotedFilename 记录备用网关已被提升，重启后不再以备用模式运行
+	promotedFilename = "standby-promoted.json"
+	snapshotSchema   = "standby_snapshot"
+)
+
+var (
+	// ErrNotStandby 表示网关不是备用网关，不能提升
+	ErrNotStandby = errors.New("网关不是备用网关")
+)
+
+// Options 定义备用模式的配置。启用后网关定期从主网关拉取 auth.db 快照并以只读方式提供服务，
+// 主网关故障时由管理员通过 POST /api/v1/admin/standby/promote 提升为主网关
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// PrimaryURL 是主网关的地址，例如 https://aegis-a.example.org
+	PrimaryURL string `mapstructure:"primary_url"`
+	// AuthToken 是主网关上管理员的 API Token
+	AuthToken string `mapstructure:"auth_token"`
+	// IntervalSeconds 是拉取快照的间隔，默认为 30 秒
+	IntervalSeconds int `mapstructure:"interval_seconds"`
+}
+
+// Status 是网关的角色与复制状态，供负载均衡与监控判断故障切换
+type Status struct {
+	Role       string `json:"role"`
+	PrimaryURL string `json:"primary_url,omitempty"`
+	// LastSnapshotAt 是最近一次应用的快照在主网关上的生成时间
+	LastSnapshotAt *time.Time `json:"last_snapshot_at,omitempty"`
+	LastSyncedAt   *time.Time `json:"last_synced_at,omitempty"`
+	// LagSeconds 是当前时间与 LastSnapshotAt 的差，尚未应用过快照时为空
+	LagSeconds *float64   `json:"lag_seconds,omitempty"`
+	Syncs      int64      `json:"syncs"`
+	LastError  string     `json:"last_error,omitempty"`
+	PromotedAt *time.Time `json:"promoted_at,omitempty"`
+}
+
+// Service 在主网关上生成 auth.db 的一致性快照，在备用网关上定期拉取并应用快照，并负责把备用网关提升为主网关。
+// 快照以逐表替换的方式应用到打开中的 auth.db，网关运行期间读取的连接不需要重新打开
+type Service struct {
+	db          *sql.DB
+	instanceDir string
+	opts        Options
+	client      *http.Client
+
+	// syncMu 使提升等待进行中的同步结束，提升之后不再有快照覆盖本地的写入
+	syncMu sync.Mutex
+	// cancelSync 取消进行中的同步，主网关无响应时提升不必等待请求超时
+	cancelSync context.CancelFunc
+
+	mu             sync.RWMutex
+	role           string
+	lastSnapshotAt time.Time
+	lastSyncedAt   time.Time
+	syncs          int64
+	lastError      string
+	promotedAt     time.Time
+	// active 在网关成为主网关时关闭
+	active chan struct{}
+}
+
+// NewService 创建备用模式服务。未启用备用模式，或备用网关此前已被提升时，网关以主网关角色运行
+func NewService(db *sql.DB, instanceDir string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("standby.Service 需要一个有效的数据库连接")
+	}
+	if opts.IntervalSeconds <= 0 {
+		opts.IntervalSeconds = defaultIntervalSeconds
+	}
+	opts.PrimaryURL = strings.TrimRight(opts.PrimaryURL, "/")
+	s := &Service{
+		db:          db,
+		instanceDir: instanceDir,
+		opts:        opts,
+		client:      &http.Client{Timeout: 5 * time.Minute},
+		role:        RoleActive,
+		active:      make(chan struct{}),
+	}
+	if opts.Enabled {
+		if opts.PrimaryURL == "" || opts.AuthToken == "" {
+			return nil, errors.New("备用模式需要配置 primary_url 与 auth_token")
+		}
+		var promoted struct {
+			PromotedAt time.Time `json:"promoted_at"`
+		}
+		data, err := os.ReadFile(filepath.Join(instanceDir, promotedFilename))
+		switch {
+		case err == nil && json.Unmarshal(data, &promoted) == nil:
+			s.promotedAt = promoted.PromotedAt
+			slog.Warn("备用网关此前已被提升，以主网关角色运行；如需重新作为备用网关，请删除提升记录", "file", promotedFilename)
+		case err == nil || errors.Is(err, os.ErrNotExist):
+			s.role = RoleStandby
+		default:
+			return nil, fmt.Errorf("读取提升记录失败: %w", err)
+		}
+	}
+	if s.role == RoleActive {
+		close(s.active)
+	}
+	return s, nil
+}
+
+// IsStandby 报告网关当前是否为只读的备用网关
+func (s *Service) IsStandby() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.role == RoleStandby
+}
+
+// Active 返回一个在网关成为主网关时关闭的通道，只应在主网关上运行的后台任务等待它之后再启动
+func (s *Service) Active() <-chan struct{} {
+	return s.active
+}
+
+// Status 返回网关的角色与复制状态
+func (s *Service) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := Status{Role: s.role, Syncs: s.syncs, LastError: s.lastError}
+	if s.opts.Enabled {
+		st.PrimaryURL = s.opts.PrimaryURL
+	}
+	if !s.lastSnapshotAt.IsZero() {
+		snapshotAt, syncedAt := s.lastSnapshotAt, s.lastSyncedAt
+		lag := time.Since(snapshotAt).Seconds()
+		st.LastSnapshotAt, st.LastSyncedAt, st.LagSeconds = &snapshotAt, &syncedAt, &lag
+	}
+	if !s.promotedAt.IsZero() {
+		promotedAt := s.promotedAt
+		st.PromotedAt = &promotedAt
+	}
+	return st
+}
+
+// WriteSnapshot 通过 VACUUM INTO 生成 auth.db 的一致性快照并写入 w。
+// 快照生成后、写入之前以快照的生成时间与大小调用 writeHeader，调用方在其中设置响应头
+func (s *Service) WriteSnapshot(ctx context.Context, w io.Writer, writeHeader func(createdAt time.Time, size int64)) error {
+	tmpDir, err := os.MkdirTemp("", "aegis-standby-*")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "auth.db")
+	createdAt := time.Now().UTC()
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("生成 auth.db 快照失败: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	writeHeader(createdAt, info.Size())
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// Run 在备用网关上按间隔拉取快照，直到 ctx 结束或网关被提升
+func (s *Service) Run(ctx context.Context) {
+	if !s.IsStandby() {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.opts.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil && !errors.Is(err, ErrNotStandby) && ctx.Err() == nil {
+			slog.Warn("从主网关拉取 auth.db 快照失败", "primary", s.opts.PrimaryURL, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.active:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 从主网关拉取一次快照并应用到本地 auth.db
+func (s *Service) Sync(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if !s.IsStandby() {
+		return ErrNotStandby
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.cancelSync = cancel
+	s.mu.Unlock()
+
+	snapshotAt, err := s.pull(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelSync = nil
+	if err != nil {
+		s.lastError = err.Error()
+	} else {
+		s.lastError = ""
+		s.lastSnapshotAt, s.lastSyncedAt = snapshotAt, time.Now().UTC()
+		s.syncs++
+	}
+	if !s.lastSnapshotAt.IsZero() {
+		aegobserve.StandbyReplicationLag.Set(time.Since(s.lastSnapshotAt).Seconds())
+	}
+	return err
+}
+
+func (s *Service) pull(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.PrimaryURL+SnapshotPath, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.opts.AuthToken)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求主网关失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return time.Time{}, fmt.Errorf("主网关返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	snapshotAt, err := time.Parse(time.RFC3339Nano, resp.Header.Get(SnapshotTimeHeader))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("主网关的响应缺少有效的 %s 头", SnapshotTimeHeader)
+	}
+
+	tmp, err := os.CreateTemp("", "aegis-standby-*.db")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("下载快照失败: %w", err)
+	}
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return time.Time{}, fmt.Errorf("下载快照不完整: 收到 %d 字节，应为 %d 字节", n, resp.ContentLength)
+	}
+	if err := s.apply(ctx, tmp.Name()); err != nil {
+		return time.Time{}, err
+	}
+	return snapshotAt, nil
+}
+
+// apply 把快照附加到本地 auth.db 的一个连接上，在一个事务中逐表替换两边都有的表，只复制两边共有的列。
+// 本地较新版本的网关新增的表与列保持不变，主网关较新版本新增的表被忽略
+func (s *Service) apply(ctx context.Context, path string) (err error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS `+snapshotSchema, path); err != nil {
+		return fmt.Errorf("打开快照失败: %w", err)
+	}
+	defer func() {
+		if _, errDetach := conn.ExecContext(context.Background(), `DETACH DATABASE `+snapshotSchema); errDetach != nil && err == nil {
+			err = errDetach
+		}
+	}()
+	var check string
+	if err := conn.QueryRowContext(ctx, `PRAGMA `+snapshotSchema+`.quick_check`).Scan(&check); err != nil || check != "ok" {
+		return fmt.Errorf("快照完整性检查失败: %v %s", err, check)
+	}
+
+	tables, err := commonTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return errors.New("快照中没有任何与本地相同的表")
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	// 外键在事务提交时检查，表的替换顺序无关紧要
+	if _, err = tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		var columns []string
+		columns, err = commonColumns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		quoted := quoteIdent(table)
+		if _, err = tx.ExecContext(ctx, `DELETE FROM main.`+quoted); err != nil {
+			return fmt.Errorf("清空表 '%s' 失败: %w", table, err)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		list := strings.Join(columns, ", ")
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO main.%s (%s) SELECT %s FROM %s.%s`, quoted, list, list, snapshotSchema, quoted)); err != nil {
+			return fmt.Errorf("复制表 '%s' 失败: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// commonTables 返回本地与快照都有的表，包括记录自增序号的 sqlite_sequence
+func commonTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT m.name FROM main.sqlite_master m
+		JOIN `+snapshotSchema+`.sqlite_master s ON s.name = m.name AND s.type = 'table'
+		WHERE m.type = 'table' AND (m.name NOT LIKE 'sqlite\_%' ESCAPE '\' OR m.name = 'sqlite_sequence')
+		ORDER BY m.name`)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照的表失败: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func commonColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT m.name FROM pragma_table_info(?, 'main') m
+		JOIN pragma_table_info(?, '`+snapshotSchema+`') s ON s.name = m.name
+		ORDER BY m.cid`, table, table)
+	if err != nil {
+		return nil, fmt.Errorf("读取表 '%s' 的列失败: %w", table, err)
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, quoteIdent(name))
+	}
+	return columns, rows.Err()
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Promote 把备用网关提升为主网关: 停止拉取快照、解除只读并启动只应在主网关上运行的后台任务。
+// 提升记录写入根目录，重启后仍以主网关角色运行
+func (s *Service) Promote() (Status, error) {
+	s.mu.Lock()
+	if s.cancelSync != nil {
+		s.cancelSync()
+	}
+	s.mu.Unlock()
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.mu.Lock()
+	if s.role != RoleStandby {
+		s.mu.Unlock()
+		return Status{}, ErrNotStandby
+	}
+	promotedAt := time.Now().UTC()
+	data, err := json.Marshal(map[string]interface{}{"promoted_at": promotedAt, "primary_url": s.opts.PrimaryURL})
+	if err == nil {
+		err = os.WriteFile(filepath.Join(s.instanceDir, promotedFilename), data, 0o644)
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return Status{}, fmt.Errorf("写入提升记录失败: %w", err)
+	}
+	s.role, s.promotedAt = RoleActive, promotedAt
+	close(s.active)
+	s.mu.Unlock()
+	slog.Warn("备用网关已提升为主网关", "previous_primary", s.opts.PrimaryURL)
+	return s.Status(), nil
+}
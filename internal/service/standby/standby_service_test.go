@@ -0,0 +1,115 @@
+// file: internal/service/standby/standby_service_test.go
+package standby
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPrimary 以 httptest 服务模拟主网关的快照接口
+func newPrimary(t *testing.T) (*sql.DB, *httptest.Server) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	primary, err := NewService(db, t.TempDir(), Options{})
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != SnapshotPath || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		err := primary.WriteSnapshot(r.Context(), w, func(createdAt time.Time, size int64) {
+			w.Header().Set(SnapshotTimeHeader, createdAt.Format(time.RFC3339Nano))
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return db, server
+}
+
+func TestStandbySyncAndPromote(t *testing.T) {
+	ctx := context.Background()
+	primaryDB, server := newPrimary(t)
+	_, err := primaryDB.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('admin', 'hash', 'admin'), ('reader', 'hash', 'user')`)
+	require.NoError(t, err)
+
+	instanceDir := t.TempDir()
+	standbyDB := fixture.OpenSystemDB(t, filepath.Join(instanceDir, "auth.db"))
+	_, err = standbyDB.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('stale', 'hash', 'user')`)
+	require.NoError(t, err)
+	s, err := NewService(standbyDB, instanceDir, Options{Enabled: true, PrimaryURL: server.URL + "/", AuthToken: "token"})
+	require.NoError(t, err)
+	assert.True(t, s.IsStandby())
+	select {
+	case <-s.Active():
+		t.Fatal("备用网关在提升前不应处于主网关角色")
+	default:
+	}
+	assert.Nil(t, s.Status().LagSeconds, "尚未同步时没有复制延迟")
+
+	require.NoError(t, s.Sync(ctx))
+	var users []string
+	rows, err := standbyDB.Query(`SELECT username FROM _user ORDER BY id`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		users = append(users, name)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"admin", "reader"}, users, "快照替换本地的表")
+
+	status := s.Status()
+	assert.Equal(t, RoleStandby, status.Role)
+	assert.Equal(t, int64(1), status.Syncs)
+	require.NotNil(t, status.LagSeconds)
+	assert.Less(t, *status.LagSeconds, 60.0)
+	assert.Empty(t, status.LastError)
+
+	bad, err := NewService(standbyDB, t.TempDir(), Options{Enabled: true, PrimaryURL: server.URL, AuthToken: "wrong"})
+	require.NoError(t, err)
+	assert.Error(t, bad.Sync(ctx))
+	assert.Contains(t, bad.Status().LastError, "401")
+
+	status, err = s.Promote()
+	require.NoError(t, err)
+	assert.Equal(t, RoleActive, status.Role)
+	assert.NotNil(t, status.PromotedAt)
+	assert.False(t, s.IsStandby())
+	<-s.Active()
+	assert.ErrorIs(t, s.Sync(ctx), ErrNotStandby, "提升后不再复制")
+	_, err = s.Promote()
+	assert.ErrorIs(t, err, ErrNotStandby)
+
+	restarted, err := NewService(standbyDB, instanceDir, Options{Enabled: true, PrimaryURL: server.URL, AuthToken: "token"})
+	require.NoError(t, err)
+	assert.False(t, restarted.IsStandby(), "提升记录在重启后保留")
+	assert.NotNil(t, restarted.Status().PromotedAt)
+}
+
+func TestNewServiceValidation(t *testing.T) {
+	db := fixture.NewSystemDB(t)
+	_, err := NewService(nil, t.TempDir(), Options{})
+	assert.Error(t, err)
+	_, err = NewService(db, t.TempDir(), Options{Enabled: true, PrimaryURL: "https://aegis-a.example.org"})
+	assert.Error(t, err, "备用模式需要 auth_token")
+
+	s, err := NewService(db, t.TempDir(), Options{})
+	require.NoError(t, err)
+	assert.False(t, s.IsStandby())
+	assert.Equal(t, RoleActive, s.Status().Role)
+	_, err = s.Promote()
+	assert.ErrorIs(t, err, ErrNotStandby)
+}
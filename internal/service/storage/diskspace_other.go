@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+// Package storage file: internal/service/storage/diskspace_other.go
+package storage
+
+import "errors"
+
+// diskSpace 在不支持的平台上总是返回错误，低磁盘保护因此不生效
+func diskSpace(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("当前平台不支持读取磁盘可用空间")
+}
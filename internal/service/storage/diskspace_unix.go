@@ -0,0 +1,15 @@
+//go:build unix
+
+// Package storage file: internal/service/storage/diskspace_unix.go
+package storage
+
+import "syscall"
+
+// diskSpace 返回 path 所在卷的总空间与非特权用户可用的空间
+func diskSpace(path string) (uint64, uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Blocks) * uint64(st.Bsize), uint64(st.Bavail) * uint64(st.Bsize), nil
+}
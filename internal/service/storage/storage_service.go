@@ -0,0 +1,248 @@
+// Package storage file: internal/service/storage/storage_service.go
+package storage
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMinFreeMB = 1024
+	// freeSpaceTTL 内复用上一次读取的可用空间，避免每个写请求都查询文件系统
+	freeSpaceTTL = 5 * time.Second
+)
+
+// Options 定义空间统计与低磁盘保护的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinFreeMB 是 instance 目录所在卷的最小可用空间，低于该值时拒绝写操作与插件安装，默认为 1024 MB
+	MinFreeMB int `mapstructure:"min_free_mb"`
+	// BackupDirectories 是需要统计占用空间的备份目录
+	BackupDirectories []string `mapstructure:"backup_directories"`
+}
+
+// Service 统计业务组库文件、instance 目录、插件安装目录与备份目录的空间占用，
+// 并在 instance 目录所在卷的可用空间低于下限时拒绝写操作 (由 Wrap 返回的装饰器执行) 与插件安装
+type Service struct {
+	instanceDir string
+	pluginDir   string
+	opts        Options
+	now         func() time.Time
+	diskSpace   func(path string) (total, free uint64, err error)
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	free      uint64
+	total     uint64
+}
+
+// NewService 创建一个新的空间统计服务实例
+func NewService(instanceDir, pluginDir string, opts Options) (*Service, error) {
+	if instanceDir == "" {
+		return nil, errors.New("storage.Service 需要有效的 instance 目录")
+	}
+	if opts.MinFreeMB < 0 {
+		return nil, fmt.Errorf("min_free_mb 不能为负数: %d", opts.MinFreeMB)
+	}
+	if opts.MinFreeMB == 0 {
+		opts.MinFreeMB = defaultMinFreeMB
+	}
+	return &Service{
+		instanceDir: instanceDir,
+		pluginDir:   pluginDir,
+		opts:        opts,
+		now:         time.Now,
+		diskSpace:   diskSpace,
+	}, nil
+}
+
+// Wrap 返回一个在可用空间不足时拒绝写操作的 DataSource 装饰器，可作为插件管理器的装饰器使用。
+// 库文件维护与丢弃压缩暂存文件不受限制，它们可以释放空间
+func (s *Service) Wrap(bizName string, inner port.DataSource) port.DataSource {
+	return &decorator{DataSource: inner, storage: s}
+}
+
+type decorator struct {
+	port.DataSource
+	storage *Service
+}
+
+// Unwrap 返回被装饰的原始 DataSource
+func (d *decorator) Unwrap() port.DataSource {
+	return d.DataSource
+}
+
+// Mutate 在可用空间充足时透传写操作
+func (d *decorator) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if err := d.storage.CheckFreeSpace(); err != nil {
+		return nil, err
+	}
+	return d.DataSource.Mutate(ctx, req)
+}
+
+// AdminMutate 与 Mutate 一样在空间不足时拒绝管理操作，释放空间的操作除外
+func (d *decorator) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	if !releasesSpace(req) {
+		if err := d.storage.CheckFreeSpace(); err != nil {
+			return nil, err
+		}
+	}
+	return port.AdminMutate(ctx, d.DataSource, req)
+}
+
+func releasesSpace(req port.MutateRequest) bool {
+	return req.Operation == port.MutateOpMaintenance || (req.Operation == port.MutateOpCompact && req.Payload["step"] == "abort")
+}
+
+// CheckFreeSpace 在 instance 目录所在卷的可用空间低于下限时返回 port.ErrInsufficientStorage。
+// 无法读取可用空间时记录日志并放行
+func (s *Service) CheckFreeSpace() error {
+	_, free, err := s.space()
+	if err != nil {
+		slog.Warn("[Storage] 读取磁盘可用空间失败", "path", s.instanceDir, "error", err)
+		return nil
+	}
+	if minFree := s.minFree(); free < minFree {
+		return fmt.Errorf("%w: 可用 %d MB，低于配置的下限 %d MB", port.ErrInsufficientStorage, free>>20, minFree>>20)
+	}
+	return nil
+}
+
+func (s *Service) minFree() uint64 {
+	return uint64(s.opts.MinFreeMB) << 20
+}
+
+// space 返回 instance 目录所在卷的总空间与可用空间，freeSpaceTTL 内复用上一次的结果
+func (s *Service) space() (uint64, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.now(); now.Sub(s.checkedAt) >= freeSpaceTTL {
+		total, free, err := s.diskSpace(s.instanceDir)
+		if err != nil {
+			return 0, 0, err
+		}
+		s.total, s.free, s.checkedAt = total, free, now
+	}
+	return s.total, s.free, nil
+}
+
+// Report 统计各业务组库文件与各目录的空间占用，以及 instance 目录所在卷的空间
+func (s *Service) Report(ctx context.Context) (*domain.StorageReport, error) {
+	report := &domain.StorageReport{
+		Bizs:        make([]domain.StorageBiz, 0),
+		Backups:     make([]domain.StorageDirectory, 0, len(s.opts.BackupDirectories)),
+		GeneratedAt: s.now().UTC(),
+	}
+	total, free, err := s.space()
+	if err != nil {
+		return nil, fmt.Errorf("读取磁盘可用空间失败: %w", err)
+	}
+	report.Disk = domain.DiskSpace{Path: s.instanceDir, TotalBytes: total, FreeBytes: free, MinFree: s.minFree(), Low: free < s.minFree()}
+
+	if report.Bizs, err = s.bizUsage(ctx); err != nil {
+		return nil, err
+	}
+	if report.Instance, err = directoryUsage(ctx, s.instanceDir); err != nil {
+		return nil, err
+	}
+	if s.pluginDir != "" {
+		if report.Plugins, err = directoryUsage(ctx, s.pluginDir); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range s.opts.BackupDirectories {
+		usage, err := directoryUsage(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		report.Backups = append(report.Backups, usage)
+	}
+	return report, nil
+}
+
+// bizUsage 统计 instance 目录下每个业务组的库文件，包括 WAL 与共享内存文件
+func (s *Service) bizUsage(ctx context.Context) ([]domain.StorageBiz, error) {
+	files, err := filepath.Glob(filepath.Join(s.instanceDir, "*", "*.db"))
+	if err != nil {
+		return nil, fmt.Errorf("扫描库文件失败: %w", err)
+	}
+	sort.Strings(files)
+	byBiz := make(map[string]*domain.StorageBiz)
+	names := make([]string, 0)
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		file := domain.StorageFile{FileName: filepath.Base(path), Bytes: info.Size()}
+		if wal, err := os.Stat(path + "-wal"); err == nil {
+			file.WALBytes = wal.Size()
+		}
+		if shm, err := os.Stat(path + "-shm"); err == nil {
+			file.SHMBytes = shm.Size()
+		}
+		bizName := filepath.Base(filepath.Dir(path))
+		biz, ok := byBiz[bizName]
+		if !ok {
+			biz = &domain.StorageBiz{BizName: bizName, Files: make([]domain.StorageFile, 0)}
+			byBiz[bizName] = biz
+			names = append(names, bizName)
+		}
+		biz.Files = append(biz.Files, file)
+		biz.Bytes += file.Bytes + file.WALBytes + file.SHMBytes
+	}
+	sort.Strings(names)
+	list := make([]domain.StorageBiz, 0, len(names))
+	for _, name := range names {
+		list = append(list, *byBiz[name])
+	}
+	return list, nil
+}
+
+// directoryUsage 统计目录及其子目录中文件的总大小，目录不存在时返回 Exists 为 false 的结果
+func directoryUsage(ctx context.Context, dir string) (domain.StorageDirectory, error) {
+	usage := domain.StorageDirectory{Path: dir}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return usage, nil
+	}
+	usage.Exists = true
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 统计期间被删除的文件直接跳过
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		usage.Bytes += info.Size()
+		usage.Files++
+		return nil
+	})
+	if err != nil {
+		return usage, fmt.Errorf("统计目录 '%s' 的空间占用失败: %w", dir, err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,99 @@
+// file: internal/service/storage/storage_service_test.go
+package storage
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingDataSource struct {
+	port.DataSource
+	mutations []string
+}
+
+func (r *recordingDataSource) Mutate(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	r.mutations = append(r.mutations, req.Operation)
+	return &port.MutateResult{Data: map[string]interface{}{}}, nil
+}
+
+func (r *recordingDataSource) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	return r.Mutate(ctx, req)
+}
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+}
+
+func TestReport(t *testing.T) {
+	instanceDir, pluginDir, backupDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(instanceDir, "auth.db"), 100)
+	writeFile(t, filepath.Join(instanceDir, "library", "a.db"), 1000)
+	writeFile(t, filepath.Join(instanceDir, "library", "a.db-wal"), 200)
+	writeFile(t, filepath.Join(instanceDir, "library", "b.db"), 300)
+	writeFile(t, filepath.Join(instanceDir, "archive", "main.db"), 50)
+	writeFile(t, filepath.Join(pluginDir, "sqlite", "1.0.0", "plugin"), 700)
+	writeFile(t, filepath.Join(backupDir, "2026-05-01", "library.zip"), 400)
+
+	svc, err := NewService(instanceDir, pluginDir, Options{MinFreeMB: 10, BackupDirectories: []string{backupDir, filepath.Join(backupDir, "missing")}})
+	require.NoError(t, err)
+	svc.diskSpace = func(string) (uint64, uint64, error) { return 100 << 20, 50 << 20, nil }
+
+	report, err := svc.Report(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50<<20), report.Disk.FreeBytes)
+	assert.Equal(t, uint64(10<<20), report.Disk.MinFree)
+	assert.False(t, report.Disk.Low)
+
+	require.Len(t, report.Bizs, 2)
+	assert.Equal(t, "archive", report.Bizs[0].BizName)
+	assert.Equal(t, "library", report.Bizs[1].BizName)
+	assert.Equal(t, int64(1500), report.Bizs[1].Bytes)
+	require.Len(t, report.Bizs[1].Files, 2)
+	assert.Equal(t, int64(200), report.Bizs[1].Files[0].WALBytes)
+
+	assert.Equal(t, int64(1650), report.Instance.Bytes)
+	assert.Equal(t, 5, report.Instance.Files)
+	assert.Equal(t, int64(700), report.Plugins.Bytes)
+	require.Len(t, report.Backups, 2)
+	assert.Equal(t, int64(400), report.Backups[0].Bytes)
+	assert.False(t, report.Backups[1].Exists)
+}
+
+func TestLowDiskBlocksMutations(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	free := uint64(5 << 20)
+	svc, err := NewService(t.TempDir(), "", Options{MinFreeMB: 10})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return now }
+	svc.diskSpace = func(string) (uint64, uint64, error) { return 100 << 20, free, nil }
+
+	inner := &recordingDataSource{}
+	ds := svc.Wrap("library", inner)
+	_, err = ds.Mutate(context.Background(), port.MutateRequest{BizName: "library", Operation: "create"})
+	assert.ErrorIs(t, err, port.ErrInsufficientStorage)
+	assert.ErrorIs(t, svc.CheckFreeSpace(), port.ErrInsufficientStorage)
+
+	// 维护可以释放空间，不受限制
+	_, err = port.AdminMutate(context.Background(), ds, port.MutateRequest{BizName: "library", Operation: port.MutateOpCompact})
+	assert.ErrorIs(t, err, port.ErrInsufficientStorage)
+	_, err = port.AdminMutate(context.Background(), ds, port.MutateRequest{BizName: "library", Operation: port.MutateOpMaintenance})
+	require.NoError(t, err)
+
+	// 可用空间在短时间内复用，过期后重新读取
+	free = 20 << 20
+	assert.Error(t, svc.CheckFreeSpace())
+	now = now.Add(freeSpaceTTL)
+	require.NoError(t, svc.CheckFreeSpace())
+	_, err = ds.Mutate(context.Background(), port.MutateRequest{BizName: "library", Operation: "create"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{port.MutateOpMaintenance, "create"}, inner.mutations)
+}
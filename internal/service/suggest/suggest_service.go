@@ -0,0 +1,492 @@
+// Package suggest file: internal/service/suggest/suggest_service.go
+package suggest
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// JobKindRebuild 是拼写建议词表重建任务的类型，任务目标为表名
+	JobKindRebuild = "suggest_vocabulary"
+
+	defaultMaxSuggestions   = 3
+	defaultMaxDistance      = 2
+	defaultMaxTermsPerField = 100000
+	vocabularyPageSize      = 5000
+)
+
+// Options 定义拼写建议的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ResultThreshold 是触发建议的结果数上限：检索结果不超过该值时才计算建议，默认为 0 (仅无结果时)
+	ResultThreshold int `mapstructure:"result_threshold"`
+	// MaxSuggestions 是每个过滤条件最多返回的建议数，默认为 3
+	MaxSuggestions int `mapstructure:"max_suggestions"`
+	// MaxDistance 是单个词允许的最大编辑距离，默认为 2；短词的上限按长度进一步收紧
+	MaxDistance int `mapstructure:"max_distance"`
+	// MaxTermsPerField 是每个字段词表保留的词数上限，超出时只保留出现次数最多的词，默认为 100000
+	MaxTermsPerField int `mapstructure:"max_terms_per_field"`
+}
+
+// Service 为每个可搜索的文本字段建立词表，并在检索结果过少时给出拼写纠正建议 ("您是不是要找")。
+// 词表由管理员以后台任务的形式按表重建，持久化后在内存中以三元组索引，重建完成后立即生效。
+type Service struct {
+	db       *sql.DB
+	config   port.QueryAdminConfigService
+	registry map[string]port.DataSource
+	jobs     *jobs.Service
+	opts     Options
+
+	mu sync.RWMutex
+	// vocabs 以 "业务组\x00表\x00字段" 索引已加载的词表
+	vocabs map[string]*vocabulary
+}
+
+// NewService 创建一个新的拼写建议服务实例
+func NewService(db *sql.DB, config port.QueryAdminConfigService, registry map[string]port.DataSource, jobService *jobs.Service, opts Options) (*Service, error) {
+	if db == nil || config == nil || jobService == nil {
+		return nil, errors.New("suggest.Service 需要有效的数据库连接、配置服务与后台任务服务")
+	}
+	if opts.ResultThreshold < 0 || opts.MaxSuggestions < 0 || opts.MaxDistance < 0 || opts.MaxTermsPerField < 0 {
+		return nil, errors.New("拼写建议的配置项不能为负数")
+	}
+	if opts.MaxSuggestions == 0 {
+		opts.MaxSuggestions = defaultMaxSuggestions
+	}
+	if opts.MaxDistance == 0 {
+		opts.MaxDistance = defaultMaxDistance
+	}
+	if opts.MaxTermsPerField == 0 {
+		opts.MaxTermsPerField = defaultMaxTermsPerField
+	}
+	return &Service{
+		db:       db,
+		config:   config,
+		registry: registry,
+		jobs:     jobService,
+		opts:     opts,
+		vocabs:   make(map[string]*vocabulary),
+	}, nil
+}
+
+func vocabKey(bizName, tableName, fieldName string) string {
+	return bizName + "\x00" + tableName + "\x00" + fieldName
+}
+
+// LoadAll 在启动时加载所有已建立的词表
+func (s *Service) LoadAll(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, table_name, field_name, term, frequency FROM suggestion_vocabulary`)
+	if err != nil {
+		return fmt.Errorf("查询拼写建议词表失败: %w", err)
+	}
+	defer rows.Close()
+	counts := make(map[string]map[string]int64)
+	for rows.Next() {
+		var biz, table, field, term string
+		var freq int64
+		if err := rows.Scan(&biz, &table, &field, &term, &freq); err != nil {
+			return fmt.Errorf("扫描拼写建议词表失败: %w", err)
+		}
+		key := vocabKey(biz, table, field)
+		if counts[key] == nil {
+			counts[key] = make(map[string]int64)
+		}
+		counts[key][term] = freq
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	vocabs := make(map[string]*vocabulary, len(counts))
+	for key, c := range counts {
+		vocabs[key] = newVocabulary(c)
+	}
+	s.mu.Lock()
+	s.vocabs = vocabs
+	s.mu.Unlock()
+	slog.Info("[Suggest] 拼写建议词表已加载", "fields", len(vocabs))
+	return nil
+}
+
+// Suggest 在检索结果不超过阈值时，为过滤条件中疑似拼错的词给出纠正建议。
+// query 为请求中的查询对象，data 为数据源返回的结果。只处理不带运算符、非拼音、未取反的字符串过滤值，
+// 字段尚未建立词表时不给出建议。没有建议时返回 nil。
+func (s *Service) Suggest(bizName string, query, data map[string]interface{}) []domain.SearchSuggestion {
+	if total, ok := resultTotal(data); !ok || total > int64(s.opts.ResultThreshold) {
+		return nil
+	}
+	tableName, _ := query["table"].(string)
+	filters, _ := query["filters"].([]interface{})
+	if tableName == "" || len(filters) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []domain.SearchSuggestion
+	seen := make(map[string]bool)
+	for _, f := range filters {
+		filterMap, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if op, _ := filterMap["op"].(string); op != "" {
+			continue
+		}
+		if pinyin, _ := filterMap["pinyin"].(bool); pinyin {
+			continue
+		}
+		if negate, _ := filterMap[port.FilterNegateKey].(bool); negate {
+			continue
+		}
+		field, _ := filterMap["field"].(string)
+		value, _ := filterMap["value"].(string)
+		if field == "" || strings.TrimSpace(value) == "" || seen[field+"\x00"+value] {
+			continue
+		}
+		seen[field+"\x00"+value] = true
+		vocab := s.vocabs[vocabKey(bizName, tableName, field)]
+		if vocab == nil {
+			continue
+		}
+		for _, suggestion := range s.suggestValue(vocab, value) {
+			suggestion.Field = field
+			out = append(out, suggestion)
+		}
+	}
+	return out
+}
+
+// suggestValue 对值中的每个词求纠正候选，并组合为至多 MaxSuggestions 个完整的建议值。
+// 第一个建议为各词的最佳候选，其后依次把某个词替换为它的次优候选。
+func (s *Service) suggestValue(vocab *vocabulary, value string) []domain.SearchSuggestion {
+	tokens := tokenize(value)
+	options := make([][]correction, len(tokens))
+	corrected := false
+	for i, tok := range tokens {
+		maxDistance := min(s.opts.MaxDistance, max(1, utf8.RuneCountInString(tok.text)/3))
+		options[i] = vocab.corrections(tok.text, maxDistance, s.opts.MaxSuggestions)
+		corrected = corrected || len(options[i]) > 0
+	}
+	if !corrected {
+		return nil
+	}
+
+	build := func(choice []int) domain.SearchSuggestion {
+		var b strings.Builder
+		last, distance := 0, 0
+		for i, tok := range tokens {
+			if len(options[i]) == 0 {
+				continue
+			}
+			c := options[i][choice[i]]
+			b.WriteString(value[last:tok.start])
+			b.WriteString(matchCase(value[tok.start:tok.end], c.term))
+			last = tok.end
+			distance += c.distance
+		}
+		b.WriteString(value[last:])
+		return domain.SearchSuggestion{Value: value, Suggestion: b.String(), Distance: distance}
+	}
+
+	best := make([]int, len(tokens))
+	out := []domain.SearchSuggestion{build(best)}
+	for i := range tokens {
+		for alt := 1; alt < len(options[i]) && len(out) < s.opts.MaxSuggestions; alt++ {
+			choice := make([]int, len(tokens))
+			choice[i] = alt
+			out = append(out, build(choice))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Distance < out[j].Distance })
+	return out
+}
+
+// matchCase 让纠正后的词沿用原词的大小写形式 (全大写或首字母大写)
+func matchCase(original, term string) string {
+	switch {
+	case original == strings.ToUpper(original) && original != strings.ToLower(original):
+		return strings.ToUpper(term)
+	case startsUpper(original):
+		r, size := utf8.DecodeRuneInString(term)
+		return string(unicode.ToUpper(r)) + term[size:]
+	default:
+		return term
+	}
+}
+
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
+
+// resultTotal 取出结果中的总数，没有 total 时以 items 的条数代替
+func resultTotal(data map[string]interface{}) (int64, bool) {
+	switch total := data["total"].(type) {
+	case int:
+		return int64(total), true
+	case int64:
+		return total, true
+	case float64: // 经 gRPC (structpb) 传输的数字
+		return int64(total), true
+	}
+	if items, ok := data["items"].([]interface{}); ok {
+		return int64(len(items)), true
+	}
+	return 0, false
+}
+
+// Rebuild 启动后台任务，为表中每个可搜索的文本字段重新建立词表。
+// 已有重建任务在运行时返回 jobs.ErrJobConflict。
+func (s *Service) Rebuild(ctx context.Context, bizName, tableName string, userID int64) (*domain.BackgroundJob, error) {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if _, exists := bizConfig.Tables[tableName]; !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	dataSource, exists := s.registry[bizName]
+	if !exists {
+		return nil, port.ErrBizNotFound
+	}
+	return s.jobs.Start(ctx, JobKindRebuild, bizName, tableName, userID, func(ctx context.Context, progress *jobs.Progress) error {
+		return s.run(ctx, dataSource, bizName, tableName, progress)
+	})
+}
+
+// run 是重建任务的执行体，逐个字段分页读取取值、切词并替换词表。
+// 任务开始时已不可搜索的字段的旧词表会被删除。
+func (s *Service) run(ctx context.Context, dataSource port.DataSource, bizName, tableName string, progress *jobs.Progress) error {
+	bizConfig, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return err
+	}
+	if bizConfig == nil {
+		return port.ErrBizNotFound
+	}
+	tableConfig, exists := bizConfig.Tables[tableName]
+	if !exists {
+		return port.ErrTableNotFoundInBiz
+	}
+	var fields []string
+	for name, setting := range tableConfig.Fields {
+		if setting.IsSearchable && !isNumericType(setting.DataType) {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	progress.Reset(int64(len(fields)))
+
+	for _, field := range fields {
+		progress.SetMessage(fmt.Sprintf("正在建立字段 '%s' 的词表", field))
+		counts, err := s.collectTerms(ctx, dataSource, bizName, tableName, field)
+		if err != nil {
+			return err
+		}
+		if err := s.replace(ctx, bizName, tableName, field, counts); err != nil {
+			return err
+		}
+		progress.Add(1)
+	}
+	if err := s.removeStale(ctx, bizName, tableName, fields); err != nil {
+		return err
+	}
+	progress.SetMessage(fmt.Sprintf("已为 %d 个字段建立词表", len(fields)))
+	return nil
+}
+
+// collectTerms 分页读取字段的全部取值并切词，按出现次数累加，只保留出现次数最多的 MaxTermsPerField 个词
+func (s *Service) collectTerms(ctx context.Context, dataSource port.DataSource, bizName, tableName, field string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		query := map[string]interface{}{
+			port.QueryModeKey: port.QueryModeVocabulary,
+			"table":           tableName,
+			"field":           field,
+			"limit":           float64(vocabularyPageSize),
+		}
+		if after != "" {
+			query["after"] = after
+		}
+		res, err := dataSource.Query(ctx, port.QueryRequest{BizName: bizName, Query: query})
+		if err != nil {
+			return nil, err
+		}
+		values, _ := res.Data["values"].([]interface{})
+		for _, raw := range values {
+			entry, _ := raw.(map[string]interface{})
+			value, _ := entry["value"].(string)
+			n := toInt64(entry["count"])
+			for _, tok := range tokenize(value) {
+				counts[tok.text] += n
+			}
+		}
+		// 截断前先累加完一页，避免页内同一个词被部分计数
+		if len(counts) > 2*s.opts.MaxTermsPerField {
+			counts = topTerms(counts, s.opts.MaxTermsPerField)
+		}
+		done, _ := res.Data["done"].(bool)
+		next, _ := res.Data["next_after"].(string)
+		if done || next == "" {
+			break
+		}
+		after = next
+	}
+	return topTerms(counts, s.opts.MaxTermsPerField), nil
+}
+
+// topTerms 返回出现次数最多的 limit 个词
+func topTerms(counts map[string]int64, limit int) map[string]int64 {
+	if len(counts) <= limit {
+		return counts
+	}
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if counts[terms[i]] != counts[terms[j]] {
+			return counts[terms[i]] > counts[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	out := make(map[string]int64, limit)
+	for _, term := range terms[:limit] {
+		out[term] = counts[term]
+	}
+	return out
+}
+
+// replace 在一个事务中替换字段的词表，提交后替换内存中的副本
+func (s *Service) replace(ctx context.Context, bizName, tableName, field string, counts map[string]int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启拼写建议词表事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM suggestion_vocabulary WHERE biz_name = ? AND table_name = ? AND field_name = ?`,
+		bizName, tableName, field); err != nil {
+		return fmt.Errorf("清除字段 '%s' 的旧词表失败: %w", field, err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO suggestion_vocabulary (biz_name, table_name, field_name, term, frequency) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备写入词表失败: %w", err)
+	}
+	defer stmt.Close()
+	for term, freq := range counts {
+		if _, err := stmt.ExecContext(ctx, bizName, tableName, field, term, freq); err != nil {
+			return fmt.Errorf("写入字段 '%s' 的词表失败: %w", field, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO suggestion_vocabulary_fields (biz_name, table_name, field_name, term_count, built_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(biz_name, table_name, field_name) DO UPDATE SET term_count = excluded.term_count, built_at = excluded.built_at`,
+		bizName, tableName, field, len(counts), time.Now().UTC()); err != nil {
+		return fmt.Errorf("登记字段 '%s' 的词表失败: %w", field, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交拼写建议词表失败: %w", err)
+	}
+
+	vocab := newVocabulary(counts)
+	s.mu.Lock()
+	s.vocabs[vocabKey(bizName, tableName, field)] = vocab
+	s.mu.Unlock()
+	return nil
+}
+
+// removeStale 删除表中不在 keep 之列的字段的词表
+func (s *Service) removeStale(ctx context.Context, bizName, tableName string, keep []string) error {
+	existing, err := s.ListVocabularies(ctx, bizName)
+	if err != nil {
+		return err
+	}
+	for _, v := range existing {
+		if v.TableName != tableName || slices.Contains(keep, v.FieldName) {
+			continue
+		}
+		for _, table := range []string{"suggestion_vocabulary", "suggestion_vocabulary_fields"} {
+			if _, err := s.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE biz_name = ? AND table_name = ? AND field_name = ?`,
+				bizName, tableName, v.FieldName); err != nil {
+				return fmt.Errorf("删除字段 '%s' 的词表失败: %w", v.FieldName, err)
+			}
+		}
+		s.mu.Lock()
+		delete(s.vocabs, vocabKey(bizName, tableName, v.FieldName))
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// ListVocabularies 返回业务组下已建立的词表，bizName 为空时返回全部
+func (s *Service) ListVocabularies(ctx context.Context, bizName string) ([]domain.SuggestionVocabulary, error) {
+	query := `SELECT biz_name, table_name, field_name, term_count, built_at FROM suggestion_vocabulary_fields`
+	var args []interface{}
+	if bizName != "" {
+		query += ` WHERE biz_name = ?`
+		args = append(args, bizName)
+	}
+	query += ` ORDER BY biz_name, table_name, field_name`
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询拼写建议词表失败: %w", err)
+	}
+	defer rows.Close()
+	out := make([]domain.SuggestionVocabulary, 0)
+	for rows.Next() {
+		var v domain.SuggestionVocabulary
+		if err := rows.Scan(&v.BizName, &v.TableName, &v.FieldName, &v.TermCount, &v.BuiltAt); err != nil {
+			return nil, fmt.Errorf("扫描拼写建议词表失败: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// isNumericType 判断字段的数据类型是否为数值，数值字段不建立词表
+func isNumericType(dataType string) bool {
+	t := strings.ToUpper(dataType)
+	for _, marker := range []string{"INT", "REAL", "FLOA", "DOUB", "NUM", "DEC"} {
+		if strings.Contains(t, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt64 兼容进程内数据源返回的整数与经 gRPC (structpb) 传输后的 float64
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
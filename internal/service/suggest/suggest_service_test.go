@@ -0,0 +1,155 @@
+// file: internal/service/suggest/suggest_service_test.go
+package suggest
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/sqlite"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	var texts []string
+	for _, tok := range tokenize("The Old-Man, 1952 鲁迅全集 x") {
+		texts = append(texts, tok.text)
+	}
+	assert.Equal(t, []string{"the", "old", "man", "鲁迅全集"}, texts, "纯数字与单个字符不作为词")
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein([]rune("sea"), []rune("sea"), 2))
+	assert.Equal(t, 1, levenshtein([]rune("tranquility"), []rune("tranquillity"), 2))
+	assert.Equal(t, 2, levenshtein([]rune("鲁迅全集"), []rune("鲁讯全"), 2))
+	assert.Equal(t, 3, levenshtein([]rune("abc"), []rune("xyzw"), 2), "超出上限时返回上限加一")
+}
+
+func TestVocabularyCorrections(t *testing.T) {
+	v := newVocabulary(map[string]int64{"society": 3, "variety": 1, "sea": 5, "tranquility": 1})
+	assert.Nil(t, v.corrections("sea", 2, 3), "词表中已有的词不需要纠正")
+
+	got := v.corrections("socety", 2, 3)
+	require.NotEmpty(t, got)
+	assert.Equal(t, "society", got[0].term)
+	assert.Equal(t, 1, got[0].distance)
+	assert.Empty(t, v.corrections("zzzzzz", 2, 3))
+}
+
+type testEnv struct {
+	svc   *Service
+	jobs  *jobs.Service
+	sysDB *sql.DB
+}
+
+func newTestEnv(t *testing.T, opts Options) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	sysDB := fixture.NewSystemDB(t)
+	_, err := sysDB.Exec(`INSERT INTO biz_overall_settings (biz_name, is_publicly_searchable, default_query_table) VALUES ('library', TRUE, 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_searchable_tables (biz_name, table_name) VALUES ('library', 'books')`)
+	require.NoError(t, err)
+	_, err = sysDB.Exec(`INSERT INTO biz_table_field_settings (biz_name, table_name, field_name, is_searchable, is_returnable, data_type) VALUES
+		('library', 'books', 'title', TRUE, TRUE, 'TEXT'), ('library', 'books', 'note', FALSE, TRUE, 'TEXT'), ('library', 'books', 'year', TRUE, TRUE, 'INTEGER')`)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "library"), 0o755))
+	libDB, err := sql.Open("sqlite", filepath.Join(root, "library", "main.db"))
+	require.NoError(t, err)
+	_, err = libDB.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, note TEXT, year INTEGER);
+		INSERT INTO books (title, note, year) VALUES
+			('The Old Man and the Sea', 'a', 1952), ('Sea of Tranquility', 'b', 2022), ('Café Society', 'c', 1990), ('Society of Mind', 'd', 1986);`)
+	require.NoError(t, err)
+	require.NoError(t, libDB.Close())
+
+	config, err := admin_config.NewAdminConfigServiceImpl(sysDB, 100, time.Minute)
+	require.NoError(t, err)
+	manager := sqlite.NewManager(config)
+	require.NoError(t, manager.InitForBiz(ctx, root, "library"))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	jobService, err := jobs.NewService(sysDB)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	svc, err := NewService(sysDB, config, map[string]port.DataSource{"library": manager}, jobService, opts)
+	require.NoError(t, err)
+	return &testEnv{svc: svc, jobs: jobService, sysDB: sysDB}
+}
+
+func (e *testEnv) rebuild(t *testing.T) {
+	t.Helper()
+	job, err := e.svc.Rebuild(context.Background(), "library", "books", 1)
+	require.NoError(t, err)
+	var current *domain.BackgroundJob
+	require.Eventually(t, func() bool {
+		current, err = e.jobs.Get(context.Background(), job.ID)
+		require.NoError(t, err)
+		return current.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, domain.JobStatusSucceeded, current.Status, current.Error)
+}
+
+func titleQuery(value string) map[string]interface{} {
+	return map[string]interface{}{
+		"table":   "books",
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": value, "fuzzy": true}},
+	}
+}
+
+func TestRebuildAndSuggest(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, Options{Enabled: true})
+	empty := map[string]interface{}{"items": []interface{}{}, "total": int64(0)}
+
+	assert.Nil(t, env.svc.Suggest("library", titleQuery("socety"), empty), "尚未建立词表时没有建议")
+
+	env.rebuild(t)
+	vocabs, err := env.svc.ListVocabularies(ctx, "library")
+	require.NoError(t, err)
+	require.Len(t, vocabs, 1, "只为可搜索的文本字段建立词表")
+	assert.Equal(t, "title", vocabs[0].FieldName)
+	assert.EqualValues(t, 10, vocabs[0].TermCount)
+
+	got := env.svc.Suggest("library", titleQuery("Socety of Mind"), empty)
+	require.NotEmpty(t, got)
+	assert.Equal(t, domain.SearchSuggestion{Field: "title", Value: "Socety of Mind", Suggestion: "Society of Mind", Distance: 1}, got[0])
+
+	got = env.svc.Suggest("library", titleQuery("OLD MAM"), empty)
+	require.NotEmpty(t, got)
+	assert.Equal(t, "OLD MAN", got[0].Suggestion, "沿用原词的大小写")
+
+	assert.Nil(t, env.svc.Suggest("library", titleQuery("socety"), map[string]interface{}{"total": float64(2)}), "结果数超过阈值时不给出建议")
+	assert.Nil(t, env.svc.Suggest("library", titleQuery("society"), empty), "拼写正确时没有建议")
+	pinyin := map[string]interface{}{
+		"table":   "books",
+		"filters": []interface{}{map[string]interface{}{"field": "title", "value": "socety", "pinyin": true}},
+	}
+	assert.Nil(t, env.svc.Suggest("library", pinyin, empty))
+
+	// 重启后从数据库加载词表
+	reloaded, err := NewService(env.sysDB, env.svc.config, env.svc.registry, env.jobs, Options{Enabled: true, ResultThreshold: 2})
+	require.NoError(t, err)
+	require.NoError(t, reloaded.LoadAll(ctx))
+	got = reloaded.Suggest("library", titleQuery("tranquilty"), map[string]interface{}{"total": float64(2)})
+	require.Len(t, got, 1)
+	assert.Equal(t, "tranquility", got[0].Suggestion)
+}
+
+func TestRebuildUnknownTable(t *testing.T) {
+	env := newTestEnv(t, Options{Enabled: true})
+	_, err := env.svc.Rebuild(context.Background(), "library", "missing", 1)
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+	_, err = env.svc.Rebuild(context.Background(), "archive", "books", 1)
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
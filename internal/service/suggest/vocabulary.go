@@ -0,0 +1,202 @@
+// Package suggest file: internal/service/suggest/vocabulary.go
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxCandidates 是每个词按三元组重合度初筛后参与编辑距离计算的候选数上限
+	maxCandidates = 200
+	// maxTermRunes 是词表中词的最大长度，更长的多为编号或粘连文本
+	maxTermRunes = 40
+)
+
+// token 是从文本中切出的一个词，start/end 为其在原文中的字节区间
+type token struct {
+	text       string // 小写形式
+	start, end int
+}
+
+// tokenize 把文本切分为词：连续的拉丁字母与数字为一个词，连续的汉字为一个词，其它字符作为分隔符。
+// 纯数字、单个字符与过长的片段不作为词。
+func tokenize(s string) []token {
+	var tokens []token
+	start, kind := -1, 0
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		text := strings.ToLower(s[start:end])
+		n := len([]rune(text))
+		if n >= 2 && n <= maxTermRunes && strings.TrimFunc(text, unicode.IsDigit) != "" {
+			tokens = append(tokens, token{text: text, start: start, end: end})
+		}
+		start = -1
+	}
+	for i, r := range s {
+		k := runeKind(r)
+		if k != kind {
+			flush(i)
+			if k != 0 {
+				start = i
+			}
+			kind = k
+		}
+	}
+	flush(len(s))
+	return tokens
+}
+
+// runeKind 返回字符所属的类别：0 为分隔符，1 为汉字，2 为其它字母或数字
+func runeKind(r rune) int {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return 1
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 2
+	default:
+		return 0
+	}
+}
+
+// vocabulary 是一个字段的词表及其三元组索引
+type vocabulary struct {
+	terms  []string
+	freq   []int64
+	byTerm map[string]int32
+	// grams 以三元组 (首尾补空格) 索引包含它的词
+	grams map[string][]int32
+}
+
+func newVocabulary(counts map[string]int64) *vocabulary {
+	v := &vocabulary{
+		terms:  make([]string, 0, len(counts)),
+		freq:   make([]int64, 0, len(counts)),
+		byTerm: make(map[string]int32, len(counts)),
+		grams:  make(map[string][]int32),
+	}
+	for term := range counts {
+		v.terms = append(v.terms, term)
+	}
+	sort.Strings(v.terms)
+	for i, term := range v.terms {
+		id := int32(i)
+		v.freq = append(v.freq, counts[term])
+		v.byTerm[term] = id
+		for _, g := range trigrams(term) {
+			v.grams[g] = append(v.grams[g], id)
+		}
+	}
+	return v
+}
+
+func trigrams(term string) []string {
+	runes := []rune(" " + term + " ")
+	seen := make(map[string]bool, len(runes))
+	grams := make([]string, 0, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		g := string(runes[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
+
+// correction 是一个词的纠正候选
+type correction struct {
+	term     string
+	distance int
+	freq     int64
+}
+
+// corrections 返回与 word 的编辑距离不超过 maxDistance 的词，按距离升序、词频降序排列，最多 limit 个。
+// word 本身在词表中时返回 nil。
+func (v *vocabulary) corrections(word string, maxDistance, limit int) []correction {
+	if _, known := v.byTerm[word]; known || maxDistance <= 0 {
+		return nil
+	}
+	wordRunes := []rune(word)
+
+	overlap := make(map[int32]int)
+	for _, g := range trigrams(word) {
+		for _, id := range v.grams[g] {
+			overlap[id]++
+		}
+	}
+	ids := make([]int32, 0, len(overlap))
+	for id := range overlap {
+		if abs(len([]rune(v.terms[id]))-len(wordRunes)) <= maxDistance {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if overlap[ids[i]] != overlap[ids[j]] {
+			return overlap[ids[i]] > overlap[ids[j]]
+		}
+		return v.freq[ids[i]] > v.freq[ids[j]]
+	})
+	if len(ids) > maxCandidates {
+		ids = ids[:maxCandidates]
+	}
+
+	var out []correction
+	for _, id := range ids {
+		if d := levenshtein(wordRunes, []rune(v.terms[id]), maxDistance); d <= maxDistance {
+			out = append(out, correction{term: v.terms[id], distance: d, freq: v.freq[id]})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].distance != out[j].distance {
+			return out[i].distance < out[j].distance
+		}
+		if out[i].freq != out[j].freq {
+			return out[i].freq > out[j].freq
+		}
+		return out[i].term < out[j].term
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// levenshtein 计算两个字符序列的编辑距离；距离必然超过 bound 时提前返回 bound+1
+func levenshtein(a, b []rune, bound int) int {
+	if abs(len(a)-len(b)) > bound {
+		return bound + 1
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > bound {
+			return bound + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,243 @@
+// Package telemetry file: internal/service/telemetry/telemetry_service.go
+package telemetry
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/core/domain"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	schemaVersion        = 1
+	defaultIntervalHours = 24
+	installationIDKey    = "telemetry_installation_id"
+)
+
+// volumeBuckets 是请求数区间的上界 (不含) 与名称，超过最后一个上界时为 "1000000+"
+var volumeBuckets = []struct {
+	below int64
+	name  string
+}{
+	{1, "0"},
+	{100, "1-99"},
+	{1000, "100-999"},
+	{10000, "1000-9999"},
+	{100000, "10000-99999"},
+	{1000000, "100000-999999"},
+}
+
+// Options 定义匿名使用统计的配置，默认关闭，需要管理员显式开启
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint 是接收统计的地址，开启时必须配置
+	Endpoint string `mapstructure:"endpoint"`
+	// IntervalHours 是发送间隔，默认为 24 小时
+	IntervalHours int `mapstructure:"interval_hours"`
+}
+
+// Service 在管理员开启后定期把匿名的聚合统计 (版本、插件数、业务组数、请求量区间) POST 到配置的地址。
+// 未开启时不会发送任何内容，但仍可通过 Status 预览将要发送的内容
+type Service struct {
+	db       *sql.DB
+	version  string
+	opts     Options
+	client   *http.Client
+	now      func() time.Time
+	counters func() (requests, errors int64)
+
+	mu           sync.Mutex
+	baseline     int64 // 上次成功发送时的请求计数
+	lastSentAt   *time.Time
+	lastErr      string
+	installation string
+}
+
+// NewService 创建一个新的使用统计服务实例，version 是当前网关的版本号
+func NewService(db *sql.DB, version string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("telemetry.Service 需要一个有效的数据库连接")
+	}
+	if opts.IntervalHours < 0 {
+		return nil, fmt.Errorf("interval_hours 不能为负数: %d", opts.IntervalHours)
+	}
+	if opts.Enabled && opts.Endpoint == "" {
+		return nil, errors.New("开启使用统计时必须配置 endpoint")
+	}
+	if opts.IntervalHours == 0 {
+		opts.IntervalHours = defaultIntervalHours
+	}
+	return &Service{
+		db:       db,
+		version:  version,
+		opts:     opts,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+		counters: aegobserve.RequestTotals,
+	}, nil
+}
+
+// Enabled 判断管理员是否开启了使用统计
+func (s *Service) Enabled() bool {
+	return s.opts.Enabled
+}
+
+// Run 按配置的间隔发送统计，未开启时立即返回，ctx 结束时返回
+func (s *Service) Run(ctx context.Context) {
+	if !s.opts.Enabled {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.opts.IntervalHours) * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Send(ctx); err != nil {
+				slog.Warn("[Telemetry] 发送使用统计失败", "error", err)
+			}
+		}
+	}
+}
+
+// Status 返回开关与发送状态，以及此刻生成的、下一次将要发送的内容
+func (s *Service) Status(ctx context.Context) (*domain.TelemetryStatus, error) {
+	report, _, err := s.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := &domain.TelemetryStatus{
+		Enabled:       s.opts.Enabled,
+		Endpoint:      s.opts.Endpoint,
+		IntervalHours: s.opts.IntervalHours,
+		LastError:     s.lastErr,
+		Preview:       *report,
+	}
+	if s.lastSentAt != nil {
+		at := *s.lastSentAt
+		status.LastSentAt = &at
+	}
+	return status, nil
+}
+
+// Send 立即发送一次统计，成功后请求量区间从此刻重新计算。未开启时不发送
+func (s *Service) Send(ctx context.Context) error {
+	if !s.opts.Enabled {
+		return nil
+	}
+	report, requests, err := s.collect(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.post(ctx, report)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastErr = err.Error()
+		return err
+	}
+	now := s.now().UTC()
+	s.baseline, s.lastSentAt, s.lastErr = requests, &now, ""
+	return nil
+}
+
+func (s *Service) post(ctx context.Context, report *domain.TelemetryReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("序列化使用统计失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("使用统计地址无效: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送使用统计失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("发送使用统计失败: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// collect 生成统计内容，同时返回当前的请求计数
+func (s *Service) collect(ctx context.Context) (*domain.TelemetryReport, int64, error) {
+	id, err := s.installationID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	report := &domain.TelemetryReport{
+		SchemaVersion:  schemaVersion,
+		InstallationID: id,
+		Version:        s.version,
+		Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		Day:            s.now().UTC().Format("2006-01-02"),
+	}
+	counts := []struct {
+		query string
+		dest  *int
+	}{
+		{`SELECT COUNT(DISTINCT plugin_id) FROM installed_plugins`, &report.InstalledPlugins},
+		{`SELECT COUNT(*) FROM plugin_instances WHERE status = 'RUNNING'`, &report.RunningInstances},
+		{`SELECT COUNT(*) FROM biz_overall_settings`, &report.BizCount},
+	}
+	for _, c := range counts {
+		if err := s.db.QueryRowContext(ctx, c.query).Scan(c.dest); err != nil {
+			return nil, 0, fmt.Errorf("统计使用情况失败: %w", err)
+		}
+	}
+
+	requests, _ := s.counters()
+	s.mu.Lock()
+	since := requests - s.baseline
+	s.mu.Unlock()
+	report.RequestVolume = volumeBucket(since)
+	return report, requests, nil
+}
+
+// installationID 返回持久化的匿名安装标识，首次调用时随机生成
+func (s *Service) installationID(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.installation != "" {
+		return s.installation, nil
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成匿名安装标识失败: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO global_settings (key, value, description) VALUES (?, ?, '匿名使用统计的随机安装标识')`,
+		installationIDKey, hex.EncodeToString(buf)); err != nil {
+		return "", fmt.Errorf("保存匿名安装标识失败: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT value FROM global_settings WHERE key = ?`, installationIDKey).Scan(&s.installation); err != nil {
+		return "", fmt.Errorf("读取匿名安装标识失败: %w", err)
+	}
+	return s.installation, nil
+}
+
+func volumeBucket(n int64) string {
+	for _, b := range volumeBuckets {
+		if n < b.below {
+			return b.name
+		}
+	}
+	return "1000000+"
+}
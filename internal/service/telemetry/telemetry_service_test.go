@@ -0,0 +1,116 @@
+// file: internal/service/telemetry/telemetry_service_test.go
+package telemetry
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) (*Service, *sql.DB, *int64) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES
+		('sqlite', '1.0.0', '/p'), ('sqlite', '1.1.0', '/p'), ('maps', '2.0.0', '/p')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO plugin_instances (instance_id, display_name, plugin_id, version, biz_name, port, status) VALUES
+		('a', 'A', 'sqlite', '1.1.0', 'letters', 50101, 'RUNNING'), ('b', 'B', 'maps', '2.0.0', 'maps', 50102, 'STOPPED')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO biz_overall_settings (biz_name) VALUES ('letters'), ('maps')`)
+	require.NoError(t, err)
+
+	svc, err := NewService(db, "v1.0.0", opts)
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC) }
+	requests := int64(1500)
+	svc.counters = func() (int64, int64) { return requests, 0 }
+	return svc, db, &requests
+}
+
+func TestNewService_RequiresEndpoint(t *testing.T) {
+	_, err := NewService(&sql.DB{}, "v1", Options{Enabled: true})
+	assert.Error(t, err)
+}
+
+func TestStatus_PreviewWithoutSending(t *testing.T) {
+	svc, db, _ := newTestService(t, Options{})
+	ctx := context.Background()
+
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.Equal(t, 24, status.IntervalHours)
+	p := status.Preview
+	assert.Len(t, p.InstallationID, 32)
+	assert.Equal(t, "v1.0.0", p.Version)
+	assert.Equal(t, 2, p.InstalledPlugins)
+	assert.Equal(t, 1, p.RunningInstances)
+	assert.Equal(t, 2, p.BizCount)
+	assert.Equal(t, "1000-9999", p.RequestVolume)
+	assert.Equal(t, "2026-03-02", p.Day)
+
+	// 安装标识持久化，重新创建服务后不变
+	again, err := NewService(db, "v1.0.0", Options{})
+	require.NoError(t, err)
+	status2, err := again.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, p.InstallationID, status2.Preview.InstallationID)
+
+	require.NoError(t, svc.Send(ctx), "未开启时 Send 什么也不做")
+	status, err = svc.Status(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, status.LastSentAt)
+}
+
+func TestSend(t *testing.T) {
+	var received []domain.TelemetryReport
+	fail := false
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var report domain.TelemetryReport
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		received = append(received, report)
+	}))
+	defer endpoint.Close()
+	svc, _, requests := newTestService(t, Options{Enabled: true, Endpoint: endpoint.URL})
+	ctx := context.Background()
+
+	preview, err := svc.Status(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.Send(ctx))
+	require.Len(t, received, 1)
+	assert.Equal(t, preview.Preview, received[0], "发送的内容与预览一致")
+
+	// 请求量区间从上次成功发送开始计算
+	*requests += 42
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1-99", status.Preview.RequestVolume)
+	require.NotNil(t, status.LastSentAt)
+
+	fail = true
+	assert.Error(t, svc.Send(ctx))
+	status, err = svc.Status(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, status.LastError, "503")
+	assert.Equal(t, "1-99", status.Preview.RequestVolume)
+}
+
+func TestVolumeBucket(t *testing.T) {
+	assert.Equal(t, "0", volumeBucket(0))
+	assert.Equal(t, "1-99", volumeBucket(99))
+	assert.Equal(t, "100-999", volumeBucket(100))
+	assert.Equal(t, "1000000+", volumeBucket(5_000_000))
+}
@@ -0,0 +1,269 @@
+// Package typegen file: internal/service/typegen/typegen_service.go
+package typegen
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidRequest 表示目标语言或 Go 包名无效
+var ErrInvalidRequest = errors.New("无效的类型生成请求")
+
+// 可生成的目标语言
+const (
+	LangTypeScript = "ts"
+	LangGo         = "go"
+)
+
+// libField 是 SQLite 数据源在结果中附加的来源库字段，其他数据源不一定返回
+const libField = "__lib"
+
+// Service 按业务组的字段配置生成客户端类型定义：每张可查询的表生成一个 TypeScript 接口或 Go 结构体，
+// 只包含可返回的字段。类型随管理员的配置变化，客户端可在构建时拉取以保持同步
+type Service struct {
+	config port.QueryAdminConfigService
+}
+
+// NewService 创建一个新的类型生成服务实例
+func NewService(config port.QueryAdminConfigService) (*Service, error) {
+	if config == nil {
+		return nil, errors.New("typegen.Service 需要一个有效的配置服务")
+	}
+	return &Service{config: config}, nil
+}
+
+// genField 是一个待生成的字段
+type genField struct {
+	name        string
+	displayName string
+	kind        fieldKind
+}
+
+// genTable 是一张待生成的表
+type genTable struct {
+	name   string
+	fields []genField
+}
+
+// fieldKind 是字段在目标语言中的基本类型
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInteger
+	kindFloat
+	kindBool
+)
+
+// Generate 生成业务组的类型定义源码。goPackage 为生成的 Go 源码的包名，为空时由业务组名推导，TypeScript 忽略该参数
+func (s *Service) Generate(ctx context.Context, bizName, lang, goPackage string) (string, error) {
+	if lang != LangTypeScript && lang != LangGo {
+		return "", fmt.Errorf("%w: 不支持的目标语言 '%s'，可选 %s 或 %s", ErrInvalidRequest, lang, LangTypeScript, LangGo)
+	}
+	cfg, err := s.config.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		return "", port.ErrBizNotFound
+	}
+
+	tables := collectTables(cfg)
+	if lang == LangGo {
+		if goPackage == "" {
+			goPackage = goPackageName(bizName)
+		}
+		if !token.IsIdentifier(goPackage) {
+			return "", fmt.Errorf("%w: 无效的 Go 包名 '%s'", ErrInvalidRequest, goPackage)
+		}
+		return renderGo(bizName, goPackage, tables), nil
+	}
+	return renderTypeScript(bizName, tables), nil
+}
+
+// collectTables 按名称排序返回可查询的表及其可返回的字段
+func collectTables(cfg *domain.BizQueryConfig) []genTable {
+	var tables []genTable
+	for name, tc := range cfg.Tables {
+		if tc == nil || !tc.IsSearchable {
+			continue
+		}
+		table := genTable{name: name}
+		for fieldName, field := range tc.Fields {
+			if field.IsReturnable {
+				table.fields = append(table.fields, genField{name: fieldName, displayName: field.DisplayName, kind: kindOf(field.DataType)})
+			}
+		}
+		sort.Slice(table.fields, func(i, j int) bool { return table.fields[i].name < table.fields[j].name })
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].name < tables[j].name })
+	return tables
+}
+
+// kindOf 按 SQLite 的类型亲和规则把字段配置的数据类型映射为基本类型，无法识别的类型按字符串处理
+func kindOf(dataType string) fieldKind {
+	t := strings.ToUpper(dataType)
+	switch {
+	case strings.Contains(t, "BOOL"):
+		return kindBool
+	case strings.Contains(t, "INT"):
+		return kindInteger
+	}
+	for _, marker := range []string{"REAL", "FLOA", "DOUB", "NUM", "DEC"} {
+		if strings.Contains(t, marker) {
+			return kindFloat
+		}
+	}
+	return kindString
+}
+
+// renderTypeScript 为每张表生成一个接口。所有字段都是可选且可为 null 的：记录中的值可能为空，
+// 查询也可能通过 fields_to_return 或视图只返回部分字段
+func renderTypeScript(bizName string, tables []genTable) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// Code generated by ArchiveAegis typegen for biz %q. DO NOT EDIT.\n", bizName)
+	for _, table := range tables {
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, "/** 表 %s */\n", table.name)
+		fmt.Fprintf(&sb, "export interface %s {\n", typeName(bizName, table.name))
+		for _, field := range table.fields {
+			if field.displayName != "" {
+				fmt.Fprintf(&sb, "  /** %s */\n", field.displayName)
+			}
+			fmt.Fprintf(&sb, "  %s?: %s | null;\n", tsPropertyName(field.name), tsType(field.kind))
+		}
+		fmt.Fprintf(&sb, "  /** 来源库，仅部分数据源返回 */\n  %s?: string;\n", libField)
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// renderGo 为每张表生成一个结构体。字段均为指针类型，以区分空值与缺省；输出经 gofmt 格式化
+func renderGo(bizName, goPackage string, tables []genTable) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// Code generated by ArchiveAegis typegen for biz %q. DO NOT EDIT.\n\n", bizName)
+	fmt.Fprintf(&sb, "package %s\n", goPackage)
+	for _, table := range tables {
+		structName := typeName(bizName, table.name)
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, "// %s 对应表 %s\n", structName, table.name)
+		fmt.Fprintf(&sb, "type %s struct {\n", structName)
+		used := map[string]bool{"Lib": true}
+		for _, field := range table.fields {
+			name := goFieldName(field.name)
+			for base, i := name, 2; used[name]; i++ {
+				name = fmt.Sprintf("%s%d", base, i)
+			}
+			used[name] = true
+			if field.displayName != "" {
+				fmt.Fprintf(&sb, "\t// %s %s\n", name, field.displayName)
+			}
+			fmt.Fprintf(&sb, "\t%s *%s `json:%q`\n", name, goType(field.kind), field.name+",omitempty")
+		}
+		sb.WriteString("\t// Lib 是来源库，仅部分数据源返回\n")
+		fmt.Fprintf(&sb, "\tLib string `json:%q`\n", libField+",omitempty")
+		sb.WriteString("}\n")
+	}
+	// 字段名与包名均已校验为合法标识符，格式化失败时退回未对齐的源码
+	if formatted, err := format.Source([]byte(sb.String())); err == nil {
+		return string(formatted)
+	}
+	return sb.String()
+}
+
+func tsType(kind fieldKind) string {
+	switch kind {
+	case kindInteger, kindFloat:
+		return "number"
+	case kindBool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+func goType(kind fieldKind) string {
+	switch kind {
+	case kindInteger:
+		return "int64"
+	case kindFloat:
+		return "float64"
+	case kindBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// typeName 由业务组名与表名生成类型名，例如 archive + letter_scans 生成 ArchiveLetterScans
+func typeName(bizName, tableName string) string {
+	return exportedIdentifier(pascalCase(bizName) + pascalCase(tableName))
+}
+
+// goFieldName 生成导出的结构体字段名；无法以大写字母开头的名称 (如中文字段名) 加上前缀 Field
+func goFieldName(fieldName string) string {
+	return exportedIdentifier(pascalCase(fieldName))
+}
+
+// exportedIdentifier 保证名称是一个导出的 Go 标识符，同时也是合法的 TypeScript 标识符
+func exportedIdentifier(name string) string {
+	if name == "" {
+		return "Field"
+	}
+	first := []rune(name)[0]
+	if !unicode.IsUpper(first) {
+		name = "Field" + name
+	}
+	return name
+}
+
+// pascalCase 以非字母数字字符为界切分名称，各段首字母大写后拼接
+func pascalCase(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// tsPropertyName 在字段名不是合法的 TypeScript 标识符时加上引号
+func tsPropertyName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+// goPackageName 由业务组名推导 Go 包名：只保留小写字母与数字，不能以数字开头
+func goPackageName(bizName string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(bizName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	name := sb.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "biz" + name
+	}
+	if token.IsKeyword(name) {
+		name += "types"
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+// file: internal/service/typegen/typegen_service_test.go
+package typegen
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type configStub struct {
+	port.QueryAdminConfigService
+	cfg *domain.BizQueryConfig
+}
+
+func (s configStub) GetBizQueryConfig(context.Context, string) (*domain.BizQueryConfig, error) {
+	return s.cfg, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	svc, err := NewService(configStub{cfg: &domain.BizQueryConfig{
+		BizName: "rare-books",
+		Tables: map[string]*domain.TableConfig{
+			"letter_scans": {TableName: "letter_scans", IsSearchable: true, Fields: map[string]domain.FieldSetting{
+				"id":         {FieldName: "id", IsReturnable: true, DataType: "INTEGER"},
+				"title":      {FieldName: "title", IsReturnable: true, DataType: "string", DisplayName: "标题"},
+				"page count": {FieldName: "page count", IsReturnable: true, DataType: "REAL"},
+				"题名":         {FieldName: "题名", IsReturnable: true, DataType: "TEXT"},
+				"public":     {FieldName: "public", IsReturnable: true, DataType: "BOOLEAN"},
+				"secret":     {FieldName: "secret", IsSearchable: true, DataType: "string"},
+			}},
+			"hidden": {TableName: "hidden", Fields: map[string]domain.FieldSetting{
+				"id": {FieldName: "id", IsReturnable: true},
+			}},
+		},
+	}})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestGenerate_Go(t *testing.T) {
+	svc := newTestService(t)
+	src, err := svc.Generate(context.Background(), "rare-books", LangGo, "")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "types.go", src, parser.ParseComments)
+	require.NoError(t, err, "生成的 Go 源码应能被解析:\n%s", src)
+	formatted, err := format.Source([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), src, "生成的 Go 源码应已是 gofmt 格式")
+
+	assert.Contains(t, src, "package rarebooks\n")
+	assert.Contains(t, src, "type RareBooksLetterScans struct {")
+	for _, line := range []string{
+		"Id *int64 `json:\"id,omitempty\"`",
+		"// Title 标题",
+		"Title *string `json:\"title,omitempty\"`",
+		"PageCount *float64 `json:\"page count,omitempty\"`",
+		"Public *bool `json:\"public,omitempty\"`",
+		"Field题名 *string `json:\"题名,omitempty\"`",
+		"Lib string `json:\"__lib,omitempty\"`",
+	} {
+		assert.Contains(t, normalizedLines(src), line)
+	}
+	assert.NotContains(t, src, "secret", "不可返回的字段不应生成")
+	assert.NotContains(t, src, "Hidden", "不可查询的表不应生成")
+
+	src, err = svc.Generate(context.Background(), "rare-books", LangGo, "archive")
+	require.NoError(t, err)
+	assert.Contains(t, src, "package archive\n")
+	_, err = svc.Generate(context.Background(), "rare-books", LangGo, "func")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestGenerate_TypeScript(t *testing.T) {
+	svc := newTestService(t)
+	src, err := svc.Generate(context.Background(), "rare-books", LangTypeScript, "")
+	require.NoError(t, err)
+	assert.Contains(t, src, "export interface RareBooksLetterScans {\n")
+	assert.Contains(t, src, "  id?: number | null;\n")
+	assert.Contains(t, src, "  /** 标题 */\n  title?: string | null;\n")
+	assert.Contains(t, src, "  \"page count\"?: number | null;\n")
+	assert.Contains(t, src, "  public?: boolean | null;\n")
+	assert.Contains(t, src, "  题名?: string | null;\n")
+	assert.Contains(t, src, "  __lib?: string;\n")
+	assert.NotContains(t, src, "secret")
+
+	_, err = svc.Generate(context.Background(), "rare-books", "rust", "")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestGenerate_BizNotFound(t *testing.T) {
+	svc, err := NewService(configStub{})
+	require.NoError(t, err)
+	_, err = svc.Generate(context.Background(), "missing", LangTypeScript, "")
+	assert.ErrorIs(t, err, port.ErrBizNotFound)
+}
+
+// normalizedLines 按行切分源码并压缩行内空白，使断言不受 gofmt 对齐的影响
+func normalizedLines(src string) []string {
+	var lines []string
+	for _, line := range strings.Split(src, "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	return lines
+}
@@ -0,0 +1,414 @@
+// Package updates file: internal/service/updates/update_service.go
+package updates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/plugin_manager"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCheckInterval = 24 * time.Hour
+	maxFeedBytes         = 1 << 20
+)
+
+var (
+	// ErrNoUpdate 表示插件没有可用的新版本，或没有已暂存的更新
+	ErrNoUpdate = errors.New("没有可用的更新")
+	// ErrIncompatible 表示新版本要求更高的网关版本
+	ErrIncompatible = errors.New("新版本与当前网关不兼容")
+	// ErrNotChecked 表示尚未执行过更新检查
+	ErrNotChecked = errors.New("尚未执行更新检查")
+)
+
+// PluginSource 是更新检查需要的插件管理能力，由 *plugin_manager.PluginManager 实现
+type PluginSource interface {
+	RefreshRepositories()
+	GetAvailablePlugins() []domain.PluginManifest
+	ListInstances() ([]domain.PluginInstance, error)
+	Install(pluginID, version string) error
+	StopWithReason(instanceID, reason string) error
+	Start(instanceID string) error
+	UpdateInstance(instanceID string, upd plugin_manager.InstanceUpdate) error
+}
+
+// Options 定义更新检查的配置
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ReleaseFeedURL 是网关发布源，返回 {"releases": [{"version", "release_date", "url", "changelog"}]}，
+	// 为空时只检查插件
+	ReleaseFeedURL string `mapstructure:"release_feed_url"`
+	// CheckIntervalHours 是后台检查的间隔，默认为 24 小时
+	CheckIntervalHours int `mapstructure:"check_interval_hours"`
+}
+
+// release 是发布源中的一个网关版本
+type release struct {
+	Version     string    `json:"version"`
+	ReleaseDate time.Time `json:"release_date"`
+	URL         string    `json:"url"`
+	Changelog   string    `json:"changelog"`
+}
+
+// Service 定期把网关版本与已安装插件的版本和发布源、插件仓库比较，列出可用更新。
+// 插件的新版本可以先暂存 (下载安装)，之后一键把使用旧版本的实例切换过去；网关自身只做提示
+type Service struct {
+	db             *sql.DB
+	plugins        PluginSource
+	gatewayVersion string
+	opts           Options
+	interval       time.Duration
+	client         *http.Client
+	now            func() time.Time
+
+	mu     sync.RWMutex
+	report *domain.UpdateReport
+
+	applyMu sync.Mutex // 同一时间只应用一个插件的更新
+}
+
+// NewService 创建一个新的更新检查服务实例，gatewayVersion 是当前网关的版本号
+func NewService(db *sql.DB, plugins PluginSource, gatewayVersion string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("updates.Service 需要一个有效的数据库连接")
+	}
+	if plugins == nil {
+		return nil, errors.New("updates.Service 需要插件管理器")
+	}
+	if opts.CheckIntervalHours < 0 {
+		return nil, fmt.Errorf("check_interval_hours 不能为负数: %d", opts.CheckIntervalHours)
+	}
+	interval := defaultCheckInterval
+	if opts.CheckIntervalHours > 0 {
+		interval = time.Duration(opts.CheckIntervalHours) * time.Hour
+	}
+	return &Service{
+		db:             db,
+		plugins:        plugins,
+		gatewayVersion: gatewayVersion,
+		opts:           opts,
+		interval:       interval,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		now:            time.Now,
+	}, nil
+}
+
+// Run 启动后立即检查一次，之后按配置的间隔定期检查，ctx 结束时返回
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Check(ctx); err != nil {
+			slog.Error("[Updates] 更新检查失败", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report 返回最近一次检查的结果，并附上当前的暂存状态
+func (s *Service) Report(ctx context.Context) (*domain.UpdateReport, error) {
+	s.mu.RLock()
+	cached := s.report
+	s.mu.RUnlock()
+	if cached == nil {
+		return nil, ErrNotChecked
+	}
+	report := *cached
+	report.Plugins = make([]domain.PluginUpdate, len(cached.Plugins))
+	copy(report.Plugins, cached.Plugins)
+	if err := s.attachStages(ctx, report.Plugins); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Check 刷新插件仓库并读取发布源，重新计算可用更新。发布源不可达不会导致失败，而是记录在 Errors 中
+func (s *Service) Check(ctx context.Context) (*domain.UpdateReport, error) {
+	report := &domain.UpdateReport{
+		CheckedAt: s.now().UTC(),
+		Gateway:   domain.GatewayUpdate{CurrentVersion: s.gatewayVersion},
+		Plugins:   []domain.PluginUpdate{},
+	}
+	if s.opts.ReleaseFeedURL != "" {
+		if err := s.checkGateway(ctx, &report.Gateway); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	s.plugins.RefreshRepositories()
+	installed, err := s.installedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := s.plugins.ListInstances()
+	if err != nil {
+		return nil, fmt.Errorf("读取插件实例失败: %w", err)
+	}
+	for _, manifest := range s.plugins.GetAvailablePlugins() {
+		current, ok := installed[manifest.ID]
+		if !ok {
+			continue
+		}
+		latest := latestVersion(manifest.Versions)
+		if latest == nil || CompareVersions(latest.VersionString, current) <= 0 {
+			continue
+		}
+		upd := domain.PluginUpdate{
+			PluginID:          manifest.ID,
+			InstalledVersion:  current,
+			LatestVersion:     latest.VersionString,
+			ReleaseDate:       latest.ReleaseDate,
+			Changelog:         latest.Changelog,
+			Compatible:        s.compatible(latest.MinGatewayVersion),
+			OutdatedInstances: outdatedInstances(instances, manifest.ID, latest.VersionString),
+		}
+		report.Plugins = append(report.Plugins, upd)
+	}
+
+	s.mu.Lock()
+	s.report = report
+	s.mu.Unlock()
+	if len(report.Plugins) > 0 || report.Gateway.UpdateAvailable {
+		slog.Info("[Updates] 发现可用更新", "gateway", report.Gateway.LatestVersion, "plugins", len(report.Plugins))
+	}
+	return s.Report(ctx)
+}
+
+// Stage 下载安装插件的最新版本并记录为待应用，已暂存的旧记录会被替换
+func (s *Service) Stage(ctx context.Context, pluginID string, userID int64) (*domain.PluginUpdate, error) {
+	upd, err := s.pending(pluginID)
+	if err != nil {
+		return nil, err
+	}
+	if !upd.Compatible {
+		return nil, fmt.Errorf("%w: %s v%s", ErrIncompatible, pluginID, upd.LatestVersion)
+	}
+	if err := s.plugins.Install(pluginID, upd.LatestVersion); err != nil {
+		return nil, fmt.Errorf("安装插件 '%s' v%s 失败: %w", pluginID, upd.LatestVersion, err)
+	}
+	stagedBy := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO plugin_update_stages (plugin_id, version, staged_by, staged_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO UPDATE SET version = excluded.version, staged_by = excluded.staged_by, staged_at = excluded.staged_at`,
+		pluginID, upd.LatestVersion, stagedBy, s.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("记录暂存的更新失败: %w", err)
+	}
+	slog.Info("[Updates] 插件更新已暂存", "plugin_id", pluginID, "version", upd.LatestVersion)
+	list := []domain.PluginUpdate{upd}
+	if err := s.attachStages(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// Apply 把使用旧版本的独立进程实例切换到已暂存的版本：运行中的实例先停止，切换后重新启动。
+// 单个实例失败不影响其余实例；全部成功后清除暂存记录
+func (s *Service) Apply(ctx context.Context, pluginID string) ([]domain.PluginUpdateResult, error) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	var version string
+	err := s.db.QueryRowContext(ctx, `SELECT version FROM plugin_update_stages WHERE plugin_id = ?`, pluginID).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: 插件 '%s' 没有已暂存的更新", ErrNoUpdate, pluginID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取暂存的更新失败: %w", err)
+	}
+	instances, err := s.plugins.ListInstances()
+	if err != nil {
+		return nil, fmt.Errorf("读取插件实例失败: %w", err)
+	}
+
+	results := []domain.PluginUpdateResult{}
+	failed := false
+	for _, inst := range instances {
+		if inst.PluginID != pluginID || inst.Mode == plugin_manager.InstanceModeEmbedded || inst.Version == version {
+			continue
+		}
+		res := domain.PluginUpdateResult{InstanceID: inst.InstanceID, FromVersion: inst.Version, ToVersion: version}
+		if err := s.switchInstance(inst, version, &res); err != nil {
+			res.Error = err.Error()
+			failed = true
+			slog.Error("[Updates] 切换插件实例版本失败", "instance_id", inst.InstanceID, "version", version, "error", err)
+		}
+		results = append(results, res)
+	}
+	if !failed {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM plugin_update_stages WHERE plugin_id = ?`, pluginID); err != nil {
+			return results, fmt.Errorf("清除暂存的更新失败: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// switchInstance 停止 (如在运行)、切换版本并重新启动一个实例
+func (s *Service) switchInstance(inst domain.PluginInstance, version string, res *domain.PluginUpdateResult) error {
+	running := inst.Status == "RUNNING"
+	if running {
+		if err := s.plugins.StopWithReason(inst.InstanceID, plugin_manager.StopReasonUpgrade); err != nil {
+			return fmt.Errorf("停止实例失败: %w", err)
+		}
+	}
+	if err := s.plugins.UpdateInstance(inst.InstanceID, plugin_manager.InstanceUpdate{Version: &version}); err != nil {
+		if running {
+			// 切换失败时尽量恢复旧版本的运行
+			_ = s.plugins.Start(inst.InstanceID)
+		}
+		return err
+	}
+	if running {
+		if err := s.plugins.Start(inst.InstanceID); err != nil {
+			return fmt.Errorf("已切换到 v%s，但重新启动失败: %w", version, err)
+		}
+		res.Restarted = true
+	}
+	return nil
+}
+
+// pending 返回最近一次检查中插件的可用更新
+func (s *Service) pending(pluginID string) (domain.PluginUpdate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.report == nil {
+		return domain.PluginUpdate{}, ErrNotChecked
+	}
+	for _, upd := range s.report.Plugins {
+		if upd.PluginID == pluginID {
+			return upd, nil
+		}
+	}
+	return domain.PluginUpdate{}, fmt.Errorf("%w: 插件 '%s'", ErrNoUpdate, pluginID)
+}
+
+// attachStages 填充各插件的暂存版本
+func (s *Service) attachStages(ctx context.Context, list []domain.PluginUpdate) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT plugin_id, version, staged_at FROM plugin_update_stages`)
+	if err != nil {
+		return fmt.Errorf("读取暂存的更新失败: %w", err)
+	}
+	defer rows.Close()
+	type stage struct {
+		version string
+		at      time.Time
+	}
+	stages := make(map[string]stage)
+	for rows.Next() {
+		var id string
+		var st stage
+		if err := rows.Scan(&id, &st.version, &st.at); err != nil {
+			return fmt.Errorf("读取暂存的更新失败: %w", err)
+		}
+		stages[id] = st
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("读取暂存的更新失败: %w", err)
+	}
+	for i := range list {
+		list[i].StagedVersion, list[i].StagedAt = "", nil
+		if st, ok := stages[list[i].PluginID]; ok {
+			at := st.at.UTC()
+			list[i].StagedVersion, list[i].StagedAt = st.version, &at
+		}
+	}
+	return nil
+}
+
+// installedVersions 返回每个插件已安装的最高版本
+func (s *Service) installedVersions(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT plugin_id, version FROM installed_plugins`)
+	if err != nil {
+		return nil, fmt.Errorf("读取已安装插件失败: %w", err)
+	}
+	defer rows.Close()
+	installed := make(map[string]string)
+	for rows.Next() {
+		var id, version string
+		if err := rows.Scan(&id, &version); err != nil {
+			return nil, fmt.Errorf("读取已安装插件失败: %w", err)
+		}
+		if cur, ok := installed[id]; !ok || CompareVersions(version, cur) > 0 {
+			installed[id] = version
+		}
+	}
+	return installed, rows.Err()
+}
+
+// checkGateway 读取发布源，找出比当前网关更新的最高版本
+func (s *Service) checkGateway(ctx context.Context, gw *domain.GatewayUpdate) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.ReleaseFeedURL, nil)
+	if err != nil {
+		return fmt.Errorf("发布源地址无效: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("读取发布源失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("读取发布源失败: HTTP %d", resp.StatusCode)
+	}
+	var feed struct {
+		Releases []release `json:"releases"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxFeedBytes)).Decode(&feed); err != nil {
+		return fmt.Errorf("解析发布源失败: %w", err)
+	}
+	var latest *release
+	for i := range feed.Releases {
+		if latest == nil || CompareVersions(feed.Releases[i].Version, latest.Version) > 0 {
+			latest = &feed.Releases[i]
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	gw.LatestVersion = latest.Version
+	gw.UpdateAvailable = CompareVersions(latest.Version, s.gatewayVersion) > 0
+	if gw.UpdateAvailable {
+		gw.ReleaseDate, gw.URL, gw.Changelog = latest.ReleaseDate, latest.URL, latest.Changelog
+	}
+	return nil
+}
+
+// compatible 判断当前网关是否满足插件要求的最低网关版本，未声明时视为兼容
+func (s *Service) compatible(minGateway string) bool {
+	return minGateway == "" || CompareVersions(s.gatewayVersion, minGateway) >= 0
+}
+
+func latestVersion(versions []domain.PluginVersion) *domain.PluginVersion {
+	var latest *domain.PluginVersion
+	for i := range versions {
+		if latest == nil || CompareVersions(versions[i].VersionString, latest.VersionString) > 0 {
+			latest = &versions[i]
+		}
+	}
+	return latest
+}
+
+// outdatedInstances 返回插件中版本低于 latest 的独立进程实例ID，内嵌实例随网关升级，不在此列
+func outdatedInstances(instances []domain.PluginInstance, pluginID, latest string) []string {
+	ids := []string{}
+	for _, inst := range instances {
+		if inst.PluginID == pluginID && inst.Mode != plugin_manager.InstanceModeEmbedded && CompareVersions(inst.Version, latest) < 0 {
+			ids = append(ids, inst.InstanceID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,205 @@
+// file: internal/service/updates/update_service_test.go
+package updates
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/plugin_manager"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlugins 在内存中模拟插件管理器
+type fakePlugins struct {
+	db        *sql.DB
+	catalog   []domain.PluginManifest
+	instances []domain.PluginInstance
+	calls     []string
+	failStart map[string]bool
+}
+
+func (f *fakePlugins) RefreshRepositories()                            { f.calls = append(f.calls, "refresh") }
+func (f *fakePlugins) GetAvailablePlugins() []domain.PluginManifest    { return f.catalog }
+func (f *fakePlugins) ListInstances() ([]domain.PluginInstance, error) { return f.instances, nil }
+
+func (f *fakePlugins) Install(pluginID, version string) error {
+	f.calls = append(f.calls, "install "+pluginID+" "+version)
+	_, err := f.db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES (?, ?, '/p')`, pluginID, version)
+	return err
+}
+
+func (f *fakePlugins) StopWithReason(instanceID, reason string) error {
+	f.calls = append(f.calls, "stop "+instanceID+" "+reason)
+	return nil
+}
+
+func (f *fakePlugins) Start(instanceID string) error {
+	f.calls = append(f.calls, "start "+instanceID)
+	if f.failStart[instanceID] {
+		return errors.New("端口被占用")
+	}
+	return nil
+}
+
+func (f *fakePlugins) UpdateInstance(instanceID string, upd plugin_manager.InstanceUpdate) error {
+	f.calls = append(f.calls, fmt.Sprintf("update %s %s", instanceID, *upd.Version))
+	return nil
+}
+
+func newTestService(t *testing.T, feedURL string) (*Service, *fakePlugins) {
+	t.Helper()
+	db := fixture.NewSystemDB(t)
+	_, err := db.Exec(`INSERT INTO installed_plugins (plugin_id, version, install_path) VALUES
+		('sqlite', '1.0.0', '/p'), ('sqlite', '1.2.0', '/p'), ('maps', '2.0.0', '/p'), ('iiif', '0.9.0', '/p')`)
+	require.NoError(t, err)
+
+	plugins := &fakePlugins{
+		db: db,
+		catalog: []domain.PluginManifest{
+			{ID: "iiif", Versions: []domain.PluginVersion{{VersionString: "1.0.0", MinGatewayVersion: "v2.0.0"}}},
+			{ID: "maps", Versions: []domain.PluginVersion{{VersionString: "2.0.0"}}},
+			{ID: "sqlite", Versions: []domain.PluginVersion{
+				{VersionString: "1.10.0", Changelog: "更快的分页"}, {VersionString: "1.9.0"}, {VersionString: "1.2.0"},
+			}},
+			{ID: "not-installed", Versions: []domain.PluginVersion{{VersionString: "9.0.0"}}},
+		},
+		instances: []domain.PluginInstance{
+			{InstanceID: "s-run", PluginID: "sqlite", Version: "1.0.0", Mode: "process", Status: "RUNNING"},
+			{InstanceID: "s-stopped", PluginID: "sqlite", Version: "1.2.0", Mode: "process", Status: "STOPPED"},
+			{InstanceID: "s-embedded", PluginID: "sqlite", Version: "1.0.0", Mode: plugin_manager.InstanceModeEmbedded, Status: "RUNNING"},
+		},
+		failStart: map[string]bool{},
+	}
+	svc, err := NewService(db, plugins, "v1.0.0-alpha5", Options{Enabled: true, ReleaseFeedURL: feedURL})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC) }
+	return svc, plugins
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.0.0-alpha5", "1.0.0", -1},
+		{"v1.0.0-alpha10", "v1.0.0-alpha9", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"2.0.0+build7", "2.0.0", 0},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, CompareVersions(tc.a, tc.b), "%s vs %s", tc.a, tc.b)
+		assert.Equal(t, -tc.want, CompareVersions(tc.b, tc.a), "%s vs %s", tc.b, tc.a)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"releases": [
+			{"version": "v1.0.0-alpha4"},
+			{"version": "v1.0.0", "url": "https://example.org/releases/v1.0.0", "changelog": "正式版"},
+			{"version": "v1.0.0-alpha6"}
+		]}`))
+	}))
+	defer feed.Close()
+	svc, plugins := newTestService(t, feed.URL)
+	ctx := context.Background()
+
+	_, err := svc.Report(ctx)
+	assert.ErrorIs(t, err, ErrNotChecked)
+
+	report, err := svc.Check(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, plugins.calls, "refresh")
+	assert.Empty(t, report.Errors)
+	assert.True(t, report.Gateway.UpdateAvailable)
+	assert.Equal(t, "v1.0.0", report.Gateway.LatestVersion)
+	assert.Equal(t, "https://example.org/releases/v1.0.0", report.Gateway.URL)
+
+	// maps 已是最新，not-installed 未安装
+	require.Len(t, report.Plugins, 2)
+	iiif, sqlite := report.Plugins[0], report.Plugins[1]
+	assert.Equal(t, "iiif", iiif.PluginID)
+	assert.False(t, iiif.Compatible)
+	assert.Equal(t, "sqlite", sqlite.PluginID)
+	assert.Equal(t, "1.2.0", sqlite.InstalledVersion)
+	assert.Equal(t, "1.10.0", sqlite.LatestVersion)
+	assert.Equal(t, "更快的分页", sqlite.Changelog)
+	assert.True(t, sqlite.Compatible)
+	assert.Equal(t, []string{"s-run", "s-stopped"}, sqlite.OutdatedInstances)
+}
+
+func TestCheck_FeedUnavailable(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer feed.Close()
+	svc, _ := newTestService(t, feed.URL)
+
+	report, err := svc.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "502")
+	assert.False(t, report.Gateway.UpdateAvailable)
+	assert.Len(t, report.Plugins, 2)
+}
+
+func TestStageAndApply(t *testing.T) {
+	svc, plugins := newTestService(t, "")
+	ctx := context.Background()
+
+	_, err := svc.Stage(ctx, "sqlite", 0)
+	assert.ErrorIs(t, err, ErrNotChecked)
+	_, err = svc.Apply(ctx, "sqlite")
+	assert.ErrorIs(t, err, ErrNoUpdate)
+
+	_, err = svc.Check(ctx)
+	require.NoError(t, err)
+	_, err = svc.Stage(ctx, "maps", 0)
+	assert.ErrorIs(t, err, ErrNoUpdate)
+	_, err = svc.Stage(ctx, "iiif", 0)
+	assert.ErrorIs(t, err, ErrIncompatible)
+
+	staged, err := svc.Stage(ctx, "sqlite", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", staged.StagedVersion)
+	require.NotNil(t, staged.StagedAt)
+	assert.Contains(t, plugins.calls, "install sqlite 1.10.0")
+
+	report, err := svc.Report(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", report.Plugins[1].StagedVersion)
+
+	// 重启失败的实例保留暂存记录，便于再次应用
+	plugins.failStart["s-run"] = true
+	plugins.calls = nil
+	results, err := svc.Apply(ctx, "sqlite")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "s-run", results[0].InstanceID)
+	assert.Contains(t, results[0].Error, "重新启动失败")
+	assert.Equal(t, domain.PluginUpdateResult{InstanceID: "s-stopped", FromVersion: "1.2.0", ToVersion: "1.10.0"}, results[1])
+	assert.Equal(t, []string{
+		"stop s-run " + plugin_manager.StopReasonUpgrade, "update s-run 1.10.0", "start s-run",
+		"update s-stopped 1.10.0",
+	}, plugins.calls)
+
+	plugins.failStart["s-run"] = false
+	results, err = svc.Apply(ctx, "sqlite")
+	require.NoError(t, err)
+	assert.True(t, results[0].Restarted)
+	assert.Empty(t, results[0].Error)
+
+	_, err = svc.Apply(ctx, "sqlite")
+	assert.ErrorIs(t, err, ErrNoUpdate)
+}
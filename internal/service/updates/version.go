@@ -0,0 +1,93 @@
+// Package updates file: internal/service/updates/version.go
+package updates
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions 比较两个形如 v1.2.3 或 1.2.3-alpha5 的版本号，a 较新时返回 1，较旧时返回 -1，相同时返回 0。
+// 数字段按数值比较，缺少的段视为 0；带预发布后缀的版本低于同号的正式版本，预发布后缀之间按数字感知的方式比较
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var x, y string
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if c := compareSegment(x, y); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegment(aPre, bPre)
+}
+
+// splitVersion 去掉前缀 v 与构建元数据 (+...)，拆分出主版本号与预发布后缀
+func splitVersion(v string) (core, pre string) {
+	v = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(v), "v"), "V")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// compareSegment 逐段比较字母与数字，使 alpha10 高于 alpha9
+func compareSegment(a, b string) int {
+	for a != "" || b != "" {
+		var x, y string
+		x, a = nextToken(a)
+		y, b = nextToken(b)
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		if x == "" {
+			xn, xErr = 0, nil
+		}
+		if y == "" {
+			yn, yErr = 0, nil
+		}
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn > yn {
+					return 1
+				}
+				return -1
+			}
+		case x != y:
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// nextToken 取出开头连续的数字或非数字部分
+func nextToken(s string) (token, rest string) {
+	if s == "" {
+		return "", ""
+	}
+	digit := s[0] >= '0' && s[0] <= '9'
+	i := 1
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9') == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,402 @@
+// Package uploads file: internal/service/uploads/uploads_service.go
+package uploads
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxChunkMB    = 64
+	defaultMaxFileGB     = 64
+	defaultTTLHours      = 24
+	sweepInterval        = 10 * time.Minute
+	maxFileNameLength    = 255
+	partFileSuffix       = ".part"
+	uploadIDRandomLength = 16
+)
+
+var (
+	// ErrInvalidUpload 表示上传会话的参数不合法
+	ErrInvalidUpload = errors.New("无效的上传请求")
+	// ErrNotFound 表示上传会话不存在或已过期
+	ErrNotFound = errors.New("上传会话不存在或已过期")
+	// ErrOffsetMismatch 表示分块的偏移与已接收的字节数不一致，客户端应查询会话后从 received 处续传
+	ErrOffsetMismatch = errors.New("分块偏移与已接收的字节数不一致")
+	// ErrChecksumMismatch 表示分块或整个文件的 SHA-256 与声明的不一致
+	ErrChecksumMismatch = errors.New("校验值不一致")
+	// ErrTooLarge 表示分块或文件超出大小限制
+	ErrTooLarge = errors.New("超出大小限制")
+	// ErrNotComplete 表示上传尚未完成，不能使用
+	ErrNotComplete = errors.New("上传尚未完成")
+)
+
+// Options 定义分块上传的配置
+type Options struct {
+	// MaxChunkMB 是单个分块的大小上限，默认为 64 MB
+	MaxChunkMB int64 `mapstructure:"max_chunk_mb"`
+	// MaxFileGB 是单个文件的大小上限，默认为 64 GB
+	MaxFileGB int64 `mapstructure:"max_file_gb"`
+	// TTLHours 是会话自最近一次活动起的保留时间，过期的会话及其数据会被清理，默认为 24 小时
+	TTLHours int `mapstructure:"ttl_hours"`
+}
+
+// Service 管理分块上传：客户端先创建会话并声明文件大小与可选的 SHA-256，再按顺序追加带校验值的分块，
+// 最后完成上传并校验整个文件。已确认的字节数保存在数据库中，连接中断后客户端查询会话即可从断点续传。
+// 完成的上传以会话 ID 交给库文件替换、快照恢复等接口使用
+type Service struct {
+	db   *sql.DB
+	dir  string
+	opts Options
+	now  func() time.Time
+
+	mu sync.Mutex
+	// locks 串行化同一会话的分块写入
+	locks map[string]*sync.Mutex
+}
+
+// NewService 创建一个新的分块上传服务实例，dir 是保存上传数据的目录
+func NewService(db *sql.DB, dir string, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("uploads.Service 需要一个有效的数据库连接")
+	}
+	if dir == "" {
+		return nil, errors.New("uploads.Service 需要有效的上传目录")
+	}
+	if opts.MaxChunkMB < 0 || opts.MaxFileGB < 0 || opts.TTLHours < 0 {
+		return nil, errors.New("max_chunk_mb、max_file_gb 与 ttl_hours 不能为负数")
+	}
+	if opts.MaxChunkMB == 0 {
+		opts.MaxChunkMB = defaultMaxChunkMB
+	}
+	if opts.MaxFileGB == 0 {
+		opts.MaxFileGB = defaultMaxFileGB
+	}
+	if opts.TTLHours == 0 {
+		opts.TTLHours = defaultTTLHours
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("建立上传目录失败: %w", err)
+	}
+	return &Service{db: db, dir: dir, opts: opts, now: time.Now, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+// MaxChunkBytes 返回单个分块的大小上限
+func (s *Service) MaxChunkBytes() int64 {
+	return s.opts.MaxChunkMB << 20
+}
+
+// Init 创建一个上传会话。sha256 为空时完成上传时不校验整个文件
+func (s *Service) Init(ctx context.Context, userID int64, fileName string, size int64, checksum string) (*domain.UploadSession, error) {
+	fileName = filepath.Base(strings.TrimSpace(fileName))
+	if fileName == "" || fileName == "." || len(fileName) > maxFileNameLength {
+		return nil, fmt.Errorf("%w: 文件名为空或过长", ErrInvalidUpload)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: 文件大小必须大于 0", ErrInvalidUpload)
+	}
+	if size > s.opts.MaxFileGB<<30 {
+		return nil, fmt.Errorf("%w: 文件大小 %d 字节超过上限 %d GB", ErrTooLarge, size, s.opts.MaxFileGB)
+	}
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum != "" && !isSHA256(checksum) {
+		return nil, fmt.Errorf("%w: sha256 应为 64 位十六进制字符串", ErrInvalidUpload)
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	session := &domain.UploadSession{
+		ID: id, UserID: userID, FileName: fileName, TotalSize: size, SHA256: checksum,
+		Status: domain.UploadStatusUploading, CreatedAt: now, UpdatedAt: now, ExpiresAt: s.expiry(now),
+	}
+	f, err := os.Create(s.partPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("建立上传文件失败: %w", err)
+	}
+	_ = f.Close()
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO upload_sessions (id, user_id, file_name, total_size, sha256, received, status, created_at, updated_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?, ?)`,
+		session.ID, session.UserID, session.FileName, session.TotalSize, session.SHA256, session.Status, now, now, session.ExpiresAt); err != nil {
+		_ = os.Remove(s.partPath(id))
+		return nil, fmt.Errorf("保存上传会话失败: %w", err)
+	}
+	return session, nil
+}
+
+// Get 返回未过期的上传会话
+func (s *Service) Get(ctx context.Context, id string) (*domain.UploadSession, error) {
+	var session domain.UploadSession
+	err := s.db.QueryRowContext(ctx, `SELECT id, user_id, file_name, total_size, sha256, received, status, created_at, updated_at, expires_at
+		FROM upload_sessions WHERE id = ?`, id).Scan(
+		&session.ID, &session.UserID, &session.FileName, &session.TotalSize, &session.SHA256, &session.Received,
+		&session.Status, &session.CreatedAt, &session.UpdatedAt, &session.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取上传会话失败: %w", err)
+	}
+	if !session.ExpiresAt.After(s.now()) {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// AppendChunk 在 offset 处写入一个分块，chunkSHA256 是该分块的校验值。offset 必须等于已接收的字节数；
+// 分块写入后先校验，校验不通过时丢弃写入的数据，已接收的字节数不变
+func (s *Service) AppendChunk(ctx context.Context, id string, offset int64, chunkSHA256 string, r io.Reader) (*domain.UploadSession, error) {
+	chunkSHA256 = strings.ToLower(strings.TrimSpace(chunkSHA256))
+	if !isSHA256(chunkSHA256) {
+		return nil, fmt.Errorf("%w: 分块必须附带 64 位十六进制的 SHA-256 校验值", ErrInvalidUpload)
+	}
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.UploadStatusUploading {
+		return nil, fmt.Errorf("%w: 上传已完成，不能再追加分块", ErrInvalidUpload)
+	}
+	if offset != session.Received {
+		return session, fmt.Errorf("%w: 请从偏移 %d 处继续上传", ErrOffsetMismatch, session.Received)
+	}
+
+	f, err := os.OpenFile(s.partPath(id), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("打开上传文件失败: %w", err)
+	}
+	defer f.Close()
+	// 截断到已确认的长度，丢弃此前中断的分块可能留下的数据
+	if err := f.Truncate(offset); err != nil {
+		return nil, fmt.Errorf("截断上传文件失败: %w", err)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	remaining := session.TotalSize - offset
+	limit := min(s.MaxChunkBytes(), remaining)
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, limit+1))
+	if err != nil {
+		_ = f.Truncate(offset)
+		return nil, fmt.Errorf("写入分块失败: %w", err)
+	}
+	if n > limit {
+		_ = f.Truncate(offset)
+		if limit == remaining {
+			return nil, fmt.Errorf("%w: 分块超出声明的文件大小，剩余 %d 字节", ErrTooLarge, remaining)
+		}
+		return nil, fmt.Errorf("%w: 分块超过 %d MB", ErrTooLarge, s.opts.MaxChunkMB)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: 分块为空", ErrInvalidUpload)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != chunkSHA256 {
+		_ = f.Truncate(offset)
+		return nil, fmt.Errorf("%w: 分块的 SHA-256 与声明的不一致，请重新上传该分块", ErrChecksumMismatch)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Truncate(offset)
+		return nil, fmt.Errorf("写入分块失败: %w", err)
+	}
+
+	now := s.now().UTC()
+	session.Received = offset + n
+	session.UpdatedAt, session.ExpiresAt = now, s.expiry(now)
+	if _, err := s.db.ExecContext(ctx, `UPDATE upload_sessions SET received = ?, updated_at = ?, expires_at = ? WHERE id = ?`,
+		session.Received, now, session.ExpiresAt, id); err != nil {
+		_ = f.Truncate(offset)
+		return nil, fmt.Errorf("保存上传进度失败: %w", err)
+	}
+	return session, nil
+}
+
+// Complete 在全部字节已接收后校验整个文件，通过后会话变为可用
+func (s *Service) Complete(ctx context.Context, id string) (*domain.UploadSession, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == domain.UploadStatusComplete {
+		return session, nil
+	}
+	if session.Received != session.TotalSize {
+		return session, fmt.Errorf("%w: 已接收 %d / %d 字节", ErrNotComplete, session.Received, session.TotalSize)
+	}
+	if session.SHA256 != "" {
+		actual, err := fileSHA256(s.partPath(id))
+		if err != nil {
+			return nil, err
+		}
+		if actual != session.SHA256 {
+			return nil, fmt.Errorf("%w: 文件的 SHA-256 为 %s，与声明的 %s 不一致，请删除会话后重新上传", ErrChecksumMismatch, actual, session.SHA256)
+		}
+	}
+
+	now := s.now().UTC()
+	session.Status = domain.UploadStatusComplete
+	session.UpdatedAt, session.ExpiresAt = now, s.expiry(now)
+	if _, err := s.db.ExecContext(ctx, `UPDATE upload_sessions SET status = ?, updated_at = ?, expires_at = ? WHERE id = ?`,
+		session.Status, now, session.ExpiresAt, id); err != nil {
+		return nil, fmt.Errorf("保存上传会话失败: %w", err)
+	}
+	return session, nil
+}
+
+// Path 返回已完成上传的文件路径，供导入、替换等接口读取
+func (s *Service) Path(ctx context.Context, id string) (string, *domain.UploadSession, error) {
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if session.Status != domain.UploadStatusComplete {
+		return "", nil, fmt.Errorf("%w: 已接收 %d / %d 字节，请先完成上传", ErrNotComplete, session.Received, session.TotalSize)
+	}
+	return s.partPath(id), session, nil
+}
+
+// Delete 删除上传会话及其数据，会话不存在时返回 ErrNotFound
+func (s *Service) Delete(ctx context.Context, id string) error {
+	unlock := s.lock(id)
+	defer unlock()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除上传会话失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if err := os.Remove(s.partPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("[Uploads] 删除上传文件失败", "id", id, "error", err)
+	}
+	return nil
+}
+
+// Run 定期清理过期的上传会话，直到 ctx 被取消
+func (s *Service) Run(ctx context.Context) {
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep 删除过期的会话及其数据，以及没有对应会话的残留文件
+func (s *Service) sweep(ctx context.Context) {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE expires_at <= ?`, s.now().UTC()); err != nil {
+		slog.Warn("[Uploads] 清理过期的上传会话失败", "error", err)
+		return
+	}
+	live := make(map[string]bool)
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM upload_sessions`)
+	if err != nil {
+		slog.Warn("[Uploads] 读取上传会话失败", "error", err)
+		return
+	}
+	for rows.Next() {
+		var id string
+		if rows.Scan(&id) == nil {
+			live[id] = true
+		}
+	}
+	_ = rows.Close()
+
+	s.mu.Lock()
+	for id := range s.locks {
+		if !live[id] {
+			delete(s.locks, id)
+		}
+	}
+	s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), partFileSuffix)
+		if !ok || live[id] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err == nil {
+			slog.Info("[Uploads] 已清理过期的上传", "id", id)
+		}
+	}
+}
+
+func (s *Service) expiry(now time.Time) time.Time {
+	return now.Add(time.Duration(s.opts.TTLHours) * time.Hour)
+}
+
+func (s *Service) partPath(id string) string {
+	return filepath.Join(s.dir, id+partFileSuffix)
+}
+
+// lock 取得会话的写锁，返回的函数释放该锁
+func (s *Service) lock(id string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+	s.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func newUploadID() (string, error) {
+	buf := make([]byte, uploadIDRandomLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成上传会话 ID 失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,121 @@
+// file: internal/service/uploads/uploads_service_test.go
+package uploads
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, opts Options) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	db := fixture.OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+	svc, err := NewService(db, filepath.Join(dir, "uploads"), opts)
+	require.NoError(t, err)
+	return svc
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func TestUpload_ChunksResumeAndComplete(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{})
+	content := []byte("第一块数据|第二块数据")
+	first, second := content[:15], content[15:]
+
+	session, err := svc.Init(ctx, 1, "../archive.db", int64(len(content)), sum(content))
+	require.NoError(t, err)
+	assert.Equal(t, "archive.db", session.FileName, "文件名只保留最后一段")
+
+	_, err = svc.AppendChunk(ctx, session.ID, 0, sum([]byte("别的内容")), bytes.NewReader(first))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	got, err := svc.Get(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), got.Received, "校验失败的分块不计入进度")
+
+	got, err = svc.AppendChunk(ctx, session.ID, 0, sum(first), bytes.NewReader(first))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(first)), got.Received)
+
+	_, err = svc.AppendChunk(ctx, session.ID, 0, sum(first), bytes.NewReader(first))
+	assert.ErrorIs(t, err, ErrOffsetMismatch, "重复发送的分块按偏移拒绝")
+	_, err = svc.Complete(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrNotComplete)
+	_, _, err = svc.Path(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrNotComplete)
+
+	_, err = svc.AppendChunk(ctx, session.ID, int64(len(first)), sum(append(second, 'x')), bytes.NewReader(append(second, 'x')))
+	assert.ErrorIs(t, err, ErrTooLarge, "超出声明大小的分块被拒绝")
+	_, err = svc.AppendChunk(ctx, session.ID, int64(len(first)), sum(second), bytes.NewReader(second))
+	require.NoError(t, err)
+
+	got, err = svc.Complete(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UploadStatusComplete, got.Status)
+	path, _, err := svc.Path(ctx, session.ID)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	require.NoError(t, svc.Delete(ctx, session.ID))
+	assert.NoFileExists(t, path)
+	_, err = svc.Get(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpload_WholeFileChecksumAndLimits(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{MaxChunkMB: 1})
+
+	content := []byte("archive")
+	session, err := svc.Init(ctx, 1, "a.db", int64(len(content)), sum([]byte("other")))
+	require.NoError(t, err)
+	_, err = svc.AppendChunk(ctx, session.ID, 0, sum(content), bytes.NewReader(content))
+	require.NoError(t, err)
+	_, err = svc.Complete(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	big := make([]byte, 1<<20+1)
+	session, err = svc.Init(ctx, 1, "big.db", int64(len(big)), "")
+	require.NoError(t, err)
+	_, err = svc.AppendChunk(ctx, session.ID, 0, sum(big), bytes.NewReader(big))
+	assert.ErrorIs(t, err, ErrTooLarge, "分块超过上限")
+
+	_, err = svc.Init(ctx, 1, "a.db", 0, "")
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+	_, err = svc.Init(ctx, 1, "a.db", 10, "not-a-hash")
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+	_, err = svc.AppendChunk(ctx, session.ID, 0, "", bytes.NewReader(content))
+	assert.ErrorIs(t, err, ErrInvalidUpload, "分块必须附带校验值")
+}
+
+func TestUpload_SweepExpired(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, Options{TTLHours: 1})
+	session, err := svc.Init(ctx, 1, "a.db", 10, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(svc.dir, "orphan"+partFileSuffix), []byte("x"), 0o644))
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = svc.Get(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "过期的会话不可用")
+
+	svc.sweep(ctx)
+	assert.NoFileExists(t, svc.partPath(session.ID))
+	assert.NoFileExists(t, filepath.Join(svc.dir, "orphan"+partFileSuffix))
+}
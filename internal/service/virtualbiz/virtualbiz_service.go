@@ -0,0 +1,169 @@
+// Package virtualbiz file: internal/service/virtualbiz/virtualbiz_service.go
+package virtualbiz
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/readsplit"
+	"ArchiveAegis/internal/adapter/datasource/sharded"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	// ErrVirtualBizNotFound 表示指定的虚拟业务组不存在
+	ErrVirtualBizNotFound = errors.New("虚拟业务组不存在")
+	// ErrInvalidDefinition 表示虚拟业务组定义不合法
+	ErrInvalidDefinition = errors.New("无效的虚拟业务组定义")
+)
+
+// Registrar 负责将虚拟业务组注册到网关的数据源注册表，由插件管理器实现
+type Registrar interface {
+	RegisterStaticDataSource(bizName string, ds port.DataSource) error
+	UnregisterStaticDataSource(bizName string)
+	LookupDataSource(bizName string) (port.DataSource, bool)
+}
+
+// Service 负责持久化虚拟业务组定义，并使注册表中的数据源与定义保持一致
+type Service struct {
+	db        *sql.DB
+	registrar Registrar
+}
+
+// NewService 创建一个新的虚拟业务组服务实例
+func NewService(db *sql.DB, registrar Registrar) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("virtualbiz.Service 需要一个有效的数据库连接")
+	}
+	return &Service{db: db, registrar: registrar}, nil
+}
+
+// LoadAll 在启动时注册所有已保存的虚拟业务组。单个定义注册失败不会影响其它定义。
+func (s *Service) LoadAll(ctx context.Context) error {
+	defs, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if err := s.registrar.RegisterStaticDataSource(def.BizName, virtual.New(def, s.registrar.LookupDataSource)); err != nil {
+			slog.Warn("[VirtualBiz] 注册虚拟业务组失败，已跳过", "biz", def.BizName, "error", err)
+		}
+	}
+	return nil
+}
+
+// List 返回所有虚拟业务组定义
+func (s *Service) List(ctx context.Context) ([]domain.VirtualBiz, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT biz_name, description, tables_json, updated_at FROM virtual_biz_definitions ORDER BY biz_name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询虚拟业务组失败: %w", err)
+	}
+	defer rows.Close()
+
+	defs := make([]domain.VirtualBiz, 0)
+	for rows.Next() {
+		var def domain.VirtualBiz
+		var tablesJSON string
+		if err := rows.Scan(&def.BizName, &def.Description, &tablesJSON, &def.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描虚拟业务组失败: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tablesJSON), &def.Tables); err != nil {
+			return nil, fmt.Errorf("解析虚拟业务组 '%s' 的表映射失败: %w", def.BizName, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// Save 创建或更新一个虚拟业务组，并立即在网关中生效
+func (s *Service) Save(ctx context.Context, def domain.VirtualBiz) error {
+	if err := s.validate(ctx, def); err != nil {
+		return err
+	}
+	tablesJSON, err := json.Marshal(def.Tables)
+	if err != nil {
+		return fmt.Errorf("序列化表映射失败: %w", err)
+	}
+
+	// 先注册再持久化：名称被插件占用时不应留下无法生效的定义
+	if err := s.registrar.RegisterStaticDataSource(def.BizName, virtual.New(def, s.registrar.LookupDataSource)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO virtual_biz_definitions (biz_name, description, tables_json, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(biz_name) DO UPDATE SET
+			description = excluded.description,
+			tables_json = excluded.tables_json,
+			updated_at = CURRENT_TIMESTAMP`,
+		def.BizName, def.Description, string(tablesJSON),
+	)
+	if err != nil {
+		s.registrar.UnregisterStaticDataSource(def.BizName)
+		return fmt.Errorf("保存虚拟业务组 '%s' 失败: %w", def.BizName, err)
+	}
+	return nil
+}
+
+// Delete 删除一个虚拟业务组，并将其从网关注销
+func (s *Service) Delete(ctx context.Context, bizName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM virtual_biz_definitions WHERE biz_name = ?`, bizName)
+	if err != nil {
+		return fmt.Errorf("删除虚拟业务组 '%s' 失败: %w", bizName, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrVirtualBizNotFound
+	}
+	s.registrar.UnregisterStaticDataSource(bizName)
+	return nil
+}
+
+// validate 校验定义的完整性。底层业务组不能是虚拟业务组，以避免循环路由。
+func (s *Service) validate(ctx context.Context, def domain.VirtualBiz) error {
+	if def.BizName == "" {
+		return fmt.Errorf("%w: biz_name 不能为空", ErrInvalidDefinition)
+	}
+	if len(def.Tables) == 0 {
+		return fmt.Errorf("%w: 至少需要映射一张表", ErrInvalidDefinition)
+	}
+	if ds, loaded := s.registrar.LookupDataSource(def.BizName); loaded {
+		switch ds.(type) {
+		case *sharded.DataSource, *readsplit.DataSource:
+			return fmt.Errorf("%w: 名称 '%s' 已被分片或读写分离业务组使用", ErrInvalidDefinition, def.BizName)
+		}
+	}
+
+	existing, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	virtualNames := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		virtualNames[v.BizName] = true
+	}
+
+	seen := make(map[string]bool, len(def.Tables))
+	for _, t := range def.Tables {
+		if t.Name == "" || t.SourceBiz == "" || t.SourceTable == "" {
+			return fmt.Errorf("%w: 每张表必须包含 name、source_biz 和 source_table", ErrInvalidDefinition)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("%w: 表名 '%s' 重复", ErrInvalidDefinition, t.Name)
+		}
+		seen[t.Name] = true
+		if t.SourceBiz == def.BizName || virtualNames[t.SourceBiz] {
+			return fmt.Errorf("%w: 表 '%s' 的底层业务组 '%s' 不能是虚拟业务组", ErrInvalidDefinition, t.Name, t.SourceBiz)
+		}
+		for _, f := range t.Filters {
+			if f.Field == "" {
+				return fmt.Errorf("%w: 表 '%s' 的过滤条件缺少字段名", ErrInvalidDefinition, t.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// file: internal/service/virtualbiz/virtualbiz_service_test.go
+package virtualbiz_test
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/readsplit"
+	"ArchiveAegis/internal/adapter/datasource/virtual"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"ArchiveAegis/pkg/testsupport"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLettersSource() *testsupport.FakeDataSource {
+	return testsupport.NewFakeDataSource().WithTable("letters",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}, {Name: "place"}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅", "place": "北京"},
+		map[string]interface{}{"id": float64(2), "sender": "胡适", "place": "上海"},
+		map[string]interface{}{"id": float64(3), "sender": "鲁迅", "place": "上海"},
+	)
+}
+
+func newTestService(t *testing.T) (*virtualbiz.Service, *testsupport.StaticRegistry) {
+	t.Helper()
+	registry := testsupport.NewStaticRegistry().RegisterPlugin("archive", newLettersSource())
+	svc, err := virtualbiz.NewService(testsupport.NewSystemDB(t), registry)
+	require.NoError(t, err)
+	return svc, registry
+}
+
+func shanghaiLetters() domain.VirtualBiz {
+	return domain.VirtualBiz{
+		BizName: "shanghai",
+		Tables: []domain.VirtualTable{{
+			Name: "letters", SourceBiz: "archive", SourceTable: "letters",
+			Filters: []domain.VirtualTableFilter{{Field: "place", Value: "上海"}},
+		}},
+	}
+}
+
+func TestNewServiceRequiresDB(t *testing.T) {
+	_, err := virtualbiz.NewService(nil, testsupport.NewStaticRegistry())
+	assert.Error(t, err)
+}
+
+func TestSaveRegistersVirtualBizAndAppliesFilters(t *testing.T) {
+	ctx := context.Background()
+	svc, registry := newTestService(t)
+	require.NoError(t, svc.Save(ctx, shanghaiLetters()))
+
+	ds, loaded := registry.LookupDataSource("shanghai")
+	require.True(t, loaded)
+	require.IsType(t, &virtual.DataSource{}, ds)
+
+	result, err := ds.Query(ctx, port.QueryRequest{BizName: "shanghai", Query: map[string]interface{}{
+		"table":   "letters",
+		"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), result.Data["total"], "固定过滤条件与用户过滤条件同时生效")
+
+	defs, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, shanghaiLetters().Tables, defs[0].Tables)
+}
+
+func TestSaveUpdatesExistingDefinition(t *testing.T) {
+	ctx := context.Background()
+	svc, registry := newTestService(t)
+	require.NoError(t, svc.Save(ctx, shanghaiLetters()))
+
+	def := shanghaiLetters()
+	def.Description = "全部信件"
+	def.Tables[0].Filters = nil
+	require.NoError(t, svc.Save(ctx, def))
+
+	defs, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "全部信件", defs[0].Description)
+	assert.Empty(t, defs[0].Tables[0].Filters)
+
+	ds, _ := registry.LookupDataSource("shanghai")
+	result, err := ds.Query(ctx, port.QueryRequest{BizName: "shanghai", Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), result.Data["total"], "更新后的定义立即生效")
+}
+
+func TestSaveRejectsInvalidDefinitions(t *testing.T) {
+	ctx := context.Background()
+	svc, registry := newTestService(t)
+	require.NoError(t, svc.Save(ctx, shanghaiLetters()))
+	require.NoError(t, registry.RegisterStaticDataSource("split", readsplit.New(domain.ReadSplitBiz{BizName: "split", Writer: "archive"}, registry.LookupDataSource)))
+
+	table := func(name, sourceBiz string) domain.VirtualTable {
+		return domain.VirtualTable{Name: name, SourceBiz: sourceBiz, SourceTable: "letters"}
+	}
+	cases := map[string]domain.VirtualBiz{
+		"缺少名称":      {Tables: []domain.VirtualTable{table("letters", "archive")}},
+		"没有表":       {BizName: "empty"},
+		"表缺少来源":     {BizName: "v", Tables: []domain.VirtualTable{{Name: "letters"}}},
+		"表名重复":      {BizName: "v", Tables: []domain.VirtualTable{table("letters", "archive"), table("letters", "archive")}},
+		"引用自身":      {BizName: "v", Tables: []domain.VirtualTable{table("letters", "v")}},
+		"引用虚拟业务组":   {BizName: "v", Tables: []domain.VirtualTable{table("letters", "shanghai")}},
+		"名称被读写分离占用": {BizName: "split", Tables: []domain.VirtualTable{table("letters", "archive")}},
+		"过滤条件缺少字段": {BizName: "v", Tables: []domain.VirtualTable{{
+			Name: "letters", SourceBiz: "archive", SourceTable: "letters",
+			Filters: []domain.VirtualTableFilter{{Value: "上海"}},
+		}}},
+	}
+	for name, def := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, svc.Save(ctx, def), virtualbiz.ErrInvalidDefinition)
+		})
+	}
+
+	defs, err := svc.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "被拒绝的定义不应被保存")
+	_, loaded := registry.LookupDataSource("v")
+	assert.False(t, loaded, "被拒绝的定义不应被注册")
+}
+
+func TestSaveRejectsNameServedByPlugin(t *testing.T) {
+	ctx := context.Background()
+	svc, registry := newTestService(t)
+	def := shanghaiLetters()
+	def.BizName = "archive"
+	def.Tables[0].SourceBiz = "other"
+
+	assert.ErrorIs(t, svc.Save(ctx, def), virtualbiz.ErrInvalidDefinition)
+	defs, err := svc.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, defs, "名称被插件占用时不应留下无法生效的定义")
+	ds, _ := registry.LookupDataSource("archive")
+	assert.IsType(t, &testsupport.FakeDataSource{}, ds, "插件的数据源不应被替换")
+}
+
+func TestDeleteUnregisters(t *testing.T) {
+	ctx := context.Background()
+	svc, registry := newTestService(t)
+	require.NoError(t, svc.Save(ctx, shanghaiLetters()))
+
+	require.NoError(t, svc.Delete(ctx, "shanghai"))
+	_, loaded := registry.LookupDataSource("shanghai")
+	assert.False(t, loaded)
+	_, loaded = registry.LookupDataSource("archive")
+	assert.True(t, loaded, "底层业务组不受影响")
+
+	assert.ErrorIs(t, svc.Delete(ctx, "shanghai"), virtualbiz.ErrVirtualBizNotFound)
+}
+
+func TestLoadAllRegistersSavedDefinitions(t *testing.T) {
+	ctx := context.Background()
+	db := testsupport.NewSystemDB(t)
+	first, err := virtualbiz.NewService(db, testsupport.NewStaticRegistry().RegisterPlugin("archive", newLettersSource()))
+	require.NoError(t, err)
+	require.NoError(t, first.Save(ctx, shanghaiLetters()))
+	blocked := shanghaiLetters()
+	blocked.BizName = "reclaimed"
+	require.NoError(t, first.Save(ctx, blocked))
+
+	// 重启后 reclaimed 已由插件提供，跳过它不影响其它定义的注册
+	registry := testsupport.NewStaticRegistry().
+		RegisterPlugin("archive", newLettersSource()).
+		RegisterPlugin("reclaimed", testsupport.NewFakeDataSource())
+	restarted, err := virtualbiz.NewService(db, registry)
+	require.NoError(t, err)
+	require.NoError(t, restarted.LoadAll(ctx))
+
+	ds, loaded := registry.LookupDataSource("shanghai")
+	require.True(t, loaded)
+	assert.IsType(t, &virtual.DataSource{}, ds)
+	ds, _ = registry.LookupDataSource("reclaimed")
+	assert.IsType(t, &testsupport.FakeDataSource{}, ds)
+}
@@ -0,0 +1,466 @@
+// Package vocabulary file: internal/service/vocabulary/vocabulary_service.go
+package vocabulary
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxEndpointBytes 是外部权威列表响应体的上限
+	maxEndpointBytes = 16 << 20
+	// maxTermRunes 是单个词条的值或名称的长度上限
+	maxTermRunes = 500
+	// defaultLookupLimit 与 maxLookupLimit 是词表查询每次返回的默认与最大词条数
+	defaultLookupLimit = 50
+	maxLookupLimit     = 500
+)
+
+var (
+	// ErrVocabularyNotFound 表示字段未绑定受控词表
+	ErrVocabularyNotFound = errors.New("字段未绑定受控词表")
+	// ErrInvalidVocabulary 表示受控词表的内容不合法，或字段未在业务组中配置
+	ErrInvalidVocabulary = errors.New("无效的受控词表")
+	// ErrValueNotInVocabulary 表示写入严格模式字段的值不在受控词表中
+	ErrValueNotInVocabulary = errors.New("取值不在受控词表中")
+	// ErrVocabularyUnavailable 表示外部权威列表无法访问且没有可用的缓存
+	ErrVocabularyUnavailable = errors.New("受控词表暂时不可用")
+)
+
+// Options 定义受控词表的配置
+type Options struct {
+	// MaxTerms 是单个词表的词条数上限，默认为 50000
+	MaxTerms int `mapstructure:"max_terms"`
+	// EndpointTimeoutSeconds 是拉取外部权威列表的超时 (秒)，默认为 10
+	EndpointTimeoutSeconds int `mapstructure:"endpoint_timeout_seconds"`
+	// EndpointCacheMinutes 是外部权威列表的缓存时长 (分钟)，默认为 10。
+	// 缓存过期后拉取失败时继续使用旧的词条，避免外部服务故障阻塞写入
+	EndpointCacheMinutes int `mapstructure:"endpoint_cache_minutes"`
+}
+
+// Service 维护字段与受控词表 (权威列表) 的绑定，为界面的下拉选项提供词条查询，
+// 并在严格模式下校验 Mutate 写入的值。绑定直接读写数据库，快照恢复后无需刷新；
+// 只有外部权威列表的词条缓存在内存中
+type Service struct {
+	db            *sql.DB
+	configService port.QueryAdminConfigService
+	opts          Options
+	client        *http.Client
+	now           func() time.Time
+
+	mu       sync.Mutex
+	fetched  map[string]*endpointTerms
+	fetching map[string]*sync.Mutex
+}
+
+// endpointTerms 是外部权威列表的一次拉取结果
+type endpointTerms struct {
+	url       string
+	terms     []domain.VocabularyTerm
+	fetchedAt time.Time
+}
+
+// NewService 创建一个新的受控词表服务实例
+func NewService(db *sql.DB, configService port.QueryAdminConfigService, opts Options) (*Service, error) {
+	if db == nil {
+		return nil, errors.New("vocabulary.Service 需要一个有效的数据库连接")
+	}
+	if configService == nil {
+		return nil, errors.New("vocabulary.Service 需要有效的配置服务")
+	}
+	if opts.MaxTerms <= 0 {
+		opts.MaxTerms = 50000
+	}
+	if opts.EndpointTimeoutSeconds <= 0 {
+		opts.EndpointTimeoutSeconds = 10
+	}
+	if opts.EndpointCacheMinutes <= 0 {
+		opts.EndpointCacheMinutes = 10
+	}
+	return &Service{
+		db:            db,
+		configService: configService,
+		opts:          opts,
+		client:        &http.Client{Timeout: time.Duration(opts.EndpointTimeoutSeconds) * time.Second},
+		now:           time.Now,
+		fetched:       make(map[string]*endpointTerms),
+		fetching:      make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// List 返回业务组的词表绑定，tableName 不为空时只返回该表的字段。上传的词条不随列表返回
+func (s *Service) List(ctx context.Context, bizName, tableName string) ([]domain.FieldVocabulary, error) {
+	query := `SELECT biz_name, table_name, field_name, kind, endpoint_url, strict, updated_by, updated_at
+		FROM biz_field_vocabularies WHERE biz_name = ?`
+	args := []interface{}{bizName}
+	if tableName != "" {
+		query += ` AND table_name = ?`
+		args = append(args, tableName)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY table_name, field_name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询受控词表失败: %w", err)
+	}
+	defer rows.Close()
+	vocabularies := []domain.FieldVocabulary{}
+	for rows.Next() {
+		var v domain.FieldVocabulary
+		if err := rows.Scan(&v.BizName, &v.TableName, &v.FieldName, &v.Kind, &v.EndpointURL, &v.Strict,
+			&v.UpdatedBy, &v.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描受控词表失败: %w", err)
+		}
+		vocabularies = append(vocabularies, v)
+	}
+	return vocabularies, rows.Err()
+}
+
+// Get 返回字段绑定的受控词表，包含上传的词条
+func (s *Service) Get(ctx context.Context, bizName, tableName, fieldName string) (*domain.FieldVocabulary, error) {
+	v := domain.FieldVocabulary{BizName: bizName, TableName: tableName, FieldName: fieldName}
+	var termsJSON string
+	err := s.db.QueryRowContext(ctx, `SELECT kind, terms_json, endpoint_url, strict, updated_by, updated_at
+		FROM biz_field_vocabularies WHERE biz_name = ? AND table_name = ? AND field_name = ?`,
+		bizName, tableName, fieldName).Scan(&v.Kind, &termsJSON, &v.EndpointURL, &v.Strict, &v.UpdatedBy, &v.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVocabularyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询受控词表失败: %w", err)
+	}
+	if err := json.Unmarshal([]byte(termsJSON), &v.Terms); err != nil {
+		return nil, fmt.Errorf("解析受控词表 '%s.%s' 的词条失败: %w", tableName, fieldName, err)
+	}
+	return &v, nil
+}
+
+// Save 创建或替换字段的受控词表。字段必须已在业务组的字段设置中配置；
+// 外部权威列表在保存前会试拉取一次，确保地址可用且格式正确
+func (s *Service) Save(ctx context.Context, v domain.FieldVocabulary, userID int64) (*domain.FieldVocabulary, error) {
+	v.Kind = strings.ToLower(strings.TrimSpace(v.Kind))
+	v.EndpointURL = strings.TrimSpace(v.EndpointURL)
+	if v.Kind == "" {
+		v.Kind = domain.VocabularyKindList
+		if v.EndpointURL != "" {
+			v.Kind = domain.VocabularyKindEndpoint
+		}
+	}
+	switch v.Kind {
+	case domain.VocabularyKindList:
+		if v.EndpointURL != "" {
+			return nil, fmt.Errorf("%w: 上传的词表不能同时指定 endpoint_url", ErrInvalidVocabulary)
+		}
+		terms, err := s.normalizeTerms(v.Terms)
+		if err != nil {
+			return nil, err
+		}
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("%w: 词表至少需要一个词条", ErrInvalidVocabulary)
+		}
+		v.Terms = terms
+	case domain.VocabularyKindEndpoint:
+		if len(v.Terms) > 0 {
+			return nil, fmt.Errorf("%w: 外部权威列表不能同时上传词条", ErrInvalidVocabulary)
+		}
+		u, err := url.Parse(v.EndpointURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: endpoint_url 必须是 http 或 https 地址", ErrInvalidVocabulary)
+		}
+	default:
+		return nil, fmt.Errorf("%w: 未知的词表类型 '%s'，可选 list 或 endpoint", ErrInvalidVocabulary, v.Kind)
+	}
+
+	cfg, err := s.configService.GetBizQueryConfig(ctx, v.BizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	if _, ok := configuredField(cfg, v.TableName, v.FieldName); !ok {
+		return nil, fmt.Errorf("%w: 业务组 '%s' 的表 '%s' 中未配置字段 '%s'", ErrInvalidVocabulary, v.BizName, v.TableName, v.FieldName)
+	}
+
+	key := cacheKey(v.BizName, v.TableName, v.FieldName)
+	if v.Kind == domain.VocabularyKindEndpoint {
+		terms, err := s.fetchEndpoint(ctx, v.EndpointURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 拉取外部权威列表失败: %v", ErrInvalidVocabulary, err)
+		}
+		s.mu.Lock()
+		s.fetched[key] = &endpointTerms{url: v.EndpointURL, terms: terms, fetchedAt: s.now()}
+		s.mu.Unlock()
+	} else {
+		s.forget(key)
+	}
+
+	termsJSON, err := json.Marshal(v.Terms)
+	if err != nil {
+		return nil, err
+	}
+	if v.Terms == nil {
+		termsJSON = []byte("[]")
+	}
+	v.UpdatedBy, v.UpdatedAt = userID, s.now().UTC()
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO biz_field_vocabularies
+		(biz_name, table_name, field_name, kind, terms_json, endpoint_url, strict, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(biz_name, table_name, field_name) DO UPDATE SET
+			kind = excluded.kind, terms_json = excluded.terms_json, endpoint_url = excluded.endpoint_url,
+			strict = excluded.strict, updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		v.BizName, v.TableName, v.FieldName, v.Kind, string(termsJSON), v.EndpointURL, v.Strict, v.UpdatedBy, v.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("保存受控词表失败: %w", err)
+	}
+	return &v, nil
+}
+
+// Delete 解除字段与受控词表的绑定
+func (s *Service) Delete(ctx context.Context, bizName, tableName, fieldName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM biz_field_vocabularies WHERE biz_name = ? AND table_name = ? AND field_name = ?`,
+		bizName, tableName, fieldName)
+	if err != nil {
+		return fmt.Errorf("删除受控词表失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrVocabularyNotFound
+	}
+	s.forget(cacheKey(bizName, tableName, fieldName))
+	return nil
+}
+
+// Lookup 按 q 在词条的值与名称中不区分大小写地查找，返回至多 limit 个词条及字段是否为严格模式，
+// 供界面的下拉选项使用。只有可检索或可返回的字段公开词表
+func (s *Service) Lookup(ctx context.Context, bizName, tableName, fieldName, q string, limit int) ([]domain.VocabularyTerm, bool, error) {
+	cfg, err := s.configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, false, err
+	}
+	if cfg == nil {
+		return nil, false, port.ErrBizNotFound
+	}
+	if field, ok := configuredField(cfg, tableName, fieldName); !ok || !(field.IsSearchable || field.IsReturnable) {
+		return nil, false, ErrVocabularyNotFound
+	}
+	v, err := s.Get(ctx, bizName, tableName, fieldName)
+	if err != nil {
+		return nil, false, err
+	}
+	terms, err := s.terms(ctx, v)
+	if err != nil {
+		return nil, false, err
+	}
+	if limit <= 0 {
+		limit = defaultLookupLimit
+	}
+	limit = min(limit, maxLookupLimit)
+	q = strings.ToLower(strings.TrimSpace(q))
+	matched := []domain.VocabularyTerm{}
+	for _, t := range terms {
+		if len(matched) >= limit {
+			break
+		}
+		if q == "" || strings.Contains(strings.ToLower(t.Value), q) || strings.Contains(strings.ToLower(t.Label), q) {
+			matched = append(matched, t)
+		}
+	}
+	return matched, v.Strict, nil
+}
+
+// ValidateMutate 校验 create 或 update 写入的数据：严格模式字段的值必须是词表中某个词条的值，
+// null 表示清空，不做校验
+func (s *Service) ValidateMutate(ctx context.Context, bizName, tableName string, data map[string]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT field_name FROM biz_field_vocabularies
+		WHERE biz_name = ? AND table_name = ? AND strict = 1`, bizName, tableName)
+	if err != nil {
+		return fmt.Errorf("查询受控词表失败: %w", err)
+	}
+	var strictFields []string
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&field); err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描受控词表失败: %w", err)
+		}
+		strictFields = append(strictFields, field)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, field := range strictFields {
+		value, present := data[field]
+		if !present || value == nil {
+			continue
+		}
+		v, err := s.Get(ctx, bizName, tableName, field)
+		if err != nil {
+			return err
+		}
+		terms, err := s.terms(ctx, v)
+		if err != nil {
+			return err
+		}
+		text := fmt.Sprint(value)
+		found := false
+		for _, t := range terms {
+			if t.Value == text {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: 字段 '%s' 的取值 '%s'", ErrValueNotInVocabulary, field, text)
+		}
+	}
+	return nil
+}
+
+// terms 返回词表的词条：上传的词表直接返回，外部权威列表使用缓存，过期后重新拉取
+func (s *Service) terms(ctx context.Context, v *domain.FieldVocabulary) ([]domain.VocabularyTerm, error) {
+	if v.Kind != domain.VocabularyKindEndpoint {
+		return v.Terms, nil
+	}
+	key := cacheKey(v.BizName, v.TableName, v.FieldName)
+	ttl := time.Duration(s.opts.EndpointCacheMinutes) * time.Minute
+
+	s.mu.Lock()
+	lock, ok := s.fetching[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.fetching[key] = lock
+	}
+	s.mu.Unlock()
+	// 同一词表同时只拉取一次，其余请求等待并使用拉取的结果
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.Lock()
+	cached := s.fetched[key]
+	s.mu.Unlock()
+	if cached != nil && cached.url == v.EndpointURL && s.now().Sub(cached.fetchedAt) < ttl {
+		return cached.terms, nil
+	}
+	terms, err := s.fetchEndpoint(ctx, v.EndpointURL)
+	if err != nil {
+		if cached != nil && cached.url == v.EndpointURL {
+			slog.Warn("[Vocabulary] 拉取外部权威列表失败，继续使用缓存的词条",
+				"biz", v.BizName, "table", v.TableName, "field", v.FieldName, "error", err)
+			// 下一个缓存周期再重试，避免外部服务故障期间每次写入都发起请求
+			s.mu.Lock()
+			s.fetched[key] = &endpointTerms{url: cached.url, terms: cached.terms, fetchedAt: s.now()}
+			s.mu.Unlock()
+			return cached.terms, nil
+		}
+		return nil, fmt.Errorf("%w: 字段 '%s' 的外部权威列表: %v", ErrVocabularyUnavailable, v.FieldName, err)
+	}
+	s.mu.Lock()
+	s.fetched[key] = &endpointTerms{url: v.EndpointURL, terms: terms, fetchedAt: s.now()}
+	s.mu.Unlock()
+	return terms, nil
+}
+
+// fetchEndpoint 拉取外部权威列表并规范化其词条
+func (s *Service) fetchEndpoint(ctx context.Context, endpoint string) ([]domain.VocabularyTerm, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("返回状态码 %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEndpointBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxEndpointBytes {
+		return nil, fmt.Errorf("响应超过 %d 字节", maxEndpointBytes)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		var wrapped struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil || wrapped.Data == nil {
+			return nil, errors.New("响应必须是词条数组或 {\"data\": [...]}")
+		}
+		items = wrapped.Data
+	}
+	terms := make([]domain.VocabularyTerm, 0, len(items))
+	for _, item := range items {
+		var t domain.VocabularyTerm
+		var value string
+		if err := json.Unmarshal(item, &value); err == nil {
+			t.Value = value
+		} else if err := json.Unmarshal(item, &t); err != nil {
+			return nil, errors.New("词条必须是字符串或 {\"value\", \"label\"} 对象")
+		}
+		terms = append(terms, t)
+	}
+	return s.normalizeTerms(terms)
+}
+
+// normalizeTerms 去除词条两端的空白并检查长度、重复与数量
+func (s *Service) normalizeTerms(terms []domain.VocabularyTerm) ([]domain.VocabularyTerm, error) {
+	if len(terms) > s.opts.MaxTerms {
+		return nil, fmt.Errorf("%w: 词条数不能超过 %d", ErrInvalidVocabulary, s.opts.MaxTerms)
+	}
+	seen := make(map[string]bool, len(terms))
+	normalized := make([]domain.VocabularyTerm, 0, len(terms))
+	for _, t := range terms {
+		t.Value, t.Label = strings.TrimSpace(t.Value), strings.TrimSpace(t.Label)
+		if t.Value == "" {
+			return nil, fmt.Errorf("%w: 词条的值不能为空", ErrInvalidVocabulary)
+		}
+		if utf8.RuneCountInString(t.Value) > maxTermRunes || utf8.RuneCountInString(t.Label) > maxTermRunes {
+			return nil, fmt.Errorf("%w: 词条 '%s' 超过 %d 个字符", ErrInvalidVocabulary, t.Value, maxTermRunes)
+		}
+		if seen[t.Value] {
+			return nil, fmt.Errorf("%w: 词条 '%s' 重复", ErrInvalidVocabulary, t.Value)
+		}
+		seen[t.Value] = true
+		normalized = append(normalized, t)
+	}
+	return normalized, nil
+}
+
+func (s *Service) forget(key string) {
+	s.mu.Lock()
+	delete(s.fetched, key)
+	s.mu.Unlock()
+}
+
+func cacheKey(bizName, tableName, fieldName string) string {
+	return bizName + "\x00" + tableName + "\x00" + fieldName
+}
+
+func configuredField(cfg *domain.BizQueryConfig, tableName, fieldName string) (domain.FieldSetting, bool) {
+	table, ok := cfg.Tables[tableName]
+	if !ok || table == nil {
+		return domain.FieldSetting{}, false
+	}
+	field, ok := table.Fields[fieldName]
+	return field, ok
+}
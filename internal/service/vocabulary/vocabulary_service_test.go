@@ -0,0 +1,114 @@
+// file: internal/service/vocabulary/vocabulary_service_test.go
+package vocabulary
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	db := fixture.NewSystemDB(t)
+	cfg, err := admin_config.NewAdminConfigServiceImpl(db, 100, time.Minute)
+	require.NoError(t, err)
+	public := true
+	require.NoError(t, cfg.UpdateBizOverallSettings(ctx, "library", domain.BizOverallSettings{IsPubliclySearchable: &public}))
+	require.NoError(t, cfg.UpdateBizSearchableTables(ctx, "library", []string{"books"}))
+	require.NoError(t, cfg.UpdateTableFieldSettings(ctx, "library", "books", []domain.FieldSetting{
+		{FieldName: "genre", IsSearchable: true, IsReturnable: true},
+		{FieldName: "subject", IsReturnable: true},
+		{FieldName: "internal_code"},
+	}))
+	svc, err := NewService(db, cfg, Options{})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestVocabulary_ListStrictValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	_, err := svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "missing",
+		Terms: []domain.VocabularyTerm{{Value: "诗歌"}}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidVocabulary, "未配置的字段不能绑定词表")
+	_, err = svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "genre",
+		Terms: []domain.VocabularyTerm{{Value: "诗歌"}, {Value: " 诗歌 "}}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidVocabulary, "去除空白后重复的词条被拒绝")
+
+	saved, err := svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "genre", Strict: true,
+		Terms: []domain.VocabularyTerm{{Value: "poetry", Label: "诗歌"}, {Value: "novel", Label: "小说"}}}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VocabularyKindList, saved.Kind)
+
+	terms, strict, err := svc.Lookup(ctx, "library", "books", "genre", "小说", 0)
+	require.NoError(t, err)
+	assert.True(t, strict)
+	assert.Equal(t, []domain.VocabularyTerm{{Value: "novel", Label: "小说"}}, terms, "按名称查找")
+
+	require.NoError(t, svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"genre": "poetry", "title": "任意"}))
+	require.NoError(t, svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"genre": nil}), "null 表示清空")
+	err = svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"genre": "诗歌"})
+	assert.ErrorIs(t, err, ErrValueNotInVocabulary, "只接受词条的值，不接受名称")
+
+	_, err = svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "internal_code",
+		Terms: []domain.VocabularyTerm{{Value: "A"}}}, 1)
+	require.NoError(t, err)
+	_, _, err = svc.Lookup(ctx, "library", "books", "internal_code", "", 0)
+	assert.ErrorIs(t, err, ErrVocabularyNotFound, "既不可检索也不可返回的字段不公开词表")
+
+	require.NoError(t, svc.Delete(ctx, "library", "books", "genre"))
+	assert.ErrorIs(t, svc.Delete(ctx, "library", "books", "genre"), ErrVocabularyNotFound)
+	require.NoError(t, svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"genre": "诗歌"}))
+}
+
+func TestVocabulary_EndpointCachedAndStale(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	var calls atomic.Int32
+	var failing atomic.Bool
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": ["history", {"value": "art", "label": "艺术"}]}`))
+	}))
+	defer endpoint.Close()
+
+	_, err := svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "subject",
+		EndpointURL: "ftp://example.org/list"}, 1)
+	assert.ErrorIs(t, err, ErrInvalidVocabulary)
+	saved, err := svc.Save(ctx, domain.FieldVocabulary{BizName: "library", TableName: "books", FieldName: "subject",
+		EndpointURL: endpoint.URL, Strict: true}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VocabularyKindEndpoint, saved.Kind)
+	assert.Equal(t, int32(1), calls.Load(), "保存时试拉取一次")
+
+	terms, _, err := svc.Lookup(ctx, "library", "books", "subject", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.VocabularyTerm{{Value: "history"}, {Value: "art", Label: "艺术"}}, terms)
+	require.NoError(t, svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"subject": "art"}))
+	assert.Equal(t, int32(1), calls.Load(), "缓存未过期时不再拉取")
+
+	failing.Store(true)
+	svc.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"subject": "history"}),
+		"拉取失败时使用旧的词条")
+	assert.Equal(t, int32(2), calls.Load())
+
+	svc.forget(cacheKey("library", "books", "subject"))
+	err = svc.ValidateMutate(ctx, "library", "books", map[string]interface{}{"subject": "history"})
+	assert.ErrorIs(t, err, ErrVocabularyUnavailable, "没有缓存时拒绝写入")
+}
@@ -0,0 +1,130 @@
+// Package warmup file: internal/service/warmup/warmup_service.go
+package warmup
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultTimeoutSeconds = 30
+
+// PinnedQuery 是业务组注册后预先执行的一条检索，query 与 /data/query 请求体中的 query 格式相同
+type PinnedQuery struct {
+	BizName string                 `mapstructure:"biz"`
+	Query   map[string]interface{} `mapstructure:"query"`
+}
+
+// Options 定义预热阶段的行为
+type Options struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TimeoutSeconds 是单个业务组预热的总时长上限，默认为 30 秒
+	TimeoutSeconds int           `mapstructure:"timeout_seconds"`
+	Queries        []PinnedQuery `mapstructure:"queries"`
+}
+
+// Status 是业务组最近一次预热的结果
+type Status struct {
+	BizName    string    `json:"biz_name"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Queries    int       `json:"queries"`
+	Errors     []string  `json:"errors,omitempty"`
+}
+
+// Service 在插件实例注册到网关后预热业务组：读取业务组配置以填充配置缓存，读取表结构，
+// 并执行为该业务组固定的检索，使重启后的第一个用户请求不必承担冷启动的开销
+type Service struct {
+	opts          Options
+	configService port.QueryAdminConfigService
+	queries       map[string][]json.RawMessage
+
+	mu     sync.Mutex
+	status map[string]Status
+}
+
+// NewService 创建一个新的预热服务实例
+func NewService(configService port.QueryAdminConfigService, opts Options) (*Service, error) {
+	if configService == nil {
+		return nil, errors.New("warmup.Service 需要有效的配置服务")
+	}
+	if opts.TimeoutSeconds < 0 {
+		return nil, fmt.Errorf("timeout_seconds 不能为负数")
+	}
+	if opts.TimeoutSeconds == 0 {
+		opts.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	// 配置文件中的数字解析为整数，经 JSON 转换后与 API 请求中的查询一样为 float64
+	queries := make(map[string][]json.RawMessage)
+	for i, q := range opts.Queries {
+		if q.BizName == "" || len(q.Query) == 0 {
+			return nil, fmt.Errorf("第 %d 条预热检索缺少 biz 或 query", i+1)
+		}
+		raw, err := json.Marshal(q.Query)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 条预热检索无法序列化: %w", i+1, err)
+		}
+		queries[q.BizName] = append(queries[q.BizName], raw)
+	}
+	return &Service{opts: opts, configService: configService, queries: queries, status: make(map[string]Status)}, nil
+}
+
+// Enabled 报告是否启用了预热
+func (s *Service) Enabled() bool {
+	return s.opts.Enabled
+}
+
+// Warm 预热一个刚注册的业务组。各步骤的失败只记录在状态中，不影响业务组的服务
+func (s *Service) Warm(bizName string, ds port.DataSource) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.opts.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	st := Status{BizName: bizName, StartedAt: time.Now().UTC()}
+	if _, err := s.configService.GetBizQueryConfig(ctx, bizName); err != nil {
+		st.Errors = append(st.Errors, fmt.Sprintf("读取业务组配置失败: %v", err))
+	}
+	if _, err := ds.GetSchema(ctx, port.SchemaRequest{BizName: bizName}); err != nil {
+		st.Errors = append(st.Errors, fmt.Sprintf("读取表结构失败: %v", err))
+	}
+	for i, raw := range s.queries[bizName] {
+		// 每次执行都重新解码，数据源可能修改收到的查询
+		var query map[string]interface{}
+		if err := json.Unmarshal(raw, &query); err != nil {
+			st.Errors = append(st.Errors, fmt.Sprintf("第 %d 条预热检索无法解析: %v", i+1, err))
+			continue
+		}
+		if _, err := ds.Query(ctx, port.QueryRequest{BizName: bizName, Query: query}); err != nil {
+			st.Errors = append(st.Errors, fmt.Sprintf("第 %d 条预热检索失败: %v", i+1, err))
+			continue
+		}
+		st.Queries++
+	}
+	st.DurationMs = time.Since(st.StartedAt).Milliseconds()
+
+	s.mu.Lock()
+	s.status[bizName] = st
+	s.mu.Unlock()
+	if len(st.Errors) > 0 {
+		slog.Warn("[Warmup] 业务组预热未完全成功", "biz", bizName, "duration_ms", st.DurationMs, "errors", st.Errors)
+		return
+	}
+	slog.Info("[Warmup] 业务组预热完成", "biz", bizName, "duration_ms", st.DurationMs, "queries", st.Queries)
+}
+
+// Status 返回各业务组最近一次预热的结果，按业务组名排序
+func (s *Service) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.status))
+	for _, st := range s.status {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].BizName < out[j].BizName })
+	return out
+}
@@ -0,0 +1,67 @@
+// file: internal/service/warmup/warmup_service_test.go
+package warmup
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubConfig struct {
+	port.QueryAdminConfigService
+	reads []string
+}
+
+func (s *stubConfig) GetBizQueryConfig(_ context.Context, bizName string) (*domain.BizQueryConfig, error) {
+	s.reads = append(s.reads, bizName)
+	return &domain.BizQueryConfig{BizName: bizName}, nil
+}
+
+type stubDataSource struct {
+	port.DataSource
+	schemaReads int
+	queries     []map[string]interface{}
+}
+
+func (s *stubDataSource) GetSchema(context.Context, port.SchemaRequest) (*port.SchemaResult, error) {
+	s.schemaReads++
+	return &port.SchemaResult{}, nil
+}
+
+func (s *stubDataSource) Query(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	s.queries = append(s.queries, req.Query)
+	if req.Query["table"] == "missing" {
+		return nil, errors.New("表不存在")
+	}
+	return &port.QueryResult{}, nil
+}
+
+func TestWarm(t *testing.T) {
+	config := &stubConfig{}
+	svc, err := NewService(config, Options{Enabled: true, Queries: []PinnedQuery{
+		{BizName: "archive", Query: map[string]interface{}{"table": "letters", "size": 20}},
+		{BizName: "archive", Query: map[string]interface{}{"table": "missing"}},
+		{BizName: "maps", Query: map[string]interface{}{"table": "sheets"}},
+	}})
+	require.NoError(t, err)
+
+	ds := &stubDataSource{}
+	svc.Warm("archive", ds)
+	assert.Equal(t, []string{"archive"}, config.reads, "预热读取业务组配置以填充缓存")
+	assert.Equal(t, 1, ds.schemaReads)
+	require.Len(t, ds.queries, 2, "只执行为该业务组固定的检索")
+	assert.Equal(t, float64(20), ds.queries[0]["size"], "配置文件中的整数与 API 请求一样转换为 float64")
+
+	status := svc.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, 1, status[0].Queries)
+	assert.Len(t, status[0].Errors, 1)
+
+	_, err = NewService(config, Options{Queries: []PinnedQuery{{BizName: "archive"}}})
+	assert.Error(t, err, "缺少 query 的预热检索无效")
+}
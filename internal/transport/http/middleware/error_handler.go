@@ -38,6 +38,18 @@ func ErrorHandlingMiddleware() gin.HandlerFunc {
 		case errors.Is(err, port.ErrBizNotFound), errors.Is(err, port.ErrTableNotFoundInBiz):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 
+		case errors.Is(err, port.ErrInvalidIdentifier):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		case errors.Is(err, port.ErrInsufficientStorage):
+			c.JSON(http.StatusInsufficientStorage, gin.H{"error": err.Error()})
+
+		case errors.Is(err, port.ErrChangeCaptureUnsupported):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+
+		case errors.Is(err, port.ErrAdminMutateUnsupported):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+
 		default:
 			// 对于所有其他未知错误，返回 500 服务器内部错误
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "服务器内部错误"})
@@ -0,0 +1,165 @@
+// Package router file: internal/transport/http/router/abuse_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/abuse"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// abuseDetection 把请求结果交给异常行为检测，需在 authMiddleware 之后使用。
+// 数据查询会预先读取请求体中的 query.page 与 query.size；403 既可能由处理器直接写出，
+// 也可能是交给错误中间件的 ErrPermissionDenied，后者在此时尚未写出响应
+func abuseDetection(detector *abuse.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector == nil {
+			c.Next()
+			return
+		}
+		var page, size int
+		if c.Request.Method == http.MethodPost && strings.HasSuffix(c.FullPath(), "/data/query") && c.Request.Body != nil {
+			full, err := io.ReadAll(c.Request.Body)
+			c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(full))
+			if err == nil {
+				page, size = queryPaging(full)
+			}
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			if !errors.Is(c.Errors.Last().Err, port.ErrPermissionDenied) {
+				return
+			}
+			status = http.StatusForbidden
+		}
+		obs := abuse.Observation{IP: c.ClientIP(), UserID: requestUserID(c), Status: status, Page: page, Size: size}
+		if _, err := detector.Observe(c.Request.Context(), obs); err != nil {
+			slog.Error("[Abuse] 异常行为检测失败", "error", err)
+		}
+	}
+}
+
+// queryPaging 从查询请求体中取出页码与每页条数，缺省时页码为 1
+func queryPaging(body []byte) (page, size int) {
+	var req struct {
+		Query struct {
+			Page float64 `json:"page"`
+			Size float64 `json:"size"`
+		} `json:"query"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 0, 0
+	}
+	page = int(req.Query.Page)
+	if page <= 0 {
+		page = 1
+	}
+	return page, int(req.Query.Size)
+}
+
+// respondAbuseError 把豁免名单的错误映射为 400/404，其余交给错误中间件
+func respondAbuseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, abuse.ErrInvalidEntry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, abuse.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listAbuseFlagsHandler 按时间倒序列出异常行为判定记录，可用查询参数 limit 指定条数
+func listAbuseFlagsHandler(detector *abuse.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "异常行为检测未启用"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		flags, err := detector.ListFlags(c.Request.Context(), limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": flags})
+	}
+}
+
+// listAbuseAllowlistHandler 列出管理员添加的豁免对象
+func listAbuseAllowlistHandler(detector *abuse.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "异常行为检测未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		entries, err := detector.ListAllowlist(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, entries, page)
+	}
+}
+
+// addAbuseAllowlistHandler 添加豁免对象，请求体为 {"kind": "ip", "subject": "10.0.0.0/8", "note": "..."}。
+// 豁免不会解除已生效的封禁，需要时通过 DELETE /admin/security/penalties/:kind 解除
+func addAbuseAllowlistHandler(detector *abuse.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "异常行为检测未启用"})
+			return
+		}
+		var body struct {
+			Kind    string `json:"kind" binding:"required"`
+			Subject string `json:"subject" binding:"required"`
+			Note    string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须包含 kind 与 subject"})
+			return
+		}
+		entry, err := detector.Allow(c.Request.Context(), body.Kind, body.Subject, body.Note, requestUserID(c))
+		if err != nil {
+			respondAbuseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": entry})
+	}
+}
+
+// removeAbuseAllowlistHandler 移除豁免对象，对象由查询参数 subject 指定
+func removeAbuseAllowlistHandler(detector *abuse.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if detector == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "异常行为检测未启用"})
+			return
+		}
+		subject := c.Query("subject")
+		if subject == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少查询参数 'subject'"})
+			return
+		}
+		if err := detector.Disallow(c.Request.Context(), c.Param("kind"), subject); err != nil {
+			respondAbuseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "已移除豁免对象"})
+	}
+}
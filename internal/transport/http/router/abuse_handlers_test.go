@@ -0,0 +1,84 @@
+// file: internal/transport/http/router/abuse_handlers_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/abuse"
+	"ArchiveAegis/internal/service/penalties"
+	"ArchiveAegis/internal/transport/http/middleware"
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbuseDetection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := fixture.NewSystemDB(t)
+	box, err := penalties.NewService(db, penalties.Options{})
+	require.NoError(t, err)
+	detector, err := abuse.NewService(db, box, abuse.Options{ScrapePageSize: 50, ScrapeMaxPages: 3, ForbiddenThreshold: 2})
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandlingMiddleware())
+	group := engine.Group("/api/v1/data", func(c *gin.Context) {
+		if c.GetHeader("X-User") != "" {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), service.ClaimKey, &service.Claim{ID: 3, Role: "user"}))
+		}
+		c.Next()
+	}, abuseDetection(detector))
+	group.POST("/query", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": []any{}}) })
+	group.GET("/denied", func(c *gin.Context) { _ = c.Error(port.ErrPermissionDenied) })
+
+	serve := func(method, path, body string, user bool) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.5:1234"
+		if user {
+			req.Header.Set("X-User", "1")
+		}
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for page := 1; page <= 3; page++ {
+		body := fmt.Sprintf(`{"biz_name":"archive","query":{"table":"letters","page":%d,"size":50}}`, page)
+		require.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/data/query", body, true))
+	}
+	_, banned := box.Banned(domain.PenaltyKindUser, "3")
+	assert.True(t, banned, "以最大分页逐页读取判定为抓取")
+
+	// 交给错误中间件的 ErrPermissionDenied 在检测时尚未写出，同样按 403 计数
+	require.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/data/denied", "", false))
+	require.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/data/denied", "", false))
+	_, banned = box.Banned(domain.PenaltyKindIP, "10.0.0.5")
+	assert.True(t, banned)
+
+	flags, err := detector.ListFlags(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, domain.AbuseReasonForbidden, flags[0].Reason)
+	assert.Equal(t, domain.AbuseReasonScraping, flags[1].Reason)
+}
+
+func TestQueryPaging(t *testing.T) {
+	page, size := queryPaging([]byte(`{"query":{"page":4,"size":200}}`))
+	assert.Equal(t, 4, page)
+	assert.Equal(t, 200, size)
+	page, size = queryPaging([]byte(`{"query":{"table":"letters"}}`))
+	assert.Equal(t, 1, page, "缺省页码为 1")
+	assert.Zero(t, size)
+	page, size = queryPaging([]byte(`not json`))
+	assert.Zero(t, page)
+	assert.Zero(t, size)
+}
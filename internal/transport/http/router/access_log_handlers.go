@@ -0,0 +1,78 @@
+// Package router file: internal/transport/http/router/access_log_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/accesslog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessViewer 返回记录访问日志中的访问者
+func accessViewer(c *gin.Context) accesslog.Viewer {
+	viewer := accesslog.Viewer{IP: c.ClientIP()}
+	if claims := service.ClaimFrom(c.Request); claims != nil {
+		id := claims.ID
+		viewer.UserID = &id
+	}
+	return viewer
+}
+
+// logRecordAccess 记录敏感业务组的检索结果中被查看的记录。写入失败时已写入错误响应并返回 false，数据不应返回
+func logRecordAccess(c *gin.Context, accessLog *accesslog.Service, bizName, tableName string, data map[string]interface{}) bool {
+	if accessLog == nil {
+		return true
+	}
+	if err := accessLog.RecordResult(c.Request.Context(), bizName, tableName, data, accessViewer(c)); err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	return true
+}
+
+// listRecordAccessHandler 查询敏感业务组的记录访问日志，可按 biz、table、record_id、user_id 与时间范围 (since/until，RFC 3339) 过滤
+func listRecordAccessHandler(accessLog *accesslog.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if accessLog == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录访问日志不可用"})
+			return
+		}
+		filter := accesslog.Filter{
+			BizName:   c.Query("biz"),
+			TableName: c.Query("table"),
+			RecordID:  c.Query("record_id"),
+		}
+		if raw := c.Query("user_id"); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "无效的 user_id"})
+				return
+			}
+			filter.UserID = id
+		}
+		for name, target := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+			if raw := c.Query(name); raw != "" {
+				t, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "参数 '" + name + "' 应为 RFC 3339 时间"})
+					return
+				}
+				*target = &t
+			}
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		filter.Limit, filter.Offset = page.Size, page.offset()
+		items, total, err := accessLog.List(c.Request.Context(), filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondPage(c, items, total, page)
+	}
+}
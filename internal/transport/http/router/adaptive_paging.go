@@ -0,0 +1,125 @@
+// Package router file: internal/transport/http/router/adaptive_paging.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+const (
+	// MaxBytesHeader 是客户端声明的单次检索结果字节数上限，只能调低业务组的上限，供慢速或按流量计费的客户端使用
+	MaxBytesHeader = "X-Aegis-Max-Bytes"
+
+	// defaultMinAdaptiveBytes 是按客户端速度调低后的字节数上限的下限
+	defaultMinAdaptiveBytes = 64 << 10
+	// minThroughputSample 是参与测速的最小响应大小；更小的响应写入套接字缓冲区即返回，耗时不反映客户端的速度
+	minThroughputSample = 256 << 10
+	// throughputSmoothing 是下载速度滑动平均中新样本的权重
+	throughputSmoothing = 0.3
+	maxTrackedClients   = 10000
+	throughputTTL       = 10 * time.Minute
+)
+
+// AdaptivePagingOptions 控制网关按客户端的下载速度调低单次检索的字节数上限
+type AdaptivePagingOptions struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TargetDuration 是期望单次检索响应的最长传输时间，客户端按最近的下载速度在该时间内收不完时调低上限
+	TargetDuration time.Duration `mapstructure:"target_duration"`
+	// MinBytes 是调低后的下限，不大于 0 时取默认值 64 KiB
+	MinBytes int64 `mapstructure:"min_bytes"`
+}
+
+// adaptivePaging 记录每个客户端 (已登录用户按用户，匿名访问者按 IP) 最近的下载速度
+type adaptivePaging struct {
+	opts AdaptivePagingOptions
+	mu   sync.Mutex
+	// rates 是客户端的下载速度 (字节/秒) 的滑动平均
+	rates *lru.LRU[string, float64]
+}
+
+// newAdaptivePaging 在未启用或未设置期望传输时间时返回 nil
+func newAdaptivePaging(opts AdaptivePagingOptions) *adaptivePaging {
+	if !opts.Enabled || opts.TargetDuration <= 0 {
+		return nil
+	}
+	if opts.MinBytes <= 0 {
+		opts.MinBytes = defaultMinAdaptiveBytes
+	}
+	return &adaptivePaging{opts: opts, rates: lru.NewLRU[string, float64](maxTrackedClients, nil, throughputTTL)}
+}
+
+func pagingClientKey(c *gin.Context) string {
+	if id := requestUserID(c); id != 0 {
+		return "user:" + strconv.FormatInt(id, 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// limit 返回客户端生效的结果字节数上限：客户端声明的上限与按其下载速度估算的上限都只能调低业务组的上限
+func (p *adaptivePaging) limit(c *gin.Context, bizLimit int64) int64 {
+	limit := bizLimit
+	if declared, err := strconv.ParseInt(c.GetHeader(MaxBytesHeader), 10, 64); err == nil && declared > 0 && declared < limit {
+		limit = declared
+	}
+	if p == nil {
+		return limit
+	}
+	p.mu.Lock()
+	rate, ok := p.rates.Get(pagingClientKey(c))
+	p.mu.Unlock()
+	if !ok {
+		return limit
+	}
+	if budget := int64(rate * p.opts.TargetDuration.Seconds()); budget < limit {
+		limit = max(budget, min(p.opts.MinBytes, limit))
+	}
+	return limit
+}
+
+// observe 以本次响应的大小与写出耗时更新客户端的下载速度。
+// 压缩中间件在处理器返回后才写出剩余数据，因此测得的是下限附近的近似值
+func (p *adaptivePaging) observe(c *gin.Context, written int, elapsed time.Duration) {
+	if p == nil || written < minThroughputSample || elapsed <= 0 {
+		return
+	}
+	sample := float64(written) / elapsed.Seconds()
+	key := pagingClientKey(c)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rate, ok := p.rates.Get(key); ok {
+		sample = rate + throughputSmoothing*(sample-rate)
+	}
+	p.rates.Add(key, sample)
+}
+
+// withPageSizeHint 为因字节数上限被截断的结果附带建议的页大小 (port.ResultRecommendedPageSizeKey)，
+// 按返回记录的平均大小估算在上限内能完整返回的记录数。结果可能来自缓存，复制后再附加
+func withPageSizeHint(result *port.QueryResult, maxBytes int64) *port.QueryResult {
+	if result == nil || maxBytes <= 0 {
+		return result
+	}
+	if truncated, _ := result.Data[port.ResultTruncatedKey].(bool); !truncated {
+		return result
+	}
+	recommended := int64(1)
+	if items, _ := result.Data["items"].([]interface{}); len(items) > 0 {
+		var used int64
+		for _, item := range items {
+			used += approxJSONSize(item)
+		}
+		if avg := used / int64(len(items)); avg > 0 {
+			recommended = max(maxBytes/avg, 1)
+		}
+	}
+	data := make(map[string]interface{}, len(result.Data)+1)
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	data[port.ResultRecommendedPageSizeKey] = recommended
+	return &port.QueryResult{Data: data, Source: result.Source}
+}
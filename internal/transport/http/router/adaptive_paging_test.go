@@ -0,0 +1,69 @@
+// file: internal/transport/http/router/adaptive_paging_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func pagingContext(remoteAddr, maxBytes string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/data/query", nil)
+	c.Request.RemoteAddr = remoteAddr
+	if maxBytes != "" {
+		c.Request.Header.Set(MaxBytesHeader, maxBytes)
+	}
+	return c
+}
+
+func TestWithPageSizeHint(t *testing.T) {
+	row := map[string]interface{}{"id": float64(1), "body": strings.Repeat("x", 1000)}
+	result := &port.QueryResult{Data: map[string]interface{}{"items": []interface{}{row, row, row, row, row}}, Source: "sqlite"}
+
+	capped := withPageSizeHint(capResultBytes(result, 2500), 2500)
+	assert.Equal(t, int64(2), capped.Data[port.ResultRecommendedPageSizeKey])
+	assert.NotContains(t, result.Data, port.ResultRecommendedPageSizeKey, "原结果可能来自缓存，不应被修改")
+
+	// 未截断的结果不附带建议
+	assert.Same(t, result, withPageSizeHint(result, 1<<20))
+
+	// 第一条记录即超出上限时建议逐条读取
+	single := withPageSizeHint(capResultBytes(result, 10), 10)
+	assert.Equal(t, int64(1), single.Data[port.ResultRecommendedPageSizeKey])
+}
+
+func TestAdaptivePaging_Limit(t *testing.T) {
+	// 未启用按速度调整时仍接受客户端声明的更小上限，但不能调高
+	var disabled *adaptivePaging
+	assert.Equal(t, int64(1000), disabled.limit(pagingContext("10.0.0.1:1", "1000"), 8<<20))
+	assert.Equal(t, int64(8<<20), disabled.limit(pagingContext("10.0.0.1:1", "999999999"), 8<<20))
+	assert.Equal(t, int64(8<<20), disabled.limit(pagingContext("10.0.0.1:1", "abc"), 8<<20))
+	assert.Nil(t, newAdaptivePaging(AdaptivePagingOptions{Enabled: true}), "未设置期望传输时间时不按速度调整")
+
+	paging := newAdaptivePaging(AdaptivePagingOptions{Enabled: true, TargetDuration: 10 * time.Second, MinBytes: 100 << 10})
+	slow := pagingContext("10.0.0.2:1", "")
+	assert.Equal(t, int64(8<<20), paging.limit(slow, 8<<20), "尚无测速样本")
+
+	// 小响应不参与测速
+	paging.observe(slow, 1<<10, time.Second)
+	assert.Equal(t, int64(8<<20), paging.limit(slow, 8<<20))
+
+	// 512 KiB 用时 10 秒，约 52 KB/s，10 秒内能传完约 512 KiB
+	paging.observe(slow, 512<<10, 10*time.Second)
+	assert.Equal(t, int64(512<<10), paging.limit(slow, 8<<20))
+	assert.Equal(t, int64(8<<20), paging.limit(pagingContext("10.0.0.3:1", ""), 8<<20), "速度按客户端分别统计")
+
+	// 更慢的样本按滑动平均降低估计，但不低于下限
+	for i := 0; i < 20; i++ {
+		paging.observe(slow, 256<<10, 100*time.Second)
+	}
+	assert.Equal(t, int64(100<<10), paging.limit(slow, 8<<20))
+	assert.Equal(t, int64(50<<10), paging.limit(slow, 50<<10), "下限不能高于业务组的上限")
+}
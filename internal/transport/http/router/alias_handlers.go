@@ -0,0 +1,134 @@
+// Package router file: internal/transport/http/router/alias_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errViewNotFound 表示查询指定的视图不存在
+var errViewNotFound = errors.New("视图不存在")
+
+// findNamedView 按名称查找表的视图配置，tableName 为空时查找业务组默认查询表的视图；不存在时返回 errViewNotFound
+func findNamedView(ctx context.Context, configService port.QueryAdminConfigService, bizName, tableName, viewName string) (*domain.ViewConfig, error) {
+	if tableName == "" {
+		bizConfig, err := configService.GetBizQueryConfig(ctx, bizName)
+		if err != nil {
+			return nil, err
+		}
+		if bizConfig != nil {
+			tableName = bizConfig.DefaultQueryTable
+		}
+	}
+	views, err := configService.GetAllViewConfigsForBiz(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range views[tableName] {
+		if v != nil && v.ViewName == viewName {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: 表 '%s' 不存在名为 '%s' 的视图", errViewNotFound, tableName, viewName)
+}
+
+// resolveFieldAliases 计算指定表的 字段名 -> 展示名 映射。
+// 字段设置中的 display_name 为基础；视图（指定的视图，未指定时为默认视图）中表格列的 displayName 优先。
+// 若多个字段映射到同一个展示名，这些字段均保留原名，避免结果中的字段相互覆盖。
+func resolveFieldAliases(ctx context.Context, configService port.QueryAdminConfigService, bizName, tableName, viewName string) (map[string]string, error) {
+	bizConfig, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if tableName == "" && bizConfig != nil {
+		tableName = bizConfig.DefaultQueryTable
+	}
+
+	var view *domain.ViewConfig
+	if viewName == "" {
+		view, err = configService.GetDefaultViewConfig(ctx, bizName, tableName)
+	} else {
+		view, err = findNamedView(ctx, configService, bizName, tableName, viewName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return viewFieldAliases(bizConfig, tableName, view), nil
+}
+
+// viewFieldAliases 按字段设置与给定视图 (可以为 nil 或尚未保存) 计算 字段名 -> 展示名 映射，规则同 resolveFieldAliases
+func viewFieldAliases(bizConfig *domain.BizQueryConfig, tableName string, view *domain.ViewConfig) map[string]string {
+	aliases := make(map[string]string)
+	if bizConfig != nil {
+		if tableConfig, ok := bizConfig.Tables[tableName]; ok && tableConfig != nil {
+			for name, field := range tableConfig.Fields {
+				if field.DisplayName != "" {
+					aliases[name] = field.DisplayName
+				}
+			}
+		}
+	}
+	if view != nil && view.Binding.Table != nil {
+		for _, col := range view.Binding.Table.Columns {
+			if col.Field != "" && col.DisplayName != "" {
+				aliases[col.Field] = col.DisplayName
+			}
+		}
+	}
+
+	targets := make(map[string]int, len(aliases))
+	for _, alias := range aliases {
+		targets[alias]++
+	}
+	for field, alias := range aliases {
+		if targets[alias] > 1 || alias == field {
+			delete(aliases, field)
+		}
+	}
+	return aliases
+}
+
+// applyFieldAliases 返回一个字段已重命名的结果副本，不修改原结果（原结果可能被查询缓存共享）。
+// 普通查询重命名 items 中的每一行，按主键读取时重命名 record。
+func applyFieldAliases(result *port.QueryResult, aliases map[string]string) *port.QueryResult {
+	if result == nil || len(aliases) == 0 {
+		return result
+	}
+	data := make(map[string]interface{}, len(result.Data))
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	if items, ok := result.Data["items"].([]interface{}); ok {
+		renamed := make([]interface{}, len(items))
+		for i, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				renamed[i] = renameFields(row, aliases)
+			} else {
+				renamed[i] = item
+			}
+		}
+		data["items"] = renamed
+	}
+	if record, ok := result.Data["record"].(map[string]interface{}); ok {
+		data["record"] = renameFields(record, aliases)
+	}
+	return &port.QueryResult{Data: data, Source: result.Source}
+}
+
+// renameFields 按别名复制一行数据。若展示名与行中另一个原始字段同名，则保留原名以免丢失数据。
+func renameFields(row map[string]interface{}, aliases map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		key := k
+		if alias, ok := aliases[k]; ok {
+			if _, clash := row[alias]; !clash {
+				key = alias
+			}
+		}
+		out[key] = v
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+// file: internal/transport/http/router/alias_handlers_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFieldAliases_CopiesResult(t *testing.T) {
+	original := &port.QueryResult{
+		Data: map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"ti": "论语", "au": "孔子", "题名": "保留"},
+			},
+			"total": float64(1),
+		},
+		Source: "sqlite",
+	}
+	aliases := map[string]string{"ti": "题名", "au": "作者"}
+
+	aliased := applyFieldAliases(original, aliases)
+
+	row := aliased.Data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "孔子", row["作者"])
+	assert.Equal(t, "论语", row["ti"], "展示名与已有字段冲突时应保留原名")
+	assert.Equal(t, "保留", row["题名"])
+	assert.Equal(t, float64(1), aliased.Data["total"])
+
+	origRow := original.Data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "孔子", origRow["au"], "原结果可能被缓存共享，不应被修改")
+	assert.NotContains(t, origRow, "作者")
+}
+
+func TestApplyFieldAliases_Record(t *testing.T) {
+	original := &port.QueryResult{Data: map[string]interface{}{
+		"record": map[string]interface{}{"au": "孔子"}, "matches": float64(1),
+	}}
+	aliased := applyFieldAliases(original, map[string]string{"au": "作者"})
+	assert.Equal(t, map[string]interface{}{"作者": "孔子"}, aliased.Data["record"])
+	assert.Same(t, original, applyFieldAliases(original, nil))
+}
@@ -0,0 +1,111 @@
+// Package router file: internal/transport/http/router/analytics_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/analytics"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bizUsageReportHandler 返回业务组最近一段时间的检索统计：检索次数、常用检索字段、
+// 常见检索词与无结果检索词。?days= 默认 30 (最多 366)，?limit= 默认 20 (最多 200)。
+func bizUsageReportHandler(usage *analytics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if usage == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "检索统计未启用"})
+			return
+		}
+		days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+		if err != nil || days < 1 || days > 366 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days 必须是 1 到 366 之间的整数"})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须是 1 到 200 之间的整数"})
+			return
+		}
+		report, err := usage.Report(c.Request.Context(), c.Param("bizName"), days, limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// listZeroResultsHandler 列出待复核的无结果检索，按出现次数倒序。
+// 支持 ?biz=、?status= (默认 open，all 表示全部)、?tag= 与分页参数 ?page=、?size=。
+func listZeroResultsHandler(usage *analytics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if usage == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "检索统计未启用"})
+			return
+		}
+		filter := analytics.ZeroResultFilter{
+			BizName: c.Query("biz"),
+			Status:  c.DefaultQuery("status", analytics.ZeroResultOpen),
+			Tag:     c.Query("tag"),
+		}
+		if filter.Status == "all" {
+			filter.Status = ""
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		filter.Limit, filter.Offset = page.Size, page.offset()
+
+		items, total, err := usage.ListZeroResults(c.Request.Context(), filter)
+		if err != nil {
+			if errors.Is(err, analytics.ErrInvalidReview) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		respondPage(c, items, total, page)
+	}
+}
+
+// reviewZeroResultHandler 为一条无结果检索打标签、填写处理说明，并将其标记为 resolved、ignored 或重新打开
+func reviewZeroResultHandler(usage *analytics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if usage == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "检索统计未启用"})
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录 ID"})
+			return
+		}
+		var review analytics.ZeroResultReview
+		if err := c.ShouldBindJSON(&review); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		var reviewerID int64
+		if claims := service.ClaimFrom(c.Request); claims != nil {
+			reviewerID = claims.ID
+		}
+		item, err := usage.ReviewZeroResult(c.Request.Context(), id, review, reviewerID)
+		if err != nil {
+			switch {
+			case errors.Is(err, analytics.ErrZeroResultNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, analytics.ErrInvalidReview):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": item})
+	}
+}
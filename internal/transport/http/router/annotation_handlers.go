@@ -0,0 +1,214 @@
+// Package router file: internal/transport/http/router/annotation_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/annotations"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondAnnotationError 把批注的校验错误映射为 400/404/409，其余交给错误中间件
+func respondAnnotationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, annotations.ErrInvalidAnnotation), errors.Is(err, annotations.ErrQuotaExceeded),
+		errors.Is(err, annotations.ErrNotApplicable), errors.Is(err, port.ErrTableNotFoundInBiz):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, annotations.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, annotations.ErrAlreadyModerated), errors.Is(err, annotations.ErrStale):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// annotationFilterFromQuery 从查询参数 biz、table、record_id、status 与分页参数 page、size 读取检索条件
+func annotationFilterFromQuery(c *gin.Context) (domain.AnnotationFilter, pageParams, bool) {
+	filter := domain.AnnotationFilter{
+		BizName:   c.Query("biz"),
+		TableName: c.Query("table"),
+		RecordID:  c.Query("record_id"),
+		Status:    c.Query("status"),
+	}
+	page, ok := pageParamsFromQuery(c)
+	if !ok {
+		return filter, page, false
+	}
+	filter.Limit, filter.Offset = page.Size, page.offset()
+	return filter, page, true
+}
+
+func annotationIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的批注 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// createAnnotationHandler 为一条记录提交批注或勘误，提交后等待管理员审核
+func createAnnotationHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		var a domain.Annotation
+		if err := c.ShouldBindJSON(&a); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		claims := service.ClaimFrom(c.Request)
+		created, err := notes.Create(c.Request.Context(), claims.ID, claims.Role == "admin", a)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": created})
+	}
+}
+
+// listMyAnnotationsHandler 分页列出当前用户提交的批注及其审核状态
+func listMyAnnotationsHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		filter, page, ok := annotationFilterFromQuery(c)
+		if !ok {
+			return
+		}
+		filter.AuthorID = requestUserID(c)
+		items, total, err := notes.List(c.Request.Context(), filter)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		respondPage(c, items, int64(total), page)
+	}
+}
+
+// withdrawAnnotationHandler 撤回当前用户尚未审核的批注
+func withdrawAnnotationHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		id, ok := annotationIDParam(c)
+		if !ok {
+			return
+		}
+		if err := notes.Withdraw(c.Request.Context(), requestUserID(c), id); err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "批注已撤回"})
+	}
+}
+
+// listAnnotationsHandler 供管理员分页检索批注，通常以 ?status=pending 列出审核队列
+func listAnnotationsHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		filter, page, ok := annotationFilterFromQuery(c)
+		if !ok {
+			return
+		}
+		items, total, err := notes.List(c.Request.Context(), filter)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		respondPage(c, items, int64(total), page)
+	}
+}
+
+// reviewAnnotationHandler 审核一条批注，请求体为 {"status": "approved"|"rejected", "note": "..."}
+func reviewAnnotationHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		id, ok := annotationIDParam(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Status string `json:"status" binding:"required"`
+			Note   string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		reviewed, err := notes.Review(c.Request.Context(), requestUserID(c), id, body.Status, body.Note)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": reviewed})
+	}
+}
+
+// correctionDiffHandler 返回勘误的原值、记录当前值与建议值，以及能否写回
+func correctionDiffHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		id, ok := annotationIDParam(c)
+		if !ok {
+			return
+		}
+		diff, err := notes.Diff(c.Request.Context(), id)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": diff})
+	}
+}
+
+// applyCorrectionHandler 采纳勘误并写回数据源，请求体可选 {"note": "..."}。
+// 提交勘误后记录已被修改时返回 409，需重新比对差异。
+func applyCorrectionHandler(notes *annotations.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if notes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录批注未启用"})
+			return
+		}
+		id, ok := annotationIDParam(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Note string `json:"note"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		applied, err := notes.Apply(c.Request.Context(), requestUserID(c), id, body.Note)
+		if err != nil {
+			respondAnnotationError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": applied})
+	}
+}
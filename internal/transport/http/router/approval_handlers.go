@@ -0,0 +1,152 @@
+// Package router file: internal/transport/http/router/approval_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/approvals"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerApprovalAppliers 注册审批通过后执行各类高风险变更的函数
+func registerApprovalAppliers(deps Dependencies) {
+	deps.Approvals.Register(approvals.KindDisablePublicSearch, func(ctx context.Context, target string, payload json.RawMessage) error {
+		var settings domain.BizOverallSettings
+		if err := json.Unmarshal(payload, &settings); err != nil {
+			return err
+		}
+		return deps.AdminConfigService.UpdateBizOverallSettings(ctx, target, settings)
+	})
+	deps.Approvals.Register(approvals.KindDeleteInstance, func(_ context.Context, target string, _ json.RawMessage) error {
+		return deps.PluginManager.DeleteInstance(target)
+	})
+	deps.Approvals.Register(approvals.KindUpdateIPLimit, func(ctx context.Context, _ string, payload json.RawMessage) error {
+		var setting domain.IPLimitSetting
+		if err := json.Unmarshal(payload, &setting); err != nil {
+			return err
+		}
+		return deps.AdminConfigService.UpdateIPLimitSettings(ctx, setting)
+	})
+}
+
+// submitForApproval 在启用双人审批时把变更记录为待审批申请并返回 202。
+// 返回 false 表示未启用审批，调用方应直接执行变更
+func submitForApproval(c *gin.Context, changes *approvals.Service, kind, target string, payload any) bool {
+	if changes == nil {
+		return false
+	}
+	cr, err := changes.Submit(c.Request.Context(), kind, target, payload, requestUserID(c))
+	if err != nil {
+		respondApprovalError(c, err)
+		return true
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "pending_approval", "message": "该变更需要另一位管理员审批后才会生效", "data": cr})
+	return true
+}
+
+// respondApprovalError 把审批错误映射为 400/403/404/409，其余交给错误中间件
+func respondApprovalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, approvals.ErrInvalidChange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, approvals.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, approvals.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, approvals.ErrNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+func changeRequestID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的变更申请 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// listChangeRequestsHandler 列出变更申请，默认只返回待审批的，?status=all 返回全部
+func listChangeRequestsHandler(changes *approvals.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if changes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "双人审批未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		status := c.DefaultQuery("status", domain.ChangeStatusPending)
+		if status == "all" {
+			status = ""
+		}
+		list, err := changes.List(c.Request.Context(), status)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// getChangeRequestHandler 返回单个变更申请
+func getChangeRequestHandler(changes *approvals.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if changes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "双人审批未启用"})
+			return
+		}
+		id, ok := changeRequestID(c)
+		if !ok {
+			return
+		}
+		cr, err := changes.Get(c.Request.Context(), id)
+		if err != nil {
+			respondApprovalError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": cr})
+	}
+}
+
+// reviewChangeRequestHandler 批准或驳回变更申请，请求体可携带 {"note": "..."}
+func reviewChangeRequestHandler(changes *approvals.Service, approve bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if changes == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "双人审批未启用"})
+			return
+		}
+		id, ok := changeRequestID(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Note string `json:"note"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		review := changes.Reject
+		if approve {
+			review = changes.Approve
+		}
+		cr, err := review(c.Request.Context(), id, requestUserID(c), body.Note)
+		if err != nil {
+			respondApprovalError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": cr})
+	}
+}
@@ -0,0 +1,39 @@
+// Package router file: internal/transport/http/router/biz_clone_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/bizclone"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cloneBizConfigHandler 把业务组的配置复制到 ?target= 指定的业务组，?dry_run=true 时只校验并返回报告。
+// 目标业务组已有配置时返回 409，源配置中的表在目标业务组中不存在时返回 422
+func cloneBizConfigHandler(clones *bizclone.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := clones.Clone(c.Request.Context(), c.Param("bizName"), c.Query("target"), c.Query("dry_run") == "true")
+		if err != nil {
+			respondBizCloneError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+func respondBizCloneError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, bizclone.ErrInvalidRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, bizclone.ErrTargetConfigured):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, bizclone.ErrMissingTables), errors.Is(err, port.ErrPhysicalSchemaUnsupported):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, port.ErrBizNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package router file: internal/transport/http/router/biz_rename_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/caching"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/bizrename"
+	"ArchiveAegis/internal/service/catalog"
+	"ArchiveAegis/internal/service/sitemap"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renameBizHandler 把业务组重命名为请求体中的 new_name，配置、实例绑定与历史记录随之迁移
+func renameBizHandler(renames *bizrename.Service, queryCache *caching.Cache, catalogService *catalog.Service, sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if renames == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "业务组重命名未启用"})
+			return
+		}
+		var body struct {
+			NewName string `json:"new_name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须包含 new_name"})
+			return
+		}
+		var userID *int64
+		if id := requestUserID(c); id > 0 {
+			userID = &id
+		}
+		oldName := c.Param("bizName")
+		report, err := renames.Rename(c.Request.Context(), oldName, body.NewName, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, bizrename.ErrInvalidName):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, port.ErrBizNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, bizrename.ErrNameTaken), errors.Is(err, bizrename.ErrReferenced):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, bizrename.ErrUnsupported):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		if queryCache != nil {
+			queryCache.Purge(oldName)
+		}
+		if catalogService != nil {
+			catalogService.Invalidate()
+		}
+		if sitemaps != nil {
+			sitemaps.Invalidate()
+		}
+		slog.Info("审计日志: 重命名业务组", "biz", oldName, "new_name", report.NewName, "tables", len(report.Tables), "user_id", requestUserID(c))
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// listBizRenamesHandler 返回业务组重命名记录，?biz= 按新旧名称之一过滤
+func listBizRenamesHandler(renames *bizrename.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if renames == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "业务组重命名未启用"})
+			return
+		}
+		items, err := renames.List(c.Request.Context(), c.Query("biz"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": items})
+	}
+}
@@ -0,0 +1,122 @@
+// Package router file: internal/transport/http/router/biz_status_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/catalog"
+	"ArchiveAegis/internal/service/sitemap"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestBizName 按路径参数、?biz= 与 JSON 请求体中的 biz_name 的顺序确定请求针对的业务组。
+// 读取过的请求体会被还原，供后续处理器再次绑定
+func requestBizName(c *gin.Context) string {
+	for _, param := range []string{"biz", "bizName"} {
+		if name := c.Param(param); name != "" {
+			return name
+		}
+	}
+	if name := c.Query("biz"); name != "" {
+		return name
+	}
+	if c.Request.Method != http.MethodPost || !strings.Contains(c.GetHeader("Content-Type"), "application/json") || c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var extractor struct {
+		BizName string `json:"biz_name"`
+	}
+	_ = json.Unmarshal(body, &extractor)
+	return extractor.BizName
+}
+
+// bizDisabled 判断业务组是否被暂停服务。未配置的业务组 (如虚拟业务组) 视为启用
+func bizDisabled(ctx context.Context, configService port.QueryAdminConfigService, bizName string) (bool, error) {
+	cfg, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return false, err
+	}
+	return cfg != nil && !cfg.Enabled, nil
+}
+
+// requireBizEnabled 拒绝针对已暂停服务的业务组的数据与元数据请求，返回 503
+func requireBizEnabled(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := requestBizName(c)
+		if bizName == "" {
+			c.Next()
+			return
+		}
+		disabled, err := bizDisabled(c.Request.Context(), configService, bizName)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		if disabled {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("业务组 '%s' 已暂停服务", bizName)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// setBizEnabledHandler 暂停或恢复业务组的服务，请求体为 {"enabled": false}。
+// 只修改启用状态，其余总体设置保持不变
+func setBizEnabledHandler(configService port.QueryAdminConfigService, catalogService *catalog.Service, sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		var body struct {
+			Enabled *bool `json:"enabled" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		cfg, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("业务组 '%s' 尚未配置", bizName)})
+			return
+		}
+		// UpdateBizOverallSettings 会按 UPSERT 覆盖公开检索与默认表，这里带上当前值
+		settings := domain.BizOverallSettings{IsPubliclySearchable: &cfg.IsPubliclySearchable, Enabled: body.Enabled}
+		if cfg.DefaultQueryTable != "" {
+			settings.DefaultQueryTable = &cfg.DefaultQueryTable
+		}
+		if err := configService.UpdateBizOverallSettings(c.Request.Context(), bizName, settings); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if catalogService != nil {
+			catalogService.Invalidate()
+		}
+		if sitemaps != nil {
+			sitemaps.Invalidate()
+		}
+		slog.Info("审计日志: 修改业务组启用状态", "biz", bizName, "enabled", *body.Enabled, "user_id", requestUserID(c))
+		message := "业务组已恢复服务"
+		if !*body.Enabled {
+			message = "业务组已暂停服务，配置、视图与实例均已保留"
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": message})
+	}
+}
@@ -0,0 +1,232 @@
+// Package router file: internal/transport/http/router/biz_time.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateRangeKey 是过滤条件中的相对日期范围，如 {"field": "sent_at", "date_range": "last_7_days"}。
+// 网关按业务组的时区把它展开为具体的日期，见 expandDateRange
+const dateRangeKey = "date_range"
+
+// maxRelativeDays 是 last_N_days 允许的最大天数
+const maxRelativeDays = 366
+
+// localeTagPattern 与配置服务的校验一致：语言子标签后跟若干以 "-" 分隔的子标签
+var localeTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// checkBizTimeSettings 校验业务组设置中的时区与语言区域，返回给客户端的错误信息，合法时为空
+func checkBizTimeSettings(settings domain.BizOverallSettings) string {
+	if tz := settings.Timezone; tz != nil && *tz != "" {
+		if _, err := time.LoadLocation(*tz); err != nil || *tz == "Local" {
+			return fmt.Sprintf("无法识别的时区 '%s'，应为 IANA 时区名称 (如 Asia/Shanghai)", *tz)
+		}
+	}
+	if locale := settings.Locale; locale != nil && *locale != "" && !localeTagPattern.MatchString(*locale) {
+		return fmt.Sprintf("无效的语言区域 '%s'，应为 BCP 47 语言标签 (如 zh-CN)", *locale)
+	}
+	return ""
+}
+
+// bizLocation 返回业务组配置的时区，未配置或业务组不存在时为服务器时区
+func bizLocation(cfg *domain.BizQueryConfig) *time.Location {
+	if cfg == nil || cfg.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// isDateDataType 判断字段的声明类型是否为日期或时间
+func isDateDataType(dataType string) bool {
+	upper := strings.ToUpper(dataType)
+	return strings.Contains(upper, "DATE") || strings.Contains(upper, "TIME")
+}
+
+// applyDates 按业务组的时区处理查询中的日期过滤条件，原查询不被修改，没有需要处理的条件时原样返回
+func applyDates(ctx context.Context, configService port.QueryAdminConfigService, bizName string, query map[string]interface{}) (map[string]interface{}, error) {
+	filters, _ := query["filters"].([]interface{})
+	if len(filters) == 0 {
+		return query, nil
+	}
+	cfg, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	var table *domain.TableConfig
+	if cfg != nil {
+		tableName, _ := query["table"].(string)
+		table = cfg.Tables[tableName]
+	}
+	return expandDateFilters(query, table, bizLocation(cfg), time.Now())
+}
+
+// expandDateFilters 展开过滤条件中的 date_range，并把日期类型字段上带时区的取值 (如 "2024-03-05T16:00:00Z")
+// 换算为业务组时区的本地时间：DATE 字段取日期部分，其余日期时间字段为不带时区的 "2006-01-02T15:04:05"。
+// 不带时区的取值视为已是业务组的本地时间，保持不变
+func expandDateFilters(query map[string]interface{}, table *domain.TableConfig, loc *time.Location, now time.Time) (map[string]interface{}, error) {
+	filters, _ := query["filters"].([]interface{})
+	var out []interface{}
+	for i, f := range filters {
+		filter, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field, _ := filter["field"].(string)
+		var dataType string
+		if table != nil {
+			dataType = table.Fields[field].DataType
+		}
+		_, hasRange := filter[dateRangeKey]
+		if !hasRange && !isDateDataType(dataType) {
+			continue
+		}
+		rewritten := make(map[string]interface{}, len(filter)+2)
+		for k, v := range filter {
+			rewritten[k] = v
+		}
+		if hasRange {
+			if err := expandDateRange(rewritten, loc, now); err != nil {
+				return nil, err
+			}
+		} else {
+			upper := strings.ToUpper(dataType)
+			dateOnly := strings.Contains(upper, "DATE") && !strings.Contains(upper, "TIME")
+			if v, ok := rewritten["value"].(string); ok {
+				rewritten["value"] = localDateValue(v, loc, dateOnly)
+			}
+			if values, ok := rewritten[port.FilterValuesKey].([]interface{}); ok {
+				converted := make([]interface{}, len(values))
+				for j, v := range values {
+					if s, ok := v.(string); ok {
+						converted[j] = localDateValue(s, loc, dateOnly)
+					} else {
+						converted[j] = v
+					}
+				}
+				rewritten[port.FilterValuesKey] = converted
+			}
+		}
+		if out == nil {
+			out = append([]interface{}(nil), filters...)
+		}
+		out[i] = rewritten
+	}
+	if out == nil {
+		return query, nil
+	}
+	result := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		result[k] = v
+	}
+	result["filters"] = out
+	return result, nil
+}
+
+// localDateValue 把带时区的 RFC 3339 时间换算为 loc 中的本地时间，其它取值原样返回
+func localDateValue(v string, loc *time.Location, dateOnly bool) string {
+	t, err := time.Parse(time.RFC3339Nano, strings.Replace(strings.TrimSpace(v), " ", "T", 1))
+	if err != nil {
+		return v
+	}
+	t = t.In(loc)
+	if dateOnly {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format("2006-01-02T15:04:05.999999999")
+}
+
+// expandDateRange 把过滤条件中的 date_range 展开为对日期文本的包含匹配。支持的范围：
+// today、yesterday、last_N_days (含今天的最近 N 天，N 不超过 366)、this_month、last_month、this_year、last_year。
+// 按天的范围展开为 value 与候选值 values 中的各个日期，按月、按年的范围匹配 "2006-01" 或 "2006" 前缀，
+// 因此同样适用于 "2006-01-02T15:04:05" 形式的日期时间文本。"今天" 以业务组的时区计算
+func expandDateRange(filter map[string]interface{}, loc *time.Location, now time.Time) error {
+	field, _ := filter["field"].(string)
+	spec, ok := filter[dateRangeKey].(string)
+	if !ok || spec == "" {
+		return fmt.Errorf("%w: 字段 '%s' 的 '%s' 必须是非空字符串", errInvalidFilters, field, dateRangeKey)
+	}
+	for _, conflicting := range []string{"value", port.FilterValuesKey, "op"} {
+		if _, set := filter[conflicting]; set {
+			return fmt.Errorf("%w: 字段 '%s' 的 '%s' 不能与 '%s' 同时使用", errInvalidFilters, field, dateRangeKey, conflicting)
+		}
+	}
+
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	var values []string
+	switch spec {
+	case "today":
+		values = []string{today.Format(time.DateOnly)}
+	case "yesterday":
+		values = []string{today.AddDate(0, 0, -1).Format(time.DateOnly)}
+	case "this_month":
+		values = []string{today.Format("2006-01")}
+	case "last_month":
+		values = []string{time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, loc).Format("2006-01")}
+	case "this_year":
+		values = []string{strconv.Itoa(today.Year())}
+	case "last_year":
+		values = []string{strconv.Itoa(today.Year() - 1)}
+	default:
+		days, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(spec, "last_"), "_days"))
+		if err != nil || !strings.HasPrefix(spec, "last_") || !strings.HasSuffix(spec, "_days") {
+			return fmt.Errorf("%w: 不支持的相对日期范围 '%s'", errInvalidFilters, spec)
+		}
+		if days < 1 || days > maxRelativeDays {
+			return fmt.Errorf("%w: 相对日期范围的天数必须在 1 到 %d 之间", errInvalidFilters, maxRelativeDays)
+		}
+		for d := days - 1; d >= 0; d-- {
+			values = append(values, today.AddDate(0, 0, -d).Format(time.DateOnly))
+		}
+	}
+
+	delete(filter, dateRangeKey)
+	filter["value"] = values[0]
+	filter["fuzzy"] = true
+	if len(values) > 1 {
+		candidates := make([]interface{}, 0, len(values)-1)
+		for _, v := range values[1:] {
+			candidates = append(candidates, v)
+		}
+		filter[port.FilterValuesKey] = candidates
+	}
+	return nil
+}
+
+// bizLocaleHandlerV1 返回业务组的时区与语言区域。timezone 为实际生效的时区 (未配置时为服务器时区)，
+// utc_offset 是该时区当前相对 UTC 的偏移
+func bizLocaleHandlerV1(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		cfg, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg == nil {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+		loc := bizLocation(cfg)
+		etagJSON(c, gin.H{"data": gin.H{
+			"biz_name":            bizName,
+			"timezone":            loc.String(),
+			"timezone_configured": cfg.Timezone != "",
+			"utc_offset":          time.Now().In(loc).Format("-07:00"),
+			"locale":              cfg.Locale,
+		}})
+	}
+}
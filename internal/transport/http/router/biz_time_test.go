@@ -0,0 +1,65 @@
+// file: internal/transport/http/router/biz_time_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandDateFilters(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	// UTC 时间 3 月 1 日 20:00 在上海已是 3 月 2 日
+	now := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	table := &domain.TableConfig{Fields: map[string]domain.FieldSetting{
+		"sent_on": {FieldName: "sent_on", DataType: "DATE"},
+		"sent_at": {FieldName: "sent_at", DataType: "DATETIME"},
+		"sender":  {FieldName: "sender", DataType: "TEXT"},
+	}}
+	expand := func(filters ...interface{}) ([]interface{}, error) {
+		out, err := expandDateFilters(map[string]interface{}{"filters": filters}, table, shanghai, now)
+		if err != nil {
+			return nil, err
+		}
+		return out["filters"].([]interface{}), nil
+	}
+
+	filters, err := expand(map[string]interface{}{"field": "sent_on", "date_range": "last_3_days"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"field": "sent_on", "value": "2024-02-29", "fuzzy": true, "values": []interface{}{"2024-03-01", "2024-03-02"},
+	}, filters[0], "今天按业务组的时区计算")
+
+	filters, err = expand(map[string]interface{}{"field": "sent_at", "date_range": "last_month"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"field": "sent_at", "value": "2024-02", "fuzzy": true}, filters[0])
+
+	filters, err = expand(
+		map[string]interface{}{"field": "sent_on", "value": "2024-03-01T16:30:00Z"},
+		map[string]interface{}{"field": "sent_at", "values": []interface{}{"2024-03-01T16:30:00Z", "1921-03-05"}},
+		map[string]interface{}{"field": "sender", "value": "2024-03-01T16:30:00Z"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-02", filters[0].(map[string]interface{})["value"], "DATE 字段取本地日期")
+	assert.Equal(t, []interface{}{"2024-03-02T00:30:00", "1921-03-05"}, filters[1].(map[string]interface{})["values"], "不带时区的取值不变")
+	assert.Equal(t, "2024-03-01T16:30:00Z", filters[2].(map[string]interface{})["value"], "非日期字段不处理")
+
+	for _, bad := range []map[string]interface{}{
+		{"field": "sent_on", "date_range": "next_week"},
+		{"field": "sent_on", "date_range": "last_0_days"},
+		{"field": "sent_on", "date_range": "last_400_days"},
+		{"field": "sent_on", "date_range": "today", "value": "2024-03-01"},
+	} {
+		_, err := expand(bad)
+		assert.ErrorIs(t, err, errInvalidFilters, bad["date_range"])
+	}
+
+	query := map[string]interface{}{"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}}}
+	out, err := expandDateFilters(query, table, shanghai, now)
+	require.NoError(t, err)
+	assert.Equal(t, query, out, "没有日期条件时原样返回")
+}
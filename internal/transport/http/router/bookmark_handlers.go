@@ -0,0 +1,238 @@
+// Package router file: internal/transport/http/router/bookmark_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/bookmarks"
+	"ArchiveAegis/internal/service/provenance"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondBookmarkError 把收藏的校验错误映射为 400/404/409，其余交给错误中间件
+func respondBookmarkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, bookmarks.ErrInvalidBookmark), errors.Is(err, bookmarks.ErrQuotaExceeded):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, bookmarks.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, bookmarks.ErrDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// bookmarkFilterFromQuery 从查询参数 biz、table、tag、q 与分页参数 page、size 读取检索条件
+func bookmarkFilterFromQuery(c *gin.Context) (domain.BookmarkFilter, pageParams, bool) {
+	filter := domain.BookmarkFilter{
+		BizName:   c.Query("biz"),
+		TableName: c.Query("table"),
+		Tag:       c.Query("tag"),
+		Query:     c.Query("q"),
+	}
+	page, ok := pageParamsFromQuery(c)
+	if !ok {
+		return filter, page, false
+	}
+	filter.Page, filter.Size = page.Page, page.Size
+	return filter, page, true
+}
+
+func bookmarkIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的收藏 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// bookmarkPermalink 返回收藏记录的永久链接，与 recordHandlerV1 的格式一致
+func bookmarkPermalink(c *gin.Context, b domain.Bookmark) string {
+	link := fmt.Sprintf("%s/api/v1/data/record/%s/%s/%s",
+		requestBaseURL(c), url.PathEscape(b.BizName), url.PathEscape(b.TableName), url.PathEscape(b.RecordID))
+	if b.Lib != "" {
+		link += "?lib=" + url.QueryEscape(b.Lib)
+	}
+	return link
+}
+
+// listBookmarksHandler 分页检索当前用户的收藏，可按业务组、表、标签过滤，q 匹配备注与主键
+func listBookmarksHandler(marks *bookmarks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, page, ok := bookmarkFilterFromQuery(c)
+		if !ok {
+			return
+		}
+		items, total, err := marks.List(c.Request.Context(), requestUserID(c), filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondPage(c, items, total, page)
+	}
+}
+
+// createBookmarkHandler 收藏一条记录，记录必须存在且对公开检索可见
+func createBookmarkHandler(marks *bookmarks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var b domain.Bookmark
+		if err := c.ShouldBindJSON(&b); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		created, err := marks.Create(c.Request.Context(), requestUserID(c), b)
+		if err != nil {
+			if errors.Is(err, port.ErrTableNotFoundInBiz) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			respondBookmarkError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": created})
+	}
+}
+
+// updateBookmarkHandler 修改收藏的备注与标签
+func updateBookmarkHandler(marks *bookmarks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := bookmarkIDParam(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Note string   `json:"note"`
+			Tags []string `json:"tags"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		updated, err := marks.Update(c.Request.Context(), requestUserID(c), id, body.Note, body.Tags)
+		if err != nil {
+			respondBookmarkError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": updated})
+	}
+}
+
+// deleteBookmarkHandler 删除一条收藏
+func deleteBookmarkHandler(marks *bookmarks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := bookmarkIDParam(c)
+		if !ok {
+			return
+		}
+		if err := marks.Delete(c.Request.Context(), requestUserID(c), id); err != nil {
+			respondBookmarkError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+}
+
+// listBookmarkTagsHandler 返回当前用户使用过的标签及各自的收藏数
+func listBookmarkTagsHandler(marks *bookmarks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		tags, err := marks.Tags(c.Request.Context(), requestUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, tags, page)
+	}
+}
+
+// exportBookmarksHandler 以附件形式导出符合条件的全部收藏 (?format=json 或 csv，默认 json)，每条附带永久链接。
+// 启用来源信息时，CSV 以 "# " 注释行开头，JSON 改为 {"provenance": {...}, "items": [...]}。
+// created_at 以各收藏所属业务组的时区输出
+func exportBookmarksHandler(marks *bookmarks.Service, origin *provenance.Service, configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format 只能是 json 或 csv"})
+			return
+		}
+		filter, _, ok := bookmarkFilterFromQuery(c)
+		if !ok {
+			return
+		}
+		items, err := marks.Export(c.Request.Context(), requestUserID(c), filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		locations := make(map[string]*time.Location)
+		createdAt := func(b domain.Bookmark) time.Time {
+			loc, ok := locations[b.BizName]
+			if !ok {
+				// 业务组已被删除或配置读取失败时使用服务器时区
+				var cfg *domain.BizQueryConfig
+				cfg, _ = configService.GetBizQueryConfig(c.Request.Context(), b.BizName)
+				loc = bizLocation(cfg)
+				locations[b.BizName] = loc
+			}
+			return b.CreatedAt.In(loc)
+		}
+
+		fileName := fmt.Sprintf("bookmarks-%s.%s", time.Now().Format("20060102-150405"), format)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+		if format == "json" {
+			out := make([]gin.H, len(items))
+			for i, b := range items {
+				out[i] = gin.H{
+					"biz_name": b.BizName, "table_name": b.TableName, "record_id": b.RecordID, "lib": b.Lib,
+					"note": b.Note, "tags": b.Tags, "created_at": createdAt(b), "permalink": bookmarkPermalink(c, b),
+				}
+			}
+			if origin != nil {
+				c.JSON(http.StatusOK, gin.H{"provenance": origin.For(bookmarkSources(items), requestUserID(c)), "items": out})
+				return
+			}
+			c.JSON(http.StatusOK, out)
+			return
+		}
+
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		// 写入 BOM，便于表格软件以 UTF-8 打开中文内容
+		_, _ = c.Writer.WriteString("\ufeff")
+		if origin != nil {
+			for _, line := range provenance.CommentLines(origin.For(bookmarkSources(items), requestUserID(c))) {
+				_, _ = c.Writer.WriteString(line + "\n")
+			}
+		}
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write([]string{"biz_name", "table_name", "record_id", "lib", "note", "tags", "created_at", "permalink"})
+		for _, b := range items {
+			_ = w.Write([]string{b.BizName, b.TableName, b.RecordID, b.Lib, b.Note, strings.Join(b.Tags, ";"),
+				createdAt(b).Format(time.RFC3339), bookmarkPermalink(c, b)})
+		}
+		w.Flush()
+	}
+}
+
+// bookmarkSources 返回收藏涉及的业务组
+func bookmarkSources(items []domain.Bookmark) []string {
+	sources := make([]string, 0, len(items))
+	for _, b := range items {
+		sources = append(sources, b.BizName)
+	}
+	return sources
+}
@@ -0,0 +1,21 @@
+// Package router file: internal/transport/http/router/cache_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/caching"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// purgeQueryCacheHandler 显式清除查询缓存，?biz= 为空时清空全部
+func purgeQueryCacheHandler(queryCache *caching.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if queryCache == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "查询缓存未启用"})
+			return
+		}
+		purged := queryCache.Purge(c.Query("biz"))
+		c.JSON(http.StatusOK, gin.H{"status": "success", "purged": purged})
+	}
+}
@@ -0,0 +1,99 @@
+// Package router file: internal/transport/http/router/cache_policy.go
+package router
+
+import (
+	"ArchiveAegis/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMetaCachePolicy  = "private, no-cache"
+	defaultDataCachePolicy  = "private, no-cache"
+	defaultAdminCachePolicy = "no-store"
+	// errorCachePolicy 用于非成功响应，错误不应被浏览器或中间缓存保存
+	errorCachePolicy = "no-store"
+)
+
+// CachePolicyOptions 定义各类接口响应的 Cache-Control 取值，为空时使用默认值
+type CachePolicyOptions struct {
+	// Meta 用于元数据接口 (/api/v1/meta)，默认 "private, no-cache"，配合 ETag 每次重新验证
+	Meta string `mapstructure:"meta"`
+	// Data 用于数据平面 (/api/v1/data)，默认 "private, no-cache"
+	Data string `mapstructure:"data"`
+	// Admin 用于管理、业务组配置、当前用户与登录接口，默认 "no-store"
+	Admin string `mapstructure:"admin"`
+	// Anonymous 用于匿名访问者的元数据与数据请求，为空时与已登录用户相同。
+	// 匿名访问者只能读取公开检索的业务组，可设为 "public, max-age=60" 让 CDN 与浏览器共享缓存
+	Anonymous string `mapstructure:"anonymous"`
+}
+
+func (o CachePolicyOptions) withDefaults() CachePolicyOptions {
+	if o.Meta == "" {
+		o.Meta = defaultMetaCachePolicy
+	}
+	if o.Data == "" {
+		o.Data = defaultDataCachePolicy
+	}
+	if o.Admin == "" {
+		o.Admin = defaultAdminCachePolicy
+	}
+	return o
+}
+
+// cachePolicy 为所在路由组的成功响应设置 Cache-Control，处理器自行设置的取值优先。
+// 取值在写出响应头时才确定，此时已完成认证，可以区分匿名访问者；非 2xx/304 的响应一律为 no-store。
+// anonymous 为空时匿名访问者与已登录用户使用相同的取值
+func cachePolicy(policy, anonymous string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &cachePolicyWriter{ResponseWriter: c.Writer, ctx: c, policy: policy, anonymous: anonymous}
+		c.Next()
+	}
+}
+
+// cachePolicyWriter 在状态码确定时设置 Cache-Control。gin 推迟到首次写出时才发送响应头，此时修改仍然有效
+type cachePolicyWriter struct {
+	gin.ResponseWriter
+	ctx       *gin.Context
+	policy    string
+	anonymous string
+	applied   bool
+}
+
+func (w *cachePolicyWriter) WriteHeader(code int) {
+	w.apply(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cachePolicyWriter) WriteHeaderNow() {
+	w.apply(w.Status())
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cachePolicyWriter) Write(p []byte) (int, error) {
+	w.apply(w.Status())
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *cachePolicyWriter) WriteString(s string) (int, error) {
+	w.apply(w.Status())
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cachePolicyWriter) apply(code int) {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	header := w.Header()
+	switch {
+	case code != http.StatusNotModified && (code < 200 || code >= 300):
+		header.Set("Cache-Control", errorCachePolicy)
+	case header.Get("Cache-Control") != "":
+	case w.anonymous != "" && service.ClaimFrom(w.ctx.Request) == nil:
+		header.Set("Cache-Control", w.anonymous)
+	default:
+		header.Set("Cache-Control", w.policy)
+	}
+}
@@ -0,0 +1,60 @@
+// file: internal/transport/http/router/cache_policy_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	group := engine.Group("/meta")
+	group.Use(cachePolicy("private, no-cache", "public, max-age=60"), func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), service.ClaimKey, &service.Claim{ID: 1, Role: "user"}))
+		}
+		c.Next()
+	})
+	group.GET("/schema", func(c *gin.Context) { etagJSON(c, gin.H{"data": "schema"}) })
+	group.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "not found"}) })
+	group.GET("/custom", func(c *gin.Context) {
+		c.Header("Cache-Control", "max-age=5")
+		c.String(http.StatusOK, "ok")
+	})
+
+	serve := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, "public, max-age=60", serve("/meta/schema", nil).Header().Get("Cache-Control"), "匿名访问者使用 anonymous 策略")
+	rr := serve("/meta/schema", map[string]string{"Authorization": "Bearer x"})
+	assert.Equal(t, "private, no-cache", rr.Header().Get("Cache-Control"))
+
+	rr = serve("/meta/schema", map[string]string{"Authorization": "Bearer x", "If-None-Match": rr.Header().Get("ETag")})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Equal(t, "private, no-cache", rr.Header().Get("Cache-Control"), "304 同样带有缓存策略")
+
+	assert.Equal(t, "no-store", serve("/meta/missing", nil).Header().Get("Cache-Control"), "错误响应不缓存")
+	assert.Equal(t, "max-age=5", serve("/meta/custom", nil).Header().Get("Cache-Control"), "处理器自行设置的取值优先")
+}
+
+func TestCachePolicyOptions_Defaults(t *testing.T) {
+	opts := CachePolicyOptions{Data: "no-store"}.withDefaults()
+	assert.Equal(t, defaultMetaCachePolicy, opts.Meta)
+	assert.Equal(t, "no-store", opts.Data)
+	assert.Equal(t, defaultAdminCachePolicy, opts.Admin)
+	assert.Empty(t, opts.Anonymous)
+}
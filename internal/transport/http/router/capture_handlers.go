@@ -0,0 +1,143 @@
+// Package router file: internal/transport/http/router/capture_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/capture"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureWriter 在写出响应的同时保留前 limit 个字节
+type captureWriter struct {
+	gin.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) keep(b []byte) {
+	if room := w.limit - w.buf.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.buf.Write(b)
+	}
+}
+
+// captureExchanges 在业务组开启采集时记录请求与响应，需在 authMiddleware 之后使用。
+// 没有进行中的采集时直接放行，不解析业务组名
+func captureExchanges(captures *capture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if captures == nil || !captures.AnyActive() {
+			c.Next()
+			return
+		}
+		bizName := requestBizName(c)
+		if bizName == "" || !captures.Active(bizName) {
+			c.Next()
+			return
+		}
+
+		limit := captures.ReadLimit()
+		var reqBody []byte
+		if c.Request.Body != nil {
+			full, err := io.ReadAll(c.Request.Body)
+			c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(full))
+			if err == nil {
+				reqBody = full[:min(len(full), limit)]
+			}
+		}
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+		start := time.Now()
+
+		c.Next()
+
+		captures.Record(bizName, c.Request, reqBody, writer.Status(), writer.buf.Bytes(), time.Since(start), requestUserID(c))
+	}
+}
+
+// respondCaptureError 把采集错误映射为 400/404，其余交给错误中间件
+func respondCaptureError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, capture.ErrInvalidCapture):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, capture.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listCaptureSessionsHandler 列出所有业务组的采集会话
+func listCaptureSessionsHandler(captures *capture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		respondList(c, captures.Sessions(), page)
+	}
+}
+
+// startCaptureHandler 开始采集业务组的请求与响应，请求体可携带 {"duration_seconds": 600, "max_entries": 100}。
+// 同一业务组已有的采集与记录会被替换
+func startCaptureHandler(captures *capture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			DurationSeconds int `json:"duration_seconds"`
+			MaxEntries      int `json:"max_entries"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		session, err := captures.Start(c.Param("bizName"), time.Duration(body.DurationSeconds)*time.Second, body.MaxEntries, requestUserID(c))
+		if err != nil {
+			respondCaptureError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": session})
+	}
+}
+
+// stopCaptureHandler 提前结束业务组的采集，已采集的记录保留
+func stopCaptureHandler(captures *capture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := captures.Stop(c.Param("bizName"))
+		if err != nil {
+			respondCaptureError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": session})
+	}
+}
+
+// listCapturedExchangesHandler 返回业务组已采集的请求与响应
+func listCapturedExchangesHandler(captures *capture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, entries, err := captures.Entries(c.Param("bizName"))
+		if err != nil {
+			respondCaptureError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"session": session, "items": entries}})
+	}
+}
@@ -0,0 +1,20 @@
+// Package router file: internal/transport/http/router/catalog_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// catalogHandlerV1 一次性返回全部业务组的 Schema、各表记录数与可用视图，供门户首页列出所有馆藏
+func catalogHandlerV1(catalogService *catalog.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := catalogService.Get(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		etagJSON(c, gin.H{"data": result})
+	}
+}
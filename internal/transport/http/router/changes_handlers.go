@@ -0,0 +1,55 @@
+// Package router file: internal/transport/http/router/changes_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changesHandlerV1 读取业务组的变更捕获 (CDC) 日志，供下游同步消费者增量拉取。
+// since 为上一次响应中的 next_cursor，留空表示从头读取。
+func changesHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Query("biz")
+		if bizName == "" {
+			_ = c.Error(errors.New("缺少 'biz' 参数"))
+			return
+		}
+
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		query := map[string]interface{}{
+			port.QueryModeKey: port.QueryModeChanges,
+			"since":           c.Query("since"),
+		}
+		if limitStr := c.Query("limit"); limitStr != "" {
+			limit, err := strconv.Atoi(limitStr)
+			if err != nil || limit <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "参数 'limit' 必须为正整数"})
+				return
+			}
+			// 与 JSON 解码后的数字类型保持一致
+			query["limit"] = float64(limit)
+		}
+
+		result, err := dataSource.Query(c.Request.Context(), port.QueryRequest{
+			BizName: bizName,
+			Query:   query,
+		})
+		if err != nil {
+			slog.Error("changesHandlerV1 执行失败", "biz", bizName, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
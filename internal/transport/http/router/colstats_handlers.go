@@ -0,0 +1,21 @@
+// Package router file: internal/transport/http/router/colstats_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/colstats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// columnStatsHandlerV1 返回表中各可返回字段的基数、最小值、最大值、NULL 比例与过滤控件建议。
+// 统计在首次请求时计算并缓存，computed_at 为计算时间
+func columnStatsHandlerV1(colStats *colstats.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := colStats.Get(c.Request.Context(), c.Param("bizName"), c.Param("table"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		etagJSON(c, gin.H{"data": stats})
+	}
+}
@@ -0,0 +1,34 @@
+// Package router file: internal/transport/http/router/compaction_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/compaction"
+	"ArchiveAegis/internal/service/jobs"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compactLibsHandler 在后台把业务组的多个库文件合并为一个，请求体为 {"libs": ["a", "b"], "target": "a", "provenance_column": "_source_lib"}。
+// 可通过 /admin/jobs/:id 查询进度，同一业务组已有压缩任务时返回 409
+func compactLibsHandler(compactionService *compaction.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body compaction.Request
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		job, err := compactionService.Compact(c.Request.Context(), c.Param("bizName"), body, requestUserID(c))
+		switch {
+		case errors.Is(err, compaction.ErrInvalidRequest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, jobs.ErrJobConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			_ = c.Error(err)
+		default:
+			c.JSON(http.StatusAccepted, gin.H{"data": job})
+		}
+	}
+}
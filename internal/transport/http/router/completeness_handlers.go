@@ -0,0 +1,37 @@
+// Package router file: internal/transport/http/router/completeness_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// completenessReportHandler 返回表中每个已配置字段缺少值 (NULL 或空字符串) 的记录数，供数据质量检查使用
+func completenessReportHandler(registry map[string]port.DataSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		result, err := dataSource.Query(c.Request.Context(), port.QueryRequest{
+			BizName: bizName,
+			Query: map[string]interface{}{
+				port.QueryModeKey: port.QueryModeCompleteness,
+				"table":           tableName,
+			},
+		})
+		if err != nil {
+			slog.Error("completenessReportHandler 执行失败", "biz", bizName, "table", tableName, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,51 @@
+// Package router file: internal/transport/http/router/compliance_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/compliance"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportComplianceHandler 生成并下载当前的合规快照。?format=json (默认) 返回完整快照，
+// ?format=prometheus 返回 Prometheus textfile 格式的指标
+func exportComplianceHandler(exports *compliance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "prometheus" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支持的导出格式 '%s'，可选 json 或 prometheus", format)})
+			return
+		}
+		report, err := exports.Generate(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		stamp := report.GeneratedAt.Format("20060102-150405")
+		if format == "prometheus" {
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="compliance-%s.prom"`, stamp))
+			c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", compliance.Textfile(report))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="compliance-%s.json"`, stamp))
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// writeComplianceFilesHandler 立即把合规快照写入配置的导出目录，不必等待下一次定期写入
+func writeComplianceFilesHandler(exports *compliance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := exports.WriteFiles(c.Request.Context()); err != nil {
+			if errors.Is(err, compliance.ErrNoExportDir) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+}
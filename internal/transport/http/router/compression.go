@@ -0,0 +1,305 @@
+// Package router file: internal/transport/http/router/compression.go
+package router
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCompressionMinSize = 1024
+
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+	encodingBrotli  = "br"
+)
+
+// CompressionOptions 定义 API 响应压缩的配置
+type CompressionOptions struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Level 是压缩级别 (1 最快 ~ 9 最小)，0 或超出范围时使用各编码的默认级别
+	Level int `mapstructure:"level"`
+	// MinSizeBytes 是启用压缩的最小响应体大小，更小的响应原样返回；不大于 0 时取默认值 1024
+	MinSizeBytes int `mapstructure:"min_size_bytes"`
+	// Brotli 为 true 时额外提供 br 编码，q 值相同时优先于 gzip 与 deflate。br 压缩率更高但更耗 CPU，默认关闭
+	Brotli bool `mapstructure:"brotli"`
+	// ExcludedPaths 是不压缩的路径前缀，用于响应体本身已经压缩或需要逐块推送的接口
+	ExcludedPaths []string `mapstructure:"excluded_paths"`
+}
+
+// CompressionEncoder 是一种内容编码的压缩器，与 gzip.Writer 的方法一致，可通过 Reset 复用
+type CompressionEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// CompressionEncoderFactory 按压缩级别 (0 表示默认级别) 创建写入 w 的压缩器
+type CompressionEncoderFactory func(w io.Writer, level int) (CompressionEncoder, error)
+
+var encoders = map[string]CompressionEncoderFactory{
+	encodingGzip: func(w io.Writer, level int) (CompressionEncoder, error) {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+	encodingDeflate: func(w io.Writer, level int) (CompressionEncoder, error) {
+		if level == 0 {
+			level = flate.DefaultCompression
+		}
+		return flate.NewWriter(w, level)
+	},
+	encodingBrotli: func(w io.Writer, level int) (CompressionEncoder, error) {
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w, level), nil
+	},
+}
+
+// compressedContentTypes 是本身已经压缩、再压缩没有收益的响应类型前缀
+var compressedContentTypes = []string{
+	"application/zip", "application/gzip", "application/x-gzip", "application/octet-stream",
+	"application/pdf", "image/", "video/", "audio/", "font/woff", "text/event-stream",
+}
+
+// compressionSkipKey 是 gin 上下文中标记当前路由不压缩的键，见 noCompression
+const compressionSkipKey = "aegis.compression.skip"
+
+// noCompression 让所在路由的响应不经压缩，用于附件下载等响应体本身已经压缩的接口
+func noCompression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(compressionSkipKey, true)
+		c.Next()
+	}
+}
+
+// compressionMiddleware 按 Accept-Encoding 协商响应编码 (br、gzip、deflate，br 需开启 Brotli)。
+// 响应体先缓冲到 MinSizeBytes，达到阈值后才决定是否压缩，小响应不付出压缩的开销
+func compressionMiddleware(opts CompressionOptions) gin.HandlerFunc {
+	if opts.Level < 0 || opts.Level > 9 {
+		slog.Warn("compression.level 超出 0 到 9 的范围，使用默认级别", "level", opts.Level)
+		opts.Level = 0
+	}
+	if opts.MinSizeBytes <= 0 {
+		opts.MinSizeBytes = defaultCompressionMinSize
+	}
+	offered := []string{encodingGzip, encodingDeflate}
+	if opts.Brotli {
+		offered = append([]string{encodingBrotli}, offered...)
+	}
+	pools := make(map[string]*sync.Pool, len(offered))
+	for _, encoding := range offered {
+		factory, level := encoders[encoding], opts.Level
+		pools[encoding] = &sync.Pool{New: func() any {
+			enc, err := factory(io.Discard, level)
+			if err != nil {
+				slog.Error("创建压缩器失败", "encoding", encoding, "error", err)
+				return nil
+			}
+			return enc
+		}}
+	}
+
+	return func(c *gin.Context) {
+		if !opts.Enabled || c.Request.Method == http.MethodHead || hasPathPrefix(c.Request.URL.Path, opts.ExcludedPaths) {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), offered)
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		cw := &compressWriter{ResponseWriter: c.Writer, ctx: c, encoding: encoding, pool: pools[encoding], minSize: opts.MinSizeBytes}
+		c.Writer = cw
+		defer func() {
+			cw.finish()
+			c.Writer = cw.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding 按 Accept-Encoding 的 q 值选出 offered 中的编码，q 值相同时按 offered 的顺序优先。
+// 客户端未接受任何编码时返回空串，即不压缩
+func negotiateEncoding(header string, offered []string) string {
+	if header == "" {
+		return ""
+	}
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	candidates := make([]string, 0, len(offered))
+	for _, encoding := range offered {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			candidates = append(candidates, encoding)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return qValue(accepted, candidates[i]) > qValue(accepted, candidates[j])
+	})
+	return candidates[0]
+}
+
+func qValue(accepted map[string]float64, encoding string) float64 {
+	if q, ok := accepted[encoding]; ok {
+		return q
+	}
+	return accepted["*"]
+}
+
+// compressWriter 缓冲响应体直到可以决定是否压缩：达到最小大小、处理器主动 Flush 或响应结束
+type compressWriter struct {
+	gin.ResponseWriter
+	ctx      *gin.Context // 压缩与否在首次写出时才决定，此时读取路由的 noCompression 标记
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+
+	buf     []byte
+	decided bool
+	enc     CompressionEncoder
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written 在响应体已缓冲但尚未写出时同样返回 true，处理器据此判断是否还能改写为错误响应
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush 用于逐块推送的响应，此时不再等待最小大小
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(len(w.buf) >= w.minSize); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 决定是否压缩并写出已缓冲的内容。compress 为 false 或响应不适合压缩时原样写出
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	if compress && !w.ctx.GetBool(compressionSkipKey) && header.Get("Content-Encoding") == "" &&
+		status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		!isCompressedContentType(header.Get("Content-Type")) {
+		// 编码器创建失败时池中取出 nil，此时退回为不压缩
+		if enc, _ := w.pool.Get().(CompressionEncoder); enc != nil {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			enc.Reset(w.ResponseWriter)
+			w.enc = enc
+		}
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish 在处理器返回后写出剩余内容并结束压缩流
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if err := w.decide(len(w.buf) >= w.minSize); err != nil {
+			slog.Debug("写出响应失败", "error", err)
+		}
+	}
+	if w.enc != nil {
+		if err := w.enc.Close(); err != nil {
+			slog.Debug("结束压缩流失败", "encoding", w.encoding, "error", err)
+		}
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+func isCompressedContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "image/svg") {
+		return false
+	}
+	for _, prefix := range compressedContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// file: internal/transport/http/router/compression_test.go
+package router
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionEngine(opts CompressionOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(compressionMiddleware(opts))
+	large := strings.Repeat("archive ", 500)
+	engine.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	engine.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	engine.GET("/zip", func(c *gin.Context) { c.Data(http.StatusOK, "application/zip", []byte(large)) })
+	engine.GET("/download", noCompression(), func(c *gin.Context) { c.String(http.StatusOK, large) })
+	engine.GET("/raw/file", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	return engine
+}
+
+func serveCompressed(engine *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+	engine.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	engine := newCompressionEngine(CompressionOptions{Enabled: true, Level: 9, ExcludedPaths: []string{"/raw/"}})
+	large := strings.Repeat("archive ", 500)
+
+	rr := serveCompressed(engine, "/large", "gzip, deflate")
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Contains(t, rr.Header().Values("Vary"), "Accept-Encoding")
+	zr, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rr = serveCompressed(engine, "/large", "gzip;q=0.5, deflate")
+	require.Equal(t, "deflate", rr.Header().Get("Content-Encoding"), "按 q 值选择编码")
+	body, err = io.ReadAll(flate.NewReader(rr.Body))
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	for path, reason := range map[string]string{
+		"/small":    "小于最小大小",
+		"/zip":      "已经压缩的内容类型",
+		"/download": "路由选择不压缩",
+		"/raw/file": "排除的路径前缀",
+	} {
+		rr = serveCompressed(engine, path, "gzip")
+		assert.Empty(t, rr.Header().Get("Content-Encoding"), reason)
+		assert.NotEmpty(t, rr.Body.String(), reason)
+	}
+
+	rr = serveCompressed(engine, "/large", "")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"), "客户端未声明支持压缩")
+	rr = serveCompressed(engine, "/large", "br, gzip;q=0")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"), "未开启 br 且拒绝了 gzip")
+	assert.Equal(t, large, rr.Body.String())
+}
+
+func TestCompressionMiddleware_Brotli(t *testing.T) {
+	engine := newCompressionEngine(CompressionOptions{Enabled: true, Brotli: true})
+	large := strings.Repeat("archive ", 500)
+
+	rr := serveCompressed(engine, "/large", "gzip, deflate, br")
+	require.Equal(t, "br", rr.Header().Get("Content-Encoding"), "q 值相同时优先 br")
+	body, err := io.ReadAll(brotli.NewReader(rr.Body))
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rr = serveCompressed(engine, "/large", "br;q=0.1, gzip")
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	rr = serveCompressed(engine, "/small", "br")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"), "小于最小大小")
+}
+
+func TestCompressionMiddleware_Disabled(t *testing.T) {
+	rr := serveCompressed(newCompressionEngine(CompressionOptions{}), "/large", "gzip")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	offered := []string{"br", "gzip", "deflate"}
+	assert.Equal(t, "br", negotiateEncoding("gzip, deflate, br", offered), "q 值相同时优先 br")
+	assert.Equal(t, "gzip", negotiateEncoding("br;q=0.1, gzip", offered))
+	assert.Equal(t, "deflate", negotiateEncoding("*;q=0.2, deflate;q=0.9", offered))
+	assert.Equal(t, "", negotiateEncoding("identity", offered))
+	assert.Equal(t, "", negotiateEncoding("*;q=0", offered))
+}
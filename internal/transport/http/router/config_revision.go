@@ -0,0 +1,170 @@
+// Package router file: internal/transport/http/router/config_revision.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// configWriteMu 串行化视图与字段配置的写入，使 If-Match 的版本检查与随后的全量覆盖之间不会插入其他写入
+var configWriteMu sync.Mutex
+
+// configChange 是提交内容相对服务端当前版本的一处差异，Change 为 added、removed 或 changed
+type configChange struct {
+	Table     string      `json:"table,omitempty"`
+	Name      string      `json:"name"`
+	Change    string      `json:"change"`
+	Current   interface{} `json:"current,omitempty"`
+	Submitted interface{} `json:"submitted,omitempty"`
+}
+
+// configETag 返回资源当前内容的 ETag，与对应 GET 接口 (etagJSON) 返回的 ETag 相同
+func configETag(payload any) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return contentETag(body), nil
+}
+
+// checkIfMatch 实现配置写入的乐观锁：请求带 If-Match 且与资源当前的 ETag 不匹配时，说明客户端编辑期间
+// 已有其他管理员修改了该资源，返回 409、当前 ETag 以及提交内容相对当前版本的差异，并返回 false。
+// 不带 If-Match 的请求保持原有的直接覆盖行为。调用方需持有 configWriteMu
+func checkIfMatch(c *gin.Context, current any, diff func() []configChange) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	etag, err := configETag(current)
+	if err != nil {
+		_ = c.Error(err)
+		return false
+	}
+	if etagMatches(ifMatch, etag) {
+		return true
+	}
+	c.Header("ETag", etag)
+	c.JSON(http.StatusConflict, gin.H{
+		"error":        "配置已被其他管理员修改，请基于当前版本合并后重新提交",
+		"current_etag": etag,
+		"data":         gin.H{"changes": diff()},
+	})
+	return false
+}
+
+// setConfigETag 在写入成功后返回资源新的 ETag，客户端可以直接用于下一次修改的 If-Match
+func setConfigETag(c *gin.Context, current any) {
+	if etag, err := configETag(current); err == nil {
+		c.Header("ETag", etag)
+	}
+}
+
+// loadBizViews 读取业务组的全部视图，与 GET /views 返回的内容相同
+func loadBizViews(ctx context.Context, configService port.QueryAdminConfigService, bizName string) (map[string][]*domain.ViewConfig, error) {
+	views, err := configService.GetAllViewConfigsForBiz(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if views == nil {
+		views = make(map[string][]*domain.ViewConfig)
+	}
+	return views, nil
+}
+
+// loadTableFields 读取表的字段配置并按字段名排序，与 GET /tables/:tableName/fields 返回的内容相同
+func loadTableFields(ctx context.Context, configService port.QueryAdminConfigService, bizName, tableName string) ([]domain.FieldSetting, error) {
+	bizConfig, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if bizConfig == nil {
+		return nil, port.ErrBizNotFound
+	}
+	fields := make([]domain.FieldSetting, 0)
+	if table, exists := bizConfig.Tables[tableName]; exists {
+		for _, setting := range table.Fields {
+			fields = append(fields, setting)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].FieldName < fields[j].FieldName })
+	return fields, nil
+}
+
+// diffViews 按表与视图名比较提交的视图与当前的视图
+func diffViews(current, submitted map[string][]*domain.ViewConfig) []configChange {
+	index := func(views map[string][]*domain.ViewConfig) map[[2]string]*domain.ViewConfig {
+		out := make(map[[2]string]*domain.ViewConfig)
+		for tableName, list := range views {
+			for _, view := range list {
+				if view != nil {
+					out[[2]string{tableName, view.ViewName}] = view
+				}
+			}
+		}
+		return out
+	}
+	cur, sub := index(current), index(submitted)
+	changes := make([]configChange, 0)
+	for key, view := range sub {
+		if old, exists := cur[key]; !exists {
+			changes = append(changes, configChange{Table: key[0], Name: key[1], Change: "added", Submitted: view})
+		} else if !sameJSON(old, view) {
+			changes = append(changes, configChange{Table: key[0], Name: key[1], Change: "changed", Current: old, Submitted: view})
+		}
+	}
+	for key, old := range cur {
+		if _, exists := sub[key]; !exists {
+			changes = append(changes, configChange{Table: key[0], Name: key[1], Change: "removed", Current: old})
+		}
+	}
+	sortConfigChanges(changes)
+	return changes
+}
+
+// diffFields 按字段名比较提交的字段配置与当前的字段配置
+func diffFields(current, submitted []domain.FieldSetting) []configChange {
+	cur := make(map[string]domain.FieldSetting, len(current))
+	for _, f := range current {
+		cur[f.FieldName] = f
+	}
+	seen := make(map[string]bool, len(submitted))
+	changes := make([]configChange, 0)
+	for _, f := range submitted {
+		seen[f.FieldName] = true
+		if old, exists := cur[f.FieldName]; !exists {
+			changes = append(changes, configChange{Name: f.FieldName, Change: "added", Submitted: f})
+		} else if old != f {
+			changes = append(changes, configChange{Name: f.FieldName, Change: "changed", Current: old, Submitted: f})
+		}
+	}
+	for _, f := range current {
+		if !seen[f.FieldName] {
+			changes = append(changes, configChange{Name: f.FieldName, Change: "removed", Current: f})
+		}
+	}
+	sortConfigChanges(changes)
+	return changes
+}
+
+// sameJSON 按序列化结果比较两个配置，省略的字段与空值视为相同
+func sameJSON(a, b any) bool {
+	ab, errA := json.Marshal(a)
+	bb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ab) == string(bb)
+}
+
+func sortConfigChanges(changes []configChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Table != changes[j].Table {
+			return changes[i].Table < changes[j].Table
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
@@ -0,0 +1,90 @@
+// file: internal/transport/http/router/config_revision_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	current := []domain.FieldSetting{
+		{FieldName: "place", IsReturnable: true},
+		{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+	}
+	submitted := []domain.FieldSetting{
+		{FieldName: "sender", IsSearchable: true},
+		{FieldName: "year", IsReturnable: true},
+	}
+	engine := gin.New()
+	engine.GET("/fields", func(c *gin.Context) { etagJSON(c, current) })
+	engine.PUT("/fields", func(c *gin.Context) {
+		if checkIfMatch(c, current, func() []configChange { return diffFields(current, submitted) }) {
+			c.Status(http.StatusNoContent)
+		}
+	})
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/fields", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := httptest.NewRecorder()
+	engine.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fields", nil))
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	assert.Equal(t, http.StatusNoContent, put("").Code, "不带 If-Match 时直接覆盖")
+	assert.Equal(t, http.StatusNoContent, put(etag).Code)
+	assert.Equal(t, http.StatusNoContent, put("*").Code)
+
+	rr = put(`"stale"`)
+	require.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+	var body struct {
+		CurrentETag string `json:"current_etag"`
+		Data        struct {
+			Changes []configChange `json:"changes"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, etag, body.CurrentETag)
+	require.Len(t, body.Data.Changes, 3)
+	assert.Equal(t, []string{"place", "sender", "year"},
+		[]string{body.Data.Changes[0].Name, body.Data.Changes[1].Name, body.Data.Changes[2].Name})
+	assert.Equal(t, []string{"removed", "changed", "added"},
+		[]string{body.Data.Changes[0].Change, body.Data.Changes[1].Change, body.Data.Changes[2].Change})
+}
+
+func TestDiffViews(t *testing.T) {
+	current := map[string][]*domain.ViewConfig{
+		"letters": {
+			{ViewName: "all", ViewType: "table"},
+			{ViewName: "cards", ViewType: "cards"},
+		},
+	}
+	submitted := map[string][]*domain.ViewConfig{
+		"letters": {
+			{ViewName: "all", ViewType: "table", DefaultFilters: []domain.ViewFilter{}},
+			{ViewName: "cards", ViewType: "cards", IsDefault: true},
+		},
+		"readers": {{ViewName: "all", ViewType: "table"}},
+	}
+	changes := diffViews(current, submitted)
+	require.Len(t, changes, 2, "省略的字段与空值视为相同")
+	assert.Equal(t, configChange{Table: "letters", Name: "cards", Change: "changed", Current: current["letters"][1], Submitted: submitted["letters"][1]}, changes[0])
+	assert.Equal(t, "readers", changes[1].Table)
+	assert.Equal(t, "added", changes[1].Change)
+	assert.Empty(t, diffViews(current, current))
+}
@@ -0,0 +1,113 @@
+// Package router file: internal/transport/http/router/config_template_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/configtemplates"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondConfigTemplateError 把配置模板服务的错误映射为 400/404/422，其余交给错误中间件
+func respondConfigTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, configtemplates.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, configtemplates.ErrTemplateNotFound), errors.Is(err, port.ErrBizNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, port.ErrPhysicalSchemaUnsupported):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listConfigTemplatesHandler 列出所有配置模板
+func listConfigTemplatesHandler(templates *configtemplates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		list, err := templates.List(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// getConfigTemplateHandler 返回一个配置模板
+func getConfigTemplateHandler(templates *configtemplates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tmpl, err := templates.Get(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			respondConfigTemplateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": tmpl})
+	}
+}
+
+// saveConfigTemplateHandler 创建或更新一个配置模板，模板名称取自路径
+func saveConfigTemplateHandler(templates *configtemplates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload domain.ConfigTemplate
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		payload.Name = c.Param("name")
+		for i, rule := range payload.Views {
+			if err := validateViewConfig(&rule.View); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第 %d 个视图 '%s': %v", i+1, rule.View.ViewName, err)})
+				return
+			}
+		}
+		tmpl, err := templates.Save(c.Request.Context(), payload, requestUserID(c))
+		if err != nil {
+			respondConfigTemplateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": tmpl})
+	}
+}
+
+// deleteConfigTemplateHandler 删除一个配置模板
+func deleteConfigTemplateHandler(templates *configtemplates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if err := templates.Delete(c.Request.Context(), name); err != nil {
+			respondConfigTemplateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("配置模板 '%s' 已删除", name)})
+	}
+}
+
+// applyConfigTemplateHandler 将配置模板应用到业务组，请求体包含 "template" 与可选的 "dry_run"、"overwrite"。
+// 响应的 data 为按表列出的字段、视图与速率限制差异
+func applyConfigTemplateHandler(templates *configtemplates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Template  string `json:"template" binding:"required"`
+			DryRun    bool   `json:"dry_run"`
+			Overwrite bool   `json:"overwrite"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		report, err := templates.Apply(c.Request.Context(), payload.Template, c.Param("bizName"), payload.DryRun, payload.Overwrite)
+		if err != nil {
+			respondConfigTemplateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
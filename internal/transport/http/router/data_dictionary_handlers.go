@@ -0,0 +1,87 @@
+// Package router file: internal/transport/http/router/data_dictionary_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/datadict"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listTableDataDictionaryHandler 返回表中各字段已填写的数据字典
+func listTableDataDictionaryHandler(dataDict *datadict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := dataDict.List(c.Request.Context(), c.Param("bizName"), c.Param("tableName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": entries})
+	}
+}
+
+// saveFieldDataDictionaryHandler 创建或替换字段的数据字典，请求体为
+// {"description": "...", "source": "...", "transformation": "...", "vocabulary_link": "..."}
+func saveFieldDataDictionaryHandler(dataDict *datadict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Description    string `json:"description"`
+			Source         string `json:"source"`
+			Transformation string `json:"transformation"`
+			VocabularyLink string `json:"vocabulary_link"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		entry, err := dataDict.Save(c.Request.Context(), domain.FieldDictionaryEntry{
+			BizName:        c.Param("bizName"),
+			TableName:      c.Param("tableName"),
+			FieldName:      c.Param("fieldName"),
+			Description:    payload.Description,
+			Source:         payload.Source,
+			Transformation: payload.Transformation,
+			VocabularyLink: payload.VocabularyLink,
+		}, requestUserID(c))
+		if err != nil {
+			if errors.Is(err, datadict.ErrInvalidEntry) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": entry})
+	}
+}
+
+// deleteFieldDataDictionaryHandler 删除字段的数据字典
+func deleteFieldDataDictionaryHandler(dataDict *datadict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fieldName := c.Param("fieldName")
+		if err := dataDict.Delete(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), fieldName); err != nil {
+			if errors.Is(err, datadict.ErrEntryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("字段 '%s' 的数据字典已删除", fieldName)})
+	}
+}
+
+// dataDictionaryHandlerV1 向检索者公开业务组的数据字典，只包含可检索或可返回的字段
+func dataDictionaryHandlerV1(dataDict *datadict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := dataDict.ListPublic(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		etagJSON(c, gin.H{"data": entries})
+	}
+}
@@ -0,0 +1,118 @@
+// Package router file: internal/transport/http/router/datasubject_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/datasubject"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDataSubjectError 把数据主体请求的错误映射为 400，其余交给错误中间件
+func respondDataSubjectError(c *gin.Context, err error) {
+	if errors.Is(err, datasubject.ErrInvalidRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_ = c.Error(err)
+}
+
+// searchDataSubjectHandler 在所有业务组中检索与个人标识匹配的记录，
+// 请求体为 {"identifier": "...", "fields": [...], "bizs": [...]}，fields 与 bizs 可省略
+func searchDataSubjectHandler(subjects *datasubject.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subjects == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "数据主体请求工具未启用"})
+			return
+		}
+		var req datasubject.Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+			return
+		}
+		result, err := subjects.Search(c.Request.Context(), req, requestUserID(c))
+		if err != nil {
+			respondDataSubjectError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// exportDataSubjectHandler 以 zip 压缩包导出与个人标识匹配的记录，请求体与检索相同
+func exportDataSubjectHandler(subjects *datasubject.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subjects == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "数据主体请求工具未启用"})
+			return
+		}
+		var req datasubject.Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+			return
+		}
+		result, err := subjects.Export(c.Request.Context(), req, requestUserID(c))
+		if err != nil {
+			respondDataSubjectError(c, err)
+			return
+		}
+
+		now := time.Now()
+		fileName := fmt.Sprintf("data-subject-%s-%s.zip", result.IdentifierHash[:12], now.Format("20060102-150405"))
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+		if err := datasubject.WriteBundle(c.Writer, result, now); err != nil {
+			// 响应体已经开始写入，只能中断连接
+			slog.Error("exportDataSubjectHandler 写入压缩包失败", "error", err)
+		}
+	}
+}
+
+// eraseDataSubjectHandler 删除或匿名化与个人标识匹配的记录，请求体在检索条件之外包含
+// "mode" ("delete" 或 "anonymize") 与可选的 "dry_run"。返回逐表结果与审计记录编号
+func eraseDataSubjectHandler(subjects *datasubject.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subjects == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "数据主体请求工具未启用"})
+			return
+		}
+		var req datasubject.EraseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+			return
+		}
+		result, err := subjects.Erase(c.Request.Context(), req, requestUserID(c))
+		if err != nil {
+			respondDataSubjectError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// listDataSubjectRequestsHandler 按时间倒序列出数据主体请求的审计记录。
+// ?identifier= 只列出该标识的记录 (按摘要比对)，?limit= 控制数量
+func listDataSubjectRequestsHandler(subjects *datasubject.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subjects == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "数据主体请求工具未启用"})
+			return
+		}
+		var hash string
+		if identifier := c.Query("identifier"); identifier != "" {
+			hash = datasubject.HashIdentifier(identifier)
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		list, err := subjects.ListRequests(c.Request.Context(), hash, limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": list})
+	}
+}
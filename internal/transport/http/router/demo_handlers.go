@@ -0,0 +1,42 @@
+// Package router file: internal/transport/http/router/demo_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/demo"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// seedDemoHandler 一次性生成演示业务组（库文件、表与字段配置、视图）
+func seedDemoHandler(demoService *demo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			BizName string `json:"biz_name"`
+			Force   bool   `json:"force"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&payload); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		result, err := demoService.Seed(c.Request.Context(), payload.BizName, payload.Force)
+		if err != nil {
+			switch {
+			case errors.Is(err, demo.ErrDemoExists):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "，如需重新生成请设置 force=true"})
+			case errors.Is(err, demo.ErrInvalidBizName):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{
+			"data":    result,
+			"message": "演示业务组已生成，请为其创建并启动一个 SQLite 插件实例后即可查询",
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package router file: internal/transport/http/router/diagnostics_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/diagnostics"
+	"ArchiveAegis/internal/service/doctor"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// doctorHandler 重新执行启动自检并返回全部检查结果
+func doctorHandler(doctorService *doctor.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": doctorService.Run(c.Request.Context())})
+	}
+}
+
+// getDiagnosticsStatusHandler 返回诊断功能的开关状态
+func getDiagnosticsStatusHandler(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"enabled": diagnosticsService.Enabled()}})
+	}
+}
+
+// updateDiagnosticsStatusHandler 开启或关闭诊断功能，状态会持久化
+func updateDiagnosticsStatusHandler(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Enabled *bool `json:"enabled" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if err := diagnosticsService.SetEnabled(c.Request.Context(), *payload.Enabled); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"enabled": *payload.Enabled}})
+	}
+}
+
+// requireDiagnostics 只在诊断功能开启时放行
+func requireDiagnostics(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !diagnosticsService.Enabled() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": diagnostics.ErrDiagnosticsDisabled.Error() + "，请先通过 PUT /api/v1/admin/debug/status 开启"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// runtimeStatsHandler 返回运行时统计：GC、goroutine 数、文件描述符数等
+func runtimeStatsHandler(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": diagnosticsService.Stats()})
+	}
+}
+
+// goroutineDumpHandler 按需生成 goroutine 转储，以附件形式返回
+func goroutineDumpHandler(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="goroutines-%s.txt"`, time.Now().Format("20060102-150405")))
+		if err := diagnosticsService.WriteGoroutineDump(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// heapDumpHandler 按需生成堆转储（pprof 格式），以附件形式返回
+func heapDumpHandler(diagnosticsService *diagnostics.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="heap-%s.pprof"`, time.Now().Format("20060102-150405")))
+		if err := diagnosticsService.WriteHeapDump(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// pprofHandler 将 /debug/pprof/*profile 分派给 net/http/pprof。
+// pprof.Index 依赖固定的 /debug/pprof/ 路径前缀解析分析名，挂在 /api/v1/admin 下时需要自行分派。
+func pprofHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("profile"), "/")
+		switch name {
+		case "":
+			pprof.Index(c.Writer, c.Request)
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package router file: internal/transport/http/router/digest_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/digest"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDigestError 把摘要错误映射为 400/404，其余交给错误中间件
+func respondDigestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, digest.ErrInvalidDay):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, digest.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listDigestsHandler 按日期倒序列出最近的每日摘要，?limit= 控制数量
+func listDigestsHandler(digests *digest.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if digests == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "每日摘要未启用"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		list, err := digests.List(c.Request.Context(), limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": list})
+	}
+}
+
+// getDigestHandler 返回指定日期 (YYYY-MM-DD，UTC) 的摘要
+func getDigestHandler(digests *digest.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if digests == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "每日摘要未启用"})
+			return
+		}
+		d, err := digests.Get(c.Request.Context(), c.Param("day"))
+		if err != nil {
+			respondDigestError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": d})
+	}
+}
+
+// generateDigestHandler 立即生成并投递摘要，请求体可携带 {"day": "YYYY-MM-DD"}，默认为前一天
+func generateDigestHandler(digests *digest.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if digests == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "每日摘要未启用"})
+			return
+		}
+		var body struct {
+			Day string `json:"day"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		if body.Day == "" {
+			body.Day = time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		d, err := digests.Generate(c.Request.Context(), body.Day)
+		if err != nil {
+			respondDigestError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": d})
+	}
+}
@@ -0,0 +1,191 @@
+// Package router file: internal/transport/http/router/embed_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/embed"
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// embedTableTemplate 是 format=html 时输出的极简表格，便于直接放入 iframe。
+// 签名参数作为隐藏字段随检索表单提交，访问者只能改变检索词与页码
+var embedTableTemplate = template.Must(template.New("embed").Funcs(template.FuncMap{
+	"cell": func(item map[string]interface{}, field string) string {
+		if v, ok := item[field]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Result.Table}}</title>
+<style>body{font-family:sans-serif;font-size:14px;margin:8px}table{border-collapse:collapse;width:100%}th,td{border:1px solid #ddd;padding:4px 6px;text-align:left}th{background:#f5f5f5}nav{margin-top:6px}</style>
+</head><body>
+{{if .Searchable}}<form method="get">{{range $name, $values := .Hidden}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">{{end}}{{end}}<input type="search" name="q" value="{{.Q}}"> <button type="submit">检索</button></form>{{end}}
+<table><thead><tr>{{range .Result.Fields}}<th>{{.}}</th>{{end}}</tr></thead><tbody>
+{{range $item := .Result.Items}}<tr>{{range $.Result.Fields}}<td>{{cell $item .}}</td>{{end}}</tr>
+{{end}}</tbody></table>
+<nav>共 {{.Result.Total}} 条{{if .PrevURL}} · <a href="{{.PrevURL}}">上一页</a>{{end}}{{if .NextURL}} · <a href="{{.NextURL}}">下一页</a>{{end}}</nav>
+</body></html>`))
+
+// respondEmbedError 把嵌入接口的错误映射为 400/403/404，其余交给错误中间件
+func respondEmbedError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, embed.ErrInvalidEmbed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, embed.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, embed.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// embedQueryHandler 供外部网站匿名嵌入签名过的检索，默认返回 JSON，format=html 时返回极简表格。
+// 来源取自 Origin，iframe 请求不带 Origin 时取自 Referer
+func embedQueryHandler(embeds *embed.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := c.Request.URL.Query()
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			origin = embed.OriginFromReferer(c.GetHeader("Referer"))
+		}
+		result, err := embeds.Query(c.Request.Context(), params, origin)
+		if err != nil {
+			respondEmbedError(c, err)
+			return
+		}
+		if params.Get("format") != "html" {
+			c.JSON(http.StatusOK, gin.H{"data": result})
+			return
+		}
+
+		pageURL := func(page int) string {
+			next := url.Values{}
+			for k, v := range params {
+				next[k] = v
+			}
+			next.Set("page", strconv.Itoa(page))
+			return "?" + next.Encode()
+		}
+		hidden := url.Values{}
+		for k, v := range params {
+			if k != "q" && k != "page" {
+				hidden[k] = v
+			}
+		}
+		view := gin.H{"Result": result, "Q": params.Get("q"), "Searchable": params.Get("search") != "", "Hidden": hidden}
+		if result.Page > 1 {
+			view["PrevURL"] = pageURL(result.Page - 1)
+		}
+		if int64(result.Page*result.Size) < result.Total {
+			view["NextURL"] = pageURL(result.Page + 1)
+		}
+		var buf bytes.Buffer
+		if err := embedTableTemplate.Execute(&buf, view); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	}
+}
+
+// listEmbedKeysHandler 列出全部嵌入密钥，不含签名密钥
+func listEmbedKeysHandler(embeds *embed.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		keys, err := embeds.ListKeys(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, keys, page)
+	}
+}
+
+// createEmbedKeyHandler 为业务组签发嵌入密钥，签名密钥只在响应中出现这一次
+func createEmbedKeyHandler(embeds *embed.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Label          string   `json:"label"`
+			BizName        string   `json:"biz_name" binding:"required"`
+			AllowedOrigins []string `json:"allowed_origins"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		key, secret, err := embeds.CreateKey(c.Request.Context(), body.Label, body.BizName, body.AllowedOrigins)
+		if err != nil {
+			respondEmbedError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": gin.H{"key": key, "secret": secret}})
+	}
+}
+
+func embedKeyIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的嵌入密钥 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// revokeEmbedKeyHandler 撤销嵌入密钥
+func revokeEmbedKeyHandler(embeds *embed.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := embedKeyIDParam(c)
+		if !ok {
+			return
+		}
+		if err := embeds.RevokeKey(c.Request.Context(), id); err != nil {
+			respondEmbedError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "嵌入密钥已撤销"})
+	}
+}
+
+// signEmbedHandler 用嵌入密钥为一组参数签名并返回完整的嵌入地址，
+// 请求体为 {"params": {"table": "...", "search": "...", "filter.<字段>": "...", ...}}
+func signEmbedHandler(embeds *embed.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := embedKeyIDParam(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Params map[string]string `json:"params" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		params := url.Values{}
+		for k, v := range body.Params {
+			params.Set(k, v)
+		}
+		signed, err := embeds.Sign(c.Request.Context(), id, params)
+		if err != nil {
+			respondEmbedError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{
+			"params": signed,
+			"url":    requestBaseURL(c) + "/api/v1/embed?" + signed.Encode(),
+		}})
+	}
+}
@@ -0,0 +1,39 @@
+// file: internal/transport/http/router/embed_handlers_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/embed"
+	"bytes"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedTableTemplate_EscapesContent(t *testing.T) {
+	result := &embed.Result{
+		Table:  "books",
+		Fields: []string{"title", "note"},
+		Items: []map[string]interface{}{
+			{"title": "<script>alert(1)</script>", "note": nil},
+		},
+		Total: 3, Page: 1, Size: 1,
+	}
+	var buf bytes.Buffer
+	require.NoError(t, embedTableTemplate.Execute(&buf, gin.H{
+		"Result":     result,
+		"Q":          `"><img>`,
+		"Searchable": true,
+		"Hidden":     url.Values{"sig": {"abc"}},
+		"NextURL":    "?page=2&sig=abc",
+	}))
+	html := buf.String()
+	assert.NotContains(t, html, "<script>alert(1)</script>", "记录内容必须被转义")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.NotContains(t, html, `"><img>`, "检索词必须被转义")
+	assert.Contains(t, html, `<input type="hidden" name="sig" value="abc">`)
+	assert.Contains(t, html, "<td></td>", "空值输出为空单元格")
+	assert.Contains(t, html, `href="?page=2&amp;sig=abc"`)
+}
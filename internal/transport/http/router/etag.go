@@ -0,0 +1,53 @@
+// Package router file: internal/transport/http/router/etag.go
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagJSON 以 JSON 返回 payload，并附带由其内容哈希得到的 ETag。
+// 请求的 If-None-Match 与之匹配时返回 304 且不带响应体。适用于 Schema、视图与业务组配置等很少变化、
+// 但被前端频繁拉取的接口：配置修改后序列化结果随之变化，ETag 即为配置的修订标识，无需另外维护版本号。
+// Cache-Control 由所在路由组的缓存策略决定 (见 cachePolicy)
+func etagJSON(c *gin.Context, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	etagData(c, "application/json; charset=utf-8", body)
+}
+
+// etagData 与 etagJSON 相同，但直接返回已生成的响应体，用于生成的源码等非 JSON 内容
+func etagData(c *gin.Context, contentType string, body []byte) {
+	etag := contentETag(body)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// contentETag 返回由响应体内容哈希得到的 ETag
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches 按弱比较判断 If-None-Match 中是否有与 etag 相同的值 (RFC 9110 §13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
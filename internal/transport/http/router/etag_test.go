@@ -0,0 +1,45 @@
+// file: internal/transport/http/router/etag_test.go
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtagJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	payload := gin.H{"data": "v1"}
+	engine := gin.New()
+	engine.GET("/schema", func(c *gin.Context) { etagJSON(c, payload) })
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/schema", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data":"v1"}`, rr.Body.String())
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rr = serve(etag)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, serve(`"other", W/`+etag).Code, "列表与弱比较")
+
+	payload["data"] = "v2"
+	rr = serve(etag)
+	assert.Equal(t, http.StatusOK, rr.Code, "内容变化后 ETag 随之变化")
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+}
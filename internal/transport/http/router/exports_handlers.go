@@ -0,0 +1,69 @@
+// Package router file: internal/transport/http/router/exports_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/exports"
+	"ArchiveAegis/internal/service/jobs"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startExportHandler 启动记录导出任务，请求体见 exports.Request。
+// 返回的任务可通过 /admin/jobs/:id 查询进度，成功后经 /admin/exports/:id/download 下载
+func startExportHandler(exportService *exports.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if exportService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录导出未启用"})
+			return
+		}
+		var req exports.Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+			return
+		}
+		job, err := exportService.Start(c.Request.Context(), req, requestUserID(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, exports.ErrInvalidRequest):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, jobs.ErrJobConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": job})
+	}
+}
+
+// downloadExportHandler 下载已完成的导出任务生成的 zip 压缩包
+func downloadExportHandler(exportService *exports.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if exportService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "记录导出未启用"})
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务 ID"})
+			return
+		}
+		path, name, err := exportService.Archive(c.Request.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, exports.ErrNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, exports.ErrNotReady):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.FileAttachment(path, name)
+	}
+}
@@ -0,0 +1,103 @@
+// Package router file: internal/transport/http/router/field_bulk_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/impact"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bulkFieldsAction 是批量修改字段配置的路由后缀，路由注册为 "/:bizName/fields:action"
+const bulkFieldsAction = ":bulk"
+
+// adminBulkUpdateFieldSettingsHandler 处理 PUT /:bizName/fields:bulk，请求体为表名到字段配置列表的映射，
+// 每张表的字段配置与 PUT /tables/:tableName/fields 相同 (全量替换)。
+// 先逐表校验并做影响分析，任一张表有误时返回 400，有破坏性修改且未带 ?force=true 时返回 409，均不做任何修改；
+// 全部通过后在一个事务中应用。响应的 data 为各表的校验结果
+func adminBulkUpdateFieldSettingsHandler(configService port.QueryAdminConfigService, fieldImpact *impact.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param("action") != bulkFieldsAction {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未知的操作"})
+			return
+		}
+		bizName := c.Param("bizName")
+		var payload map[string][]domain.FieldSetting
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if len(payload) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体至少需要包含一张表的字段配置"})
+			return
+		}
+		cfg, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": port.ErrBizNotFound.Error()})
+			return
+		}
+
+		tableNames := make([]string, 0, len(payload))
+		for tableName := range payload {
+			tableNames = append(tableNames, tableName)
+		}
+		sort.Strings(tableNames)
+
+		results := make([]domain.FieldSettingsBulkResult, 0, len(tableNames))
+		var invalid, breaking int
+		for _, tableName := range tableNames {
+			fields := payload[tableName]
+			result := domain.FieldSettingsBulkResult{TableName: tableName, Fields: len(fields), Errors: []string{}}
+			if tableName == "" {
+				result.Errors = append(result.Errors, "表名不能为空")
+			} else if err := impact.Lint(fields); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+			if len(result.Errors) > 0 {
+				invalid++
+				results = append(results, result)
+				continue
+			}
+			if fieldImpact != nil {
+				report, err := fieldImpact.AnalyzeFieldSettings(c.Request.Context(), bizName, tableName, fields)
+				if err != nil {
+					respondImpactError(c, err)
+					return
+				}
+				result.Impact = report
+				if report.Breaking {
+					breaking++
+				}
+			}
+			results = append(results, result)
+		}
+
+		if invalid > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "部分表的字段配置有误，未做任何修改", "data": results})
+			return
+		}
+		if breaking > 0 && c.Query("force") != "true" {
+			c.JSON(http.StatusConflict, gin.H{"error": "该修改会使部分视图、分享链接或保存的查询失效，确认后请带 force=true 重新提交", "data": results})
+			return
+		}
+		if breaking > 0 {
+			slog.Warn("审计日志: 强制批量应用破坏性的字段配置修改", "biz", bizName, "tables", len(results), "breaking_tables", breaking, "user_id", requestUserID(c))
+		}
+		// 与单表的字段配置写入串行，避免插入其版本检查与写入之间
+		configWriteMu.Lock()
+		defer configWriteMu.Unlock()
+		if err := configService.UpdateFieldSettingsBulk(c.Request.Context(), bizName, payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": results})
+	}
+}
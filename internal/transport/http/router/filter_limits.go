@@ -0,0 +1,38 @@
+// Package router file: internal/transport/http/router/filter_limits.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"fmt"
+)
+
+// defaultMaxInValues 是未配置时 "in" 运算符取值列表的最大长度
+const defaultMaxInValues = 1000
+
+// errFilterListTooLarge 表示过滤条件的取值列表超过了网关允许的长度
+var errFilterListTooLarge = errors.New("过滤条件的取值列表过长")
+
+// checkInFilters 检查查询中 "in" 运算符的取值列表不超过 maxInValues (不大于 0 时取默认值)。
+// 限制在网关侧执行，对所有数据源插件生效；列表的具体绑定方式由各插件决定
+func checkInFilters(query map[string]interface{}, maxInValues int) error {
+	if maxInValues <= 0 {
+		maxInValues = defaultMaxInValues
+	}
+	filters, _ := query["filters"].([]interface{})
+	for _, f := range filters {
+		filterMap, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if op, _ := filterMap["op"].(string); op != "in" {
+			continue
+		}
+		values, _ := filterMap[port.FilterValuesKey].([]interface{})
+		if len(values) > maxInValues {
+			field, _ := filterMap["field"].(string)
+			return fmt.Errorf("%w: 字段 '%s' 的 'in' 列表有 %d 个值，最多允许 %d 个", errFilterListTooLarge, field, len(values), maxInValues)
+		}
+	}
+	return nil
+}
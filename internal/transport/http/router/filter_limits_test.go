@@ -0,0 +1,18 @@
+// file: internal/transport/http/router/filter_limits_test.go
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckInFilters(t *testing.T) {
+	query := map[string]interface{}{"filters": []interface{}{
+		map[string]interface{}{"field": "id", "op": "in", "values": []interface{}{"1", "2", "3"}},
+		map[string]interface{}{"field": "name", "values": []interface{}{"a", "b", "c", "d"}},
+	}}
+	assert.NoError(t, checkInFilters(query, 3))
+	assert.ErrorIs(t, checkInFilters(query, 2), errFilterListTooLarge)
+	assert.NoError(t, checkInFilters(query, 0), "未配置时取默认上限")
+}
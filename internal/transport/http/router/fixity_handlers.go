@@ -0,0 +1,63 @@
+// Package router file: internal/transport/http/router/fixity_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/fixity"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listFixityChecksumsHandler 列出所有已登记的库文件校验值
+func listFixityChecksumsHandler(fixityService *fixity.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if fixityService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件校验未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		list, err := fixityService.ListChecksums(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// listFixityEventsHandler 按时间倒序列出校验事件，?biz= 只列出该业务组，?limit= 控制数量
+func listFixityEventsHandler(fixityService *fixity.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if fixityService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件校验未启用"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		events, err := fixityService.ListEvents(c.Request.Context(), c.Query("biz"), limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": events})
+	}
+}
+
+// verifyFixityHandler 立即校验所有库文件，返回本次产生的事件
+func verifyFixityHandler(fixityService *fixity.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if fixityService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件校验未启用"})
+			return
+		}
+		run, err := fixityService.Verify(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": run})
+	}
+}
@@ -0,0 +1,74 @@
+// Package router file: internal/transport/http/router/fulltext_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/fulltext"
+	"ArchiveAegis/internal/service/jobs"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updateTableFTSConfigHandler 保存表的全文检索配置 (字段、分词器、停用词)，索引内容受影响时在后台重建索引
+func updateTableFTSConfigHandler(fullText *fulltext.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var cfg domain.FTSConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		saved, job, err := fullText.UpdateConfig(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), cfg, requestUserID(c))
+		if err != nil {
+			if errors.Is(err, fulltext.ErrInvalidConfig) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"config": saved, "job": job}})
+	}
+}
+
+// deleteTableFTSConfigHandler 清除表的全文检索配置，并在后台删除已建立的索引
+func deleteTableFTSConfigHandler(fullText *fulltext.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := fullText.DeleteConfig(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), requestUserID(c))
+		if err != nil {
+			if errors.Is(err, fulltext.ErrInvalidConfig) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"job": job}})
+	}
+}
+
+// rebuildTableFTSHandler 按当前配置在后台重建表的全文索引，可通过 /admin/jobs/:id 查询进度
+func rebuildTableFTSHandler(fullText *fulltext.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := fullText.Rebuild(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), requestUserID(c))
+		if err != nil {
+			if errors.Is(err, jobs.ErrJobConflict) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": job})
+	}
+}
+
+// requestUserID 返回发起请求的用户 ID，未认证时为 0
+func requestUserID(c *gin.Context) int64 {
+	if claims := service.ClaimFrom(c.Request); claims != nil {
+		return claims.ID
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+// Package router file: internal/transport/http/router/hierarchy_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/fieldguard"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTreePageSize 是树形浏览中一次返回的子节点数上限
+const maxTreePageSize = 500
+
+// updateTableHierarchyConfigHandler 保存表的层级关系配置。键字段与父字段必须是已配置且可返回的不同字段，
+// 排序字段 (可选) 必须是已配置的字段
+func updateTableHierarchyConfigHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		var cfg domain.HierarchyConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg.KeyField == "" || cfg.ParentField == "" || cfg.KeyField == cfg.ParentField {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "key_field 与 parent_field 必须是两个不同的字段"})
+			return
+		}
+
+		bizConfig, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if bizConfig == nil {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+		tableConfig, ok := bizConfig.Tables[tableName]
+		if !ok {
+			_ = c.Error(port.ErrTableNotFoundInBiz)
+			return
+		}
+		for _, f := range []string{cfg.KeyField, cfg.ParentField} {
+			if setting, ok := tableConfig.Fields[f]; !ok || !setting.IsReturnable {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("字段 '%s' 未配置或不可返回", f)})
+				return
+			}
+		}
+		if _, ok := tableConfig.Fields[cfg.SortField]; cfg.SortField != "" && !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("排序字段 '%s' 未配置", cfg.SortField)})
+			return
+		}
+
+		if err := configService.UpdateTableHierarchyConfig(c.Request.Context(), bizName, tableName, &cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": cfg})
+	}
+}
+
+// deleteTableHierarchyConfigHandler 清除表的层级关系配置，之后该表不再支持树形浏览
+func deleteTableHierarchyConfigHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		if err := configService.UpdateTableHierarchyConfig(c.Request.Context(), bizName, tableName, nil); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("表 '%s/%s' 的层级关系配置已清除", bizName, tableName)})
+	}
+}
+
+// treeChildrenHandler 分页返回节点的直接子节点，?node= 省略时返回根节点
+func treeChildrenHandler(registry map[string]port.DataSource, fieldGuard *fieldguard.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := map[string]interface{}{port.QueryModeKey: port.QueryModeChildren}
+		for _, key := range []string{"page", "size"} {
+			raw := c.Query(key)
+			if raw == "" {
+				continue
+			}
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s 必须是正整数", key)})
+				return
+			}
+			if key == "size" && n > maxTreePageSize {
+				n = maxTreePageSize
+			}
+			query[key] = float64(n)
+		}
+		treeQuery(c, registry, fieldGuard, query)
+	}
+}
+
+// treeAncestorsHandler 返回从根节点到 ?node= 的路径，节点不存在时返回 404
+func treeAncestorsHandler(registry map[string]port.DataSource, fieldGuard *fieldguard.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("node") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "必须通过 ?node= 指定节点"})
+			return
+		}
+		treeQuery(c, registry, fieldGuard, map[string]interface{}{port.QueryModeKey: port.QueryModeAncestors})
+	}
+}
+
+// treeSubtreeHandler 返回 ?node= 子树中各层的后代数量，node 省略时统计整棵树
+func treeSubtreeHandler(registry map[string]port.DataSource, fieldGuard *fieldguard.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		treeQuery(c, registry, fieldGuard, map[string]interface{}{port.QueryModeKey: port.QueryModeSubtree})
+	}
+}
+
+// treeQuery 以给定的层级浏览模式查询数据源，返回的节点同样经 fieldGuard 过滤
+func treeQuery(c *gin.Context, registry map[string]port.DataSource, fieldGuard *fieldguard.Service, query map[string]interface{}) {
+	bizName, tableName := c.Param("biz"), c.Param("table")
+	dataSource, exists := registry[bizName]
+	if !exists {
+		_ = c.Error(port.ErrBizNotFound)
+		return
+	}
+	query["table"] = tableName
+	query[port.QueryNodeKey] = c.Query("node")
+
+	result, err := dataSource.Query(c.Request.Context(), port.QueryRequest{BizName: bizName, Query: query})
+	if err != nil {
+		slog.Error("treeQuery 执行失败", "biz", bizName, "table", tableName, "mode", query[port.QueryModeKey], "error", err)
+		_ = c.Error(err)
+		return
+	}
+	if query[port.QueryModeKey] == port.QueryModeAncestors {
+		if items, _ := result.Data["items"].([]interface{}); len(items) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("节点 '%s' 在表 '%s' 中不存在", c.Query("node"), tableName)})
+			return
+		}
+	}
+	if fieldGuard != nil {
+		if result, err = fieldGuard.Filter(c.Request.Context(), bizName, tableName, result); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
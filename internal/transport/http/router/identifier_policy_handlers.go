@@ -0,0 +1,64 @@
+// Package router file: internal/transport/http/router/identifier_policy_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/identpolicy"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identifierPolicyReportHandler 按当前标识符策略检查所有业务组的配置，返回不合规的表名与字段名，
+// 供管理员在切换到 strict 模式前修正
+func identifierPolicyReportHandler(policy *identpolicy.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "标识符策略未启用"})
+			return
+		}
+		report, err := policy.Report(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// checkQueryIdentifiers 按标识符策略检查查询中由客户端给出的表名与字段名：
+// table、filters 中的 field、collapse_by 与 timeline 的 field。省略的表名取默认表，不做检查
+func checkQueryIdentifiers(policy *identpolicy.Service, query map[string]interface{}) error {
+	if policy == nil {
+		return nil
+	}
+	if table, _ := query["table"].(string); table != "" {
+		if err := policy.Check(domain.IdentifierKindTable, table); err != nil {
+			return err
+		}
+	}
+	fields := make([]string, 0, 4)
+	filters, _ := query["filters"].([]interface{})
+	for _, f := range filters {
+		if filterMap, ok := f.(map[string]interface{}); ok {
+			if field, _ := filterMap["field"].(string); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	if field, _ := query[port.QueryCollapseKey].(string); field != "" {
+		fields = append(fields, field)
+	}
+	if timeline, ok := query[port.QueryTimelineKey].(map[string]interface{}); ok {
+		if field, _ := timeline["field"].(string); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range fields {
+		if err := policy.Check(domain.IdentifierKindField, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
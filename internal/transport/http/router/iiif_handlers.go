@@ -0,0 +1,96 @@
+// Package router file: internal/transport/http/router/iiif_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/iiif"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// iiifManifestHandler 为单条记录生成 IIIF Presentation 3.0 清单，供 Mirador、Universal Viewer 等标准查看器加载
+func iiifManifestHandler(iiifService *iiif.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName, recordID := c.Param("biz"), c.Param("table"), c.Param("id")
+		base := requestBaseURL(c)
+		manifestURL := base + c.Request.URL.Path
+		homepage := fmt.Sprintf("%s/api/v1/data/record/%s/%s/%s",
+			base, url.PathEscape(bizName), url.PathEscape(tableName), url.PathEscape(recordID))
+
+		manifest, err := iiifService.BuildManifest(c.Request.Context(), bizName, tableName, recordID, manifestURL, homepage, accessViewer(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, iiif.ErrMappingNotFound), errors.Is(err, iiif.ErrRecordNotFound), errors.Is(err, iiif.ErrNoImages):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			default:
+				slog.Error("iiifManifestHandler 执行失败", "biz", bizName, "table", tableName, "id", recordID, "error", err)
+				_ = c.Error(err)
+			}
+			return
+		}
+		body, err := json.Marshal(manifest)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, `application/ld+json;profile="http://iiif.io/api/presentation/3/context.json"`, body)
+	}
+}
+
+// listIIIFMappingsHandler 列出所有 IIIF 映射
+func listIIIFMappingsHandler(iiifService *iiif.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		mappings, err := iiifService.ListMappings(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, mappings, page)
+	}
+}
+
+// saveIIIFMappingHandler 创建或更新一个业务表的 IIIF 映射
+func saveIIIFMappingHandler(iiifService *iiif.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var mapping domain.IIIFMapping
+		if err := c.ShouldBindJSON(&mapping); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		mapping.BizName, mapping.TableName = c.Param("bizName"), c.Param("tableName")
+		if err := iiifService.SaveMapping(c.Request.Context(), mapping); err != nil {
+			if errors.Is(err, iiif.ErrInvalidMapping) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "IIIF 映射已保存"})
+	}
+}
+
+// deleteIIIFMappingHandler 删除一个业务表的 IIIF 映射
+func deleteIIIFMappingHandler(iiifService *iiif.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := iiifService.DeleteMapping(c.Request.Context(), c.Param("bizName"), c.Param("tableName")); err != nil {
+			if errors.Is(err, iiif.ErrMappingNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "IIIF 映射已删除"})
+	}
+}
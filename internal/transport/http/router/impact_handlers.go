@@ -0,0 +1,45 @@
+// Package router file: internal/transport/http/router/impact_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/impact"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondImpactError 把字段配置的校验错误映射为 400/404，其余交给错误中间件
+func respondImpactError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, impact.ErrInvalidSettings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, port.ErrBizNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// fieldSettingsImpactHandler 预览一次字段配置修改 (请求体与 PUT /fields 相同) 的影响，不做任何修改
+func fieldSettingsImpactHandler(fieldImpact *impact.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if fieldImpact == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "影响分析未启用"})
+			return
+		}
+		var payload []domain.FieldSetting
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		report, err := fieldImpact.AnalyzeFieldSettings(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), payload)
+		if err != nil {
+			respondImpactError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
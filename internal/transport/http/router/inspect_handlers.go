@@ -0,0 +1,60 @@
+// Package router file: internal/transport/http/router/inspect_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/adapter/datasource/caching"
+	"ArchiveAegis/internal/aegmiddleware"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 以下处理器只读地展示网关内存中的缓存与限流状态，用于排查"请求为什么被限流、读到的配置为什么是旧的"
+
+// inspectConfigCacheHandler 返回配置缓存中各业务组条目的年龄与剩余有效期
+func inspectConfigCacheHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inspector, ok := configService.(port.ConfigCacheInspector)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "配置服务不支持查看缓存"})
+			return
+		}
+		c.JSON(http.StatusOK, inspector.CacheState())
+	}
+}
+
+// inspectRateLimitersHandler 返回活跃的令牌桶及剩余令牌数，?dimension= 限定维度，?key= 限定 IP、用户 ID 或业务组
+func inspectRateLimitersHandler(limiter *aegmiddleware.BusinessRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "速率限制未启用"})
+			return
+		}
+		dimension := c.Query("dimension")
+		switch dimension {
+		case "", domain.LimiterDimensionGlobal, domain.LimiterDimensionIP, domain.LimiterDimensionUser,
+			domain.LimiterDimensionBiz, domain.LimiterDimensionAnonymous:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dimension 应为 global、ip、user、biz 或 anonymous"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": limiter.Snapshot(dimension, c.Query("key"))})
+	}
+}
+
+// inspectLoadHandler 返回过载保护与查询缓存的状态。网关没有熔断器，数据源不可用时的降级由过载保护
+// 与查询缓存的旧结果承担，两者未启用时对应字段为 null
+func inspectLoadHandler(loadShedder *aegmiddleware.LoadShedder, queryCache *caching.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resp := gin.H{"load_shedding": nil, "query_cache": nil}
+		if loadShedder != nil {
+			resp["load_shedding"] = loadShedder.State()
+		}
+		if queryCache != nil {
+			resp["query_cache"] = queryCache.Stats()
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,76 @@
+// Package router file: internal/transport/http/router/jobs_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/jobs"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listJobsHandler 按创建时间倒序返回后台任务，支持 ?kind=、?biz=、?status= 与 ?limit= 筛选
+func listJobsHandler(jobService *jobs.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := jobs.ListFilter{Kind: c.Query("kind"), BizName: c.Query("biz"), Status: c.Query("status")}
+		if raw := c.Query("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须为正整数"})
+				return
+			}
+			filter.Limit = limit
+		}
+		items, err := jobService.List(c.Request.Context(), filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": items})
+	}
+}
+
+// getJobHandler 返回单个后台任务的状态与进度
+func getJobHandler(jobService *jobs.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务 ID"})
+			return
+		}
+		job, err := jobService.Get(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, jobs.ErrJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": job})
+	}
+}
+
+// cancelJobHandler 请求取消一个运行中的后台任务，任务在当前步骤结束后停止
+func cancelJobHandler(jobService *jobs.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务 ID"})
+			return
+		}
+		if err := jobService.Cancel(c.Request.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, jobs.ErrJobNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, jobs.ErrJobNotRunning):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"status": "success", "message": "已请求取消任务"})
+	}
+}
@@ -0,0 +1,68 @@
+// Package router file: internal/transport/http/router/license_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/licensing"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondLicenseError 把授权错误映射为 400/404，其余交给错误中间件
+func respondLicenseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, licensing.ErrInvalidLicense):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, licensing.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listLicensesHandler 列出已录入的插件授权及其当前校验状态，不返回授权码本身
+func listLicensesHandler(licenses *licensing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		list, err := licenses.List(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// putLicenseHandler 校验并录入授权码，请求体为 {"key": "..."}，所属插件由授权码中的声明决定
+func putLicenseHandler(licenses *licensing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Key string `json:"key" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须包含 key"})
+			return
+		}
+		lic, err := licenses.Put(c.Request.Context(), body.Key, requestUserID(c))
+		if err != nil {
+			respondLicenseError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": lic})
+	}
+}
+
+// deleteLicenseHandler 删除插件的授权，已在运行的实例不受影响
+func deleteLicenseHandler(licenses *licensing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := licenses.Delete(c.Request.Context(), c.Param("plugin_id")); err != nil {
+			respondLicenseError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "授权已删除"})
+	}
+}
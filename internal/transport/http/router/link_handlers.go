@@ -0,0 +1,159 @@
+// Package router file: internal/transport/http/router/link_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/links"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordLinkRequest 是创建记录关联的请求体
+type recordLinkRequest struct {
+	SourceBiz   string `json:"source_biz" binding:"required"`
+	SourceTable string `json:"source_table" binding:"required"`
+	SourceID    string `json:"source_id" binding:"required"`
+	TargetBiz   string `json:"target_biz" binding:"required"`
+	TargetTable string `json:"target_table" binding:"required"`
+	TargetID    string `json:"target_id" binding:"required"`
+	Relation    string `json:"relation"`
+	Note        string `json:"note"`
+}
+
+// createRecordLinkHandler 创建两条记录之间的关联，两端的记录必须存在
+func createRecordLinkHandler(recordLinks *links.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body recordLinkRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		link, err := recordLinks.Create(c.Request.Context(), domain.RecordLink{
+			SourceBiz: body.SourceBiz, SourceTable: body.SourceTable, SourceID: body.SourceID,
+			TargetBiz: body.TargetBiz, TargetTable: body.TargetTable, TargetID: body.TargetID,
+			Relation: body.Relation, Note: body.Note,
+		}, requestUserID(c))
+		if err != nil {
+			respondLinkError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": link})
+	}
+}
+
+// listRecordLinksHandler 按 ?biz=&table=&id= 列出以记录为任一端的关联，biz 为必填
+func listRecordLinksHandler(recordLinks *links.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName, recordID := c.Query("biz"), c.Query("table"), c.Query("id")
+		if bizName == "" || (recordID != "" && tableName == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "必须指定 biz，指定 id 时还必须指定 table"})
+			return
+		}
+		found, err := recordLinks.List(c.Request.Context(), bizName, tableName, recordID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": found})
+	}
+}
+
+// getRecordLinkHandler 返回指定的关联
+func getRecordLinkHandler(recordLinks *links.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := linkIDParam(c)
+		if !ok {
+			return
+		}
+		link, err := recordLinks.Get(c.Request.Context(), id)
+		if err != nil {
+			respondLinkError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": link})
+	}
+}
+
+// deleteRecordLinkHandler 删除指定的关联
+func deleteRecordLinkHandler(recordLinks *links.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := linkIDParam(c)
+		if !ok {
+			return
+		}
+		if err := recordLinks.Delete(c.Request.Context(), id); err != nil {
+			respondLinkError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+}
+
+// withRecordLinks 在 ?include_links=true 时把关联展开到查询结果各行的 "_links" 中。
+// 只给出另一端记录的位置，客户端经 /data/record 读取其内容时仍受所属业务组的权限约束
+func withRecordLinks(c *gin.Context, recordLinks *links.Service, bizName, tableName string, result *port.QueryResult) (*port.QueryResult, error) {
+	if recordLinks == nil || c.Query("include_links") != "true" {
+		return result, nil
+	}
+	items, ok := result.Data["items"].([]interface{})
+	if !ok || len(items) == 0 {
+		return result, nil
+	}
+	anonymous := service.ClaimFrom(c.Request) == nil
+	expanded, err := recordLinks.ExpandItems(c.Request.Context(), bizName, tableName, items, anonymous)
+	if err != nil {
+		return nil, err
+	}
+	linked := make([]interface{}, len(items))
+	for i, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			linked[i] = item
+			continue
+		}
+		// 结果可能来自缓存，复制后再附加
+		copied := make(map[string]interface{}, len(row)+1)
+		for k, v := range row {
+			copied[k] = v
+		}
+		if expanded[i] != nil {
+			copied["_links"] = expanded[i]
+		} else {
+			copied["_links"] = []domain.LinkedRecord{}
+		}
+		linked[i] = copied
+	}
+	data := make(map[string]interface{}, len(result.Data))
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	data["items"] = linked
+	return &port.QueryResult{Data: data, Source: result.Source}, nil
+}
+
+func linkIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的关联 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, links.ErrLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, links.ErrInvalidLink):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, links.ErrDuplicateLink), errors.Is(err, links.ErrRecordLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
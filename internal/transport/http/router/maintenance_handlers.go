@@ -0,0 +1,79 @@
+// Package router file: internal/transport/http/router/maintenance_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/maintenance"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listMaintenanceRunsHandler 按时间倒序列出库文件维护记录，?biz= 只列出该业务组，?limit= 控制数量
+func listMaintenanceRunsHandler(maintenanceService *maintenance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maintenanceService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件维护未启用"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		runs, err := maintenanceService.ListRuns(c.Request.Context(), c.Query("biz"), limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": runs})
+	}
+}
+
+// getMaintenanceStatusHandler 返回业务组各库文件的存储状况、当前流量与最近一次维护
+func getMaintenanceStatusHandler(maintenanceService *maintenance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maintenanceService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件维护未启用"})
+			return
+		}
+		status, err := maintenanceService.Status(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": status})
+	}
+}
+
+// runMaintenanceHandler 立即维护业务组的库文件，请求体可选，为 {"tasks": ["analyze", "vacuum", "checkpoint"], "force": false}。
+// 业务组繁忙时返回 409，force 为 true 时仍然执行
+func runMaintenanceHandler(maintenanceService *maintenance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maintenanceService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "库文件维护未启用"})
+			return
+		}
+		var body struct {
+			Tasks []string `json:"tasks"`
+			Force bool     `json:"force"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		run, err := maintenanceService.RunNow(c.Request.Context(), c.Param("bizName"), body.Tasks, body.Force)
+		switch {
+		case errors.Is(err, maintenance.ErrInvalidTasks):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, maintenance.ErrBusy), errors.Is(err, maintenance.ErrRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil && run != nil:
+			// 维护失败时记录已保存，一并返回
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": run})
+		case err != nil:
+			_ = c.Error(err)
+		default:
+			c.JSON(http.StatusOK, gin.H{"data": run})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package router file: internal/transport/http/router/metrics_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/aegobserve"
+	"ArchiveAegis/internal/service"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireScraper 限制 /metrics 只能由白名单内的来源、携带 -gen-service-token 生成的服务 Token 访问。
+// 需在 authMiddleware 之后使用。
+func requireScraper(allow *aegobserve.Allowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allow.Allows(c.Request) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "来源地址不在抓取白名单内"})
+			return
+		}
+		if !service.ClaimFrom(c.Request).IsServiceToken() {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "需要有效的服务 Token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// respondLogSettingsError 把日志设置错误映射为 400/409，其余交给错误中间件
+func respondLogSettingsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, aegobserve.ErrInvalidLogSettings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, aegobserve.ErrLoggerNotInitialized):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "，请先启用高级可观测性功能"})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// getLogSettingsHandler 返回当前的日志级别、输出格式、各模块级别与可设置的模块
+func getLogSettingsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := aegobserve.CurrentLogSettings()
+		if err != nil {
+			respondLogSettingsError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": settings, "modules": aegobserve.LogModules()})
+	}
+}
+
+// updateLogSettingsHandler 在运行期修改日志设置，不会持久化，重启后恢复为配置文件中的设置。
+// 请求体如 {"level": "debug", "format": "text", "modules": {"plugin_manager": "warn"}}，省略的项保持不变
+func updateLogSettingsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload aegobserve.LogSettings
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		settings, err := aegobserve.UpdateLogSettings(payload)
+		if err != nil {
+			respondLogSettingsError(c, err)
+			return
+		}
+		slog.Info("日志设置已修改", "user_id", requestUserID(c), "level", settings.Level, "format", settings.Format, "modules", settings.Modules)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "data": settings})
+	}
+}
@@ -0,0 +1,95 @@
+// Package router file: internal/transport/http/router/oaipmh_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/oaipmh"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oaiPMHHandler 是 OAI-PMH 2.0 协议端点，同时支持 GET 查询串与 POST 表单两种请求方式。
+// 协议错误以 200 状态码写入 XML 响应体，这是规范的要求。
+func oaiPMHHandler(oaiService *oaipmh.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := c.Request.ParseForm(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无法解析请求参数"})
+			return
+		}
+		baseURL := requestBaseURL(c) + c.Request.URL.Path
+		body, err := oaiService.Handle(c.Request.Context(), baseURL, c.Request.Form)
+		if err != nil {
+			slog.Error("oaiPMHHandler 执行失败", "error", err)
+			_ = c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, "text/xml; charset=utf-8", body)
+	}
+}
+
+// listOAIMappingsHandler 列出所有 Dublin Core 映射
+func listOAIMappingsHandler(oaiService *oaipmh.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		mappings, err := oaiService.ListMappings(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, mappings, page)
+	}
+}
+
+// saveOAIMappingHandler 创建或更新一个业务表的 Dublin Core 映射
+func saveOAIMappingHandler(oaiService *oaipmh.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			SetName        string            `json:"set_name"`
+			IDField        string            `json:"id_field" binding:"required"`
+			DatestampField string            `json:"datestamp_field"`
+			Elements       map[string]string `json:"elements" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		mapping := domain.OAIMapping{
+			BizName:        c.Param("bizName"),
+			TableName:      c.Param("tableName"),
+			SetName:        payload.SetName,
+			IDField:        payload.IDField,
+			DatestampField: payload.DatestampField,
+			Elements:       payload.Elements,
+		}
+		if err := oaiService.SaveMapping(c.Request.Context(), mapping); err != nil {
+			if errors.Is(err, oaipmh.ErrInvalidMapping) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "OAI-PMH 映射已保存"})
+	}
+}
+
+// deleteOAIMappingHandler 删除一个业务表的 Dublin Core 映射
+func deleteOAIMappingHandler(oaiService *oaipmh.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := oaiService.DeleteMapping(c.Request.Context(), c.Param("bizName"), c.Param("tableName")); err != nil {
+			if errors.Is(err, oaipmh.ErrMappingNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "OAI-PMH 映射已删除"})
+	}
+}
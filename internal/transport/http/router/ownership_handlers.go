@@ -0,0 +1,109 @@
+// Package router file: internal/transport/http/router/ownership_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/ownership"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireBizAdmin 放行平台管理员，以及路径中 :bizName 对应业务组的委派管理员。
+// 没有 :bizName 的路由 (如列出业务组) 对任何已登录用户放行，由处理器自行按归属过滤。
+func requireBizAdmin(owners *ownership.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := service.ClaimFrom(c.Request)
+		if claims == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "需要认证"})
+			return
+		}
+		if claims.Role == "admin" {
+			c.Next()
+			return
+		}
+		bizName := c.Param("bizName")
+		if bizName == "" {
+			c.Next()
+			return
+		}
+		owned, err := owners.IsOwner(c.Request.Context(), bizName, claims.ID)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		if !owned {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "需要管理员或该业务组管理员权限"})
+			return
+		}
+		slog.Debug("业务组管理员访问配置接口", "biz", bizName, "user_id", claims.ID, "path", c.FullPath())
+		c.Next()
+	}
+}
+
+// respondOwnershipError 把业务组管理员的错误映射为 400/404，其余交给错误中间件
+func respondOwnershipError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ownership.ErrInvalidOwner):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ownership.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listBizOwnersHandler 列出业务组的委派管理员
+func listBizOwnersHandler(owners *ownership.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		list, err := owners.Owners(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// grantBizOwnerHandler 把业务组委派给一个普通用户
+func grantBizOwnerHandler(owners *ownership.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			UserID int64 `json:"user_id" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		owner, err := owners.Grant(c.Request.Context(), c.Param("bizName"), body.UserID, requestUserID(c))
+		if err != nil {
+			respondOwnershipError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": owner})
+	}
+}
+
+// revokeBizOwnerHandler 撤销用户对业务组的管理权限
+func revokeBizOwnerHandler(owners *ownership.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户 ID"})
+			return
+		}
+		if err := owners.Revoke(c.Request.Context(), c.Param("bizName"), userID); err != nil {
+			respondOwnershipError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "已撤销业务组管理员"})
+	}
+}
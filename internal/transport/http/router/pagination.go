@@ -0,0 +1,82 @@
+// Package router file: internal/transport/http/router/pagination.go
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultPageSize 与 maxPageSize 是列表接口的默认与最大页大小，与各服务内部的分页上限一致
+	defaultPageSize = 50
+	maxPageSize     = 500
+	// maxPageNumber 防止 page*size 溢出
+	maxPageNumber = 1 << 20
+)
+
+// pageParams 是列表接口的分页参数，page 从 1 开始
+type pageParams struct {
+	Page int
+	Size int
+}
+
+func (p pageParams) offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+// pageParamsFromQuery 从查询参数 page、size 读取分页参数，size 超过 maxPageSize 时按上限处理。
+// 参数无效时已写入 400 响应，调用方直接返回
+func pageParamsFromQuery(c *gin.Context) (pageParams, bool) {
+	p := pageParams{Page: 1, Size: defaultPageSize}
+	for name, target := range map[string]*int{"page": &p.Page, "size": &p.Size} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s 必须是正整数", name)})
+				return p, false
+			}
+			*target = n
+		}
+	}
+	if p.Page > maxPageNumber {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page 不能超过 %d", maxPageNumber)})
+		return p, false
+	}
+	p.Size = min(p.Size, maxPageSize)
+	return p, true
+}
+
+// PageEnvelope 是列表接口统一的响应结构。Data 总是数组 (没有记录时为空数组而不是 null)，
+// Total 为满足条件的记录总数；Next 是下一页的相对链接 (保留其余查询参数)，已是最后一页时省略
+type PageEnvelope[T any] struct {
+	Data  []T    `json:"data"`
+	Page  int    `json:"page"`
+	Size  int    `json:"size"`
+	Total int64  `json:"total"`
+	Next  string `json:"next,omitempty"`
+}
+
+// respondPage 以 PageEnvelope 返回一页记录，items 是已按 p 分好页的结果
+func respondPage[T any](c *gin.Context, items []T, total int64, p pageParams) {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	envelope := PageEnvelope[T]{Data: items, Page: p.Page, Size: p.Size, Total: total}
+	if int64(p.Page*p.Size) < total {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(p.Page+1))
+		query.Set("size", strconv.Itoa(p.Size))
+		envelope.Next = c.Request.URL.Path + "?" + query.Encode()
+	}
+	c.JSON(http.StatusOK, envelope)
+}
+
+// respondList 对服务一次返回的完整列表在内存中分页，适用于记录数有限的管理列表
+func respondList[T any](c *gin.Context, all []T, p pageParams) {
+	start := min(p.offset(), len(all))
+	end := min(start+p.Size, len(all))
+	respondPage(c, all[start:end], int64(len(all)), p)
+}
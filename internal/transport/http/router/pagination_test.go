@@ -0,0 +1,72 @@
+// file: internal/transport/http/router/pagination_test.go
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	all := []string{"a", "b", "c", "d", "e"}
+	engine := gin.New()
+	engine.GET("/items", func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		respondList(c, all, page)
+	})
+	engine.GET("/empty", func(c *gin.Context) {
+		respondList[string](c, nil, pageParams{Page: 1, Size: defaultPageSize})
+	})
+
+	get := func(path string) (int, PageEnvelope[string]) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var out PageEnvelope[string]
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		}
+		return w.Code, out
+	}
+
+	code, out := get("/items")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, all, out.Data)
+	assert.Equal(t, 1, out.Page)
+	assert.Equal(t, defaultPageSize, out.Size)
+	assert.Equal(t, int64(5), out.Total)
+	assert.Empty(t, out.Next)
+
+	_, out = get("/items?size=2&status=all")
+	assert.Equal(t, []string{"a", "b"}, out.Data)
+	assert.Equal(t, "/items?page=2&size=2&status=all", out.Next, "下一页链接保留其余查询参数")
+
+	_, out = get("/items?page=3&size=2")
+	assert.Equal(t, []string{"e"}, out.Data)
+	assert.Empty(t, out.Next)
+
+	_, out = get("/items?page=9&size=2")
+	assert.Empty(t, out.Data, "超出范围的页返回空数组")
+	assert.Equal(t, int64(5), out.Total)
+
+	_, out = get("/items?size=100000")
+	assert.Equal(t, maxPageSize, out.Size, "size 按上限处理")
+
+	for _, path := range []string{"/items?page=0", "/items?size=-1", "/items?page=abc", "/items?page=99999999"} {
+		code, _ := get(path)
+		assert.Equal(t, http.StatusBadRequest, code, path)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	assert.JSONEq(t, `{"data":[],"page":1,"size":50,"total":0}`, w.Body.String(), "没有记录时 data 为空数组而不是 null")
+}
@@ -0,0 +1,49 @@
+// Package router file: internal/transport/http/router/penalty_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/penalties"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listPenaltiesHandler 列出当前有效的登录失败计数、登录锁定、限流违规计数与临时封禁
+func listPenaltiesHandler(box *penalties.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if box == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "登录锁定与临时封禁未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		respondList(c, box.List(), page)
+	}
+}
+
+// liftPenaltyHandler 解除一条锁定或封禁，对象由查询参数 subject 指定 (登录锁定为 "IP|用户名")
+func liftPenaltyHandler(box *penalties.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if box == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "登录锁定与临时封禁未启用"})
+			return
+		}
+		subject := c.Query("subject")
+		if subject == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少查询参数 'subject'"})
+			return
+		}
+		if err := box.Lift(c.Request.Context(), c.Param("kind"), subject); err != nil {
+			if errors.Is(err, penalties.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "已解除锁定"})
+	}
+}
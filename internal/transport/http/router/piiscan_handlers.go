@@ -0,0 +1,46 @@
+// Package router file: internal/transport/http/router/piiscan_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/piiscan"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runPIIScanHandler 对业务组的可返回字段抽样扫描个人信息，返回并保存扫描结果
+func runPIIScanHandler(scanner *piiscan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scanner == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "个人信息扫描未启用"})
+			return
+		}
+		report, err := scanner.Scan(c.Request.Context(), c.Param("bizName"), requestUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// getPIIScanHandler 返回业务组最近一次的个人信息扫描结果，尚未扫描时返回 404
+func getPIIScanHandler(scanner *piiscan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scanner == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "个人信息扫描未启用"})
+			return
+		}
+		report, err := scanner.LastReport(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			if errors.Is(err, piiscan.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
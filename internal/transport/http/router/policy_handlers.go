@@ -0,0 +1,138 @@
+// Package router file: internal/transport/http/router/policy_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/policy"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizationHooks 在请求到达处理器之前把请求上下文交给外部授权钩子评估，被拒绝时返回 403 与钩子给出的原因，
+// 钩子不可用且未配置放行时返回 503。必须放在认证中间件之后，否则钩子看到的都是匿名用户
+func authorizationHooks(hooks *policy.Service, plane string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorizeRequest(c, hooks, plane) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeRequest 以当前请求评估授权钩子，未通过时写入响应并中止请求，返回 false
+func authorizeRequest(c *gin.Context, hooks *policy.Service, plane string) bool {
+	if hooks == nil || !hooks.Covers(plane) {
+		return true
+	}
+	decision, err := hooks.Evaluate(c.Request.Context(), policyInput(c, plane))
+	if err != nil {
+		if errors.Is(err, policy.ErrUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return false
+		}
+		_ = c.Error(err)
+		c.Abort()
+		return false
+	}
+	if !decision.Allow {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "请求被授权策略拒绝"
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": reason})
+		return false
+	}
+	return true
+}
+
+// policyInput 从路由参数、URL 参数与 JSON 请求体中提取授权钩子需要的请求上下文，读取过的请求体会放回供处理器使用
+func policyInput(c *gin.Context, plane string) port.PolicyInput {
+	input := port.PolicyInput{
+		Plane:  plane,
+		IP:     c.ClientIP(),
+		Method: c.Request.Method,
+		Path:   c.Request.URL.Path,
+		Route:  c.FullPath(),
+	}
+	if claims := service.ClaimFrom(c.Request); claims != nil {
+		input.User = port.PolicyUser{ID: claims.ID, Role: claims.Role}
+	}
+	for _, param := range []string{"biz", "bizName"} {
+		if name := c.Param(param); name != "" {
+			input.BizName = name
+		}
+	}
+	for _, param := range []string{"table", "tableName"} {
+		if name := c.Param(param); name != "" {
+			input.Table = name
+		}
+	}
+	if input.BizName == "" {
+		input.BizName = c.Query("biz")
+	}
+	if input.Table == "" {
+		input.Table = c.Query("table")
+	}
+	if plane == port.PolicyPlaneAdmin {
+		input.Operation = c.Request.Method
+	} else {
+		// 数据平面的接口名是 /api/v1/data/ 之后的第一段
+		rest := strings.TrimPrefix(input.Route, "/api/v1/data/")
+		input.Operation, _, _ = strings.Cut(rest, "/")
+	}
+
+	if c.Request.Method == http.MethodGet {
+		if values := c.Request.URL.Query(); len(values) > 0 {
+			filters := make(map[string]interface{}, len(values))
+			for k, v := range values {
+				filters[k] = v[0]
+			}
+			input.Filters = filters
+		}
+		return input
+	}
+	if !strings.Contains(c.GetHeader("Content-Type"), "application/json") || c.Request.Body == nil {
+		return input
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return input
+	}
+	var extractor struct {
+		BizName   string                 `json:"biz_name"`
+		Operation string                 `json:"operation"`
+		Query     map[string]interface{} `json:"query"`
+		Payload   map[string]interface{} `json:"payload"`
+	}
+	if json.Unmarshal(body, &extractor) != nil {
+		return input
+	}
+	if input.BizName == "" {
+		input.BizName = extractor.BizName
+	}
+	if extractor.Query != nil {
+		input.Filters = extractor.Query
+		if input.Table == "" {
+			input.Table, _ = extractor.Query["table"].(string)
+		}
+	}
+	if plane == port.PolicyPlaneData && extractor.Operation != "" {
+		input.Operation = extractor.Operation
+		if input.Table == "" {
+			input.Table, _ = extractor.Payload["table_name"].(string)
+		}
+		if filters, ok := extractor.Payload["filters"]; ok {
+			input.Filters = filters
+		}
+	}
+	return input
+}
@@ -0,0 +1,118 @@
+// Package router file: internal/transport/http/router/preferences_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/preferences"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondPreferenceError 把偏好设置的校验错误映射为 400/404/413，其余交给错误中间件
+func respondPreferenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, preferences.ErrInvalidPreference):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, preferences.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, preferences.ErrQuotaExceeded):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// readPreferenceBody 读取受大小限制的请求体
+func readPreferenceBody(c *gin.Context, prefs *preferences.Service) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, prefs.MaxRequestBytes()))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+			return nil, false
+		}
+		_ = c.Error(err)
+		return nil, false
+	}
+	return body, true
+}
+
+// listPreferencesHandler 返回当前用户的全部偏好设置
+func listPreferencesHandler(prefs *preferences.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		values, err := prefs.List(c.Request.Context(), requestUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": values})
+	}
+}
+
+// mergePreferencesHandler 合并请求体中的多项偏好设置，值为 null 的键被删除，返回合并后的全部设置
+func mergePreferencesHandler(prefs *preferences.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, ok := readPreferenceBody(c, prefs)
+		if !ok {
+			return
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(body, &values); err != nil || values == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须是一个 JSON 对象"})
+			return
+		}
+		userID := requestUserID(c)
+		if err := prefs.Merge(c.Request.Context(), userID, values); err != nil {
+			respondPreferenceError(c, err)
+			return
+		}
+		merged, err := prefs.List(c.Request.Context(), userID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": merged})
+	}
+}
+
+// getPreferenceHandler 返回当前用户的一项偏好设置
+func getPreferenceHandler(prefs *preferences.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, err := prefs.Get(c.Request.Context(), requestUserID(c), c.Param("key"))
+		if err != nil {
+			respondPreferenceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": value})
+	}
+}
+
+// setPreferenceHandler 以请求体 (任意 JSON) 覆盖当前用户的一项偏好设置
+func setPreferenceHandler(prefs *preferences.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, ok := readPreferenceBody(c, prefs)
+		if !ok {
+			return
+		}
+		key := c.Param("key")
+		if err := prefs.Set(c.Request.Context(), requestUserID(c), key, body); err != nil {
+			respondPreferenceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": json.RawMessage(body)})
+	}
+}
+
+// deletePreferenceHandler 删除当前用户的一项偏好设置
+func deletePreferenceHandler(prefs *preferences.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := prefs.Delete(c.Request.Context(), requestUserID(c), c.Param("key")); err != nil {
+			respondPreferenceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+}
@@ -0,0 +1,104 @@
+// Package router file: internal/transport/http/router/projection_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// errInvalidProjection 表示投影规格不合法
+var errInvalidProjection = errors.New("无效的投影规格")
+
+// projectionPath 是一条已解析的投影规则：目标路径的各级键名，以及取值的源字段
+type projectionPath struct {
+	keys   []string
+	source string
+}
+
+// compileProjection 解析形如 {"person.name": "name_col"} 的投影规格。
+// 键为以点分隔的目标路径，值为扁平行中的源字段名。
+// 同一路径不能既是取值叶子又是其它路径的父级（例如同时出现 "a" 与 "a.b"）。
+func compileProjection(spec map[string]string) ([]projectionPath, error) {
+	targets := make([]string, 0, len(spec))
+	for target := range spec {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	leaves := make(map[string]bool, len(spec))
+	paths := make([]projectionPath, 0, len(spec))
+	for _, target := range targets {
+		source := spec[target]
+		if source == "" {
+			return nil, fmt.Errorf("%w: 路径 '%s' 缺少源字段", errInvalidProjection, target)
+		}
+		keys := strings.Split(target, ".")
+		for _, k := range keys {
+			if k == "" {
+				return nil, fmt.Errorf("%w: 路径 '%s' 含有空的键名", errInvalidProjection, target)
+			}
+		}
+		leaves[target] = true
+		paths = append(paths, projectionPath{keys: keys, source: source})
+	}
+	for _, p := range paths {
+		for i := 1; i < len(p.keys); i++ {
+			if prefix := strings.Join(p.keys[:i], "."); leaves[prefix] {
+				return nil, fmt.Errorf("%w: 路径 '%s' 与 '%s' 冲突", errInvalidProjection, prefix, strings.Join(p.keys, "."))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// applyProjection 返回一个按投影规则重组后的结果副本，不修改原结果（原结果可能被查询缓存共享）。
+// 投影后的文档只包含规格中列出的路径，源字段缺失时对应值为 null。
+func applyProjection(result *port.QueryResult, paths []projectionPath) *port.QueryResult {
+	if result == nil || len(paths) == 0 {
+		return result
+	}
+	data := make(map[string]interface{}, len(result.Data))
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	if items, ok := result.Data["items"].([]interface{}); ok {
+		projected := make([]interface{}, len(items))
+		for i, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				projected[i] = projectRow(row, paths)
+			} else {
+				projected[i] = item
+			}
+		}
+		data["items"] = projected
+	}
+	if record, ok := result.Data["record"].(map[string]interface{}); ok {
+		data["record"] = projectRow(record, paths)
+	}
+	return &port.QueryResult{Data: data, Source: result.Source}
+}
+
+// projectRow 将一行扁平数据重组为嵌套文档
+func projectRow(row map[string]interface{}, paths []projectionPath) map[string]interface{} {
+	doc := make(map[string]interface{})
+	for _, p := range paths {
+		node := doc
+		for _, k := range p.keys[:len(p.keys)-1] {
+			child, ok := node[k].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[k] = child
+			}
+			node = child
+		}
+		node[p.keys[len(p.keys)-1]] = row[p.source]
+	}
+	// 展开的记录关联不属于记录字段，原样保留
+	if linked, ok := row["_links"]; ok {
+		doc["_links"] = linked
+	}
+	return doc
+}
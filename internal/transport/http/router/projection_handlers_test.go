@@ -0,0 +1,52 @@
+// file: internal/transport/http/router/projection_handlers_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileProjection_Validation(t *testing.T) {
+	_, err := compileProjection(map[string]string{"person": "a", "person.name": "b"})
+	assert.ErrorIs(t, err, errInvalidProjection, "叶子路径不能同时作为父级")
+
+	_, err = compileProjection(map[string]string{"person..name": "b"})
+	assert.ErrorIs(t, err, errInvalidProjection)
+
+	_, err = compileProjection(map[string]string{"person.name": ""})
+	assert.ErrorIs(t, err, errInvalidProjection)
+
+	paths, err := compileProjection(nil)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func TestApplyProjection_NestsRows(t *testing.T) {
+	paths, err := compileProjection(map[string]string{
+		"person.name":       "name_col",
+		"person.birth.year": "birth_year",
+		"title":             "ti",
+		"missing":           "no_such_col",
+	})
+	require.NoError(t, err)
+
+	original := &port.QueryResult{Data: map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name_col": "鲁迅", "birth_year": float64(1881), "ti": "呐喊", "extra": 1},
+		},
+		"total": float64(1),
+	}}
+	projected := applyProjection(original, paths)
+
+	doc := projected.Data["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"person":  map[string]interface{}{"name": "鲁迅", "birth": map[string]interface{}{"year": float64(1881)}},
+		"title":   "呐喊",
+		"missing": nil,
+	}, doc)
+	assert.Equal(t, float64(1), projected.Data["total"])
+	assert.Contains(t, original.Data["items"].([]interface{})[0], "name_col", "原结果不应被修改")
+}
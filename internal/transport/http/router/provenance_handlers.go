@@ -0,0 +1,35 @@
+// Package router file: internal/transport/http/router/provenance_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/provenance"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveProvenanceHandler 根据导出文件来源信息中的 requested_by 摘要 (?hash=) 反查用户
+func resolveProvenanceHandler(origin *provenance.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if origin == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "来源信息未启用"})
+			return
+		}
+		hash := c.Query("hash")
+		if hash == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "缺少查询参数 'hash'"})
+			return
+		}
+		id, username, err := origin.ResolveUser(c.Request.Context(), hash)
+		if err != nil {
+			if errors.Is(err, provenance.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"user_id": id, "username": username}})
+	}
+}
@@ -0,0 +1,294 @@
+// Package router file: internal/transport/http/router/query_cost.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/colstats"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultMaxScanRows 是业务组与网关均未配置时单次检索允许扫描的估算行数上限
+	defaultMaxScanRows = 1_000_000
+	// CostOverrideHeader 由管理员设置为 "true" 时跳过检索代价检查
+	CostOverrideHeader = "X-Aegis-Cost-Override"
+
+	// 缺少字段统计时各类条件的假定选择率
+	defaultEqualitySelectivity = 0.1
+	defaultFuzzySelectivity    = 0.25
+	defaultNullSelectivity     = 0.1
+	fullTextSelectivity        = 0.01
+	// minFuzzyRunes 是模糊匹配检索词的最小长度，更短的检索词几乎匹配每一行
+	minFuzzyRunes = 2
+)
+
+// QueryCostOptions 是网关默认的检索代价检查设置，业务组可在总体设置中单独调整
+type QueryCostOptions struct {
+	// Policy 是默认的处理策略 (off、warn 或 reject)，为空时为 warn
+	Policy string `mapstructure:"policy"`
+	// MaxScanRows 是默认的扫描行数上限，不大于 0 时取默认值 1000000
+	MaxScanRows int64 `mapstructure:"max_scan_rows"`
+}
+
+// queryCost 是检索在执行前估算的代价
+type queryCost struct {
+	// TableRows 是表的行数，未知时为 -1
+	TableRows int64 `json:"table_rows"`
+	// EstimatedMatches 是估算的匹配行数，表的行数未知时为 -1
+	EstimatedMatches int64 `json:"estimated_matches"`
+	// EstimatedScan 是估算需要扫描的行数，无法估算时为 -1
+	EstimatedScan int64    `json:"estimated_scan"`
+	MaxScanRows   int64    `json:"max_scan_rows"`
+	Indexed       bool     `json:"indexed"`
+	Reasons       []string `json:"reasons,omitempty"`
+}
+
+// exceeds 报告估算的扫描行数是否超过上限
+func (c queryCost) exceeds() bool {
+	return c.EstimatedScan > c.MaxScanRows
+}
+
+// message 是拒绝执行时返回给客户端的说明
+func (c queryCost) message() string {
+	msg := fmt.Sprintf("检索估算需要扫描 %d 行，超过业务组允许的上限 %d 行，请增加更具体的过滤条件", c.EstimatedScan, c.MaxScanRows)
+	if len(c.Reasons) > 0 {
+		msg += ": " + strings.Join(c.Reasons, "; ")
+	}
+	return msg
+}
+
+// queryCostEstimator 在检索发往数据源前估算其代价。表的行数取自字段统计的缓存，
+// 或此前不带过滤条件的检索所返回的 total，两者都没有时只能识别分页过深的检索
+type queryCostEstimator struct {
+	opts          QueryCostOptions
+	configService port.QueryAdminConfigService
+	stats         *colstats.Service
+
+	mu        sync.RWMutex
+	tableRows map[string]int64
+}
+
+func newQueryCostEstimator(opts QueryCostOptions, configService port.QueryAdminConfigService, stats *colstats.Service) *queryCostEstimator {
+	if opts.Policy == "" {
+		opts.Policy = domain.QueryCostPolicyWarn
+	}
+	if opts.MaxScanRows <= 0 {
+		opts.MaxScanRows = defaultMaxScanRows
+	}
+	return &queryCostEstimator{opts: opts, configService: configService, stats: stats, tableRows: make(map[string]int64)}
+}
+
+// policy 返回业务组生效的处理策略与扫描行数上限
+func (e *queryCostEstimator) policy(ctx context.Context, bizName string) (string, int64) {
+	policy, maxScan := e.opts.Policy, e.opts.MaxScanRows
+	if e.configService != nil {
+		if cfg, err := e.configService.GetBizQueryConfig(ctx, bizName); err == nil && cfg != nil {
+			if cfg.QueryCostPolicy != "" {
+				policy = cfg.QueryCostPolicy
+			}
+			if cfg.MaxScanRows > 0 {
+				maxScan = cfg.MaxScanRows
+			}
+		}
+	}
+	return policy, maxScan
+}
+
+// observe 记录不带过滤条件的检索返回的 total，作为表的行数；部分库失败时 total 不完整，不予记录
+func (e *queryCostEstimator) observe(bizName, table string, query map[string]interface{}, result *port.QueryResult) {
+	if filters, _ := query["filters"].([]interface{}); len(filters) > 0 || result == nil {
+		return
+	}
+	total, ok := result.Data["total"].(float64)
+	if !ok {
+		if n, isInt := result.Data["total"].(int64); isInt {
+			total, ok = float64(n), true
+		}
+	}
+	if _, partial := result.Data[port.ResultWarningsKey]; !ok || partial {
+		return
+	}
+	e.mu.Lock()
+	e.tableRows[bizName+"\x00"+table] = int64(total)
+	e.mu.Unlock()
+}
+
+// check 按业务组的策略检查检索的代价：返回 reject 为 true 时应拒绝执行，否则 warning 非 nil 时应在结果中附带
+func (e *queryCostEstimator) check(ctx context.Context, bizName, table string, query map[string]interface{}) (warning *queryCost, reject bool) {
+	policy, maxScan := e.policy(ctx, bizName)
+	if policy == domain.QueryCostPolicyOff {
+		return nil, false
+	}
+	cost := estimateQueryCost(query, e.tableStats(bizName, table), maxScan)
+	if !cost.exceeds() {
+		return nil, false
+	}
+	return &cost, policy == domain.QueryCostPolicyReject
+}
+
+// costOverridden 报告管理员是否要求跳过检索代价检查
+func costOverridden(c *gin.Context) bool {
+	if c.GetHeader(CostOverrideHeader) != "true" {
+		return false
+	}
+	claims := service.ClaimFrom(c.Request)
+	return claims != nil && claims.Role == "admin"
+}
+
+// tableStats 返回估算所用的表统计，只有观察到的行数时 Columns 为空
+func (e *queryCostEstimator) tableStats(bizName, table string) *domain.TableStats {
+	if e.stats != nil {
+		if stats := e.stats.Cached(bizName, table); stats != nil {
+			return stats
+		}
+	}
+	e.mu.RLock()
+	rows, ok := e.tableRows[bizName+"\x00"+table]
+	e.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return &domain.TableStats{BizName: bizName, Table: table, Total: rows}
+}
+
+// estimateQueryCost 按表统计与过滤条件的选择率估算检索的代价。
+// 全文检索条件以 AND 连接时可以利用索引，只需扫描匹配的行；其余条件都需要逐行比较，扫描整张表。
+// 分页偏移按行计入扫描量，因为数据源需要先跳过前面各页的记录
+func estimateQueryCost(query map[string]interface{}, stats *domain.TableStats, maxScanRows int64) queryCost {
+	cost := queryCost{TableRows: -1, EstimatedMatches: -1, EstimatedScan: -1, MaxScanRows: maxScanRows}
+	filters, _ := query["filters"].([]interface{})
+
+	hasOR := false
+	for _, f := range filters {
+		if fm, ok := f.(map[string]interface{}); ok {
+			if logic, _ := fm["logic"].(string); strings.EqualFold(logic, "OR") {
+				hasOR = true
+			}
+		}
+	}
+
+	// 过滤条件按从左到右的顺序组合：AND 相乘，OR 相加
+	selectivity := 1.0
+	var reasons []string
+	for i, f := range filters {
+		fm, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sel := filterSelectivity(fm, stats)
+		if fts, _ := fm[port.FilterFullTextKey].(bool); fts && !hasOR {
+			cost.Indexed = true
+		}
+		if fuzzy, _ := fm["fuzzy"].(bool); fuzzy {
+			if value, _ := fm["value"].(string); utf8.RuneCountInString(value) < minFuzzyRunes {
+				field, _ := fm["field"].(string)
+				reasons = append(reasons, fmt.Sprintf("字段 '%s' 的模糊匹配检索词过短，几乎匹配每一行", field))
+			}
+		}
+		logic, _ := fm["logic"].(string)
+		switch {
+		case i == 0:
+			selectivity = sel
+		case strings.EqualFold(logic, "OR"):
+			selectivity = min(1, selectivity+sel)
+		default:
+			selectivity *= sel
+		}
+	}
+
+	offset := int64(0)
+	page, _ := query["page"].(float64)
+	size, _ := query["size"].(float64)
+	if page > 1 && size > 0 {
+		offset = int64((page - 1) * size)
+	}
+
+	if stats != nil {
+		cost.TableRows = stats.Total
+		cost.EstimatedMatches = int64(float64(stats.Total) * selectivity)
+		if cost.Indexed {
+			cost.EstimatedScan = cost.EstimatedMatches + offset
+		} else {
+			cost.EstimatedScan = stats.Total
+		}
+	} else if offset > 0 {
+		cost.EstimatedScan = offset
+	}
+
+	if !cost.exceeds() {
+		return cost
+	}
+	switch {
+	case len(filters) == 0:
+		reasons = append(reasons, "检索没有任何过滤条件，需要扫描整张表")
+	case hasOR:
+		reasons = append(reasons, "过滤条件中包含 OR，无法利用索引缩小扫描范围")
+	case !cost.Indexed:
+		reasons = append(reasons, "过滤条件中没有可利用索引的条件 (如全文检索)，需要逐行比较")
+	}
+	if offset > maxScanRows/2 {
+		reasons = append(reasons, fmt.Sprintf("分页偏移 %d 过深，请缩小检索范围而不是继续翻页", offset))
+	}
+	cost.Reasons = reasons
+	return cost
+}
+
+// filterSelectivity 估算单个过滤条件的选择率。有字段统计时等值条件取 1/基数、空值判断取空值比例，否则使用假定值
+func filterSelectivity(fm map[string]interface{}, stats *domain.TableStats) float64 {
+	var column *domain.ColumnStats
+	if stats != nil {
+		field, _ := fm["field"].(string)
+		for i := range stats.Columns {
+			if stats.Columns[i].Field == field {
+				column = &stats.Columns[i]
+				break
+			}
+		}
+	}
+
+	var sel float64
+	op, _ := fm["op"].(string)
+	fuzzy, _ := fm["fuzzy"].(bool)
+	pinyin, _ := fm["pinyin"].(bool)
+	fts, _ := fm[port.FilterFullTextKey].(bool)
+	switch {
+	case fts:
+		sel = fullTextSelectivity
+	case fuzzy || pinyin:
+		sel = defaultFuzzySelectivity
+	case op == "is_null" || op == "is_empty":
+		sel = defaultNullSelectivity
+		if column != nil && op == "is_null" {
+			sel = column.NullRatio
+		}
+	case op == "is_not_null" || op == "is_not_empty":
+		sel = 1 - defaultNullSelectivity
+		if column != nil && op == "is_not_null" {
+			sel = 1 - column.NullRatio
+		}
+	default:
+		values := 0
+		if op == "" {
+			values = 1
+		}
+		if list, ok := fm[port.FilterValuesKey].([]interface{}); ok {
+			values += len(list)
+		}
+		sel = defaultEqualitySelectivity * float64(values)
+		if column != nil && column.Cardinality > 0 {
+			sel = float64(values) / float64(column.Cardinality)
+		}
+	}
+	if negate, _ := fm[port.FilterNegateKey].(bool); negate {
+		sel = 1 - sel
+	}
+	return max(0, min(1, sel))
+}
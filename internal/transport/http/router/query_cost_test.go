@@ -0,0 +1,71 @@
+// file: internal/transport/http/router/query_cost_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateQueryCost(t *testing.T) {
+	stats := &domain.TableStats{Total: 2_000_000, Columns: []domain.ColumnStats{
+		{Field: "sender", Cardinality: 40_000, CardinalityExact: true},
+		{Field: "remark", NullRatio: 0.9},
+	}}
+	eq := func(field string, value interface{}) map[string]interface{} {
+		return map[string]interface{}{"field": field, "value": value}
+	}
+
+	cost := estimateQueryCost(map[string]interface{}{}, stats, 1_000_000)
+	assert.True(t, cost.exceeds())
+	assert.Equal(t, int64(2_000_000), cost.EstimatedScan)
+	assert.Contains(t, cost.Reasons[0], "没有任何过滤条件")
+
+	cost = estimateQueryCost(map[string]interface{}{"filters": []interface{}{eq("sender", "鲁迅")}}, stats, 1_000_000)
+	assert.Equal(t, int64(50), cost.EstimatedMatches, "等值条件的选择率取 1/基数")
+	assert.True(t, cost.exceeds(), "没有可利用索引的条件时仍需扫描整张表")
+
+	fts := eq("body", "北平")
+	fts[port.FilterFullTextKey] = true
+	cost = estimateQueryCost(map[string]interface{}{"filters": []interface{}{fts, eq("sender", "鲁迅")}}, stats, 1_000_000)
+	assert.True(t, cost.Indexed)
+	assert.False(t, cost.exceeds())
+
+	or := eq("sender", "胡适")
+	or["logic"] = "OR"
+	cost = estimateQueryCost(map[string]interface{}{"filters": []interface{}{fts, or}}, stats, 1_000_000)
+	assert.False(t, cost.Indexed, "OR 使全文检索无法缩小扫描范围")
+	assert.True(t, cost.exceeds())
+
+	cost = estimateQueryCost(map[string]interface{}{"filters": []interface{}{map[string]interface{}{"field": "remark", "op": "is_null"}}}, stats, 1_000_000)
+	assert.Equal(t, int64(1_800_000), cost.EstimatedMatches)
+
+	cost = estimateQueryCost(map[string]interface{}{"page": float64(50_001), "size": float64(50)}, nil, 1_000_000)
+	assert.True(t, cost.exceeds(), "缺少表统计时仍能识别过深的分页")
+	assert.Equal(t, int64(-1), cost.TableRows)
+
+	cost = estimateQueryCost(map[string]interface{}{"filters": []interface{}{eq("sender", "鲁迅")}}, nil, 1_000_000)
+	assert.False(t, cost.exceeds(), "无法估算时不拦截")
+}
+
+func TestQueryCostEstimator_LearnsTableRows(t *testing.T) {
+	e := newQueryCostEstimator(QueryCostOptions{Policy: domain.QueryCostPolicyReject, MaxScanRows: 100}, nil, nil)
+	ctx := context.Background()
+
+	warning, reject := e.check(ctx, "archive", "letters", map[string]interface{}{})
+	assert.Nil(t, warning)
+	assert.False(t, reject, "未观察到表的行数前不拦截")
+
+	e.observe("archive", "letters", map[string]interface{}{"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "a"}}},
+		&port.QueryResult{Data: map[string]interface{}{"total": float64(3)}})
+	e.observe("archive", "letters", map[string]interface{}{}, &port.QueryResult{Data: map[string]interface{}{"total": float64(500)}})
+
+	warning, reject = e.check(ctx, "archive", "letters", map[string]interface{}{})
+	require.NotNil(t, warning)
+	assert.True(t, reject)
+	assert.Equal(t, int64(500), warning.TableRows, "只记录不带过滤条件的检索的 total")
+}
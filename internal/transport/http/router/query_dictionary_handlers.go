@@ -0,0 +1,88 @@
+// Package router file: internal/transport/http/router/query_dictionary_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/querydict"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getQueryDictionaryHandler 返回业务组的检索词典
+func getQueryDictionaryHandler(dictionaries *querydict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dict, err := dictionaries.Get(c.Param("bizName"))
+		if err != nil {
+			if errors.Is(err, querydict.ErrDictionaryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": dict})
+	}
+}
+
+// saveQueryDictionaryHandler 创建或替换业务组的检索词典，保存后立即作用于后续查询
+func saveQueryDictionaryHandler(dictionaries *querydict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Synonyms      []domain.SynonymGroup     `json:"synonyms"`
+			Normalization domain.NormalizationRules `json:"normalization"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		dict, err := dictionaries.Save(c.Request.Context(), domain.QueryDictionary{
+			BizName:       c.Param("bizName"),
+			Synonyms:      payload.Synonyms,
+			Normalization: payload.Normalization,
+		})
+		if err != nil {
+			if errors.Is(err, querydict.ErrInvalidDictionary) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": dict})
+	}
+}
+
+// deleteQueryDictionaryHandler 删除业务组的检索词典
+func deleteQueryDictionaryHandler(dictionaries *querydict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		if err := dictionaries.Delete(c.Request.Context(), bizName); err != nil {
+			if errors.Is(err, querydict.ErrDictionaryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("业务组 '%s' 的检索词典已删除", bizName)})
+	}
+}
+
+// previewQueryDictionaryHandler 返回 ?field= 上的 ?value= 在查询时会被展开成的全部写法
+func previewQueryDictionaryHandler(dictionaries *querydict.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		field, value := c.Query("field"), c.Query("value")
+		if field == "" || value == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "必须提供 field 与 value 参数"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{
+			"field":    field,
+			"value":    value,
+			"variants": dictionaries.Preview(c.Param("bizName"), field, value),
+		}})
+	}
+}
@@ -0,0 +1,72 @@
+// Package router file: internal/transport/http/router/ranking_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updateTableRankingConfigHandler 保存表的相关度得分配置。expression 为空时使用加权计分；
+// bm25 依赖全文索引，表未配置全文检索时拒绝保存 (之后删除全文检索配置的表退回加权计分)。
+// 各字段的权重由字段配置的 search_weight 设置
+func updateTableRankingConfigHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		var cfg domain.RankingConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg.Expression == "" {
+			cfg.Expression = domain.RankExpressionWeighted
+		}
+
+		switch cfg.Expression {
+		case domain.RankExpressionWeighted:
+		case domain.RankExpressionBM25:
+			bizConfig, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			if bizConfig == nil {
+				_ = c.Error(port.ErrBizNotFound)
+				return
+			}
+			tableConfig, ok := bizConfig.Tables[tableName]
+			if !ok {
+				_ = c.Error(port.ErrTableNotFoundInBiz)
+				return
+			}
+			if tableConfig.FTS == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("表 '%s' 未配置全文检索，无法使用 bm25 计分", tableName)})
+				return
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支持的计分方式 '%s'，可选 %s 或 %s", cfg.Expression, domain.RankExpressionWeighted, domain.RankExpressionBM25)})
+			return
+		}
+
+		if err := configService.UpdateTableRankingConfig(c.Request.Context(), bizName, tableName, &cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": cfg})
+	}
+}
+
+// deleteTableRankingConfigHandler 清除表的相关度得分配置，恢复默认的加权计分
+func deleteTableRankingConfigHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		if err := configService.UpdateTableRankingConfig(c.Request.Context(), bizName, tableName, nil); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("表 '%s/%s' 的相关度得分配置已恢复默认", bizName, tableName)})
+	}
+}
@@ -0,0 +1,86 @@
+// Package router file: internal/transport/http/router/read_split_biz_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/readsplitbiz"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listReadSplitBizHandler 列出所有读写分离业务组定义
+func listReadSplitBizHandler(readSplitService *readsplitbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		defs, err := readSplitService.List(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, defs, page)
+	}
+}
+
+// saveReadSplitBizHandler 创建或更新一个读写分离业务组，保存后立即生效
+func saveReadSplitBizHandler(readSplitService *readsplitbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Description string   `json:"description"`
+			Writer      string   `json:"writer" binding:"required"`
+			Readers     []string `json:"readers" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		def := domain.ReadSplitBiz{
+			BizName:     c.Param("bizName"),
+			Description: payload.Description,
+			Writer:      payload.Writer,
+			Readers:     payload.Readers,
+		}
+		if err := readSplitService.Save(c.Request.Context(), def); err != nil {
+			if errors.Is(err, readsplitbiz.ErrInvalidDefinition) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("读写分离业务组 '%s' 已保存并生效", def.BizName)})
+	}
+}
+
+// readSplitBizStatusHandler 返回读写分离业务组各副本的分发状态
+func readSplitBizStatusHandler(readSplitService *readsplitbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		readers, err := readSplitService.ReaderStatus(c.Param("bizName"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": readers})
+	}
+}
+
+// deleteReadSplitBizHandler 删除一个读写分离业务组
+func deleteReadSplitBizHandler(readSplitService *readsplitbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		if err := readSplitService.Delete(c.Request.Context(), bizName); err != nil {
+			if errors.Is(err, readsplitbiz.ErrReadSplitBizNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("读写分离业务组 '%s' 已删除", bizName)})
+	}
+}
@@ -0,0 +1,185 @@
+// Package router file: internal/transport/http/router/record_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/accesslog"
+	"ArchiveAegis/internal/service/annotations"
+	"ArchiveAegis/internal/service/identpolicy"
+	"ArchiveAegis/internal/service/links"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCitationTemplate 在业务组未配置引用模板时使用
+const defaultCitationTemplate = `{{._biz}} / {{._table}} #{{._id}}. 访问于 {{._accessed}}. {{._url}}`
+
+// parseCitationTemplate 解析业务组的引用模板。
+// 模板可引用记录中的任意可返回字段，以及 _biz、_table、_id、_url、_accessed 几个内置变量。
+func parseCitationTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultCitationTemplate
+	}
+	return template.New("citation").Parse(text)
+}
+
+// recordHandlerV1 按主键返回单条记录及其引用元数据，链接稳定、不受搜索分页影响。
+// 复合主键以逗号拼接；当同一主键存在于多个库时，可通过 ?lib= 指定。
+// 启用记录批注时，?annotations=true 会在结果中附带已通过审核的批注；
+// ?include_links=true 会附带与其他记录的关联。
+// 业务组标记为敏感时，每次读取写入记录访问日志，写入失败则不返回记录。
+func recordHandlerV1(registry map[string]port.DataSource, configService port.QueryAdminConfigService, notes *annotations.Service, recordLinks *links.Service, accessLog *accesslog.Service, identifiers *identpolicy.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName, recordID := c.Param("biz"), c.Param("table"), c.Param("id")
+		libName := c.Query("lib")
+		if err := identifiers.Check(domain.IdentifierKindTable, tableName); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		result, err := dataSource.Query(c.Request.Context(), port.QueryRequest{
+			BizName: bizName,
+			Query: map[string]interface{}{
+				port.QueryModeKey: port.QueryModeRecord,
+				"table":           tableName,
+				"id":              recordID,
+				"lib":             libName,
+			},
+		})
+		if err != nil {
+			slog.Error("recordHandlerV1 执行失败", "biz", bizName, "table", tableName, "id", recordID, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		record, _ := result.Data["record"].(map[string]interface{})
+		if record == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("记录 '%s' 在表 '%s' 中不存在", recordID, tableName)})
+			return
+		}
+		if accessLog != nil {
+			if err := accessLog.RecordPermalink(c.Request.Context(), bizName, tableName, recordID, accessViewer(c)); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
+		// 命中多个库时，永久链接需携带库名才能唯一定位
+		permalink := fmt.Sprintf("%s/api/v1/data/record/%s/%s/%s",
+			requestBaseURL(c), url.PathEscape(bizName), url.PathEscape(tableName), url.PathEscape(recordID))
+		if libName == "" {
+			// 经 gRPC 传输后数字均为 float64
+			if matches, _ := result.Data["matches"].(float64); matches > 1 {
+				libName, _ = record["__lib"].(string)
+			}
+		}
+		if libName != "" {
+			permalink += "?lib=" + url.QueryEscape(libName)
+		}
+
+		bizConfig, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		templateText := ""
+		if bizConfig != nil {
+			templateText = bizConfig.CitationTemplate
+		}
+
+		accessedAt := time.Now()
+		citationText, err := renderCitation(templateText, record, map[string]interface{}{
+			"_biz":      bizName,
+			"_table":    tableName,
+			"_id":       recordID,
+			"_url":      permalink,
+			"_accessed": accessedAt.Format("2006-01-02"),
+		})
+		if err != nil {
+			// 模板在保存时已校验，这里的失败多为字段值导致，不影响记录本身的返回
+			slog.Warn("recordHandlerV1 渲染引用失败", "biz", bizName, "error", err)
+		}
+
+		response := gin.H{
+			"record": record,
+			"citation": gin.H{
+				"text":        citationText,
+				"permalink":   permalink,
+				"accessed_at": accessedAt.Format(time.RFC3339),
+			},
+			"source": result.Source,
+		}
+		if notes != nil && c.Query("annotations") == "true" {
+			recordLib, _ := record["__lib"].(string)
+			if recordLib == "" {
+				recordLib = libName
+			}
+			approved, err := notes.Approved(c.Request.Context(), bizName, tableName, recordID, recordLib)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			response["annotations"] = approved
+		}
+		if recordLinks != nil && c.Query("include_links") == "true" {
+			expanded, err := recordLinks.Expand(c.Request.Context(), bizName, tableName, []string{recordID}, service.ClaimFrom(c.Request) == nil)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			linked := expanded[recordID]
+			if linked == nil {
+				linked = []domain.LinkedRecord{}
+			}
+			response["links"] = linked
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// renderCitation 使用记录字段与内置变量渲染引用文本，内置变量优先于同名字段
+func renderCitation(templateText string, record, builtins map[string]interface{}) (string, error) {
+	tmpl, err := parseCitationTemplate(templateText)
+	if err != nil {
+		return "", err
+	}
+	vars := make(map[string]interface{}, len(record)+len(builtins))
+	for k, v := range record {
+		vars[k] = v
+	}
+	for k, v := range builtins {
+		vars[k] = v
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// requestBaseURL 根据请求推断对外访问的根地址，兼容反向代理设置的 X-Forwarded-Proto
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,126 @@
+// Package router file: internal/transport/http/router/replace_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/uploads"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// replaceLibRequest 是以 JSON 提交的库文件替换请求。新库文件由 source_path (网关与插件所在主机上的文件路径)
+// 或 upload_id (已完成的分块上传，见 /admin/uploads) 给出，二者取其一
+type replaceLibRequest struct {
+	Lib        string `json:"lib" binding:"required"`
+	SourcePath string `json:"source_path"`
+	UploadID   string `json:"upload_id"`
+}
+
+// replaceLibHandler 以新的库文件整体替换业务组中的一个库，用于整库重新导入 (见 port.MutateOpReplace)。
+// 请求可以是 multipart 表单 (字段 "file" 为新库文件，"lib" 为库名)，也可以是 JSON {"lib": "a", "source_path": "/data/a.db"}
+// 或 {"lib": "a", "upload_id": "..."}；大文件应先分块上传，替换成功后该上传会被删除。
+// 数据源在替换期间暂停该业务组的查询，替换完成后重新发现表结构，响应中给出新库各表的行数与表结构的变化
+func replaceLibHandler(registry map[string]port.DataSource, uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		var body replaceLibRequest
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "缺少新库文件 (表单字段 'file')"})
+				return
+			}
+			body.Lib = c.PostForm("lib")
+			if body.Lib == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "缺少库名 (表单字段 'lib')"})
+				return
+			}
+			path, err := saveUploadedDB(fileHeader)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			// 数据源会把新库复制到业务组目录，上传的临时文件在替换结束后即可删除
+			defer os.Remove(path)
+			body.SourcePath = path
+		} else if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if (body.SourcePath == "") == (body.UploadID == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "source_path 与 upload_id 必须且只能提供一个"})
+			return
+		}
+		if body.UploadID != "" {
+			if uploadService == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "分块上传未启用"})
+				return
+			}
+			path, _, err := uploadService.Path(c.Request.Context(), body.UploadID)
+			if err != nil {
+				respondUploadError(c, err)
+				return
+			}
+			body.SourcePath = path
+		}
+
+		result, err := port.AdminMutate(c.Request.Context(), dataSource, port.MutateRequest{
+			BizName:   bizName,
+			Operation: port.MutateOpReplace,
+			Payload:   map[string]interface{}{"lib": body.Lib, "source_path": body.SourcePath},
+		})
+		switch {
+		case errors.Is(err, port.ErrBizNotFound):
+			_ = c.Error(err)
+		case err != nil && strings.Contains(err.Error(), "无效请求"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err != nil:
+			slog.Error("replaceLibHandler 执行失败", "biz", bizName, "lib", body.Lib, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			slog.Info("[Replace] 库文件已替换", "biz", bizName, "lib", body.Lib, "backup_dir", result.Data["backup_dir"])
+			if body.UploadID != "" {
+				if err := uploadService.Delete(c.Request.Context(), body.UploadID); err != nil {
+					slog.Warn("[Replace] 删除已使用的上传失败", "upload_id", body.UploadID, "error", err)
+				}
+			}
+			c.JSON(http.StatusOK, gin.H{"data": result.Data})
+		}
+	}
+}
+
+// saveUploadedDB 把上传的库文件保存到临时文件，返回其路径
+func saveUploadedDB(fileHeader *multipart.FileHeader) (string, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp("", "aegis-replace-*.db")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,153 @@
+// Package router file: internal/transport/http/router/replication_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/replicacheck"
+	"ArchiveAegis/internal/service/replication"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listReplicationTargetsHandler 列出所有同步目标及其同步状态
+func listReplicationTargetsHandler(replicationService *replication.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		targets, err := replicationService.ListTargets(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, targets, page)
+	}
+}
+
+// saveReplicationTargetHandler 创建或更新一个业务组的同步目标
+func saveReplicationTargetHandler(replicationService *replication.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			TargetURL string `json:"target_url" binding:"required"`
+			TargetBiz string `json:"target_biz" binding:"required"`
+			AuthToken string `json:"auth_token" binding:"required"`
+			Enabled   *bool  `json:"enabled"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		target := domain.ReplicationTarget{
+			BizName:   c.Param("bizName"),
+			TargetURL: payload.TargetURL,
+			TargetBiz: payload.TargetBiz,
+			AuthToken: payload.AuthToken,
+			Enabled:   payload.Enabled == nil || *payload.Enabled,
+		}
+		if err := replicationService.SaveTarget(c.Request.Context(), target); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "同步目标已保存"})
+	}
+}
+
+// deleteReplicationTargetHandler 删除一个业务组的同步目标
+func deleteReplicationTargetHandler(replicationService *replication.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		if err := replicationService.DeleteTarget(c.Request.Context(), bizName); err != nil {
+			if errors.Is(err, replication.ErrTargetNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("业务组 '%s' 的同步目标已删除。", bizName)})
+	}
+}
+
+// syncReplicationTargetHandler 立即触发一次同步，而不等待后台周期
+func syncReplicationTargetHandler(replicationService *replication.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		pushed, err := replicationService.SyncNow(c.Request.Context(), bizName)
+		if err != nil {
+			if errors.Is(err, replication.ErrTargetNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "pushed": pushed})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "pushed": pushed})
+	}
+}
+
+// applyReplicationHandler 在同步目标实例上接收并应用来自源实例的变更 (源端优先)
+func applyReplicationHandler(registry map[string]port.DataSource) gin.HandlerFunc {
+	type RequestBody struct {
+		BizName string        `json:"biz_name" binding:"required"`
+		Changes []interface{} `json:"changes"`
+	}
+
+	return func(c *gin.Context) {
+		var reqBody RequestBody
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		dataSource, exists := registry[reqBody.BizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		result, err := port.AdminMutate(c.Request.Context(), dataSource, port.MutateRequest{
+			BizName:   reqBody.BizName,
+			Operation: port.MutateOpReplicate,
+			Payload:   map[string]interface{}{"changes": reqBody.Changes},
+		})
+		if err != nil {
+			slog.Error("applyReplicationHandler 执行失败", "biz", reqBody.BizName, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// verifyReplicaHandler 以只读方式比对业务组与其副本的抽样查询结果。请求体可为空 (使用同步目标)，
+// 或指定 {"compare_biz": "..."} 比对本实例上的另一个业务组，或指定 target_url、target_biz 与 auth_token
+func verifyReplicaHandler(replicaCheck *replicacheck.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req replicacheck.Request
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+				return
+			}
+		}
+		report, err := replicaCheck.Verify(c.Request.Context(), c.Param("bizName"), req)
+		if err != nil {
+			if errors.Is(err, replicacheck.ErrInvalidRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		if report.Diverged > 0 {
+			slog.Warn("副本比对发现差异", "biz", report.BizName, "against", report.Against, "diverged", report.Diverged)
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
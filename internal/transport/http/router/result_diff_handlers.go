@@ -0,0 +1,40 @@
+// Package router file: internal/transport/http/router/result_diff_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/resultdiff"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// diffHandlerV1 比对同一查询在两个业务组上的结果。请求体为
+// {"before": {"biz_name", "query"}, "after": {"biz_name", "query"}, "key": [...], "page": 1, "size": 100}。
+// 比对当前数据与快照时，先把快照恢复为另一个业务组，再以它作为 before
+func diffHandlerV1(diffs *resultdiff.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req resultdiff.Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+			return
+		}
+		diff, err := diffs.Diff(c.Request.Context(), req)
+		if err != nil {
+			respondResultDiffError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": diff})
+	}
+}
+
+func respondResultDiffError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, resultdiff.ErrInvalidRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, resultdiff.ErrTooManyRows):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
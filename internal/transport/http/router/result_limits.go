@@ -0,0 +1,82 @@
+// Package router file: internal/transport/http/router/result_limits.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// defaultMaxResponseBytes 是业务组与网关均未配置时单次检索结果的字节数上限
+const defaultMaxResponseBytes = 8 << 20
+
+// responseByteLimit 返回业务组生效的结果字节数上限：业务组的设置优先，其次为网关配置 (不大于 0 时取默认值)
+func responseByteLimit(ctx context.Context, configService port.QueryAdminConfigService, bizName string, gatewayLimit int64) int64 {
+	if configService != nil {
+		if cfg, err := configService.GetBizQueryConfig(ctx, bizName); err == nil && cfg != nil && cfg.MaxResponseBytes > 0 {
+			return cfg.MaxResponseBytes
+		}
+	}
+	if gatewayLimit <= 0 {
+		return defaultMaxResponseBytes
+	}
+	return gatewayLimit
+}
+
+// capResultBytes 按 maxBytes 截断结果的 items，并设置 port.ResultTruncatedKey。
+// 数据源已在扫描时按 port.QueryMaxBytesKey 限制结果，这里为不识别该键的插件兜底；
+// 结果可能来自缓存，需要截断时复制后再修改
+func capResultBytes(result *port.QueryResult, maxBytes int64) *port.QueryResult {
+	if result == nil || maxBytes <= 0 {
+		return result
+	}
+	items, ok := result.Data["items"].([]interface{})
+	if !ok {
+		return result
+	}
+	var used int64
+	for i, item := range items {
+		if used += approxJSONSize(item); used > maxBytes {
+			data := make(map[string]interface{}, len(result.Data)+1)
+			for k, v := range result.Data {
+				data[k] = v
+			}
+			data["items"] = items[:i:i]
+			data[port.ResultTruncatedKey] = true
+			return &port.QueryResult{Data: data, Source: result.Source}
+		}
+	}
+	return result
+}
+
+// approxJSONSize 估算值编码为 JSON 后的大小，只求量级接近，不做转义计算
+func approxJSONSize(v interface{}) int64 {
+	switch val := v.(type) {
+	case nil:
+		return 4
+	case string:
+		return int64(len(val)) + 2
+	case bool:
+		return 5
+	case float64, int64, int:
+		return 20
+	case map[string]interface{}:
+		size := int64(2)
+		for k, item := range val {
+			size += int64(len(k)) + 4 + approxJSONSize(item)
+		}
+		return size
+	case []interface{}:
+		size := int64(2)
+		for _, item := range val {
+			size += approxJSONSize(item) + 1
+		}
+		return size
+	default:
+		if b, err := json.Marshal(val); err == nil {
+			return int64(len(b))
+		}
+		return int64(len(fmt.Sprint(val)))
+	}
+}
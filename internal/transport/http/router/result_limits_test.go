@@ -0,0 +1,26 @@
+// file: internal/transport/http/router/result_limits_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapResultBytes(t *testing.T) {
+	row := map[string]interface{}{"id": float64(1), "body": strings.Repeat("x", 1000)}
+	items := []interface{}{row, row, row, row, row}
+	result := &port.QueryResult{Data: map[string]interface{}{"items": items, "total": float64(5)}, Source: "sqlite"}
+
+	capped := capResultBytes(result, 2500)
+	assert.Len(t, capped.Data["items"], 2)
+	assert.Equal(t, true, capped.Data[port.ResultTruncatedKey])
+	assert.Equal(t, float64(5), capped.Data["total"])
+	assert.Len(t, result.Data["items"], 5, "原结果可能来自缓存，不应被修改")
+	assert.NotContains(t, result.Data, port.ResultTruncatedKey)
+
+	assert.Same(t, result, capResultBytes(result, 1<<20), "未超出上限时原样返回")
+	assert.Same(t, result, capResultBytes(result, 0))
+}
@@ -2,12 +2,79 @@
 package router
 
 import (
+	"ArchiveAegis/internal/adapter/datasource/caching"
 	"ArchiveAegis/internal/aegmiddleware"
 	"ArchiveAegis/internal/aegobserve"
 	"ArchiveAegis/internal/core/domain"
 	"ArchiveAegis/internal/core/port"
 	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/abuse"
+	"ArchiveAegis/internal/service/accesslog"
+	"ArchiveAegis/internal/service/analytics"
+	"ArchiveAegis/internal/service/annotations"
+	"ArchiveAegis/internal/service/approvals"
+	"ArchiveAegis/internal/service/bizclone"
+	"ArchiveAegis/internal/service/bizrename"
+	"ArchiveAegis/internal/service/bookmarks"
+	"ArchiveAegis/internal/service/bootreport"
+	"ArchiveAegis/internal/service/capture"
+	"ArchiveAegis/internal/service/catalog"
+	"ArchiveAegis/internal/service/colstats"
+	"ArchiveAegis/internal/service/compaction"
+	"ArchiveAegis/internal/service/compliance"
+	"ArchiveAegis/internal/service/configtemplates"
+	"ArchiveAegis/internal/service/datadict"
+	"ArchiveAegis/internal/service/datasubject"
+	"ArchiveAegis/internal/service/demo"
+	"ArchiveAegis/internal/service/diagnostics"
+	"ArchiveAegis/internal/service/digest"
+	"ArchiveAegis/internal/service/doctor"
+	"ArchiveAegis/internal/service/embed"
+	"ArchiveAegis/internal/service/exports"
+	"ArchiveAegis/internal/service/fieldguard"
+	"ArchiveAegis/internal/service/fixity"
+	"ArchiveAegis/internal/service/fulltext"
+	"ArchiveAegis/internal/service/identpolicy"
+	"ArchiveAegis/internal/service/iiif"
+	"ArchiveAegis/internal/service/impact"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/internal/service/licensing"
+	"ArchiveAegis/internal/service/links"
+	"ArchiveAegis/internal/service/maintenance"
+	"ArchiveAegis/internal/service/oaipmh"
+	"ArchiveAegis/internal/service/ownership"
+	"ArchiveAegis/internal/service/penalties"
+	"ArchiveAegis/internal/service/piiscan"
 	"ArchiveAegis/internal/service/plugin_manager"
+	"ArchiveAegis/internal/service/policy"
+	"ArchiveAegis/internal/service/preferences"
+	"ArchiveAegis/internal/service/provenance"
+	"ArchiveAegis/internal/service/querydict"
+	"ArchiveAegis/internal/service/queryext"
+	"ArchiveAegis/internal/service/querytemplates"
+	"ArchiveAegis/internal/service/readsplitbiz"
+	"ArchiveAegis/internal/service/replicacheck"
+	"ArchiveAegis/internal/service/replication"
+	"ArchiveAegis/internal/service/resultdiff"
+	"ArchiveAegis/internal/service/semantic"
+	"ArchiveAegis/internal/service/sessions"
+	"ArchiveAegis/internal/service/sharding"
+	"ArchiveAegis/internal/service/sharelinks"
+	"ArchiveAegis/internal/service/siem"
+	"ArchiveAegis/internal/service/signing"
+	"ArchiveAegis/internal/service/sitemap"
+	"ArchiveAegis/internal/service/slo"
+	"ArchiveAegis/internal/service/snapshot"
+	"ArchiveAegis/internal/service/standby"
+	"ArchiveAegis/internal/service/storage"
+	"ArchiveAegis/internal/service/suggest"
+	"ArchiveAegis/internal/service/telemetry"
+	"ArchiveAegis/internal/service/typegen"
+	"ArchiveAegis/internal/service/updates"
+	"ArchiveAegis/internal/service/uploads"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"ArchiveAegis/internal/service/vocabulary"
+	"ArchiveAegis/internal/service/warmup"
 	"ArchiveAegis/internal/transport/http/middleware"
 	"database/sql"
 	"errors"
@@ -15,10 +82,10 @@ import (
 	"log/slog"
 	"net/http"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,7 +94,141 @@ type Dependencies struct {
 	Registry           map[string]port.DataSource
 	AdminConfigService port.QueryAdminConfigService
 	PluginManager      *plugin_manager.PluginManager
-	RateLimiter        *aegmiddleware.BusinessRateLimiter
+	OAIPMHService      *oaipmh.Service
+	IIIFService        *iiif.Service
+	ReplicationService *replication.Service
+	// ReplicaCheck 比对业务组与其副本的抽样查询结果
+	ReplicaCheck      *replicacheck.Service
+	SnapshotService   *snapshot.Service
+	VirtualBizService *virtualbiz.Service
+	// ShardedBizService 管理按分片键范围路由到多个业务组的分片业务组
+	ShardedBizService *sharding.Service
+	// ReadSplitService 管理写操作发往写入实例、查询由副本分担的读写分离业务组
+	ReadSplitService *readsplitbiz.Service
+	// Warmup 报告各业务组最近一次预热的结果
+	Warmup *warmup.Service
+	// Uploads 管理库文件替换、快照恢复等接口所用的分块上传
+	Uploads *uploads.Service
+	// ConfigTemplates 管理可复用的业务组配置模板并将其应用到业务组
+	ConfigTemplates *configtemplates.Service
+	// BizClone 把业务组的配置复制到另一个尚未配置的业务组
+	BizClone *bizclone.Service
+	// BizRename 重命名业务组并迁移其配置、实例绑定与历史记录，为 nil 时不提供重命名
+	BizRename         *bizrename.Service
+	QueryDictionaries *querydict.Service
+	// DataDictionary 是字段级的数据字典，合并到 /meta/schema 的字段描述中
+	DataDictionary *datadict.Service
+	// Vocabularies 把字段绑定到受控词表，提供下拉选项并在严格模式下校验 Mutate 写入的值
+	Vocabularies *vocabulary.Service
+	// RecordLinks 是业务组记录之间的关联注册表，为 nil 时不展开关联，也不检查删除的引用完整性
+	RecordLinks *links.Service
+	// Compliance 生成安全相关配置与近期审计记录的合规快照
+	Compliance *compliance.Service
+	// SecurityEvents 把登录、锁定、封禁与管理变更等安全事件转发到 syslog 或 HTTP SIEM
+	SecurityEvents *siem.Service
+	// AuthorizationHooks 在数据平面与管理平面的请求到达处理器之前调用外部授权策略
+	AuthorizationHooks *policy.Service
+	// QueryTemplates 是管理员预先定义的带命名参数的查询模板，客户端按名称执行
+	QueryTemplates *querytemplates.Service
+	// ResultDiff 比对同一查询在两个业务组上的结果
+	ResultDiff         *resultdiff.Service
+	FullTextService    *fulltext.Service
+	JobService         *jobs.Service
+	DemoService        *demo.Service
+	DiagnosticsService *diagnostics.Service
+	DoctorService      *doctor.Service
+	CatalogService     *catalog.Service
+	Preferences        *preferences.Service
+	Bookmarks          *bookmarks.Service
+	ShareLinks         *sharelinks.Service
+	Embeds             *embed.Service
+	// Annotations 为 nil 表示未启用记录批注
+	Annotations *annotations.Service
+	// UsageAnalytics 为 nil 表示未启用检索统计
+	UsageAnalytics *analytics.Service
+	// SearchSuggestions 为 nil 表示未启用拼写建议
+	SearchSuggestions *suggest.Service
+	// SemanticSearch 为 nil 表示未启用语义检索
+	SemanticSearch *semantic.Service
+	// Sitemaps 为 nil 表示未启用站点地图与 JSON-LD 元数据
+	Sitemaps *sitemap.Service
+	// RequestSigning 为 nil 表示未启用服务器间的 HMAC 请求签名
+	RequestSigning *signing.Service
+	// Approvals 为 nil 表示高风险的管理变更直接生效，不需要第二位管理员审批
+	Approvals *approvals.Service
+	// Digests 为 nil 表示未启用每日摘要
+	Digests *digest.Service
+	// DataSubjects 提供数据主体的检索、导出与删除，为 nil 时相应接口返回 404
+	DataSubjects *datasubject.Service
+	// Exports 以后台任务导出表中的记录，可加密压缩包；为 nil 时相应接口返回 404
+	Exports *exports.Service
+	// BootReport 是网关的启动报告，为 nil 时相应接口返回 404
+	BootReport *bootreport.Recorder
+	// Standby 提供 auth.db 快照与备用网关的只读模式、复制状态和提升，为 nil 时相应接口返回 404
+	Standby *standby.Service
+	// PIIScanner 抽样扫描业务组可返回字段中的个人信息，为 nil 时相应接口返回 404
+	PIIScanner *piiscan.Service
+	// Provenance 为导出文件与大批量检索结果附加来源信息，为 nil 时不附加
+	Provenance *provenance.Service
+	// Fixity 定期校验业务组库文件的校验值，为 nil 时相应接口返回 404
+	Fixity *fixity.Service
+	// SLO 按业务组跟踪服务等级目标与错误预算，为 nil 时相应接口返回 404
+	SLO *slo.Service
+	// Maintenance 按计划与阈值维护业务组的库文件，为 nil 时相应接口返回 404
+	Maintenance *maintenance.Service
+	// ColumnStats 计算并缓存表中各字段的统计，供界面选择过滤控件
+	ColumnStats *colstats.Service
+	// Compaction 把业务组的多个库文件合并为一个
+	Compaction *compaction.Service
+	// Storage 统计空间占用并在磁盘可用空间不足时拒绝插件安装与快照恢复，为 nil 时不做检查
+	Storage *storage.Service
+	// Updates 为 nil 表示未启用更新检查
+	Updates *updates.Service
+	// Captures 按管理员的开关采集指定业务组的请求与响应
+	Captures *capture.Service
+	// Licenses 保存并离线校验商业插件的授权
+	Licenses *licensing.Service
+	// Telemetry 是默认关闭的匿名使用统计，未开启时仍可预览将要发送的内容
+	Telemetry *telemetry.Service
+	// BuildInfo 是网关的版本与构建信息，由 /api/v1/system/info 返回
+	BuildInfo domain.BuildInfo
+	// FieldGuard 在网关侧按字段的可返回性再次过滤查询结果
+	FieldGuard *fieldguard.Service
+	// IdentifierPolicy 按标识符策略检查查询中的表名与字段名，为 nil 时不检查
+	IdentifierPolicy *identpolicy.Service
+	// QueryExtensions 是各业务组允许客户端传入的插件专有查询选项，为 nil 时拒绝所有扩展选项
+	QueryExtensions *queryext.Service
+	// MaxInValues 是过滤条件中 "in" 运算符取值列表的最大长度，不大于 0 时取默认值
+	MaxInValues int
+	// MaxResponseBytes 是单次检索结果的默认字节数上限，业务组可单独设置；不大于 0 时取默认值
+	MaxResponseBytes int64
+	// QueryCost 是检索代价检查的默认设置，业务组可单独设置策略与扫描行数上限
+	QueryCost QueryCostOptions
+	// AdaptivePaging 控制按客户端的下载速度调低检索结果的字节数上限
+	AdaptivePaging AdaptivePagingOptions
+	// FieldImpact 在修改字段配置前分析对视图、分享链接与保存的查询的影响
+	FieldImpact *impact.Service
+	// Typegen 由业务组的字段配置生成客户端类型定义
+	Typegen *typegen.Service
+	// BizOwners 记录业务组的委派管理员
+	BizOwners   *ownership.Service
+	QueryCache  *caching.Cache
+	RateLimiter *aegmiddleware.BusinessRateLimiter
+	// Compression 是 API 响应压缩的配置，未启用时响应原样返回
+	Compression CompressionOptions
+	// CachePolicy 是各类接口响应的 Cache-Control 取值
+	CachePolicy CachePolicyOptions
+	// Penalties 保存登录锁定与临时封禁，为 nil 时登录不做锁定
+	Penalties *penalties.Service
+	// AccessLog 记录敏感业务组中被查看的记录，为 nil 时不记录
+	AccessLog *accesslog.Service
+	// Sessions 记录登录会话并限制每个用户同时有效的会话数，为 nil 时 Token 不关联会话
+	Sessions *sessions.Service
+	// AbuseDetector 识别逐页抓取、反复 403 等异常行为并临时封禁，为 nil 时不做检测
+	AbuseDetector *abuse.Service
+	// LoadShedder 在过载时以 503 提前拒绝数据平面请求，为 nil 时不做过载保护
+	LoadShedder        *aegmiddleware.LoadShedder
+	ScrapeAllowlist    *aegobserve.Allowlist
 	AuthDB             *sql.DB
 	SetupToken         string
 	SetupTokenDeadline time.Time
@@ -39,26 +240,46 @@ func New(deps Dependencies) http.Handler {
 
 	// --- 全局中间件注册 ---
 	router.Use(aegobserve.PrometheusMiddleware())
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	router.Use(compressionMiddleware(deps.Compression))
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "If-None-Match", "If-Match", CostOverrideHeader, ChunkChecksumHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 	router.Use(middleware.ErrorHandlingMiddleware())
+	router.Use(standbyReadOnly(deps.Standby))
 
 	authService := service.NewAuthenticator(deps.AuthDB)
+	if deps.Sessions != nil {
+		authService.Sessions = deps.Sessions
+	}
+	if deps.Approvals != nil {
+		registerApprovalAppliers(deps)
+	}
+
+	// 站点地图按搜索引擎的约定挂在站点根路径下
+	seoGroup := router.Group("/")
+	seoGroup.Use(WrapNetHTTP(deps.RateLimiter.LightweightChain))
+	{
+		seoGroup.GET("/robots.txt", robotsHandler(deps.Sitemaps))
+		seoGroup.GET("/sitemap.xml", sitemapIndexHandler(deps.Sitemaps))
+		seoGroup.GET("/sitemaps/:biz/:table/:file", sitemapPageHandler(deps.Sitemaps))
+	}
 
+	cachePolicies := deps.CachePolicy.withDefaults()
 	v1 := router.Group("/api/v1")
+	if deps.SecurityEvents != nil {
+		v1.Use(adminChangeEvents(deps.SecurityEvents))
+	}
 	{
 		// --- 系统/认证平面 ---
 		authGroup := v1.Group("/auth")
-		authGroup.Use(WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		authGroup.Use(cachePolicy(cachePolicies.Admin, ""), WrapNetHTTP(deps.RateLimiter.LightweightChain))
 		{
-			authGroup.POST("/login", loginHandler(deps.AuthDB))
+			authGroup.POST("/login", loginHandler(deps.AuthDB, deps.Penalties, deps.Sessions, deps.SecurityEvents))
 		}
 
 		systemGroup := v1.Group("/system")
@@ -66,64 +287,424 @@ func New(deps Dependencies) http.Handler {
 		{
 			systemGroup.Any("/setup", setupHandler(deps.AuthDB, deps.SetupToken, deps.SetupTokenDeadline))
 		}
-		v1.GET("/system/status", statusHandler(deps.AuthDB))
+		v1.GET("/system/status", statusHandler(deps.AuthDB, deps.Standby))
+		v1.GET("/system/info", authMiddleware(authService, deps.RequestSigning), requireAdmin(), WrapNetHTTP(deps.RateLimiter.LightweightChain), systemInfoHandler(deps.BuildInfo, deps.AuthDB, deps.PluginManager))
+		v1.GET("/system/boot-report", authMiddleware(authService, deps.RequestSigning), requireAdmin(), WrapNetHTTP(deps.RateLimiter.LightweightChain), bootReportHandler(deps.BootReport))
+
+		// OAI-PMH 收割端点面向匿名的标准收割工具，数据可见性仍受业务组公开搜索配置约束
+		oaiGroup := v1.Group("/oai")
+		oaiGroup.Use(WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		{
+			oaiGroup.GET("", oaiPMHHandler(deps.OAIPMHService))
+			oaiGroup.POST("", oaiPMHHandler(deps.OAIPMHService))
+		}
+
+		// 分享链接面向匿名访问者，除 IP 限流外每条链接还有自己的访问频率限制
+		shareGroup := v1.Group("/share")
+		shareGroup.Use(WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		{
+			shareGroup.GET("/:token", openShareLinkHandler(deps.ShareLinks))
+		}
+
+		// 嵌入接口面向外部网站的匿名访问者，查询参数由嵌入密钥签名
+		embedGroup := v1.Group("/embed")
+		embedGroup.Use(WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		{
+			embedGroup.GET("", embedQueryHandler(deps.Embeds))
+		}
 
 		// --- 元数据/发现平面 ---
 		metaGroup := v1.Group("/meta")
-		metaGroup.Use(authMiddleware(authService), WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		metaGroup.Use(cachePolicy(cachePolicies.Meta, cachePolicies.Anonymous), authMiddleware(authService, deps.RequestSigning), abuseDetection(deps.AbuseDetector), WrapNetHTTP(deps.RateLimiter.LightweightChain), captureExchanges(deps.Captures), requireBizEnabled(deps.AdminConfigService), sloTracking(deps.SLO))
 		{
-			metaGroup.GET("/biz", bizHandlerV1(deps.Registry))
-			metaGroup.GET("/schema/:bizName", schemaHandlerV1(deps.Registry))
+			metaGroup.GET("/biz", bizHandlerV1(deps.Registry, deps.AdminConfigService))
+			metaGroup.GET("/catalog", catalogHandlerV1(deps.CatalogService))
+			metaGroup.GET("/schema/:bizName", schemaHandlerV1(deps.Registry, deps.DataDictionary))
+			metaGroup.GET("/dictionary/:bizName", dataDictionaryHandlerV1(deps.DataDictionary))
+			metaGroup.GET("/vocabulary/:bizName/:tableName/:fieldName", vocabularyLookupHandlerV1(deps.Vocabularies))
 			metaGroup.GET("/presentations", presentationsHandlerV1(deps.AdminConfigService))
+			metaGroup.GET("/typegen/:bizName", typegenHandler(deps.Typegen))
+			metaGroup.GET("/stats/:bizName/:table", columnStatsHandlerV1(deps.ColumnStats))
+			metaGroup.GET("/query-templates/:bizName", queryTemplatesHandlerV1(deps.QueryTemplates))
+			metaGroup.GET("/locale/:bizName", bizLocaleHandlerV1(deps.AdminConfigService))
+		}
+
+		// --- 当前用户 ---
+		meGroup := v1.Group("/me")
+		meGroup.Use(cachePolicy(cachePolicies.Admin, ""), authMiddleware(authService, deps.RequestSigning), requireUser(), WrapNetHTTP(deps.RateLimiter.LightweightChain))
+		{
+			meGroup.GET("/preferences", listPreferencesHandler(deps.Preferences))
+			meGroup.PUT("/preferences", mergePreferencesHandler(deps.Preferences))
+			meGroup.GET("/preferences/:key", getPreferenceHandler(deps.Preferences))
+			meGroup.PUT("/preferences/:key", setPreferenceHandler(deps.Preferences))
+			meGroup.DELETE("/preferences/:key", deletePreferenceHandler(deps.Preferences))
+			meGroup.GET("/bookmarks", listBookmarksHandler(deps.Bookmarks))
+			meGroup.POST("/bookmarks", createBookmarkHandler(deps.Bookmarks))
+			meGroup.GET("/bookmarks/tags", listBookmarkTagsHandler(deps.Bookmarks))
+			meGroup.GET("/bookmarks/export", exportBookmarksHandler(deps.Bookmarks, deps.Provenance, deps.AdminConfigService))
+			meGroup.PUT("/bookmarks/:id", updateBookmarkHandler(deps.Bookmarks))
+			meGroup.DELETE("/bookmarks/:id", deleteBookmarkHandler(deps.Bookmarks))
+			meGroup.GET("/annotations", listMyAnnotationsHandler(deps.Annotations))
+			meGroup.POST("/annotations", createAnnotationHandler(deps.Annotations))
+			meGroup.DELETE("/annotations/:id", withdrawAnnotationHandler(deps.Annotations))
+			meGroup.GET("/share-links", listShareLinksHandler(deps.ShareLinks))
+			meGroup.POST("/share-links", createShareLinkHandler(deps.ShareLinks))
+			meGroup.DELETE("/share-links/:id", revokeShareLinkHandler(deps.ShareLinks))
 		}
 
 		// --- 数据平面 ---
 		dataGroup := v1.Group("/data")
-		dataGroup.Use(authMiddleware(authService), WrapNetHTTP(deps.RateLimiter.FullBusinessChain))
+		dataGroup.Use(cachePolicy(cachePolicies.Data, cachePolicies.Anonymous))
+		if deps.LoadShedder != nil {
+			// 过载保护先于认证与速率限制，使被拒绝的请求尽量少占用资源
+			dataGroup.Use(WrapNetHTTP(deps.LoadShedder.Middleware))
+		}
+		dataGroup.Use(authMiddleware(authService, deps.RequestSigning), abuseDetection(deps.AbuseDetector), WrapNetHTTP(deps.RateLimiter.FullBusinessChain), captureExchanges(deps.Captures), requireBizEnabled(deps.AdminConfigService), sloTracking(deps.SLO), authorizationHooks(deps.AuthorizationHooks, port.PolicyPlaneData))
+		{
+			queryHandler := queryHandlerV1(deps.Registry, deps.AdminConfigService, deps.QueryDictionaries, deps.UsageAnalytics, deps.SearchSuggestions, deps.FieldGuard, deps.Provenance, deps.QueryCache, deps.MaxInValues, deps.MaxResponseBytes, newQueryCostEstimator(deps.QueryCost, deps.AdminConfigService, deps.ColumnStats), newAdaptivePaging(deps.AdaptivePaging), deps.RecordLinks, deps.AccessLog, deps.IdentifierPolicy, deps.QueryExtensions)
+			dataGroup.POST("/query", queryHandler)
+			dataGroup.POST("/query-templates/:biz/:name", executeQueryTemplateHandlerV1(deps.QueryTemplates, deps.AuthorizationHooks, queryHandler))
+			dataGroup.POST("/query/compile", compileWhereHandler())
+			dataGroup.POST("/timeline", timelineHandlerV1(deps.Registry, deps.AdminConfigService, deps.QueryDictionaries, deps.MaxInValues))
+			dataGroup.POST("/semantic-search", semanticSearchHandlerV1(deps.SemanticSearch))
+			dataGroup.POST("/mutate", mutateHandlerV1(deps.Registry, deps.Vocabularies, deps.RecordLinks))
+			dataGroup.GET("/changes", requireAdmin(), changesHandlerV1(deps.Registry))
+			dataGroup.POST("/diff", requireAdmin(), diffHandlerV1(deps.ResultDiff))
+			dataGroup.GET("/record/:biz/:table/:id", recordHandlerV1(deps.Registry, deps.AdminConfigService, deps.Annotations, deps.RecordLinks, deps.AccessLog, deps.IdentifierPolicy))
+			dataGroup.GET("/record/:biz/:table/:id/jsonld", recordJSONLDHandler(deps.Sitemaps))
+			dataGroup.GET("/tree/:biz/:table/children", treeChildrenHandler(deps.Registry, deps.FieldGuard))
+			dataGroup.GET("/tree/:biz/:table/ancestors", treeAncestorsHandler(deps.Registry, deps.FieldGuard))
+			dataGroup.GET("/tree/:biz/:table/subtree", treeSubtreeHandler(deps.Registry, deps.FieldGuard))
+			dataGroup.GET("/iiif/:biz/:table/:id/manifest", iiifManifestHandler(deps.IIIFService))
+		}
+
+		// --- 业务组配置: 平台管理员可访问全部接口，委派的业务组管理员只能管理自己业务组的字段设置、视图与速率限制 ---
+		bizConfigGroup := v1.Group("/admin/biz-config")
+		bizConfigGroup.Use(cachePolicy(cachePolicies.Admin, ""), authMiddleware(authService, deps.RequestSigning), requireBizAdmin(deps.BizOwners), WrapNetHTTP(deps.RateLimiter.FullBusinessChain), authorizationHooks(deps.AuthorizationHooks, port.PolicyPlaneAdmin))
 		{
-			dataGroup.POST("/query", queryHandlerV1(deps.Registry))
-			dataGroup.POST("/mutate", mutateHandlerV1(deps.Registry))
+			bizConfigGroup.GET("/", adminGetConfiguredBizNamesHandler(deps.AdminConfigService, deps.BizOwners))
+			bizConfigGroup.GET("/:bizName", getBizConfigHandler(deps.AdminConfigService))
+			bizConfigGroup.PUT("/:bizName/settings", requireAdmin(), updateBizOverallSettingsHandler(deps.AdminConfigService, deps.Approvals))
+			bizConfigGroup.PUT("/:bizName/status", requireAdmin(), setBizEnabledHandler(deps.AdminConfigService, deps.CatalogService, deps.Sitemaps))
+			bizConfigGroup.PUT("/:bizName/tables", requireAdmin(), adminUpdateBizSearchableTablesHandler(deps.AdminConfigService))
+			bizConfigGroup.PUT("/:bizName/fields:action", adminBulkUpdateFieldSettingsHandler(deps.AdminConfigService, deps.FieldImpact))
+			bizConfigGroup.POST("/:bizName/apply-template", requireAdmin(), applyConfigTemplateHandler(deps.ConfigTemplates))
+			bizConfigGroup.POST("/:bizName/clone", requireAdmin(), cloneBizConfigHandler(deps.BizClone))
+			bizConfigGroup.POST("/:bizName/rename", requireAdmin(), renameBizHandler(deps.BizRename, deps.QueryCache, deps.CatalogService, deps.Sitemaps))
+			bizConfigGroup.GET("/:bizName/rate-limit", adminGetBizRateLimitHandler(deps.AdminConfigService))
+			bizConfigGroup.PUT("/:bizName/rate-limit", adminUpdateBizRateLimitHandler(deps.AdminConfigService))
+			bizConfigGroup.GET("/:bizName/views", adminGetBizViewsHandler(deps.AdminConfigService))
+			bizConfigGroup.PUT("/:bizName/views", adminUpdateBizViewsHandler(deps.AdminConfigService))
+			bizConfigGroup.POST("/:bizName/views/preview", previewViewHandler(deps.Registry, deps.AdminConfigService, deps.QueryDictionaries, deps.FieldGuard, deps.MaxInValues))
+			bizConfigGroup.GET("/:bizName/dictionary", requireAdmin(), getQueryDictionaryHandler(deps.QueryDictionaries))
+			bizConfigGroup.PUT("/:bizName/dictionary", requireAdmin(), saveQueryDictionaryHandler(deps.QueryDictionaries))
+			bizConfigGroup.DELETE("/:bizName/dictionary", requireAdmin(), deleteQueryDictionaryHandler(deps.QueryDictionaries))
+			bizConfigGroup.GET("/:bizName/dictionary/preview", requireAdmin(), previewQueryDictionaryHandler(deps.QueryDictionaries))
+			bizConfigGroup.GET("/:bizName/query-templates", requireAdmin(), listQueryTemplatesHandler(deps.QueryTemplates))
+			bizConfigGroup.PUT("/:bizName/query-templates/:name", requireAdmin(), saveQueryTemplateHandler(deps.QueryTemplates))
+			bizConfigGroup.DELETE("/:bizName/query-templates/:name", requireAdmin(), deleteQueryTemplateHandler(deps.QueryTemplates))
+			bizConfigGroup.GET("/:bizName/suggestions", requireAdmin(), listSuggestionVocabulariesHandler(deps.SearchSuggestions))
+			bizConfigGroup.GET("/:bizName/semantic", requireAdmin(), listSemanticConfigsHandler(deps.SemanticSearch))
+			bizConfigGroup.GET("/:bizName/owners", requireAdmin(), listBizOwnersHandler(deps.BizOwners))
+			bizConfigGroup.POST("/:bizName/owners", requireAdmin(), grantBizOwnerHandler(deps.BizOwners))
+			bizConfigGroup.DELETE("/:bizName/owners/:userId", requireAdmin(), revokeBizOwnerHandler(deps.BizOwners))
+
+			tableGroup := bizConfigGroup.Group("/:bizName/tables/:tableName")
+			{
+				tableGroup.GET("/fields", adminGetTableFieldSettingsHandler(deps.AdminConfigService))
+				tableGroup.PUT("/fields", adminUpdateTableFieldSettingsHandler(deps.AdminConfigService, deps.FieldImpact))
+				tableGroup.POST("/fields/impact", fieldSettingsImpactHandler(deps.FieldImpact))
+				tableGroup.GET("/data-dictionary", listTableDataDictionaryHandler(deps.DataDictionary))
+				tableGroup.PUT("/data-dictionary/:fieldName", saveFieldDataDictionaryHandler(deps.DataDictionary))
+				tableGroup.DELETE("/data-dictionary/:fieldName", deleteFieldDataDictionaryHandler(deps.DataDictionary))
+				tableGroup.GET("/vocabularies", listTableVocabulariesHandler(deps.Vocabularies))
+				tableGroup.GET("/vocabularies/:fieldName", getFieldVocabularyHandler(deps.Vocabularies))
+				tableGroup.PUT("/vocabularies/:fieldName", saveFieldVocabularyHandler(deps.Vocabularies))
+				tableGroup.DELETE("/vocabularies/:fieldName", deleteFieldVocabularyHandler(deps.Vocabularies))
+				tableGroup.PUT("/permissions", requireAdmin(), adminUpdateTablePermissionsHandler(deps.AdminConfigService))
+				tableGroup.PUT("/fts", requireAdmin(), updateTableFTSConfigHandler(deps.FullTextService))
+				tableGroup.DELETE("/fts", requireAdmin(), deleteTableFTSConfigHandler(deps.FullTextService))
+				tableGroup.POST("/fts/rebuild", requireAdmin(), rebuildTableFTSHandler(deps.FullTextService))
+				tableGroup.PUT("/ranking", requireAdmin(), updateTableRankingConfigHandler(deps.AdminConfigService))
+				tableGroup.DELETE("/ranking", requireAdmin(), deleteTableRankingConfigHandler(deps.AdminConfigService))
+				tableGroup.PUT("/hierarchy", requireAdmin(), updateTableHierarchyConfigHandler(deps.AdminConfigService))
+				tableGroup.DELETE("/hierarchy", requireAdmin(), deleteTableHierarchyConfigHandler(deps.AdminConfigService))
+				tableGroup.PUT("/sql-view", requireAdmin(), updateTableSQLViewConfigHandler(deps.Registry, deps.AdminConfigService))
+				tableGroup.DELETE("/sql-view", requireAdmin(), deleteTableSQLViewConfigHandler(deps.AdminConfigService))
+				tableGroup.POST("/suggestions/rebuild", requireAdmin(), rebuildSuggestionVocabularyHandler(deps.SearchSuggestions))
+				tableGroup.PUT("/semantic", requireAdmin(), updateSemanticConfigHandler(deps.SemanticSearch))
+				tableGroup.DELETE("/semantic", requireAdmin(), deleteSemanticConfigHandler(deps.SemanticSearch))
+				tableGroup.POST("/semantic/rebuild", requireAdmin(), rebuildSemanticIndexHandler(deps.SemanticSearch))
+			}
 		}
 
 		// --- 控制平面 (Admin) ---
 		adminGroup := v1.Group("/admin")
-		adminGroup.Use(authMiddleware(authService), requireAdmin(), WrapNetHTTP(deps.RateLimiter.FullBusinessChain))
+		adminGroup.Use(cachePolicy(cachePolicies.Admin, ""), authMiddleware(authService, deps.RequestSigning), requireAdmin(), WrapNetHTTP(deps.RateLimiter.FullBusinessChain), authorizationHooks(deps.AuthorizationHooks, port.PolicyPlaneAdmin))
 		{
-			adminGroup.GET("/metrics", gin.WrapH(aegobserve.Handler()))
+			adminGroup.GET("/metrics", requireScraper(deps.ScrapeAllowlist), gin.WrapH(aegobserve.Handler()))
+			adminGroup.GET("/observability/log-level", getLogSettingsHandler())
+			adminGroup.PUT("/observability/log-level", updateLogSettingsHandler())
 
 			pluginAdminGroup := adminGroup.Group("/plugins")
 			{
 				pluginAdminGroup.GET("/available", listAvailablePluginsHandler(deps.PluginManager))
-				pluginAdminGroup.POST("/install", installPluginHandler(deps.PluginManager))
+				pluginAdminGroup.POST("/install", requireFreeSpace(deps.Storage), installPluginHandler(deps.PluginManager))
 				pluginAdminGroup.POST("/instances", createInstanceHandler(deps.PluginManager))
 				pluginAdminGroup.GET("/instances", listInstancesHandler(deps.PluginManager))
-				pluginAdminGroup.DELETE("/instances/:instance_id", deleteInstanceHandler(deps.PluginManager))
+				pluginAdminGroup.PATCH("/instances/:instance_id", updateInstanceHandler(deps.PluginManager))
+				pluginAdminGroup.DELETE("/instances/:instance_id", deleteInstanceHandler(deps.PluginManager, deps.Approvals))
 				pluginAdminGroup.POST("/instances/:instance_id/start", startInstanceHandler(deps.PluginManager))
 				pluginAdminGroup.POST("/instances/:instance_id/stop", stopInstanceHandler(deps.PluginManager))
+				pluginAdminGroup.GET("/instances/:instance_id/events", listInstanceEventsHandler(deps.PluginManager))
+				pluginAdminGroup.GET("/instances/:instance_id/crash-dumps", listCrashDumpsHandler(deps.PluginManager))
+				pluginAdminGroup.POST("/instances/:instance_id/crash-dumps", collectCrashDumpHandler(deps.PluginManager))
+				pluginAdminGroup.GET("/instances/:instance_id/crash-dumps/:name", downloadCrashDumpHandler(deps.PluginManager))
+				pluginAdminGroup.PUT("/instances/:instance_id/process-options", updateInstanceProcessOptionsHandler(deps.PluginManager))
+			}
+
+			adminGroup.POST("/cache/purge", purgeQueryCacheHandler(deps.QueryCache))
+			adminGroup.GET("/biz-renames", listBizRenamesHandler(deps.BizRename))
+			adminGroup.GET("/identifier-policy/report", identifierPolicyReportHandler(deps.IdentifierPolicy))
+			inspectGroup := adminGroup.Group("/inspect")
+			{
+				inspectGroup.GET("/config-cache", inspectConfigCacheHandler(deps.AdminConfigService))
+				inspectGroup.GET("/rate-limiters", inspectRateLimitersHandler(deps.RateLimiter))
+				inspectGroup.GET("/load", inspectLoadHandler(deps.LoadShedder, deps.QueryCache))
+			}
+			adminGroup.GET("/annotations", listAnnotationsHandler(deps.Annotations))
+			adminGroup.POST("/annotations/:id/review", reviewAnnotationHandler(deps.Annotations))
+			adminGroup.GET("/annotations/:id/diff", correctionDiffHandler(deps.Annotations))
+			adminGroup.POST("/annotations/:id/apply", applyCorrectionHandler(deps.Annotations))
+			adminGroup.POST("/sitemap/invalidate", invalidateSitemapHandler(deps.Sitemaps))
+			pendingChangesGroup := adminGroup.Group("/pending-changes")
+			{
+				pendingChangesGroup.GET("", listChangeRequestsHandler(deps.Approvals))
+				pendingChangesGroup.GET("/:id", getChangeRequestHandler(deps.Approvals))
+				pendingChangesGroup.POST("/:id/approve", reviewChangeRequestHandler(deps.Approvals, true))
+				pendingChangesGroup.POST("/:id/reject", reviewChangeRequestHandler(deps.Approvals, false))
+			}
+			captureGroup := adminGroup.Group("/capture")
+			{
+				captureGroup.GET("", listCaptureSessionsHandler(deps.Captures))
+				captureGroup.POST("/:bizName", startCaptureHandler(deps.Captures))
+				captureGroup.DELETE("/:bizName", stopCaptureHandler(deps.Captures))
+				captureGroup.GET("/:bizName/entries", listCapturedExchangesHandler(deps.Captures))
+			}
+			digestsGroup := adminGroup.Group("/digests")
+			{
+				digestsGroup.GET("", listDigestsHandler(deps.Digests))
+				digestsGroup.POST("", generateDigestHandler(deps.Digests))
+				digestsGroup.GET("/:day", getDigestHandler(deps.Digests))
+			}
+			dataSubjectsGroup := adminGroup.Group("/data-subjects")
+			{
+				dataSubjectsGroup.POST("/search", searchDataSubjectHandler(deps.DataSubjects))
+				dataSubjectsGroup.POST("/export", noCompression(), exportDataSubjectHandler(deps.DataSubjects))
+				dataSubjectsGroup.POST("/erase", eraseDataSubjectHandler(deps.DataSubjects))
+				dataSubjectsGroup.GET("/requests", listDataSubjectRequestsHandler(deps.DataSubjects))
+			}
+			licensesGroup := adminGroup.Group("/licenses")
+			{
+				licensesGroup.GET("", listLicensesHandler(deps.Licenses))
+				licensesGroup.POST("", putLicenseHandler(deps.Licenses))
+				licensesGroup.DELETE("/:plugin_id", deleteLicenseHandler(deps.Licenses))
+			}
+			adminGroup.GET("/telemetry/preview", telemetryPreviewHandler(deps.Telemetry))
+			updatesGroup := adminGroup.Group("/updates")
+			{
+				updatesGroup.GET("", getUpdatesHandler(deps.Updates))
+				updatesGroup.POST("/check", checkUpdatesHandler(deps.Updates))
+				updatesGroup.POST("/plugins/:plugin_id/stage", stagePluginUpdateHandler(deps.Updates))
+				updatesGroup.POST("/plugins/:plugin_id/apply", requireFreeSpace(deps.Storage), applyPluginUpdateHandler(deps.Updates))
+			}
+			signingKeysGroup := adminGroup.Group("/signing-keys")
+			{
+				signingKeysGroup.GET("", listSigningKeysHandler(deps.RequestSigning))
+				signingKeysGroup.POST("", createSigningKeyHandler(deps.RequestSigning))
+				signingKeysGroup.DELETE("/:id", revokeSigningKeyHandler(deps.RequestSigning))
+			}
+			embedKeysGroup := adminGroup.Group("/embed-keys")
+			{
+				embedKeysGroup.GET("", listEmbedKeysHandler(deps.Embeds))
+				embedKeysGroup.POST("", createEmbedKeyHandler(deps.Embeds))
+				embedKeysGroup.DELETE("/:id", revokeEmbedKeyHandler(deps.Embeds))
+				embedKeysGroup.POST("/:id/sign", signEmbedHandler(deps.Embeds))
+			}
+			exportsGroup := adminGroup.Group("/exports")
+			{
+				exportsGroup.POST("", startExportHandler(deps.Exports))
+				exportsGroup.GET("/:id/download", noCompression(), downloadExportHandler(deps.Exports))
+			}
+			standbyGroup := adminGroup.Group("/standby")
+			{
+				standbyGroup.GET("", standbyStatusHandler(deps.Standby))
+				standbyGroup.GET("/snapshot", noCompression(), standbySnapshotHandler(deps.Standby))
+				standbyGroup.POST("/promote", promoteStandbyHandler(deps.Standby))
+			}
+			jobsGroup := adminGroup.Group("/jobs")
+			{
+				jobsGroup.GET("", listJobsHandler(deps.JobService))
+				jobsGroup.GET("/:id", getJobHandler(deps.JobService))
+				jobsGroup.POST("/:id/cancel", cancelJobHandler(deps.JobService))
+			}
+			analyticsGroup := adminGroup.Group("/analytics")
+			{
+				analyticsGroup.GET("/biz/:bizName", bizUsageReportHandler(deps.UsageAnalytics))
+				analyticsGroup.GET("/zero-results", listZeroResultsHandler(deps.UsageAnalytics))
+				analyticsGroup.PATCH("/zero-results/:id", reviewZeroResultHandler(deps.UsageAnalytics))
+			}
+
+			bizGroup := adminGroup.Group("/biz/:bizName")
+			{
+				bizGroup.POST("/snapshot", noCompression(), createSnapshotHandler(deps.SnapshotService))
+				bizGroup.POST("/restore", requireFreeSpace(deps.Storage), restoreSnapshotHandler(deps.SnapshotService, deps.Fixity, deps.Uploads))
+				bizGroup.POST("/replace", requireFreeSpace(deps.Storage), replaceLibHandler(deps.Registry, deps.Uploads))
+				bizGroup.GET("/tables/:tableName/completeness", completenessReportHandler(deps.Registry))
+				bizGroup.GET("/upstream-cache", upstreamCacheStatsHandler(deps.Registry))
+				bizGroup.DELETE("/upstream-cache", purgeUpstreamCacheHandler(deps.Registry))
+				bizGroup.POST("/pii-scan", runPIIScanHandler(deps.PIIScanner))
+				bizGroup.GET("/pii-scan", getPIIScanHandler(deps.PIIScanner))
+			}
+
+			replicationGroup := adminGroup.Group("/replication")
+			{
+				replicationGroup.GET("", listReplicationTargetsHandler(deps.ReplicationService))
+				replicationGroup.POST("/apply", applyReplicationHandler(deps.Registry))
+				replicationGroup.PUT("/:bizName", saveReplicationTargetHandler(deps.ReplicationService))
+				replicationGroup.POST("/:bizName/verify", verifyReplicaHandler(deps.ReplicaCheck))
+				replicationGroup.DELETE("/:bizName", deleteReplicationTargetHandler(deps.ReplicationService))
+				replicationGroup.POST("/:bizName/sync", syncReplicationTargetHandler(deps.ReplicationService))
+			}
+
+			configTemplatesGroup := adminGroup.Group("/config-templates")
+			{
+				configTemplatesGroup.GET("", listConfigTemplatesHandler(deps.ConfigTemplates))
+				configTemplatesGroup.GET("/:name", getConfigTemplateHandler(deps.ConfigTemplates))
+				configTemplatesGroup.PUT("/:name", saveConfigTemplateHandler(deps.ConfigTemplates))
+				configTemplatesGroup.DELETE("/:name", deleteConfigTemplateHandler(deps.ConfigTemplates))
+			}
+
+			virtualBizGroup := adminGroup.Group("/virtual-biz")
+			{
+				virtualBizGroup.GET("", listVirtualBizHandler(deps.VirtualBizService))
+				virtualBizGroup.PUT("/:bizName", saveVirtualBizHandler(deps.VirtualBizService))
+				virtualBizGroup.DELETE("/:bizName", deleteVirtualBizHandler(deps.VirtualBizService))
 			}
 
-			bizConfigGroup := adminGroup.Group("/biz-config")
+			shardedBizGroup := adminGroup.Group("/sharded-biz")
 			{
-				bizConfigGroup.GET("/", adminGetConfiguredBizNamesHandler(deps.AdminConfigService))
-				bizConfigGroup.GET("/:bizName", getBizConfigHandler(deps.AdminConfigService))
-				bizConfigGroup.PUT("/:bizName/settings", updateBizOverallSettingsHandler(deps.AdminConfigService))
-				bizConfigGroup.PUT("/:bizName/tables", adminUpdateBizSearchableTablesHandler(deps.AdminConfigService))
-				bizConfigGroup.GET("/:bizName/rate-limit", adminGetBizRateLimitHandler(deps.AdminConfigService))
-				bizConfigGroup.PUT("/:bizName/rate-limit", adminUpdateBizRateLimitHandler(deps.AdminConfigService))
-				bizConfigGroup.GET("/:bizName/views", adminGetBizViewsHandler(deps.AdminConfigService))
-				bizConfigGroup.PUT("/:bizName/views", adminUpdateBizViewsHandler(deps.AdminConfigService))
-
-				tableGroup := bizConfigGroup.Group("/:bizName/tables/:tableName")
+				shardedBizGroup.GET("", listShardedBizHandler(deps.ShardedBizService))
+				shardedBizGroup.PUT("/:bizName", saveShardedBizHandler(deps.ShardedBizService))
+				shardedBizGroup.DELETE("/:bizName", deleteShardedBizHandler(deps.ShardedBizService))
+			}
+
+			adminGroup.GET("/warmup", warmupStatusHandler(deps.Warmup))
+			if deps.Compliance != nil {
+				adminGroup.GET("/compliance/export", exportComplianceHandler(deps.Compliance))
+				adminGroup.POST("/compliance/export/files", writeComplianceFilesHandler(deps.Compliance))
+			}
+			if deps.SecurityEvents != nil {
+				adminGroup.GET("/security-events/status", securityEventsStatusHandler(deps.SecurityEvents))
+				adminGroup.POST("/security-events/flush", flushSecurityEventsHandler(deps.SecurityEvents))
+			}
+			if deps.RecordLinks != nil {
+				linksGroup := adminGroup.Group("/links")
+				{
+					linksGroup.POST("", createRecordLinkHandler(deps.RecordLinks))
+					linksGroup.GET("", listRecordLinksHandler(deps.RecordLinks))
+					linksGroup.GET("/:id", getRecordLinkHandler(deps.RecordLinks))
+					linksGroup.DELETE("/:id", deleteRecordLinkHandler(deps.RecordLinks))
+				}
+			}
+			if deps.Uploads != nil {
+				uploadsGroup := adminGroup.Group("/uploads")
 				{
-					tableGroup.PUT("/fields", adminUpdateTableFieldSettingsHandler(deps.AdminConfigService))
-					tableGroup.PUT("/permissions", adminUpdateTablePermissionsHandler(deps.AdminConfigService))
+					uploadsGroup.POST("", requireFreeSpace(deps.Storage), initUploadHandler(deps.Uploads))
+					uploadsGroup.GET("/:id", getUploadHandler(deps.Uploads))
+					uploadsGroup.PUT("/:id", requireFreeSpace(deps.Storage), appendUploadChunkHandler(deps.Uploads))
+					uploadsGroup.POST("/:id/complete", completeUploadHandler(deps.Uploads))
+					uploadsGroup.DELETE("/:id", deleteUploadHandler(deps.Uploads))
 				}
 			}
 
+			readSplitGroup := adminGroup.Group("/read-split-biz")
+			{
+				readSplitGroup.GET("", listReadSplitBizHandler(deps.ReadSplitService))
+				readSplitGroup.GET("/:bizName/status", readSplitBizStatusHandler(deps.ReadSplitService))
+				readSplitGroup.PUT("/:bizName", saveReadSplitBizHandler(deps.ReadSplitService))
+				readSplitGroup.DELETE("/:bizName", deleteReadSplitBizHandler(deps.ReadSplitService))
+			}
+
+			adminGroup.POST("/demo/seed", seedDemoHandler(deps.DemoService))
+
+			adminGroup.GET("/system/doctor", doctorHandler(deps.DoctorService))
+			adminGroup.GET("/system/storage", storageReportHandler(deps.Storage))
+
+			debugGroup := adminGroup.Group("/debug")
+			{
+				debugGroup.GET("/status", getDiagnosticsStatusHandler(deps.DiagnosticsService))
+				debugGroup.PUT("/status", updateDiagnosticsStatusHandler(deps.DiagnosticsService))
+
+				enabledDebugGroup := debugGroup.Group("", requireDiagnostics(deps.DiagnosticsService))
+				enabledDebugGroup.GET("/runtime", runtimeStatsHandler(deps.DiagnosticsService))
+				enabledDebugGroup.GET("/dump/goroutines", goroutineDumpHandler(deps.DiagnosticsService))
+				enabledDebugGroup.GET("/dump/heap", noCompression(), heapDumpHandler(deps.DiagnosticsService))
+				enabledDebugGroup.GET("/pprof/*profile", pprofHandler())
+			}
+
+			oaiAdminGroup := adminGroup.Group("/oai-pmh/mappings")
+			{
+				oaiAdminGroup.GET("", listOAIMappingsHandler(deps.OAIPMHService))
+				oaiAdminGroup.PUT("/:bizName/:tableName", saveOAIMappingHandler(deps.OAIPMHService))
+				oaiAdminGroup.DELETE("/:bizName/:tableName", deleteOAIMappingHandler(deps.OAIPMHService))
+			}
+
+			iiifAdminGroup := adminGroup.Group("/iiif/mappings")
+			{
+				iiifAdminGroup.GET("", listIIIFMappingsHandler(deps.IIIFService))
+				iiifAdminGroup.PUT("/:bizName/:tableName", saveIIIFMappingHandler(deps.IIIFService))
+				iiifAdminGroup.DELETE("/:bizName/:tableName", deleteIIIFMappingHandler(deps.IIIFService))
+			}
+
 			securityGroup := adminGroup.Group("/security")
 			{
 				securityGroup.GET("/rate-limiting/global", adminGetIPLimitSettingsHandler(deps.AdminConfigService))
-				securityGroup.PUT("/rate-limiting/global", adminUpdateIPLimitSettingsHandler(deps.AdminConfigService))
+				securityGroup.PUT("/rate-limiting/global", adminUpdateIPLimitSettingsHandler(deps.AdminConfigService, deps.Approvals))
+				securityGroup.GET("/penalties", listPenaltiesHandler(deps.Penalties))
+				securityGroup.DELETE("/penalties/:kind", liftPenaltyHandler(deps.Penalties))
+				securityGroup.GET("/record-access", listRecordAccessHandler(deps.AccessLog))
+				securityGroup.GET("/users/:id/sessions", listUserSessionsHandler(deps.Sessions))
+				securityGroup.DELETE("/users/:id/sessions", revokeUserSessionsHandler(deps.Sessions))
+				securityGroup.DELETE("/users/:id/sessions/:sessionId", revokeUserSessionHandler(deps.Sessions))
+				securityGroup.GET("/abuse/flags", listAbuseFlagsHandler(deps.AbuseDetector))
+				securityGroup.GET("/abuse/allowlist", listAbuseAllowlistHandler(deps.AbuseDetector))
+				securityGroup.POST("/abuse/allowlist", addAbuseAllowlistHandler(deps.AbuseDetector))
+				securityGroup.DELETE("/abuse/allowlist/:kind", removeAbuseAllowlistHandler(deps.AbuseDetector))
+				securityGroup.GET("/provenance/resolve", resolveProvenanceHandler(deps.Provenance))
+			}
+
+			fixityGroup := adminGroup.Group("/fixity")
+			{
+				fixityGroup.GET("/checksums", listFixityChecksumsHandler(deps.Fixity))
+				fixityGroup.GET("/events", listFixityEventsHandler(deps.Fixity))
+				fixityGroup.POST("/verify", verifyFixityHandler(deps.Fixity))
+			}
+
+			sloGroup := adminGroup.Group("/slo")
+			{
+				sloGroup.GET("", listSLOStatusesHandler(deps.SLO))
+				sloGroup.GET("/:bizName", getSLOStatusHandler(deps.SLO))
+				sloGroup.PUT("/:bizName", putSLOHandler(deps.SLO))
+				sloGroup.DELETE("/:bizName", deleteSLOHandler(deps.SLO))
+			}
+
+			maintenanceGroup := adminGroup.Group("/maintenance")
+			{
+				maintenanceGroup.GET("/runs", listMaintenanceRunsHandler(deps.Maintenance))
+				maintenanceGroup.GET("/:bizName", getMaintenanceStatusHandler(deps.Maintenance))
+				maintenanceGroup.POST("/:bizName/run", runMaintenanceHandler(deps.Maintenance))
+				maintenanceGroup.POST("/:bizName/compact", requireFreeSpace(deps.Storage), compactLibsHandler(deps.Compaction))
 			}
 		}
 	}
@@ -135,24 +716,52 @@ func New(deps Dependencies) http.Handler {
 // Gin 中间件 (Middleware)
 // =============================================================================
 
-// WrapNetHTTP 是一个更简洁、惯用的方式来包装 net/http 中间件给 Gin 使用
+// WrapNetHTTP 是一个更简洁、惯用的方式来包装 net/http 中间件给 Gin 使用。
+// 中间件向下传递的请求 (如附加了上下文值) 会替换 c.Request；中间件未调用下一环 (如限流拒绝) 时中止后续处理器。
 func WrapNetHTTP(middleware func(http.Handler) http.Handler) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		passed := false
 		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed = true
+			c.Request = r
 			c.Next()
 		})
 		handlerToExec := middleware(nextHandler)
 		handlerToExec.ServeHTTP(c.Writer, c.Request)
+		if !passed {
+			c.Abort()
+		}
 	}
 }
 
-func authMiddleware(auth *service.Authenticator) gin.HandlerFunc {
+// authMiddleware 识别 JWT 与 (启用时的) HMAC 签名请求，并把用户信息注入请求上下文。
+// 它位于各路由组的限流链之前，签名校验失败的请求不会消耗限流配额。
+func authMiddleware(auth *service.Authenticator, signer *signing.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		passed := false
 		handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed = true
 			c.Request = r
 			c.Next()
 		}))
+		if signer != nil {
+			handler = signer.Middleware(handler)
+		}
 		handler.ServeHTTP(c.Writer, c.Request)
+		if !passed {
+			c.Abort()
+		}
+	}
+}
+
+// requireUser 要求请求携带有效的用户 Token
+func requireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if service.ClaimFrom(c.Request) == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "需要认证"})
+			return
+		}
+		c.Next()
 	}
 }
 
@@ -177,12 +786,31 @@ func requireAdmin() gin.HandlerFunc {
 
 // --- V1 数据平面处理器 (已更新以适配新协议) ---
 
-// queryHandlerV1 现在处理通用的查询请求
-func queryHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
+// queryHandlerV1 现在处理通用的查询请求。
+// 请求体的 "view" 或 ?view= 指定按某个命名视图查询：未指定 fields_to_return 时只返回视图绑定的字段，
+// 结果中附带视图描述 "view"，并应用视图的投影、默认过滤条件与默认排序。
+// 默认返回原始字段名；?aliased=true 时按字段设置与视图配置将字段重命名为展示名。
+// 请求体携带 projection，或 ?view= 指定的视图配置了投影时，扁平行会被重组为嵌套文档，此时不再应用别名。
+// 管理员可设置 "explain": true，让数据源在结果中附带执行的语句、参数、各库耗时与行数。
+// 启用拼写建议时，结果过少的普通检索会在结果中附带 "suggestions"。
+// 数据源返回的结果先经 fieldGuard 按字段的可返回性过滤，防止插件返回未授权的字段。
+// 启用来源信息时，记录数达到下限的结果会在结果中附带 "provenance"。
+// 查询中的 "search" 是全字段检索词，网关将其展开为表中各可检索文本字段的包含匹配 (见 applySearch)。
+// 普通检索在执行前估算代价 (见 estimateQueryCost)，超过业务组的扫描行数上限时按策略附带 "cost_warning" 或以 422 拒绝，
+// 管理员可设置 CostOverrideHeader 强制执行。
+// 结果的字节数上限可被客户端的 MaxBytesHeader 与 paging 按客户端下载速度估算的上限调低，
+// 因上限被截断的结果附带建议的页大小 "recommended_page_size"。
+// 业务组标记为敏感时，结果中的记录写入记录访问日志，写入失败则不返回结果。
+func queryHandlerV1(registry map[string]port.DataSource, configService port.QueryAdminConfigService, dictionaries *querydict.Service, usage *analytics.Service, suggestions *suggest.Service, fieldGuard *fieldguard.Service, origin *provenance.Service, queryCache *caching.Cache, maxInValues int, maxResponseBytes int64, costs *queryCostEstimator, paging *adaptivePaging, recordLinks *links.Service, accessLog *accesslog.Service, identifiers *identpolicy.Service, extensions *queryext.Service) gin.HandlerFunc {
 	// 请求体现在直接对应我们核心接口中的 port.QueryRequest
 	type RequestBody struct {
-		BizName string                 `json:"biz_name" binding:"required"`
-		Query   map[string]interface{} `json:"query" binding:"required"`
+		BizName    string                 `json:"biz_name" binding:"required"`
+		Query      map[string]interface{} `json:"query" binding:"required"`
+		Projection map[string]string      `json:"projection"`
+		Explain    bool                   `json:"explain"`
+		View       string                 `json:"view"`
+		// Extensions 是下发给插件的专有查询选项，只有业务组白名单中的键才会放行
+		Extensions map[string]interface{} `json:"extensions"`
 	}
 
 	return func(c *gin.Context) {
@@ -191,17 +819,139 @@ func queryHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
 			_ = c.Error(err)
 			return
 		}
+		viewName := reqBody.View
+		if viewName == "" {
+			viewName = c.Query("view")
+		}
 
 		dataSource, exists := registry[reqBody.BizName]
+		if !exists && queryCache != nil && queryCache.ServesStale(reqBody.BizName) {
+			// 插件重启期间业务组暂时从网关注销，仍以缓存中的旧结果回答此前执行过的查询
+			dataSource, exists = queryCache.StaleOnly(reqBody.BizName), true
+		}
 		if !exists {
 			_ = c.Error(port.ErrBizNotFound)
 			return
 		}
 
-		// 直接构建通用的 port.QueryRequest
+		// 变更日志、完整性报告与字段词表只能通过受管理员保护的接口读取，字段统计经 meta 接口缓存后读取
+		if mode, _ := reqBody.Query[port.QueryModeKey].(string); mode == port.QueryModeChanges || mode == port.QueryModeCompleteness ||
+			mode == port.QueryModeVocabulary || mode == port.QueryModeStats {
+			_ = c.Error(port.ErrPermissionDenied)
+			return
+		}
+
+		// explain 会暴露实际执行的查询语句，仅限管理员；保留键不允许由客户端直接传入
+		delete(reqBody.Query, port.QueryExplainKey)
+		delete(reqBody.Query, port.QueryMaxBytesKey)
+		delete(reqBody.Query, port.QueryRankKey)
+		if reqBody.Explain {
+			if claims := service.ClaimFrom(c.Request); claims == nil || claims.Role != "admin" {
+				_ = c.Error(port.ErrPermissionDenied)
+				return
+			}
+			reqBody.Query[port.QueryExplainKey] = true
+		}
+
+		// 投影规格在查询前解析，规格有误时无需访问数据源
+		tableName, _ := reqBody.Query["table"].(string)
+		projectionSpec := reqBody.Projection
+		var view *domain.ViewConfig
+		if viewName != "" {
+			var err error
+			view, err = findNamedView(c.Request.Context(), configService, reqBody.BizName, tableName, viewName)
+			if err != nil {
+				if errors.Is(err, errViewNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+					return
+				}
+				_ = c.Error(err)
+				return
+			}
+			if len(projectionSpec) == 0 {
+				projectionSpec = view.Projection
+			}
+			// 视图的去重设置只是默认值，查询中显式给出的 distinct 优先
+			if _, set := reqBody.Query[port.QueryDistinctKey]; !set && view.Distinct {
+				reqBody.Query[port.QueryDistinctKey] = true
+			}
+		}
+		projection, err := compileProjection(projectionSpec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 嵌套的 where 表达式由网关编译为扁平 filters，数据源无需理解表达式结构；
+		// 按视图查询时，视图的默认过滤条件与编译后的条件以 AND 组合
+		query, err := applyWhere(reqBody.Query)
+		if err == nil && view != nil {
+			query, err = applyViewDefaults(query, view)
+		}
+		if err == nil {
+			err = checkInFilters(query, maxInValues)
+		}
+		if err == nil {
+			err = checkQueryIdentifiers(identifiers, query)
+		}
+		if err == nil {
+			err = extensions.Check(reqBody.BizName, reqBody.Extensions)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 全字段检索词展开为各文本字段的 OR 条件；统计与拼写建议仍按用户输入的原查询计算。
+		// 日期过滤条件按业务组的时区换算，相对日期范围展开为具体日期
+		expanded, err := applySearch(c.Request.Context(), configService, reqBody.BizName, query)
+		if err == nil {
+			expanded, err = applyDates(c.Request.Context(), configService, reqBody.BizName, expanded)
+		}
+		if err != nil {
+			if errors.Is(err, errInvalidSearch) || errors.Is(err, errInvalidFilters) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+
+		// 直接构建通用的 port.QueryRequest；普通检索的过滤值按业务组的检索词典展开
 		queryReq := port.QueryRequest{
-			BizName: reqBody.BizName,
-			Query:   reqBody.Query,
+			BizName:    reqBody.BizName,
+			Query:      expanded,
+			Extensions: reqBody.Extensions,
+		}
+		if mode, _ := reqBody.Query[port.QueryModeKey].(string); dictionaries != nil && mode == "" {
+			queryReq.Query = dictionaries.Expand(reqBody.BizName, expanded)
+		}
+
+		// 匿名访问者每页返回的记录数 (含随机抽样的行数) 受业务组匿名访问层的上限约束
+		if limit := aegmiddleware.AnonymousPageSizeCap(c.Request); limit > 0 {
+			if size, ok := queryReq.Query["size"].(float64); !ok || size > float64(limit) {
+				queryReq.Query["size"] = float64(limit)
+			}
+			if sample, ok := queryReq.Query[port.QuerySampleKey].(float64); ok && sample > float64(limit) {
+				queryReq.Query[port.QuerySampleKey] = float64(limit)
+			}
+		}
+
+		// 结果字节数上限传给数据源，使其在扫描时即停止读取；返回后再按同一上限兜底截断
+		byteLimit := paging.limit(c, responseByteLimit(c.Request.Context(), configService, reqBody.BizName, maxResponseBytes))
+		queryReq.Query[port.QueryMaxBytesKey] = float64(byteLimit)
+
+		var costWarning *queryCost
+		if mode, _ := reqBody.Query[port.QueryModeKey].(string); costs != nil && mode == "" && !costOverridden(c) {
+			var reject bool
+			costWarning, reject = costs.check(c.Request.Context(), reqBody.BizName, tableName, queryReq.Query)
+			if reject {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error": costWarning.message(),
+					"data":  gin.H{"cost": costWarning},
+				})
+				return
+			}
 		}
 
 		result, err := dataSource.Query(c.Request.Context(), queryReq)
@@ -210,13 +960,95 @@ func queryHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
 			_ = c.Error(err)
 			return
 		}
+		if stale, _ := result.Data[port.ResultStaleKey].(bool); stale {
+			c.Header("Warning", `110 - "Response is Stale"`)
+		}
+		if costs != nil {
+			costs.observe(reqBody.BizName, tableName, queryReq.Query, result)
+		}
+		result = withPageSizeHint(capResultBytes(result, byteLimit), byteLimit)
+		if costWarning != nil {
+			data := make(map[string]interface{}, len(result.Data)+1)
+			for k, v := range result.Data {
+				data[k] = v
+			}
+			data["cost_warning"] = costWarning
+			result = &port.QueryResult{Data: data, Source: result.Source}
+		}
+		if fieldGuard != nil {
+			if result, err = fieldGuard.Filter(c.Request.Context(), reqBody.BizName, tableName, result); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		// 关联按主键展开，须在投影与别名改变字段名之前进行
+		if result, err = withRecordLinks(c, recordLinks, reqBody.BizName, tableName, result); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		// 访问日志同样按主键记录，且只记录字段过滤后实际返回的记录
+		if !logRecordAccess(c, accessLog, reqBody.BizName, tableName, result.Data) {
+			return
+		}
+		// 只统计普通检索，按主键读取与 explain 调试不计入
+		if mode, _ := reqBody.Query[port.QueryModeKey].(string); usage != nil && mode == "" && !reqBody.Explain {
+			usage.Record(reqBody.BizName, query, result.Data)
+		}
+		// 建议按用户输入的原值计算，而不是按检索词典展开后的值；结果可能来自缓存，因此复制后再附加
+		if mode, _ := reqBody.Query[port.QueryModeKey].(string); suggestions != nil && mode == "" {
+			if found := suggestions.Suggest(reqBody.BizName, query, result.Data); len(found) > 0 {
+				data := make(map[string]interface{}, len(result.Data)+1)
+				for k, v := range result.Data {
+					data[k] = v
+				}
+				data["suggestions"] = found
+				result = &port.QueryResult{Data: data, Source: result.Source}
+			}
+		}
+
+		// 视图只决定默认返回的字段，客户端显式指定 fields_to_return 或投影时以其为准
+		if _, explicit := reqBody.Query["fields_to_return"]; view != nil && !explicit && len(projection) == 0 {
+			result = restrictResultFields(result, viewBindingFields(view))
+		}
+		if len(projection) > 0 {
+			result = applyProjection(result, projection)
+		} else if c.Query("aliased") == "true" {
+			aliases, err := resolveFieldAliases(c.Request.Context(), configService, reqBody.BizName, tableName, viewName)
+			if err != nil {
+				if errors.Is(err, errViewNotFound) {
+					c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+					return
+				}
+				_ = c.Error(err)
+				return
+			}
+			result = applyFieldAliases(result, aliases)
+		}
+		if items, _ := result.Data["items"].([]interface{}); origin != nil && origin.Large(len(items)) {
+			data := make(map[string]interface{}, len(result.Data)+1)
+			for k, v := range result.Data {
+				data[k] = v
+			}
+			data["provenance"] = origin.For([]string{reqBody.BizName}, requestUserID(c))
+			result = &port.QueryResult{Data: data, Source: result.Source}
+		}
+		if view != nil {
+			data := make(map[string]interface{}, len(result.Data)+1)
+			for k, v := range result.Data {
+				data[k] = v
+			}
+			data["view"] = viewMetadata(view)
+			result = &port.QueryResult{Data: data, Source: result.Source}
+		}
 		// 直接返回插件处理后的通用结果对象
+		writeStarted := time.Now()
 		c.JSON(http.StatusOK, result)
+		paging.observe(c, c.Writer.Size(), time.Since(writeStarted))
 	}
 }
 
 // mutateHandlerV1 现在处理通用的写操作请求
-func mutateHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
+func mutateHandlerV1(registry map[string]port.DataSource, vocabularies *vocabulary.Service, recordLinks *links.Service) gin.HandlerFunc {
 	// 请求体现在直接对应我们核心接口中的 port.MutateRequest
 	type RequestBody struct {
 		BizName   string                 `json:"biz_name" binding:"required"`
@@ -237,13 +1069,44 @@ func mutateHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
 			return
 		}
 
+		claims := service.ClaimFrom(c.Request)
 		slog.Info(
 			"审计日志: 收到 Mutate 请求",
-			"user_id", service.ClaimFrom(c.Request).ID,
+			"user_id", claims.ID,
 			"biz_name", reqBody.BizName,
 			"operation", reqBody.Operation,
 		)
 
+		// 公开写接口只接受受表级写权限约束的 create、update 与 delete；同步变更、库文件维护等管理操作
+		// 经由 port.AdminMutate 在管理接口中发起，不经过这里
+		if !port.IsPublicMutateOp(reqBody.Operation) {
+			_ = c.Error(fmt.Errorf("%w: 不支持的写操作 '%s'", port.ErrPermissionDenied, reqBody.Operation))
+			return
+		}
+
+		// 严格模式字段的值必须来自受控词表
+		if vocabularies != nil && (reqBody.Operation == "create" || reqBody.Operation == "update") {
+			tableName, _ := reqBody.Payload["table_name"].(string)
+			data, _ := reqBody.Payload["data"].(map[string]interface{})
+			if err := vocabularies.ValidateMutate(c.Request.Context(), reqBody.BizName, tableName, data); err != nil {
+				respondVocabularyError(c, err)
+				return
+			}
+		}
+
+		// 仍被关联引用的记录不能删除，需先删除相关的关联
+		if recordLinks != nil && reqBody.Operation == "delete" {
+			tableName, _ := reqBody.Payload["table_name"].(string)
+			filters, _ := reqBody.Payload["filters"].([]interface{})
+			if err := recordLinks.CheckDelete(c.Request.Context(), reqBody.BizName, tableName, filters); err != nil {
+				respondLinkError(c, err)
+				return
+			}
+		}
+
+		// 操作者身份始终由网关注入，覆盖客户端可能传入的同名字段
+		reqBody.Payload[port.MutateActorKey] = strconv.FormatInt(claims.ID, 10)
+
 		// 直接构建通用的 port.MutateRequest
 		mutateReq := port.MutateRequest{
 			BizName:   reqBody.BizName,
@@ -268,19 +1131,26 @@ func mutateHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
 // --- V1 元数据平面处理器 ---
 
 // bizHandlerV1 返回所有已注册的业务组名称
-func bizHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
+func bizHandlerV1(registry map[string]port.DataSource, configService port.QueryAdminConfigService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizNames := make([]string, 0, len(registry))
 		for name := range registry {
-			bizNames = append(bizNames, name)
+			disabled, err := bizDisabled(c.Request.Context(), configService, name)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			if !disabled {
+				bizNames = append(bizNames, name)
+			}
 		}
 		sort.Strings(bizNames)
-		c.JSON(http.StatusOK, gin.H{"data": bizNames})
+		etagJSON(c, gin.H{"data": bizNames})
 	}
 }
 
 // schemaHandlerV1 返回指定业务组的 Schema 信息
-func schemaHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
+func schemaHandlerV1(registry map[string]port.DataSource, dataDict *datadict.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizName := c.Param("bizName")
 		dataSource, exists := registry[bizName]
@@ -294,12 +1164,35 @@ func schemaHandlerV1(registry map[string]port.DataSource) gin.HandlerFunc {
 			_ = c.Error(err)
 			return
 		}
+		if dataDict != nil {
+			if schema, err = dataDict.Annotate(c.Request.Context(), bizName, schema); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+
+		etagJSON(c, gin.H{"data": withRecordKeys(schema)})
+	}
+}
 
-		c.JSON(http.StatusOK, gin.H{"data": schema})
+// withRecordKeys 为每个表补全有效记录标识 (数据源未报告的按 IsPrimary 推断)，
+// 使客户端可以据此构造记录链接。返回副本，不修改可能被数据源缓存的 schema
+func withRecordKeys(schema *port.SchemaResult) *port.SchemaResult {
+	if schema == nil {
+		return nil
 	}
+	out := &port.SchemaResult{Tables: schema.Tables, RecordKeys: make(map[string][]string, len(schema.Tables))}
+	for table := range schema.Tables {
+		key := schema.RecordKey(table)
+		if key == nil {
+			key = []string{}
+		}
+		out.RecordKeys[table] = key
+	}
+	return out
 }
 
-// presentationsHandlerV1 返回指定业务组和表的默认表现层（视图）配置
+// presentationsHandlerV1 返回指定业务组和表的默认表现层（视图）配置，表格列附带解析后的结构化格式 (format_spec)
 func presentationsHandlerV1(configService port.QueryAdminConfigService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizName := c.Query("biz")
@@ -317,7 +1210,7 @@ func presentationsHandlerV1(configService port.QueryAdminConfigService) gin.Hand
 			_ = c.Error(fmt.Errorf("未找到业务 '%s' 表 '%s' 的默认表现层配置", bizName, tableName))
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"data": viewConfig})
+		etagJSON(c, gin.H{"data": withFormatSpecs(viewConfig)})
 	}
 }
 
@@ -326,18 +1219,69 @@ func presentationsHandlerV1(configService port.QueryAdminConfigService) gin.Hand
 // =============================================================================
 
 // statusHandler 返回系统状态，用于前端判断是否需要进入安装流程
-func statusHandler(db *sql.DB) gin.HandlerFunc {
+func statusHandler(db *sql.DB, standbyService *standby.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		resp := gin.H{"status": "needs_setup"}
 		if service.UserCount(db) > 0 {
-			c.JSON(http.StatusOK, gin.H{"status": "ready_for_login"})
-		} else {
-			c.JSON(http.StatusOK, gin.H{"status": "needs_setup"})
+			resp["status"] = "ready_for_login"
+		}
+		// 匿名可访问，负载均衡据此区分主网关与备用网关
+		if standbyService != nil {
+			resp["role"] = standbyService.Status().Role
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// systemInfoHandler 返回网关版本、构建信息、已启用的系统功能与正在运行的插件实例，
+// 供技术支持排查与批量审计部署版本。插件清单会暴露部署细节，因此只对管理员开放
+func systemInfoHandler(build domain.BuildInfo, db *sql.DB, pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		features, err := service.EnabledFeatures(db)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		instances, err := pluginManager.ListInstances()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		info := domain.SystemInfo{BuildInfo: build, EnabledFeatures: features, Plugins: make([]domain.RunningPluginSummary, 0)}
+		for _, inst := range instances {
+			if inst.Status != "RUNNING" {
+				continue
+			}
+			info.Plugins = append(info.Plugins, domain.RunningPluginSummary{
+				InstanceID: inst.InstanceID,
+				PluginID:   inst.PluginID,
+				Version:    inst.Version,
+				BizName:    inst.BizName,
+				Mode:       inst.Mode,
+			})
+		}
+		sort.Slice(info.Plugins, func(i, j int) bool { return info.Plugins[i].InstanceID < info.Plugins[j].InstanceID })
+		c.JSON(http.StatusOK, gin.H{"data": info})
+	}
+}
+
+// bootReportHandler 返回网关的启动报告。报告包含配置与部署细节，因此只对管理员开放
+func bootReportHandler(boot *bootreport.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if boot == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "启动报告不可用"})
+			return
 		}
+		c.JSON(http.StatusOK, gin.H{"data": boot.Report()})
 	}
 }
 
 // loginHandler 处理用户登录请求
-func loginHandler(db *sql.DB) gin.HandlerFunc {
+// loginHandler 校验用户名与密码并签发 Token。同一 IP 对同一账户连续失败达到阈值后锁定一段时间，
+// 锁定期间即使密码正确也返回相同的 401，不向攻击者透露锁定状态；锁定记录在网关重启后仍然有效。
+// 登录成功与失败 (含锁定期间的尝试) 作为安全事件转发到 SIEM。
+// 启用登录会话时每次登录记录一个会话，会话数达到上限且策略为拒绝时返回 409
+func loginHandler(db *sql.DB, lockouts *penalties.Service, userSessions *sessions.Service, events *siem.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
 			User string `form:"user" json:"user" binding:"required"`
@@ -347,17 +1291,57 @@ func loginHandler(db *sql.DB) gin.HandlerFunc {
 			_ = c.Error(err)
 			return
 		}
+		ip := c.ClientIP()
+		if lockouts != nil {
+			if _, locked := lockouts.LoginLocked(ip, req.User); locked {
+				slog.Warn("已锁定的账户再次尝试登录", "user", req.User, "ip", ip)
+				emitLoginEvent(c, events, domain.SecurityEventLoginFailure, nil, req.User, "locked")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码无效"})
+				return
+			}
+		}
 		id, role, ok := service.CheckUser(db, req.User, req.Pass)
 		if !ok {
+			if lockouts != nil {
+				if until, locked, err := lockouts.RecordLoginFailure(c.Request.Context(), ip, req.User); err != nil {
+					slog.Error("记录登录失败次数失败", "user", req.User, "ip", ip, "error", err)
+				} else if locked {
+					slog.Warn("登录失败次数达到阈值，账户已临时锁定", "user", req.User, "ip", ip, "until", until)
+				}
+			}
+			emitLoginEvent(c, events, domain.SecurityEventLoginFailure, nil, req.User, "invalid_credentials")
 			// 对于登录失败，我们直接返回401，不通过错误中间件
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码无效"})
 			return
 		}
-		token, err := service.GenToken(id, role)
+		if lockouts != nil {
+			if err := lockouts.RecordLoginSuccess(c.Request.Context(), ip, req.User); err != nil {
+				slog.Error("清除登录失败次数失败", "user", req.User, "ip", ip, "error", err)
+			}
+		}
+		sessionID := ""
+		if userSessions != nil {
+			session, evicted, err := userSessions.Create(c.Request.Context(), id, ip, c.Request.UserAgent(), time.Now().Add(service.TokenLifetime))
+			if err != nil {
+				if errors.Is(err, sessions.ErrLimitReached) {
+					emitLoginEvent(c, events, domain.SecurityEventLoginFailure, &id, req.User, "session_limit")
+					c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "，请先在其他设备上退出或联系管理员注销会话"})
+					return
+				}
+				_ = c.Error(err)
+				return
+			}
+			if len(evicted) > 0 {
+				slog.Info("会话数达到上限，已注销最早的登录会话", "user", req.User, "evicted", evicted)
+			}
+			sessionID = session.ID
+		}
+		token, err := service.GenSessionToken(id, role, sessionID)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
+		emitLoginEvent(c, events, domain.SecurityEventLoginSuccess, &id, req.User, "")
 		c.JSON(http.StatusOK, gin.H{"token": token, "user": gin.H{"id": id, "username": req.User, "role": role}})
 	}
 }
@@ -413,17 +1397,37 @@ func setupHandler(db *sql.DB, token string, deadline time.Time) gin.HandlerFunc
 //  管理员 API 处理器
 // =============================================================================
 
-func adminGetConfiguredBizNamesHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+// adminGetConfiguredBizNamesHandler 分页列出已配置的业务组，委派的业务组管理员只能看到自己管理的业务组
+func adminGetConfiguredBizNamesHandler(configService port.QueryAdminConfigService, owners *ownership.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
 		names, err := configService.GetAllConfiguredBizNames(c.Request.Context())
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
-		if names == nil {
-			names = []string{}
+		if claims := service.ClaimFrom(c.Request); claims == nil || claims.Role != "admin" {
+			owned, err := owners.OwnedBizs(c.Request.Context(), requestUserID(c))
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			allowed := make(map[string]bool, len(owned))
+			for _, name := range owned {
+				allowed[name] = true
+			}
+			visible := make([]string, 0, len(owned))
+			for _, name := range names {
+				if allowed[name] {
+					visible = append(visible, name)
+				}
+			}
+			names = visible
 		}
-		c.JSON(http.StatusOK, names)
+		respondList(c, names, page)
 	}
 }
 
@@ -442,13 +1446,16 @@ func adminGetIPLimitSettingsHandler(configService port.QueryAdminConfigService)
 	}
 }
 
-func adminUpdateIPLimitSettingsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+func adminUpdateIPLimitSettingsHandler(configService port.QueryAdminConfigService, changes *approvals.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var payload domain.IPLimitSetting
 		if err := c.ShouldBindJSON(&payload); err != nil {
 			_ = c.Error(err)
 			return
 		}
+		if submitForApproval(c, changes, approvals.KindUpdateIPLimit, "global", payload) {
+			return
+		}
 		if err := configService.UpdateIPLimitSettings(c.Request.Context(), payload); err != nil {
 			_ = c.Error(err)
 			return
@@ -469,7 +1476,7 @@ func getBizConfigHandler(configService port.QueryAdminConfigService) gin.Handler
 			_ = c.Error(port.ErrBizNotFound)
 			return
 		}
-		c.JSON(http.StatusOK, cfg)
+		etagJSON(c, cfg)
 	}
 }
 
@@ -489,6 +1496,9 @@ func adminGetBizRateLimitHandler(configService port.QueryAdminConfigService) gin
 	}
 }
 
+// maxAnonymousDelayMs 是匿名访问层可设置的最长响应延迟
+const maxAnonymousDelayMs = 5000
+
 func adminUpdateBizRateLimitHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizName := c.Param("bizName")
@@ -497,6 +1507,17 @@ func adminUpdateBizRateLimitHandler(configService port.QueryAdminConfigService)
 			_ = c.Error(err)
 			return
 		}
+		if anon := payload.Anonymous; anon != nil {
+			if anon.RateLimitPerSecond < 0 || anon.BurstSize < 0 || anon.MaxPageSize < 0 || anon.ResponseDelayMs < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "匿名访问层的配置项不能为负数"})
+				return
+			}
+			// 延迟期间请求一直占用连接，过长的延迟反而会放大爬虫造成的资源占用
+			if anon.ResponseDelayMs > maxAnonymousDelayMs {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("匿名访问层的 response_delay_ms 不能超过 %d", maxAnonymousDelayMs)})
+				return
+			}
+		}
 		if err := configService.UpdateBizRateLimitSettings(c.Request.Context(), bizName, payload); err != nil {
 			_ = c.Error(err)
 			return
@@ -507,16 +1528,12 @@ func adminUpdateBizRateLimitHandler(configService port.QueryAdminConfigService)
 
 func adminGetBizViewsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		bizName := c.Param("bizName")
-		views, err := configService.GetAllViewConfigsForBiz(c.Request.Context(), bizName)
+		views, err := loadBizViews(c.Request.Context(), configService, c.Param("bizName"))
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
-		if views == nil {
-			views = make(map[string][]*domain.ViewConfig)
-		}
-		c.JSON(http.StatusOK, views)
+		etagJSON(c, views)
 	}
 }
 
@@ -528,15 +1545,46 @@ func adminUpdateBizViewsHandler(configService port.QueryAdminConfigService) gin.
 			_ = c.Error(err)
 			return
 		}
+		for tableName, views := range viewsData {
+			for _, view := range views {
+				if view == nil {
+					continue
+				}
+				if err := validateViewConfig(view); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("表 '%s' 的视图 '%s': %v", tableName, view.ViewName, err)})
+					return
+				}
+				if len(view.ConditionalFormats) > 0 {
+					if err := validateViewFields(c.Request.Context(), configService, bizName, tableName, view); err != nil {
+						c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("表 '%s' 的视图 '%s': %v", tableName, view.ViewName, err)})
+						return
+					}
+				}
+			}
+		}
+
+		configWriteMu.Lock()
+		defer configWriteMu.Unlock()
+		current, err := loadBizViews(c.Request.Context(), configService, bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if !checkIfMatch(c, current, func() []configChange { return diffViews(current, viewsData) }) {
+			return
+		}
 		if err := configService.UpdateAllViewsForBiz(c.Request.Context(), bizName, viewsData); err != nil {
 			_ = c.Error(err)
 			return
 		}
+		if updated, err := loadBizViews(c.Request.Context(), configService, bizName); err == nil {
+			setConfigETag(c, updated)
+		}
 		c.JSON(http.StatusOK, gin.H{"status": "success"})
 	}
 }
 
-func updateBizOverallSettingsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+func updateBizOverallSettingsHandler(configService port.QueryAdminConfigService, changes *approvals.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizName := c.Param("bizName")
 		var payload domain.BizOverallSettings
@@ -544,6 +1592,25 @@ func updateBizOverallSettingsHandler(configService port.QueryAdminConfigService)
 			_ = c.Error(err)
 			return
 		}
+		if payload.CitationTemplate != nil {
+			if _, err := parseCitationTemplate(*payload.CitationTemplate); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "引用模板语法错误", "details": err.Error()})
+				return
+			}
+		}
+		if payload.MaxResponseBytes != nil && *payload.MaxResponseBytes < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_response_bytes 不能为负数"})
+			return
+		}
+		if msg := checkBizTimeSettings(payload); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		// 关闭公开检索会让匿名访问者立即失去访问权限，需要另一位管理员确认
+		if payload.IsPubliclySearchable != nil && !*payload.IsPubliclySearchable &&
+			submitForApproval(c, changes, approvals.KindDisablePublicSearch, bizName, payload) {
+			return
+		}
 		if err := configService.UpdateBizOverallSettings(c.Request.Context(), bizName, payload); err != nil {
 			_ = c.Error(err)
 			return
@@ -570,7 +1637,9 @@ func adminUpdateBizSearchableTablesHandler(configService port.QueryAdminConfigSe
 	}
 }
 
-func adminUpdateTableFieldSettingsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+// adminUpdateTableFieldSettingsHandler 全量更新表的字段配置。会导致视图、分享链接或保存的查询失效的修改
+// 返回 409 与影响分析报告，确认后带 ?force=true 重新提交才会应用
+func adminUpdateTableFieldSettingsHandler(configService port.QueryAdminConfigService, fieldImpact *impact.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bizName := c.Param("bizName")
 		tableName := c.Param("tableName")
@@ -579,14 +1648,55 @@ func adminUpdateTableFieldSettingsHandler(configService port.QueryAdminConfigSer
 			_ = c.Error(err)
 			return
 		}
+
+		configWriteMu.Lock()
+		defer configWriteMu.Unlock()
+		current, err := loadTableFields(c.Request.Context(), configService, bizName, tableName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if !checkIfMatch(c, current, func() []configChange { return diffFields(current, payload) }) {
+			return
+		}
+		if fieldImpact != nil {
+			report, err := fieldImpact.AnalyzeFieldSettings(c.Request.Context(), bizName, tableName, payload)
+			if err != nil {
+				respondImpactError(c, err)
+				return
+			}
+			if report.Breaking && c.Query("force") != "true" {
+				c.JSON(http.StatusConflict, gin.H{"error": "该修改会使部分视图、分享链接或保存的查询失效，确认后请带 force=true 重新提交", "data": report})
+				return
+			}
+			if report.Breaking {
+				slog.Warn("审计日志: 强制应用破坏性的字段配置修改", "biz", bizName, "table", tableName, "user_id", requestUserID(c),
+					"views", len(report.Views), "share_links", len(report.ShareLinks), "saved_queries", len(report.SavedQueries))
+			}
+		}
 		if err := configService.UpdateTableFieldSettings(c.Request.Context(), bizName, tableName, payload); err != nil {
 			_ = c.Error(err)
 			return
 		}
+		if updated, err := loadTableFields(c.Request.Context(), configService, bizName, tableName); err == nil {
+			setConfigETag(c, updated)
+		}
 		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "字段配置已更新"})
 	}
 }
 
+// adminGetTableFieldSettingsHandler 返回表的字段配置，响应的 ETag 可作为修改字段配置时的 If-Match
+func adminGetTableFieldSettingsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields, err := loadTableFields(c.Request.Context(), configService, c.Param("bizName"), c.Param("tableName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		etagJSON(c, fields)
+	}
+}
+
 func adminUpdateTablePermissionsHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
 	type permissionsPayload struct {
 		AllowCreate bool `json:"allow_create"`
@@ -616,14 +1726,15 @@ func adminUpdateTablePermissionsHandler(configService port.QueryAdminConfigServi
 	}
 }
 
-// listAvailablePluginsHandler 返回所有可供安装的插件列表。
+// listAvailablePluginsHandler 分页返回可供安装的插件列表。
 func listAvailablePluginsHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		availablePlugins := pluginManager.GetAvailablePlugins()
-		if availablePlugins == nil {
-			availablePlugins = make([]domain.PluginManifest, 0)
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
 		}
-		c.JSON(http.StatusOK, gin.H{"data": availablePlugins})
+		availablePlugins := pluginManager.GetAvailablePlugins()
+		respondList(c, availablePlugins, page)
 	}
 }
 
@@ -647,25 +1758,29 @@ func installPluginHandler(pluginManager *plugin_manager.PluginManager) gin.Handl
 	}
 }
 
-// listInstancesHandler 返回所有已配置的插件实例列表。
+// listInstancesHandler 分页返回已配置的插件实例列表。
 func listInstancesHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
 		instances, err := pluginManager.ListInstances()
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
-		if instances == nil {
-			instances = make([]domain.PluginInstance, 0)
-		}
-		c.JSON(http.StatusOK, gin.H{"data": instances})
+		respondList(c, instances, page)
 	}
 }
 
 // deleteInstanceHandler 删除一个插件实例的配置。
-func deleteInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+func deleteInstanceHandler(pluginManager *plugin_manager.PluginManager, changes *approvals.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		instanceID := c.Param("instance_id")
+		if submitForApproval(c, changes, approvals.KindDeleteInstance, instanceID, nil) {
+			return
+		}
 		if err := pluginManager.DeleteInstance(instanceID); err != nil {
 			_ = c.Error(err)
 			return
@@ -674,11 +1789,21 @@ func deleteInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.Hand
 	}
 }
 
-// startInstanceHandler 启动一个已配置的插件实例。
+// startInstanceHandler 启动一个已配置的插件实例。依赖未就绪或缺少有效授权时返回 409，
+// 带 ?with_dependencies=true 时先按依赖顺序启动尚未运行的依赖。
 func startInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		instanceID := c.Param("instance_id")
-		if err := pluginManager.Start(instanceID); err != nil {
+		start := pluginManager.Start
+		if c.Query("with_dependencies") == "true" {
+			start = pluginManager.StartWithDependencies
+		}
+		if err := start(instanceID); err != nil {
+			if errors.Is(err, plugin_manager.ErrPortUnavailable) || errors.Is(err, plugin_manager.ErrDependencyNotReady) ||
+				errors.Is(err, plugin_manager.ErrLicenseRequired) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
 			_ = c.Error(fmt.Errorf("启动插件实例 '%s' 失败: %w", instanceID, err))
 			return
 		}
@@ -698,13 +1823,133 @@ func stopInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.Handle
 	}
 }
 
+// listInstanceEventsHandler 按时间倒序返回插件实例的生命周期事件，可用 ?limit= 限制条数。
+func listInstanceEventsHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instanceID := c.Param("instance_id")
+		limit := 0
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit 必须是正整数"})
+				return
+			}
+			limit = n
+		}
+		events, err := pluginManager.ListInstanceEvents(instanceID, limit)
+		if err != nil {
+			if errors.Is(err, plugin_manager.ErrInstanceNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": events})
+	}
+}
+
+// listCrashDumpsHandler 按时间倒序列出插件实例的诊断包
+func listCrashDumpsHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dumps, err := pluginManager.ListCrashDumps(c.Param("instance_id"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": dumps})
+	}
+}
+
+// collectCrashDumpHandler 立即为插件实例生成一个诊断包，实例无需处于崩溃状态
+func collectCrashDumpHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dump, err := pluginManager.CollectCrashDump(c.Param("instance_id"), domain.CrashDumpTriggerManual)
+		if err != nil {
+			if errors.Is(err, plugin_manager.ErrInstanceNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": dump})
+	}
+}
+
+// downloadCrashDumpHandler 以附件形式下载插件实例的一个诊断包 (zip)
+func downloadCrashDumpHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		path, err := pluginManager.CrashDumpPath(c.Param("instance_id"), name)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.FileAttachment(path, name)
+	}
+}
+
+// updateInstanceHandler 修改插件实例的展示名、绑定的业务组与端口。
+// 业务组与端口只能在实例停止时修改，在下次启动时生效。
+func updateInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instanceID := c.Param("instance_id")
+		var upd plugin_manager.InstanceUpdate
+		if err := c.ShouldBindJSON(&upd); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if err := pluginManager.UpdateInstance(instanceID, upd); err != nil {
+			switch {
+			case errors.Is(err, plugin_manager.ErrInstanceNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, plugin_manager.ErrInvalidInstanceUpdate), errors.Is(err, plugin_manager.ErrInvalidDependencies):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, plugin_manager.ErrInstanceRunning), errors.Is(err, plugin_manager.ErrInstanceConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("插件实例 '%s' 已更新。", instanceID)})
+	}
+}
+
+// updateInstanceProcessOptionsHandler 修改插件实例的进程选项，在实例下次启动时生效。
+func updateInstanceProcessOptionsHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instanceID := c.Param("instance_id")
+		var opts domain.PluginProcessOptions
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if err := pluginManager.UpdateProcessOptions(instanceID, opts); err != nil {
+			if errors.Is(err, plugin_manager.ErrInvalidProcessOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("插件实例 '%s' 的进程选项已更新，将在下次启动时生效。", instanceID)})
+	}
+}
+
 // createInstanceHandler 创建一个新的插件实例配置。
 func createInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.HandlerFunc {
 	type createPayload struct {
 		DisplayName string `json:"display_name" binding:"required"`
 		PluginID    string `json:"plugin_id" binding:"required"`
-		Version     string `json:"version" binding:"required"`
+		Version     string `json:"version"` // 嵌入式模式下忽略
 		BizName     string `json:"biz_name" binding:"required"`
+		Mode        string `json:"mode"` // process（默认）或 embedded
+		// ProcessOptions 控制插件进程的环境变量、工作目录与 umask，仅独立进程模式可用
+		ProcessOptions domain.PluginProcessOptions `json:"process_options"`
+		// DependsOn 是启动前必须就绪的其它实例ID
+		DependsOn []string `json:"depends_on"`
 	}
 	return func(c *gin.Context) {
 		var payload createPayload
@@ -712,8 +1957,12 @@ func createInstanceHandler(pluginManager *plugin_manager.PluginManager) gin.Hand
 			_ = c.Error(err)
 			return
 		}
-		instanceID, err := pluginManager.CreateInstance(payload.DisplayName, payload.PluginID, payload.Version, payload.BizName)
+		instanceID, err := pluginManager.CreateInstance(payload.DisplayName, payload.PluginID, payload.Version, payload.BizName, payload.Mode, payload.ProcessOptions, payload.DependsOn)
 		if err != nil {
+			if errors.Is(err, plugin_manager.ErrInvalidProcessOptions) || errors.Is(err, plugin_manager.ErrInvalidDependencies) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			_ = c.Error(err)
 			return
 		}
@@ -0,0 +1,148 @@
+// Package router file: internal/transport/http/router/search_everywhere.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// errInvalidSearch 表示全字段检索请求不合法
+var errInvalidSearch = errors.New("无效的全字段检索")
+
+const (
+	// querySearchKey 是全字段检索词的键：一个检索词匹配表中所有可检索的文本字段，由网关展开为 filters
+	querySearchKey = "search"
+	// searchMaxLength 是检索词的最大长度 (字符数)
+	searchMaxLength = 200
+)
+
+// searchField 是参与全字段检索的字段及其权重
+type searchField struct {
+	name   string
+	weight float64
+}
+
+// applySearch 把查询中的全字段检索词展开为 filters，原查询不被修改。查询不含 search 时原样返回。
+// 检索词以包含匹配与表中每个可检索的文本字段比较，字段之间为 OR，与已有的 filters 为 AND；
+// 由于扁平 filters 只能表达析取范式，已有 filters 的每个子句都与每个字段组合，子句数受 whereMaxTerms 限制。
+// 各字段的 SearchWeight 作为 port.QueryRankKey 传给数据源用于计分；未指定 port.QuerySortKey 时按得分排序
+func applySearch(ctx context.Context, configService port.QueryAdminConfigService, bizName string, query map[string]interface{}) (map[string]interface{}, error) {
+	raw, ok := query[querySearchKey]
+	if !ok {
+		return query, nil
+	}
+	term, ok := raw.(string)
+	if term = strings.TrimSpace(term); !ok || term == "" {
+		return nil, fmt.Errorf("%w: '%s' 必须是非空字符串", errInvalidSearch, querySearchKey)
+	}
+	if len([]rune(term)) > searchMaxLength {
+		return nil, fmt.Errorf("%w: 检索词不能超过 %d 个字符", errInvalidSearch, searchMaxLength)
+	}
+	if mode, _ := query[port.QueryModeKey].(string); mode != "" {
+		return nil, fmt.Errorf("%w: 不能与查询模式 '%s' 同时使用", errInvalidSearch, mode)
+	}
+
+	fields, err := searchFields(ctx, configService, bizName, query)
+	if err != nil {
+		return nil, err
+	}
+	clauses, err := filterClauses(query["filters"])
+	if err != nil {
+		return nil, err
+	}
+	if len(clauses)*len(fields) > whereMaxTerms {
+		return nil, fmt.Errorf("%w: 与已有条件组合后超过 %d 个子句，请减少 OR 条件", errInvalidSearch, whereMaxTerms)
+	}
+
+	filters := make([]interface{}, 0, len(clauses)*len(fields))
+	rank := make([]interface{}, 0, len(fields))
+	for i, clause := range clauses {
+		for j, field := range fields {
+			for _, leaf := range clause {
+				filter := make(map[string]interface{}, len(leaf)+1)
+				for k, v := range leaf {
+					filter[k] = v
+				}
+				filter["logic"] = "AND"
+				filters = append(filters, filter)
+			}
+			filter := map[string]interface{}{"field": field.name, "value": term, "fuzzy": true}
+			if i < len(clauses)-1 || j < len(fields)-1 {
+				filter["logic"] = "OR"
+			}
+			filters = append(filters, filter)
+		}
+	}
+	for _, field := range fields {
+		rank = append(rank, map[string]interface{}{"field": field.name, "value": term, "weight": field.weight})
+	}
+
+	expanded := make(map[string]interface{}, len(query)+1)
+	for k, v := range query {
+		if k != querySearchKey {
+			expanded[k] = v
+		}
+	}
+	expanded["filters"] = filters
+	expanded[port.QueryRankKey] = rank
+	if _, ok := expanded[port.QuerySortKey]; !ok {
+		expanded[port.QuerySortKey] = port.ResultScoreField
+	}
+	return expanded, nil
+}
+
+// searchFields 返回目标表中参与全字段检索的字段：可检索、非数值类型且权重不为负数，按权重从高到低、名称排序
+func searchFields(ctx context.Context, configService port.QueryAdminConfigService, bizName string, query map[string]interface{}) ([]searchField, error) {
+	cfg, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, port.ErrBizNotFound
+	}
+	tableName, _ := query["table"].(string)
+	if tableName == "" {
+		tableName = cfg.DefaultQueryTable
+	}
+	table, ok := cfg.Tables[tableName]
+	if !ok {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	fields := make([]searchField, 0, len(table.Fields))
+	for name, setting := range table.Fields {
+		if !setting.IsSearchable || setting.SearchWeight < 0 || isNumericDataType(setting.DataType) {
+			continue
+		}
+		weight := setting.SearchWeight
+		if weight == 0 {
+			weight = 1
+		}
+		fields = append(fields, searchField{name: name, weight: weight})
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: 表 '%s' 没有可用于全字段检索的文本字段", errInvalidSearch, tableName)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].weight != fields[j].weight {
+			return fields[i].weight > fields[j].weight
+		}
+		return fields[i].name < fields[j].name
+	})
+	return fields, nil
+}
+
+// isNumericDataType 按 SQLite 的类型亲和规则判断字段是否为数值类型，数值字段不参与文本包含匹配
+func isNumericDataType(dataType string) bool {
+	t := strings.ToUpper(dataType)
+	for _, marker := range []string{"INT", "REAL", "FLOA", "DOUB", "NUM", "DEC"} {
+		if strings.Contains(t, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// file: internal/transport/http/router/search_everywhere_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchConfigStub 只实现 applySearch 用到的 GetBizQueryConfig
+type searchConfigStub struct {
+	port.QueryAdminConfigService
+	cfg *domain.BizQueryConfig
+}
+
+func (s searchConfigStub) GetBizQueryConfig(context.Context, string) (*domain.BizQueryConfig, error) {
+	return s.cfg, nil
+}
+
+func newSearchConfigStub() searchConfigStub {
+	return searchConfigStub{cfg: &domain.BizQueryConfig{
+		BizName:           "archive",
+		DefaultQueryTable: "letters",
+		Tables: map[string]*domain.TableConfig{
+			"letters": {TableName: "letters", IsSearchable: true, Fields: map[string]domain.FieldSetting{
+				"id":       {FieldName: "id", IsSearchable: true, DataType: "INTEGER"},
+				"title":    {FieldName: "title", IsSearchable: true, DataType: "string", SearchWeight: 3},
+				"body":     {FieldName: "body", IsSearchable: true, DataType: "TEXT"},
+				"internal": {FieldName: "internal", IsSearchable: true, DataType: "string", SearchWeight: -1},
+				"returned": {FieldName: "returned", IsReturnable: true, DataType: "string"},
+			}},
+			"empty": {TableName: "empty", IsSearchable: true, Fields: map[string]domain.FieldSetting{}},
+		},
+	}}
+}
+
+func TestApplySearch(t *testing.T) {
+	ctx := context.Background()
+	config := newSearchConfigStub()
+
+	query := map[string]interface{}{"search": " 鲁迅 "}
+	expanded, err := applySearch(ctx, config, "archive", query)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "title", "value": "鲁迅", "fuzzy": true, "logic": "OR"},
+		map[string]interface{}{"field": "body", "value": "鲁迅", "fuzzy": true},
+	}, expanded["filters"], "只展开可检索的文本字段，按权重排序，负权重的字段不参与")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "title", "value": "鲁迅", "weight": float64(3)},
+		map[string]interface{}{"field": "body", "value": "鲁迅", "weight": float64(1)},
+	}, expanded[port.QueryRankKey])
+	assert.Equal(t, port.ResultScoreField, expanded[port.QuerySortKey], "未指定排序时默认按得分排序")
+	assert.NotContains(t, expanded, "search")
+	assert.Contains(t, query, "search", "原查询不应被修改")
+
+	// (place=北京 OR place=上海) AND (title OR body) 展开为四个子句
+	expanded, err = applySearch(ctx, config, "archive", map[string]interface{}{
+		"search": "鲁迅",
+		"sort":   "",
+		"filters": []interface{}{
+			map[string]interface{}{"field": "place", "value": "北京", "logic": "or"},
+			map[string]interface{}{"field": "place", "value": "上海"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", expanded[port.QuerySortKey], "显式指定的排序不被覆盖")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "place", "value": "北京", "logic": "AND"},
+		map[string]interface{}{"field": "title", "value": "鲁迅", "fuzzy": true, "logic": "OR"},
+		map[string]interface{}{"field": "place", "value": "北京", "logic": "AND"},
+		map[string]interface{}{"field": "body", "value": "鲁迅", "fuzzy": true, "logic": "OR"},
+		map[string]interface{}{"field": "place", "value": "上海", "logic": "AND"},
+		map[string]interface{}{"field": "title", "value": "鲁迅", "fuzzy": true, "logic": "OR"},
+		map[string]interface{}{"field": "place", "value": "上海", "logic": "AND"},
+		map[string]interface{}{"field": "body", "value": "鲁迅", "fuzzy": true},
+	}, expanded["filters"])
+
+	unchanged := map[string]interface{}{"table": "letters"}
+	expanded, err = applySearch(ctx, config, "archive", unchanged)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, expanded)
+}
+
+func TestApplySearch_Validation(t *testing.T) {
+	ctx := context.Background()
+	config := newSearchConfigStub()
+
+	for name, query := range map[string]map[string]interface{}{
+		"空检索词":    {"search": "  "},
+		"非字符串":    {"search": float64(1)},
+		"与查询模式同用": {"search": "鲁迅", port.QueryModeKey: port.QueryModeRecord},
+		"没有文本字段":  {"search": "鲁迅", "table": "empty"},
+	} {
+		_, err := applySearch(ctx, config, "archive", query)
+		assert.ErrorIs(t, err, errInvalidSearch, name)
+	}
+
+	_, err := applySearch(ctx, config, "archive", map[string]interface{}{"search": "鲁迅", "table": "missing"})
+	assert.ErrorIs(t, err, port.ErrTableNotFoundInBiz)
+}
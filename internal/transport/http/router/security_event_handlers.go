@@ -0,0 +1,80 @@
+// Package router file: internal/transport/http/router/security_event_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/siem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emitLoginEvent 转发一次登录尝试，reason 是失败原因，成功时为空
+func emitLoginEvent(c *gin.Context, events *siem.Service, eventType string, userID *int64, username, reason string) {
+	if events == nil {
+		return
+	}
+	event := domain.SecurityEvent{Type: eventType, UserID: userID, Username: username, IP: c.ClientIP()}
+	if reason != "" {
+		event.Detail = map[string]interface{}{"reason": reason}
+	}
+	events.Emit(c.Request.Context(), event)
+}
+
+// adminChangeEvents 在管理员的写请求 (非 GET/HEAD/OPTIONS) 处理完成后转发一条管理变更事件，
+// 包含路由模板、实际路径与响应状态码。请求体不会被转发，避免把配置中的密钥发给 SIEM
+func adminChangeEvents(events *siem.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		// 认证中间件在路由组内运行，处理完成后 c.Request 中才带有用户信息
+		claims := service.ClaimFrom(c.Request)
+		if claims == nil || claims.Role != "admin" {
+			return
+		}
+		id := claims.ID
+		events.Emit(c.Request.Context(), domain.SecurityEvent{
+			Type:    domain.SecurityEventAdminChange,
+			UserID:  &id,
+			IP:      c.ClientIP(),
+			Subject: c.Request.URL.Path,
+			Detail: map[string]interface{}{
+				"method": c.Request.Method,
+				"route":  c.FullPath(),
+				"status": c.Writer.Status(),
+			},
+		})
+	}
+}
+
+// securityEventsStatusHandler 返回各 SIEM 接收端的待发送事件数与最近一次投递的结果
+func securityEventsStatusHandler(events *siem.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := events.Status(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": status})
+	}
+}
+
+// flushSecurityEventsHandler 立即投递队列中的安全事件，处于重试退避期的接收端被跳过
+func flushSecurityEventsHandler(events *siem.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !events.Enabled() {
+			c.JSON(http.StatusConflict, gin.H{"error": "未配置 SIEM 接收端"})
+			return
+		}
+		delivered, err := events.Flush(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"delivered": delivered}})
+	}
+}
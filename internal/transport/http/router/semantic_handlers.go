@@ -0,0 +1,145 @@
+// Package router file: internal/transport/http/router/semantic_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/internal/service/semantic"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// semanticSearchRequest 是语义检索的请求体，table 为空时使用业务组的默认查询表
+type semanticSearchRequest struct {
+	BizName  string  `json:"biz_name" binding:"required"`
+	Table    string  `json:"table"`
+	Text     string  `json:"text" binding:"required"`
+	TopK     int     `json:"top_k"`
+	MinScore float64 `json:"min_score"`
+}
+
+// semanticSearchHandlerV1 按描述字段的语义相似度检索记录，结果按相似度降序排列并附带 "_score"
+func semanticSearchHandlerV1(semanticSearch *semantic.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if semanticSearch == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "语义检索未启用"})
+			return
+		}
+		var req semanticSearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "请求体格式错误: " + err.Error()})
+			return
+		}
+		data, err := semanticSearch.Search(c.Request.Context(), req.BizName, req.Table, req.Text, req.TopK, req.MinScore)
+		if err != nil {
+			switch {
+			case errors.Is(err, semantic.ErrInvalidQuery), errors.Is(err, port.ErrVectorSearchUnsupported):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, semantic.ErrNotConfigured):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, semantic.ErrIndexOutdated):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				slog.Error("semanticSearchHandlerV1 执行失败", "biz", req.BizName, "table", req.Table, "error", err)
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": data})
+	}
+}
+
+// updateSemanticConfigHandler 保存表参与语义检索的描述字段，并在后台重建向量索引
+func updateSemanticConfigHandler(semanticSearch *semantic.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if semanticSearch == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "语义检索未启用"})
+			return
+		}
+		var body struct {
+			Fields []string `json:"fields"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		saved, job, err := semanticSearch.SaveConfig(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), body.Fields, requestUserID(c))
+		if err != nil {
+			if errors.Is(err, semantic.ErrInvalidConfig) || errors.Is(err, port.ErrVectorSearchUnsupported) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"config": saved, "job": job}})
+	}
+}
+
+// deleteSemanticConfigHandler 删除表的语义检索配置及已保存的向量
+func deleteSemanticConfigHandler(semanticSearch *semantic.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if semanticSearch == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "语义检索未启用"})
+			return
+		}
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		if err := semanticSearch.DeleteConfig(c.Request.Context(), bizName, tableName); err != nil {
+			switch {
+			case errors.Is(err, semantic.ErrNotConfigured):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, port.ErrVectorSearchUnsupported):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("表 '%s/%s' 的语义检索配置已删除", bizName, tableName)})
+	}
+}
+
+// rebuildSemanticIndexHandler 按当前配置在后台重建表的向量索引，可通过 /admin/jobs/:id 查询进度
+func rebuildSemanticIndexHandler(semanticSearch *semantic.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if semanticSearch == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "语义检索未启用"})
+			return
+		}
+		job, err := semanticSearch.Rebuild(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), requestUserID(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, jobs.ErrJobConflict):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, semantic.ErrNotConfigured):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, port.ErrVectorSearchUnsupported):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				_ = c.Error(err)
+			}
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": job})
+	}
+}
+
+// listSemanticConfigsHandler 列出业务组下各表的语义检索配置、索引模型与建立时间
+func listSemanticConfigsHandler(semanticSearch *semantic.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if semanticSearch == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "语义检索未启用"})
+			return
+		}
+		configs, err := semanticSearch.ListConfigs(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"model": semanticSearch.Model(), "tables": configs}})
+	}
+}
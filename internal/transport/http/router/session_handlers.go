@@ -0,0 +1,88 @@
+// Package router file: internal/transport/http/router/session_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/sessions"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionUserID 解析路径中的用户 ID，失败时已写入 400 响应
+func sessionUserID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户 ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// listUserSessionsHandler 列出账户当前登录的设备与 IP，?include_revoked=true 时同时列出已注销但 Token 尚未过期的会话
+func listUserSessionsHandler(userSessions *sessions.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userSessions == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "登录会话管理未启用"})
+			return
+		}
+		userID, ok := sessionUserID(c)
+		if !ok {
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		list, err := userSessions.List(c.Request.Context(), userID, c.Query("include_revoked") == "true")
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, list, page)
+	}
+}
+
+// revokeUserSessionHandler 注销账户的一个会话，该会话的 Token 立即失效
+func revokeUserSessionHandler(userSessions *sessions.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userSessions == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "登录会话管理未启用"})
+			return
+		}
+		userID, ok := sessionUserID(c)
+		if !ok {
+			return
+		}
+		if err := userSessions.Revoke(c.Request.Context(), userID, c.Param("sessionId")); err != nil {
+			if errors.Is(err, sessions.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "已注销登录会话"})
+	}
+}
+
+// revokeUserSessionsHandler 注销账户的全部会话，使其在所有设备上退出登录
+func revokeUserSessionsHandler(userSessions *sessions.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userSessions == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "登录会话管理未启用"})
+			return
+		}
+		userID, ok := sessionUserID(c)
+		if !ok {
+			return
+		}
+		revoked, err := userSessions.RevokeAll(c.Request.Context(), userID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"revoked": revoked}})
+	}
+}
@@ -0,0 +1,74 @@
+// Package router file: internal/transport/http/router/sharded_biz_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/sharding"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listShardedBizHandler 列出所有分片业务组定义
+func listShardedBizHandler(shardedBizService *sharding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		defs, err := shardedBizService.List(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, defs, page)
+	}
+}
+
+// saveShardedBizHandler 创建或更新一个分片业务组，保存后立即可被查询
+func saveShardedBizHandler(shardedBizService *sharding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Description string         `json:"description"`
+			ShardKey    string         `json:"shard_key" binding:"required"`
+			Shards      []domain.Shard `json:"shards" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		def := domain.ShardedBiz{
+			BizName:     c.Param("bizName"),
+			Description: payload.Description,
+			ShardKey:    payload.ShardKey,
+			Shards:      payload.Shards,
+		}
+		if err := shardedBizService.Save(c.Request.Context(), def); err != nil {
+			if errors.Is(err, sharding.ErrInvalidDefinition) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("分片业务组 '%s' 已保存并生效", def.BizName)})
+	}
+}
+
+// deleteShardedBizHandler 删除一个分片业务组，各分片业务组不受影响
+func deleteShardedBizHandler(shardedBizService *sharding.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		if err := shardedBizService.Delete(c.Request.Context(), bizName); err != nil {
+			if errors.Is(err, sharding.ErrShardedBizNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("分片业务组 '%s' 已删除", bizName)})
+	}
+}
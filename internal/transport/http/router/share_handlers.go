@@ -0,0 +1,111 @@
+// Package router file: internal/transport/http/router/share_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/sharelinks"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondShareError 把分享链接的错误映射为 400/404/429，其余交给错误中间件
+func respondShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sharelinks.ErrInvalidShareLink), errors.Is(err, sharelinks.ErrQuotaExceeded), errors.Is(err, port.ErrTableNotFoundInBiz):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, sharelinks.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, sharelinks.ErrRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// createShareLinkHandler 把一次查询冻结为分享链接，令牌与链接地址只在此时返回
+func createShareLinkHandler(shares *sharelinks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req sharelinks.CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		created, err := shares.Create(c.Request.Context(), requestUserID(c), req)
+		if err != nil {
+			respondShareError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": gin.H{
+			"link":  created.Link,
+			"token": created.Token,
+			"url":   requestBaseURL(c) + "/api/v1/share/" + created.Token,
+		}})
+	}
+}
+
+// listShareLinksHandler 列出当前用户创建的分享链接，不含令牌
+func listShareLinksHandler(shares *sharelinks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		links, err := shares.List(c.Request.Context(), requestUserID(c))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, links, page)
+	}
+}
+
+// revokeShareLinkHandler 撤销当前用户的一条分享链接
+func revokeShareLinkHandler(shares *sharelinks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分享链接 ID"})
+			return
+		}
+		if err := shares.Revoke(c.Request.Context(), requestUserID(c), id); err != nil {
+			respondShareError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "分享链接已撤销"})
+	}
+}
+
+// openShareLinkHandler 供持有链接的任何人只读翻阅分享的查询结果，只接受 ?page= 参数
+func openShareLinkHandler(shares *sharelinks.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page := 1
+		if raw := c.Query("page"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "page 必须是正整数"})
+				return
+			}
+			page = n
+		}
+		link, result, err := shares.Open(c.Request.Context(), c.Param("token"), page)
+		if err != nil {
+			respondShareError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": result.Data,
+			"share": gin.H{
+				"label":      link.Label,
+				"biz_name":   link.BizName,
+				"table":      link.Query["table"],
+				"page":       page,
+				"size":       link.Query["size"],
+				"expires_at": link.ExpiresAt,
+			},
+			"source": result.Source,
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package router file: internal/transport/http/router/signing_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/signing"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondSigningError 把签名密钥的错误映射为 400/404，其余交给错误中间件
+func respondSigningError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, signing.ErrInvalidKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, signing.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// listSigningKeysHandler 列出全部请求签名密钥，不含签名密钥本身
+func listSigningKeysHandler(signer *signing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "请求签名未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		keys, err := signer.ListKeys(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, keys, page)
+	}
+}
+
+// createSigningKeyHandler 为用户 (通常是服务账户) 签发请求签名密钥，签名密钥只在响应中出现这一次
+func createSigningKeyHandler(signer *signing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "请求签名未启用"})
+			return
+		}
+		var body struct {
+			UserID int64  `json:"user_id" binding:"required"`
+			Label  string `json:"label"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		key, secret, err := signer.CreateKey(c.Request.Context(), body.UserID, body.Label)
+		if err != nil {
+			respondSigningError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": gin.H{"key": key, "secret": secret}})
+	}
+}
+
+// revokeSigningKeyHandler 撤销请求签名密钥
+func revokeSigningKeyHandler(signer *signing.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "请求签名未启用"})
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的签名密钥 ID"})
+			return
+		}
+		if err := signer.RevokeKey(c.Request.Context(), id); err != nil {
+			respondSigningError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "签名密钥已撤销"})
+	}
+}
@@ -0,0 +1,104 @@
+// Package router file: internal/transport/http/router/sitemap_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/sitemap"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondSitemapError 把站点地图的错误映射为 404，其余交给错误中间件
+func respondSitemapError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sitemap.ErrNotFound), errors.Is(err, port.ErrTableNotFoundInBiz):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// sitemapIndexHandler 返回全部公开记录的站点地图索引
+func sitemapIndexHandler(sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sitemaps == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "站点地图未启用"})
+			return
+		}
+		body, err := sitemaps.IndexXML(c.Request.Context(), sitemaps.BaseURL(requestBaseURL(c)))
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+	}
+}
+
+// sitemapPageHandler 返回一张表某一页记录的站点地图，路径形如 /sitemaps/:biz/:table/3.xml
+func sitemapPageHandler(sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sitemaps == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "站点地图未启用"})
+			return
+		}
+		page, err := sitemap.ParseSitemapFile(c.Param("file"))
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		body, err := sitemaps.SitemapXML(c.Request.Context(), sitemaps.BaseURL(requestBaseURL(c)), c.Param("biz"), c.Param("table"), page)
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+	}
+}
+
+// robotsHandler 在 robots.txt 中声明站点地图的位置
+func robotsHandler(sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sitemaps == nil {
+			c.String(http.StatusOK, "User-agent: *\nDisallow: /api/v1/admin/\n")
+			return
+		}
+		c.String(http.StatusOK, "User-agent: *\nDisallow: /api/v1/admin/\n\nSitemap: %s/sitemap.xml\n", sitemaps.BaseURL(requestBaseURL(c)))
+	}
+}
+
+// recordJSONLDHandler 返回单条记录的 schema.org JSON-LD 元数据，供门户页面嵌入或搜索引擎直接抓取
+func recordJSONLDHandler(sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sitemaps == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "站点地图未启用"})
+			return
+		}
+		doc, err := sitemaps.JSONLD(c.Request.Context(), sitemaps.BaseURL(requestBaseURL(c)),
+			c.Param("biz"), c.Param("table"), c.Param("id"), c.Query("lib"))
+		if err != nil {
+			respondSitemapError(c, err)
+			return
+		}
+		body, err := json.Marshal(doc)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, "application/ld+json; charset=utf-8", body)
+	}
+}
+
+// invalidateSitemapHandler 丢弃站点地图缓存，供管理员在批量导入数据后立即重新生成
+func invalidateSitemapHandler(sitemaps *sitemap.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sitemaps == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "站点地图未启用"})
+			return
+		}
+		sitemaps.Invalidate()
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "站点地图缓存已清除，将在下次访问时重新生成"})
+	}
+}
@@ -0,0 +1,138 @@
+// Package router file: internal/transport/http/router/slo_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/slo"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// sloTracking 把业务组数据与元数据请求的结果与耗时交给服务等级目标统计，需在 requireBizEnabled 之后使用，
+// 使被暂停服务的业务组返回的 503 不计入错误。交给错误中间件的错误此时尚未写出响应，按错误中间件的规则推断状态码
+func sloTracking(tracker *slo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker == nil {
+			c.Next()
+			return
+		}
+		bizName := requestBizName(c)
+		start := time.Now()
+		c.Next()
+		if bizName == "" {
+			return
+		}
+		status := c.Writer.Status()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			status = errorStatus(c.Errors.Last().Err)
+		}
+		tracker.Observe(bizName, status, time.Since(start))
+	}
+}
+
+// errorStatus 返回错误中间件为 err 写出的状态码
+func errorStatus(err error) int {
+	var ve validator.ValidationErrors
+	switch {
+	case errors.As(err, &ve), errors.Is(err, port.ErrInvalidIdentifier):
+		return http.StatusBadRequest
+	case errors.Is(err, port.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, port.ErrBizNotFound), errors.Is(err, port.ErrTableNotFoundInBiz):
+		return http.StatusNotFound
+	case errors.Is(err, port.ErrInsufficientStorage):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, port.ErrChangeCaptureUnsupported):
+		return http.StatusConflict
+	case errors.Is(err, port.ErrAdminMutateUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// listSLOStatusesHandler 列出所有业务组的服务等级目标、达成情况与错误预算的消耗速度
+func listSLOStatusesHandler(tracker *slo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "服务等级目标跟踪未启用"})
+			return
+		}
+		statuses, err := tracker.ListStatuses(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": statuses})
+	}
+}
+
+// getSLOStatusHandler 返回一个业务组的服务等级目标与达成情况
+func getSLOStatusHandler(tracker *slo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "服务等级目标跟踪未启用"})
+			return
+		}
+		status, err := tracker.Status(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			respondSLOError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": status})
+	}
+}
+
+// putSLOHandler 设置业务组的服务等级目标，请求体为 {"availability_target": 0.999, "latency_threshold_ms": 500, "latency_target": 0.95, "window_days": 30}
+func putSLOHandler(tracker *slo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "服务等级目标跟踪未启用"})
+			return
+		}
+		var body domain.BizSLO
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		body.BizName = c.Param("bizName")
+		saved, err := tracker.SetSLO(c.Request.Context(), body)
+		if err != nil {
+			respondSLOError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": saved})
+	}
+}
+
+// deleteSLOHandler 删除业务组的服务等级目标及其请求统计
+func deleteSLOHandler(tracker *slo.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "服务等级目标跟踪未启用"})
+			return
+		}
+		if err := tracker.DeleteSLO(c.Request.Context(), c.Param("bizName")); err != nil {
+			respondSLOError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "服务等级目标已删除"})
+	}
+}
+
+// respondSLOError 把无效目标映射为 400、未设置目标映射为 404，其余交给错误中间件
+func respondSLOError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, slo.ErrInvalidSLO):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, slo.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package router file: internal/transport/http/router/snapshot_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/fixity"
+	"ArchiveAegis/internal/service/snapshot"
+	"ArchiveAegis/internal/service/uploads"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createSnapshotHandler 将业务组导出为可下载的快照包 (zip)
+func createSnapshotHandler(snapshotService *snapshot.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		fileName := fmt.Sprintf("%s-%s.aegis.zip", bizName, time.Now().Format("20060102-150405"))
+
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+		if err := snapshotService.Export(c.Request.Context(), bizName, c.Writer); err != nil {
+			slog.Error("createSnapshotHandler 执行失败", "biz", bizName, "error", err)
+			// 响应体可能已经开始写入，此时只能中断连接，无法再返回 JSON 错误
+			if !c.Writer.Written() {
+				c.Header("Content-Type", "")
+				c.Header("Content-Disposition", "")
+				_ = c.Error(err)
+			}
+			return
+		}
+	}
+}
+
+// restoreSnapshotHandler 从上传的快照包恢复业务组，表单字段为 "file"；较大的快照包可以先分块上传，
+// 再以 ?upload_id= 指定已完成的上传，恢复成功后该上传会被删除。
+// 当目标业务组已有数据库文件时需要显式传递 ?overwrite=true。
+func restoreSnapshotHandler(snapshotService *snapshot.Service, fixityService *fixity.Service, uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		var (
+			file io.ReaderAt
+			size int64
+		)
+		uploadID := c.Query("upload_id")
+		if uploadID != "" {
+			if uploadService == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "分块上传未启用"})
+				return
+			}
+			path, session, err := uploadService.Path(c.Request.Context(), uploadID)
+			if err != nil {
+				respondUploadError(c, err)
+				return
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			defer f.Close()
+			file, size = f, session.TotalSize
+		} else {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "缺少快照文件 (表单字段 'file' 或参数 upload_id)"})
+				return
+			}
+			f, err := fileHeader.Open()
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			defer f.Close()
+			file, size = f, fileHeader.Size
+		}
+
+		overwrite := c.Query("overwrite") == "true"
+		result, err := snapshotService.Restore(c.Request.Context(), bizName, file, size, overwrite)
+		if err != nil {
+			switch {
+			case errors.Is(err, snapshot.ErrInvalidSnapshot):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, snapshot.ErrBizDataExists):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "，如需覆盖请传递 overwrite=true"})
+			default:
+				slog.Error("restoreSnapshotHandler 执行失败", "biz", bizName, "error", err)
+				_ = c.Error(err)
+			}
+			return
+		}
+		// 恢复会替换库文件，告知库文件校验这是预期内的变更
+		if fixityService != nil {
+			fixityService.NoteWrite(bizName)
+		}
+		if uploadID != "" {
+			if err := uploadService.Delete(c.Request.Context(), uploadID); err != nil {
+				slog.Warn("restoreSnapshotHandler 删除已使用的上传失败", "upload_id", uploadID, "error", err)
+			}
+		}
+		message := fmt.Sprintf("业务组 '%s' 已从快照恢复，请为其创建或启动插件实例以加载数据。", bizName)
+		if result.Restarted {
+			message = fmt.Sprintf("业务组 '%s' 已从快照恢复，插件实例 '%s' 已重新启动。", bizName, result.InstanceID)
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":      "success",
+			"message":     message,
+			"source_biz":  result.Manifest.BizName,
+			"created_at":  result.Manifest.CreatedAt,
+			"lib_count":   len(result.Manifest.Files),
+			"instance_id": result.InstanceID,
+			"restarted":   result.Restarted,
+			"warnings":    result.Warnings,
+		})
+	}
+}
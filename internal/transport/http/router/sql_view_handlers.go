@@ -0,0 +1,82 @@
+// Package router file: internal/transport/http/router/sql_view_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updateTableSQLViewConfigHandler 把表定义为 SQL 视图表。语句先由数据源校验并在业务组的库中试执行，
+// 通过后才保存；响应中的 columns 是视图的列名，管理员随后据此配置字段。视图表只读，不能与写权限同时启用
+func updateTableSQLViewConfigHandler(registry map[string]port.DataSource, configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		var cfg domain.SQLViewConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		bizConfig, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if bizConfig == nil {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+		tableConfig, ok := bizConfig.Tables[tableName]
+		if !ok {
+			_ = c.Error(port.ErrTableNotFoundInBiz)
+			return
+		}
+		if tableConfig.AllowCreate || tableConfig.AllowUpdate || tableConfig.AllowDelete {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("表 '%s' 启用了写权限，SQL 视图表只读，请先关闭写权限", tableName)})
+			return
+		}
+
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+		validator, ok := port.FindCapability[port.SQLViewValidator](dataSource)
+		if !ok {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": fmt.Sprintf("业务组 '%s' 的数据源不支持 SQL 视图表", bizName)})
+			return
+		}
+		columns, err := validator.ValidateSQLView(c.Request.Context(), bizName, tableName, &cfg)
+		if err != nil {
+			if errors.Is(err, port.ErrInvalidSQLView) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+
+		if err := configService.UpdateTableSQLViewConfig(c.Request.Context(), bizName, tableName, &cfg); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"sql_view": cfg, "columns": columns}})
+	}
+}
+
+// deleteTableSQLViewConfigHandler 清除表的 SQL 视图定义，之后该表按同名的物理表查询
+func deleteTableSQLViewConfigHandler(configService port.QueryAdminConfigService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName, tableName := c.Param("bizName"), c.Param("tableName")
+		if err := configService.UpdateTableSQLViewConfig(c.Request.Context(), bizName, tableName, nil); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("表 '%s/%s' 的 SQL 视图定义已清除", bizName, tableName)})
+	}
+}
@@ -0,0 +1,112 @@
+// Package router file: internal/transport/http/router/standby_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/standby"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHeader 在每个响应中标明网关的角色 (active / standby)，供负载均衡判断故障切换
+const RoleHeader = "X-Aegis-Role"
+
+// standbyReadOnly 在备用网关上拒绝写请求。数据平面的检索以 POST 提交，仍然放行；
+// 登录与提升接口也放行，否则主网关故障后无法登录备用网关并将其提升
+func standbyReadOnly(standbyService *standby.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if standbyService == nil {
+			c.Next()
+			return
+		}
+		if !standbyService.IsStandby() {
+			c.Header(RoleHeader, standby.RoleActive)
+			c.Next()
+			return
+		}
+		c.Header(RoleHeader, standby.RoleStandby)
+		if standbyAllowed(c.Request.Method, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "备用网关为只读，请向主网关提交写请求"})
+	}
+}
+
+func standbyAllowed(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		switch path {
+		case "/api/v1/auth/login", "/api/v1/admin/standby/promote", "/api/v1/oai":
+			return true
+		case "/api/v1/data/mutate":
+			return false
+		}
+		return strings.HasPrefix(path, "/api/v1/data/")
+	}
+	return false
+}
+
+// standbyStatusHandler 返回网关的角色与 auth.db 的复制延迟
+func standbyStatusHandler(standbyService *standby.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if standbyService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "备用模式未配置"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": standbyService.Status()})
+	}
+}
+
+// standbySnapshotHandler 向备用网关提供 auth.db 的一致性快照
+func standbySnapshotHandler(standbyService *standby.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if standbyService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "备用模式未配置"})
+			return
+		}
+		wroteHeader := false
+		err := standbyService.WriteSnapshot(c.Request.Context(), c.Writer, func(createdAt time.Time, size int64) {
+			wroteHeader = true
+			c.Header(standby.SnapshotTimeHeader, createdAt.Format(time.RFC3339Nano))
+			c.Header("Content-Length", strconv.FormatInt(size, 10))
+			c.Header("Content-Type", "application/vnd.sqlite3")
+			c.Status(http.StatusOK)
+		})
+		if err != nil {
+			if !wroteHeader {
+				_ = c.Error(err)
+				return
+			}
+			// 响应头已发出，备用网关会因长度不符丢弃这次快照
+			slog.Warn("发送 auth.db 快照时中断", "error", err)
+		}
+	}
+}
+
+// promoteStandbyHandler 把备用网关提升为主网关。应先确认原主网关已停止服务，避免两个网关同时接受写入
+func promoteStandbyHandler(standbyService *standby.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if standbyService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "备用模式未配置"})
+			return
+		}
+		status, err := standbyService.Promote()
+		if err != nil {
+			if errors.Is(err, standby.ErrNotStandby) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": status})
+	}
+}
@@ -0,0 +1,29 @@
+// file: internal/transport/http/router/standby_handlers_test.go
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandbyAllowed(t *testing.T) {
+	cases := []struct {
+		method, path string
+		want         bool
+	}{
+		{http.MethodGet, "/api/v1/admin/users", true},
+		{http.MethodPost, "/api/v1/data/query", true},
+		{http.MethodPost, "/api/v1/data/timeline", true},
+		{http.MethodPost, "/api/v1/data/mutate", false},
+		{http.MethodPost, "/api/v1/auth/login", true},
+		{http.MethodPost, "/api/v1/admin/standby/promote", true},
+		{http.MethodPost, "/api/v1/admin/exports", false},
+		{http.MethodPut, "/api/v1/me/preferences", false},
+		{http.MethodDelete, "/api/v1/data/record", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, standbyAllowed(tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+}
@@ -0,0 +1,41 @@
+// Package router file: internal/transport/http/router/storage_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/storage"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireFreeSpace 在磁盘可用空间低于下限时以 507 拒绝请求，用于插件安装与快照恢复等会写入大量数据的管理接口。
+// 数据写操作由数据源装饰器另行拦截
+func requireFreeSpace(storageService *storage.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if storageService == nil {
+			c.Next()
+			return
+		}
+		if err := storageService.CheckFreeSpace(); err != nil {
+			c.AbortWithStatusJSON(http.StatusInsufficientStorage, gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// storageReportHandler 返回业务组库文件、instance 目录、插件安装目录与备份目录的空间占用，以及磁盘可用空间
+func storageReportHandler(storageService *storage.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if storageService == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "空间统计未启用"})
+			return
+		}
+		report, err := storageService.Report(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
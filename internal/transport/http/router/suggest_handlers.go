@@ -0,0 +1,51 @@
+// Package router file: internal/transport/http/router/suggest_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/internal/service/suggest"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rebuildSuggestionVocabularyHandler 在后台为表中可搜索的文本字段重建拼写建议词表，可通过 /admin/jobs/:id 查询进度
+func rebuildSuggestionVocabularyHandler(suggestions *suggest.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if suggestions == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "拼写建议未启用"})
+			return
+		}
+		job, err := suggestions.Rebuild(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), requestUserID(c))
+		if err != nil {
+			if errors.Is(err, jobs.ErrJobConflict) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"data": job})
+	}
+}
+
+// listSuggestionVocabulariesHandler 列出业务组下已建立的拼写建议词表及其词数与建立时间
+func listSuggestionVocabulariesHandler(suggestions *suggest.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if suggestions == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "拼写建议未启用"})
+			return
+		}
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		vocabs, err := suggestions.ListVocabularies(c.Request.Context(), c.Param("bizName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, vocabs, page)
+	}
+}
@@ -0,0 +1,22 @@
+// Package router file: internal/transport/http/router/telemetry_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/telemetry"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// telemetryPreviewHandler 返回使用统计的开关与发送状态，以及下一次将要发送的完整内容。
+// 未开启使用统计时同样可用，便于管理员在开启前确认发送的内容
+func telemetryPreviewHandler(usage *telemetry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := usage.Status(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": status})
+	}
+}
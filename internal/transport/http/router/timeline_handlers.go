@@ -0,0 +1,97 @@
+// Package router file: internal/transport/http/router/timeline_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/querydict"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timelineHandlerV1 把与查询匹配的记录按日期字段分桶计数，供时间轴可视化使用。
+// 请求体的 query 与 /data/query 相同 (含 where 与 search)，过滤条件按同样的规则展开后交给数据源一次性分组统计；
+// 分页、去重、抽样与折叠参数被忽略。日期字段必须可返回
+func timelineHandlerV1(registry map[string]port.DataSource, configService port.QueryAdminConfigService, dictionaries *querydict.Service, maxInValues int) gin.HandlerFunc {
+	type requestBody struct {
+		BizName     string                 `json:"biz_name" binding:"required"`
+		Query       map[string]interface{} `json:"query" binding:"required"`
+		Field       string                 `json:"field" binding:"required"`
+		Granularity string                 `json:"granularity"`
+	}
+
+	return func(c *gin.Context) {
+		var body requestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		dataSource, exists := registry[body.BizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+		if mode, _ := body.Query[port.QueryModeKey].(string); mode != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "时间轴只能基于普通检索"})
+			return
+		}
+		for _, key := range []string{port.QueryExplainKey, port.QueryMaxBytesKey, port.QueryRankKey, port.QueryDistinctKey,
+			port.QuerySampleKey, port.QueryCollapseKey, "page", "size", "fields_to_return"} {
+			delete(body.Query, key)
+		}
+
+		query, err := applyWhere(body.Query)
+		if err == nil {
+			err = checkInFilters(query, maxInValues)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		expanded, err := applySearch(c.Request.Context(), configService, body.BizName, query)
+		if err == nil {
+			expanded, err = applyDates(c.Request.Context(), configService, body.BizName, expanded)
+		}
+		if err != nil {
+			if errors.Is(err, errInvalidSearch) || errors.Is(err, errInvalidFilters) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		// 时间轴只计数，不需要计分
+		delete(expanded, port.QueryRankKey)
+		delete(expanded, port.QuerySortKey)
+		if dictionaries != nil {
+			expanded = dictionaries.Expand(body.BizName, expanded)
+		}
+		// 业务组配置了时区时，带时区的日期先换算到该时区再分桶，避免跨越午夜的时间落入相邻的桶；
+		// 未配置时由插件按日期原文分桶
+		spec := map[string]interface{}{"field": body.Field, "granularity": body.Granularity}
+		cfg, err := configService.GetBizQueryConfig(c.Request.Context(), body.BizName)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if cfg != nil && cfg.Timezone != "" {
+			spec["timezone"] = bizLocation(cfg).String()
+		}
+		expanded[port.QueryTimelineKey] = spec
+
+		result, err := dataSource.Query(c.Request.Context(), port.QueryRequest{BizName: body.BizName, Query: expanded})
+		if err != nil {
+			slog.Error("timelineHandlerV1 执行失败", "biz", body.BizName, "field", body.Field, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		// 不识别时间轴参数的数据源会返回普通的分页结果
+		if _, ok := result.Data["buckets"]; !ok {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": "该业务组的数据源不支持时间轴统计"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
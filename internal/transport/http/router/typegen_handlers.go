@@ -0,0 +1,32 @@
+// Package router file: internal/transport/http/router/typegen_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/typegen"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// typegenHandler 返回由业务组的字段配置生成的客户端类型定义源码。
+// lang 为 ts (默认) 或 go，go 可用 package 指定包名。响应带 ETag，客户端可在构建时按条件请求拉取
+func typegenHandler(svc *typegen.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if svc == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "类型生成未启用"})
+			return
+		}
+		lang := c.DefaultQuery("lang", typegen.LangTypeScript)
+		src, err := svc.Generate(c.Request.Context(), c.Param("bizName"), lang, c.Query("package"))
+		if err != nil {
+			if errors.Is(err, typegen.ErrInvalidRequest) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		etagData(c, "text/plain; charset=utf-8", []byte(src))
+	}
+}
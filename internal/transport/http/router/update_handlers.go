@@ -0,0 +1,86 @@
+// Package router file: internal/transport/http/router/update_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/updates"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondUpdateError 把更新检查错误映射为 404/409，其余交给错误中间件
+func respondUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, updates.ErrNotChecked), errors.Is(err, updates.ErrNoUpdate):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, updates.ErrIncompatible):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
+
+// getUpdatesHandler 返回最近一次更新检查的结果
+func getUpdatesHandler(checker *updates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "更新检查未启用"})
+			return
+		}
+		report, err := checker.Report(c.Request.Context())
+		if err != nil {
+			respondUpdateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// checkUpdatesHandler 立即刷新插件仓库与发布源并返回检查结果
+func checkUpdatesHandler(checker *updates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "更新检查未启用"})
+			return
+		}
+		report, err := checker.Check(c.Request.Context())
+		if err != nil {
+			respondUpdateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": report})
+	}
+}
+
+// stagePluginUpdateHandler 下载安装插件的最新版本，等待应用
+func stagePluginUpdateHandler(checker *updates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "更新检查未启用"})
+			return
+		}
+		upd, err := checker.Stage(c.Request.Context(), c.Param("plugin_id"), requestUserID(c))
+		if err != nil {
+			respondUpdateError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": upd})
+	}
+}
+
+// applyPluginUpdateHandler 把使用旧版本的实例切换到已暂存的版本，返回每个实例的结果
+func applyPluginUpdateHandler(checker *updates.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "更新检查未启用"})
+			return
+		}
+		results, err := checker.Apply(c.Request.Context(), c.Param("plugin_id"))
+		if err != nil {
+			respondUpdateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": results})
+	}
+}
@@ -0,0 +1,115 @@
+// Package router file: internal/transport/http/router/upload_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/uploads"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChunkChecksumHeader 是分块上传时随每个分块提交的 SHA-256 (十六进制)
+const ChunkChecksumHeader = "X-Chunk-SHA256"
+
+// initUploadRequest 是创建分块上传会话的请求体
+type initUploadRequest struct {
+	FileName string `json:"file_name" binding:"required"`
+	Size     int64  `json:"size" binding:"required"`
+	SHA256   string `json:"sha256"`
+}
+
+// initUploadHandler 创建分块上传会话，响应中的 max_chunk_bytes 是单个分块的大小上限
+func initUploadHandler(uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body initUploadRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		session, err := uploadService.Init(c.Request.Context(), requestUserID(c), body.FileName, body.Size, body.SHA256)
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": session, "max_chunk_bytes": uploadService.MaxChunkBytes()})
+	}
+}
+
+// getUploadHandler 返回上传会话，断点续传时从 received 处继续
+func getUploadHandler(uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := uploadService.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": session})
+	}
+}
+
+// appendUploadChunkHandler 追加一个分块：请求体为分块的原始字节，?offset= 为其在文件中的偏移，
+// 请求头 X-Chunk-SHA256 为其校验值。偏移与已接收的字节数不一致时返回 409 及当前的 received
+func appendUploadChunkHandler(uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset 参数必须是非负整数"})
+			return
+		}
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, uploadService.MaxChunkBytes()+1)
+		session, err := uploadService.AppendChunk(c.Request.Context(), c.Param("id"), offset, c.GetHeader(ChunkChecksumHeader), body)
+		if errors.Is(err, uploads.ErrOffsetMismatch) && session != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "received": session.Received})
+			return
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = uploads.ErrTooLarge
+		}
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": session})
+	}
+}
+
+// completeUploadHandler 在全部分块上传后校验整个文件，完成的上传可以用其 ID 调用库文件替换与快照恢复
+func completeUploadHandler(uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := uploadService.Complete(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": session})
+	}
+}
+
+// deleteUploadHandler 放弃上传并删除已上传的数据
+func deleteUploadHandler(uploadService *uploads.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := uploadService.Delete(c.Request.Context(), c.Param("id")); err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	}
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, uploads.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, uploads.ErrInvalidUpload), errors.Is(err, uploads.ErrChecksumMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, uploads.ErrOffsetMismatch), errors.Is(err, uploads.ErrNotComplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, uploads.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,44 @@
+// Package router file: internal/transport/http/router/upstream_cache_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upstreamCacheStatsHandler 返回业务组数据源对上游接口响应的缓存计数 (如 REST 插件)。
+// 数据源不缓存上游响应时由错误中间件返回 501
+func upstreamCacheStatsHandler(registry map[string]port.DataSource) gin.HandlerFunc {
+	return upstreamCacheHandler(registry, "stats")
+}
+
+// purgeUpstreamCacheHandler 清空业务组数据源缓存的上游响应，下次查询重新请求上游
+func purgeUpstreamCacheHandler(registry map[string]port.DataSource) gin.HandlerFunc {
+	return upstreamCacheHandler(registry, "purge")
+}
+
+func upstreamCacheHandler(registry map[string]port.DataSource, step string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		result, err := port.AdminMutate(c.Request.Context(), dataSource, port.MutateRequest{
+			BizName:   bizName,
+			Operation: port.MutateOpUpstreamCache,
+			Payload:   map[string]interface{}{"step": step},
+		})
+		if err != nil {
+			slog.Error("upstreamCacheHandler 执行失败", "biz", bizName, "step", step, "error", err)
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result.Data)
+	}
+}
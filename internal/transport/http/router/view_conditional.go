@@ -0,0 +1,106 @@
+// Package router file: internal/transport/http/router/view_conditional.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	maxConditionalFormats   = 50
+	maxConditionalLabelRune = 32
+)
+
+// conditionOperands 是各条件格式运算符需要的比较值：value 需要 Value，values 需要非空的 Values，none 两者都不需要
+var conditionOperands = map[string]string{
+	domain.ConditionOpEq:        "value",
+	domain.ConditionOpNe:        "value",
+	domain.ConditionOpGt:        "value",
+	domain.ConditionOpGte:       "value",
+	domain.ConditionOpLt:        "value",
+	domain.ConditionOpLte:       "value",
+	domain.ConditionOpContains:  "value",
+	domain.ConditionOpIn:        "values",
+	domain.ConditionOpIsNull:    "none",
+	domain.ConditionOpIsNotNull: "none",
+	domain.ConditionOpIsEmpty:   "none",
+}
+
+var conditionTones = map[string]bool{
+	domain.ConditionToneDanger:  true,
+	domain.ConditionToneWarning: true,
+	domain.ConditionToneSuccess: true,
+	domain.ConditionToneInfo:    true,
+	domain.ConditionToneMuted:   true,
+}
+
+// validateConditionalFormats 校验视图中条件格式规则的结构：运算符与比较值、作用范围与样式
+func validateConditionalFormats(view *domain.ViewConfig) error {
+	if len(view.ConditionalFormats) > maxConditionalFormats {
+		return fmt.Errorf("条件格式规则不能超过 %d 条", maxConditionalFormats)
+	}
+	for i, rule := range view.ConditionalFormats {
+		if rule.Field == "" {
+			return fmt.Errorf("第 %d 条条件格式规则缺少 'field'", i+1)
+		}
+		operand, ok := conditionOperands[rule.Op]
+		if !ok {
+			return fmt.Errorf("第 %d 条条件格式规则的运算符 '%s' 不受支持", i+1, rule.Op)
+		}
+		switch {
+		case operand == "values" && len(rule.Values) == 0:
+			return fmt.Errorf("第 %d 条条件格式规则的运算符 '%s' 需要非空的 'values' 列表", i+1, rule.Op)
+		case operand == "value" && rule.Value == "" && rule.Op != domain.ConditionOpEq && rule.Op != domain.ConditionOpNe:
+			return fmt.Errorf("第 %d 条条件格式规则的运算符 '%s' 需要 'value'", i+1, rule.Op)
+		case operand != "values" && len(rule.Values) > 0:
+			return fmt.Errorf("第 %d 条条件格式规则的运算符 '%s' 不使用 'values'", i+1, rule.Op)
+		}
+		if rule.Scope != "" && rule.Scope != domain.ConditionScopeCell && rule.Scope != domain.ConditionScopeRow {
+			return fmt.Errorf("第 %d 条条件格式规则的作用范围 '%s' 无效，可选 cell 或 row", i+1, rule.Scope)
+		}
+		style := rule.Style
+		if style.Tone != "" && !conditionTones[style.Tone] {
+			return fmt.Errorf("第 %d 条条件格式规则的色调 '%s' 无效，可选 danger、warning、success、info、muted", i+1, style.Tone)
+		}
+		if utf8.RuneCountInString(style.Label) > maxConditionalLabelRune {
+			return fmt.Errorf("第 %d 条条件格式规则的标签不能超过 %d 个字符", i+1, maxConditionalLabelRune)
+		}
+		if style.Tone == "" && !style.Bold && !style.Italic && style.Label == "" {
+			return fmt.Errorf("第 %d 条条件格式规则没有设置任何样式", i+1)
+		}
+	}
+	return nil
+}
+
+// validateConditionalFormatFields 校验条件格式规则引用的字段是表中可返回的字段，客户端只能看到这些字段的取值
+func validateConditionalFormatFields(view *domain.ViewConfig, table *domain.TableConfig) error {
+	for i, rule := range view.ConditionalFormats {
+		setting, exists := table.Fields[rule.Field]
+		if !exists {
+			return fmt.Errorf("第 %d 条条件格式规则的字段 '%s' 不在表 '%s' 的字段配置中", i+1, rule.Field, table.TableName)
+		}
+		if !setting.IsReturnable {
+			return fmt.Errorf("第 %d 条条件格式规则的字段 '%s' 不可返回，客户端无法据此求值", i+1, rule.Field)
+		}
+	}
+	return nil
+}
+
+// validateViewFields 按业务组当前的字段配置校验视图中条件格式引用的字段
+func validateViewFields(ctx context.Context, configService port.QueryAdminConfigService, bizName, tableName string, view *domain.ViewConfig) error {
+	bizConfig, err := configService.GetBizQueryConfig(ctx, bizName)
+	if err != nil {
+		return err
+	}
+	if bizConfig == nil {
+		return port.ErrBizNotFound
+	}
+	table, exists := bizConfig.Tables[tableName]
+	if !exists {
+		return fmt.Errorf("表 '%s' 没有字段配置，无法校验条件格式", tableName)
+	}
+	return validateConditionalFormatFields(view, table)
+}
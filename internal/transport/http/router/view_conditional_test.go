@@ -0,0 +1,34 @@
+// file: internal/transport/http/router/view_conditional_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConditionalFormats(t *testing.T) {
+	bold := domain.ConditionalStyle{Bold: true}
+	assert.NoError(t, validateConditionalFormats(&domain.ViewConfig{ConditionalFormats: []domain.ConditionalFormat{
+		{Field: "status", Op: domain.ConditionOpEq, Value: "damaged", Scope: domain.ConditionScopeRow, Style: domain.ConditionalStyle{Tone: domain.ConditionToneDanger}},
+		{Field: "status", Op: domain.ConditionOpEq, Style: bold},
+		{Field: "year", Op: domain.ConditionOpLt, Value: "1900", Style: domain.ConditionalStyle{Label: "早期"}},
+		{Field: "status", Op: domain.ConditionOpIn, Values: []string{"lost", "stolen"}, Style: bold},
+		{Field: "note", Op: domain.ConditionOpIsEmpty, Style: domain.ConditionalStyle{Tone: domain.ConditionToneMuted, Italic: true}},
+	}}))
+
+	for name, rule := range map[string]domain.ConditionalFormat{
+		"缺少字段":     {Op: domain.ConditionOpEq, Style: bold},
+		"未知运算符":    {Field: "a", Op: "like", Style: bold},
+		"比较缺少值":    {Field: "a", Op: domain.ConditionOpGt, Style: bold},
+		"in 缺少候选值": {Field: "a", Op: domain.ConditionOpIn, Style: bold},
+		"多余的候选值":   {Field: "a", Op: domain.ConditionOpIsNull, Values: []string{"x"}, Style: bold},
+		"无效的作用范围":  {Field: "a", Op: domain.ConditionOpIsNull, Scope: "column", Style: bold},
+		"无效的色调":    {Field: "a", Op: domain.ConditionOpIsNull, Style: domain.ConditionalStyle{Tone: "#ff0000"}},
+		"没有样式":     {Field: "a", Op: domain.ConditionOpIsNull},
+		"标签过长":     {Field: "a", Op: domain.ConditionOpIsNull, Style: domain.ConditionalStyle{Label: "这是一个超过三十二个字符长度限制的非常非常非常非常非常非常长的标签文本"}},
+	} {
+		assert.Error(t, validateConditionalFormats(&domain.ViewConfig{ConditionalFormats: []domain.ConditionalFormat{rule}}), name)
+	}
+}
@@ -0,0 +1,127 @@
+// Package router file: internal/transport/http/router/view_defaults.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"fmt"
+	"strings"
+)
+
+// validateViewConfig 校验视图中由网关执行或解释的部分：投影规格、默认过滤条件、默认排序、表格列的格式与条件格式
+func validateViewConfig(view *domain.ViewConfig) error {
+	if _, err := compileProjection(view.Projection); err != nil {
+		return err
+	}
+	if err := validateColumnFormats(view); err != nil {
+		return err
+	}
+	if err := validateConditionalFormats(view); err != nil {
+		return err
+	}
+	for i, f := range view.DefaultFilters {
+		if f.Field == "" {
+			return fmt.Errorf("第 %d 个默认过滤条件缺少 'field'", i+1)
+		}
+		if f.Op != "" && !whereOperators[f.Op] {
+			return fmt.Errorf("第 %d 个默认过滤条件的运算符 '%s' 不受支持", i+1, f.Op)
+		}
+		if f.Op == "in" && len(f.Values) == 0 {
+			return fmt.Errorf("第 %d 个默认过滤条件的运算符 'in' 需要非空的 '%s' 列表", i+1, port.FilterValuesKey)
+		}
+		if f.Logic != "" && !strings.EqualFold(f.Logic, "AND") && !strings.EqualFold(f.Logic, "OR") {
+			return fmt.Errorf("第 %d 个默认过滤条件的 logic '%s' 无效，可选 AND 或 OR", i+1, f.Logic)
+		}
+	}
+	if view.DefaultSort != "" && view.DefaultSort != port.ResultScoreField {
+		return fmt.Errorf("默认排序键 '%s' 不受支持，目前只支持 '%s'", view.DefaultSort, port.ResultScoreField)
+	}
+	return nil
+}
+
+// applyViewDefaults 把视图的默认过滤条件与默认排序加到已编译 where 的查询上，原查询不被修改。
+// 默认过滤条件与查询自身的 filters 为 AND：两者各自按 SQL 优先级拆分为子句后两两组合，
+// 组合后的子句数受 whereMaxTerms 限制。默认排序只在查询未指定 sort 时生效
+func applyViewDefaults(query map[string]interface{}, view *domain.ViewConfig) (map[string]interface{}, error) {
+	if len(view.DefaultFilters) == 0 && view.DefaultSort == "" {
+		return query, nil
+	}
+	applied := make(map[string]interface{}, len(query)+1)
+	for k, v := range query {
+		applied[k] = v
+	}
+	if _, set := query[port.QuerySortKey]; !set && view.DefaultSort != "" {
+		applied[port.QuerySortKey] = view.DefaultSort
+	}
+	if len(view.DefaultFilters) == 0 {
+		return applied, nil
+	}
+
+	defaults := make([]interface{}, len(view.DefaultFilters))
+	for i, f := range view.DefaultFilters {
+		defaults[i] = viewFilterMap(f)
+	}
+	viewClauses, err := filterClauses(defaults)
+	if err != nil {
+		return nil, err
+	}
+	queryClauses, err := filterClauses(query["filters"])
+	if err != nil {
+		return nil, err
+	}
+	if len(viewClauses)*len(queryClauses) > whereMaxTerms {
+		return nil, fmt.Errorf("%w: 与视图的默认过滤条件组合后超过 %d 个子句，请减少 OR 条件", errInvalidFilters, whereMaxTerms)
+	}
+
+	// (V1 OR V2) AND (Q1 OR Q2) = V1Q1 OR V1Q2 OR V2Q1 OR V2Q2
+	var filters []interface{}
+	for i, viewClause := range viewClauses {
+		for j, queryClause := range queryClauses {
+			clause := append(append([]map[string]interface{}{}, viewClause...), queryClause...)
+			last := i == len(viewClauses)-1 && j == len(queryClauses)-1
+			for k, leaf := range clause {
+				filter := make(map[string]interface{}, len(leaf)+1)
+				for key, v := range leaf {
+					filter[key] = v
+				}
+				switch {
+				case k < len(clause)-1:
+					filter["logic"] = "AND"
+				case !last:
+					filter["logic"] = "OR"
+				}
+				filters = append(filters, filter)
+			}
+		}
+	}
+	applied["filters"] = filters
+	return applied, nil
+}
+
+// viewFilterMap 把视图的默认过滤条件转换为查询 filters 数组的元素，省略零值的键
+func viewFilterMap(f domain.ViewFilter) map[string]interface{} {
+	filter := map[string]interface{}{"field": f.Field}
+	if f.Value != "" {
+		filter["value"] = f.Value
+	}
+	if len(f.Values) > 0 {
+		values := make([]interface{}, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = v
+		}
+		filter[port.FilterValuesKey] = values
+	}
+	if f.Op != "" {
+		filter["op"] = f.Op
+	}
+	if f.Fuzzy {
+		filter["fuzzy"] = true
+	}
+	if f.Negate {
+		filter[port.FilterNegateKey] = true
+	}
+	if f.Logic != "" {
+		filter["logic"] = f.Logic
+	}
+	return filter
+}
@@ -0,0 +1,74 @@
+// file: internal/transport/http/router/view_defaults_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyViewDefaults(t *testing.T) {
+	view := &domain.ViewConfig{
+		ViewName: "published",
+		DefaultFilters: []domain.ViewFilter{
+			{Field: "status", Value: "published", Logic: "or"},
+			{Field: "status", Value: "archived"},
+		},
+		DefaultSort: port.ResultScoreField,
+	}
+
+	// (status=published OR status=archived) AND (place=北京 OR place=上海)
+	query := map[string]interface{}{
+		"filters": []interface{}{
+			map[string]interface{}{"field": "place", "value": "北京", "logic": "OR"},
+			map[string]interface{}{"field": "place", "value": "上海"},
+		},
+	}
+	applied, err := applyViewDefaults(query, view)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "status", "value": "published", "logic": "AND"},
+		map[string]interface{}{"field": "place", "value": "北京", "logic": "OR"},
+		map[string]interface{}{"field": "status", "value": "published", "logic": "AND"},
+		map[string]interface{}{"field": "place", "value": "上海", "logic": "OR"},
+		map[string]interface{}{"field": "status", "value": "archived", "logic": "AND"},
+		map[string]interface{}{"field": "place", "value": "北京", "logic": "OR"},
+		map[string]interface{}{"field": "status", "value": "archived", "logic": "AND"},
+		map[string]interface{}{"field": "place", "value": "上海"},
+	}, applied["filters"])
+	assert.Equal(t, port.ResultScoreField, applied[port.QuerySortKey])
+	assert.NotContains(t, query, port.QuerySortKey, "原查询不应被修改")
+
+	// 查询没有条件时只使用视图的默认条件；显式指定的排序不被覆盖
+	applied, err = applyViewDefaults(map[string]interface{}{"sort": ""}, &domain.ViewConfig{
+		DefaultFilters: []domain.ViewFilter{{Field: "tags", Op: "in", Values: []string{"a", "b"}, Negate: true}},
+		DefaultSort:    port.ResultScoreField,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "tags", "op": "in", port.FilterValuesKey: []interface{}{"a", "b"}, port.FilterNegateKey: true},
+	}, applied["filters"])
+	assert.Equal(t, "", applied[port.QuerySortKey])
+
+	_, err = applyViewDefaults(map[string]interface{}{"filters": "x"}, view)
+	assert.ErrorIs(t, err, errInvalidFilters)
+}
+
+func TestValidateViewConfig(t *testing.T) {
+	assert.NoError(t, validateViewConfig(&domain.ViewConfig{
+		DefaultFilters: []domain.ViewFilter{{Field: "place", Op: "is_null", Logic: "or"}, {Field: "id", Op: "in", Values: []string{"1"}}},
+		DefaultSort:    port.ResultScoreField,
+	}))
+	for name, view := range map[string]*domain.ViewConfig{
+		"缺少字段":     {DefaultFilters: []domain.ViewFilter{{Value: "x"}}},
+		"未知运算符":    {DefaultFilters: []domain.ViewFilter{{Field: "a", Op: "like"}}},
+		"in 缺少候选值": {DefaultFilters: []domain.ViewFilter{{Field: "a", Op: "in"}}},
+		"无效 logic": {DefaultFilters: []domain.ViewFilter{{Field: "a", Logic: "xor"}}},
+		"不支持的排序键":  {DefaultSort: "title"},
+	} {
+		assert.Error(t, validateViewConfig(view), name)
+	}
+}
@@ -0,0 +1,84 @@
+// Package router file: internal/transport/http/router/view_fields.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"strings"
+)
+
+// viewBindingFields 返回视图绑定引用的字段 (卡片的各个槽位与表格的各列)，按首次出现的顺序去重
+func viewBindingFields(view *domain.ViewConfig) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	add := func(field string) {
+		if field != "" && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	if card := view.Binding.Card; card != nil {
+		for _, field := range []string{card.Title, card.Subtitle, card.Description, card.ImageUrl, card.Tag} {
+			add(field)
+		}
+	}
+	if table := view.Binding.Table; table != nil {
+		for _, column := range table.Columns {
+			add(column.Field)
+		}
+	}
+	return fields
+}
+
+// restrictResultFields 返回只保留指定字段的结果副本，不修改原结果 (原结果可能被查询缓存共享)。
+// 以 "_" 开头的键是数据源或网关附加的信息 (如 __lib、_score)，始终保留
+func restrictResultFields(result *port.QueryResult, fields []string) *port.QueryResult {
+	if result == nil || len(fields) == 0 {
+		return result
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+	restrict := func(row map[string]interface{}) map[string]interface{} {
+		out := make(map[string]interface{}, len(fields))
+		for k, v := range row {
+			if keep[k] || strings.HasPrefix(k, "_") {
+				out[k] = v
+			}
+		}
+		return out
+	}
+
+	data := make(map[string]interface{}, len(result.Data))
+	for k, v := range result.Data {
+		data[k] = v
+	}
+	if items, ok := result.Data["items"].([]interface{}); ok {
+		restricted := make([]interface{}, len(items))
+		for i, item := range items {
+			if row, ok := item.(map[string]interface{}); ok {
+				restricted[i] = restrict(row)
+			} else {
+				restricted[i] = item
+			}
+		}
+		data["items"] = restricted
+	}
+	if record, ok := result.Data["record"].(map[string]interface{}); ok {
+		data["record"] = restrict(record)
+	}
+	return &port.QueryResult{Data: data, Source: result.Source}
+}
+
+// viewMetadata 是随按视图查询的结果返回的视图描述，供客户端据此渲染；
+// 默认过滤条件等由网关执行的设置不包含在内
+func viewMetadata(view *domain.ViewConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"view_name":    view.ViewName,
+		"view_type":    view.ViewType,
+		"display_name": view.DisplayName,
+		"is_default":   view.IsDefault,
+		"binding":      view.Binding,
+	}
+}
@@ -0,0 +1,153 @@
+// Package router file: internal/transport/http/router/view_format.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxFormatPrecision = 10
+
+var (
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	localePattern       = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	// dateTokens 是日期格式中可用的占位符，按长度从长到短匹配
+	dateTokens = []string{"yyyy", "yy", "MM", "M", "dd", "d", "HH", "H", "mm", "ss"}
+)
+
+// parseColumnFormat 按格式词汇解析列格式，格式为 "<类型>[:<参数>...][@<区域设置>]"：
+//   - date:<格式>，如 date:yyyy-MM-dd
+//   - number[:<小数位数>]、percent[:<小数位数>]
+//   - currency:<货币代码>[:<小数位数>]，如 currency:CNY:2
+//   - boolean[:<真值标签>/<假值标签>]，如 boolean:是/否
+func parseColumnFormat(format string) (*domain.ColumnFormat, error) {
+	spec := &domain.ColumnFormat{}
+	body := format
+	if at := strings.LastIndex(format, "@"); at >= 0 {
+		body, spec.Locale = format[:at], format[at+1:]
+		if !localePattern.MatchString(spec.Locale) {
+			return nil, fmt.Errorf("区域设置 '%s' 无效，应为 BCP 47 格式，如 zh-CN", spec.Locale)
+		}
+	}
+	kind, args, _ := strings.Cut(body, ":")
+	spec.Type = kind
+
+	switch kind {
+	case domain.ColumnFormatDate:
+		if err := validateDatePattern(args); err != nil {
+			return nil, err
+		}
+		spec.Pattern = args
+	case domain.ColumnFormatNumber, domain.ColumnFormatPercent:
+		if args != "" {
+			precision, err := parseFormatPrecision(args)
+			if err != nil {
+				return nil, err
+			}
+			spec.Precision = precision
+		}
+	case domain.ColumnFormatCurrency:
+		code, precision, _ := strings.Cut(args, ":")
+		if !currencyCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("货币代码 '%s' 无效，应为三个大写字母，如 CNY", code)
+		}
+		spec.Currency = code
+		if precision != "" {
+			p, err := parseFormatPrecision(precision)
+			if err != nil {
+				return nil, err
+			}
+			spec.Precision = p
+		}
+	case domain.ColumnFormatBoolean:
+		if args != "" {
+			trueLabel, falseLabel, ok := strings.Cut(args, "/")
+			if !ok || trueLabel == "" || falseLabel == "" {
+				return nil, fmt.Errorf("布尔格式的标签 '%s' 无效，应为 <真值标签>/<假值标签>", args)
+			}
+			spec.TrueLabel, spec.FalseLabel = trueLabel, falseLabel
+		}
+	default:
+		return nil, fmt.Errorf("未知的格式类型 '%s'，可选 date、number、percent、currency、boolean", kind)
+	}
+	return spec, nil
+}
+
+func parseFormatPrecision(s string) (*int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > maxFormatPrecision {
+		return nil, fmt.Errorf("小数位数 '%s' 无效，应为 0 到 %d 之间的整数", s, maxFormatPrecision)
+	}
+	return &n, nil
+}
+
+// validateDatePattern 校验日期格式：字母必须组成已知的占位符，其余字符作为分隔符原样输出
+func validateDatePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("日期格式不能为空，如 date:yyyy-MM-dd")
+	}
+	hasToken := false
+	for rest := pattern; rest != ""; {
+		r := []rune(rest)[0]
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			rest = rest[len(string(r)):]
+			continue
+		}
+		matched := ""
+		for _, token := range dateTokens {
+			if strings.HasPrefix(rest, token) {
+				matched = token
+				break
+			}
+		}
+		if matched == "" {
+			return fmt.Errorf("日期格式 '%s' 中的 '%c' 不是可用的占位符 (%s)", pattern, r, strings.Join(dateTokens, "、"))
+		}
+		hasToken = true
+		rest = rest[len(matched):]
+	}
+	if !hasToken {
+		return fmt.Errorf("日期格式 '%s' 不含任何占位符", pattern)
+	}
+	return nil
+}
+
+// validateColumnFormats 校验表格视图中每一列的格式
+func validateColumnFormats(view *domain.ViewConfig) error {
+	if view.Binding.Table == nil {
+		return nil
+	}
+	for i, col := range view.Binding.Table.Columns {
+		if col.Format == "" {
+			continue
+		}
+		if _, err := parseColumnFormat(col.Format); err != nil {
+			return fmt.Errorf("第 %d 列 '%s' 的格式 '%s' 无效: %w", i+1, col.Field, col.Format, err)
+		}
+	}
+	return nil
+}
+
+// withFormatSpecs 返回填写了各列结构化格式的视图副本，原视图不被修改。
+// 按旧版本保存、不符合格式词汇的格式不填写，客户端按原字符串处理
+func withFormatSpecs(view *domain.ViewConfig) *domain.ViewConfig {
+	if view == nil || view.Binding.Table == nil {
+		return view
+	}
+	copied := *view
+	table := *view.Binding.Table
+	table.Columns = make([]domain.TableColumnBinding, len(view.Binding.Table.Columns))
+	for i, col := range view.Binding.Table.Columns {
+		col.FormatSpec = nil
+		if col.Format != "" {
+			col.FormatSpec, _ = parseColumnFormat(col.Format)
+		}
+		table.Columns[i] = col
+	}
+	copied.Binding.Table = &table
+	return &copied
+}
@@ -0,0 +1,59 @@
+// file: internal/transport/http/router/view_format_test.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColumnFormat(t *testing.T) {
+	two := 2
+	zero := 0
+	for format, want := range map[string]*domain.ColumnFormat{
+		"date:yyyy-MM-dd":      {Type: domain.ColumnFormatDate, Pattern: "yyyy-MM-dd"},
+		"date:yyyy年M月d日 HH:mm": {Type: domain.ColumnFormatDate, Pattern: "yyyy年M月d日 HH:mm"},
+		"number":               {Type: domain.ColumnFormatNumber},
+		"number:2@de-DE":       {Type: domain.ColumnFormatNumber, Precision: &two, Locale: "de-DE"},
+		"percent:0":            {Type: domain.ColumnFormatPercent, Precision: &zero},
+		"currency:CNY:2":       {Type: domain.ColumnFormatCurrency, Currency: "CNY", Precision: &two},
+		"currency:USD@en-US":   {Type: domain.ColumnFormatCurrency, Currency: "USD", Locale: "en-US"},
+		"boolean:是/否":          {Type: domain.ColumnFormatBoolean, TrueLabel: "是", FalseLabel: "否"},
+		"boolean":              {Type: domain.ColumnFormatBoolean},
+	} {
+		got, err := parseColumnFormat(format)
+		require.NoError(t, err, format)
+		assert.Equal(t, want, got, format)
+	}
+
+	for _, format := range []string{
+		"money", "date", "date:yyyy-QQ", "date:--", "number:x", "number:11",
+		"currency:cny", "currency:CNY:-1", "boolean:是", "number@中文",
+	} {
+		_, err := parseColumnFormat(format)
+		assert.Error(t, err, format)
+	}
+}
+
+func TestColumnFormatsInViews(t *testing.T) {
+	view := &domain.ViewConfig{Binding: domain.ViewBinding{Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{
+		{Field: "sent_at", Format: "date:yyyy-MM-dd"},
+		{Field: "title"},
+	}}}}
+	require.NoError(t, validateViewConfig(view))
+
+	filled := withFormatSpecs(view)
+	require.NotNil(t, filled.Binding.Table.Columns[0].FormatSpec)
+	assert.Equal(t, "yyyy-MM-dd", filled.Binding.Table.Columns[0].FormatSpec.Pattern)
+	assert.Nil(t, filled.Binding.Table.Columns[1].FormatSpec)
+	assert.Nil(t, view.Binding.Table.Columns[0].FormatSpec, "原视图不被修改")
+
+	view.Binding.Table.Columns[1].Format = "uppercase"
+	err := validateViewConfig(view)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "title")
+	// 旧版本保存的格式仍按原字符串返回，不填写结构化格式
+	assert.Nil(t, withFormatSpecs(view).Binding.Table.Columns[1].FormatSpec)
+}
@@ -0,0 +1,149 @@
+// Package router file: internal/transport/http/router/view_preview_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/fieldguard"
+	"ArchiveAegis/internal/service/querydict"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultViewPreviewRows = 10
+	maxViewPreviewRows     = 50
+)
+
+// previewViewHandler 按一个尚未保存的视图查询实时数据，返回与客户端按该视图查询时相同的结果，供管理员在保存前检查绑定。
+// 请求体为 {"table": 表名, "view": 视图配置, "query": 可选的查询 (filters、where、search、page、size 等), "aliased": 是否应用展示名}。
+// 视图按保存时的规则校验；每次预览至多返回 50 行，结果中的 "view_config" 是 presentations 接口将返回的视图
+func previewViewHandler(registry map[string]port.DataSource, configService port.QueryAdminConfigService, dictionaries *querydict.Service, fieldGuard *fieldguard.Service, maxInValues int) gin.HandlerFunc {
+	type requestBody struct {
+		Table   string                 `json:"table" binding:"required"`
+		View    domain.ViewConfig      `json:"view"`
+		Query   map[string]interface{} `json:"query"`
+		Aliased bool                   `json:"aliased"`
+	}
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		var body requestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		view := &body.View
+		if err := validateViewConfig(view); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("视图无效: %v", err)})
+			return
+		}
+		if len(view.ConditionalFormats) > 0 {
+			if err := validateViewFields(c.Request.Context(), configService, bizName, body.Table, view); err != nil {
+				if errors.Is(err, port.ErrBizNotFound) {
+					_ = c.Error(err)
+					return
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("视图无效: %v", err)})
+				return
+			}
+		}
+		dataSource, exists := registry[bizName]
+		if !exists {
+			_ = c.Error(port.ErrBizNotFound)
+			return
+		}
+
+		// 只预览普通检索；保留键不允许由客户端传入
+		query := make(map[string]interface{}, len(body.Query)+2)
+		for k, v := range body.Query {
+			query[k] = v
+		}
+		if mode, _ := query[port.QueryModeKey].(string); mode != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("视图预览不支持查询模式 '%s'", mode)})
+			return
+		}
+		delete(query, port.QueryExplainKey)
+		delete(query, port.QueryMaxBytesKey)
+		delete(query, port.QueryRankKey)
+		query["table"] = body.Table
+		if size, ok := query["size"].(float64); !ok || size <= 0 {
+			query["size"] = float64(defaultViewPreviewRows)
+		} else if size > maxViewPreviewRows {
+			query["size"] = float64(maxViewPreviewRows)
+		}
+		if _, set := query[port.QueryDistinctKey]; !set && view.Distinct {
+			query[port.QueryDistinctKey] = true
+		}
+		projection, err := compileProjection(view.Projection)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// 以下步骤与 queryHandlerV1 按命名视图查询时相同
+		compiled, err := applyWhere(query)
+		if err == nil {
+			compiled, err = applyViewDefaults(compiled, view)
+		}
+		if err == nil {
+			err = checkInFilters(compiled, maxInValues)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		expanded, err := applySearch(c.Request.Context(), configService, bizName, compiled)
+		if err == nil {
+			expanded, err = applyDates(c.Request.Context(), configService, bizName, expanded)
+		}
+		if err != nil {
+			if errors.Is(err, errInvalidSearch) || errors.Is(err, errInvalidFilters) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		queryReq := port.QueryRequest{BizName: bizName, Query: expanded}
+		if dictionaries != nil {
+			queryReq.Query = dictionaries.Expand(bizName, expanded)
+		}
+
+		result, err := dataSource.Query(c.Request.Context(), queryReq)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if fieldGuard != nil {
+			if result, err = fieldGuard.Filter(c.Request.Context(), bizName, body.Table, result); err != nil {
+				_ = c.Error(err)
+				return
+			}
+		}
+		if _, explicit := query["fields_to_return"]; !explicit && len(projection) == 0 {
+			result = restrictResultFields(result, viewBindingFields(view))
+		}
+		if len(projection) > 0 {
+			result = applyProjection(result, projection)
+		} else if body.Aliased {
+			bizConfig, err := configService.GetBizQueryConfig(c.Request.Context(), bizName)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			result = applyFieldAliases(result, viewFieldAliases(bizConfig, body.Table, view))
+		}
+
+		data := make(map[string]interface{}, len(result.Data)+2)
+		for k, v := range result.Data {
+			data[k] = v
+		}
+		data["view"] = viewMetadata(view)
+		data["view_config"] = withFormatSpecs(view)
+		// 与 queryHandlerV1 的响应结构相同
+		c.JSON(http.StatusOK, &port.QueryResult{Data: data, Source: result.Source})
+	}
+}
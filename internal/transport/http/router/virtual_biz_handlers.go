@@ -0,0 +1,72 @@
+// Package router file: internal/transport/http/router/virtual_biz_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listVirtualBizHandler 列出所有虚拟业务组定义
+func listVirtualBizHandler(virtualBizService *virtualbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := pageParamsFromQuery(c)
+		if !ok {
+			return
+		}
+		defs, err := virtualBizService.List(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		respondList(c, defs, page)
+	}
+}
+
+// saveVirtualBizHandler 创建或更新一个虚拟业务组，保存后立即可被查询
+func saveVirtualBizHandler(virtualBizService *virtualbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Description string                `json:"description"`
+			Tables      []domain.VirtualTable `json:"tables" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		def := domain.VirtualBiz{
+			BizName:     c.Param("bizName"),
+			Description: payload.Description,
+			Tables:      payload.Tables,
+		}
+		if err := virtualBizService.Save(c.Request.Context(), def); err != nil {
+			if errors.Is(err, virtualbiz.ErrInvalidDefinition) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("虚拟业务组 '%s' 已保存并生效", def.BizName)})
+	}
+}
+
+// deleteVirtualBizHandler 删除一个虚拟业务组
+func deleteVirtualBizHandler(virtualBizService *virtualbiz.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bizName := c.Param("bizName")
+		if err := virtualBizService.Delete(c.Request.Context(), bizName); err != nil {
+			if errors.Is(err, virtualbiz.ErrVirtualBizNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("虚拟业务组 '%s' 已删除", bizName)})
+	}
+}
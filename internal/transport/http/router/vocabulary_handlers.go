@@ -0,0 +1,147 @@
+// Package router file: internal/transport/http/router/vocabulary_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/service/vocabulary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listTableVocabulariesHandler 返回表中各字段绑定的受控词表，不含上传的词条
+func listTableVocabulariesHandler(vocabularies *vocabulary.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bindings, err := vocabularies.List(c.Request.Context(), c.Param("bizName"), c.Param("tableName"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": bindings})
+	}
+}
+
+// getFieldVocabularyHandler 返回字段绑定的受控词表，包含上传的词条
+func getFieldVocabularyHandler(vocabularies *vocabulary.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, err := vocabularies.Get(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), c.Param("fieldName"))
+		if err != nil {
+			respondVocabularyError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": v})
+	}
+}
+
+// saveFieldVocabularyHandler 把字段绑定到受控词表。请求体为
+// {"kind": "list", "terms": [{"value": "...", "label": "..."}], "strict": true} 或
+// {"kind": "endpoint", "endpoint_url": "https://...", "strict": false}；
+// 也可以直接上传 Content-Type 为 text/csv 的词表，每行为 "值[,名称]"，严格模式由 ?strict=true 指定
+func saveFieldVocabularyHandler(vocabularies *vocabulary.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload struct {
+			Kind        string                  `json:"kind"`
+			Terms       []domain.VocabularyTerm `json:"terms"`
+			EndpointURL string                  `json:"endpoint_url"`
+			Strict      bool                    `json:"strict"`
+		}
+		if c.ContentType() == "text/csv" {
+			terms, err := parseVocabularyCSV(c.Request.Body)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			payload.Kind, payload.Terms = domain.VocabularyKindList, terms
+			payload.Strict, _ = strconv.ParseBool(c.Query("strict"))
+		} else if err := c.ShouldBindJSON(&payload); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		v, err := vocabularies.Save(c.Request.Context(), domain.FieldVocabulary{
+			BizName:     c.Param("bizName"),
+			TableName:   c.Param("tableName"),
+			FieldName:   c.Param("fieldName"),
+			Kind:        payload.Kind,
+			Terms:       payload.Terms,
+			EndpointURL: payload.EndpointURL,
+			Strict:      payload.Strict,
+		}, requestUserID(c))
+		if err != nil {
+			respondVocabularyError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": v})
+	}
+}
+
+// deleteFieldVocabularyHandler 解除字段与受控词表的绑定
+func deleteFieldVocabularyHandler(vocabularies *vocabulary.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fieldName := c.Param("fieldName")
+		if err := vocabularies.Delete(c.Request.Context(), c.Param("bizName"), c.Param("tableName"), fieldName); err != nil {
+			respondVocabularyError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": fmt.Sprintf("字段 '%s' 的受控词表已解除绑定", fieldName)})
+	}
+}
+
+// vocabularyLookupHandlerV1 为界面的下拉选项查找字段的受控词表，?q= 为关键字，?limit= 为返回的词条数
+func vocabularyLookupHandlerV1(vocabularies *vocabulary.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		terms, strict, err := vocabularies.Lookup(c.Request.Context(), c.Param("bizName"), c.Param("tableName"),
+			c.Param("fieldName"), c.Query("q"), limit)
+		if err != nil {
+			respondVocabularyError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": terms, "strict": strict})
+	}
+}
+
+// parseVocabularyCSV 解析上传的 CSV 词表，每行为 "值[,名称]"
+func parseVocabularyCSV(r io.Reader) ([]domain.VocabularyTerm, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	var terms []domain.VocabularyTerm
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return terms, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("无效请求: 解析 CSV 词表失败: %w", err)
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if len(record) > 2 {
+			return nil, fmt.Errorf("无效请求: CSV 词表的每行最多两列 (值, 名称)")
+		}
+		t := domain.VocabularyTerm{Value: record[0]}
+		if len(record) == 2 {
+			t.Label = record[1]
+		}
+		terms = append(terms, t)
+	}
+}
+
+func respondVocabularyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, vocabulary.ErrVocabularyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, vocabulary.ErrInvalidVocabulary), errors.Is(err, vocabulary.ErrValueNotInVocabulary):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, vocabulary.ErrVocabularyUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,16 @@
+// Package router file: internal/transport/http/router/warmup_handlers.go
+package router
+
+import (
+	"ArchiveAegis/internal/service/warmup"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// warmupStatusHandler 返回各业务组最近一次预热的结果
+func warmupStatusHandler(warmupService *warmup.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"enabled": warmupService.Enabled(), "data": warmupService.Status()})
+	}
+}
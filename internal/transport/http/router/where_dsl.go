@@ -0,0 +1,253 @@
+// Package router file: internal/transport/http/router/where_dsl.go
+package router
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// errInvalidWhere 表示 where 表达式不合法
+	errInvalidWhere = errors.New("无效的 where 表达式")
+	// errInvalidFilters 表示扁平 filters 的结构不合法
+	errInvalidFilters = errors.New("无效的 filters")
+)
+
+const (
+	// queryWhereKey 是查询中嵌套布尔表达式的键，由网关编译为扁平的 filters 后再交给数据源
+	queryWhereKey = "where"
+	// whereMaxDepth 是表达式允许的最大嵌套层数
+	whereMaxDepth = 8
+	// whereMaxLeaves 是表达式允许的最大叶子条件数
+	whereMaxLeaves = 50
+	// whereMaxTerms 是展开为析取范式后允许的最大子句数，防止 (a OR b) AND (c OR d) ... 组合爆炸
+	whereMaxTerms = 64
+)
+
+// whereOperators 是叶子条件中 "op" 可取的值，与数据源支持的运算符一致
+var whereOperators = map[string]bool{
+	"in":           true,
+	"is_null":      true,
+	"is_not_null":  true,
+	"is_empty":     true,
+	"is_not_empty": true,
+}
+
+// compileWhere 把形如 {"and": [{...}, {"or": [{...}, {"not": {...}}]}]} 的嵌套表达式编译为扁平的 filters。
+// 扁平 filters 只能表达按 SQL 优先级 (AND 先于 OR) 连接的条件序列，
+// 因此表达式先展开为析取范式：子句内的叶子以 AND 连接，子句之间以 OR 连接；
+// "not" 按德摩根定律下推到叶子，编译为叶子的 negate 标记。
+// 叶子条件的格式与 filters 数组的元素相同，但不能带 "logic"，连接关系完全由表达式结构决定。
+// 返回编译后的 filters 与析取范式的子句数。
+func compileWhere(expr interface{}) ([]interface{}, int, error) {
+	leaves := 0
+	terms, err := expandWhere(expr, 1, false, &leaves)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := make([]interface{}, 0, leaves)
+	for i, term := range terms {
+		for j, leaf := range term {
+			filter := make(map[string]interface{}, len(leaf)+1)
+			for k, v := range leaf {
+				filter[k] = v
+			}
+			switch {
+			case j < len(term)-1:
+				filter["logic"] = "AND"
+			case i < len(terms)-1:
+				filter["logic"] = "OR"
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, len(terms), nil
+}
+
+// expandWhere 递归地把表达式展开为析取范式，每个子句是一组以 AND 连接的叶子条件。
+// negated 表示该节点处于奇数层 "not" 之下：此时 and 与 or 互换，叶子条件取反
+func expandWhere(expr interface{}, depth int, negated bool, leaves *int) ([][]map[string]interface{}, error) {
+	if depth > whereMaxDepth {
+		return nil, fmt.Errorf("%w: 嵌套层数超过 %d", errInvalidWhere, whereMaxDepth)
+	}
+	node, ok := expr.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: 表达式节点必须是 JSON 对象", errInvalidWhere)
+	}
+
+	andOperands, isAnd := node["and"]
+	orOperands, isOr := node["or"]
+	notOperand, isNot := node["not"]
+	switch {
+	case isAnd && isOr, isAnd && isNot, isOr && isNot:
+		return nil, fmt.Errorf("%w: 同一节点只能包含 'and'、'or'、'not' 之一", errInvalidWhere)
+	case isAnd || isOr || isNot:
+		if len(node) != 1 {
+			return nil, fmt.Errorf("%w: 'and'/'or'/'not' 节点不能包含其它键", errInvalidWhere)
+		}
+	default:
+		return expandWhereLeaf(node, negated, leaves)
+	}
+	if isNot {
+		return expandWhere(notOperand, depth+1, !negated, leaves)
+	}
+
+	key, operands := "and", andOperands
+	if isOr {
+		key, operands = "or", orOperands
+	}
+	list, ok := operands.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%w: '%s' 必须是非空数组", errInvalidWhere, key)
+	}
+
+	// NOT (A AND B) = NOT A OR NOT B，NOT (A OR B) = NOT A AND NOT B
+	disjunction := isOr != negated
+	var result [][]map[string]interface{}
+	for i, operand := range list {
+		child, err := expandWhere(operand, depth+1, negated, leaves)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case disjunction:
+			result = append(result, child...)
+		case i == 0:
+			result = child
+		default:
+			// (A1 OR A2) AND (B1 OR B2) = A1B1 OR A1B2 OR A2B1 OR A2B2
+			product := make([][]map[string]interface{}, 0, len(result)*len(child))
+			for _, left := range result {
+				for _, right := range child {
+					term := make([]map[string]interface{}, 0, len(left)+len(right))
+					term = append(term, left...)
+					product = append(product, append(term, right...))
+				}
+			}
+			result = product
+		}
+		if len(result) > whereMaxTerms {
+			return nil, fmt.Errorf("%w: 表达式过于复杂，展开后超过 %d 个子句", errInvalidWhere, whereMaxTerms)
+		}
+	}
+	return result, nil
+}
+
+// expandWhereLeaf 校验叶子条件，返回只含该条件的单个子句；negated 为 true 时返回取反后的条件副本
+func expandWhereLeaf(leaf map[string]interface{}, negated bool, leaves *int) ([][]map[string]interface{}, error) {
+	if field, ok := leaf["field"].(string); !ok || field == "" {
+		return nil, fmt.Errorf("%w: 叶子条件缺少 'field' 或类型不正确", errInvalidWhere)
+	}
+	if _, ok := leaf["logic"]; ok {
+		return nil, fmt.Errorf("%w: 叶子条件不能包含 'logic'，请用 'and'/'or' 组合条件", errInvalidWhere)
+	}
+	if op, ok := leaf["op"]; ok {
+		if s, _ := op.(string); !whereOperators[s] {
+			return nil, fmt.Errorf("%w: 不支持的过滤运算符 '%v'", errInvalidWhere, op)
+		}
+	}
+	negate := false
+	if v, ok := leaf[port.FilterNegateKey]; ok {
+		if negate, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("%w: 叶子条件的 '%s' 必须是布尔值", errInvalidWhere, port.FilterNegateKey)
+		}
+	}
+	if *leaves++; *leaves > whereMaxLeaves {
+		return nil, fmt.Errorf("%w: 叶子条件超过 %d 个", errInvalidWhere, whereMaxLeaves)
+	}
+	if negated {
+		flipped := make(map[string]interface{}, len(leaf)+1)
+		for k, v := range leaf {
+			flipped[k] = v
+		}
+		if negate {
+			delete(flipped, port.FilterNegateKey)
+		} else {
+			flipped[port.FilterNegateKey] = true
+		}
+		leaf = flipped
+	}
+	return [][]map[string]interface{}{{leaf}}, nil
+}
+
+// filterClauses 按 SQL 优先级 (AND 先于 OR) 把扁平 filters 拆分为以 OR 连接的子句，子句中的条件不含 logic。
+// 没有 filters 时返回一个空子句
+func filterClauses(raw interface{}) ([][]map[string]interface{}, error) {
+	if raw == nil {
+		return [][]map[string]interface{}{{}}, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: filters 必须是数组", errInvalidFilters)
+	}
+	clauses := [][]map[string]interface{}{{}}
+	for i, item := range list {
+		filter, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: filters 数组的第 %d 个元素不是一个有效的JSON对象", errInvalidFilters, i)
+		}
+		leaf := make(map[string]interface{}, len(filter))
+		for k, v := range filter {
+			if k != "logic" {
+				leaf[k] = v
+			}
+		}
+		last := len(clauses) - 1
+		clauses[last] = append(clauses[last], leaf)
+		if logic, _ := filter["logic"].(string); strings.EqualFold(logic, "OR") && i < len(list)-1 {
+			clauses = append(clauses, []map[string]interface{}{})
+		}
+	}
+	return clauses, nil
+}
+
+// applyWhere 把查询中的 where 表达式编译并替换为 filters，原查询不被修改。
+// 查询不含 where 时原样返回；where 与 filters 不能同时出现。
+func applyWhere(query map[string]interface{}) (map[string]interface{}, error) {
+	expr, ok := query[queryWhereKey]
+	if !ok {
+		return query, nil
+	}
+	if _, hasFilters := query["filters"]; hasFilters {
+		return nil, fmt.Errorf("%w: 'where' 与 'filters' 不能同时使用", errInvalidWhere)
+	}
+	filters, _, err := compileWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	compiled := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		if k != queryWhereKey {
+			compiled[k] = v
+		}
+	}
+	compiled["filters"] = filters
+	return compiled, nil
+}
+
+// compileWhereHandler 只校验并编译 where 表达式，不执行查询。
+// 客户端可借此检查表达式，或把编译结果作为 filters 保存 (例如用于分享链接)
+func compileWhereHandler() gin.HandlerFunc {
+	type RequestBody struct {
+		Where interface{} `json:"where" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		var reqBody RequestBody
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		filters, terms, err := compileWhere(reqBody.Where)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"filters": filters, "terms": terms}})
+	}
+}
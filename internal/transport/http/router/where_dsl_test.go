@@ -0,0 +1,130 @@
+// file: internal/transport/http/router/where_dsl_test.go
+package router
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeWhere(t *testing.T, raw string) interface{} {
+	t.Helper()
+	var expr interface{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &expr))
+	return expr
+}
+
+func TestCompileWhere_ExpandsToDisjunctiveNormalForm(t *testing.T) {
+	// a AND (b OR c) = (a AND b) OR (a AND c)
+	filters, terms, err := compileWhere(decodeWhere(t, `{"and":[
+		{"field":"author","value":"鲁迅"},
+		{"or":[{"field":"year","value":"1921"},{"field":"title","value":"呐喊","fuzzy":true}]}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, terms)
+	require.Len(t, filters, 4)
+
+	want := []struct{ field, logic string }{
+		{"author", "AND"}, {"year", "OR"}, {"author", "AND"}, {"title", ""},
+	}
+	for i, w := range want {
+		f := filters[i].(map[string]interface{})
+		assert.Equal(t, w.field, f["field"])
+		logic, _ := f["logic"].(string)
+		assert.Equal(t, w.logic, logic, "第 %d 个条件的连接符", i)
+	}
+	assert.Equal(t, true, filters[3].(map[string]interface{})["fuzzy"], "叶子的其它键原样保留")
+}
+
+func TestCompileWhere_SingleLeaf(t *testing.T) {
+	filters, terms, err := compileWhere(decodeWhere(t, `{"field":"author","op":"is_not_null"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, terms)
+	require.Len(t, filters, 1)
+	assert.NotContains(t, filters[0], "logic")
+}
+
+func TestCompileWhere_NotPushesDownToLeaves(t *testing.T) {
+	// NOT (a AND (b OR NOT c)) = NOT a OR (NOT b AND c)
+	filters, terms, err := compileWhere(decodeWhere(t, `{"not":{"and":[
+		{"field":"a","value":"1"},
+		{"or":[{"field":"b","value":"2"},{"not":{"field":"c","value":"3"}}]}
+	]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, terms)
+	require.Len(t, filters, 3)
+
+	want := []struct {
+		field, logic string
+		negate       bool
+	}{
+		{"a", "OR", true}, {"b", "AND", true}, {"c", "", false},
+	}
+	for i, w := range want {
+		f := filters[i].(map[string]interface{})
+		assert.Equal(t, w.field, f["field"])
+		logic, _ := f["logic"].(string)
+		assert.Equal(t, w.logic, logic, "第 %d 个条件的连接符", i)
+		negate, _ := f["negate"].(bool)
+		assert.Equal(t, w.negate, negate, "第 %d 个条件的取反标记", i)
+	}
+
+	// 叶子自带的 negate 与外层 not 相互抵消
+	filters, _, err = compileWhere(decodeWhere(t, `{"not":{"field":"a","value":"1","negate":true}}`))
+	require.NoError(t, err)
+	assert.NotContains(t, filters[0], "negate")
+}
+
+func TestCompileWhere_Validation(t *testing.T) {
+	cases := map[string]string{
+		"空 and":      `{"and":[]}`,
+		"and 与 or":   `{"and":[{"field":"a"}],"or":[{"field":"b"}]}`,
+		"多余的键":       `{"and":[{"field":"a"}],"field":"b"}`,
+		"缺少 field":   `{"value":"x"}`,
+		"叶子带 logic":  `{"field":"a","logic":"OR"}`,
+		"未知运算符":      `{"field":"a","op":"drop"}`,
+		"非对象节点":      `{"or":["a"]}`,
+		"not 与 and":  `{"not":{"field":"a"},"and":[{"field":"b"}]}`,
+		"negate 非布尔": `{"field":"a","negate":"yes"}`,
+	}
+	for name, raw := range cases {
+		_, _, err := compileWhere(decodeWhere(t, raw))
+		assert.ErrorIs(t, err, errInvalidWhere, name)
+	}
+
+	// 8 组 (x OR y) 相与会展开为 256 个子句
+	group := `{"or":[{"field":"x"},{"field":"y"}]}`
+	raw := `{"and":[` + group
+	for i := 1; i < 8; i++ {
+		raw += "," + group
+	}
+	_, _, err := compileWhere(decodeWhere(t, raw+`]}`))
+	assert.ErrorIs(t, err, errInvalidWhere, "组合爆炸应被拒绝")
+
+	deep := `{"field":"a"}`
+	for i := 0; i < whereMaxDepth; i++ {
+		deep = `{"and":[` + deep + `]}`
+	}
+	_, _, err = compileWhere(decodeWhere(t, deep))
+	assert.ErrorIs(t, err, errInvalidWhere, "嵌套过深应被拒绝")
+}
+
+func TestApplyWhere(t *testing.T) {
+	query := map[string]interface{}{"table": "books", "where": decodeWhere(t, `{"field":"a","value":"1"}`)}
+	compiled, err := applyWhere(query)
+	require.NoError(t, err)
+	assert.NotContains(t, compiled, "where")
+	assert.Len(t, compiled["filters"], 1)
+	assert.Equal(t, "books", compiled["table"])
+	assert.Contains(t, query, "where", "原查询不应被修改")
+
+	plain := map[string]interface{}{"filters": []interface{}{}}
+	same, err := applyWhere(plain)
+	require.NoError(t, err)
+	assert.Equal(t, plain, same)
+
+	_, err = applyWhere(map[string]interface{}{"where": query["where"], "filters": []interface{}{}})
+	assert.ErrorIs(t, err, errInvalidWhere)
+}
@@ -0,0 +1,31 @@
+// Package testsupport 提供用于集成测试的辅助设施：一个可编排的内存 DataSource，
+// 以及一个启动完整 Gin 路由器的 httptest 测试夹具，使下游团队与插件作者无需真实插件即可针对网关编写测试。
+//
+// file: pkg/testsupport/fixture.go
+package testsupport
+
+import (
+	"ArchiveAegis/pkg/testsupport/fixture"
+	"database/sql"
+	"testing"
+)
+
+// FakeDataSource 是一个内存中的 port.DataSource 实现，见 fixture.FakeDataSource
+type FakeDataSource = fixture.FakeDataSource
+
+// NewFakeDataSource 创建一个没有任何表的 FakeDataSource
+func NewFakeDataSource() *FakeDataSource {
+	return fixture.NewFakeDataSource()
+}
+
+// NewSystemDB 在测试临时目录中创建已初始化全部平台表的系统库，见 fixture.NewSystemDB
+func NewSystemDB(t testing.TB) *sql.DB {
+	t.Helper()
+	return fixture.NewSystemDB(t)
+}
+
+// OpenSystemDB 在 path 创建已初始化全部平台表的系统库，见 fixture.OpenSystemDB
+func OpenSystemDB(t testing.TB, path string) *sql.DB {
+	t.Helper()
+	return fixture.OpenSystemDB(t, path)
+}
@@ -0,0 +1,354 @@
+// Package fixture 提供不依赖任何服务包的测试夹具：已初始化平台表的系统库与一个可编排的内存 DataSource。
+// testsupport 装配全部服务，服务包自身的测试无法导入它，因此这些夹具放在本包中，由 testsupport 重新导出。
+//
+// file: pkg/testsupport/fixture/fake_datasource.go
+package fixture
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FakeDataSource 是一个内存中的 port.DataSource 实现。
+// 表结构、数据行以及各方法返回的错误均可预先编排；收到的所有请求都会被记录，供断言使用。
+//
+// 普通查询只支持等值过滤以及 is_null / is_not_null / is_empty / is_not_empty 运算符，
+// 所有条件均按 AND 处理。返回的数字与经 gRPC 传输后一致，均为 float64。
+type FakeDataSource struct {
+	// QueryFunc / MutateFunc 非空时完全接管对应方法，适合需要自定义结果的场景
+	QueryFunc  func(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error)
+	MutateFunc func(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error)
+	// AdminMutateFunc 非空时处理管理操作 (port.AdminMutator)，为空时管理操作返回 port.ErrAdminMutateUnsupported
+	AdminMutateFunc func(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error)
+
+	mu        sync.Mutex
+	tables    map[string]*fakeTable
+	queryErr  error
+	tableErrs map[string]error
+	mutateErr error
+	schemaErr error
+	healthErr error
+	queries   []port.QueryRequest
+	mutations []port.MutateRequest
+}
+
+type fakeTable struct {
+	fields []port.FieldDescription
+	rows   []map[string]interface{}
+}
+
+// NewFakeDataSource 创建一个没有任何表的 FakeDataSource
+func NewFakeDataSource() *FakeDataSource {
+	return &FakeDataSource{tables: make(map[string]*fakeTable)}
+}
+
+// WithTable 定义（或替换）一张表的结构与数据，返回自身以便链式调用
+func (f *FakeDataSource) WithTable(name string, fields []port.FieldDescription, rows ...map[string]interface{}) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		copied[i] = copyRow(row)
+	}
+	f.tables[name] = &fakeTable{fields: fields, rows: copied}
+	return f
+}
+
+// FailQuery 使后续的 Query 调用返回 err；传入 nil 恢复正常
+func (f *FakeDataSource) FailQuery(err error) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queryErr = err
+	return f
+}
+
+// FailQueryOn 使后续针对 table 的 Query 调用返回 err，例如以 port.ErrPermissionDenied 模拟无权检索的表；传入 nil 恢复正常
+func (f *FakeDataSource) FailQueryOn(table string, err error) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tableErrs == nil {
+		f.tableErrs = make(map[string]error)
+	}
+	if err == nil {
+		delete(f.tableErrs, table)
+	} else {
+		f.tableErrs[table] = err
+	}
+	return f
+}
+
+// FailMutate 使后续的 Mutate 调用返回 err；传入 nil 恢复正常
+func (f *FakeDataSource) FailMutate(err error) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mutateErr = err
+	return f
+}
+
+// FailSchema 使后续的 GetSchema 调用返回 err；传入 nil 恢复正常
+func (f *FakeDataSource) FailSchema(err error) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.schemaErr = err
+	return f
+}
+
+// FailHealthCheck 使后续的 HealthCheck 调用返回 err；传入 nil 恢复正常
+func (f *FakeDataSource) FailHealthCheck(err error) *FakeDataSource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthErr = err
+	return f
+}
+
+// Queries 返回迄今收到的全部查询请求
+func (f *FakeDataSource) Queries() []port.QueryRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]port.QueryRequest(nil), f.queries...)
+}
+
+// Mutations 返回迄今收到的全部写操作请求
+func (f *FakeDataSource) Mutations() []port.MutateRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]port.MutateRequest(nil), f.mutations...)
+}
+
+// Type 返回适配器的类型标识符
+func (f *FakeDataSource) Type() string {
+	return "fake"
+}
+
+// Query 按编排的数据执行查询，支持普通分页查询与按主键读取 (record) 模式
+func (f *FakeDataSource) Query(ctx context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, req)
+	queryErr := f.queryErr
+	if tableName, _ := req.Query["table"].(string); queryErr == nil {
+		queryErr = f.tableErrs[tableName]
+	}
+	f.mu.Unlock()
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	if f.QueryFunc != nil {
+		return f.QueryFunc(ctx, req)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tableName, _ := req.Query["table"].(string)
+	table, exists := f.tables[tableName]
+	if !exists {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+
+	if mode, _ := req.Query[port.QueryModeKey].(string); mode == port.QueryModeRecord {
+		recordID, _ := req.Query["id"].(string)
+		pk := primaryKeyOf(table.fields)
+		var record interface{}
+		matches := 0
+		for _, row := range table.rows {
+			if fmt.Sprintf("%v", row[pk]) == recordID {
+				if record == nil {
+					record = copyRow(row)
+				}
+				matches++
+			}
+		}
+		return &port.QueryResult{
+			Data:   map[string]interface{}{"record": record, "matches": float64(matches)},
+			Source: f.Type(),
+		}, nil
+	}
+
+	var matched []map[string]interface{}
+	for _, row := range table.rows {
+		ok, err := matchesFilters(row, req.Query["filters"])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+
+	page, size := 1, 50
+	if p, ok := req.Query["page"].(float64); ok && p >= 1 {
+		page = int(p)
+	}
+	if s, ok := req.Query["size"].(float64); ok && s >= 1 {
+		size = int(s)
+	}
+	items := make([]interface{}, 0, size)
+	for i := (page - 1) * size; i < len(matched) && len(items) < size; i++ {
+		items = append(items, copyRow(matched[i]))
+	}
+	return &port.QueryResult{
+		Data:   map[string]interface{}{"items": items, "total": float64(len(matched))},
+		Source: f.Type(),
+	}, nil
+}
+
+// Mutate 记录写操作请求。create 操作会将 payload 中的 data 追加到 table_name 指定的表中，其它操作不修改数据。
+func (f *FakeDataSource) Mutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	f.mu.Lock()
+	f.mutations = append(f.mutations, req)
+	mutateErr := f.mutateErr
+	f.mu.Unlock()
+	if mutateErr != nil {
+		return nil, mutateErr
+	}
+	if f.MutateFunc != nil {
+		return f.MutateFunc(ctx, req)
+	}
+	if !port.IsPublicMutateOp(req.Operation) {
+		return nil, fmt.Errorf("不支持的写操作类型: '%s'", req.Operation)
+	}
+
+	var rowsAffected float64
+	if req.Operation == "create" {
+		tableName, _ := req.Payload["table_name"].(string)
+		data, _ := req.Payload["data"].(map[string]interface{})
+		f.mu.Lock()
+		table, exists := f.tables[tableName]
+		if exists && data != nil {
+			table.rows = append(table.rows, copyRow(data))
+			rowsAffected = 1
+		}
+		f.mu.Unlock()
+		if !exists {
+			return nil, port.ErrTableNotFoundInBiz
+		}
+	}
+	return &port.MutateResult{
+		Data:   map[string]interface{}{"success": true, "rows_affected": rowsAffected},
+		Source: f.Type(),
+	}, nil
+}
+
+// AdminMutate 实现 port.AdminMutator，记录请求后交由 AdminMutateFunc 处理
+func (f *FakeDataSource) AdminMutate(ctx context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+	f.mu.Lock()
+	f.mutations = append(f.mutations, req)
+	mutateErr := f.mutateErr
+	f.mu.Unlock()
+	if mutateErr != nil {
+		return nil, mutateErr
+	}
+	if f.AdminMutateFunc == nil {
+		return nil, port.ErrAdminMutateUnsupported
+	}
+	return f.AdminMutateFunc(ctx, req)
+}
+
+// GetSchema 返回编排的表结构，TableName 非空时只返回该表
+func (f *FakeDataSource) GetSchema(_ context.Context, req port.SchemaRequest) (*port.SchemaResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.schemaErr != nil {
+		return nil, f.schemaErr
+	}
+	result := &port.SchemaResult{Tables: make(map[string][]port.FieldDescription)}
+	for name, table := range f.tables {
+		if req.TableName != "" && name != req.TableName {
+			continue
+		}
+		result.Tables[name] = append([]port.FieldDescription(nil), table.fields...)
+	}
+	if req.TableName != "" && len(result.Tables) == 0 {
+		return nil, port.ErrTableNotFoundInBiz
+	}
+	return result, nil
+}
+
+// PhysicalSchema 实现 port.PhysicalSchemaReader，返回编排的各表列名 (按字母排序)
+func (f *FakeDataSource) PhysicalSchema(context.Context, string) (map[string][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.schemaErr != nil {
+		return nil, f.schemaErr
+	}
+	tables := make(map[string][]string, len(f.tables))
+	for name, table := range f.tables {
+		columns := make([]string, 0, len(table.fields))
+		for _, field := range table.fields {
+			columns = append(columns, field.Name)
+		}
+		sort.Strings(columns)
+		tables[name] = columns
+	}
+	return tables, nil
+}
+
+// HealthCheck 返回编排的健康检查错误
+func (f *FakeDataSource) HealthCheck(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.healthErr
+}
+
+// matchesFilters 判断一行是否满足过滤条件，条件之间按 logic ("and" 或 "or"，缺省为 "and") 从左到右连接
+func matchesFilters(row map[string]interface{}, rawFilters interface{}) (bool, error) {
+	if rawFilters == nil {
+		return true, nil
+	}
+	filters, ok := rawFilters.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("无效请求: 'filters' 必须是一个数组")
+	}
+	matched := true
+	for i, rf := range filters {
+		filter, ok := rf.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("无效请求: filters 数组的第 %d 个元素不是一个有效的JSON对象", i)
+		}
+		field, _ := filter["field"].(string)
+		value, present := row[field]
+		op, _ := filter["op"].(string)
+		switch op {
+		case "is_null":
+			ok = !present || value == nil
+		case "is_not_null":
+			ok = present && value != nil
+		case "is_empty":
+			ok = present && value == ""
+		case "is_not_empty":
+			ok = present && value != nil && value != ""
+		case "":
+			ok = present && fmt.Sprintf("%v", value) == fmt.Sprintf("%v", filter["value"])
+		default:
+			return false, fmt.Errorf("无效请求: 不支持的过滤运算符 '%s'", op)
+		}
+		// 与前面的条件按 logic 连接，从左到右求值
+		if logic, _ := filter["logic"].(string); i > 0 && strings.EqualFold(logic, "or") {
+			matched = matched || ok
+		} else {
+			matched = matched && ok
+		}
+	}
+	return matched, nil
+}
+
+// primaryKeyOf 返回第一个主键字段名，未声明主键时使用 "id"
+func primaryKeyOf(fields []port.FieldDescription) string {
+	for _, f := range fields {
+		if f.IsPrimary {
+			return f.Name
+		}
+	}
+	return "id"
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		out[k] = v
+	}
+	return out
+}
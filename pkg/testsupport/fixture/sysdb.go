@@ -0,0 +1,34 @@
+// file: pkg/testsupport/fixture/sysdb.go
+package fixture
+
+import (
+	"ArchiveAegis/internal/service"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewSystemDB 在测试临时目录中创建系统库 auth.db，并初始化全部平台表。
+// 外键约束已开启，级联删除与置空路径因此也会被测试覆盖；测试结束时连接会被自动关闭。
+func NewSystemDB(t testing.TB) *sql.DB {
+	t.Helper()
+	return OpenSystemDB(t, filepath.Join(t.TempDir(), "auth.db"))
+}
+
+// OpenSystemDB 与 NewSystemDB 相同，但系统库位于 path，供需要与库文件同目录布局的测试使用
+func OpenSystemDB(t testing.TB, path string) *sql.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("fixture: 打开系统库失败: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := service.InitPlatformTables(db); err != nil {
+		t.Fatalf("fixture: 初始化系统表失败: %v", err)
+	}
+	return db
+}
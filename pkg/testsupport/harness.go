@@ -0,0 +1,540 @@
+// Package testsupport file: pkg/testsupport/harness.go
+package testsupport
+
+import (
+	"ArchiveAegis/internal/aegmiddleware"
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"ArchiveAegis/internal/service/abuse"
+	"ArchiveAegis/internal/service/accesslog"
+	"ArchiveAegis/internal/service/admin_config"
+	"ArchiveAegis/internal/service/analytics"
+	"ArchiveAegis/internal/service/annotations"
+	"ArchiveAegis/internal/service/approvals"
+	"ArchiveAegis/internal/service/bizclone"
+	"ArchiveAegis/internal/service/bizrename"
+	"ArchiveAegis/internal/service/bookmarks"
+	"ArchiveAegis/internal/service/bootreport"
+	"ArchiveAegis/internal/service/capture"
+	"ArchiveAegis/internal/service/catalog"
+	"ArchiveAegis/internal/service/colstats"
+	"ArchiveAegis/internal/service/compliance"
+	"ArchiveAegis/internal/service/configtemplates"
+	"ArchiveAegis/internal/service/datadict"
+	"ArchiveAegis/internal/service/datasubject"
+	"ArchiveAegis/internal/service/demo"
+	"ArchiveAegis/internal/service/diagnostics"
+	"ArchiveAegis/internal/service/digest"
+	"ArchiveAegis/internal/service/doctor"
+	"ArchiveAegis/internal/service/embed"
+	"ArchiveAegis/internal/service/exports"
+	"ArchiveAegis/internal/service/fieldguard"
+	"ArchiveAegis/internal/service/fulltext"
+	"ArchiveAegis/internal/service/identpolicy"
+	"ArchiveAegis/internal/service/iiif"
+	"ArchiveAegis/internal/service/impact"
+	"ArchiveAegis/internal/service/jobs"
+	"ArchiveAegis/internal/service/licensing"
+	"ArchiveAegis/internal/service/links"
+	"ArchiveAegis/internal/service/oaipmh"
+	"ArchiveAegis/internal/service/ownership"
+	"ArchiveAegis/internal/service/penalties"
+	"ArchiveAegis/internal/service/piiscan"
+	"ArchiveAegis/internal/service/plugin_manager"
+	"ArchiveAegis/internal/service/policy"
+	"ArchiveAegis/internal/service/preferences"
+	"ArchiveAegis/internal/service/provenance"
+	"ArchiveAegis/internal/service/querydict"
+	"ArchiveAegis/internal/service/queryext"
+	"ArchiveAegis/internal/service/querytemplates"
+	"ArchiveAegis/internal/service/readsplitbiz"
+	"ArchiveAegis/internal/service/replicacheck"
+	"ArchiveAegis/internal/service/replication"
+	"ArchiveAegis/internal/service/resultdiff"
+	"ArchiveAegis/internal/service/semantic"
+	"ArchiveAegis/internal/service/sessions"
+	"ArchiveAegis/internal/service/sharding"
+	"ArchiveAegis/internal/service/sharelinks"
+	"ArchiveAegis/internal/service/siem"
+	"ArchiveAegis/internal/service/signing"
+	"ArchiveAegis/internal/service/sitemap"
+	"ArchiveAegis/internal/service/slo"
+	"ArchiveAegis/internal/service/snapshot"
+	"ArchiveAegis/internal/service/standby"
+	"ArchiveAegis/internal/service/suggest"
+	"ArchiveAegis/internal/service/telemetry"
+	"ArchiveAegis/internal/service/typegen"
+	"ArchiveAegis/internal/service/updates"
+	"ArchiveAegis/internal/service/uploads"
+	"ArchiveAegis/internal/service/virtualbiz"
+	"ArchiveAegis/internal/service/vocabulary"
+	"ArchiveAegis/internal/service/warmup"
+	"ArchiveAegis/internal/transport/http/router"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	harnessAdminUser     = "testsupport-admin"
+	harnessAdminPassword = "testsupport-password"
+)
+
+// Harness 以与 cmd/gateway 相同的方式装配全部服务，并通过 httptest 启动完整的 Gin 路由器。
+// 系统库位于测试临时目录中，测试结束时服务器与数据库会被自动关闭。
+//
+// 数据源通过 RegisterDataSource 直接放入注册表，应在发起请求前完成注册。
+type Harness struct {
+	Server      *httptest.Server
+	DB          *sql.DB
+	Registry    map[string]port.DataSource
+	AdminConfig *admin_config.AdminConfigServiceImpl
+	// Policies 是授权钩子服务，测试可以向其注册进程内的钩子
+	Policies *policy.Service
+
+	t          testing.TB
+	adminOnce  sync.Once
+	adminToken string
+}
+
+// NewHarness 创建并启动一个测试夹具
+func NewHarness(t testing.TB) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	db := OpenSystemDB(t, filepath.Join(dir, "auth.db"))
+
+	adminConfig, err := admin_config.NewAdminConfigServiceImpl(db, 1000, time.Minute)
+	if err != nil {
+		t.Fatalf("testsupport: 创建配置服务失败: %v", err)
+	}
+	registry := make(map[string]port.DataSource)
+	closers := make([]io.Closer, 0)
+	pm, err := plugin_manager.NewPluginManager(db, dir, nil, filepath.Join(dir, "plugins"), registry, &closers)
+	if err != nil {
+		t.Fatalf("testsupport: 创建插件管理器失败: %v", err)
+	}
+	replicationService, err := replication.NewService(db, registry)
+	if err != nil {
+		t.Fatalf("testsupport: 创建同步服务失败: %v", err)
+	}
+	replicaCheck, err := replicacheck.NewService(adminConfig, registry, replicationService)
+	if err != nil {
+		t.Fatalf("testsupport: 创建副本比对服务失败: %v", err)
+	}
+	oaiPMHService, err := oaipmh.NewService(db, registry, oaipmh.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建 OAI-PMH 服务失败: %v", err)
+	}
+	virtualBizService, err := virtualbiz.NewService(db, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建虚拟业务组服务失败: %v", err)
+	}
+	shardedBizService, err := sharding.NewService(db, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建分片业务组服务失败: %v", err)
+	}
+	readSplitService, err := readsplitbiz.NewService(db, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建读写分离业务组服务失败: %v", err)
+	}
+	warmupService, err := warmup.NewService(adminConfig, warmup.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建预热服务失败: %v", err)
+	}
+	uploadService, err := uploads.NewService(db, filepath.Join(t.TempDir(), "uploads"), uploads.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建分块上传服务失败: %v", err)
+	}
+	configTemplates, err := configtemplates.NewService(db, adminConfig, registry)
+	if err != nil {
+		t.Fatalf("testsupport: 创建配置模板服务失败: %v", err)
+	}
+	queryDictionaries, err := querydict.NewService(db)
+	if err != nil {
+		t.Fatalf("testsupport: 创建检索词典服务失败: %v", err)
+	}
+	dataDictionary, err := datadict.NewService(db, adminConfig)
+	if err != nil {
+		t.Fatalf("testsupport: 创建数据字典服务失败: %v", err)
+	}
+	vocabularies, err := vocabulary.NewService(db, adminConfig, vocabulary.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建受控词表服务失败: %v", err)
+	}
+	bizClone, err := bizclone.NewService(adminConfig, registry)
+	if err != nil {
+		t.Fatalf("testsupport: 创建配置复制服务失败: %v", err)
+	}
+	bizRename, err := bizrename.NewService(db, adminConfig, registry, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建业务组重命名服务失败: %v", err)
+	}
+	queryTemplates, err := querytemplates.NewService(db, adminConfig)
+	if err != nil {
+		t.Fatalf("testsupport: 创建查询模板服务失败: %v", err)
+	}
+	resultDiff, err := resultdiff.NewService(registry, resultdiff.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建结果比对服务失败: %v", err)
+	}
+	recordLinks, err := links.NewService(db, registry, adminConfig)
+	if err != nil {
+		t.Fatalf("testsupport: 创建记录关联服务失败: %v", err)
+	}
+	accessLog, err := accesslog.NewService(db, registry, adminConfig, accesslog.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建记录访问日志服务失败: %v", err)
+	}
+	complianceService, err := compliance.NewService(db, compliance.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建合规导出服务失败: %v", err)
+	}
+	securityEvents, err := siem.NewService(db, siem.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建安全事件转发服务失败: %v", err)
+	}
+	authorizationHooks, err := policy.NewService(policy.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建授权钩子服务失败: %v", err)
+	}
+	jobService, err := jobs.NewService(db)
+	if err != nil {
+		t.Fatalf("testsupport: 创建后台任务服务失败: %v", err)
+	}
+	t.Cleanup(func() { _ = jobService.Shutdown(context.Background()) })
+	fullTextService, err := fulltext.NewService(adminConfig, registry, jobService)
+	if err != nil {
+		t.Fatalf("testsupport: 创建全文检索服务失败: %v", err)
+	}
+	snapshotService, err := snapshot.NewService(db, dir, adminConfig, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建快照服务失败: %v", err)
+	}
+	diagnosticsService, err := diagnostics.NewService(db)
+	if err != nil {
+		t.Fatalf("testsupport: 创建诊断服务失败: %v", err)
+	}
+	doctorService, err := doctor.NewService(doctor.Options{
+		RootDir:     dir,
+		ConfigPath:  filepath.Join(dir, "config.yaml"),
+		InstanceDir: dir,
+		DBPath:      filepath.Join(dir, "auth.db"),
+		InstallDir:  filepath.Join(dir, "plugins"),
+	})
+	if err != nil {
+		t.Fatalf("testsupport: 创建自检服务失败: %v", err)
+	}
+	demoService, err := demo.NewService(adminConfig, dir)
+	if err != nil {
+		t.Fatalf("testsupport: 创建演示数据服务失败: %v", err)
+	}
+	usageAnalytics, err := analytics.NewService(db, analytics.Options{Enabled: true, CaptureZeroResults: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建检索统计服务失败: %v", err)
+	}
+	searchSuggestions, err := suggest.NewService(db, adminConfig, registry, jobService, suggest.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建拼写建议服务失败: %v", err)
+	}
+	catalogService, err := catalog.NewService(adminConfig, registry, catalog.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建目录服务失败: %v", err)
+	}
+	preferenceService, err := preferences.NewService(db, preferences.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建偏好设置服务失败: %v", err)
+	}
+	bookmarkService, err := bookmarks.NewService(db, registry, bookmarks.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建收藏服务失败: %v", err)
+	}
+	annotationService, err := annotations.NewService(db, adminConfig, registry, annotations.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建记录批注服务失败: %v", err)
+	}
+	shareLinkService, err := sharelinks.NewService(db, registry, sharelinks.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建分享链接服务失败: %v", err)
+	}
+	embedService, err := embed.NewService(db, registry, embed.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建嵌入服务失败: %v", err)
+	}
+	sitemapService, err := sitemap.NewService(adminConfig, registry, sitemap.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建站点地图服务失败: %v", err)
+	}
+	requestSigning, err := signing.NewService(db, signing.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建请求签名服务失败: %v", err)
+	}
+	bizOwners, err := ownership.NewService(db)
+	if err != nil {
+		t.Fatalf("testsupport: 创建业务组管理员服务失败: %v", err)
+	}
+	fieldImpact, err := impact.NewService(adminConfig, shareLinkService, virtualBizService)
+	if err != nil {
+		t.Fatalf("testsupport: 创建影响分析服务失败: %v", err)
+	}
+	typegenService, err := typegen.NewService(adminConfig)
+	if err != nil {
+		t.Fatalf("testsupport: 创建类型生成服务失败: %v", err)
+	}
+	columnStats, err := colstats.NewService(registry, colstats.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建字段统计服务失败: %v", err)
+	}
+	fieldGuard, err := fieldguard.NewService(adminConfig, fieldguard.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建字段白名单服务失败: %v", err)
+	}
+	iiifService, err := iiif.NewService(db, registry, fieldGuard, accessLog)
+	if err != nil {
+		t.Fatalf("testsupport: 创建 IIIF 服务失败: %v", err)
+	}
+	identifierPolicy, err := identpolicy.NewService(adminConfig, identpolicy.Options{Mode: identpolicy.ModeStrict})
+	if err != nil {
+		t.Fatalf("testsupport: 创建标识符策略服务失败: %v", err)
+	}
+	adminConfig.SetIdentifierChecker(identifierPolicy)
+	queryExtensions, err := queryext.NewService(queryext.Options{Allow: map[string][]string{"archive": {"hint"}}})
+	if err != nil {
+		t.Fatalf("testsupport: 创建查询扩展选项服务失败: %v", err)
+	}
+	approvalService, err := approvals.NewService(db, approvals.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建双人审批服务失败: %v", err)
+	}
+	digestService, err := digest.NewService(db, "", digest.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建每日摘要服务失败: %v", err)
+	}
+	captureService, err := capture.NewService(capture.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建请求采集服务失败: %v", err)
+	}
+	updateService, err := updates.NewService(db, pm, "v1.0.0", updates.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建更新检查服务失败: %v", err)
+	}
+	licenseService, err := licensing.NewService(db, pm)
+	if err != nil {
+		t.Fatalf("testsupport: 创建插件授权服务失败: %v", err)
+	}
+	pm.SetLicenseChecker(licenseService.Entitlement)
+	telemetryService, err := telemetry.NewService(db, "v1.0.0", telemetry.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建使用统计服务失败: %v", err)
+	}
+	penaltyService, err := penalties.NewService(db, penalties.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建登录锁定服务失败: %v", err)
+	}
+	sessionService, err := sessions.NewService(db, sessions.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建登录会话服务失败: %v", err)
+	}
+	abuseDetector, err := abuse.NewService(db, penaltyService, abuse.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建异常行为检测服务失败: %v", err)
+	}
+	sloService, err := slo.NewService(db, slo.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建服务等级目标服务失败: %v", err)
+	}
+	// 测试中的请求密集且来自同一地址，放宽全局与按 IP 的限流以免干扰断言
+	if _, err := db.Exec(`UPDATE global_settings SET value = '1000000' WHERE key IN ('ip_rate_limit_per_minute', 'ip_burst_size')`); err != nil {
+		t.Fatalf("testsupport: 放宽 IP 限流失败: %v", err)
+	}
+	rateLimiter := aegmiddleware.NewBusinessRateLimiter(adminConfig, 1e6, 1e6)
+	rateLimiter.SetPenaltyBox(penaltyService)
+	dataSubjects, err := datasubject.NewService(db, adminConfig, registry, datasubject.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建数据主体请求服务失败: %v", err)
+	}
+	exportService, err := exports.NewService(adminConfig, registry, jobService, filepath.Join(t.TempDir(), "exports"), exports.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建记录导出服务失败: %v", err)
+	}
+	standbyService, err := standby.NewService(db, dir, standby.Options{})
+	if err != nil {
+		t.Fatalf("testsupport: 创建备用模式服务失败: %v", err)
+	}
+	piiScanner, err := piiscan.NewService(db, adminConfig, registry, piiscan.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建个人信息扫描服务失败: %v", err)
+	}
+	origin, err := provenance.NewService(db, provenance.Options{Enabled: true, Issuer: "testsupport", License: "CC BY 4.0", MinQueryItems: 3, UserHashSecret: "testsupport"})
+	if err != nil {
+		t.Fatalf("testsupport: 创建来源信息服务失败: %v", err)
+	}
+	semanticSearch, err := semantic.NewService(db, adminConfig, registry, jobService, semantic.Options{Enabled: true})
+	if err != nil {
+		t.Fatalf("testsupport: 创建语义检索服务失败: %v", err)
+	}
+
+	handler := router.New(router.Dependencies{
+		Registry:           registry,
+		AdminConfigService: adminConfig,
+		PluginManager:      pm,
+		Compression:        router.CompressionOptions{Enabled: true},
+		OAIPMHService:      oaiPMHService,
+		IIIFService:        iiifService,
+		ReplicationService: replicationService,
+		ReplicaCheck:       replicaCheck,
+		SnapshotService:    snapshotService,
+		VirtualBizService:  virtualBizService,
+		ShardedBizService:  shardedBizService,
+		ReadSplitService:   readSplitService,
+		Warmup:             warmupService,
+		Uploads:            uploadService,
+		ConfigTemplates:    configTemplates,
+		BizClone:           bizClone,
+		BizRename:          bizRename,
+		QueryDictionaries:  queryDictionaries,
+		DataDictionary:     dataDictionary,
+		Vocabularies:       vocabularies,
+		RecordLinks:        recordLinks,
+		AccessLog:          accessLog,
+		Compliance:         complianceService,
+		SecurityEvents:     securityEvents,
+		AuthorizationHooks: authorizationHooks,
+		QueryTemplates:     queryTemplates,
+		ResultDiff:         resultDiff,
+		FullTextService:    fullTextService,
+		JobService:         jobService,
+		DemoService:        demoService,
+		DiagnosticsService: diagnosticsService,
+		DoctorService:      doctorService,
+		CatalogService:     catalogService,
+		Preferences:        preferenceService,
+		Bookmarks:          bookmarkService,
+		Annotations:        annotationService,
+		ShareLinks:         shareLinkService,
+		Embeds:             embedService,
+		UsageAnalytics:     usageAnalytics,
+		SearchSuggestions:  searchSuggestions,
+		SemanticSearch:     semanticSearch,
+		Sitemaps:           sitemapService,
+		RequestSigning:     requestSigning,
+		BizOwners:          bizOwners,
+		FieldImpact:        fieldImpact,
+		Typegen:            typegenService,
+		ColumnStats:        columnStats,
+		FieldGuard:         fieldGuard,
+		IdentifierPolicy:   identifierPolicy,
+		QueryExtensions:    queryExtensions,
+		Approvals:          approvalService,
+		Digests:            digestService,
+		DataSubjects:       dataSubjects,
+		Exports:            exportService,
+		Standby:            standbyService,
+		BootReport:         bootreport.New("testsupport", time.Now()),
+		PIIScanner:         piiScanner,
+		Provenance:         origin,
+		Updates:            updateService,
+		Captures:           captureService,
+		Licenses:           licenseService,
+		Telemetry:          telemetryService,
+		Penalties:          penaltyService,
+		Sessions:           sessionService,
+		AbuseDetector:      abuseDetector,
+		SLO:                sloService,
+		BuildInfo:          domain.BuildInfo{Version: "test", GoVersion: runtime.Version()},
+		RateLimiter:        rateLimiter,
+		AuthDB:             db,
+	})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &Harness{
+		Server:      server,
+		DB:          db,
+		Registry:    registry,
+		AdminConfig: adminConfig,
+		Policies:    authorizationHooks,
+		t:           t,
+	}
+}
+
+// RegisterDataSource 将数据源注册到网关的业务组注册表中
+func (h *Harness) RegisterDataSource(bizName string, ds port.DataSource) {
+	h.Registry[bizName] = ds
+}
+
+// AdminToken 返回一个管理员的 JWT，首次调用时创建管理员账户
+func (h *Harness) AdminToken() string {
+	h.t.Helper()
+	h.adminOnce.Do(func() {
+		if err := service.CreateAdmin(h.DB, harnessAdminUser, harnessAdminPassword); err != nil {
+			h.t.Fatalf("testsupport: 创建管理员失败: %v", err)
+		}
+		id, role, ok := service.GetUserByUsername(h.DB, harnessAdminUser)
+		if !ok {
+			h.t.Fatalf("testsupport: 未找到刚创建的管理员")
+		}
+		token, err := service.GenToken(id, role)
+		if err != nil {
+			h.t.Fatalf("testsupport: 生成管理员令牌失败: %v", err)
+		}
+		h.adminToken = token
+	})
+	return h.adminToken
+}
+
+// Do 发起一个 HTTP 请求。body 非空时以 JSON 编码发送；token 非空时作为 Bearer 令牌携带。
+// 响应体由调用方负责关闭。
+func (h *Harness) Do(method, path string, body interface{}, token string) *http.Response {
+	h.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("testsupport: 序列化请求体失败: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, h.Server.URL+path, reader)
+	if err != nil {
+		h.t.Fatalf("testsupport: 构造请求失败: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := h.Server.Client().Do(req)
+	if err != nil {
+		h.t.Fatalf("testsupport: 请求 %s %s 失败: %v", method, path, err)
+	}
+	return resp
+}
+
+// DoJSON 发起请求并将 JSON 响应解码到 out (可为 nil)，返回 HTTP 状态码
+func (h *Harness) DoJSON(method, path string, body interface{}, token string, out interface{}) int {
+	h.t.Helper()
+	resp := h.Do(method, path, body, token)
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			h.t.Fatalf("testsupport: 解码 %s %s 的响应失败: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
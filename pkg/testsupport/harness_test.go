@@ -0,0 +1,1744 @@
+// file: pkg/testsupport/harness_test.go
+package testsupport
+
+import (
+	"ArchiveAegis/internal/core/domain"
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLettersSource() *FakeDataSource {
+	return NewFakeDataSource().WithTable("letters",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}, {Name: "place"}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅", "place": "北京"},
+		map[string]interface{}{"id": float64(2), "sender": "胡适", "place": nil},
+		map[string]interface{}{"id": float64(3), "sender": "鲁迅", "place": "上海"},
+	)
+}
+
+func TestHarness_QueryThroughGateway(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+
+	var body struct {
+		Data map[string]interface{}
+	}
+	status := h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive",
+		"query": map[string]interface{}{
+			"table":   "letters",
+			"filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+		},
+	}, "", &body)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(2), body.Data["total"])
+	require.Len(t, fake.Queries(), 1)
+	assert.Equal(t, "archive", fake.Queries()[0].BizName)
+
+	status = h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "missing", "query": map[string]interface{}{"table": "letters"},
+	}, "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestHarness_AdminOnlyFeatures(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	query := map[string]interface{}{
+		"biz_name": "archive",
+		"query":    map[string]interface{}{"table": "letters"},
+		"explain":  true,
+	}
+
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil),
+		"匿名请求不能使用 explain")
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, h.AdminToken(), nil))
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/admin/virtual-biz", nil, "", nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/virtual-biz", nil, h.AdminToken(), nil))
+}
+
+func TestHarness_DelegatedBizAdmin(t *testing.T) {
+	h := NewHarness(t)
+	admin := h.AdminToken()
+	public := true
+	for _, biz := range []string{"archive", "museum"} {
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/"+biz+"/settings",
+			domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	}
+	res, err := h.DB.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('curator', 'x', 'user')`)
+	require.NoError(t, err)
+	curatorID, _ := res.LastInsertId()
+	curator, err := service.GenToken(curatorID, "user")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive", nil, curator, nil),
+		"未委派时无权访问")
+	require.Equal(t, http.StatusCreated, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/owners",
+		map[string]interface{}{"user_id": curatorID}, admin, nil))
+
+	var names struct {
+		Data  []string `json:"data"`
+		Total int64    `json:"total"`
+		Next  string   `json:"next"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/", nil, curator, &names))
+	assert.Equal(t, []string{"archive"}, names.Data, "只列出自己管理的业务组")
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive", nil, curator, nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/rate-limit",
+		domain.BizRateLimitSetting{RateLimitPerSecond: 5, BurstSize: 10}, curator, nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive/rate-limit", nil, curator, nil))
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/museum", nil, curator, nil))
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, curator, nil), "公开与权限设置仍需平台管理员")
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive/owners", nil, curator, nil))
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/admin/virtual-biz", nil, curator, nil))
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/", nil, admin, &names))
+	assert.Equal(t, []string{"archive", "museum"}, names.Data)
+	assert.Equal(t, int64(2), names.Total)
+
+	names.Data = nil
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/?size=1", nil, admin, &names))
+	assert.Equal(t, []string{"archive"}, names.Data)
+	assert.Equal(t, int64(2), names.Total)
+	require.NotEmpty(t, names.Next, "还有下一页")
+	next := names.Next
+	names.Data, names.Next = nil, ""
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, next, nil, admin, &names))
+	assert.Equal(t, []string{"museum"}, names.Data)
+	assert.Empty(t, names.Next, "最后一页不返回 next")
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, fmt.Sprintf("/api/v1/admin/biz-config/archive/owners/%d", curatorID), nil, admin, nil))
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive", nil, curator, nil))
+}
+
+func TestHarness_TwoPersonApproval(t *testing.T) {
+	h := NewHarness(t)
+	alice := h.AdminToken()
+	res, err := h.DB.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('bob', 'x', 'admin')`)
+	require.NoError(t, err)
+	bobID, _ := res.LastInsertId()
+	bob, err := service.GenToken(bobID, "admin")
+	require.NoError(t, err)
+
+	var before domain.IPLimitSetting
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/rate-limiting/global", nil, alice, &before))
+	var submitted struct {
+		Data domain.ChangeRequest `json:"data"`
+	}
+	require.Equal(t, http.StatusAccepted, h.DoJSON(http.MethodPut, "/api/v1/admin/security/rate-limiting/global",
+		domain.IPLimitSetting{RateLimitPerMinute: 7, BurstSize: 2}, alice, &submitted))
+	assert.Equal(t, domain.ChangeStatusPending, submitted.Data.Status)
+
+	var after domain.IPLimitSetting
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/rate-limiting/global", nil, alice, &after))
+	assert.Equal(t, before, after, "审批前不生效")
+
+	var pending struct {
+		Data []domain.ChangeRequest `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/pending-changes", nil, bob, &pending))
+	require.Len(t, pending.Data, 1)
+
+	approvePath := fmt.Sprintf("/api/v1/admin/pending-changes/%d/approve", submitted.Data.ID)
+	assert.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodPost, approvePath, nil, alice, nil), "申请人不能自己审批")
+	var approved struct {
+		Data domain.ChangeRequest `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, approvePath, map[string]string{"note": "已核对"}, bob, &approved))
+	assert.Equal(t, domain.ChangeStatusApplied, approved.Data.Status)
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/rate-limiting/global", nil, alice, &after))
+	assert.Equal(t, domain.IPLimitSetting{RateLimitPerMinute: 7, BurstSize: 2}, after)
+	assert.Equal(t, http.StatusConflict, h.DoJSON(http.MethodPost, approvePath, nil, bob, nil))
+
+	// 开启公开检索直接生效，关闭则需要审批
+	public, private := true, false
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, alice, nil))
+	assert.Equal(t, http.StatusAccepted, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &private}, alice, nil))
+}
+
+func TestHarness_SoftDisabledBiz(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public, enabled, disabled := true, true, false
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	query := map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{"table": "letters"}}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/status",
+		map[string]interface{}{"enabled": disabled}, admin, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, h.DoJSON(http.MethodGet, "/api/v1/data/record/archive/letters/1", nil, admin, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, h.DoJSON(http.MethodGet, "/api/v1/meta/schema/archive", nil, "", nil))
+	var bizs struct {
+		Data []string `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/biz", nil, "", &bizs))
+	assert.Empty(t, bizs.Data)
+
+	// 配置保持不变，恢复后立即可用
+	var cfg domain.BizQueryConfig
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive", nil, admin, &cfg))
+	assert.True(t, cfg.IsPubliclySearchable)
+	assert.False(t, cfg.Enabled)
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/status",
+		map[string]interface{}{"enabled": enabled}, admin, nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+}
+
+func TestHarness_QueryOptionsReachDataSource(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		map[string][]*domain.ViewConfig{"letters": {
+			{ViewName: "senders", ViewType: "table", Distinct: true},
+			{ViewName: "beijing", ViewType: "table", DefaultFilters: []domain.ViewFilter{{Field: "place", Value: "北京"}}, DefaultSort: port.ResultScoreField},
+		}}, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		map[string][]*domain.ViewConfig{"letters": {{ViewName: "bad", ViewType: "table", DefaultSort: "sender"}}}, admin, nil))
+
+	lastQuery := func() map[string]interface{} {
+		queries := fake.Queries()
+		require.NotEmpty(t, queries)
+		return queries[len(queries)-1].Query
+	}
+
+	// 视图的 distinct 是默认值，查询中显式指定时以查询为准
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?view=senders", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters"},
+	}, "", nil))
+	assert.Equal(t, true, lastQuery()[port.QueryDistinctKey])
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?view=senders", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "distinct": false},
+	}, "", nil))
+	assert.Equal(t, false, lastQuery()[port.QueryDistinctKey])
+
+	// 视图的默认过滤条件总是与查询的条件以 AND 组合，默认排序可被查询覆盖
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?view=beijing", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "filters": []interface{}{
+			map[string]interface{}{"field": "sender", "value": "胡适"},
+		}},
+	}, "", nil))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "place", "value": "北京", "logic": "AND"},
+		map[string]interface{}{"field": "sender", "value": "胡适"},
+	}, lastQuery()["filters"])
+	assert.Equal(t, port.ResultScoreField, lastQuery()[port.QuerySortKey])
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?view=beijing", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "sort": ""},
+	}, "", nil))
+	assert.Equal(t, "", lastQuery()[port.QuerySortKey])
+
+	// where 表达式在网关编译为扁平 filters 后才交给数据源
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "where": map[string]interface{}{
+			"or": []interface{}{
+				map[string]interface{}{"field": "sender", "value": "胡适"},
+				map[string]interface{}{"not": map[string]interface{}{"field": "place", "op": "is_null"}},
+			},
+		}},
+	}, "", nil))
+	assert.NotContains(t, lastQuery(), "where")
+	assert.Len(t, lastQuery()["filters"], 2)
+
+	ids := make([]interface{}, 1001)
+	for i := range ids {
+		ids[i] = float64(i)
+	}
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "filters": []interface{}{
+			map[string]interface{}{"field": "id", "op": "in", "values": ids},
+		}},
+	}, "", nil), "in 列表超过默认上限")
+}
+
+func TestHarness_RequestCapture(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+
+	query := map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{"table": "letters"}}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/capture/archive/entries", nil, admin, nil))
+
+	require.Equal(t, http.StatusCreated, h.DoJSON(http.MethodPost, "/api/v1/admin/capture/archive",
+		map[string]int{"duration_seconds": 60, "max_entries": 2}, admin, nil))
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, admin, nil))
+	}
+
+	var out struct {
+		Data struct {
+			Session domain.CaptureSession     `json:"session"`
+			Items   []domain.CapturedExchange `json:"items"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/capture/archive/entries", nil, admin, &out))
+	assert.True(t, out.Data.Session.Active)
+	assert.Equal(t, 1, out.Data.Session.Dropped, "缓冲区满后挤掉最早的记录")
+	require.Len(t, out.Data.Items, 2)
+	item := out.Data.Items[1]
+	assert.Equal(t, "/api/v1/data/query", item.Path)
+	assert.Equal(t, http.StatusOK, item.Status)
+	assert.Equal(t, "[REDACTED]", item.RequestHeaders["Authorization"])
+	assert.Contains(t, item.RequestBody, `"biz_name":"archive"`)
+	assert.Contains(t, item.ResponseBody, `"total":3`)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/capture/archive", nil, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/capture/archive/entries", nil, admin, &out))
+	assert.False(t, out.Data.Session.Active)
+	assert.Len(t, out.Data.Items, 2, "停止后不再采集，已有记录保留")
+}
+
+func TestHarness_SystemInfo(t *testing.T) {
+	h := NewHarness(t)
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/system/info", nil, "", nil), "插件清单只对管理员开放")
+
+	var out struct {
+		Data domain.SystemInfo `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/system/info", nil, h.AdminToken(), &out))
+	assert.Equal(t, "test", out.Data.Version)
+	assert.NotEmpty(t, out.Data.GoVersion)
+	assert.NotNil(t, out.Data.EnabledFeatures)
+	assert.Empty(t, out.Data.Plugins)
+}
+
+func TestHarness_CORSPreflightAllowsPatch(t *testing.T) {
+	h := NewHarness(t)
+	req, err := http.NewRequest(http.MethodOptions, h.Server.URL+"/api/v1/admin/plugins/instances/i1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://console.example.org")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	resp, err := h.Server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Less(t, resp.StatusCode, 300, "PATCH 预检请求应被允许")
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPatch)
+}
+
+func TestHarness_UpdateCheck(t *testing.T) {
+	h := NewHarness(t)
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/updates", nil, h.AdminToken(), nil), "尚未检查")
+
+	var out struct {
+		Data domain.UpdateReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/updates/check", nil, h.AdminToken(), &out))
+	assert.Equal(t, "v1.0.0", out.Data.Gateway.CurrentVersion)
+	assert.False(t, out.Data.Gateway.UpdateAvailable)
+	assert.Empty(t, out.Data.Plugins)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/updates", nil, h.AdminToken(), nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/admin/updates/plugins/sqlite/stage", nil, h.AdminToken(), nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/admin/updates/plugins/sqlite/apply", nil, h.AdminToken(), nil))
+}
+
+func TestHarness_Licenses(t *testing.T) {
+	h := NewHarness(t)
+	var out struct {
+		Data []domain.License `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/licenses", nil, h.AdminToken(), &out))
+	assert.Empty(t, out.Data)
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/licenses", map[string]string{"key": "not-a-license"}, h.AdminToken(), nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodDelete, "/api/v1/admin/licenses/pro-ocr", nil, h.AdminToken(), nil))
+}
+
+func TestHarness_TelemetryPreview(t *testing.T) {
+	h := NewHarness(t)
+	var out struct {
+		Data domain.TelemetryStatus `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/telemetry/preview", nil, h.AdminToken(), &out))
+	assert.False(t, out.Data.Enabled)
+	assert.Equal(t, "v1.0.0", out.Data.Preview.Version)
+	assert.NotEmpty(t, out.Data.Preview.InstallationID)
+}
+
+func TestHarness_LoginLockout(t *testing.T) {
+	h := NewHarness(t)
+	token := h.AdminToken()
+	login := func(pass string) int {
+		return h.DoJSON(http.MethodPost, "/api/v1/auth/login", map[string]string{"user": harnessAdminUser, "pass": pass}, "", nil)
+	}
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusUnauthorized, login("wrong"))
+	}
+	assert.Equal(t, http.StatusUnauthorized, login(harnessAdminPassword), "锁定期间正确的密码同样被拒绝")
+
+	var out struct {
+		Data []domain.Penalty `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/penalties", nil, token, &out))
+	require.Len(t, out.Data, 1)
+	assert.Equal(t, domain.PenaltyKindLogin, out.Data[0].Kind)
+	require.NotNil(t, out.Data[0].LockedUntil)
+
+	path := "/api/v1/admin/security/penalties/login?subject=" + url.QueryEscape(out.Data[0].Subject)
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, path, nil, token, nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodDelete, path, nil, token, nil))
+	assert.Equal(t, http.StatusOK, login(harnessAdminPassword))
+}
+
+func TestHarness_UserSessions(t *testing.T) {
+	h := NewHarness(t)
+	admin := h.AdminToken()
+	var login struct {
+		Token string `json:"token"`
+		User  struct {
+			ID int64 `json:"id"`
+		} `json:"user"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/auth/login",
+		map[string]string{"user": harnessAdminUser, "pass": harnessAdminPassword}, "", &login))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/system/info", nil, login.Token, nil))
+
+	sessionsPath := fmt.Sprintf("/api/v1/admin/security/users/%d/sessions", login.User.ID)
+	var out struct {
+		Data []domain.UserSession `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, sessionsPath, nil, admin, &out))
+	require.Len(t, out.Data, 1)
+	assert.NotEmpty(t, out.Data[0].IP)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, sessionsPath+"/"+out.Data[0].ID, nil, admin, nil))
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/system/info", nil, login.Token, nil),
+		"注销的会话的 Token 立即失效")
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodDelete, sessionsPath+"/"+out.Data[0].ID, nil, admin, nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/system/info", nil, admin, nil), "没有会话 ID 的 Token 不受影响")
+}
+
+func TestHarness_AbuseDetectionBansRepeatedForbidden(t *testing.T) {
+	h := NewHarness(t)
+	admin := h.AdminToken()
+	_, err := h.DB.Exec(`INSERT INTO _user (username, password_hash, role) VALUES ('prober', 'x', 'user')`)
+	require.NoError(t, err)
+	// 放宽该用户的限流，429 只可能来自封禁
+	_, err = h.DB.Exec(`UPDATE _user SET rate_limit_per_second = 1000, burst_size = 1000 WHERE username = 'prober'`)
+	require.NoError(t, err)
+	var proberID int64
+	require.NoError(t, h.DB.QueryRow(`SELECT id FROM _user WHERE username = 'prober'`).Scan(&proberID))
+	prober, err := service.GenToken(proberID, "user")
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		require.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/data/changes", nil, prober, nil))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, h.DoJSON(http.MethodGet, "/api/v1/data/changes", nil, prober, nil),
+		"反复触发 403 后临时封禁该用户")
+
+	var flags struct {
+		Data []domain.AbuseFlag `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/abuse/flags", nil, admin, &flags),
+		"同一地址的其他用户不受影响")
+	require.Len(t, flags.Data, 1)
+	assert.Equal(t, domain.AbuseReasonForbidden, flags.Data[0].Reason)
+	assert.Equal(t, fmt.Sprint(proberID), flags.Data[0].Subject)
+
+	// 管理员解除封禁并加入豁免名单后不再检测
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/security/penalties/user?subject="+flags.Data[0].Subject, nil, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/security/abuse/allowlist",
+		map[string]string{"kind": "user", "subject": flags.Data[0].Subject, "note": "安全审计"}, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/security/abuse/allowlist",
+		map[string]string{"kind": "ip", "subject": "not-an-ip"}, admin, nil))
+	for i := 0; i < 25; i++ {
+		require.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodGet, "/api/v1/data/changes", nil, prober, nil))
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/security/abuse/allowlist/user?subject="+flags.Data[0].Subject, nil, admin, nil))
+}
+
+func TestHarness_DataSubjectRequests(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+
+	var found struct {
+		Data domain.DataSubjectSearchResult `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/data-subjects/search",
+		map[string]string{"identifier": "鲁迅"}, admin, &found))
+	require.Len(t, found.Data.Matches, 2)
+	assert.Equal(t, "1", found.Data.Matches[0].RecordID)
+	assert.Equal(t, []string{"sender"}, found.Data.Matches[0].MatchedFields)
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/data-subjects/search",
+		map[string]string{"identifier": ""}, admin, nil))
+
+	resp := h.Do(http.MethodPost, "/api/v1/admin/data-subjects/export", map[string]string{"identifier": "鲁迅"}, admin)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "records/archive/letters.json", zr.File[1].Name)
+
+	erase := map[string]interface{}{"identifier": "鲁迅", "mode": domain.DataSubjectModeDelete}
+	var erased struct {
+		Data domain.DataSubjectRequest `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/data-subjects/erase", erase, admin, &erased))
+	require.Len(t, erased.Data.Erasures, 1)
+	assert.Equal(t, domain.DataSubjectStatusSkipped, erased.Data.Erasures[0].Status, "未开放删除权限的表不执行删除")
+	assert.Empty(t, fake.Mutations())
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/permissions",
+		map[string]bool{"allow_delete": true}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/data-subjects/erase", erase, admin, &erased))
+	assert.Equal(t, domain.DataSubjectStatusDone, erased.Data.Erasures[0].Status)
+	mutations := fake.Mutations()
+	require.Len(t, mutations, 1)
+	assert.Equal(t, "delete", mutations[0].Operation)
+	assert.NotEmpty(t, mutations[0].Payload[port.MutateActorKey], "删除以管理员身份执行，便于变更捕获追溯")
+
+	var audit struct {
+		Data []domain.DataSubjectRequest `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/data-subjects/requests?identifier="+url.QueryEscape("鲁迅"), nil, admin, &audit))
+	require.Len(t, audit.Data, 4)
+	assert.Equal(t, domain.DataSubjectActionErase, audit.Data[0].Action)
+	assert.Equal(t, domain.DataSubjectActionSearch, audit.Data[3].Action)
+}
+
+func TestHarness_PIIScan(t *testing.T) {
+	h := NewHarness(t)
+	fake := NewFakeDataSource().WithTable("readers",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "name"}, {Name: "contact"}},
+		map[string]interface{}{"id": float64(1), "name": "张三", "contact": "zhangsan@example.com"},
+		map[string]interface{}{"id": float64(2), "name": "李四", "contact": "13812345678"},
+		map[string]interface{}{"id": float64(3), "name": "王五", "contact": "lisi@example.org"},
+	)
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"readers"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/readers/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "name", IsSearchable: true, IsReturnable: true},
+			{FieldName: "contact", IsReturnable: true},
+		}, admin, nil))
+
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/biz/archive/pii-scan", nil, admin, nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/admin/biz/missing/pii-scan", nil, admin, nil))
+
+	var scanned struct {
+		Data domain.PIIScanReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz/archive/pii-scan", nil, admin, &scanned))
+	assert.True(t, scanned.Data.Public)
+	assert.Equal(t, 1, scanned.Data.Flagged)
+	require.Len(t, scanned.Data.Tables, 1)
+	require.Len(t, scanned.Data.Tables[0].Fields, 3)
+	contact := scanned.Data.Tables[0].Fields[0]
+	assert.Equal(t, "contact", contact.Field)
+	assert.True(t, contact.Flagged)
+	assert.Equal(t, domain.PIIKindEmail, contact.Detections[0].Kind)
+
+	var last struct {
+		Data domain.PIIScanReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz/archive/pii-scan", nil, admin, &last))
+	assert.Equal(t, scanned.Data.Flagged, last.Data.Flagged)
+}
+
+func TestHarness_ProvenanceOnExports(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+
+	var queried struct {
+		Data struct {
+			Items      []map[string]interface{} `json:"items"`
+			Provenance *domain.Provenance       `json:"provenance"`
+		}
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive",
+		"query":    map[string]interface{}{"table": "letters"},
+	}, admin, &queried))
+	require.Len(t, queried.Data.Items, 3)
+	require.NotNil(t, queried.Data.Provenance, "记录数达到下限的结果附带来源信息")
+	assert.Equal(t, []string{"archive"}, queried.Data.Provenance.Sources)
+	assert.Equal(t, "CC BY 4.0", queried.Data.Provenance.License)
+	hash := queried.Data.Provenance.RequestedBy
+	assert.Len(t, hash, 16)
+
+	require.Equal(t, http.StatusCreated, h.DoJSON(http.MethodPost, "/api/v1/me/bookmarks",
+		domain.Bookmark{BizName: "archive", TableName: "letters", RecordID: "1"}, admin, nil))
+	resp := h.Do(http.MethodGet, "/api/v1/me/bookmarks/export?format=csv", nil, admin)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	lines := strings.Split(strings.TrimPrefix(string(body), "\ufeff"), "\n")
+	assert.Equal(t, "# issuer: testsupport", lines[0])
+	assert.Equal(t, "# sources: archive", lines[1])
+	assert.Equal(t, "# requested_by: "+hash, lines[3])
+	assert.Equal(t, "# license: CC BY 4.0", lines[4])
+	assert.True(t, strings.HasPrefix(lines[5], "biz_name,"))
+
+	var resolved struct {
+		Data struct {
+			UserID   int64  `json:"user_id"`
+			Username string `json:"username"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/provenance/resolve?hash="+hash, nil, admin, &resolved))
+	assert.NotZero(t, resolved.Data.UserID)
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/security/provenance/resolve?hash=0000000000000000", nil, admin, nil))
+}
+
+func TestHarness_ReplicaVerification(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	h.RegisterDataSource("archive_restored", NewFakeDataSource().WithTable("letters",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}, {Name: "place"}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅", "place": "北京"},
+		map[string]interface{}{"id": float64(2), "sender": "胡适", "place": nil},
+	))
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+
+	var verified struct {
+		Data domain.ReplicaVerification `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/replication/archive/verify",
+		map[string]string{"compare_biz": "archive_restored"}, admin, &verified))
+	assert.Equal(t, "biz:archive_restored", verified.Data.Against)
+	require.NotEmpty(t, verified.Data.Checks)
+	assert.Equal(t, int64(3), verified.Data.Checks[0].LocalTotal)
+	assert.Equal(t, int64(2), verified.Data.Checks[0].RemoteTotal)
+	assert.Positive(t, verified.Data.Diverged)
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/replication/archive/verify", nil, admin, nil),
+		"未指定对端且没有同步目标")
+}
+
+func TestHarness_BulkFieldSettings(t *testing.T) {
+	h := NewHarness(t)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters", "people"}}, admin, nil))
+
+	var out struct {
+		Data []domain.FieldSettingsBulkResult `json:"data"`
+	}
+	invalid := map[string][]domain.FieldSetting{
+		"letters": {{FieldName: "sender", IsSearchable: true, IsReturnable: true}},
+		"people":  {{FieldName: "name"}, {FieldName: "name"}},
+	}
+	require.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/fields:bulk", invalid, admin, &out))
+	require.Len(t, out.Data, 2)
+	assert.Empty(t, out.Data[0].Errors)
+	assert.Equal(t, "people", out.Data[1].TableName)
+	assert.NotEmpty(t, out.Data[1].Errors, "重复字段应被指出")
+	cfg, err := h.AdminConfig.GetBizQueryConfig(context.Background(), "archive")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Tables["letters"].Fields, "任一张表有误时整批都不应用")
+
+	valid := map[string][]domain.FieldSetting{
+		"letters": {{FieldName: "sender", IsSearchable: true, IsReturnable: true}, {FieldName: "body", IsReturnable: true}},
+		"people":  {{FieldName: "name", IsSearchable: true, IsReturnable: true}},
+	}
+	out.Data = nil
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/fields:bulk", valid, admin, &out))
+	require.Len(t, out.Data, 2)
+	assert.Equal(t, 2, out.Data[0].Fields)
+	assert.NotNil(t, out.Data[0].Impact)
+	cfg, err = h.AdminConfig.GetBizQueryConfig(context.Background(), "archive")
+	require.NoError(t, err)
+	assert.Len(t, cfg.Tables["letters"].Fields, 2)
+	assert.True(t, cfg.Tables["people"].Fields["name"].IsSearchable)
+
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/fieldsx", valid, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/fields:bulk",
+		map[string]interface{}{}, admin, nil))
+}
+
+func TestHarness_ApplyConfigTemplate(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+
+	tmpl := domain.ConfigTemplate{
+		Description: "书信类档案",
+		Fields: []domain.TemplateFieldRule{
+			{Column: "id", IsReturnable: true, DataType: "number"},
+			{Column: "sender", IsSearchable: true, IsReturnable: true, DisplayName: "发信人"},
+		},
+		Views: []domain.TemplateViewRule{{View: domain.ViewConfig{ViewName: "list", ViewType: "table", IsDefault: true,
+			Binding: domain.ViewBinding{Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{{Field: "sender"}}}}}}},
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/config-templates/letters", tmpl, admin, nil))
+	tmpl.Fields[0].Column = "[bad"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/config-templates/broken", tmpl, admin, nil))
+
+	var out struct {
+		Data domain.TemplateApplyReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/apply-template",
+		map[string]interface{}{"template": "letters", "dry_run": true}, admin, &out))
+	require.Len(t, out.Data.Tables, 1)
+	assert.Len(t, out.Data.Tables[0].Added, 2)
+	assert.Equal(t, []string{"place"}, out.Data.Tables[0].Unmatched)
+	cfg, err := h.AdminConfig.GetBizQueryConfig(context.Background(), "archive")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Tables["letters"].Fields, "预览不做任何修改")
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/apply-template",
+		map[string]interface{}{"template": "letters"}, admin, &out))
+	cfg, err = h.AdminConfig.GetBizQueryConfig(context.Background(), "archive")
+	require.NoError(t, err)
+	assert.Equal(t, "发信人", cfg.Tables["letters"].Fields["sender"].DisplayName)
+	view, err := h.AdminConfig.GetDefaultViewConfig(context.Background(), "archive", "letters")
+	require.NoError(t, err)
+	require.NotNil(t, view)
+	assert.Equal(t, "list", view.ViewName)
+
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/apply-template",
+		map[string]interface{}{"template": "missing"}, admin, nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/config-templates/letters", nil, admin, nil))
+}
+
+func TestFakeDataSource_Scripting(t *testing.T) {
+	fake := newLettersSource()
+	ctx := context.Background()
+
+	result, err := fake.Query(ctx, port.QueryRequest{Query: map[string]interface{}{
+		"table":   "letters",
+		"filters": []interface{}{map[string]interface{}{"field": "place", "op": "is_null"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), result.Data["total"])
+
+	result, err = fake.Query(ctx, port.QueryRequest{Query: map[string]interface{}{
+		port.QueryModeKey: port.QueryModeRecord, "table": "letters", "id": "3",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "上海", result.Data["record"].(map[string]interface{})["place"])
+
+	boom := errors.New("boom")
+	fake.FailQuery(boom)
+	_, err = fake.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"table": "letters"}})
+	assert.ErrorIs(t, err, boom)
+
+	_, err = fake.Mutate(ctx, port.MutateRequest{Operation: "create", Payload: map[string]interface{}{
+		"table_name": "letters", "data": map[string]interface{}{"id": float64(4), "sender": "茅盾"},
+	}})
+	require.NoError(t, err)
+	fake.FailQuery(nil)
+	result, err = fake.Query(ctx, port.QueryRequest{Query: map[string]interface{}{"table": "letters"}})
+	require.NoError(t, err)
+	assert.Equal(t, float64(4), result.Data["total"])
+	assert.Len(t, fake.Mutations(), 1)
+}
+
+func TestHarness_QueryThroughNamedView(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		map[string][]*domain.ViewConfig{"letters": {
+			{ViewName: "full", ViewType: "table", IsDefault: true},
+			{ViewName: "cards", ViewType: "card", DisplayName: "卡片", Binding: domain.ViewBinding{Card: &domain.CardBinding{Title: "sender"}}},
+		}}, admin, nil))
+
+	var body struct {
+		Data struct {
+			Items []map[string]interface{} `json:"items"`
+			View  map[string]interface{}   `json:"view"`
+		} `json:"data"`
+	}
+	// 只返回视图绑定的字段
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive", "view": "cards", "query": map[string]interface{}{"table": "letters"},
+	}, "", &body))
+	require.NotEmpty(t, body.Data.Items)
+	for _, item := range body.Data.Items {
+		assert.Contains(t, item, "sender")
+		assert.NotContains(t, item, "place")
+	}
+	assert.Equal(t, "cards", body.Data.View["view_name"])
+	assert.Equal(t, "卡片", body.Data.View["display_name"])
+	assert.NotNil(t, body.Data.View["binding"])
+
+	// 显式指定的返回字段优先于视图绑定
+	body.Data.Items, body.Data.View = nil, nil
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?view=cards", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters", "fields_to_return": []interface{}{"sender", "place"}},
+	}, "", &body))
+	require.NotEmpty(t, body.Data.Items)
+	assert.Contains(t, body.Data.Items[0], "place")
+
+	// 没有绑定的视图不限制返回字段，未按视图查询时不附带视图描述
+	body.Data.Items, body.Data.View = nil, nil
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters"},
+	}, "", &body))
+	assert.Nil(t, body.Data.View)
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive", "view": "missing", "query": map[string]interface{}{"table": "letters"},
+	}, "", nil))
+}
+
+func TestHarness_TypegenFromBizConfig(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true, DataType: "INTEGER"},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true, DataType: "TEXT"},
+			{FieldName: "place", IsSearchable: true, DataType: "TEXT"},
+		}, admin, nil))
+
+	resp := h.Do(http.MethodGet, "/api/v1/meta/typegen/archive", nil, "")
+	src, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(src), "export interface ArchiveLetters {")
+	assert.Contains(t, string(src), "  id?: number | null;")
+	assert.Contains(t, string(src), "  sender?: string | null;")
+	assert.NotContains(t, string(src), "place", "不可返回的字段不应出现在生成的类型中")
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp = h.Do(http.MethodGet, "/api/v1/meta/typegen/archive?lang=go&package=letters", nil, "")
+	src, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(src), "package letters")
+	assert.Contains(t, string(src), "type ArchiveLetters struct {")
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/meta/typegen/archive?lang=rust", nil, "", nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/meta/typegen/missing", nil, "", nil))
+}
+
+func TestHarness_DataDictionaryInSchema(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true, DataType: "TEXT"},
+			{FieldName: "place", DataType: "TEXT"},
+		}, admin, nil))
+
+	base := "/api/v1/admin/biz-config/archive/tables/letters/data-dictionary/"
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, base+"sender", map[string]string{
+		"description": "寄信人", "source": "信封落款", "transformation": "统一为规范人名", "vocabulary_link": "https://example.org/names",
+	}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, base+"place", map[string]string{"description": "寄出地"}, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, base+"id", map[string]string{"description": "编号"}, admin, nil),
+		"未配置的字段不能填写数据字典")
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, base+"sender", map[string]string{"description": "  "}, admin, nil))
+
+	var schema struct {
+		Data port.SchemaResult `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/schema/archive", nil, "", &schema))
+	var sender port.FieldDescription
+	for _, f := range schema.Data.Tables["letters"] {
+		if f.Name == "sender" {
+			sender = f
+		}
+	}
+	assert.Equal(t, "寄信人", sender.Description)
+	assert.Equal(t, "信封落款", sender.Source)
+	assert.Equal(t, "统一为规范人名", sender.Transformation)
+	assert.Equal(t, "https://example.org/names", sender.VocabularyLink)
+	assert.Equal(t, []string{"id"}, schema.Data.RecordKeys["letters"], "数据源未报告时按主键字段给出记录标识")
+
+	var dict struct {
+		Data []domain.FieldDictionaryEntry `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/dictionary/archive", nil, "", &dict))
+	require.Len(t, dict.Data, 1, "既不可检索也不可返回的字段不公开")
+	assert.Equal(t, "sender", dict.Data[0].FieldName)
+	assert.Zero(t, dict.Data[0].UpdatedBy)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, base+"sender", nil, admin, nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodDelete, base+"sender", nil, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/dictionary/archive", nil, "", &dict))
+	assert.Empty(t, dict.Data)
+}
+
+func TestHarness_ControlledVocabulary(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true, DataType: "TEXT"},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true, DataType: "TEXT"},
+		}, admin, nil))
+
+	req, err := http.NewRequest(http.MethodPut, h.Server.URL+"/api/v1/admin/biz-config/archive/tables/letters/vocabularies/place?strict=true",
+		strings.NewReader("北京,北平\n上海\n"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Authorization", "Bearer "+admin)
+	resp, err := h.Server.Client().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var lookup struct {
+		Data   []domain.VocabularyTerm `json:"data"`
+		Strict bool                    `json:"strict"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/vocabulary/archive/letters/place?q="+url.QueryEscape("北"), nil, "", &lookup))
+	assert.True(t, lookup.Strict)
+	assert.Equal(t, []domain.VocabularyTerm{{Value: "北京", Label: "北平"}}, lookup.Data)
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/meta/vocabulary/archive/letters/sender", nil, "", nil))
+
+	mutate := func(place interface{}) int {
+		return h.DoJSON(http.MethodPost, "/api/v1/data/mutate", map[string]interface{}{
+			"biz_name": "archive", "operation": "create",
+			"payload": map[string]interface{}{"table_name": "letters", "data": map[string]interface{}{"sender": "鲁迅", "place": place}},
+		}, admin, nil)
+	}
+	assert.Equal(t, http.StatusBadRequest, mutate("广州"), "严格模式拒绝词表之外的值")
+	assert.Empty(t, fake.Mutations())
+	assert.Equal(t, http.StatusOK, mutate("上海"))
+	assert.Len(t, fake.Mutations(), 1)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/biz-config/archive/tables/letters/vocabularies/place", nil, admin, nil))
+	assert.Equal(t, http.StatusOK, mutate("广州"), "解除绑定后不再校验")
+}
+
+func TestHarness_MutateAllowlist(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	var adminOps []string
+	fake.AdminMutateFunc = func(_ context.Context, req port.MutateRequest) (*port.MutateResult, error) {
+		adminOps = append(adminOps, req.Operation)
+		return &port.MutateResult{Data: map[string]interface{}{"applied": float64(0)}}, nil
+	}
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+
+	mutate := func(op string) int {
+		return h.DoJSON(http.MethodPost, "/api/v1/data/mutate", map[string]interface{}{
+			"biz_name": "archive", "operation": op,
+			"payload": map[string]interface{}{"table_name": "letters", "changes": []interface{}{}},
+		}, admin, nil)
+	}
+	// 公开写接口只接受 create、update 与 delete，管理操作与未知操作一律拒绝，不会到达数据源
+	for _, op := range []string{port.MutateOpReplicate, port.MutateOpCompact, port.MutateOpReplace, "truncate"} {
+		assert.Equal(t, http.StatusForbidden, mutate(op), op)
+	}
+	assert.Empty(t, fake.Mutations())
+	assert.Equal(t, http.StatusOK, mutate("delete"))
+	assert.Len(t, fake.Mutations(), 1)
+
+	// 管理操作经由管理接口与 AdminMutate 执行
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/replication/apply",
+		map[string]interface{}{"biz_name": "archive", "changes": []interface{}{}}, admin, nil))
+	assert.Equal(t, []string{port.MutateOpReplicate}, adminOps)
+}
+
+func TestHarness_RecordLinks(t *testing.T) {
+	h := NewHarness(t)
+	letters := newLettersSource()
+	h.RegisterDataSource("archive", letters)
+	h.RegisterDataSource("people", NewFakeDataSource().WithTable("persons",
+		[]port.FieldDescription{{Name: "pid", IsPrimary: true}, {Name: "name"}},
+		map[string]interface{}{"pid": "p1", "name": "周树人"},
+	))
+	admin := h.AdminToken()
+	public := true
+	for biz, table := range map[string]string{"archive": "letters", "people": "persons"} {
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/"+biz+"/settings",
+			domain.BizOverallSettings{IsPubliclySearchable: &public, DefaultQueryTable: &table}, admin, nil))
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/"+biz+"/tables",
+			map[string]interface{}{"searchable_tables": []string{table}}, admin, nil))
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true, DataType: "INTEGER"},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true, DataType: "TEXT"},
+		}, admin, nil))
+
+	link := map[string]string{
+		"source_biz": "archive", "source_table": "letters", "source_id": "1",
+		"target_biz": "people", "target_table": "persons", "target_id": "p1", "relation": "author",
+	}
+	var created struct {
+		Data domain.RecordLink `json:"data"`
+	}
+	require.Equal(t, http.StatusCreated, h.DoJSON(http.MethodPost, "/api/v1/admin/links", link, admin, &created))
+	assert.Equal(t, http.StatusConflict, h.DoJSON(http.MethodPost, "/api/v1/admin/links", link, admin, nil))
+	link["target_id"] = "p404"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/links", link, admin, nil), "目标记录必须存在")
+
+	var query struct {
+		Data struct {
+			Items []map[string]interface{} `json:"items"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query?include_links=true", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters"},
+	}, "", &query))
+	require.Len(t, query.Data.Items, 3)
+	linked := query.Data.Items[0]["_links"].([]interface{})
+	require.Len(t, linked, 1)
+	assert.Equal(t, map[string]interface{}{"link_id": float64(created.Data.ID), "direction": "outgoing", "relation": "author",
+		"biz": "people", "table": "persons", "id": "p1"}, linked[0])
+	assert.Empty(t, query.Data.Items[1]["_links"])
+
+	var record struct {
+		Links []domain.LinkedRecord `json:"links"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/data/record/people/persons/p1?include_links=true", nil, "", &record))
+	require.Len(t, record.Links, 1)
+	assert.Equal(t, domain.LinkedRecord{LinkID: created.Data.ID, Direction: domain.LinkDirectionIncoming, Relation: "author",
+		Biz: "archive", Table: "letters", ID: "1"}, record.Links[0])
+
+	deleteLetter := func(filters ...map[string]interface{}) int {
+		return h.DoJSON(http.MethodPost, "/api/v1/data/mutate", map[string]interface{}{
+			"biz_name": "archive", "operation": "delete",
+			"payload": map[string]interface{}{"table_name": "letters", "filters": filters},
+		}, admin, nil)
+	}
+	assert.Equal(t, http.StatusConflict, deleteLetter(map[string]interface{}{"field": "id", "value": 1}), "被关联的记录不能删除")
+	assert.Equal(t, http.StatusConflict, deleteLetter(map[string]interface{}{"field": "sender", "value": "鲁迅"}), "有关联的表只能按主键删除")
+	assert.Equal(t, http.StatusOK, deleteLetter(map[string]interface{}{"field": "id", "value": 2}))
+
+	id := strconv.FormatInt(created.Data.ID, 10)
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/links/"+id, nil, admin, nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/links/"+id, nil, admin, nil))
+	assert.Equal(t, http.StatusOK, deleteLetter(map[string]interface{}{"field": "id", "value": 1}))
+}
+
+func TestHarness_HierarchyBrowse(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	fake.QueryFunc = func(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+		if req.Query[port.QueryModeKey] == port.QueryModeAncestors && req.Query[port.QueryNodeKey] != "1" {
+			return &port.QueryResult{Data: map[string]interface{}{"items": []interface{}{}}, Source: "fake"}, nil
+		}
+		return &port.QueryResult{Data: map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": float64(1), "sender": "鲁迅", "_child_count": float64(2)}},
+			"total": float64(1),
+		}, Source: "fake"}, nil
+	}
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true, DataType: "INTEGER"},
+			{FieldName: "sender", IsReturnable: true, DataType: "TEXT"},
+			{FieldName: "place", DataType: "TEXT"},
+		}, admin, nil))
+
+	hierarchyURL := "/api/v1/admin/biz-config/archive/tables/letters/hierarchy"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, hierarchyURL,
+		domain.HierarchyConfig{KeyField: "id", ParentField: "id"}, admin, nil), "键字段与父字段不能相同")
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, hierarchyURL,
+		domain.HierarchyConfig{KeyField: "id", ParentField: "place"}, admin, nil), "父字段必须可返回")
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, hierarchyURL,
+		domain.HierarchyConfig{KeyField: "id", ParentField: "sender", SortField: "place"}, admin, nil))
+
+	var bizConfig domain.BizQueryConfig
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive", nil, admin, &bizConfig))
+	require.Contains(t, bizConfig.Tables, "letters")
+	assert.Equal(t, &domain.HierarchyConfig{KeyField: "id", ParentField: "sender", SortField: "place"},
+		bizConfig.Tables["letters"].Hierarchy)
+
+	var children struct {
+		Data struct {
+			Items []map[string]interface{} `json:"items"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/data/tree/archive/letters/children?node=1&size=9999", nil, "", &children))
+	require.Len(t, children.Data.Items, 1)
+	assert.EqualValues(t, 2, children.Data.Items[0]["_child_count"])
+	queries := fake.Queries()
+	last := queries[len(queries)-1].Query
+	assert.Equal(t, port.QueryModeChildren, last[port.QueryModeKey])
+	assert.Equal(t, "1", last[port.QueryNodeKey])
+	assert.EqualValues(t, 500, last["size"], "size 被限制在上限内")
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/data/tree/archive/letters/ancestors", nil, "", nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/data/tree/archive/letters/ancestors?node=9", nil, "", nil))
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/data/tree/archive/letters/ancestors?node=1", nil, "", nil))
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, hierarchyURL, nil, admin, nil))
+}
+
+func TestHarness_Timeline(t *testing.T) {
+	h := NewHarness(t)
+	plain := newLettersSource()
+	h.RegisterDataSource("plain", plain)
+	fake := newLettersSource()
+	fake.QueryFunc = func(_ context.Context, req port.QueryRequest) (*port.QueryResult, error) {
+		return &port.QueryResult{Data: map[string]interface{}{
+			"buckets": []interface{}{map[string]interface{}{"bucket": "1921", "count": float64(2)}},
+			"total":   float64(3),
+			"undated": float64(1),
+		}, Source: "fake"}, nil
+	}
+	h.RegisterDataSource("archive", fake)
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/timeline", map[string]interface{}{
+		"biz_name":    "archive",
+		"query":       map[string]interface{}{"table": "letters", "page": 3, "size": 10, "distinct": true},
+		"field":       "sent",
+		"granularity": "year",
+	}, "", &body))
+	assert.EqualValues(t, 1, body.Data["undated"])
+	queries := fake.Queries()
+	sent := queries[len(queries)-1].Query
+	assert.Equal(t, map[string]interface{}{"field": "sent", "granularity": "year"}, sent[port.QueryTimelineKey])
+	for _, key := range []string{"page", "size", port.QueryDistinctKey} {
+		assert.NotContains(t, sent, key, "分页与去重参数不传给数据源")
+	}
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/data/timeline", map[string]interface{}{
+		"biz_name": "archive", "query": map[string]interface{}{"table": "letters"},
+	}, "", nil), "必须指定日期字段")
+	assert.Equal(t, http.StatusNotImplemented, h.DoJSON(http.MethodPost, "/api/v1/data/timeline", map[string]interface{}{
+		"biz_name": "plain", "query": map[string]interface{}{"table": "letters"}, "field": "sent",
+	}, "", nil), "不支持时间轴的数据源返回普通结果")
+}
+
+func TestHarness_ComplianceExport(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+
+	var report domain.ComplianceReport
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/compliance/export", nil, admin, &report))
+	require.Len(t, report.Bizs, 1)
+	assert.True(t, report.Bizs[0].Public)
+	assert.NotEmpty(t, report.Users)
+
+	resp := h.Do(http.MethodGet, "/api/v1/admin/compliance/export?format=prometheus", nil, admin)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), ".prom")
+	assert.Contains(t, string(body), `archiveaegis_compliance_biz_public{biz="archive"} 1`)
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/admin/compliance/export?format=xml", nil, admin, nil))
+	assert.Equal(t, http.StatusConflict, h.DoJSON(http.MethodPost, "/api/v1/admin/compliance/export/files", nil, admin, nil),
+		"未配置导出目录")
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/admin/compliance/export", nil, "", nil))
+}
+
+func TestHarness_AuthorizationHooks(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+
+	var inputs []port.PolicyInput
+	h.Policies.Register("test", port.AuthorizationHookFunc(func(ctx context.Context, input port.PolicyInput) (port.PolicyDecision, error) {
+		inputs = append(inputs, input)
+		if input.Plane == port.PolicyPlaneAdmin && strings.HasSuffix(input.Path, "/compliance/export") {
+			return port.PolicyDecision{}, errors.New("策略引擎不可达")
+		}
+		if input.Table == "letters" && input.User.Role != "admin" {
+			return port.PolicyDecision{Allow: false, Reason: "书信仅限馆员查阅"}, nil
+		}
+		return port.PolicyDecision{Allow: true}, nil
+	}))
+
+	query := map[string]interface{}{
+		"biz_name": "archive",
+		"query":    map[string]interface{}{"table": "letters", "filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}}},
+	}
+	var denied struct{ Error string }
+	require.Equal(t, http.StatusForbidden, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", &denied))
+	assert.Equal(t, "书信仅限馆员查阅", denied.Error)
+	assert.Empty(t, fake.Queries(), "被拒绝的请求不会到达数据源")
+	require.Len(t, inputs, 1)
+	assert.Equal(t, port.PolicyPlaneData, inputs[0].Plane)
+	assert.Equal(t, "archive", inputs[0].BizName)
+	assert.Equal(t, "query", inputs[0].Operation)
+	assert.Equal(t, int64(0), inputs[0].User.ID)
+	assert.NotNil(t, inputs[0].Filters)
+
+	var body struct{ Data map[string]interface{} }
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, admin, &body),
+		"钩子读取请求体后处理器仍能解析")
+	assert.Equal(t, float64(2), body.Data["total"])
+	assert.Equal(t, "admin", inputs[1].User.Role)
+
+	assert.Equal(t, http.StatusServiceUnavailable, h.DoJSON(http.MethodGet, "/api/v1/admin/compliance/export", nil, admin, nil),
+		"钩子出错时默认拒绝")
+	assert.Equal(t, port.PolicyPlaneAdmin, inputs[len(inputs)-1].Plane)
+	assert.Equal(t, http.MethodGet, inputs[len(inputs)-1].Operation)
+}
+
+func TestHarness_QueryTemplates(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{{FieldName: "id", IsReturnable: true}, {FieldName: "sender", IsSearchable: true, IsReturnable: true}}, admin, nil))
+
+	template := domain.QueryTemplate{
+		Description: "按写信人检索书信",
+		TableName:   "letters",
+		Filters:     []domain.QueryTemplateFilter{{Field: "sender", Param: "sender"}},
+		Params:      []domain.QueryTemplateParam{{Name: "sender", Required: true, Description: "写信人姓名"}},
+	}
+	require.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/query-templates/by-sender", template, "", nil))
+	template.Filters[0].Field = "place"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/query-templates/by-sender", template, admin, nil),
+		"不可搜索的字段不能用于模板")
+	template.Filters[0].Field = "sender"
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/query-templates/by-sender", template, admin, nil))
+
+	var listed struct{ Data []domain.QueryTemplate }
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/query-templates/archive", nil, "", &listed))
+	require.Len(t, listed.Data, 1)
+	assert.Equal(t, "by-sender", listed.Data[0].Name)
+	assert.Equal(t, "写信人姓名", listed.Data[0].Params[0].Description)
+
+	var body struct{ Data map[string]interface{} }
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query-templates/archive/by-sender",
+		map[string]interface{}{"params": map[string]interface{}{"sender": "鲁迅"}, "size": 10}, "", &body))
+	assert.Equal(t, float64(2), body.Data["total"])
+	queries := fake.Queries()
+	require.Len(t, queries, 1)
+	assert.Equal(t, "letters", queries[0].Query["table"])
+	assert.EqualValues(t, 10, queries[0].Query["size"])
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/data/query-templates/archive/by-sender",
+		map[string]interface{}{"params": map[string]interface{}{}}, "", nil), "缺少必填参数")
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodPost, "/api/v1/data/query-templates/archive/missing", nil, "", nil))
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/biz-config/archive/query-templates/by-sender", nil, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/query-templates/archive", nil, "", &listed))
+	assert.Empty(t, listed.Data)
+}
+
+func TestHarness_ResultDiff(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	h.RegisterDataSource("archive_restored", NewFakeDataSource().WithTable("letters",
+		[]port.FieldDescription{{Name: "id", IsPrimary: true}, {Name: "sender"}, {Name: "place"}},
+		map[string]interface{}{"id": float64(1), "sender": "鲁迅", "place": "北京"},
+		map[string]interface{}{"id": float64(2), "sender": "胡适", "place": "上海"},
+	))
+	request := map[string]interface{}{
+		"before": map[string]interface{}{"biz_name": "archive_restored", "query": map[string]interface{}{"table": "letters"}},
+		"after":  map[string]interface{}{"biz_name": "archive"},
+	}
+	require.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodPost, "/api/v1/data/diff", request, "", nil))
+
+	var body struct{ Data domain.ResultDiff }
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/diff", request, h.AdminToken(), &body))
+	assert.Equal(t, []string{"id"}, body.Data.Key)
+	assert.Equal(t, 1, body.Data.Summary.Added)
+	assert.Equal(t, 1, body.Data.Summary.Changed)
+	require.Len(t, body.Data.Entries, 2)
+	assert.Equal(t, []string{"place"}, body.Data.Entries[0].Fields)
+
+	request["key"] = []string{"sender"}
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/data/diff", request, h.AdminToken(), nil), "键字段不唯一")
+}
+
+func TestHarness_BizTimezoneAndLocale(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	tz, locale, badTZ := "Asia/Shanghai", "zh-CN", "Mars/Olympus"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{Timezone: &badTZ}, admin, nil), "无法识别的时区")
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{Timezone: &tz, Locale: &locale}, admin, nil))
+
+	var body struct {
+		Data struct {
+			Timezone           string `json:"timezone"`
+			TimezoneConfigured bool   `json:"timezone_configured"`
+			UTCOffset          string `json:"utc_offset"`
+			Locale             string `json:"locale"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/locale/archive", nil, "", &body))
+	assert.Equal(t, "Asia/Shanghai", body.Data.Timezone)
+	assert.True(t, body.Data.TimezoneConfigured)
+	assert.Equal(t, "+08:00", body.Data.UTCOffset)
+	assert.Equal(t, "zh-CN", body.Data.Locale)
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/meta/locale/missing", nil, "", nil))
+
+	query := map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{
+		"table": "letters", "filters": []interface{}{map[string]interface{}{"field": "sender", "date_range": "someday"}},
+	}}
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, admin, nil), "不支持的相对日期范围")
+}
+
+func TestHarness_RecordAccessLog(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	query := map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{
+		"table": "letters", "filters": []interface{}{map[string]interface{}{"field": "sender", "value": "鲁迅"}},
+	}}
+	var out struct {
+		Data  []domain.RecordAccess `json:"data"`
+		Total int64                 `json:"total"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/record-access", nil, admin, &out))
+	assert.Empty(t, out.Data, "未标记为敏感的业务组不记录")
+
+	sensitive := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &sensitive, Sensitive: &sensitive}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/data/record/archive/letters/2", nil, "", nil))
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/record-access?biz=archive", nil, admin, &out))
+	assert.Equal(t, int64(3), out.Total)
+	ids := map[string]string{}
+	for _, entry := range out.Data {
+		ids[entry.RecordID] = entry.Source
+	}
+	assert.Equal(t, map[string]string{"1": domain.RecordAccessQuery, "3": domain.RecordAccessQuery, "2": domain.RecordAccessPermalink}, ids)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/record-access?record_id=2", nil, admin, &out))
+	require.Len(t, out.Data, 1)
+	assert.Nil(t, out.Data[0].UserID, "匿名访问者只记录 IP")
+	assert.NotEmpty(t, out.Data[0].IP)
+
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/security/record-access?record_id=1", nil, admin, &out))
+	require.Len(t, out.Data, 1)
+	require.NotNil(t, out.Data[0].UserID)
+	assert.Equal(t, harnessAdminUser, out.Data[0].Username)
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/admin/security/record-access?since=yesterday", nil, admin, nil))
+}
+
+func TestHarness_CloneBizConfig(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	h.RegisterDataSource("branch", newLettersSource())
+	h.RegisterDataSource("museum", NewFakeDataSource().WithTable("objects", []port.FieldDescription{{Name: "id"}}))
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true, DisplayName: "寄信人"},
+		}, admin, nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/clone?target=museum", nil, admin, nil),
+		"目标业务组没有 letters 表")
+	var body struct{ Data domain.BizCloneReport }
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/clone?target=branch", nil, admin, &body))
+	assert.Equal(t, []string{"letters"}, body.Data.Tables)
+
+	var cfg domain.BizQueryConfig
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/branch", nil, admin, &cfg))
+	assert.True(t, cfg.IsPubliclySearchable)
+	require.Contains(t, cfg.Tables, "letters")
+	assert.Equal(t, "寄信人", cfg.Tables["letters"].Fields["sender"].DisplayName)
+	assert.Equal(t, http.StatusConflict, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/clone?target=branch", nil, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/clone", nil, admin, nil))
+}
+
+func TestHarness_SLOTracksBizRequests(t *testing.T) {
+	h := NewHarness(t)
+	source := newLettersSource()
+	h.RegisterDataSource("archive", source)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{{FieldName: "id", IsReturnable: true}, {FieldName: "sender", IsSearchable: true, IsReturnable: true}}, admin, nil))
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/slo/archive",
+		map[string]interface{}{"availability_target": 1.5}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/slo/archive",
+		map[string]interface{}{"availability_target": 0.99, "latency_threshold_ms": 60000, "latency_target": 0.9}, admin, nil))
+
+	query := map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{"table": "letters"}}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+	}
+	source.FailQuery(errors.New("下游不可用"))
+	require.Equal(t, http.StatusInternalServerError, h.DoJSON(http.MethodPost, "/api/v1/data/query", query, "", nil))
+
+	var body struct {
+		Data domain.SLOStatus `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/slo/archive", nil, admin, &body))
+	require.Len(t, body.Data.Objectives, 2)
+	availability := body.Data.Objectives[0]
+	assert.Equal(t, domain.SLOObjectiveAvailability, availability.Objective)
+	assert.Equal(t, int64(4), availability.Requests)
+	assert.Equal(t, int64(1), availability.BadRequests)
+	assert.Equal(t, domain.SLOAlertExhausted, availability.Alert)
+	assert.Equal(t, int64(0), body.Data.Objectives[1].BadRequests)
+
+	var list struct {
+		Data []domain.SLOStatus `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/slo", nil, admin, &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, http.StatusOK, h.DoJSON(http.MethodDelete, "/api/v1/admin/slo/archive", nil, admin, nil))
+	assert.Equal(t, http.StatusNotFound, h.DoJSON(http.MethodGet, "/api/v1/admin/slo/archive", nil, admin, nil))
+}
+
+func TestHarness_ConditionalFormatsInPresentations(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: false},
+		}, admin, nil))
+
+	rule := domain.ConditionalFormat{Field: "sender", Op: domain.ConditionOpEq, Value: "鲁迅", Scope: domain.ConditionScopeRow,
+		Style: domain.ConditionalStyle{Tone: domain.ConditionToneWarning, Label: "重点"}}
+	view := func(rules ...domain.ConditionalFormat) map[string][]*domain.ViewConfig {
+		return map[string][]*domain.ViewConfig{"letters": {{ViewName: "full", ViewType: "table", IsDefault: true, ConditionalFormats: rules}}}
+	}
+	// 引用不可返回或未配置的字段、使用未知色调的规则被拒绝
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		view(domain.ConditionalFormat{Field: "place", Op: domain.ConditionOpIsNull, Style: domain.ConditionalStyle{Bold: true}}), admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		view(domain.ConditionalFormat{Field: "year", Op: domain.ConditionOpIsNull, Style: domain.ConditionalStyle{Bold: true}}), admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views",
+		view(domain.ConditionalFormat{Field: "sender", Op: domain.ConditionOpEq, Style: domain.ConditionalStyle{Tone: "red"}}), admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/views", view(rule), admin, nil))
+
+	var body struct {
+		Data domain.ViewConfig `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/meta/presentations?biz=archive&table=letters", nil, "", &body))
+	assert.Equal(t, []domain.ConditionalFormat{rule}, body.Data.ConditionalFormats)
+}
+
+func TestHarness_PreviewUnsavedView(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{
+			{FieldName: "id", IsReturnable: true},
+			{FieldName: "sender", IsSearchable: true, IsReturnable: true},
+			{FieldName: "place", IsSearchable: true, IsReturnable: true},
+		}, admin, nil))
+
+	view := domain.ViewConfig{ViewName: "draft", ViewType: "table",
+		Binding: domain.ViewBinding{Table: &domain.TableBinding{Columns: []domain.TableColumnBinding{
+			{Field: "sender", DisplayName: "发信人", Format: "boolean:是/否"},
+		}}},
+		DefaultFilters: []domain.ViewFilter{{Field: "sender", Value: "鲁迅"}},
+	}
+	var body struct {
+		Data map[string]interface{}
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/views/preview", map[string]interface{}{
+		"table": "letters", "view": view, "aliased": true, "query": map[string]interface{}{"size": 500},
+	}, admin, &body))
+	items := body.Data["items"].([]interface{})
+	require.Len(t, items, 2)
+	for _, item := range items {
+		assert.Equal(t, "鲁迅", item.(map[string]interface{})["发信人"])
+		assert.NotContains(t, item, "place")
+	}
+	assert.Equal(t, "draft", body.Data["view"].(map[string]interface{})["view_name"])
+	columns := body.Data["view_config"].(map[string]interface{})["binding"].(map[string]interface{})["table"].(map[string]interface{})["columns"].([]interface{})
+	assert.Equal(t, "boolean", columns[0].(map[string]interface{})["format_spec"].(map[string]interface{})["type"])
+	queries := fake.Queries()
+	assert.Equal(t, float64(50), queries[len(queries)-1].Query["size"], "预览的行数受上限约束")
+
+	// 预览不保存视图，无效的视图按保存时的规则拒绝
+	var views map[string][]*domain.ViewConfig
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/archive/views", nil, admin, &views))
+	assert.Empty(t, views["letters"])
+	view.DefaultSort = "sender"
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/views/preview", map[string]interface{}{
+		"table": "letters", "view": view,
+	}, admin, nil))
+}
+
+func TestHarness_InspectCacheAndLimiters(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/data/query",
+		map[string]interface{}{"biz_name": "archive", "query": map[string]interface{}{"table": "letters"}}, "", nil))
+
+	var cache domain.ConfigCacheState
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/inspect/config-cache", nil, admin, &cache))
+	require.Len(t, cache.Entries, 1)
+	assert.Equal(t, "archive", cache.Entries[0].BizName)
+	assert.Positive(t, cache.Entries[0].ExpiresInSeconds)
+	assert.NotEmpty(t, cache.ConfigVersion)
+
+	var limiters struct {
+		Items []domain.RateLimiterBucket `json:"items"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/inspect/rate-limiters?dimension=anonymous", nil, admin, &limiters))
+	require.Len(t, limiters.Items, 1)
+	assert.Equal(t, "archive", limiters.Items[0].Key)
+	assert.Less(t, limiters.Items[0].Tokens, float64(limiters.Items[0].Burst), "匿名查询消耗了令牌")
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/admin/inspect/rate-limiters?dimension=planet", nil, admin, nil))
+
+	var load map[string]interface{}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/inspect/load", nil, admin, &load))
+	assert.Nil(t, load["load_shedding"], "未启用的组件返回 null")
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/admin/inspect/load", nil, "", nil))
+}
+
+func TestHarness_RenameBiz(t *testing.T) {
+	h := NewHarness(t)
+	h.RegisterDataSource("archive", newLettersSource())
+	admin := h.AdminToken()
+	public := true
+	for _, biz := range []string{"archive", "drafts"} {
+		require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/"+biz+"/settings",
+			domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/archive/rename",
+		map[string]string{"new_name": "letters"}, admin, nil), "数据源不是由插件实例提供的")
+	assert.Equal(t, http.StatusConflict, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/drafts/rename",
+		map[string]string{"new_name": "archive"}, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/drafts/rename",
+		map[string]string{}, admin, nil))
+
+	var out struct {
+		Data domain.BizRenameReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPost, "/api/v1/admin/biz-config/drafts/rename",
+		map[string]string{"new_name": "manuscripts"}, admin, &out))
+	assert.Equal(t, int64(1), out.Data.Tables["biz_overall_settings"])
+	var cfg domain.BizQueryConfig
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-config/manuscripts", nil, admin, &cfg))
+	assert.True(t, cfg.IsPubliclySearchable)
+
+	var renames struct {
+		Data []domain.BizRename `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/biz-renames?biz=drafts", nil, admin, &renames))
+	require.Len(t, renames.Data, 1)
+	assert.Equal(t, "manuscripts", renames.Data[0].NewName)
+}
+
+func TestHarness_IdentifierPolicy(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	admin := h.AdminToken()
+	public := true
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/settings",
+		domain.BizOverallSettings{IsPubliclySearchable: &public}, admin, nil))
+
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters", `letters"; DROP TABLE users; --`}}, admin, nil))
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables",
+		map[string]interface{}{"searchable_tables": []string{"letters"}}, admin, nil))
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodPut, "/api/v1/admin/biz-config/archive/tables/letters/fields",
+		[]domain.FieldSetting{{FieldName: "id", IsReturnable: true}, {FieldName: "__lib", IsReturnable: true}}, admin, nil),
+		"保留前缀")
+
+	queries := len(fake.Queries())
+	status := h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+		"biz_name": "archive",
+		"query": map[string]interface{}{
+			"table":   "letters",
+			"filters": []interface{}{map[string]interface{}{"field": `sender" OR 1=1 --`, "value": "x"}},
+		},
+	}, admin, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, http.StatusBadRequest, h.DoJSON(http.MethodGet, "/api/v1/data/record/archive/sqlite_master/1", nil, admin, nil))
+	assert.Len(t, fake.Queries(), queries, "不合规的请求不应到达数据源")
+
+	var report struct {
+		Data domain.IdentifierPolicyReport `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, h.DoJSON(http.MethodGet, "/api/v1/admin/identifier-policy/report", nil, admin, &report))
+	assert.Equal(t, "strict", report.Data.Mode)
+	assert.Empty(t, report.Data.Violations)
+	assert.Equal(t, http.StatusUnauthorized, h.DoJSON(http.MethodGet, "/api/v1/admin/identifier-policy/report", nil, "", nil))
+}
+
+func TestHarness_QueryExtensions(t *testing.T) {
+	h := NewHarness(t)
+	fake := newLettersSource()
+	h.RegisterDataSource("archive", fake)
+	h.RegisterDataSource("drafts", newLettersSource())
+	admin := h.AdminToken()
+
+	query := func(biz string, extensions map[string]interface{}) int {
+		return h.DoJSON(http.MethodPost, "/api/v1/data/query", map[string]interface{}{
+			"biz_name":   biz,
+			"query":      map[string]interface{}{"table": "letters"},
+			"extensions": extensions,
+		}, admin, nil)
+	}
+	require.Equal(t, http.StatusOK, query("archive", map[string]interface{}{"hint": "idx_sender"}))
+	queries := fake.Queries()
+	require.NotEmpty(t, queries)
+	assert.Equal(t, map[string]interface{}{"hint": "idx_sender"}, queries[len(queries)-1].Extensions, "白名单中的扩展选项原样下发")
+
+	assert.Equal(t, http.StatusBadRequest, query("archive", map[string]interface{}{"script": "ctx._source"}))
+	assert.Equal(t, http.StatusBadRequest, query("drafts", map[string]interface{}{"hint": "idx_sender"}), "未在白名单中配置的业务组")
+	assert.Equal(t, http.StatusOK, query("drafts", nil))
+}
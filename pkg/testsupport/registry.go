@@ -0,0 +1,58 @@
+// file: pkg/testsupport/registry.go
+package testsupport
+
+import (
+	"ArchiveAegis/internal/core/port"
+	"ArchiveAegis/internal/service/plugin_manager"
+	"sync"
+)
+
+// StaticRegistry 是插件管理器数据源注册表的内存实现，满足虚拟、分片与读写分离业务组服务所需的 Registrar 接口。
+// 通过 RegisterPlugin 放入的数据源视为由插件实例提供，与插件管理器一样不能被静态数据源覆盖或注销。
+type StaticRegistry struct {
+	mu      sync.RWMutex
+	sources map[string]port.DataSource
+	plugins map[string]bool
+}
+
+// NewStaticRegistry 创建一个空的注册表
+func NewStaticRegistry() *StaticRegistry {
+	return &StaticRegistry{sources: make(map[string]port.DataSource), plugins: make(map[string]bool)}
+}
+
+// RegisterPlugin 以插件实例的名义注册业务组的数据源，返回自身以便链式调用
+func (r *StaticRegistry) RegisterPlugin(bizName string, ds port.DataSource) *StaticRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[bizName] = ds
+	r.plugins[bizName] = true
+	return r
+}
+
+// RegisterStaticDataSource 注册一个静态数据源，业务组已由插件提供时返回 plugin_manager.ErrBizServedByPlugin
+func (r *StaticRegistry) RegisterStaticDataSource(bizName string, ds port.DataSource) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plugins[bizName] {
+		return plugin_manager.ErrBizServedByPlugin
+	}
+	r.sources[bizName] = ds
+	return nil
+}
+
+// UnregisterStaticDataSource 注销一个静态数据源，插件提供的业务组不受影响
+func (r *StaticRegistry) UnregisterStaticDataSource(bizName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.plugins[bizName] {
+		delete(r.sources, bizName)
+	}
+}
+
+// LookupDataSource 按业务组名查找当前已注册的数据源
+func (r *StaticRegistry) LookupDataSource(bizName string) (port.DataSource, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ds, ok := r.sources[bizName]
+	return ds, ok
+}